@@ -10,6 +10,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -78,6 +79,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, nil, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"temporary ECS instance, key pair, security group and VPC", "image and snapshots"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 
 	client, err := b.config.Client()
@@ -163,9 +168,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 				b.config.SSHPrivateIp),
 			SSHConfig: b.config.RunConfig.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.RunConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.RunConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.RunConfig.Guest,
 		},
 		&stepStopAlicloudInstance{
 			ForceStop:   b.config.ForceStopInstance,
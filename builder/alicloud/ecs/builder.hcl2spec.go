@@ -50,8 +50,14 @@ type FlatConfig struct {
 	PackerBuildName                   *string                     `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
 	PackerBuilderType                 *string                     `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
 	PackerDebug                       *bool                       `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerBreakpoints                 []string                    `mapstructure:"packer_breakpoints" cty:"packer_breakpoints" hcl:"packer_breakpoints"`
 	PackerForce                       *bool                       `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
 	PackerOnError                     *string                     `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerOnConflict                  *string                     `mapstructure:"packer_on_conflict" cty:"packer_on_conflict" hcl:"packer_on_conflict"`
+	PackerJournal                     *string                     `mapstructure:"packer_journal" cty:"packer_journal" hcl:"packer_journal"`
+	PackerResume                      *bool                       `mapstructure:"packer_resume" cty:"packer_resume" hcl:"packer_resume"`
+	PackerBillingTags                 map[string]string           `mapstructure:"packer_billing_tags" cty:"packer_billing_tags" hcl:"packer_billing_tags"`
+	PackerPreflight                   *string                     `mapstructure:"packer_preflight" cty:"packer_preflight" hcl:"packer_preflight"`
 	PackerUserVars                    map[string]string           `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
 	PackerSensitiveVars               []string                    `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
 	AlicloudAccessKey                 *string                     `mapstructure:"access_key" required:"true" cty:"access_key" hcl:"access_key"`
@@ -101,36 +107,60 @@ type FlatConfig struct {
 	WaitSnapshotReadyTimeout          *int                        `mapstructure:"wait_snapshot_ready_timeout" required:"false" cty:"wait_snapshot_ready_timeout" hcl:"wait_snapshot_ready_timeout"`
 	Type                              *string                     `mapstructure:"communicator" cty:"communicator" hcl:"communicator"`
 	PauseBeforeConnect                *string                     `mapstructure:"pause_before_connecting" cty:"pause_before_connecting" hcl:"pause_before_connecting"`
+	LivenessTimeout                   *string                     `mapstructure:"liveness_timeout" cty:"liveness_timeout" hcl:"liveness_timeout"`
+	MetricsInterval                   *string                     `mapstructure:"metrics_interval" cty:"metrics_interval" hcl:"metrics_interval"`
+	DisableGuestFacts                 *bool                       `mapstructure:"disable_guest_facts" cty:"disable_guest_facts" hcl:"disable_guest_facts"`
 	SSHHost                           *string                     `mapstructure:"ssh_host" cty:"ssh_host" hcl:"ssh_host"`
 	SSHPort                           *int                        `mapstructure:"ssh_port" cty:"ssh_port" hcl:"ssh_port"`
 	SSHUsername                       *string                     `mapstructure:"ssh_username" cty:"ssh_username" hcl:"ssh_username"`
 	SSHPassword                       *string                     `mapstructure:"ssh_password" cty:"ssh_password" hcl:"ssh_password"`
 	SSHKeyPairName                    *string                     `mapstructure:"ssh_keypair_name" undocumented:"true" cty:"ssh_keypair_name" hcl:"ssh_keypair_name"`
 	SSHTemporaryKeyPairName           *string                     `mapstructure:"temporary_key_pair_name" undocumented:"true" cty:"temporary_key_pair_name" hcl:"temporary_key_pair_name"`
+	SSHTemporaryKeyPairShared         *bool                       `mapstructure:"temporary_key_pair_shared" cty:"temporary_key_pair_shared" hcl:"temporary_key_pair_shared"`
+	SSHTemporaryKeyPairReuse          *bool                       `mapstructure:"temporary_key_pair_reuse" cty:"temporary_key_pair_reuse" hcl:"temporary_key_pair_reuse"`
 	SSHCiphers                        []string                    `mapstructure:"ssh_ciphers" cty:"ssh_ciphers" hcl:"ssh_ciphers"`
 	SSHClearAuthorizedKeys            *bool                       `mapstructure:"ssh_clear_authorized_keys" cty:"ssh_clear_authorized_keys" hcl:"ssh_clear_authorized_keys"`
 	SSHKEXAlgos                       []string                    `mapstructure:"ssh_key_exchange_algorithms" cty:"ssh_key_exchange_algorithms" hcl:"ssh_key_exchange_algorithms"`
+	SSHSecurityPolicy                 *string                     `mapstructure:"ssh_security_policy" cty:"ssh_security_policy" hcl:"ssh_security_policy"`
 	SSHPrivateKeyFile                 *string                     `mapstructure:"ssh_private_key_file" undocumented:"true" cty:"ssh_private_key_file" hcl:"ssh_private_key_file"`
 	SSHCertificateFile                *string                     `mapstructure:"ssh_certificate_file" cty:"ssh_certificate_file" hcl:"ssh_certificate_file"`
 	SSHPty                            *bool                       `mapstructure:"ssh_pty" cty:"ssh_pty" hcl:"ssh_pty"`
+	SSHPtyOutput                      *string                     `mapstructure:"ssh_pty_output" cty:"ssh_pty_output" hcl:"ssh_pty_output"`
 	SSHTimeout                        *string                     `mapstructure:"ssh_timeout" cty:"ssh_timeout" hcl:"ssh_timeout"`
-	SSHWaitTimeout                    *string                     `mapstructure:"ssh_wait_timeout" undocumented:"true" cty:"ssh_wait_timeout" hcl:"ssh_wait_timeout"`
+	SSHWaitTimeout                    *string                     `mapstructure:"ssh_wait_timeout" undocumented:"true" deprecated:"ssh_timeout" cty:"ssh_wait_timeout" hcl:"ssh_wait_timeout"`
 	SSHAgentAuth                      *bool                       `mapstructure:"ssh_agent_auth" undocumented:"true" cty:"ssh_agent_auth" hcl:"ssh_agent_auth"`
 	SSHDisableAgentForwarding         *bool                       `mapstructure:"ssh_disable_agent_forwarding" cty:"ssh_disable_agent_forwarding" hcl:"ssh_disable_agent_forwarding"`
 	SSHHandshakeAttempts              *int                        `mapstructure:"ssh_handshake_attempts" cty:"ssh_handshake_attempts" hcl:"ssh_handshake_attempts"`
+	SSHHandshakeTimeout               *string                     `mapstructure:"ssh_handshake_timeout" cty:"ssh_handshake_timeout" hcl:"ssh_handshake_timeout"`
+	SSHBannerReadTimeout              *string                     `mapstructure:"ssh_banner_read_timeout" cty:"ssh_banner_read_timeout" hcl:"ssh_banner_read_timeout"`
+	SSHPreAuthGracePeriod             *string                     `mapstructure:"ssh_pre_auth_grace_period" cty:"ssh_pre_auth_grace_period" hcl:"ssh_pre_auth_grace_period"`
 	SSHBastionHost                    *string                     `mapstructure:"ssh_bastion_host" cty:"ssh_bastion_host" hcl:"ssh_bastion_host"`
 	SSHBastionPort                    *int                        `mapstructure:"ssh_bastion_port" cty:"ssh_bastion_port" hcl:"ssh_bastion_port"`
 	SSHBastionAgentAuth               *bool                       `mapstructure:"ssh_bastion_agent_auth" cty:"ssh_bastion_agent_auth" hcl:"ssh_bastion_agent_auth"`
 	SSHBastionUsername                *string                     `mapstructure:"ssh_bastion_username" cty:"ssh_bastion_username" hcl:"ssh_bastion_username"`
 	SSHBastionPassword                *string                     `mapstructure:"ssh_bastion_password" cty:"ssh_bastion_password" hcl:"ssh_bastion_password"`
 	SSHBastionInteractive             *bool                       `mapstructure:"ssh_bastion_interactive" cty:"ssh_bastion_interactive" hcl:"ssh_bastion_interactive"`
+	SSHBastionTOTPSecret              *string                     `mapstructure:"ssh_bastion_totp_secret" cty:"ssh_bastion_totp_secret" hcl:"ssh_bastion_totp_secret"`
 	SSHBastionPrivateKeyFile          *string                     `mapstructure:"ssh_bastion_private_key_file" cty:"ssh_bastion_private_key_file" hcl:"ssh_bastion_private_key_file"`
 	SSHBastionCertificateFile         *string                     `mapstructure:"ssh_bastion_certificate_file" cty:"ssh_bastion_certificate_file" hcl:"ssh_bastion_certificate_file"`
 	SSHFileTransferMethod             *string                     `mapstructure:"ssh_file_transfer_method" cty:"ssh_file_transfer_method" hcl:"ssh_file_transfer_method"`
+	SSHSftpServerBinary               *string                     `mapstructure:"ssh_sftp_server_binary" cty:"ssh_sftp_server_binary" hcl:"ssh_sftp_server_binary"`
 	SSHProxyHost                      *string                     `mapstructure:"ssh_proxy_host" cty:"ssh_proxy_host" hcl:"ssh_proxy_host"`
 	SSHProxyPort                      *int                        `mapstructure:"ssh_proxy_port" cty:"ssh_proxy_port" hcl:"ssh_proxy_port"`
 	SSHProxyUsername                  *string                     `mapstructure:"ssh_proxy_username" cty:"ssh_proxy_username" hcl:"ssh_proxy_username"`
 	SSHProxyPassword                  *string                     `mapstructure:"ssh_proxy_password" cty:"ssh_proxy_password" hcl:"ssh_proxy_password"`
+	SSHCallbackAddress                *string                     `mapstructure:"ssh_callback_address" cty:"ssh_callback_address" hcl:"ssh_callback_address"`
+	SSHCallbackAuthorizedKeysFile     *string                     `mapstructure:"ssh_callback_authorized_keys_file" cty:"ssh_callback_authorized_keys_file" hcl:"ssh_callback_authorized_keys_file"`
+	SSHCallbackHostKeyFile            *string                     `mapstructure:"ssh_callback_host_key_file" cty:"ssh_callback_host_key_file" hcl:"ssh_callback_host_key_file"`
+	SSHTeleportProxy                  *string                     `mapstructure:"ssh_teleport_proxy" cty:"ssh_teleport_proxy" hcl:"ssh_teleport_proxy"`
+	SSHTeleportCluster                *string                     `mapstructure:"ssh_teleport_cluster" cty:"ssh_teleport_cluster" hcl:"ssh_teleport_cluster"`
+	SSHTeleportIdentityFile           *string                     `mapstructure:"ssh_teleport_identity_file" cty:"ssh_teleport_identity_file" hcl:"ssh_teleport_identity_file"`
+	SSHBoundaryTargetID               *string                     `mapstructure:"ssh_boundary_target_id" cty:"ssh_boundary_target_id" hcl:"ssh_boundary_target_id"`
+	SSHBoundaryHostID                 *string                     `mapstructure:"ssh_boundary_host_id" cty:"ssh_boundary_host_id" hcl:"ssh_boundary_host_id"`
+	SSHBoundaryAddr                   *string                     `mapstructure:"ssh_boundary_addr" cty:"ssh_boundary_addr" hcl:"ssh_boundary_addr"`
+	SSHWebSocketURL                   *string                     `mapstructure:"ssh_websocket_url" cty:"ssh_websocket_url" hcl:"ssh_websocket_url"`
+	SSHWebSocketToken                 *string                     `mapstructure:"ssh_websocket_token" cty:"ssh_websocket_token" hcl:"ssh_websocket_token"`
+	SSHWebSocketInsecureSkipTLSVerify *bool                       `mapstructure:"ssh_websocket_insecure_skip_tls_verify" cty:"ssh_websocket_insecure_skip_tls_verify" hcl:"ssh_websocket_insecure_skip_tls_verify"`
 	SSHKeepAliveInterval              *string                     `mapstructure:"ssh_keep_alive_interval" cty:"ssh_keep_alive_interval" hcl:"ssh_keep_alive_interval"`
 	SSHReadWriteTimeout               *string                     `mapstructure:"ssh_read_write_timeout" cty:"ssh_read_write_timeout" hcl:"ssh_read_write_timeout"`
 	SSHRemoteTunnels                  []string                    `mapstructure:"ssh_remote_tunnels" cty:"ssh_remote_tunnels" hcl:"ssh_remote_tunnels"`
@@ -146,6 +176,33 @@ type FlatConfig struct {
 	WinRMUseSSL                       *bool                       `mapstructure:"winrm_use_ssl" cty:"winrm_use_ssl" hcl:"winrm_use_ssl"`
 	WinRMInsecure                     *bool                       `mapstructure:"winrm_insecure" cty:"winrm_insecure" hcl:"winrm_insecure"`
 	WinRMUseNTLM                      *bool                       `mapstructure:"winrm_use_ntlm" cty:"winrm_use_ntlm" hcl:"winrm_use_ntlm"`
+	WinRMUsePSRP                      *bool                       `mapstructure:"winrm_use_psrp" cty:"winrm_use_psrp" hcl:"winrm_use_psrp"`
+	WinRMBastionHost                  *string                     `mapstructure:"winrm_bastion_host" cty:"winrm_bastion_host" hcl:"winrm_bastion_host"`
+	WinRMBastionPort                  *int                        `mapstructure:"winrm_bastion_port" cty:"winrm_bastion_port" hcl:"winrm_bastion_port"`
+	WinRMBastionUsername              *string                     `mapstructure:"winrm_bastion_username" cty:"winrm_bastion_username" hcl:"winrm_bastion_username"`
+	WinRMBastionPassword              *string                     `mapstructure:"winrm_bastion_password" cty:"winrm_bastion_password" hcl:"winrm_bastion_password"`
+	WinRMBastionPrivateKeyFile        *string                     `mapstructure:"winrm_bastion_private_key_file" cty:"winrm_bastion_private_key_file" hcl:"winrm_bastion_private_key_file"`
+	WinRMBastionAgentAuth             *bool                       `mapstructure:"winrm_bastion_agent_auth" cty:"winrm_bastion_agent_auth" hcl:"winrm_bastion_agent_auth"`
+	WinRMProxyHost                    *string                     `mapstructure:"winrm_proxy_host" cty:"winrm_proxy_host" hcl:"winrm_proxy_host"`
+	WinRMProxyPort                    *int                        `mapstructure:"winrm_proxy_port" cty:"winrm_proxy_port" hcl:"winrm_proxy_port"`
+	WinRMProxyUsername                *string                     `mapstructure:"winrm_proxy_username" cty:"winrm_proxy_username" hcl:"winrm_proxy_username"`
+	WinRMProxyPassword                *string                     `mapstructure:"winrm_proxy_password" cty:"winrm_proxy_password" hcl:"winrm_proxy_password"`
+	CredentialRotation                *string                     `mapstructure:"credential_rotation" cty:"credential_rotation" hcl:"credential_rotation"`
+	GuestCleanup                      []string                    `mapstructure:"guest_cleanup" cty:"guest_cleanup" hcl:"guest_cleanup"`
+	GuestCleanupDryRun                *bool                       `mapstructure:"guest_cleanup_dry_run" cty:"guest_cleanup_dry_run" hcl:"guest_cleanup_dry_run"`
+	SysprepGeneralize                 *bool                       `mapstructure:"sysprep_generalize" cty:"sysprep_generalize" hcl:"sysprep_generalize"`
+	SysprepUnattendFile               *string                     `mapstructure:"sysprep_unattend_file" cty:"sysprep_unattend_file" hcl:"sysprep_unattend_file"`
+	SysprepModeVM                     *bool                       `mapstructure:"sysprep_mode_vm" cty:"sysprep_mode_vm" hcl:"sysprep_mode_vm"`
+	SysprepTimeout                    *string                     `mapstructure:"sysprep_timeout" cty:"sysprep_timeout" hcl:"sysprep_timeout"`
+	LinuxGeneralize                   *bool                       `mapstructure:"linux_generalize" cty:"linux_generalize" hcl:"linux_generalize"`
+	LinuxGeneralizeOperations         []string                    `mapstructure:"linux_generalize_operations" cty:"linux_generalize_operations" hcl:"linux_generalize_operations"`
+	LinuxGeneralizeSkip               []string                    `mapstructure:"linux_generalize_skip" cty:"linux_generalize_skip" hcl:"linux_generalize_skip"`
+	RemoteShell                       *string                     `mapstructure:"remote_shell" cty:"remote_shell" hcl:"remote_shell"`
+	RemoteLocale                      *string                     `mapstructure:"remote_locale" cty:"remote_locale" hcl:"remote_locale"`
+	RemotePath                        *string                     `mapstructure:"remote_path" cty:"remote_path" hcl:"remote_path"`
+	ClockSkewCheck                    *bool                       `mapstructure:"clock_skew_check" cty:"clock_skew_check" hcl:"clock_skew_check"`
+	ClockMaxSkew                      *string                     `mapstructure:"clock_max_skew" cty:"clock_max_skew" hcl:"clock_max_skew"`
+	ClockSkewFix                      *bool                       `mapstructure:"clock_skew_fix" cty:"clock_skew_fix" hcl:"clock_skew_fix"`
 	SSHPrivateIp                      *bool                       `mapstructure:"ssh_private_ip" required:"false" cty:"ssh_private_ip" hcl:"ssh_private_ip"`
 }
 
@@ -161,106 +218,163 @@ func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec }
 // The decoded values from this spec will then be applied to a FlatConfig.
 func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"packer_build_name":            &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
-		"packer_builder_type":          &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
-		"packer_debug":                 &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
-		"packer_force":                 &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
-		"packer_on_error":              &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
-		"packer_user_variables":        &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
-		"packer_sensitive_variables":   &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
-		"access_key":                   &hcldec.AttrSpec{Name: "access_key", Type: cty.String, Required: false},
-		"secret_key":                   &hcldec.AttrSpec{Name: "secret_key", Type: cty.String, Required: false},
-		"region":                       &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
-		"skip_region_validation":       &hcldec.AttrSpec{Name: "skip_region_validation", Type: cty.Bool, Required: false},
-		"skip_image_validation":        &hcldec.AttrSpec{Name: "skip_image_validation", Type: cty.Bool, Required: false},
-		"profile":                      &hcldec.AttrSpec{Name: "profile", Type: cty.String, Required: false},
-		"shared_credentials_file":      &hcldec.AttrSpec{Name: "shared_credentials_file", Type: cty.String, Required: false},
-		"security_token":               &hcldec.AttrSpec{Name: "security_token", Type: cty.String, Required: false},
-		"image_name":                   &hcldec.AttrSpec{Name: "image_name", Type: cty.String, Required: false},
-		"image_version":                &hcldec.AttrSpec{Name: "image_version", Type: cty.String, Required: false},
-		"image_description":            &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
-		"image_share_account":          &hcldec.AttrSpec{Name: "image_share_account", Type: cty.List(cty.String), Required: false},
-		"image_unshare_account":        &hcldec.AttrSpec{Name: "image_unshare_account", Type: cty.List(cty.String), Required: false},
-		"image_copy_regions":           &hcldec.AttrSpec{Name: "image_copy_regions", Type: cty.List(cty.String), Required: false},
-		"image_copy_names":             &hcldec.AttrSpec{Name: "image_copy_names", Type: cty.List(cty.String), Required: false},
-		"image_encrypted":              &hcldec.AttrSpec{Name: "image_encrypted", Type: cty.Bool, Required: false},
-		"image_force_delete":           &hcldec.AttrSpec{Name: "image_force_delete", Type: cty.Bool, Required: false},
-		"image_force_delete_snapshots": &hcldec.AttrSpec{Name: "image_force_delete_snapshots", Type: cty.Bool, Required: false},
-		"image_force_delete_instances": &hcldec.AttrSpec{Name: "image_force_delete_instances", Type: cty.Bool, Required: false},
-		"image_ignore_data_disks":      &hcldec.AttrSpec{Name: "image_ignore_data_disks", Type: cty.Bool, Required: false},
-		"tags":                         &hcldec.AttrSpec{Name: "tags", Type: cty.Map(cty.String), Required: false},
-		"tag":                          &hcldec.BlockListSpec{TypeName: "tag", Nested: hcldec.ObjectSpec((*hcl2template.FlatKeyValue)(nil).HCL2Spec())},
-		"system_disk_mapping":          &hcldec.BlockSpec{TypeName: "system_disk_mapping", Nested: hcldec.ObjectSpec((*FlatAlicloudDiskDevice)(nil).HCL2Spec())},
-		"image_disk_mappings":          &hcldec.BlockListSpec{TypeName: "image_disk_mappings", Nested: hcldec.ObjectSpec((*FlatAlicloudDiskDevice)(nil).HCL2Spec())},
-		"associate_public_ip_address":  &hcldec.AttrSpec{Name: "associate_public_ip_address", Type: cty.Bool, Required: false},
-		"zone_id":                      &hcldec.AttrSpec{Name: "zone_id", Type: cty.String, Required: false},
-		"io_optimized":                 &hcldec.AttrSpec{Name: "io_optimized", Type: cty.Bool, Required: false},
-		"instance_type":                &hcldec.AttrSpec{Name: "instance_type", Type: cty.String, Required: false},
-		"description":                  &hcldec.AttrSpec{Name: "description", Type: cty.String, Required: false},
-		"source_image":                 &hcldec.AttrSpec{Name: "source_image", Type: cty.String, Required: false},
-		"force_stop_instance":          &hcldec.AttrSpec{Name: "force_stop_instance", Type: cty.Bool, Required: false},
-		"disable_stop_instance":        &hcldec.AttrSpec{Name: "disable_stop_instance", Type: cty.Bool, Required: false},
-		"security_group_id":            &hcldec.AttrSpec{Name: "security_group_id", Type: cty.String, Required: false},
-		"security_group_name":          &hcldec.AttrSpec{Name: "security_group_name", Type: cty.String, Required: false},
-		"user_data":                    &hcldec.AttrSpec{Name: "user_data", Type: cty.String, Required: false},
-		"user_data_file":               &hcldec.AttrSpec{Name: "user_data_file", Type: cty.String, Required: false},
-		"vpc_id":                       &hcldec.AttrSpec{Name: "vpc_id", Type: cty.String, Required: false},
-		"vpc_name":                     &hcldec.AttrSpec{Name: "vpc_name", Type: cty.String, Required: false},
-		"vpc_cidr_block":               &hcldec.AttrSpec{Name: "vpc_cidr_block", Type: cty.String, Required: false},
-		"vswitch_id":                   &hcldec.AttrSpec{Name: "vswitch_id", Type: cty.String, Required: false},
-		"vswitch_name":                 &hcldec.AttrSpec{Name: "vswitch_name", Type: cty.String, Required: false},
-		"instance_name":                &hcldec.AttrSpec{Name: "instance_name", Type: cty.String, Required: false},
-		"internet_charge_type":         &hcldec.AttrSpec{Name: "internet_charge_type", Type: cty.String, Required: false},
-		"internet_max_bandwidth_out":   &hcldec.AttrSpec{Name: "internet_max_bandwidth_out", Type: cty.Number, Required: false},
-		"wait_snapshot_ready_timeout":  &hcldec.AttrSpec{Name: "wait_snapshot_ready_timeout", Type: cty.Number, Required: false},
-		"communicator":                 &hcldec.AttrSpec{Name: "communicator", Type: cty.String, Required: false},
-		"pause_before_connecting":      &hcldec.AttrSpec{Name: "pause_before_connecting", Type: cty.String, Required: false},
-		"ssh_host":                     &hcldec.AttrSpec{Name: "ssh_host", Type: cty.String, Required: false},
-		"ssh_port":                     &hcldec.AttrSpec{Name: "ssh_port", Type: cty.Number, Required: false},
-		"ssh_username":                 &hcldec.AttrSpec{Name: "ssh_username", Type: cty.String, Required: false},
-		"ssh_password":                 &hcldec.AttrSpec{Name: "ssh_password", Type: cty.String, Required: false},
-		"ssh_keypair_name":             &hcldec.AttrSpec{Name: "ssh_keypair_name", Type: cty.String, Required: false},
-		"temporary_key_pair_name":      &hcldec.AttrSpec{Name: "temporary_key_pair_name", Type: cty.String, Required: false},
-		"ssh_ciphers":                  &hcldec.AttrSpec{Name: "ssh_ciphers", Type: cty.List(cty.String), Required: false},
-		"ssh_clear_authorized_keys":    &hcldec.AttrSpec{Name: "ssh_clear_authorized_keys", Type: cty.Bool, Required: false},
-		"ssh_key_exchange_algorithms":  &hcldec.AttrSpec{Name: "ssh_key_exchange_algorithms", Type: cty.List(cty.String), Required: false},
-		"ssh_private_key_file":         &hcldec.AttrSpec{Name: "ssh_private_key_file", Type: cty.String, Required: false},
-		"ssh_certificate_file":         &hcldec.AttrSpec{Name: "ssh_certificate_file", Type: cty.String, Required: false},
-		"ssh_pty":                      &hcldec.AttrSpec{Name: "ssh_pty", Type: cty.Bool, Required: false},
-		"ssh_timeout":                  &hcldec.AttrSpec{Name: "ssh_timeout", Type: cty.String, Required: false},
-		"ssh_wait_timeout":             &hcldec.AttrSpec{Name: "ssh_wait_timeout", Type: cty.String, Required: false},
-		"ssh_agent_auth":               &hcldec.AttrSpec{Name: "ssh_agent_auth", Type: cty.Bool, Required: false},
-		"ssh_disable_agent_forwarding": &hcldec.AttrSpec{Name: "ssh_disable_agent_forwarding", Type: cty.Bool, Required: false},
-		"ssh_handshake_attempts":       &hcldec.AttrSpec{Name: "ssh_handshake_attempts", Type: cty.Number, Required: false},
-		"ssh_bastion_host":             &hcldec.AttrSpec{Name: "ssh_bastion_host", Type: cty.String, Required: false},
-		"ssh_bastion_port":             &hcldec.AttrSpec{Name: "ssh_bastion_port", Type: cty.Number, Required: false},
-		"ssh_bastion_agent_auth":       &hcldec.AttrSpec{Name: "ssh_bastion_agent_auth", Type: cty.Bool, Required: false},
-		"ssh_bastion_username":         &hcldec.AttrSpec{Name: "ssh_bastion_username", Type: cty.String, Required: false},
-		"ssh_bastion_password":         &hcldec.AttrSpec{Name: "ssh_bastion_password", Type: cty.String, Required: false},
-		"ssh_bastion_interactive":      &hcldec.AttrSpec{Name: "ssh_bastion_interactive", Type: cty.Bool, Required: false},
-		"ssh_bastion_private_key_file": &hcldec.AttrSpec{Name: "ssh_bastion_private_key_file", Type: cty.String, Required: false},
-		"ssh_bastion_certificate_file": &hcldec.AttrSpec{Name: "ssh_bastion_certificate_file", Type: cty.String, Required: false},
-		"ssh_file_transfer_method":     &hcldec.AttrSpec{Name: "ssh_file_transfer_method", Type: cty.String, Required: false},
-		"ssh_proxy_host":               &hcldec.AttrSpec{Name: "ssh_proxy_host", Type: cty.String, Required: false},
-		"ssh_proxy_port":               &hcldec.AttrSpec{Name: "ssh_proxy_port", Type: cty.Number, Required: false},
-		"ssh_proxy_username":           &hcldec.AttrSpec{Name: "ssh_proxy_username", Type: cty.String, Required: false},
-		"ssh_proxy_password":           &hcldec.AttrSpec{Name: "ssh_proxy_password", Type: cty.String, Required: false},
-		"ssh_keep_alive_interval":      &hcldec.AttrSpec{Name: "ssh_keep_alive_interval", Type: cty.String, Required: false},
-		"ssh_read_write_timeout":       &hcldec.AttrSpec{Name: "ssh_read_write_timeout", Type: cty.String, Required: false},
-		"ssh_remote_tunnels":           &hcldec.AttrSpec{Name: "ssh_remote_tunnels", Type: cty.List(cty.String), Required: false},
-		"ssh_local_tunnels":            &hcldec.AttrSpec{Name: "ssh_local_tunnels", Type: cty.List(cty.String), Required: false},
-		"ssh_public_key":               &hcldec.AttrSpec{Name: "ssh_public_key", Type: cty.List(cty.Number), Required: false},
-		"ssh_private_key":              &hcldec.AttrSpec{Name: "ssh_private_key", Type: cty.List(cty.Number), Required: false},
-		"winrm_username":               &hcldec.AttrSpec{Name: "winrm_username", Type: cty.String, Required: false},
-		"winrm_password":               &hcldec.AttrSpec{Name: "winrm_password", Type: cty.String, Required: false},
-		"winrm_host":                   &hcldec.AttrSpec{Name: "winrm_host", Type: cty.String, Required: false},
-		"winrm_no_proxy":               &hcldec.AttrSpec{Name: "winrm_no_proxy", Type: cty.Bool, Required: false},
-		"winrm_port":                   &hcldec.AttrSpec{Name: "winrm_port", Type: cty.Number, Required: false},
-		"winrm_timeout":                &hcldec.AttrSpec{Name: "winrm_timeout", Type: cty.String, Required: false},
-		"winrm_use_ssl":                &hcldec.AttrSpec{Name: "winrm_use_ssl", Type: cty.Bool, Required: false},
-		"winrm_insecure":               &hcldec.AttrSpec{Name: "winrm_insecure", Type: cty.Bool, Required: false},
-		"winrm_use_ntlm":               &hcldec.AttrSpec{Name: "winrm_use_ntlm", Type: cty.Bool, Required: false},
-		"ssh_private_ip":               &hcldec.AttrSpec{Name: "ssh_private_ip", Type: cty.Bool, Required: false},
+		"packer_build_name":                      &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":                    &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_debug":                           &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_breakpoints":                     &hcldec.AttrSpec{Name: "packer_breakpoints", Type: cty.List(cty.String), Required: false},
+		"packer_force":                           &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":                        &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_on_conflict":                     &hcldec.AttrSpec{Name: "packer_on_conflict", Type: cty.String, Required: false},
+		"packer_journal":                         &hcldec.AttrSpec{Name: "packer_journal", Type: cty.String, Required: false},
+		"packer_resume":                          &hcldec.AttrSpec{Name: "packer_resume", Type: cty.Bool, Required: false},
+		"packer_billing_tags":                    &hcldec.AttrSpec{Name: "packer_billing_tags", Type: cty.Map(cty.String), Required: false},
+		"packer_preflight":                       &hcldec.AttrSpec{Name: "packer_preflight", Type: cty.String, Required: false},
+		"packer_user_variables":                  &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables":             &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"access_key":                             &hcldec.AttrSpec{Name: "access_key", Type: cty.String, Required: false},
+		"secret_key":                             &hcldec.AttrSpec{Name: "secret_key", Type: cty.String, Required: false},
+		"region":                                 &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
+		"skip_region_validation":                 &hcldec.AttrSpec{Name: "skip_region_validation", Type: cty.Bool, Required: false},
+		"skip_image_validation":                  &hcldec.AttrSpec{Name: "skip_image_validation", Type: cty.Bool, Required: false},
+		"profile":                                &hcldec.AttrSpec{Name: "profile", Type: cty.String, Required: false},
+		"shared_credentials_file":                &hcldec.AttrSpec{Name: "shared_credentials_file", Type: cty.String, Required: false},
+		"security_token":                         &hcldec.AttrSpec{Name: "security_token", Type: cty.String, Required: false},
+		"image_name":                             &hcldec.AttrSpec{Name: "image_name", Type: cty.String, Required: false},
+		"image_version":                          &hcldec.AttrSpec{Name: "image_version", Type: cty.String, Required: false},
+		"image_description":                      &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
+		"image_share_account":                    &hcldec.AttrSpec{Name: "image_share_account", Type: cty.List(cty.String), Required: false},
+		"image_unshare_account":                  &hcldec.AttrSpec{Name: "image_unshare_account", Type: cty.List(cty.String), Required: false},
+		"image_copy_regions":                     &hcldec.AttrSpec{Name: "image_copy_regions", Type: cty.List(cty.String), Required: false},
+		"image_copy_names":                       &hcldec.AttrSpec{Name: "image_copy_names", Type: cty.List(cty.String), Required: false},
+		"image_encrypted":                        &hcldec.AttrSpec{Name: "image_encrypted", Type: cty.Bool, Required: false},
+		"image_force_delete":                     &hcldec.AttrSpec{Name: "image_force_delete", Type: cty.Bool, Required: false},
+		"image_force_delete_snapshots":           &hcldec.AttrSpec{Name: "image_force_delete_snapshots", Type: cty.Bool, Required: false},
+		"image_force_delete_instances":           &hcldec.AttrSpec{Name: "image_force_delete_instances", Type: cty.Bool, Required: false},
+		"image_ignore_data_disks":                &hcldec.AttrSpec{Name: "image_ignore_data_disks", Type: cty.Bool, Required: false},
+		"tags":                                   &hcldec.AttrSpec{Name: "tags", Type: cty.Map(cty.String), Required: false},
+		"tag":                                    &hcldec.BlockListSpec{TypeName: "tag", Nested: hcldec.ObjectSpec((*hcl2template.FlatKeyValue)(nil).HCL2Spec())},
+		"system_disk_mapping":                    &hcldec.BlockSpec{TypeName: "system_disk_mapping", Nested: hcldec.ObjectSpec((*FlatAlicloudDiskDevice)(nil).HCL2Spec())},
+		"image_disk_mappings":                    &hcldec.BlockListSpec{TypeName: "image_disk_mappings", Nested: hcldec.ObjectSpec((*FlatAlicloudDiskDevice)(nil).HCL2Spec())},
+		"associate_public_ip_address":            &hcldec.AttrSpec{Name: "associate_public_ip_address", Type: cty.Bool, Required: false},
+		"zone_id":                                &hcldec.AttrSpec{Name: "zone_id", Type: cty.String, Required: false},
+		"io_optimized":                           &hcldec.AttrSpec{Name: "io_optimized", Type: cty.Bool, Required: false},
+		"instance_type":                          &hcldec.AttrSpec{Name: "instance_type", Type: cty.String, Required: false},
+		"description":                            &hcldec.AttrSpec{Name: "description", Type: cty.String, Required: false},
+		"source_image":                           &hcldec.AttrSpec{Name: "source_image", Type: cty.String, Required: false},
+		"force_stop_instance":                    &hcldec.AttrSpec{Name: "force_stop_instance", Type: cty.Bool, Required: false},
+		"disable_stop_instance":                  &hcldec.AttrSpec{Name: "disable_stop_instance", Type: cty.Bool, Required: false},
+		"security_group_id":                      &hcldec.AttrSpec{Name: "security_group_id", Type: cty.String, Required: false},
+		"security_group_name":                    &hcldec.AttrSpec{Name: "security_group_name", Type: cty.String, Required: false},
+		"user_data":                              &hcldec.AttrSpec{Name: "user_data", Type: cty.String, Required: false},
+		"user_data_file":                         &hcldec.AttrSpec{Name: "user_data_file", Type: cty.String, Required: false},
+		"vpc_id":                                 &hcldec.AttrSpec{Name: "vpc_id", Type: cty.String, Required: false},
+		"vpc_name":                               &hcldec.AttrSpec{Name: "vpc_name", Type: cty.String, Required: false},
+		"vpc_cidr_block":                         &hcldec.AttrSpec{Name: "vpc_cidr_block", Type: cty.String, Required: false},
+		"vswitch_id":                             &hcldec.AttrSpec{Name: "vswitch_id", Type: cty.String, Required: false},
+		"vswitch_name":                           &hcldec.AttrSpec{Name: "vswitch_name", Type: cty.String, Required: false},
+		"instance_name":                          &hcldec.AttrSpec{Name: "instance_name", Type: cty.String, Required: false},
+		"internet_charge_type":                   &hcldec.AttrSpec{Name: "internet_charge_type", Type: cty.String, Required: false},
+		"internet_max_bandwidth_out":             &hcldec.AttrSpec{Name: "internet_max_bandwidth_out", Type: cty.Number, Required: false},
+		"wait_snapshot_ready_timeout":            &hcldec.AttrSpec{Name: "wait_snapshot_ready_timeout", Type: cty.Number, Required: false},
+		"communicator":                           &hcldec.AttrSpec{Name: "communicator", Type: cty.String, Required: false},
+		"pause_before_connecting":                &hcldec.AttrSpec{Name: "pause_before_connecting", Type: cty.String, Required: false},
+		"liveness_timeout":                       &hcldec.AttrSpec{Name: "liveness_timeout", Type: cty.String, Required: false},
+		"metrics_interval":                       &hcldec.AttrSpec{Name: "metrics_interval", Type: cty.String, Required: false},
+		"disable_guest_facts":                    &hcldec.AttrSpec{Name: "disable_guest_facts", Type: cty.Bool, Required: false},
+		"ssh_host":                               &hcldec.AttrSpec{Name: "ssh_host", Type: cty.String, Required: false},
+		"ssh_port":                               &hcldec.AttrSpec{Name: "ssh_port", Type: cty.Number, Required: false},
+		"ssh_username":                           &hcldec.AttrSpec{Name: "ssh_username", Type: cty.String, Required: false},
+		"ssh_password":                           &hcldec.AttrSpec{Name: "ssh_password", Type: cty.String, Required: false},
+		"ssh_keypair_name":                       &hcldec.AttrSpec{Name: "ssh_keypair_name", Type: cty.String, Required: false},
+		"temporary_key_pair_name":                &hcldec.AttrSpec{Name: "temporary_key_pair_name", Type: cty.String, Required: false},
+		"temporary_key_pair_shared":              &hcldec.AttrSpec{Name: "temporary_key_pair_shared", Type: cty.Bool, Required: false},
+		"temporary_key_pair_reuse":               &hcldec.AttrSpec{Name: "temporary_key_pair_reuse", Type: cty.Bool, Required: false},
+		"ssh_ciphers":                            &hcldec.AttrSpec{Name: "ssh_ciphers", Type: cty.List(cty.String), Required: false},
+		"ssh_clear_authorized_keys":              &hcldec.AttrSpec{Name: "ssh_clear_authorized_keys", Type: cty.Bool, Required: false},
+		"ssh_key_exchange_algorithms":            &hcldec.AttrSpec{Name: "ssh_key_exchange_algorithms", Type: cty.List(cty.String), Required: false},
+		"ssh_security_policy":                    &hcldec.AttrSpec{Name: "ssh_security_policy", Type: cty.String, Required: false},
+		"ssh_private_key_file":                   &hcldec.AttrSpec{Name: "ssh_private_key_file", Type: cty.String, Required: false},
+		"ssh_certificate_file":                   &hcldec.AttrSpec{Name: "ssh_certificate_file", Type: cty.String, Required: false},
+		"ssh_pty":                                &hcldec.AttrSpec{Name: "ssh_pty", Type: cty.Bool, Required: false},
+		"ssh_pty_output":                         &hcldec.AttrSpec{Name: "ssh_pty_output", Type: cty.String, Required: false},
+		"ssh_timeout":                            &hcldec.AttrSpec{Name: "ssh_timeout", Type: cty.String, Required: false},
+		"ssh_wait_timeout":                       &hcldec.AttrSpec{Name: "ssh_wait_timeout", Type: cty.String, Required: false},
+		"ssh_agent_auth":                         &hcldec.AttrSpec{Name: "ssh_agent_auth", Type: cty.Bool, Required: false},
+		"ssh_disable_agent_forwarding":           &hcldec.AttrSpec{Name: "ssh_disable_agent_forwarding", Type: cty.Bool, Required: false},
+		"ssh_handshake_attempts":                 &hcldec.AttrSpec{Name: "ssh_handshake_attempts", Type: cty.Number, Required: false},
+		"ssh_handshake_timeout":                  &hcldec.AttrSpec{Name: "ssh_handshake_timeout", Type: cty.String, Required: false},
+		"ssh_banner_read_timeout":                &hcldec.AttrSpec{Name: "ssh_banner_read_timeout", Type: cty.String, Required: false},
+		"ssh_pre_auth_grace_period":              &hcldec.AttrSpec{Name: "ssh_pre_auth_grace_period", Type: cty.String, Required: false},
+		"ssh_bastion_host":                       &hcldec.AttrSpec{Name: "ssh_bastion_host", Type: cty.String, Required: false},
+		"ssh_bastion_port":                       &hcldec.AttrSpec{Name: "ssh_bastion_port", Type: cty.Number, Required: false},
+		"ssh_bastion_agent_auth":                 &hcldec.AttrSpec{Name: "ssh_bastion_agent_auth", Type: cty.Bool, Required: false},
+		"ssh_bastion_username":                   &hcldec.AttrSpec{Name: "ssh_bastion_username", Type: cty.String, Required: false},
+		"ssh_bastion_password":                   &hcldec.AttrSpec{Name: "ssh_bastion_password", Type: cty.String, Required: false},
+		"ssh_bastion_interactive":                &hcldec.AttrSpec{Name: "ssh_bastion_interactive", Type: cty.Bool, Required: false},
+		"ssh_bastion_totp_secret":                &hcldec.AttrSpec{Name: "ssh_bastion_totp_secret", Type: cty.String, Required: false},
+		"ssh_bastion_private_key_file":           &hcldec.AttrSpec{Name: "ssh_bastion_private_key_file", Type: cty.String, Required: false},
+		"ssh_bastion_certificate_file":           &hcldec.AttrSpec{Name: "ssh_bastion_certificate_file", Type: cty.String, Required: false},
+		"ssh_file_transfer_method":               &hcldec.AttrSpec{Name: "ssh_file_transfer_method", Type: cty.String, Required: false},
+		"ssh_sftp_server_binary":                 &hcldec.AttrSpec{Name: "ssh_sftp_server_binary", Type: cty.String, Required: false},
+		"ssh_proxy_host":                         &hcldec.AttrSpec{Name: "ssh_proxy_host", Type: cty.String, Required: false},
+		"ssh_proxy_port":                         &hcldec.AttrSpec{Name: "ssh_proxy_port", Type: cty.Number, Required: false},
+		"ssh_proxy_username":                     &hcldec.AttrSpec{Name: "ssh_proxy_username", Type: cty.String, Required: false},
+		"ssh_proxy_password":                     &hcldec.AttrSpec{Name: "ssh_proxy_password", Type: cty.String, Required: false},
+		"ssh_callback_address":                   &hcldec.AttrSpec{Name: "ssh_callback_address", Type: cty.String, Required: false},
+		"ssh_callback_authorized_keys_file":      &hcldec.AttrSpec{Name: "ssh_callback_authorized_keys_file", Type: cty.String, Required: false},
+		"ssh_callback_host_key_file":             &hcldec.AttrSpec{Name: "ssh_callback_host_key_file", Type: cty.String, Required: false},
+		"ssh_teleport_proxy":                     &hcldec.AttrSpec{Name: "ssh_teleport_proxy", Type: cty.String, Required: false},
+		"ssh_teleport_cluster":                   &hcldec.AttrSpec{Name: "ssh_teleport_cluster", Type: cty.String, Required: false},
+		"ssh_teleport_identity_file":             &hcldec.AttrSpec{Name: "ssh_teleport_identity_file", Type: cty.String, Required: false},
+		"ssh_boundary_target_id":                 &hcldec.AttrSpec{Name: "ssh_boundary_target_id", Type: cty.String, Required: false},
+		"ssh_boundary_host_id":                   &hcldec.AttrSpec{Name: "ssh_boundary_host_id", Type: cty.String, Required: false},
+		"ssh_boundary_addr":                      &hcldec.AttrSpec{Name: "ssh_boundary_addr", Type: cty.String, Required: false},
+		"ssh_websocket_url":                      &hcldec.AttrSpec{Name: "ssh_websocket_url", Type: cty.String, Required: false},
+		"ssh_websocket_token":                    &hcldec.AttrSpec{Name: "ssh_websocket_token", Type: cty.String, Required: false},
+		"ssh_websocket_insecure_skip_tls_verify": &hcldec.AttrSpec{Name: "ssh_websocket_insecure_skip_tls_verify", Type: cty.Bool, Required: false},
+		"ssh_keep_alive_interval":                &hcldec.AttrSpec{Name: "ssh_keep_alive_interval", Type: cty.String, Required: false},
+		"ssh_read_write_timeout":                 &hcldec.AttrSpec{Name: "ssh_read_write_timeout", Type: cty.String, Required: false},
+		"ssh_remote_tunnels":                     &hcldec.AttrSpec{Name: "ssh_remote_tunnels", Type: cty.List(cty.String), Required: false},
+		"ssh_local_tunnels":                      &hcldec.AttrSpec{Name: "ssh_local_tunnels", Type: cty.List(cty.String), Required: false},
+		"ssh_public_key":                         &hcldec.AttrSpec{Name: "ssh_public_key", Type: cty.List(cty.Number), Required: false},
+		"ssh_private_key":                        &hcldec.AttrSpec{Name: "ssh_private_key", Type: cty.List(cty.Number), Required: false},
+		"winrm_username":                         &hcldec.AttrSpec{Name: "winrm_username", Type: cty.String, Required: false},
+		"winrm_password":                         &hcldec.AttrSpec{Name: "winrm_password", Type: cty.String, Required: false},
+		"winrm_host":                             &hcldec.AttrSpec{Name: "winrm_host", Type: cty.String, Required: false},
+		"winrm_no_proxy":                         &hcldec.AttrSpec{Name: "winrm_no_proxy", Type: cty.Bool, Required: false},
+		"winrm_port":                             &hcldec.AttrSpec{Name: "winrm_port", Type: cty.Number, Required: false},
+		"winrm_timeout":                          &hcldec.AttrSpec{Name: "winrm_timeout", Type: cty.String, Required: false},
+		"winrm_use_ssl":                          &hcldec.AttrSpec{Name: "winrm_use_ssl", Type: cty.Bool, Required: false},
+		"winrm_insecure":                         &hcldec.AttrSpec{Name: "winrm_insecure", Type: cty.Bool, Required: false},
+		"winrm_use_ntlm":                         &hcldec.AttrSpec{Name: "winrm_use_ntlm", Type: cty.Bool, Required: false},
+		"winrm_use_psrp":                         &hcldec.AttrSpec{Name: "winrm_use_psrp", Type: cty.Bool, Required: false},
+		"winrm_bastion_host":                     &hcldec.AttrSpec{Name: "winrm_bastion_host", Type: cty.String, Required: false},
+		"winrm_bastion_port":                     &hcldec.AttrSpec{Name: "winrm_bastion_port", Type: cty.Number, Required: false},
+		"winrm_bastion_username":                 &hcldec.AttrSpec{Name: "winrm_bastion_username", Type: cty.String, Required: false},
+		"winrm_bastion_password":                 &hcldec.AttrSpec{Name: "winrm_bastion_password", Type: cty.String, Required: false},
+		"winrm_bastion_private_key_file":         &hcldec.AttrSpec{Name: "winrm_bastion_private_key_file", Type: cty.String, Required: false},
+		"winrm_bastion_agent_auth":               &hcldec.AttrSpec{Name: "winrm_bastion_agent_auth", Type: cty.Bool, Required: false},
+		"winrm_proxy_host":                       &hcldec.AttrSpec{Name: "winrm_proxy_host", Type: cty.String, Required: false},
+		"winrm_proxy_port":                       &hcldec.AttrSpec{Name: "winrm_proxy_port", Type: cty.Number, Required: false},
+		"winrm_proxy_username":                   &hcldec.AttrSpec{Name: "winrm_proxy_username", Type: cty.String, Required: false},
+		"winrm_proxy_password":                   &hcldec.AttrSpec{Name: "winrm_proxy_password", Type: cty.String, Required: false},
+		"credential_rotation":                    &hcldec.AttrSpec{Name: "credential_rotation", Type: cty.String, Required: false},
+		"guest_cleanup":                          &hcldec.AttrSpec{Name: "guest_cleanup", Type: cty.List(cty.String), Required: false},
+		"guest_cleanup_dry_run":                  &hcldec.AttrSpec{Name: "guest_cleanup_dry_run", Type: cty.Bool, Required: false},
+		"sysprep_generalize":                     &hcldec.AttrSpec{Name: "sysprep_generalize", Type: cty.Bool, Required: false},
+		"sysprep_unattend_file":                  &hcldec.AttrSpec{Name: "sysprep_unattend_file", Type: cty.String, Required: false},
+		"sysprep_mode_vm":                        &hcldec.AttrSpec{Name: "sysprep_mode_vm", Type: cty.Bool, Required: false},
+		"sysprep_timeout":                        &hcldec.AttrSpec{Name: "sysprep_timeout", Type: cty.String, Required: false},
+		"linux_generalize":                       &hcldec.AttrSpec{Name: "linux_generalize", Type: cty.Bool, Required: false},
+		"linux_generalize_operations":            &hcldec.AttrSpec{Name: "linux_generalize_operations", Type: cty.List(cty.String), Required: false},
+		"linux_generalize_skip":                  &hcldec.AttrSpec{Name: "linux_generalize_skip", Type: cty.List(cty.String), Required: false},
+		"remote_shell":                           &hcldec.AttrSpec{Name: "remote_shell", Type: cty.String, Required: false},
+		"remote_locale":                          &hcldec.AttrSpec{Name: "remote_locale", Type: cty.String, Required: false},
+		"remote_path":                            &hcldec.AttrSpec{Name: "remote_path", Type: cty.String, Required: false},
+		"clock_skew_check":                       &hcldec.AttrSpec{Name: "clock_skew_check", Type: cty.Bool, Required: false},
+		"clock_max_skew":                         &hcldec.AttrSpec{Name: "clock_max_skew", Type: cty.String, Required: false},
+		"clock_skew_fix":                         &hcldec.AttrSpec{Name: "clock_skew_fix", Type: cty.Bool, Required: false},
+		"ssh_private_ip":                         &hcldec.AttrSpec{Name: "ssh_private_ip", Type: cty.Bool, Required: false},
 	}
 	return s
 }
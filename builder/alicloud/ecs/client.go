@@ -1,12 +1,14 @@
 package ecs
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/errors"
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/responses"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/ecs"
+	"github.com/hashicorp/packer/common/wait"
 )
 
 type ClientWrapper struct {
@@ -148,6 +150,18 @@ type WaitForExpectArgs struct {
 	RetryTimeout  time.Duration
 }
 
+// stopRetryError carries the error, possibly nil, of the request the
+// evaluation stopped retrying on.
+type stopRetryError struct {
+	err error
+}
+
+func (e *stopRetryError) Error() string {
+	return fmt.Sprint(e.err)
+}
+
+var errRetryTimesExceeded = fmt.Errorf("retry times exceeded")
+
 func (c *ClientWrapper) WaitForExpected(args *WaitForExpectArgs) (responses.AcsResponse, error) {
 	if args.RetryInterval <= 0 {
 		args.RetryInterval = defaultRetryInterval
@@ -156,36 +170,40 @@ func (c *ClientWrapper) WaitForExpected(args *WaitForExpectArgs) (responses.AcsR
 		args.RetryTimes = defaultRetryTimes
 	}
 
-	var timeoutPoint time.Time
-	if args.RetryTimeout > 0 {
-		timeoutPoint = time.Now().Add(args.RetryTimeout)
-	}
-
 	var lastResponse responses.AcsResponse
 	var lastError error
 
-	for i := 0; ; i++ {
-		if args.RetryTimeout > 0 && time.Now().After(timeoutPoint) {
-			break
-		}
-
-		if args.RetryTimeout <= 0 && i >= args.RetryTimes {
-			break
-		}
-
+	retries := 0
+	err := wait.Config{
+		Description: "the expected response",
+		Timeout:     args.RetryTimeout,
+		MinDelay:    args.RetryInterval,
+		MaxDelay:    args.RetryInterval,
+		Multiplier:  1,
+	}.Until(context.TODO(), func(context.Context) (bool, string, error) {
 		response, err := args.RequestFunc()
 		lastResponse = response
 		lastError = err
 
 		evalResult := args.EvalFunc(response, err)
 		if evalResult.evalPass {
-			return response, nil
+			return true, "passed", nil
 		}
 		if evalResult.stopRetry {
-			return response, err
+			return false, "stopped", &stopRetryError{err: err}
 		}
 
-		time.Sleep(args.RetryInterval)
+		retries++
+		if args.RetryTimeout <= 0 && retries >= args.RetryTimes {
+			return false, "retrying", errRetryTimesExceeded
+		}
+		return false, "retrying", nil
+	})
+	if err == nil {
+		return lastResponse, nil
+	}
+	if stop, ok := err.(*stopRetryError); ok {
+		return lastResponse, stop.err
 	}
 
 	if lastError == nil {
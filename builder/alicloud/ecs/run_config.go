@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -114,7 +115,8 @@ type RunConfig struct {
 	// timeout value.
 	WaitSnapshotReadyTimeout int `mapstructure:"wait_snapshot_ready_timeout" required:"false"`
 	// Communicator settings
-	Comm communicator.Config `mapstructure:",squash"`
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
 	// If this value is true, packer will connect to
 	// the ECS created through private ip instead of allocating a public ip or an
 	// EIP. The default value is false.
@@ -130,6 +132,7 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) []error {
 
 	// Validation
 	errs := c.Comm.Prepare(ctx)
+	errs = append(errs, c.Guest.Prepare(&c.Comm)...)
 	if c.AlicloudSourceImage == "" {
 		errs = append(errs, errors.New("A source_image must be specified"))
 	}
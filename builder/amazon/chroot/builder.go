@@ -199,6 +199,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 		InterpolateFilter: &interpolate.RenderFilter{
 			Exclude: []string{
 				"ami_description",
+				"name_pattern",
 				"snapshot_tags",
 				"tags",
 				"root_volume_tags",
@@ -339,6 +340,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return generatedData, warns, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"temporary EBS volume and snapshot", "AMI and EBS snapshots"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	if runtime.GOOS != "linux" {
 		return nil, errors.New("The amazon-chroot builder only works on Linux environments.")
@@ -350,6 +355,12 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	}
 	ec2conn := ec2.New(session)
 
+	// Suffix the AMI name as set with name_collision when it's taken, before
+	// the steps use it.
+	if err := b.config.AMIConfig.UniqueAMIName(ec2conn, ui); err != nil {
+		return nil, err
+	}
+
 	wrappedCommand := func(command string) (string, error) {
 		ictx := b.config.ctx
 		ictx.Data = &wrappedCommandTemplate{Command: command}
@@ -373,6 +384,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		&awscommon.StepPreValidate{
 			DestAmiName:     b.config.AMIName,
 			ForceDeregister: b.config.AMIForceDeregister,
+			OnConflict:      b.config.OnConflict(),
 		},
 		&StepInstanceInfo{},
 	}
@@ -466,6 +478,11 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	b.runner = common.NewRunner(steps, b.config.PackerConfig, ui)
 	b.runner.Run(ctx, state)
 
+	// The artifact already exists and the build was skipped.
+	if common.Skipped(state) {
+		return nil, nil
+	}
+
 	// If there was an error, return that
 	if rawErr, ok := state.GetOk("error"); ok {
 		return nil, rawErr.(error)
@@ -108,6 +108,7 @@ func (s *StepCreateVolume) Run(ctx context.Context, state multistep.StateBag) mu
 	// Set the volume ID so we remember to delete it later
 	s.volumeId = *createVolumeResp.VolumeId
 	log.Printf("Volume ID: %s", s.volumeId)
+	awscommon.TrackResource(ec2conn, awscommon.CleanupVolume, s.volumeId)
 
 	// Wait for the volume to become ready
 	err = awscommon.WaitUntilVolumeAvailable(ctx, ec2conn, s.volumeId)
@@ -134,7 +135,9 @@ func (s *StepCreateVolume) Cleanup(state multistep.StateBag) {
 	_, err := ec2conn.DeleteVolume(&ec2.DeleteVolumeInput{VolumeId: &s.volumeId})
 	if err != nil {
 		ui.Error(fmt.Sprintf("Error deleting EBS volume: %s", err))
+		return
 	}
+	awscommon.UntrackResource(awscommon.CleanupVolume, s.volumeId)
 }
 
 func (s *StepCreateVolume) buildCreateVolumeInput(az string, rootDevice *ec2.BlockDeviceMapping) (*ec2.CreateVolumeInput, error) {
@@ -15,7 +15,12 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/sts"
 	cleanhttp "github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/packer/common/credentialhelper"
+	"github.com/hashicorp/packer/common/oidc"
+	"github.com/hashicorp/packer/common/ratelimit"
+	commonhelper "github.com/hashicorp/packer/helper/common"
 	"github.com/hashicorp/packer/template/interpolate"
 	vaultapi "github.com/hashicorp/vault/api"
 )
@@ -48,6 +53,12 @@ type AccessConfig struct {
 	// is not required if you are using `use_vault_aws_engine` for
 	// authentication instead.
 	AccessKey string `mapstructure:"access_key" required:"true"`
+	// The command, and its arguments, of a [credential
+	// helper](/docs/builders/amazon#credential-helper) returning the
+	// credentials Packer authenticates with. The helper is run again when the
+	// credentials expire during the build. Can't be used with `access_key`,
+	// `secret_key` or `vault_aws_engine`.
+	CredentialHelper []string `mapstructure:"credential_helper" required:"false"`
 	// This option is useful if you use a cloud
 	// provider whose API is compatible with aws EC2. Specify another endpoint
 	// like this https://ec2.custom.endpoint.com.
@@ -64,6 +75,9 @@ type AccessConfig struct {
 	// where requests are being throttled or experiencing transient failures.
 	// The delay between the subsequent API calls increases exponentially.
 	MaxRetries int `mapstructure:"max_retries" required:"false"`
+	// The rate limit, retries and circuit breaker of the AWS API requests,
+	// applied on top of the retries of `max_retries`.
+	RateLimitConfig ratelimit.Config `mapstructure:",squash"`
 	// The MFA
 	// [TOTP](https://en.wikipedia.org/wiki/Time-based_One-time_Password_Algorithm)
 	// code. This should probably be a user variable since it changes all the
@@ -90,7 +104,18 @@ type AccessConfig struct {
 	// access key and secret key. If you're not sure what this is, then you
 	// probably don't need it. This will also be read from the AWS_SESSION_TOKEN
 	// environmental variable.
-	Token   string `mapstructure:"token" required:"false"`
+	Token string `mapstructure:"token" required:"false"`
+	// The ARN of the role to assume with the OIDC token of the CI job Packer
+	// runs in, like a GitHub Actions or a GitLab CI job. See
+	// [OIDC](/docs/builders/amazon#oidc). The role is assumed again when its
+	// credentials expire during the build. Can't be used with `access_key`,
+	// `secret_key`, `credential_helper` or `vault_aws_engine`.
+	WebIdentityRoleARN string `mapstructure:"web_identity_role_arn" required:"false"`
+	// The name of the session of the role assumed with the OIDC token.
+	// Defaults to `packer`.
+	WebIdentitySessionName string `mapstructure:"web_identity_session_name" required:"false"`
+	// The OIDC token the role is assumed with.
+	OIDC    oidc.Config `mapstructure:",squash"`
 	session *session.Session
 	// Get credentials from Hashicorp Vault's aws secrets engine. You must
 	// already have created a role to use. For more information about
@@ -154,9 +179,27 @@ func (c *AccessConfig) Session() (*session.Session, error) {
 		config = config.WithMaxRetries(c.MaxRetries)
 	}
 
-	staticCreds := credentials.NewStaticCredentials(c.AccessKey, c.SecretKey, c.Token)
-	if _, err := staticCreds.Get(); err != credentials.ErrStaticCredentialsEmpty {
-		config.WithCredentials(staticCreds)
+	var webIdentity *webIdentityProvider
+	if c.WebIdentityRoleARN != "" {
+		tokens, err := c.OIDC.TokenSource(defaultWebIdentityAudience)
+		if err != nil {
+			return nil, err
+		}
+		webIdentity = &webIdentityProvider{
+			roleARN:     c.WebIdentityRoleARN,
+			sessionName: c.WebIdentitySessionName,
+			tokens:      tokens,
+		}
+		config.WithCredentials(credentials.NewCredentials(webIdentity))
+	} else if len(c.CredentialHelper) > 0 {
+		config.WithCredentials(credentials.NewCredentials(&credentialHelperProvider{
+			helper: credentialhelper.New(c.CredentialHelper, credentialhelper.ProviderAWS),
+		}))
+	} else {
+		staticCreds := credentials.NewStaticCredentials(c.AccessKey, c.SecretKey, c.Token)
+		if _, err := staticCreds.Get(); err != credentials.ErrStaticCredentialsEmpty {
+			config.WithCredentials(staticCreds)
+		}
 	}
 
 	if c.RawRegion != "" {
@@ -169,12 +212,15 @@ func (c *AccessConfig) Session() (*session.Session, error) {
 
 	config = config.WithHTTPClient(cleanhttp.DefaultClient())
 	transport := config.HTTPClient.Transport.(*http.Transport)
+	if err := commonhelper.ConfigureTransport(transport); err != nil {
+		return nil, err
+	}
 	if c.InsecureSkipTLSVerify {
-		transport.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: true,
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
 		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
 	}
-	transport.Proxy = http.ProxyFromEnvironment
 
 	opts := session.Options{
 		SharedConfigState: session.SharedConfigEnable,
@@ -195,9 +241,19 @@ func (c *AccessConfig) Session() (*session.Session, error) {
 	if err != nil {
 		return nil, err
 	}
+	// The session loads the CA bundle of AWS_CA_BUNDLE in the *http.Transport
+	// of the client, so the transport is wrapped afterwards.
+	sess.Config.HTTPClient.Transport = c.RateLimitConfig.Transport("amazon", sess.Config.HTTPClient.Transport)
 	log.Printf("Found region %s", *sess.Config.Region)
 	c.session = sess
 
+	if webIdentity != nil {
+		// Roles are assumed with web identities without credentials.
+		webIdentity.client = sts.New(sess.Copy(&aws.Config{
+			Credentials: credentials.AnonymousCredentials,
+		}))
+	}
+
 	cp, err := c.session.Config.Credentials.Get()
 
 	if IsAWSErr(err, "NoCredentialProviders", "") {
@@ -239,6 +295,9 @@ func (c *AccessConfig) GetCredsFromVault() error {
 	// const EnvVaultAddress = "VAULT_ADDR"
 	// const EnvVaultToken = "VAULT_TOKEN"
 	vaultConfig := vaultapi.DefaultConfig()
+	if err := commonhelper.ConfigureClient(vaultConfig.HttpClient); err != nil {
+		return err
+	}
 	cli, err := vaultapi.NewClient(vaultConfig)
 	if err != nil {
 		return fmt.Errorf("Error getting Vault client: %s", err)
@@ -291,11 +350,34 @@ func (c *AccessConfig) Prepare(ctx *interpolate.Context) []error {
 		}
 	}
 
+	if c.WebIdentityRoleARN != "" {
+		if len(c.AccessKey) > 0 || len(c.SecretKey) > 0 ||
+			len(c.CredentialHelper) > 0 || !c.VaultAWSEngine.Empty() {
+			errs = append(errs,
+				fmt.Errorf("If you have set web_identity_role_arn, you must not set"+
+					" the access_key, secret_key, credential_helper or vault_aws_engine."))
+		}
+		if c.WebIdentitySessionName == "" {
+			c.WebIdentitySessionName = "packer"
+		}
+		errs = append(errs, c.OIDC.Prepare()...)
+	}
+
+	if len(c.CredentialHelper) > 0 {
+		if len(c.AccessKey) > 0 || len(c.SecretKey) > 0 || !c.VaultAWSEngine.Empty() {
+			errs = append(errs,
+				fmt.Errorf("If you have set credential_helper, you must not set"+
+					" the access_key, secret_key or vault_aws_engine."))
+		}
+	}
+
 	if (len(c.AccessKey) > 0) != (len(c.SecretKey) > 0) {
 		errs = append(errs,
 			fmt.Errorf("`access_key` and `secret_key` must both be either set or not set."))
 	}
 
+	errs = append(errs, c.RateLimitConfig.Prepare()...)
+
 	return errs
 }
 
@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/hashicorp/packer/common/ratelimit"
 )
 
 func testAccessConfig() *AccessConfig {
@@ -68,3 +69,57 @@ func TestAccessConfigPrepare_RegionRestricted(t *testing.T) {
 		t.Fatal("We should be in gov region.")
 	}
 }
+
+func TestAccessConfigPrepare_CredentialHelper(t *testing.T) {
+	c := testAccessConfig()
+	c.CredentialHelper = []string{"aws-credentials", "--profile", "packer"}
+	if err := c.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %s", err)
+	}
+
+	c.AccessKey = "AKID"
+	c.SecretKey = "secret"
+	if err := c.Prepare(nil); err == nil {
+		t.Fatal("should have error with access_key and secret_key")
+	}
+}
+
+func TestAccessConfigPrepare_WebIdentity(t *testing.T) {
+	c := testAccessConfig()
+	c.WebIdentityRoleARN = "arn:aws:iam::123456789012:role/packer"
+	if err := c.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %s", err)
+	}
+	if c.WebIdentitySessionName != "packer" {
+		t.Fatalf("bad session name: %q", c.WebIdentitySessionName)
+	}
+
+	c.CredentialHelper = []string{"aws-credentials"}
+	if err := c.Prepare(nil); err == nil {
+		t.Fatal("should have error with credential_helper")
+	}
+}
+
+func TestAccessConfigPrepare_RateLimit(t *testing.T) {
+	c := testAccessConfig()
+	c.RateLimitConfig.APIRateLimit = -1
+	if err := c.Prepare(nil); err == nil {
+		t.Fatal("should have error with a negative api_rate_limit")
+	}
+
+	c = testAccessConfig()
+	c.RawRegion = "us-east-1"
+	c.AccessKey = "AKID"
+	c.SecretKey = "secret"
+	c.RateLimitConfig.APIRateLimit = 10
+	if err := c.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %s", err)
+	}
+	sess, err := c.Session()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := sess.Config.HTTPClient.Transport.(*ratelimit.Transport); !ok {
+		t.Fatalf("API requests not rate limited: %T", sess.Config.HTTPClient.Transport)
+	}
+}
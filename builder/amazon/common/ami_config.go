@@ -7,8 +7,13 @@ import (
 	"log"
 	"regexp"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/hashicorp/packer/common/naming"
 	"github.com/hashicorp/packer/hcl2template"
 	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
@@ -137,6 +142,8 @@ type AMIConfig struct {
 	// to create volumes from the snapshot(s). all will make the snapshot
 	// publicly accessible.
 	SnapshotGroups []string `mapstructure:"snapshot_groups" required:"false"`
+	// The naming policy of the AMI, applied to `ami_name`.
+	NamingConfig naming.Config `mapstructure:",squash"`
 }
 
 func stringInSlice(s []string, searchstr string) bool {
@@ -158,6 +165,15 @@ func (c *AMIConfig) Prepare(accessConfig *AccessConfig, ctx *interpolate.Context
 		errs = append(errs, fmt.Errorf("ami_name must be specified"))
 	}
 
+	errs = append(errs, c.NamingConfig.Prepare()...)
+	if c.AMIName != "" {
+		name, err := c.NamingConfig.Name(ctx, c.AMIName, naming.Amazon)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		c.AMIName = name
+	}
+
 	// Make sure that if we have region_kms_key_ids defined,
 	//  the regions in region_kms_key_ids are also in ami_regions
 	if len(c.AMIRegionKMSKeyIDs) > 0 {
@@ -240,6 +256,34 @@ func (c *AMIConfig) Prepare(accessConfig *AccessConfig, ctx *interpolate.Context
 	return nil
 }
 
+// UniqueAMIName names the AMI after ami_name with the suffix of
+// name_collision when an AMI with its name exists in the build region.
+func (c *AMIConfig) UniqueAMIName(conn ec2iface.EC2API, ui packer.Ui) error {
+	if c.AMISkipBuildRegion {
+		return nil
+	}
+	name, err := c.NamingConfig.Unique(c.AMIName, naming.Amazon, func(name string) (bool, error) {
+		resp, err := conn.DescribeImages(&ec2.DescribeImagesInput{
+			Filters: []*ec2.Filter{{
+				Name:   aws.String("name"),
+				Values: aws.StringSlice([]string{name}),
+			}},
+		})
+		if err != nil {
+			return false, fmt.Errorf("Error querying AMI: %s", err)
+		}
+		return len(resp.Images) > 0, nil
+	})
+	if err != nil {
+		return err
+	}
+	if name != c.AMIName {
+		ui.Say(fmt.Sprintf("AMI %s already exists, naming the AMI %s", c.AMIName, name))
+		c.AMIName = name
+	}
+	return nil
+}
+
 func (c *AMIConfig) prepareRegions(accessConfig *AccessConfig) (errs []error) {
 	if len(c.AMIRegions) > 0 {
 		regionSet := make(map[string]struct{})
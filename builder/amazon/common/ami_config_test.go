@@ -9,6 +9,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
 )
 
 func testAMIConfig() *AMIConfig {
@@ -247,3 +249,59 @@ func TestAMINameValidation(t *testing.T) {
 	}
 
 }
+
+func TestAMIConfigPrepare_naming(t *testing.T) {
+	c := testAMIConfig()
+	c.AMIName = "Base: Ubuntu"
+	c.NamingConfig.NamePattern = "{{ .Name }} ({{ .BuildName }})"
+	c.NamingConfig.NameClean = true
+	ctx := &interpolate.Context{BuildName: "web*"}
+	if err := c.Prepare(testAccessConfig(), ctx); err != nil {
+		t.Fatalf("shouldn't have err: %s", err)
+	}
+	if c.AMIName != "Base- Ubuntu (web-)" {
+		t.Fatalf("bad AMI name: %q", c.AMIName)
+	}
+
+	c = testAMIConfig()
+	c.NamingConfig.NameCollision = "overwrite"
+	if err := c.Prepare(testAccessConfig(), nil); err == nil {
+		t.Fatal("an unknown name_collision should be rejected")
+	}
+}
+
+type mockImagesEC2Client struct {
+	ec2iface.EC2API
+	names map[string]bool
+}
+
+func (m *mockImagesEC2Client) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	output := &ec2.DescribeImagesOutput{}
+	if name := aws.StringValue(input.Filters[0].Values[0]); m.names[name] {
+		output.Images = []*ec2.Image{{Name: aws.String(name), ImageId: aws.String("ami-12345")}}
+	}
+	return output, nil
+}
+
+func TestAMIConfig_UniqueAMIName(t *testing.T) {
+	conn := &mockImagesEC2Client{names: map[string]bool{"foo": true, "foo-2": true}}
+
+	c := testAMIConfig()
+	c.NamingConfig.NameCollision = "counter"
+	if err := c.UniqueAMIName(conn, packer.TestUi(t)); err != nil {
+		t.Fatal(err)
+	}
+	if c.AMIName != "foo-3" {
+		t.Fatalf("bad AMI name: %q", c.AMIName)
+	}
+
+	c = testAMIConfig()
+	c.NamingConfig.NameCollision = "counter"
+	c.AMISkipBuildRegion = true
+	if err := c.UniqueAMIName(conn, packer.TestUi(t)); err != nil {
+		t.Fatal(err)
+	}
+	if c.AMIName != "foo" {
+		t.Fatalf("no AMI is registered in the build region, got %q", c.AMIName)
+	}
+}
@@ -0,0 +1,114 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/packer/common/cleanup"
+)
+
+// CleanupProvider is the provider name of the resources the amazon builders
+// track in the cleanup registry.
+const CleanupProvider = "amazon"
+
+// Types of the resources the amazon builders track in the cleanup registry.
+const (
+	CleanupInstance       = "instance"
+	CleanupKeyPair        = "keypair"
+	CleanupSecurityGroup  = "security-group"
+	CleanupLaunchTemplate = "launch-template"
+	CleanupVolume         = "volume"
+)
+
+// TrackResource tracks a resource created by an amazon builder in the
+// cleanup registry.
+func TrackResource(ec2conn *ec2.EC2, typ, id string) {
+	cleanup.Track(cleanup.Resource{
+		Provider: CleanupProvider,
+		Type:     typ,
+		ID:       id,
+		Region:   aws.StringValue(ec2conn.Config.Region),
+	})
+}
+
+// adoptResource tracks a resource created by the build this build resumes.
+func adoptResource(ec2conn *ec2.EC2, typ, id string) {
+	cleanup.Adopt(cleanup.Resource{
+		Provider: CleanupProvider,
+		Type:     typ,
+		ID:       id,
+		Region:   aws.StringValue(ec2conn.Config.Region),
+	})
+}
+
+// UntrackResource records that a resource tracked with TrackResource was
+// removed.
+func UntrackResource(typ, id string) {
+	cleanup.Untrack(CleanupProvider, typ, id)
+}
+
+// SweepResource removes a resource left behind by an amazon builder. It uses
+// the default credentials of the environment.
+func SweepResource(ctx context.Context, r cleanup.Resource) error {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            aws.Config{Region: aws.String(r.Region)},
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return err
+	}
+	ec2conn := ec2.New(sess)
+
+	switch r.Type {
+	case CleanupInstance:
+		_, err = ec2conn.TerminateInstancesWithContext(ctx, &ec2.TerminateInstancesInput{
+			InstanceIds: []*string{aws.String(r.ID)},
+		})
+		if err == nil {
+			err = WaitUntilInstanceTerminated(ctx, ec2conn, r.ID)
+		}
+	case CleanupKeyPair:
+		_, err = ec2conn.DeleteKeyPairWithContext(ctx, &ec2.DeleteKeyPairInput{
+			KeyName: aws.String(r.ID),
+		})
+	case CleanupSecurityGroup:
+		_, err = ec2conn.DeleteSecurityGroupWithContext(ctx, &ec2.DeleteSecurityGroupInput{
+			GroupId: aws.String(r.ID),
+		})
+	case CleanupLaunchTemplate:
+		_, err = ec2conn.DeleteLaunchTemplateWithContext(ctx, &ec2.DeleteLaunchTemplateInput{
+			LaunchTemplateName: aws.String(r.ID),
+		})
+	case CleanupVolume:
+		// A volume still attached, like the one of a chroot build, fails
+		// to delete and is kept for a later run.
+		_, err = ec2conn.DeleteVolumeWithContext(ctx, &ec2.DeleteVolumeInput{
+			VolumeId: aws.String(r.ID),
+		})
+	default:
+		return fmt.Errorf("unknown resource type %q", r.Type)
+	}
+
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func isNotFound(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		switch awsErr.Code() {
+		case "InvalidInstanceID.NotFound",
+			"InvalidKeyPair.NotFound",
+			"InvalidGroup.NotFound",
+			"InvalidLaunchTemplateName.NotFoundException",
+			"InvalidVolume.NotFound":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"context"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/packer/common/cost"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// Prices are the default prices of the amazon builders, in USD: the
+// on-demand prices of Linux instances and the prices of EBS volumes in
+// us-east-1, and the price of the data copied to other regions.
+var Prices = cost.Prices{
+	"instance/t2.micro":   0.0116,
+	"instance/t2.small":   0.023,
+	"instance/t2.medium":  0.0464,
+	"instance/t2.large":   0.0928,
+	"instance/t3.micro":   0.0104,
+	"instance/t3.small":   0.0208,
+	"instance/t3.medium":  0.0416,
+	"instance/t3.large":   0.0832,
+	"instance/t3.xlarge":  0.1664,
+	"instance/t3a.micro":  0.0094,
+	"instance/t3a.small":  0.0188,
+	"instance/t3a.medium": 0.0376,
+	"instance/t3a.large":  0.0752,
+	"instance/t4g.micro":  0.0084,
+	"instance/t4g.small":  0.0168,
+	"instance/t4g.medium": 0.0336,
+	"instance/t4g.large":  0.0672,
+	"instance/m5.large":   0.096,
+	"instance/m5.xlarge":  0.192,
+	"instance/m5.2xlarge": 0.384,
+	"instance/m6g.large":  0.077,
+	"instance/c5.large":   0.085,
+	"instance/c5.xlarge":  0.17,
+	"instance/c5.2xlarge": 0.34,
+	"instance/c6g.large":  0.068,
+	"instance/r5.large":   0.126,
+	"storage/gp2":         0.10,
+	"storage/gp3":         0.08,
+	"storage/io1":         0.125,
+	"storage/io2":         0.125,
+	"storage/st1":         0.045,
+	"storage/sc1":         0.015,
+	"storage/standard":    0.05,
+	"egress/inter-region": 0.02,
+}
+
+// meterInstance starts metering the source instance of the build and its
+// EBS volumes.
+func meterInstance(ctx context.Context, state multistep.StateBag, ec2conn *ec2.EC2, instance *ec2.Instance) {
+	m := cost.FromState(state)
+	if m == nil {
+		return
+	}
+	m.Start(cost.Instance, aws.StringValue(instance.InstanceId), aws.StringValue(instance.InstanceType), 0)
+
+	var volumeIds []*string
+	for _, mapping := range instance.BlockDeviceMappings {
+		if mapping.Ebs != nil {
+			volumeIds = append(volumeIds, mapping.Ebs.VolumeId)
+		}
+	}
+	if len(volumeIds) == 0 {
+		return
+	}
+	resp, err := ec2conn.DescribeVolumesWithContext(ctx, &ec2.DescribeVolumesInput{VolumeIds: volumeIds})
+	if err != nil {
+		log.Printf("[WARN] Could not describe the volumes of %s to estimate their cost: %s",
+			aws.StringValue(instance.InstanceId), err)
+		return
+	}
+	for _, v := range resp.Volumes {
+		m.Start(cost.Storage, aws.StringValue(v.VolumeId), aws.StringValue(v.VolumeType), float64(aws.Int64Value(v.Size)))
+	}
+}
+
+// unmeterInstance stops metering the terminated source instance of the
+// build and its volumes.
+func unmeterInstance(state multistep.StateBag, instanceId string) {
+	m := cost.FromState(state)
+	if m == nil {
+		return
+	}
+	m.Stop(cost.Instance, instanceId)
+	if instance, ok := state.Get("instance").(*ec2.Instance); ok {
+		for _, mapping := range instance.BlockDeviceMappings {
+			if mapping.Ebs != nil {
+				m.Stop(cost.Storage, aws.StringValue(mapping.Ebs.VolumeId))
+			}
+		}
+	}
+}
+
+// meterImageCopy meters the copy of the image to another region, from the
+// size of its volumes.
+func meterImageCopy(state multistep.StateBag, image *ec2.Image) {
+	var size int64
+	for _, mapping := range image.BlockDeviceMappings {
+		if mapping.Ebs != nil {
+			size += aws.Int64Value(mapping.Ebs.VolumeSize)
+		}
+	}
+	cost.FromState(state).Add(cost.Egress, aws.StringValue(image.ImageId), "inter-region", float64(size))
+}
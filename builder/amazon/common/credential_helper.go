@@ -0,0 +1,35 @@
+package common
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/hashicorp/packer/common/credentialhelper"
+)
+
+// credentialHelperProviderName is the name of the provider of the
+// credentials returned by a credential helper.
+const credentialHelperProviderName = "CredentialHelperProvider"
+
+// credentialHelperProvider provides the credentials returned by a credential
+// helper, and provides new credentials when they are about to expire.
+type credentialHelperProvider struct {
+	helper *credentialhelper.Helper
+}
+
+func (p *credentialHelperProvider) Retrieve() (credentials.Value, error) {
+	c, err := p.helper.Get(context.TODO())
+	if err != nil {
+		return credentials.Value{ProviderName: credentialHelperProviderName}, err
+	}
+	return credentials.Value{
+		AccessKeyID:     c.AccessKey,
+		SecretAccessKey: c.SecretKey,
+		SessionToken:    c.Token,
+		ProviderName:    credentialHelperProviderName,
+	}, nil
+}
+
+func (p *credentialHelperProvider) IsExpired() bool {
+	return p.helper.Expired()
+}
@@ -0,0 +1,181 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hashicorp/packer/common/preflight"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// PreflightActions returns the IAM actions launching and connecting to the
+// source instance of the build require.
+func (c *RunConfig) PreflightActions() []string {
+	actions := []string{
+		"ec2:CreateTags",
+		"ec2:DescribeImages",
+		"ec2:DescribeInstances",
+		"ec2:DescribeRegions",
+		"ec2:DescribeSecurityGroups",
+		"ec2:DescribeSubnets",
+		"ec2:DescribeVolumes",
+		"ec2:DescribeVpcs",
+		"ec2:RunInstances",
+		"ec2:TerminateInstances",
+	}
+	if c.IsSpotInstance() {
+		actions = append(actions,
+			"ec2:CreateFleet",
+			"ec2:CreateLaunchTemplate",
+			"ec2:DeleteLaunchTemplate",
+		)
+	}
+	if !c.InstanceRequirements.Empty() {
+		actions = append(actions, "ec2:DescribeInstanceTypes")
+	}
+	if c.Comm.SSHKeyPairName == "" && c.Comm.SSHPrivateKeyFile == "" && !c.Comm.SSHAgentAuth {
+		actions = append(actions, "ec2:CreateKeyPair", "ec2:ImportKeyPair", "ec2:DeleteKeyPair")
+	}
+	if len(c.SecurityGroupIds) == 0 && c.SecurityGroupFilter.Empty() {
+		actions = append(actions,
+			"ec2:AuthorizeSecurityGroupIngress",
+			"ec2:CreateSecurityGroup",
+			"ec2:DeleteSecurityGroup",
+		)
+	}
+	if c.IamInstanceProfile != "" {
+		actions = append(actions, "iam:GetInstanceProfile", "iam:PassRole")
+	}
+	if c.TemporaryIamInstanceProfilePolicyDocument != nil {
+		actions = append(actions,
+			"iam:AddRoleToInstanceProfile",
+			"iam:CreateInstanceProfile",
+			"iam:CreateRole",
+			"iam:DeleteInstanceProfile",
+			"iam:DeleteRole",
+			"iam:DeleteRolePolicy",
+			"iam:GetInstanceProfile",
+			"iam:GetRole",
+			"iam:PassRole",
+			"iam:PutRolePolicy",
+			"iam:RemoveRoleFromInstanceProfile",
+		)
+	}
+	if c.SSMAgentEnabled() {
+		actions = append(actions, "ssm:StartSession", "ssm:TerminateSession")
+	}
+	if c.Comm.Type == "winrm" && c.Comm.WinRMPassword == "" {
+		actions = append(actions, "ec2:GetPasswordData")
+	}
+	return actions
+}
+
+// PreflightActions returns the IAM actions creating the AMIs of the build
+// require, on top of creating or registering the image itself.
+func (c *AMIConfig) PreflightActions() []string {
+	actions := []string{"ec2:CreateTags", "ec2:DescribeImages"}
+	if len(c.AMIRegions) > 0 {
+		actions = append(actions, "ec2:CopyImage")
+	}
+	if c.AMIDescription != "" || len(c.AMIUsers) > 0 || len(c.AMIGroups) > 0 || len(c.AMIProductCodes) > 0 {
+		actions = append(actions, "ec2:ModifyImageAttribute")
+	}
+	if len(c.SnapshotUsers) > 0 || len(c.SnapshotGroups) > 0 {
+		actions = append(actions, "ec2:ModifySnapshotAttribute")
+	}
+	if c.AMIForceDeregister || c.AMISkipBuildRegion {
+		actions = append(actions, "ec2:DeregisterImage")
+	}
+	if c.AMIForceDeleteSnapshot || c.AMISkipBuildRegion {
+		actions = append(actions, "ec2:DeleteSnapshot", "ec2:DescribeSnapshots")
+	}
+	return actions
+}
+
+// NewPreflightChecker returns the preflight.Checker simulating the IAM
+// policies of the principal of the credentials of session with the actions
+// of the build.
+func NewPreflightChecker(session *session.Session) preflight.Checker {
+	return func(ctx context.Context, _ multistep.StateBag, actions []string) (string, []string, error) {
+		identity, err := sts.New(session).GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			return "", nil, err
+		}
+		principal := aws.StringValue(identity.Arn)
+
+		iamconn := iam.New(session)
+		source, err := policySourceArn(principal)
+		if err != nil {
+			return principal, nil, err
+		}
+		if source == "" {
+			// The root user can perform all the operations.
+			return principal, nil, nil
+		}
+		if role := assumedRoleName(principal); role != "" {
+			// The ARN of the assumed role lacks the path of the role.
+			resp, err := iamconn.GetRoleWithContext(ctx, &iam.GetRoleInput{RoleName: aws.String(role)})
+			if err == nil {
+				source = aws.StringValue(resp.Role.Arn)
+			}
+		}
+
+		var missing []string
+		err = iamconn.SimulatePrincipalPolicyPagesWithContext(ctx, &iam.SimulatePrincipalPolicyInput{
+			PolicySourceArn: aws.String(source),
+			ActionNames:     aws.StringSlice(actions),
+		}, func(page *iam.SimulatePolicyResponse, _ bool) bool {
+			for _, result := range page.EvaluationResults {
+				if aws.StringValue(result.EvalDecision) != iam.PolicyEvaluationDecisionTypeAllowed {
+					missing = append(missing, aws.StringValue(result.EvalActionName))
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return principal, nil, fmt.Errorf("Error simulating the policies of %s: %s", source, err)
+		}
+		return principal, missing, nil
+	}
+}
+
+// policySourceArn returns the ARN of the IAM user or role whose policies
+// apply to principal, the ARN of the caller identity, or an empty string for
+// the root user.
+func policySourceArn(principal string) (string, error) {
+	// arn:partition:service::account:resource
+	parts := strings.SplitN(principal, ":", 6)
+	if len(parts) != 6 {
+		return "", fmt.Errorf("unexpected caller identity %q", principal)
+	}
+	switch {
+	case parts[2] == "iam" && parts[5] == "root":
+		return "", nil
+	case parts[2] == "iam":
+		return principal, nil
+	case parts[2] == "sts" && strings.HasPrefix(parts[5], "assumed-role/"):
+		role := assumedRoleName(principal)
+		return fmt.Sprintf("arn:%s:iam::%s:role/%s", parts[1], parts[4], role), nil
+	default:
+		return "", fmt.Errorf("the policies of %s can't be simulated", principal)
+	}
+}
+
+// assumedRoleName returns the name of the role of an assumed role ARN, like
+// arn:aws:sts::123456789012:assumed-role/name/session, or an empty string.
+func assumedRoleName(principal string) string {
+	i := strings.Index(principal, ":assumed-role/")
+	if i < 0 {
+		return ""
+	}
+	name := principal[i+len(":assumed-role/"):]
+	if j := strings.Index(name, "/"); j >= 0 {
+		name = name[:j]
+	}
+	return name
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolicySourceArn(t *testing.T) {
+	cases := []struct {
+		principal string
+		source    string
+		err       bool
+	}{
+		{"arn:aws:iam::123456789012:user/packer", "arn:aws:iam::123456789012:user/packer", false},
+		{"arn:aws:iam::123456789012:root", "", false},
+		{"arn:aws:sts::123456789012:assumed-role/packer/session", "arn:aws:iam::123456789012:role/packer", false},
+		{"arn:aws-cn:sts::123456789012:assumed-role/packer/i-0123", "arn:aws-cn:iam::123456789012:role/packer", false},
+		{"arn:aws:sts::123456789012:federated-user/packer", "", true},
+		{"packer", "", true},
+	}
+	for _, tc := range cases {
+		source, err := policySourceArn(tc.principal)
+		if (err != nil) != tc.err {
+			t.Fatalf("%s: unexpected error %v", tc.principal, err)
+		}
+		if source != tc.source {
+			t.Fatalf("%s: unexpected source %q, expected %q", tc.principal, source, tc.source)
+		}
+	}
+}
+
+func TestRunConfig_PreflightActions(t *testing.T) {
+	c := testConfig()
+	c.SecurityGroupIds = []string{"sg-1"}
+	c.Comm.SSHKeyPairName = "packer"
+	c.Comm.SSHPrivateKeyFile = "packer.pem"
+	expected := []string{
+		"ec2:CreateTags",
+		"ec2:DescribeImages",
+		"ec2:DescribeInstances",
+		"ec2:DescribeRegions",
+		"ec2:DescribeSecurityGroups",
+		"ec2:DescribeSubnets",
+		"ec2:DescribeVolumes",
+		"ec2:DescribeVpcs",
+		"ec2:RunInstances",
+		"ec2:TerminateInstances",
+	}
+	if actions := c.PreflightActions(); !reflect.DeepEqual(actions, expected) {
+		t.Fatalf("unexpected actions %v", actions)
+	}
+
+	c.SpotPrice = "auto"
+	c.IamInstanceProfile = "packer"
+	c.SecurityGroupIds = nil
+	actions := c.PreflightActions()
+	for _, a := range []string{"ec2:CreateFleet", "iam:PassRole", "ec2:CreateSecurityGroup"} {
+		if !contains(actions, a) {
+			t.Fatalf("%s should be required: %v", a, actions)
+		}
+	}
+}
+
+func contains(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// The keys of what the amazon steps save in the journal of the build, to
+// resume it after the packer process died.
+const (
+	journalKeyPairName    = "amazon_key_pair_name"
+	journalPrivateKey     = "amazon_ssh_private_key"
+	journalSecurityGroup  = "amazon_security_group_id"
+	journalLaunchTemplate = "amazon_launch_template_name"
+	journalInstanceID     = "amazon_instance_id"
+)
+
+// resumeInstance waits for the source instance of a resumed build to run,
+// and returns it.
+func resumeInstance(ctx context.Context, ec2conn *ec2.EC2, instanceId string) (*ec2.Instance, error) {
+	r, err := ec2conn.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: []*string{aws.String(instanceId)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error finding source instance %s: %s", instanceId, err)
+	}
+	if len(r.Reservations) == 0 || len(r.Reservations[0].Instances) == 0 {
+		return nil, fmt.Errorf("Source instance %s is gone", instanceId)
+	}
+	instance := r.Reservations[0].Instances[0]
+
+	switch name := aws.StringValue(instance.State.Name); name {
+	case ec2.InstanceStateNameRunning:
+		return instance, nil
+	case ec2.InstanceStateNamePending:
+		if err := WaitUntilInstanceRunning(ctx, ec2conn, instanceId); err != nil {
+			return nil, fmt.Errorf("Error waiting for instance (%s) to become ready: %s", instanceId, err)
+		}
+		return resumeInstance(ctx, ec2conn, instanceId)
+	default:
+		return nil, fmt.Errorf("Source instance %s is %s, the build can't be resumed", instanceId, name)
+	}
+}
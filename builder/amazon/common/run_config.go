@@ -1,5 +1,5 @@
 //go:generate struct-markdown
-//go:generate mapstructure-to-hcl2 -type AmiFilterOptions,SecurityGroupFilterOptions,SubnetFilterOptions,VpcFilterOptions,PolicyDocument,Statement
+//go:generate mapstructure-to-hcl2 -type AmiFilterOptions,SecurityGroupFilterOptions,SubnetFilterOptions,VpcFilterOptions,PolicyDocument,Statement,MetadataOptions,EnclaveOptions,InstanceRequirements
 
 package common
 
@@ -7,10 +7,14 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/hcl2template"
 	"github.com/hashicorp/packer/helper/communicator"
@@ -67,6 +71,137 @@ type SecurityGroupFilterOptions struct {
 	hcl2template.NameValueFilter `mapstructure:",squash"`
 }
 
+// Configures the instance metadata service (IMDS) of the build instance.
+// Requiring session tokens with `http_tokens` only allows IMDSv2.
+type MetadataOptions struct {
+	// Whether the metadata service is available: `enabled` or `disabled`.
+	// Defaults to `enabled`.
+	HttpEndpoint string `mapstructure:"http_endpoint" required:"false"`
+	// Whether the metadata requests require a session token: `optional`,
+	// allowing IMDSv1, or `required`, only allowing IMDSv2. Defaults to
+	// `optional`.
+	HttpTokens string `mapstructure:"http_tokens" required:"false"`
+	// The hop limit of the PUT response requesting a session token, from 1
+	// to 64. Defaults to 1.
+	HttpPutResponseHopLimit int64 `mapstructure:"http_put_response_hop_limit" required:"false"`
+}
+
+func (o *MetadataOptions) Empty() bool {
+	return o.HttpEndpoint == "" && o.HttpTokens == "" && o.HttpPutResponseHopLimit == 0
+}
+
+func (o *MetadataOptions) instanceMetadataOptionsRequest() *ec2.InstanceMetadataOptionsRequest {
+	req := new(ec2.InstanceMetadataOptionsRequest)
+	if o.HttpEndpoint != "" {
+		req.SetHttpEndpoint(o.HttpEndpoint)
+	}
+	if o.HttpTokens != "" {
+		req.SetHttpTokens(o.HttpTokens)
+	}
+	if o.HttpPutResponseHopLimit != 0 {
+		req.SetHttpPutResponseHopLimit(o.HttpPutResponseHopLimit)
+	}
+	return req
+}
+
+func (o *MetadataOptions) launchTemplateMetadataOptionsRequest() *ec2.LaunchTemplateInstanceMetadataOptionsRequest {
+	req := new(ec2.LaunchTemplateInstanceMetadataOptionsRequest)
+	if o.HttpEndpoint != "" {
+		req.SetHttpEndpoint(o.HttpEndpoint)
+	}
+	if o.HttpTokens != "" {
+		req.SetHttpTokens(o.HttpTokens)
+	}
+	if o.HttpPutResponseHopLimit != 0 {
+		req.SetHttpPutResponseHopLimit(o.HttpPutResponseHopLimit)
+	}
+	return req
+}
+
+func (o *MetadataOptions) Prepare() (errs []error) {
+	switch o.HttpEndpoint {
+	case "", "enabled", "disabled":
+	default:
+		errs = append(errs, fmt.Errorf("metadata_options.http_endpoint only accepts 'enabled' or 'disabled' values."))
+	}
+	switch o.HttpTokens {
+	case "", "optional", "required":
+	default:
+		errs = append(errs, fmt.Errorf("metadata_options.http_tokens only accepts 'optional' or 'required' values."))
+	}
+	if o.HttpPutResponseHopLimit < 0 || o.HttpPutResponseHopLimit > 64 {
+		errs = append(errs, fmt.Errorf("metadata_options.http_put_response_hop_limit must be between 1 and 64."))
+	}
+	return errs
+}
+
+// Configures the AWS Nitro Enclaves of the build instance.
+type EnclaveOptions struct {
+	// Enable Nitro Enclaves on the build instance, which requires an instance
+	// type supporting them. Defaults to `false`.
+	Enabled bool `mapstructure:"enabled" required:"false"`
+}
+
+// Selects the instance type of the build instance from its attributes,
+// instead of `instance_type` or `spot_instance_types`. The smallest instance
+// types of the current generation matching the requirements and the
+// architecture of the source AMI are selected: the on-demand instance falls
+// back to the next ones when EC2 has no capacity for the first, and the
+// spot fleet requests them all.
+type InstanceRequirements struct {
+	// The minimum number of vCPUs. Required.
+	VCpuCountMin int64 `mapstructure:"vcpu_count_min" required:"false"`
+	// The maximum number of vCPUs. Defaults to no maximum.
+	VCpuCountMax int64 `mapstructure:"vcpu_count_max" required:"false"`
+	// The minimum memory, in MiB. Required.
+	MemoryMiBMin int64 `mapstructure:"memory_mib_min" required:"false"`
+	// The maximum memory, in MiB. Defaults to no maximum.
+	MemoryMiBMax int64 `mapstructure:"memory_mib_max" required:"false"`
+	// Whether burstable performance instance types, like `t3`, are
+	// `included`, `excluded` or `required`. Defaults to `excluded`.
+	BurstablePerformance string `mapstructure:"burstable_performance" required:"false"`
+	// The instance types never selected, which can use `*` as a wildcard,
+	// like `m5*` or `*.metal`.
+	ExcludedInstanceTypes []string `mapstructure:"excluded_instance_types" required:"false"`
+}
+
+func (r *InstanceRequirements) Empty() bool {
+	return r.VCpuCountMin == 0 && r.VCpuCountMax == 0 &&
+		r.MemoryMiBMin == 0 && r.MemoryMiBMax == 0 &&
+		r.BurstablePerformance == "" && len(r.ExcludedInstanceTypes) == 0
+}
+
+func (r *InstanceRequirements) Prepare() (errs []error) {
+	if r.Empty() {
+		return nil
+	}
+	if r.VCpuCountMin <= 0 {
+		errs = append(errs, fmt.Errorf("instance_requirements.vcpu_count_min must be specified"))
+	}
+	if r.MemoryMiBMin <= 0 {
+		errs = append(errs, fmt.Errorf("instance_requirements.memory_mib_min must be specified"))
+	}
+	if r.VCpuCountMax != 0 && r.VCpuCountMax < r.VCpuCountMin {
+		errs = append(errs, fmt.Errorf("instance_requirements.vcpu_count_max must not be lower than vcpu_count_min"))
+	}
+	if r.MemoryMiBMax != 0 && r.MemoryMiBMax < r.MemoryMiBMin {
+		errs = append(errs, fmt.Errorf("instance_requirements.memory_mib_max must not be lower than memory_mib_min"))
+	}
+	switch r.BurstablePerformance {
+	case "":
+		r.BurstablePerformance = "excluded"
+	case "included", "excluded", "required":
+	default:
+		errs = append(errs, fmt.Errorf("instance_requirements.burstable_performance only accepts 'included', 'excluded' or 'required' values."))
+	}
+	for _, pattern := range r.ExcludedInstanceTypes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("instance_requirements.excluded_instance_types ('%s') is invalid: %s", pattern, err))
+		}
+	}
+	return errs
+}
+
 // RunConfig contains configuration for running an instance from a source
 // AMI and details on how to access that launched image.
 type RunConfig struct {
@@ -134,6 +269,21 @@ type RunConfig struct {
 	// Unlimited - even for instances that would usually qualify for the
 	// [AWS Free Tier](https://aws.amazon.com/free/).
 	EnableT2Unlimited bool `mapstructure:"enable_t2_unlimited" required:"false"`
+	// Enables [Nitro
+	// Enclaves](https://docs.aws.amazon.com/enclaves/latest/user/nitro-enclave.html)
+	// on the build instance. HCL2 example:
+	//
+	// ```hcl
+	//   enclave_options {
+	//     enabled = true
+	//   }
+	// ```
+	//
+	// -   `enabled` (bool) - Enable Nitro Enclaves, which requires an
+	//     instance type supporting them. Defaults to `false`.
+	//
+	// Nitro Enclaves can't be used with Spot Instances.
+	EnclaveOptions EnclaveOptions `mapstructure:"enclave_options" required:"false"`
 	// The name of an [IAM instance
 	// profile](https://docs.aws.amazon.com/IAM/latest/UserGuide/instance-profiles.html)
 	// to launch the EC2 instance with.
@@ -166,6 +316,51 @@ type RunConfig struct {
 	// The EC2 instance type to use while building the
 	// AMI, such as t2.small.
 	InstanceType string `mapstructure:"instance_type" required:"true"`
+	// Selects the instance type from its attributes, in place of
+	// `instance_type` or `spot_instance_types`. The smallest instance types of
+	// the current generation matching the requirements and the architecture
+	// of the source AMI are selected: an on-demand instance falls back to the
+	// next ones when EC2 has no capacity for the first, and a Spot Instance
+	// is requested with all of them. HCL2 example:
+	//
+	// ```hcl
+	//   instance_requirements {
+	//     vcpu_count_min = 2
+	//     vcpu_count_max = 4
+	//     memory_mib_min = 4096
+	//     excluded_instance_types = ["*.metal"]
+	//   }
+	// ```
+	//
+	// -   `vcpu_count_min` (int) - The minimum number of vCPUs. Required.
+	// -   `vcpu_count_max` (int) - The maximum number of vCPUs.
+	// -   `memory_mib_min` (int) - The minimum memory, in MiB. Required.
+	// -   `memory_mib_max` (int) - The maximum memory, in MiB.
+	// -   `burstable_performance` (string) - Whether burstable performance
+	//     instance types, like `t3`, are `included`, `excluded` or
+	//     `required`. Defaults to `excluded`.
+	// -   `excluded_instance_types` (array of strings) - The instance types
+	//     never selected, which can use `*` as a wildcard, like `m5*`.
+	InstanceRequirements InstanceRequirements `mapstructure:"instance_requirements" required:"false"`
+	// Configures the instance metadata service of the build instance. HCL2
+	// example, only allowing IMDSv2:
+	//
+	// ```hcl
+	//   metadata_options {
+	//     http_endpoint = "enabled"
+	//     http_tokens = "required"
+	//     http_put_response_hop_limit = 1
+	//   }
+	// ```
+	//
+	// -   `http_endpoint` (string) - Whether the metadata service is
+	//     available: `enabled` or `disabled`. Defaults to `enabled`.
+	// -   `http_tokens` (string) - Whether the metadata requests require a
+	//     session token: `optional`, allowing IMDSv1, or `required`, only
+	//     allowing IMDSv2. Defaults to `optional`.
+	// -   `http_put_response_hop_limit` (int) - The hop limit of the PUT
+	//     response requesting a session token, from 1 to 64. Defaults to 1.
+	MetadataOptions MetadataOptions `mapstructure:"metadata_options" required:"false"`
 	// Filters used to populate the `security_group_ids` field. JSON Example:
 	//
 	// ```json
@@ -282,6 +477,26 @@ type RunConfig struct {
 	//   criteria provided in `source_ami_filter`; this pins the AMI returned by the
 	//   filter, but will cause Packer to fail if the `source_ami` does not exist.
 	SourceAmiFilter AmiFilterOptions `mapstructure:"source_ami_filter" required:"false"`
+	// The allocation strategy of the spot fleet, across the instance types
+	// and availability zones: `lowest-price`, `diversified` or
+	// `capacity-optimized`, which favors the pools the least likely to be
+	// interrupted. Defaults to `lowest-price`. Requires `spot_price` to be
+	// set.
+	SpotAllocationStrategy string `mapstructure:"spot_allocation_strategy" required:"false"`
+	// The availability zones the spot fleet can run the instance in, in the
+	// default VPC. Can't be used with `availability_zone`, `subnet_id` or
+	// `subnet_filter`. Requires `spot_price` to be set.
+	SpotAvailabilityZones []string `mapstructure:"spot_availability_zones" required:"false"`
+	// Requests the spot capacity again every 30 seconds, when there is none,
+	// until this timeout, then runs an on-demand instance of one of the same
+	// instance types instead. By default, the build fails right away without
+	// spot capacity. Can't be used with `block_duration_minutes`. Requires
+	// `spot_price` to be set.
+	SpotFallbackTimeout time.Duration `mapstructure:"spot_fallback_timeout" required:"false"`
+	// The maximum hourly price of the on-demand instance run by
+	// `spot_fallback_timeout`. No on-demand instance runs when they all
+	// cost more. Defaults to no maximum.
+	OnDemandMaxPrice string `mapstructure:"on_demand_max_price" required:"false"`
 	// a list of acceptable instance
 	// types to run your build on. We will request a spot instance using the max
 	// price of spot_price and the allocation strategy of "lowest price".
@@ -447,9 +662,33 @@ type RunConfig struct {
 	// password for Windows instances. Defaults to 20 minutes. Example value:
 	// 10m
 	WindowsPasswordTimeout time.Duration `mapstructure:"windows_password_timeout" required:"false"`
+	// Prepares the launch agent of the Windows guest, EC2Launch v2,
+	// EC2Launch or EC2Config, once provisioned, so that the instances
+	// launched from the AMI get a new Administrator password, retrieved with
+	// their key pair, and run their user data, like the Windows AMIs of
+	// Amazon do:
+	//
+	// -   `initialize` - Schedules the initialization of the instance at the
+	//     next boot: `EC2Launch.exe reset` with EC2Launch v2,
+	//     `InitializeInstance.ps1 -Schedule` with EC2Launch, and the
+	//     `Ec2SetPassword` and `Ec2HandleUserData` plugins enabled with
+	//     EC2Config.
+	//
+	// -   `sysprep` - Also generalizes the guest with sysprep, with the
+	//     answer file of the agent: `EC2Launch.exe sysprep` with EC2Launch
+	//     v2, `SysprepInstance.ps1 -NoShutdown` with EC2Launch, and
+	//     `sysprep2008.xml` with EC2Config. Packer waits up to
+	//     `sysprep_timeout` for sysprep to finish, before stopping the
+	//     instance itself.
+	//
+	// The agent is prepared after the `credential_rotation` and the
+	// `sysprep_generalize` steps, and can't be combined with the
+	// `remove_user` rotation. Requires the WinRM communicator.
+	WindowsLaunchPrepare string `mapstructure:"windows_launch_prepare" required:"false"`
 
 	// Communicator settings
-	Comm communicator.Config `mapstructure:",squash"`
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
 
 	// One of `public_ip`, `private_ip`, `public_dns`, `private_dns` or `session_manager`.
 	//    If set, either the public IP address, private IP address, public DNS name
@@ -500,6 +739,7 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) []error {
 
 	// Validation
 	errs := c.Comm.Prepare(ctx)
+	errs = append(errs, c.Guest.Prepare(&c.Comm)...)
 
 	// Copy singular tag maps
 	errs = append(errs, c.RunTag.CopyOn(&c.RunTags)...)
@@ -510,10 +750,38 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) []error {
 		&c.SecurityGroupFilter,
 		&c.SubnetFilter,
 		&c.VpcFilter,
+		&c.MetadataOptions,
+		&c.InstanceRequirements,
 	} {
 		errs = append(errs, preparer.Prepare()...)
 	}
 
+	switch c.WindowsLaunchPrepare {
+	case "":
+	case WindowsLaunchInitialize, WindowsLaunchSysprep:
+		if c.Comm.Type != "winrm" {
+			errs = append(errs, fmt.Errorf("windows_launch_prepare requires the winrm communicator"))
+		}
+		if c.Guest.CredentialRotation == guest.CredentialRotationRemoveUser {
+			errs = append(errs, fmt.Errorf("windows_launch_prepare can't be combined with credential_rotation 'remove_user'"))
+		}
+		if c.WindowsLaunchPrepare == WindowsLaunchSysprep {
+			if c.Guest.SysprepGeneralize {
+				errs = append(errs, fmt.Errorf("windows_launch_prepare 'sysprep' can't be combined with sysprep_generalize"))
+			}
+			for _, name := range c.Guest.GuestCleanup {
+				if name == "sysprep" {
+					errs = append(errs, fmt.Errorf("windows_launch_prepare 'sysprep' can't be combined with the sysprep guest cleanup task"))
+				}
+			}
+			if c.Guest.SysprepTimeout == 0 {
+				c.Guest.SysprepTimeout = 15 * time.Minute
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("windows_launch_prepare only accepts 'initialize' or 'sysprep' values."))
+	}
+
 	// Validating ssh_interface
 	if c.SSHInterface != "public_ip" &&
 		c.SSHInterface != "private_ip" &&
@@ -553,9 +821,18 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) []error {
 		errs = append(errs, fmt.Errorf("For security reasons, your source AMI filter must declare an owner."))
 	}
 
-	if c.InstanceType == "" && len(c.SpotInstanceTypes) == 0 {
-		errs = append(errs, fmt.Errorf("either instance_type or "+
-			"spot_instance_types must be specified"))
+	if c.InstanceType == "" && len(c.SpotInstanceTypes) == 0 && c.InstanceRequirements.Empty() {
+		errs = append(errs, fmt.Errorf("either instance_type, "+
+			"spot_instance_types or instance_requirements must be specified"))
+	}
+
+	if !c.InstanceRequirements.Empty() && (c.InstanceType != "" || len(c.SpotInstanceTypes) > 0) {
+		errs = append(errs, fmt.Errorf("instance_requirements can't be "+
+			"specified with instance_type or spot_instance_types"))
+	}
+
+	if c.EnclaveOptions.Enabled && c.IsSpotInstance() {
+		errs = append(errs, fmt.Errorf("enclave_options can't be used in conjunction with Spot Instances"))
 	}
 
 	if c.InstanceType != "" && len(c.SpotInstanceTypes) > 0 {
@@ -568,6 +845,38 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) []error {
 			"block_duration_minutes must be multiple of 60"))
 	}
 
+	if !c.IsSpotInstance() {
+		if c.SpotAllocationStrategy != "" || len(c.SpotAvailabilityZones) > 0 || c.SpotFallbackTimeout != 0 {
+			errs = append(errs, fmt.Errorf("spot_allocation_strategy, spot_availability_zones and "+
+				"spot_fallback_timeout require spot_price to be set"))
+		}
+	}
+
+	switch c.SpotAllocationStrategy {
+	case "", "lowest-price", "diversified", "capacity-optimized":
+	default:
+		errs = append(errs, fmt.Errorf("spot_allocation_strategy only accepts 'lowest-price', "+
+			"'diversified' or 'capacity-optimized' values."))
+	}
+
+	if len(c.SpotAvailabilityZones) > 0 && (c.AvailabilityZone != "" || c.SubnetId != "" || !c.SubnetFilter.Empty()) {
+		errs = append(errs, fmt.Errorf("spot_availability_zones can't be used with "+
+			"availability_zone, subnet_id or subnet_filter"))
+	}
+
+	if c.SpotFallbackTimeout != 0 && c.BlockDurationMinutes != 0 {
+		errs = append(errs, fmt.Errorf("spot_fallback_timeout can't be used with block_duration_minutes"))
+	}
+
+	if c.OnDemandMaxPrice != "" {
+		if c.SpotFallbackTimeout == 0 {
+			errs = append(errs, fmt.Errorf("on_demand_max_price requires spot_fallback_timeout to be set"))
+		}
+		if _, err := strconv.ParseFloat(c.OnDemandMaxPrice, 64); err != nil {
+			errs = append(errs, fmt.Errorf("on_demand_max_price is invalid: %s", err))
+		}
+	}
+
 	if c.SpotTags != nil {
 		if c.SpotPrice == "" || c.SpotPrice == "0" {
 			errs = append(errs, fmt.Errorf(
@@ -1,4 +1,4 @@
-// Code generated by "mapstructure-to-hcl2 -type AmiFilterOptions,SecurityGroupFilterOptions,SubnetFilterOptions,VpcFilterOptions,PolicyDocument,Statement"; DO NOT EDIT.
+// Code generated by "mapstructure-to-hcl2 -type AmiFilterOptions,SecurityGroupFilterOptions,SubnetFilterOptions,VpcFilterOptions,PolicyDocument,Statement,MetadataOptions,EnclaveOptions,InstanceRequirements"; DO NOT EDIT.
 package common
 
 import (
@@ -36,6 +36,89 @@ func (*FlatAmiFilterOptions) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatEnclaveOptions is an auto-generated flat version of EnclaveOptions.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatEnclaveOptions struct {
+	Enabled *bool `mapstructure:"enabled" required:"false" cty:"enabled" hcl:"enabled"`
+}
+
+// FlatMapstructure returns a new FlatEnclaveOptions.
+// FlatEnclaveOptions is an auto-generated flat version of EnclaveOptions.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*EnclaveOptions) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatEnclaveOptions)
+}
+
+// HCL2Spec returns the hcl spec of a EnclaveOptions.
+// This spec is used by HCL to read the fields of EnclaveOptions.
+// The decoded values from this spec will then be applied to a FlatEnclaveOptions.
+func (*FlatEnclaveOptions) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"enabled": &hcldec.AttrSpec{Name: "enabled", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
+// FlatInstanceRequirements is an auto-generated flat version of InstanceRequirements.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatInstanceRequirements struct {
+	VCpuCountMin          *int64   `mapstructure:"vcpu_count_min" required:"false" cty:"vcpu_count_min" hcl:"vcpu_count_min"`
+	VCpuCountMax          *int64   `mapstructure:"vcpu_count_max" required:"false" cty:"vcpu_count_max" hcl:"vcpu_count_max"`
+	MemoryMiBMin          *int64   `mapstructure:"memory_mib_min" required:"false" cty:"memory_mib_min" hcl:"memory_mib_min"`
+	MemoryMiBMax          *int64   `mapstructure:"memory_mib_max" required:"false" cty:"memory_mib_max" hcl:"memory_mib_max"`
+	BurstablePerformance  *string  `mapstructure:"burstable_performance" required:"false" cty:"burstable_performance" hcl:"burstable_performance"`
+	ExcludedInstanceTypes []string `mapstructure:"excluded_instance_types" required:"false" cty:"excluded_instance_types" hcl:"excluded_instance_types"`
+}
+
+// FlatMapstructure returns a new FlatInstanceRequirements.
+// FlatInstanceRequirements is an auto-generated flat version of InstanceRequirements.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*InstanceRequirements) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatInstanceRequirements)
+}
+
+// HCL2Spec returns the hcl spec of a InstanceRequirements.
+// This spec is used by HCL to read the fields of InstanceRequirements.
+// The decoded values from this spec will then be applied to a FlatInstanceRequirements.
+func (*FlatInstanceRequirements) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"vcpu_count_min":          &hcldec.AttrSpec{Name: "vcpu_count_min", Type: cty.Number, Required: false},
+		"vcpu_count_max":          &hcldec.AttrSpec{Name: "vcpu_count_max", Type: cty.Number, Required: false},
+		"memory_mib_min":          &hcldec.AttrSpec{Name: "memory_mib_min", Type: cty.Number, Required: false},
+		"memory_mib_max":          &hcldec.AttrSpec{Name: "memory_mib_max", Type: cty.Number, Required: false},
+		"burstable_performance":   &hcldec.AttrSpec{Name: "burstable_performance", Type: cty.String, Required: false},
+		"excluded_instance_types": &hcldec.AttrSpec{Name: "excluded_instance_types", Type: cty.List(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatMetadataOptions is an auto-generated flat version of MetadataOptions.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatMetadataOptions struct {
+	HttpEndpoint            *string `mapstructure:"http_endpoint" required:"false" cty:"http_endpoint" hcl:"http_endpoint"`
+	HttpTokens              *string `mapstructure:"http_tokens" required:"false" cty:"http_tokens" hcl:"http_tokens"`
+	HttpPutResponseHopLimit *int64  `mapstructure:"http_put_response_hop_limit" required:"false" cty:"http_put_response_hop_limit" hcl:"http_put_response_hop_limit"`
+}
+
+// FlatMapstructure returns a new FlatMetadataOptions.
+// FlatMetadataOptions is an auto-generated flat version of MetadataOptions.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*MetadataOptions) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatMetadataOptions)
+}
+
+// HCL2Spec returns the hcl spec of a MetadataOptions.
+// This spec is used by HCL to read the fields of MetadataOptions.
+// The decoded values from this spec will then be applied to a FlatMetadataOptions.
+func (*FlatMetadataOptions) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"http_endpoint":               &hcldec.AttrSpec{Name: "http_endpoint", Type: cty.String, Required: false},
+		"http_tokens":                 &hcldec.AttrSpec{Name: "http_tokens", Type: cty.String, Required: false},
+		"http_put_response_hop_limit": &hcldec.AttrSpec{Name: "http_put_response_hop_limit", Type: cty.Number, Required: false},
+	}
+	return s
+}
+
 // FlatPolicyDocument is an auto-generated flat version of PolicyDocument.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatPolicyDocument struct {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/packer/hcl2template"
 	"github.com/hashicorp/packer/helper/communicator"
@@ -131,6 +132,99 @@ func TestRunConfigPrepare_EnableT2UnlimitedBadWithSpotInstanceRequest(t *testing
 	}
 }
 
+func TestRunConfigPrepare_MetadataOptions(t *testing.T) {
+	c := testConfig()
+	c.MetadataOptions = MetadataOptions{
+		HttpEndpoint:            "enabled",
+		HttpTokens:              "required",
+		HttpPutResponseHopLimit: 2,
+	}
+	if err := c.Prepare(nil); len(err) != 0 {
+		t.Fatalf("err: %s", err)
+	}
+
+	c.MetadataOptions = MetadataOptions{
+		HttpEndpoint:            "on",
+		HttpTokens:              "v2",
+		HttpPutResponseHopLimit: 65,
+	}
+	if err := c.Prepare(nil); len(err) != 3 {
+		t.Fatalf("Should error on invalid metadata_options, got: %s", err)
+	}
+}
+
+func TestRunConfigPrepare_EnclaveOptionsWithSpotInstanceRequest(t *testing.T) {
+	c := testConfig()
+	c.EnclaveOptions.Enabled = true
+	if err := c.Prepare(nil); len(err) != 0 {
+		t.Fatalf("err: %s", err)
+	}
+
+	c.SpotPrice = "auto"
+	if err := c.Prepare(nil); len(err) != 1 {
+		t.Fatalf("Should error if enclave_options is used in conjunction with a Spot Price request")
+	}
+}
+
+func TestRunConfigPrepare_InstanceRequirements(t *testing.T) {
+	c := testConfig()
+	c.InstanceType = ""
+	c.InstanceRequirements = InstanceRequirements{
+		VCpuCountMin: 2,
+		MemoryMiBMin: 4096,
+	}
+	if err := c.Prepare(nil); len(err) != 0 {
+		t.Fatalf("err: %s", err)
+	}
+	if c.InstanceRequirements.BurstablePerformance != "excluded" {
+		t.Fatalf("bad burstable_performance: %s", c.InstanceRequirements.BurstablePerformance)
+	}
+
+	c.InstanceType = "m5.large"
+	if err := c.Prepare(nil); len(err) != 1 {
+		t.Fatalf("Should error if instance_requirements is used with instance_type")
+	}
+
+	c = testConfig()
+	c.InstanceType = ""
+	c.InstanceRequirements = InstanceRequirements{
+		VCpuCountMax:          1,
+		MemoryMiBMax:          1024,
+		BurstablePerformance:  "sometimes",
+		ExcludedInstanceTypes: []string{"m5["},
+	}
+	if err := c.Prepare(nil); len(err) != 4 {
+		t.Fatalf("Should error on invalid instance_requirements, got: %s", err)
+	}
+}
+
+func TestRunConfigPrepare_SpotFallback(t *testing.T) {
+	c := testConfig()
+	c.SpotPrice = "0.05"
+	c.SpotAllocationStrategy = "capacity-optimized"
+	c.SpotAvailabilityZones = []string{"us-east-1a", "us-east-1b"}
+	c.SpotFallbackTimeout = 10 * time.Minute
+	c.OnDemandMaxPrice = "0.2"
+	if err := c.Prepare(nil); len(err) != 0 {
+		t.Fatalf("err: %s", err)
+	}
+
+	c.SpotAllocationStrategy = "cheapest"
+	c.SubnetId = "subnet-12345678"
+	c.BlockDurationMinutes = 60
+	c.OnDemandMaxPrice = "cheap"
+	if err := c.Prepare(nil); len(err) != 4 {
+		t.Fatalf("Should error on invalid spot fallback options, got: %s", err)
+	}
+
+	c = testConfig()
+	c.SpotFallbackTimeout = 10 * time.Minute
+	c.OnDemandMaxPrice = "0.2"
+	if err := c.Prepare(nil); len(err) != 1 {
+		t.Fatalf("Should error if spot_fallback_timeout is set without spot_price, got: %s", err)
+	}
+}
+
 func TestRunConfigPrepare_SpotAuto(t *testing.T) {
 	c := testConfig()
 	c.SpotPrice = "auto"
@@ -166,6 +260,43 @@ func TestRunConfigPrepare_SSHPort(t *testing.T) {
 	}
 }
 
+func TestRunConfigPrepare_WindowsLaunchPrepare(t *testing.T) {
+	c := testConfig()
+	c.WindowsLaunchPrepare = "sysprep"
+	if err := c.Prepare(nil); len(err) != 1 {
+		t.Fatalf("windows_launch_prepare should require the winrm communicator, got: %v", err)
+	}
+
+	c = testConfig()
+	c.Comm.Type = "winrm"
+	c.Comm.WinRMUser = "Administrator"
+	c.WindowsLaunchPrepare = "sysprep"
+	if err := c.Prepare(nil); len(err) != 0 {
+		t.Fatalf("err: %s", err)
+	}
+	if c.Guest.SysprepTimeout != 15*time.Minute {
+		t.Fatalf("bad sysprep_timeout: %s", c.Guest.SysprepTimeout)
+	}
+
+	c.Guest.GuestCleanup = []string{"sysprep"}
+	if err := c.Prepare(nil); len(err) != 1 {
+		t.Fatalf("sysprep should not be run twice, got: %v", err)
+	}
+
+	c.Guest.GuestCleanup = nil
+	c.Guest.CredentialRotation = "remove_user"
+	c.Comm.WinRMUser = "packer"
+	if err := c.Prepare(nil); len(err) != 1 {
+		t.Fatalf("the agent can't be prepared as a removed user, got: %v", err)
+	}
+
+	c.Guest.CredentialRotation = ""
+	c.WindowsLaunchPrepare = "reboot"
+	if err := c.Prepare(nil); len(err) != 1 {
+		t.Fatalf("an unknown value should be rejected, got: %v", err)
+	}
+}
+
 func TestRunConfigPrepare_UserData(t *testing.T) {
 	c := testConfig()
 	tf, err := ioutil.TempFile("", "packer")
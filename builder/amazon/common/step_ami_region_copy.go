@@ -221,6 +221,8 @@ func (s *StepAMIRegionCopy) amiRegionCopy(ctx context.Context, state multistep.S
 			imageId, target, err)
 	}
 
+	meterImageCopy(state, describeImageResp.Images[0])
+
 	for _, blockDeviceMapping := range describeImageResp.Images[0].BlockDeviceMappings {
 		if blockDeviceMapping.Ebs != nil && blockDeviceMapping.Ebs.SnapshotId != nil {
 			snapshotIds = append(snapshotIds, *blockDeviceMapping.Ebs.SnapshotId)
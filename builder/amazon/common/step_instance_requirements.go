@@ -0,0 +1,163 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// maxInstanceTypes is the maximum number of instance types selected from the
+// instance requirements, so that the spot fleet request stays reasonable.
+const maxInstanceTypes = 20
+
+// StepInstanceRequirements selects the instance types of the build instance
+// from the instance requirements, when set.
+//
+// Uses:
+//   ec2 *ec2.EC2
+//   source_image *ec2.Image
+//   ui packer.Ui
+//
+// Produces:
+//   instance_types []string - the selected instance types, smallest first
+type StepInstanceRequirements struct {
+	Requirements InstanceRequirements
+	// Spot only selects the instance types available as Spot Instances.
+	Spot bool
+}
+
+func (s *StepInstanceRequirements) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Requirements.Empty() {
+		return multistep.ActionContinue
+	}
+	ec2conn := state.Get("ec2").(*ec2.EC2)
+	image := state.Get("source_image").(*ec2.Image)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Selecting the instance types matching the instance requirements...")
+	var infos []*ec2.InstanceTypeInfo
+	input := &ec2.DescribeInstanceTypesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("current-generation"),
+				Values: []*string{aws.String("true")},
+			},
+		},
+	}
+	err := ec2conn.DescribeInstanceTypesPagesWithContext(ctx, input, func(page *ec2.DescribeInstanceTypesOutput, lastPage bool) bool {
+		infos = append(infos, page.InstanceTypes...)
+		return true
+	})
+	if err != nil {
+		err := fmt.Errorf("Error describing instance types: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	usageClass := ec2.UsageClassTypeOnDemand
+	if s.Spot {
+		usageClass = ec2.UsageClassTypeSpot
+	}
+	instanceTypes := s.Requirements.InstanceTypes(infos,
+		aws.StringValue(image.Architecture), aws.StringValue(image.RootDeviceType), usageClass)
+	if len(instanceTypes) == 0 {
+		err := fmt.Errorf("No instance type matches the instance requirements for the %s source AMI",
+			aws.StringValue(image.Architecture))
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	ui.Message(fmt.Sprintf("Selected instance types: %s", strings.Join(instanceTypes, ", ")))
+	state.Put("instance_types", instanceTypes)
+	return multistep.ActionContinue
+}
+
+func (s *StepInstanceRequirements) Cleanup(state multistep.StateBag) {}
+
+// InstanceTypes returns the instance types of infos matching the
+// requirements, the architecture and the root device type of the source AMI,
+// and the usage class of the build instance, smallest first.
+func (r *InstanceRequirements) InstanceTypes(infos []*ec2.InstanceTypeInfo, arch, rootDeviceType, usageClass string) []string {
+	var matches []*ec2.InstanceTypeInfo
+	for _, info := range infos {
+		if r.matches(info, arch, rootDeviceType, usageClass) {
+			matches = append(matches, info)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		vcpusI, vcpusJ := aws.Int64Value(matches[i].VCpuInfo.DefaultVCpus), aws.Int64Value(matches[j].VCpuInfo.DefaultVCpus)
+		if vcpusI != vcpusJ {
+			return vcpusI < vcpusJ
+		}
+		memI, memJ := aws.Int64Value(matches[i].MemoryInfo.SizeInMiB), aws.Int64Value(matches[j].MemoryInfo.SizeInMiB)
+		if memI != memJ {
+			return memI < memJ
+		}
+		return aws.StringValue(matches[i].InstanceType) < aws.StringValue(matches[j].InstanceType)
+	})
+
+	var instanceTypes []string
+	for _, info := range matches {
+		if len(instanceTypes) == maxInstanceTypes {
+			break
+		}
+		instanceTypes = append(instanceTypes, aws.StringValue(info.InstanceType))
+	}
+	return instanceTypes
+}
+
+func (r *InstanceRequirements) matches(info *ec2.InstanceTypeInfo, arch, rootDeviceType, usageClass string) bool {
+	if info.VCpuInfo == nil || info.MemoryInfo == nil || info.ProcessorInfo == nil {
+		return false
+	}
+	vcpus := aws.Int64Value(info.VCpuInfo.DefaultVCpus)
+	if vcpus < r.VCpuCountMin || (r.VCpuCountMax != 0 && vcpus > r.VCpuCountMax) {
+		return false
+	}
+	memory := aws.Int64Value(info.MemoryInfo.SizeInMiB)
+	if memory < r.MemoryMiBMin || (r.MemoryMiBMax != 0 && memory > r.MemoryMiBMax) {
+		return false
+	}
+
+	burstable := aws.BoolValue(info.BurstablePerformanceSupported)
+	switch r.BurstablePerformance {
+	case "excluded", "":
+		if burstable {
+			return false
+		}
+	case "required":
+		if !burstable {
+			return false
+		}
+	}
+
+	if !containsString(aws.StringValueSlice(info.ProcessorInfo.SupportedArchitectures), arch) ||
+		!containsString(aws.StringValueSlice(info.SupportedRootDeviceTypes), rootDeviceType) ||
+		!containsString(aws.StringValueSlice(info.SupportedUsageClasses), usageClass) {
+		return false
+	}
+
+	for _, pattern := range r.ExcludedInstanceTypes {
+		if ok, _ := path.Match(pattern, aws.StringValue(info.InstanceType)); ok {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func testInstanceTypeInfo(instanceType string, vcpus, memory int64, burstable bool, arch string) *ec2.InstanceTypeInfo {
+	return &ec2.InstanceTypeInfo{
+		InstanceType:                  aws.String(instanceType),
+		BurstablePerformanceSupported: aws.Bool(burstable),
+		VCpuInfo:                      &ec2.VCpuInfo{DefaultVCpus: aws.Int64(vcpus)},
+		MemoryInfo:                    &ec2.MemoryInfo{SizeInMiB: aws.Int64(memory)},
+		ProcessorInfo:                 &ec2.ProcessorInfo{SupportedArchitectures: aws.StringSlice([]string{arch})},
+		SupportedRootDeviceTypes:      aws.StringSlice([]string{"ebs"}),
+		SupportedUsageClasses:         aws.StringSlice([]string{"on-demand", "spot"}),
+	}
+}
+
+func TestInstanceRequirements_InstanceTypes(t *testing.T) {
+	infos := []*ec2.InstanceTypeInfo{
+		testInstanceTypeInfo("m5.2xlarge", 8, 32768, false, "x86_64"),
+		testInstanceTypeInfo("m5.large", 2, 8192, false, "x86_64"),
+		testInstanceTypeInfo("c5.large", 2, 4096, false, "x86_64"),
+		testInstanceTypeInfo("m6g.large", 2, 8192, false, "arm64"),
+		testInstanceTypeInfo("t3.large", 2, 8192, true, "x86_64"),
+		testInstanceTypeInfo("m5.metal", 96, 393216, false, "x86_64"),
+		testInstanceTypeInfo("m5.xlarge", 4, 16384, false, "x86_64"),
+		testInstanceTypeInfo("c5.medium", 1, 2048, false, "x86_64"),
+	}
+
+	cases := []struct {
+		name         string
+		requirements InstanceRequirements
+		arch         string
+		expected     []string
+	}{
+		{
+			name:         "smallest first",
+			requirements: InstanceRequirements{VCpuCountMin: 2, MemoryMiBMin: 4096, BurstablePerformance: "excluded"},
+			arch:         "x86_64",
+			expected:     []string{"c5.large", "m5.large", "m5.xlarge", "m5.2xlarge", "m5.metal"},
+		},
+		{
+			name: "maximums and exclusions",
+			requirements: InstanceRequirements{
+				VCpuCountMin:          2,
+				VCpuCountMax:          8,
+				MemoryMiBMin:          8192,
+				MemoryMiBMax:          16384,
+				BurstablePerformance:  "included",
+				ExcludedInstanceTypes: []string{"m5.x*"},
+			},
+			arch:     "x86_64",
+			expected: []string{"m5.large", "t3.large"},
+		},
+		{
+			name:         "burstable required",
+			requirements: InstanceRequirements{VCpuCountMin: 1, MemoryMiBMin: 1, BurstablePerformance: "required"},
+			arch:         "x86_64",
+			expected:     []string{"t3.large"},
+		},
+		{
+			name:         "architecture",
+			requirements: InstanceRequirements{VCpuCountMin: 1, MemoryMiBMin: 1, BurstablePerformance: "excluded"},
+			arch:         "arm64",
+			expected:     []string{"m6g.large"},
+		},
+		{
+			name:         "no match",
+			requirements: InstanceRequirements{VCpuCountMin: 128, MemoryMiBMin: 1, BurstablePerformance: "excluded"},
+			arch:         "x86_64",
+			expected:     nil,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.requirements.InstanceTypes(infos, tc.arch, "ebs", "on-demand")
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("bad instance types: %v, expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestInstanceRequirements_InstanceTypesUsageClass(t *testing.T) {
+	info := testInstanceTypeInfo("m5.large", 2, 8192, false, "x86_64")
+	info.SupportedUsageClasses = aws.StringSlice([]string{"on-demand"})
+	r := InstanceRequirements{VCpuCountMin: 1, MemoryMiBMin: 1}
+
+	if got := r.InstanceTypes([]*ec2.InstanceTypeInfo{info}, "x86_64", "ebs", "spot"); len(got) != 0 {
+		t.Fatalf("should not select on-demand only instance types for spot instances: %v", got)
+	}
+	if got := r.InstanceTypes([]*ec2.InstanceTypeInfo{info}, "x86_64", "instance-store", "on-demand"); len(got) != 0 {
+		t.Fatalf("should not select ebs only instance types for instance-store AMIs: %v", got)
+	}
+}
@@ -2,15 +2,22 @@ package common
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"runtime"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	packerCommon "github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/retry"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/helper/ssh"
 	"github.com/hashicorp/packer/packer"
 )
 
@@ -18,8 +25,10 @@ type StepKeyPair struct {
 	Debug        bool
 	Comm         *communicator.Config
 	DebugKeyPath string
+	BuildName    string
 
 	doCleanup bool
+	shared    *ssh.SharedKeyPair
 }
 
 func (s *StepKeyPair) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -55,10 +64,20 @@ func (s *StepKeyPair) Run(ctx context.Context, state multistep.StateBag) multist
 	}
 
 	ec2conn := state.Get("ec2").(*ec2.EC2)
+
+	shared, err := s.Comm.SharedTemporaryKeyPair(s.BuildName, s.Debug)
+	if err != nil {
+		state.Put("error", fmt.Errorf("Error creating temporary keypair: %s", err))
+		return multistep.ActionHalt
+	}
+	if shared != nil {
+		return s.runShared(ec2conn, shared, state)
+	}
+
 	var keyResp *ec2.CreateKeyPairOutput
 
 	ui.Say(fmt.Sprintf("Creating temporary keypair: %s", s.Comm.SSHTemporaryKeyPairName))
-	err := retry.Config{
+	err = retry.Config{
 		Tries:      11,
 		RetryDelay: (&retry.Backoff{InitialBackoff: 200 * time.Millisecond, MaxBackoff: 30 * time.Second, Multiplier: 2}).Linear,
 	}.Run(ctx, func(ctx context.Context) error {
@@ -74,11 +93,16 @@ func (s *StepKeyPair) Run(ctx context.Context, state multistep.StateBag) multist
 	}
 
 	s.doCleanup = true
+	TrackResource(ec2conn, CleanupKeyPair, s.Comm.SSHTemporaryKeyPairName)
 
 	// Set some data for use in future steps
 	s.Comm.SSHKeyPairName = s.Comm.SSHTemporaryKeyPairName
 	s.Comm.SSHPrivateKey = []byte(*keyResp.KeyMaterial)
 
+	// Save the key to connect to the instance again when resuming the build.
+	packerCommon.JournalSet(state, journalKeyPairName, s.Comm.SSHTemporaryKeyPairName)
+	packerCommon.JournalSet(state, journalPrivateKey, *keyResp.KeyMaterial)
+
 	// If we're in debug mode, output the private key to the working
 	// directory.
 	if s.Debug {
@@ -108,7 +132,90 @@ func (s *StepKeyPair) Run(ctx context.Context, state multistep.StateBag) multist
 	return multistep.ActionContinue
 }
 
+// Resume uses the temporary keypair of the resumed build again, with the
+// private key saved in its journal, to connect to its instance.
+func (s *StepKeyPair) Resume(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	name := packerCommon.JournalGet(state, journalKeyPairName)
+	privateKey := packerCommon.JournalGet(state, journalPrivateKey)
+	if name == "" || privateKey == "" {
+		return s.Run(ctx, state)
+	}
+
+	ec2conn := state.Get("ec2").(*ec2.EC2)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say(fmt.Sprintf("Resuming with temporary keypair: %s", name))
+	s.doCleanup = true
+	adoptResource(ec2conn, CleanupKeyPair, name)
+
+	s.Comm.SSHTemporaryKeyPairName = name
+	s.Comm.SSHKeyPairName = name
+	s.Comm.SSHPrivateKey = []byte(privateKey)
+	return multistep.ActionContinue
+}
+
+// runShared uses the temporary keypair shared with other builds: the first
+// build using it in the region imports it.
+func (s *StepKeyPair) runShared(ec2conn *ec2.EC2, shared *ssh.SharedKeyPair, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say(fmt.Sprintf("Using shared temporary keypair: %s", shared.Name))
+	kp, err := shared.Acquire(keyPairScope(ec2conn), s.keyPairUser(), func(kp ssh.KeyPair) error {
+		ui.Say(fmt.Sprintf("Importing temporary keypair: %s", shared.Name))
+		_, err := ec2conn.ImportKeyPair(&ec2.ImportKeyPairInput{
+			KeyName:           &shared.Name,
+			PublicKeyMaterial: kp.PublicKeyAuthorizedKeysLine,
+		})
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "InvalidKeyPair.Duplicate" {
+			// A persisted keypair was imported by an earlier run.
+			return nil
+		}
+		if err == nil && !shared.Persist {
+			TrackResource(ec2conn, CleanupKeyPair, shared.Name)
+		}
+		return err
+	})
+	if err != nil {
+		state.Put("error", fmt.Errorf("Error importing temporary keypair: %s", err))
+		return multistep.ActionHalt
+	}
+	s.shared = shared
+
+	s.Comm.SSHTemporaryKeyPairName = shared.Name
+	s.Comm.SSHKeyPairName = shared.Name
+	s.Comm.SSHPrivateKey = kp.PrivateKeyPemBlock
+
+	if s.Debug {
+		ui.Message(fmt.Sprintf("Saving key for debug purposes: %s", s.DebugKeyPath))
+		if err := ioutil.WriteFile(s.DebugKeyPath, kp.PrivateKeyPemBlock, 0600); err != nil {
+			state.Put("error", fmt.Errorf("Error saving debug key: %s", err))
+			return multistep.ActionHalt
+		}
+	}
+	return multistep.ActionContinue
+}
+
+// keyPairScope returns the scope of the keypairs of ec2conn: keypairs are
+// per account and region.
+func keyPairScope(ec2conn *ec2.EC2) string {
+	region := aws.StringValue(ec2conn.Config.Region)
+	creds, err := ec2conn.Config.Credentials.Get()
+	if err != nil {
+		return region
+	}
+	sum := sha256.Sum256([]byte(creds.AccessKeyID))
+	return region + "_" + hex.EncodeToString(sum[:8])
+}
+
+func (s *StepKeyPair) keyPairUser() string {
+	return fmt.Sprintf("%s_%d", s.BuildName, os.Getpid())
+}
+
 func (s *StepKeyPair) Cleanup(state multistep.StateBag) {
+	if s.shared != nil {
+		s.cleanupShared(state)
+		return
+	}
 	if !s.doCleanup {
 		return
 	}
@@ -118,10 +225,12 @@ func (s *StepKeyPair) Cleanup(state multistep.StateBag) {
 
 	// Remove the keypair
 	ui.Say("Deleting temporary keypair...")
-	_, err := ec2conn.DeleteKeyPair(&ec2.DeleteKeyPairInput{KeyName: &s.Comm.SSHTemporaryKeyPairName})
+	_, err := ec2conn.DeleteKeyPairWithContext(multistep.CleanupContext(state), &ec2.DeleteKeyPairInput{KeyName: &s.Comm.SSHTemporaryKeyPairName})
 	if err != nil {
 		ui.Error(fmt.Sprintf(
 			"Error cleaning up keypair. Please delete the key manually: %s", s.Comm.SSHTemporaryKeyPairName))
+	} else {
+		UntrackResource(CleanupKeyPair, s.Comm.SSHTemporaryKeyPairName)
 	}
 
 	// Also remove the physical key if we're debugging.
@@ -132,3 +241,28 @@ func (s *StepKeyPair) Cleanup(state multistep.StateBag) {
 		}
 	}
 }
+
+func (s *StepKeyPair) cleanupShared(state multistep.StateBag) {
+	ec2conn := state.Get("ec2").(*ec2.EC2)
+	ui := state.Get("ui").(packer.Ui)
+
+	err := s.shared.Release(keyPairScope(ec2conn), s.keyPairUser(), func() error {
+		ui.Say("Deleting temporary keypair...")
+		_, err := ec2conn.DeleteKeyPair(&ec2.DeleteKeyPairInput{KeyName: &s.shared.Name})
+		if err == nil {
+			UntrackResource(CleanupKeyPair, s.shared.Name)
+		}
+		return err
+	})
+	if err != nil {
+		ui.Error(fmt.Sprintf(
+			"Error cleaning up keypair. Please delete the key manually: %s: %s", s.shared.Name, err))
+	}
+
+	if s.Debug && !s.shared.Persist {
+		if err := os.Remove(s.DebugKeyPath); err != nil {
+			ui.Error(fmt.Sprintf(
+				"Error removing debug key '%s': %s", s.DebugKeyPath, err))
+		}
+	}
+}
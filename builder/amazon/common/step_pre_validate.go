@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	packerCommon "github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/retry"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -16,10 +17,12 @@ import (
 
 // StepPreValidate provides an opportunity to pre-validate any configuration for
 // the build before actually doing any time consuming work
-//
 type StepPreValidate struct {
-	DestAmiName        string
-	ForceDeregister    bool
+	DestAmiName     string
+	ForceDeregister bool
+	// OnConflict is what to do when an AMI named DestAmiName exists, when
+	// not forcing: one of the packer.Conflict* modes.
+	OnConflict         string
 	AMISkipBuildRegion bool
 	VpcId              string
 	SubnetId           string
@@ -112,10 +115,12 @@ func (s *StepPreValidate) Run(ctx context.Context, state multistep.StateBag) mul
 	}
 
 	if len(resp.Images) > 0 {
-		err := fmt.Errorf("Error: AMI Name: '%s' is used by an existing AMI: %s", *resp.Images[0].Name, *resp.Images[0].ImageId)
-		state.Put("error", err)
-		ui.Error(err.Error())
-		return multistep.ActionHalt
+		image := fmt.Sprintf("'%s' (%s)", *resp.Images[0].Name, *resp.Images[0].ImageId)
+		action := packerCommon.HandleArtifactConflict(state, s.OnConflict, "AMI", image)
+		if err, ok := state.GetOk("error"); ok {
+			ui.Error(err.(error).Error())
+		}
+		return action
 	}
 
 	return multistep.ActionContinue
@@ -0,0 +1,107 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/common/guest"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/masterzen/winrm"
+)
+
+// The values of windows_launch_prepare.
+const (
+	WindowsLaunchInitialize = "initialize"
+	WindowsLaunchSysprep    = "sysprep"
+)
+
+// windowsLaunchScript prepares the launch agent found on the guest for the
+// action of windows_launch_prepare. The EC2Launch agents run sysprep until
+// it's done, EC2Config's answer file is passed to sysprep started in the
+// background.
+const windowsLaunchScript = `$ErrorActionPreference = 'Stop'
+$action = '%s'
+$ec2launchV2 = "$env:ProgramFiles\Amazon\EC2Launch\EC2Launch.exe"
+$ec2launch = "$env:ProgramData\Amazon\EC2-Windows\Launch\Scripts"
+$ec2config = "$env:ProgramFiles\Amazon\Ec2ConfigService"
+if (Test-Path $ec2launchV2) {
+  Write-Output 'Preparing EC2Launch v2'
+  if ($action -eq 'sysprep') { & $ec2launchV2 sysprep --clean --block } else { & $ec2launchV2 reset --block }
+  exit $LASTEXITCODE
+}
+if (Test-Path "$ec2launch\InitializeInstance.ps1") {
+  Write-Output 'Preparing EC2Launch'
+  if ($action -eq 'sysprep') { & "$ec2launch\SysprepInstance.ps1" -NoShutdown } else { & "$ec2launch\InitializeInstance.ps1" -Schedule }
+  exit 0
+}
+if (Test-Path "$ec2config\Settings\config.xml") {
+  Write-Output 'Preparing EC2Config'
+  $settings = "$ec2config\Settings\config.xml"
+  $xml = [xml](Get-Content $settings)
+  foreach ($plugin in $xml.Ec2ConfigurationSettings.Plugins.Plugin) {
+    if ($plugin.Name -eq 'Ec2SetPassword' -or $plugin.Name -eq 'Ec2HandleUserData') { $plugin.State = 'Enabled' }
+  }
+  $xml.Save($settings)
+  if ($action -eq 'sysprep') {
+    Remove-Item -Path "$env:SystemRoot\System32\Sysprep\Panther\setuperr.log" -ErrorAction SilentlyContinue
+    Start-Process -FilePath "$env:SystemRoot\System32\Sysprep\Sysprep.exe" -ArgumentList '/generalize', '/oobe', '/quiet', '/quit', "/unattend:$ec2config\sysprep2008.xml" | Out-Null
+  }
+  exit 0
+}
+Write-Output 'None of EC2Launch v2, EC2Launch or EC2Config is installed on the guest'
+exit 1`
+
+// StepPrepareWindowsLaunch prepares the launch agent of a Windows guest, as
+// configured with windows_launch_prepare, once provisioned: it schedules the
+// initialization of the instances launched from the AMI, and generalizes the
+// guest with sysprep when asked to, waiting for it to finish.
+type StepPrepareWindowsLaunch struct {
+	Prepare string
+	Guest   *guest.Config
+}
+
+func (s *StepPrepareWindowsLaunch) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Prepare == "" {
+		return multistep.ActionContinue
+	}
+
+	comm, ok := state.Get("communicator").(packer.Communicator)
+	if !ok {
+		return multistep.ActionContinue
+	}
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Preparing the launch agent of the guest for the instances of the AMI...")
+	if err := s.prepare(ctx, ui, comm); err != nil {
+		err = fmt.Errorf("Error preparing the launch agent of the guest: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	return multistep.ActionContinue
+}
+
+func (s *StepPrepareWindowsLaunch) prepare(ctx context.Context, ui packer.Ui, comm packer.Communicator) error {
+	cmd := &packer.RemoteCmd{Command: winrm.Powershell(fmt.Sprintf(windowsLaunchScript, s.Prepare))}
+	err := cmd.RunWithUi(ctx, comm, ui)
+	if err == nil && cmd.ExitStatus() != 0 {
+		err = fmt.Errorf("the command exited with status %d", cmd.ExitStatus())
+	}
+	if err != nil {
+		return err
+	}
+	if s.Prepare != WindowsLaunchSysprep {
+		return nil
+	}
+
+	ui.Message(fmt.Sprintf("Waiting up to %s for sysprep to finish...", s.Guest.SysprepTimeout))
+	if err := guest.WaitForSysprep(ctx, comm, s.Guest.SysprepTimeout); err != nil {
+		return err
+	}
+	ui.Message("The guest is generalized")
+	return nil
+}
+
+func (s *StepPrepareWindowsLaunch) Cleanup(state multistep.StateBag) {
+}
@@ -0,0 +1,49 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/common/guest"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestStepPrepareWindowsLaunch(t *testing.T) {
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	})
+	comm := new(packer.MockCommunicator)
+	state.Put("communicator", comm)
+
+	step := &StepPrepareWindowsLaunch{Guest: &guest.Config{SysprepTimeout: time.Minute}}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if comm.StartCalled {
+		t.Fatal("the launch agent was prepared without windows_launch_prepare")
+	}
+
+	step.Prepare = WindowsLaunchInitialize
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v: %v", action, state.Get("error"))
+	}
+	if !strings.HasPrefix(comm.StartCmd.Command, "powershell.exe -EncodedCommand ") {
+		t.Fatalf("bad command: %s", comm.StartCmd.Command)
+	}
+
+	// The guest has no launch agent.
+	step.Prepare = WindowsLaunchSysprep
+	comm.StartExitStatus = 1
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if err := state.Get("error").(error); !strings.Contains(err.Error(), "status 1") {
+		t.Fatalf("bad error: %s", err)
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/url"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ec2"
 
+	packerCommon "github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/retry"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -26,10 +29,12 @@ type StepRunSourceInstance struct {
 	Debug                             bool
 	EbsOptimized                      bool
 	EnableT2Unlimited                 bool
+	EnclaveOptions                    EnclaveOptions
 	ExpectedRootDevice                string
 	InstanceInitiatedShutdownBehavior string
 	InstanceType                      string
 	IsRestricted                      bool
+	MetadataOptions                   MetadataOptions
 	SourceAMI                         string
 	Tags                              map[string]string
 	UserData                          string
@@ -104,10 +109,16 @@ func (s *StepRunSourceInstance) Run(ctx context.Context, state multistep.StateBa
 		return multistep.ActionHalt
 	}
 
+	// The instance types selected from the instance requirements are tried
+	// in order, when EC2 has no capacity for the previous ones.
+	instanceTypes := []string{s.InstanceType}
+	if s.InstanceType == "" {
+		instanceTypes, _ = state.Get("instance_types").([]string)
+	}
+
 	az := state.Get("availability_zone").(string)
 	runOpts := &ec2.RunInstancesInput{
 		ImageId:             &s.SourceAMI,
-		InstanceType:        &s.InstanceType,
 		UserData:            &userData,
 		MaxCount:            aws.Int64(1),
 		MinCount:            aws.Int64(1),
@@ -141,6 +152,15 @@ func (s *StepRunSourceInstance) Run(ctx context.Context, state multistep.StateBa
 		runOpts.CreditSpecification = &ec2.CreditSpecificationRequest{CpuCredits: &creditOption}
 	}
 
+	if !s.MetadataOptions.Empty() {
+		runOpts.MetadataOptions = s.MetadataOptions.instanceMetadataOptionsRequest()
+	}
+
+	var requestOptions []request.Option
+	if s.EnclaveOptions.Enabled {
+		requestOptions = append(requestOptions, withEnclaveOptions)
+	}
+
 	// Collect tags for tagging on resource creation
 	var tagSpecs []*ec2.TagSpecification
 
@@ -195,19 +215,27 @@ func (s *StepRunSourceInstance) Run(ctx context.Context, state multistep.StateBa
 	}
 
 	var runResp *ec2.Reservation
-	err = retry.Config{
-		Tries: 11,
-		ShouldRetry: func(err error) bool {
-			if IsAWSErr(err, "InvalidParameterValue", "iamInstanceProfile") {
-				return true
-			}
-			return false
-		},
-		RetryDelay: (&retry.Backoff{InitialBackoff: 200 * time.Millisecond, MaxBackoff: 30 * time.Second, Multiplier: 2}).Linear,
-	}.Run(ctx, func(ctx context.Context) error {
-		runResp, err = ec2conn.RunInstances(runOpts)
-		return err
-	})
+	for i, instanceType := range instanceTypes {
+		runOpts.InstanceType = aws.String(instanceType)
+		err = retry.Config{
+			Tries: 11,
+			ShouldRetry: func(err error) bool {
+				if IsAWSErr(err, "InvalidParameterValue", "iamInstanceProfile") {
+					return true
+				}
+				return false
+			},
+			RetryDelay: (&retry.Backoff{InitialBackoff: 200 * time.Millisecond, MaxBackoff: 30 * time.Second, Multiplier: 2}).Linear,
+		}.Run(ctx, func(ctx context.Context) error {
+			runResp, err = ec2conn.RunInstancesWithContext(ctx, runOpts, requestOptions...)
+			return err
+		})
+		if i < len(instanceTypes)-1 && IsAWSErr(err, "InsufficientInstanceCapacity", "") {
+			ui.Message(fmt.Sprintf("No capacity for the %s instance type, trying the next one...", instanceType))
+			continue
+		}
+		break
+	}
 
 	if IsAWSErr(err, "VPCIdNotSpecified", "No default VPC for this user") && subnetId == "" {
 		err := fmt.Errorf("Error launching source instance: a valid Subnet Id was not specified")
@@ -226,6 +254,8 @@ func (s *StepRunSourceInstance) Run(ctx context.Context, state multistep.StateBa
 
 	// Set the instance ID so that the cleanup works properly
 	s.instanceId = instanceId
+	TrackResource(ec2conn, CleanupInstance, instanceId)
+	packerCommon.JournalSet(state, journalInstanceID, instanceId)
 
 	ui.Message(fmt.Sprintf("Instance ID: %s", instanceId))
 	ui.Say(fmt.Sprintf("Waiting for instance (%v) to become ready...", instanceId))
@@ -284,6 +314,7 @@ func (s *StepRunSourceInstance) Run(ctx context.Context, state multistep.StateBa
 	// instance_id is the generic term used so that users can have access to the
 	// instance id inside of the provisioners, used in step_provision.
 	state.Put("instance_id", instance.InstanceId)
+	meterInstance(ctx, state, ec2conn, instance)
 
 	// If we're in a region that doesn't support tagging on instance creation,
 	// do that now.
@@ -351,21 +382,73 @@ func (s *StepRunSourceInstance) Run(ctx context.Context, state multistep.StateBa
 	return multistep.ActionContinue
 }
 
-func (s *StepRunSourceInstance) Cleanup(state multistep.StateBag) {
+// Resume re-attaches to the source instance of the resumed build, when it is
+// still running.
+func (s *StepRunSourceInstance) Resume(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	instanceId := packerCommon.JournalGet(state, journalInstanceID)
+	if instanceId == "" {
+		return s.Run(ctx, state)
+	}
+
+	ec2conn := state.Get("ec2").(*ec2.EC2)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say(fmt.Sprintf("Resuming with source instance: %s", instanceId))
+	s.instanceId = instanceId
+	adoptResource(ec2conn, CleanupInstance, instanceId)
+
+	instance, err := resumeInstance(ctx, ec2conn, instanceId)
+	if err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	state.Put("instance", instance)
+	state.Put("instance_id", instance.InstanceId)
+	meterInstance(ctx, state, ec2conn, instance)
+	return multistep.ActionContinue
+}
 
+func (s *StepRunSourceInstance) Cleanup(state multistep.StateBag) {
+	ctx := multistep.CleanupContext(state)
 	ec2conn := state.Get("ec2").(*ec2.EC2)
 	ui := state.Get("ui").(packer.Ui)
 
 	// Terminate the source instance if it exists
 	if s.instanceId != "" {
 		ui.Say("Terminating the source AWS instance...")
-		if _, err := ec2conn.TerminateInstances(&ec2.TerminateInstancesInput{InstanceIds: []*string{&s.instanceId}}); err != nil {
+		if _, err := ec2conn.TerminateInstancesWithContext(ctx, &ec2.TerminateInstancesInput{InstanceIds: []*string{&s.instanceId}}); err != nil {
 			ui.Error(fmt.Sprintf("Error terminating instance, may still be around: %s", err))
 			return
 		}
+		UntrackResource(CleanupInstance, s.instanceId)
 
-		if err := WaitUntilInstanceTerminated(aws.BackgroundContext(), ec2conn, s.instanceId); err != nil {
+		if err := WaitUntilInstanceTerminated(ctx, ec2conn, s.instanceId); err != nil {
 			ui.Error(err.Error())
 		}
+		unmeterInstance(state, s.instanceId)
 	}
 }
+
+// withEnclaveOptions enables Nitro Enclaves on the instance run by the
+// request, adding the EnclaveOptions parameter the SDK doesn't know about to
+// the built query.
+func withEnclaveOptions(r *request.Request) {
+	r.Handlers.Build.PushBack(func(r *request.Request) {
+		if r.Error != nil {
+			return
+		}
+		body, err := ioutil.ReadAll(r.GetBody())
+		if err != nil {
+			r.Error = err
+			return
+		}
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			r.Error = err
+			return
+		}
+		values.Set("EnclaveOptions.Enabled", "true")
+		r.SetStringBody(values.Encode())
+	})
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestWithEnclaveOptions(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+	}))
+	ec2conn := ec2.New(sess)
+
+	req, _ := ec2conn.RunInstancesRequest(&ec2.RunInstancesInput{
+		ImageId:      aws.String("ami-12345678"),
+		InstanceType: aws.String("m5.xlarge"),
+		MaxCount:     aws.Int64(1),
+		MinCount:     aws.Int64(1),
+	})
+	req.ApplyOptions(withEnclaveOptions)
+	if err := req.Build(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	body, err := ioutil.ReadAll(req.GetBody())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if values.Get("EnclaveOptions.Enabled") != "true" {
+		t.Fatalf("should enable enclaves: %s", body)
+	}
+	if values.Get("Action") != "RunInstances" || values.Get("InstanceType") != "m5.xlarge" {
+		t.Fatalf("should keep the other parameters: %s", body)
+	}
+}
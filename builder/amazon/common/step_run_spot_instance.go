@@ -10,6 +10,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	packerCommon "github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/random"
 	"github.com/hashicorp/packer/common/retry"
 	"github.com/hashicorp/packer/helper/communicator"
@@ -18,6 +19,10 @@ import (
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
+// spotRetryInterval is how often the spot capacity is requested again, until
+// the spot fallback timeout.
+var spotRetryInterval = 30 * time.Second
+
 type EC2BlockDeviceMappingsBuilder interface {
 	BuildEC2BlockDeviceMappings() []*ec2.BlockDeviceMapping
 }
@@ -32,10 +37,15 @@ type StepRunSpotInstance struct {
 	ExpectedRootDevice                string
 	InstanceInitiatedShutdownBehavior string
 	InstanceType                      string
+	MetadataOptions                   MetadataOptions
 	SourceAMI                         string
 	SpotPrice                         string
 	SpotTags                          map[string]string
 	SpotInstanceTypes                 []string
+	SpotAllocationStrategy            string
+	SpotAvailabilityZones             []string
+	SpotFallbackTimeout               time.Duration
+	OnDemandMaxPrice                  string
 	Tags                              map[string]string
 	VolumeTags                        map[string]string
 	UserData                          string
@@ -96,10 +106,13 @@ func (s *StepRunSpotInstance) CreateTemplateData(userData *string, az string,
 		IamInstanceProfile:    &ec2.LaunchTemplateIamInstanceProfileSpecificationRequest{Name: iamInstanceProfile},
 		ImageId:               &s.SourceAMI,
 		InstanceMarketOptions: marketOptions,
-		Placement: &ec2.LaunchTemplatePlacementRequest{
+		UserData:              userData,
+	}
+	// The fleet picks the availability zone when there are several.
+	if az != "" {
+		templateData.Placement = &ec2.LaunchTemplatePlacementRequest{
 			AvailabilityZone: &az,
-		},
-		UserData: userData,
+		}
 	}
 	// Create a network interface
 	securityGroupIds := aws.StringSlice(state.Get("securityGroupIds").([]string))
@@ -132,6 +145,10 @@ func (s *StepRunSpotInstance) CreateTemplateData(userData *string, az string,
 		templateData.SetKeyName(s.Comm.SSHKeyPairName)
 	}
 
+	if !s.MetadataOptions.Empty() {
+		templateData.SetMetadataOptions(s.MetadataOptions.launchTemplateMetadataOptionsRequest())
+	}
+
 	return &templateData
 }
 
@@ -217,6 +234,14 @@ func (s *StepRunSpotInstance) Run(ctx context.Context, state multistep.StateBag)
 		SpotOptions: &spotOptions,
 	}
 	marketOptions.SetMarketType(ec2.MarketTypeSpot)
+	if s.SpotFallbackTimeout > 0 {
+		// The same launch template runs the on-demand instance, so the spot
+		// market options are set on the fleet instead.
+		marketOptions = nil
+	}
+	if len(s.SpotAvailabilityZones) > 0 {
+		az = ""
+	}
 
 	// Create a launch template for the instance
 	ui.Message("Loading User Data File...")
@@ -249,69 +274,47 @@ func (s *StepRunSpotInstance) Run(ctx context.Context, state multistep.StateBag)
 		ui.Error(err.Error())
 		return multistep.ActionHalt
 	}
+	TrackResource(ec2conn, CleanupLaunchTemplate, launchTemplateName)
+	packerCommon.JournalSet(state, journalLaunchTemplate, launchTemplateName)
 
-	// Add overrides for each user-provided instance type
-	var overrides []*ec2.FleetLaunchTemplateOverridesRequest
-	for _, instanceType := range s.SpotInstanceTypes {
-		override := ec2.FleetLaunchTemplateOverridesRequest{
-			InstanceType: aws.String(instanceType),
-		}
-		overrides = append(overrides, &override)
+	// Use the user-provided instance types, or the instance types selected
+	// from the instance requirements
+	spotInstanceTypes := s.SpotInstanceTypes
+	if s.InstanceType == "" && len(spotInstanceTypes) == 0 {
+		spotInstanceTypes, _ = state.Get("instance_types").([]string)
 	}
 
-	createFleetInput := &ec2.CreateFleetInput{
-		LaunchTemplateConfigs: []*ec2.FleetLaunchTemplateConfigRequest{
-			{
-				LaunchTemplateSpecification: &ec2.FleetLaunchTemplateSpecificationRequest{
-					LaunchTemplateName: aws.String(launchTemplateName),
-					Version:            aws.String("1"),
-				},
-				Overrides: overrides,
-			},
-		},
-		ReplaceUnhealthyInstances: aws.Bool(false),
-		TargetCapacitySpecification: &ec2.TargetCapacitySpecificationRequest{
-			TotalTargetCapacity:       aws.Int64(1),
-			DefaultTargetCapacityType: aws.String("spot"),
-		},
-		Type: aws.String("instant"),
+	deadline := time.Now().Add(s.SpotFallbackTimeout)
+	for {
+		instanceId, err = s.createFleet(ec2conn, ui, launchTemplateName, ec2.DefaultTargetCapacityTypeSpot,
+			s.fleetOverrides(spotInstanceTypes, true))
+		if err == nil || s.SpotFallbackTimeout == 0 || time.Now().After(deadline) {
+			break
+		}
+		ui.Message(fmt.Sprintf("%s, retrying in %s...", err, spotRetryInterval))
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(spotRetryInterval):
+			continue
+		}
+		break
+	}
+	if err != nil && s.SpotFallbackTimeout > 0 && ctx.Err() == nil {
+		ui.Say(fmt.Sprintf("No spot capacity within %s, falling back to an on-demand instance...", s.SpotFallbackTimeout))
+		instanceId, err = s.createFleet(ec2conn, ui, launchTemplateName, ec2.DefaultTargetCapacityTypeOnDemand,
+			s.fleetOverrides(spotInstanceTypes, false))
 	}
-
-	// Create the request for the spot instance.
-	req, createOutput := ec2conn.CreateFleetRequest(createFleetInput)
-	ui.Message(fmt.Sprintf("Sending spot request (%s)...", req.RequestID))
-	// Actually send the spot connection request.
-	err = req.Send()
 	if err != nil {
-		if createOutput.FleetId != nil {
-			err = fmt.Errorf("Error waiting for fleet request (%s): %s", *createOutput.FleetId, err)
-		} else {
-			err = fmt.Errorf("Error waiting for fleet request: %s", err)
-		}
 		state.Put("error", err)
 		ui.Error(err.Error())
 		return multistep.ActionHalt
 	}
 
-	if len(createOutput.Instances) == 0 {
-		// We can end up with errors because one of the allowed availability
-		// zones doesn't have one of the allowed instance types; as long as
-		// an instance is launched, these errors aren't important.
-		if len(createOutput.Errors) > 0 {
-			errString := fmt.Sprintf("Error waiting for fleet request (%s) to become ready:", *createOutput.FleetId)
-			for _, outErr := range createOutput.Errors {
-				errString = errString + aws.StringValue(outErr.ErrorMessage)
-			}
-			err = fmt.Errorf(errString)
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		}
-	}
-
-	instanceId = *createOutput.Instances[0].InstanceIds[0]
 	// Set the instance ID so that the cleanup works properly
 	s.instanceId = instanceId
+	TrackResource(ec2conn, CleanupInstance, instanceId)
+	packerCommon.JournalSet(state, journalInstanceID, instanceId)
 
 	ui.Message(fmt.Sprintf("Instance ID: %s", instanceId))
 
@@ -354,6 +357,10 @@ func (s *StepRunSpotInstance) Run(ctx context.Context, state multistep.StateBag)
 		// Use the instance ID to find out the SIR, so that we can tag the spot
 		// request associated with this instance.
 		sir := describeOutput.Reservations[0].Instances[0].SpotInstanceRequestId
+		if sir == nil {
+			// The instance is the on-demand fallback.
+			spotTags = nil
+		}
 
 		// Apply tags to the spot request.
 		err = retry.Config{
@@ -449,11 +456,139 @@ func (s *StepRunSpotInstance) Run(ctx context.Context, state multistep.StateBag)
 	// instance_id is the generic term used so that users can have access to the
 	// instance id inside of the provisioners, used in step_provision.
 	state.Put("instance_id", instance.InstanceId)
+	meterInstance(ctx, state, ec2conn, instance)
 
 	return multistep.ActionContinue
 }
 
+// fleetOverrides returns the overrides of the fleet for every instance type
+// and availability zone. The spot price caps the price of each one, in the
+// fleet, when spot is set and the template has no spot market options.
+func (s *StepRunSpotInstance) fleetOverrides(instanceTypes []string, spot bool) []*ec2.FleetLaunchTemplateOverridesRequest {
+	var maxPrice *string
+	if spot && s.SpotFallbackTimeout > 0 && s.SpotPrice != "auto" {
+		maxPrice = aws.String(s.SpotPrice)
+	}
+	azs := s.SpotAvailabilityZones
+	if len(azs) == 0 {
+		azs = []string{""}
+	}
+	if len(instanceTypes) == 0 {
+		instanceTypes = []string{""}
+	}
+
+	var overrides []*ec2.FleetLaunchTemplateOverridesRequest
+	for _, instanceType := range instanceTypes {
+		for _, az := range azs {
+			override := &ec2.FleetLaunchTemplateOverridesRequest{MaxPrice: maxPrice}
+			if instanceType != "" {
+				override.InstanceType = aws.String(instanceType)
+			}
+			if az != "" {
+				override.AvailabilityZone = aws.String(az)
+			}
+			overrides = append(overrides, override)
+		}
+	}
+	if len(overrides) == 1 && *overrides[0] == (ec2.FleetLaunchTemplateOverridesRequest{}) {
+		return nil
+	}
+	return overrides
+}
+
+// createFleet runs an instance from the launch template with an instant
+// fleet of capacityType, spot or on-demand, and returns its ID.
+func (s *StepRunSpotInstance) createFleet(ec2conn *ec2.EC2, ui packer.Ui, launchTemplateName, capacityType string,
+	overrides []*ec2.FleetLaunchTemplateOverridesRequest) (string, error) {
+	createFleetInput := &ec2.CreateFleetInput{
+		LaunchTemplateConfigs: []*ec2.FleetLaunchTemplateConfigRequest{
+			{
+				LaunchTemplateSpecification: &ec2.FleetLaunchTemplateSpecificationRequest{
+					LaunchTemplateName: aws.String(launchTemplateName),
+					Version:            aws.String("1"),
+				},
+				Overrides: overrides,
+			},
+		},
+		ReplaceUnhealthyInstances: aws.Bool(false),
+		TargetCapacitySpecification: &ec2.TargetCapacitySpecificationRequest{
+			TotalTargetCapacity:       aws.Int64(1),
+			DefaultTargetCapacityType: aws.String(capacityType),
+		},
+		Type: aws.String("instant"),
+	}
+	switch capacityType {
+	case ec2.DefaultTargetCapacityTypeSpot:
+		if s.SpotAllocationStrategy != "" {
+			createFleetInput.SpotOptions = &ec2.SpotOptionsRequest{
+				AllocationStrategy: aws.String(s.SpotAllocationStrategy),
+			}
+		}
+	case ec2.DefaultTargetCapacityTypeOnDemand:
+		createFleetInput.OnDemandOptions = &ec2.OnDemandOptionsRequest{
+			AllocationStrategy: aws.String(ec2.FleetOnDemandAllocationStrategyLowestPrice),
+		}
+		if s.OnDemandMaxPrice != "" {
+			createFleetInput.OnDemandOptions.MaxTotalPrice = aws.String(s.OnDemandMaxPrice)
+		}
+	}
+
+	// Create the request for the instance.
+	req, createOutput := ec2conn.CreateFleetRequest(createFleetInput)
+	ui.Message(fmt.Sprintf("Sending %s request (%s)...", capacityType, req.RequestID))
+	// Actually send the request.
+	if err := req.Send(); err != nil {
+		if createOutput.FleetId != nil {
+			return "", fmt.Errorf("Error waiting for fleet request (%s): %s", *createOutput.FleetId, err)
+		}
+		return "", fmt.Errorf("Error waiting for fleet request: %s", err)
+	}
+
+	if len(createOutput.Instances) == 0 || len(createOutput.Instances[0].InstanceIds) == 0 {
+		// We can end up with errors because one of the allowed availability
+		// zones doesn't have one of the allowed instance types; as long as
+		// an instance is launched, these errors aren't important.
+		errString := fmt.Sprintf("Error waiting for fleet request (%s) to become ready:", aws.StringValue(createOutput.FleetId))
+		for _, outErr := range createOutput.Errors {
+			errString = errString + aws.StringValue(outErr.ErrorMessage)
+		}
+		return "", fmt.Errorf(errString)
+	}
+	return aws.StringValue(createOutput.Instances[0].InstanceIds[0]), nil
+}
+
+// Resume re-attaches to the spot instance of the resumed build, when it is
+// still running.
+func (s *StepRunSpotInstance) Resume(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	launchTemplateName := packerCommon.JournalGet(state, journalLaunchTemplate)
+	instanceId := packerCommon.JournalGet(state, journalInstanceID)
+	if launchTemplateName == "" || instanceId == "" {
+		return s.Run(ctx, state)
+	}
+
+	ec2conn := state.Get("ec2").(*ec2.EC2)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say(fmt.Sprintf("Resuming with spot instance: %s", instanceId))
+	state.Put("launchTemplateName", launchTemplateName) // For the cleanup step
+	adoptResource(ec2conn, CleanupLaunchTemplate, launchTemplateName)
+	s.instanceId = instanceId
+	adoptResource(ec2conn, CleanupInstance, instanceId)
+
+	instance, err := resumeInstance(ctx, ec2conn, instanceId)
+	if err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	state.Put("instance", instance)
+	state.Put("instance_id", instance.InstanceId)
+	meterInstance(ctx, state, ec2conn, instance)
+	return multistep.ActionContinue
+}
+
 func (s *StepRunSpotInstance) Cleanup(state multistep.StateBag) {
+	ctx := multistep.CleanupContext(state)
 	ec2conn := state.Get("ec2").(*ec2.EC2)
 	ui := state.Get("ui").(packer.Ui)
 	launchTemplateName := state.Get("launchTemplateName").(string)
@@ -461,21 +596,25 @@ func (s *StepRunSpotInstance) Cleanup(state multistep.StateBag) {
 	// Terminate the source instance if it exists
 	if s.instanceId != "" {
 		ui.Say("Terminating the source AWS instance...")
-		if _, err := ec2conn.TerminateInstances(&ec2.TerminateInstancesInput{InstanceIds: []*string{&s.instanceId}}); err != nil {
+		if _, err := ec2conn.TerminateInstancesWithContext(ctx, &ec2.TerminateInstancesInput{InstanceIds: []*string{&s.instanceId}}); err != nil {
 			ui.Error(fmt.Sprintf("Error terminating instance, may still be around: %s", err))
 			return
 		}
+		UntrackResource(CleanupInstance, s.instanceId)
 
-		if err := WaitUntilInstanceTerminated(aws.BackgroundContext(), ec2conn, s.instanceId); err != nil {
+		if err := WaitUntilInstanceTerminated(ctx, ec2conn, s.instanceId); err != nil {
 			ui.Error(err.Error())
 		}
+		unmeterInstance(state, s.instanceId)
 	}
 
 	// Delete the launch template used to create the spot fleet
 	deleteInput := &ec2.DeleteLaunchTemplateInput{
 		LaunchTemplateName: aws.String(launchTemplateName),
 	}
-	if _, err := ec2conn.DeleteLaunchTemplate(deleteInput); err != nil {
+	if _, err := ec2conn.DeleteLaunchTemplateWithContext(ctx, deleteInput); err != nil {
 		ui.Error(err.Error())
+		return
 	}
+	UntrackResource(CleanupLaunchTemplate, launchTemplateName)
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -133,3 +134,40 @@ func TestCreateTemplateData_NoEphemeral(t *testing.T) {
 	// 	t.Fatalf("Should have created 26 mappings to keep ephemeral drives from appearing.")
 	// }
 }
+
+func TestCreateTemplateData_NoAvailabilityZone(t *testing.T) {
+	state := tStateSpot()
+	stepRunSpotInstance := getBasicStep()
+	template := stepRunSpotInstance.CreateTemplateData(aws.String("userdata"), "", state, nil)
+	if template.Placement != nil {
+		t.Fatalf("Template shouldn't contain a placement without availability zone: %#v", template.Placement)
+	}
+}
+
+func TestFleetOverrides(t *testing.T) {
+	stepRunSpotInstance := getBasicStep()
+	if overrides := stepRunSpotInstance.fleetOverrides(nil, true); overrides != nil {
+		t.Fatalf("Should not override the launch template: %#v", overrides)
+	}
+
+	stepRunSpotInstance.SpotPrice = "0.05"
+	stepRunSpotInstance.SpotFallbackTimeout = time.Minute
+	stepRunSpotInstance.SpotAvailabilityZones = []string{"us-east-1a", "us-east-1b"}
+	overrides := stepRunSpotInstance.fleetOverrides([]string{"m5.large", "c5.large"}, true)
+	if len(overrides) != 4 {
+		t.Fatalf("Should override every instance type in every availability zone: %#v", overrides)
+	}
+	for _, override := range overrides {
+		if aws.StringValue(override.MaxPrice) != "0.05" {
+			t.Fatalf("Should cap the spot price: %#v", override)
+		}
+	}
+	if *overrides[1].InstanceType != "m5.large" || *overrides[1].AvailabilityZone != "us-east-1b" {
+		t.Fatalf("bad override: %#v", overrides[1])
+	}
+
+	overrides = stepRunSpotInstance.fleetOverrides([]string{"m5.large"}, false)
+	if len(overrides) != 2 || overrides[0].MaxPrice != nil {
+		t.Fatalf("Should not cap the on-demand price in the overrides: %#v", overrides)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	packerCommon "github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -96,6 +97,8 @@ func (s *StepSecurityGroup) Run(ctx context.Context, state multistep.StateBag) m
 
 	// Set the group ID so we can delete it later
 	s.createdGroupId = *groupResp.GroupId
+	TrackResource(ec2conn, CleanupSecurityGroup, s.createdGroupId)
+	packerCommon.JournalSet(state, journalSecurityGroup, s.createdGroupId)
 
 	// Wait for the security group become available for authorizing
 	log.Printf("[DEBUG] Waiting for temporary security group: %s", s.createdGroupId)
@@ -159,6 +162,24 @@ func (s *StepSecurityGroup) Run(ctx context.Context, state multistep.StateBag) m
 	return multistep.ActionContinue
 }
 
+// Resume uses the temporary security group of the resumed build again.
+func (s *StepSecurityGroup) Resume(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	groupId := packerCommon.JournalGet(state, journalSecurityGroup)
+	if groupId == "" {
+		return s.Run(ctx, state)
+	}
+
+	ec2conn := state.Get("ec2").(*ec2.EC2)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say(fmt.Sprintf("Resuming with temporary security group: %s", groupId))
+	s.createdGroupId = groupId
+	adoptResource(ec2conn, CleanupSecurityGroup, groupId)
+
+	state.Put("securityGroupIds", []string{groupId})
+	return multistep.ActionContinue
+}
+
 func (s *StepSecurityGroup) Cleanup(state multistep.StateBag) {
 	if s.createdGroupId == "" {
 		return
@@ -169,9 +190,10 @@ func (s *StepSecurityGroup) Cleanup(state multistep.StateBag) {
 
 	ui.Say("Deleting temporary security group...")
 
+	ctx := multistep.CleanupContext(state)
 	var err error
 	for i := 0; i < 5; i++ {
-		_, err = ec2conn.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{GroupId: &s.createdGroupId})
+		_, err = ec2conn.DeleteSecurityGroupWithContext(ctx, &ec2.DeleteSecurityGroupInput{GroupId: &s.createdGroupId})
 		if err == nil {
 			break
 		}
@@ -184,7 +206,9 @@ func (s *StepSecurityGroup) Cleanup(state multistep.StateBag) {
 		ui.Error(fmt.Sprintf(
 			"Error cleaning up security group. Please delete the group manually:"+
 				" err: %s; security group ID: %s", err, s.createdGroupId))
+		return
 	}
+	UntrackResource(CleanupSecurityGroup, s.createdGroupId)
 }
 
 func waitUntilSecurityGroupExists(c *ec2.EC2, input *ec2.DescribeSecurityGroupsInput) error {
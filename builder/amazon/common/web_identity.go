@@ -0,0 +1,63 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/hashicorp/packer/common/oidc"
+)
+
+// defaultWebIdentityAudience is the audience of the OIDC tokens the roles are
+// assumed with.
+const defaultWebIdentityAudience = "sts.amazonaws.com"
+
+// webIdentityProviderName is the name of the provider of the credentials of
+// the roles assumed with OIDC tokens.
+const webIdentityProviderName = "WebIdentityProvider"
+
+// webIdentityExpiryWindow is how long before they expire the credentials of
+// the role are refreshed.
+const webIdentityExpiryWindow = 5 * time.Minute
+
+// webIdentityProvider provides the credentials of a role assumed with the
+// OIDC token of the CI job, and assumes the role again when they are about
+// to expire.
+type webIdentityProvider struct {
+	credentials.Expiry
+
+	client      stsiface.STSAPI
+	roleARN     string
+	sessionName string
+	tokens      *oidc.TokenSource
+}
+
+func (p *webIdentityProvider) Retrieve() (credentials.Value, error) {
+	token, err := p.tokens.Token(context.TODO())
+	if err != nil {
+		return credentials.Value{ProviderName: webIdentityProviderName}, err
+	}
+
+	resp, err := p.client.AssumeRoleWithWebIdentity(&sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(p.roleARN),
+		RoleSessionName:  aws.String(p.sessionName),
+		WebIdentityToken: aws.String(token),
+	})
+	if err != nil {
+		return credentials.Value{ProviderName: webIdentityProviderName},
+			fmt.Errorf("Error assuming role %s with the OIDC token of the %s job: %s",
+				p.roleARN, p.tokens.Provider, err)
+	}
+
+	p.SetExpiration(aws.TimeValue(resp.Credentials.Expiration), webIdentityExpiryWindow)
+	return credentials.Value{
+		AccessKeyID:     aws.StringValue(resp.Credentials.AccessKeyId),
+		SecretAccessKey: aws.StringValue(resp.Credentials.SecretAccessKey),
+		SessionToken:    aws.StringValue(resp.Credentials.SessionToken),
+		ProviderName:    webIdentityProviderName,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/hashicorp/packer/common/oidc"
+)
+
+type mockSTSWebIdentity struct {
+	stsiface.STSAPI
+	input *sts.AssumeRoleWithWebIdentityInput
+}
+
+func (m *mockSTSWebIdentity) AssumeRoleWithWebIdentity(input *sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	m.input = input
+	return &sts.AssumeRoleWithWebIdentityOutput{
+		Credentials: &sts.Credentials{
+			AccessKeyId:     aws.String("AKID"),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("session"),
+			Expiration:      aws.Time(time.Now().Add(time.Hour)),
+		},
+	}, nil
+}
+
+func TestWebIdentityProvider(t *testing.T) {
+	os.Setenv("PACKER_TEST_OIDC_TOKEN", "oidc-token")
+	defer os.Unsetenv("PACKER_TEST_OIDC_TOKEN")
+
+	client := &mockSTSWebIdentity{}
+	p := &webIdentityProvider{
+		client:      client,
+		roleARN:     "arn:aws:iam::123456789012:role/packer",
+		sessionName: "packer",
+		tokens: &oidc.TokenSource{
+			Provider: oidc.ProviderGitLab,
+			TokenEnv: "PACKER_TEST_OIDC_TOKEN",
+		},
+	}
+	if !p.IsExpired() {
+		t.Fatal("credentials should be retrieved")
+	}
+
+	v, err := p.Retrieve()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if v.AccessKeyID != "AKID" || v.SecretAccessKey != "secret" || v.SessionToken != "session" {
+		t.Fatalf("bad: %#v", v)
+	}
+	if aws.StringValue(client.input.WebIdentityToken) != "oidc-token" ||
+		aws.StringValue(client.input.RoleArn) != p.roleARN {
+		t.Fatalf("bad input: %#v", client.input)
+	}
+	if p.IsExpired() {
+		t.Fatal("credentials should not be expired")
+	}
+}
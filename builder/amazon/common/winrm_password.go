@@ -0,0 +1,49 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/packer/common/retry"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// WinRMPasswordSource retrieves the password EC2 generated for the
+// Administrator of the instance.
+var WinRMPasswordSource = communicator.WinRMPasswordSource{
+	Get:       getPasswordData,
+	Encrypted: true,
+}
+
+func getPasswordData(ctx context.Context, state multistep.StateBag) (string, error) {
+	ec2conn := state.Get("ec2").(*ec2.EC2)
+	instance := state.Get("instance").(*ec2.Instance)
+
+	// Wrap in a retry so that we don't fail on rate-limiting.
+	var resp *ec2.GetPasswordDataOutput
+	err := retry.Config{
+		Tries:      11,
+		RetryDelay: (&retry.Backoff{InitialBackoff: 200 * time.Millisecond, MaxBackoff: 30 * time.Second, Multiplier: 2}).Linear,
+	}.Run(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = ec2conn.GetPasswordData(&ec2.GetPasswordDataInput{
+			InstanceId: instance.InstanceId,
+		})
+		if err != nil {
+			err := fmt.Errorf("Error retrieving auto-generated instance password: %s", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if resp.PasswordData == nil {
+		return "", nil
+	}
+	return *resp.PasswordData, nil
+}
@@ -18,6 +18,9 @@ import (
 	"github.com/hashicorp/packer/builder"
 	awscommon "github.com/hashicorp/packer/builder/amazon/common"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/cost"
+	"github.com/hashicorp/packer/common/guest"
+	"github.com/hashicorp/packer/common/preflight"
 	"github.com/hashicorp/packer/hcl2template"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -90,6 +93,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 		InterpolateFilter: &interpolate.RenderFilter{
 			Exclude: []string{
 				"ami_description",
+				"name_pattern",
 				"run_tags",
 				"run_volume_tags",
 				"spot_tags",
@@ -119,6 +123,11 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	errs = packer.MultiErrorAppend(errs, b.config.LaunchMappings.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.RunConfig.Prepare(&b.config.ctx)...)
 
+	// The billing tags of the build go on its temporary resources.
+	b.config.RunTags = b.config.WithBillingTags(b.config.RunTags)
+	b.config.SpotTags = b.config.WithBillingTags(b.config.SpotTags)
+	b.config.VolumeRunTags = b.config.WithBillingTags(b.config.VolumeRunTags)
+
 	if b.config.IsSpotInstance() && (b.config.AMIENASupport.True() || b.config.AMISriovNetSupport) {
 		errs = packer.MultiErrorAppend(errs,
 			fmt.Errorf("Spot instances do not support modification, which is required "+
@@ -144,6 +153,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return generatedData, warns, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"temporary EC2 instance, key pair and security group", "AMI and EBS snapshots"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 
 	session, err := b.config.Session()
@@ -152,6 +165,13 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	}
 
 	ec2conn := ec2.New(session)
+
+	// Suffix the AMI name as set with name_collision when it's taken, before
+	// the steps use it.
+	if err := b.config.AMIConfig.UniqueAMIName(ec2conn, ui); err != nil {
+		return nil, err
+	}
+
 	iam := iam.New(session)
 	// Setup the state bag and initial state for the steps
 	state := new(multistep.BasicStateBag)
@@ -163,6 +183,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	state.Put("awsSession", session)
 	state.Put("hook", hook)
 	state.Put("ui", ui)
+	state.Put("winrmPasswordSource", awscommon.WinRMPasswordSource)
 	generatedData := &builder.GeneratedData{State: state}
 
 	var instanceStep multistep.Step
@@ -179,11 +200,16 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			ExpectedRootDevice:                "ebs",
 			InstanceInitiatedShutdownBehavior: b.config.InstanceInitiatedShutdownBehavior,
 			InstanceType:                      b.config.InstanceType,
+			MetadataOptions:                   b.config.MetadataOptions,
 			SourceAMI:                         b.config.SourceAmi,
 			SpotPrice:                         b.config.SpotPrice,
 			SpotTags:                          b.config.SpotTags,
 			Tags:                              b.config.RunTags,
 			SpotInstanceTypes:                 b.config.SpotInstanceTypes,
+			SpotAllocationStrategy:            b.config.SpotAllocationStrategy,
+			SpotAvailabilityZones:             b.config.SpotAvailabilityZones,
+			SpotFallbackTimeout:               b.config.SpotFallbackTimeout,
+			OnDemandMaxPrice:                  b.config.OnDemandMaxPrice,
 			UserData:                          b.config.UserData,
 			UserDataFile:                      b.config.UserDataFile,
 			VolumeTags:                        b.config.VolumeRunTags,
@@ -198,10 +224,12 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Debug:                             b.config.PackerDebug,
 			EbsOptimized:                      b.config.EbsOptimized,
 			EnableT2Unlimited:                 b.config.EnableT2Unlimited,
+			EnclaveOptions:                    b.config.EnclaveOptions,
 			ExpectedRootDevice:                "ebs",
 			InstanceInitiatedShutdownBehavior: b.config.InstanceInitiatedShutdownBehavior,
 			InstanceType:                      b.config.InstanceType,
 			IsRestricted:                      b.config.IsChinaCloud() || b.config.IsGovCloud(),
+			MetadataOptions:                   b.config.MetadataOptions,
 			SourceAMI:                         b.config.SourceAmi,
 			Tags:                              b.config.RunTags,
 			UserData:                          b.config.UserData,
@@ -213,14 +241,22 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 
 	// Build the steps
 	steps := []multistep.Step{
+		&cost.StepMeter{Prices: awscommon.Prices},
 		&awscommon.StepPreValidate{
 			DestAmiName:        b.config.AMIName,
 			ForceDeregister:    b.config.AMIForceDeregister,
+			OnConflict:         b.config.OnConflict(),
 			AMISkipBuildRegion: b.config.AMISkipBuildRegion,
 			VpcId:              b.config.VpcId,
 			SubnetId:           b.config.SubnetId,
 			HasSubnetFilter:    !b.config.SubnetFilter.Empty(),
 		},
+		&preflight.StepPreflight{
+			Mode: b.config.PackerPreflight,
+			Permissions: append(append(b.config.RunConfig.PreflightActions(), b.config.AMIConfig.PreflightActions()...),
+				"ec2:CreateImage", "ec2:ModifyInstanceAttribute", "ec2:StopInstances"),
+			Check: awscommon.NewPreflightChecker(session),
+		},
 		&awscommon.StepSourceAMIInfo{
 			SourceAmi:                b.config.SourceAmi,
 			EnableAMISriovNetSupport: b.config.AMISriovNetSupport,
@@ -228,6 +264,10 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			AmiFilters:               b.config.SourceAmiFilter,
 			AMIVirtType:              b.config.AMIVirtType,
 		},
+		&awscommon.StepInstanceRequirements{
+			Requirements: b.config.InstanceRequirements,
+			Spot:         b.config.IsSpotInstance(),
+		},
 		&awscommon.StepNetworkInfo{
 			VpcId:               b.config.VpcId,
 			VpcFilter:           b.config.VpcFilter,
@@ -241,6 +281,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Debug:        b.config.PackerDebug,
 			Comm:         &b.config.RunConfig.Comm,
 			DebugKeyPath: fmt.Sprintf("ec2_%s.pem", b.config.PackerBuildName),
+			BuildName:    b.config.PackerBuildName,
 		},
 		&awscommon.StepSecurityGroup{
 			SecurityGroupFilter:    b.config.SecurityGroupFilter,
@@ -258,11 +299,10 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			LaunchMappings: b.config.LaunchMappings,
 		},
 		instanceStep,
-		&awscommon.StepGetPassword{
-			Debug:     b.config.PackerDebug,
-			Comm:      &b.config.RunConfig.Comm,
-			Timeout:   b.config.WindowsPasswordTimeout,
-			BuildName: b.config.PackerBuildName,
+		&communicator.StepGetWinRMPassword{
+			Debug:   b.config.PackerDebug,
+			Comm:    &b.config.RunConfig.Comm,
+			Timeout: b.config.WindowsPasswordTimeout,
 		},
 		&awscommon.StepCreateSSMTunnel{
 			AWSSession:       session,
@@ -284,9 +324,32 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			),
 			SSHConfig: b.config.RunConfig.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.RunConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.RunConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.RunConfig.Guest,
+		},
+		&awscommon.StepPrepareWindowsLaunch{
+			Prepare: b.config.WindowsLaunchPrepare,
+			Guest:   &b.config.RunConfig.Guest,
 		},
 		&awscommon.StepStopEBSBackedInstance{
 			Skip:                b.config.IsSpotInstance(),
@@ -336,6 +399,13 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	// Run!
 	b.runner = common.NewRunner(steps, b.config.PackerConfig, ui)
 	b.runner.Run(ctx, state)
+
+	// The artifact already exists, or the build only checked its
+	// permissions, and the build was skipped.
+	if common.Skipped(state) {
+		return nil, nil
+	}
+
 	// If there was an error, return that
 	if rawErr, ok := state.GetOk("error"); ok {
 		return nil, rawErr.(error)
@@ -148,3 +148,28 @@ func TestBuilderPrepare_ReturnGeneratedData(t *testing.T) {
 		t.Fatalf("Generated data should contain SourceAMIName")
 	}
 }
+
+func TestBuilderPrepare_BillingTags(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["run_tags"] = map[string]string{"team": "web", "env": "prod"}
+	config["packer_billing_tags"] = map[string]string{"team": "images"}
+
+	_, warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.RunTags["team"] != "images" || b.config.RunTags["env"] != "prod" {
+		t.Fatalf("unexpected run tags %#v", b.config.RunTags)
+	}
+	if b.config.VolumeRunTags["team"] != "images" || b.config.SpotTags["team"] != "images" {
+		t.Fatalf("unexpected volume and spot tags %#v %#v", b.config.VolumeRunTags, b.config.SpotTags)
+	}
+	if len(b.config.AMITags) > 0 {
+		t.Fatalf("the billing tags shouldn't go on the AMI: %#v", b.config.AMITags)
+	}
+}
@@ -14,6 +14,9 @@ import (
 	"github.com/hashicorp/hcl/v2/hcldec"
 	awscommon "github.com/hashicorp/packer/builder/amazon/common"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/cost"
+	"github.com/hashicorp/packer/common/guest"
+	"github.com/hashicorp/packer/common/preflight"
 	"github.com/hashicorp/packer/hcl2template"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -112,6 +115,11 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	errs = packer.MultiErrorAppend(errs, b.config.VolumeRunTag.CopyOn(&b.config.VolumeRunTags)...)
 	errs = packer.MultiErrorAppend(errs, b.config.AccessConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.RunConfig.Prepare(&b.config.ctx)...)
+
+	// The billing tags of the build go on its temporary resources.
+	b.config.RunTags = b.config.WithBillingTags(b.config.RunTags)
+	b.config.SpotTags = b.config.WithBillingTags(b.config.SpotTags)
+	b.config.VolumeRunTags = b.config.WithBillingTags(b.config.VolumeRunTags)
 	errs = packer.MultiErrorAppend(errs, b.config.launchBlockDevices.Prepare(&b.config.ctx)...)
 
 	for _, d := range b.config.VolumeMappings {
@@ -150,6 +158,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return generatedData, warns, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"temporary EC2 instance, key pair and security group", "EBS volumes"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	session, err := b.config.Session()
 	if err != nil {
@@ -166,6 +178,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	state.Put("iam", iam)
 	state.Put("hook", hook)
 	state.Put("ui", ui)
+	state.Put("winrmPasswordSource", awscommon.WinRMPasswordSource)
 
 	var instanceStep multistep.Step
 
@@ -181,8 +194,13 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			ExpectedRootDevice:                "ebs",
 			InstanceInitiatedShutdownBehavior: b.config.InstanceInitiatedShutdownBehavior,
 			InstanceType:                      b.config.InstanceType,
+			MetadataOptions:                   b.config.MetadataOptions,
 			SourceAMI:                         b.config.SourceAmi,
 			SpotInstanceTypes:                 b.config.SpotInstanceTypes,
+			SpotAllocationStrategy:            b.config.SpotAllocationStrategy,
+			SpotAvailabilityZones:             b.config.SpotAvailabilityZones,
+			SpotFallbackTimeout:               b.config.SpotFallbackTimeout,
+			OnDemandMaxPrice:                  b.config.OnDemandMaxPrice,
 			SpotPrice:                         b.config.SpotPrice,
 			SpotTags:                          b.config.SpotTags,
 			Tags:                              b.config.RunTags,
@@ -199,10 +217,12 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Debug:                             b.config.PackerDebug,
 			EbsOptimized:                      b.config.EbsOptimized,
 			EnableT2Unlimited:                 b.config.EnableT2Unlimited,
+			EnclaveOptions:                    b.config.EnclaveOptions,
 			ExpectedRootDevice:                "ebs",
 			InstanceInitiatedShutdownBehavior: b.config.InstanceInitiatedShutdownBehavior,
 			InstanceType:                      b.config.InstanceType,
 			IsRestricted:                      b.config.IsChinaCloud() || b.config.IsGovCloud(),
+			MetadataOptions:                   b.config.MetadataOptions,
 			SourceAMI:                         b.config.SourceAmi,
 			Tags:                              b.config.RunTags,
 			UserData:                          b.config.UserData,
@@ -213,12 +233,23 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 
 	// Build the steps
 	steps := []multistep.Step{
+		&cost.StepMeter{Prices: awscommon.Prices},
+		&preflight.StepPreflight{
+			Mode: b.config.PackerPreflight,
+			Permissions: append(b.config.RunConfig.PreflightActions(),
+				"ec2:ModifyInstanceAttribute", "ec2:StopInstances"),
+			Check: awscommon.NewPreflightChecker(session),
+		},
 		&awscommon.StepSourceAMIInfo{
 			SourceAmi:                b.config.SourceAmi,
 			EnableAMISriovNetSupport: b.config.AMISriovNetSupport,
 			EnableAMIENASupport:      b.config.AMIENASupport,
 			AmiFilters:               b.config.SourceAmiFilter,
 		},
+		&awscommon.StepInstanceRequirements{
+			Requirements: b.config.InstanceRequirements,
+			Spot:         b.config.IsSpotInstance(),
+		},
 		&awscommon.StepNetworkInfo{
 			VpcId:               b.config.VpcId,
 			VpcFilter:           b.config.VpcFilter,
@@ -232,6 +263,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Debug:        b.config.PackerDebug,
 			Comm:         &b.config.RunConfig.Comm,
 			DebugKeyPath: fmt.Sprintf("ec2_%s.pem", b.config.PackerBuildName),
+			BuildName:    b.config.PackerBuildName,
 		},
 		&awscommon.StepSecurityGroup{
 			SecurityGroupFilter:    b.config.SecurityGroupFilter,
@@ -250,11 +282,10 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			VolumeMapping: b.config.VolumeMappings,
 			Ctx:           b.config.ctx,
 		},
-		&awscommon.StepGetPassword{
-			Debug:     b.config.PackerDebug,
-			Comm:      &b.config.RunConfig.Comm,
-			Timeout:   b.config.WindowsPasswordTimeout,
-			BuildName: b.config.PackerBuildName,
+		&communicator.StepGetWinRMPassword{
+			Debug:   b.config.PackerDebug,
+			Comm:    &b.config.RunConfig.Comm,
+			Timeout: b.config.WindowsPasswordTimeout,
 		},
 		&awscommon.StepCreateSSMTunnel{
 			AWSSession:       session,
@@ -276,9 +307,32 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			),
 			SSHConfig: b.config.RunConfig.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.RunConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.RunConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.RunConfig.Guest,
+		},
+		&awscommon.StepPrepareWindowsLaunch{
+			Prepare: b.config.WindowsLaunchPrepare,
+			Guest:   &b.config.RunConfig.Guest,
 		},
 		&awscommon.StepStopEBSBackedInstance{
 			Skip:                b.config.IsSpotInstance(),
@@ -294,6 +348,11 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	b.runner = common.NewRunner(steps, b.config.PackerConfig, ui)
 	b.runner.Run(ctx, state)
 
+	// The build only checked its permissions and was skipped.
+	if common.Skipped(state) {
+		return nil, nil
+	}
+
 	// If there was an error, return that
 	if rawErr, ok := state.GetOk("error"); ok {
 		return nil, rawErr.(error)
@@ -59,15 +59,28 @@ type FlatConfig struct {
 	PackerBuildName                           *string                                `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
 	PackerBuilderType                         *string                                `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
 	PackerDebug                               *bool                                  `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerBreakpoints                         []string                               `mapstructure:"packer_breakpoints" cty:"packer_breakpoints" hcl:"packer_breakpoints"`
 	PackerForce                               *bool                                  `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
 	PackerOnError                             *string                                `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerOnConflict                          *string                                `mapstructure:"packer_on_conflict" cty:"packer_on_conflict" hcl:"packer_on_conflict"`
+	PackerJournal                             *string                                `mapstructure:"packer_journal" cty:"packer_journal" hcl:"packer_journal"`
+	PackerResume                              *bool                                  `mapstructure:"packer_resume" cty:"packer_resume" hcl:"packer_resume"`
+	PackerBillingTags                         map[string]string                      `mapstructure:"packer_billing_tags" cty:"packer_billing_tags" hcl:"packer_billing_tags"`
+	PackerPreflight                           *string                                `mapstructure:"packer_preflight" cty:"packer_preflight" hcl:"packer_preflight"`
 	PackerUserVars                            map[string]string                      `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
 	PackerSensitiveVars                       []string                               `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
 	AccessKey                                 *string                                `mapstructure:"access_key" required:"true" cty:"access_key" hcl:"access_key"`
+	CredentialHelper                          []string                               `mapstructure:"credential_helper" required:"false" cty:"credential_helper" hcl:"credential_helper"`
 	CustomEndpointEc2                         *string                                `mapstructure:"custom_endpoint_ec2" required:"false" cty:"custom_endpoint_ec2" hcl:"custom_endpoint_ec2"`
 	DecodeAuthZMessages                       *bool                                  `mapstructure:"decode_authorization_messages" required:"false" cty:"decode_authorization_messages" hcl:"decode_authorization_messages"`
 	InsecureSkipTLSVerify                     *bool                                  `mapstructure:"insecure_skip_tls_verify" required:"false" cty:"insecure_skip_tls_verify" hcl:"insecure_skip_tls_verify"`
 	MaxRetries                                *int                                   `mapstructure:"max_retries" required:"false" cty:"max_retries" hcl:"max_retries"`
+	APIRateLimit                              *float64                               `mapstructure:"api_rate_limit" required:"false" cty:"api_rate_limit" hcl:"api_rate_limit"`
+	APIBurst                                  *int                                   `mapstructure:"api_burst" required:"false" cty:"api_burst" hcl:"api_burst"`
+	APIMaxRetries                             *int                                   `mapstructure:"api_max_retries" required:"false" cty:"api_max_retries" hcl:"api_max_retries"`
+	APIRetryBudget                            *int                                   `mapstructure:"api_retry_budget" required:"false" cty:"api_retry_budget" hcl:"api_retry_budget"`
+	APICircuitBreakerThreshold                *int                                   `mapstructure:"api_circuit_breaker_threshold" required:"false" cty:"api_circuit_breaker_threshold" hcl:"api_circuit_breaker_threshold"`
+	APICircuitBreakerTimeout                  *string                                `mapstructure:"api_circuit_breaker_timeout" required:"false" cty:"api_circuit_breaker_timeout" hcl:"api_circuit_breaker_timeout"`
 	MFACode                                   *string                                `mapstructure:"mfa_code" required:"false" cty:"mfa_code" hcl:"mfa_code"`
 	ProfileName                               *string                                `mapstructure:"profile" required:"false" cty:"profile" hcl:"profile"`
 	RawRegion                                 *string                                `mapstructure:"region" required:"true" cty:"region" hcl:"region"`
@@ -75,6 +88,11 @@ type FlatConfig struct {
 	SkipValidation                            *bool                                  `mapstructure:"skip_region_validation" required:"false" cty:"skip_region_validation" hcl:"skip_region_validation"`
 	SkipMetadataApiCheck                      *bool                                  `mapstructure:"skip_metadata_api_check" cty:"skip_metadata_api_check" hcl:"skip_metadata_api_check"`
 	Token                                     *string                                `mapstructure:"token" required:"false" cty:"token" hcl:"token"`
+	WebIdentityRoleARN                        *string                                `mapstructure:"web_identity_role_arn" required:"false" cty:"web_identity_role_arn" hcl:"web_identity_role_arn"`
+	WebIdentitySessionName                    *string                                `mapstructure:"web_identity_session_name" required:"false" cty:"web_identity_session_name" hcl:"web_identity_session_name"`
+	OIDCProvider                              *string                                `mapstructure:"oidc_provider" required:"false" cty:"oidc_provider" hcl:"oidc_provider"`
+	OIDCAudience                              *string                                `mapstructure:"oidc_audience" required:"false" cty:"oidc_audience" hcl:"oidc_audience"`
+	OIDCTokenEnv                              *string                                `mapstructure:"oidc_token_env" required:"false" cty:"oidc_token_env" hcl:"oidc_token_env"`
 	VaultAWSEngine                            *common.FlatVaultAWSEngineOptions      `mapstructure:"vault_aws_engine" required:"false" cty:"vault_aws_engine" hcl:"vault_aws_engine"`
 	AssociatePublicIpAddress                  *bool                                  `mapstructure:"associate_public_ip_address" required:"false" cty:"associate_public_ip_address" hcl:"associate_public_ip_address"`
 	AvailabilityZone                          *string                                `mapstructure:"availability_zone" required:"false" cty:"availability_zone" hcl:"availability_zone"`
@@ -82,11 +100,14 @@ type FlatConfig struct {
 	DisableStopInstance                       *bool                                  `mapstructure:"disable_stop_instance" required:"false" cty:"disable_stop_instance" hcl:"disable_stop_instance"`
 	EbsOptimized                              *bool                                  `mapstructure:"ebs_optimized" required:"false" cty:"ebs_optimized" hcl:"ebs_optimized"`
 	EnableT2Unlimited                         *bool                                  `mapstructure:"enable_t2_unlimited" required:"false" cty:"enable_t2_unlimited" hcl:"enable_t2_unlimited"`
+	EnclaveOptions                            *common.FlatEnclaveOptions             `mapstructure:"enclave_options" required:"false" cty:"enclave_options" hcl:"enclave_options"`
 	IamInstanceProfile                        *string                                `mapstructure:"iam_instance_profile" required:"false" cty:"iam_instance_profile" hcl:"iam_instance_profile"`
 	SkipProfileValidation                     *bool                                  `mapstructure:"skip_profile_validation" required:"false" cty:"skip_profile_validation" hcl:"skip_profile_validation"`
 	TemporaryIamInstanceProfilePolicyDocument *common.FlatPolicyDocument             `mapstructure:"temporary_iam_instance_profile_policy_document" required:"false" cty:"temporary_iam_instance_profile_policy_document" hcl:"temporary_iam_instance_profile_policy_document"`
 	InstanceInitiatedShutdownBehavior         *string                                `mapstructure:"shutdown_behavior" required:"false" cty:"shutdown_behavior" hcl:"shutdown_behavior"`
 	InstanceType                              *string                                `mapstructure:"instance_type" required:"true" cty:"instance_type" hcl:"instance_type"`
+	InstanceRequirements                      *common.FlatInstanceRequirements       `mapstructure:"instance_requirements" required:"false" cty:"instance_requirements" hcl:"instance_requirements"`
+	MetadataOptions                           *common.FlatMetadataOptions            `mapstructure:"metadata_options" required:"false" cty:"metadata_options" hcl:"metadata_options"`
 	SecurityGroupFilter                       *common.FlatSecurityGroupFilterOptions `mapstructure:"security_group_filter" required:"false" cty:"security_group_filter" hcl:"security_group_filter"`
 	RunTags                                   map[string]string                      `mapstructure:"run_tags" required:"false" cty:"run_tags" hcl:"run_tags"`
 	RunTag                                    []hcl2template.FlatKeyValue            `mapstructure:"run_tag" required:"false" cty:"run_tag" hcl:"run_tag"`
@@ -94,6 +115,10 @@ type FlatConfig struct {
 	SecurityGroupIds                          []string                               `mapstructure:"security_group_ids" required:"false" cty:"security_group_ids" hcl:"security_group_ids"`
 	SourceAmi                                 *string                                `mapstructure:"source_ami" required:"true" cty:"source_ami" hcl:"source_ami"`
 	SourceAmiFilter                           *common.FlatAmiFilterOptions           `mapstructure:"source_ami_filter" required:"false" cty:"source_ami_filter" hcl:"source_ami_filter"`
+	SpotAllocationStrategy                    *string                                `mapstructure:"spot_allocation_strategy" required:"false" cty:"spot_allocation_strategy" hcl:"spot_allocation_strategy"`
+	SpotAvailabilityZones                     []string                               `mapstructure:"spot_availability_zones" required:"false" cty:"spot_availability_zones" hcl:"spot_availability_zones"`
+	SpotFallbackTimeout                       *string                                `mapstructure:"spot_fallback_timeout" required:"false" cty:"spot_fallback_timeout" hcl:"spot_fallback_timeout"`
+	OnDemandMaxPrice                          *string                                `mapstructure:"on_demand_max_price" required:"false" cty:"on_demand_max_price" hcl:"on_demand_max_price"`
 	SpotInstanceTypes                         []string                               `mapstructure:"spot_instance_types" required:"false" cty:"spot_instance_types" hcl:"spot_instance_types"`
 	SpotPrice                                 *string                                `mapstructure:"spot_price" required:"false" cty:"spot_price" hcl:"spot_price"`
 	SpotPriceAutoProduct                      *string                                `mapstructure:"spot_price_auto_product" required:"false" undocumented:"true" cty:"spot_price_auto_product" hcl:"spot_price_auto_product"`
@@ -108,37 +133,62 @@ type FlatConfig struct {
 	VpcFilter                                 *common.FlatVpcFilterOptions           `mapstructure:"vpc_filter" required:"false" cty:"vpc_filter" hcl:"vpc_filter"`
 	VpcId                                     *string                                `mapstructure:"vpc_id" required:"false" cty:"vpc_id" hcl:"vpc_id"`
 	WindowsPasswordTimeout                    *string                                `mapstructure:"windows_password_timeout" required:"false" cty:"windows_password_timeout" hcl:"windows_password_timeout"`
+	WindowsLaunchPrepare                      *string                                `mapstructure:"windows_launch_prepare" required:"false" cty:"windows_launch_prepare" hcl:"windows_launch_prepare"`
 	Type                                      *string                                `mapstructure:"communicator" cty:"communicator" hcl:"communicator"`
 	PauseBeforeConnect                        *string                                `mapstructure:"pause_before_connecting" cty:"pause_before_connecting" hcl:"pause_before_connecting"`
+	LivenessTimeout                           *string                                `mapstructure:"liveness_timeout" cty:"liveness_timeout" hcl:"liveness_timeout"`
+	MetricsInterval                           *string                                `mapstructure:"metrics_interval" cty:"metrics_interval" hcl:"metrics_interval"`
+	DisableGuestFacts                         *bool                                  `mapstructure:"disable_guest_facts" cty:"disable_guest_facts" hcl:"disable_guest_facts"`
 	SSHHost                                   *string                                `mapstructure:"ssh_host" cty:"ssh_host" hcl:"ssh_host"`
 	SSHPort                                   *int                                   `mapstructure:"ssh_port" cty:"ssh_port" hcl:"ssh_port"`
 	SSHUsername                               *string                                `mapstructure:"ssh_username" cty:"ssh_username" hcl:"ssh_username"`
 	SSHPassword                               *string                                `mapstructure:"ssh_password" cty:"ssh_password" hcl:"ssh_password"`
 	SSHKeyPairName                            *string                                `mapstructure:"ssh_keypair_name" undocumented:"true" cty:"ssh_keypair_name" hcl:"ssh_keypair_name"`
+	SSHTemporaryKeyPairShared                 *bool                                  `mapstructure:"temporary_key_pair_shared" cty:"temporary_key_pair_shared" hcl:"temporary_key_pair_shared"`
+	SSHTemporaryKeyPairReuse                  *bool                                  `mapstructure:"temporary_key_pair_reuse" cty:"temporary_key_pair_reuse" hcl:"temporary_key_pair_reuse"`
 	SSHCiphers                                []string                               `mapstructure:"ssh_ciphers" cty:"ssh_ciphers" hcl:"ssh_ciphers"`
 	SSHClearAuthorizedKeys                    *bool                                  `mapstructure:"ssh_clear_authorized_keys" cty:"ssh_clear_authorized_keys" hcl:"ssh_clear_authorized_keys"`
 	SSHKEXAlgos                               []string                               `mapstructure:"ssh_key_exchange_algorithms" cty:"ssh_key_exchange_algorithms" hcl:"ssh_key_exchange_algorithms"`
+	SSHSecurityPolicy                         *string                                `mapstructure:"ssh_security_policy" cty:"ssh_security_policy" hcl:"ssh_security_policy"`
 	SSHPrivateKeyFile                         *string                                `mapstructure:"ssh_private_key_file" undocumented:"true" cty:"ssh_private_key_file" hcl:"ssh_private_key_file"`
 	SSHCertificateFile                        *string                                `mapstructure:"ssh_certificate_file" cty:"ssh_certificate_file" hcl:"ssh_certificate_file"`
 	SSHPty                                    *bool                                  `mapstructure:"ssh_pty" cty:"ssh_pty" hcl:"ssh_pty"`
+	SSHPtyOutput                              *string                                `mapstructure:"ssh_pty_output" cty:"ssh_pty_output" hcl:"ssh_pty_output"`
 	SSHTimeout                                *string                                `mapstructure:"ssh_timeout" cty:"ssh_timeout" hcl:"ssh_timeout"`
-	SSHWaitTimeout                            *string                                `mapstructure:"ssh_wait_timeout" undocumented:"true" cty:"ssh_wait_timeout" hcl:"ssh_wait_timeout"`
+	SSHWaitTimeout                            *string                                `mapstructure:"ssh_wait_timeout" undocumented:"true" deprecated:"ssh_timeout" cty:"ssh_wait_timeout" hcl:"ssh_wait_timeout"`
 	SSHAgentAuth                              *bool                                  `mapstructure:"ssh_agent_auth" undocumented:"true" cty:"ssh_agent_auth" hcl:"ssh_agent_auth"`
 	SSHDisableAgentForwarding                 *bool                                  `mapstructure:"ssh_disable_agent_forwarding" cty:"ssh_disable_agent_forwarding" hcl:"ssh_disable_agent_forwarding"`
 	SSHHandshakeAttempts                      *int                                   `mapstructure:"ssh_handshake_attempts" cty:"ssh_handshake_attempts" hcl:"ssh_handshake_attempts"`
+	SSHHandshakeTimeout                       *string                                `mapstructure:"ssh_handshake_timeout" cty:"ssh_handshake_timeout" hcl:"ssh_handshake_timeout"`
+	SSHBannerReadTimeout                      *string                                `mapstructure:"ssh_banner_read_timeout" cty:"ssh_banner_read_timeout" hcl:"ssh_banner_read_timeout"`
+	SSHPreAuthGracePeriod                     *string                                `mapstructure:"ssh_pre_auth_grace_period" cty:"ssh_pre_auth_grace_period" hcl:"ssh_pre_auth_grace_period"`
 	SSHBastionHost                            *string                                `mapstructure:"ssh_bastion_host" cty:"ssh_bastion_host" hcl:"ssh_bastion_host"`
 	SSHBastionPort                            *int                                   `mapstructure:"ssh_bastion_port" cty:"ssh_bastion_port" hcl:"ssh_bastion_port"`
 	SSHBastionAgentAuth                       *bool                                  `mapstructure:"ssh_bastion_agent_auth" cty:"ssh_bastion_agent_auth" hcl:"ssh_bastion_agent_auth"`
 	SSHBastionUsername                        *string                                `mapstructure:"ssh_bastion_username" cty:"ssh_bastion_username" hcl:"ssh_bastion_username"`
 	SSHBastionPassword                        *string                                `mapstructure:"ssh_bastion_password" cty:"ssh_bastion_password" hcl:"ssh_bastion_password"`
 	SSHBastionInteractive                     *bool                                  `mapstructure:"ssh_bastion_interactive" cty:"ssh_bastion_interactive" hcl:"ssh_bastion_interactive"`
+	SSHBastionTOTPSecret                      *string                                `mapstructure:"ssh_bastion_totp_secret" cty:"ssh_bastion_totp_secret" hcl:"ssh_bastion_totp_secret"`
 	SSHBastionPrivateKeyFile                  *string                                `mapstructure:"ssh_bastion_private_key_file" cty:"ssh_bastion_private_key_file" hcl:"ssh_bastion_private_key_file"`
 	SSHBastionCertificateFile                 *string                                `mapstructure:"ssh_bastion_certificate_file" cty:"ssh_bastion_certificate_file" hcl:"ssh_bastion_certificate_file"`
 	SSHFileTransferMethod                     *string                                `mapstructure:"ssh_file_transfer_method" cty:"ssh_file_transfer_method" hcl:"ssh_file_transfer_method"`
+	SSHSftpServerBinary                       *string                                `mapstructure:"ssh_sftp_server_binary" cty:"ssh_sftp_server_binary" hcl:"ssh_sftp_server_binary"`
 	SSHProxyHost                              *string                                `mapstructure:"ssh_proxy_host" cty:"ssh_proxy_host" hcl:"ssh_proxy_host"`
 	SSHProxyPort                              *int                                   `mapstructure:"ssh_proxy_port" cty:"ssh_proxy_port" hcl:"ssh_proxy_port"`
 	SSHProxyUsername                          *string                                `mapstructure:"ssh_proxy_username" cty:"ssh_proxy_username" hcl:"ssh_proxy_username"`
 	SSHProxyPassword                          *string                                `mapstructure:"ssh_proxy_password" cty:"ssh_proxy_password" hcl:"ssh_proxy_password"`
+	SSHCallbackAddress                        *string                                `mapstructure:"ssh_callback_address" cty:"ssh_callback_address" hcl:"ssh_callback_address"`
+	SSHCallbackAuthorizedKeysFile             *string                                `mapstructure:"ssh_callback_authorized_keys_file" cty:"ssh_callback_authorized_keys_file" hcl:"ssh_callback_authorized_keys_file"`
+	SSHCallbackHostKeyFile                    *string                                `mapstructure:"ssh_callback_host_key_file" cty:"ssh_callback_host_key_file" hcl:"ssh_callback_host_key_file"`
+	SSHTeleportProxy                          *string                                `mapstructure:"ssh_teleport_proxy" cty:"ssh_teleport_proxy" hcl:"ssh_teleport_proxy"`
+	SSHTeleportCluster                        *string                                `mapstructure:"ssh_teleport_cluster" cty:"ssh_teleport_cluster" hcl:"ssh_teleport_cluster"`
+	SSHTeleportIdentityFile                   *string                                `mapstructure:"ssh_teleport_identity_file" cty:"ssh_teleport_identity_file" hcl:"ssh_teleport_identity_file"`
+	SSHBoundaryTargetID                       *string                                `mapstructure:"ssh_boundary_target_id" cty:"ssh_boundary_target_id" hcl:"ssh_boundary_target_id"`
+	SSHBoundaryHostID                         *string                                `mapstructure:"ssh_boundary_host_id" cty:"ssh_boundary_host_id" hcl:"ssh_boundary_host_id"`
+	SSHBoundaryAddr                           *string                                `mapstructure:"ssh_boundary_addr" cty:"ssh_boundary_addr" hcl:"ssh_boundary_addr"`
+	SSHWebSocketURL                           *string                                `mapstructure:"ssh_websocket_url" cty:"ssh_websocket_url" hcl:"ssh_websocket_url"`
+	SSHWebSocketToken                         *string                                `mapstructure:"ssh_websocket_token" cty:"ssh_websocket_token" hcl:"ssh_websocket_token"`
+	SSHWebSocketInsecureSkipTLSVerify         *bool                                  `mapstructure:"ssh_websocket_insecure_skip_tls_verify" cty:"ssh_websocket_insecure_skip_tls_verify" hcl:"ssh_websocket_insecure_skip_tls_verify"`
 	SSHKeepAliveInterval                      *string                                `mapstructure:"ssh_keep_alive_interval" cty:"ssh_keep_alive_interval" hcl:"ssh_keep_alive_interval"`
 	SSHReadWriteTimeout                       *string                                `mapstructure:"ssh_read_write_timeout" cty:"ssh_read_write_timeout" hcl:"ssh_read_write_timeout"`
 	SSHRemoteTunnels                          []string                               `mapstructure:"ssh_remote_tunnels" cty:"ssh_remote_tunnels" hcl:"ssh_remote_tunnels"`
@@ -154,6 +204,33 @@ type FlatConfig struct {
 	WinRMUseSSL                               *bool                                  `mapstructure:"winrm_use_ssl" cty:"winrm_use_ssl" hcl:"winrm_use_ssl"`
 	WinRMInsecure                             *bool                                  `mapstructure:"winrm_insecure" cty:"winrm_insecure" hcl:"winrm_insecure"`
 	WinRMUseNTLM                              *bool                                  `mapstructure:"winrm_use_ntlm" cty:"winrm_use_ntlm" hcl:"winrm_use_ntlm"`
+	WinRMUsePSRP                              *bool                                  `mapstructure:"winrm_use_psrp" cty:"winrm_use_psrp" hcl:"winrm_use_psrp"`
+	WinRMBastionHost                          *string                                `mapstructure:"winrm_bastion_host" cty:"winrm_bastion_host" hcl:"winrm_bastion_host"`
+	WinRMBastionPort                          *int                                   `mapstructure:"winrm_bastion_port" cty:"winrm_bastion_port" hcl:"winrm_bastion_port"`
+	WinRMBastionUsername                      *string                                `mapstructure:"winrm_bastion_username" cty:"winrm_bastion_username" hcl:"winrm_bastion_username"`
+	WinRMBastionPassword                      *string                                `mapstructure:"winrm_bastion_password" cty:"winrm_bastion_password" hcl:"winrm_bastion_password"`
+	WinRMBastionPrivateKeyFile                *string                                `mapstructure:"winrm_bastion_private_key_file" cty:"winrm_bastion_private_key_file" hcl:"winrm_bastion_private_key_file"`
+	WinRMBastionAgentAuth                     *bool                                  `mapstructure:"winrm_bastion_agent_auth" cty:"winrm_bastion_agent_auth" hcl:"winrm_bastion_agent_auth"`
+	WinRMProxyHost                            *string                                `mapstructure:"winrm_proxy_host" cty:"winrm_proxy_host" hcl:"winrm_proxy_host"`
+	WinRMProxyPort                            *int                                   `mapstructure:"winrm_proxy_port" cty:"winrm_proxy_port" hcl:"winrm_proxy_port"`
+	WinRMProxyUsername                        *string                                `mapstructure:"winrm_proxy_username" cty:"winrm_proxy_username" hcl:"winrm_proxy_username"`
+	WinRMProxyPassword                        *string                                `mapstructure:"winrm_proxy_password" cty:"winrm_proxy_password" hcl:"winrm_proxy_password"`
+	CredentialRotation                        *string                                `mapstructure:"credential_rotation" cty:"credential_rotation" hcl:"credential_rotation"`
+	GuestCleanup                              []string                               `mapstructure:"guest_cleanup" cty:"guest_cleanup" hcl:"guest_cleanup"`
+	GuestCleanupDryRun                        *bool                                  `mapstructure:"guest_cleanup_dry_run" cty:"guest_cleanup_dry_run" hcl:"guest_cleanup_dry_run"`
+	SysprepGeneralize                         *bool                                  `mapstructure:"sysprep_generalize" cty:"sysprep_generalize" hcl:"sysprep_generalize"`
+	SysprepUnattendFile                       *string                                `mapstructure:"sysprep_unattend_file" cty:"sysprep_unattend_file" hcl:"sysprep_unattend_file"`
+	SysprepModeVM                             *bool                                  `mapstructure:"sysprep_mode_vm" cty:"sysprep_mode_vm" hcl:"sysprep_mode_vm"`
+	SysprepTimeout                            *string                                `mapstructure:"sysprep_timeout" cty:"sysprep_timeout" hcl:"sysprep_timeout"`
+	LinuxGeneralize                           *bool                                  `mapstructure:"linux_generalize" cty:"linux_generalize" hcl:"linux_generalize"`
+	LinuxGeneralizeOperations                 []string                               `mapstructure:"linux_generalize_operations" cty:"linux_generalize_operations" hcl:"linux_generalize_operations"`
+	LinuxGeneralizeSkip                       []string                               `mapstructure:"linux_generalize_skip" cty:"linux_generalize_skip" hcl:"linux_generalize_skip"`
+	RemoteShell                               *string                                `mapstructure:"remote_shell" cty:"remote_shell" hcl:"remote_shell"`
+	RemoteLocale                              *string                                `mapstructure:"remote_locale" cty:"remote_locale" hcl:"remote_locale"`
+	RemotePath                                *string                                `mapstructure:"remote_path" cty:"remote_path" hcl:"remote_path"`
+	ClockSkewCheck                            *bool                                  `mapstructure:"clock_skew_check" cty:"clock_skew_check" hcl:"clock_skew_check"`
+	ClockMaxSkew                              *string                                `mapstructure:"clock_max_skew" cty:"clock_max_skew" hcl:"clock_max_skew"`
+	ClockSkewFix                              *bool                                  `mapstructure:"clock_skew_fix" cty:"clock_skew_fix" hcl:"clock_skew_fix"`
 	SSHInterface                              *string                                `mapstructure:"ssh_interface" cty:"ssh_interface" hcl:"ssh_interface"`
 	SessionManagerPort                        *int                                   `mapstructure:"session_manager_port" cty:"session_manager_port" hcl:"session_manager_port"`
 	AMIENASupport                             *bool                                  `mapstructure:"ena_support" required:"false" cty:"ena_support" hcl:"ena_support"`
@@ -178,15 +255,28 @@ func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 		"packer_build_name":             &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
 		"packer_builder_type":           &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
 		"packer_debug":                  &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_breakpoints":            &hcldec.AttrSpec{Name: "packer_breakpoints", Type: cty.List(cty.String), Required: false},
 		"packer_force":                  &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
 		"packer_on_error":               &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_on_conflict":            &hcldec.AttrSpec{Name: "packer_on_conflict", Type: cty.String, Required: false},
+		"packer_journal":                &hcldec.AttrSpec{Name: "packer_journal", Type: cty.String, Required: false},
+		"packer_resume":                 &hcldec.AttrSpec{Name: "packer_resume", Type: cty.Bool, Required: false},
+		"packer_billing_tags":           &hcldec.AttrSpec{Name: "packer_billing_tags", Type: cty.Map(cty.String), Required: false},
+		"packer_preflight":              &hcldec.AttrSpec{Name: "packer_preflight", Type: cty.String, Required: false},
 		"packer_user_variables":         &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
 		"packer_sensitive_variables":    &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
 		"access_key":                    &hcldec.AttrSpec{Name: "access_key", Type: cty.String, Required: false},
+		"credential_helper":             &hcldec.AttrSpec{Name: "credential_helper", Type: cty.List(cty.String), Required: false},
 		"custom_endpoint_ec2":           &hcldec.AttrSpec{Name: "custom_endpoint_ec2", Type: cty.String, Required: false},
 		"decode_authorization_messages": &hcldec.AttrSpec{Name: "decode_authorization_messages", Type: cty.Bool, Required: false},
 		"insecure_skip_tls_verify":      &hcldec.AttrSpec{Name: "insecure_skip_tls_verify", Type: cty.Bool, Required: false},
 		"max_retries":                   &hcldec.AttrSpec{Name: "max_retries", Type: cty.Number, Required: false},
+		"api_rate_limit":                &hcldec.AttrSpec{Name: "api_rate_limit", Type: cty.Number, Required: false},
+		"api_burst":                     &hcldec.AttrSpec{Name: "api_burst", Type: cty.Number, Required: false},
+		"api_max_retries":               &hcldec.AttrSpec{Name: "api_max_retries", Type: cty.Number, Required: false},
+		"api_retry_budget":              &hcldec.AttrSpec{Name: "api_retry_budget", Type: cty.Number, Required: false},
+		"api_circuit_breaker_threshold": &hcldec.AttrSpec{Name: "api_circuit_breaker_threshold", Type: cty.Number, Required: false},
+		"api_circuit_breaker_timeout":   &hcldec.AttrSpec{Name: "api_circuit_breaker_timeout", Type: cty.String, Required: false},
 		"mfa_code":                      &hcldec.AttrSpec{Name: "mfa_code", Type: cty.String, Required: false},
 		"profile":                       &hcldec.AttrSpec{Name: "profile", Type: cty.String, Required: false},
 		"region":                        &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
@@ -194,6 +284,11 @@ func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 		"skip_region_validation":        &hcldec.AttrSpec{Name: "skip_region_validation", Type: cty.Bool, Required: false},
 		"skip_metadata_api_check":       &hcldec.AttrSpec{Name: "skip_metadata_api_check", Type: cty.Bool, Required: false},
 		"token":                         &hcldec.AttrSpec{Name: "token", Type: cty.String, Required: false},
+		"web_identity_role_arn":         &hcldec.AttrSpec{Name: "web_identity_role_arn", Type: cty.String, Required: false},
+		"web_identity_session_name":     &hcldec.AttrSpec{Name: "web_identity_session_name", Type: cty.String, Required: false},
+		"oidc_provider":                 &hcldec.AttrSpec{Name: "oidc_provider", Type: cty.String, Required: false},
+		"oidc_audience":                 &hcldec.AttrSpec{Name: "oidc_audience", Type: cty.String, Required: false},
+		"oidc_token_env":                &hcldec.AttrSpec{Name: "oidc_token_env", Type: cty.String, Required: false},
 		"vault_aws_engine":              &hcldec.BlockSpec{TypeName: "vault_aws_engine", Nested: hcldec.ObjectSpec((*common.FlatVaultAWSEngineOptions)(nil).HCL2Spec())},
 		"associate_public_ip_address":   &hcldec.AttrSpec{Name: "associate_public_ip_address", Type: cty.Bool, Required: false},
 		"availability_zone":             &hcldec.AttrSpec{Name: "availability_zone", Type: cty.String, Required: false},
@@ -201,85 +296,144 @@ func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 		"disable_stop_instance":         &hcldec.AttrSpec{Name: "disable_stop_instance", Type: cty.Bool, Required: false},
 		"ebs_optimized":                 &hcldec.AttrSpec{Name: "ebs_optimized", Type: cty.Bool, Required: false},
 		"enable_t2_unlimited":           &hcldec.AttrSpec{Name: "enable_t2_unlimited", Type: cty.Bool, Required: false},
+		"enclave_options":               &hcldec.BlockSpec{TypeName: "enclave_options", Nested: hcldec.ObjectSpec((*common.FlatEnclaveOptions)(nil).HCL2Spec())},
 		"iam_instance_profile":          &hcldec.AttrSpec{Name: "iam_instance_profile", Type: cty.String, Required: false},
 		"skip_profile_validation":       &hcldec.AttrSpec{Name: "skip_profile_validation", Type: cty.Bool, Required: false},
 		"temporary_iam_instance_profile_policy_document": &hcldec.BlockSpec{TypeName: "temporary_iam_instance_profile_policy_document", Nested: hcldec.ObjectSpec((*common.FlatPolicyDocument)(nil).HCL2Spec())},
-		"shutdown_behavior":                     &hcldec.AttrSpec{Name: "shutdown_behavior", Type: cty.String, Required: false},
-		"instance_type":                         &hcldec.AttrSpec{Name: "instance_type", Type: cty.String, Required: false},
-		"security_group_filter":                 &hcldec.BlockSpec{TypeName: "security_group_filter", Nested: hcldec.ObjectSpec((*common.FlatSecurityGroupFilterOptions)(nil).HCL2Spec())},
-		"run_tags":                              &hcldec.AttrSpec{Name: "run_tags", Type: cty.Map(cty.String), Required: false},
-		"run_tag":                               &hcldec.BlockListSpec{TypeName: "run_tag", Nested: hcldec.ObjectSpec((*hcl2template.FlatKeyValue)(nil).HCL2Spec())},
-		"security_group_id":                     &hcldec.AttrSpec{Name: "security_group_id", Type: cty.String, Required: false},
-		"security_group_ids":                    &hcldec.AttrSpec{Name: "security_group_ids", Type: cty.List(cty.String), Required: false},
-		"source_ami":                            &hcldec.AttrSpec{Name: "source_ami", Type: cty.String, Required: false},
-		"source_ami_filter":                     &hcldec.BlockSpec{TypeName: "source_ami_filter", Nested: hcldec.ObjectSpec((*common.FlatAmiFilterOptions)(nil).HCL2Spec())},
-		"spot_instance_types":                   &hcldec.AttrSpec{Name: "spot_instance_types", Type: cty.List(cty.String), Required: false},
-		"spot_price":                            &hcldec.AttrSpec{Name: "spot_price", Type: cty.String, Required: false},
-		"spot_price_auto_product":               &hcldec.AttrSpec{Name: "spot_price_auto_product", Type: cty.String, Required: false},
-		"spot_tags":                             &hcldec.AttrSpec{Name: "spot_tags", Type: cty.Map(cty.String), Required: false},
-		"spot_tag":                              &hcldec.BlockListSpec{TypeName: "spot_tag", Nested: hcldec.ObjectSpec((*hcl2template.FlatKeyValue)(nil).HCL2Spec())},
-		"subnet_filter":                         &hcldec.BlockSpec{TypeName: "subnet_filter", Nested: hcldec.ObjectSpec((*common.FlatSubnetFilterOptions)(nil).HCL2Spec())},
-		"subnet_id":                             &hcldec.AttrSpec{Name: "subnet_id", Type: cty.String, Required: false},
-		"temporary_key_pair_name":               &hcldec.AttrSpec{Name: "temporary_key_pair_name", Type: cty.String, Required: false},
-		"temporary_security_group_source_cidrs": &hcldec.AttrSpec{Name: "temporary_security_group_source_cidrs", Type: cty.List(cty.String), Required: false},
-		"user_data":                             &hcldec.AttrSpec{Name: "user_data", Type: cty.String, Required: false},
-		"user_data_file":                        &hcldec.AttrSpec{Name: "user_data_file", Type: cty.String, Required: false},
-		"vpc_filter":                            &hcldec.BlockSpec{TypeName: "vpc_filter", Nested: hcldec.ObjectSpec((*common.FlatVpcFilterOptions)(nil).HCL2Spec())},
-		"vpc_id":                                &hcldec.AttrSpec{Name: "vpc_id", Type: cty.String, Required: false},
-		"windows_password_timeout":              &hcldec.AttrSpec{Name: "windows_password_timeout", Type: cty.String, Required: false},
-		"communicator":                          &hcldec.AttrSpec{Name: "communicator", Type: cty.String, Required: false},
-		"pause_before_connecting":               &hcldec.AttrSpec{Name: "pause_before_connecting", Type: cty.String, Required: false},
-		"ssh_host":                              &hcldec.AttrSpec{Name: "ssh_host", Type: cty.String, Required: false},
-		"ssh_port":                              &hcldec.AttrSpec{Name: "ssh_port", Type: cty.Number, Required: false},
-		"ssh_username":                          &hcldec.AttrSpec{Name: "ssh_username", Type: cty.String, Required: false},
-		"ssh_password":                          &hcldec.AttrSpec{Name: "ssh_password", Type: cty.String, Required: false},
-		"ssh_keypair_name":                      &hcldec.AttrSpec{Name: "ssh_keypair_name", Type: cty.String, Required: false},
-		"ssh_ciphers":                           &hcldec.AttrSpec{Name: "ssh_ciphers", Type: cty.List(cty.String), Required: false},
-		"ssh_clear_authorized_keys":             &hcldec.AttrSpec{Name: "ssh_clear_authorized_keys", Type: cty.Bool, Required: false},
-		"ssh_key_exchange_algorithms":           &hcldec.AttrSpec{Name: "ssh_key_exchange_algorithms", Type: cty.List(cty.String), Required: false},
-		"ssh_private_key_file":                  &hcldec.AttrSpec{Name: "ssh_private_key_file", Type: cty.String, Required: false},
-		"ssh_certificate_file":                  &hcldec.AttrSpec{Name: "ssh_certificate_file", Type: cty.String, Required: false},
-		"ssh_pty":                               &hcldec.AttrSpec{Name: "ssh_pty", Type: cty.Bool, Required: false},
-		"ssh_timeout":                           &hcldec.AttrSpec{Name: "ssh_timeout", Type: cty.String, Required: false},
-		"ssh_wait_timeout":                      &hcldec.AttrSpec{Name: "ssh_wait_timeout", Type: cty.String, Required: false},
-		"ssh_agent_auth":                        &hcldec.AttrSpec{Name: "ssh_agent_auth", Type: cty.Bool, Required: false},
-		"ssh_disable_agent_forwarding":          &hcldec.AttrSpec{Name: "ssh_disable_agent_forwarding", Type: cty.Bool, Required: false},
-		"ssh_handshake_attempts":                &hcldec.AttrSpec{Name: "ssh_handshake_attempts", Type: cty.Number, Required: false},
-		"ssh_bastion_host":                      &hcldec.AttrSpec{Name: "ssh_bastion_host", Type: cty.String, Required: false},
-		"ssh_bastion_port":                      &hcldec.AttrSpec{Name: "ssh_bastion_port", Type: cty.Number, Required: false},
-		"ssh_bastion_agent_auth":                &hcldec.AttrSpec{Name: "ssh_bastion_agent_auth", Type: cty.Bool, Required: false},
-		"ssh_bastion_username":                  &hcldec.AttrSpec{Name: "ssh_bastion_username", Type: cty.String, Required: false},
-		"ssh_bastion_password":                  &hcldec.AttrSpec{Name: "ssh_bastion_password", Type: cty.String, Required: false},
-		"ssh_bastion_interactive":               &hcldec.AttrSpec{Name: "ssh_bastion_interactive", Type: cty.Bool, Required: false},
-		"ssh_bastion_private_key_file":          &hcldec.AttrSpec{Name: "ssh_bastion_private_key_file", Type: cty.String, Required: false},
-		"ssh_bastion_certificate_file":          &hcldec.AttrSpec{Name: "ssh_bastion_certificate_file", Type: cty.String, Required: false},
-		"ssh_file_transfer_method":              &hcldec.AttrSpec{Name: "ssh_file_transfer_method", Type: cty.String, Required: false},
-		"ssh_proxy_host":                        &hcldec.AttrSpec{Name: "ssh_proxy_host", Type: cty.String, Required: false},
-		"ssh_proxy_port":                        &hcldec.AttrSpec{Name: "ssh_proxy_port", Type: cty.Number, Required: false},
-		"ssh_proxy_username":                    &hcldec.AttrSpec{Name: "ssh_proxy_username", Type: cty.String, Required: false},
-		"ssh_proxy_password":                    &hcldec.AttrSpec{Name: "ssh_proxy_password", Type: cty.String, Required: false},
-		"ssh_keep_alive_interval":               &hcldec.AttrSpec{Name: "ssh_keep_alive_interval", Type: cty.String, Required: false},
-		"ssh_read_write_timeout":                &hcldec.AttrSpec{Name: "ssh_read_write_timeout", Type: cty.String, Required: false},
-		"ssh_remote_tunnels":                    &hcldec.AttrSpec{Name: "ssh_remote_tunnels", Type: cty.List(cty.String), Required: false},
-		"ssh_local_tunnels":                     &hcldec.AttrSpec{Name: "ssh_local_tunnels", Type: cty.List(cty.String), Required: false},
-		"ssh_public_key":                        &hcldec.AttrSpec{Name: "ssh_public_key", Type: cty.List(cty.Number), Required: false},
-		"ssh_private_key":                       &hcldec.AttrSpec{Name: "ssh_private_key", Type: cty.List(cty.Number), Required: false},
-		"winrm_username":                        &hcldec.AttrSpec{Name: "winrm_username", Type: cty.String, Required: false},
-		"winrm_password":                        &hcldec.AttrSpec{Name: "winrm_password", Type: cty.String, Required: false},
-		"winrm_host":                            &hcldec.AttrSpec{Name: "winrm_host", Type: cty.String, Required: false},
-		"winrm_no_proxy":                        &hcldec.AttrSpec{Name: "winrm_no_proxy", Type: cty.Bool, Required: false},
-		"winrm_port":                            &hcldec.AttrSpec{Name: "winrm_port", Type: cty.Number, Required: false},
-		"winrm_timeout":                         &hcldec.AttrSpec{Name: "winrm_timeout", Type: cty.String, Required: false},
-		"winrm_use_ssl":                         &hcldec.AttrSpec{Name: "winrm_use_ssl", Type: cty.Bool, Required: false},
-		"winrm_insecure":                        &hcldec.AttrSpec{Name: "winrm_insecure", Type: cty.Bool, Required: false},
-		"winrm_use_ntlm":                        &hcldec.AttrSpec{Name: "winrm_use_ntlm", Type: cty.Bool, Required: false},
-		"ssh_interface":                         &hcldec.AttrSpec{Name: "ssh_interface", Type: cty.String, Required: false},
-		"session_manager_port":                  &hcldec.AttrSpec{Name: "session_manager_port", Type: cty.Number, Required: false},
-		"ena_support":                           &hcldec.AttrSpec{Name: "ena_support", Type: cty.Bool, Required: false},
-		"sriov_support":                         &hcldec.AttrSpec{Name: "sriov_support", Type: cty.Bool, Required: false},
-		"ebs_volumes":                           &hcldec.BlockListSpec{TypeName: "ebs_volumes", Nested: hcldec.ObjectSpec((*FlatBlockDevice)(nil).HCL2Spec())},
-		"run_volume_tags":                       &hcldec.AttrSpec{Name: "run_volume_tags", Type: cty.Map(cty.String), Required: false},
-		"run_volume_tag":                        &hcldec.BlockListSpec{TypeName: "run_volume_tag", Nested: hcldec.ObjectSpec((*hcl2template.FlatKeyValue)(nil).HCL2Spec())},
+		"shutdown_behavior":                      &hcldec.AttrSpec{Name: "shutdown_behavior", Type: cty.String, Required: false},
+		"instance_type":                          &hcldec.AttrSpec{Name: "instance_type", Type: cty.String, Required: false},
+		"instance_requirements":                  &hcldec.BlockSpec{TypeName: "instance_requirements", Nested: hcldec.ObjectSpec((*common.FlatInstanceRequirements)(nil).HCL2Spec())},
+		"metadata_options":                       &hcldec.BlockSpec{TypeName: "metadata_options", Nested: hcldec.ObjectSpec((*common.FlatMetadataOptions)(nil).HCL2Spec())},
+		"security_group_filter":                  &hcldec.BlockSpec{TypeName: "security_group_filter", Nested: hcldec.ObjectSpec((*common.FlatSecurityGroupFilterOptions)(nil).HCL2Spec())},
+		"run_tags":                               &hcldec.AttrSpec{Name: "run_tags", Type: cty.Map(cty.String), Required: false},
+		"run_tag":                                &hcldec.BlockListSpec{TypeName: "run_tag", Nested: hcldec.ObjectSpec((*hcl2template.FlatKeyValue)(nil).HCL2Spec())},
+		"security_group_id":                      &hcldec.AttrSpec{Name: "security_group_id", Type: cty.String, Required: false},
+		"security_group_ids":                     &hcldec.AttrSpec{Name: "security_group_ids", Type: cty.List(cty.String), Required: false},
+		"source_ami":                             &hcldec.AttrSpec{Name: "source_ami", Type: cty.String, Required: false},
+		"source_ami_filter":                      &hcldec.BlockSpec{TypeName: "source_ami_filter", Nested: hcldec.ObjectSpec((*common.FlatAmiFilterOptions)(nil).HCL2Spec())},
+		"spot_allocation_strategy":               &hcldec.AttrSpec{Name: "spot_allocation_strategy", Type: cty.String, Required: false},
+		"spot_availability_zones":                &hcldec.AttrSpec{Name: "spot_availability_zones", Type: cty.List(cty.String), Required: false},
+		"spot_fallback_timeout":                  &hcldec.AttrSpec{Name: "spot_fallback_timeout", Type: cty.String, Required: false},
+		"on_demand_max_price":                    &hcldec.AttrSpec{Name: "on_demand_max_price", Type: cty.String, Required: false},
+		"spot_instance_types":                    &hcldec.AttrSpec{Name: "spot_instance_types", Type: cty.List(cty.String), Required: false},
+		"spot_price":                             &hcldec.AttrSpec{Name: "spot_price", Type: cty.String, Required: false},
+		"spot_price_auto_product":                &hcldec.AttrSpec{Name: "spot_price_auto_product", Type: cty.String, Required: false},
+		"spot_tags":                              &hcldec.AttrSpec{Name: "spot_tags", Type: cty.Map(cty.String), Required: false},
+		"spot_tag":                               &hcldec.BlockListSpec{TypeName: "spot_tag", Nested: hcldec.ObjectSpec((*hcl2template.FlatKeyValue)(nil).HCL2Spec())},
+		"subnet_filter":                          &hcldec.BlockSpec{TypeName: "subnet_filter", Nested: hcldec.ObjectSpec((*common.FlatSubnetFilterOptions)(nil).HCL2Spec())},
+		"subnet_id":                              &hcldec.AttrSpec{Name: "subnet_id", Type: cty.String, Required: false},
+		"temporary_key_pair_name":                &hcldec.AttrSpec{Name: "temporary_key_pair_name", Type: cty.String, Required: false},
+		"temporary_security_group_source_cidrs":  &hcldec.AttrSpec{Name: "temporary_security_group_source_cidrs", Type: cty.List(cty.String), Required: false},
+		"user_data":                              &hcldec.AttrSpec{Name: "user_data", Type: cty.String, Required: false},
+		"user_data_file":                         &hcldec.AttrSpec{Name: "user_data_file", Type: cty.String, Required: false},
+		"vpc_filter":                             &hcldec.BlockSpec{TypeName: "vpc_filter", Nested: hcldec.ObjectSpec((*common.FlatVpcFilterOptions)(nil).HCL2Spec())},
+		"vpc_id":                                 &hcldec.AttrSpec{Name: "vpc_id", Type: cty.String, Required: false},
+		"windows_password_timeout":               &hcldec.AttrSpec{Name: "windows_password_timeout", Type: cty.String, Required: false},
+		"windows_launch_prepare":                 &hcldec.AttrSpec{Name: "windows_launch_prepare", Type: cty.String, Required: false},
+		"communicator":                           &hcldec.AttrSpec{Name: "communicator", Type: cty.String, Required: false},
+		"pause_before_connecting":                &hcldec.AttrSpec{Name: "pause_before_connecting", Type: cty.String, Required: false},
+		"liveness_timeout":                       &hcldec.AttrSpec{Name: "liveness_timeout", Type: cty.String, Required: false},
+		"metrics_interval":                       &hcldec.AttrSpec{Name: "metrics_interval", Type: cty.String, Required: false},
+		"disable_guest_facts":                    &hcldec.AttrSpec{Name: "disable_guest_facts", Type: cty.Bool, Required: false},
+		"ssh_host":                               &hcldec.AttrSpec{Name: "ssh_host", Type: cty.String, Required: false},
+		"ssh_port":                               &hcldec.AttrSpec{Name: "ssh_port", Type: cty.Number, Required: false},
+		"ssh_username":                           &hcldec.AttrSpec{Name: "ssh_username", Type: cty.String, Required: false},
+		"ssh_password":                           &hcldec.AttrSpec{Name: "ssh_password", Type: cty.String, Required: false},
+		"ssh_keypair_name":                       &hcldec.AttrSpec{Name: "ssh_keypair_name", Type: cty.String, Required: false},
+		"temporary_key_pair_shared":              &hcldec.AttrSpec{Name: "temporary_key_pair_shared", Type: cty.Bool, Required: false},
+		"temporary_key_pair_reuse":               &hcldec.AttrSpec{Name: "temporary_key_pair_reuse", Type: cty.Bool, Required: false},
+		"ssh_ciphers":                            &hcldec.AttrSpec{Name: "ssh_ciphers", Type: cty.List(cty.String), Required: false},
+		"ssh_clear_authorized_keys":              &hcldec.AttrSpec{Name: "ssh_clear_authorized_keys", Type: cty.Bool, Required: false},
+		"ssh_key_exchange_algorithms":            &hcldec.AttrSpec{Name: "ssh_key_exchange_algorithms", Type: cty.List(cty.String), Required: false},
+		"ssh_security_policy":                    &hcldec.AttrSpec{Name: "ssh_security_policy", Type: cty.String, Required: false},
+		"ssh_private_key_file":                   &hcldec.AttrSpec{Name: "ssh_private_key_file", Type: cty.String, Required: false},
+		"ssh_certificate_file":                   &hcldec.AttrSpec{Name: "ssh_certificate_file", Type: cty.String, Required: false},
+		"ssh_pty":                                &hcldec.AttrSpec{Name: "ssh_pty", Type: cty.Bool, Required: false},
+		"ssh_pty_output":                         &hcldec.AttrSpec{Name: "ssh_pty_output", Type: cty.String, Required: false},
+		"ssh_timeout":                            &hcldec.AttrSpec{Name: "ssh_timeout", Type: cty.String, Required: false},
+		"ssh_wait_timeout":                       &hcldec.AttrSpec{Name: "ssh_wait_timeout", Type: cty.String, Required: false},
+		"ssh_agent_auth":                         &hcldec.AttrSpec{Name: "ssh_agent_auth", Type: cty.Bool, Required: false},
+		"ssh_disable_agent_forwarding":           &hcldec.AttrSpec{Name: "ssh_disable_agent_forwarding", Type: cty.Bool, Required: false},
+		"ssh_handshake_attempts":                 &hcldec.AttrSpec{Name: "ssh_handshake_attempts", Type: cty.Number, Required: false},
+		"ssh_handshake_timeout":                  &hcldec.AttrSpec{Name: "ssh_handshake_timeout", Type: cty.String, Required: false},
+		"ssh_banner_read_timeout":                &hcldec.AttrSpec{Name: "ssh_banner_read_timeout", Type: cty.String, Required: false},
+		"ssh_pre_auth_grace_period":              &hcldec.AttrSpec{Name: "ssh_pre_auth_grace_period", Type: cty.String, Required: false},
+		"ssh_bastion_host":                       &hcldec.AttrSpec{Name: "ssh_bastion_host", Type: cty.String, Required: false},
+		"ssh_bastion_port":                       &hcldec.AttrSpec{Name: "ssh_bastion_port", Type: cty.Number, Required: false},
+		"ssh_bastion_agent_auth":                 &hcldec.AttrSpec{Name: "ssh_bastion_agent_auth", Type: cty.Bool, Required: false},
+		"ssh_bastion_username":                   &hcldec.AttrSpec{Name: "ssh_bastion_username", Type: cty.String, Required: false},
+		"ssh_bastion_password":                   &hcldec.AttrSpec{Name: "ssh_bastion_password", Type: cty.String, Required: false},
+		"ssh_bastion_interactive":                &hcldec.AttrSpec{Name: "ssh_bastion_interactive", Type: cty.Bool, Required: false},
+		"ssh_bastion_totp_secret":                &hcldec.AttrSpec{Name: "ssh_bastion_totp_secret", Type: cty.String, Required: false},
+		"ssh_bastion_private_key_file":           &hcldec.AttrSpec{Name: "ssh_bastion_private_key_file", Type: cty.String, Required: false},
+		"ssh_bastion_certificate_file":           &hcldec.AttrSpec{Name: "ssh_bastion_certificate_file", Type: cty.String, Required: false},
+		"ssh_file_transfer_method":               &hcldec.AttrSpec{Name: "ssh_file_transfer_method", Type: cty.String, Required: false},
+		"ssh_sftp_server_binary":                 &hcldec.AttrSpec{Name: "ssh_sftp_server_binary", Type: cty.String, Required: false},
+		"ssh_proxy_host":                         &hcldec.AttrSpec{Name: "ssh_proxy_host", Type: cty.String, Required: false},
+		"ssh_proxy_port":                         &hcldec.AttrSpec{Name: "ssh_proxy_port", Type: cty.Number, Required: false},
+		"ssh_proxy_username":                     &hcldec.AttrSpec{Name: "ssh_proxy_username", Type: cty.String, Required: false},
+		"ssh_proxy_password":                     &hcldec.AttrSpec{Name: "ssh_proxy_password", Type: cty.String, Required: false},
+		"ssh_callback_address":                   &hcldec.AttrSpec{Name: "ssh_callback_address", Type: cty.String, Required: false},
+		"ssh_callback_authorized_keys_file":      &hcldec.AttrSpec{Name: "ssh_callback_authorized_keys_file", Type: cty.String, Required: false},
+		"ssh_callback_host_key_file":             &hcldec.AttrSpec{Name: "ssh_callback_host_key_file", Type: cty.String, Required: false},
+		"ssh_teleport_proxy":                     &hcldec.AttrSpec{Name: "ssh_teleport_proxy", Type: cty.String, Required: false},
+		"ssh_teleport_cluster":                   &hcldec.AttrSpec{Name: "ssh_teleport_cluster", Type: cty.String, Required: false},
+		"ssh_teleport_identity_file":             &hcldec.AttrSpec{Name: "ssh_teleport_identity_file", Type: cty.String, Required: false},
+		"ssh_boundary_target_id":                 &hcldec.AttrSpec{Name: "ssh_boundary_target_id", Type: cty.String, Required: false},
+		"ssh_boundary_host_id":                   &hcldec.AttrSpec{Name: "ssh_boundary_host_id", Type: cty.String, Required: false},
+		"ssh_boundary_addr":                      &hcldec.AttrSpec{Name: "ssh_boundary_addr", Type: cty.String, Required: false},
+		"ssh_websocket_url":                      &hcldec.AttrSpec{Name: "ssh_websocket_url", Type: cty.String, Required: false},
+		"ssh_websocket_token":                    &hcldec.AttrSpec{Name: "ssh_websocket_token", Type: cty.String, Required: false},
+		"ssh_websocket_insecure_skip_tls_verify": &hcldec.AttrSpec{Name: "ssh_websocket_insecure_skip_tls_verify", Type: cty.Bool, Required: false},
+		"ssh_keep_alive_interval":                &hcldec.AttrSpec{Name: "ssh_keep_alive_interval", Type: cty.String, Required: false},
+		"ssh_read_write_timeout":                 &hcldec.AttrSpec{Name: "ssh_read_write_timeout", Type: cty.String, Required: false},
+		"ssh_remote_tunnels":                     &hcldec.AttrSpec{Name: "ssh_remote_tunnels", Type: cty.List(cty.String), Required: false},
+		"ssh_local_tunnels":                      &hcldec.AttrSpec{Name: "ssh_local_tunnels", Type: cty.List(cty.String), Required: false},
+		"ssh_public_key":                         &hcldec.AttrSpec{Name: "ssh_public_key", Type: cty.List(cty.Number), Required: false},
+		"ssh_private_key":                        &hcldec.AttrSpec{Name: "ssh_private_key", Type: cty.List(cty.Number), Required: false},
+		"winrm_username":                         &hcldec.AttrSpec{Name: "winrm_username", Type: cty.String, Required: false},
+		"winrm_password":                         &hcldec.AttrSpec{Name: "winrm_password", Type: cty.String, Required: false},
+		"winrm_host":                             &hcldec.AttrSpec{Name: "winrm_host", Type: cty.String, Required: false},
+		"winrm_no_proxy":                         &hcldec.AttrSpec{Name: "winrm_no_proxy", Type: cty.Bool, Required: false},
+		"winrm_port":                             &hcldec.AttrSpec{Name: "winrm_port", Type: cty.Number, Required: false},
+		"winrm_timeout":                          &hcldec.AttrSpec{Name: "winrm_timeout", Type: cty.String, Required: false},
+		"winrm_use_ssl":                          &hcldec.AttrSpec{Name: "winrm_use_ssl", Type: cty.Bool, Required: false},
+		"winrm_insecure":                         &hcldec.AttrSpec{Name: "winrm_insecure", Type: cty.Bool, Required: false},
+		"winrm_use_ntlm":                         &hcldec.AttrSpec{Name: "winrm_use_ntlm", Type: cty.Bool, Required: false},
+		"winrm_use_psrp":                         &hcldec.AttrSpec{Name: "winrm_use_psrp", Type: cty.Bool, Required: false},
+		"winrm_bastion_host":                     &hcldec.AttrSpec{Name: "winrm_bastion_host", Type: cty.String, Required: false},
+		"winrm_bastion_port":                     &hcldec.AttrSpec{Name: "winrm_bastion_port", Type: cty.Number, Required: false},
+		"winrm_bastion_username":                 &hcldec.AttrSpec{Name: "winrm_bastion_username", Type: cty.String, Required: false},
+		"winrm_bastion_password":                 &hcldec.AttrSpec{Name: "winrm_bastion_password", Type: cty.String, Required: false},
+		"winrm_bastion_private_key_file":         &hcldec.AttrSpec{Name: "winrm_bastion_private_key_file", Type: cty.String, Required: false},
+		"winrm_bastion_agent_auth":               &hcldec.AttrSpec{Name: "winrm_bastion_agent_auth", Type: cty.Bool, Required: false},
+		"winrm_proxy_host":                       &hcldec.AttrSpec{Name: "winrm_proxy_host", Type: cty.String, Required: false},
+		"winrm_proxy_port":                       &hcldec.AttrSpec{Name: "winrm_proxy_port", Type: cty.Number, Required: false},
+		"winrm_proxy_username":                   &hcldec.AttrSpec{Name: "winrm_proxy_username", Type: cty.String, Required: false},
+		"winrm_proxy_password":                   &hcldec.AttrSpec{Name: "winrm_proxy_password", Type: cty.String, Required: false},
+		"credential_rotation":                    &hcldec.AttrSpec{Name: "credential_rotation", Type: cty.String, Required: false},
+		"guest_cleanup":                          &hcldec.AttrSpec{Name: "guest_cleanup", Type: cty.List(cty.String), Required: false},
+		"guest_cleanup_dry_run":                  &hcldec.AttrSpec{Name: "guest_cleanup_dry_run", Type: cty.Bool, Required: false},
+		"sysprep_generalize":                     &hcldec.AttrSpec{Name: "sysprep_generalize", Type: cty.Bool, Required: false},
+		"sysprep_unattend_file":                  &hcldec.AttrSpec{Name: "sysprep_unattend_file", Type: cty.String, Required: false},
+		"sysprep_mode_vm":                        &hcldec.AttrSpec{Name: "sysprep_mode_vm", Type: cty.Bool, Required: false},
+		"sysprep_timeout":                        &hcldec.AttrSpec{Name: "sysprep_timeout", Type: cty.String, Required: false},
+		"linux_generalize":                       &hcldec.AttrSpec{Name: "linux_generalize", Type: cty.Bool, Required: false},
+		"linux_generalize_operations":            &hcldec.AttrSpec{Name: "linux_generalize_operations", Type: cty.List(cty.String), Required: false},
+		"linux_generalize_skip":                  &hcldec.AttrSpec{Name: "linux_generalize_skip", Type: cty.List(cty.String), Required: false},
+		"remote_shell":                           &hcldec.AttrSpec{Name: "remote_shell", Type: cty.String, Required: false},
+		"remote_locale":                          &hcldec.AttrSpec{Name: "remote_locale", Type: cty.String, Required: false},
+		"remote_path":                            &hcldec.AttrSpec{Name: "remote_path", Type: cty.String, Required: false},
+		"clock_skew_check":                       &hcldec.AttrSpec{Name: "clock_skew_check", Type: cty.Bool, Required: false},
+		"clock_max_skew":                         &hcldec.AttrSpec{Name: "clock_max_skew", Type: cty.String, Required: false},
+		"clock_skew_fix":                         &hcldec.AttrSpec{Name: "clock_skew_fix", Type: cty.Bool, Required: false},
+		"ssh_interface":                          &hcldec.AttrSpec{Name: "ssh_interface", Type: cty.String, Required: false},
+		"session_manager_port":                   &hcldec.AttrSpec{Name: "session_manager_port", Type: cty.Number, Required: false},
+		"ena_support":                            &hcldec.AttrSpec{Name: "ena_support", Type: cty.Bool, Required: false},
+		"sriov_support":                          &hcldec.AttrSpec{Name: "sriov_support", Type: cty.Bool, Required: false},
+		"ebs_volumes":                            &hcldec.BlockListSpec{TypeName: "ebs_volumes", Nested: hcldec.ObjectSpec((*FlatBlockDevice)(nil).HCL2Spec())},
+		"run_volume_tags":                        &hcldec.AttrSpec{Name: "run_volume_tags", Type: cty.Map(cty.String), Required: false},
+		"run_volume_tag":                         &hcldec.BlockListSpec{TypeName: "run_volume_tag", Nested: hcldec.ObjectSpec((*hcl2template.FlatKeyValue)(nil).HCL2Spec())},
 	}
 	return s
 }
@@ -19,6 +19,9 @@ import (
 	"github.com/hashicorp/packer/builder/azure/common/constants"
 	"github.com/hashicorp/packer/builder/azure/common/lin"
 	packerCommon "github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/cost"
+	"github.com/hashicorp/packer/common/firewall"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -51,6 +54,14 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, errs
 }
 
+func (b *Builder) PlannedResources() []string {
+	resources := []string{"temporary resource group, virtual machine, key vault and network"}
+	if b.config.FirewallConfig.TemporaryFirewallRule {
+		resources = append(resources, "temporary firewall rule")
+	}
+	return append(resources, "managed image or VHD")
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 
 	ui.Say("Running builder ...")
@@ -90,7 +101,8 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		b.config.SharedGalleryTimeout,
 		b.config.PollingDurationTimeout,
 		spnCloud,
-		spnKeyVault)
+		spnKeyVault,
+		b.config.ClientConfig.Sender())
 
 	if err != nil {
 		return nil, err
@@ -201,7 +213,13 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	var steps []multistep.Step
 	if b.config.OSType == constants.Target_Linux {
 		steps = []multistep.Step{
+			&cost.StepMeter{Prices: Prices},
 			NewStepCreateResourceGroup(azureClient, ui),
+			&firewall.StepTemporaryRule{
+				Config: &b.config.FirewallConfig,
+				Comm:   &b.config.Comm,
+				Create: b.config.allowFirewallRule,
+			},
 			NewStepValidateTemplate(azureClient, ui, &b.config, GetVirtualMachineDeployment),
 			NewStepDeployTemplate(azureClient, ui, &b.config, deploymentName, GetVirtualMachineDeployment),
 			NewStepGetIPAddress(azureClient, ui, endpointConnectType),
@@ -210,9 +228,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 				Host:      lin.SSHHost,
 				SSHConfig: b.config.Comm.SSHConfigFunc(),
 			},
+			&guest.StepCheckClock{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepEnvironment{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
 			&packerCommon.StepProvision{},
-			&packerCommon.StepCleanupTempKeys{
-				Comm: &b.config.Comm,
+			&guest.StepRotateCredentials{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepCleanup{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepLinuxGeneralize{
+				Config: &b.config.Guest,
+			},
+			&guest.StepSysprep{
+				Config: &b.config.Guest,
 			},
 			NewStepGetOSDisk(azureClient, ui),
 			NewStepGetAdditionalDisks(azureClient, ui),
@@ -227,7 +264,13 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		}
 	} else if b.config.OSType == constants.Target_Windows {
 		steps = []multistep.Step{
+			&cost.StepMeter{Prices: Prices},
 			NewStepCreateResourceGroup(azureClient, ui),
+			&firewall.StepTemporaryRule{
+				Config: &b.config.FirewallConfig,
+				Comm:   &b.config.Comm,
+				Create: b.config.allowFirewallRule,
+			},
 		}
 		if b.config.BuildKeyVaultName == "" {
 			keyVaultDeploymentName := b.stateBag.Get(constants.ArmKeyVaultDeploymentName).(string)
@@ -238,8 +281,14 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		} else {
 			steps = append(steps, NewStepCertificateInKeyVault(&azureClient.VaultClient, ui, &b.config))
 		}
+		if b.config.PrivateNetworkOnly && b.config.BuildKeyVaultName == "" {
+			resourceGroupName := b.stateBag.Get(constants.ArmResourceGroupName).(string)
+			keyVaultDeploymentName := b.stateBag.Get(constants.ArmKeyVaultDeploymentName).(string)
+			steps = append(steps, NewStepGetCertificateFromDeployment(azureClient, ui, resourceGroupName, keyVaultDeploymentName))
+		} else {
+			steps = append(steps, NewStepGetCertificate(azureClient, ui))
+		}
 		steps = append(steps,
-			NewStepGetCertificate(azureClient, ui),
 			NewStepSetCertificate(&b.config, ui),
 			NewStepValidateTemplate(azureClient, ui, &b.config, GetVirtualMachineDeployment),
 			NewStepDeployTemplate(azureClient, ui, &b.config, deploymentName, GetVirtualMachineDeployment),
@@ -256,7 +305,29 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 					}, nil
 				},
 			},
+			&guest.StepCheckClock{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepEnvironment{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
 			&packerCommon.StepProvision{},
+			&guest.StepRotateCredentials{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepCleanup{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepLinuxGeneralize{
+				Config: &b.config.Guest,
+			},
+			&guest.StepSysprep{
+				Config: &b.config.Guest,
+			},
 			NewStepGetOSDisk(azureClient, ui),
 			NewStepGetAdditionalDisks(azureClient, ui),
 			NewStepPowerOffCompute(azureClient, ui),
@@ -394,6 +465,7 @@ func (b *Builder) configureStateBag(stateBag multistep.StateBag) {
 	stateBag.Put(constants.AuthorizedKey, b.config.sshAuthorizedKey)
 
 	stateBag.Put(constants.ArmTags, b.config.AzureTags)
+	stateBag.Put(constants.ArmBillingTags, b.config.billingTags())
 	stateBag.Put(constants.ArmComputeName, b.config.tmpComputeName)
 	stateBag.Put(constants.ArmDeploymentName, b.config.tmpDeploymentName)
 
@@ -0,0 +1,60 @@
+package arm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-02-01/resources"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/hashicorp/packer/common/cleanup"
+)
+
+// CleanupProvider is the provider name of the resources the azure-arm
+// builder tracks in the cleanup registry.
+const CleanupProvider = "azure"
+
+// CleanupResourceGroup is the type of the temporary resource groups the
+// azure-arm builder tracks in the cleanup registry. Removing the group
+// removes everything the build deployed in it.
+const CleanupResourceGroup = "resource-group"
+
+func trackResourceGroup(subscriptionID, location, name string) {
+	cleanup.Track(cleanup.Resource{
+		Provider: CleanupProvider,
+		Type:     CleanupResourceGroup,
+		ID:       name,
+		Region:   location,
+		Account:  subscriptionID,
+	})
+}
+
+func untrackResourceGroup(name string) {
+	cleanup.Untrack(CleanupProvider, CleanupResourceGroup, name)
+}
+
+// SweepResource removes a resource left behind by the azure-arm builder. It
+// authenticates with the AZURE_* environment variables, like
+// AZURE_CLIENT_ID and AZURE_CLIENT_SECRET, or with the managed identity of
+// the machine.
+func SweepResource(ctx context.Context, r cleanup.Resource) error {
+	if r.Type != CleanupResourceGroup {
+		return fmt.Errorf("unknown resource type %q", r.Type)
+	}
+	authorizer, err := auth.NewAuthorizerFromEnvironment()
+	if err != nil {
+		return err
+	}
+	client := resources.NewGroupsClient(r.Account)
+	client.Authorizer = authorizer
+
+	f, err := client.Delete(ctx, r.ID)
+	if err == nil {
+		err = f.WaitForCompletionRef(ctx, client.Client)
+	}
+	if de, ok := err.(autorest.DetailedError); ok && de.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
@@ -29,6 +29,8 @@ import (
 	"github.com/hashicorp/packer/builder/azure/common/constants"
 	"github.com/hashicorp/packer/builder/azure/pkcs12"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/firewall"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/hcl2template"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -304,6 +306,16 @@ type Config struct {
 	// containing the virtual network. If the resource group cannot be found, or
 	// it cannot be disambiguated, this value should be set.
 	VirtualNetworkResourceGroupName string `mapstructure:"virtual_network_resource_group_name" required:"false"`
+	// Build without any public endpoint, for environments forbidding public
+	// IP addresses. Requires virtual_network_name: the VM gets no public IP
+	// and Packer connects to its private IP, which must be reachable from the
+	// Packer host through the virtual network, a peered virtual network, a
+	// VPN or a bastion host (see `ssh_bastion_host`). For Windows builds, the
+	// temporary key vault denies public network access and the URL of the
+	// WinRM certificate is read from its deployment instead; a
+	// build_key_vault_name key vault must be reachable through its private
+	// endpoint.
+	PrivateNetworkOnly bool `mapstructure:"private_network_only" required:"false"`
 	// Specify a file containing custom data to inject into the cloud-init
 	// process. The contents of the file are read and injected into the ARM
 	// template. The custom data will be passed to cloud-init for processing at
@@ -395,6 +407,12 @@ type Config struct {
 	// Providing `allowed_inbound_ip_addresses` in combination with
 	// `virtual_network_name` is not allowed.
 	AllowedInboundIpAddresses []string `mapstructure:"allowed_inbound_ip_addresses"`
+	// The temporary firewall rule of the communicator port: the network
+	// security group of `allowed_inbound_ip_addresses`, allowing the public
+	// IP address of Packer. It is deleted with the other temporary resources
+	// of the build. It can't be used with `allowed_inbound_ip_addresses` or
+	// `virtual_network_name`.
+	FirewallConfig firewall.Config `mapstructure:",squash"`
 
 	// Specify storage to store Boot Diagnostics -- Enabling this option
 	// will create 2 Files in the specified storage account. (serial console log & screehshot file)
@@ -431,8 +449,9 @@ type Config struct {
 	// Authentication with the VM via WinRM
 	winrmCertificate string
 
-	Comm communicator.Config `mapstructure:",squash"`
-	ctx  interpolate.Context
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
+	ctx   interpolate.Context
 	// If you want packer to delete the
 	// temporary resource group asynchronously set this value. It's a boolean
 	// value and defaults to false. Important Setting this true means that
@@ -596,6 +615,7 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 
 	var errs *packer.MultiError
 	errs = packer.MultiErrorAppend(errs, c.Comm.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.Guest.Prepare(&c.Comm)...)
 
 	assertRequiredParametersSet(c, errs)
 	assertTagProperties(c, errs)
@@ -764,6 +784,38 @@ func provideDefaultValues(c *Config) {
 	c.ClientConfig.SetDefaultValues()
 }
 
+// billingTags returns the billing tags of the build, for its temporary
+// resources.
+func (c *Config) billingTags() map[string]*string {
+	tags := make(map[string]*string, len(c.PackerBillingTags))
+	for k, v := range c.PackerBillingTags {
+		v := v
+		tags[k] = &v
+	}
+	return tags
+}
+
+// temporaryTags returns the tags of the temporary resources of the build:
+// the azure_tags and the billing tags of the build.
+func (c *Config) temporaryTags() map[string]*string {
+	return mergeTags(c.AzureTags, c.billingTags())
+}
+
+// mergeTags returns the tags with the extra tags, which win.
+func mergeTags(tags, extra map[string]*string) map[string]*string {
+	if len(extra) == 0 {
+		return tags
+	}
+	merged := make(map[string]*string, len(tags)+len(extra))
+	for k, v := range tags {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
 func assertTagProperties(c *Config, errs *packer.MultiError) {
 	if len(c.AzureTags) > 15 {
 		errs = packer.MultiErrorAppend(errs, fmt.Errorf("a max of 15 tags are supported, but %d were provided", len(c.AzureTags)))
@@ -1008,6 +1060,26 @@ func assertRequiredParametersSet(c *Config, errs *packer.MultiError) {
 	if c.VirtualNetworkName == "" && c.VirtualNetworkSubnetName != "" {
 		errs = packer.MultiErrorAppend(errs, fmt.Errorf("If virtual_network_subnet_name is specified, so must virtual_network_name"))
 	}
+	if c.PrivateNetworkOnly {
+		if c.VirtualNetworkName == "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("If private_network_only is specified, so must virtual_network_name"))
+		}
+		if c.PrivateVirtualNetworkWithPublicIp {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("If private_network_only is specified, private_virtual_network_with_public_ip cannot be specified"))
+		}
+	}
+
+	for _, err := range c.FirewallConfig.Prepare() {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+	if c.FirewallConfig.TemporaryFirewallRule {
+		if c.VirtualNetworkName != "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("If virtual_network_name is specified, temporary_firewall_rule cannot be specified"))
+		}
+		if len(c.AllowedInboundIpAddresses) > 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("If allowed_inbound_ip_addresses is specified, temporary_firewall_rule cannot be specified"))
+		}
+	}
 
 	if c.AllowedInboundIpAddresses != nil && len(c.AllowedInboundIpAddresses) >= 1 {
 		if c.VirtualNetworkName != "" {
@@ -425,6 +425,39 @@ func TestConfigShouldAcceptCorrectInboundIpAddresses(t *testing.T) {
 	}
 }
 
+func TestConfigShouldRejectTemporaryFirewallRuleConflicts(t *testing.T) {
+	config := map[string]interface{}{
+		"capture_name_prefix":     "ignore",
+		"capture_container_name":  "ignore",
+		"location":                "ignore",
+		"image_url":               "ignore",
+		"storage_account":         "ignore",
+		"resource_group_name":     "ignore",
+		"subscription_id":         "ignore",
+		"os_type":                 constants.Target_Linux,
+		"communicator":            "none",
+		"temporary_firewall_rule": true,
+	}
+
+	var c Config
+	if _, err := c.Prepare(config, getPackerConfiguration()); err != nil {
+		t.Fatal(err)
+	}
+
+	config["allowed_inbound_ip_addresses"] = []string{"127.0.0.1"}
+	c = Config{}
+	if _, err := c.Prepare(config, getPackerConfiguration()); err == nil {
+		t.Error("Expected configuration creation to fail with both temporary_firewall_rule and allowed_inbound_ip_addresses")
+	}
+
+	delete(config, "allowed_inbound_ip_addresses")
+	config["virtual_network_name"] = "ignore"
+	c = Config{}
+	if _, err := c.Prepare(config, getPackerConfiguration()); err == nil {
+		t.Error("Expected configuration creation to fail with both temporary_firewall_rule and virtual_network_name")
+	}
+}
+
 func TestConfigShouldRejectIncorrectInboundIpAddresses(t *testing.T) {
 	config := map[string]interface{}{
 		"capture_name_prefix":    "ignore",
@@ -453,6 +486,39 @@ func TestConfigShouldRejectIncorrectInboundIpAddresses(t *testing.T) {
 	}
 }
 
+func TestConfigPrivateNetworkOnlyMustBeSetWithVirtualNetworkName(t *testing.T) {
+	config := map[string]interface{}{
+		"capture_name_prefix":    "ignore",
+		"capture_container_name": "ignore",
+		"location":               "ignore",
+		"image_url":              "ignore",
+		"storage_account":        "ignore",
+		"resource_group_name":    "ignore",
+		"subscription_id":        "ignore",
+		"os_type":                constants.Target_Linux,
+		"communicator":           "none",
+		"private_network_only":   true,
+	}
+
+	var c Config
+	_, err := c.Prepare(config, getPackerConfiguration())
+	if err == nil {
+		t.Error("Expected Config to reject private_network_only, if virtual_network_name is not set.")
+	}
+
+	config["virtual_network_name"] = "some_vnet_name"
+	_, err = c.Prepare(config, getPackerConfiguration())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config["private_virtual_network_with_public_ip"] = true
+	_, err = c.Prepare(config, getPackerConfiguration())
+	if err == nil {
+		t.Error("Expected Config to reject private_network_only with private_virtual_network_with_public_ip.")
+	}
+}
+
 func TestConfigShouldRejectInboundIpAddressesWithVirtualNetwork(t *testing.T) {
 	config := map[string]interface{}{
 		"capture_name_prefix":          "ignore",
@@ -939,6 +1005,42 @@ func TestConfigShouldAcceptTags(t *testing.T) {
 	}
 }
 
+func TestConfigTemporaryTags(t *testing.T) {
+	config := map[string]interface{}{
+		"capture_name_prefix":    "ignore",
+		"capture_container_name": "ignore",
+		"image_offer":            "ignore",
+		"image_publisher":        "ignore",
+		"image_sku":              "ignore",
+		"location":               "ignore",
+		"storage_account":        "ignore",
+		"resource_group_name":    "ignore",
+		"subscription_id":        "ignore",
+		"communicator":           "none",
+		"os_type":                constants.Target_Linux,
+		"azure_tags": map[string]string{
+			"team": "web",
+			"env":  "prod",
+		},
+		"packer_billing_tags": map[string]string{
+			"team": "images",
+		},
+	}
+
+	var c Config
+	if _, err := c.Prepare(config, getPackerConfiguration()); err != nil {
+		t.Fatal(err)
+	}
+
+	if *c.AzureTags["team"] != "web" {
+		t.Errorf("the billing tags shouldn't change azure_tags, got %q", *c.AzureTags["team"])
+	}
+	tags := c.temporaryTags()
+	if len(tags) != 2 || *tags["team"] != "images" || *tags["env"] != "prod" {
+		t.Errorf("unexpected temporary tags %v", tags)
+	}
+}
+
 func TestConfigShouldRejectTagsInExcessOf15AcceptTags(t *testing.T) {
 	tooManyTags := map[string]string{}
 	for i := 0; i < 16; i++ {
@@ -0,0 +1,57 @@
+package arm
+
+import (
+	"github.com/hashicorp/packer/builder/azure/common/constants"
+	"github.com/hashicorp/packer/common/cost"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// Prices are the default prices of the azure-arm builder, in USD: the
+// pay-as-you-go prices of Linux virtual machines and the prices of managed
+// disks in eastus.
+var Prices = cost.Prices{
+	"instance/Standard_A1":     0.06,
+	"instance/Standard_B1s":    0.0104,
+	"instance/Standard_B1ms":   0.0207,
+	"instance/Standard_B2s":    0.0416,
+	"instance/Standard_B2ms":   0.0832,
+	"instance/Standard_D2_v3":  0.096,
+	"instance/Standard_D2s_v3": 0.096,
+	"instance/Standard_D4s_v3": 0.192,
+	"instance/Standard_D2s_v4": 0.096,
+	"instance/Standard_D4s_v4": 0.192,
+	"instance/Standard_DS1_v2": 0.073,
+	"instance/Standard_DS2_v2": 0.146,
+	"instance/Standard_F2s_v2": 0.085,
+	"storage/Standard_LRS":     0.045,
+	"storage/StandardSSD_LRS":  0.075,
+	"storage/Premium_LRS":      0.135,
+}
+
+// defaultOSDiskSizeGB is the size of the OS disk of the images, when
+// os_disk_size_gb is not set.
+var defaultOSDiskSizeGB = map[string]float64{
+	constants.Target_Linux:   30,
+	constants.Target_Windows: 127,
+}
+
+// meterVirtualMachine starts metering the virtual machine of the build and
+// its OS disk.
+func meterVirtualMachine(state multistep.StateBag, config *Config) {
+	m := cost.FromState(state)
+	if m == nil {
+		return
+	}
+	computeName := state.Get(constants.ArmComputeName).(string)
+	m.Start(cost.Instance, computeName, config.VMSize, 0)
+
+	size := float64(config.OSDiskSizeGB)
+	if size == 0 {
+		size = defaultOSDiskSizeGB[config.OSType]
+	}
+	storageType := string(config.managedImageStorageAccountType)
+	if storageType == "" {
+		storageType = "Standard_LRS"
+	}
+	m.Start(cost.Storage, computeName+"-osdisk", storageType, size)
+}
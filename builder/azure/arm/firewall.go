@@ -0,0 +1,17 @@
+package arm
+
+import (
+	"context"
+
+	"github.com/hashicorp/packer/common/firewall"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// allowFirewallRule makes the deployment of the virtual machine create the
+// temporary firewall rule, as the network security group of
+// allowed_inbound_ip_addresses. The rule is deleted with the resources of the
+// deployment.
+func (c *Config) allowFirewallRule(_ context.Context, _ multistep.StateBag, rule *firewall.Rule) error {
+	c.AllowedInboundIpAddresses = rule.SourceCidrs
+	return nil
+}
@@ -39,8 +39,10 @@ func (s *StepCreateResourceGroup) createResourceGroup(ctx context.Context, resou
 
 	if err != nil {
 		s.say(s.client.LastError.Error())
+		return err
 	}
-	return err
+	trackResourceGroup(s.client.GroupsClient.SubscriptionID, location, resourceGroupName)
+	return nil
 }
 
 func (s *StepCreateResourceGroup) doesResourceGroupExist(ctx context.Context, resourceGroupName string) (bool, error) {
@@ -62,6 +64,9 @@ func (s *StepCreateResourceGroup) Run(ctx context.Context, state multistep.State
 	var resourceGroupName = state.Get(constants.ArmResourceGroupName).(string)
 	var location = state.Get(constants.ArmLocation).(string)
 	var tags = state.Get(constants.ArmTags).(map[string]*string)
+	if billingTags, ok := state.GetOk(constants.ArmBillingTags); ok {
+		tags = mergeTags(tags, billingTags.(map[string]*string))
+	}
 
 	exists, err := s.exists(ctx, resourceGroupName)
 	if err != nil {
@@ -115,7 +120,7 @@ func (s *StepCreateResourceGroup) Cleanup(state multistep.StateBag) {
 		ui.Say("\nCleanup requested, deleting resource group ...")
 
 		var resourceGroupName = state.Get(constants.ArmResourceGroupName).(string)
-		ctx := context.TODO()
+		ctx := multistep.CleanupContext(state)
 		f, err := s.client.GroupsClient.Delete(ctx, resourceGroupName)
 		if err == nil {
 			if state.Get(constants.ArmAsyncResourceGroupDelete).(bool) {
@@ -130,6 +135,7 @@ func (s *StepCreateResourceGroup) Cleanup(state multistep.StateBag) {
 				"Error: %s", resourceGroupName, err))
 			return
 		}
+		untrackResourceGroup(resourceGroupName)
 		if !state.Get(constants.ArmAsyncResourceGroupDelete).(bool) {
 			ui.Say("Resource group has been deleted.")
 		}
@@ -83,9 +83,12 @@ func (s *StepDeployTemplate) Run(ctx context.Context, state multistep.StateBag)
 	s.say(fmt.Sprintf(" -> ResourceGroupName : '%s'", resourceGroupName))
 	s.say(fmt.Sprintf(" -> DeploymentName    : '%s'", s.name))
 
-	return processStepResult(
-		s.deploy(ctx, resourceGroupName, s.name),
-		s.error, state)
+	err := s.deploy(ctx, resourceGroupName, s.name)
+	if err == nil && s.name == state.Get(constants.ArmDeploymentName) {
+		meterVirtualMachine(state, s.config)
+	}
+
+	return processStepResult(err, s.error, state)
 }
 
 func (s *StepDeployTemplate) getImageDetails(ctx context.Context, resourceGroupName string, computeName string) (string, string, error) {
@@ -176,7 +179,8 @@ func (s *StepDeployTemplate) deleteImage(ctx context.Context, imageType string,
 }
 
 func (s *StepDeployTemplate) Cleanup(state multistep.StateBag) {
-	defer s.deleteTemplate(context.Background(), state)
+	ctx := multistep.CleanupContext(state)
+	defer s.deleteTemplate(ctx, state)
 
 	//Only clean up if this was an existing resource group and the resource group
 	//is marked as created
@@ -191,7 +195,7 @@ func (s *StepDeployTemplate) Cleanup(state multistep.StateBag) {
 	var resourceGroupName = state.Get(constants.ArmResourceGroupName).(string)
 	var computeName = state.Get(constants.ArmComputeName).(string)
 	var deploymentName = s.name
-	imageType, imageName, err := s.disk(context.TODO(), resourceGroupName, computeName)
+	imageType, imageName, err := s.disk(ctx, resourceGroupName, computeName)
 	if err != nil {
 		ui.Error("Could not retrieve OS Image details")
 	}
@@ -199,7 +203,7 @@ func (s *StepDeployTemplate) Cleanup(state multistep.StateBag) {
 	ui.Say(" -> Deployment Resources within: " + deploymentName)
 	if deploymentName != "" {
 		maxResources := int32(50)
-		deploymentOperations, err := s.client.DeploymentOperationsClient.ListComplete(context.TODO(), resourceGroupName, deploymentName, &maxResources)
+		deploymentOperations, err := s.client.DeploymentOperationsClient.ListComplete(ctx, resourceGroupName, deploymentName, &maxResources)
 		if err != nil {
 			ui.Error(fmt.Sprintf("Error deleting resources.  Please delete them manually.\n\n"+
 				"Name: %s\n"+
@@ -215,7 +219,7 @@ func (s *StepDeployTemplate) Cleanup(state multistep.StateBag) {
 			ui.Say(fmt.Sprintf(" -> %s : '%s'",
 				*deploymentOperation.Properties.TargetResource.ResourceType,
 				*deploymentOperation.Properties.TargetResource.ResourceName))
-			err = s.delete(context.TODO(), s.client,
+			err = s.delete(ctx, s.client,
 				*deploymentOperation.Properties.TargetResource.ResourceType,
 				*deploymentOperation.Properties.TargetResource.ResourceName,
 				resourceGroupName)
@@ -235,7 +239,7 @@ func (s *StepDeployTemplate) Cleanup(state multistep.StateBag) {
 		// The disk is not defined as an operation in the template so has to be
 		// deleted separately
 		ui.Say(fmt.Sprintf(" -> %s : '%s'", imageType, imageName))
-		err = s.deleteDisk(context.TODO(), imageType, imageName, resourceGroupName)
+		err = s.deleteDisk(ctx, imageType, imageName, resourceGroupName)
 		if err != nil {
 			ui.Error(fmt.Sprintf("Error deleting resource.  Please delete manually.\n\n"+
 				"Name: %s\n"+
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-02-01/resources"
 	"github.com/hashicorp/packer/builder/azure/common/constants"
+	"github.com/hashicorp/packer/builder/azure/common/template"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
@@ -30,6 +32,17 @@ func NewStepGetCertificate(client *AzureClient, ui packer.Ui) *StepGetCertificat
 	return step
 }
 
+// NewStepGetCertificateFromDeployment reads the certificate's URL from the
+// outputs of the Key Vault deployment, for Key Vaults denying public network
+// access.
+func NewStepGetCertificateFromDeployment(client *AzureClient, ui packer.Ui, resourceGroupName string, deploymentName string) *StepGetCertificate {
+	var step = NewStepGetCertificate(client, ui)
+	step.get = func(string, string) (string, error) {
+		return step.getCertificateUrlFromDeployment(resourceGroupName, deploymentName)
+	}
+	return step
+}
+
 func (s *StepGetCertificate) getCertificateUrl(keyVaultName string, secretName string) (string, error) {
 	secret, err := s.client.GetSecret(keyVaultName, secretName)
 	if err != nil {
@@ -40,6 +53,31 @@ func (s *StepGetCertificate) getCertificateUrl(keyVaultName string, secretName s
 	return *secret.ID, err
 }
 
+func (s *StepGetCertificate) getCertificateUrlFromDeployment(resourceGroupName string, deploymentName string) (string, error) {
+	deployment, err := s.client.DeploymentsClient.Get(context.TODO(), resourceGroupName, deploymentName)
+	if err != nil {
+		s.say(s.client.LastError.Error())
+		return "", err
+	}
+
+	return deploymentOutput(deployment.Properties, template.KeyVaultSecretUriOutput)
+}
+
+// deploymentOutput returns the string value of the name output of a
+// deployment.
+func deploymentOutput(properties *resources.DeploymentPropertiesExtended, name string) (string, error) {
+	if properties != nil {
+		if outputs, ok := properties.Outputs.(map[string]interface{}); ok {
+			if output, ok := outputs[name].(map[string]interface{}); ok {
+				if value, ok := output["value"].(string); ok {
+					return value, nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("the deployment has no %q output", name)
+}
+
 func (s *StepGetCertificate) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	s.say("Getting the certificate's URL ...")
 
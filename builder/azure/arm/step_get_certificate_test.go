@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-02-01/resources"
+
 	"github.com/hashicorp/packer/builder/azure/common/constants"
 	"github.com/hashicorp/packer/helper/multistep"
 )
@@ -97,3 +99,29 @@ func createTestStateBagStepGetCertificate() multistep.StateBag {
 
 	return stateBag
 }
+
+func TestDeploymentOutput(t *testing.T) {
+	properties := &resources.DeploymentPropertiesExtended{
+		Outputs: map[string]interface{}{
+			"keyVaultSecretUri": map[string]interface{}{
+				"type":  "String",
+				"value": "https://vault.vault.azure.net/secrets/packerKeyVaultSecret/1",
+			},
+		},
+	}
+
+	url, err := deploymentOutput(properties, "keyVaultSecretUri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://vault.vault.azure.net/secrets/packerKeyVaultSecret/1" {
+		t.Fatalf("Expected the certificate URL, but got %q", url)
+	}
+
+	if _, err := deploymentOutput(properties, "missing"); err == nil {
+		t.Fatal("Expected a missing output to fail")
+	}
+	if _, err := deploymentOutput(nil, "keyVaultSecretUri"); err == nil {
+		t.Fatal("Expected a deployment without properties to fail")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/packer/builder/azure/common/constants"
+	"github.com/hashicorp/packer/common/cost"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
@@ -48,6 +49,10 @@ func (s *StepPowerOffCompute) Run(ctx context.Context, state multistep.StateBag)
 	s.say(fmt.Sprintf(" -> ComputeName       : '%s'", computeName))
 
 	err := s.powerOff(ctx, resourceGroupName, computeName)
+	if err == nil {
+		// A deallocated machine is no longer billed, its disks still are.
+		cost.FromState(state).Stop(cost.Instance, computeName)
+	}
 
 	return processStepResult(err, s.error, state)
 }
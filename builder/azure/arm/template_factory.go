@@ -24,7 +24,13 @@ func GetKeyVaultDeployment(config *Config) (*resources.Deployment, error) {
 	}
 
 	builder, _ := template.NewTemplateBuilder(template.KeyVault)
-	builder.SetTags(&config.AzureTags)
+	tags := config.temporaryTags()
+	builder.SetTags(&tags)
+	if config.PrivateNetworkOnly {
+		if err := builder.SetKeyVaultPrivateAccess(); err != nil {
+			return nil, err
+		}
+	}
 
 	doc, _ := builder.ToJSON()
 	return createDeploymentParameters(*doc, params)
@@ -176,7 +182,8 @@ func GetVirtualMachineDeployment(config *Config) (*resources.Deployment, error)
 		}
 	}
 
-	err = builder.SetTags(&config.AzureTags)
+	tags := config.temporaryTags()
+	err = builder.SetTags(&tags)
 	if err != nil {
 		return nil, err
 	}
@@ -610,6 +610,27 @@ func TestKeyVaultDeployment03(t *testing.T) {
 	}
 }
 
+// Ensure the KeyVault template denies public network access when building
+// over a private network only.
+func TestKeyVaultDeployment04(t *testing.T) {
+	config := map[string]interface{}{
+		"virtual_network_name": "--virtual_network_name--",
+		"private_network_only": true,
+	}
+
+	var c Config
+	c.Prepare(config, getArmBuilderConfigurationWithWindows(), getPackerConfiguration())
+	deployment, err := GetKeyVaultDeployment(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = approvaltests.VerifyJSONStruct(t, deployment.Properties.Template)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestPlanInfo01(t *testing.T) {
 	planInfo := map[string]interface{}{
 		"plan_info": map[string]string{
@@ -179,6 +179,9 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	for _, err := range b.config.ClientConfig.RateLimitConfig.Prepare() {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
 
 	if b.config.ChrootMounts == nil {
 		b.config.ChrootMounts = make([][]string, 0)
@@ -390,6 +393,10 @@ func checkHyperVGeneration(s string) interface{} {
 		s, compute.PossibleHyperVGenerationValues())
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"temporary managed disk", "managed image or shared image version"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	if runtime.GOOS != "linux" {
 		return nil, errors.New("the azure-chroot builder only works on Linux environments")
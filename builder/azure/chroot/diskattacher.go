@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/packer/builder/azure/common/client"
+	"github.com/hashicorp/packer/common/wait"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
 	"github.com/Azure/go-autorest/autorest/azure"
@@ -45,23 +46,27 @@ func (diskAttacher) DiskPathForLun(lun int32) string {
 func (da diskAttacher) WaitForDevice(ctx context.Context, lun int32) (device string, err error) {
 	path := da.DiskPathForLun(lun)
 
-	for {
-		link, err := os.Readlink(path)
+	var link string
+	err = wait.Config{
+		Description: fmt.Sprintf("device of lun %d", lun),
+		MinDelay:    100 * time.Millisecond,
+		MaxDelay:    time.Second,
+	}.Until(ctx, func(context.Context) (bool, string, error) {
+		var err error
+		link, err = os.Readlink(path)
 		if err == nil {
-			return filepath.Abs("/dev/disk/azure/scsi1/" + link)
+			return true, link, nil
 		} else if err != os.ErrNotExist {
 			if pe, ok := err.(*os.PathError); ok && pe.Err != syscall.ENOENT {
-				return "", err
+				return false, "", err
 			}
 		}
-
-		select {
-		case <-time.After(100 * time.Millisecond):
-			// continue
-		case <-ctx.Done():
-			return "", ctx.Err()
-		}
+		return false, "missing", nil
+	})
+	if err != nil {
+		return "", err
 	}
+	return filepath.Abs("/dev/disk/azure/scsi1/" + link)
 }
 
 func (da *diskAttacher) DetachDisk(ctx context.Context, diskID string) error {
@@ -93,22 +98,22 @@ func (da *diskAttacher) DetachDisk(ctx context.Context, diskID string) error {
 }
 
 func (da *diskAttacher) WaitForDetach(ctx context.Context, diskID string) error {
-	for { // loop until disk is not attached, timeout or error
+	// loop until disk is not attached, timeout or error
+	return wait.Config{
+		Description: fmt.Sprintf("disk %s to detach", diskID),
+		MinDelay:    time.Second,
+		MaxDelay:    10 * time.Second,
+	}.Until(ctx, func(ctx context.Context) (bool, string, error) {
 		list, err := da.getDisks(ctx)
 		if err != nil {
-			return err
+			return false, "", err
 		}
 		if findDiskInList(list, diskID) == nil {
 			log.Println("Disk is no longer in VM model, assuming detached")
-			return nil
+			return true, "detached", nil
 		}
-
-		select {
-		case <-time.After(time.Second): //continue
-		case <-ctx.Done():
-			return ctx.Err()
-		}
-	}
+		return false, "attached", nil
+	})
 }
 
 var DiskNotFoundError = errors.New("Disk not found")
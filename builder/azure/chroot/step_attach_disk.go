@@ -74,7 +74,7 @@ func (s *StepAttachDisk) CleanupFunc(state multistep.StateBag) error {
 		ui.Say(fmt.Sprintf("Detaching disk '%s'", diskResourceID))
 
 		da := NewDiskAttacher(azcli)
-		err := da.DetachDisk(context.Background(), diskResourceID)
+		err := da.DetachDisk(multistep.CleanupContext(state), diskResourceID)
 		if err != nil {
 			return fmt.Errorf("error detaching %q: %v", diskResourceID, err)
 		}
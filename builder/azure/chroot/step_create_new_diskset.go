@@ -212,6 +212,7 @@ func (s StepCreateNewDiskset) getDatadiskDefinitionFromImage(lun int32) compute.
 }
 
 func (s *StepCreateNewDiskset) Cleanup(state multistep.StateBag) {
+	ctx := multistep.CleanupContext(state)
 	if !s.SkipCleanup {
 		azcli := state.Get("azureclient").(client.AzureClientSet)
 		ui := state.Get("ui").(packer.Ui)
@@ -219,16 +220,16 @@ func (s *StepCreateNewDiskset) Cleanup(state multistep.StateBag) {
 		for _, d := range s.disks {
 
 			ui.Say(fmt.Sprintf("Waiting for disk %q detach to complete", d))
-			err := NewDiskAttacher(azcli).WaitForDetach(context.Background(), d.String())
+			err := NewDiskAttacher(azcli).WaitForDetach(ctx, d.String())
 			if err != nil {
 				ui.Error(fmt.Sprintf("error detaching disk %q: %s", d, err))
 			}
 
 			ui.Say(fmt.Sprintf("Deleting disk %q", d))
 
-			f, err := azcli.DisksClient().Delete(context.TODO(), d.ResourceGroup, d.ResourceName.String())
+			f, err := azcli.DisksClient().Delete(ctx, d.ResourceGroup, d.ResourceName.String())
 			if err == nil {
-				err = f.WaitForCompletionRef(context.TODO(), azcli.PollClient())
+				err = f.WaitForCompletionRef(ctx, azcli.PollClient())
 			}
 			if err != nil {
 				log.Printf("StepCreateNewDiskset.Cleanup: error: %+v", err)
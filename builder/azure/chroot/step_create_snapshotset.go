@@ -90,6 +90,7 @@ func (s *StepCreateSnapshotset) Run(ctx context.Context, state multistep.StateBa
 }
 
 func (s *StepCreateSnapshotset) Cleanup(state multistep.StateBag) {
+	ctx := multistep.CleanupContext(state)
 	if !s.SkipCleanup {
 		azcli := state.Get("azureclient").(client.AzureClientSet)
 		ui := state.Get("ui").(packer.Ui)
@@ -98,10 +99,10 @@ func (s *StepCreateSnapshotset) Cleanup(state multistep.StateBag) {
 
 			ui.Say(fmt.Sprintf("Removing any active SAS for snapshot %q", resource))
 			{
-				f, err := azcli.SnapshotsClient().RevokeAccess(context.TODO(), resource.ResourceGroup, resource.ResourceName.String())
+				f, err := azcli.SnapshotsClient().RevokeAccess(ctx, resource.ResourceGroup, resource.ResourceName.String())
 				if err == nil {
 					log.Printf("StepCreateSnapshotset.Cleanup: removing SAS...")
-					err = f.WaitForCompletionRef(context.TODO(), azcli.PollClient())
+					err = f.WaitForCompletionRef(ctx, azcli.PollClient())
 				}
 				if err != nil {
 					log.Printf("StepCreateSnapshotset.Cleanup: error: %+v", err)
@@ -111,10 +112,10 @@ func (s *StepCreateSnapshotset) Cleanup(state multistep.StateBag) {
 
 			ui.Say(fmt.Sprintf("Deleting snapshot %q", resource))
 			{
-				f, err := azcli.SnapshotsClient().Delete(context.TODO(), resource.ResourceGroup, resource.ResourceName.String())
+				f, err := azcli.SnapshotsClient().Delete(ctx, resource.ResourceGroup, resource.ResourceName.String())
 				if err == nil {
 					log.Printf("StepCreateSnapshotset.Cleanup: deleting snapshot...")
-					err = f.WaitForCompletionRef(context.TODO(), azcli.PollClient())
+					err = f.WaitForCompletionRef(ctx, azcli.PollClient())
 				}
 				if err != nil {
 					log.Printf("StepCreateSnapshotset.Cleanup: error: %+v", err)
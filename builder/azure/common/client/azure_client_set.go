@@ -1,7 +1,6 @@
 package client
 
 import (
-	"net/http"
 	"regexp"
 	"time"
 
@@ -54,7 +53,7 @@ func new(c Config, say func(string)) (*azureClientSet, error) {
 	return &azureClientSet{
 		authorizer:     autorest.NewBearerAuthorizer(token),
 		subscriptionID: c.SubscriptionID,
-		sender:         http.DefaultClient,
+		sender:         c.Sender(),
 		PollingDelay:   time.Second,
 	}, nil
 }
@@ -8,9 +8,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/azure"
 	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/hashicorp/packer/common/oidc"
+	"github.com/hashicorp/packer/common/ratelimit"
+	commonhelper "github.com/hashicorp/packer/helper/common"
 	"github.com/hashicorp/packer/packer"
 )
 
@@ -32,7 +36,8 @@ type Config struct {
 	// Authentication fields
 
 	// The application ID of the AAD Service Principal.
-	// Requires either `client_secret`, `client_cert_path` or `client_jwt` to be set as well.
+	// Requires either `client_secret`, `client_cert_path`, `client_jwt` or
+	// `credential_helper` to be set as well.
 	ClientID string `mapstructure:"client_id"`
 	// A password/secret registered for the AAD SP.
 	ClientSecret string `mapstructure:"client_secret"`
@@ -43,6 +48,18 @@ type Config struct {
 	// to authenticate the AAD SP. Provides more control over token the expiration
 	// when using certificate authentication than when using `client_cert_path`.
 	ClientJWT string `mapstructure:"client_jwt"`
+	// The command, and its arguments, of a [credential
+	// helper](/docs/credential-helpers) returning the JWTs used to
+	// authenticate the AAD SP, like federated OIDC tokens. The helper is run
+	// again when the JWT expires during the build.
+	CredentialHelper []string `mapstructure:"credential_helper"`
+	// Authenticate the AAD SP with the OIDC token of the CI job Packer runs
+	// in, like a GitHub Actions or a GitLab CI job, trusted by a federated
+	// credential of the SP. A new OIDC token is requested when the tokens
+	// expire during the build.
+	UseOIDC bool `mapstructure:"use_oidc"`
+	// The OIDC token the AAD SP is authenticated with.
+	OIDC oidc.Config `mapstructure:",squash"`
 	// The object ID for the AAD SP. Optional, will be derived from the oAuth token if left empty.
 	ObjectID string `mapstructure:"object_id"`
 
@@ -52,18 +69,27 @@ type Config struct {
 	TenantID string `mapstructure:"tenant_id" required:"false"`
 	// The subscription to use.
 	SubscriptionID string `mapstructure:"subscription_id"`
+	// The rate limit, retries and circuit breaker of the Azure API requests,
+	// applied on top of the retries of the Azure SDK.
+	RateLimitConfig ratelimit.Config `mapstructure:",squash"`
 
 	authType string
 }
 
 const (
-	authTypeDeviceLogin     = "DeviceLogin"
-	authTypeMSI             = "ManagedIdentity"
-	authTypeClientSecret    = "ClientSecret"
-	authTypeClientCert      = "ClientCertificate"
-	authTypeClientBearerJWT = "ClientBearerJWT"
+	authTypeDeviceLogin      = "DeviceLogin"
+	authTypeMSI              = "ManagedIdentity"
+	authTypeClientSecret     = "ClientSecret"
+	authTypeClientCert       = "ClientCertificate"
+	authTypeClientBearerJWT  = "ClientBearerJWT"
+	authTypeCredentialHelper = "CredentialHelper"
+	authTypeOIDC             = "OIDC"
 )
 
+// defaultOIDCAudience is the audience of the OIDC tokens the AAD SPs are
+// authenticated with.
+const defaultOIDCAudience = "api://AzureADTokenExchange"
+
 const DefaultCloudEnvironmentName = "Public"
 
 func (c *Config) SetDefaultValues() error {
@@ -112,6 +138,8 @@ func (c *Config) setCloudEnvironment() error {
 }
 
 func (c Config) Validate(errs *packer.MultiError) {
+	errs = packer.MultiErrorAppend(errs, c.RateLimitConfig.Prepare()...)
+
 	/////////////////////////////////////////////
 	// Authentication via OAUTH
 
@@ -135,7 +163,9 @@ func (c Config) Validate(errs *packer.MultiError) {
 	if c.SubscriptionID != "" && c.ClientID != "" &&
 		c.ClientSecret != "" &&
 		c.ClientCertPath == "" &&
-		c.ClientJWT == "" {
+		c.ClientJWT == "" &&
+		len(c.CredentialHelper) == 0 &&
+		!c.UseOIDC {
 		// Service principal using secret
 		return
 	}
@@ -143,7 +173,9 @@ func (c Config) Validate(errs *packer.MultiError) {
 	if c.SubscriptionID != "" && c.ClientID != "" &&
 		c.ClientSecret == "" &&
 		c.ClientCertPath != "" &&
-		c.ClientJWT == "" {
+		c.ClientJWT == "" &&
+		len(c.CredentialHelper) == 0 &&
+		!c.UseOIDC {
 		// Service principal using certificate
 
 		if _, err := os.Stat(c.ClientCertPath); err != nil {
@@ -155,7 +187,32 @@ func (c Config) Validate(errs *packer.MultiError) {
 	if c.SubscriptionID != "" && c.ClientID != "" &&
 		c.ClientSecret == "" &&
 		c.ClientCertPath == "" &&
-		c.ClientJWT != "" {
+		c.ClientJWT == "" &&
+		len(c.CredentialHelper) > 0 &&
+		!c.UseOIDC {
+		// Service principal using the JWTs of a credential helper
+		return
+	}
+
+	if c.SubscriptionID != "" && c.ClientID != "" &&
+		c.ClientSecret == "" &&
+		c.ClientCertPath == "" &&
+		c.ClientJWT == "" &&
+		len(c.CredentialHelper) == 0 &&
+		c.UseOIDC {
+		// Service principal using the OIDC token of the CI job
+		for _, err := range c.OIDC.Prepare() {
+			errs = packer.MultiErrorAppend(errs, err)
+		}
+		return
+	}
+
+	if c.SubscriptionID != "" && c.ClientID != "" &&
+		c.ClientSecret == "" &&
+		c.ClientCertPath == "" &&
+		c.ClientJWT != "" &&
+		len(c.CredentialHelper) == 0 &&
+		!c.UseOIDC {
 		// Service principal using JWT
 		// Check that JWT is valid for at least 5 more minutes
 
@@ -182,7 +239,9 @@ func (c Config) Validate(errs *packer.MultiError) {
 		"  to use an Azure Active Directory service principal, specify either:\n"+
 		"  - subscription_id, client_id and client_secret\n"+
 		"  - subscription_id, client_id and client_cert_path\n"+
-		"  - subscription_id, client_id and client_jwt."))
+		"  - subscription_id, client_id and client_jwt\n"+
+		"  - subscription_id, client_id and credential_helper\n"+
+		"  - subscription_id, client_id and use_oidc."))
 }
 
 func (c Config) useDeviceLogin() bool {
@@ -190,7 +249,15 @@ func (c Config) useDeviceLogin() bool {
 		c.ClientID == "" &&
 		c.ClientSecret == "" &&
 		c.ClientJWT == "" &&
-		c.ClientCertPath == ""
+		c.ClientCertPath == "" &&
+		len(c.CredentialHelper) == 0 &&
+		!c.UseOIDC
+}
+
+// Sender returns the sender of the Azure API requests, honoring the proxy,
+// the CA bundle and the client certificate of the environment.
+func (c Config) Sender() autorest.Sender {
+	return c.RateLimitConfig.Client("azure", commonhelper.HttpClient())
 }
 
 func (c Config) UseMSI() bool {
@@ -199,6 +266,8 @@ func (c Config) UseMSI() bool {
 		c.ClientSecret == "" &&
 		c.ClientJWT == "" &&
 		c.ClientCertPath == "" &&
+		len(c.CredentialHelper) == 0 &&
+		!c.UseOIDC &&
 		c.TenantID == ""
 }
 
@@ -245,6 +314,16 @@ func (c Config) GetServicePrincipalToken(
 	case authTypeClientBearerJWT:
 		say("Getting tokens using client bearer JWT")
 		auth = NewJWTOAuthTokenProvider(*c.cloudEnvironment, c.ClientID, c.ClientJWT, c.TenantID)
+	case authTypeCredentialHelper:
+		say("Getting tokens using credential helper")
+		auth = NewCredentialHelperOAuthTokenProvider(*c.cloudEnvironment, c.ClientID, c.CredentialHelper, c.TenantID)
+	case authTypeOIDC:
+		say("Getting tokens using the OIDC token of the CI job")
+		tokens, err := c.OIDC.TokenSource(defaultOIDCAudience)
+		if err != nil {
+			return nil, err
+		}
+		auth = NewOIDCOAuthTokenProvider(*c.cloudEnvironment, c.ClientID, tokens, c.TenantID)
 	default:
 		panic("authType not set, call FillParameters, or set explicitly")
 	}
@@ -253,6 +332,7 @@ func (c Config) GetServicePrincipalToken(
 	if err != nil {
 		return nil, err
 	}
+	servicePrincipalToken.SetSender(commonhelper.HttpClient())
 
 	err = servicePrincipalToken.EnsureFresh()
 	if err != nil {
@@ -270,6 +350,10 @@ func (c *Config) FillParameters() error {
 			c.authType = authTypeDeviceLogin
 		} else if c.UseMSI() {
 			c.authType = authTypeMSI
+		} else if c.UseOIDC {
+			c.authType = authTypeOIDC
+		} else if len(c.CredentialHelper) > 0 {
+			c.authType = authTypeCredentialHelper
 		} else if c.ClientSecret != "" {
 			c.authType = authTypeClientSecret
 		} else if c.ClientCertPath != "" {
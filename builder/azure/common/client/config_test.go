@@ -353,6 +353,59 @@ func Test_ClientConfig_CannotUseBothClientJWTAndSecret(t *testing.T) {
 	assertInvalid(t, cfg)
 }
 
+func Test_ClientConfig_CanUseCredentialHelper(t *testing.T) {
+	cfg := Config{
+		SubscriptionID:   "12345",
+		ClientID:         "12345",
+		CredentialHelper: []string{"azure-oidc-helper"},
+	}
+
+	assertValid(t, cfg)
+}
+
+func Test_ClientConfig_CannotUseBothCredentialHelperAndSecret(t *testing.T) {
+	cfg := Config{
+		SubscriptionID:   "12345",
+		ClientID:         "12345",
+		ClientSecret:     "12345",
+		CredentialHelper: []string{"azure-oidc-helper"},
+	}
+
+	assertInvalid(t, cfg)
+}
+
+func Test_ClientConfig_CanUseOIDC(t *testing.T) {
+	cfg := Config{
+		SubscriptionID: "12345",
+		ClientID:       "12345",
+		UseOIDC:        true,
+	}
+
+	assertValid(t, cfg)
+}
+
+func Test_ClientConfig_CannotUseBothOIDCAndSecret(t *testing.T) {
+	cfg := Config{
+		SubscriptionID: "12345",
+		ClientID:       "12345",
+		ClientSecret:   "12345",
+		UseOIDC:        true,
+	}
+
+	assertInvalid(t, cfg)
+}
+
+func Test_ClientConfig_OIDCProviderShouldBeKnown(t *testing.T) {
+	cfg := Config{
+		SubscriptionID: "12345",
+		ClientID:       "12345",
+		UseOIDC:        true,
+	}
+	cfg.OIDC.OIDCProvider = "jenkins"
+
+	assertInvalid(t, cfg)
+}
+
 func Test_ClientConfig_ClientJWTShouldBeValidForAtLeast5Minutes(t *testing.T) {
 	cfg := Config{
 		SubscriptionID: "12345",
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/hashicorp/packer/common/credentialhelper"
+	"github.com/hashicorp/packer/common/oidc"
+)
+
+// for clientID/bearer JWT auth, with the JWTs requested again whenever the
+// tokens are refreshed, like the JWTs of credential helpers or OIDC tokens
+type assertionOAuthTokenProvider struct {
+	env                azure.Environment
+	clientID, tenantID string
+	assertion          func() (string, error)
+}
+
+func NewCredentialHelperOAuthTokenProvider(env azure.Environment, clientID string, credentialHelper []string, tenantID string) oAuthTokenProvider {
+	helper := credentialhelper.New(credentialHelper, credentialhelper.ProviderAzure)
+	return &assertionOAuthTokenProvider{env, clientID, tenantID, func() (string, error) {
+		c, err := helper.Get(context.TODO())
+		if err != nil {
+			return "", err
+		}
+		return c.Token, nil
+	}}
+}
+
+func NewOIDCOAuthTokenProvider(env azure.Environment, clientID string, tokens *oidc.TokenSource, tenantID string) oAuthTokenProvider {
+	return &assertionOAuthTokenProvider{env, clientID, tenantID, func() (string, error) {
+		return tokens.Token(context.TODO())
+	}}
+}
+
+func (tp *assertionOAuthTokenProvider) getServicePrincipalToken() (*adal.ServicePrincipalToken, error) {
+	return tp.getServicePrincipalTokenWithResource(tp.env.ResourceManagerEndpoint)
+}
+
+func (tp *assertionOAuthTokenProvider) getServicePrincipalTokenWithResource(resource string) (*adal.ServicePrincipalToken, error) {
+	oauthConfig, err := adal.NewOAuthConfig(tp.env.ActiveDirectoryEndpoint, tp.tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return adal.NewServicePrincipalTokenWithSecret(
+		*oauthConfig,
+		tp.clientID,
+		resource,
+		tp)
+}
+
+// implements github.com/Azure/go-autorest/autorest/adal.ServicePrincipalSecret
+func (tp *assertionOAuthTokenProvider) SetAuthenticationValues(
+	t *adal.ServicePrincipalToken, v *url.Values) error {
+	assertion, err := tp.assertion()
+	if err != nil {
+		return err
+	}
+	v.Set("client_assertion", assertion)
+	v.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	return nil
+}
@@ -34,6 +34,7 @@ const (
 	ArmDoubleResourceGroupNameSet      string = "arm.DoubleResourceGroupNameSet"
 	ArmStorageAccountName              string = "arm.StorageAccountName"
 	ArmTags                            string = "arm.Tags"
+	ArmBillingTags                     string = "arm.BillingTags"
 	ArmVirtualMachineCaptureParameters string = "arm.VirtualMachineCaptureParameters"
 	ArmIsExistingResourceGroup         string = "arm.IsExistingResourceGroup"
 	ArmIsExistingKeyVault              string = "arm.IsExistingKeyVault"
@@ -13,6 +13,7 @@ type Template struct {
 	Parameters     *map[string]Parameters `json:"parameters"`
 	Variables      *map[string]string     `json:"variables"`
 	Resources      []*Resource            `json:"resources"`
+	Outputs        *map[string]Output     `json:"outputs,omitempty"`
 }
 
 /////////////////////////////////////////////////
@@ -22,6 +23,13 @@ type Parameters struct {
 	DefaultValue *string `json:"defaultValue,omitempty"`
 }
 
+/////////////////////////////////////////////////
+// Template > Outputs
+type Output struct {
+	Type  *string `json:"type"`
+	Value *string `json:"value"`
+}
+
 /////////////////////////////////////////////////
 // Template > Resource
 type Resource struct {
@@ -87,6 +95,7 @@ type Properties struct {
 	EnabledForTemplateDeployment *string                             `json:"enabledForTemplateDeployment,omitempty"`
 	HardwareProfile              *compute.HardwareProfile            `json:"hardwareProfile,omitempty"`
 	IPConfigurations             *[]network.IPConfiguration          `json:"ipConfigurations,omitempty"`
+	NetworkAcls                  *NetworkAcls                        `json:"networkAcls,omitempty"`
 	NetworkProfile               *compute.NetworkProfile             `json:"networkProfile,omitempty"`
 	OsProfile                    *compute.OSProfile                  `json:"osProfile,omitempty"`
 	PublicIPAllocatedMethod      *network.IPAllocationMethod         `json:"publicIPAllocationMethod,omitempty"`
@@ -117,6 +126,11 @@ type Permissions struct {
 	Secrets *[]string `json:"secrets,omitempty"`
 }
 
+type NetworkAcls struct {
+	Bypass        *string `json:"bypass,omitempty"`
+	DefaultAction *string `json:"defaultAction,omitempty"`
+}
+
 type Sku struct {
 	Family *string `json:"family,omitempty"`
 	Name   *string `json:"name,omitempty"`
@@ -23,6 +23,13 @@ const (
 	resourceNetworkSecurityGroups = "Microsoft.Network/networkSecurityGroups"
 
 	variableSshKeyPath = "sshKeyPath"
+
+	// The first Key Vault API version supporting network ACLs.
+	keyVaultNetworkAclsApiVersion = "2018-02-14"
+
+	// KeyVaultSecretUriOutput is the name of the Key Vault deployment output
+	// holding the URL of the WinRM certificate, see SetKeyVaultPrivateAccess.
+	KeyVaultSecretUriOutput = "keyVaultSecretUri"
 )
 
 type TemplateBuilder struct {
@@ -341,6 +348,28 @@ func (s *TemplateBuilder) SetPrivateVirtualNetworkWithPublicIp(virtualNetworkRes
 	return nil
 }
 
+// SetKeyVaultPrivateAccess denies public network access to the Key Vault,
+// only letting trusted Azure services such as the VM deployment through. As
+// the secret can then not be read from the Key Vault, its URL is output by the
+// deployment.
+func (s *TemplateBuilder) SetKeyVaultPrivateAccess() error {
+	resource, err := s.getResourceByType(resourceKeyVaults)
+	if err != nil {
+		return err
+	}
+
+	resource.ApiVersion = to.StringPtr(keyVaultNetworkAclsApiVersion)
+	resource.Properties.NetworkAcls = &NetworkAcls{
+		Bypass:        to.StringPtr("AzureServices"),
+		DefaultAction: to.StringPtr("Deny"),
+	}
+
+	s.setOutput(KeyVaultSecretUriOutput, fmt.Sprintf(
+		"[reference(resourceId('%s/secrets', parameters('keyVaultName'), variables('keyVaultSecretName')), '%s').secretUriWithVersion]",
+		resourceKeyVaults, keyVaultNetworkAclsApiVersion))
+	return nil
+}
+
 func (s *TemplateBuilder) SetNetworkSecurityGroup(ipAddresses []string, port int) error {
 	nsgResource, dependency, resourceId := s.createNsgResource(ipAddresses, port)
 	if err := s.addResource(nsgResource); err != nil {
@@ -424,6 +453,16 @@ func (s *TemplateBuilder) setVariable(name string, value string) {
 	(*s.template.Variables)[name] = value
 }
 
+func (s *TemplateBuilder) setOutput(name string, value string) {
+	if s.template.Outputs == nil {
+		s.template.Outputs = &map[string]Output{}
+	}
+	(*s.template.Outputs)[name] = Output{
+		Type:  to.StringPtr("string"),
+		Value: to.StringPtr(value),
+	}
+}
+
 func (s *TemplateBuilder) toResourceID(id, name string) string {
 	return fmt.Sprintf("[resourceId(resourceGroup().name, '%s', '%s')]", id, name)
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/hashicorp/packer/builder/azure/common/constants"
 	"github.com/hashicorp/packer/builder/azure/common/lin"
 	packerCommon "github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -51,6 +52,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, errs
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"temporary DevTest Labs virtual machine", "custom image"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 
 	ui.Say("Running builder ...")
@@ -82,7 +87,8 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		b.config.ClientConfig.CloudEnvironment(),
 		b.config.SharedGalleryTimeout,
 		b.config.PollingDurationTimeout,
-		spnCloud)
+		spnCloud,
+		b.config.ClientConfig.Sender())
 
 	if err != nil {
 		return nil, err
@@ -187,9 +193,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 				Host:      lin.SSHHost,
 				SSHConfig: b.config.Comm.SSHConfigFunc(),
 			},
+			&guest.StepCheckClock{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepEnvironment{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
 			&packerCommon.StepProvision{},
-			&packerCommon.StepCleanupTempKeys{
-				Comm: &b.config.Comm,
+			&guest.StepRotateCredentials{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepCleanup{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepLinuxGeneralize{
+				Config: &b.config.Guest,
+			},
+			&guest.StepSysprep{
+				Config: &b.config.Guest,
 			},
 			NewStepPowerOffCompute(azureClient, ui, b.config),
 			NewStepCaptureImage(azureClient, ui, b.config),
@@ -215,7 +240,29 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 					}, nil
 				},
 			},
+			&guest.StepCheckClock{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepEnvironment{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
 			&packerCommon.StepProvision{},
+			&guest.StepRotateCredentials{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepCleanup{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepLinuxGeneralize{
+				Config: &b.config.Guest,
+			},
+			&guest.StepSysprep{
+				Config: &b.config.Guest,
+			},
 			NewStepPowerOffCompute(azureClient, ui, b.config),
 			NewStepCaptureImage(azureClient, ui, b.config),
 			NewStepPublishToSharedImageGallery(azureClient, ui, b.config),
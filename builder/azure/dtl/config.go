@@ -25,6 +25,7 @@ import (
 
 	"github.com/hashicorp/packer/builder/azure/pkcs12"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
@@ -302,8 +303,9 @@ type Config struct {
 	winrmCertificate string
 	winrmPassword    string
 
-	Comm communicator.Config `mapstructure:",squash"`
-	ctx  interpolate.Context
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
+	ctx   interpolate.Context
 }
 
 type keyVaultCertificate struct {
@@ -425,6 +427,7 @@ func newConfig(raws ...interface{}) (*Config, []string, error) {
 
 	var errs *packer.MultiError
 	errs = packer.MultiErrorAppend(errs, c.Comm.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.Guest.Prepare(&c.Comm)...)
 
 	c.ClientConfig.Validate(errs)
 
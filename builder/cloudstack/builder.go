@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -85,9 +86,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			SSHPort:   commPort,
 			WinRMPort: commPort,
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.Guest,
 		},
 		&stepShutdownInstance{},
 		&stepCreateTemplate{},
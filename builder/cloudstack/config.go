@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -22,6 +23,7 @@ type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 	common.HTTPConfig   `mapstructure:",squash"`
 	Comm                communicator.Config `mapstructure:",squash"`
+	Guest               guest.Config        `mapstructure:",squash"`
 
 	// The CloudStack API endpoint we will connect to. It can
 	// also be specified via environment variable CLOUDSTACK_API_URL, if set.
@@ -305,6 +307,9 @@ func (c *Config) Prepare(raws ...interface{}) error {
 	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
 		errs = packer.MultiErrorAppend(errs, es...)
 	}
+	if es := c.Guest.Prepare(&c.Comm); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
 
 	// Check for errors and return if we have any.
 	if errs != nil && len(errs.Errors) > 0 {
@@ -12,6 +12,8 @@ import (
 	"github.com/digitalocean/godo"
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
+	commonhelper "github.com/hashicorp/packer/helper/common"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -37,10 +39,16 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, nil, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"temporary droplet and SSH key", "snapshot"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
-	client := godo.NewClient(oauth2.NewClient(context.TODO(), &apiTokenSource{
-		AccessToken: b.config.APIToken,
-	}))
+	httpContext := context.WithValue(context.TODO(), oauth2.HTTPClient, commonhelper.HttpClient())
+	client := godo.NewClient(b.config.RateLimitConfig.Client("digitalocean",
+		oauth2.NewClient(httpContext, &apiTokenSource{
+			AccessToken: b.config.APIToken,
+		})))
 	if b.config.APIURL != "" {
 		u, err := url.Parse(b.config.APIURL)
 		if err != nil {
@@ -91,9 +99,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Host:      communicator.CommHost(b.config.Comm.Host(), "droplet_ip"),
 			SSHConfig: b.config.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
 		new(common.StepProvision),
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.Guest,
 		},
 		new(stepShutdown),
 		new(stepPowerOff),
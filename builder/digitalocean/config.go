@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
+	"github.com/hashicorp/packer/common/ratelimit"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -22,6 +24,7 @@ import (
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 	Comm                communicator.Config `mapstructure:",squash"`
+	Guest               guest.Config        `mapstructure:",squash"`
 	// The client TOKEN to use to access your account. It
 	// can also be specified via environment variable DIGITALOCEAN_API_TOKEN, if
 	// set.
@@ -30,6 +33,9 @@ type Config struct {
 	// using a DigitalOcean API compatible service. It can also be specified via
 	// environment variable DIGITALOCEAN_API_URL.
 	APIURL string `mapstructure:"api_url" required:"false"`
+	// The rate limit, retries and circuit breaker of the DigitalOcean API
+	// requests.
+	RateLimitConfig ratelimit.Config `mapstructure:",squash"`
 	// The name (or slug) of the region to launch the droplet
 	// in. Consequently, this is the region where the snapshot will be available.
 	// See
@@ -145,6 +151,12 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
 		errs = packer.MultiErrorAppend(errs, es...)
 	}
+	if es := c.Guest.Prepare(&c.Comm); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
+	if es := c.RateLimitConfig.Prepare(); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
 	if c.APIToken == "" {
 		// Required configurations that will display errors if not set
 		errs = packer.MultiErrorAppend(
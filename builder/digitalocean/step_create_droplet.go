@@ -56,7 +56,7 @@ func (s *stepCreateDroplet) Run(ctx context.Context, state multistep.StateBag) m
 
 	log.Printf("[DEBUG] Droplet create paramaters: %s", godo.Stringify(dropletCreateReq))
 
-	droplet, _, err := client.Droplets.Create(context.TODO(), dropletCreateReq)
+	droplet, _, err := client.Droplets.Create(ctx, dropletCreateReq)
 	if err != nil {
 		err := fmt.Errorf("Error creating droplet: %s", err)
 		state.Put("error", err)
@@ -87,7 +87,7 @@ func (s *stepCreateDroplet) Cleanup(state multistep.StateBag) {
 
 	// Destroy the droplet we just created
 	ui.Say("Destroying droplet...")
-	_, err := client.Droplets.Delete(context.TODO(), s.dropletId)
+	_, err := client.Droplets.Delete(multistep.CleanupContext(state), s.dropletId)
 	if err != nil {
 		ui.Error(fmt.Sprintf(
 			"Error destroying droplet. Please destroy it manually: %s", err))
@@ -60,7 +60,7 @@ func (s *stepCreateSSHKey) Run(ctx context.Context, state multistep.StateBag) mu
 	name := fmt.Sprintf("packer-%s", uuid.TimeOrderedUUID())
 
 	// Create the key!
-	key, _, err := client.Keys.Create(context.TODO(), &godo.KeyCreateRequest{
+	key, _, err := client.Keys.Create(ctx, &godo.KeyCreateRequest{
 		Name:      name,
 		PublicKey: pub_sshformat,
 	})
@@ -117,7 +117,7 @@ func (s *stepCreateSSHKey) Cleanup(state multistep.StateBag) {
 	ui := state.Get("ui").(packer.Ui)
 
 	ui.Say("Deleting temporary ssh key...")
-	_, err := client.Keys.DeleteByID(context.TODO(), s.keyId)
+	_, err := client.Keys.DeleteByID(multistep.CleanupContext(state), s.keyId)
 	if err != nil {
 		log.Printf("Error cleaning up ssh key: %s", err)
 		ui.Error(fmt.Sprintf(
@@ -28,7 +28,7 @@ func (s *stepDropletInfo) Run(ctx context.Context, state multistep.StateBag) mul
 	}
 
 	// Set the IP on the state for later
-	droplet, _, err := client.Droplets.Get(context.TODO(), dropletID)
+	droplet, _, err := client.Droplets.Get(ctx, dropletID)
 	if err != nil {
 		err := fmt.Errorf("Error retrieving droplet: %s", err)
 		state.Put("error", err)
@@ -18,7 +18,7 @@ func (s *stepPowerOff) Run(ctx context.Context, state multistep.StateBag) multis
 	ui := state.Get("ui").(packer.Ui)
 	dropletId := state.Get("droplet_id").(int)
 
-	droplet, _, err := client.Droplets.Get(context.TODO(), dropletId)
+	droplet, _, err := client.Droplets.Get(ctx, dropletId)
 	if err != nil {
 		err := fmt.Errorf("Error checking droplet state: %s", err)
 		state.Put("error", err)
@@ -33,7 +33,7 @@ func (s *stepPowerOff) Run(ctx context.Context, state multistep.StateBag) multis
 
 	// Pull the plug on the Droplet
 	ui.Say("Forcefully shutting down Droplet...")
-	_, _, err = client.DropletActions.PowerOff(context.TODO(), dropletId)
+	_, _, err = client.DropletActions.PowerOff(ctx, dropletId)
 	if err != nil {
 		err := fmt.Errorf("Error powering off droplet: %s", err)
 		state.Put("error", err)
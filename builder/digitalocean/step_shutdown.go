@@ -24,7 +24,7 @@ func (s *stepShutdown) Run(ctx context.Context, state multistep.StateBag) multis
 	// did absolutely nothing (*ALAKAZAM!* magic!). We give up after
 	// a pretty arbitrary amount of time.
 	ui.Say("Gracefully shutting down droplet...")
-	_, _, err := client.DropletActions.Shutdown(context.TODO(), dropletId)
+	_, _, err := client.DropletActions.Shutdown(ctx, dropletId)
 	if err != nil {
 		// If we get an error the first time, actually report it
 		err := fmt.Errorf("Error shutting down droplet: %s", err)
@@ -51,7 +51,7 @@ func (s *stepShutdown) Run(ctx context.Context, state multistep.StateBag) multis
 
 		for attempts := 2; attempts > 0; attempts++ {
 			log.Printf("ShutdownDroplet attempt #%d...", attempts)
-			_, _, err := client.DropletActions.Shutdown(context.TODO(), dropletId)
+			_, _, err := client.DropletActions.Shutdown(ctx, dropletId)
 			if err != nil {
 				log.Printf("Shutdown retry error: %s", err)
 			}
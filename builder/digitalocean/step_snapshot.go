@@ -24,7 +24,7 @@ func (s *stepSnapshot) Run(ctx context.Context, state multistep.StateBag) multis
 	var snapshotRegions []string
 
 	ui.Say(fmt.Sprintf("Creating snapshot: %v", c.SnapshotName))
-	action, _, err := client.DropletActions.Snapshot(context.TODO(), dropletId, c.SnapshotName)
+	action, _, err := client.DropletActions.Snapshot(ctx, dropletId, c.SnapshotName)
 	if err != nil {
 		err := fmt.Errorf("Error creating snapshot: %s", err)
 		state.Put("error", err)
@@ -57,7 +57,7 @@ func (s *stepSnapshot) Run(ctx context.Context, state multistep.StateBag) multis
 	}
 
 	log.Printf("Looking up snapshot ID for snapshot: %s", c.SnapshotName)
-	images, _, err := client.Droplets.Snapshots(context.TODO(), dropletId, nil)
+	images, _, err := client.Droplets.Snapshots(ctx, dropletId, nil)
 	if err != nil {
 		err := fmt.Errorf("Error looking up snapshot ID: %s", err)
 		state.Put("error", err)
@@ -87,7 +87,7 @@ func (s *stepSnapshot) Run(ctx context.Context, state multistep.StateBag) multis
 				"type":   "transfer",
 				"region": snapshotRegions[transfer],
 			}
-			imageTransfer, _, err := client.ImageActions.Transfer(context.TODO(), images[0].ID, transferRequest)
+			imageTransfer, _, err := client.ImageActions.Transfer(ctx, images[0].ID, transferRequest)
 			if err != nil {
 				err := fmt.Errorf("Error transferring snapshot: %s", err)
 				state.Put("error", err)
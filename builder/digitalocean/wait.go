@@ -3,59 +3,33 @@ package digitalocean
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/digitalocean/godo"
+	"github.com/hashicorp/packer/common/wait"
 )
 
+// waitConfig is the configuration of the waits of the DigitalOcean API.
+func waitConfig(description string, timeout time.Duration) wait.Config {
+	return wait.Config{
+		Description: description,
+		Timeout:     timeout,
+		MinDelay:    3 * time.Second,
+	}
+}
+
 // waitForDropletUnlocked waits for the Droplet to be unlocked to
 // avoid "pending" errors when making state changes.
 func waitForDropletUnlocked(
 	client *godo.Client, dropletId int, timeout time.Duration) error {
-	done := make(chan struct{})
-	defer close(done)
-
-	result := make(chan error, 1)
-	go func() {
-		attempts := 0
-		for {
-			attempts += 1
-
-			log.Printf("[DEBUG] Checking droplet lock state... (attempt: %d)", attempts)
-			droplet, _, err := client.Droplets.Get(context.TODO(), dropletId)
+	return waitConfig(fmt.Sprintf("droplet %d to unlock", dropletId), timeout).Until(context.TODO(),
+		func(ctx context.Context) (bool, string, error) {
+			droplet, _, err := client.Droplets.Get(ctx, dropletId)
 			if err != nil {
-				result <- err
-				return
-			}
-
-			if !droplet.Locked {
-				result <- nil
-				return
-			}
-
-			// Wait 3 seconds in between
-			time.Sleep(3 * time.Second)
-
-			// Verify we shouldn't exit
-			select {
-			case <-done:
-				// We finished, so just exit the goroutine
-				return
-			default:
-				// Keep going
+				return false, "", err
 			}
-		}
-	}()
-
-	log.Printf("[DEBUG] Waiting for up to %d seconds for droplet to unlock", timeout/time.Second)
-	select {
-	case err := <-result:
-		return err
-	case <-time.After(timeout):
-		return fmt.Errorf(
-			"Timeout while waiting to for droplet to unlock")
-	}
+			return !droplet.Locked, fmt.Sprintf("locked: %t", droplet.Locked), nil
+		})
 }
 
 // waitForDropletState simply blocks until the droplet is in
@@ -63,49 +37,15 @@ func waitForDropletUnlocked(
 func waitForDropletState(
 	desiredState string, dropletId int,
 	client *godo.Client, timeout time.Duration) error {
-	done := make(chan struct{})
-	defer close(done)
-
-	result := make(chan error, 1)
-	go func() {
-		attempts := 0
-		for {
-			attempts += 1
-
-			log.Printf("Checking droplet status... (attempt: %d)", attempts)
-			droplet, _, err := client.Droplets.Get(context.TODO(), dropletId)
+	_, err := waitConfig(fmt.Sprintf("droplet %d to become %s", dropletId, desiredState), timeout).ForState(context.TODO(),
+		func(ctx context.Context) (interface{}, string, error) {
+			droplet, _, err := client.Droplets.Get(ctx, dropletId)
 			if err != nil {
-				result <- err
-				return
-			}
-
-			if droplet.Status == desiredState {
-				result <- nil
-				return
+				return nil, "", err
 			}
-
-			// Wait 3 seconds in between
-			time.Sleep(3 * time.Second)
-
-			// Verify we shouldn't exit
-			select {
-			case <-done:
-				// We finished, so just exit the goroutine
-				return
-			default:
-				// Keep going
-			}
-		}
-	}()
-
-	log.Printf("Waiting for up to %d seconds for droplet to become %s", timeout/time.Second, desiredState)
-	select {
-	case err := <-result:
-		return err
-	case <-time.After(timeout):
-		err := fmt.Errorf("Timeout while waiting to for droplet to become '%s'", desiredState)
-		return err
-	}
+			return droplet, droplet.Status, nil
+		}, nil, []string{desiredState})
+	return err
 }
 
 // waitForActionState simply blocks until the droplet action is in
@@ -113,49 +53,15 @@ func waitForDropletState(
 func waitForActionState(
 	desiredState string, dropletId, actionId int,
 	client *godo.Client, timeout time.Duration) error {
-	done := make(chan struct{})
-	defer close(done)
-
-	result := make(chan error, 1)
-	go func() {
-		attempts := 0
-		for {
-			attempts += 1
-
-			log.Printf("Checking action status... (attempt: %d)", attempts)
-			action, _, err := client.DropletActions.Get(context.TODO(), dropletId, actionId)
+	_, err := waitConfig(fmt.Sprintf("action %d to become %s", actionId, desiredState), timeout).ForState(context.TODO(),
+		func(ctx context.Context) (interface{}, string, error) {
+			action, _, err := client.DropletActions.Get(ctx, dropletId, actionId)
 			if err != nil {
-				result <- err
-				return
-			}
-
-			if action.Status == desiredState {
-				result <- nil
-				return
+				return nil, "", err
 			}
-
-			// Wait 3 seconds in between
-			time.Sleep(3 * time.Second)
-
-			// Verify we shouldn't exit
-			select {
-			case <-done:
-				// We finished, so just exit the goroutine
-				return
-			default:
-				// Keep going
-			}
-		}
-	}()
-
-	log.Printf("Waiting for up to %d seconds for action to become %s", timeout/time.Second, desiredState)
-	select {
-	case err := <-result:
-		return err
-	case <-time.After(timeout):
-		err := fmt.Errorf("Timeout while waiting to for action to become '%s'", desiredState)
-		return err
-	}
+			return action, action.Status, nil
+		}, nil, []string{desiredState})
+	return err
 }
 
 // WaitForImageState simply blocks until the image action is in
@@ -163,47 +69,13 @@ func waitForActionState(
 func WaitForImageState(
 	desiredState string, imageId, actionId int,
 	client *godo.Client, timeout time.Duration) error {
-	done := make(chan struct{})
-	defer close(done)
-
-	result := make(chan error, 1)
-	go func() {
-		attempts := 0
-		for {
-			attempts += 1
-
-			log.Printf("Checking action status... (attempt: %d)", attempts)
-			action, _, err := client.ImageActions.Get(context.TODO(), imageId, actionId)
+	_, err := waitConfig(fmt.Sprintf("image transfer %d to become %s", actionId, desiredState), timeout).ForState(context.TODO(),
+		func(ctx context.Context) (interface{}, string, error) {
+			action, _, err := client.ImageActions.Get(ctx, imageId, actionId)
 			if err != nil {
-				result <- err
-				return
+				return nil, "", err
 			}
-
-			if action.Status == desiredState {
-				result <- nil
-				return
-			}
-
-			// Wait 3 seconds in between
-			time.Sleep(3 * time.Second)
-
-			// Verify we shouldn't exit
-			select {
-			case <-done:
-				// We finished, so just exit the goroutine
-				return
-			default:
-				// Keep going
-			}
-		}
-	}()
-
-	log.Printf("Waiting for up to %d seconds for image transfer to become %s", timeout/time.Second, desiredState)
-	select {
-	case err := <-result:
-		return err
-	case <-time.After(timeout):
-		err := fmt.Errorf("Timeout while waiting to for image transfer to become '%s'", desiredState)
-		return err
-	}
+			return action, action.Status, nil
+		}, nil, []string{desiredState})
+	return err
 }
@@ -32,6 +32,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"temporary container", "local image or exported tarball"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	driver := &DockerDriver{Ctx: &b.config.ctx, Ui: ui}
 	if err := driver.Verify(); err != nil {
@@ -58,9 +62,6 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			},
 		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.Comm,
-		},
 	}
 
 	if b.config.Discard {
@@ -68,6 +69,9 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	} else if b.config.Commit {
 		log.Print("[DEBUG] Container will be committed")
 		steps = append(steps, new(StepCommit))
+		if b.config.Squash {
+			steps = append(steps, new(StepSquash))
+		}
 	} else if b.config.ExportPath != "" {
 		log.Printf("[DEBUG] Container will be exported to %s", b.config.ExportPath)
 		steps = append(steps, new(StepExport))
@@ -1,11 +1,15 @@
 //go:generate struct-markdown
-//go:generate mapstructure-to-hcl2 -type Config
+//go:generate mapstructure-to-hcl2 -type Config,HealthcheckConfig
 
 package docker
 
 import (
 	"fmt"
 	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/helper/communicator"
@@ -22,6 +26,42 @@ var (
 	errImageNotSpecified   = fmt.Errorf("Image must be specified")
 )
 
+var cacheVolumeNameRe = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// HealthcheckConfig sets the HEALTHCHECK of the committed image.
+type HealthcheckConfig struct {
+	// The command to run in the container to check that it is still
+	// working, in exec form. Example: `["curl", "-f", "http://localhost/"]`.
+	Command []string `mapstructure:"command" required:"false"`
+	// The time to wait between two checks. Defaults to the Docker default
+	// of "30s".
+	Interval time.Duration `mapstructure:"interval" required:"false"`
+	// The time after which a check is considered to have failed. Defaults to
+	// the Docker default of "30s".
+	Timeout time.Duration `mapstructure:"timeout" required:"false"`
+	// The time the container is given to start before failed checks count
+	// towards the number of retries. Defaults to the Docker default of "0s".
+	StartPeriod time.Duration `mapstructure:"start_period" required:"false"`
+	// The number of consecutive failed checks after which the container is
+	// considered unhealthy. Defaults to the Docker default of 3.
+	Retries int `mapstructure:"retries" required:"false"`
+	// Disable the healthcheck inherited from the base image instead. This
+	// can't be used with command.
+	Disable bool `mapstructure:"disable" required:"false"`
+}
+
+func (h *HealthcheckConfig) empty() bool {
+	return len(h.Command) == 0 && !h.Disable
+}
+
+func (h *HealthcheckConfig) change() string {
+	if h.Disable {
+		return "HEALTHCHECK NONE"
+	}
+
+	return healthcheckChange(h.Interval, h.Timeout, h.StartPeriod, h.Retries, h.Command)
+}
+
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 	Comm                communicator.Config `mapstructure:",squash"`
@@ -34,6 +74,31 @@ type Config struct {
 	Changes []string `mapstructure:"changes"`
 	// If true, the container will be committed to an image rather than exported.
 	Commit bool `mapstructure:"commit" required:"true"`
+	// The default command of the committed image, in exec form. This is a
+	// declarative alternative to a CMD instruction in changes. Example:
+	// `["nginx", "-g", "daemon off;"]`
+	Cmd []string `mapstructure:"cmd" required:"false"`
+	// The entrypoint of the committed image, in exec form. This overrides
+	// the entrypoint set by run_command, and is a declarative alternative
+	// to an ENTRYPOINT instruction in changes.
+	Entrypoint []string `mapstructure:"entrypoint" required:"false"`
+	// Labels to set on the committed image.
+	Labels map[string]string `mapstructure:"labels" required:"false"`
+	// The healthcheck of the committed image. See the [healthcheck
+	// configuration](#healthcheck-configuration) below.
+	Healthcheck HealthcheckConfig `mapstructure:"healthcheck" required:"false"`
+	// If true, the layers of the committed image, including the ones of the
+	// base image, are squashed into a single layer. This is done by
+	// exporting the container and importing it again with the configuration
+	// of the committed image, so the history of the image is lost. This
+	// can't be used with Windows containers. Defaults to false.
+	Squash bool `mapstructure:"squash" required:"false"`
+	// Paths in the container, such as package manager caches, to mount from
+	// named volumes during the build. The volumes are named after the path,
+	// for example `packer-cache-var-cache-apt` for `/var/cache/apt`, and are
+	// kept between builds so that they act as a cache. The content of
+	// volumes is never part of the committed or exported image.
+	CacheVolumes []string `mapstructure:"cache_volumes" required:"false"`
 
 	// The directory inside container to mount temp directory from host server
 	// for work [file provisioner](/docs/provisioners/file). This defaults
@@ -198,6 +263,25 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		}
 	}
 
+	if !c.Commit && (len(c.Cmd) > 0 || len(c.Entrypoint) > 0 || len(c.Labels) > 0 ||
+		!c.Healthcheck.empty() || c.Squash) {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("cmd, entrypoint, labels, healthcheck and squash can only be used with commit"))
+	}
+
+	if c.Healthcheck.Disable && len(c.Healthcheck.Command) > 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("healthcheck command can't be set when disable is true"))
+	}
+
+	if c.Squash && c.WindowsContainer {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("squash can't be used with Windows containers"))
+	}
+
+	for _, p := range c.CacheVolumes {
+		if !c.WindowsContainer && !path.IsAbs(p) {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("cache_volumes must be absolute paths: %s", p))
+		}
+	}
+
 	if c.EcrLogin && c.LoginServer == "" {
 		errs = packer.MultiErrorAppend(errs, fmt.Errorf("ECR login requires login server to be provided."))
 	}
@@ -208,3 +292,27 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 
 	return nil, nil
 }
+
+// commitChanges returns the Dockerfile instructions applied to the committed
+// image: the ones from changes followed by the ones generated from cmd,
+// entrypoint, labels and healthcheck.
+func (c *Config) commitChanges() []string {
+	changes := append([]string{}, c.Changes...)
+	changes = append(changes, labelChanges(c.Labels)...)
+	if !c.Healthcheck.empty() {
+		changes = append(changes, c.Healthcheck.change())
+	}
+	if len(c.Entrypoint) > 0 {
+		changes = append(changes, "ENTRYPOINT "+execForm(c.Entrypoint))
+	}
+	if len(c.Cmd) > 0 {
+		changes = append(changes, "CMD "+execForm(c.Cmd))
+	}
+	return changes
+}
+
+// cacheVolumeName returns the name of the named volume mounted at the given
+// path of the container for cache_volumes.
+func cacheVolumeName(p string) string {
+	return "packer-cache-" + strings.Trim(cacheVolumeNameRe.ReplaceAllString(p, "-"), "-.")
+}
@@ -3,7 +3,9 @@ package docker
 import (
 	"io/ioutil"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func testConfig() map[string]interface{} {
@@ -145,3 +147,106 @@ func TestConfigPrepare_pull(t *testing.T) {
 		t.Fatal("should not pull")
 	}
 }
+
+func TestConfigPrepare_commitSettings(t *testing.T) {
+	raw := testConfig()
+
+	// Commit settings without commit
+	raw["cmd"] = []string{"nginx"}
+	raw["labels"] = map[string]string{"foo": "bar"}
+	warns, errs := (&Config{}).Prepare(raw)
+	testConfigErr(t, warns, errs)
+
+	// Good
+	delete(raw, "export_path")
+	raw["commit"] = true
+	warns, errs = (&Config{}).Prepare(raw)
+	testConfigOk(t, warns, errs)
+
+	// Healthcheck command and disable
+	raw["healthcheck"] = map[string]interface{}{
+		"command": []string{"true"},
+		"disable": true,
+	}
+	warns, errs = (&Config{}).Prepare(raw)
+	testConfigErr(t, warns, errs)
+}
+
+func TestConfigPrepare_squash(t *testing.T) {
+	raw := testConfig()
+
+	// Squash without commit
+	raw["squash"] = true
+	warns, errs := (&Config{}).Prepare(raw)
+	testConfigErr(t, warns, errs)
+
+	// Good
+	delete(raw, "export_path")
+	raw["commit"] = true
+	warns, errs = (&Config{}).Prepare(raw)
+	testConfigOk(t, warns, errs)
+
+	// Squash a Windows container
+	raw["windows_container"] = true
+	warns, errs = (&Config{}).Prepare(raw)
+	testConfigErr(t, warns, errs)
+}
+
+func TestConfigPrepare_cacheVolumes(t *testing.T) {
+	raw := testConfig()
+
+	// Relative path
+	raw["cache_volumes"] = []string{"var/cache/apt"}
+	warns, errs := (&Config{}).Prepare(raw)
+	testConfigErr(t, warns, errs)
+
+	// Good
+	raw["cache_volumes"] = []string{"/var/cache/apt", "/root/.cache/pip"}
+	warns, errs = (&Config{}).Prepare(raw)
+	testConfigOk(t, warns, errs)
+}
+
+func TestConfig_commitChanges(t *testing.T) {
+	c := &Config{
+		Changes:    []string{"USER app"},
+		Cmd:        []string{"nginx", "-g", "daemon off;"},
+		Entrypoint: []string{"/entrypoint.sh"},
+		Labels:     map[string]string{"version": "1.0", "maintainer": "ops"},
+		Healthcheck: HealthcheckConfig{
+			Command:  []string{"curl", "-f", "http://localhost/"},
+			Interval: 10 * time.Second,
+			Retries:  5,
+		},
+	}
+
+	expected := []string{
+		"USER app",
+		`LABEL "maintainer"="ops"`,
+		`LABEL "version"="1.0"`,
+		`HEALTHCHECK --interval=10s --retries=5 CMD ["curl","-f","http://localhost/"]`,
+		`ENTRYPOINT ["/entrypoint.sh"]`,
+		`CMD ["nginx","-g","daemon off;"]`,
+	}
+	if changes := c.commitChanges(); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("bad: %#v", changes)
+	}
+
+	c = &Config{Healthcheck: HealthcheckConfig{Disable: true}}
+	expected = []string{"HEALTHCHECK NONE"}
+	if changes := c.commitChanges(); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("bad: %#v", changes)
+	}
+}
+
+func TestCacheVolumeName(t *testing.T) {
+	cases := map[string]string{
+		"/var/cache/apt":   "packer-cache-var-cache-apt",
+		"/root/.cache/pip": "packer-cache-root-.cache-pip",
+		"/go/pkg/mod/":     "packer-cache-go-pkg-mod",
+	}
+	for p, expected := range cases {
+		if name := cacheVolumeName(p); name != expected {
+			t.Fatalf("%s: bad: %s", p, name)
+		}
+	}
+}
@@ -19,7 +19,11 @@ type Driver interface {
 	// Export exports the container with the given ID to the given writer.
 	Export(id string, dst io.Writer) error
 
-	// Import imports a container from a tar file
+	// ImageConfig returns the configuration of the image with the given ID.
+	ImageConfig(id string) (*ImageConfig, error)
+
+	// Import imports a container from a tar file. The image is left
+	// untagged if repo is empty.
 	Import(path string, changes []string, repo string) (string, error)
 
 	// IPAddress returns the address of the container that can be used
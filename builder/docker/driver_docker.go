@@ -2,6 +2,7 @@ package docker
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -106,7 +107,9 @@ func (d *DockerDriver) Import(path string, changes []string, repo string) (strin
 	}
 
 	args = append(args, "-")
-	args = append(args, repo)
+	if repo != "" {
+		args = append(args, repo)
+	}
 
 	cmd := exec.Command("docker", args...)
 	cmd.Stdout = &stdout
@@ -142,6 +145,28 @@ func (d *DockerDriver) Import(path string, changes []string, repo string) (strin
 	return strings.TrimSpace(stdout.String()), nil
 }
 
+func (d *DockerDriver) ImageConfig(id string) (*ImageConfig, error) {
+	var stderr, stdout bytes.Buffer
+	cmd := exec.Command(
+		"docker",
+		"inspect",
+		"--format",
+		"{{ json .Config }}",
+		id)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("Error: %s\n\nStderr: %s", err, stderr.String())
+	}
+
+	var config ImageConfig
+	if err := json.Unmarshal(stdout.Bytes(), &config); err != nil {
+		return nil, fmt.Errorf("Error parsing image configuration: %s", err)
+	}
+
+	return &config, nil
+}
+
 func (d *DockerDriver) IPAddress(id string) (string, error) {
 	var stderr, stdout bytes.Buffer
 	cmd := exec.Command(
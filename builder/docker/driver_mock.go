@@ -10,6 +10,7 @@ import (
 type MockDriver struct {
 	CommitCalled      bool
 	CommitContainerId string
+	CommitChanges     []string
 	CommitImageId     string
 	CommitErr         error
 
@@ -17,11 +18,17 @@ type MockDriver struct {
 	DeleteImageId     string
 	DeleteImageErr    error
 
-	ImportCalled bool
-	ImportPath   string
-	ImportRepo   string
-	ImportId     string
-	ImportErr    error
+	ImageConfigCalled bool
+	ImageConfigId     string
+	ImageConfigResult *ImageConfig
+	ImageConfigErr    error
+
+	ImportCalled  bool
+	ImportPath    string
+	ImportChanges []string
+	ImportRepo    string
+	ImportId      string
+	ImportErr     error
 
 	IPAddressCalled bool
 	IPAddressID     string
@@ -82,6 +89,7 @@ type MockDriver struct {
 func (d *MockDriver) Commit(id string, author string, changes []string, message string) (string, error) {
 	d.CommitCalled = true
 	d.CommitContainerId = id
+	d.CommitChanges = changes
 	return d.CommitImageId, d.CommitErr
 }
 
@@ -105,9 +113,19 @@ func (d *MockDriver) Export(id string, dst io.Writer) error {
 	return d.ExportError
 }
 
+func (d *MockDriver) ImageConfig(id string) (*ImageConfig, error) {
+	d.ImageConfigCalled = true
+	d.ImageConfigId = id
+	if d.ImageConfigResult == nil && d.ImageConfigErr == nil {
+		return &ImageConfig{}, nil
+	}
+	return d.ImageConfigResult, d.ImageConfigErr
+}
+
 func (d *MockDriver) Import(path string, changes []string, repo string) (string, error) {
 	d.ImportCalled = true
 	d.ImportPath = path
+	d.ImportChanges = changes
 	d.ImportRepo = repo
 	return d.ImportId, d.ImportErr
 }
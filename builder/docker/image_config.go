@@ -0,0 +1,142 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImageConfig is the part of the configuration of an image, as returned by
+// `docker inspect`, that can be set again with Dockerfile instructions.
+type ImageConfig struct {
+	Cmd          []string
+	Entrypoint   []string
+	Env          []string
+	ExposedPorts map[string]struct{}
+	Healthcheck  *ImageHealthcheck
+	Labels       map[string]string
+	OnBuild      []string
+	User         string
+	Volumes      map[string]struct{}
+	WorkingDir   string
+}
+
+// ImageHealthcheck is the healthcheck of an image, as returned by
+// `docker inspect`.
+type ImageHealthcheck struct {
+	Test        []string
+	Interval    time.Duration
+	Timeout     time.Duration
+	StartPeriod time.Duration
+	Retries     int
+}
+
+// Changes returns the Dockerfile instructions that set the configuration of
+// the image, in a stable order.
+func (c *ImageConfig) Changes() []string {
+	var changes []string
+
+	for _, env := range c.Env {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("ENV %s=%s", parts[0], strconv.Quote(parts[1])))
+	}
+	if c.WorkingDir != "" {
+		changes = append(changes, "WORKDIR "+c.WorkingDir)
+	}
+	if c.User != "" {
+		changes = append(changes, "USER "+c.User)
+	}
+	for _, port := range sortedKeys(c.ExposedPorts) {
+		changes = append(changes, "EXPOSE "+port)
+	}
+	if volumes := sortedKeys(c.Volumes); len(volumes) > 0 {
+		changes = append(changes, "VOLUME "+execForm(volumes))
+	}
+	changes = append(changes, labelChanges(c.Labels)...)
+	for _, onBuild := range c.OnBuild {
+		changes = append(changes, "ONBUILD "+onBuild)
+	}
+	if c.Healthcheck != nil {
+		changes = append(changes, c.Healthcheck.change())
+	}
+	if c.Entrypoint != nil {
+		changes = append(changes, "ENTRYPOINT "+execForm(c.Entrypoint))
+	}
+	if c.Cmd != nil {
+		changes = append(changes, "CMD "+execForm(c.Cmd))
+	}
+
+	return changes
+}
+
+func (h *ImageHealthcheck) change() string {
+	if len(h.Test) == 0 || h.Test[0] == "NONE" {
+		return "HEALTHCHECK NONE"
+	}
+
+	return healthcheckChange(h.Interval, h.Timeout, h.StartPeriod, h.Retries, h.Test)
+}
+
+// healthcheckChange returns a HEALTHCHECK instruction. test is either a
+// command in exec form, or a command prefixed by "CMD" or "CMD-SHELL" as
+// stored in the configuration of an image.
+func healthcheckChange(interval, timeout, startPeriod time.Duration, retries int, test []string) string {
+	change := "HEALTHCHECK"
+	if interval > 0 {
+		change += " --interval=" + interval.String()
+	}
+	if timeout > 0 {
+		change += " --timeout=" + timeout.String()
+	}
+	if startPeriod > 0 {
+		change += " --start-period=" + startPeriod.String()
+	}
+	if retries > 0 {
+		change += " --retries=" + strconv.Itoa(retries)
+	}
+
+	switch test[0] {
+	case "CMD-SHELL":
+		return change + " CMD " + strings.Join(test[1:], " ")
+	case "CMD":
+		return change + " CMD " + execForm(test[1:])
+	default:
+		return change + " CMD " + execForm(test)
+	}
+}
+
+func labelChanges(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var changes []string
+	for _, k := range keys {
+		changes = append(changes, fmt.Sprintf("LABEL %s=%s", strconv.Quote(k), strconv.Quote(labels[k])))
+	}
+	return changes
+}
+
+// execForm returns the JSON array form of a command for a Dockerfile
+// instruction.
+func execForm(args []string) string {
+	b, _ := json.Marshal(args)
+	return string(b)
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestImageConfig_Changes(t *testing.T) {
+	raw := `{
+		"User": "app",
+		"ExposedPorts": {"8080/tcp": {}, "443/tcp": {}},
+		"Env": ["PATH=/usr/local/bin:/usr/bin", "GREETING=hello \"world\""],
+		"Cmd": ["nginx", "-g", "daemon off;"],
+		"Healthcheck": {
+			"Test": ["CMD-SHELL", "curl -f http://localhost/ || exit 1"],
+			"Interval": 30000000000,
+			"Retries": 3
+		},
+		"Volumes": {"/data": {}},
+		"WorkingDir": "/srv",
+		"Entrypoint": null,
+		"OnBuild": null,
+		"Labels": {"version": "1.0"}
+	}`
+
+	var c ImageConfig
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{
+		`ENV PATH="/usr/local/bin:/usr/bin"`,
+		`ENV GREETING="hello \"world\""`,
+		"WORKDIR /srv",
+		"USER app",
+		"EXPOSE 443/tcp",
+		"EXPOSE 8080/tcp",
+		`VOLUME ["/data"]`,
+		`LABEL "version"="1.0"`,
+		"HEALTHCHECK --interval=30s --retries=3 CMD curl -f http://localhost/ || exit 1",
+		`CMD ["nginx","-g","daemon off;"]`,
+	}
+	if changes := c.Changes(); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("bad: %#v", changes)
+	}
+}
+
+func TestImageConfig_ChangesHealthcheck(t *testing.T) {
+	cases := []struct {
+		Healthcheck ImageHealthcheck
+		Expected    string
+	}{
+		{ImageHealthcheck{Test: []string{"NONE"}}, "HEALTHCHECK NONE"},
+		{ImageHealthcheck{Test: []string{"CMD", "true"}}, `HEALTHCHECK CMD ["true"]`},
+	}
+
+	for _, tc := range cases {
+		c := ImageConfig{Healthcheck: &tc.Healthcheck}
+		changes := c.Changes()
+		if len(changes) != 1 || changes[0] != tc.Expected {
+			t.Fatalf("bad: %#v", changes)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package docker
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/hashicorp/packer/helper/multistep"
@@ -66,3 +67,24 @@ func TestStepCommit_error(t *testing.T) {
 		t.Fatal("shouldn't save image ID")
 	}
 }
+
+func TestStepCommit_changes(t *testing.T) {
+	state := testStepCommitState(t)
+	step := new(StepCommit)
+	defer step.Cleanup(state)
+
+	config := state.Get("config").(*Config)
+	config.Changes = []string{"USER app"}
+	config.Cmd = []string{"nginx"}
+	driver := state.Get("driver").(*MockDriver)
+
+	// run the step
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	expected := []string{"USER app", `CMD ["nginx"]`}
+	if !reflect.DeepEqual(driver.CommitChanges, expected) {
+		t.Fatalf("bad: %#v", driver.CommitChanges)
+	}
+}
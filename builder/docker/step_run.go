@@ -37,6 +37,10 @@ func (s *StepRun) Run(ctx context.Context, state multistep.StateBag) multistep.S
 		runConfig.Volumes[host] = container
 	}
 
+	for _, p := range config.CacheVolumes {
+		runConfig.Volumes[cacheVolumeName(p)] = p
+	}
+
 	tempDir := state.Get("temp_dir").(string)
 	runConfig.Volumes[tempDir] = config.ContainerDir
 
@@ -95,3 +95,23 @@ func TestStepRun_error(t *testing.T) {
 		t.Fatal("should not have stopped")
 	}
 }
+
+func TestStepRun_cacheVolumes(t *testing.T) {
+	state := testStepRunState(t)
+	step := new(StepRun)
+	defer step.Cleanup(state)
+
+	config := state.Get("config").(*Config)
+	config.CacheVolumes = []string{"/var/cache/apt"}
+	driver := state.Get("driver").(*MockDriver)
+	driver.StartID = "foo"
+
+	// run the step
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	if v := driver.StartConfig.Volumes["packer-cache-var-cache-apt"]; v != "/var/cache/apt" {
+		t.Fatalf("bad: %#v", driver.StartConfig.Volumes)
+	}
+}
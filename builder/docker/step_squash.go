@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/tmp"
+)
+
+// StepSquash squashes the layers of the committed image into a single layer
+// by exporting the container and importing it again with the configuration
+// of the committed image.
+type StepSquash struct{}
+
+func (s *StepSquash) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+	driver := state.Get("driver").(Driver)
+	containerId := state.Get("container_id").(string)
+	imageId := state.Get("image_id").(string)
+
+	ui.Say("Squashing the image")
+	imageConfig, err := driver.ImageConfig(imageId)
+	if err != nil {
+		err := fmt.Errorf("Error reading image configuration: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	f, err := tmp.File("packer-docker-squash")
+	if err != nil {
+		err := fmt.Errorf("Error creating temporary file: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	defer os.Remove(f.Name())
+
+	err = driver.Export(containerId, f)
+	f.Close()
+	if err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	squashedId, err := driver.Import(f.Name(), imageConfig.Changes(), "")
+	if err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// The committed image is only an intermediate step now
+	if err := driver.DeleteImage(imageId); err != nil {
+		ui.Error(fmt.Sprintf("Error deleting intermediate image %s: %s", imageId, err))
+	}
+
+	state.Put("image_id", squashedId)
+	ui.Message(fmt.Sprintf("Image ID: %s", squashedId))
+
+	return multistep.ActionContinue
+}
+
+func (s *StepSquash) Cleanup(state multistep.StateBag) {}
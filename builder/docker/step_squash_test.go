@@ -0,0 +1,85 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func testStepSquashState(t *testing.T) multistep.StateBag {
+	state := testState(t)
+	state.Put("container_id", "foo")
+	state.Put("image_id", "bar")
+	return state
+}
+
+func TestStepSquash_impl(t *testing.T) {
+	var _ multistep.Step = new(StepSquash)
+}
+
+func TestStepSquash(t *testing.T) {
+	state := testStepSquashState(t)
+	step := new(StepSquash)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*MockDriver)
+	driver.ImageConfigResult = &ImageConfig{Cmd: []string{"nginx"}}
+	driver.ImportId = "baz"
+
+	// run the step
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	// verify we did the right thing
+	if driver.ImageConfigId != "bar" {
+		t.Fatalf("bad: %#v", driver.ImageConfigId)
+	}
+	if driver.ExportID != "foo" {
+		t.Fatalf("bad: %#v", driver.ExportID)
+	}
+	if driver.ImportRepo != "" {
+		t.Fatalf("bad: %#v", driver.ImportRepo)
+	}
+	if !reflect.DeepEqual(driver.ImportChanges, []string{`CMD ["nginx"]`}) {
+		t.Fatalf("bad: %#v", driver.ImportChanges)
+	}
+	if driver.DeleteImageId != "bar" {
+		t.Fatalf("bad: %#v", driver.DeleteImageId)
+	}
+
+	// verify the ID is saved
+	if id := state.Get("image_id").(string); id != "baz" {
+		t.Fatalf("bad: %#v", id)
+	}
+}
+
+func TestStepSquash_error(t *testing.T) {
+	state := testStepSquashState(t)
+	step := new(StepSquash)
+	defer step.Cleanup(state)
+
+	driver := state.Get("driver").(*MockDriver)
+	driver.ImportErr = errors.New("foo")
+
+	// run the step
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	// verify we have an error
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+
+	// the committed image is kept
+	if driver.DeleteImageCalled {
+		t.Fatal("should not delete the image")
+	}
+	if id := state.Get("image_id").(string); id != "bar" {
+		t.Fatalf("bad: %#v", id)
+	}
+}
@@ -36,6 +36,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"local file"}
+}
+
 // Run is where the actual build should take place. It takes a Build and a Ui.
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	artifact := new(FileArtifact)
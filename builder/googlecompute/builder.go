@@ -9,6 +9,10 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/cost"
+	"github.com/hashicorp/packer/common/firewall"
+	"github.com/hashicorp/packer/common/guest"
+	"github.com/hashicorp/packer/common/preflight"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -33,11 +37,24 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	resources := []string{"temporary compute instance, disk and SSH key"}
+	if b.config.FirewallConfig.TemporaryFirewallRule {
+		resources = append(resources, "temporary firewall rule")
+	}
+	return append(resources, "image")
+}
+
 // Run executes a googlecompute Packer build and returns a packer.Artifact
 // representing a GCE machine image.
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
+	ts, err := b.config.tokenSource()
+	if err != nil {
+		return nil, err
+	}
 	driver, err := NewDriverGCE(
-		ui, b.config.ProjectId, b.config.account, b.config.VaultGCPOauthEngine)
+		ui, b.config.ProjectId, b.config.account, b.config.VaultGCPOauthEngine, ts,
+		&b.config.RateLimitConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +68,12 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 
 	// Build the steps.
 	steps := []multistep.Step{
+		&cost.StepMeter{Prices: Prices},
+		&preflight.StepPreflight{
+			Mode:        b.config.PackerPreflight,
+			Permissions: b.config.preflightPermissions(),
+			Check:       b.config.checkPermissions(driver),
+		},
 		new(StepCheckExistingImage),
 		&StepCreateSSHKey{
 			Debug:        b.config.PackerDebug,
@@ -59,6 +82,12 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		&StepImportOSLoginSSHKey{
 			Debug: b.config.PackerDebug,
 		},
+		&firewall.StepTemporaryRule{
+			Config: &b.config.FirewallConfig,
+			Comm:   &b.config.Comm,
+			Create: createFirewallRule,
+			Delete: deleteFirewallRule,
+		},
 		&StepCreateInstance{
 			Debug: b.config.PackerDebug,
 		},
@@ -81,9 +110,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			SSHConfig:   b.config.Comm.SSHConfigFunc(),
 			WinRMConfig: winrmConfig,
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
 		new(common.StepProvision),
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.Guest,
 		},
 	}
 	if _, exists := b.config.Metadata[StartupScriptKey]; exists || b.config.StartupScriptFile != "" {
@@ -95,6 +143,11 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	b.runner = common.NewRunner(steps, b.config.PackerConfig, ui)
 	b.runner.Run(ctx, state)
 
+	// The build only checked its permissions and was skipped.
+	if common.Skipped(state) {
+		return nil, nil
+	}
+
 	// Report any errors.
 	if rawErr, ok := state.GetOk("error"); ok {
 		return nil, rawErr.(error)
@@ -0,0 +1,96 @@
+package googlecompute
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/packer/common/cleanup"
+	"github.com/hashicorp/packer/packer"
+	"golang.org/x/oauth2/google"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// CleanupProvider is the provider name of the resources the googlecompute
+// builder tracks in the cleanup registry.
+const CleanupProvider = "googlecompute"
+
+// Types of the resources the googlecompute builder tracks in the cleanup
+// registry. Instances and disks live in a zone, firewall rules are global.
+const (
+	CleanupInstance     = "instance"
+	CleanupDisk         = "disk"
+	CleanupFirewallRule = "firewall-rule"
+)
+
+func trackResource(project, zone, typ, name string) {
+	cleanup.Track(cleanup.Resource{
+		Provider: CleanupProvider,
+		Type:     typ,
+		ID:       name,
+		Region:   zone,
+		Account:  project,
+	})
+}
+
+func untrackResource(typ, name string) {
+	cleanup.Untrack(CleanupProvider, typ, name)
+}
+
+// SweepResource removes a resource left behind by the googlecompute builder.
+// It uses the application default credentials of the environment.
+func SweepResource(ctx context.Context, r cleanup.Resource) error {
+	client, err := google.DefaultClient(ctx, DriverScopes...)
+	if err != nil {
+		return err
+	}
+	service, err := compute.New(client)
+	if err != nil {
+		return err
+	}
+
+	var op *compute.Operation
+	switch r.Type {
+	case CleanupInstance:
+		op, err = service.Instances.Delete(r.Account, r.Region, r.ID).Context(ctx).Do()
+	case CleanupDisk:
+		op, err = service.Disks.Delete(r.Account, r.Region, r.ID).Context(ctx).Do()
+	case CleanupFirewallRule:
+		op, err = service.Firewalls.Delete(r.Account, r.ID).Context(ctx).Do()
+	default:
+		return fmt.Errorf("unknown resource type %q", r.Type)
+	}
+	if isNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// The resources created before this one, removed next, may be in use
+	// by it: wait until it is gone.
+	for op.Status != "DONE" {
+		if r.Region != "" {
+			op, err = service.ZoneOperations.Wait(r.Account, r.Region, op.Name).Context(ctx).Do()
+		} else {
+			op, err = service.GlobalOperations.Wait(r.Account, op.Name).Context(ctx).Do()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if op.Error != nil {
+		for _, e := range op.Error.Errors {
+			err = packer.MultiErrorAppend(err, fmt.Errorf(e.Message))
+		}
+	}
+	return err
+}
+
+func isNotFound(err error) bool {
+	if gErr, ok := err.(*googleapi.Error); ok {
+		return gErr.Code == http.StatusNotFound
+	}
+	return false
+}
@@ -9,9 +9,15 @@ import (
 	"os"
 	"regexp"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/firewall"
+	"github.com/hashicorp/packer/common/guest"
+	"github.com/hashicorp/packer/common/naming"
+	"github.com/hashicorp/packer/common/oidc"
+	"github.com/hashicorp/packer/common/ratelimit"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -24,17 +30,53 @@ import (
 // used for ImageName and ImageFamily
 var validImageName = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
 
+// iapSourceRange is where the connections forwarded by IAP come from.
+const iapSourceRange = "35.235.240.0/20"
+
+// scopeAliases maps the gcloud aliases of the service account scopes to their
+// URL.
+var scopeAliases = map[string]string{
+	"bigquery":              "https://www.googleapis.com/auth/bigquery",
+	"cloud-platform":        "https://www.googleapis.com/auth/cloud-platform",
+	"cloud-source-repos":    "https://www.googleapis.com/auth/source.full_control",
+	"cloud-source-repos-ro": "https://www.googleapis.com/auth/source.read_only",
+	"compute-ro":            "https://www.googleapis.com/auth/compute.readonly",
+	"compute-rw":            "https://www.googleapis.com/auth/compute",
+	"datastore":             "https://www.googleapis.com/auth/datastore",
+	"logging-write":         "https://www.googleapis.com/auth/logging.write",
+	"monitoring":            "https://www.googleapis.com/auth/monitoring",
+	"monitoring-read":       "https://www.googleapis.com/auth/monitoring.read",
+	"monitoring-write":      "https://www.googleapis.com/auth/monitoring.write",
+	"pubsub":                "https://www.googleapis.com/auth/pubsub",
+	"service-control":       "https://www.googleapis.com/auth/servicecontrol",
+	"service-management":    "https://www.googleapis.com/auth/service.management.readonly",
+	"sql-admin":             "https://www.googleapis.com/auth/sqlservice.admin",
+	"storage-full":          "https://www.googleapis.com/auth/devstorage.full_control",
+	"storage-ro":            "https://www.googleapis.com/auth/devstorage.read_only",
+	"storage-rw":            "https://www.googleapis.com/auth/devstorage.read_write",
+	"taskqueue":             "https://www.googleapis.com/auth/taskqueue",
+	"trace":                 "https://www.googleapis.com/auth/trace.append",
+	"userinfo-email":        "https://www.googleapis.com/auth/userinfo.email",
+}
+
 // Config is the configuration structure for the GCE builder. It stores
 // both the publicly settable state as well as the privately generated
 // state of the config object.
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 	Comm                communicator.Config `mapstructure:",squash"`
+	Guest               guest.Config        `mapstructure:",squash"`
 
 	// The JSON file containing your account credentials. Not required if you
 	// run Packer on a GCE instance with a service account. Instructions for
 	// creating the file or using service accounts are above.
 	AccountFile string `mapstructure:"account_file" required:"false"`
+	// The command, and its arguments, of a [credential
+	// helper](/docs/credential-helpers) returning the OAuth2 access tokens
+	// Packer authenticates with. The helper is run again when the token
+	// expires during the build. Can't be used with `account_file` or
+	// `vault_gcp_oauth_engine`.
+	CredentialHelper []string `mapstructure:"credential_helper" required:"false"`
 	// The project ID that will be used to launch instances and store images.
 	ProjectId string `mapstructure:"project_id" required:"true"`
 	// Full or partial URL of the guest accelerator type. GPU accelerators can
@@ -73,6 +115,11 @@ type Config struct {
 	// state of your VM instances. Note: integrity monitoring relies on having
 	// vTPM enabled. [Details](https://cloud.google.com/security/shielded-cloud/shielded-vm)
 	EnableIntegrityMonitoring bool `mapstructure:"enable_integrity_monitoring" required:"false"`
+	// Create a Confidential VM, whose memory is encrypted with AMD SEV. It
+	// requires an N2D `machine_type`, `on_host_maintenance` set to `TERMINATE`
+	// (the default for Confidential VMs) and a source image supporting
+	// Confidential Computing. [Details](https://cloud.google.com/compute/confidential-vm/docs/about-cvm)
+	EnableConfidentialCompute bool `mapstructure:"enable_confidential_compute" required:"false"`
 	// Whether to use an IAP proxy.
 	IAPConfig `mapstructure:",squash"`
 	// The unique name of the resulting image. Defaults to
@@ -175,6 +222,10 @@ type Config struct {
 	//   "https://www.googleapis.com/auth/devstorage.full_control"
 	// ]
 	// ```
+	//
+	// Scopes may also be given by their `gcloud` alias, like `cloud-platform`,
+	// `compute-ro` or `storage-ro`, to grant a per-build service account only
+	// the scopes it needs.
 	Scopes []string `mapstructure:"scopes" required:"false"`
 	// The service account to be used for launched instance. Defaults to the
 	// project's default service account unless disable_default_service_account
@@ -276,6 +327,31 @@ type Config struct {
 	// https://www.vaultproject.io/docs/commands/#environment-variables
 	// Example:`"vault_gcp_oauth_engine": "gcp/token/my-project-editor",`
 	VaultGCPOauthEngine string `mapstructure:"vault_gcp_oauth_engine"`
+	// The full name of the workload identity pool provider trusting the OIDC
+	// token of the CI job Packer runs in, like a GitHub Actions or a GitLab
+	// CI job, like
+	// `projects/123456789/locations/global/workloadIdentityPools/my-pool/providers/my-provider`.
+	// See [OIDC](/docs/builders/googlecompute#running-with-oidc). The OIDC
+	// token is exchanged again when the token expires during the build. Can't
+	// be used with `account_file`, `credential_helper` or
+	// `vault_gcp_oauth_engine`.
+	WorkloadIdentityProvider string `mapstructure:"workload_identity_provider" required:"false"`
+	// The email of the service account impersonated with the token of the
+	// workload identity pool. When not set, the token of the workload
+	// identity pool is used directly.
+	ImpersonateServiceAccount string `mapstructure:"impersonate_service_account" required:"false"`
+	// The OIDC token exchanged with the workload identity provider.
+	OIDC oidc.Config `mapstructure:",squash"`
+	// The rate limit, retries and circuit breaker of the Google Compute API
+	// requests.
+	RateLimitConfig ratelimit.Config `mapstructure:",squash"`
+	// The naming policy of the image, applied to `image_name`.
+	NamingConfig naming.Config `mapstructure:",squash"`
+	// The temporary firewall rule of the communicator port. The rule applies
+	// to the instance through a network tag named after it. With `use_iap`,
+	// it allows the IAP TCP forwarding range, `35.235.240.0/20`, unless
+	// `temporary_firewall_source_cidrs` is set.
+	FirewallConfig firewall.Config `mapstructure:",squash"`
 	// The zone in which to launch the instance used to create the image.
 	// Example: "us-central1-a"
 	Zone string `mapstructure:"zone" required:"true"`
@@ -293,6 +369,7 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		InterpolateFilter: &interpolate.RenderFilter{
 			Exclude: []string{
 				"run_command",
+				"name_pattern",
 			},
 		},
 	}, raws...)
@@ -339,7 +416,8 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	// Setting OnHostMaintenance Correct Defaults
 	//   "MIGRATE" : Possible and default if Preemptible is false
 	//   "TERMINATE": Required if Preemptible is true
-	if c.Preemptible {
+	//   "TERMINATE": Required if EnableConfidentialCompute is true
+	if c.Preemptible || (c.EnableConfidentialCompute && c.OnHostMaintenance == "") {
 		c.OnHostMaintenance = "TERMINATE"
 	} else {
 		if c.OnHostMaintenance == "" {
@@ -363,6 +441,28 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		}
 	}
 
+	if c.FirewallConfig.TemporaryFirewallRule {
+		if c.IAPConfig.IAP && len(c.FirewallConfig.TemporaryFirewallSourceCidrs) == 0 {
+			c.FirewallConfig.TemporaryFirewallSourceCidrs = []string{iapSourceRange}
+		}
+		if c.Network == "" {
+			errs = packer.MultiErrorAppend(errs,
+				errors.New("network must be set with temporary_firewall_rule, the rule is created in it"))
+		}
+	}
+	for _, err := range c.FirewallConfig.Prepare() {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+
+	for _, err := range c.NamingConfig.Prepare() {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+	if name, err := c.NamingConfig.Name(&c.ctx, c.ImageName, naming.Google); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	} else {
+		c.ImageName = name
+	}
+
 	// used for ImageName and ImageFamily
 	imageErrorText := "Invalid image %s %q: The first character must be a lowercase letter, and all following characters must be a dash, lowercase letter, or digit, except the last character, which cannot be a dash"
 
@@ -403,6 +503,27 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		c.MachineType = "n1-standard-1"
 	}
 
+	// The billing tags of the build go on its instance, as labels.
+	if len(c.PackerBillingTags) > 0 {
+		if c.Labels == nil {
+			c.Labels = make(map[string]string)
+		}
+		for k, v := range c.PackerBillingTags {
+			c.Labels[billingLabel(k)] = billingLabel(v)
+		}
+	}
+
+	if c.EnableConfidentialCompute {
+		if !strings.HasPrefix(c.MachineType, "n2d-") {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("Confidential VMs require an N2D machine_type, got %q.", c.MachineType))
+		}
+		if c.OnHostMaintenance != "TERMINATE" {
+			errs = packer.MultiErrorAppend(errs,
+				errors.New("on_host_maintenance must be TERMINATE when enable_confidential_compute is true."))
+		}
+	}
+
 	if c.StateTimeout == 0 {
 		c.StateTimeout = 5 * time.Minute
 	}
@@ -411,6 +532,9 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
 		errs = packer.MultiErrorAppend(errs, es...)
 	}
+	if es := c.Guest.Prepare(&c.Comm); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
 
 	// set defaults for IAP
 	if c.IAPConfig.IAPHashBang == "" {
@@ -463,6 +587,17 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 			"https://www.googleapis.com/auth/devstorage.full_control",
 		}
 	}
+	for i, scope := range c.Scopes {
+		if strings.Contains(scope, "/") {
+			continue
+		}
+		if url, ok := scopeAliases[scope]; ok {
+			c.Scopes[i] = url
+		} else {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("Unknown scope alias %q, use the scope URL instead.", scope))
+		}
+	}
 
 	if c.SourceImage == "" && c.SourceImageFamily == "" {
 		errs = packer.MultiErrorAppend(
@@ -479,6 +614,27 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		c.Region = region
 	}
 
+	if len(c.CredentialHelper) > 0 && (c.AccountFile != "" || c.VaultGCPOauthEngine != "") {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("You cannot "+
+			"specify credential_helper with account_file or vault_gcp_oauth_engine."))
+	}
+
+	if c.WorkloadIdentityProvider != "" {
+		if c.AccountFile != "" || c.VaultGCPOauthEngine != "" || len(c.CredentialHelper) > 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("You cannot "+
+				"specify workload_identity_provider with account_file, "+
+				"credential_helper or vault_gcp_oauth_engine."))
+		}
+		errs = packer.MultiErrorAppend(errs, c.OIDC.Prepare()...)
+	} else if c.ImpersonateServiceAccount != "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+			"impersonate_service_account requires workload_identity_provider."))
+	}
+
+	for _, err := range c.RateLimitConfig.Prepare() {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+
 	// Authenticating via an account file
 	if c.AccountFile != "" {
 		if c.VaultGCPOauthEngine != "" {
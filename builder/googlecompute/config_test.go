@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -368,6 +369,83 @@ func TestConfigPrepareServiceAccount(t *testing.T) {
 	}
 }
 
+func TestConfigPrepareConfidentialCompute(t *testing.T) {
+	cases := []struct {
+		Keys   []string
+		Values []interface{}
+		Err    bool
+	}{
+		{
+			[]string{"enable_confidential_compute", "machine_type"},
+			[]interface{}{true, "n2d-standard-2"},
+			false,
+		},
+		{
+			[]string{"enable_confidential_compute", "machine_type"},
+			[]interface{}{true, nil},
+			true,
+		},
+		{
+			[]string{"enable_confidential_compute", "machine_type", "on_host_maintenance"},
+			[]interface{}{true, "n2d-standard-2", "MIGRATE"},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		raw, tempfile := testConfig(t)
+		defer os.Remove(tempfile)
+
+		errStr := ""
+		for k := range tc.Keys {
+
+			// Create the string for error reporting
+			// convert value to string if it can be converted
+			errStr += fmt.Sprintf("%s:%v, ", tc.Keys[k], tc.Values[k])
+			if tc.Values[k] == nil {
+				delete(raw, tc.Keys[k])
+			} else {
+				raw[tc.Keys[k]] = tc.Values[k]
+			}
+		}
+
+		var c Config
+		warns, errs := c.Prepare(raw)
+
+		if tc.Err {
+			testConfigErr(t, warns, errs, strings.TrimRight(errStr, ", "))
+		} else {
+			testConfigOk(t, warns, errs)
+			if c.OnHostMaintenance != "TERMINATE" {
+				t.Fatalf("Confidential VMs should terminate on host maintenance, got %q", c.OnHostMaintenance)
+			}
+		}
+	}
+}
+
+func TestConfigPrepareScopeAliases(t *testing.T) {
+	raw, tempfile := testConfig(t)
+	defer os.Remove(tempfile)
+
+	raw["scopes"] = []string{"cloud-platform", "https://www.googleapis.com/auth/compute", "storage-ro"}
+	var c Config
+	warns, errs := c.Prepare(raw)
+	testConfigOk(t, warns, errs)
+
+	expected := []string{
+		"https://www.googleapis.com/auth/cloud-platform",
+		"https://www.googleapis.com/auth/compute",
+		"https://www.googleapis.com/auth/devstorage.read_only",
+	}
+	if !reflect.DeepEqual(c.Scopes, expected) {
+		t.Fatalf("bad scopes: %#v", c.Scopes)
+	}
+
+	raw["scopes"] = []string{"not-a-scope"}
+	warns, errs = c.Prepare(raw)
+	testConfigErr(t, warns, errs, "unknown scope alias")
+}
+
 func TestConfigPrepareStartupScriptFile(t *testing.T) {
 	config := map[string]interface{}{
 		"project_id":          "project",
@@ -385,6 +463,60 @@ func TestConfigPrepareStartupScriptFile(t *testing.T) {
 	}
 }
 
+func TestConfigPrepareCredentialHelper(t *testing.T) {
+	config, tempfile := testConfig(t)
+	defer os.Remove(tempfile)
+
+	config["credential_helper"] = []string{"gcp-token-helper", "--audience", "packer"}
+
+	var c Config
+	warns, errs := c.Prepare(config)
+	testConfigErr(t, warns, errs, "credential_helper with account_file")
+
+	delete(config, "account_file")
+	c = Config{}
+	warns, errs = c.Prepare(config)
+	testConfigOk(t, warns, errs)
+}
+
+func TestConfigPrepareBillingTags(t *testing.T) {
+	config, tempfile := testConfig(t)
+	defer os.Remove(tempfile)
+
+	config["labels"] = map[string]string{"team": "web"}
+	config["packer_billing_tags"] = map[string]string{"team": "images", "Cost Center": "R&D"}
+
+	var c Config
+	warns, errs := c.Prepare(config)
+	testConfigOk(t, warns, errs)
+
+	if c.Labels["team"] != "images" || c.Labels["cost_center"] != "r_d" {
+		t.Fatalf("unexpected labels %#v", c.Labels)
+	}
+}
+
+func TestConfigPrepareWorkloadIdentity(t *testing.T) {
+	config, tempfile := testConfig(t)
+	defer os.Remove(tempfile)
+
+	config["workload_identity_provider"] = "projects/123/locations/global/workloadIdentityPools/pool/providers/github"
+	config["impersonate_service_account"] = "packer@project.iam.gserviceaccount.com"
+
+	var c Config
+	warns, errs := c.Prepare(config)
+	testConfigErr(t, warns, errs, "workload_identity_provider with account_file")
+
+	delete(config, "account_file")
+	c = Config{}
+	warns, errs = c.Prepare(config)
+	testConfigOk(t, warns, errs)
+
+	delete(config, "workload_identity_provider")
+	c = Config{}
+	warns, errs = c.Prepare(config)
+	testConfigErr(t, warns, errs, "impersonate_service_account without workload_identity_provider")
+}
+
 func TestConfigPrepareIAP_SSH(t *testing.T) {
 	config := map[string]interface{}{
 		"project_id":   "project",
@@ -510,6 +642,32 @@ func TestRegion(t *testing.T) {
 	}
 }
 
+func TestTemporaryFirewallRule(t *testing.T) {
+	raw, tempfile := testConfig(t)
+	defer os.Remove(tempfile)
+	raw["temporary_firewall_rule"] = true
+	raw["use_iap"] = true
+
+	var c Config
+	warns, errs := c.Prepare(raw)
+	testConfigOk(t, warns, errs)
+	if cidrs := c.FirewallConfig.TemporaryFirewallSourceCidrs; len(cidrs) != 1 || cidrs[0] != iapSourceRange {
+		t.Fatalf("the rule should allow the IAP range with use_iap, got %v", cidrs)
+	}
+	if network := firewallNetwork(&c); network != "projects/hashicorp/global/networks/default" {
+		t.Fatalf("bad network: %s", network)
+	}
+
+	raw, tempfile = testConfig(t)
+	defer os.Remove(tempfile)
+	raw["temporary_firewall_rule"] = true
+	raw["subnetwork"] = "projects/hashicorp/regions/us-east1/subnetworks/packer"
+
+	c = Config{}
+	warns, errs = c.Prepare(raw)
+	testConfigErr(t, warns, errs, "temporary_firewall_rule without network")
+}
+
 func TestApplyIAPTunnel_SSH(t *testing.T) {
 	c := &communicator.Config{
 		Type: "ssh",
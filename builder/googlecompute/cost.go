@@ -0,0 +1,44 @@
+package googlecompute
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/packer/common/cost"
+)
+
+// Prices are the default prices of the googlecompute builder, in USD: the
+// on-demand prices of Linux instances and the prices of persistent disks in
+// us-central1.
+var Prices = cost.Prices{
+	"instance/f1-micro":       0.0076,
+	"instance/g1-small":       0.0257,
+	"instance/e2-micro":       0.008376,
+	"instance/e2-small":       0.016751,
+	"instance/e2-medium":      0.033503,
+	"instance/e2-standard-2":  0.067006,
+	"instance/e2-standard-4":  0.134012,
+	"instance/n1-standard-1":  0.0475,
+	"instance/n1-standard-2":  0.095,
+	"instance/n1-standard-4":  0.19,
+	"instance/n2-standard-2":  0.097118,
+	"instance/n2-standard-4":  0.194236,
+	"instance/n2d-standard-2": 0.084492,
+	"instance/n2d-standard-4": 0.168984,
+	"storage/pd-standard":     0.04,
+	"storage/pd-balanced":     0.10,
+	"storage/pd-ssd":          0.17,
+}
+
+var invalidLabelChars = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// billingLabel turns a billing tag key or value into a valid label key or
+// value: lowercase letters, digits, underscores and dashes, at most 63 of
+// them.
+func billingLabel(s string) string {
+	s = invalidLabelChars.ReplaceAllString(strings.ToLower(s), "_")
+	if len(s) > 63 {
+		s = s[:63]
+	}
+	return s
+}
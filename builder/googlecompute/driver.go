@@ -16,6 +16,14 @@ type Driver interface {
 	// Engine.
 	CreateImage(name, description, family, zone, disk string, image_labels map[string]string, image_licenses []string, image_encryption_key *compute.CustomerEncryptionKey, imageStorageLocation []string) (<-chan *Image, <-chan error)
 
+	// CreateFirewallRule creates a firewall rule named name in network of
+	// project, allowing TCP connections to port from sourceRanges to the
+	// instances tagged with name.
+	CreateFirewallRule(project, network, name string, port int, sourceRanges []string) (<-chan error, error)
+
+	// DeleteFirewallRule deletes the firewall rule with the given name.
+	DeleteFirewallRule(project, name string) (<-chan error, error)
+
 	// DeleteImage deletes the image with the given name.
 	DeleteImage(name string) <-chan error
 
@@ -58,6 +66,10 @@ type Driver interface {
 	// RunInstance takes the given config and launches an instance.
 	RunInstance(*InstanceConfig) (<-chan error, error)
 
+	// TestIamPermissions returns the permissions the credentials of the
+	// driver lack in the project among permissions.
+	TestIamPermissions(permissions []string) ([]string, error)
+
 	// WaitForInstance waits for an instance to reach the given state.
 	WaitForInstance(state, zone, name string) <-chan error
 
@@ -82,6 +94,7 @@ type InstanceConfig struct {
 	EnableSecureBoot             bool
 	EnableVtpm                   bool
 	EnableIntegrityMonitoring    bool
+	EnableConfidentialCompute    bool
 	Image                        *Image
 	Labels                       map[string]string
 	MachineType                  string
@@ -1,6 +1,7 @@
 package googlecompute
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
@@ -11,13 +12,17 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
 	oslogin "google.golang.org/api/oslogin/v1"
 
-	"github.com/hashicorp/packer/common/retry"
+	"github.com/hashicorp/packer/common/ratelimit"
+	"github.com/hashicorp/packer/common/wait"
+	commonhelper "github.com/hashicorp/packer/helper/common"
 	"github.com/hashicorp/packer/helper/useragent"
 	"github.com/hashicorp/packer/packer"
 	vaultapi "github.com/hashicorp/vault/api"
@@ -30,13 +35,21 @@ import (
 // driverGCE is a Driver implementation that actually talks to GCE.
 // Create an instance using NewDriverGCE.
 type driverGCE struct {
+	client         *http.Client
 	projectId      string
 	service        *compute.Service
 	osLoginService *oslogin.Service
 	ui             packer.Ui
 }
 
-var DriverScopes = []string{"https://www.googleapis.com/auth/compute", "https://www.googleapis.com/auth/devstorage.full_control"}
+// DriverScopes are the OAuth scopes of the driver. The read-only cloud
+// platform scope lets the preflight checks test the permissions of the
+// credentials.
+var DriverScopes = []string{
+	"https://www.googleapis.com/auth/compute",
+	"https://www.googleapis.com/auth/devstorage.full_control",
+	"https://www.googleapis.com/auth/cloud-platform.read-only",
+}
 
 // Define a TokenSource that gets tokens from Vault
 type OauthTokenSource struct {
@@ -46,6 +59,9 @@ type OauthTokenSource struct {
 func (ots OauthTokenSource) Token() (*oauth2.Token, error) {
 	log.Printf("Retrieving Oauth token from Vault...")
 	vaultConfig := vaultapi.DefaultConfig()
+	if err := commonhelper.ConfigureClient(vaultConfig.HttpClient); err != nil {
+		return nil, err
+	}
 	cli, err := vaultapi.NewClient(vaultConfig)
 	if err != nil {
 		return nil, fmt.Errorf("%s\n", err)
@@ -71,16 +87,27 @@ func (ots OauthTokenSource) Token() (*oauth2.Token, error) {
 
 }
 
-func NewClientGCE(conf *jwt.Config, vaultOauth string) (*http.Client, error) {
+// NewClientGCE returns a client authenticated with the tokens of ts when not
+// nil, like the tokens of a credential helper, or else with Vault, conf, or
+// the default credentials.
+func NewClientGCE(conf *jwt.Config, vaultOauth string, ts oauth2.TokenSource) (*http.Client, error) {
 	var err error
 
 	var client *http.Client
 
-	if vaultOauth != "" {
+	// The requests of the clients, and of their token sources, are sent with
+	// the proxy, the CA bundle and the client certificate of the
+	// environment.
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, commonhelper.HttpClient())
+
+	if ts != nil {
+		return oauth2.NewClient(ctx, ts), nil
+
+	} else if vaultOauth != "" {
 		// Auth with Vault Oauth
 		log.Printf("Using Vault to generate Oauth token.")
 		ts := OauthTokenSource{vaultOauth}
-		return oauth2.NewClient(context.TODO(), ts), nil
+		return oauth2.NewClient(ctx, ts), nil
 
 	} else if conf != nil && len(conf.PrivateKey) > 0 {
 		// Auth with AccountFile if provided
@@ -92,10 +119,10 @@ func NewClientGCE(conf *jwt.Config, vaultOauth string) (*http.Client, error) {
 		// Initiate an http.Client. The following GET request will be
 		// authorized and authenticated on the behalf of
 		// your service account.
-		client = conf.Client(context.TODO())
+		client = conf.Client(ctx)
 	} else {
 		log.Printf("[INFO] Requesting Google token via GCE API Default Client Token Source...")
-		client, err = google.DefaultClient(context.TODO(), DriverScopes...)
+		client, err = google.DefaultClient(ctx, DriverScopes...)
 		// The DefaultClient uses the DefaultTokenSource of the google lib.
 		// The DefaultTokenSource uses the "Application Default Credentials"
 		// It looks for credentials in the following places, preferring the first location found:
@@ -117,11 +144,14 @@ func NewClientGCE(conf *jwt.Config, vaultOauth string) (*http.Client, error) {
 	return client, nil
 }
 
-func NewDriverGCE(ui packer.Ui, p string, conf *jwt.Config, vaultOauth string) (Driver, error) {
-	client, err := NewClientGCE(conf, vaultOauth)
+// NewDriverGCE returns a driver sending the API requests through the
+// middleware of rateLimit, when not nil.
+func NewDriverGCE(ui packer.Ui, p string, conf *jwt.Config, vaultOauth string, ts oauth2.TokenSource, rateLimit *ratelimit.Config) (Driver, error) {
+	client, err := NewClientGCE(conf, vaultOauth, ts)
 	if err != nil {
 		return nil, err
 	}
+	client = rateLimit.Client("googlecompute", client)
 
 	log.Printf("[INFO] Instantiating GCE client...")
 	service, err := compute.New(client)
@@ -138,6 +168,7 @@ func NewDriverGCE(ui packer.Ui, p string, conf *jwt.Config, vaultOauth string) (
 	service.UserAgent = useragent.String()
 
 	return &driverGCE{
+		client:         client,
 		projectId:      p,
 		service:        service,
 		osLoginService: osLoginService,
@@ -186,6 +217,39 @@ func (d *driverGCE) CreateImage(name, description, family, zone, disk string, im
 	return imageCh, errCh
 }
 
+func (d *driverGCE) CreateFirewallRule(project, network, name string, port int, sourceRanges []string) (<-chan error, error) {
+	rule := &compute.Firewall{
+		Name:        name,
+		Description: "Temporary rule created by Packer",
+		Network:     network,
+		Direction:   "INGRESS",
+		Allowed: []*compute.FirewallAllowed{
+			{IPProtocol: "tcp", Ports: []string{strconv.Itoa(port)}},
+		},
+		SourceRanges: sourceRanges,
+		TargetTags:   []string{name},
+	}
+	op, err := d.service.Firewalls.Insert(project, rule).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	errCh := make(chan error, 1)
+	go waitForState(errCh, "DONE", d.refreshProjectGlobalOp(project, op))
+	return errCh, nil
+}
+
+func (d *driverGCE) DeleteFirewallRule(project, name string) (<-chan error, error) {
+	op, err := d.service.Firewalls.Delete(project, name).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	errCh := make(chan error, 1)
+	go waitForState(errCh, "DONE", d.refreshProjectGlobalOp(project, op))
+	return errCh, nil
+}
+
 func (d *driverGCE) DeleteImage(name string) <-chan error {
 	errCh := make(chan error, 1)
 	op, err := d.service.Images.Delete(d.projectId, name).Do()
@@ -492,8 +556,14 @@ func (d *driverGCE) RunInstance(c *InstanceConfig) (<-chan error, error) {
 		shieldedUiMessage = " Shielded VM"
 	}
 
-	d.ui.Message(fmt.Sprintf("Requesting%s instance creation...", shieldedUiMessage))
-	op, err := d.service.Instances.Insert(d.projectId, zone.Name, &instance).Do()
+	var op *compute.Operation
+	if c.EnableConfidentialCompute {
+		d.ui.Message(fmt.Sprintf("Requesting%s Confidential VM instance creation...", shieldedUiMessage))
+		op, err = d.insertConfidentialInstance(zone.Name, &instance)
+	} else {
+		d.ui.Message(fmt.Sprintf("Requesting%s instance creation...", shieldedUiMessage))
+		op, err = d.service.Instances.Insert(d.projectId, zone.Name, &instance).Do()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -503,6 +573,56 @@ func (d *driverGCE) RunInstance(c *InstanceConfig) (<-chan error, error) {
 	return errCh, nil
 }
 
+// insertConfidentialInstance inserts an instance with Confidential Computing
+// enabled. The vendored compute API predates Confidential VMs, so the request
+// is sent by hand.
+func (d *driverGCE) insertConfidentialInstance(zone string, instance *compute.Instance) (*compute.Operation, error) {
+	body, err := confidentialInstanceBody(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s%s/zones/%s/instances", d.service.BasePath, d.projectId, zone)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", googleapi.UserAgent+" "+d.service.UserAgent)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(resp)
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+
+	op := &compute.Operation{}
+	if err := json.NewDecoder(resp.Body).Decode(op); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// confidentialInstanceBody returns the JSON representation of instance with
+// Confidential Computing enabled.
+func confidentialInstanceBody(instance *compute.Instance) ([]byte, error) {
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	body["confidentialInstanceConfig"] = map[string]interface{}{
+		"enableConfidentialCompute": true,
+	}
+	return json.Marshal(body)
+}
+
 func (d *driverGCE) CreateOrResetWindowsPassword(instance, zone string, c *WindowsPasswordConfig) (<-chan error, error) {
 
 	errCh := make(chan error, 1)
@@ -552,42 +672,41 @@ func (d *driverGCE) createWindowsPassword(errCh chan<- error, name, zone string,
 		return
 	}
 
-	timeout := time.Now().Add(time.Minute * 3)
 	hash := sha1.New()
 	random := rand.Reader
 
-	for time.Now().Before(timeout) {
-		if passwordResponses, err := d.getPasswordResponses(zone, name); err == nil {
-			for _, response := range passwordResponses {
-				if response.Modulus == c.Modulus {
-
-					decodedPassword, err := base64.StdEncoding.DecodeString(response.EncryptedPassword)
-
-					if err != nil {
-						errCh <- err
-						return
-					}
-					password, err := rsa.DecryptOAEP(hash, random, c.key, decodedPassword, nil)
-
-					if err != nil {
-						errCh <- err
-						return
-					}
-
-					c.password = string(password)
-					errCh <- nil
-					return
+	err = wait.Config{
+		Description: "the Windows password",
+		Timeout:     3 * time.Minute,
+		MinDelay:    2 * time.Second,
+		MaxDelay:    2 * time.Second,
+	}.Until(context.TODO(), func(context.Context) (bool, string, error) {
+		passwordResponses, err := d.getPasswordResponses(zone, name)
+		if err != nil {
+			return false, "", nil
+		}
+		for _, response := range passwordResponses {
+			if response.Modulus == c.Modulus {
+				decodedPassword, err := base64.StdEncoding.DecodeString(response.EncryptedPassword)
+				if err != nil {
+					return false, "", err
+				}
+				password, err := rsa.DecryptOAEP(hash, random, c.key, decodedPassword, nil)
+				if err != nil {
+					return false, "", err
 				}
+
+				c.password = string(password)
+				return true, "set", nil
 			}
 		}
-
-		time.Sleep(2 * time.Second)
+		return false, "", nil
+	})
+	if _, ok := err.(*wait.TimeoutError); ok {
+		err = errors.New("Could not retrieve password. Timed out.")
 	}
-	err = errors.New("Could not retrieve password. Timed out.")
 
 	errCh <- err
-	return
-
 }
 
 func (d *driverGCE) getPasswordResponses(zone, instance string) ([]windowsPasswordResponse, error) {
@@ -635,6 +754,43 @@ func (d *driverGCE) DeleteOSLoginSSHKey(user, fingerprint string) error {
 	return nil
 }
 
+// testIamPermissionsURL is the testIamPermissions method of the Resource
+// Manager API, which the compute API lacks for projects.
+const testIamPermissionsURL = "https://cloudresourcemanager.googleapis.com/v1/projects/%s:testIamPermissions"
+
+func (d *driverGCE) TestIamPermissions(permissions []string) ([]string, error) {
+	body, err := json.Marshal(map[string][]string{"permissions": permissions})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.client.Post(fmt.Sprintf(testIamPermissionsURL, d.projectId), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var granted struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&granted); err != nil {
+		return nil, err
+	}
+	has := make(map[string]bool, len(granted.Permissions))
+	for _, p := range granted.Permissions {
+		has[p] = true
+	}
+	var missing []string
+	for _, p := range permissions {
+		if !has[p] {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
+
 func (d *driverGCE) WaitForInstance(state, zone, name string) <-chan error {
 	errCh := make(chan error, 1)
 	go waitForState(errCh, state, d.refreshInstanceState(zone, name))
@@ -652,8 +808,14 @@ func (d *driverGCE) refreshInstanceState(zone, name string) stateRefreshFunc {
 }
 
 func (d *driverGCE) refreshGlobalOp(op *compute.Operation) stateRefreshFunc {
+	return d.refreshProjectGlobalOp(d.projectId, op)
+}
+
+// refreshProjectGlobalOp refreshes a global operation of project, like the
+// operations on the firewall rules of a shared network.
+func (d *driverGCE) refreshProjectGlobalOp(project string, op *compute.Operation) stateRefreshFunc {
 	return func() (string, error) {
-		newOp, err := d.service.GlobalOperations.Get(d.projectId, op.Name).Do()
+		newOp, err := d.service.GlobalOperations.Get(project, op.Name).Do()
 		if err != nil {
 			return "", err
 		}
@@ -697,20 +859,18 @@ func (d *driverGCE) refreshZoneOp(zone string, op *compute.Operation) stateRefre
 type stateRefreshFunc func() (string, error)
 
 // waitForState will spin in a loop forever waiting for state to
-// reach a certain target.
+// reach a certain target. Errors of refresh are logged and retried.
 func waitForState(errCh chan<- error, target string, refresh stateRefreshFunc) error {
-	ctx := context.TODO()
-	err := retry.Config{
-		RetryDelay: (&retry.Backoff{InitialBackoff: 2 * time.Second, MaxBackoff: 2 * time.Second, Multiplier: 2}).Linear,
-	}.Run(ctx, func(ctx context.Context) error {
+	err := wait.Config{
+		Description: fmt.Sprintf("state %s", target),
+		MaxDelay:    10 * time.Second,
+	}.Until(context.TODO(), func(context.Context) (bool, string, error) {
 		state, err := refresh()
 		if err != nil {
-			return err
-		}
-		if state == target {
-			return nil
+			log.Printf("[WARN] Error refreshing the state, retrying: %s", err)
+			return false, "", nil
 		}
-		return fmt.Errorf("retrying for state %s, got %s", target, state)
+		return state == target, state, nil
 	})
 	errCh <- err
 	return err
@@ -25,6 +25,19 @@ type DriverMock struct {
 	CreateImageErrCh            <-chan error
 	CreateImageResultCh         <-chan *Image
 
+	CreateFirewallRuleProject      string
+	CreateFirewallRuleNetwork      string
+	CreateFirewallRuleName         string
+	CreateFirewallRulePort         int
+	CreateFirewallRuleSourceRanges []string
+	CreateFirewallRuleErrCh        <-chan error
+	CreateFirewallRuleErr          error
+
+	DeleteFirewallRuleProject string
+	DeleteFirewallRuleName    string
+	DeleteFirewallRuleErrCh   <-chan error
+	DeleteFirewallRuleErr     error
+
 	DeleteImageName  string
 	DeleteImageErrCh <-chan error
 
@@ -78,6 +91,10 @@ type DriverMock struct {
 	RunInstanceErrCh  <-chan error
 	RunInstanceErr    error
 
+	TestIamPermissionsPermissions []string
+	TestIamPermissionsMissing     []string
+	TestIamPermissionsErr         error
+
 	CreateOrResetWindowsPasswordZone     string
 	CreateOrResetWindowsPasswordInstance string
 	CreateOrResetWindowsPasswordConfig   *WindowsPasswordConfig
@@ -137,6 +154,37 @@ func (d *DriverMock) CreateImage(name, description, family, zone, disk string, i
 	return resultCh, errCh
 }
 
+func (d *DriverMock) CreateFirewallRule(project, network, name string, port int, sourceRanges []string) (<-chan error, error) {
+	d.CreateFirewallRuleProject = project
+	d.CreateFirewallRuleNetwork = network
+	d.CreateFirewallRuleName = name
+	d.CreateFirewallRulePort = port
+	d.CreateFirewallRuleSourceRanges = sourceRanges
+
+	resultCh := d.CreateFirewallRuleErrCh
+	if resultCh == nil {
+		ch := make(chan error)
+		close(ch)
+		resultCh = ch
+	}
+
+	return resultCh, d.CreateFirewallRuleErr
+}
+
+func (d *DriverMock) DeleteFirewallRule(project, name string) (<-chan error, error) {
+	d.DeleteFirewallRuleProject = project
+	d.DeleteFirewallRuleName = name
+
+	resultCh := d.DeleteFirewallRuleErrCh
+	if resultCh == nil {
+		ch := make(chan error)
+		close(ch)
+		resultCh = ch
+	}
+
+	return resultCh, d.DeleteFirewallRuleErr
+}
+
 func (d *DriverMock) DeleteImage(name string) <-chan error {
 	d.DeleteImageName = name
 
@@ -240,6 +288,11 @@ func (d *DriverMock) RunInstance(c *InstanceConfig) (<-chan error, error) {
 	return resultCh, d.RunInstanceErr
 }
 
+func (d *DriverMock) TestIamPermissions(permissions []string) ([]string, error) {
+	d.TestIamPermissionsPermissions = permissions
+	return d.TestIamPermissionsMissing, d.TestIamPermissionsErr
+}
+
 func (d *DriverMock) WaitForInstance(state, zone, name string) <-chan error {
 	d.WaitForInstanceState = state
 	d.WaitForInstanceZone = zone
@@ -0,0 +1,60 @@
+package googlecompute
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common/firewall"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// firewallNetwork returns the partial URL of the network the temporary
+// firewall rule is created in.
+func firewallNetwork(c *Config) string {
+	if strings.Contains(c.Network, "/") {
+		return c.Network
+	}
+	return "projects/" + c.NetworkProjectId + "/global/networks/" + c.Network
+}
+
+// createFirewallRule creates the temporary firewall rule in the network of
+// the instance, for the instance to be tagged with its name.
+func createFirewallRule(ctx context.Context, state multistep.StateBag, rule *firewall.Rule) error {
+	c := state.Get("config").(*Config)
+	d := state.Get("driver").(Driver)
+
+	errCh, err := d.CreateFirewallRule(c.NetworkProjectId, firewallNetwork(c), rule.Name, rule.Port, rule.SourceCidrs)
+	if err != nil {
+		return err
+	}
+	trackResource(c.NetworkProjectId, "", CleanupFirewallRule, rule.Name)
+	return waitFirewallOp(ctx, c, errCh)
+}
+
+func deleteFirewallRule(ctx context.Context, state multistep.StateBag, rule *firewall.Rule) error {
+	c := state.Get("config").(*Config)
+	d := state.Get("driver").(Driver)
+
+	errCh, err := d.DeleteFirewallRule(c.NetworkProjectId, rule.Name)
+	if err != nil {
+		return err
+	}
+	if err := waitFirewallOp(ctx, c, errCh); err != nil {
+		return err
+	}
+	untrackResource(CleanupFirewallRule, rule.Name)
+	return nil
+}
+
+func waitFirewallOp(ctx context.Context, c *Config, errCh <-chan error) error {
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(c.StateTimeout):
+		return errors.New("time out while waiting for the firewall rule")
+	}
+}
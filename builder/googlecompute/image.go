@@ -25,6 +25,15 @@ func (i *Image) IsWindows() bool {
 	return false
 }
 
+func (i *Image) IsConfidentialComputeCompatible() bool {
+	for _, osFeature := range i.GuestOsFeatures {
+		if osFeature.Type == "SEV_CAPABLE" {
+			return true
+		}
+	}
+	return false
+}
+
 func (i *Image) IsSecureBootCompatible() bool {
 	for _, osFeature := range i.GuestOsFeatures {
 		if osFeature.Type == "UEFI_COMPATIBLE" {
@@ -0,0 +1,71 @@
+package googlecompute
+
+import (
+	"context"
+
+	"github.com/hashicorp/packer/common/preflight"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// preflightPermissions returns the IAM permissions the build requires in
+// the project.
+func (c *Config) preflightPermissions() []string {
+	permissions := []string{
+		"compute.disks.create",
+		"compute.disks.delete",
+		"compute.disks.get",
+		"compute.globalOperations.get",
+		"compute.images.create",
+		"compute.images.get",
+		"compute.images.useReadOnly",
+		"compute.instances.create",
+		"compute.instances.delete",
+		"compute.instances.get",
+		"compute.instances.getSerialPortOutput",
+		"compute.instances.setMetadata",
+		"compute.subnetworks.use",
+		"compute.zoneOperations.get",
+	}
+	if c.PackerForce {
+		permissions = append(permissions, "compute.images.delete")
+	}
+	if !c.OmitExternalIP {
+		permissions = append(permissions, "compute.subnetworks.useExternalIp")
+	}
+	if c.Address != "" {
+		permissions = append(permissions, "compute.addresses.use")
+	}
+	if len(c.Labels) > 0 {
+		permissions = append(permissions, "compute.instances.setLabels")
+	}
+	if len(c.Tags) > 0 || c.FirewallConfig.TemporaryFirewallRule {
+		permissions = append(permissions, "compute.instances.setTags")
+	}
+	// The permissions on a shared network are in its project.
+	if c.FirewallConfig.TemporaryFirewallRule && c.NetworkProjectId == c.ProjectId {
+		permissions = append(permissions,
+			"compute.firewalls.create",
+			"compute.firewalls.delete",
+			"compute.networks.updatePolicy")
+	}
+	if !c.DisableDefaultServiceAccount {
+		permissions = append(permissions, "compute.instances.setServiceAccount", "iam.serviceAccounts.actAs")
+	}
+	if c.IAP {
+		permissions = append(permissions, "iap.tunnelInstances.accessViaIAP")
+	}
+	return permissions
+}
+
+// checkPermissions is the preflight.Checker of the build, testing the
+// permissions of the credentials of the driver in the project.
+func (c *Config) checkPermissions(driver Driver) preflight.Checker {
+	return func(_ context.Context, _ multistep.StateBag, permissions []string) (string, []string, error) {
+		principal := c.ImpersonateServiceAccount
+		if c.account != nil {
+			principal = c.account.Email
+		}
+		missing, err := driver.TestIamPermissions(permissions)
+		return principal, missing, err
+	}
+}
@@ -0,0 +1,39 @@
+package googlecompute
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_checkPermissions(t *testing.T) {
+	c := testConfigStruct(t)
+	c.DisableDefaultServiceAccount = true
+	c.OmitExternalIP = true
+	c.IAP = true
+
+	permissions := c.preflightPermissions()
+	for _, p := range []string{"compute.instances.setServiceAccount", "compute.subnetworks.useExternalIp", "compute.images.delete"} {
+		for _, q := range permissions {
+			if p == q {
+				t.Fatalf("%s shouldn't be required", p)
+			}
+		}
+	}
+
+	driver := new(DriverMock)
+	driver.TestIamPermissionsMissing = []string{"iap.tunnelInstances.accessViaIAP"}
+	principal, missing, err := c.checkPermissions(driver)(context.Background(), nil, permissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal != c.account.Email {
+		t.Fatalf("unexpected principal %q", principal)
+	}
+	if !reflect.DeepEqual(driver.TestIamPermissionsPermissions, permissions) {
+		t.Fatalf("unexpected tested permissions %v", driver.TestIamPermissionsPermissions)
+	}
+	if !reflect.DeepEqual(missing, driver.TestIamPermissionsMissing) {
+		t.Fatalf("unexpected missing permissions %v", missing)
+	}
+}
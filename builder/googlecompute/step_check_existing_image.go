@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/packer/common/naming"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
 
 // StepCheckExistingImage represents a Packer build step that checks if the
-// target image already exists, and aborts immediately if so.
+// target image already exists, and aborts immediately if so, unless the image
+// name is suffixed as set with name_collision.
 type StepCheckExistingImage int
 
 // Run executes the Packer build step that checks if the image already exists.
@@ -19,6 +21,18 @@ func (s *StepCheckExistingImage) Run(ctx context.Context, state multistep.StateB
 	ui := state.Get("ui").(packer.Ui)
 
 	ui.Say("Checking image does not exist...")
+	name, err := c.NamingConfig.Unique(c.ImageName, naming.Google, func(name string) (bool, error) {
+		return d.ImageExists(name), nil
+	})
+	if err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	if name != c.ImageName {
+		ui.Message(fmt.Sprintf("Image %s already exists, naming the image %s", c.ImageName, name))
+		c.ImageName = name
+	}
 	c.imageAlreadyExists = d.ImageExists(c.ImageName)
 	if !c.PackerForce && c.imageAlreadyExists {
 		err := fmt.Errorf("Image %s already exists.\n"+
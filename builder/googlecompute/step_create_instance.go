@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/packer/common/cost"
+	"github.com/hashicorp/packer/common/firewall"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
@@ -121,6 +123,13 @@ func (s *StepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 		return multistep.ActionHalt
 	}
 
+	if c.EnableConfidentialCompute && !sourceImage.IsConfidentialComputeCompatible() {
+		err := fmt.Errorf("Image: %s does not support Confidential Computing. Please set 'enable_confidential_compute' to false or choose another source image.", sourceImage.Name)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
 	ui.Say(fmt.Sprintf("Using image: %s", sourceImage.Name))
 
 	if sourceImage.IsWindows() && c.Comm.Type == "winrm" && c.Comm.WinRMPassword == "" {
@@ -139,6 +148,13 @@ func (s *StepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 		return multistep.ActionHalt
 	}
 
+	// The temporary firewall rule applies to the instances tagged with its
+	// name.
+	tags := c.Tags
+	if rule, ok := state.GetOk(firewall.StateRule); ok {
+		tags = append(append([]string{}, c.Tags...), rule.(string))
+	}
+
 	errCh, err = d.RunInstance(&InstanceConfig{
 		AcceleratorType:              c.AcceleratorType,
 		AcceleratorCount:             c.AcceleratorCount,
@@ -150,6 +166,7 @@ func (s *StepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 		EnableSecureBoot:             c.EnableSecureBoot,
 		EnableVtpm:                   c.EnableVtpm,
 		EnableIntegrityMonitoring:    c.EnableIntegrityMonitoring,
+		EnableConfidentialCompute:    c.EnableConfidentialCompute,
 		Image:                        sourceImage,
 		Labels:                       c.Labels,
 		MachineType:                  c.MachineType,
@@ -165,11 +182,15 @@ func (s *StepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 		ServiceAccountEmail:          c.ServiceAccountEmail,
 		Scopes:                       c.Scopes,
 		Subnetwork:                   c.Subnetwork,
-		Tags:                         c.Tags,
+		Tags:                         tags,
 		Zone:                         c.Zone,
 	})
 
 	if err == nil {
+		// The boot disk is tracked first so that it is removed after the
+		// instance.
+		trackResource(c.ProjectId, c.Zone, CleanupDisk, c.DiskName)
+		trackResource(c.ProjectId, c.Zone, CleanupInstance, name)
 		ui.Message("Waiting for creation operation to complete...")
 		select {
 		case err = <-errCh:
@@ -199,6 +220,10 @@ func (s *StepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 	// instance id inside of the provisioners, used in step_provision.
 	state.Put("instance_id", name)
 
+	m := cost.FromState(state)
+	m.Start(cost.Instance, name, c.MachineType, 0)
+	m.Start(cost.Storage, c.DiskName, c.DiskType, float64(c.DiskSizeGb))
+
 	return multistep.ActionContinue
 }
 
@@ -216,12 +241,15 @@ func (s *StepCreateInstance) Cleanup(state multistep.StateBag) {
 	config := state.Get("config").(*Config)
 	driver := state.Get("driver").(Driver)
 	ui := state.Get("ui").(packer.Ui)
+	ctx := multistep.CleanupContext(state)
 
 	ui.Say("Deleting instance...")
 	errCh, err := driver.DeleteInstance(config.Zone, name)
 	if err == nil {
 		select {
 		case err = <-errCh:
+		case <-ctx.Done():
+			err = ctx.Err()
 		case <-time.After(config.StateTimeout):
 			err = errors.New("time out while waiting for instance to delete")
 		}
@@ -232,10 +260,13 @@ func (s *StepCreateInstance) Cleanup(state multistep.StateBag) {
 			"Error deleting instance. Please delete it manually.\n\n"+
 				"Name: %s\n"+
 				"Error: %s", name, err))
+	} else {
+		untrackResource(CleanupInstance, name)
 	}
 
 	ui.Message("Instance has been deleted!")
 	state.Put("instance_name", "")
+	cost.FromState(state).Stop(cost.Instance, name)
 
 	// Deleting the instance does not remove the boot disk. This cleanup removes
 	// the disk.
@@ -244,6 +275,8 @@ func (s *StepCreateInstance) Cleanup(state multistep.StateBag) {
 	if err == nil {
 		select {
 		case err = <-errCh:
+		case <-ctx.Done():
+			err = ctx.Err()
 		case <-time.After(config.StateTimeout):
 			err = errors.New("time out while waiting for disk to delete")
 		}
@@ -254,9 +287,12 @@ func (s *StepCreateInstance) Cleanup(state multistep.StateBag) {
 			"Error deleting disk. Please delete it manually.\n\n"+
 				"Name: %s\n"+
 				"Error: %s", config.InstanceName, err))
+	} else {
+		untrackResource(CleanupDisk, config.DiskName)
 	}
 
 	ui.Message("Disk has been deleted!")
+	cost.FromState(state).Stop(cost.Storage, config.DiskName)
 
 	return
 }
@@ -2,15 +2,22 @@ package googlecompute
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/packer/common/cleanup"
+	"github.com/hashicorp/packer/common/firewall"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/stretchr/testify/assert"
+	compute "google.golang.org/api/compute/v1"
 )
 
 func TestStepCreateInstance_impl(t *testing.T) {
@@ -45,6 +52,67 @@ func TestStepCreateInstance(t *testing.T) {
 	assert.Equal(t, d.DeleteDiskZone, c.Zone, "Incorrect disk zone passed to driver.")
 }
 
+// trackedResources returns the resources tracked in the cleanup registry.
+func trackedResources(t *testing.T) []string {
+	files, _ := filepath.Glob(filepath.Join(os.Getenv("PACKER_CLEANUP_DIR"), "*.json"))
+	var resources []string
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rec cleanup.Record
+		if err := json.Unmarshal(b, &rec); err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range rec.Resources {
+			resources = append(resources, r.String())
+		}
+	}
+	return resources
+}
+
+func TestStepCreateInstance_cleanupRegistry(t *testing.T) {
+	state := testState(t)
+	step := new(StepCreateInstance)
+	state.Put("ssh_public_key", "key")
+
+	c := state.Get("config").(*Config)
+	c.InstanceName = "packer-tracked"
+	c.DiskName = "packer-tracked-disk"
+	d := state.Get("driver").(*DriverMock)
+	d.GetImageResult = StubImage("test-image", "test-project", []string{}, 100)
+
+	assert.Equal(t, step.Run(context.Background(), state), multistep.ActionContinue, "Step should have passed and continued.")
+	where := fmt.Sprintf(" (%s, %s)", c.ProjectId, c.Zone)
+	tracked := trackedResources(t)
+	assert.Contains(t, tracked, "googlecompute disk packer-tracked-disk"+where, "The disk should be tracked.")
+	assert.Contains(t, tracked, "googlecompute instance packer-tracked"+where, "The instance should be tracked.")
+
+	step.Cleanup(state)
+	for _, r := range trackedResources(t) {
+		assert.NotContains(t, r, "packer-tracked", "The deleted resources should not be tracked anymore.")
+	}
+}
+
+func TestStepCreateInstance_firewallRule(t *testing.T) {
+	state := testState(t)
+	step := new(StepCreateInstance)
+	defer step.Cleanup(state)
+
+	state.Put("ssh_public_key", "key")
+	state.Put(firewall.StateRule, "packer-rule")
+
+	c := state.Get("config").(*Config)
+	c.Tags = []string{"web"}
+	d := state.Get("driver").(*DriverMock)
+	d.GetImageResult = StubImage("test-image", "test-project", []string{}, 100)
+
+	assert.Equal(t, step.Run(context.Background(), state), multistep.ActionContinue, "Step should have passed and continued.")
+	assert.Equal(t, []string{"web", "packer-rule"}, d.RunInstanceConfig.Tags, "The instance should be tagged with the firewall rule.")
+	assert.Equal(t, []string{"web"}, c.Tags, "The tags of the config should be left as is.")
+}
+
 func TestStepCreateInstance_fromFamily(t *testing.T) {
 	cases := []struct {
 		Name   string
@@ -385,3 +453,44 @@ func TestCreateInstanceMetadata_withWrapStartupScript(t *testing.T) {
 		assert.Equal(t, tc.WrappedStartupScriptStatus, metadata[StartupScriptStatusKey], fmt.Sprintf("Instance metadata startup script status should be %q.", tc.WrappedStartupScriptStatus))
 	}
 }
+
+func TestStepCreateInstance_confidentialCompute(t *testing.T) {
+	state := testState(t)
+	step := new(StepCreateInstance)
+	defer step.Cleanup(state)
+
+	state.Put("ssh_public_key", "key")
+
+	c := state.Get("config").(*Config)
+	c.EnableConfidentialCompute = true
+	d := state.Get("driver").(*DriverMock)
+	d.GetImageResult = StubImage("test-image", "test-project", []string{}, 100)
+
+	// run the step
+	assert.Equal(t, step.Run(context.Background(), state), multistep.ActionHalt, "Step should have failed on an image without Confidential Computing support.")
+	_, ok := state.GetOk("error")
+	assert.True(t, ok, "State should have an error.")
+
+	state.Remove("error")
+	d.GetImageResult.GuestOsFeatures = []*compute.GuestOsFeature{{Type: "SEV_CAPABLE"}}
+	assert.Equal(t, step.Run(context.Background(), state), multistep.ActionContinue, "Step should have passed and continued.")
+
+	// cleanup
+	step.Cleanup(state)
+
+	assert.True(t, d.RunInstanceConfig.EnableConfidentialCompute, "Confidential Computing should be passed to driver.")
+}
+
+func TestConfidentialInstanceBody(t *testing.T) {
+	body, err := confidentialInstanceBody(&compute.Instance{Name: "packer"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var instance map[string]interface{}
+	if err := json.Unmarshal(body, &instance); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assert.Equal(t, "packer", instance["name"], "Instance should keep its fields.")
+	assert.Equal(t, map[string]interface{}{"enableConfidentialCompute": true}, instance["confidentialInstanceConfig"], "Instance should enable Confidential Computing.")
+}
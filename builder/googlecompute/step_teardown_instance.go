@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hashicorp/packer/common/cost"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
@@ -46,8 +47,10 @@ func (s *StepTeardownInstance) Run(ctx context.Context, state multistep.StateBag
 				"Error: %s", name, err))
 		return multistep.ActionHalt
 	}
+	untrackResource(CleanupInstance, name)
 	ui.Message("Instance has been deleted!")
 	state.Put("instance_name", "")
+	cost.FromState(state).Stop(cost.Instance, name)
 
 	return multistep.ActionContinue
 }
@@ -58,12 +61,15 @@ func (s *StepTeardownInstance) Cleanup(state multistep.StateBag) {
 	config := state.Get("config").(*Config)
 	driver := state.Get("driver").(Driver)
 	ui := state.Get("ui").(packer.Ui)
+	ctx := multistep.CleanupContext(state)
 
 	ui.Say("Deleting disk...")
 	errCh, err := driver.DeleteDisk(config.Zone, config.DiskName)
 	if err == nil {
 		select {
 		case err = <-errCh:
+		case <-ctx.Done():
+			err = ctx.Err()
 		case <-time.After(config.StateTimeout):
 			err = errors.New("time out while waiting for disk to delete")
 		}
@@ -75,9 +81,12 @@ func (s *StepTeardownInstance) Cleanup(state multistep.StateBag) {
 				"DiskName: %s\n"+
 				"Zone: %s\n"+
 				"Error: %s", config.DiskName, config.Zone, err))
+	} else {
+		untrackResource(CleanupDisk, config.DiskName)
 	}
 
 	ui.Message("Disk has been deleted!")
+	cost.FromState(state).Stop(cost.Storage, config.DiskName)
 
 	return
 }
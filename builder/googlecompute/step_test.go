@@ -2,12 +2,27 @@ package googlecompute
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
 
+func TestMain(m *testing.M) {
+	// The steps track the resources they create: keep the records of the
+	// tests out of the cleanup directory of the user.
+	dir, err := ioutil.TempDir("", "packer-cleanup")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("PACKER_CLEANUP_DIR", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func testState(t *testing.T) multistep.StateBag {
 	state := new(multistep.BasicStateBag)
 	state.Put("config", testConfigStruct(t))
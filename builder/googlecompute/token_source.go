@@ -0,0 +1,56 @@
+package googlecompute
+
+import (
+	"context"
+	"log"
+
+	"github.com/hashicorp/packer/common/credentialhelper"
+	commonhelper "github.com/hashicorp/packer/helper/common"
+	"golang.org/x/oauth2"
+)
+
+// credentialHelperTokenSource is a TokenSource getting the tokens from a
+// credential helper, which is run again when they expire.
+type credentialHelperTokenSource struct {
+	helper *credentialhelper.Helper
+}
+
+func (ts credentialHelperTokenSource) Token() (*oauth2.Token, error) {
+	c, err := ts.helper.Get(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	token := &oauth2.Token{AccessToken: c.Token}
+	if c.Expiration != nil {
+		token.Expiry = *c.Expiration
+	}
+	return token, nil
+}
+
+// tokenSource returns the source of the tokens of the credential helper or
+// of the workload identity provider, nil when the builder authenticates
+// otherwise.
+func (c *Config) tokenSource() (oauth2.TokenSource, error) {
+	switch {
+	case len(c.CredentialHelper) > 0:
+		log.Printf("[INFO] Requesting Google token via credential_helper...")
+		helper := credentialhelper.New(c.CredentialHelper, credentialhelper.ProviderGCP)
+		helper.Request.Scopes = DriverScopes
+		return credentialHelperTokenSource{helper}, nil
+
+	case c.WorkloadIdentityProvider != "":
+		log.Printf("[INFO] Requesting Google token via workload_identity_provider...")
+		tokens, err := c.OIDC.TokenSource(workloadIdentityAudience(c.WorkloadIdentityProvider))
+		if err != nil {
+			return nil, err
+		}
+		return &workloadIdentityTokenSource{
+			provider:       c.WorkloadIdentityProvider,
+			serviceAccount: c.ImpersonateServiceAccount,
+			scopes:         DriverScopes,
+			tokens:         tokens,
+			client:         commonhelper.HttpClient(),
+		}, nil
+	}
+	return nil, nil
+}
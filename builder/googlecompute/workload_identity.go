@@ -0,0 +1,128 @@
+package googlecompute
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common/oidc"
+	"golang.org/x/oauth2"
+)
+
+// The endpoints the OIDC tokens are exchanged at, variables for the tests.
+var (
+	stsTokenURL          = "https://sts.googleapis.com/v1/token"
+	iamCredentialsURLFmt = "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/%s:generateAccessToken"
+)
+
+// workloadIdentityProviderName returns the name of the workload identity
+// provider, like
+// projects/123/locations/global/workloadIdentityPools/pool/providers/provider.
+func workloadIdentityProviderName(provider string) string {
+	provider = strings.TrimPrefix(provider, "https:")
+	return strings.TrimPrefix(provider, "//iam.googleapis.com/")
+}
+
+// workloadIdentityAudience returns the default audience of the OIDC tokens
+// exchanged with the workload identity provider.
+func workloadIdentityAudience(provider string) string {
+	return "https://iam.googleapis.com/" + workloadIdentityProviderName(provider)
+}
+
+// workloadIdentityTokenSource is a TokenSource exchanging the OIDC tokens of
+// the CI job for the tokens of a workload identity pool, and then for the
+// tokens of the service account it impersonates, if any. The OIDC tokens are
+// exchanged again when the tokens expire.
+type workloadIdentityTokenSource struct {
+	provider       string
+	serviceAccount string
+	scopes         []string
+	tokens         *oidc.TokenSource
+	client         *http.Client
+}
+
+func (ts *workloadIdentityTokenSource) Token() (*oauth2.Token, error) {
+	ctx := context.TODO()
+	idToken, err := ts.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"audience":             {"//iam.googleapis.com/" + workloadIdentityProviderName(ts.provider)},
+		"scope":                {"https://www.googleapis.com/auth/cloud-platform"},
+		"requested_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
+		"subject_token":        {idToken},
+		"subject_token_type":   {"urn:ietf:params:oauth:token-type:jwt"},
+	}
+	req, err := http.NewRequest("POST", stsTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var federated struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := ts.do(req.WithContext(ctx), &federated); err != nil {
+		return nil, fmt.Errorf("Error exchanging the OIDC token of the %s job with %s: %s",
+			ts.tokens.Provider, ts.provider, err)
+	}
+	token := &oauth2.Token{
+		AccessToken: federated.AccessToken,
+		Expiry:      time.Now().Add(time.Duration(federated.ExpiresIn) * time.Second),
+	}
+	if ts.serviceAccount == "" {
+		return token, nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"scope": ts.scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err = http.NewRequest("POST", fmt.Sprintf(iamCredentialsURLFmt, ts.serviceAccount), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	var impersonated struct {
+		AccessToken string    `json:"accessToken"`
+		ExpireTime  time.Time `json:"expireTime"`
+	}
+	if err := ts.do(req.WithContext(ctx), &impersonated); err != nil {
+		return nil, fmt.Errorf("Error impersonating service account %s: %s", ts.serviceAccount, err)
+	}
+	return &oauth2.Token{
+		AccessToken: impersonated.AccessToken,
+		Expiry:      impersonated.ExpireTime,
+	}, nil
+}
+
+// do sends the request and decodes the JSON response into v.
+func (ts *workloadIdentityTokenSource) do(req *http.Request, v interface{}) error {
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, body)
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,91 @@
+package googlecompute
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/common/oidc"
+)
+
+func TestWorkloadIdentityTokenSource(t *testing.T) {
+	provider := "projects/123/locations/global/workloadIdentityPools/pool/providers/github"
+	expire := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if r.Form.Get("subject_token") != "oidc-token" ||
+			r.Form.Get("audience") != "//iam.googleapis.com/"+provider {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"access_token": "federated-token", "expires_in": 3600}`)
+	})
+	mux.HandleFunc("/v1/projects/-/serviceAccounts/packer@project.iam.gserviceaccount.com:generateAccessToken",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer federated-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"accessToken": "service-account-token",
+				"expireTime":  expire,
+			})
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	defer func(sts, iam string) {
+		stsTokenURL, iamCredentialsURLFmt = sts, iam
+	}(stsTokenURL, iamCredentialsURLFmt)
+	stsTokenURL = server.URL + "/v1/token"
+	iamCredentialsURLFmt = server.URL + "/v1/projects/-/serviceAccounts/%s:generateAccessToken"
+
+	os.Setenv("PACKER_TEST_OIDC_TOKEN", "oidc-token")
+	defer os.Unsetenv("PACKER_TEST_OIDC_TOKEN")
+
+	ts := &workloadIdentityTokenSource{
+		provider: "//iam.googleapis.com/" + provider,
+		scopes:   DriverScopes,
+		tokens: &oidc.TokenSource{
+			Provider: oidc.ProviderGitLab,
+			TokenEnv: "PACKER_TEST_OIDC_TOKEN",
+		},
+		client: server.Client(),
+	}
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if token.AccessToken != "federated-token" || token.Expiry.Before(time.Now()) {
+		t.Fatalf("bad token: %#v", token)
+	}
+
+	ts.serviceAccount = "packer@project.iam.gserviceaccount.com"
+	token, err = ts.Token()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if token.AccessToken != "service-account-token" || !token.Expiry.Equal(expire) {
+		t.Fatalf("bad token: %#v", token)
+	}
+}
+
+func TestWorkloadIdentityAudience(t *testing.T) {
+	expected := "https://iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/github"
+	for _, provider := range []string{
+		"projects/123/locations/global/workloadIdentityPools/pool/providers/github",
+		"//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/github",
+	} {
+		if got := workloadIdentityAudience(provider); got != expected {
+			t.Fatalf("%s: bad audience: %s", provider, got)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -34,6 +35,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, nil, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"temporary server and SSH key", "snapshot"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	opts := []hcloud.ClientOption{
 		hcloud.WithToken(b.config.HCloudToken),
@@ -61,9 +66,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Host:      getServerIP,
 			SSHConfig: b.config.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.Guest,
 		},
 		&stepShutdownServer{},
 		&stepCreateSnapshot{},
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -22,6 +23,7 @@ import (
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 	Comm                communicator.Config `mapstructure:",squash"`
+	Guest               guest.Config        `mapstructure:",squash"`
 
 	HCloudToken string `mapstructure:"token"`
 	Endpoint    string `mapstructure:"endpoint"`
@@ -99,6 +101,9 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
 		errs = packer.MultiErrorAppend(errs, es...)
 	}
+	if es := c.Guest.Prepare(&c.Comm); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
 	if c.HCloudToken == "" {
 		// Required configurations that will display errors if not set
 		errs = packer.MultiErrorAppend(
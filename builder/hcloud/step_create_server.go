@@ -148,7 +148,7 @@ func (s *stepCreateServer) Cleanup(state multistep.StateBag) {
 
 	// Destroy the server we just created
 	ui.Say("Destroying server...")
-	_, err := client.Server.Delete(context.TODO(), &hcloud.Server{ID: s.serverId})
+	_, err := client.Server.Delete(multistep.CleanupContext(state), &hcloud.Server{ID: s.serverId})
 	if err != nil {
 		ui.Error(fmt.Sprintf(
 			"Error destroying server. Please destroy it manually: %s", err))
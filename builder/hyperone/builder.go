@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -73,6 +74,14 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Host:      getPublicIP,
 			SSHConfig: b.config.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
 	}
 
 	if b.config.ChrootDisk {
@@ -92,6 +101,20 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	} else {
 		steps = append(steps,
 			&common.StepProvision{},
+			&guest.StepRotateCredentials{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepCleanup{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepLinuxGeneralize{
+				Config: &b.config.Guest,
+			},
+			&guest.StepSysprep{
+				Config: &b.config.Guest,
+			},
 			&stepStopVM{},
 			&stepCreateImage{},
 		)
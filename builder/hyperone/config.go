@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/json"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/hcl2template"
@@ -36,6 +37,7 @@ const (
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 	Comm                communicator.Config `mapstructure:",squash"`
+	Guest               guest.Config        `mapstructure:",squash"`
 	// Custom API endpoint URL, compatible with HyperOne.
 	// It can also be specified via environment variable HYPERONE_API_URL.
 	APIURL string `mapstructure:"api_url" required:"false"`
@@ -273,6 +275,9 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
 		errs = packer.MultiErrorAppend(errs, es...)
 	}
+	if es := c.Guest.Prepare(&c.Comm); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
 
 	if c.Token == "" {
 		errs = packer.MultiErrorAppend(errs, errors.New("token is required"))
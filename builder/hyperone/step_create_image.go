@@ -57,7 +57,7 @@ func (s *stepCreateImage) Cleanup(state multistep.StateBag) {
 	client := state.Get("client").(*openapi.APIClient)
 	ui := state.Get("ui").(packer.Ui)
 
-	_, err := client.ImageApi.ImageDelete(context.TODO(), s.imageID)
+	_, err := client.ImageApi.ImageDelete(multistep.CleanupContext(state), s.imageID)
 	if err != nil {
 		ui.Error(fmt.Sprintf("error deleting image '%s' - consider deleting it manually: %s",
 			s.imageID, formatOpenAPIError(err)))
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/hashicorp/packer/common"
@@ -35,6 +36,14 @@ const (
 	DefaultPassword = ""
 )
 
+var secureBootTemplates = map[string]struct{}{
+	"MicrosoftWindows":                  {},
+	"MicrosoftUEFICertificateAuthority": {},
+	"OpenSourceShieldedVM":              {},
+}
+
+var guidRegex = regexp.MustCompile(`^\{?[0-9a-fA-F]{8}-([0-9a-fA-F]{4}-){3}[0-9a-fA-F]{12}\}?$`)
+
 type CommonConfig struct {
 	common.FloppyConfig `mapstructure:",squash"`
 	// The block size of the VHD to be created.
@@ -106,10 +115,29 @@ type CommonConfig struct {
 	// below for additional settings.
 	EnableSecureBoot bool `mapstructure:"enable_secure_boot" required:"false"`
 	// The secure boot template to be
-	// configured. Valid values are "MicrosoftWindows" (Windows) or
-	// "MicrosoftUEFICertificateAuthority" (Linux). This only takes effect if
-	// enable_secure_boot is set to "true". This defaults to "MicrosoftWindows".
+	// configured. Valid values are "MicrosoftWindows" (Windows),
+	// "MicrosoftUEFICertificateAuthority" (Linux), "OpenSourceShieldedVM"
+	// (shielded Linux) or the GUID of a custom template, as accepted by
+	// `Set-VMFirmware -SecureBootTemplateId`. This only takes effect if
+	// enable_secure_boot is set to "true". This defaults to
+	// "MicrosoftWindows".
 	SecureBootTemplate string `mapstructure:"secure_boot_template" required:"false"`
+	// If true, add a virtual TPM to the
+	// virtual machine, as required by Windows 11 or by BitLocker with a TPM
+	// protector. This is only supported by Generation 2 virtual machines.
+	// This defaults to false. See key_protector_guardian below for the key
+	// protector of the TPM.
+	EnableTPM bool `mapstructure:"enable_tpm" required:"false"`
+	// The name of the Host Guardian
+	// Service guardian, as listed by `Get-HgsGuardian`, that owns the key
+	// protector of the virtual TPM. By default a new local key protector
+	// owned by the "UntrustedGuardian" of the build host is used, so the
+	// exported virtual machine can only be started on that host. To run the
+	// image on other hosts, create a guardian with `New-HgsGuardian` and
+	// import its certificates on those hosts. If the virtual machine to
+	// clone already has a TPM enabled, its key protector is kept. This
+	// requires enable_tpm to be set to "true".
+	KeyProtectorGuardian string `mapstructure:"key_protector_guardian" required:"false"`
 	// If true enable
 	// virtualization extensions for the virtual machine. This defaults to
 	// false. For nested virtualization you need to enable MAC spoofing,
@@ -205,6 +233,18 @@ func (c *CommonConfig) Prepare(ctx *interpolate.Context, pc *common.PackerConfig
 		}
 	}
 
+	if c.SecureBootTemplate != "" {
+		if _, ok := secureBootTemplates[c.SecureBootTemplate]; !ok && !guidRegex.MatchString(c.SecureBootTemplate) {
+			err := fmt.Errorf("secure_boot_template must be one of MicrosoftWindows, " +
+				"MicrosoftUEFICertificateAuthority, OpenSourceShieldedVM or the GUID of a custom template")
+			errs = append(errs, err)
+		}
+	}
+
+	if c.KeyProtectorGuardian != "" && !c.EnableTPM {
+		errs = append(errs, fmt.Errorf("key_protector_guardian can only be set when enable_tpm is true"))
+	}
+
 	if len(c.AdditionalDiskSize) > 64 {
 		errs = append(errs, fmt.Errorf("VM's currently support a maximum of 64 additional SCSI attached disks."))
 	}
@@ -93,6 +93,8 @@ type Driver interface {
 
 	SetVirtualMachineSecureBoot(string, bool, string) error
 
+	EnableVirtualMachineTPM(string, string) error
+
 	SetVirtualMachineVirtualizationExtensions(string, bool) error
 
 	EnableVirtualMachineIntegrationService(string, string) error
@@ -180,6 +180,11 @@ type DriverMock struct {
 	SetVirtualMachineSecureBoot_Enable       bool
 	SetVirtualMachineSecureBoot_Err          error
 
+	EnableVirtualMachineTPM_Called       bool
+	EnableVirtualMachineTPM_VmName       string
+	EnableVirtualMachineTPM_GuardianName string
+	EnableVirtualMachineTPM_Err          error
+
 	SetVirtualMachineVirtualizationExtensions_Called bool
 	SetVirtualMachineVirtualizationExtensions_VmName string
 	SetVirtualMachineVirtualizationExtensions_Enable bool
@@ -512,6 +517,13 @@ func (d *DriverMock) SetVirtualMachineSecureBoot(vmName string, enable bool, tem
 	return d.SetVirtualMachineSecureBoot_Err
 }
 
+func (d *DriverMock) EnableVirtualMachineTPM(vmName string, guardianName string) error {
+	d.EnableVirtualMachineTPM_Called = true
+	d.EnableVirtualMachineTPM_VmName = vmName
+	d.EnableVirtualMachineTPM_GuardianName = guardianName
+	return d.EnableVirtualMachineTPM_Err
+}
+
 func (d *DriverMock) SetVirtualMachineVirtualizationExtensions(vmName string, enable bool) error {
 	d.SetVirtualMachineVirtualizationExtensions_Called = true
 	d.SetVirtualMachineVirtualizationExtensions_VmName = vmName
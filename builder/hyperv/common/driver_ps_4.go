@@ -224,6 +224,10 @@ func (d *HypervPS4Driver) SetVirtualMachineSecureBoot(vmName string, enable bool
 	return hyperv.SetVirtualMachineSecureBoot(vmName, enable, templateName)
 }
 
+func (d *HypervPS4Driver) EnableVirtualMachineTPM(vmName string, guardianName string) error {
+	return hyperv.EnableVirtualMachineTPM(vmName, guardianName)
+}
+
 func (d *HypervPS4Driver) SetVirtualMachineVirtualizationExtensions(vmName string, enable bool) error {
 	return hyperv.SetVirtualMachineVirtualizationExtensions(vmName, enable)
 }
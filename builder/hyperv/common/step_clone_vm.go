@@ -29,6 +29,8 @@ type StepCloneVM struct {
 	EnableDynamicMemory            bool
 	EnableSecureBoot               bool
 	SecureBootTemplate             string
+	EnableTPM                      bool
+	KeyProtectorGuardian           string
 	EnableVirtualizationExtensions bool
 	MacAddress                     string
 	KeepRegistered                 bool
@@ -114,6 +116,21 @@ func (s *StepCloneVM) Run(ctx context.Context, state multistep.StateBag) multist
 			ui.Error(err.Error())
 			return multistep.ActionHalt
 		}
+
+		if s.EnableTPM {
+			err = driver.EnableVirtualMachineTPM(s.VMName, s.KeyProtectorGuardian)
+			if err != nil {
+				err := fmt.Errorf("Error enabling TPM: %s", err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+		}
+	} else if s.EnableTPM {
+		err := fmt.Errorf("Error enabling TPM: only Generation 2 virtual machines support a TPM")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
 	}
 
 	if s.EnableVirtualizationExtensions {
@@ -29,6 +29,8 @@ type StepCreateVM struct {
 	EnableDynamicMemory            bool
 	EnableSecureBoot               bool
 	SecureBootTemplate             string
+	EnableTPM                      bool
+	KeyProtectorGuardian           string
 	EnableVirtualizationExtensions bool
 	AdditionalDiskSize             []uint
 	DifferencingDisk               bool
@@ -127,6 +129,16 @@ func (s *StepCreateVM) Run(ctx context.Context, state multistep.StateBag) multis
 			ui.Error(err.Error())
 			return multistep.ActionHalt
 		}
+
+		if s.EnableTPM {
+			err = driver.EnableVirtualMachineTPM(s.VMName, s.KeyProtectorGuardian)
+			if err != nil {
+				err := fmt.Errorf("Error enabling TPM: %s", err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+		}
 	}
 
 	if s.EnableVirtualizationExtensions {
@@ -56,3 +56,33 @@ func TestStepCreateVM_CheckVMNameErr(t *testing.T) {
 		t.Fatal("Should have called CheckVMName")
 	}
 }
+
+func TestStepCreateVM_EnableTPM(t *testing.T) {
+	state := testState(t)
+	step := new(StepCreateVM)
+
+	step.VMName = "test-VM-Name"
+	step.Generation = 2
+	step.EnableTPM = true
+	step.KeyProtectorGuardian = "packer"
+	driver := state.Get("driver").(*DriverMock)
+
+	// Test the run
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("Bad action: %v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("Should NOT have error")
+	}
+
+	// Test the driver
+	if !driver.EnableVirtualMachineTPM_Called {
+		t.Fatal("Should have called EnableVirtualMachineTPM")
+	}
+	if driver.EnableVirtualMachineTPM_VmName != "test-VM-Name" {
+		t.Fatalf("Bad vm name: %s", driver.EnableVirtualMachineTPM_VmName)
+	}
+	if driver.EnableVirtualMachineTPM_GuardianName != "packer" {
+		t.Fatalf("Bad guardian name: %s", driver.EnableVirtualMachineTPM_GuardianName)
+	}
+}
@@ -25,6 +25,7 @@ type StepTypeBootCommand struct {
 	SwitchName    string
 	Ctx           interpolate.Context
 	GroupInterval time.Duration
+	Keymap        string
 }
 
 func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -55,7 +56,13 @@ func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag)
 		scanCodesToSendString := strings.Join(codes, " ")
 		return driver.TypeScanCodes(vmName, scanCodesToSendString)
 	}
-	d := bootcommand.NewPCXTDriver(sendCodes, -1, s.GroupInterval)
+	d, err := bootcommand.NewKeymapDriver(bootcommand.NewPCXTDriver(sendCodes, -1, s.GroupInterval), s.Keymap)
+	if err != nil {
+		err := fmt.Errorf("Error preparing boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
 
 	ui.Say("Typing the boot command...")
 	command, err := interpolate.Render(s.BootCommand, &s.Ctx)
@@ -655,3 +655,80 @@ func TestBuilderPrepare_UseLegacyNetworkAdapter(t *testing.T) {
 		t.Fatal("should have error")
 	}
 }
+
+func TestBuilderPrepare_SecureBootTemplate(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["generation"] = 2
+	config["enable_secure_boot"] = true
+
+	for _, template := range []string{
+		"MicrosoftUEFICertificateAuthority",
+		"OpenSourceShieldedVM",
+		"1734c6e8-3154-4dda-ba5f-a874cc483422",
+		"{1734c6e8-3154-4dda-ba5f-a874cc483422}",
+	} {
+		config["secure_boot_template"] = template
+
+		b = Builder{}
+		_, warns, err := b.Prepare(config)
+		if len(warns) > 0 {
+			t.Fatalf("bad: %#v", warns)
+		}
+		if err != nil {
+			t.Fatalf("should not have error for %s: %s", template, err)
+		}
+	}
+
+	config["secure_boot_template"] = "Microsoft"
+
+	b = Builder{}
+	_, warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_EnableTPM(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// should not be allowed for gen 1
+	config["enable_tpm"] = true
+
+	b = Builder{}
+	_, warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["generation"] = 2
+	config["key_protector_guardian"] = "packer"
+
+	b = Builder{}
+	_, warns, err = b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// a guardian requires a TPM
+	config["enable_tpm"] = false
+
+	b = Builder{}
+	_, warns, err = b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
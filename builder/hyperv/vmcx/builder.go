@@ -14,6 +14,7 @@ import (
 	hypervcommon "github.com/hashicorp/packer/builder/hyperv/common"
 	"github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/common/guest"
 	powershell "github.com/hashicorp/packer/common/powershell"
 	"github.com/hashicorp/packer/common/shutdowncommand"
 	"github.com/hashicorp/packer/helper/communicator"
@@ -212,6 +213,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"local virtual machine", "output directory"}
+}
+
 // Run executes a Packer build and returns a packer.Artifact representing
 // a Hyperv appliance.
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
@@ -233,8 +238,8 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			TempPath: b.config.TempPath,
 		},
 		&common.StepOutputDir{
-			Force: b.config.PackerForce,
-			Path:  b.config.OutputDir,
+			OnConflict: b.config.OnConflict(),
+			Path:       b.config.OutputDir,
 		},
 		&common.StepDownload{
 			Checksum:    b.config.ISOChecksum,
@@ -272,6 +277,8 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			EnableDynamicMemory:            b.config.EnableDynamicMemory,
 			EnableSecureBoot:               b.config.EnableSecureBoot,
 			SecureBootTemplate:             b.config.SecureBootTemplate,
+			EnableTPM:                      b.config.EnableTPM,
+			KeyProtectorGuardian:           b.config.KeyProtectorGuardian,
 			EnableVirtualizationExtensions: b.config.EnableVirtualizationExtensions,
 			MacAddress:                     b.config.MacAddress,
 			KeepRegistered:                 b.config.KeepRegistered,
@@ -324,6 +331,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			SwitchName:    b.config.SwitchName,
 			Ctx:           b.config.ctx,
 			GroupInterval: b.config.BootConfig.BootGroupInterval,
+			Keymap:        b.config.BootConfig.BootKeymap,
 		},
 
 		// configure the communicator ssh, winrm
@@ -332,18 +340,38 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Host:      hypervcommon.CommHost(b.config.SSHConfig.Comm.SSHHost),
 			SSHConfig: b.config.SSHConfig.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
 
 		// provision requires communicator to be setup
 		&common.StepProvision{},
 
 		// Remove ephemeral SSH keys, if using
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.SSHConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.SSHConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.SSHConfig.Guest,
 		},
 
 		&hypervcommon.StepShutdown{
 			Command: b.config.ShutdownCommand,
 			Timeout: b.config.ShutdownTimeout,
+			Force:   b.config.ForceShutdown,
 		},
 
 		// wait for the vm to be powered off
@@ -376,6 +404,11 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	b.runner = common.NewRunner(steps, b.config.PackerConfig, ui)
 	b.runner.Run(ctx, state)
 
+	// The artifact already exists and the build was skipped.
+	if common.Skipped(state) {
+		return nil, nil
+	}
+
 	// Report any errors.
 	if rawErr, ok := state.GetOk("error"); ok {
 		return nil, rawErr.(error)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -68,8 +69,30 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			SSHConfig: b.config.JDCloudInstanceSpecConfig.Comm.SSHConfigFunc(),
 			Host:      instanceHost,
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.JDCloudInstanceSpecConfig.Guest,
+			Comm:   &b.config.JDCloudInstanceSpecConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.JDCloudInstanceSpecConfig.Guest,
+			Comm:   &b.config.JDCloudInstanceSpecConfig.Comm,
+		},
 
 		&common.StepProvision{},
+		&guest.StepRotateCredentials{
+			Config: &b.config.JDCloudInstanceSpecConfig.Guest,
+			Comm:   &b.config.JDCloudInstanceSpecConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.JDCloudInstanceSpecConfig.Guest,
+			Comm:   &b.config.JDCloudInstanceSpecConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.JDCloudInstanceSpecConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.JDCloudInstanceSpecConfig.Guest,
+		},
 
 		&stepStopJDCloudInstance{
 			InstanceSpecConfig: &b.config.JDCloudInstanceSpecConfig,
@@ -3,6 +3,7 @@ package jdcloud
 import (
 	"fmt"
 
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/template/interpolate"
 )
@@ -14,6 +15,7 @@ type JDCloudInstanceSpecConfig struct {
 	ImageName       string              `mapstructure:"image_name"`
 	SubnetId        string              `mapstructure:"subnet_id"`
 	Comm            communicator.Config `mapstructure:",squash"`
+	Guest           guest.Config        `mapstructure:",squash"`
 	InstanceId      string
 	ArtifactId      string
 	PublicIpAddress string
@@ -23,6 +25,7 @@ type JDCloudInstanceSpecConfig struct {
 func (jd *JDCloudInstanceSpecConfig) Prepare(ctx *interpolate.Context) []error {
 
 	errs := jd.Comm.Prepare(ctx)
+	errs = append(errs, jd.Guest.Prepare(&jd.Comm)...)
 
 	if jd == nil {
 		return append(errs, fmt.Errorf("[PRE-FLIGHT] Configuration appears to be empty"))
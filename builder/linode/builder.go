@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/packer/common"
 	"github.com/linode/linodego"
 
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -36,10 +37,14 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, nil, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"temporary linode", "image"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (ret packer.Artifact, err error) {
 	ui.Say("Running builder ...")
 
-	client := newLinodeClient(b.config.PersonalAccessToken)
+	client := newLinodeClient(b.config.PersonalAccessToken, &b.config.RateLimitConfig)
 
 	if err != nil {
 		ui.Error(err.Error())
@@ -62,9 +67,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (ret
 			Host:      commHost(b.config.Comm.Host()),
 			SSHConfig: b.config.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.Guest,
 		},
 		&stepShutdownLinode{client},
 		&stepCreateImage{client},
@@ -11,6 +11,8 @@ import (
 	"regexp"
 
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
+	"github.com/hashicorp/packer/common/ratelimit"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
@@ -21,9 +23,12 @@ type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 	ctx                 interpolate.Context
 	Comm                communicator.Config `mapstructure:",squash"`
+	Guest               guest.Config        `mapstructure:",squash"`
 
 	PersonalAccessToken string `mapstructure:"linode_token"`
 
+	RateLimitConfig ratelimit.Config `mapstructure:",squash"`
+
 	Region       string   `mapstructure:"region"`
 	InstanceType string   `mapstructure:"instance_type"`
 	Label        string   `mapstructure:"instance_label"`
@@ -62,6 +67,10 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 
 	var errs *packer.MultiError
 
+	if es := c.RateLimitConfig.Prepare(); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
+
 	// Defaults
 
 	if c.PersonalAccessToken == "" {
@@ -97,6 +106,9 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
 		errs = packer.MultiErrorAppend(errs, es...)
 	}
+	if es := c.Guest.Prepare(&c.Comm); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
 
 	c.Comm.SSHPassword = c.RootPass
 
@@ -4,20 +4,23 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/hashicorp/packer/common/ratelimit"
+	commonhelper "github.com/hashicorp/packer/helper/common"
 	"github.com/hashicorp/packer/version"
 	"github.com/linode/linodego"
 	"golang.org/x/oauth2"
 )
 
-func newLinodeClient(pat string) linodego.Client {
+func newLinodeClient(pat string, rateLimit *ratelimit.Config) linodego.Client {
 	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: pat})
 
 	oauthTransport := &oauth2.Transport{
 		Source: tokenSource,
+		Base:   commonhelper.HttpTransport(),
 	}
-	oauth2Client := &http.Client{
+	oauth2Client := rateLimit.Client("linode", &http.Client{
 		Transport: oauthTransport,
-	}
+	})
 
 	client := linodego.NewClient(oauth2Client)
 
@@ -91,7 +91,7 @@ func (s *stepCreateLinode) Cleanup(state multistep.StateBag) {
 
 	ui := state.Get("ui").(packer.Ui)
 
-	if err := s.client.DeleteInstance(context.Background(), instance.(*linodego.Instance).ID); err != nil {
+	if err := s.client.DeleteInstance(multistep.CleanupContext(state), instance.(*linodego.Instance).ID); err != nil {
 		ui.Error("Error cleaning up Linode: " + err.Error())
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/NaverCloudPlatform/ncloud-sdk-go-v2/services/server"
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -65,9 +66,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 				},
 				SSHConfig: b.config.Comm.SSHConfigFunc(),
 			},
+			&guest.StepCheckClock{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepEnvironment{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
 			&common.StepProvision{},
-			&common.StepCleanupTempKeys{
-				Comm: &b.config.Comm,
+			&guest.StepRotateCredentials{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepCleanup{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepLinuxGeneralize{
+				Config: &b.config.Guest,
+			},
+			&guest.StepSysprep{
+				Config: &b.config.Guest,
 			},
 			NewStepStopServerInstance(conn, ui),
 			NewStepCreateServerImage(conn, ui, &b.config),
@@ -94,7 +114,29 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 					}, nil
 				},
 			},
+			&guest.StepCheckClock{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepEnvironment{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
 			&common.StepProvision{},
+			&guest.StepRotateCredentials{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepCleanup{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepLinuxGeneralize{
+				Config: &b.config.Guest,
+			},
+			&guest.StepSysprep{
+				Config: &b.config.Guest,
+			},
 			NewStepStopServerInstance(conn, ui),
 			NewStepCreateServerImage(conn, ui, &b.config),
 			NewStepDeleteBlockStorageInstance(conn, ui, &b.config),
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
@@ -16,6 +17,7 @@ type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 
 	CommConfig communicator.Config `mapstructure:",squash"`
+	Guest      guest.Config        `mapstructure:",squash"`
 }
 
 func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
@@ -32,6 +34,9 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	if es := c.CommConfig.Prepare(nil); len(es) > 0 {
 		errs = packer.MultiErrorAppend(errs, es...)
 	}
+	if es := c.Guest.Prepare(&c.CommConfig); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
 
 	if c.CommConfig.Type != "none" {
 		if c.CommConfig.Host() == "" {
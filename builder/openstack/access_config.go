@@ -13,6 +13,8 @@ import (
 	"github.com/gophercloud/gophercloud/openstack"
 	"github.com/gophercloud/utils/openstack/clientconfig"
 	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/packer/common/ratelimit"
+	commonhelper "github.com/hashicorp/packer/helper/common"
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
@@ -85,6 +87,9 @@ type AccessConfig struct {
 	// for more information about `clouds.yaml` files. If omitted, the
 	// `OS_CLOUD` environment variable is used.
 	Cloud string `mapstructure:"cloud" required:"false"`
+	// The rate limit, retries and circuit breaker of the OpenStack API
+	// requests.
+	RateLimitConfig ratelimit.Config `mapstructure:",squash"`
 
 	osClient *gophercloud.ProviderClient
 }
@@ -97,6 +102,10 @@ func (c *AccessConfig) Prepare(ctx *interpolate.Context) []error {
 		return []error{fmt.Errorf("Invalid endpoint type provided")}
 	}
 
+	if errs := c.RateLimitConfig.Prepare(); len(errs) > 0 {
+		return errs
+	}
+
 	// Legacy RackSpace stuff. We're keeping this around to keep things BC.
 	if c.Password == "" {
 		c.Password = os.Getenv("SDK_PASSWORD")
@@ -224,7 +233,10 @@ func (c *AccessConfig) Prepare(ctx *interpolate.Context) []error {
 
 	transport := cleanhttp.DefaultTransport()
 	transport.TLSClientConfig = tls_config
-	client.HTTPClient.Transport = transport
+	if err := commonhelper.ConfigureTransport(transport); err != nil {
+		return []error{err}
+	}
+	client.HTTPClient.Transport = c.RateLimitConfig.Transport("openstack", transport)
 
 	// Auth
 	err = openstack.Authenticate(client, *ao)
@@ -11,6 +11,8 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/firewall"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -70,6 +72,14 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, nil, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	resources := []string{"temporary server, key pair and floating IP"}
+	if b.config.FirewallConfig.TemporaryFirewallRule {
+		resources = append(resources, "temporary firewall rule")
+	}
+	return append(resources, "image")
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	computeClient, err := b.config.computeV2Client()
 	if err != nil {
@@ -86,6 +96,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	state.Put("config", &b.config)
 	state.Put("hook", hook)
 	state.Put("ui", ui)
+	state.Put("winrmPasswordSource", winrmPasswordSource)
 
 	// Build the steps
 	steps := []multistep.Step{
@@ -115,6 +126,12 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			VolumeType:             b.config.VolumeType,
 			VolumeAvailabilityZone: b.config.VolumeAvailabilityZone,
 		},
+		&firewall.StepTemporaryRule{
+			Config: &b.config.FirewallConfig,
+			Comm:   &b.config.RunConfig.Comm,
+			Create: createSecurityGroup,
+			Delete: deleteSecurityGroup,
+		},
 		&StepRunSourceServer{
 			Name:                  b.config.InstanceName,
 			SecurityGroups:        b.config.SecurityGroups,
@@ -126,7 +143,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			UseBlockStorageVolume: b.config.UseBlockStorageVolume,
 			ForceDelete:           b.config.ForceDelete,
 		},
-		&StepGetPassword{
+		&communicator.StepGetWinRMPassword{
 			Debug: b.config.PackerDebug,
 			Comm:  &b.config.RunConfig.Comm,
 		},
@@ -148,9 +165,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 				b.config.SSHIPVersion),
 			SSHConfig: b.config.RunConfig.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.RunConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.RunConfig.Guest,
+			Comm:   &b.config.RunConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.RunConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.RunConfig.Guest,
 		},
 		&StepStopServer{},
 		&StepDetachVolume{
@@ -160,6 +196,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			UseBlockStorageVolume: b.config.UseBlockStorageVolume,
 		},
 		&stepUpdateImageTags{},
+		&stepUpdateImageProperties{},
 		&stepUpdateImageVisibility{},
 		&stepAddImageMembers{},
 		&stepUpdateImageMinDisk{},
@@ -28,3 +28,15 @@ func TestBuilder_Prepare_BadType(t *testing.T) {
 		t.Fatalf("prepare should fail")
 	}
 }
+
+func TestBuilder_PlannedResources(t *testing.T) {
+	b := &Builder{}
+	if r := b.PlannedResources(); len(r) != 2 {
+		t.Fatalf("bad: %#v", r)
+	}
+
+	b.config.FirewallConfig.TemporaryFirewallRule = true
+	if r := b.PlannedResources(); len(r) != 3 || r[1] != "temporary firewall rule" {
+		t.Fatalf("the temporary firewall rule should be planned: %#v", r)
+	}
+}
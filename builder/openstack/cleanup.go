@@ -0,0 +1,106 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/keypairs"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/hashicorp/packer/common/cleanup"
+)
+
+// CleanupProvider is the provider name of the resources the openstack
+// builder tracks in the cleanup registry.
+const CleanupProvider = "openstack"
+
+// Types of the resources the openstack builder tracks in the cleanup
+// registry.
+const (
+	CleanupServer     = "server"
+	CleanupKeyPair    = "keypair"
+	CleanupVolume     = "volume"
+	CleanupFloatingIP = "floating-ip"
+)
+
+func trackResource(region, typ, id string) {
+	cleanup.Track(cleanup.Resource{
+		Provider: CleanupProvider,
+		Type:     typ,
+		ID:       id,
+		Region:   region,
+	})
+}
+
+func untrackResource(typ, id string) {
+	cleanup.Untrack(CleanupProvider, typ, id)
+}
+
+// SweepResource removes a resource left behind by the openstack builder. It
+// authenticates with the OS_* environment variables, like OS_AUTH_URL and
+// OS_USERNAME.
+func SweepResource(ctx context.Context, r cleanup.Resource) error {
+	opts, err := openstack.AuthOptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	provider, err := openstack.AuthenticatedClient(opts)
+	if err != nil {
+		return err
+	}
+	provider.Context = ctx
+	eo := gophercloud.EndpointOpts{Region: r.Region}
+
+	switch r.Type {
+	case CleanupServer:
+		client, err := openstack.NewComputeV2(provider, eo)
+		if err != nil {
+			return err
+		}
+		if err := servers.Delete(client, r.ID).ExtractErr(); err != nil {
+			return ignoreNotFound(err)
+		}
+		// The volume created before the server can only be removed once
+		// the server is gone.
+		_, err = WaitForState(&StateChangeConf{
+			Pending: []string{"ACTIVE", "BUILD", "REBUILD", "SUSPENDED", "SHUTOFF", "STOPPED", "ERROR"},
+			Refresh: ServerStateRefreshFunc(client, &servers.Server{ID: r.ID}),
+			Target:  []string{"DELETED"},
+			Context: ctx,
+		})
+		return err
+	case CleanupKeyPair:
+		client, err := openstack.NewComputeV2(provider, eo)
+		if err != nil {
+			return err
+		}
+		return ignoreNotFound(keypairs.Delete(client, r.ID).ExtractErr())
+	case CleanupVolume:
+		client, err := openstack.NewBlockStorageV3(provider, eo)
+		if err != nil {
+			return err
+		}
+		if err := volumes.Delete(client, r.ID, volumes.DeleteOpts{}).ExtractErr(); err != nil {
+			return ignoreNotFound(err)
+		}
+		return WaitForVolumeDeletion(ctx, client, r.ID)
+	case CleanupFloatingIP:
+		client, err := openstack.NewNetworkV2(provider, eo)
+		if err != nil {
+			return err
+		}
+		return ignoreNotFound(floatingips.Delete(client, r.ID).ExtractErr())
+	default:
+		return fmt.Errorf("unknown resource type %q", r.Type)
+	}
+}
+
+func ignoreNotFound(err error) error {
+	if _, ok := err.(gophercloud.ErrDefault404); ok {
+		return nil
+	}
+	return err
+}
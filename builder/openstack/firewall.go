@@ -0,0 +1,64 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/hashicorp/packer/common/firewall"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// createSecurityGroup creates the temporary firewall rule as a security group
+// named after it, for the server to be launched in.
+func createSecurityGroup(_ context.Context, state multistep.StateBag, rule *firewall.Rule) error {
+	config := state.Get("config").(*Config)
+	networkClient, err := config.networkV2Client()
+	if err != nil {
+		return fmt.Errorf("Error initializing network client: %s", err)
+	}
+
+	group, err := groups.Create(networkClient, groups.CreateOpts{
+		Name:        rule.Name,
+		Description: "Temporary group for Packer",
+	}).Extract()
+	if err != nil {
+		return err
+	}
+	rule.ID = group.ID
+
+	for _, cidr := range rule.SourceCidrs {
+		etherType := rules.EtherType4
+		if strings.Contains(cidr, ":") {
+			etherType = rules.EtherType6
+		}
+		_, err := rules.Create(networkClient, rules.CreateOpts{
+			Direction:      rules.DirIngress,
+			EtherType:      etherType,
+			SecGroupID:     group.ID,
+			PortRangeMin:   rule.Port,
+			PortRangeMax:   rule.Port,
+			Protocol:       rules.ProtocolTCP,
+			RemoteIPPrefix: cidr,
+		}).Extract()
+		if err != nil {
+			if err := groups.Delete(networkClient, group.ID).ExtractErr(); err != nil {
+				log.Printf("[WARN] Error deleting security group %s: %s", group.ID, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteSecurityGroup(_ context.Context, state multistep.StateBag, rule *firewall.Rule) error {
+	config := state.Get("config").(*Config)
+	networkClient, err := config.networkV2Client()
+	if err != nil {
+		return fmt.Errorf("Error initializing network client: %s", err)
+	}
+	return groups.Delete(networkClient, rule.ID).ExtractErr()
+}
@@ -33,6 +33,26 @@ type ImageConfig struct {
 	ImageTags []string `mapstructure:"image_tags" required:"false"`
 	// Minimum disk size needed to boot image, in gigabytes.
 	ImageMinDisk int `mapstructure:"image_min_disk" required:"false"`
+	// Glance properties set on the image once it is created. Unlike
+	// `metadata`, which goes through the Compute or the Block Storage service
+	// and may be filtered on the way, these properties are set on the image
+	// itself, whether or not use_blockstorage_volume is true.
+	ImageProperties map[string]string `mapstructure:"image_properties" required:"false"`
+	// Firmware of the instances booted from the image, `bios` or `uefi`. Sets
+	// the `hw_firmware_type` image property.
+	ImageFirmwareType string `mapstructure:"image_firmware_type" required:"false"`
+	// Disk bus of the instances booted from the image, like `virtio` or
+	// `scsi`. Sets the `hw_disk_bus` image property.
+	ImageDiskBus string `mapstructure:"image_disk_bus" required:"false"`
+	// SCSI controller model of the instances booted from the image, like
+	// `virtio-scsi`. Sets the `hw_scsi_model` image property.
+	ImageSCSIModel string `mapstructure:"image_scsi_model" required:"false"`
+	// Network interface model of the instances booted from the image, like
+	// `virtio` or `e1000`. Sets the `hw_vif_model` image property.
+	ImageVIFModel string `mapstructure:"image_vif_model" required:"false"`
+	// Whether the instances booted from the image run the QEMU guest agent.
+	// Sets the `hw_qemu_guest_agent` image property.
+	ImageQemuGuestAgent bool `mapstructure:"image_qemu_guest_agent" required:"false"`
 }
 
 func (c *ImageConfig) Prepare(ctx *interpolate.Context) []error {
@@ -73,6 +93,33 @@ func (c *ImageConfig) Prepare(ctx *interpolate.Context) []error {
 		errs = append(errs, fmt.Errorf("An image min disk size must be greater than or equal to 0"))
 	}
 
+	if c.ImageFirmwareType != "" && c.ImageFirmwareType != "bios" && c.ImageFirmwareType != "uefi" {
+		errs = append(errs, fmt.Errorf("image_firmware_type must be one of bios or uefi"))
+	}
+
+	hwProperties := map[string]string{
+		"hw_firmware_type": c.ImageFirmwareType,
+		"hw_disk_bus":      c.ImageDiskBus,
+		"hw_scsi_model":    c.ImageSCSIModel,
+		"hw_vif_model":     c.ImageVIFModel,
+	}
+	if c.ImageQemuGuestAgent {
+		hwProperties["hw_qemu_guest_agent"] = "yes"
+	}
+	for name, value := range hwProperties {
+		if value == "" {
+			continue
+		}
+		if c.ImageProperties == nil {
+			c.ImageProperties = make(map[string]string)
+		}
+		if v, ok := c.ImageProperties[name]; ok && v != value {
+			errs = append(errs, fmt.Errorf("The %s image property is set to both %q and %q", name, v, value))
+			continue
+		}
+		c.ImageProperties[name] = value
+	}
+
 	if len(errs) > 0 {
 		return errs
 	}
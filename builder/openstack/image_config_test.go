@@ -1,7 +1,10 @@
 package openstack
 
 import (
+	"reflect"
 	"testing"
+
+	imageservice "github.com/gophercloud/gophercloud/openstack/imageservice/v2/images"
 )
 
 func testImageConfig() *ImageConfig {
@@ -21,3 +24,54 @@ func TestImageConfigPrepare_Region(t *testing.T) {
 		t.Fatal("should have error")
 	}
 }
+
+func TestImageConfigPrepare_hwProperties(t *testing.T) {
+	c := testImageConfig()
+	c.ImageProperties = map[string]string{"os_distro": "ubuntu"}
+	c.ImageFirmwareType = "uefi"
+	c.ImageDiskBus = "scsi"
+	c.ImageSCSIModel = "virtio-scsi"
+	c.ImageQemuGuestAgent = true
+	if err := c.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %s", err)
+	}
+
+	expected := map[string]string{
+		"os_distro":           "ubuntu",
+		"hw_firmware_type":    "uefi",
+		"hw_disk_bus":         "scsi",
+		"hw_scsi_model":       "virtio-scsi",
+		"hw_qemu_guest_agent": "yes",
+	}
+	if !reflect.DeepEqual(c.ImageProperties, expected) {
+		t.Fatalf("bad image properties: %#v", c.ImageProperties)
+	}
+
+	c = testImageConfig()
+	c.ImageFirmwareType = "efi"
+	if err := c.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+
+	c = testImageConfig()
+	c.ImageProperties = map[string]string{"hw_vif_model": "e1000"}
+	c.ImageVIFModel = "virtio"
+	if err := c.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestImagePropertiesUpdateOpts(t *testing.T) {
+	opts := imagePropertiesUpdateOpts(map[string]string{
+		"hw_firmware_type": "uefi",
+		"hw_disk_bus":      "scsi",
+	})
+
+	expected := imageservice.UpdateOpts{
+		imageservice.UpdateImageProperty{Op: imageservice.AddOp, Name: "hw_disk_bus", Value: "scsi"},
+		imageservice.UpdateImageProperty{Op: imageservice.AddOp, Name: "hw_firmware_type", Value: "uefi"},
+	}
+	if !reflect.DeepEqual(opts, expected) {
+		t.Fatalf("bad update opts: %#v", opts)
+	}
+}
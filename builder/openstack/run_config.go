@@ -7,6 +7,8 @@ import (
 	"fmt"
 
 	"github.com/gophercloud/gophercloud/openstack/imageservice/v2/images"
+	"github.com/hashicorp/packer/common/firewall"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/template/interpolate"
@@ -15,7 +17,8 @@ import (
 // RunConfig contains configuration for running an instance from a source image
 // and details on how to access that launched image.
 type RunConfig struct {
-	Comm communicator.Config `mapstructure:",squash"`
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
 	// The type of interface to connect via SSH. Values useful for Rackspace
 	// are "public" or "private", and the default behavior is to connect via
 	// whichever is returned first from the OpenStack API.
@@ -111,6 +114,10 @@ type RunConfig struct {
 	ReuseIPs bool `mapstructure:"reuse_ips" required:"false"`
 	// A list of security groups by name to add to this instance.
 	SecurityGroups []string `mapstructure:"security_groups" required:"false"`
+	// The temporary firewall rule of the communicator port, created as a
+	// temporary security group the instance is added to, on top of
+	// `security_groups`.
+	FirewallConfig firewall.Config `mapstructure:",squash"`
 	// A list of networks by UUID to attach to this instance.
 	Networks []string `mapstructure:"networks" required:"false"`
 	// A list of ports by UUID to attach to this instance.
@@ -238,6 +245,8 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) []error {
 
 	// Validation
 	errs := c.Comm.Prepare(ctx)
+	errs = append(errs, c.Guest.Prepare(&c.Comm)...)
+	errs = append(errs, c.FirewallConfig.Prepare()...)
 
 	if c.Comm.SSHKeyPairName != "" {
 		if c.Comm.Type == "winrm" && c.Comm.WinRMPassword == "" && c.Comm.SSHPrivateKeyFile == "" {
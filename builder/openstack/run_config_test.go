@@ -75,6 +75,19 @@ func TestRunConfigPrepare_SSHPort(t *testing.T) {
 	}
 }
 
+func TestRunConfigPrepare_TemporaryFirewallRule(t *testing.T) {
+	c := testRunConfig()
+	c.FirewallConfig.TemporaryFirewallRule = true
+	if err := c.Prepare(nil); len(err) != 0 {
+		t.Fatalf("err: %s", err)
+	}
+
+	c.FirewallConfig.TemporaryFirewallSourceCidrs = []string{"10.0.0.1"}
+	if err := c.Prepare(nil); len(err) != 1 {
+		t.Fatalf("bad CIDR should error: %s", err)
+	}
+}
+
 func TestRunConfigPrepare_BlockStorage(t *testing.T) {
 	c := testRunConfig()
 	c.UseBlockStorageVolume = true
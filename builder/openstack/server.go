@@ -1,14 +1,18 @@
 package openstack
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/hashicorp/packer/common/wait"
 	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
 )
 
 // StateRefreshFunc is a function type used for StateChangeConf that is
@@ -29,6 +33,10 @@ type StateChangeConf struct {
 	Refresh   StateRefreshFunc
 	StepState multistep.StateBag
 	Target    []string
+	// Ui reports the state changes, when set.
+	Ui packer.Ui
+	// Context stops the wait when done, when set.
+	Context context.Context
 }
 
 // ServerStateRefreshFunc returns a StateRefreshFunc that is used to watch
@@ -52,42 +60,29 @@ func ServerStateRefreshFunc(
 
 // WaitForState watches an object and waits for it to achieve a certain
 // state.
-func WaitForState(conf *StateChangeConf) (i interface{}, err error) {
-	log.Printf("Waiting for state to become: %s", conf.Target)
-
-	for {
-		var currentProgress int
-		var currentState string
-		i, currentState, currentProgress, err = conf.Refresh()
+func WaitForState(conf *StateChangeConf) (interface{}, error) {
+	c := wait.Config{
+		Description: fmt.Sprintf("state to become %s", strings.Join(conf.Target, " or ")),
+		MaxDelay:    10 * time.Second,
+	}
+	if conf.Ui != nil {
+		c.Progress = wait.UiProgress(conf.Ui)
+	}
+	ctx := conf.Context
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+	return c.ForState(ctx, func(context.Context) (interface{}, string, error) {
+		i, currentState, currentProgress, err := conf.Refresh()
 		if err != nil {
-			return
+			return nil, "", err
 		}
-
-		for _, t := range conf.Target {
-			if currentState == t {
-				return
-			}
-		}
-
 		if conf.StepState != nil {
 			if _, ok := conf.StepState.GetOk(multistep.StateCancelled); ok {
-				return nil, errors.New("interrupted")
+				return nil, currentState, errors.New("interrupted")
 			}
 		}
-
-		found := false
-		for _, allowed := range conf.Pending {
-			if currentState == allowed {
-				found = true
-				break
-			}
-		}
-
-		if !found {
-			return nil, fmt.Errorf("unexpected state '%s', wanted target '%s'", currentState, conf.Target)
-		}
-
 		log.Printf("Waiting for state to become: %s currently %s (%d%%)", conf.Target, currentState, currentProgress)
-		time.Sleep(2 * time.Second)
-	}
+		return i, currentState, nil
+	}, conf.Pending, conf.Target)
 }
@@ -106,6 +106,7 @@ func (s *StepAllocateIp) Run(ctx context.Context, state multistep.StateBag) mult
 		}
 
 		instanceIP = *newIP
+		trackResource(config.Region, CleanupFloatingIP, instanceIP.ID)
 		ui.Message(fmt.Sprintf("Created floating IP: '%s' (%s)", instanceIP.ID, instanceIP.FloatingIP))
 		state.Put("floatingip_istemp", true)
 	}
@@ -173,6 +174,7 @@ func (s *StepAllocateIp) Cleanup(state multistep.StateBag) {
 			return
 		}
 
+		untrackResource(CleanupFloatingIP, instanceIP.ID)
 		ui.Say(fmt.Sprintf("Deleted temporary floating IP '%s' (%s)", instanceIP.ID, instanceIP.FloatingIP))
 	}
 }
@@ -73,22 +73,24 @@ func (s *StepCreateVolume) Run(ctx context.Context, state multistep.StateBag) mu
 		return multistep.ActionHalt
 	}
 
+	// Volume was created, so remember to clean it up, even if it never
+	// becomes available.
+	s.doCleanup = true
+	s.volumeID = volume.ID
+	trackResource(config.Region, CleanupVolume, volume.ID)
+
 	// Wait for volume to become available.
 	ui.Say(fmt.Sprintf("Waiting for volume %s (volume id: %s) to become available...", config.VolumeName, volume.ID))
-	if err := WaitForVolume(blockStorageClient, volume.ID); err != nil {
+	if err := WaitForVolume(ctx, blockStorageClient, volume.ID); err != nil {
 		err := fmt.Errorf("Error waiting for volume: %s", err)
 		state.Put("error", err)
 		ui.Error(err.Error())
 		return multistep.ActionHalt
 	}
 
-	// Volume was created, so remember to clean it up.
-	s.doCleanup = true
-
 	// Set the Volume ID in the state.
 	ui.Message(fmt.Sprintf("Volume ID: %s", volume.ID))
 	state.Put("volume_id", volume.ID)
-	s.volumeID = volume.ID
 
 	return multistep.ActionContinue
 }
@@ -100,6 +102,7 @@ func (s *StepCreateVolume) Cleanup(state multistep.StateBag) {
 
 	config := state.Get("config").(*Config)
 	ui := state.Get("ui").(packer.Ui)
+	ctx := multistep.CleanupContext(state)
 
 	blockStorageClient, err := config.blockStorageV3Client()
 	if err != nil {
@@ -116,10 +119,11 @@ func (s *StepCreateVolume) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	if status != "available" {
+	// A volume in error can be deleted right away.
+	if status != "available" && status != "error" {
 		ui.Say(fmt.Sprintf(
 			"Waiting for volume %s (volume id: %s) to become available...", s.VolumeName, s.volumeID))
-		if err := WaitForVolume(blockStorageClient, s.volumeID); err != nil {
+		if err := WaitForVolume(ctx, blockStorageClient, s.volumeID); err != nil {
 			ui.Error(fmt.Sprintf(
 				"Error getting the volume information. Please delete the volume manually: %s", s.volumeID))
 			return
@@ -130,5 +134,15 @@ func (s *StepCreateVolume) Cleanup(state multistep.StateBag) {
 	if err != nil {
 		ui.Error(fmt.Sprintf(
 			"Error cleaning up volume. Please delete the volume manually: %s", s.volumeID))
+		return
+	}
+
+	// Make sure the volume is gone, as a volume failing to delete goes to
+	// the error_deleting status.
+	if err := WaitForVolumeDeletion(ctx, blockStorageClient, s.volumeID); err != nil {
+		ui.Error(fmt.Sprintf(
+			"Error deleting volume (%s). Please delete the volume manually: %s", err, s.volumeID))
+		return
 	}
+	untrackResource(CleanupVolume, s.volumeID)
 }
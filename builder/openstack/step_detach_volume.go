@@ -39,7 +39,7 @@ func (s *StepDetachVolume) Run(ctx context.Context, state multistep.StateBag) mu
 
 	// Wait for volume to become available.
 	ui.Say(fmt.Sprintf("Waiting for volume %s (volume id: %s) to become available...", config.VolumeName, volume))
-	if err := WaitForVolume(blockStorageClient, volume); err != nil {
+	if err := WaitForVolume(ctx, blockStorageClient, volume); err != nil {
 		err := fmt.Errorf("Error waiting for volume: %s", err)
 		state.Put("error", err)
 		ui.Error(err.Error())
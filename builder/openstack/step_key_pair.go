@@ -76,6 +76,7 @@ func (s *StepKeyPair) Run(ctx context.Context, state multistep.StateBag) multist
 		state.Put("error", fmt.Errorf("Error creating temporary keypair: %s", err))
 		return multistep.ActionHalt
 	}
+	trackResource(config.Region, CleanupKeyPair, s.Comm.SSHTemporaryKeyPairName)
 
 	if len(keypair.PrivateKey) == 0 {
 		state.Put("error", fmt.Errorf("The temporary keypair returned was blank"))
@@ -186,5 +187,7 @@ func (s *StepKeyPair) Cleanup(state multistep.StateBag) {
 	if err != nil {
 		ui.Error(fmt.Sprintf(
 			"Error cleaning up keypair. Please delete the key manually: %s", s.Comm.SSHTemporaryKeyPairName))
+		return
 	}
+	untrackResource(CleanupKeyPair, s.Comm.SSHTemporaryKeyPairName)
 }
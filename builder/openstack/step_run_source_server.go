@@ -9,6 +9,7 @@ import (
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/bootfromvolume"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/keypairs"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/hashicorp/packer/common/firewall"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
@@ -53,11 +54,17 @@ func (s *StepRunSourceServer) Run(ctx context.Context, state multistep.StateBag)
 
 	ui.Say("Launching server...")
 
+	// The temporary firewall rule is a security group named after it.
+	securityGroups := s.SecurityGroups
+	if rule, ok := state.GetOk(firewall.StateRule); ok {
+		securityGroups = append(append([]string{}, s.SecurityGroups...), rule.(string))
+	}
+
 	serverOpts := servers.CreateOpts{
 		Name:             s.Name,
 		ImageRef:         sourceImage,
 		FlavorRef:        flavor,
-		SecurityGroups:   s.SecurityGroups,
+		SecurityGroups:   securityGroups,
 		Networks:         networks,
 		AvailabilityZone: s.AvailabilityZone,
 		UserData:         userData,
@@ -108,6 +115,7 @@ func (s *StepRunSourceServer) Run(ctx context.Context, state multistep.StateBag)
 		return multistep.ActionHalt
 	}
 
+	trackResource(config.Region, CleanupServer, s.server.ID)
 	ui.Message(fmt.Sprintf("Server ID: %s", s.server.ID))
 	log.Printf("server id: %s", s.server.ID)
 
@@ -117,6 +125,7 @@ func (s *StepRunSourceServer) Run(ctx context.Context, state multistep.StateBag)
 		Target:    []string{"ACTIVE"},
 		Refresh:   ServerStateRefreshFunc(computeClient, s.server),
 		StepState: state,
+		Ui:        ui,
 	}
 	latestServer, err := WaitForState(&stateChange)
 	if err != nil {
@@ -167,7 +176,10 @@ func (s *StepRunSourceServer) Cleanup(state multistep.StateBag) {
 		Pending: []string{"ACTIVE", "BUILD", "REBUILD", "SUSPENDED", "SHUTOFF", "STOPPED"},
 		Refresh: ServerStateRefreshFunc(computeClient, s.server),
 		Target:  []string{"DELETED"},
+		Context: multistep.CleanupContext(state),
 	}
 
-	WaitForState(&stateChange)
+	if _, err := WaitForState(&stateChange); err == nil {
+		untrackResource(CleanupServer, s.server.ID)
+	}
 }
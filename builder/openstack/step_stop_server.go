@@ -46,6 +46,7 @@ func (s *StepStopServer) Run(ctx context.Context, state multistep.StateBag) mult
 		Target:    []string{"SHUTOFF", "STOPPED"},
 		Refresh:   ServerStateRefreshFunc(client, server),
 		StepState: state,
+		Ui:        ui,
 	}
 	if _, err := WaitForState(&stateChange); err != nil {
 		err := fmt.Errorf("Error waiting for server (%s) to stop: %s", server.ID, err)
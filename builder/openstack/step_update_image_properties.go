@@ -0,0 +1,64 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	imageservice "github.com/gophercloud/gophercloud/openstack/imageservice/v2/images"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+type stepUpdateImageProperties struct{}
+
+func (s *stepUpdateImageProperties) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	imageId := state.Get("image").(string)
+	ui := state.Get("ui").(packer.Ui)
+	config := state.Get("config").(*Config)
+
+	if len(config.ImageProperties) == 0 {
+		return multistep.ActionContinue
+	}
+	imageClient, err := config.imageV2Client()
+	if err != nil {
+		err = fmt.Errorf("Error initializing image service client: %s", err)
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	ui.Say("Updating image properties...")
+	r := imageservice.Update(imageClient, imageId, imagePropertiesUpdateOpts(config.ImageProperties))
+
+	if _, err = r.Extract(); err != nil {
+		err = fmt.Errorf("Error updating image properties: %s", err)
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepUpdateImageProperties) Cleanup(multistep.StateBag) {
+	// No cleanup...
+}
+
+// imagePropertiesUpdateOpts returns the operations setting properties on an
+// image, sorted by name. Adding an existing property replaces its value.
+func imagePropertiesUpdateOpts(properties map[string]string) imageservice.UpdateOpts {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	opts := make(imageservice.UpdateOpts, 0, len(names))
+	for _, name := range names {
+		opts = append(opts, imageservice.UpdateImageProperty{
+			Op:    imageservice.AddOp,
+			Name:  name,
+			Value: properties[name],
+		})
+	}
+	return opts
+}
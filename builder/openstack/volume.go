@@ -1,6 +1,8 @@
 package openstack
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"time"
 
@@ -10,7 +12,7 @@ import (
 )
 
 // WaitForVolume waits for the given volume to become available.
-func WaitForVolume(blockStorageClient *gophercloud.ServiceClient, volumeID string) error {
+func WaitForVolume(ctx context.Context, blockStorageClient *gophercloud.ServiceClient, volumeID string) error {
 	maxNumErrors := 10
 	numErrors := 0
 
@@ -25,7 +27,9 @@ func WaitForVolume(blockStorageClient *gophercloud.ServiceClient, volumeID strin
 					return err
 				}
 				log.Printf("[ERROR] %d error received, will ignore and retry: %s", errCode.Actual, err)
-				time.Sleep(2 * time.Second)
+				if err := sleepContext(ctx, 2*time.Second); err != nil {
+					return err
+				}
 				continue
 			}
 
@@ -37,7 +41,41 @@ func WaitForVolume(blockStorageClient *gophercloud.ServiceClient, volumeID strin
 		}
 
 		log.Printf("Waiting for volume creation status: %s", status)
-		time.Sleep(2 * time.Second)
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return err
+		}
+	}
+}
+
+// WaitForVolumeDeletion waits for the given volume to be deleted.
+func WaitForVolumeDeletion(ctx context.Context, blockStorageClient *gophercloud.ServiceClient, volumeID string) error {
+	for {
+		status, err := GetVolumeStatus(blockStorageClient, volumeID)
+		if err != nil {
+			if _, ok := err.(gophercloud.ErrDefault404); ok {
+				return nil
+			}
+			return err
+		}
+
+		if status == "error_deleting" {
+			return fmt.Errorf("volume %s failed to delete", volumeID)
+		}
+
+		log.Printf("Waiting for volume deletion status: %s", status)
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return err
+		}
+	}
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
 	}
 }
 
@@ -0,0 +1,31 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// winrmPasswordSource retrieves the password the booted server generated
+// for its Administrator.
+var winrmPasswordSource = communicator.WinRMPasswordSource{
+	Get:       getPassword,
+	Encrypted: true,
+}
+
+func getPassword(_ context.Context, state multistep.StateBag) (string, error) {
+	config := state.Get("config").(*Config)
+	server := state.Get("server").(*servers.Server)
+
+	// We need the v2 compute client
+	computeClient, err := config.computeV2Client()
+	if err != nil {
+		return "", fmt.Errorf("Error initializing compute client: %s", err)
+	}
+
+	// Without a private key, the password is returned encrypted.
+	return servers.GetPassword(computeClient, server.ID).ExtractPassword(nil)
+}
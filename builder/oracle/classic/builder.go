@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/hcl/v2/hcldec"
 	ocommon "github.com/hashicorp/packer/builder/oracle/common"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -106,7 +107,29 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 				Host:      communicator.CommHost(b.config.Comm.Host(), "instance_ip"),
 				SSHConfig: b.config.Comm.SSHConfigFunc(),
 			},
+			&guest.StepCheckClock{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepEnvironment{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
 			&common.StepProvision{},
+			&guest.StepRotateCredentials{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepCleanup{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepLinuxGeneralize{
+				Config: &b.config.Guest,
+			},
+			&guest.StepSysprep{
+				Config: &b.config.Guest,
+			},
 			&stepTerminatePVMaster{},
 			&stepSecurity{
 				SecurityListKey: "security_list_builder",
@@ -142,9 +165,6 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			},
 			&stepCreateImage{},
 			&stepListImages{},
-			&common.StepCleanupTempKeys{
-				Comm: &b.config.Comm,
-			},
 		}
 	} else {
 		// Build the steps
@@ -169,9 +189,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 				Host:      communicator.CommHost(b.config.Comm.Host(), "instance_ip"),
 				SSHConfig: b.config.Comm.SSHConfigFunc(),
 			},
+			&guest.StepCheckClock{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepEnvironment{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
 			&common.StepProvision{},
-			&common.StepCleanupTempKeys{
-				Comm: &b.config.Comm,
+			&guest.StepRotateCredentials{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepCleanup{
+				Config: &b.config.Guest,
+				Comm:   &b.config.Comm,
+			},
+			&guest.StepLinuxGeneralize{
+				Config: &b.config.Guest,
+			},
+			&guest.StepSysprep{
+				Config: &b.config.Guest,
 			},
 			&stepSnapshot{},
 			&stepListImages{},
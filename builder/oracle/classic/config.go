@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
@@ -20,6 +21,7 @@ type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 	PVConfig            `mapstructure:",squash"`
 	Comm                communicator.Config `mapstructure:",squash"`
+	Guest               guest.Config        `mapstructure:",squash"`
 	attribs             map[string]interface{}
 
 	// Access config overrides
@@ -122,6 +124,9 @@ func (c *Config) Prepare(raws ...interface{}) error {
 	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
 		errs = packer.MultiErrorAppend(errs, es...)
 	}
+	if es := c.Guest.Prepare(&c.Comm); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
 
 	if errs != nil && len(errs.Errors) > 0 {
 		return errs
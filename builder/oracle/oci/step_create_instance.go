@@ -46,6 +46,7 @@ func (s *stepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 }
 
 func (s *stepCreateInstance) Cleanup(state multistep.StateBag) {
+	ctx := multistep.CleanupContext(state)
 	driver := state.Get("driver").(Driver)
 	ui := state.Get("ui").(packer.Ui)
 
@@ -57,14 +58,14 @@ func (s *stepCreateInstance) Cleanup(state multistep.StateBag) {
 
 	ui.Say(fmt.Sprintf("Terminating instance (%s)...", id))
 
-	if err := driver.TerminateInstance(context.TODO(), id); err != nil {
+	if err := driver.TerminateInstance(ctx, id); err != nil {
 		err = fmt.Errorf("Error terminating instance. Please terminate manually: %s", err)
 		ui.Error(err.Error())
 		state.Put("error", err)
 		return
 	}
 
-	err := driver.WaitForInstanceState(context.TODO(), id, []string{"TERMINATING"}, "TERMINATED")
+	err := driver.WaitForInstanceState(ctx, id, []string{"TERMINATING"}, "TERMINATED")
 	if err != nil {
 		err = fmt.Errorf("Error terminating instance. Please terminate manually: %s", err)
 		ui.Error(err.Error())
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/hcl2template"
 	"github.com/hashicorp/packer/helper/communicator"
@@ -86,6 +87,7 @@ type RunConfig struct {
 
 	// Communicator settings
 	Comm         communicator.Config `mapstructure:",squash"`
+	Guest        guest.Config        `mapstructure:",squash"`
 	SSHInterface string              `mapstructure:"ssh_interface"`
 }
 
@@ -110,6 +112,7 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) []error {
 
 	// Validation
 	errs := c.Comm.Prepare(ctx)
+	errs = append(errs, c.Guest.Prepare(&c.Comm)...)
 
 	for _, preparer := range []interface{ Prepare() []error }{
 		&c.SourceOmiFilter,
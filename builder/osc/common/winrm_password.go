@@ -0,0 +1,30 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/outscale/osc-go/oapi"
+)
+
+// WinRMPasswordSource retrieves the password Outscale generated for the
+// Administrator of the vm.
+var WinRMPasswordSource = communicator.WinRMPasswordSource{
+	Get:       readAdminPassword,
+	Encrypted: true,
+}
+
+func readAdminPassword(_ context.Context, state multistep.StateBag) (string, error) {
+	oapiconn := state.Get("oapi").(*oapi.Client)
+	vm := state.Get("vm").(oapi.Vm)
+
+	resp, err := oapiconn.POST_ReadAdminPassword(oapi.ReadAdminPasswordRequest{
+		VmId: vm.VmId,
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error retrieving auto-generated vm password: %s", err)
+	}
+	return resp.OK.AdminPassword, nil
+}
@@ -25,8 +25,9 @@ type Driver interface {
 	// Get path to the first virtual disk image
 	DiskPath(string) (string, error)
 
-	// Import a VM
-	Import(string, string, string, bool) error
+	// Import a VM, optionally as a linked clone and from a snapshot of
+	// the source VM.
+	Import(string, string, string, bool, bool, string) error
 
 	// Checks if the VM with the given name is running.
 	IsRunning(string) (bool, error)
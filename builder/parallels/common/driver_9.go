@@ -2,6 +2,7 @@ package common
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -31,7 +32,9 @@ type Parallels9Driver struct {
 }
 
 // Import creates a clone of the source VM and reassigns the MAC address if needed.
-func (d *Parallels9Driver) Import(name, srcPath, dstDir string, reassignMAC bool) error {
+// The clone is a linked clone if linked is true, and is made from the given
+// snapshot, by name or ID, if snapshot is not empty.
+func (d *Parallels9Driver) Import(name, srcPath, dstDir string, reassignMAC, linked bool, snapshot string) error {
 	err := d.Prlctl("register", srcPath, "--preserve-uuid")
 	if err != nil {
 		return err
@@ -50,7 +53,19 @@ func (d *Parallels9Driver) Import(name, srcPath, dstDir string, reassignMAC bool
 		}
 	}
 
-	err = d.Prlctl("clone", srcID, "--name", name, "--dst", dstDir)
+	args := []string{"clone", srcID, "--name", name, "--dst", dstDir}
+	if linked {
+		args = append(args, "--linked")
+	}
+	if snapshot != "" {
+		snapshotID, err := d.snapshotID(srcID, snapshot)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--id", snapshotID)
+	}
+
+	err = d.Prlctl(args...)
 	if err != nil {
 		return err
 	}
@@ -67,6 +82,47 @@ func (d *Parallels9Driver) Import(name, srcPath, dstDir string, reassignMAC bool
 	return nil
 }
 
+// snapshotID returns the ID of the snapshot of the VM with the given name or
+// ID.
+func (d *Parallels9Driver) snapshotID(vmID, snapshot string) (string, error) {
+	out, err := exec.Command(d.PrlctlPath, "snapshot-list", vmID, "--json").Output()
+	if err != nil {
+		return "", fmt.Errorf("Error listing snapshots: %s", err)
+	}
+
+	return parseSnapshotID(out, snapshot)
+}
+
+func parseSnapshotID(out []byte, snapshot string) (string, error) {
+	snapshots := make(map[string]struct {
+		Name string `json:"name"`
+	})
+	if len(bytes.TrimSpace(out)) > 0 {
+		if err := json.Unmarshal(out, &snapshots); err != nil {
+			return "", fmt.Errorf("Error parsing snapshots: %s", err)
+		}
+	}
+
+	var ids []string
+	for id, s := range snapshots {
+		if strings.Trim(id, "{}") == strings.Trim(snapshot, "{}") {
+			return id, nil
+		}
+		if s.Name == snapshot {
+			ids = append(ids, id)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("Snapshot %q not found", snapshot)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("Several snapshots are named %q, use the ID of one of them instead", snapshot)
+	}
+}
+
 func getVMID(path string) (string, error) {
 	return getConfigValueFromXpath(path, "/ParallelsVirtualMachine/Identification/VmUuid")
 }
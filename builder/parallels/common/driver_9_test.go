@@ -85,3 +85,36 @@ func TestXMLParseConfig(t *testing.T) {
 		t.Fatalf("Expected %q, got %q", "20", result)
 	}
 }
+
+func TestParseSnapshotID(t *testing.T) {
+	out := []byte(`{
+	"{64d8c3b9-5fb5-4bb6-8c4f-95e8e4b3a9d1}": {"name": "golden", "date": "2020-05-01 10:00:00", "state": "poweroff", "current": false, "parent": ""},
+	"{b1e2d3c4-0000-4bb6-8c4f-95e8e4b3a9d1}": {"name": "dup", "date": "2020-05-02 10:00:00", "state": "poweroff", "current": false, "parent": ""},
+	"{c1e2d3c4-0000-4bb6-8c4f-95e8e4b3a9d1}": {"name": "dup", "date": "2020-05-03 10:00:00", "state": "poweroff", "current": true, "parent": ""}
+}`)
+
+	cases := map[string]string{
+		"golden":                                 "{64d8c3b9-5fb5-4bb6-8c4f-95e8e4b3a9d1}",
+		"{64d8c3b9-5fb5-4bb6-8c4f-95e8e4b3a9d1}": "{64d8c3b9-5fb5-4bb6-8c4f-95e8e4b3a9d1}",
+		"c1e2d3c4-0000-4bb6-8c4f-95e8e4b3a9d1":   "{c1e2d3c4-0000-4bb6-8c4f-95e8e4b3a9d1}",
+	}
+	for snapshot, expected := range cases {
+		id, err := parseSnapshotID(out, snapshot)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if id != expected {
+			t.Fatalf("bad id for %s: %s", snapshot, id)
+		}
+	}
+
+	// Ambiguous names and missing snapshots are errors
+	for _, snapshot := range []string{"dup", "missing"} {
+		if _, err := parseSnapshotID(out, snapshot); err == nil {
+			t.Fatalf("should have error for %s", snapshot)
+		}
+	}
+	if _, err := parseSnapshotID([]byte(""), "golden"); err == nil {
+		t.Fatal("should have error without snapshots")
+	}
+}
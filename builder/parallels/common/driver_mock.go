@@ -20,11 +20,13 @@ type DriverMock struct {
 	DiskPathResult string
 	DiskPathErr    error
 
-	ImportCalled  bool
-	ImportName    string
-	ImportSrcPath string
-	ImportDstPath string
-	ImportErr     error
+	ImportCalled   bool
+	ImportName     string
+	ImportSrcPath  string
+	ImportDstPath  string
+	ImportLinked   bool
+	ImportSnapshot string
+	ImportErr      error
 
 	IsRunningName   string
 	IsRunningReturn bool
@@ -82,11 +84,13 @@ func (d *DriverMock) DiskPath(name string) (string, error) {
 	return d.DiskPathResult, d.DiskPathErr
 }
 
-func (d *DriverMock) Import(name, srcPath, dstPath string, reassignMAC bool) error {
+func (d *DriverMock) Import(name, srcPath, dstPath string, reassignMAC, linked bool, snapshot string) error {
 	d.ImportCalled = true
 	d.ImportName = name
 	d.ImportSrcPath = srcPath
 	d.ImportDstPath = dstPath
+	d.ImportLinked = linked
+	d.ImportSnapshot = snapshot
 	return d.ImportErr
 }
 
@@ -1,16 +1,20 @@
 package common
 
 import (
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
 // SSHConfig contains the configuration for SSH communicator.
 type SSHConfig struct {
-	Comm communicator.Config `mapstructure:",squash"`
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
 }
 
 // Prepare sets the default values for SSH communicator properties.
 func (c *SSHConfig) Prepare(ctx *interpolate.Context) []error {
-	return c.Comm.Prepare(ctx)
+	errs := c.Comm.Prepare(ctx)
+	errs = append(errs, c.Guest.Prepare(&c.Comm)...)
+	return errs
 }
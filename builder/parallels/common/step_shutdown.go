@@ -1,19 +1,16 @@
 package common
 
 import (
-	"bytes"
 	"context"
-	"errors"
-	"fmt"
-	"log"
 	"time"
 
+	"github.com/hashicorp/packer/common/shutdowncommand"
 	"github.com/hashicorp/packer/helper/multistep"
-	"github.com/hashicorp/packer/packer"
 )
 
 // StepShutdown is a step that shuts down the machine. It first attempts to do
-// so gracefully, but ultimately forcefully shuts it down if that fails.
+// so gracefully, but ultimately forcefully shuts it down if that fails and
+// Force is set.
 //
 // Uses:
 //   communicator packer.Communicator
@@ -22,70 +19,38 @@ import (
 //   vmName string
 //
 // Produces:
-//   <nothing>
+//   shutdown_path string
 type StepShutdown struct {
 	Command string
 	Timeout time.Duration
+	Force   bool
 }
 
 // Run shuts down the VM.
 func (s *StepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
-	comm := state.Get("communicator").(packer.Communicator)
-	driver := state.Get("driver").(Driver)
-	ui := state.Get("ui").(packer.Ui)
-	vmName := state.Get("vmName").(string)
-
-	if s.Command != "" {
-		ui.Say("Gracefully halting virtual machine...")
-		log.Printf("Executing shutdown command: %s", s.Command)
-
-		var stdout, stderr bytes.Buffer
-		cmd := &packer.RemoteCmd{
+	step := &shutdowncommand.StepShutdown{
+		Shutdown: shutdowncommand.Shutdown{
 			Command: s.Command,
-			Stdout:  &stdout,
-			Stderr:  &stderr,
-		}
-		if err := comm.Start(ctx, cmd); err != nil {
-			err := fmt.Errorf("Failed to send shutdown command: %s", err)
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		}
-
-		// Wait for the machine to actually shut down
-		log.Printf("Waiting max %s for shutdown to complete", s.Timeout)
-		shutdownTimer := time.After(s.Timeout)
-		for {
-			running, _ := driver.IsRunning(vmName)
-			if !running {
-				break
-			}
-
-			select {
-			case <-shutdownTimer:
-				log.Printf("Shutdown stdout: %s", stdout.String())
-				log.Printf("Shutdown stderr: %s", stderr.String())
-				err := errors.New("Timeout while waiting for machine to shut down.")
-				state.Put("error", err)
-				ui.Error(err.Error())
-				return multistep.ActionHalt
-			default:
-				time.Sleep(500 * time.Millisecond)
+			Timeout: s.Timeout,
+			Force:   s.Force,
+		},
+		Machine: func(state multistep.StateBag) shutdowncommand.Machine {
+			return &vm{
+				driver: state.Get("driver").(Driver),
+				name:   state.Get("vmName").(string),
 			}
-		}
-	} else {
-		ui.Say("Halting the virtual machine...")
-		if err := driver.Stop(vmName); err != nil {
-			err = fmt.Errorf("Error stopping VM: %s", err)
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		}
+		},
 	}
-
-	log.Println("VM shut down.")
-	return multistep.ActionContinue
+	return step.Run(ctx, state)
 }
 
-// Cleanup does nothing.
 func (s *StepShutdown) Cleanup(state multistep.StateBag) {}
+
+// vm is the machine of the driver named name.
+type vm struct {
+	driver Driver
+	name   string
+}
+
+func (m *vm) IsRunning() (bool, error) { return m.driver.IsRunning(m.name) }
+func (m *vm) Stop() error              { return m.driver.Stop(m.name) }
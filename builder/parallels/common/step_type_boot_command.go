@@ -26,6 +26,7 @@ type StepTypeBootCommand struct {
 	VMName         string
 	Ctx            interpolate.Context
 	GroupInterval  time.Duration
+	Keymap         string
 }
 
 // Run types the boot command by sending key scancodes into the VM.
@@ -79,7 +80,13 @@ func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag)
 	sendCodes := func(codes []string) error {
 		return driver.SendKeyScanCodes(s.VMName, codes...)
 	}
-	d := bootcommand.NewPCXTDriver(sendCodes, -1, s.GroupInterval)
+	d, err := bootcommand.NewKeymapDriver(bootcommand.NewPCXTDriver(sendCodes, -1, s.GroupInterval), s.Keymap)
+	if err != nil {
+		err := fmt.Errorf("Error preparing boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
 
 	ui.Say("Typing the boot command...")
 	command, err := interpolate.Render(s.BootCommand, &s.Ctx)
@@ -12,6 +12,7 @@ import (
 	parallelscommon "github.com/hashicorp/packer/builder/parallels/common"
 	"github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/shutdowncommand"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -178,6 +179,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"local virtual machine", "output directory"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	// Create the driver that we'll use to communicate with Parallels
 	driver, err := parallelscommon.NewDriver()
@@ -233,12 +238,21 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			VMName:         b.config.VMName,
 			Ctx:            b.config.ctx,
 			GroupInterval:  b.config.BootConfig.BootGroupInterval,
+			Keymap:         b.config.BootConfig.BootKeymap,
 		},
 		&communicator.StepConnect{
 			Config:    &b.config.SSHConfig.Comm,
 			Host:      parallelscommon.CommHost(b.config.SSHConfig.Comm.SSHHost),
 			SSHConfig: b.config.SSHConfig.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
 		&parallelscommon.StepUploadVersion{
 			Path: b.config.PrlctlVersionFile,
 		},
@@ -249,12 +263,24 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Ctx:                     b.config.ctx,
 		},
 		new(common.StepProvision),
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.SSHConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.SSHConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.SSHConfig.Guest,
 		},
 		&parallelscommon.StepShutdown{
 			Command: b.config.ShutdownCommand,
 			Timeout: b.config.ShutdownTimeout,
+			Force:   b.config.ForceShutdown,
 		},
 		&parallelscommon.StepPrlctl{
 			Commands: b.config.PrlctlPost,
@@ -45,6 +45,16 @@ type Config struct {
 	// NIC will reused when imported else a new MAC address will be generated
 	// by Parallels. Defaults to "false".
 	ReassignMAC bool `mapstructure:"reassign_mac" required:"false"`
+	// If this is "true", the virtual machine is created as a linked clone
+	// of the source, which is much faster and uses less space than a full
+	// clone, but keeps depending on the disks of the source. Use this to
+	// iterate on provisioning from a "golden" virtual machine. Defaults to
+	// "false".
+	Linked bool `mapstructure:"linked" required:"false"`
+	// The name or ID of a snapshot of the source virtual machine to clone
+	// from, instead of its current state. Snapshot IDs are listed by
+	// `prlctl snapshot-list`.
+	SourceSnapshot string `mapstructure:"source_snapshot" required:"false"`
 
 	ctx interpolate.Context
 }
@@ -22,7 +22,7 @@ func (s *StepImport) Run(ctx context.Context, state multistep.StateBag) multiste
 	config := state.Get("config").(*Config)
 
 	ui.Say(fmt.Sprintf("Importing VM: %s", s.SourcePath))
-	if err := driver.Import(s.Name, s.SourcePath, config.OutputDir, config.ReassignMAC); err != nil {
+	if err := driver.Import(s.Name, s.SourcePath, config.OutputDir, config.ReassignMAC, config.Linked, config.SourceSnapshot); err != nil {
 		err := fmt.Errorf("Error importing VM: %s", err)
 		state.Put("error", err)
 		ui.Error(err.Error())
@@ -13,6 +13,7 @@ import (
 
 	"github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -28,6 +29,7 @@ type Config struct {
 	bootcommand.BootConfig `mapstructure:",squash"`
 	BootKeyInterval        time.Duration       `mapstructure:"boot_key_interval"`
 	Comm                   communicator.Config `mapstructure:",squash"`
+	Guest                  guest.Config        `mapstructure:",squash"`
 
 	ProxmoxURLRaw      string `mapstructure:"proxmox_url"`
 	proxmoxURL         *url.URL
@@ -189,7 +191,13 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	}
 
 	errs = packer.MultiErrorAppend(errs, c.Comm.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.Guest.Prepare(&c.Comm)...)
 	errs = packer.MultiErrorAppend(errs, c.BootConfig.Prepare(&c.ctx)...)
+	// The keys are sent to Proxmox with their modifiers, that the keymaps
+	// can't hold down.
+	if c.BootKeymap != "" && c.BootKeymap != "us" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("boot_keymap %q is not supported by this builder", c.BootKeymap))
+	}
 	errs = packer.MultiErrorAppend(errs, c.HTTPConfig.Prepare(&c.ctx)...)
 
 	// Check ISO config
@@ -208,3 +208,14 @@ func TestPacketQueueSupportForNetworkAdapters(t *testing.T) {
 		}
 	}
 }
+
+func TestBootKeymapUnsupported(t *testing.T) {
+	cfg := mandatoryConfig(t)
+	cfg["boot_keymap"] = "de"
+
+	var c Config
+	_, err := c.Prepare(cfg)
+	if err == nil {
+		t.Error("expected config preparation to fail, but no error occured")
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/common/consolerecord"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/shutdowncommand"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -75,6 +77,7 @@ type Config struct {
 	common.ISOConfig               `mapstructure:",squash"`
 	bootcommand.VNCConfig          `mapstructure:",squash"`
 	shutdowncommand.ShutdownConfig `mapstructure:",squash"`
+	consolerecord.RecordConfig     `mapstructure:",squash"`
 	CommConfig                     CommConfig `mapstructure:",squash"`
 	common.FloppyConfig            `mapstructure:",squash"`
 	// Use iso from provided url. Qemu must support
@@ -308,6 +311,12 @@ type Config struct {
 	// QMP Socket Path when `qmp_enable` is true. Defaults to
 	// `output_directory`/`vm_name`.monitor.
 	QMPSocketPath string `mapstructure:"qmp_socket_path" required:"false"`
+	// Host directories exposed to the guest during the build, see the
+	// [shared directory configuration](#shared-directory-configuration).
+	SharedDirectories []SharedDirectory `mapstructure:"shared_directory" required:"false"`
+	// The `virtiofsd` daemon serving the shared directories using the
+	// `virtiofs` driver. Defaults to `virtiofsd`, looked up in the `PATH`.
+	VirtiofsdBinary string `mapstructure:"virtiofsd_binary" required:"false"`
 	// If true, do not pass a -display option
 	// to qemu, allowing it to choose the default. This may be needed when running
 	// under macOS, and getting errors about sdl not being available.
@@ -466,6 +475,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 
 	errs = packer.MultiErrorAppend(errs, b.config.FloppyConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.VNCConfig.Prepare(&b.config.ctx)...)
+	errs = packer.MultiErrorAppend(errs, b.config.RecordConfig.Prepare(&b.config.ctx, &b.config.PackerConfig)...)
 
 	if b.config.NetDevice == "" {
 		b.config.NetDevice = "virtio-net"
@@ -561,6 +571,25 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 		b.config.QMPSocketPath = filepath.Join(b.config.OutputDir, socketName)
 	}
 
+	tags := make(map[string]bool)
+	for i := range b.config.SharedDirectories {
+		d := &b.config.SharedDirectories[i]
+		errs = packer.MultiErrorAppend(errs, d.Prepare()...)
+		if tags[d.Tag] {
+			errs = packer.MultiErrorAppend(
+				errs, fmt.Errorf("shared_directory tag %q is used more than once", d.Tag))
+		}
+		tags[d.Tag] = true
+		if d.Driver == "virtiofs" && runtime.GOOS != "linux" {
+			errs = packer.MultiErrorAppend(
+				errs, fmt.Errorf("the virtiofs shared_directory driver is only supported in Linux based OSes"))
+		}
+	}
+
+	if b.config.VirtiofsdBinary == "" {
+		b.config.VirtiofsdBinary = "virtiofsd"
+	}
+
 	if b.config.QemuArgs == nil {
 		b.config.QemuArgs = make([][]string, 0)
 	}
@@ -572,6 +601,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"local virtual machine", "output directory"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	// Create the driver that we'll use to communicate with Qemu
 	driver, err := b.newDriver(b.config.QemuBinary)
@@ -633,10 +666,15 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 
 	steps = append(steps,
 		new(stepConfigureVNC),
+		new(stepStartVirtiofsd),
 		steprun,
 		&stepConfigureQMP{
 			QMPSocketPath: b.config.QMPSocketPath,
 		},
+		&consolerecord.StepRecordConsole{
+			Config:           &b.config.RecordConfig,
+			NewScreenshotter: newVNCScreenshotter,
+		},
 		&stepTypeBootCommand{},
 	)
 
@@ -657,6 +695,14 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 				SSHPort:   commPort,
 				WinRMPort: commPort,
 			},
+			&guest.StepCheckClock{
+				Config: &b.config.CommConfig.Guest,
+				Comm:   &b.config.CommConfig.Comm,
+			},
+			&guest.StepEnvironment{
+				Config: &b.config.CommConfig.Guest,
+				Comm:   &b.config.CommConfig.Comm,
+			},
 		)
 	}
 
@@ -665,8 +711,19 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	)
 
 	steps = append(steps,
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.CommConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.CommConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.CommConfig.Guest,
 		},
 	)
 	steps = append(steps,
@@ -625,6 +625,49 @@ func TestBuilderPrepare_VNCPassword(t *testing.T) {
 	}
 }
 
+func TestBuilderPrepare_SharedDirectories(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config["shared_directory"] = []map[string]interface{}{
+		{"host_path": dir, "tag": "mirror", "read_only": true},
+	}
+	_, warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if b.config.SharedDirectories[0].Driver != "9p" {
+		t.Fatalf("bad driver: %s", b.config.SharedDirectories[0].Driver)
+	}
+	if b.config.VirtiofsdBinary != "virtiofsd" {
+		t.Fatalf("bad virtiofsd binary: %s", b.config.VirtiofsdBinary)
+	}
+
+	invalid := [][]map[string]interface{}{
+		{{"host_path": dir}},
+		{{"host_path": filepath.Join(dir, "missing"), "tag": "mirror"}},
+		{{"host_path": dir, "tag": "mirror", "driver": "nfs"}},
+		{{"host_path": dir, "tag": "mirror", "driver": "virtiofs", "read_only": true}},
+		{{"host_path": dir, "tag": "mirror"}, {"host_path": dir, "tag": "mirror"}},
+	}
+	for _, directories := range invalid {
+		b = Builder{}
+		config["shared_directory"] = directories
+		_, _, err = b.Prepare(config)
+		if err == nil {
+			t.Fatalf("should have error: %#v", directories)
+		}
+	}
+}
+
 func TestCommConfigPrepare_BackwardsCompatibility(t *testing.T) {
 	var b Builder
 	config := testConfig()
@@ -4,12 +4,14 @@ package qemu
 import (
 	"errors"
 
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
 type CommConfig struct {
-	Comm communicator.Config `mapstructure:",squash"`
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
 	// The minimum port to use for the Communicator port on the host machine which is forwarded
 	// to the SSH or WinRM port on the guest machine. By default this is 2222.
 	HostPortMin int `mapstructure:"host_port_min" required:"false"`
@@ -59,6 +61,7 @@ func (c *CommConfig) Prepare(ctx *interpolate.Context) (warnings []string, errs
 	}
 
 	errs = c.Comm.Prepare(ctx)
+	errs = append(errs, c.Guest.Prepare(&c.Comm)...)
 	if c.HostPortMin > c.HostPortMax {
 		errs = append(errs,
 			errors.New("host_port_min must be less than host_port_max"))
@@ -0,0 +1,83 @@
+//go:generate struct-markdown
+//go:generate mapstructure-to-hcl2 -type SharedDirectory
+
+package qemu
+
+import (
+	"fmt"
+	"os"
+)
+
+// A host directory exposed to the guest during the build, so that large
+// payloads don't need to be uploaded through the communicator. The guest
+// mounts it by its tag, with `mount -t 9p -o trans=virtio mirror /mnt` for
+// the `9p` driver or `mount -t virtiofs mirror /mnt` for the `virtiofs`
+// driver.
+//
+// HCL2 example:
+//
+// ```hcl
+// shared_directory {
+//   host_path = "/srv/mirror"
+//   tag       = "mirror"
+//   read_only = true
+// }
+// ```
+//
+// JSON example:
+//
+// ```json
+// "shared_directory": [
+//   {
+//     "host_path": "/srv/mirror",
+//     "tag": "mirror",
+//     "read_only": true
+//   }
+// ]
+// ```
+type SharedDirectory struct {
+	// The host directory to share with the guest.
+	HostPath string `mapstructure:"host_path" required:"true"`
+	// The tag the guest mounts the directory by. It must be unique and at
+	// most 31 characters long.
+	Tag string `mapstructure:"tag" required:"true"`
+	// How the directory is shared: `9p` (the default), which works with any
+	// QEMU, or `virtiofs`, which is faster but needs the `virtiofsd` daemon
+	// on the host (see `virtiofsd_binary`) and a guest kernel supporting it.
+	Driver string `mapstructure:"driver" required:"false"`
+	// Whether the guest can only read the directory. Only supported by the
+	// `9p` driver. Defaults to false.
+	ReadOnly bool `mapstructure:"read_only" required:"false"`
+}
+
+func (d *SharedDirectory) Prepare() []error {
+	var errs []error
+
+	if d.HostPath == "" {
+		errs = append(errs, fmt.Errorf("A host_path must be specified for a shared_directory"))
+	} else if fi, err := os.Stat(d.HostPath); err != nil {
+		errs = append(errs, fmt.Errorf("shared_directory host_path is invalid: %s", err))
+	} else if !fi.IsDir() {
+		errs = append(errs, fmt.Errorf("shared_directory host_path %q is not a directory", d.HostPath))
+	}
+
+	if d.Tag == "" {
+		errs = append(errs, fmt.Errorf("A tag must be specified for a shared_directory"))
+	} else if len(d.Tag) > 31 {
+		errs = append(errs, fmt.Errorf("shared_directory tag %q must be at most 31 characters long", d.Tag))
+	}
+
+	switch d.Driver {
+	case "":
+		d.Driver = "9p"
+	case "9p":
+	case "virtiofs":
+		if d.ReadOnly {
+			errs = append(errs, fmt.Errorf("read_only is not supported by the virtiofs driver"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("shared_directory driver must be one of 9p or virtiofs"))
+	}
+
+	return errs
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"math/rand"
 
+	"github.com/hashicorp/packer/common/consolerecord"
 	"github.com/hashicorp/packer/common/net"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -87,3 +88,16 @@ func (s *stepConfigureVNC) Cleanup(multistep.StateBag) {
 		}
 	}
 }
+
+// newVNCScreenshotter returns a screenshotter for the VNC server of the VM
+// configured by stepConfigureVNC.
+func newVNCScreenshotter(state multistep.StateBag) (consolerecord.Screenshotter, error) {
+	config := state.Get("config").(*Config)
+	vncPort := state.Get("vnc_port").(int)
+	vncPassword := state.Get("vnc_password").(string)
+
+	return &consolerecord.VNCScreenshotter{
+		Address:  fmt.Sprintf("%s:%d", config.VNCBindAddress, vncPort),
+		Password: vncPassword,
+	}, nil
+}
@@ -202,6 +202,32 @@ func getCommandArgs(bootDrive string, state multistep.StateBag) ([]string, error
 		}
 	}
 
+	var virtfsArgs, chardevArgs []string
+	for i, d := range config.SharedDirectories {
+		switch d.Driver {
+		case "9p":
+			virtfsArg := fmt.Sprintf("local,path=%s,mount_tag=%s,security_model=mapped-xattr,id=fs%d", d.HostPath, d.Tag, i)
+			if d.ReadOnly {
+				virtfsArg += ",readonly=on"
+			}
+			virtfsArgs = append(virtfsArgs, virtfsArg)
+		case "virtiofs":
+			sockets := state.Get("virtiofs_sockets").(map[string]string)
+			chardevArgs = append(chardevArgs, fmt.Sprintf("socket,id=fs%d,path=%s", i, sockets[d.Tag]))
+			deviceArgs = append(deviceArgs, fmt.Sprintf("vhost-user-fs-pci,chardev=fs%d,tag=%s", i, d.Tag))
+		}
+	}
+	if len(virtfsArgs) > 0 {
+		defaultArgs["-virtfs"] = virtfsArgs
+	}
+	if len(chardevArgs) > 0 {
+		// vhost-user devices need the guest memory to be shared with the
+		// virtiofsd daemons.
+		defaultArgs["-chardev"] = chardevArgs
+		defaultArgs["-object"] = fmt.Sprintf("memory-backend-memfd,id=mem,size=%dM,share=on", config.MemorySize)
+		defaultArgs["-numa"] = "node,memdev=mem"
+	}
+
 	defaultArgs["-device"] = deviceArgs
 	defaultArgs["-drive"] = driveArgs
 
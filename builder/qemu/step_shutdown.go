@@ -2,17 +2,16 @@ package qemu
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
 	"time"
 
+	"github.com/hashicorp/packer/common/shutdowncommand"
 	"github.com/hashicorp/packer/helper/multistep"
-	"github.com/hashicorp/packer/packer"
 )
 
 // This step shuts down the machine. It first attempts to do so gracefully,
-// but ultimately forcefully shuts it down if that fails.
+// but ultimately forcefully shuts it down if that fails and force_shutdown
+// is set. Without a communicator, it waits for the guest to shut down by
+// itself.
 //
 // Uses:
 //   communicator packer.Communicator
@@ -21,70 +20,40 @@ import (
 //   ui     packer.Ui
 //
 // Produces:
-//   <nothing>
+//   shutdown_path string
 type stepShutdown struct{}
 
 func (s *stepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	config := state.Get("config").(*Config)
-	driver := state.Get("driver").(Driver)
-	ui := state.Get("ui").(packer.Ui)
 
-	if state.Get("communicator") == nil {
-		cancelCh := make(chan struct{}, 1)
-		go func() {
-			defer close(cancelCh)
-			<-time.After(config.ShutdownTimeout)
-		}()
-		ui.Say("Waiting for shutdown...")
-		if ok := driver.WaitForShutdown(cancelCh); ok {
-			log.Println("VM shut down.")
-			return multistep.ActionContinue
-		} else {
-			err := fmt.Errorf("Failed to shutdown")
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		}
+	step := &shutdowncommand.StepShutdown{
+		Shutdown: shutdowncommand.Shutdown{
+			Command:  config.ShutdownCommand,
+			Timeout:  config.ShutdownTimeout,
+			Disabled: state.Get("communicator") == nil,
+			Force:    config.ForceShutdown,
+		},
+		Machine: func(state multistep.StateBag) shutdowncommand.Machine {
+			return &vm{driver: state.Get("driver").(Driver)}
+		},
 	}
+	return step.Run(ctx, state)
+}
 
-	comm := state.Get("communicator").(packer.Communicator)
-	if config.ShutdownCommand != "" {
-		ui.Say("Gracefully halting virtual machine...")
-		log.Printf("Executing shutdown command: %s", config.ShutdownCommand)
-		cmd := &packer.RemoteCmd{Command: config.ShutdownCommand}
-		if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
-			err := fmt.Errorf("Failed to send shutdown command: %s", err)
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		}
-
-		// Start the goroutine that will time out our graceful attempt
-		cancelCh := make(chan struct{}, 1)
-		go func() {
-			defer close(cancelCh)
-			<-time.After(config.ShutdownTimeout)
-		}()
+func (s *stepShutdown) Cleanup(state multistep.StateBag) {}
 
-		log.Printf("Waiting max %s for shutdown to complete", config.ShutdownTimeout)
-		if ok := driver.WaitForShutdown(cancelCh); !ok {
-			err := errors.New("Timeout while waiting for machine to shut down.")
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		}
-	} else {
-		ui.Say("Halting the virtual machine...")
-		if err := driver.Stop(); err != nil {
-			err := fmt.Errorf("Error stopping VM: %s", err)
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		}
-	}
+// vm is the machine of the driver.
+type vm struct {
+	driver Driver
+}
 
-	log.Println("VM shut down.")
-	return multistep.ActionContinue
+// IsRunning waits shortly for the machine to shut down, the driver only
+// telling when it did.
+func (m *vm) IsRunning() (bool, error) {
+	cancelCh := make(chan struct{})
+	timer := time.AfterFunc(10*time.Millisecond, func() { close(cancelCh) })
+	defer timer.Stop()
+	return !m.driver.WaitForShutdown(cancelCh), nil
 }
 
-func (s *stepShutdown) Cleanup(state multistep.StateBag) {}
+func (m *vm) Stop() error { return m.driver.Stop() }
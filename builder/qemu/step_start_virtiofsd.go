@@ -0,0 +1,106 @@
+package qemu
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step starts a virtiofsd daemon for each shared directory using the
+// virtiofs driver.
+//
+// Uses:
+//   config *config
+//   ui     packer.Ui
+//
+// Produces:
+//   virtiofs_sockets map[string]string - the daemon sockets, by tag
+type stepStartVirtiofsd struct {
+	tempDir   string
+	processes []*exec.Cmd
+}
+
+func (s *stepStartVirtiofsd) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+
+	sockets := make(map[string]string)
+	for _, d := range config.SharedDirectories {
+		if d.Driver != "virtiofs" {
+			continue
+		}
+		if s.tempDir == "" {
+			// Socket paths are limited to about a hundred characters, so
+			// the sockets don't go into the output directory.
+			tempDir, err := ioutil.TempDir("", "packer-virtiofsd")
+			if err != nil {
+				err := fmt.Errorf("Error creating the virtiofsd socket directory: %s", err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			s.tempDir = tempDir
+		}
+
+		socketPath := filepath.Join(s.tempDir, d.Tag+".sock")
+		ui.Say(fmt.Sprintf("Starting virtiofsd sharing %s as %s...", d.HostPath, d.Tag))
+		cmd := exec.Command(config.VirtiofsdBinary,
+			"--socket-path="+socketPath, "-o", "source="+d.HostPath, "-o", "cache=auto")
+		log.Printf("Executing %s: %#v", config.VirtiofsdBinary, cmd.Args)
+		if err := cmd.Start(); err != nil {
+			err := fmt.Errorf("Error starting virtiofsd: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		s.processes = append(s.processes, cmd)
+
+		if err := waitForSocket(ctx, socketPath, 10*time.Second); err != nil {
+			err := fmt.Errorf("Error waiting for virtiofsd to share %s: %s", d.HostPath, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		sockets[d.Tag] = socketPath
+	}
+
+	state.Put("virtiofs_sockets", sockets)
+	return multistep.ActionContinue
+}
+
+func (s *stepStartVirtiofsd) Cleanup(state multistep.StateBag) {
+	for _, cmd := range s.processes {
+		if err := cmd.Process.Kill(); err != nil {
+			log.Printf("Error killing virtiofsd: %s", err)
+		}
+		cmd.Wait()
+	}
+	if s.tempDir != "" {
+		os.RemoveAll(s.tempDir)
+	}
+}
+
+// waitForSocket waits for the unix socket at path to be created.
+func waitForSocket(ctx context.Context, path string, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("timeout waiting for %s", path)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
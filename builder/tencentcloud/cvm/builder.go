@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -122,9 +123,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			SSHConfig: b.config.TencentCloudRunConfig.Comm.SSHConfigFunc(),
 			Host:      SSHHost(b.config.AssociatePublicIpAddress),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.TencentCloudRunConfig.Guest,
+			Comm:   &b.config.TencentCloudRunConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.TencentCloudRunConfig.Guest,
+			Comm:   &b.config.TencentCloudRunConfig.Comm,
+		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.TencentCloudRunConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.TencentCloudRunConfig.Guest,
+			Comm:   &b.config.TencentCloudRunConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.TencentCloudRunConfig.Guest,
+			Comm:   &b.config.TencentCloudRunConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.TencentCloudRunConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.TencentCloudRunConfig.Guest,
 		},
 		// We need this step to detach keypair from instance, otherwise
 		// it always fails to delete the key.
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/hcl2template"
 	"github.com/hashicorp/packer/helper/communicator"
@@ -95,6 +96,7 @@ type TencentCloudRunConfig struct {
 
 	// Communicator settings
 	Comm         communicator.Config `mapstructure:",squash"`
+	Guest        guest.Config        `mapstructure:",squash"`
 	SSHPrivateIp bool                `mapstructure:"ssh_private_ip"`
 }
 
@@ -111,6 +113,7 @@ func (cf *TencentCloudRunConfig) Prepare(ctx *interpolate.Context) []error {
 	}
 
 	errs := cf.Comm.Prepare(ctx)
+	errs = append(errs, cf.Guest.Prepare(&cf.Comm)...)
 	if cf.SourceImageId == "" && cf.SourceImageName == "" {
 		errs = append(errs, errors.New("source_image_id or source_image_name must be specified"))
 	}
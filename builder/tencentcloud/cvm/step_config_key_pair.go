@@ -97,7 +97,7 @@ func (s *stepConfigKeyPair) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	ctx := context.TODO()
+	ctx := multistep.CleanupContext(state)
 	client := state.Get("cvm_client").(*cvm.Client)
 
 	SayClean(state, "keypair")
@@ -111,7 +111,7 @@ func (s *stepConfigSecurityGroup) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	ctx := context.TODO()
+	ctx := multistep.CleanupContext(state)
 	vpcClient := state.Get("vpc_client").(*vpc.Client)
 
 	SayClean(state, "securitygroup")
@@ -76,7 +76,7 @@ func (s *stepConfigSubnet) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	ctx := context.TODO()
+	ctx := multistep.CleanupContext(state)
 	vpcClient := state.Get("vpc_client").(*vpc.Client)
 
 	SayClean(state, "subnet")
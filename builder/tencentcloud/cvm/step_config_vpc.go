@@ -68,7 +68,7 @@ func (s *stepConfigVPC) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	ctx := context.TODO()
+	ctx := multistep.CleanupContext(state)
 	vpcClient := state.Get("vpc_client").(*vpc.Client)
 
 	SayClean(state, "vpc")
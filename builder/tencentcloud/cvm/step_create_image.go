@@ -94,7 +94,7 @@ func (s *stepCreateImage) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	ctx := context.TODO()
+	ctx := multistep.CleanupContext(state)
 	client := state.Get("cvm_client").(*cvm.Client)
 
 	SayClean(state, "image")
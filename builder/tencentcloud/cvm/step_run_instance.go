@@ -205,7 +205,7 @@ func (s *stepRunInstance) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	ctx := context.TODO()
+	ctx := multistep.CleanupContext(state)
 	client := state.Get("cvm_client").(*cvm.Client)
 
 	SayClean(state, "instance")
@@ -52,7 +52,7 @@ func (s *stepShareImage) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	ctx := context.TODO()
+	ctx := multistep.CleanupContext(state)
 	client := state.Get("cvm_client").(*cvm.Client)
 
 	imageId := state.Get("image").(*cvm.Image).ImageId
@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -37,6 +38,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	errs = multierror.Append(errs, b.config.AccessConfig.Prepare(&b.config.ctx)...)
 	errs = multierror.Append(errs, b.config.SourceMachineConfig.Prepare(&b.config.ctx)...)
 	errs = multierror.Append(errs, b.config.Comm.Prepare(&b.config.ctx)...)
+	errs = multierror.Append(errs, b.config.Guest.Prepare(&b.config.Comm)...)
 	errs = multierror.Append(errs, b.config.TargetImageConfig.Prepare(&b.config.ctx)...)
 
 	// If we are using an SSH agent to sign requests, and no private key has been
@@ -70,9 +72,28 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Host:      commHost(b.config.Comm.Host()),
 			SSHConfig: b.config.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &config.Guest,
+			Comm:   &config.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &config.Guest,
+			Comm:   &config.Comm,
+		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &config.Comm,
+		&guest.StepRotateCredentials{
+			Config: &config.Guest,
+			Comm:   &config.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &config.Guest,
+			Comm:   &config.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &config.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &config.Guest,
 		},
 		&StepStopMachine{},
 		&StepCreateImageFromMachine{},
@@ -4,6 +4,7 @@ package triton
 
 import (
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/template/interpolate"
 )
@@ -14,7 +15,8 @@ type Config struct {
 	SourceMachineConfig `mapstructure:",squash"`
 	TargetImageConfig   `mapstructure:",squash"`
 
-	Comm communicator.Config `mapstructure:",squash"`
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
 
 	ctx interpolate.Context
 }
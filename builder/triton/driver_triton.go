@@ -3,10 +3,12 @@ package triton
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"sort"
 	"time"
 
+	"github.com/hashicorp/packer/common/wait"
 	"github.com/hashicorp/packer/packer"
 	"github.com/joyent/triton-go/compute"
 	terrors "github.com/joyent/triton-go/errors"
@@ -148,6 +150,7 @@ func (d *driverTriton) StopMachine(machineId string) error {
 // timeout, nil is returned.
 func (d *driverTriton) WaitForMachineState(machineId string, state string, timeout time.Duration) error {
 	return waitFor(
+		fmt.Sprintf("machine %s to become %s", machineId, state),
 		func() (bool, error) {
 			computeClient, _ := d.client.Compute()
 			machine, err := computeClient.Instances().Get(context.Background(), &compute.GetInstanceInput{
@@ -158,7 +161,6 @@ func (d *driverTriton) WaitForMachineState(machineId string, state string, timeo
 			}
 			return machine.State == state, err
 		},
-		3*time.Second,
 		timeout,
 	)
 }
@@ -168,6 +170,7 @@ func (d *driverTriton) WaitForMachineState(machineId string, state string, timeo
 // the machine has already been issued at this point.
 func (d *driverTriton) WaitForMachineDeletion(machineId string, timeout time.Duration) error {
 	return waitFor(
+		fmt.Sprintf("machine %s to be deleted", machineId),
 		func() (bool, error) {
 			computeClient, _ := d.client.Compute()
 			_, err := computeClient.Instances().Get(context.Background(), &compute.GetInstanceInput{
@@ -184,13 +187,13 @@ func (d *driverTriton) WaitForMachineDeletion(machineId string, timeout time.Dur
 
 			return false, err
 		},
-		3*time.Second,
 		timeout,
 	)
 }
 
 func (d *driverTriton) WaitForImageCreation(imageId string, timeout time.Duration) error {
 	return waitFor(
+		fmt.Sprintf("image %s to become active", imageId),
 		func() (bool, error) {
 			computeClient, _ := d.client.Compute()
 			image, err := computeClient.Images().Get(context.Background(), &compute.GetImageInput{
@@ -201,28 +204,20 @@ func (d *driverTriton) WaitForImageCreation(imageId string, timeout time.Duratio
 			}
 			return image.State == "active", err
 		},
-		3*time.Second,
 		timeout,
 	)
 }
 
-func waitFor(f func() (bool, error), every, timeout time.Duration) error {
-	start := time.Now()
-
-	for time.Since(start) <= timeout {
+func waitFor(description string, f func() (bool, error), timeout time.Duration) error {
+	return wait.Config{
+		Description: description,
+		Timeout:     timeout,
+		MinDelay:    3 * time.Second,
+		MaxDelay:    10 * time.Second,
+	}.Until(context.Background(), func(context.Context) (bool, string, error) {
 		stop, err := f()
-		if err != nil {
-			return err
-		}
-
-		if stop {
-			return nil
-		}
-
-		time.Sleep(every)
-	}
-
-	return errors.New("Timed out while waiting for resource change")
+		return stop, "", err
+	})
 }
 
 func mostRecentImages(images []*compute.Image) *compute.Image {
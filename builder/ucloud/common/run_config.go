@@ -6,6 +6,7 @@ import (
 	"os"
 	"regexp"
 
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/template/interpolate"
@@ -71,7 +72,8 @@ type RunConfig struct {
 	//        - `Amd/Epyc2` as the version of Amd CPU platform selected by system will be `Amd/Epyc2` and above;
 	MinCpuPlatform string `mapstructure:"min_cpu_platform" required:"false"`
 	// Communicator settings
-	Comm communicator.Config `mapstructure:",squash"`
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
 	// If this value is true, packer will connect to the created UHost instance via a private ip
 	// instead of allocating an EIP (elastic public ip).(Default: `false`).
 	UseSSHPrivateIp bool `mapstructure:"use_ssh_private_ip"`
@@ -81,6 +83,7 @@ var instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9\p{Han}-_.]{1,63}$`)
 
 func (c *RunConfig) Prepare(ctx *interpolate.Context) []error {
 	errs := c.Comm.Prepare(ctx)
+	errs = append(errs, c.Guest.Prepare(&c.Comm)...)
 
 	if c.Zone == "" {
 		errs = append(errs, fmt.Errorf("%q must be set", "availability_zone"))
@@ -137,7 +137,7 @@ func (s *stepCreateInstance) Cleanup(state multistep.StateBag) {
 	_, halted := state.GetOk(multistep.StateHalted)
 
 	ui := state.Get("ui").(packer.Ui)
-	ctx := context.TODO()
+	ctx := multistep.CleanupContext(state)
 
 	if cancelled || halted {
 		ui.Say("Deleting instance because of cancellation or error...")
@@ -16,6 +16,7 @@ import (
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -37,7 +38,8 @@ type Config struct {
 	common.FloppyConfig    `mapstructure:",squash"`
 	bootcommand.BootConfig `mapstructure:",squash"`
 
-	Comm communicator.Config `mapstructure:",squash"`
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
 	// The directory to create that will contain your output box. We always
 	// create this directory and run from inside of it to prevent Vagrant init
 	// collisions. If unset, it will be set to packer- plus your buildname.
@@ -182,6 +184,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 		errs = packer.MultiErrorAppend(errs,
 			fmt.Errorf(`The Vagrant builder currently only supports the ssh communicator"`))
 	}
+	errs = packer.MultiErrorAppend(errs, b.config.Guest.Prepare(&b.config.Comm)...)
 	// The box isn't a namespace like you'd pull from vagrant cloud
 	if b.config.BoxName == "" {
 		b.config.BoxName = fmt.Sprintf("packer_%s", b.config.PackerBuildName)
@@ -272,6 +275,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"local Vagrant box", "output directory"}
+}
+
 // Run executes a Packer build and returns a packer.Artifact representing
 // a VirtualBox appliance.
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
@@ -307,8 +314,8 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	}
 	steps = append(steps,
 		&common.StepOutputDir{
-			Force: b.config.PackerForce,
-			Path:  b.config.OutputDir,
+			OnConflict: b.config.OnConflict(),
+			Path:       b.config.OutputDir,
 		},
 		&StepCreateVagrantfile{
 			Template:     b.config.Template,
@@ -346,7 +353,29 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Host:      CommHost(),
 			SSHConfig: b.config.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
 		new(common.StepProvision),
+		&guest.StepRotateCredentials{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.Guest,
+			Comm:   &b.config.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.Guest,
+		},
 		&StepPackage{
 			SkipPackage: b.config.SkipPackage,
 			Include:     b.config.PackageInclude,
@@ -358,6 +387,11 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	b.runner = common.NewRunnerWithPauseFn(steps, b.config.PackerConfig, ui, state)
 	b.runner.Run(ctx, state)
 
+	// The artifact already exists and the build was skipped.
+	if common.Skipped(state) {
+		return nil, nil
+	}
+
 	// Report any errors.
 	if rawErr, ok := state.GetOk("error"); ok {
 		return nil, rawErr.(error)
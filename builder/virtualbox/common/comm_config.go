@@ -5,12 +5,14 @@ package common
 import (
 	"errors"
 
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
 type CommConfig struct {
-	Comm communicator.Config `mapstructure:",squash"`
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
 	// The minimum port to use for the Communicator port on the host machine which is forwarded
 	// to the SSH or WinRM port on the guest machine. By default this is 2222.
 	HostPortMin int `mapstructure:"host_port_min" required:"false"`
@@ -26,11 +28,11 @@ type CommConfig struct {
 
 	// These are deprecated, but we keep them around for backwards compatibility
 	// TODO: remove later
-	SSHHostPortMin int `mapstructure:"ssh_host_port_min" required:"false"`
+	SSHHostPortMin int `mapstructure:"ssh_host_port_min" required:"false" deprecated:"host_port_min"`
 	// TODO: remove later
-	SSHHostPortMax int `mapstructure:"ssh_host_port_max"`
+	SSHHostPortMax int `mapstructure:"ssh_host_port_max" deprecated:"host_port_max"`
 	// TODO: remove later
-	SSHSkipNatMapping bool `mapstructure:"ssh_skip_nat_mapping" required:"false"`
+	SSHSkipNatMapping bool `mapstructure:"ssh_skip_nat_mapping" required:"false" deprecated:"skip_nat_mapping"`
 }
 
 func (c *CommConfig) Prepare(ctx *interpolate.Context) []error {
@@ -63,6 +65,7 @@ func (c *CommConfig) Prepare(ctx *interpolate.Context) []error {
 	}
 
 	errs := c.Comm.Prepare(ctx)
+	errs = append(errs, c.Guest.Prepare(&c.Comm)...)
 	if c.HostPortMin > c.HostPortMax {
 		errs = append(errs,
 			errors.New("host_port_min must be less than host_port_max"))
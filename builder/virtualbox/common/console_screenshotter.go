@@ -0,0 +1,39 @@
+package common
+
+import (
+	"path/filepath"
+
+	"github.com/hashicorp/packer/common/consolerecord"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// consoleScreenshotter captures the console of the VM with
+// `VBoxManage controlvm screenshotpng`, which also works for headless VMs.
+type consoleScreenshotter struct {
+	driver Driver
+	vmName string
+}
+
+// NewConsoleScreenshotter returns a screenshotter for the console of the VM
+// started by StepRun.
+func NewConsoleScreenshotter(state multistep.StateBag) (consolerecord.Screenshotter, error) {
+	return &consoleScreenshotter{
+		driver: state.Get("driver").(Driver),
+		vmName: state.Get("vmName").(string),
+	}, nil
+}
+
+func (s *consoleScreenshotter) Screenshot(path string) error {
+	// The screenshot is written by the VirtualBox service, whose working
+	// directory is not the one of Packer.
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	return s.driver.VBoxManage("controlvm", s.vmName, "screenshotpng", path)
+}
+
+func (s *consoleScreenshotter) Close() error {
+	return nil
+}
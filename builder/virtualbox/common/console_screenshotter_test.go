@@ -0,0 +1,31 @@
+package common
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConsoleScreenshotter(t *testing.T) {
+	state := testState(t)
+	state.Put("vmName", "foo")
+	driver := state.Get("driver").(*DriverMock)
+
+	screenshotter, err := NewConsoleScreenshotter(state)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := screenshotter.Screenshot("console-00000.png"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	path, err := filepath.Abs("console-00000.png")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []string{"controlvm", "foo", "screenshotpng", path}
+	if len(driver.VBoxManageCalls) != 1 || !reflect.DeepEqual(driver.VBoxManageCalls[0], expected) {
+		t.Fatalf("bad: %#v", driver.VBoxManageCalls)
+	}
+}
@@ -37,6 +37,11 @@ type ShutdownConfig struct {
 	// If it's set to true, it will shutdown the VM via power button. It could be a good option
 	// when keeping the machine state is necessary after shutting it down.
 	ACPIShutdown bool `mapstructure:"acpi_shutdown" required:"false"`
+	// If the virtual machine doesn't shut down in `shutdown_timeout`, power
+	// it off forcefully instead of failing the build. The path taken to shut
+	// the machine down is available as the `ShutdownPath` build variable, and
+	// in the manifest. Defaults to false.
+	ForceShutdown bool `mapstructure:"force_shutdown" required:"false"`
 }
 
 func (c *ShutdownConfig) Prepare(ctx *interpolate.Context) []error {
@@ -2,17 +2,14 @@ package common
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
 	"time"
 
+	"github.com/hashicorp/packer/common/shutdowncommand"
 	"github.com/hashicorp/packer/helper/multistep"
-	"github.com/hashicorp/packer/packer"
 )
 
 // This step shuts down the machine. It first attempts to do so gracefully,
-// but ultimately forcefully shuts it down if that fails.
+// but ultimately forcefully shuts it down if that fails and Force is set.
 //
 // Uses:
 //   communicator packer.Communicator
@@ -21,82 +18,44 @@ import (
 //   vmName string
 //
 // Produces:
-//   <nothing>
+//   shutdown_path string
 type StepShutdown struct {
 	Command         string
 	Timeout         time.Duration
 	Delay           time.Duration
 	DisableShutdown bool
 	ACPIShutdown    bool
+	Force           bool
 }
 
 func (s *StepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
-	comm := state.Get("communicator").(packer.Communicator)
-	driver := state.Get("driver").(Driver)
-	ui := state.Get("ui").(packer.Ui)
-	vmName := state.Get("vmName").(string)
-
-	if s.ACPIShutdown {
-		ui.Say("Shuting down the virtual machine via ACPI power button...")
-		if err := driver.StopViaACPI(vmName); err != nil {
-			err := fmt.Errorf("Error stopping VM: %s", err)
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		}
-	} else if !s.DisableShutdown {
-		if s.Command != "" {
-			ui.Say("Gracefully halting virtual machine...")
-			log.Printf("Executing shutdown command: %s", s.Command)
-			cmd := &packer.RemoteCmd{Command: s.Command}
-			if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
-				err := fmt.Errorf("Failed to send shutdown command: %s", err)
-				state.Put("error", err)
-				ui.Error(err.Error())
-				return multistep.ActionHalt
-			}
-
-		} else {
-			ui.Say("Halting the virtual machine...")
-			if err := driver.Stop(vmName); err != nil {
-				err := fmt.Errorf("Error stopping VM: %s", err)
-				state.Put("error", err)
-				ui.Error(err.Error())
-				return multistep.ActionHalt
+	step := &shutdowncommand.StepShutdown{
+		Shutdown: shutdowncommand.Shutdown{
+			Command:  s.Command,
+			Timeout:  s.Timeout,
+			ACPI:     s.ACPIShutdown,
+			Disabled: s.DisableShutdown,
+			Force:    s.Force,
+		},
+		Machine: func(state multistep.StateBag) shutdowncommand.Machine {
+			return &vm{
+				driver: state.Get("driver").(Driver),
+				name:   state.Get("vmName").(string),
 			}
-		}
-	} else {
-		ui.Say("Automatic shutdown disabled. Please shutdown virtual machine.")
+		},
+		Delay: s.Delay,
 	}
+	return step.Run(ctx, state)
+}
 
-	// Wait for the machine to actually shut down
-	log.Printf("Waiting max %s for shutdown to complete", s.Timeout)
-	shutdownTimer := time.After(s.Timeout)
-	for {
-		running, _ := driver.IsRunning(vmName)
-		if !running {
-
-			if s.Delay.Nanoseconds() > 0 {
-				log.Printf("Delay for %s after shutdown to allow locks to clear...", s.Delay)
-				time.Sleep(s.Delay)
-			}
-
-			break
-		}
-
-		select {
-		case <-shutdownTimer:
-			err := errors.New("Timeout while waiting for machine to shutdown.")
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		default:
-			time.Sleep(500 * time.Millisecond)
-		}
-	}
+func (s *StepShutdown) Cleanup(state multistep.StateBag) {}
 
-	log.Println("VM shut down.")
-	return multistep.ActionContinue
+// vm is the machine of the driver named name.
+type vm struct {
+	driver Driver
+	name   string
 }
 
-func (s *StepShutdown) Cleanup(state multistep.StateBag) {}
+func (m *vm) IsRunning() (bool, error) { return m.driver.IsRunning(m.name) }
+func (m *vm) Stop() error              { return m.driver.Stop(m.name) }
+func (m *vm) StopViaACPI() error       { return m.driver.StopViaACPI(m.name) }
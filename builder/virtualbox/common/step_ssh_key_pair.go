@@ -17,7 +17,10 @@ import (
 type StepSshKeyPair struct {
 	Debug        bool
 	DebugKeyPath string
+	BuildName    string
 	Comm         *communicator.Config
+
+	shared *ssh.SharedKeyPair
 }
 
 func (s *StepSshKeyPair) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -57,24 +60,41 @@ func (s *StepSshKeyPair) Run(ctx context.Context, state multistep.StateBag) mult
 		return multistep.ActionContinue
 	}
 
-	ui.Say("Creating ephemeral key pair for SSH communicator...")
-
-	kp, err := ssh.NewKeyPair(ssh.CreateKeyPairConfig{
-		Comment: fmt.Sprintf("packer_%s", uuid.TimeOrderedUUID()),
-	})
+	shared, err := s.Comm.SharedTemporaryKeyPair(s.BuildName, s.Debug)
 	if err != nil {
 		state.Put("error", fmt.Errorf("Error creating temporary keypair: %s", err))
 		return multistep.ActionHalt
 	}
 
+	var kp ssh.KeyPair
+	if shared != nil {
+		ui.Say(fmt.Sprintf("Using shared ephemeral key pair %s for SSH communicator...", shared.Name))
+		kp, err = shared.Acquire("", s.keyPairUser(), nil)
+		if err != nil {
+			state.Put("error", fmt.Errorf("Error creating temporary keypair: %s", err))
+			return multistep.ActionHalt
+		}
+		s.shared = shared
+	} else {
+		ui.Say("Creating ephemeral key pair for SSH communicator...")
+
+		kp, err = ssh.NewKeyPair(ssh.CreateKeyPairConfig{
+			Comment: fmt.Sprintf("packer_%s", uuid.TimeOrderedUUID()),
+		})
+		if err != nil {
+			state.Put("error", fmt.Errorf("Error creating temporary keypair: %s", err))
+			return multistep.ActionHalt
+		}
+
+		ui.Say("Created ephemeral SSH key pair for communicator")
+	}
+
 	s.Comm.SSHKeyPairName = kp.Comment
 	s.Comm.SSHTemporaryKeyPairName = kp.Comment
 	s.Comm.SSHPrivateKey = kp.PrivateKeyPemBlock
 	s.Comm.SSHPublicKey = kp.PublicKeyAuthorizedKeysLine
 	s.Comm.SSHClearAuthorizedKeys = true
 
-	ui.Say("Created ephemeral SSH key pair for communicator")
-
 	// If we're in debug mode, output the private key to the working
 	// directory.
 	if s.Debug {
@@ -96,7 +116,20 @@ func (s *StepSshKeyPair) Run(ctx context.Context, state multistep.StateBag) mult
 	return multistep.ActionContinue
 }
 
+func (s *StepSshKeyPair) keyPairUser() string {
+	return fmt.Sprintf("%s_%d", s.BuildName, os.Getpid())
+}
+
 func (s *StepSshKeyPair) Cleanup(state multistep.StateBag) {
+	if s.shared != nil {
+		if err := s.shared.Release("", s.keyPairUser(), nil); err != nil {
+			ui := state.Get("ui").(packer.Ui)
+			ui.Error(fmt.Sprintf("Error releasing key pair %s: %s", s.shared.Name, err))
+		}
+		if s.shared.Persist {
+			return
+		}
+	}
 	if s.Debug {
 		if err := os.Remove(s.DebugKeyPath); err != nil {
 			ui := state.Get("ui").(packer.Ui)
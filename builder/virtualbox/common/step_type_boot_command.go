@@ -37,6 +37,7 @@ type StepTypeBootCommand struct {
 	Ctx           interpolate.Context
 	GroupInterval time.Duration
 	Comm          *communicator.Config
+	Keymap        string
 }
 
 func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -76,7 +77,13 @@ func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag)
 
 		return driver.VBoxManage(args...)
 	}
-	d := bootcommand.NewPCXTDriver(sendCodes, 25, s.GroupInterval)
+	d, err := bootcommand.NewKeymapDriver(bootcommand.NewPCXTDriver(sendCodes, 25, s.GroupInterval), s.Keymap)
+	if err != nil {
+		err := fmt.Errorf("Error preparing boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
 
 	ui.Say("Typing the boot command...")
 	command, err := interpolate.Render(s.BootCommand, &s.Ctx)
@@ -13,6 +13,8 @@ import (
 	vboxcommon "github.com/hashicorp/packer/builder/virtualbox/common"
 	"github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/common/consolerecord"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -37,6 +39,7 @@ type Config struct {
 	vboxcommon.OutputConfig         `mapstructure:",squash"`
 	vboxcommon.RunConfig            `mapstructure:",squash"`
 	vboxcommon.ShutdownConfig       `mapstructure:",squash"`
+	consolerecord.RecordConfig      `mapstructure:",squash"`
 	vboxcommon.CommConfig           `mapstructure:",squash"`
 	vboxcommon.HWConfig             `mapstructure:",squash"`
 	vboxcommon.VBoxManageConfig     `mapstructure:",squash"`
@@ -164,6 +167,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	errs = packer.MultiErrorAppend(errs, b.config.HTTPConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.RunConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.ShutdownConfig.Prepare(&b.config.ctx)...)
+	errs = packer.MultiErrorAppend(errs, b.config.RecordConfig.Prepare(&b.config.ctx, &b.config.PackerConfig)...)
 	errs = packer.MultiErrorAppend(errs, b.config.CommConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.HWConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.VBoxBundleConfig.Prepare(&b.config.ctx)...)
@@ -273,6 +277,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"local virtual machine", "output directory"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	// Create the driver that we'll use to communicate with VirtualBox
 	driver, err := vboxcommon.NewDriver()
@@ -296,8 +304,8 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Url:         b.config.ISOUrls,
 		},
 		&common.StepOutputDir{
-			Force: b.config.PackerForce,
-			Path:  b.config.OutputDir,
+			OnConflict: b.config.OnConflict(),
+			Path:       b.config.OutputDir,
 		},
 		&common.StepCreateFloppy{
 			Files:       b.config.FloppyConfig.FloppyFiles,
@@ -314,6 +322,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		&vboxcommon.StepSshKeyPair{
 			Debug:        b.config.PackerDebug,
 			DebugKeyPath: fmt.Sprintf("%s.pem", b.config.PackerBuildName),
+			BuildName:    b.config.PackerBuildName,
 			Comm:         &b.config.Comm,
 		},
 		new(vboxcommon.StepSuppressMessages),
@@ -343,12 +352,17 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		&vboxcommon.StepRun{
 			Headless: b.config.Headless,
 		},
+		&consolerecord.StepRecordConsole{
+			Config:           &b.config.RecordConfig,
+			NewScreenshotter: vboxcommon.NewConsoleScreenshotter,
+		},
 		&vboxcommon.StepTypeBootCommand{
 			BootWait:      b.config.BootWait,
 			BootCommand:   b.config.FlatBootCommand(),
 			VMName:        b.config.VMName,
 			Ctx:           b.config.ctx,
 			GroupInterval: b.config.BootConfig.BootGroupInterval,
+			Keymap:        b.config.BootConfig.BootKeymap,
 			Comm:          &b.config.Comm,
 		},
 		&communicator.StepConnect{
@@ -358,6 +372,14 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			SSHPort:   vboxcommon.CommPort,
 			WinRMPort: vboxcommon.CommPort,
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
 		&vboxcommon.StepUploadVersion{
 			Path: *b.config.VBoxVersionFile,
 		},
@@ -367,8 +389,19 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Ctx:                b.config.ctx,
 		},
 		new(common.StepProvision),
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.CommConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.CommConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.CommConfig.Guest,
 		},
 		&vboxcommon.StepShutdown{
 			Command:         b.config.ShutdownCommand,
@@ -376,6 +409,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Delay:           b.config.PostShutdownDelay,
 			DisableShutdown: b.config.DisableShutdown,
 			ACPIShutdown:    b.config.ACPIShutdown,
+			Force:           b.config.ForceShutdown,
 		},
 		&vboxcommon.StepRemoveDevices{
 			Bundling:                b.config.VBoxBundleConfig,
@@ -408,6 +442,11 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	b.runner = common.NewRunnerWithPauseFn(steps, b.config.PackerConfig, ui, state)
 	b.runner.Run(ctx, state)
 
+	// The artifact already exists and the build was skipped.
+	if common.Skipped(state) {
+		return nil, nil
+	}
+
 	// If there was an error, return that
 	if rawErr, ok := state.GetOk("error"); ok {
 		return nil, rawErr.(error)
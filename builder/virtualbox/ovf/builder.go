@@ -8,6 +8,8 @@ import (
 	"github.com/hashicorp/hcl/v2/hcldec"
 	vboxcommon "github.com/hashicorp/packer/builder/virtualbox/common"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/consolerecord"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -31,6 +33,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"local virtual machine", "output directory"}
+}
+
 // Run executes a Packer build and returns a packer.Artifact representing
 // a VirtualBox appliance.
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
@@ -51,8 +57,8 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	// Build the steps.
 	steps := []multistep.Step{
 		&common.StepOutputDir{
-			Force: b.config.PackerForce,
-			Path:  b.config.OutputDir,
+			OnConflict: b.config.OnConflict(),
+			Path:       b.config.OutputDir,
 		},
 		new(vboxcommon.StepSuppressMessages),
 		&common.StepCreateFloppy{
@@ -70,6 +76,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		&vboxcommon.StepSshKeyPair{
 			Debug:        b.config.PackerDebug,
 			DebugKeyPath: fmt.Sprintf("%s.pem", b.config.PackerBuildName),
+			BuildName:    b.config.PackerBuildName,
 			Comm:         &b.config.Comm,
 		},
 		&vboxcommon.StepDownloadGuestAdditions{
@@ -114,12 +121,17 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		&vboxcommon.StepRun{
 			Headless: b.config.Headless,
 		},
+		&consolerecord.StepRecordConsole{
+			Config:           &b.config.RecordConfig,
+			NewScreenshotter: vboxcommon.NewConsoleScreenshotter,
+		},
 		&vboxcommon.StepTypeBootCommand{
 			BootWait:      b.config.BootWait,
 			BootCommand:   b.config.FlatBootCommand(),
 			VMName:        b.config.VMName,
 			Ctx:           b.config.ctx,
 			GroupInterval: b.config.BootConfig.BootGroupInterval,
+			Keymap:        b.config.BootConfig.BootKeymap,
 			Comm:          &b.config.Comm,
 		},
 		&communicator.StepConnect{
@@ -129,6 +141,14 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			SSHPort:   vboxcommon.CommPort,
 			WinRMPort: vboxcommon.CommPort,
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
 		&vboxcommon.StepUploadVersion{
 			Path: *b.config.VBoxVersionFile,
 		},
@@ -138,8 +158,19 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Ctx:                b.config.ctx,
 		},
 		new(common.StepProvision),
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.CommConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.CommConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.CommConfig.Guest,
 		},
 		&vboxcommon.StepShutdown{
 			Command:         b.config.ShutdownCommand,
@@ -147,6 +178,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Delay:           b.config.PostShutdownDelay,
 			DisableShutdown: b.config.DisableShutdown,
 			ACPIShutdown:    b.config.ACPIShutdown,
+			Force:           b.config.ForceShutdown,
 		},
 		&vboxcommon.StepRemoveDevices{
 			GuestAdditionsInterface: b.config.GuestAdditionsInterface,
@@ -169,6 +201,11 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	b.runner = common.NewRunnerWithPauseFn(steps, b.config.PackerConfig, ui, state)
 	b.runner.Run(ctx, state)
 
+	// The artifact already exists and the build was skipped.
+	if common.Skipped(state) {
+		return nil, nil
+	}
+
 	// Report any errors.
 	if rawErr, ok := state.GetOk("error"); ok {
 		return nil, rawErr.(error)
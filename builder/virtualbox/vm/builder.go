@@ -8,6 +8,8 @@ import (
 	"github.com/hashicorp/hcl/v2/hcldec"
 	vboxcommon "github.com/hashicorp/packer/builder/virtualbox/common"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/consolerecord"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -31,6 +33,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"snapshot of an existing virtual machine"}
+}
+
 // Run executes a Packer build and returns a packer.Artifact representing
 // a VirtualBox appliance.
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
@@ -97,12 +103,17 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		&vboxcommon.StepRun{
 			Headless: b.config.Headless,
 		},
+		&consolerecord.StepRecordConsole{
+			Config:           &b.config.RecordConfig,
+			NewScreenshotter: vboxcommon.NewConsoleScreenshotter,
+		},
 		&vboxcommon.StepTypeBootCommand{
 			BootWait:      b.config.BootWait,
 			BootCommand:   b.config.FlatBootCommand(),
 			VMName:        b.config.VMName,
 			Ctx:           b.config.ctx,
 			GroupInterval: b.config.BootConfig.BootGroupInterval,
+			Keymap:        b.config.BootConfig.BootKeymap,
 			Comm:          &b.config.Comm,
 		},
 		&communicator.StepConnect{
@@ -112,6 +123,14 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			SSHPort:   vboxcommon.CommPort,
 			WinRMPort: vboxcommon.CommPort,
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
 		&vboxcommon.StepUploadVersion{
 			Path: *b.config.VBoxVersionFile,
 		},
@@ -121,8 +140,19 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Ctx:                b.config.ctx,
 		},
 		new(common.StepProvision),
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.CommConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.CommConfig.Guest,
+			Comm:   &b.config.CommConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.CommConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.CommConfig.Guest,
 		},
 		&vboxcommon.StepShutdown{
 			Command:         b.config.ShutdownCommand,
@@ -130,6 +160,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Delay:           b.config.PostShutdownDelay,
 			DisableShutdown: b.config.DisableShutdown,
 			ACPIShutdown:    b.config.ACPIShutdown,
+			Force:           b.config.ForceShutdown,
 		},
 		&vboxcommon.StepVBoxManage{
 			Commands: b.config.VBoxManagePost,
@@ -153,14 +184,19 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		steps = append(steps, nil)
 		copy(steps[1:], steps)
 		steps[0] = &common.StepOutputDir{
-			Force: b.config.PackerForce,
-			Path:  b.config.OutputDir,
+			OnConflict: b.config.OnConflict(),
+			Path:       b.config.OutputDir,
 		}
 	}
 	// Run the steps.
 	b.runner = common.NewRunnerWithPauseFn(steps, b.config.PackerConfig, ui, state)
 	b.runner.Run(ctx, state)
 
+	// The artifact already exists and the build was skipped.
+	if common.Skipped(state) {
+		return nil, nil
+	}
+
 	// Report any errors.
 	if rawErr, ok := state.GetOk("error"); ok {
 		return nil, rawErr.(error)
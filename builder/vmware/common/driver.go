@@ -22,8 +22,9 @@ import (
 type Driver interface {
 	// Clone clones the VMX and the disk to the destination path. The
 	// destination is a path to the VMX file. The disk will be copied
-	// to that same directory.
-	Clone(dst string, src string, cloneType bool) error
+	// to that same directory. If snapshot is not empty, the clone is
+	// made from that snapshot of the source instead of its current state.
+	Clone(dst string, src string, cloneType bool, snapshot string) error
 
 	// CompactDisk compacts a virtual disk.
 	CompactDisk(string) error
@@ -48,7 +48,10 @@ type ESX5Driver struct {
 	vmId      string
 }
 
-func (d *ESX5Driver) Clone(dst, src string, linked bool) error {
+func (d *ESX5Driver) Clone(dst, src string, linked bool, snapshot string) error {
+	if snapshot != "" {
+		return fmt.Errorf("Cloning from a snapshot is not supported with remote_type esx5")
+	}
 
 	linesToArray := func(lines string) []string { return strings.Split(strings.Trim(lines, "\r\n"), "\n") }
 
@@ -18,7 +18,7 @@ type Fusion6Driver struct {
 	Fusion5Driver
 }
 
-func (d *Fusion6Driver) Clone(dst, src string, linked bool) error {
+func (d *Fusion6Driver) Clone(dst, src string, linked bool, snapshot string) error {
 
 	var cloneType string
 	if linked {
@@ -27,10 +27,12 @@ func (d *Fusion6Driver) Clone(dst, src string, linked bool) error {
 		cloneType = "full"
 	}
 
-	cmd := exec.Command(d.vmrunPath(),
-		"-T", "fusion",
-		"clone", src, dst,
-		cloneType)
+	args := []string{"-T", "fusion", "clone", src, dst, cloneType}
+	if snapshot != "" {
+		args = append(args, "-snapshot="+snapshot)
+	}
+
+	cmd := exec.Command(d.vmrunPath(), args...)
 	if _, _, err := runAndLog(cmd); err != nil {
 		if strings.Contains(err.Error(), "parameters was invalid") {
 			return fmt.Errorf(
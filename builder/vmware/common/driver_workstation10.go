@@ -13,7 +13,7 @@ type Workstation10Driver struct {
 	Workstation9Driver
 }
 
-func (d *Workstation10Driver) Clone(dst, src string, linked bool) error {
+func (d *Workstation10Driver) Clone(dst, src string, linked bool, snapshot string) error {
 
 	var cloneType string
 	if linked {
@@ -22,10 +22,12 @@ func (d *Workstation10Driver) Clone(dst, src string, linked bool) error {
 		cloneType = "full"
 	}
 
-	cmd := exec.Command(d.Workstation9Driver.VmrunPath,
-		"-T", "ws",
-		"clone", src, dst,
-		cloneType)
+	args := []string{"-T", "ws", "clone", src, dst, cloneType}
+	if snapshot != "" {
+		args = append(args, "-snapshot="+snapshot)
+	}
+
+	cmd := exec.Command(d.Workstation9Driver.VmrunPath, args...)
 
 	if _, _, err := runAndLog(cmd); err != nil {
 		return err
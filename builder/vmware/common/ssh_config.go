@@ -3,12 +3,14 @@
 package common
 
 import (
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
 type SSHConfig struct {
-	Comm communicator.Config `mapstructure:",squash"`
+	Comm  communicator.Config `mapstructure:",squash"`
+	Guest guest.Config        `mapstructure:",squash"`
 
 	// These are deprecated, but we keep them around for BC
 	// TODO(@mitchellh): remove
@@ -20,5 +22,7 @@ func (c *SSHConfig) Prepare(ctx *interpolate.Context) []error {
 		c.Comm.SSHPty = false
 	}
 
-	return c.Comm.Prepare(ctx)
+	errs := c.Comm.Prepare(ctx)
+	errs = append(errs, c.Guest.Prepare(&c.Comm)...)
+	return errs
 }
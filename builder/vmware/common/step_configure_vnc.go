@@ -6,6 +6,7 @@ import (
 	"log"
 	"math/rand"
 
+	"github.com/hashicorp/packer/common/consolerecord"
 	"github.com/hashicorp/packer/common/net"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -140,3 +141,16 @@ func (s *StepConfigureVNC) Cleanup(multistep.StateBag) {
 		}
 	}
 }
+
+// NewVNCScreenshotter returns a screenshotter for the VNC server configured by
+// StepConfigureVNC.
+func NewVNCScreenshotter(state multistep.StateBag) (consolerecord.Screenshotter, error) {
+	vncIp := state.Get("vnc_ip").(string)
+	vncPort := state.Get("vnc_port").(int)
+	vncPassword := state.Get("vnc_password").(string)
+
+	return &consolerecord.VNCScreenshotter{
+		Address:  fmt.Sprintf("%s:%d", vncIp, vncPort),
+		Password: vncPassword,
+	}, nil
+}
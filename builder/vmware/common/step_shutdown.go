@@ -1,21 +1,19 @@
 package common
 
 import (
-	"bytes"
 	"context"
-	"errors"
-	"fmt"
 	"log"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/packer/common/shutdowncommand"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
 
 // This step shuts down the machine. It first attempts to do so gracefully,
-// but ultimately forcefully shuts it down if that fails.
+// but ultimately forcefully shuts it down if that fails and Force is set.
 //
 // Uses:
 //   communicator packer.Communicator
@@ -25,69 +23,36 @@ import (
 //   vmx_path string
 //
 // Produces:
-//   <nothing>
+//   shutdown_path string
 type StepShutdown struct {
 	Command string
 	Timeout time.Duration
+	Force   bool
 
 	// Set this to true if we're testing
 	Testing bool
 }
 
 func (s *StepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
-	comm := state.Get("communicator").(packer.Communicator)
+	comm, _ := state.Get("communicator").(packer.Communicator)
 	dir := state.Get("dir").(OutputDir)
 	driver := state.Get("driver").(Driver)
 	ui := state.Get("ui").(packer.Ui)
 	vmxPath := state.Get("vmx_path").(string)
 
-	if s.Command != "" {
-		ui.Say("Gracefully halting virtual machine...")
-		log.Printf("Executing shutdown command: %s", s.Command)
-
-		var stdout, stderr bytes.Buffer
-		cmd := &packer.RemoteCmd{
-			Command: s.Command,
-			Stdout:  &stdout,
-			Stderr:  &stderr,
-		}
-		if err := comm.Start(ctx, cmd); err != nil {
-			err := fmt.Errorf("Failed to send shutdown command: %s", err)
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		}
-
-		// Wait for the machine to actually shut down
-		log.Printf("Waiting max %s for shutdown to complete", s.Timeout)
-		shutdownTimer := time.After(s.Timeout)
-		for {
-			running, _ := driver.IsRunning(vmxPath)
-			if !running {
-				break
-			}
-
-			select {
-			case <-shutdownTimer:
-				log.Printf("Shutdown stdout: %s", stdout.String())
-				log.Printf("Shutdown stderr: %s", stderr.String())
-				err := errors.New("Timeout while waiting for machine to shut down.")
-				state.Put("error", err)
-				ui.Error(err.Error())
-				return multistep.ActionHalt
-			default:
-				time.Sleep(150 * time.Millisecond)
-			}
-		}
-	} else {
-		ui.Say("Forcibly halting virtual machine...")
-		if err := driver.Stop(vmxPath); err != nil {
-			err := fmt.Errorf("Error stopping VM: %s", err)
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
-		}
+	shutdown := &shutdowncommand.Shutdown{
+		Command:      s.Command,
+		Timeout:      s.Timeout,
+		Force:        s.Force,
+		PollInterval: 150 * time.Millisecond,
+	}
+	path, err := shutdown.Run(ctx, ui, comm, &vm{driver: driver, vmxPath: vmxPath})
+	if err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
 	}
+	shutdowncommand.PutPath(state, path)
 
 	ui.Message("Waiting for VMware to clean up after itself...")
 	lockRegex := regexp.MustCompile(`(?i)\.lck$`)
@@ -137,8 +102,16 @@ LockWaitLoop:
 		time.Sleep(5 * time.Second)
 	}
 
-	log.Println("VM shut down.")
 	return multistep.ActionContinue
 }
 
 func (s *StepShutdown) Cleanup(state multistep.StateBag) {}
+
+// vm is the machine of the driver at vmxPath.
+type vm struct {
+	driver  Driver
+	vmxPath string
+}
+
+func (m *vm) IsRunning() (bool, error) { return m.driver.IsRunning(m.vmxPath) }
+func (m *vm) Stop() error              { return m.driver.Stop(m.vmxPath) }
@@ -30,6 +30,7 @@ type StepTypeBootCommand struct {
 	VMName      string
 	Ctx         interpolate.Context
 	KeyInterval time.Duration
+	Keymap      string
 }
 type bootCommandTemplateData struct {
 	HTTPIP   string
@@ -104,7 +105,13 @@ func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag)
 		s.VMName,
 	}
 
-	d := bootcommand.NewVNCDriver(c, s.KeyInterval)
+	d, err := bootcommand.NewKeymapDriver(bootcommand.NewVNCDriver(c, s.KeyInterval), s.Keymap)
+	if err != nil {
+		err := fmt.Errorf("Error preparing boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
 
 	ui.Say("Typing the boot command over VNC...")
 	command, err := interpolate.Render(s.BootCommand, &s.Ctx)
@@ -9,6 +9,8 @@ import (
 	"github.com/hashicorp/hcl/v2/hcldec"
 	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/consolerecord"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -30,6 +32,10 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	return nil, warnings, nil
 }
 
+func (b *Builder) PlannedResources() []string {
+	return []string{"local or ESXi virtual machine", "output directory"}
+}
+
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	driver, err := vmwcommon.NewDriver(&b.config.DriverConfig, &b.config.SSHConfig, b.config.VMName)
 	if err != nil {
@@ -130,6 +136,10 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			DurationBeforeStop: 5 * time.Second,
 			Headless:           b.config.Headless,
 		},
+		&consolerecord.StepRecordConsole{
+			Config:           &b.config.RecordConfig,
+			NewScreenshotter: vmwcommon.NewVNCScreenshotter,
+		},
 		&vmwcommon.StepTypeBootCommand{
 			BootWait:    b.config.BootWait,
 			VNCEnabled:  !b.config.DisableVNC,
@@ -137,12 +147,21 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			VMName:      b.config.VMName,
 			Ctx:         b.config.ctx,
 			KeyInterval: b.config.VNCConfig.BootKeyInterval,
+			Keymap:      b.config.VNCConfig.BootKeymap,
 		},
 		&communicator.StepConnect{
 			Config:    &b.config.SSHConfig.Comm,
 			Host:      driver.CommHost,
 			SSHConfig: b.config.SSHConfig.Comm.SSHConfigFunc(),
 		},
+		&guest.StepCheckClock{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
+		&guest.StepEnvironment{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
 		&vmwcommon.StepUploadTools{
 			RemoteType:        b.config.RemoteType,
 			ToolsUploadFlavor: b.config.ToolsUploadFlavor,
@@ -150,12 +169,24 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			Ctx:               b.config.ctx,
 		},
 		&common.StepProvision{},
-		&common.StepCleanupTempKeys{
-			Comm: &b.config.SSHConfig.Comm,
+		&guest.StepRotateCredentials{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
+		&guest.StepCleanup{
+			Config: &b.config.SSHConfig.Guest,
+			Comm:   &b.config.SSHConfig.Comm,
+		},
+		&guest.StepLinuxGeneralize{
+			Config: &b.config.SSHConfig.Guest,
+		},
+		&guest.StepSysprep{
+			Config: &b.config.SSHConfig.Guest,
 		},
 		&vmwcommon.StepShutdown{
 			Command: b.config.ShutdownCommand,
 			Timeout: b.config.ShutdownTimeout,
+			Force:   b.config.ForceShutdown,
 		},
 		&vmwcommon.StepCleanFiles{},
 		&vmwcommon.StepCompactDisk{
@@ -448,6 +448,32 @@ func TestBuilderPrepare_VNCPort(t *testing.T) {
 	}
 }
 
+func TestBuilderPrepare_RecordConsole(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Good
+	config["record_console"] = true
+	_, warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.RecordConsoleDirectory != "console-foo" {
+		t.Fatalf("bad: %s", b.config.RecordConsoleDirectory)
+	}
+
+	// Bad
+	config["disable_vnc"] = true
+	b = Builder{}
+	_, _, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
 func TestBuilderCheckCollisions(t *testing.T) {
 	config := testConfig()
 	config["vmx_data"] = map[string]string{
@@ -12,6 +12,7 @@ import (
 	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
 	"github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/common/consolerecord"
 	"github.com/hashicorp/packer/common/shutdowncommand"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
@@ -29,6 +30,7 @@ type Config struct {
 	vmwcommon.OutputConfig         `mapstructure:",squash"`
 	vmwcommon.RunConfig            `mapstructure:",squash"`
 	shutdowncommand.ShutdownConfig `mapstructure:",squash"`
+	consolerecord.RecordConfig     `mapstructure:",squash"`
 	vmwcommon.SSHConfig            `mapstructure:",squash"`
 	vmwcommon.ToolsConfig          `mapstructure:",squash"`
 	vmwcommon.VMXConfig            `mapstructure:",squash"`
@@ -154,6 +156,7 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	errs = packer.MultiErrorAppend(errs, c.VMXConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.FloppyConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.VNCConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.RecordConfig.Prepare(&c.ctx, &c.PackerConfig)...)
 	errs = packer.MultiErrorAppend(errs, c.ExportConfig.Prepare(&c.ctx)...)
 
 	if c.DiskName == "" {
@@ -253,6 +256,11 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		errs = packer.MultiErrorAppend(errs, err)
 	}
 
+	if c.RecordConsole && c.DisableVNC {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("record_console requires VNC, it can't be used with disable_vnc"))
+	}
+
 	// Warnings
 	if c.ShutdownCommand == "" {
 		warnings = append(warnings,
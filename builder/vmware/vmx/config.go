@@ -10,6 +10,7 @@ import (
 	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
 	"github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/common/consolerecord"
 	"github.com/hashicorp/packer/common/shutdowncommand"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
@@ -26,6 +27,7 @@ type Config struct {
 	vmwcommon.OutputConfig         `mapstructure:",squash"`
 	vmwcommon.RunConfig            `mapstructure:",squash"`
 	shutdowncommand.ShutdownConfig `mapstructure:",squash"`
+	consolerecord.RecordConfig     `mapstructure:",squash"`
 	vmwcommon.SSHConfig            `mapstructure:",squash"`
 	vmwcommon.ToolsConfig          `mapstructure:",squash"`
 	vmwcommon.VMXConfig            `mapstructure:",squash"`
@@ -47,6 +49,12 @@ type Config struct {
 	// Path to the source VMX file to clone. If
 	// remote_type is enabled then this specifies a path on the remote_host.
 	SourcePath string `mapstructure:"source_path" required:"true"`
+	// The name of a snapshot of the source virtual machine to clone from,
+	// instead of its current state. Combined with linked, this lets a
+	// "golden" virtual machine be kept at a known snapshot while builds that
+	// only iterate on provisioning start from it in seconds. This is not
+	// supported with remote_type esx5.
+	SourceSnapshot string `mapstructure:"source_snapshot" required:"false"`
 	// This is the name of the VMX file for the new virtual
 	// machine, without the file extension. By default this is packer-BUILDNAME,
 	// where "BUILDNAME" is the name of the build.
@@ -88,6 +96,7 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	errs = packer.MultiErrorAppend(errs, c.VMXConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.FloppyConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.VNCConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.RecordConfig.Prepare(&c.ctx, &c.PackerConfig)...)
 	errs = packer.MultiErrorAppend(errs, c.ExportConfig.Prepare(&c.ctx)...)
 
 	if c.RemoteType == "" {
@@ -110,6 +119,11 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 			errs = packer.MultiErrorAppend(errs,
 				fmt.Errorf("Only 'esx5' value is accepted for remote_type"))
 		}
+
+		if c.SourceSnapshot != "" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("source_snapshot is not supported with remote_type"))
+		}
 	}
 
 	err = c.DriverConfig.Validate(c.SkipExport)
@@ -131,6 +145,11 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 			fmt.Errorf("format must be one of ova, ovf, or vmx"))
 	}
 
+	if c.RecordConsole && c.DisableVNC {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("record_console requires VNC, it can't be used with disable_vnc"))
+	}
+
 	// Warnings
 	var warnings []string
 	if c.ShutdownCommand == "" {
@@ -58,3 +58,25 @@ func TestNewConfig_sourcePath(t *testing.T) {
 	warns, errs = (&Config{}).Prepare(cfg)
 	testConfigOk(t, warns, errs)
 }
+
+func TestNewConfig_sourceSnapshot(t *testing.T) {
+	// Good
+	cfg := testConfig(t)
+	cfg["linked"] = true
+	cfg["source_snapshot"] = "golden"
+	warns, errs := (&Config{}).Prepare(cfg)
+	testConfigOk(t, warns, errs)
+
+	// Good
+	delete(cfg, "source_snapshot")
+	cfg["remote_type"] = "esx5"
+	cfg["remote_host"] = "esx.example.com"
+	cfg["skip_export"] = true
+	warns, errs = (&Config{}).Prepare(cfg)
+	testConfigOk(t, warns, errs)
+
+	// Bad
+	cfg["source_snapshot"] = "golden"
+	warns, errs = (&Config{}).Prepare(cfg)
+	testConfigErr(t, warns, errs)
+}
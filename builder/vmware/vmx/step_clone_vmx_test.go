@@ -65,6 +65,8 @@ func TestStepCloneVMX(t *testing.T) {
 	step.OutputDir = td
 	step.Path = sourcePath
 	step.VMName = "foo"
+	step.Linked = true
+	step.Snapshot = "golden"
 
 	driver := state.Get("driver").(*vmwcommon.DriverMock)
 
@@ -80,6 +82,9 @@ func TestStepCloneVMX(t *testing.T) {
 	if !driver.CloneCalled {
 		t.Fatal("should call clone")
 	}
+	if !driver.Linked || driver.Snapshot != "golden" {
+		t.Fatalf("bad clone: linked %t, snapshot %q", driver.Linked, driver.Snapshot)
+	}
 
 	// Test that we have our paths
 	if vmxPath, ok := state.GetOk("vmx_path"); !ok {
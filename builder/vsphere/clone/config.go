@@ -6,6 +6,7 @@ package clone
 import (
 	"github.com/hashicorp/packer/builder/vsphere/common"
 	packerCommon "github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
@@ -26,6 +27,7 @@ type Config struct {
 	common.BootConfig     `mapstructure:",squash"`
 	common.WaitIpConfig   `mapstructure:",squash"`
 	Comm                  communicator.Config `mapstructure:",squash"`
+	Guest                 guest.Config        `mapstructure:",squash"`
 	common.ShutdownConfig `mapstructure:",squash"`
 
 	// Create a snapshot when set to `true`, so the VM can be used as a base
@@ -70,6 +72,7 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	errs = packer.MultiErrorAppend(errs, c.BootConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.WaitIpConfig.Prepare()...)
 	errs = packer.MultiErrorAppend(errs, c.Comm.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.Guest.Prepare(&c.Comm)...)
 	errs = packer.MultiErrorAppend(errs, c.ShutdownConfig.Prepare()...)
 	if c.Export != nil {
 		errs = packer.MultiErrorAppend(errs, c.Export.Prepare(&c.ctx, &c.LocationConfig, &c.PackerConfig)...)
@@ -76,11 +76,13 @@ func (s *StepBootCommand) Run(ctx context.Context, state multistep.StateBag) mul
 		ui.Say(fmt.Sprintf("HTTP server is working at http://%v:%v/", ip, port))
 	}
 
-	var keyAlt, keyCtrl, keyShift bool
+	var keyAlt, keyAltGr, keyCtrl, keyShift bool
 	sendCodes := func(code key.Code, down bool) error {
 		switch code {
 		case key.CodeLeftAlt:
 			keyAlt = down
+		case key.CodeRightAlt:
+			keyAltGr = down
 		case key.CodeLeftControl:
 			keyCtrl = down
 		case key.CodeLeftShift:
@@ -91,6 +93,7 @@ func (s *StepBootCommand) Run(ctx context.Context, state multistep.StateBag) mul
 			Scancode: code,
 			Ctrl:     keyCtrl,
 			Alt:      keyAlt,
+			AltGr:    keyAltGr,
 			Shift:    keyShift,
 		})
 		if err != nil {
@@ -98,7 +101,13 @@ func (s *StepBootCommand) Run(ctx context.Context, state multistep.StateBag) mul
 		}
 		return nil
 	}
-	d := bootcommand.NewUSBDriver(sendCodes, s.Config.BootGroupInterval)
+	d, err := bootcommand.NewKeymapDriver(bootcommand.NewUSBDriver(sendCodes, s.Config.BootGroupInterval), s.Config.BootKeymap)
+	if err != nil {
+		err := fmt.Errorf("Error preparing boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
 
 	ui.Say("Typing boot command...")
 	flatBootCommand := s.Config.FlatBootCommand()
@@ -11,13 +11,13 @@ import (
 // FlatExportConfig is an auto-generated flat version of ExportConfig.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatExportConfig struct {
-	Name      *string      `mapstructure:"name" cty:"name" hcl:"name"`
-	Force     *bool        `mapstructure:"force" cty:"force" hcl:"force"`
-	Images    *bool        `mapstructure:"images" cty:"images" hcl:"images"`
-	Manifest  *string      `mapstructure:"manifest" cty:"manifest" hcl:"manifest"`
-	OutputDir *string      `mapstructure:"output_directory" required:"false" cty:"output_directory" hcl:"output_directory"`
-	DirPerm   *os.FileMode `mapstructure:"directory_permission" required:"false" cty:"directory_permission" hcl:"directory_permission"`
-	Options   []string     `mapstructure:"options" cty:"options" hcl:"options"`
+	Name      *string     `mapstructure:"name" cty:"name" hcl:"name"`
+	Force     *bool       `mapstructure:"force" cty:"force" hcl:"force"`
+	Images    *bool       `mapstructure:"images" cty:"images" hcl:"images"`
+	Manifest  *string     `mapstructure:"manifest" cty:"manifest" hcl:"manifest"`
+	OutputDir *string     `mapstructure:"output_directory" required:"false" cty:"output_directory" hcl:"output_directory"`
+	DirPerm   os.FileMode `mapstructure:"directory_permission" required:"false" cty:"directory_permission" hcl:"directory_permission"`
+	Options   []string    `mapstructure:"options" cty:"options" hcl:"options"`
 }
 
 // FlatMapstructure returns a new FlatExportConfig.
@@ -37,7 +37,7 @@ func (*FlatExportConfig) HCL2Spec() map[string]hcldec.Spec {
 		"images":               &hcldec.AttrSpec{Name: "images", Type: cty.Bool, Required: false},
 		"manifest":             &hcldec.AttrSpec{Name: "manifest", Type: cty.String, Required: false},
 		"output_directory":     &hcldec.AttrSpec{Name: "output_directory", Type: cty.String, Required: false},
-		"directory_permission": &hcldec.AttrSpec{Name: "directory_permission", Type: cty.Number, Required: false},
+		"directory_permission": &hcldec.AttrSpec{Name: "directory_permission", Type: cty.Bool, Required: false}, /* TODO(azr): could not find type */
 		"options":              &hcldec.AttrSpec{Name: "options", Type: cty.List(cty.String), Required: false},
 	}
 	return s
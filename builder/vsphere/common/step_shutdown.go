@@ -4,13 +4,11 @@
 package common
 
 import (
-	"bytes"
 	"context"
-	"fmt"
-	"log"
 	"time"
 
 	"github.com/hashicorp/packer/builder/vsphere/driver"
+	"github.com/hashicorp/packer/common/shutdowncommand"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
@@ -29,6 +27,9 @@ type ShutdownConfig struct {
 	// Packer will wait for a default of five minutes until the virtual machine is shutdown.
 	// The timeout can be changed using `shutdown_timeout` option.
 	DisableShutdown bool `mapstructure:"disable_shutdown"`
+	// Power the VM off if it doesn't shut down gracefully in
+	// `shutdown_timeout`, instead of failing the build.
+	ForceShutdown bool `mapstructure:"force_shutdown"`
 }
 
 func (c *ShutdownConfig) Prepare() []error {
@@ -47,50 +48,45 @@ type StepShutdown struct {
 
 func (s *StepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	ui := state.Get("ui").(packer.Ui)
-	comm := state.Get("communicator").(packer.Communicator)
+	comm, _ := state.Get("communicator").(packer.Communicator)
 	vm := state.Get("vm").(*driver.VirtualMachine)
 
 	if off, _ := vm.IsPoweredOff(); off {
 		// Probably power off initiated by last provisioner, though disable_shutdown is not set
 		ui.Say("VM is already powered off")
+		shutdowncommand.PutPath(state, shutdowncommand.PathAlreadyOff)
 		return multistep.ActionContinue
 	}
 
-	if s.Config.DisableShutdown {
-		ui.Say("Automatic shutdown disabled. Please shutdown virtual machine.")
-	} else if s.Config.Command != "" {
-		ui.Say("Executing shutdown command...")
-		log.Printf("Shutdown command: %s", s.Config.Command)
-
-		var stdout, stderr bytes.Buffer
-		cmd := &packer.RemoteCmd{
-			Command: s.Config.Command,
-			Stdout:  &stdout,
-			Stderr:  &stderr,
-		}
-		err := comm.Start(ctx, cmd)
-		if err != nil {
-			state.Put("error", fmt.Errorf("Failed to send shutdown command: %s", err))
-			return multistep.ActionHalt
-		}
-	} else {
-		ui.Say("Shutting down VM...")
-
-		err := vm.StartShutdown()
-		if err != nil {
-			state.Put("error", fmt.Errorf("Cannot shut down VM: %v", err))
-			return multistep.ActionHalt
-		}
+	shutdown := &shutdowncommand.Shutdown{
+		Command: s.Config.Command,
+		Timeout: s.Config.Timeout,
+		// VMware guest tools are used without a shutdown command.
+		ACPI:         s.Config.Command == "" && !s.Config.DisableShutdown,
+		Disabled:     s.Config.DisableShutdown,
+		Force:        s.Config.ForceShutdown,
+		PollInterval: time.Second,
 	}
-
-	log.Printf("Waiting max %s for shutdown to complete", s.Config.Timeout)
-	err := vm.WaitForShutdown(ctx, s.Config.Timeout)
+	path, err := shutdown.Run(ctx, ui, comm, &machine{vm: vm})
 	if err != nil {
 		state.Put("error", err)
 		return multistep.ActionHalt
 	}
-
+	shutdowncommand.PutPath(state, path)
 	return multistep.ActionContinue
 }
 
 func (s *StepShutdown) Cleanup(state multistep.StateBag) {}
+
+// machine shuts a VM down with the guest tools.
+type machine struct {
+	vm *driver.VirtualMachine
+}
+
+func (m *machine) IsRunning() (bool, error) {
+	off, err := m.vm.IsPoweredOff()
+	return !off, err
+}
+
+func (m *machine) Stop() error        { return m.vm.PowerOff() }
+func (m *machine) StopViaACPI() error { return m.vm.StartShutdown() }
@@ -12,6 +12,7 @@ type FlatShutdownConfig struct {
 	Command         *string `mapstructure:"shutdown_command" cty:"shutdown_command" hcl:"shutdown_command"`
 	Timeout         *string `mapstructure:"shutdown_timeout" cty:"shutdown_timeout" hcl:"shutdown_timeout"`
 	DisableShutdown *bool   `mapstructure:"disable_shutdown" cty:"disable_shutdown" hcl:"disable_shutdown"`
+	ForceShutdown   *bool   `mapstructure:"force_shutdown" cty:"force_shutdown" hcl:"force_shutdown"`
 }
 
 // FlatMapstructure returns a new FlatShutdownConfig.
@@ -29,6 +30,7 @@ func (*FlatShutdownConfig) HCL2Spec() map[string]hcldec.Spec {
 		"shutdown_command": &hcldec.AttrSpec{Name: "shutdown_command", Type: cty.String, Required: false},
 		"shutdown_timeout": &hcldec.AttrSpec{Name: "shutdown_timeout", Type: cty.String, Required: false},
 		"disable_shutdown": &hcldec.AttrSpec{Name: "disable_shutdown", Type: cty.Bool, Required: false},
+		"force_shutdown":   &hcldec.AttrSpec{Name: "force_shutdown", Type: cty.Bool, Required: false},
 	}
 	return s
 }
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/packer/builder/vsphere/driver"
+	"github.com/hashicorp/packer/common/wait"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
@@ -135,34 +136,39 @@ func doGetIp(vm *driver.VirtualMachine, ctx context.Context, c *WaitIpConfig) (s
 	} else {
 		interval = 1 * time.Second
 	}
-loop:
-	ip, err := vm.WaitForIP(ctx, c.ipnet)
-	if err != nil {
-		return "", err
-	}
-	if prevIp == "" || prevIp != ip {
-		if prevIp == "" {
-			log.Printf("VM IP aquired: %s", ip)
-		} else {
-			log.Printf("VM IP changed from %s to %s", prevIp, ip)
+	err := wait.Config{
+		Description: "the IP to settle",
+		MinDelay:    interval,
+		MaxDelay:    interval,
+	}.Until(ctx, func(ctx context.Context) (bool, string, error) {
+		ip, err := vm.WaitForIP(ctx, c.ipnet)
+		if err != nil {
+			return false, "", err
+		}
+		if prevIp == "" || prevIp != ip {
+			if prevIp == "" {
+				log.Printf("VM IP aquired: %s", ip)
+			} else {
+				log.Printf("VM IP changed from %s to %s", prevIp, ip)
+			}
+			prevIp = ip
+			stopTime = time.Now().Add(c.SettleTimeout)
+			return false, ip, nil
 		}
-		prevIp = ip
-		stopTime = time.Now().Add(c.SettleTimeout)
-		goto loop
-	} else {
 		log.Printf("VM IP is still the same: %s", prevIp)
 		if time.Now().After(stopTime) {
 			log.Printf("VM IP seems stable enough: %s", ip)
-			return ip, nil
+			return true, ip, nil
 		}
-		select {
-		case <-ctx.Done():
+		return false, ip, nil
+	})
+	if err != nil {
+		if err == ctx.Err() {
 			return "", fmt.Errorf("IP wait cancelled")
-		case <-time.After(interval):
-			goto loop
 		}
+		return "", err
 	}
-
+	return prevIp, nil
 }
 
 func (s *StepWaitForIp) Cleanup(state multistep.StateBag) {}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/packer/common/wait"
 	"github.com/hashicorp/packer/packer"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/nfc"
@@ -647,27 +648,26 @@ func (vm *VirtualMachine) StartShutdown() error {
 }
 
 func (vm *VirtualMachine) WaitForShutdown(ctx context.Context, timeout time.Duration) error {
-	shutdownTimer := time.After(timeout)
-	for {
+	err := wait.Config{
+		Description: "machine to shut down",
+		Timeout:     timeout,
+		MinDelay:    time.Second,
+		MaxDelay:    5 * time.Second,
+	}.Until(ctx, func(context.Context) (bool, string, error) {
 		off, err := vm.IsPoweredOff()
-		if err != nil {
-			return err
-		}
 		if off {
-			break
-		}
-
-		select {
-		case <-shutdownTimer:
-			err := errors.New("Timeout while waiting for machine to shut down.")
-			return err
-		case <-ctx.Done():
-			return nil
-		default:
-			time.Sleep(1 * time.Second)
+			return true, "off", err
 		}
+		return false, "on", err
+	})
+	switch err.(type) {
+	case *wait.TimeoutError:
+		return errors.New("Timeout while waiting for machine to shut down.")
 	}
-	return nil
+	if err != nil && err == ctx.Err() {
+		return nil
+	}
+	return err
 }
 
 func (vm *VirtualMachine) CreateSnapshot(name string) error {
@@ -9,6 +9,7 @@ import (
 type KeyInput struct {
 	Scancode key.Code
 	Alt      bool
+	AltGr    bool
 	Ctrl     bool
 	Shift    bool
 }
@@ -21,6 +22,7 @@ func (vm *VirtualMachine) TypeOnKeyboard(input KeyInput) (int32, error) {
 		Modifiers: &types.UsbScanCodeSpecModifierType{
 			LeftControl: &input.Ctrl,
 			LeftAlt:     &input.Alt,
+			RightAlt:    &input.AltGr,
 			LeftShift:   &input.Shift,
 		},
 	})
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/guest"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
@@ -31,6 +32,7 @@ var reImageFamily = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 	Communicator        communicator.Config `mapstructure:",squash"`
+	Guest               guest.Config        `mapstructure:",squash"`
 
 	// Non standard api endpoint URL.
 	Endpoint string `mapstructure:"endpoint" required:"false"`
@@ -223,6 +225,9 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	if es := c.Communicator.Prepare(&c.ctx); len(es) > 0 {
 		errs = packer.MultiErrorAppend(errs, es...)
 	}
+	if es := c.Guest.Prepare(&c.Communicator); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
 
 	// Process required parameters.
 	if c.SourceImageID == "" {
@@ -281,7 +281,7 @@ func (s *StepCreateInstance) Cleanup(state multistep.StateBag) {
 	driver := state.Get("driver").(Driver)
 	ui := state.Get("ui").(packer.Ui)
 
-	ctx, cancel := context.WithTimeout(context.Background(), config.StateTimeout)
+	ctx, cancel := context.WithTimeout(multistep.CleanupContext(state), config.StateTimeout)
 	defer cancel()
 
 	if s.SerialLogFile != "" {
@@ -0,0 +1,144 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common/agent"
+	"github.com/posener/complete"
+)
+
+type AgentCommand struct {
+	Meta
+}
+
+func (c *AgentCommand) Run(args []string) int {
+	ctx, cleanup := handleTermInterrupt(c.Ui)
+	defer cleanup()
+
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(ctx, cfg)
+}
+
+func (c *AgentCommand) ParseArgs(args []string) (*AgentArgs, int) {
+	var cfg AgentArgs
+	flags := c.Meta.FlagSet("agent", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	return &cfg, 0
+}
+
+func (c *AgentCommand) RunContext(ctx context.Context, cla *AgentArgs) int {
+	if cla.Token == "" {
+		c.Ui.Error(fmt.Sprintf("A token is required: set it with -token or %s", agent.EnvToken))
+		return 1
+	}
+	if (cla.TLSCert == "") != (cla.TLSKey == "") {
+		c.Ui.Error("-tls-cert and -tls-key must be set together")
+		return 1
+	}
+
+	server := &http.Server{
+		Addr: cla.Address,
+		Handler: &agent.Server{
+			Token:   cla.Token,
+			WorkDir: cla.WorkDir,
+			MaxJobs: cla.MaxJobs,
+		},
+		// Stopping the agent interrupts the running builds.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	errs := make(chan error, 1)
+	go func() {
+		if cla.TLSCert != "" {
+			errs <- server.ListenAndServeTLS(cla.TLSCert, cla.TLSKey)
+		} else {
+			errs <- server.ListenAndServe()
+		}
+	}()
+
+	if cla.TLSCert == "" {
+		c.Ui.Error("Warning: -tls-cert isn't set, the token and the jobs are sent in clear text.")
+	}
+	c.Ui.Say(fmt.Sprintf("Agent listening on %s, running jobs in %s", cla.Address, cla.WorkDir))
+
+	select {
+	case err := <-errs:
+		c.Ui.Error(fmt.Sprintf("Agent failed: %s", err))
+		return 1
+	case <-ctx.Done():
+	}
+
+	// Give the interrupted builds time to clean up.
+	c.Ui.Say("Stopping the agent, waiting for the interrupted builds to clean up...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to stop the agent: %s", err))
+		return 1
+	}
+	return 0
+}
+
+func (*AgentCommand) Help() string {
+	helpText := `
+Usage: packer agent [options]
+
+  Runs builds submitted by packer remote-build. The agent runs on the host
+  builds need, like the host of a hypervisor, and streams the output and the
+  artifacts of the builds back to the clients.
+
+  Each job runs packer build in its own directory of the work directory,
+  where its files and artifacts are kept after the build. Interrupting a
+  client, or the agent, interrupts the builds.
+
+Options:
+
+  -address=addr          The address to listen on. Defaults to ":8181".
+  -token=token           The token clients authenticate with
+                         (Default: $` + agent.EnvToken + `). Required.
+  -work-dir=path         The directory of the jobs. Defaults to
+                         "packer-agent-jobs".
+  -max-jobs=n            The number of builds running at once; other jobs
+                         wait. Zero means no limit. Defaults to 1.
+  -tls-cert=path         The certificate to serve HTTPS with.
+  -tls-key=path          The key of the certificate.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*AgentCommand) Synopsis() string {
+	return "runs builds submitted by remote clients"
+}
+
+func (*AgentCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*AgentCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-address":  complete.PredictNothing,
+		"-token":    complete.PredictNothing,
+		"-work-dir": complete.PredictDirs("*"),
+		"-max-jobs": complete.PredictNothing,
+		"-tls-cert": complete.PredictFiles("*"),
+		"-tls-key":  complete.PredictFiles("*"),
+	}
+}
@@ -0,0 +1,576 @@
+package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common/artifacts"
+	"github.com/hashicorp/packer/common/uuid"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/version"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+// openArtifactsStore returns the store at address, the local store in the
+// Packer configuration directory when address is empty, or nil when address
+// is artifacts.Disabled.
+func openArtifactsStore(address string) (artifacts.Store, error) {
+	switch address {
+	case artifacts.Disabled:
+		return nil, nil
+	case "":
+		dir, err := packer.ConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		return artifacts.NewFile(filepath.Join(dir, "artifacts.jsonl")), nil
+	}
+	return artifacts.New(address)
+}
+
+// artifactsRecorder records the metadata of the builds of a run of packer
+// build in an artifacts store.
+type artifactsRecorder struct {
+	store    artifacts.Store
+	template string
+	host     string
+	// inputs hashes the inputs of the builds, nil when they can't be.
+	inputs    *buildCache
+	variables map[string]string
+	varFiles  map[string]string
+}
+
+// newArtifactsRecorder returns the recorder of the builds of cla, or nil when
+// recording is disabled. cache is the cache of the builds, if any, whose
+// hash of the inputs is reused.
+func newArtifactsRecorder(cla *BuildArgs, cache *buildCache) (*artifactsRecorder, error) {
+	store, err := openArtifactsStore(cla.ArtifactsStore)
+	if err != nil || store == nil {
+		return nil, err
+	}
+
+	r := &artifactsRecorder{
+		store:     store,
+		inputs:    cache,
+		variables: map[string]string{},
+		varFiles:  map[string]string{},
+	}
+	r.host, _ = os.Hostname()
+	if cla.Path != "" && cla.Path != "-" {
+		if abs, err := filepath.Abs(cla.Path); err == nil {
+			r.template = abs
+		}
+	}
+	if r.inputs == nil {
+		if inputs, err := hashBuildInputs(cla); err == nil {
+			r.inputs = &buildCache{inputs: inputs}
+		} else {
+			log.Printf("Not recording the hash of the inputs of the builds: %s", err)
+		}
+	}
+
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "PKR_VAR_") {
+			parts := strings.SplitN(strings.TrimPrefix(kv, "PKR_VAR_"), "=", 2)
+			r.variables[parts[0]] = recordedVariable(parts[0], parts[1])
+		}
+	}
+	for k, v := range cla.Vars {
+		r.variables[k] = recordedVariable(k, v)
+	}
+	cfgType, _ := cla.GetConfigType()
+	profileVarFiles, _ := cla.ProfileVarFiles(cfgType)
+	for _, path := range append(profileVarFiles, cla.VarFiles...) {
+		// Var files can hold secrets, only their hash is recorded.
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		sum := sha256.Sum256(contents)
+		r.varFiles[path] = hex.EncodeToString(sum[:])
+	}
+	return r, nil
+}
+
+// recordedVariable returns the value of the variable key as it is recorded,
+// hiding sensitive values.
+func recordedVariable(key, value string) string {
+	if packer.IsSensitiveKey(key) {
+		return "<sensitive>"
+	}
+	return packer.LogSecretFilter.FilterString(value)
+}
+
+// record records the run of b, which started at start and depended on the
+// builds whose outputs are deps, even when it was cancelled. cached tells
+// that runArtifacts come from the build cache.
+func (r *artifactsRecorder) record(b packer.Build, start time.Time, deps packer.BuildOutputs, cached bool, outputs map[string]string, runArtifacts []packer.Artifact, err error) error {
+	rec := &artifacts.Record{
+		Build:         b.Name(),
+		Template:      r.template,
+		Host:          r.host,
+		PackerVersion: version.FormattedVersion(),
+		Started:       start.UTC(),
+		Finished:      time.Now().UTC(),
+		Cached:        cached,
+		Outputs:       outputs,
+		Inputs: artifacts.Inputs{
+			Variables: r.variables,
+			VarFiles:  r.varFiles,
+		},
+	}
+	if cb, ok := b.(*packer.CoreBuild); ok && cb.Info() != nil {
+		info := cb.Info()
+		rec.ID = info.UUID
+		rec.Git = artifacts.Git{Commit: info.Git.Commit, Branch: info.Git.Branch, Dirty: info.Git.Dirty}
+	} else {
+		rec.ID = uuid.TimeOrderedUUID()
+	}
+	if r.inputs != nil {
+		rec.Inputs.Hash = r.inputs.key(b.Name(), deps)
+	}
+	if len(deps) > 0 {
+		rec.Inputs.Dependencies = deps
+	}
+	if err != nil {
+		rec.Error = packer.LogSecretFilter.FilterString(err.Error())
+	}
+	for _, a := range runArtifacts {
+		if a == nil {
+			continue
+		}
+		rec.Artifacts = append(rec.Artifacts, artifacts.Artifact{
+			BuilderID: a.BuilderId(),
+			ID:        a.Id(),
+			String:    a.String(),
+			Files:     a.Files(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return r.store.Append(ctx, rec)
+}
+
+type ArtifactsCommand struct {
+	Meta
+}
+
+func (*ArtifactsCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+func (*ArtifactsCommand) Help() string {
+	helpText := `
+Usage: packer artifacts <subcommand> [options] [args]
+
+  Queries the metadata of the builds recorded by packer build: what every
+  build produced, like the id of an AMI, and what it was built from. Builds
+  are recorded in the artifacts.jsonl file of the Packer configuration
+  directory, or in the store set with the -store option or the
+  PACKER_ARTIFACTS_STORE environment variable.
+
+Subcommands:
+
+  diff    Shows what changed between two builds.
+  list    Lists the recorded builds.
+  show    Shows a recorded build.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*ArtifactsCommand) Synopsis() string {
+	return "queries the history of the builds and of their artifacts"
+}
+
+// artifactsRecords returns the records of the store of cla.
+func (m *Meta) artifactsRecords(cla *ArtifactsArgs) ([]*artifacts.Record, bool) {
+	store, err := openArtifactsStore(cla.Store)
+	if err == nil && store == nil {
+		err = fmt.Errorf("the artifacts store is disabled")
+	}
+	if err != nil {
+		m.Ui.Error(err.Error())
+		return nil, false
+	}
+	records, err := store.Records(context.Background())
+	if err != nil {
+		m.Ui.Error(fmt.Sprintf("Error reading the artifacts store: %s", err))
+		return nil, false
+	}
+	return records, true
+}
+
+// findRecord returns the record designated by ref, reporting when there is
+// none.
+func (m *Meta) findRecord(records []*artifacts.Record, ref string) (*artifacts.Record, bool) {
+	r, err := artifacts.Find(records, ref)
+	if err == artifacts.ErrNotFound {
+		m.Ui.Error(fmt.Sprintf("No recorded build %s", ref))
+		return nil, false
+	}
+	if err != nil {
+		m.Ui.Error(err.Error())
+		return nil, false
+	}
+	return r, true
+}
+
+func (m *Meta) sayJSON(v interface{}) int {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		m.Ui.Error(err.Error())
+		return 1
+	}
+	m.Ui.Say(string(b))
+	return 0
+}
+
+func recordStatus(r *artifacts.Record) string {
+	switch {
+	case !r.Succeeded():
+		return "failed"
+	case r.Cached:
+		return "cached"
+	default:
+		return "succeeded"
+	}
+}
+
+type ArtifactsListCommand struct {
+	Meta
+}
+
+func (c *ArtifactsListCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *ArtifactsListCommand) ParseArgs(args []string) (*ArtifactsArgs, int) {
+	var cfg ArtifactsArgs
+	flags := c.Meta.FlagSet("artifacts list", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	flags.StringVar(&cfg.Build, "build", "", "")
+	flags.StringVar(&cfg.Template, "template", "", "")
+	flags.IntVar(&cfg.Limit, "limit", 0, "")
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if flags.NArg() != 0 || cfg.Limit < 0 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	if cfg.Template != "" {
+		if abs, err := filepath.Abs(cfg.Template); err == nil {
+			cfg.Template = abs
+		}
+	}
+	return &cfg, 0
+}
+
+func (c *ArtifactsListCommand) RunContext(cla *ArtifactsArgs) int {
+	records, ok := c.artifactsRecords(cla)
+	if !ok {
+		return 1
+	}
+	records = artifacts.Filter(records, cla.Build, cla.Template)
+	if cla.Limit > 0 && len(records) > cla.Limit {
+		records = records[len(records)-cla.Limit:]
+	}
+
+	if cla.JSON {
+		if records == nil {
+			records = []*artifacts.Record{}
+		}
+		return c.sayJSON(records)
+	}
+	c.Ui.Say(fmt.Sprintf("%d recorded build(s):", len(records)))
+	for _, r := range records {
+		c.Ui.Say(fmt.Sprintf("  %s  %s  %s  %s", r.ID, r.Started.Format(time.RFC3339), r.Build, recordStatus(r)))
+		for _, a := range r.Artifacts {
+			c.Ui.Say(fmt.Sprintf("      %s", a.ID))
+		}
+	}
+	return 0
+}
+
+func (*ArtifactsListCommand) Help() string {
+	helpText := `
+Usage: packer artifacts list [options]
+
+  Lists the recorded builds, from the oldest to the newest, with the ids of
+  their artifacts.
+
+Options:
+
+  -build=name                   Only list the builds with the given name, like amazon-ebs.base.
+  -template=path                Only list the builds of the given template.
+  -limit=n                      Only list the n latest builds.
+  -json                         Print the records of the builds as JSON.
+  -store=address                The artifacts store (Default: $PACKER_ARTIFACTS_STORE, or the local store).
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*ArtifactsListCommand) Synopsis() string {
+	return "lists the recorded builds"
+}
+
+func (*ArtifactsListCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*ArtifactsListCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-build":    complete.PredictNothing,
+		"-template": complete.PredictFiles("*"),
+		"-limit":    complete.PredictNothing,
+		"-json":     complete.PredictNothing,
+		"-store":    complete.PredictNothing,
+	}
+}
+
+type ArtifactsShowCommand struct {
+	Meta
+}
+
+func (c *ArtifactsShowCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *ArtifactsShowCommand) ParseArgs(args []string) (*ArtifactsArgs, int) {
+	var cfg ArtifactsArgs
+	flags := c.Meta.FlagSet("artifacts show", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.Refs = flags.Args()
+	return &cfg, 0
+}
+
+func (c *ArtifactsShowCommand) RunContext(cla *ArtifactsArgs) int {
+	records, ok := c.artifactsRecords(cla)
+	if !ok {
+		return 1
+	}
+	r, ok := c.findRecord(records, cla.Refs[0])
+	if !ok {
+		return 1
+	}
+
+	if cla.JSON {
+		return c.sayJSON(r)
+	}
+	c.Ui.Say(fmt.Sprintf("Build %s of %s", r.ID, r.Build))
+	c.Ui.Say(fmt.Sprintf("  status:          %s", recordStatus(r)))
+	if r.Error != "" {
+		c.Ui.Say(fmt.Sprintf("  error:           %s", r.Error))
+	}
+	c.Ui.Say(fmt.Sprintf("  started:         %s on %s", r.Started.Format(time.RFC3339), r.Host))
+	c.Ui.Say(fmt.Sprintf("  duration:        %s", r.Finished.Sub(r.Started).Round(time.Second)))
+	c.Ui.Say(fmt.Sprintf("  template:        %s", r.Template))
+	c.Ui.Say(fmt.Sprintf("  packer version:  %s", r.PackerVersion))
+	if r.Git.Commit != "" {
+		c.Ui.Say(fmt.Sprintf("  git:             %s on %s, dirty: %s", r.Git.Commit, r.Git.Branch, strconv.FormatBool(r.Git.Dirty)))
+	}
+	c.Ui.Say(fmt.Sprintf("  inputs hash:     %s", r.Inputs.Hash))
+	sayMap := func(title string, m map[string]string) {
+		if len(m) == 0 {
+			return
+		}
+		c.Ui.Say(fmt.Sprintf("  %s:", title))
+		for _, k := range sortedKeys(m) {
+			c.Ui.Say(fmt.Sprintf("    %s = %s", k, m[k]))
+		}
+	}
+	sayMap("variables", r.Inputs.Variables)
+	sayMap("var files", r.Inputs.VarFiles)
+	var deps []string
+	for dep := range r.Inputs.Dependencies {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+	for _, dep := range deps {
+		sayMap("outputs of "+dep, r.Inputs.Dependencies[dep])
+	}
+	sayMap("outputs", r.Outputs)
+	if len(r.Artifacts) > 0 {
+		c.Ui.Say("  artifacts:")
+		for _, a := range r.Artifacts {
+			c.Ui.Say(fmt.Sprintf("    %s (%s)", a.ID, a.BuilderID))
+			for _, f := range a.Files {
+				c.Ui.Say(fmt.Sprintf("      %s", f))
+			}
+		}
+	}
+	return 0
+}
+
+func (*ArtifactsShowCommand) Help() string {
+	helpText := `
+Usage: packer artifacts show [options] BUILD
+
+  Shows a recorded build: its artifacts, its outputs, and what it was built
+  from. BUILD is the id of the build, a prefix of its id, or the name of a
+  build, like amazon-ebs.base, to show its latest build.
+
+Options:
+
+  -json                         Print the record of the build as JSON.
+  -store=address                The artifacts store (Default: $PACKER_ARTIFACTS_STORE, or the local store).
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*ArtifactsShowCommand) Synopsis() string {
+	return "shows a recorded build"
+}
+
+func (*ArtifactsShowCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*ArtifactsShowCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-json":  complete.PredictNothing,
+		"-store": complete.PredictNothing,
+	}
+}
+
+type ArtifactsDiffCommand struct {
+	Meta
+}
+
+func (c *ArtifactsDiffCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *ArtifactsDiffCommand) ParseArgs(args []string) (*ArtifactsArgs, int) {
+	var cfg ArtifactsArgs
+	flags := c.Meta.FlagSet("artifacts diff", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if flags.NArg() != 1 && flags.NArg() != 2 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.Refs = flags.Args()
+	return &cfg, 0
+}
+
+func (c *ArtifactsDiffCommand) RunContext(cla *ArtifactsArgs) int {
+	records, ok := c.artifactsRecords(cla)
+	if !ok {
+		return 1
+	}
+
+	var old, new *artifacts.Record
+	if len(cla.Refs) == 1 {
+		builds := artifacts.Filter(records, cla.Refs[0], "")
+		if len(builds) < 2 {
+			c.Ui.Error(fmt.Sprintf("Build %s was recorded less than twice", cla.Refs[0]))
+			return 1
+		}
+		old, new = builds[len(builds)-2], builds[len(builds)-1]
+	} else {
+		if old, ok = c.findRecord(records, cla.Refs[0]); !ok {
+			return 1
+		}
+		if new, ok = c.findRecord(records, cla.Refs[1]); !ok {
+			return 1
+		}
+	}
+
+	changes := artifacts.Diff(old, new)
+	if cla.JSON {
+		if changes == nil {
+			changes = []artifacts.Change{}
+		}
+		return c.sayJSON(changes)
+	}
+	c.Ui.Say(fmt.Sprintf("Changes from build %s to build %s:", old.ID, new.ID))
+	if len(changes) == 0 {
+		c.Ui.Say("  none")
+	}
+	for _, change := range changes {
+		c.Ui.Say("  " + change.String())
+	}
+	return 0
+}
+
+func (*ArtifactsDiffCommand) Help() string {
+	helpText := `
+Usage: packer artifacts diff [options] OLD NEW
+       packer artifacts diff [options] NAME
+
+  Shows what changed between two recorded builds: their inputs, like the
+  commit of the template and the variables, their outputs and the ids of
+  their artifacts. OLD and NEW are ids of builds, prefixes of ids, or names
+  of builds for their latest build. With a build NAME, like
+  amazon-ebs.base, compares its two latest builds.
+
+Options:
+
+  -json                         Print the changes as JSON.
+  -store=address                The artifacts store (Default: $PACKER_ARTIFACTS_STORE, or the local store).
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*ArtifactsDiffCommand) Synopsis() string {
+	return "shows what changed between two recorded builds"
+}
+
+func (*ArtifactsDiffCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*ArtifactsDiffCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-json":  complete.PredictNothing,
+		"-store": complete.PredictNothing,
+	}
+}
@@ -4,14 +4,21 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/cost"
+	"github.com/hashicorp/packer/common/state"
 	"github.com/hashicorp/packer/hcl2template"
 	"github.com/hashicorp/packer/packer"
 	"github.com/hashicorp/packer/template"
@@ -32,6 +39,7 @@ func (c *BuildCommand) Run(args []string) int {
 	if ret != 0 {
 		return ret
 	}
+	cfg.Args = args
 
 	return c.RunContext(ctx, cfg)
 }
@@ -49,6 +57,11 @@ func (c *BuildCommand) ParseArgs(args []string) (*BuildArgs, int) {
 		cfg.ParallelBuilds = math.MaxInt64
 	}
 
+	if _, err := cfg.onConflict(); err != nil {
+		c.Ui.Error(err.Error())
+		return &cfg, 1
+	}
+
 	args = flags.Args()
 	if len(args) != 1 {
 		flags.Usage()
@@ -58,15 +71,33 @@ func (c *BuildCommand) ParseArgs(args []string) (*BuildArgs, int) {
 	return &cfg, 0
 }
 
-func (m *Meta) GetConfigFromHCL(cla *MetaArgs) (*hcl2template.PackerConfig, int) {
-	parser := &hcl2template.Parser{
+func (m *Meta) hclParser() *hcl2template.Parser {
+	return &hcl2template.Parser{
 		Parser:                hclparse.NewParser(),
 		BuilderSchemas:        m.CoreConfig.Components.BuilderStore,
 		ProvisionersSchemas:   m.CoreConfig.Components.ProvisionerStore,
 		PostProcessorsSchemas: m.CoreConfig.Components.PostProcessorStore,
+		PluginConfig:          m.CoreConfig.Components.PluginConfig,
 	}
-	cfg, diags := parser.Parse(cla.Path, cla.VarFiles, cla.Vars)
-	return cfg, writeDiags(m.Ui, parser.Files(), diags)
+}
+
+func (m *Meta) GetConfigFromHCL(cla *MetaArgs) (*hcl2template.PackerConfig, int) {
+	cfg, files, diags := m.loadHCLConfig(cla)
+	return cfg, writeDiags(m.Ui, files, diags)
+}
+
+func (m *Meta) loadHCLConfig(cla *MetaArgs) (*hcl2template.PackerConfig, map[string]*hcl.File, hcl.Diagnostics) {
+	parser := m.hclParser()
+	profileVarFiles, err := cla.ProfileVarFiles(ConfigTypeHCL2)
+	if err != nil {
+		return nil, nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to load the profile",
+			Detail:   err.Error(),
+		}}
+	}
+	cfg, diags := parser.Parse(cla.Path, append(profileVarFiles, cla.VarFiles...), cla.Vars)
+	return cfg, parser.Files(), diags
 }
 
 func writeDiags(ui packer.Ui, files map[string]*hcl.File, diags hcl.Diagnostics) int {
@@ -88,31 +119,37 @@ func writeDiags(ui packer.Ui, files map[string]*hcl.File, diags hcl.Diagnostics)
 }
 
 func (m *Meta) GetConfig(cla *MetaArgs) (packer.Handler, int) {
+	packerStarter, files, diags := m.loadConfig(cla)
+	return packerStarter, writeDiags(m.Ui, files, diags)
+}
+
+// loadConfig parses the template of cla, returning its errors as
+// diagnostics, with the files of the HCL2 templates they refer to. The
+// syntax errors of JSON templates have their position in the template.
+func (m *Meta) loadConfig(cla *MetaArgs) (packer.Handler, map[string]*hcl.File, hcl.Diagnostics) {
 	cfgType, err := cla.GetConfigType()
 	if err != nil {
-		m.Ui.Error(fmt.Sprintf("%q: %s", cla.Path, err))
-		return nil, 1
+		return nil, nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid template path",
+			Detail:   fmt.Sprintf("%q: %s", cla.Path, err),
+		}}
 	}
 
-	switch cfgType {
-	case ConfigTypeHCL2:
+	if cfgType == ConfigTypeHCL2 {
 		// TODO(azr): allow to pass a slice of files here.
-		return m.GetConfigFromHCL(cla)
-	default:
-		// TODO: uncomment once we've polished HCL a bit more.
-		// c.Ui.Say(`Legacy JSON Configuration Will Be Used.
-		// The template will be parsed in the legacy configuration style. This style
-		// will continue to work but users are encouraged to move to the new style.
-		// See: https://packer.io/guides/hcl
-		// `)
-		return m.GetConfigFromJSON(cla)
+		return m.loadHCLConfig(cla)
 	}
-}
 
-func (m *Meta) GetConfigFromJSON(cla *MetaArgs) (packer.Handler, int) {
+	// TODO: uncomment once we've polished HCL a bit more.
+	// c.Ui.Say(`Legacy JSON Configuration Will Be Used.
+	// The template will be parsed in the legacy configuration style. This style
+	// will continue to work but users are encouraged to move to the new style.
+	// See: https://packer.io/guides/hcl
+	// `)
+
 	// Parse the template
 	var tpl *template.Template
-	var err error
 	if cla.Path == "" {
 		// here cla validation passed so this means we want a default builder
 		// and we probably are in the console command
@@ -120,20 +157,37 @@ func (m *Meta) GetConfigFromJSON(cla *MetaArgs) (packer.Handler, int) {
 	} else {
 		tpl, err = template.ParseFile(cla.Path)
 	}
-
 	if err != nil {
-		m.Ui.Error(fmt.Sprintf("Failed to parse template: %s", err))
-		return nil, 1
+		diag := &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to parse template",
+			Detail:   err.Error(),
+		}
+		if syntaxErr, ok := err.(*template.SyntaxError); ok {
+			diag.Summary = "Error parsing JSON"
+			diag.Detail = syntaxErr.Err.Error()
+			diag.Subject = &hcl.Range{
+				Filename: cla.Path,
+				Start: hcl.Pos{
+					Line:   syntaxErr.Line,
+					Column: syntaxErr.Column,
+					Byte:   int(syntaxErr.Offset),
+				},
+			}
+		}
+		return nil, nil, hcl.Diagnostics{diag}
 	}
 
 	// Get the core
 	core, err := m.Core(tpl, cla)
-	ret := 0
 	if err != nil {
-		m.Ui.Error(err.Error())
-		ret = 1
+		return &CoreWrapper{core}, nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to initialize template",
+			Detail:   err.Error(),
+		}}
 	}
-	return &CoreWrapper{core}, ret
+	return &CoreWrapper{core}, nil, nil
 }
 
 func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int {
@@ -147,21 +201,135 @@ func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int
 		return ret
 	}
 
+	onConflict, err := cla.onConflict()
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
 	builds, diags := packerStarter.GetBuilds(packer.GetBuildsOptions{
-		Only:    cla.Only,
-		Except:  cla.Except,
-		Debug:   cla.Debug,
-		Force:   cla.Force,
-		OnError: cla.OnError,
+		Only:        cla.Only,
+		Except:      cla.Except,
+		Debug:       cla.Debug,
+		Breakpoints: cla.breakpoints(),
+		BillingTags: cla.BillingTags,
+		Force:       cla.Force,
+		OnError:     cla.OnError,
+		OnConflict:  onConflict,
+		Preflight:   cla.Preflight,
+		Resume:      cla.Resume,
+
+		DeferDependents: true,
 	})
 
 	// here, something could have gone wrong but we still want to run valid
 	// builds.
 	ret = writeDiags(c.Ui, nil, diags)
 
+	// Builds run after the builds they depend on.
+	builds, err = packer.SortBuilds(builds)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
 	if cla.Debug {
 		c.Ui.Say("Debug mode enabled. Builds will not be parallelized.")
 	}
+	stepping := len(cla.breakpoints()) > 0
+	if stepping && !cla.Debug {
+		c.Ui.Say("Breakpoints set. Builds will not be parallelized.")
+	}
+	if stepping && cla.Ui == "json" {
+		c.Ui.Error("-step and -breakpoint can't be used with -ui=json, which can't ask for input")
+		return 1
+	}
+
+	var stateBackend state.Backend
+	if cla.State != "" {
+		stateBackend, err = state.New(cla.State)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+	}
+
+	var cache *buildCache
+	if cla.Cache {
+		cache, err = newBuildCache(cla, stateBackend)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error hashing the inputs of the builds: %s", err))
+			return 1
+		}
+	}
+
+	recorder, err := newArtifactsRecorder(cla, cache)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error opening the artifacts store: %s", err))
+		return 1
+	}
+
+	if cla.Ui == "json" && cla.EventStream != "" {
+		c.Ui.Error("-event-stream can't be used with -ui=json, which writes the events to the output")
+		return 1
+	}
+	if _, ok := c.Ui.(*packer.MachineReadableUi); ok && (cla.Ui == "json" || cla.Ui == "fancy") {
+		c.Ui.Error(fmt.Sprintf("-machine-readable can't be used with -ui=%s", cla.Ui))
+		return 1
+	}
+
+	var events *packer.EventStream
+	if cla.EventStream != "" {
+		w, err := openEventStream(cla.EventStream)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error opening event stream: %s", err))
+			return 1
+		}
+		defer w.Close()
+		events = packer.NewEventStream(w)
+	}
+
+	// The json and fancy UIs write to where the regular output goes.
+	var out io.Writer = os.Stdout
+	if basic, ok := c.Ui.(*packer.BasicUi); ok {
+		out = basic.Writer
+	}
+	var dashboard *packer.Dashboard
+	switch cla.Ui {
+	case "json":
+		events = packer.NewEventStream(out)
+		c.Ui = &packer.JSONUi{Stream: events}
+	case "fancy":
+		dashboard = &packer.Dashboard{
+			Ui:     c.Ui,
+			Writer: out,
+			Color:  cla.Color && os.Getenv("PACKER_NO_COLOR") == "",
+		}
+		if width, _, err := common.GetTerminalDimensions(); err == nil {
+			dashboard.Width = width
+		}
+	}
+
+	var monitor *packer.BuildMonitor
+	if cla.APIListen != "" {
+		var interrupt context.CancelFunc
+		buildCtx, interrupt = context.WithCancel(buildCtx)
+		defer interrupt()
+		monitor = &packer.BuildMonitor{Interrupt: interrupt}
+		api, err := listenBuildAPI(cla.APIListen, monitor)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error starting the API: %s", err))
+			return 1
+		}
+		defer api.Close()
+		c.Ui.Say(fmt.Sprintf("API listening on http://%s", api.Addr()))
+		c.Ui.Machine("api-address", api.Addr())
+	}
+
+	var timings *packer.Timings
+	if cla.Timings || cla.TimingsJSON != "" || cla.TimingsFlamegraph != "" {
+		timings = &packer.Timings{}
+	}
 
 	// Compile all the UIs for the builds
 	colors := [5]packer.UiColor{
@@ -174,7 +342,12 @@ func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int
 	buildUis := make(map[packer.Build]packer.Ui)
 	for i := range builds {
 		ui := c.Ui
-		if cla.Color {
+		switch {
+		case cla.Ui == "json":
+			ui = &packer.JSONUi{Stream: events, Target: builds[i].Name()}
+		case dashboard != nil:
+			ui = dashboard.BuildUi(builds[i].Name())
+		case cla.Color:
 			// Only set up UI colors if -machine-readable isn't set.
 			if _, ok := c.Ui.(*packer.MachineReadableUi); !ok {
 				ui = &packer.ColoredUi{
@@ -189,11 +362,23 @@ func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int
 			}
 		}
 		// Now add timestamps if requested
-		if cla.TimestampUi {
+		if cla.TimestampUi && cla.Ui != "json" && dashboard == nil {
 			ui = &packer.TimestampedUi{
 				Ui: ui,
 			}
 		}
+		if events != nil {
+			ui = &packer.EventStreamUi{
+				Ui:     ui,
+				Stream: events,
+			}
+		}
+		if monitor != nil {
+			ui = monitor.BuildUi(builds[i].Name(), ui)
+		}
+		if timings != nil {
+			ui = timings.BuildUi(builds[i].Name(), ui)
+		}
 
 		buildUis[builds[i]] = ui
 	}
@@ -213,7 +398,23 @@ func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int
 		sync.RWMutex
 		m map[string]error
 	}{m: make(map[string]error)}
+	// The estimated costs of the builds whose builders estimate them.
+	var costs = struct {
+		sync.Mutex
+		m map[string]string
+	}{m: make(map[string]string)}
+	// The outputs of the builds that succeeded are passed to the builds
+	// depending on them, once they are done.
+	outputs := &buildOutputs{m: packer.BuildOutputs{}}
+	done := make(map[string]chan struct{}, len(builds))
+	for _, b := range builds {
+		done[b.Name()] = make(chan struct{})
+	}
 	limitParallel := semaphore.NewWeighted(cla.ParallelBuilds)
+	semaphores := &buildSemaphores{}
+	if dashboard != nil {
+		dashboard.Start()
+	}
 	for i := range builds {
 		if err := buildCtx.Err(); err != nil {
 			log.Println("Interrupted, not going to start any more builds.")
@@ -223,12 +424,19 @@ func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int
 		b := builds[i]
 		name := b.Name()
 		ui := buildUis[b]
-		if err := limitParallel.Acquire(buildCtx, 1); err != nil {
-			ui.Error(fmt.Sprintf("Build '%s' failed to acquire semaphore: %s", name, err))
-			errors.Lock()
-			errors.m[name] = err
-			errors.Unlock()
-			break
+		// Builds depending on other builds only take a slot once these
+		// are done, and builds limited by semaphores once they got a slot of
+		// each of them, so that other builds can run in the meantime.
+		dependent := len(packer.BuildDependencies(b)) > 0
+		deferred := dependent || len(semaphoreLimits(b)) > 0
+		if !deferred {
+			if err := limitParallel.Acquire(buildCtx, 1); err != nil {
+				ui.Error(fmt.Sprintf("Build '%s' failed to acquire semaphore: %s", name, err))
+				errors.Lock()
+				errors.m[name] = err
+				errors.Unlock()
+				break
+			}
 		}
 		// Increment the waitgroup so we wait for this item to finish properly
 		wg.Add(1)
@@ -236,19 +444,179 @@ func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int
 		// Run the build in a goroutine
 		go func() {
 			defer wg.Done()
+			defer close(done[name])
+
+			buildCtx := buildCtx
+			if monitor != nil {
+				var cancel context.CancelFunc
+				buildCtx, cancel = monitor.Context(buildCtx, name)
+				defer cancel()
+			}
 
+			if deferred {
+				var err error
+				if dependent {
+					var warnings []string
+					warnings, err = prepareDependentBuild(buildCtx, b, done, outputs)
+					for _, warning := range warnings {
+						ui.Say(fmt.Sprintf("Warning when preparing build '%s': %s", name, warning))
+					}
+				}
+				release := func() {}
+				if err == nil {
+					release, err = semaphores.acquire(buildCtx, b, ui)
+				}
+				if err == nil {
+					if err = limitParallel.Acquire(buildCtx, 1); err != nil {
+						release()
+					}
+				}
+				if err != nil {
+					ui.Error(fmt.Sprintf("Build '%s' can't run: %s", name, err))
+					events.Emit(packer.Event{Type: packer.EventError, Build: name, Error: err.Error()})
+					if dashboard != nil {
+						dashboard.Finished(name, err)
+					}
+					if monitor != nil {
+						monitor.Finished(name, err)
+					}
+					errors.Lock()
+					errors.m[name] = err
+					errors.Unlock()
+					return
+				}
+				defer release()
+			}
 			defer limitParallel.Release(1)
 
+			if stateBackend != nil {
+				lease, err := acquireState(buildCtx, stateBackend, b, cla.StateLockTimeout)
+				if err != nil {
+					err = fmt.Errorf("locking state: %s", err)
+					ui.Error(fmt.Sprintf("Build '%s' can't run: %s", name, err))
+					events.Emit(packer.Event{Type: packer.EventError, Build: name, Error: err.Error()})
+					if dashboard != nil {
+						dashboard.Finished(name, err)
+					}
+					if monitor != nil {
+						monitor.Finished(name, err)
+					}
+					errors.Lock()
+					errors.m[name] = err
+					errors.Unlock()
+					return
+				}
+				defer releaseState(stateBackend, lease, ui)
+			}
+
 			log.Printf("Starting build run: %s", name)
-			runArtifacts, err := b.Run(buildCtx, ui)
+			events.Emit(packer.Event{Type: packer.EventBuildStarted, Build: name})
+			if dashboard != nil {
+				dashboard.Started(name)
+			}
+			if monitor != nil {
+				monitor.Started(name)
+			}
+			if timings != nil {
+				timings.Started(name)
+			}
+			start := time.Now()
+			deps := outputs.dependencies(b)
+			var cacheKey string
+			var runArtifacts []packer.Artifact
+			var err error
+			if cache != nil {
+				cacheKey = cache.key(name, deps)
+				runArtifacts = lookupCache(buildCtx, cache, cacheKey, cla.Force, ui)
+			}
+			cached := runArtifacts != nil
+			if runArtifacts == nil {
+				journal := startJournal(b, cla)
+				runArtifacts, err = b.Run(buildCtx, ui)
+				if journal != nil {
+					// The build ran and cleaned up after itself, there is
+					// nothing left to resume.
+					if err := journal.Delete(); err != nil {
+						log.Printf("Error removing the journal of build %s: %s", name, err)
+					}
+				}
+				if cache != nil && err == nil && len(runArtifacts) > 0 {
+					if err := cache.record(buildCtx, cacheKey, name, runArtifacts); err != nil {
+						ui.Error(fmt.Sprintf("Failed to cache the artifacts of build '%s': %s", name, err))
+					}
+				}
+			}
+			if timings != nil {
+				timings.Finished(name)
+			}
+			var buildOutputs map[string]string
+			if err == nil {
+				buildOutputs, err = packer.Outputs(b, runArtifacts)
+			}
+			if dashboard != nil {
+				dashboard.Finished(name, err)
+			}
+			if monitor != nil {
+				monitor.Finished(name, err)
+			}
 
+			finished := packer.Event{
+				Type:     packer.EventBuildFinished,
+				Build:    name,
+				Duration: time.Since(start).Seconds(),
+			}
+			verifyErr, verifyFailed := err.(*packer.VerificationError)
 			if err != nil {
+				finished.Error = err.Error()
+				errorType := packer.EventError
+				if verifyFailed {
+					errorType = packer.EventVerificationFailed
+				}
+				events.Emit(packer.Event{Type: errorType, Build: name, Error: err.Error()})
+			}
+			for _, a := range runArtifacts {
+				if a != nil {
+					events.Emit(packer.Event{Type: packer.EventArtifact, Build: name, Artifact: packer.NewArtifactEvent(a)})
+				}
+			}
+			events.Emit(finished)
+
+			if cb, ok := b.(*packer.CoreBuild); ok {
+				if amount, ok := cb.GeneratedData()[cost.EstimatedCostKey].(string); ok {
+					costs.Lock()
+					costs.m[name] = amount
+					costs.Unlock()
+				}
+			}
+
+			if recorder != nil {
+				if err := recorder.record(b, start, deps, cached, buildOutputs, runArtifacts, err); err != nil {
+					ui.Error(fmt.Sprintf("Failed to record build '%s' in the artifacts store: %s", name, err))
+				}
+			}
+
+			if verifyFailed {
+				ui.Error(fmt.Sprintf("Build '%s' failed verification: %d verifier(s) failed", name, len(verifyErr.Failures)))
+				errors.Lock()
+				errors.m[name] = err
+				errors.Unlock()
+				// With on_failure = "continue" the build still produced
+				// artifacts.
+				if len(runArtifacts) > 0 {
+					artifacts.Lock()
+					artifacts.m[name] = runArtifacts
+					artifacts.Unlock()
+				}
+			} else if err != nil {
 				ui.Error(fmt.Sprintf("Build '%s' errored: %s", name, err))
 				errors.Lock()
 				errors.m[name] = err
 				errors.Unlock()
 			} else {
 				ui.Say(fmt.Sprintf("Build '%s' finished.", name))
+				outputs.Lock()
+				outputs.m[name] = buildOutputs
+				outputs.Unlock()
 				if nil != runArtifacts {
 					artifacts.Lock()
 					artifacts.m[name] = runArtifacts
@@ -257,7 +625,7 @@ func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int
 			}
 		}()
 
-		if cla.Debug {
+		if cla.Debug || stepping {
 			log.Printf("Debug enabled, so waiting for build to finish: %s", b.Name())
 			wg.Wait()
 		}
@@ -273,17 +641,44 @@ func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int
 	// if it is interrupted.
 	log.Printf("Waiting on builds to complete...")
 	wg.Wait()
+	if dashboard != nil {
+		dashboard.Stop()
+	}
+
+	if hasNamedOutputs(builds) && len(outputs.m) > 0 {
+		if err := writeOutputsFile(cla.OutputsFile, outputs.m); err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to write outputs to %s: %s", cla.OutputsFile, err))
+		} else {
+			log.Printf("Wrote the outputs of the builds to %s", cla.OutputsFile)
+		}
+	}
+
+	if timings != nil {
+		writeTimings(c.Ui, timings, cla)
+	}
 
 	if err := buildCtx.Err(); err != nil {
 		c.Ui.Say("Cleanly cancelled builds after being interrupted.")
 		return 1
 	}
 
-	if len(errors.m) > 0 {
-		c.Ui.Machine("error-count", strconv.FormatInt(int64(len(errors.m)), 10))
+	// Verification failures are test failures of builds that otherwise
+	// worked, they are reported apart from build errors.
+	buildErrors := map[string]error{}
+	verifyErrors := map[string]*packer.VerificationError{}
+	for name, err := range errors.m {
+		if verifyErr, ok := err.(*packer.VerificationError); ok {
+			verifyErrors[name] = verifyErr
+		} else {
+			buildErrors[name] = err
+		}
+	}
+
+	if len(buildErrors) > 0 {
+		c.Ui.Machine("error-count", strconv.FormatInt(int64(len(buildErrors)), 10))
 
 		c.Ui.Error("\n==> Some builds didn't complete successfully and had errors:")
-		for name, err := range errors.m {
+		for name, err := range buildErrors {
 			// Create a UI for the machine readable stuff to be targeted
 			ui := &packer.TargetedUI{
 				Target: name,
@@ -296,6 +691,23 @@ func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int
 		}
 	}
 
+	if len(verifyErrors) > 0 {
+		c.Ui.Machine("verification-failure-count", strconv.FormatInt(int64(len(verifyErrors)), 10))
+
+		c.Ui.Error("\n==> Some builds failed verification:")
+		for name, verifyErr := range verifyErrors {
+			ui := &packer.TargetedUI{
+				Target: name,
+				Ui:     c.Ui,
+			}
+
+			for _, f := range verifyErr.Failures {
+				ui.Machine("verification-failure", f.Verifier, f.Message)
+				c.Ui.Error(fmt.Sprintf("--> %s: %s", name, f))
+			}
+		}
+	}
+
 	if len(artifacts.m) > 0 {
 		c.Ui.Say("\n==> Builds finished. The artifacts of successful builds are:")
 		for name, buildArtifacts := range artifacts.m {
@@ -344,6 +756,23 @@ func (c *BuildCommand) RunContext(buildCtx context.Context, cla *BuildArgs) int
 		c.Ui.Say("\n==> Builds finished but no artifacts were created.")
 	}
 
+	if len(costs.m) > 0 {
+		c.Ui.Say("\n==> Estimated costs of the builds:")
+		names := make([]string, 0, len(costs.m))
+		for name := range costs.m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ui := &packer.TargetedUI{
+				Target: name,
+				Ui:     c.Ui,
+			}
+			ui.Machine("estimated-cost", costs.m[name])
+			c.Ui.Say(fmt.Sprintf("--> %s: %s", name, costs.m[name]))
+		}
+	}
+
 	if len(errors.m) > 0 {
 		// If any errors occurred, exit with a non-zero exit status
 		ret = 1
@@ -361,15 +790,34 @@ Usage: packer build [options] TEMPLATE
 
 Options:
 
+  -api-listen=127.0.0.1:8080    Serve an HTTP API on this localhost address to monitor, pause and cancel the builds.
+  -artifacts-store=address      Where to record the metadata of the builds, "off" not to (Default: $PACKER_ARTIFACTS_STORE, or the local store).
+  -billing-tags 'key=value'     Tag for the temporary resources of the builds, to account for their cost. Can be used multiple times.
+  -breakpoint=StepName          Pause before the steps or provisioners (provisioner.type) with these names or patterns.
+  -cache                        Skip the builds whose inputs are unchanged since a previous build, reusing its artifacts.
+  -cache-input=path             File or directory that is also an input of the builds, for -cache. Can be used multiple times.
   -color=false                  Disable color output. (Default: color)
   -debug                        Debug mode enabled for builds.
-  -except=foo,bar,baz           Run all builds and post-procesors other than these.
-  -only=foo,bar,baz             Build only the specified builds.
+  -event-stream=path            Write build events as JSON lines to a file, or to a unix socket with unix:path.
+  -except=foo,bar,baz           Run all builds and post-procesors other than these. HCL2 builds can also be excluded with tag:pattern or ${expression}.
+  -only=foo,bar,baz             Build only the specified builds. HCL2 builds can also be selected with tag:pattern or ${expression}.
   -force                        Force a build to continue if artifacts exist, deletes existing artifacts.
   -machine-readable             Produce machine-readable output.
   -on-error=[cleanup|abort|ask|run-cleanup-provisioner] If the build fails do: clean up (default), abort, ask, or run-cleanup-provisioner.
+  -outputs-file=path            Where to write the outputs of the builds (Default: packer-outputs.json).
   -parallel-builds=1            Number of builds to run in parallel. 1 disables parallelization. 0 means no limit (Default: 0)
+  -preflight=[check|only]       Check that the credentials of the cloud builders can perform all the operations of the builds before launching anything, or only check them.
+  -profile=name                 Load the name.pkrvars.hcl and name.pkrvars.json var files found next to the template.
+  -replace                      Replace existing artifacts once the builds are about to create their own.
+  -skip-if-exists               Skip the builds whose artifacts already exist.
+  -state=address                State backend recording running builds and locking their names (Default: $PACKER_STATE).
+  -state-lock-timeout=10m       How long to wait for locked names (Default: 0).
+  -step                         Pause before every step and provisioner of the builds.
   -timestamp-ui                 Enable prefixing of each ui output with an RFC3339 timestamp.
+  -timings                      Show how long the steps, provisioners and post-processors of the builds took.
+  -timings-flamegraph=path      Write these timings as the folded stacks of a flame graph.
+  -timings-json=path            Write these timings as JSON.
+  -ui=[plain|fancy|json]        Show the output of the builds as is (default), as a live dashboard, or as JSON lines events.
   -var 'key=value'              Variable for templates, can be used multiple times.
   -var-file=path                JSON file containing user variables.
 `
@@ -387,16 +835,35 @@ func (*BuildCommand) AutocompleteArgs() complete.Predictor {
 
 func (*BuildCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
-		"-color":            complete.PredictNothing,
-		"-debug":            complete.PredictNothing,
-		"-except":           complete.PredictNothing,
-		"-only":             complete.PredictNothing,
-		"-force":            complete.PredictNothing,
-		"-machine-readable": complete.PredictNothing,
-		"-on-error":         complete.PredictNothing,
-		"-parallel":         complete.PredictNothing,
-		"-timestamp-ui":     complete.PredictNothing,
-		"-var":              complete.PredictNothing,
-		"-var-file":         complete.PredictNothing,
+		"-api-listen":         complete.PredictNothing,
+		"-artifacts-store":    complete.PredictNothing,
+		"-billing-tags":       complete.PredictNothing,
+		"-breakpoint":         complete.PredictNothing,
+		"-cache":              complete.PredictNothing,
+		"-cache-input":        complete.PredictFiles("*"),
+		"-color":              complete.PredictNothing,
+		"-debug":              complete.PredictNothing,
+		"-event-stream":       complete.PredictFiles("*"),
+		"-except":             complete.PredictNothing,
+		"-only":               complete.PredictNothing,
+		"-force":              complete.PredictNothing,
+		"-machine-readable":   complete.PredictNothing,
+		"-on-error":           complete.PredictNothing,
+		"-outputs-file":       complete.PredictFiles("*.json"),
+		"-parallel":           complete.PredictNothing,
+		"-preflight":          complete.PredictSet("check", "only"),
+		"-profile":            complete.PredictNothing,
+		"-replace":            complete.PredictNothing,
+		"-skip-if-exists":     complete.PredictNothing,
+		"-state":              complete.PredictNothing,
+		"-state-lock-timeout": complete.PredictNothing,
+		"-step":               complete.PredictNothing,
+		"-timestamp-ui":       complete.PredictNothing,
+		"-timings":            complete.PredictNothing,
+		"-timings-flamegraph": complete.PredictFiles("*"),
+		"-timings-json":       complete.PredictFiles("*.json"),
+		"-ui":                 complete.PredictSet("plain", "fancy", "json"),
+		"-var":                complete.PredictNothing,
+		"-var-file":           complete.PredictNothing,
 	}
 }
@@ -0,0 +1,221 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// buildAPI serves the HTTP API of the -api-listen option, to monitor and
+// control the builds of a BuildMonitor. GET /v1/builds returns the status of
+// the builds, GET /v1/builds/NAME the status of one of them, and GET /v1/logs
+// the last lines they output, filtered with the build, after and limit query
+// parameters. POST /v1/builds/NAME/pause pauses a build before its next step,
+// resume resumes it and cancel cancels it. POST /v1/pause, /v1/resume and
+// /v1/cancel do the same for all the builds.
+type buildAPI struct {
+	monitor  *packer.BuildMonitor
+	listener net.Listener
+	server   *http.Server
+}
+
+// listenBuildAPI starts serving the API of monitor on addr, that must be a
+// localhost address.
+func listenBuildAPI(addr string, monitor *packer.BuildMonitor) (*buildAPI, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if !isLoopback(host) {
+		return nil, fmt.Errorf("%q is not a localhost address", addr)
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	a := &buildAPI{monitor: monitor, listener: l}
+	a.server = &http.Server{Handler: a}
+	go func() {
+		if err := a.server.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.Printf("[ERR] The API stopped serving: %s", err)
+		}
+	}()
+	return a, nil
+}
+
+// Addr returns the address the API listens on.
+func (a *buildAPI) Addr() string {
+	return a.listener.Addr().String()
+}
+
+// Close stops serving the API.
+func (a *buildAPI) Close() error {
+	return a.server.Close()
+}
+
+func (a *buildAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Only local programs are trusted: the pages of other sites reach the
+	// API through the browser with their Origin, or through DNS rebinding
+	// with their host name.
+	host := r.Host
+	if h, _, err := net.SplitHostPort(r.Host); err == nil {
+		host = h
+	}
+	if !isLoopback(host) {
+		apiError(w, http.StatusForbidden, fmt.Errorf("forbidden host %q", r.Host))
+		return
+	}
+	if r.Header.Get("Origin") != "" {
+		apiError(w, http.StatusForbidden, fmt.Errorf("cross-origin requests are forbidden"))
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "v1" {
+		apiError(w, http.StatusNotFound, fmt.Errorf("not found"))
+		return
+	}
+	switch {
+	case len(parts) == 2 && parts[1] == "builds":
+		if apiMethod(w, r, http.MethodGet) {
+			apiReply(w, map[string]interface{}{"builds": a.monitor.Builds()})
+		}
+	case len(parts) == 3 && parts[1] == "builds":
+		if apiMethod(w, r, http.MethodGet) {
+			status, err := a.monitor.Build(parts[2])
+			if err != nil {
+				apiError(w, http.StatusNotFound, err)
+				return
+			}
+			apiReply(w, status)
+		}
+	case len(parts) == 2 && parts[1] == "logs":
+		if apiMethod(w, r, http.MethodGet) {
+			a.logs(w, r)
+		}
+	case len(parts) == 4 && parts[1] == "builds":
+		if apiMethod(w, r, http.MethodPost) {
+			a.control(w, parts[3], []string{parts[2]})
+		}
+	case len(parts) == 2:
+		if apiMethod(w, r, http.MethodPost) {
+			a.controlAll(w, parts[1])
+		}
+	default:
+		apiError(w, http.StatusNotFound, fmt.Errorf("not found"))
+	}
+}
+
+func (a *buildAPI) logs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var after int64
+	var limit int
+	var err error
+	if s := q.Get("after"); s != "" {
+		if after, err = strconv.ParseInt(s, 10, 64); err != nil {
+			apiError(w, http.StatusBadRequest, fmt.Errorf("invalid after: %s", err))
+			return
+		}
+	}
+	if s := q.Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil {
+			apiError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %s", err))
+			return
+		}
+	}
+	build := q.Get("build")
+	if build != "" {
+		if _, err := a.monitor.Build(build); err != nil {
+			apiError(w, http.StatusNotFound, err)
+			return
+		}
+	}
+	apiReply(w, map[string]interface{}{"lines": a.monitor.Lines(build, after, limit)})
+}
+
+// control runs action on the builds called names.
+func (a *buildAPI) control(w http.ResponseWriter, action string, names []string) {
+	var fn func(string) error
+	switch action {
+	case "pause":
+		fn = a.monitor.Pause
+	case "resume":
+		fn = a.monitor.Resume
+	case "cancel":
+		fn = a.monitor.Cancel
+	default:
+		apiError(w, http.StatusNotFound, fmt.Errorf("unknown action %q", action))
+		return
+	}
+	for _, name := range names {
+		switch err := fn(name); err {
+		case nil:
+			log.Printf("API: %s build %s", action, name)
+		case packer.ErrUnknownBuild:
+			apiError(w, http.StatusNotFound, err)
+			return
+		case packer.ErrBuildFinished:
+			if len(names) == 1 {
+				apiError(w, http.StatusConflict, err)
+				return
+			}
+		default:
+			apiError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// controlAll runs action on all the builds.
+func (a *buildAPI) controlAll(w http.ResponseWriter, action string) {
+	if action == "cancel" {
+		log.Printf("API: cancel all builds")
+		a.monitor.CancelAll()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	var names []string
+	for _, b := range a.monitor.Builds() {
+		names = append(names, b.Name)
+	}
+	a.control(w, action, names)
+}
+
+// apiMethod replies with an error when the method of r is not method.
+func apiMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	apiError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+func apiReply(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[ERR] Failed to write the API reply: %s", err)
+	}
+}
+
+func apiError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// isLoopback tells whether host is localhost or a loopback address.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
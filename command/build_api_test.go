@@ -0,0 +1,157 @@
+package command
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func testBuildAPI(t *testing.T) (*buildAPI, packer.Ui) {
+	monitor := &packer.BuildMonitor{}
+	ui := monitor.BuildUi("vm", packer.TestUi(t))
+	monitor.BuildUi("other", packer.TestUi(t))
+	monitor.Started("vm")
+	return &buildAPI{monitor: monitor}, ui
+}
+
+func apiRequest(a *buildAPI, method, target string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	r.Host = "127.0.0.1:8080"
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, r)
+	return w
+}
+
+func TestBuildAPI_builds(t *testing.T) {
+	a, ui := testBuildAPI(t)
+	ui.Machine("vm,"+packer.EventStepStarted, "StepCreateVM")
+
+	w := apiRequest(a, "GET", "/v1/builds")
+	if w.Code != http.StatusOK {
+		t.Fatalf("bad code: %d %s", w.Code, w.Body)
+	}
+	var reply struct {
+		Builds []packer.BuildStatus `json:"builds"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Builds) != 2 || reply.Builds[0].Step != "StepCreateVM" || reply.Builds[1].State != packer.BuildStatePending {
+		t.Fatalf("bad builds: %#v", reply.Builds)
+	}
+
+	w = apiRequest(a, "GET", "/v1/builds/vm")
+	var status packer.BuildStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Name != "vm" || status.State != packer.BuildStateRunning {
+		t.Fatalf("bad status: %#v", status)
+	}
+
+	if w := apiRequest(a, "GET", "/v1/builds/nope"); w.Code != http.StatusNotFound {
+		t.Fatalf("bad code: %d", w.Code)
+	}
+	if w := apiRequest(a, "DELETE", "/v1/builds"); w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("bad code: %d", w.Code)
+	}
+}
+
+func TestBuildAPI_logs(t *testing.T) {
+	a, ui := testBuildAPI(t)
+	ui.Say("one")
+	ui.Error("two")
+
+	w := apiRequest(a, "GET", "/v1/logs?build=vm&limit=1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("bad code: %d %s", w.Code, w.Body)
+	}
+	var reply struct {
+		Lines []packer.LogLine `json:"lines"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Lines) != 1 || reply.Lines[0].Message != "two" || reply.Lines[0].Level != "error" {
+		t.Fatalf("bad lines: %#v", reply.Lines)
+	}
+
+	if w := apiRequest(a, "GET", "/v1/logs?after=x"); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad code: %d", w.Code)
+	}
+}
+
+func TestBuildAPI_controls(t *testing.T) {
+	a, _ := testBuildAPI(t)
+
+	if w := apiRequest(a, "POST", "/v1/builds/vm/pause"); w.Code != http.StatusNoContent {
+		t.Fatalf("bad code: %d %s", w.Code, w.Body)
+	}
+	if s, _ := a.monitor.Build("vm"); !s.Paused {
+		t.Fatal("the build is not paused")
+	}
+	if w := apiRequest(a, "POST", "/v1/resume"); w.Code != http.StatusNoContent {
+		t.Fatalf("bad code: %d %s", w.Code, w.Body)
+	}
+	if s, _ := a.monitor.Build("vm"); s.Paused {
+		t.Fatal("the build is paused")
+	}
+
+	if w := apiRequest(a, "POST", "/v1/builds/vm/cancel"); w.Code != http.StatusNoContent {
+		t.Fatalf("bad code: %d %s", w.Code, w.Body)
+	}
+	a.monitor.Finished("vm", nil)
+	if w := apiRequest(a, "POST", "/v1/builds/vm/pause"); w.Code != http.StatusConflict {
+		t.Fatalf("bad code: %d", w.Code)
+	}
+	if w := apiRequest(a, "POST", "/v1/builds/vm/explode"); w.Code != http.StatusNotFound {
+		t.Fatalf("bad code: %d", w.Code)
+	}
+	if w := apiRequest(a, "GET", "/v1/builds/vm/cancel"); w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("bad code: %d", w.Code)
+	}
+}
+
+func TestBuildAPI_forbidden(t *testing.T) {
+	a, _ := testBuildAPI(t)
+
+	r := httptest.NewRequest("GET", "/v1/builds", nil)
+	r.Host = "evil.example.com"
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("bad code: %d", w.Code)
+	}
+
+	r = httptest.NewRequest("POST", "/v1/cancel", nil)
+	r.Host = "localhost:8080"
+	r.Header.Set("Origin", "http://evil.example.com")
+	w = httptest.NewRecorder()
+	a.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("bad code: %d", w.Code)
+	}
+}
+
+func TestListenBuildAPI(t *testing.T) {
+	if _, err := listenBuildAPI("0.0.0.0:0", &packer.BuildMonitor{}); err == nil {
+		t.Fatal("expected an error listening on all the interfaces")
+	}
+
+	a, err := listenBuildAPI("127.0.0.1:0", &packer.BuildMonitor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	resp, err := http.Get("http://" + a.Addr() + "/v1/builds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("bad code: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,315 @@
+package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common/state"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/version"
+)
+
+// buildCache skips the builds whose inputs didn't change since a previous
+// successful build, reusing its artifacts. The inputs of a build are the
+// version of Packer, the files of the template, its variables, the files the
+// template references and the outputs of the builds the build depends on.
+type buildCache struct {
+	backend state.Backend
+	// inputs is the hash of the inputs shared by all the builds.
+	inputs []byte
+}
+
+// newBuildCache returns the cache of the builds of cla, stored in backend,
+// or in the packer cache directory when backend is nil.
+func newBuildCache(cla *BuildArgs, backend state.Backend) (*buildCache, error) {
+	if backend == nil {
+		dir, err := packer.CachePath("builds")
+		if err != nil {
+			return nil, err
+		}
+		backend = state.NewLocal(dir)
+	}
+	inputs, err := hashBuildInputs(cla)
+	if err != nil {
+		return nil, err
+	}
+	return &buildCache{backend: backend, inputs: inputs}, nil
+}
+
+// key returns the hash of the inputs of the build named name, which depends
+// on the builds whose outputs are depOutputs.
+func (c *buildCache) key(name string, depOutputs packer.BuildOutputs) string {
+	h := sha256.New()
+	h.Write(c.inputs)
+	writeHashParts(h, "build", name)
+	var deps []string
+	for dep := range depOutputs {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+	for _, dep := range deps {
+		outputs := depOutputs[dep]
+		var keys []string
+		for k := range outputs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeHashParts(h, "output", dep, k, outputs[k])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lookup returns the artifacts of the previous build with the given key, nil
+// when there is none.
+func (c *buildCache) lookup(ctx context.Context, key string) ([]packer.Artifact, *state.CacheEntry, error) {
+	e, err := c.backend.Cached(ctx, key)
+	if err != nil || e == nil {
+		return nil, nil, err
+	}
+	artifacts := make([]packer.Artifact, 0, len(e.Artifacts))
+	for _, a := range e.Artifacts {
+		artifacts = append(artifacts, &cachedArtifact{a})
+	}
+	return artifacts, e, nil
+}
+
+// record records the artifacts of the successful build named name under key.
+func (c *buildCache) record(ctx context.Context, key, name string, artifacts []packer.Artifact) error {
+	host, _ := os.Hostname()
+	e := &state.CacheEntry{
+		Key:     key,
+		Build:   name,
+		Host:    host,
+		Created: time.Now().UTC(),
+	}
+	for _, a := range artifacts {
+		if a == nil {
+			continue
+		}
+		e.Artifacts = append(e.Artifacts, state.CachedArtifact{
+			BuilderID: a.BuilderId(),
+			ID:        a.Id(),
+			Files:     a.Files(),
+			String:    a.String(),
+		})
+	}
+	return c.backend.PutCached(ctx, e)
+}
+
+// lookupCache returns the artifacts of the previous build with the same
+// inputs as the build of ui, nil when there is none or when the build is
+// forced. Failing to read the cache only gets reported, the build runs then.
+func lookupCache(ctx context.Context, cache *buildCache, key string, force bool, ui packer.Ui) []packer.Artifact {
+	if force {
+		return nil
+	}
+	artifacts, e, err := cache.lookup(ctx, key)
+	if err != nil {
+		ui.Error(fmt.Sprintf("Failed to read the build cache, building: %s", err))
+		return nil
+	}
+	if e == nil {
+		ui.Say("No cached build with the same inputs, building")
+		return nil
+	}
+	ui.Say(fmt.Sprintf("Inputs unchanged since the build of %s on %s, reusing its artifacts",
+		e.Created.Format(time.RFC3339), e.Host))
+	return artifacts
+}
+
+// cachedArtifact is an artifact of a previous build, reused by a cached
+// build.
+type cachedArtifact struct {
+	a state.CachedArtifact
+}
+
+func (a *cachedArtifact) BuilderId() string             { return a.a.BuilderID }
+func (a *cachedArtifact) Files() []string               { return a.a.Files }
+func (a *cachedArtifact) Id() string                    { return a.a.ID }
+func (a *cachedArtifact) String() string                { return a.a.String }
+func (a *cachedArtifact) State(name string) interface{} { return nil }
+
+// Destroy does nothing: the artifact belongs to the build that created it.
+func (a *cachedArtifact) Destroy() error { return nil }
+
+// templateFileExts are the extensions of the files of a template
+// directory that are part of the inputs of its builds.
+var templateFileExts = []string{".pkr.hcl", ".pkr.json", ".pkrvars.hcl", ".pkrvars.json"}
+
+// quotedString matches the string literals of HCL and JSON templates.
+var quotedString = regexp.MustCompile(`"((?:[^"\\\n]|\\.)*)"`)
+
+// hashBuildInputs returns the hash of the inputs shared by the builds of
+// cla.
+func hashBuildInputs(cla *BuildArgs) ([]byte, error) {
+	if cla.Path == "" || cla.Path == "-" {
+		return nil, fmt.Errorf("the build cache needs a template file")
+	}
+
+	h := sha256.New()
+	writeHashParts(h, "packer", version.FormattedVersion())
+
+	var files []string
+	dir := cla.Path
+	if isDir, _ := isDir(cla.Path); isDir {
+		entries, err := ioutil.ReadDir(cla.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			for _, ext := range templateFileExts {
+				if !e.IsDir() && strings.HasSuffix(e.Name(), ext) {
+					files = append(files, filepath.Join(cla.Path, e.Name()))
+					break
+				}
+			}
+		}
+	} else {
+		files = append(files, cla.Path)
+		dir = filepath.Dir(cla.Path)
+	}
+	cfgType, err := cla.GetConfigType()
+	if err != nil {
+		return nil, err
+	}
+	profileVarFiles, err := cla.ProfileVarFiles(cfgType)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, profileVarFiles...)
+	files = append(files, cla.VarFiles...)
+
+	// Files referenced by the template, like provisioner scripts, with
+	// paths relative to the template directory.
+	referenced := map[string]bool{}
+	for _, file := range files {
+		if err := hashFile(h, dir, file); err != nil {
+			return nil, err
+		}
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range referencedFiles(string(contents), dir) {
+			referenced[path] = true
+		}
+	}
+	var paths []string
+	for path := range referenced {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := hashFile(h, dir, path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Inputs the template can't tell about, like files referenced through
+	// variables.
+	for _, path := range cla.CacheInputs {
+		if err := hashTree(h, path); err != nil {
+			return nil, err
+		}
+	}
+
+	var keys []string
+	for k := range cla.Vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeHashParts(h, "var", k, cla.Vars[k])
+	}
+	var env []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "PKR_VAR_") {
+			env = append(env, kv)
+		}
+	}
+	sort.Strings(env)
+	for _, kv := range env {
+		writeHashParts(h, "env", kv)
+	}
+
+	return h.Sum(nil), nil
+}
+
+// referencedFiles returns the regular files of dir named by the string
+// literals of a template, like "scripts/setup.sh" or
+// "${path.root}/scripts/setup.sh". Absolute paths are left out, they
+// usually are paths of the machine being built.
+func referencedFiles(contents, dir string) []string {
+	var paths []string
+	for _, m := range quotedString.FindAllStringSubmatch(contents, -1) {
+		path := m[1]
+		for _, prefix := range []string{"${path.root}/", "{{template_dir}}/", "{{ template_dir }}/", "./"} {
+			path = strings.TrimPrefix(path, prefix)
+		}
+		if path == "" || filepath.IsAbs(path) || strings.Contains(path, "${") ||
+			strings.Contains(path, "{{") || strings.Contains(path, "://") {
+			continue
+		}
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// hashTree hashes the file at root, or the files of the directory at root.
+func hashTree(h hash.Hash, root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return hashFile(h, filepath.Dir(root), path)
+	})
+}
+
+// hashFile hashes the name of the file at path relative to base, so that
+// the hash doesn't depend on where the template is checked out, and its
+// contents.
+func hashFile(h hash.Hash, base, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	name := path
+	if rel, err := filepath.Rel(base, path); err == nil {
+		name = rel
+	}
+	writeHashParts(h, "file", filepath.ToSlash(name))
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	h.Write([]byte{0})
+	return nil
+}
+
+// writeHashParts writes parts to h, separated so that different parts never
+// hash the same.
+func writeHashParts(h hash.Hash, parts ...string) {
+	for _, p := range parts {
+		io.WriteString(h, p)
+		h.Write([]byte{0})
+	}
+}
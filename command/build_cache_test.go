@@ -0,0 +1,56 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestReferencedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"scripts/a.sh", "scripts/b.sh", "c.sh"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	contents := `
+  script = "${path.root}/scripts/a.sh"
+  scripts = ["./scripts/b.sh", "/usr/bin/c.sh", "${var.dir}/c.sh", "missing.sh", "scripts"]
+`
+	got := referencedFiles(contents, dir)
+	want := []string{
+		filepath.Join(dir, "scripts", "a.sh"),
+		filepath.Join(dir, "scripts", "b.sh"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected referenced files: %s", diff)
+	}
+}
+
+func TestBuildCache_key(t *testing.T) {
+	c := &buildCache{inputs: []byte("inputs")}
+
+	key := c.key("a", nil)
+	if key == c.key("b", nil) {
+		t.Fatal("builds with different names should have different keys")
+	}
+	deps := packer.BuildOutputs{"b": {"id": "1"}}
+	if key == c.key("a", deps) {
+		t.Fatal("dependencies should change the key")
+	}
+	if c.key("a", deps) == c.key("a", packer.BuildOutputs{"b": {"id": "2"}}) {
+		t.Fatal("outputs of dependencies should change the key")
+	}
+}
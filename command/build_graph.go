@@ -0,0 +1,136 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/packer/packer"
+	"golang.org/x/sync/semaphore"
+)
+
+// buildOutputs are the outputs of the builds that succeeded so far.
+type buildOutputs struct {
+	sync.RWMutex
+	m packer.BuildOutputs
+}
+
+// prepareDependentBuild waits for the builds b depends on, whose done
+// channels are closed when they finish, and prepares b with their outputs. It
+// fails when one of them did not succeed.
+func prepareDependentBuild(ctx context.Context, b packer.Build, done map[string]chan struct{}, outputs *buildOutputs) ([]string, error) {
+	deps := packer.BuildDependencies(b)
+	for _, d := range deps {
+		select {
+		case <-done[d]:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	outputs.RLock()
+	depOutputs := make(packer.BuildOutputs, len(deps))
+	for _, d := range deps {
+		if o, ok := outputs.m[d]; ok {
+			depOutputs[d] = o
+		}
+	}
+	outputs.RUnlock()
+	for _, d := range deps {
+		if _, ok := depOutputs[d]; !ok {
+			return nil, fmt.Errorf("build '%s', which it depends on, didn't succeed", d)
+		}
+	}
+
+	b.(packer.DependentBuild).SetOutputs(depOutputs)
+	return b.Prepare()
+}
+
+// dependencies returns the outputs of the builds b depends on that
+// succeeded.
+func (o *buildOutputs) dependencies(b packer.Build) packer.BuildOutputs {
+	o.RLock()
+	defer o.RUnlock()
+	deps := packer.BuildOutputs{}
+	for _, d := range packer.BuildDependencies(b) {
+		if outputs, ok := o.m[d]; ok {
+			deps[d] = outputs
+		}
+	}
+	return deps
+}
+
+// hasNamedOutputs tells whether one of builds declares named outputs, in
+// which case the outputs of the builds are recorded in the outputs file.
+func hasNamedOutputs(builds []packer.Build) bool {
+	for _, b := range builds {
+		if cb, ok := b.(*packer.CoreBuild); ok && cb.NamedOutputs != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSemaphores are the named semaphores of the semaphore blocks, each
+// limiting how many of the builds it selects run at once.
+type buildSemaphores struct {
+	sync.Mutex
+	m map[string]*semaphore.Weighted
+}
+
+// semaphoreLimits returns the limits of the semaphores of b, by name.
+func semaphoreLimits(b packer.Build) map[string]int {
+	if cb, ok := b.(*packer.CoreBuild); ok {
+		return cb.Semaphores
+	}
+	return nil
+}
+
+// acquire takes a slot of each semaphore of b, and returns the function
+// releasing them. The semaphores are acquired in the order of their names so
+// that the builds sharing several semaphores don't deadlock.
+func (s *buildSemaphores) acquire(ctx context.Context, b packer.Build, ui packer.Ui) (func(), error) {
+	limits := semaphoreLimits(b)
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var acquired []*semaphore.Weighted
+	release := func() {
+		for _, sem := range acquired {
+			sem.Release(1)
+		}
+	}
+	for _, name := range names {
+		sem := s.get(name, limits[name])
+		if !sem.TryAcquire(1) {
+			ui.Say(fmt.Sprintf("Build '%s' waiting for a slot of semaphore '%s'...", b.Name(), name))
+			if err := sem.Acquire(ctx, 1); err != nil {
+				release()
+				return nil, fmt.Errorf("waiting for semaphore '%s': %s", name, err)
+			}
+		}
+		log.Printf("Build '%s' acquired semaphore '%s'", b.Name(), name)
+		acquired = append(acquired, sem)
+	}
+	return release, nil
+}
+
+// get returns the semaphore named name, created with limit slots.
+func (s *buildSemaphores) get(name string, limit int) *semaphore.Weighted {
+	s.Lock()
+	defer s.Unlock()
+	if s.m == nil {
+		s.m = map[string]*semaphore.Weighted{}
+	}
+	sem, ok := s.m[name]
+	if !ok {
+		sem = semaphore.NewWeighted(int64(limit))
+		s.m[name] = sem
+	}
+	return sem
+}
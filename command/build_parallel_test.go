@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 
@@ -170,3 +171,34 @@ func TestBuildParallel_Timeout(t *testing.T) {
 	close(locked.unlock) // unlock locking one
 	wg.Wait()            // wait for termination
 }
+
+func TestBuildSemaphores(t *testing.T) {
+	s := &buildSemaphores{}
+	ui := packer.TestUi(t)
+	first := &packer.CoreBuild{Type: "first", Semaphores: map[string]int{"vsphere": 1, "all": 2}}
+	second := &packer.CoreBuild{Type: "second", Semaphores: map[string]int{"vsphere": 1}}
+	other := &packer.CoreBuild{Type: "other", Semaphores: map[string]int{"all": 2}}
+
+	release, err := s.acquire(context.Background(), first, ui)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The semaphore shared with the first build has no slot left.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.acquire(ctx, second, ui); err == nil {
+		t.Fatal("the second build shouldn't get a slot of the vsphere semaphore")
+	}
+	releaseOther, err := s.acquire(context.Background(), other, ui)
+	if err != nil {
+		t.Fatalf("the other build should get a slot of the all semaphore: %s", err)
+	}
+	releaseOther()
+
+	release()
+	releaseSecond, err := s.acquire(context.Background(), second, ui)
+	if err != nil {
+		t.Fatalf("the second build should get a slot once the first released it: %s", err)
+	}
+	releaseSecond()
+}
@@ -2,6 +2,7 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -363,6 +364,50 @@ Build 'null.example' finished.
 	}
 }
 
+func TestBuildEventStream(t *testing.T) {
+	c := &BuildCommand{
+		Meta: testMetaFile(t),
+	}
+
+	dir, err := ioutil.TempDir("", "packer-events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	eventsFile := filepath.Join(dir, "events.jsonl")
+
+	args := []string{
+		"-event-stream=" + eventsFile,
+		filepath.Join(testFixture("event-stream"), "template.json"),
+	}
+
+	defer cleanup()
+
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+
+	b, err := ioutil.ReadFile(eventsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var e packer.Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("bad event %q: %s", line, err)
+		}
+		if e.Build != "chocolate" {
+			t.Fatalf("bad build in event %q", line)
+		}
+		types = append(types, e.Type)
+	}
+	expected := []string{packer.EventBuildStarted, packer.EventArtifact, packer.EventBuildFinished}
+	if diff := cmp.Diff(expected, types); diff != "" {
+		t.Fatalf("unexpected events: %s", diff)
+	}
+}
+
 func TestBuildOnlyFileCommaFlags(t *testing.T) {
 	c := &BuildCommand{
 		Meta: testMetaFile(t),
@@ -822,6 +867,7 @@ func TestBuildCommand_ParseArgs(t *testing.T) {
 				MetaArgs:       MetaArgs{Path: "file.json"},
 				ParallelBuilds: math.MaxInt64,
 				Color:          true,
+				OutputsFile:    "packer-outputs.json",
 			},
 			0,
 		},
@@ -831,6 +877,7 @@ func TestBuildCommand_ParseArgs(t *testing.T) {
 				MetaArgs:       MetaArgs{Path: "file.json"},
 				ParallelBuilds: 10,
 				Color:          true,
+				OutputsFile:    "packer-outputs.json",
 			},
 			0,
 		},
@@ -840,6 +887,7 @@ func TestBuildCommand_ParseArgs(t *testing.T) {
 				MetaArgs:       MetaArgs{Path: "file.json"},
 				ParallelBuilds: 1,
 				Color:          true,
+				OutputsFile:    "packer-outputs.json",
 			},
 			0,
 		},
@@ -849,6 +897,7 @@ func TestBuildCommand_ParseArgs(t *testing.T) {
 				MetaArgs:       MetaArgs{Path: "file.json"},
 				ParallelBuilds: 5,
 				Color:          true,
+				OutputsFile:    "packer-outputs.json",
 			},
 			0,
 		},
@@ -858,9 +907,32 @@ func TestBuildCommand_ParseArgs(t *testing.T) {
 				MetaArgs:       MetaArgs{Path: "otherfile.json"},
 				ParallelBuilds: 5,
 				Color:          true,
+				OutputsFile:    "packer-outputs.json",
+			},
+			0,
+		},
+		{fields{defaultMeta},
+			args{[]string{"-replace", "file.json"}},
+			&BuildArgs{
+				MetaArgs:       MetaArgs{Path: "file.json"},
+				ParallelBuilds: math.MaxInt64,
+				Color:          true,
+				Replace:        true,
+				OutputsFile:    "packer-outputs.json",
 			},
 			0,
 		},
+		{fields{defaultMeta},
+			args{[]string{"-force", "-skip-if-exists", "file.json"}},
+			&BuildArgs{
+				ParallelBuilds: math.MaxInt64,
+				Color:          true,
+				Force:          true,
+				SkipIfExists:   true,
+				OutputsFile:    "packer-outputs.json",
+			},
+			1,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("%s", tt.args.args), func(t *testing.T) {
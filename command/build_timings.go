@@ -0,0 +1,50 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// writeTimings shows the timings of the builds with -timings, and writes them
+// to the files of -timings-json and -timings-flamegraph. Failing to write a
+// file doesn't fail the build, the builds are done.
+func writeTimings(ui packer.Ui, timings *packer.Timings, cla *BuildArgs) {
+	if cla.Timings {
+		if table := timings.Table(); table != "" {
+			ui.Say("\n==> Timings of the builds:")
+			ui.Say(strings.TrimRight(table, "\n"))
+		}
+	}
+
+	files := []struct {
+		path  string
+		write func(io.Writer) error
+	}{
+		{cla.TimingsJSON, timings.WriteJSON},
+		{cla.TimingsFlamegraph, timings.WriteFolded},
+	}
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+		if err := writeTimingsFile(f.path, f.write); err != nil {
+			ui.Error(fmt.Sprintf("Failed to write the timings of the builds to %s: %s", f.path, err))
+		}
+	}
+}
+
+func writeTimingsFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
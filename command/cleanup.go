@@ -0,0 +1,121 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	awscommon "github.com/hashicorp/packer/builder/amazon/common"
+	azurearmbuilder "github.com/hashicorp/packer/builder/azure/arm"
+	googlecomputebuilder "github.com/hashicorp/packer/builder/googlecompute"
+	openstackbuilder "github.com/hashicorp/packer/builder/openstack"
+	registry "github.com/hashicorp/packer/common/cleanup"
+	"github.com/posener/complete"
+)
+
+// Sweepers are used by `packer cleanup` to remove the resources of each
+// provider.
+var Sweepers = map[string]registry.SweepFunc{
+	awscommon.CleanupProvider:            awscommon.SweepResource,
+	azurearmbuilder.CleanupProvider:      azurearmbuilder.SweepResource,
+	googlecomputebuilder.CleanupProvider: googlecomputebuilder.SweepResource,
+	openstackbuilder.CleanupProvider:     openstackbuilder.SweepResource,
+}
+
+type CleanupCommand struct {
+	Meta
+}
+
+func (c *CleanupCommand) Run(args []string) int {
+	ctx, stop := handleTermInterrupt(c.Ui)
+	defer stop()
+
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(ctx, cfg)
+}
+
+func (c *CleanupCommand) ParseArgs(args []string) (*CleanupArgs, int) {
+	var cfg CleanupArgs
+	flags := c.Meta.FlagSet("cleanup", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if len(flags.Args()) != 0 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	return &cfg, 0
+}
+
+func (c *CleanupCommand) RunContext(ctx context.Context, cla *CleanupArgs) int {
+	dir, err := registry.Dir()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error finding cleanup directory: %s", err))
+		return 1
+	}
+
+	records, err := registry.Orphans(dir)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading cleanup records: %s", err))
+		return 1
+	}
+	if len(records) == 0 {
+		c.Ui.Say("No orphaned resources found.")
+		return 0
+	}
+
+	ret := 0
+	for _, rec := range records {
+		c.Ui.Say(rec.Summary())
+		if cla.DryRun {
+			continue
+		}
+		if err := registry.Sweep(ctx, rec, Sweepers, c.Ui); err != nil {
+			c.Ui.Error(fmt.Sprintf("Some resources could not be removed: %s", err))
+			ret = 1
+		}
+	}
+	return ret
+}
+
+func (*CleanupCommand) Help() string {
+	helpText := `
+Usage: packer cleanup [options]
+
+  Removes the resources left behind by builds that crashed or were killed
+  before they could clean up, like instances, key pairs or security groups.
+
+  The Amazon EC2, Azure ARM, Google Compute and OpenStack builders record the
+  resources they create in the cleanup directory of the Packer configuration
+  directory, or in PACKER_CLEANUP_DIR when it is set. Resources of builds
+  that are still running are never touched. The default credentials of the
+  environment are used to remove resources.
+
+Options:
+
+  -dry-run                      List the orphaned resources without removing them.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*CleanupCommand) Synopsis() string {
+	return "removes resources left behind by interrupted builds"
+}
+
+func (*CleanupCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*CleanupCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-dry-run": complete.PredictNothing,
+	}
+}
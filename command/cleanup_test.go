@@ -0,0 +1,66 @@
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	registry "github.com/hashicorp/packer/common/cleanup"
+)
+
+func TestCleanup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-cleanup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("PACKER_CLEANUP_DIR", dir)
+	defer os.Unsetenv("PACKER_CLEANUP_DIR")
+
+	rec := &registry.Record{
+		Path:    filepath.Join(dir, "orphan.json"),
+		PID:     math.MaxInt32,
+		Started: time.Now(),
+		Resources: []registry.Resource{
+			{Provider: "mock", Type: "instance", ID: "i-1"},
+		},
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	var swept []string
+	Sweepers["mock"] = func(_ context.Context, r registry.Resource) error {
+		swept = append(swept, r.ID)
+		return nil
+	}
+	defer delete(Sweepers, "mock")
+
+	c := &CleanupCommand{
+		Meta: testMeta(t),
+	}
+	if code := c.Run([]string{"-dry-run"}); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+	if out, _ := outputCommand(t, c.Meta); !strings.Contains(out, "mock instance i-1") {
+		t.Fatalf("bad output: %s", out)
+	}
+	if len(swept) != 0 {
+		t.Fatalf("dry-run should not remove resources: %v", swept)
+	}
+
+	if code := c.Run(nil); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+	if len(swept) != 1 || swept[0] != "i-1" {
+		t.Fatalf("bad: %v", swept)
+	}
+	if _, err := os.Stat(rec.Path); !os.IsNotExist(err) {
+		t.Fatalf("record should be deleted: %v", err)
+	}
+}
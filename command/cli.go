@@ -2,11 +2,20 @@ package command
 
 import (
 	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/packer/common/agent"
+	"github.com/hashicorp/packer/common/artifacts"
+	"github.com/hashicorp/packer/common/registry"
+	"github.com/hashicorp/packer/common/state"
 	"github.com/hashicorp/packer/helper/enumflag"
 	kvflag "github.com/hashicorp/packer/helper/flag-kv"
 	sliceflag "github.com/hashicorp/packer/helper/flag-slice"
+	"github.com/hashicorp/packer/packer"
 )
 
 //go:generate enumer -type configType -trimprefix ConfigType -transform snake
@@ -49,12 +58,50 @@ func (ma *MetaArgs) GetConfigType() (configType, error) {
 	return ma.ConfigType, err
 }
 
+// profileVarFileExts are the extensions of the var files making a profile.
+var profileVarFileExts = []string{".pkrvars.hcl", ".pkrvars.json"}
+
+// ProfileVarFiles returns the var files of the selected profile. A profile
+// named "staging" is made of the staging.pkrvars.hcl and
+// staging.pkrvars.json files found next to the config. Legacy JSON templates
+// can only use the JSON file. It is an error to select a profile that has no
+// file.
+func (ma *MetaArgs) ProfileVarFiles(cfgType configType) ([]string, error) {
+	if ma.Profile == "" {
+		return nil, nil
+	}
+	if strings.ContainsAny(ma.Profile, `/\`) {
+		return nil, fmt.Errorf("invalid profile name %q", ma.Profile)
+	}
+
+	dir := ma.Path
+	if isDir, _ := isDir(dir); !isDir {
+		dir = filepath.Dir(dir)
+	}
+
+	var files []string
+	for _, ext := range profileVarFileExts {
+		if cfgType != ConfigTypeHCL2 && ext != ".pkrvars.json" {
+			continue
+		}
+		file := filepath.Join(dir, ma.Profile+ext)
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no var file found for profile %q in %s", ma.Profile, dir)
+	}
+	return files, nil
+}
+
 // NewMetaArgs parses cli args and put possible values
 func (ma *MetaArgs) AddFlagSets(fs *flag.FlagSet) {
 	fs.Var((*sliceflag.StringFlag)(&ma.Only), "only", "")
 	fs.Var((*sliceflag.StringFlag)(&ma.Except), "except", "")
 	fs.Var((*kvflag.Flag)(&ma.Vars), "var", "")
 	fs.Var((*kvflag.StringSlice)(&ma.VarFiles), "var-file", "")
+	fs.StringVar(&ma.Profile, "profile", "", "")
 	fs.Var(&ma.ConfigType, "config-type", "set to 'hcl2' to run in hcl2 mode when no file is passed.")
 }
 
@@ -66,6 +113,9 @@ type MetaArgs struct {
 	Only, Except []string
 	Vars         map[string]string
 	VarFiles     []string
+	// Profile is the name of the set of var files to load before the ones
+	// passed with -var-file.
+	Profile string
 	// set to "hcl2" to force hcl2 mode
 	ConfigType configType
 }
@@ -74,14 +124,36 @@ func (ba *BuildArgs) AddFlagSets(flags *flag.FlagSet) {
 	flags.BoolVar(&ba.Color, "color", true, "")
 	flags.BoolVar(&ba.Debug, "debug", false, "")
 	flags.BoolVar(&ba.Force, "force", false, "")
+	flags.BoolVar(&ba.Replace, "replace", false, "")
+	flags.BoolVar(&ba.SkipIfExists, "skip-if-exists", false, "")
 	flags.BoolVar(&ba.TimestampUi, "timestamp-ui", false, "")
 	flags.BoolVar(&ba.MachineReadable, "machine-readable", false, "")
+	flags.BoolVar(&ba.Step, "step", false, "")
+	flags.Var((*sliceflag.StringFlag)(&ba.Breakpoints), "breakpoint", "")
+	flags.Var((*kvflag.Flag)(&ba.BillingTags), "billing-tags", "")
 
 	flags.Int64Var(&ba.ParallelBuilds, "parallel-builds", 0, "")
+	flags.StringVar(&ba.EventStream, "event-stream", "", "")
+	flags.StringVar(&ba.APIListen, "api-listen", "", "")
+	flags.StringVar(&ba.OutputsFile, "outputs-file", defaultOutputsFile, "")
+	flags.StringVar(&ba.State, "state", os.Getenv(state.EnvBackend), "")
+	flags.DurationVar(&ba.StateLockTimeout, "state-lock-timeout", 0, "")
+	flags.BoolVar(&ba.Cache, "cache", false, "")
+	flags.Var((*sliceflag.StringFlag)(&ba.CacheInputs), "cache-input", "")
+	flags.StringVar(&ba.ArtifactsStore, "artifacts-store", os.Getenv(artifacts.EnvStore), "")
+	flags.BoolVar(&ba.Timings, "timings", false, "")
+	flags.StringVar(&ba.TimingsJSON, "timings-json", "", "")
+	flags.StringVar(&ba.TimingsFlamegraph, "timings-flamegraph", "", "")
 
 	flagOnError := enumflag.New(&ba.OnError, "cleanup", "abort", "ask", "run-cleanup-provisioner")
 	flags.Var(flagOnError, "on-error", "")
 
+	flagPreflight := enumflag.New(&ba.Preflight, packer.PreflightCheck, packer.PreflightOnly)
+	flags.Var(flagPreflight, "preflight", "")
+
+	flagUi := enumflag.New(&ba.Ui, "plain", "fancy", "json")
+	flags.Var(flagUi, "ui", "")
+
 	ba.MetaArgs.AddFlagSets(flags)
 }
 
@@ -91,6 +163,93 @@ type BuildArgs struct {
 	Color, Debug, Force, TimestampUi, MachineReadable bool
 	ParallelBuilds                                    int64
 	OnError                                           string
+	// Replace replaces the existing artifacts of the builds once the
+	// builds are about to create their own, rather than before building.
+	Replace bool
+	// SkipIfExists skips the builds whose artifacts already exist.
+	SkipIfExists bool
+	// Step pauses before every step of the builds.
+	Step bool
+	// Breakpoints are the names of the steps to pause before.
+	Breakpoints []string
+	// BillingTags are the tags the builders add to the temporary resources
+	// of the builds, to account for their cost.
+	BillingTags map[string]string
+	// Preflight makes the builders check that their credentials can
+	// perform all the operations of the builds before launching anything:
+	// packer.PreflightCheck, or packer.PreflightOnly to only check them.
+	Preflight string
+	// EventStream is the file or the unix socket, when prefixed with
+	// "unix:", to write the JSON lines event stream to.
+	EventStream string
+	// APIListen is the localhost address to serve the HTTP API monitoring
+	// and controlling the builds on, if any.
+	APIListen string
+	// OutputsFile is where the outputs of the builds are recorded, when
+	// builds declare named outputs.
+	OutputsFile string
+	// State is the address of the state backend recording the running
+	// builds and their locks, if any.
+	State string
+	// StateLockTimeout is how long a build waits for the names it locks
+	// to be unlocked by other builds.
+	StateLockTimeout time.Duration
+	// Cache skips the builds whose inputs didn't change since a previous
+	// successful build, reusing its artifacts.
+	Cache bool
+	// CacheInputs are files and directories the inputs of the builds
+	// include, on top of the files the template references.
+	CacheInputs []string
+	// ArtifactsStore is the address of the store the metadata of the
+	// builds is recorded in, the local store when empty, or
+	// artifacts.Disabled.
+	ArtifactsStore string
+	// Ui is how the output of the builds is shown: "plain", "fancy" for a
+	// live dashboard, or "json" for an event stream on the output.
+	Ui string
+	// Timings prints how long the steps, the provisioners and the
+	// post-processors of the builds took once they are done.
+	Timings bool
+	// TimingsJSON and TimingsFlamegraph are the files to write these
+	// timings to, as JSON and as the folded stacks of a flame graph.
+	TimingsJSON, TimingsFlamegraph string
+	// Args are the command line arguments of the build command, recorded
+	// in the journals of the builds to resume them.
+	Args []string
+	// Resume is the id of the build the build resumes, set by `packer
+	// resume`.
+	Resume string
+}
+
+// breakpoints returns the names of the steps to pause before.
+func (ba *BuildArgs) breakpoints() []string {
+	if ba.Step {
+		return []string{packer.BreakpointAll}
+	}
+	return ba.Breakpoints
+}
+
+// onConflict returns what to do when the artifact of a build already exists,
+// one of the packer.Conflict* modes, or an error when several were set.
+func (ba *BuildArgs) onConflict() (string, error) {
+	var modes []string
+	if ba.Force {
+		modes = append(modes, packer.ConflictForce)
+	}
+	if ba.Replace {
+		modes = append(modes, packer.ConflictReplace)
+	}
+	if ba.SkipIfExists {
+		modes = append(modes, packer.ConflictSkip)
+	}
+	switch len(modes) {
+	case 0:
+		return packer.ConflictFail, nil
+	case 1:
+		return modes[0], nil
+	default:
+		return "", fmt.Errorf("-force, -replace and -skip-if-exists are mutually exclusive")
+	}
 }
 
 // ConsoleArgs represents a parsed cli line for a `packer console`
@@ -110,8 +269,117 @@ type FixArgs struct {
 	Validate bool
 }
 
+func (fa *FormatArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.BoolVar(&fa.Check, "check", false, "")
+	flags.BoolVar(&fa.Diff, "diff", false, "")
+	flags.BoolVar(&fa.Write, "write", true, "")
+	flags.BoolVar(&fa.Recursive, "recursive", false, "")
+
+	fa.MetaArgs.AddFlagSets(flags)
+}
+
+// FormatArgs represents a parsed cli line for `packer fmt`
+type FormatArgs struct {
+	MetaArgs
+	Check, Diff, Write, Recursive bool
+}
+
+func (ia *InitArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.BoolVar(&ia.Upgrade, "upgrade", false, "")
+}
+
+// InitArgs represents a parsed cli line for a `packer init`
+type InitArgs struct {
+	MetaArgs
+	Upgrade bool
+}
+
+func (aa *AgentArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.StringVar(&aa.Address, "address", ":8181", "")
+	flags.StringVar(&aa.Token, "token", os.Getenv(agent.EnvToken), "")
+	flags.StringVar(&aa.WorkDir, "work-dir", "packer-agent-jobs", "")
+	flags.Int64Var(&aa.MaxJobs, "max-jobs", 1, "")
+	flags.StringVar(&aa.TLSCert, "tls-cert", "", "")
+	flags.StringVar(&aa.TLSKey, "tls-key", "", "")
+}
+
+// AgentArgs represents a parsed cli line for a `packer agent`
+type AgentArgs struct {
+	Address, Token  string
+	WorkDir         string
+	MaxJobs         int64
+	TLSCert, TLSKey string
+}
+
+func (ra *RemoteBuildArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.StringVar(&ra.Agent, "agent", os.Getenv(agent.EnvAddress), "")
+	flags.StringVar(&ra.Token, "token", os.Getenv(agent.EnvToken), "")
+	flags.StringVar(&ra.Root, "root", "", "")
+	flags.StringVar(&ra.CACert, "ca-cert", "", "")
+	flags.BoolVar(&ra.Force, "force", false, "")
+
+	flagOnError := enumflag.New(&ra.OnError, "cleanup", "abort", "run-cleanup-provisioner")
+	flags.Var(flagOnError, "on-error", "")
+
+	ra.MetaArgs.AddFlagSets(flags)
+}
+
+// RemoteBuildArgs represents a parsed cli line for a `packer remote-build`
+type RemoteBuildArgs struct {
+	MetaArgs
+	// Agent is the URL of the agent running the builds.
+	Agent, Token string
+	// Root is the directory uploaded to the agent, the directory of the
+	// configuration by default.
+	Root string
+	// CACert is the certificate authority of the TLS certificate of the
+	// agent, when not signed by a system one.
+	CACert  string
+	Force   bool
+	OnError string
+}
+
+func (ra *RegistryArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.StringVar(&ra.Registry, "registry", os.Getenv(registry.EnvAddress), "")
+}
+
+// RegistryArgs represents a parsed cli line for a `packer registry`
+// subcommand
+type RegistryArgs struct {
+	Registry string
+	Image    string
+	// Channel and BuildID are the channel and the build to promote.
+	Channel, BuildID string
+}
+
+func (sa *PluginScaffoldArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.StringVar(&sa.Module, "module", "", "")
+}
+
+// PluginScaffoldArgs represents a parsed cli line for a `packer plugin
+// scaffold`
+type PluginScaffoldArgs struct {
+	Kind, Name string
+	// Dir is the directory the plugin is generated in.
+	Dir    string
+	Module string
+}
+
+func (ua *HCL2UpgradeArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.StringVar(&ua.OutputFile, "output-file", "", "")
+
+	ua.MetaArgs.AddFlagSets(flags)
+}
+
+// HCL2UpgradeArgs represents a parsed cli line for a `packer hcl2_upgrade`
+type HCL2UpgradeArgs struct {
+	MetaArgs
+	OutputFile string
+}
+
 func (va *ValidateArgs) AddFlagSets(flags *flag.FlagSet) {
 	flags.BoolVar(&va.SyntaxOnly, "syntax-only", false, "check syntax only")
+	flags.BoolVar(&va.JSON, "json", false, "")
 
 	va.MetaArgs.AddFlagSets(flags)
 }
@@ -120,6 +388,7 @@ func (va *ValidateArgs) AddFlagSets(flags *flag.FlagSet) {
 type ValidateArgs struct {
 	MetaArgs
 	SyntaxOnly bool
+	JSON       bool
 }
 
 func (va *InspectArgs) AddFlagSets(flags *flag.FlagSet) {
@@ -130,3 +399,127 @@ func (va *InspectArgs) AddFlagSets(flags *flag.FlagSet) {
 type InspectArgs struct {
 	MetaArgs
 }
+
+func (ca *CleanupArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.BoolVar(&ca.DryRun, "dry-run", false, "")
+}
+
+// CleanupArgs represents a parsed cli line for a `packer cleanup`
+type CleanupArgs struct {
+	DryRun bool
+}
+
+// ResumeArgs represents a parsed cli line for a `packer resume`
+type ResumeArgs struct {
+	// BuildID is the id of the build to resume; the builds that can be
+	// resumed are listed when it is empty.
+	BuildID string
+}
+
+func (pa *PlanArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.BoolVar(&pa.JSON, "json", false, "")
+
+	pa.MetaArgs.AddFlagSets(flags)
+}
+
+// PlanArgs represents a parsed cli line for a `packer plan`
+type PlanArgs struct {
+	MetaArgs
+	JSON bool
+}
+
+func (la *LintArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.BoolVar(&la.JSON, "json", false, "")
+	flags.StringVar(&la.FailOn, "fail-on", "error", "")
+	flags.Var((*sliceflag.StringFlag)(&la.Disable), "disable", "")
+	flags.Var((*kvflag.StringSlice)(&la.RulesFiles), "rules", "")
+
+	la.MetaArgs.AddFlagSets(flags)
+}
+
+// LintArgs represents a parsed cli line for a `packer lint`
+type LintArgs struct {
+	MetaArgs
+	JSON bool
+	// FailOn is the lowest severity of the issues failing the command.
+	FailOn string
+	// Disable are the names of the rules not to run.
+	Disable []string
+	// RulesFiles are the files declaring custom rules.
+	RulesFiles []string
+}
+
+func (la *LspArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.StringVar(&la.DocsDir, "docs", "", "")
+}
+
+// LspArgs represents a parsed cli line for a `packer lsp`
+type LspArgs struct {
+	// DocsDir is the directory of the docs of the options of the components.
+	DocsDir string
+}
+
+func (sa *SchemaArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.StringVar(&sa.DocsDir, "docs", "", "")
+}
+
+// SchemaArgs represents a parsed cli line for a `packer schema`
+type SchemaArgs struct {
+	// DocsDir is the directory of the docs of the options of the components.
+	DocsDir string
+}
+
+func (oa *OutputArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.StringVar(&oa.File, "file", defaultOutputsFile, "")
+	flags.BoolVar(&oa.JSON, "json", false, "")
+}
+
+// OutputArgs represents a parsed cli line for a `packer output`
+type OutputArgs struct {
+	File        string
+	JSON        bool
+	Build, Name string
+}
+
+func (aa *ArtifactsArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.StringVar(&aa.Store, "store", os.Getenv(artifacts.EnvStore), "")
+	flags.BoolVar(&aa.JSON, "json", false, "")
+}
+
+// ArtifactsArgs represents a parsed cli line for a `packer artifacts`
+// subcommand
+type ArtifactsArgs struct {
+	Store string
+	JSON  bool
+	// Build and Template filter the records listed.
+	Build, Template string
+	// Limit is how many of the latest records are listed, all when 0.
+	Limit int
+	// Refs are the records shown or compared.
+	Refs []string
+}
+
+func (ia *ImageDiffArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.BoolVar(&ia.JSON, "json", false, "")
+	flags.BoolVar(&ia.ExitCode, "exit-code", false, "")
+}
+
+// ImageDiffArgs represents a parsed cli line for a `packer image-diff`
+type ImageDiffArgs struct {
+	JSON bool
+	// ExitCode makes the command exit with 2 when the images differ.
+	ExitCode bool
+	// Old and New are the inventory files of the images.
+	Old, New string
+}
+
+func (sa *StateArgs) AddFlagSets(flags *flag.FlagSet) {
+	flags.StringVar(&sa.State, "state", os.Getenv(state.EnvBackend), "")
+}
+
+// StateArgs represents a parsed cli line for a `packer state` subcommand
+type StateArgs struct {
+	State string
+	// Names are the locked names to unlock.
+	Names []string
+}
@@ -0,0 +1,54 @@
+package command
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMetaArgs_ProfileVarFiles(t *testing.T) {
+	dir := testFixture("profile")
+	tt := []struct {
+		name    string
+		args    MetaArgs
+		cfgType configType
+		want    []string
+		wantErr bool
+	}{
+		{"no profile", MetaArgs{Path: dir}, ConfigTypeHCL2, nil, false},
+		{"profile next to folder", MetaArgs{Path: dir, Profile: "staging"}, ConfigTypeHCL2,
+			[]string{filepath.Join(dir, "staging.pkrvars.hcl")}, false},
+		{"profile next to file", MetaArgs{Path: filepath.Join(dir, "build.pkr.hcl"), Profile: "staging"}, ConfigTypeHCL2,
+			[]string{filepath.Join(dir, "staging.pkrvars.hcl")}, false},
+		{"json templates only load json files", MetaArgs{Path: dir, Profile: "staging"}, ConfigTypeJSON, nil, true},
+		{"unknown profile", MetaArgs{Path: dir, Profile: "prod"}, ConfigTypeHCL2, nil, true},
+		{"invalid profile", MetaArgs{Path: dir, Profile: "../staging"}, ConfigTypeHCL2, nil, true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.args.ProfileVarFiles(tc.cfgType)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ProfileVarFiles() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected var files: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateCommand_Profile(t *testing.T) {
+	c := &ValidateCommand{
+		Meta: testMetaFile(t),
+	}
+	args := []string{"-profile", "staging", testFixture("profile")}
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+
+	args = []string{"-profile", "prod", testFixture("profile")}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("an unknown profile should fail validation, got exit code %d", code)
+	}
+}
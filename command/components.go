@@ -0,0 +1,120 @@
+package command
+
+import (
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/lsp"
+	"github.com/hashicorp/packer/packer"
+)
+
+// componentSpecs are the specs and docs of the components: the components
+// built in Packer, whose docs can be found from their packages, and the
+// plugins.
+type componentSpecs struct {
+	finder packer.ComponentFinder
+	docs   *lsp.Docs
+	// specs are the specs of the plugins, by kind and type, as starting
+	// a plugin is slow.
+	specs map[string]hcldec.ObjectSpec
+}
+
+// newComponentSpecs returns the specs of the components of finder, with the
+// docs of docsDir, the website/pages/partials directory of Packer, when set.
+func newComponentSpecs(finder packer.ComponentFinder, docsDir string) (*componentSpecs, error) {
+	cs := &componentSpecs{
+		finder: finder,
+		specs:  map[string]hcldec.ObjectSpec{},
+	}
+	if docsDir != "" {
+		docs, err := lsp.LoadDocs(docsDir)
+		if err != nil {
+			return nil, err
+		}
+		cs.docs = docs
+	}
+	return cs, nil
+}
+
+func (lc *componentSpecs) Types(kind lsp.Kind) []string {
+	var types []string
+	switch kind {
+	case lsp.Builder:
+		types = lc.finder.BuilderStore.List()
+	case lsp.Provisioner:
+		types = lc.finder.ProvisionerStore.List()
+	case lsp.PostProcessor:
+		types = lc.finder.PostProcessorStore.List()
+	}
+	sort.Strings(types)
+	return types
+}
+
+// builtin returns the component built in Packer, nil when there is none.
+func (lc *componentSpecs) builtin(kind lsp.Kind, typ string) packer.HCL2Speccer {
+	var c packer.HCL2Speccer
+	var ok bool
+	switch kind {
+	case lsp.Builder:
+		c, ok = Builders[typ]
+	case lsp.Provisioner:
+		c, ok = Provisioners[typ]
+	case lsp.PostProcessor:
+		c, ok = PostProcessors[typ]
+	}
+	if !ok {
+		return nil
+	}
+	return c
+}
+
+func (lc *componentSpecs) Spec(kind lsp.Kind, typ string) hcldec.ObjectSpec {
+	if c := lc.builtin(kind, typ); c != nil {
+		return c.ConfigSpec()
+	}
+
+	key := string(kind) + "." + typ
+	if spec, ok := lc.specs[key]; ok {
+		return spec
+	}
+	var c packer.HCL2Speccer
+	var err error
+	switch kind {
+	case lsp.Builder:
+		if lc.finder.BuilderStore.Has(typ) {
+			c, err = lc.finder.BuilderStore.Start(typ)
+		}
+	case lsp.Provisioner:
+		if lc.finder.ProvisionerStore.Has(typ) {
+			c, err = lc.finder.ProvisionerStore.Start(typ)
+		}
+	case lsp.PostProcessor:
+		if lc.finder.PostProcessorStore.Has(typ) {
+			c, err = lc.finder.PostProcessorStore.Start(typ)
+		}
+	}
+	var spec hcldec.ObjectSpec
+	if err != nil {
+		log.Printf("Could not start %s %s: %s", kind, typ, err)
+	} else if c != nil {
+		spec = c.ConfigSpec()
+	}
+	lc.specs[key] = spec
+	return spec
+}
+
+func (lc *componentSpecs) Doc(kind lsp.Kind, typ, option string) string {
+	c := lc.builtin(kind, typ)
+	if c == nil || lc.docs == nil {
+		return ""
+	}
+	t := reflect.TypeOf(c)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pkgDir := strings.TrimPrefix(t.PkgPath(), "github.com/hashicorp/packer/")
+	return lc.docs.Option(pkgDir, option)
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/chzyer/readline"
+	"github.com/hashicorp/packer/hcl2template"
+	"github.com/hashicorp/packer/hcl2template/repl"
 	"github.com/hashicorp/packer/helper/wrappedreadline"
 	"github.com/hashicorp/packer/helper/wrappedstreams"
 	"github.com/hashicorp/packer/packer"
@@ -66,12 +68,18 @@ func (c *ConsoleCommand) RunContext(ctx context.Context, cla *ConsoleArgs) int {
 		return ret
 	}
 
+	// HCL2 expressions can span multiple lines.
+	incomplete := func(string) bool { return false }
+	if _, ok := packerStarter.(*hcl2template.PackerConfig); ok {
+		incomplete = repl.IncompleteExpression
+	}
+
 	// Determine if stdin is a pipe. If so, we evaluate directly.
 	if c.StdinPiped() {
-		return c.modePiped(packerStarter)
+		return c.modePiped(packerStarter, incomplete)
 	}
 
-	return c.modeInteractive(packerStarter)
+	return c.modeInteractive(packerStarter, incomplete)
 }
 
 func (*ConsoleCommand) Help() string {
@@ -83,7 +91,11 @@ Usage: packer console [options] [TEMPLATE]
   variables defined therein into its context to be referenced during
   interpolation.
 
+  In HCL2 mode, expressions can span multiple lines; type "help" in the
+  console for the available commands.
+
 Options:
+  -profile=name          Load the name.pkrvars.hcl and name.pkrvars.json var files found next to the template.
   -var 'key=value'       Variable for templates, can be used multiple times.
   -var-file=path         JSON file containing user variables. [ Note that even in HCL mode this expects file to contain JSON, a fix is comming soon ]
 `
@@ -101,30 +113,42 @@ func (*ConsoleCommand) AutocompleteArgs() complete.Predictor {
 
 func (*ConsoleCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
+		"-profile":  complete.PredictNothing,
 		"-var":      complete.PredictNothing,
 		"-var-file": complete.PredictNothing,
 	}
 }
 
-func (c *ConsoleCommand) modePiped(cfg packer.Evaluator) int {
+func (c *ConsoleCommand) modePiped(cfg packer.Evaluator, incomplete func(string) bool) int {
 	var lastResult string
+	var pending []string
 	scanner := bufio.NewScanner(wrappedstreams.Stdin())
 	ret := 0
 	for scanner.Scan() {
-		result, _, diags := cfg.EvaluateExpression(strings.TrimSpace(scanner.Text()))
+		pending = append(pending, scanner.Text())
+		line := strings.TrimSpace(strings.Join(pending, "\n"))
+		if incomplete(line) {
+			continue
+		}
+		pending = nil
+		result, _, diags := cfg.EvaluateExpression(line)
 		if len(diags) > 0 {
 			ret = writeDiags(c.Ui, nil, diags)
 		}
 		// Store the last result
 		lastResult = result
 	}
+	if len(pending) > 0 {
+		_, _, diags := cfg.EvaluateExpression(strings.TrimSpace(strings.Join(pending, "\n")))
+		ret = writeDiags(c.Ui, nil, diags)
+	}
 
 	// Output the final result
 	c.Ui.Message(lastResult)
 	return ret
 }
 
-func (c *ConsoleCommand) modeInteractive(cfg packer.Evaluator) int {
+func (c *ConsoleCommand) modeInteractive(cfg packer.Evaluator, incomplete func(string) bool) int {
 	// Setup the UI so we can output directly to stdout
 	l, err := readline.NewEx(wrappedreadline.Override(&readline.Config{
 		Prompt:            "> ",
@@ -138,10 +162,17 @@ func (c *ConsoleCommand) modeInteractive(cfg packer.Evaluator) int {
 			err))
 		return 1
 	}
+	var pending []string
 	for {
 		// Read a line
 		line, err := l.Readline()
 		if err == readline.ErrInterrupt {
+			if len(pending) > 0 {
+				// Cancel the expression being written.
+				pending = nil
+				l.SetPrompt("> ")
+				continue
+			}
 			if len(line) == 0 {
 				break
 			} else {
@@ -150,7 +181,15 @@ func (c *ConsoleCommand) modeInteractive(cfg packer.Evaluator) int {
 		} else if err == io.EOF {
 			break
 		}
-		out, exit, diags := cfg.EvaluateExpression(line)
+		pending = append(pending, line)
+		line = strings.Join(pending, "\n")
+		if incomplete(line) {
+			l.SetPrompt(". ")
+			continue
+		}
+		pending = nil
+		l.SetPrompt("> ")
+		out, exit, diags := cfg.EvaluateExpression(strings.TrimSpace(line))
 		ret := writeDiags(c.Ui, nil, diags)
 		if exit {
 			return ret
@@ -0,0 +1,17 @@
+package command
+
+import (
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// openEventStream opens the destination of the -event-stream option: a unix
+// socket when it starts with "unix:", a file otherwise.
+func openEventStream(dest string) (io.WriteCloser, error) {
+	if strings.HasPrefix(dest, "unix:") {
+		return net.Dial("unix", strings.TrimPrefix(dest, "unix:"))
+	}
+	return os.Create(dest)
+}
@@ -84,6 +84,7 @@ func (c *FixCommand) RunContext(ctx context.Context, cla *FixArgs) int {
 			return 1
 		}
 	}
+	input = c.fixDeprecations(input)
 
 	var output bytes.Buffer
 	encoder := json.NewEncoder(&output)
@@ -147,6 +148,9 @@ Fixes that are run (in order):
 		helpText += fmt.Sprintf(
 			"  %-27s%s\n", name, fix.Fixers[name].Synopsis())
 	}
+	helpText += fmt.Sprintf(
+		"  %-27s%s\n", "deprecated-options",
+		"Renames the options the components deprecate to their replacements")
 
 	helpText += `
 Options:
@@ -0,0 +1,118 @@
+package command
+
+import (
+	"log"
+
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+)
+
+// fixDeprecations renames the deprecated options of the builders,
+// provisioners and post-processors of the JSON template to the options
+// replacing them. The deprecated options are the ones the components report
+// when they are prepared with their configuration, so this also fixes the
+// options deprecated by plugins.
+func (c *FixCommand) fixDeprecations(input map[string]interface{}) map[string]interface{} {
+	components := c.CoreConfig.Components
+
+	for _, raw := range sliceOf(input["builders"]) {
+		m, typ := componentConfig(raw)
+		if m == nil {
+			continue
+		}
+		b, err := components.BuilderStore.Start(typ)
+		if err != nil {
+			log.Printf("Can't start builder %q to fix its deprecated options: %s", typ, err)
+			continue
+		}
+		// The configuration may be incomplete or invalid, the deprecated
+		// options are found before it is validated.
+		_, _, _ = b.Prepare(m)
+		renameDeprecated(m, b)
+	}
+
+	for _, raw := range sliceOf(input["provisioners"]) {
+		m, typ := componentConfig(raw)
+		if m == nil {
+			continue
+		}
+		p, err := components.ProvisionerStore.Start(typ)
+		if err != nil {
+			log.Printf("Can't start provisioner %q to fix its deprecated options: %s", typ, err)
+			continue
+		}
+		_ = p.Prepare(m)
+		renameDeprecated(m, p)
+	}
+
+	for _, raw := range sliceOf(input["post-processors"]) {
+		// Post-processors can be chained in arrays.
+		seq, ok := raw.([]interface{})
+		if !ok {
+			seq = []interface{}{raw}
+		}
+		for _, raw := range seq {
+			m, typ := componentConfig(raw)
+			if m == nil {
+				continue
+			}
+			pp, err := components.PostProcessorStore.Start(typ)
+			if err != nil {
+				log.Printf("Can't start post-processor %q to fix its deprecated options: %s", typ, err)
+				continue
+			}
+			_ = pp.Configure(m)
+			renameDeprecated(m, pp)
+		}
+	}
+
+	return input
+}
+
+// sliceOf returns v when it is a slice, nil otherwise.
+func sliceOf(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
+
+// componentConfig returns the configuration and the type of the component
+// of raw, nil when raw isn't the configuration of a component.
+func componentConfig(raw interface{}) (map[string]interface{}, string) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, ""
+	}
+	typ, ok := m["type"].(string)
+	if !ok {
+		return nil, ""
+	}
+	return m, typ
+}
+
+// renameDeprecated renames the deprecated options of configuration m
+// reported by component to their replacements. The options nothing replaces
+// are kept, and so are the options whose replacement is already set.
+func renameDeprecated(m map[string]interface{}, component interface{}) {
+	r, ok := component.(packer.DeprecationReporter)
+	if !ok {
+		return
+	}
+	for _, d := range r.Deprecations() {
+		rename(m, d)
+	}
+}
+
+func rename(m map[string]interface{}, d config.Deprecation) {
+	if d.Replacement == "" {
+		return
+	}
+	v, ok := m[d.Option]
+	if !ok {
+		return
+	}
+	if _, ok := m[d.Replacement]; ok {
+		return
+	}
+	m[d.Replacement] = v
+	delete(m, d.Option)
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 	"github.com/stretchr/testify/assert"
 )
@@ -61,3 +62,27 @@ func TestFix_invalidTemplateDisableValidation(t *testing.T) {
 		fatalCommand(t, c.Meta)
 	}
 }
+
+func TestFix_rename(t *testing.T) {
+	m := map[string]interface{}{
+		"type":              "test",
+		"ssh_wait_timeout":  "5m",
+		"old":               "kept",
+		"host_port_min":     2222,
+		"ssh_host_port_min": 22,
+	}
+	for _, d := range []config.Deprecation{
+		{Option: "ssh_wait_timeout", Replacement: "ssh_timeout"},
+		{Option: "old"},
+		{Option: "ssh_host_port_min", Replacement: "host_port_min"},
+	} {
+		rename(m, d)
+	}
+	assert.Equal(t, map[string]interface{}{
+		"type":              "test",
+		"ssh_timeout":       "5m",
+		"old":               "kept",
+		"host_port_min":     2222,
+		"ssh_host_port_min": 22,
+	}, m)
+}
@@ -0,0 +1,116 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/hashicorp/packer/hcl2template"
+	"github.com/posener/complete"
+)
+
+type FormatCommand struct {
+	Meta
+}
+
+func (c *FormatCommand) Run(args []string) int {
+	ctx := context.Background()
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(ctx, cfg)
+}
+
+func (c *FormatCommand) ParseArgs(args []string) (*FormatArgs, int) {
+	var cfg FormatArgs
+	flags := c.Meta.FlagSet("fmt", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	args = flags.Args()
+	switch len(args) {
+	case 0:
+		cfg.Path = "."
+	case 1:
+		cfg.Path = args[0]
+	default:
+		flags.Usage()
+		return &cfg, 1
+	}
+	return &cfg, 0
+}
+
+func (c *FormatCommand) RunContext(ctx context.Context, cla *FormatArgs) int {
+	if cla.Check {
+		cla.Write = false
+	}
+
+	var out bytes.Buffer
+	formatter := hcl2template.Formatter{
+		ShowDiff:  cla.Diff,
+		Write:     cla.Write,
+		Recursive: cla.Recursive,
+		Output:    &out,
+	}
+	changed, diags := formatter.Format(cla.Path)
+	if out.Len() > 0 {
+		c.Ui.Say(strings.TrimSuffix(out.String(), "\n"))
+	}
+	ret := writeDiags(c.Ui, nil, diags)
+	if ret != 0 {
+		return ret
+	}
+
+	if cla.Check && changed > 0 {
+		return 3
+	}
+	return 0
+}
+
+func (*FormatCommand) Help() string {
+	helpText := `
+Usage: packer fmt [options] [PATH]
+
+  Rewrites the HCL2 configuration files and the JSON templates of PATH, the
+  current directory by default, to the canonical format and style: HCL2
+  files are indented like 'terraform fmt', with their top level blocks
+  ordered by type and their attributes sorted by name; JSON templates are
+  indented with two spaces, with the type and name of their components
+  first. The names of the files that were not formatted are printed.
+
+  In a directory, the *.pkr.hcl and *.pkrvars.hcl files are formatted, and
+  the *.json files that are templates.
+
+Options:
+
+  -check        Check if the files are formatted, without writing them. The
+                exit status is 0 if they are and 3 otherwise.
+  -diff         Display the differences with the formatted files.
+  -recursive    Also format the files of the sub-directories.
+  -write=false  Don't write the formatted files (Default: true).
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*FormatCommand) Synopsis() string {
+	return "rewrites HCL2 configurations and JSON templates to the canonical format"
+}
+
+func (*FormatCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*FormatCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-check":     complete.PredictNothing,
+		"-diff":      complete.PredictNothing,
+		"-recursive": complete.PredictNothing,
+		"-write":     complete.PredictNothing,
+	}
+}
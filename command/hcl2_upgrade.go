@@ -0,0 +1,161 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/fix"
+	"github.com/hashicorp/packer/hcl2template/upgrade"
+	"github.com/hashicorp/packer/template"
+
+	"github.com/posener/complete"
+)
+
+type HCL2UpgradeCommand struct {
+	Meta
+}
+
+func (c *HCL2UpgradeCommand) Run(args []string) int {
+	ctx, cleanup := handleTermInterrupt(c.Ui)
+	defer cleanup()
+
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(ctx, cfg)
+}
+
+func (c *HCL2UpgradeCommand) ParseArgs(args []string) (*HCL2UpgradeArgs, int) {
+	var cfg HCL2UpgradeArgs
+	flags := c.Meta.FlagSet("hcl2_upgrade", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.Path = args[0]
+	if cfg.OutputFile == "" {
+		cfg.OutputFile = strings.TrimSuffix(cfg.Path, ".json") + ".pkr.hcl"
+	}
+	return &cfg, 0
+}
+
+func (c *HCL2UpgradeCommand) RunContext(ctx context.Context, cla *HCL2UpgradeArgs) int {
+	raw, err := ioutil.ReadFile(cla.Path)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error opening template: %s", err))
+		return 1
+	}
+
+	// Fix the template first, for the options that were renamed or moved
+	// to be converted to their current HCL2 names.
+	var templateData map[string]interface{}
+	if err := json.Unmarshal(raw, &templateData); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing template: %s", err))
+		return 1
+	}
+	for _, name := range fix.FixerOrder {
+		log.Printf("Running fixer: %s", name)
+		templateData, err = fix.Fixers[name].Fix(templateData)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error fixing: %s", err))
+			return 1
+		}
+	}
+	fixed, err := json.Marshal(templateData)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error encoding: %s", err))
+		return 1
+	}
+
+	tpl, err := template.Parse(bytes.NewReader(fixed))
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing template: %s", err))
+		return 1
+	}
+
+	out, warnings, err := upgrade.Upgrade(tpl, upgrade.Options{Spec: c.componentSpec})
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error upgrading template: %s", err))
+		return 1
+	}
+	for _, w := range warnings {
+		c.Ui.Error(fmt.Sprintf("Warning: %s", w))
+	}
+
+	if err := ioutil.WriteFile(cla.OutputFile, out, 0644); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing %s: %s", cla.OutputFile, err))
+		return 1
+	}
+	c.Ui.Say(fmt.Sprintf("Successfully created %s", cla.OutputFile))
+	return 0
+}
+
+// componentSpec returns the HCL2 spec of a component, or nil when the
+// component can't be started.
+func (c *HCL2UpgradeCommand) componentSpec(kind, typ string) hcldec.ObjectSpec {
+	components := c.CoreConfig.Components
+	var spec interface{ ConfigSpec() hcldec.ObjectSpec }
+	var err error
+	switch kind {
+	case upgrade.KindBuilder:
+		spec, err = components.BuilderStore.Start(typ)
+	case upgrade.KindProvisioner:
+		spec, err = components.ProvisionerStore.Start(typ)
+	case upgrade.KindPostProcessor:
+		spec, err = components.PostProcessorStore.Start(typ)
+	}
+	if err != nil || spec == nil {
+		log.Printf("no spec for %s %q: %v", kind, typ, err)
+		return nil
+	}
+	return spec.ConfigSpec()
+}
+
+func (*HCL2UpgradeCommand) Help() string {
+	helpText := `
+Usage: packer hcl2_upgrade [options] TEMPLATE
+
+  Converts the JSON template to an HCL2 configuration, written to the
+  TEMPLATE.pkr.hcl file by default. The user variables become variable
+  blocks, the builders source blocks, and the provisioners and
+  post-processors are written in build blocks.
+
+  What can't be converted exactly is reported as a warning, and should be
+  checked before using the configuration.
+
+Options:
+
+  -output-file=path    File to write the configuration to (Default: TEMPLATE.pkr.hcl).
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*HCL2UpgradeCommand) Synopsis() string {
+	return "transform a JSON template into an HCL2 configuration"
+}
+
+func (*HCL2UpgradeCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*.json")
+}
+
+func (*HCL2UpgradeCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-output-file": complete.PredictNothing,
+	}
+}
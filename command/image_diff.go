@@ -0,0 +1,105 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/packer/common/inventory"
+	"github.com/posener/complete"
+)
+
+type ImageDiffCommand struct {
+	Meta
+}
+
+func (c *ImageDiffCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *ImageDiffCommand) ParseArgs(args []string) (*ImageDiffArgs, int) {
+	var cfg ImageDiffArgs
+	flags := c.Meta.FlagSet("image-diff", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.Old, cfg.New = flags.Arg(0), flags.Arg(1)
+	return &cfg, 0
+}
+
+func (c *ImageDiffCommand) RunContext(cla *ImageDiffArgs) int {
+	old, err := inventory.Read(cla.Old)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading inventory: %s", err))
+		return 1
+	}
+	new, err := inventory.Read(cla.New)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading inventory: %s", err))
+		return 1
+	}
+
+	report := inventory.Diff(old, new)
+	if report.Old == "" {
+		report.Old = cla.Old
+	}
+	if report.New == "" || report.New == report.Old {
+		report.New = cla.New
+	}
+	if cla.JSON {
+		if ret := c.sayJSON(report); ret != 0 {
+			return ret
+		}
+	} else {
+		c.Ui.Say(report.String())
+	}
+
+	if cla.ExitCode && !report.Empty() {
+		return 2
+	}
+	return 0
+}
+
+func (*ImageDiffCommand) Help() string {
+	helpText := `
+Usage: packer image-diff [options] OLD NEW
+
+  Compares two images from the inventories captured by the inventory
+  provisioner, OLD and NEW: the packages added, removed and upgraded, the
+  files added, removed and changed, and the listening ports opened and
+  closed. Useful to review an image before promoting it.
+
+Options:
+
+  -json                         Print the change report as JSON.
+  -exit-code                    Exit with 2 when the images differ, 0 when they don't.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*ImageDiffCommand) Synopsis() string {
+	return "compares the inventories of two images"
+}
+
+func (*ImageDiffCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*.json")
+}
+
+func (*ImageDiffCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-json":      complete.PredictNothing,
+		"-exit-code": complete.PredictNothing,
+	}
+}
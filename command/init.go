@@ -0,0 +1,178 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	plugingetter "github.com/hashicorp/packer/packer/plugin-getter"
+	"github.com/hashicorp/packer/packer/plugin-getter/github"
+	"github.com/posener/complete"
+)
+
+type InitCommand struct {
+	Meta
+
+	// getters get the plugins, the GitHub getter by default.
+	getters []plugingetter.Getter
+}
+
+func (c *InitCommand) Run(args []string) int {
+	ctx := context.Background()
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(ctx, cfg)
+}
+
+func (c *InitCommand) ParseArgs(args []string) (*InitArgs, int) {
+	var cfg InitArgs
+	flags := c.Meta.FlagSet("init", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	args = flags.Args()
+	switch len(args) {
+	case 0:
+		cfg.Path = "."
+	case 1:
+		cfg.Path = args[0]
+	default:
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.ConfigType = ConfigTypeHCL2
+	return &cfg, 0
+}
+
+func (c *InitCommand) RunContext(ctx context.Context, cla *InitArgs) int {
+	pluginConfig := c.CoreConfig.Components.PluginConfig
+	if pluginConfig == nil || len(pluginConfig.PluginDirectories) == 0 {
+		c.Ui.Error("No plugin directory to install plugins in")
+		return 1
+	}
+
+	cfg, ret := c.GetConfigFromHCL(&cla.MetaArgs)
+	if ret != 0 {
+		return ret
+	}
+	reqs := cfg.PluginRequirements()
+	if len(reqs) == 0 {
+		c.Ui.Say("No plugins requirement found, nothing to install.")
+		return 0
+	}
+
+	lockPath := cfg.LockFilePath()
+	lock, diags := plugingetter.ReadLockFile(lockPath)
+	if ret := writeDiags(c.Ui, nil, diags); ret != 0 {
+		return ret
+	}
+
+	getters := c.getters
+	if getters == nil {
+		getters = []plugingetter.Getter{&github.Getter{}}
+	}
+	opts := plugingetter.InstallOptions{
+		Getters:     getters,
+		InDirectory: pluginConfig.PluginDirectories[0],
+	}
+	platform := plugingetter.Platform(runtime.GOOS, runtime.GOARCH)
+
+	ret = 0
+	for _, req := range reqs {
+		source := req.Identifier.String()
+		constraints := req.VersionConstraints.String()
+		installOpts := opts
+		installOpts.Version, installOpts.Checksum = nil, ""
+
+		locked, isLocked := lock.Plugins[source]
+		if isLocked && !cla.Upgrade {
+			v, err := version.NewVersion(locked.Version)
+			switch {
+			case err != nil:
+				c.Ui.Error(fmt.Sprintf("%s: invalid locked version %q: %s", lockPath, locked.Version, err))
+				ret = 1
+				continue
+			case !req.VersionConstraints.Check(v):
+				c.Ui.Error(fmt.Sprintf("The version %s of %s locked in %s doesn't match the "+
+					"constraints %q; run 'packer init -upgrade' to select a new version.",
+					locked.Version, source, lockPath, constraints))
+				ret = 1
+				continue
+			}
+			installOpts.Version = v
+			installOpts.Checksum = locked.Checksums[platform]
+		}
+
+		install, err := req.InstallLatest(installOpts)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to install plugin %s: %s", req.Accessor, err))
+			ret = 1
+			continue
+		}
+		c.Ui.Say(fmt.Sprintf("Installed plugin %s v%s in %q", source, install.Version, install.Dir))
+
+		if !isLocked || locked.Version != install.Version.String() {
+			locked = &plugingetter.LockedPlugin{Source: source}
+			lock.Plugins[source] = locked
+		}
+		locked.Version = install.Version.String()
+		locked.Constraints = constraints
+		if install.Checksum != "" {
+			if locked.Checksums == nil {
+				locked.Checksums = map[string]string{}
+			}
+			locked.Checksums[platform] = install.Checksum
+		}
+	}
+
+	if err := lock.Write(lockPath); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to write the lock file: %s", err))
+		return 1
+	}
+	return ret
+}
+
+func (*InitCommand) Help() string {
+	helpText := `
+Usage: packer init [options] [PATH]
+
+  Installs the plugins required by the HCL2 configuration of PATH, the current
+  directory by default, in the first plugin directory: the plugins of the
+  required_plugins blocks are downloaded from their source and their
+  checksums verified.
+
+  The installed versions and their checksums are recorded in the
+  ` + plugingetter.LockFileName + ` lock file of the configuration, which should be
+  committed: later runs of init, and builds, use the locked versions.
+
+Options:
+
+  -upgrade    Install the latest versions matching the constraints of the
+              configuration instead of the locked ones, and update the lock
+              file.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*InitCommand) Synopsis() string {
+	return "install the plugins required by a configuration"
+}
+
+func (*InitCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*InitCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-upgrade": complete.PredictNothing,
+	}
+}
@@ -0,0 +1,189 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/packer/lint"
+	"github.com/posener/complete"
+)
+
+type LintCommand struct {
+	Meta
+}
+
+func (c *LintCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *LintCommand) ParseArgs(args []string) (*LintArgs, int) {
+	var cfg LintArgs
+	flags := c.Meta.FlagSet("lint", FlagSetVars)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.Path = args[0]
+	return &cfg, 0
+}
+
+// lintRules returns the rules to run: the registered rules and the custom
+// ones, except the disabled ones.
+func lintRules(cla *LintArgs) (map[string]lint.Rule, error) {
+	rules := map[string]lint.Rule{}
+	for n, r := range lint.Rules {
+		rules[n] = r
+	}
+	for _, path := range cla.RulesFiles {
+		custom, err := lint.LoadRules(path)
+		if err != nil {
+			return nil, err
+		}
+		for n, r := range custom {
+			if _, ok := rules[n]; ok {
+				return nil, fmt.Errorf("%s: rule %q is already defined", path, n)
+			}
+			rules[n] = r
+		}
+	}
+	for _, n := range cla.Disable {
+		if _, ok := rules[n]; !ok {
+			return nil, fmt.Errorf("unknown rule %q", n)
+		}
+		delete(rules, n)
+	}
+	return rules, nil
+}
+
+func (c *LintCommand) RunContext(cla *LintArgs) int {
+	failOn, err := lint.ParseSeverity(cla.FailOn)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	rules, err := lintRules(cla)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	packerStarter, ret := c.GetConfig(&cla.MetaArgs)
+	if ret != 0 {
+		return ret
+	}
+	diags := packerStarter.Initialize()
+	ret = writeDiags(c.Ui, nil, diags)
+	if ret != 0 {
+		return ret
+	}
+	blocks, diags := packerStarter.LintConfig()
+	ret = writeDiags(c.Ui, nil, diags)
+	if ret != 0 {
+		return ret
+	}
+
+	issues := lint.Run(blocks, rules)
+	failed := 0
+	for _, i := range issues {
+		if i.Severity >= failOn {
+			failed++
+		}
+	}
+
+	if cla.JSON {
+		if issues == nil {
+			issues = []lint.Issue{}
+		}
+		out, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Say(string(out))
+	} else {
+		for _, i := range issues {
+			c.Ui.Machine("lint-issue", i.Rule, i.Severity.String(), i.Filename, strconv.Itoa(i.Line), i.Block, i.Option, i.Message)
+			if i.Severity >= failOn {
+				c.Ui.Error(i.String())
+			} else {
+				c.Ui.Say(i.String())
+			}
+		}
+		if len(issues) == 0 {
+			c.Ui.Say("No issues found.")
+		} else {
+			c.Ui.Say(fmt.Sprintf("%d issue(s) found, %d failing with -fail-on=%s.", len(issues), failed, failOn))
+		}
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func (*LintCommand) Help() string {
+	rules := make([]string, 0, len(lint.Rules))
+	for n, r := range lint.Rules {
+		rules = append(rules, fmt.Sprintf("  %-28s %s", n, r.Synopsis()))
+	}
+	sort.Strings(rules)
+
+	helpText := `
+Usage: packer lint [options] TEMPLATE
+
+  Checks the template for deprecated options, insecure settings and other
+  problems that don't prevent it from building. Exits with a non-zero status
+  when issues of at least the -fail-on severity are found, to be used in CI.
+
+Options:
+
+  -disable=foo,bar              Don't run these rules.
+  -fail-on=error                Lowest severity failing the command: error, warning or info (Default: error).
+  -json                         Print the issues as JSON.
+  -profile=name                 Load the name.pkrvars.hcl and name.pkrvars.json var files.
+  -rules=path                   JSON file declaring custom rules, can be used multiple times.
+  -var 'key=value'              Variable for templates, can be used multiple times.
+  -var-file=path                JSON or HCL2 file containing user variables.
+
+Rules:
+
+` + strings.Join(rules, "\n")
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*LintCommand) Synopsis() string {
+	return "checks a template for deprecated options and insecure settings"
+}
+
+func (*LintCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*LintCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-disable":  complete.PredictNothing,
+		"-fail-on":  complete.PredictSet("error", "warning", "info"),
+		"-json":     complete.PredictNothing,
+		"-profile":  complete.PredictNothing,
+		"-rules":    complete.PredictFiles("*.json"),
+		"-var":      complete.PredictNothing,
+		"-var-file": complete.PredictNothing,
+	}
+}
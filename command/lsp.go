@@ -0,0 +1,130 @@
+package command
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/packer/lsp"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/version"
+	"github.com/posener/complete"
+)
+
+type LspCommand struct {
+	Meta
+}
+
+func (c *LspCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *LspCommand) ParseArgs(args []string) (*LspArgs, int) {
+	var cfg LspArgs
+	flags := c.Meta.FlagSet("lsp", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if len(flags.Args()) != 0 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	return &cfg, 0
+}
+
+func (c *LspCommand) RunContext(cla *LspArgs) int {
+	components, err := newComponentSpecs(c.CoreConfig.Components, cla.DocsDir)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading docs: %s", err))
+		return 1
+	}
+
+	// The client talks to the server over stdio, nothing else can be
+	// written to stdout.
+	server := &lsp.Server{
+		Components: components,
+		Validate:   c.validate,
+		Version:    version.FormattedVersion(),
+	}
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		log.Printf("lsp: %s", err)
+		return 1
+	}
+	return 0
+}
+
+// validate returns the diagnostics of the template of dir, like packer
+// validate, parsing the files being edited from their contents.
+func (c *LspCommand) validate(dir string, files map[string][]byte) hcl.Diagnostics {
+	parser := c.hclParser()
+
+	// The parser reuses the files it already parsed rather than reading
+	// them from the disk.
+	var diags hcl.Diagnostics
+	for path, src := range files {
+		if strings.HasSuffix(path, ".pkr.json") {
+			_, moreDiags := parser.ParseJSON(src, path)
+			diags = append(diags, moreDiags...)
+		} else {
+			_, moreDiags := parser.ParseHCL(src, path)
+			diags = append(diags, moreDiags...)
+		}
+	}
+	if diags.HasErrors() {
+		return diags
+	}
+
+	cfg, moreDiags := parser.Parse(dir, nil, nil)
+	diags = append(diags, moreDiags...)
+	if diags.HasErrors() {
+		return diags
+	}
+	moreDiags = cfg.Initialize()
+	diags = append(diags, moreDiags...)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+	_, moreDiags = cfg.GetBuilds(packer.GetBuildsOptions{})
+	return append(diags, moreDiags...)
+}
+
+func (*LspCommand) Help() string {
+	helpText := `
+Usage: packer lsp [options]
+
+  Starts a Language Server Protocol server for HCL2 templates, talking to
+  the editor over stdin and stdout. The server completes the options of the
+  builders, provisioners and post-processors, shows their docs, and
+  validates the templates as they are edited, like packer validate.
+
+Options:
+
+  -docs=path                    Directory of the docs of the options, the website/pages/partials directory of Packer.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*LspCommand) Synopsis() string {
+	return "starts a language server for HCL2 templates"
+}
+
+func (*LspCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*LspCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-docs": complete.PredictDirs("*"),
+	}
+}
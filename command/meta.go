@@ -37,9 +37,15 @@ func (m *Meta) Core(tpl *template.Template, cla *MetaArgs) (*packer.Core, error)
 	config := *m.CoreConfig
 	config.Template = tpl
 
+	profileVarFiles, err := cla.ProfileVarFiles(ConfigTypeJSON)
+	if err != nil {
+		return nil, err
+	}
+
 	fj := &kvflag.FlagJSON{}
-	// First populate fj with contents from var files
-	for _, file := range cla.VarFiles {
+	// First populate fj with contents from var files, profile files first so
+	// that files passed with -var-file override them.
+	for _, file := range append(profileVarFiles, cla.VarFiles...) {
 		err := fj.Set(file)
 		if err != nil {
 			return nil, err
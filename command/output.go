@@ -0,0 +1,143 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+type OutputCommand struct {
+	Meta
+}
+
+func (c *OutputCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *OutputCommand) ParseArgs(args []string) (*OutputArgs, int) {
+	var cfg OutputArgs
+	flags := c.Meta.FlagSet("output", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	args = flags.Args()
+	if len(args) > 2 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	if len(args) > 0 {
+		cfg.Build = args[0]
+	}
+	if len(args) > 1 {
+		cfg.Name = args[1]
+	}
+	return &cfg, 0
+}
+
+func (c *OutputCommand) RunContext(cla *OutputArgs) int {
+	f, err := readOutputsFile(cla.File)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading outputs: %s", err))
+		return 1
+	}
+
+	var result interface{} = f.Builds
+	if cla.Build != "" {
+		outputs, ok := f.Builds[cla.Build]
+		if !ok {
+			c.Ui.Error(fmt.Sprintf("No outputs recorded for build %q", cla.Build))
+			return 1
+		}
+		result = outputs
+		if cla.Name != "" {
+			value, ok := outputs[cla.Name]
+			if !ok {
+				c.Ui.Error(fmt.Sprintf("Build %q has no output %q", cla.Build, cla.Name))
+				return 1
+			}
+			result = value
+		}
+	}
+
+	if cla.JSON {
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Say(string(b))
+		return 0
+	}
+
+	switch result := result.(type) {
+	case string:
+		c.Ui.Say(result)
+	case map[string]string:
+		for _, k := range sortedKeys(result) {
+			c.Ui.Say(fmt.Sprintf("%s = %s", k, result[k]))
+		}
+	default:
+		builds := make([]string, 0, len(f.Builds))
+		for b := range f.Builds {
+			builds = append(builds, b)
+		}
+		sort.Strings(builds)
+		for _, b := range builds {
+			for _, k := range sortedKeys(f.Builds[b]) {
+				c.Ui.Say(fmt.Sprintf("%s.%s = %s", b, k, f.Builds[b][k]))
+			}
+		}
+	}
+	return 0
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (*OutputCommand) Help() string {
+	helpText := `
+Usage: packer output [options] [BUILD [NAME]]
+
+  Reads the outputs recorded by the last packer build: all of them, the
+  outputs of BUILD, or the raw value of its output NAME.
+
+Options:
+
+  -file=path                    The outputs file to read (Default: packer-outputs.json).
+  -json                         Print the outputs as JSON.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*OutputCommand) Synopsis() string {
+	return "reads the outputs of builds"
+}
+
+func (*OutputCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*OutputCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-file": complete.PredictFiles("*.json"),
+		"-json": complete.PredictNothing,
+	}
+}
@@ -0,0 +1,70 @@
+package command
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// defaultOutputsFile is where packer build records the outputs of builds, and
+// where packer output reads them from.
+const defaultOutputsFile = "packer-outputs.json"
+
+// outputsFile is the content of an outputs file.
+type outputsFile struct {
+	// Builds are the outputs of the builds that succeeded last, by build
+	// name.
+	Builds packer.BuildOutputs `json:"builds"`
+}
+
+func readOutputsFile(path string) (*outputsFile, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &outputsFile{}
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, err
+	}
+	if f.Builds == nil {
+		f.Builds = packer.BuildOutputs{}
+	}
+	return f, nil
+}
+
+// writeOutputsFile records outputs in the outputs file at path, keeping the
+// outputs of the builds that didn't run this time.
+func writeOutputsFile(path string, outputs packer.BuildOutputs) error {
+	f, err := readOutputsFile(path)
+	if os.IsNotExist(err) {
+		f, err = &outputsFile{Builds: packer.BuildOutputs{}}, nil
+	}
+	if err != nil {
+		return err
+	}
+	for build, o := range outputs {
+		f.Builds[build] = o
+	}
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,232 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/posener/complete"
+)
+
+// planStep is a provisioner or a post-processor of a planned build.
+type planStep struct {
+	Type      string   `json:"type"`
+	Name      string   `json:"name,omitempty"`
+	Resources []string `json:"resources,omitempty"`
+}
+
+// planBuild is what a build would do.
+type planBuild struct {
+	Name         string         `json:"name"`
+	Builder      string         `json:"builder"`
+	Resources    []string       `json:"resources"`
+	DependsOn    []string       `json:"depends_on,omitempty"`
+	Locks        []string       `json:"locks,omitempty"`
+	Semaphores   map[string]int `json:"semaphores,omitempty"`
+	Provisioners []planStep     `json:"provisioners"`
+	// PostProcessors are the chains of post-processors.
+	PostProcessors [][]planStep `json:"post_processors"`
+}
+
+func newPlanBuild(b packer.Build) planBuild {
+	p := planBuild{
+		Name:           b.Name(),
+		Provisioners:   []planStep{},
+		PostProcessors: [][]planStep{},
+		DependsOn:      packer.BuildDependencies(b),
+	}
+	cb, ok := b.(*packer.CoreBuild)
+	if !ok {
+		return p
+	}
+	p.Builder = cb.BuilderType
+	p.Resources = describeResources(cb.Builder)
+	p.Locks = cb.Locks
+	p.Semaphores = cb.Semaphores
+	for _, prov := range cb.Provisioners {
+		p.Provisioners = append(p.Provisioners, planStep{Type: prov.PType, Name: prov.PName})
+	}
+	for _, chain := range cb.PostProcessors {
+		steps := []planStep{}
+		for _, pp := range chain {
+			steps = append(steps, planStep{Type: pp.PType, Name: pp.PName, Resources: packer.PlannedResources(pp.PostProcessor)})
+		}
+		p.PostProcessors = append(p.PostProcessors, steps)
+	}
+	return p
+}
+
+// describeResources returns the resources builder creates, as it describes
+// them.
+func describeResources(builder packer.Builder) []string {
+	if r := packer.PlannedResources(builder); r != nil {
+		return r
+	}
+	return []string{"unknown, see the documentation of the builder"}
+}
+
+type PlanCommand struct {
+	Meta
+}
+
+func (c *PlanCommand) Run(args []string) int {
+	ctx := context.Background()
+
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(ctx, cfg)
+}
+
+func (c *PlanCommand) ParseArgs(args []string) (*PlanArgs, int) {
+	var cfg PlanArgs
+	flags := c.Meta.FlagSet("plan", FlagSetBuildFilter|FlagSetVars)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.Path = args[0]
+	return &cfg, 0
+}
+
+func (c *PlanCommand) RunContext(ctx context.Context, cla *PlanArgs) int {
+	packerStarter, ret := c.GetConfig(&cla.MetaArgs)
+	if ret != 0 {
+		return ret
+	}
+	diags := packerStarter.Initialize()
+	ret = writeDiags(c.Ui, nil, diags)
+	if ret != 0 {
+		return ret
+	}
+
+	// Preparing the builds interpolates and validates their whole
+	// configuration, without starting anything remote. Builds depending on
+	// other builds are prepared with placeholder outputs.
+	builds, diags := packerStarter.GetBuilds(packer.GetBuildsOptions{
+		Only:   cla.Only,
+		Except: cla.Except,
+	})
+	ret = writeDiags(c.Ui, nil, diags)
+	if ret != 0 {
+		return ret
+	}
+	builds, err := packer.SortBuilds(builds)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	plan := make([]planBuild, len(builds))
+	for i, b := range builds {
+		plan[i] = newPlanBuild(b)
+	}
+
+	if cla.JSON {
+		out, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Say(string(out))
+		return 0
+	}
+
+	c.Ui.Say(fmt.Sprintf("%d build(s) would run, in this order:", len(plan)))
+	for _, b := range plan {
+		c.Ui.Say("")
+		c.Ui.Say(fmt.Sprintf("Build '%s' (%s)", b.Name, b.Builder))
+		if len(b.DependsOn) > 0 {
+			c.Ui.Say(fmt.Sprintf("  after: %s", strings.Join(b.DependsOn, ", ")))
+		}
+		if len(b.Locks) > 0 {
+			c.Ui.Say(fmt.Sprintf("  locks: %s", strings.Join(b.Locks, ", ")))
+		}
+		if len(b.Semaphores) > 0 {
+			c.Ui.Say(fmt.Sprintf("  semaphores: %s", formatSemaphores(b.Semaphores)))
+		}
+		if len(b.Resources) > 0 {
+			c.Ui.Say(fmt.Sprintf("  creates: %s", strings.Join(b.Resources, "; ")))
+		}
+		for _, p := range b.Provisioners {
+			c.Ui.Say(fmt.Sprintf("  provisioner: %s", p.Type))
+		}
+		for i, chain := range b.PostProcessors {
+			for _, pp := range chain {
+				line := fmt.Sprintf("  post-processor %d: %s", i+1, pp.Type)
+				if len(pp.Resources) > 0 {
+					line += fmt.Sprintf(" (creates: %s)", strings.Join(pp.Resources, "; "))
+				}
+				c.Ui.Say(line)
+			}
+		}
+	}
+	return 0
+}
+
+func (*PlanCommand) Help() string {
+	helpText := `
+Usage: packer plan [options] TEMPLATE
+
+  Shows what packer build would do with the template, without starting
+  anything: the builds that would run and in which order, their provisioners
+  and post-processors, and what they would create. The template is fully
+  interpolated and validated, like with packer validate.
+
+Options:
+
+  -except=foo,bar,baz           Plan all builds other than these.
+  -only=foo,bar,baz             Plan only the specified builds.
+  -var 'key=value'              Variable for templates, can be used multiple times.
+  -var-file=path                JSON or HCL2 file containing user variables.
+  -profile=name                 Load the name.pkrvars.hcl and name.pkrvars.json var files.
+  -json                         Print the plan as JSON.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*PlanCommand) Synopsis() string {
+	return "shows what a build would do, without running it"
+}
+
+func (*PlanCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*PlanCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-except":   complete.PredictNothing,
+		"-only":     complete.PredictNothing,
+		"-var":      complete.PredictNothing,
+		"-var-file": complete.PredictNothing,
+		"-profile":  complete.PredictNothing,
+		"-json":     complete.PredictNothing,
+	}
+}
+
+// formatSemaphores returns the semaphores and their limits, like
+// "aws (8), vsphere (2)".
+func formatSemaphores(semaphores map[string]int) string {
+	names := make([]string, 0, len(semaphores))
+	for name := range semaphores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		names[i] = fmt.Sprintf("%s (%d)", name, semaphores[name])
+	}
+	return strings.Join(names, ", ")
+}
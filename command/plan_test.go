@@ -0,0 +1,70 @@
+package command
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func testMetaPlan(t *testing.T) Meta {
+	m := testMetaFile(t)
+	m.CoreConfig.Components.BuilderStore.(packer.MapOfBuilder)["mock"] = func() (packer.Builder, error) {
+		return &packer.MockBuilder{}, nil
+	}
+	return m
+}
+
+func TestPlan_JSON(t *testing.T) {
+	c := &PlanCommand{Meta: testMetaPlan(t)}
+	args := []string{"-json", filepath.Join(testFixture("plan"), "template.json")}
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+
+	stdout, _ := outputCommand(t, c.Meta)
+	var plan []planBuild
+	if err := json.Unmarshal([]byte(stdout), &plan); err != nil {
+		t.Fatalf("invalid JSON output %q: %s", stdout, err)
+	}
+	resources := map[string][]string{}
+	for _, b := range plan {
+		resources[b.Name] = b.Resources
+	}
+	expected := map[string][]string{
+		"chocolate": {"local file"},
+		"vanilla":   {},
+		"cherry":    {"unknown, see the documentation of the builder"},
+	}
+	if !reflect.DeepEqual(resources, expected) {
+		t.Fatalf("expected resources %#v, got %#v", expected, resources)
+	}
+	for _, b := range plan {
+		if len(b.PostProcessors) != 1 || !reflect.DeepEqual(b.PostProcessors[0][0].Resources, []string{"manifest file"}) {
+			t.Fatalf("bad post-processors of %s: %#v", b.Name, b.PostProcessors)
+		}
+	}
+}
+
+func TestPlan_text(t *testing.T) {
+	c := &PlanCommand{Meta: testMetaPlan(t)}
+	args := []string{"-only=chocolate", filepath.Join(testFixture("plan"), "template.json")}
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+
+	stdout, _ := outputCommand(t, c.Meta)
+	for _, line := range []string{
+		"1 build(s) would run",
+		"Build 'chocolate' (file)",
+		"creates: local file",
+		"post-processor 1: manifest (creates: manifest file)",
+	} {
+		if !strings.Contains(stdout, line) {
+			t.Fatalf("%q should be in the plan:\n%s", line, stdout)
+		}
+	}
+}
@@ -77,6 +77,7 @@ import (
 	googlecomputeexportpostprocessor "github.com/hashicorp/packer/post-processor/googlecompute-export"
 	googlecomputeimportpostprocessor "github.com/hashicorp/packer/post-processor/googlecompute-import"
 	manifestpostprocessor "github.com/hashicorp/packer/post-processor/manifest"
+	registrypostprocessor "github.com/hashicorp/packer/post-processor/registry"
 	shelllocalpostprocessor "github.com/hashicorp/packer/post-processor/shell-local"
 	ucloudimportpostprocessor "github.com/hashicorp/packer/post-processor/ucloud-import"
 	vagrantpostprocessor "github.com/hashicorp/packer/post-processor/vagrant"
@@ -94,6 +95,7 @@ import (
 	convergeprovisioner "github.com/hashicorp/packer/provisioner/converge"
 	fileprovisioner "github.com/hashicorp/packer/provisioner/file"
 	inspecprovisioner "github.com/hashicorp/packer/provisioner/inspec"
+	inventoryprovisioner "github.com/hashicorp/packer/provisioner/inventory"
 	powershellprovisioner "github.com/hashicorp/packer/provisioner/powershell"
 	puppetmasterlessprovisioner "github.com/hashicorp/packer/provisioner/puppet-masterless"
 	puppetserverprovisioner "github.com/hashicorp/packer/provisioner/puppet-server"
@@ -103,6 +105,7 @@ import (
 	sleepprovisioner "github.com/hashicorp/packer/provisioner/sleep"
 	windowsrestartprovisioner "github.com/hashicorp/packer/provisioner/windows-restart"
 	windowsshellprovisioner "github.com/hashicorp/packer/provisioner/windows-shell"
+	windowsupdateprovisioner "github.com/hashicorp/packer/provisioner/windows-update"
 )
 
 type PluginCommand struct {
@@ -172,6 +175,7 @@ var Provisioners = map[string]packer.Provisioner{
 	"converge":          new(convergeprovisioner.Provisioner),
 	"file":              new(fileprovisioner.Provisioner),
 	"inspec":            new(inspecprovisioner.Provisioner),
+	"inventory":         new(inventoryprovisioner.Provisioner),
 	"powershell":        new(powershellprovisioner.Provisioner),
 	"puppet-masterless": new(puppetmasterlessprovisioner.Provisioner),
 	"puppet-server":     new(puppetserverprovisioner.Provisioner),
@@ -181,6 +185,7 @@ var Provisioners = map[string]packer.Provisioner{
 	"sleep":             new(sleepprovisioner.Provisioner),
 	"windows-restart":   new(windowsrestartprovisioner.Provisioner),
 	"windows-shell":     new(windowsshellprovisioner.Provisioner),
+	"windows-update":    new(windowsupdateprovisioner.Provisioner),
 }
 
 var PostProcessors = map[string]packer.PostProcessor{
@@ -198,6 +203,7 @@ var PostProcessors = map[string]packer.PostProcessor{
 	"googlecompute-export": new(googlecomputeexportpostprocessor.PostProcessor),
 	"googlecompute-import": new(googlecomputeimportpostprocessor.PostProcessor),
 	"manifest":             new(manifestpostprocessor.PostProcessor),
+	"registry":             new(registrypostprocessor.PostProcessor),
 	"shell-local":          new(shelllocalpostprocessor.PostProcessor),
 	"ucloud-import":        new(ucloudimportpostprocessor.PostProcessor),
 	"vagrant":              new(vagrantpostprocessor.PostProcessor),
@@ -229,12 +235,7 @@ func (c *PluginCommand) Run(args []string) int {
 	pluginType := parts[1] // capture group 1 (builder|post-processor|provisioner)
 	pluginName := parts[2] // capture group 2 (.+)
 
-	server, err := plugin.Server()
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf("Error starting plugin server: %s", err))
-		return 1
-	}
-
+	var components plugin.Components
 	switch pluginType {
 	case "builder":
 		builder, found := Builders[pluginName]
@@ -242,24 +243,27 @@ func (c *PluginCommand) Run(args []string) int {
 			c.Ui.Error(fmt.Sprintf("Could not load builder: %s", pluginName))
 			return 1
 		}
-		server.RegisterBuilder(builder)
+		components.Builder = builder
 	case "provisioner":
 		provisioner, found := Provisioners[pluginName]
 		if !found {
 			c.Ui.Error(fmt.Sprintf("Could not load provisioner: %s", pluginName))
 			return 1
 		}
-		server.RegisterProvisioner(provisioner)
+		components.Provisioner = provisioner
 	case "post-processor":
 		postProcessor, found := PostProcessors[pluginName]
 		if !found {
 			c.Ui.Error(fmt.Sprintf("Could not load post-processor: %s", pluginName))
 			return 1
 		}
-		server.RegisterPostProcessor(postProcessor)
+		components.PostProcessor = postProcessor
 	}
 
-	server.Serve()
+	if err := plugin.Serve(components); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error serving plugin: %s", err))
+		return 1
+	}
 
 	return 0
 }
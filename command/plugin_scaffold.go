@@ -0,0 +1,101 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/packer/sdk/scaffold"
+	"github.com/hashicorp/packer/version"
+	"github.com/posener/complete"
+)
+
+type PluginScaffoldCommand struct {
+	Meta
+}
+
+func (c *PluginScaffoldCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *PluginScaffoldCommand) ParseArgs(args []string) (*PluginScaffoldArgs, int) {
+	var cfg PluginScaffoldArgs
+	flags := c.Meta.FlagSet("plugin scaffold", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	args = flags.Args()
+	if len(args) < 2 || len(args) > 3 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.Kind, cfg.Name = args[0], args[1]
+	cfg.Dir = "packer-plugin-" + cfg.Name
+	if len(args) == 3 {
+		cfg.Dir = args[2]
+	}
+	return &cfg, 0
+}
+
+func (c *PluginScaffoldCommand) RunContext(cla *PluginScaffoldArgs) int {
+	opts := scaffold.Options{
+		Kind:   scaffold.Kind(cla.Kind),
+		Name:   cla.Name,
+		Module: cla.Module,
+	}
+	if version.VersionPrerelease == "" {
+		opts.PackerVersion = version.Version
+	}
+
+	paths, err := scaffold.Generate(cla.Dir, opts)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to scaffold the plugin: %s", err))
+		return 1
+	}
+	for _, path := range paths {
+		c.Ui.Say(path)
+	}
+	c.Ui.Say(fmt.Sprintf("\nGenerated the %s plugin in %q; see its README.md to build it.", cla.Name, cla.Dir))
+	return 0
+}
+
+func (*PluginScaffoldCommand) Help() string {
+	helpText := `
+Usage: packer plugin scaffold [options] KIND NAME [DIR]
+
+  Generates the skeleton of a new plugin serving a component named NAME, in
+  DIR, packer-plugin-NAME by default. KIND is builder, provisioner or
+  post-processor.
+
+  The plugin is built on the packages of the Packer SDK,
+  github.com/hashicorp/packer/sdk/..., whose API is stable.
+
+Options:
+
+  -module=path    The path of the Go module of the plugin. Defaults to
+                  github.com/<you>/packer-plugin-NAME.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*PluginScaffoldCommand) Synopsis() string {
+	return "generates the skeleton of a new plugin"
+}
+
+func (*PluginScaffoldCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictSet("builder", "provisioner", "post-processor")
+}
+
+func (*PluginScaffoldCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-module": complete.PredictNothing,
+	}
+}
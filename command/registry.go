@@ -0,0 +1,218 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common/registry"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+type RegistryCommand struct {
+	Meta
+}
+
+func (*RegistryCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+func (*RegistryCommand) Help() string {
+	helpText := `
+Usage: packer registry <subcommand> [options] [args]
+
+  Reads the builds of images published to an image registry by the registry
+  post-processor, and assigns them to channels. The registry is set with the
+  -registry option or the PACKER_REGISTRY environment variable.
+
+Subcommands:
+
+  list       Lists the builds of an image and its channels.
+  promote    Assigns a build of an image to a channel.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*RegistryCommand) Synopsis() string {
+	return "reads image registries and assigns builds to channels"
+}
+
+type RegistryListCommand struct {
+	Meta
+}
+
+func (c *RegistryListCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *RegistryListCommand) ParseArgs(args []string) (*RegistryArgs, int) {
+	var cfg RegistryArgs
+	flags := c.Meta.FlagSet("registry list", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if flags.NArg() != 1 || cfg.Registry == "" {
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.Image = flags.Arg(0)
+	return &cfg, 0
+}
+
+func (c *RegistryListCommand) RunContext(cla *RegistryArgs) int {
+	backend, err := registry.New(cla.Registry)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	ctx := context.Background()
+
+	builds, err := backend.Builds(ctx, cla.Image)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading builds: %s", err))
+		return 1
+	}
+	channels, err := backend.Channels(ctx, cla.Image)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading channels: %s", err))
+		return 1
+	}
+
+	byBuild := map[string][]string{}
+	for channel, id := range channels {
+		byBuild[id] = append(byBuild[id], channel)
+	}
+	c.Ui.Say(fmt.Sprintf("%d build(s) of %s:", len(builds), cla.Image))
+	for _, b := range builds {
+		line := fmt.Sprintf("  %s  %s  %s", b.ID, b.Created.Format(time.RFC3339), b.Name)
+		if cs := byBuild[b.ID]; len(cs) > 0 {
+			sort.Strings(cs)
+			line += fmt.Sprintf("  [%s]", strings.Join(cs, ", "))
+		}
+		c.Ui.Say(line)
+		for _, a := range b.Artifacts {
+			c.Ui.Say(fmt.Sprintf("      %s", a.String))
+		}
+	}
+	return 0
+}
+
+func (*RegistryListCommand) Help() string {
+	helpText := `
+Usage: packer registry list [options] IMAGE
+
+  Lists the builds of IMAGE, from the oldest to the newest, with their
+  artifacts and the channels they are assigned to.
+
+Options:
+
+  -registry=address             The image registry (Default: $PACKER_REGISTRY).
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*RegistryListCommand) Synopsis() string {
+	return "lists the builds of an image and its channels"
+}
+
+func (*RegistryListCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*RegistryListCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-registry": complete.PredictNothing,
+	}
+}
+
+type RegistryPromoteCommand struct {
+	Meta
+}
+
+func (c *RegistryPromoteCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *RegistryPromoteCommand) ParseArgs(args []string) (*RegistryArgs, int) {
+	var cfg RegistryArgs
+	flags := c.Meta.FlagSet("registry promote", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if flags.NArg() != 3 || cfg.Registry == "" {
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.Image, cfg.Channel, cfg.BuildID = flags.Arg(0), flags.Arg(1), flags.Arg(2)
+	return &cfg, 0
+}
+
+func (c *RegistryPromoteCommand) RunContext(cla *RegistryArgs) int {
+	backend, err := registry.New(cla.Registry)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	err = registry.Promote(context.Background(), backend, cla.Image, cla.Channel, cla.BuildID)
+	if err == registry.ErrNotFound {
+		c.Ui.Error(fmt.Sprintf("Build %s of %s not found", cla.BuildID, cla.Image))
+		return 1
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error promoting build %s: %s", cla.BuildID, err))
+		return 1
+	}
+	c.Ui.Say(fmt.Sprintf("Assigned build %s of %s to %s", cla.BuildID, cla.Image, cla.Channel))
+	return 0
+}
+
+func (*RegistryPromoteCommand) Help() string {
+	helpText := `
+Usage: packer registry promote [options] IMAGE CHANNEL BUILD_ID
+
+  Assigns the build BUILD_ID of IMAGE to CHANNEL, like "production", replacing
+  the build the channel was assigned to.
+
+Options:
+
+  -registry=address             The image registry (Default: $PACKER_REGISTRY).
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*RegistryPromoteCommand) Synopsis() string {
+	return "assigns a build of an image to a channel"
+}
+
+func (*RegistryPromoteCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*RegistryPromoteCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-registry": complete.PredictNothing,
+	}
+}
@@ -0,0 +1,226 @@
+package command
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/packer/common/agent"
+	"github.com/hashicorp/packer/packer"
+	"github.com/posener/complete"
+)
+
+type RemoteBuildCommand struct {
+	Meta
+}
+
+func (c *RemoteBuildCommand) Run(args []string) int {
+	ctx, cleanup := handleTermInterrupt(c.Ui)
+	defer cleanup()
+
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(ctx, cfg)
+}
+
+func (c *RemoteBuildCommand) ParseArgs(args []string) (*RemoteBuildArgs, int) {
+	var cfg RemoteBuildArgs
+	flags := c.Meta.FlagSet("remote-build", FlagSetBuildFilter|FlagSetVars)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	cfg.Path = args[0]
+	return &cfg, 0
+}
+
+func (c *RemoteBuildCommand) RunContext(ctx context.Context, cla *RemoteBuildArgs) int {
+	if cla.Agent == "" {
+		c.Ui.Error(fmt.Sprintf("The address of the agent is required: set it with -agent or %s", agent.EnvAddress))
+		return 1
+	}
+
+	cfgType, err := cla.GetConfigType()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("%q: %s", cla.Path, err))
+		return 1
+	}
+	profileVarFiles, err := cla.ProfileVarFiles(cfgType)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	root := cla.Root
+	if root == "" {
+		root = cla.Path
+		if isDir, _ := isDir(root); !isDir {
+			root = filepath.Dir(root)
+		}
+	}
+	job, err := agent.NewJob(root, cla.Path, append(profileVarFiles, cla.VarFiles...))
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to read the files of the build: %s", err))
+		return 1
+	}
+	job.Only, job.Except = cla.Only, cla.Except
+	job.Vars = cla.Vars
+	job.Force = cla.Force
+	job.OnError = cla.OnError
+
+	client := &agent.Client{Address: cla.Agent, Token: cla.Token}
+	if cla.CACert != "" {
+		pem, err := ioutil.ReadFile(cla.CACert)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to read the CA certificate: %s", err))
+			return 1
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			c.Ui.Error(fmt.Sprintf("No certificate found in %s", cla.CACert))
+			return 1
+		}
+		client.HTTPClient = &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		}
+	}
+
+	c.Ui.Say(fmt.Sprintf("Submitting %d file(s) to %s...", len(job.Files), cla.Agent))
+	artifacts := map[string][]*packer.ArtifactEvent{}
+	buildErrors := map[string]string{}
+	err = client.Build(ctx, job, func(e packer.Event) {
+		ui := c.Ui
+		if e.Build != "" {
+			ui = &packer.TargetedUI{Target: e.Build, Ui: c.Ui}
+		}
+		switch e.Type {
+		case agent.EventJobStarted:
+			c.Ui.Say(fmt.Sprintf("Build started on the agent in %s", e.Message))
+		case packer.EventUi:
+			switch e.Level {
+			case "error":
+				ui.Error(e.Message)
+			case "message":
+				ui.Message(e.Message)
+			default:
+				ui.Say(e.Message)
+			}
+		case packer.EventError:
+			if e.Build == "" {
+				c.Ui.Error(e.Error)
+			} else {
+				buildErrors[e.Build] = e.Error
+			}
+		case packer.EventArtifact:
+			artifacts[e.Build] = append(artifacts[e.Build], e.Artifact)
+		case packer.EventBuildFinished:
+			if e.Error != "" {
+				ui.Error(fmt.Sprintf("Build '%s' errored: %s", e.Build, e.Error))
+			} else {
+				ui.Say(fmt.Sprintf("Build '%s' finished.", e.Build))
+			}
+		}
+	})
+
+	if len(buildErrors) > 0 {
+		c.Ui.Error("\n==> Some builds didn't complete successfully and had errors:")
+		for _, name := range sortedKeys(buildErrors) {
+			c.Ui.Error(fmt.Sprintf("--> %s: %s", name, buildErrors[name]))
+		}
+	}
+	if len(artifacts) > 0 {
+		c.Ui.Say("\n==> Builds finished. The artifacts of successful builds are:")
+		names := make([]string, 0, len(artifacts))
+		for name := range artifacts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, a := range artifacts[name] {
+				c.Ui.Say(fmt.Sprintf("--> %s: %s", name, a.String))
+			}
+		}
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			c.Ui.Error("Interrupted the remote build.")
+		} else {
+			c.Ui.Error(fmt.Sprintf("Remote build failed: %s", err))
+		}
+		return 1
+	}
+	return 0
+}
+
+func (*RemoteBuildCommand) Help() string {
+	helpText := `
+Usage: packer remote-build [options] TEMPLATE
+
+  Runs a build on a packer agent, streaming its output and its artifacts back.
+  TEMPLATE is a JSON template, an HCL2 file or the directory of an HCL2
+  configuration.
+
+  The files of the directory of the configuration, or of -root, are uploaded
+  to the agent with the var files; hidden files and the packer_cache
+  directory are skipped. The artifacts stay on the agent.
+
+Options:
+
+  -agent=url                    The URL of the agent (Default: $` + agent.EnvAddress + `).
+  -token=token                  The token of the agent (Default: $` + agent.EnvToken + `).
+  -root=path                    The directory to upload. It must contain
+                                TEMPLATE.
+  -ca-cert=path                 The CA certificate of the agent, when it isn't
+                                signed by a system one.
+  -except=foo,bar,baz           Run all builds and post-procesors other than these.
+  -only=foo,bar,baz             Build only the specified builds.
+  -force                        Force a build to continue if artifacts exist, deletes existing artifacts.
+  -on-error=[cleanup|abort|run-cleanup-provisioner] If the build fails do: clean up (default), abort, or run-cleanup-provisioner.
+  -var 'key=value'              Variable for templates, can be used multiple times.
+  -var-file=path                JSON or HCL2 file containing user variables.
+  -profile=name                 Load the var files of the profile 'name'.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*RemoteBuildCommand) Synopsis() string {
+	return "runs a build on a packer agent"
+}
+
+func (*RemoteBuildCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*RemoteBuildCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-agent":    complete.PredictNothing,
+		"-token":    complete.PredictNothing,
+		"-root":     complete.PredictDirs("*"),
+		"-ca-cert":  complete.PredictFiles("*"),
+		"-except":   complete.PredictNothing,
+		"-only":     complete.PredictNothing,
+		"-force":    complete.PredictNothing,
+		"-on-error": complete.PredictNothing,
+		"-var":      complete.PredictNothing,
+		"-var-file": complete.PredictNothing,
+		"-profile":  complete.PredictNothing,
+	}
+}
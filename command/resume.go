@@ -0,0 +1,197 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	registry "github.com/hashicorp/packer/common/cleanup"
+	"github.com/hashicorp/packer/packer"
+	"github.com/posener/complete"
+)
+
+type ResumeCommand struct {
+	Meta
+}
+
+func (c *ResumeCommand) Run(args []string) int {
+	ctx, cleanup := handleTermInterrupt(c.Ui)
+	defer cleanup()
+
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(ctx, cfg)
+}
+
+func (c *ResumeCommand) ParseArgs(args []string) (*ResumeArgs, int) {
+	var cfg ResumeArgs
+	flags := c.Meta.FlagSet("resume", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	args = flags.Args()
+	switch len(args) {
+	case 0:
+	case 1:
+		cfg.BuildID = args[0]
+	default:
+		flags.Usage()
+		return &cfg, 1
+	}
+	return &cfg, 0
+}
+
+func (c *ResumeCommand) RunContext(ctx context.Context, cla *ResumeArgs) int {
+	if cla.BuildID == "" {
+		return c.list()
+	}
+
+	path, err := packer.JournalPath(cla.BuildID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error finding journal directory: %s", err))
+		return 1
+	}
+	journal, err := packer.LoadJournal(path)
+	if os.IsNotExist(err) {
+		c.Ui.Error(fmt.Sprintf("No build %s to resume: it completed, or it never started.", cla.BuildID))
+		return 1
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading journal of build %s: %s", cla.BuildID, err))
+		return 1
+	}
+	if journal.PID != os.Getpid() && registry.ProcessAlive(journal.PID) {
+		c.Ui.Error(fmt.Sprintf("Build %s is still running in process %d.", cla.BuildID, journal.PID))
+		return 1
+	}
+
+	// Run the build command again where it ran, its template and
+	// variable files can be relative paths.
+	if err := os.Chdir(journal.Dir); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error resuming build %s: %s", cla.BuildID, err))
+		return 1
+	}
+	build := &BuildCommand{Meta: c.Meta}
+	cfg, ret := build.ParseArgs(journal.Args)
+	if ret != 0 {
+		return ret
+	}
+	cfg.Args = journal.Args
+	cfg.Only = []string{journal.BuildName}
+	cfg.Except = nil
+	cfg.Resume = journal.BuildID
+
+	c.Ui.Say(fmt.Sprintf("Resuming build '%s' (%s), started at %s",
+		journal.BuildName, journal.BuildID, journal.Started.Format(time.RFC3339)))
+	return build.RunContext(ctx, cfg)
+}
+
+// list shows the builds that can be resumed: the ones whose process died.
+func (c *ResumeCommand) list() int {
+	dir, err := packer.JournalDir()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error finding journal directory: %s", err))
+		return 1
+	}
+	journals, err := packer.Journals(dir)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading journals: %s", err))
+		return 1
+	}
+
+	found := false
+	for _, j := range journals {
+		if registry.ProcessAlive(j.PID) {
+			continue
+		}
+		found = true
+		c.Ui.Say(fmt.Sprintf("%s  %s  started at %s, %d steps completed",
+			j.BuildID, j.BuildName, j.Started.Format(time.RFC3339), len(j.Steps)))
+	}
+	if !found {
+		c.Ui.Say("No builds to resume.")
+	}
+	return 0
+}
+
+// startJournal creates the journal of a build about to run, or takes over
+// the journal of the build it resumes. It returns nil when the build has no
+// journal; failing to journal a build only gets logged.
+func startJournal(b packer.Build, cla *BuildArgs) *packer.Journal {
+	cb, ok := b.(*packer.CoreBuild)
+	if !ok || cb.Info() == nil {
+		return nil
+	}
+	path := cb.Info().Journal()
+	if path == "" {
+		return nil
+	}
+
+	journal := &packer.Journal{Path: path}
+	if cla.Resume != "" {
+		if j, err := packer.LoadJournal(path); err == nil {
+			journal = j
+		} else {
+			log.Printf("Error reading the journal of build %s: %s", b.Name(), err)
+		}
+	}
+	if journal.BuildID == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			log.Printf("Not journaling build %s: %s", b.Name(), err)
+			return nil
+		}
+		journal.BuildID = cb.Info().UUID
+		journal.BuildName = b.Name()
+		journal.Args = cla.Args
+		journal.Dir = dir
+	}
+	journal.PID = os.Getpid()
+	journal.Started = time.Now().UTC()
+	if err := journal.Save(); err != nil {
+		log.Printf("Not journaling build %s: %s", b.Name(), err)
+		return nil
+	}
+	return journal
+}
+
+func (*ResumeCommand) Help() string {
+	helpText := `
+Usage: packer resume [BUILD-ID]
+
+  Resumes a build whose packer process died, like when the machine running
+  Packer rebooted. The build runs again with the same command line
+  arguments, re-attaching to the resources the build created, like its
+  still running instance, rather than creating them again. The steps that
+  can't be resumed run again.
+
+  Every running build records the steps it completed, and what they created,
+  in a journal in the journal directory of the Packer configuration
+  directory, or in PACKER_JOURNAL_DIR when it is set. The journal is removed
+  once the build ran.
+
+  Without a build id, lists the builds that can be resumed.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*ResumeCommand) Synopsis() string {
+	return "resumes a build whose packer process died"
+}
+
+func (*ResumeCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*ResumeCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
@@ -0,0 +1,118 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/packer/lsp"
+	"github.com/hashicorp/packer/schema"
+	"github.com/posener/complete"
+)
+
+type SchemaCommand struct {
+	Meta
+}
+
+func (c *SchemaCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *SchemaCommand) ParseArgs(args []string) (*SchemaArgs, int) {
+	var cfg SchemaArgs
+	flags := c.Meta.FlagSet("schema", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if len(flags.Args()) != 0 {
+		flags.Usage()
+		return &cfg, 1
+	}
+	return &cfg, 0
+}
+
+// schemaKinds are the kinds of components of the schema.
+var schemaKinds = []struct {
+	lsp    lsp.Kind
+	schema schema.Kind
+}{
+	{lsp.Builder, schema.Builders},
+	{lsp.Provisioner, schema.Provisioners},
+	{lsp.PostProcessor, schema.PostProcessors},
+}
+
+func (c *SchemaCommand) RunContext(cla *SchemaArgs) int {
+	specs, err := newComponentSpecs(c.CoreConfig.Components, cla.DocsDir)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading docs: %s", err))
+		return 1
+	}
+
+	var components []schema.Component
+	for _, kind := range schemaKinds {
+		for _, typ := range specs.Types(kind.lsp) {
+			spec := specs.Spec(kind.lsp, typ)
+			if spec == nil {
+				c.Ui.Error(fmt.Sprintf("Skipping %s %s: its config spec could not be loaded", kind.lsp, typ))
+				continue
+			}
+			kind, typ := kind, typ
+			components = append(components, schema.Component{
+				Kind: kind.schema,
+				Type: typ,
+				Spec: spec,
+				Doc: func(option string) string {
+					return specs.Doc(kind.lsp, typ, option)
+				},
+			})
+		}
+	}
+
+	out, err := json.MarshalIndent(schema.Template(components), "", "  ")
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	c.Ui.Say(string(out))
+	return 0
+}
+
+func (*SchemaCommand) Help() string {
+	helpText := `
+Usage: packer schema [options]
+
+  Prints the JSON schema of the builders, provisioners and post-processors,
+  including the plugins, generated from their HCL2 specs: their options, the
+  types of the options, and which are required or deprecated. The schema
+  validates JSON templates, and defines the schema of the config of every
+  component, like builders.amazon-ebs.
+
+Options:
+
+  -docs=path                    Directory of the docs of the options, the website/pages/partials directory of Packer, for their descriptions and defaults.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*SchemaCommand) Synopsis() string {
+	return "prints the JSON schema of the components"
+}
+
+func (*SchemaCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*SchemaCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-docs": complete.PredictDirs("*"),
+	}
+}
@@ -3,13 +3,31 @@ package command
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/hashicorp/packer/common"
 	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/plugin"
 )
 
+// cleanupTimeoutGrace is how long after the cleanup timeout of the builds
+// Packer waits for them to return before aborting.
+const cleanupTimeoutGrace = time.Minute
+
+// hardAbort exits right away, killing the plugins without letting the builds
+// clean up.
+var hardAbort = func() {
+	plugin.CleanupClients()
+	os.Exit(1)
+}
+
+// handleTermInterrupt returns a context cancelled on the first interrupt, so
+// that the builds stop and clean up after themselves. A second interrupt, or
+// builds not done cleaning up within the cleanup timeout, abort right away.
 func handleTermInterrupt(ui packer.Ui) (context.Context, func()) {
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	// Handle interrupts for this build
@@ -28,10 +46,32 @@ func handleTermInterrupt(ui packer.Ui) (context.Context, func()) {
 				// triggered first
 				return
 			}
-			ui.Error(fmt.Sprintf("Cancelling build after receiving %s", sig))
+			ui.Error(fmt.Sprintf("Cancelling build after receiving %s, "+
+				"interrupt again to abort without cleaning up", sig))
 			cancelCtx()
 		case <-ctx.Done():
+			return
+		}
+
+		var timeout <-chan time.Time
+		if cleanupTimeout := common.CleanupTimeout(); cleanupTimeout > 0 {
+			timeout = time.After(cleanupTimeout + cleanupTimeoutGrace)
+		}
+		select {
+		case sig := <-sigCh:
+			if sig == nil {
+				// the builds are done cleaning up
+				return
+			}
+			ui.Error(fmt.Sprintf("Aborting after receiving %s again, "+
+				"resources may be left behind: run `packer cleanup` to delete them", sig))
+		case <-timeout:
+			ui.Error(fmt.Sprintf("Aborting, the builds did not clean up within %s, "+
+				"resources may be left behind: run `packer cleanup` to delete them",
+				common.CleanupTimeout()))
 		}
+		log.Printf("Hard abort")
+		hardAbort()
 	}()
 	return ctx, cleanup
 }
@@ -0,0 +1,261 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common/state"
+	"github.com/hashicorp/packer/packer"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+// acquireState records b as running in the state backend and locks its
+// names.
+func acquireState(ctx context.Context, backend state.Backend, b packer.Build, timeout time.Duration) (*state.Lease, error) {
+	var names []string
+	if cb, ok := b.(*packer.CoreBuild); ok {
+		names = cb.Locks
+	}
+	lease, err := state.NewLease(b.Name(), names)
+	if err != nil {
+		return nil, err
+	}
+	if err := state.Acquire(ctx, backend, lease, timeout); err != nil {
+		return nil, err
+	}
+	log.Printf("Build '%s' locked %q", b.Name(), lease.Locks)
+	return lease, nil
+}
+
+// releaseState releases the state of a build once it finished, even when it
+// was cancelled.
+func releaseState(backend state.Backend, lease *state.Lease, ui packer.Ui) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := state.Release(ctx, backend, lease); err != nil {
+		ui.Error(fmt.Sprintf("Failed to release the state of build '%s', unlock it with packer state unlock: %s", lease.Build, err))
+	}
+}
+
+type StateCommand struct {
+	Meta
+}
+
+func (*StateCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+func (*StateCommand) Help() string {
+	helpText := `
+Usage: packer state <subcommand> [options] [args]
+
+  Reads and changes the state shared by builds: the builds running and the
+  names they locked. The state backend is set with the -state option or the
+  PACKER_STATE environment variable.
+
+Subcommands:
+
+  list      Lists the running builds and the locked names.
+  unlock    Forcibly unlocks names.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*StateCommand) Synopsis() string {
+	return "reads and changes the state shared by builds"
+}
+
+type StateListCommand struct {
+	Meta
+}
+
+func (c *StateListCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *StateListCommand) ParseArgs(args []string) (*StateArgs, int) {
+	var cfg StateArgs
+	flags := c.Meta.FlagSet("state list", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	if flags.NArg() > 0 || cfg.State == "" {
+		flags.Usage()
+		return &cfg, 1
+	}
+	return &cfg, 0
+}
+
+func (c *StateListCommand) RunContext(cla *StateArgs) int {
+	backend, err := state.New(cla.State)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	ctx := context.Background()
+
+	builds, err := backend.Builds(ctx)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading builds: %s", err))
+		return 1
+	}
+	locks, err := backend.Locks(ctx)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading locks: %s", err))
+		return 1
+	}
+
+	sort.Slice(builds, func(i, j int) bool { return builds[i].Started.Before(builds[j].Started) })
+	c.Ui.Say(fmt.Sprintf("%d running build(s):", len(builds)))
+	for _, l := range builds {
+		c.Ui.Say(fmt.Sprintf("  %s (%s)", l, l.ID))
+	}
+
+	names := make([]string, 0, len(locks))
+	for n := range locks {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	c.Ui.Say(fmt.Sprintf("%d locked name(s):", len(names)))
+	for _, n := range names {
+		c.Ui.Say(fmt.Sprintf("  %s: %s", n, locks[n]))
+	}
+	return 0
+}
+
+func (*StateListCommand) Help() string {
+	helpText := `
+Usage: packer state list [options]
+
+  Lists the running builds and the names they locked.
+
+Options:
+
+  -state=address                The state backend (Default: $PACKER_STATE).
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*StateListCommand) Synopsis() string {
+	return "lists the running builds and the locked names"
+}
+
+func (*StateListCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*StateListCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-state": complete.PredictNothing,
+	}
+}
+
+type StateUnlockCommand struct {
+	Meta
+}
+
+func (c *StateUnlockCommand) Run(args []string) int {
+	cfg, ret := c.ParseArgs(args)
+	if ret != 0 {
+		return ret
+	}
+
+	return c.RunContext(cfg)
+}
+
+func (c *StateUnlockCommand) ParseArgs(args []string) (*StateArgs, int) {
+	var cfg StateArgs
+	flags := c.Meta.FlagSet("state unlock", FlagSetNone)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	cfg.AddFlagSets(flags)
+	if err := flags.Parse(args); err != nil {
+		return &cfg, 1
+	}
+
+	cfg.Names = flags.Args()
+	if len(cfg.Names) == 0 || cfg.State == "" {
+		flags.Usage()
+		return &cfg, 1
+	}
+	return &cfg, 0
+}
+
+func (c *StateUnlockCommand) RunContext(cla *StateArgs) int {
+	backend, err := state.New(cla.State)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	ctx := context.Background()
+
+	locks, err := backend.Locks(ctx)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading locks: %s", err))
+		return 1
+	}
+
+	ret := 0
+	for _, n := range cla.Names {
+		holder, ok := locks[n]
+		if !ok {
+			c.Ui.Say(fmt.Sprintf("%s is not locked", n))
+			continue
+		}
+		if err := backend.Unlock(ctx, n, ""); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error unlocking %s: %s", n, err))
+			ret = 1
+			continue
+		}
+		// The build was killed before it could release its state.
+		if err := backend.DeleteBuild(ctx, holder.ID); err != nil {
+			log.Printf("[WARN] Failed to forget build %s: %s", holder.ID, err)
+		}
+		c.Ui.Say(fmt.Sprintf("Unlocked %s, locked by %s", n, holder))
+	}
+	return ret
+}
+
+func (*StateUnlockCommand) Help() string {
+	helpText := `
+Usage: packer state unlock [options] NAME...
+
+  Forcibly unlocks names locked by builds that were killed before they could
+  unlock them. Unlocking the names of a build that is still running lets
+  other builds overwrite its artifacts.
+
+Options:
+
+  -state=address                The state backend (Default: $PACKER_STATE).
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*StateUnlockCommand) Synopsis() string {
+	return "forcibly unlocks names locked by builds"
+}
+
+func (*StateUnlockCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (*StateUnlockCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-state": complete.PredictNothing,
+	}
+}
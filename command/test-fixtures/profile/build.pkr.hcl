@@ -0,0 +1,11 @@
+
+variable "foo" {
+}
+source "file" "chocolate" {
+  content = var.foo
+  target  = "chocolate.txt"
+}
+
+build {
+  sources = ["source.file.chocolate"]
+}
@@ -2,8 +2,10 @@ package command
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/packer/packer"
 
 	"github.com/posener/complete"
@@ -45,34 +47,159 @@ func (c *ValidateCommand) ParseArgs(args []string) (*ValidateArgs, int) {
 }
 
 func (c *ValidateCommand) RunContext(ctx context.Context, cla *ValidateArgs) int {
-	packerStarter, ret := c.GetConfig(&cla.MetaArgs)
-	if ret != 0 {
-		return 1
+	packerStarter, files, diags := c.loadConfig(&cla.MetaArgs)
+	if diags.HasErrors() {
+		return c.writeDiags(cla, files, diags, nil)
 	}
 
 	// If we're only checking syntax, then we're done already
 	if cla.SyntaxOnly {
-		c.Ui.Say("Syntax-only check passed. Everything looks okay.")
-		return 0
+		ret := c.writeDiags(cla, files, diags, nil)
+		if ret == 0 && !cla.JSON {
+			c.Ui.Say("Syntax-only check passed. Everything looks okay.")
+		}
+		return ret
 	}
 
-	diags := packerStarter.Initialize()
-	ret = writeDiags(c.Ui, nil, diags)
-	if ret != 0 {
-		return ret
+	moreDiags := packerStarter.Initialize()
+	diags = append(diags, moreDiags...)
+	if moreDiags.HasErrors() {
+		return c.writeDiags(cla, files, diags, nil)
 	}
 
-	_, diags = packerStarter.GetBuilds(packer.GetBuildsOptions{
+	builds, moreDiags := packerStarter.GetBuilds(packer.GetBuildsOptions{
 		Only:   cla.Only,
 		Except: cla.Except,
 	})
+	diags = append(diags, moreDiags...)
 
 	fixerDiags := packerStarter.FixConfig(packer.FixConfigOptions{
 		Mode: packer.Diff,
 	})
 	diags = append(diags, fixerDiags...)
 
-	return writeDiags(c.Ui, nil, diags)
+	return c.writeDiags(cla, files, diags, builds)
+}
+
+// writeDiags writes diags, as JSON with the deprecations of builds with
+// -json, and returns the exit code of the validation.
+func (c *ValidateCommand) writeDiags(cla *ValidateArgs, files map[string]*hcl.File, diags hcl.Diagnostics, builds []packer.Build) int {
+	if !cla.JSON {
+		return writeDiags(c.Ui, files, diags)
+	}
+
+	// The diagnostics of JSON templates without a position are about the
+	// template.
+	filename := cla.Path
+	if cfgType, _ := cla.GetConfigType(); cfgType == ConfigTypeHCL2 {
+		filename = ""
+	}
+	return c.writeJSON(diags, filename, builds)
+}
+
+// validateOutput is the output of `packer validate -json`.
+type validateOutput struct {
+	Valid        bool             `json:"valid"`
+	ErrorCount   int              `json:"error_count"`
+	WarningCount int              `json:"warning_count"`
+	Diagnostics  []jsonDiagnostic `json:"diagnostics"`
+	// Deprecations are the deprecated options set in the template, also
+	// reported as warnings.
+	Deprecations []jsonDeprecation `json:"deprecations"`
+}
+
+// jsonDeprecation is a deprecated option of `packer validate -json`. The
+// replacement is left out when nothing replaces the option.
+type jsonDeprecation struct {
+	Build       string `json:"build"`
+	Kind        string `json:"kind"`
+	Type        string `json:"type"`
+	Option      string `json:"option"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// jsonDiagnostic is a diagnostic of `packer validate -json`. The position is
+// left out when the diagnostic has none, like for the warnings of the
+// builders of JSON templates.
+type jsonDiagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail,omitempty"`
+
+	Filename  string `json:"filename,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	EndColumn int    `json:"end_column,omitempty"`
+}
+
+// newJSONDiagnostic converts diag, defaulting its file to filename when it
+// has no position.
+func newJSONDiagnostic(diag *hcl.Diagnostic, filename string) jsonDiagnostic {
+	d := jsonDiagnostic{
+		Severity: "error",
+		Summary:  diag.Summary,
+		Detail:   diag.Detail,
+		Filename: filename,
+	}
+	if diag.Severity == hcl.DiagWarning {
+		d.Severity = "warning"
+	}
+	if r := diag.Subject; r != nil {
+		if r.Filename != "" {
+			d.Filename = r.Filename
+		}
+		d.Line, d.Column = r.Start.Line, r.Start.Column
+		if r.End.Line > 0 {
+			d.EndLine, d.EndColumn = r.End.Line, r.End.Column
+		}
+	}
+	return d
+}
+
+// writeJSON writes the output of `packer validate -json`. filename is the
+// file of the diagnostics without a position.
+func (c *ValidateCommand) writeJSON(diags hcl.Diagnostics, filename string, builds []packer.Build) int {
+	out := validateOutput{
+		Valid:        !diags.HasErrors(),
+		Diagnostics:  []jsonDiagnostic{},
+		Deprecations: []jsonDeprecation{},
+	}
+	for _, diag := range diags {
+		d := newJSONDiagnostic(diag, filename)
+		if d.Severity == "warning" {
+			out.WarningCount++
+		} else {
+			out.ErrorCount++
+		}
+		out.Diagnostics = append(out.Diagnostics, d)
+	}
+	for _, b := range builds {
+		cb, ok := b.(*packer.CoreBuild)
+		if !ok {
+			continue
+		}
+		for _, d := range cb.Deprecations() {
+			out.Deprecations = append(out.Deprecations, jsonDeprecation{
+				Build:       b.Name(),
+				Kind:        d.Kind,
+				Type:        d.Type,
+				Option:      d.Option,
+				Replacement: d.Replacement,
+			})
+		}
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	c.Ui.Say(string(b))
+	if !out.Valid {
+		return 1
+	}
+	return 0
 }
 
 func (*ValidateCommand) Help() string {
@@ -89,8 +216,10 @@ Usage: packer validate [options] TEMPLATE
 Options:
 
   -syntax-only           Only check syntax. Do not verify config of the template.
+  -json                  Print the errors and warnings as JSON, with their positions.
   -except=foo,bar,baz    Validate all builds other than these.
   -only=foo,bar,baz      Validate only these builds.
+  -profile=name          Load the name.pkrvars.hcl and name.pkrvars.json var files found next to the template.
   -var 'key=value'       Variable for templates, can be used multiple times.
   -var-file=path         JSON file containing user variables. [ Note that even in HCL mode this expects file to contain JSON, a fix is comming soon ]
 `
@@ -109,8 +238,10 @@ func (*ValidateCommand) AutocompleteArgs() complete.Predictor {
 func (*ValidateCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
 		"-syntax-only": complete.PredictNothing,
+		"-json":        complete.PredictNothing,
 		"-except":      complete.PredictNothing,
 		"-only":        complete.PredictNothing,
+		"-profile":     complete.PredictNothing,
 		"-var":         complete.PredictNothing,
 		"-var-file":    complete.PredictNothing,
 	}
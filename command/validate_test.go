@@ -1,6 +1,7 @@
 package command
 
 import (
+	"encoding/json"
 	"path/filepath"
 	"testing"
 
@@ -162,3 +163,41 @@ func TestValidateCommandExcept(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCommand_JSON(t *testing.T) {
+	tt := []struct {
+		path     string
+		exitCode int
+	}{
+		{path: filepath.Join(testFixture("validate"), "build.pkr.hcl")},
+		{path: filepath.Join(testFixture("validate"), "build.json")},
+		{path: filepath.Join(testFixture("validate-invalid"), "missing_build_block.pkr.hcl"), exitCode: 1},
+		{path: filepath.Join(testFixture("validate-invalid"), "bad_provisioner.json"), exitCode: 1},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.path, func(t *testing.T) {
+			c := &ValidateCommand{
+				Meta: testMetaFile(t),
+			}
+			tc := tc
+			if code := c.Run([]string{"-json", tc.path}); code != tc.exitCode {
+				fatalCommand(t, c.Meta)
+			}
+
+			stdout, _ := outputCommand(t, c.Meta)
+			var out validateOutput
+			if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+				t.Fatalf("invalid JSON output %q: %s", stdout, err)
+			}
+			if out.Valid != (tc.exitCode == 0) || out.Valid != (out.ErrorCount == 0) {
+				t.Fatalf("unexpected output: %s", stdout)
+			}
+			for _, d := range out.Diagnostics {
+				if d.Severity == "error" && d.Filename != tc.path {
+					t.Fatalf("error without the position of the template: %s", stdout)
+				}
+			}
+		})
+	}
+}
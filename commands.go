@@ -17,11 +17,46 @@ const OutputPrefix = "o:"
 
 func init() {
 	Commands = map[string]cli.CommandFactory{
+		"agent": func() (cli.Command, error) {
+			return &command.AgentCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"artifacts": func() (cli.Command, error) {
+			return &command.ArtifactsCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"artifacts diff": func() (cli.Command, error) {
+			return &command.ArtifactsDiffCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"artifacts list": func() (cli.Command, error) {
+			return &command.ArtifactsListCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"artifacts show": func() (cli.Command, error) {
+			return &command.ArtifactsShowCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
 		"build": func() (cli.Command, error) {
 			return &command.BuildCommand{
 				Meta: *CommandMeta,
 			}, nil
 		},
+		"cleanup": func() (cli.Command, error) {
+			return &command.CleanupCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
 		"console": func() (cli.Command, error) {
 			return &command.ConsoleCommand{
 				Meta: *CommandMeta,
@@ -34,12 +69,102 @@ func init() {
 			}, nil
 		},
 
+		"fmt": func() (cli.Command, error) {
+			return &command.FormatCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"hcl2_upgrade": func() (cli.Command, error) {
+			return &command.HCL2UpgradeCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"image-diff": func() (cli.Command, error) {
+			return &command.ImageDiffCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"init": func() (cli.Command, error) {
+			return &command.InitCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
 		"inspect": func() (cli.Command, error) {
 			return &command.InspectCommand{
 				Meta: *CommandMeta,
 			}, nil
 		},
 
+		"lsp": func() (cli.Command, error) {
+			return &command.LspCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"plan": func() (cli.Command, error) {
+			return &command.PlanCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"registry": func() (cli.Command, error) {
+			return &command.RegistryCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"registry list": func() (cli.Command, error) {
+			return &command.RegistryListCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"registry promote": func() (cli.Command, error) {
+			return &command.RegistryPromoteCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"remote-build": func() (cli.Command, error) {
+			return &command.RemoteBuildCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"resume": func() (cli.Command, error) {
+			return &command.ResumeCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"schema": func() (cli.Command, error) {
+			return &command.SchemaCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"state": func() (cli.Command, error) {
+			return &command.StateCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"state list": func() (cli.Command, error) {
+			return &command.StateListCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"state unlock": func() (cli.Command, error) {
+			return &command.StateUnlockCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
 		"validate": func() (cli.Command, error) {
 			return &command.ValidateCommand{
 				Meta: *CommandMeta,
@@ -53,10 +178,28 @@ func init() {
 			}, nil
 		},
 
+		"lint": func() (cli.Command, error) {
+			return &command.LintCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
+		"output": func() (cli.Command, error) {
+			return &command.OutputCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
+
 		"plugin": func() (cli.Command, error) {
 			return &command.PluginCommand{
 				Meta: *CommandMeta,
 			}, nil
 		},
+
+		"plugin scaffold": func() (cli.Command, error) {
+			return &command.PluginScaffoldCommand{
+				Meta: *CommandMeta,
+			}, nil
+		},
 	}
 }
@@ -0,0 +1,348 @@
+// Package agent runs builds submitted over HTTP by remote Packer processes.
+// An agent runs next to the hypervisor or the cloud credentials builds
+// need; packer remote-build uploads a configuration to it, and the agent
+// streams the events of the build back, like packer build -ui=json.
+package agent
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/kardianos/osext"
+	"golang.org/x/sync/semaphore"
+)
+
+// EnvToken is the environment variable setting the token of the agent when
+// the -token option isn't set.
+const EnvToken = "PACKER_AGENT_TOKEN"
+
+// EnvAddress is the environment variable setting the address of the agent
+// packer remote-build submits builds to.
+const EnvAddress = "PACKER_AGENT_ADDR"
+
+// BuildsPath is the path builds are submitted to.
+const BuildsPath = "/v1/builds"
+
+// Types of the events the agent adds to the events of the build.
+const (
+	// EventJobStarted is sent when the build of a job starts; its message
+	// is the directory of the job on the agent.
+	EventJobStarted = "job-started"
+	// EventJobFinished is the last event of a job; its error is set when
+	// the build failed.
+	EventJobFinished = "job-finished"
+)
+
+// DefaultMaxUploadSize is the default size limit of a job.
+const DefaultMaxUploadSize = 100 << 20
+
+// Job is a build submitted to an agent.
+type Job struct {
+	// Files are the files of the configuration, by slash separated path
+	// relative to the directory of the job.
+	Files map[string][]byte `json:"files"`
+	// Path is the template, or the directory of the HCL2 configuration, in
+	// Files.
+	Path string `json:"path"`
+
+	Only     []string          `json:"only,omitempty"`
+	Except   []string          `json:"except,omitempty"`
+	Vars     map[string]string `json:"vars,omitempty"`
+	VarFiles []string          `json:"var_files,omitempty"`
+	Force    bool              `json:"force,omitempty"`
+	// OnError is the -on-error option of the build, "ask" excepted.
+	OnError string `json:"on_error,omitempty"`
+}
+
+// validPath tells if p is a path relative to the directory of a job that
+// doesn't leave it.
+func validPath(p string) bool {
+	if p == "" || path.IsAbs(p) || strings.Contains(p, `\`) {
+		return false
+	}
+	clean := path.Clean(p)
+	return clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+// Validate checks that the paths of j stay in the directory of the job.
+func (j *Job) Validate() error {
+	if len(j.Files) == 0 {
+		return fmt.Errorf("the job has no file")
+	}
+	for p := range j.Files {
+		if !validPath(p) {
+			return fmt.Errorf("invalid file path %q", p)
+		}
+	}
+	for _, p := range append([]string{j.Path}, j.VarFiles...) {
+		if p != "." && !validPath(p) {
+			return fmt.Errorf("invalid path %q", p)
+		}
+	}
+	if j.OnError == "ask" {
+		return fmt.Errorf("-on-error=ask can't be used with a remote build")
+	}
+	return nil
+}
+
+// BuildArgs returns the arguments of packer build running j in dir.
+func (j *Job) BuildArgs(dir string) []string {
+	args := []string{"build", "-ui=json", "-color=false"}
+	if j.Force {
+		args = append(args, "-force")
+	}
+	if j.OnError != "" {
+		args = append(args, "-on-error="+j.OnError)
+	}
+	if len(j.Only) > 0 {
+		args = append(args, "-only="+strings.Join(j.Only, ","))
+	}
+	if len(j.Except) > 0 {
+		args = append(args, "-except="+strings.Join(j.Except, ","))
+	}
+	names := make([]string, 0, len(j.Vars))
+	for name := range j.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "-var", name+"="+j.Vars[name])
+	}
+	for _, f := range j.VarFiles {
+		args = append(args, "-var-file="+filepath.Join(dir, filepath.FromSlash(f)))
+	}
+	return append(args, filepath.Join(dir, filepath.FromSlash(j.Path)))
+}
+
+// write writes the files of j in dir.
+func (j *Job) write(dir string) error {
+	for p, content := range j.Files {
+		p = filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(p, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Server runs the jobs submitted to BuildsPath.
+type Server struct {
+	// Token authenticates the clients, which send it as a bearer token. It
+	// is required.
+	Token string
+	// WorkDir is where the directories of the jobs are created. They are
+	// kept after the builds, with the artifacts written in them.
+	WorkDir string
+	// MaxJobs is the number of builds running at once; other jobs wait.
+	// Zero means no limit.
+	MaxJobs int64
+	// MaxUploadSize is the size limit of a job, DefaultMaxUploadSize when
+	// zero.
+	MaxUploadSize int64
+
+	// Command returns the command running packer build with args; the
+	// packer binary of this process by default. The command is interrupted
+	// when the client goes away, so that the build cleans up.
+	Command func(args []string) (*exec.Cmd, error)
+
+	once sync.Once
+	sem  *semaphore.Weighted
+}
+
+func (s *Server) command(args []string) (*exec.Cmd, error) {
+	if s.Command != nil {
+		return s.Command(args)
+	}
+	exe, err := osext.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(exe, args...), nil
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if s.Token == "" || !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	token := strings.TrimPrefix(auth, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != BuildsPath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		log.Printf("[WARN] agent: unauthorized request from %s", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	maxSize := s.MaxUploadSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxUploadSize
+	}
+	var job Job
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSize)).Decode(&job); err != nil {
+		http.Error(w, fmt.Sprintf("invalid job: %s", err), http.StatusBadRequest)
+		return
+	}
+	if err := job.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	out := &flushWriter{w: w}
+	if f, ok := w.(http.Flusher); ok {
+		out.f = f
+	}
+	events := packer.NewEventStream(out)
+
+	err := s.run(r.Context(), &job, events)
+	finished := packer.Event{Type: EventJobFinished}
+	if err != nil {
+		finished.Error = err.Error()
+	}
+	events.Emit(finished)
+}
+
+// run runs the build of job, streaming its events to events.
+func (s *Server) run(ctx context.Context, job *Job, events *packer.EventStream) error {
+	s.once.Do(func() {
+		if s.MaxJobs > 0 {
+			s.sem = semaphore.NewWeighted(s.MaxJobs)
+		}
+	})
+	if s.sem != nil {
+		if !s.sem.TryAcquire(1) {
+			events.Emit(packer.Event{Type: packer.EventUi, Level: "say", Message: "Waiting for a running job to finish..."})
+			if err := s.sem.Acquire(ctx, 1); err != nil {
+				return err
+			}
+		}
+		defer s.sem.Release(1)
+	}
+
+	if err := os.MkdirAll(s.WorkDir, 0755); err != nil {
+		return err
+	}
+	dir, err := ioutil.TempDir(s.WorkDir, time.Now().UTC().Format("20060102-150405-"))
+	if err != nil {
+		return err
+	}
+	if err := job.write(dir); err != nil {
+		return fmt.Errorf("could not write the files of the job: %s", err)
+	}
+	events.Emit(packer.Event{Type: EventJobStarted, Message: dir})
+	log.Printf("agent: running job in %s", dir)
+
+	cmd, err := s.command(job.BuildArgs(dir))
+	if err != nil {
+		return err
+	}
+	cmd.Dir = dir
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			log.Printf("agent: client of the job in %s went away, interrupting the build", dir)
+			if err := cmd.Process.Signal(os.Interrupt); err != nil {
+				cmd.Process.Kill()
+			}
+		case <-done:
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		forwardEvents(stdout, events, "say")
+	}()
+	go func() {
+		defer wg.Done()
+		forwardEvents(stderr, events, "error")
+	}()
+	wg.Wait()
+
+	err = cmd.Wait()
+	log.Printf("agent: job in %s finished: %v", dir, err)
+	if err != nil {
+		return fmt.Errorf("build failed: %s", err)
+	}
+	return nil
+}
+
+// forwardEvents emits the events read from r, and the other lines as ui
+// events of level.
+func forwardEvents(r io.Reader, events *packer.EventStream, level string) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var e packer.Event
+		if err := json.Unmarshal(line, &e); err == nil && e.Type != "" {
+			events.Emit(e)
+			continue
+		}
+		events.Emit(packer.Event{Type: packer.EventUi, Level: level, Message: string(line)})
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("[ERR] agent: reading the output of the build: %s", err)
+	}
+}
+
+// flushWriter flushes every write, so that events reach the client as
+// they happen.
+type flushWriter struct {
+	w io.Writer
+	f http.Flusher
+}
+
+func (fw *flushWriter) Write(b []byte) (int, error) {
+	n, err := fw.w.Write(b)
+	if fw.f != nil {
+		fw.f.Flush()
+	}
+	return n, err
+}
@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// TestHelperProcess is the packer build the tests run: it prints an
+// artifact event for the template it gets, and fails when the template is
+// fail.pkr.hcl.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	template := args[len(args)-1]
+	content, err := ioutil.ReadFile(template)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr, "a log line")
+	if filepath.Base(template) == "fail.pkr.hcl" {
+		os.Exit(1)
+	}
+	e, _ := json.Marshal(packer.Event{
+		Type:     packer.EventArtifact,
+		Build:    "null.test",
+		Artifact: &packer.ArtifactEvent{ID: strings.TrimSpace(string(content))},
+	})
+	fmt.Println(string(e))
+}
+
+func testServer(t *testing.T) (*Client, func()) {
+	dir, err := ioutil.TempDir("", "packer-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		Token:   "secret",
+		WorkDir: dir,
+		MaxJobs: 1,
+		Command: func(args []string) (*exec.Cmd, error) {
+			cmd := exec.Command(os.Args[0], append([]string{"-test.run=TestHelperProcess", "--"}, args...)...)
+			cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1")
+			return cmd, nil
+		},
+	}
+	ts := httptest.NewServer(s)
+	return &Client{Address: ts.URL, Token: "secret"}, func() {
+		ts.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestClient_Build(t *testing.T) {
+	c, cleanup := testServer(t)
+	defer cleanup()
+
+	var events []packer.Event
+	job := &Job{
+		Files: map[string][]byte{"build.pkr.hcl": []byte("image-1")},
+		Path:  "build.pkr.hcl",
+	}
+	if err := c.Build(context.Background(), job, func(e packer.Event) { events = append(events, e) }); err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	var artifact *packer.ArtifactEvent
+	for _, e := range events {
+		types = append(types, e.Type)
+		if e.Type == packer.EventArtifact {
+			artifact = e.Artifact
+		}
+	}
+	// The order of the outputs of the build is not deterministic.
+	if len(types) != 3 || types[0] != EventJobStarted {
+		t.Fatalf("unexpected events: %v", types)
+	}
+	if artifact == nil || artifact.ID != "image-1" {
+		t.Fatalf("unexpected artifact: %#v", artifact)
+	}
+}
+
+func TestClient_Build_failed(t *testing.T) {
+	c, cleanup := testServer(t)
+	defer cleanup()
+
+	job := &Job{
+		Files: map[string][]byte{"fail.pkr.hcl": []byte("")},
+		Path:  "fail.pkr.hcl",
+	}
+	err := c.Build(context.Background(), job, func(packer.Event) {})
+	if err == nil || !strings.Contains(err.Error(), "build failed") {
+		t.Fatalf("expected a failed build, got %v", err)
+	}
+}
+
+func TestClient_Build_refused(t *testing.T) {
+	c, cleanup := testServer(t)
+	defer cleanup()
+
+	job := &Job{
+		Files: map[string][]byte{"build.pkr.hcl": nil},
+		Path:  "build.pkr.hcl",
+	}
+	bad := *c
+	bad.Token = "wrong"
+	if err := bad.Build(context.Background(), job, func(packer.Event) {}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+
+	job.Files["../escape"] = nil
+	if err := c.Build(context.Background(), job, func(packer.Event) {}); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("expected a bad request error, got %v", err)
+	}
+}
+
+func TestJob_BuildArgs(t *testing.T) {
+	job := &Job{
+		Path:     "build.pkr.hcl",
+		Only:     []string{"a", "b"},
+		Vars:     map[string]string{"b": "2", "a": "1"},
+		VarFiles: []string{"x.pkrvars.hcl"},
+		Force:    true,
+	}
+	dir := filepath.FromSlash("/jobs/1")
+	expected := []string{
+		"build", "-ui=json", "-color=false", "-force", "-only=a,b",
+		"-var", "a=1", "-var", "b=2",
+		"-var-file=" + filepath.Join(dir, "x.pkrvars.hcl"),
+		filepath.Join(dir, "build.pkr.hcl"),
+	}
+	if args := job.BuildArgs(dir); !reflect.DeepEqual(args, expected) {
+		t.Fatalf("unexpected args:\n%q\nexpected:\n%q", args, expected)
+	}
+}
+
+func TestNewJob(t *testing.T) {
+	root, err := ioutil.TempDir("", "packer-job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	for _, p := range []string{"build.pkr.hcl", "scripts/setup.sh", ".git/HEAD", "packer_cache/x.iso"} {
+		p = filepath.Join(root, filepath.FromSlash(p))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := ioutil.WriteFile(p, []byte(p), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outside, err := ioutil.TempFile("", "vars.pkrvars.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside.Close()
+	defer os.Remove(outside.Name())
+
+	job, err := NewJob(root, root, []string{filepath.Join(root, "build.pkr.hcl"), outside.Name()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Path != "." {
+		t.Errorf("unexpected path %q", job.Path)
+	}
+	var files []string
+	for p := range job.Files {
+		files = append(files, p)
+	}
+	varFile := ".packer-agent/1-" + filepath.Base(outside.Name())
+	if len(files) != 3 || job.Files["build.pkr.hcl"] == nil || job.Files["scripts/setup.sh"] == nil || job.Files[varFile] == nil {
+		t.Errorf("unexpected files: %q", files)
+	}
+	if expected := []string{"build.pkr.hcl", varFile}; !reflect.DeepEqual(job.VarFiles, expected) {
+		t.Errorf("unexpected var files %q", job.VarFiles)
+	}
+	if err := job.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := NewJob(filepath.Join(root, "scripts"), filepath.Join(root, "build.pkr.hcl"), nil); err == nil {
+		t.Error("expected an error for a template outside of the root")
+	}
+}
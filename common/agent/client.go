@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// Client submits jobs to an agent.
+type Client struct {
+	// Address is the URL of the agent, like https://lab:8181.
+	Address string
+	Token   string
+	// HTTPClient is http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+// Build submits job and calls handle with the events of its build, until
+// it finishes. It returns an error when the build failed.
+func (c *Client) Build(ctx context.Context, job *Job, handle func(packer.Event)) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.Address, "/")+BuildsPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("agent refused the job: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var e packer.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("invalid event from the agent: %s", err)
+		}
+		if e.Type == EventJobFinished {
+			if e.Error != "" {
+				return fmt.Errorf("%s", e.Error)
+			}
+			return nil
+		}
+		handle(e)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("lost the connection to the agent before the end of the build")
+}
+
+// skipDirs are the directories NewJob doesn't upload.
+var skipDirs = map[string]bool{
+	"packer_cache": true,
+}
+
+// NewJob returns the job of the template or HCL2 configuration directory
+// at path: the files of root, which must contain path, are uploaded, so
+// that the files the configuration references are found on the agent.
+// Hidden files and directories, and the packer_cache directory, are
+// skipped. Var files outside of root are uploaded in .packer-agent.
+func NewJob(root, path string, varFiles []string) (*Job, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	rel := func(p string) (string, error) {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return "", err
+		}
+		r, err := filepath.Rel(root, abs)
+		if err != nil {
+			return "", err
+		}
+		r = filepath.ToSlash(r)
+		if r != "." && !validPath(r) {
+			return "", fmt.Errorf("%s is not in %s", p, root)
+		}
+		return r, nil
+	}
+
+	job := &Job{Files: map[string][]byte{}}
+	if job.Path, err = rel(path); err != nil {
+		return nil, err
+	}
+
+	err = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != root && (strings.HasPrefix(info.Name(), ".") || skipDirs[info.Name()]) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		r, err := rel(p)
+		if err != nil {
+			return err
+		}
+		job.Files[r] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, f := range varFiles {
+		r, err := rel(f)
+		if err != nil {
+			content, err := ioutil.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			r = fmt.Sprintf(".packer-agent/%d-%s", i, filepath.Base(f))
+			job.Files[r] = content
+		} else if _, ok := job.Files[r]; !ok {
+			// Hidden var files are skipped by the walk.
+			content, err := ioutil.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			job.Files[r] = content
+		}
+		job.VarFiles = append(job.VarFiles, r)
+	}
+	return job, nil
+}
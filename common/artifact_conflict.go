@@ -0,0 +1,43 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// StateSkipped is set in the state of a build skipped because its artifact
+// already exists, or because it only ran its preflight checks.
+const StateSkipped = "skipped"
+
+// HandleArtifactConflict handles an existing artifact with the name of the
+// artifact of a build, described by kind, like "AMI", and name, according to
+// onConflict. It returns ActionContinue when the step should go on and force
+// or replace the artifact, and ActionHalt when the build fails or is skipped.
+func HandleArtifactConflict(state multistep.StateBag, onConflict, kind, name string) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+
+	switch onConflict {
+	case packer.ConflictForce, packer.ConflictReplace:
+		return multistep.ActionContinue
+	case packer.ConflictSkip:
+		ui.Say(fmt.Sprintf("%s %s already exists, skipping the build.", kind, name))
+		state.Put(StateSkipped, true)
+		return multistep.ActionHalt
+	default:
+		err := fmt.Errorf("%s %s already exists.\n\n"+
+			"Use -force or -replace to replace it, or -skip-if-exists to skip the build.",
+			kind, name)
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+}
+
+// Skipped tells whether a build was skipped because its artifact already
+// exists or because it only ran its preflight checks, in which case the
+// builder returns no artifact and no error.
+func Skipped(state multistep.StateBag) bool {
+	_, ok := state.GetOk(StateSkipped)
+	return ok
+}
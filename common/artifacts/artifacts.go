@@ -0,0 +1,240 @@
+// Package artifacts records the metadata of every build Packer runs in an
+// append-only store: what the build produced, like the id of an AMI, and
+// what it was built from, like the commit of the template and its variables,
+// so that the history of the builds can be queried without reading the logs
+// of CI jobs.
+//
+// The file and HTTP stores are built in.
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EnvStore is the environment variable setting the store when the -store
+// option isn't set. Setting it to Disabled stops recording builds.
+const EnvStore = "PACKER_ARTIFACTS_STORE"
+
+// Disabled is the address of no store.
+const Disabled = "off"
+
+// ErrNotFound is returned for records that aren't in a store.
+var ErrNotFound = errors.New("not found")
+
+// Artifact describes an artifact of a build.
+type Artifact struct {
+	BuilderID string   `json:"builder_id"`
+	ID        string   `json:"id"`
+	String    string   `json:"string"`
+	Files     []string `json:"files,omitempty"`
+}
+
+// Git is the git metadata of the template of a build.
+type Git struct {
+	Commit string `json:"commit,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	Dirty  bool   `json:"dirty,omitempty"`
+}
+
+// Inputs are what a build was built from.
+type Inputs struct {
+	// Hash is the hash of all the inputs of the build: the version of
+	// Packer, the files of the template and the files it references, its
+	// variables and the outputs of the builds it depends on. Builds with the
+	// same hash were built from the same inputs.
+	Hash string `json:"hash,omitempty"`
+	// Variables are the variables set on the command line and in the
+	// environment; sensitive values are hidden.
+	Variables map[string]string `json:"variables,omitempty"`
+	// VarFiles are the hashes of the var files, by path.
+	VarFiles map[string]string `json:"var_files,omitempty"`
+	// Dependencies are the outputs of the builds the build depends on, by
+	// build name.
+	Dependencies map[string]map[string]string `json:"dependencies,omitempty"`
+}
+
+// Record is the metadata of a run of a build.
+type Record struct {
+	// ID is unique to the run of the build.
+	ID string `json:"id"`
+	// Build is the name of the build in its template.
+	Build string `json:"build"`
+	// Template is the absolute path of the template.
+	Template      string    `json:"template,omitempty"`
+	Host          string    `json:"host,omitempty"`
+	PackerVersion string    `json:"packer_version,omitempty"`
+	Started       time.Time `json:"started"`
+	Finished      time.Time `json:"finished"`
+	Git           Git       `json:"git"`
+	Inputs        Inputs    `json:"inputs"`
+	// Cached tells that the build reused the artifacts of a previous build
+	// with the same inputs.
+	Cached bool `json:"cached,omitempty"`
+	// Error is why the build failed, empty when it succeeded.
+	Error     string            `json:"error,omitempty"`
+	Outputs   map[string]string `json:"outputs,omitempty"`
+	Artifacts []Artifact        `json:"artifacts,omitempty"`
+}
+
+// Succeeded tells whether the build succeeded.
+func (r *Record) Succeeded() bool {
+	return r.Error == ""
+}
+
+// Store stores records. Records are never changed nor removed once added.
+type Store interface {
+	// Append adds r to the store.
+	Append(ctx context.Context, r *Record) error
+	// Records returns the records of the store, in the order they were
+	// added.
+	Records(ctx context.Context) ([]*Record, error)
+}
+
+// New returns the store configured by address:
+//
+//	file:path                  a JSON lines file
+//	http(s)://host/prefix      the HTTP API served by NewHandler
+func New(address string) (Store, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifacts store %q: %s", address, err)
+	}
+	switch u.Scheme {
+	case "file":
+		path := u.Opaque
+		if path == "" {
+			path = u.Path
+		}
+		if path == "" {
+			return nil, fmt.Errorf("invalid artifacts store %q: the file store needs a path", address)
+		}
+		return NewFile(path), nil
+	case "http", "https":
+		return newHTTP(u), nil
+	default:
+		return nil, fmt.Errorf("invalid artifacts store %q: unknown scheme %q", address, u.Scheme)
+	}
+}
+
+// Filter returns the records of records matching the build name and the
+// template, when they are set.
+func Filter(records []*Record, build, template string) []*Record {
+	var res []*Record
+	for _, r := range records {
+		if build != "" && r.Build != build {
+			continue
+		}
+		if template != "" && r.Template != template {
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// Find returns the record designated by ref: the record with the ID ref or
+// starting with ref, or the latest record of the build named ref. It returns
+// ErrNotFound when there is none, and an error when the prefix is ambiguous.
+func Find(records []*Record, ref string) (*Record, error) {
+	if ref == "" {
+		return nil, ErrNotFound
+	}
+	var matches []*Record
+	for _, r := range records {
+		if r.ID == ref {
+			return r, nil
+		}
+		if strings.HasPrefix(r.ID, ref) {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%q matches %d records", ref, len(matches))
+	}
+	if builds := Filter(records, ref, ""); len(builds) > 0 {
+		return builds[len(builds)-1], nil
+	}
+	return nil, ErrNotFound
+}
+
+// Change is a difference between two records.
+type Change struct {
+	// Field is what changed, like "variable region".
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %q => %q", c.Field, c.Old, c.New)
+}
+
+// Diff returns the differences between the inputs, the outputs and the
+// artifacts of old and new, sorted by field.
+func Diff(old, new *Record) []Change {
+	var changes []Change
+	add := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, Change{Field: field, Old: o, New: n})
+		}
+	}
+	addMap := func(prefix string, o, n map[string]string) {
+		for k, v := range o {
+			add(prefix+" "+k, v, n[k])
+		}
+		for k, v := range n {
+			if _, ok := o[k]; !ok {
+				add(prefix+" "+k, "", v)
+			}
+		}
+	}
+
+	add("build", old.Build, new.Build)
+	add("template", old.Template, new.Template)
+	add("packer version", old.PackerVersion, new.PackerVersion)
+	add("git commit", old.Git.Commit, new.Git.Commit)
+	add("git branch", old.Git.Branch, new.Git.Branch)
+	add("git dirty", fmt.Sprint(old.Git.Dirty), fmt.Sprint(new.Git.Dirty))
+	add("inputs hash", old.Inputs.Hash, new.Inputs.Hash)
+	addMap("variable", old.Inputs.Variables, new.Inputs.Variables)
+	addMap("var file", old.Inputs.VarFiles, new.Inputs.VarFiles)
+	deps := map[string]bool{}
+	for dep := range old.Inputs.Dependencies {
+		deps[dep] = true
+	}
+	for dep := range new.Inputs.Dependencies {
+		deps[dep] = true
+	}
+	for dep := range deps {
+		addMap("dependency "+dep, old.Inputs.Dependencies[dep], new.Inputs.Dependencies[dep])
+	}
+	add("error", old.Error, new.Error)
+	addMap("output", old.Outputs, new.Outputs)
+	addMap("artifact", artifactIDs(old), artifactIDs(new))
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// artifactIDs returns the IDs of the artifacts of r, by builder ID.
+func artifactIDs(r *Record) map[string]string {
+	ids := map[string]string{}
+	for _, a := range r.Artifacts {
+		if id, ok := ids[a.BuilderID]; ok {
+			ids[a.BuilderID] = id + "," + a.ID
+		} else {
+			ids[a.BuilderID] = a.ID
+		}
+	}
+	return ids
+}
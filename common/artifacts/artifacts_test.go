@@ -0,0 +1,161 @@
+package artifacts
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testRecord(id, build, ami string) *Record {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &Record{
+		ID:       id,
+		Build:    build,
+		Template: "/src/base.pkr.hcl",
+		Started:  now,
+		Finished: now.Add(time.Minute),
+		Git:      Git{Commit: "abc", Branch: "main"},
+		Inputs: Inputs{
+			Hash:      "hash-" + ami,
+			Variables: map[string]string{"region": "us-east-1"},
+		},
+		Outputs:   map[string]string{"ami": ami},
+		Artifacts: []Artifact{{BuilderID: "mitchellh.amazonebs", ID: "us-east-1:" + ami, String: "AMIs were created"}},
+	}
+}
+
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	if records, err := s.Records(ctx); err != nil || len(records) != 0 {
+		t.Fatalf("unexpected records of an empty store: %v, %v", records, err)
+	}
+
+	var want []*Record
+	for i, ami := range []string{"ami-1", "ami-2"} {
+		r := testRecord("0000000"+string('1'+rune(i)), "amazon-ebs.base", ami)
+		if err := s.Append(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, r)
+	}
+
+	records, err := s.Records(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("unexpected records %#v, expected %#v", records, want)
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "store", "artifacts.jsonl")
+	testStore(t, NewFile(path))
+
+	// A record cut while being appended is skipped.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id": "cut`)
+	f.Close()
+	records, err := NewFile(path).Records(context.Background())
+	if err != nil || len(records) != 2 {
+		t.Fatalf("unexpected records %v, %v", records, err)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(NewHandler(NewFile(filepath.Join(dir, "artifacts.jsonl")), "secret"))
+	defer server.Close()
+
+	s, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Records(context.Background()); err == nil {
+		t.Fatal("should error without the token")
+	}
+	s.(*HTTP).Token = "secret"
+	testStore(t, s)
+}
+
+func TestNew(t *testing.T) {
+	if s, err := New("file:/tmp/artifacts.jsonl"); err != nil || s.(*File).Path != "/tmp/artifacts.jsonl" {
+		t.Fatalf("unexpected store %#v, %v", s, err)
+	}
+	for _, address := range []string{"file:", "s3://bucket", "::"} {
+		if _, err := New(address); err == nil {
+			t.Fatalf("%q should error", address)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	records := []*Record{
+		testRecord("5f1a-0001", "amazon-ebs.base", "ami-1"),
+		testRecord("5f1a-0002", "amazon-ebs.base", "ami-2"),
+		testRecord("6a00-0003", "qemu.base", "disk"),
+	}
+
+	for ref, id := range map[string]string{
+		"5f1a-0001":       "5f1a-0001",
+		"6a":              "6a00-0003",
+		"amazon-ebs.base": "5f1a-0002",
+	} {
+		r, err := Find(records, ref)
+		if err != nil || r.ID != id {
+			t.Fatalf("%q: unexpected record %v, %v, expected %s", ref, r, err, id)
+		}
+	}
+	if _, err := Find(records, "5f1a"); err == nil || err == ErrNotFound {
+		t.Fatalf("an ambiguous prefix should error, got %v", err)
+	}
+	if _, err := Find(records, "nope"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if got := Filter(records, "qemu.base", ""); len(got) != 1 || got[0].ID != "6a00-0003" {
+		t.Fatalf("unexpected filtered records %v", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := testRecord("1", "amazon-ebs.base", "ami-1")
+	new := testRecord("2", "amazon-ebs.base", "ami-2")
+	new.Git.Commit = "def"
+	new.Inputs.Variables = map[string]string{"region": "us-west-2", "size": "large"}
+
+	want := []Change{
+		{Field: "artifact mitchellh.amazonebs", Old: "us-east-1:ami-1", New: "us-east-1:ami-2"},
+		{Field: "git commit", Old: "abc", New: "def"},
+		{Field: "inputs hash", Old: "hash-ami-1", New: "hash-ami-2"},
+		{Field: "output ami", Old: "ami-1", New: "ami-2"},
+		{Field: "variable region", Old: "us-east-1", New: "us-west-2"},
+		{Field: "variable size", Old: "", New: "large"},
+	}
+	if got := Diff(old, new); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected changes %v, expected %v", got, want)
+	}
+	if got := Diff(old, old); len(got) != 0 {
+		t.Fatalf("unexpected changes %v", got)
+	}
+}
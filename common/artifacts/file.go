@@ -0,0 +1,93 @@
+package artifacts
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/packer/common/filelock"
+)
+
+// File is a store appending the records to a JSON lines file, one record per
+// line. Appends are serialized with a lock file, so that builds running in
+// parallel processes can share the file.
+type File struct {
+	Path string
+}
+
+var _ Store = new(File)
+
+// NewFile returns a store appending the records to the file at path.
+func NewFile(path string) *File {
+	return &File{Path: path}
+}
+
+func (f *File) Append(ctx context.Context, r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return err
+	}
+
+	lock := filelock.New(f.Path + ".lock")
+	for {
+		locked, err := lock.TryLock()
+		if err != nil {
+			return err
+		}
+		if locked {
+			break
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer lock.Unlock()
+
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (f *File) Records(_ context.Context) ([]*Record, error) {
+	file, err := os.Open(f.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []*Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		r := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), r); err != nil {
+			// A line cut by a crash while appending it only loses its
+			// record.
+			log.Printf("[WARN] Skipping line %d of %s: %s", line, f.Path, err)
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,146 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	commonhelper "github.com/hashicorp/packer/helper/common"
+)
+
+// EnvToken is the environment variable setting the token the HTTP store
+// authenticates with.
+const EnvToken = "PACKER_ARTIFACTS_TOKEN"
+
+// HTTP is a store using the HTTP API of a remote store:
+//
+//	GET  /records    the records, as a JSON array
+//	POST /records    appends a record
+//
+// Requests send the token as a bearer token. NewHandler serves the API.
+type HTTP struct {
+	// URL is the prefix of the paths of the API.
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+var _ Store = new(HTTP)
+
+func newHTTP(u *url.URL) *HTTP {
+	return &HTTP{
+		URL:    strings.TrimSuffix(u.String(), "/"),
+		Token:  os.Getenv(EnvToken),
+		Client: commonhelper.HttpClient(),
+	}
+}
+
+func (h *HTTP) do(ctx context.Context, method string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, h.URL+"/records", body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s /records: %s: %s", method, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (h *HTTP) Append(ctx context.Context, r *Record) error {
+	return h.do(ctx, http.MethodPost, r, nil)
+}
+
+func (h *HTTP) Records(ctx context.Context) ([]*Record, error) {
+	var records []*Record
+	err := h.do(ctx, http.MethodGet, nil, &records)
+	return records, err
+}
+
+// NewHandler serves the HTTP API of s, for the HTTP store. Requests must send
+// token as a bearer token, unless it is empty.
+func NewHandler(s Store, token string) http.Handler {
+	return &handler{s: s, token: token}
+}
+
+type handler struct {
+	s     Store
+	token string
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" {
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(auth), []byte(h.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	if strings.Trim(r.URL.Path, "/") != "records" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		records, err := h.s.Records(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if records == nil {
+			records = []*Record{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(records)
+	case http.MethodPost:
+		record := &Record{}
+		if err := json.NewDecoder(r.Body).Decode(record); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if record.ID == "" || record.Build == "" {
+			http.Error(w, "the record has no id or no build", http.StatusBadRequest)
+			return
+		}
+		if err := h.s.Append(r.Context(), record); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
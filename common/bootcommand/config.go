@@ -156,6 +156,12 @@ type BootConfig struct {
 	// well, and are covered in the section below on the boot command. If this
 	// is not specified, it is assumed the installer will start itself.
 	BootCommand []string `mapstructure:"boot_command"`
+	// The keyboard layout of the guest, so that the characters of the
+	// `boot_command` are typed with the keys of this layout instead of the
+	// US one: `us`, `de`, `fr` or `gb`. The layouts are those of the Linux
+	// console and of X, the characters typed with dead keys are followed by
+	// a space. Defaults to `us`. The proxmox builder only supports `us`.
+	BootKeymap string `mapstructure:"boot_keymap"`
 }
 
 // The boot command "typed" character for character over a VNC connection to
@@ -188,6 +194,10 @@ func (c *BootConfig) Prepare(ctx *interpolate.Context) (errs []error) {
 		}
 	}
 
+	if err := ValidateKeymap(c.BootKeymap); err != nil {
+		errs = append(errs, err)
+	}
+
 	return
 }
 
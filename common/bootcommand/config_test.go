@@ -28,6 +28,22 @@ func TestConfigPrepare(t *testing.T) {
 	if len(errs) > 0 {
 		t.Fatalf("bad: %#v", errs)
 	}
+
+	// Test a good boot_keymap
+	c = new(BootConfig)
+	c.BootKeymap = "de"
+	errs = c.Prepare(&interpolate.Context{})
+	if len(errs) > 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+
+	// Test a bad boot_keymap
+	c = new(BootConfig)
+	c.BootKeymap = "azerty"
+	errs = c.Prepare(&interpolate.Context{})
+	if len(errs) != 1 {
+		t.Fatalf("bad: %#v", errs)
+	}
 }
 
 func TestVNCConfigPrepare(t *testing.T) {
@@ -0,0 +1,171 @@
+package bootcommand
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// usKeys are the keys typing characters on a US keyboard, row by row, and
+// the position of the 102nd key of ISO keyboards, between the left shift and
+// z, that US keyboards don't have. The drivers type the characters as on a
+// US keyboard, so the keymaps type the characters of the other layouts with
+// the keys of the same position.
+var usKeys = []rune("`1234567890-=qwertyuiop[]\\asdfghjkl;'zxcvbnm,./" + string(oem102))
+
+// oem102 stands for the 102nd key in usKeys. The drivers type it with the
+// "oem102" special, that can't be used in a boot_command.
+const oem102 rune = 0
+
+// keyboardLayout lists the characters typed by the keys of usKeys, alone,
+// with shift and with AltGr. A space means no character. The characters of
+// dead typed without AltGr are typed with dead keys, and are followed by a
+// space.
+type keyboardLayout struct {
+	plain, shift, altGr string
+	dead                string
+}
+
+// The layouts are those of the Linux console and of X, that most installers
+// use.
+var keyboardLayouts = map[string]keyboardLayout{
+	"de": {
+		plain: "^1234567890ß´qwertzuiopü+#asdfghjklöäyxcvbnm,.-<",
+		shift: "°!\"§$%&/()=?`QWERTZUIOPÜ*'ASDFGHJKLÖÄYXCVBNM;:_>",
+		altGr: "  ²³   {[]}\\ @ €        ~                  µ   |",
+		dead:  "^´`",
+	},
+	"fr": {
+		plain: "²&é\"'(-è_çà)=azertyuiop^$*qsdfghjklmùwxcvbn,;:!<",
+		shift: " 1234567890°+AZERTYUIOP¨£µQSDFGHJKLM%WXCVBN?./§>",
+		altGr: "  ~#{[|`\\^@]}  €                                ",
+		dead:  "^¨",
+	},
+	"gb": {
+		plain: "`1234567890-=qwertyuiop[]#asdfghjkl;'zxcvbnm,./\\",
+		shift: "¬!\"£$%^&*()_+QWERTYUIOP{}~ASDFGHJKL:@ZXCVBNM<>?|",
+		altGr: "¦   €                                           ",
+	},
+}
+
+// keyStroke is how a character is typed on a US keyboard.
+type keyStroke struct {
+	key          rune
+	shift, altGr bool
+	dead         bool
+}
+
+// ValidateKeymap returns an error when keymap is not a known keyboard layout.
+func ValidateKeymap(keymap string) error {
+	if keymap == "" || keymap == "us" {
+		return nil
+	}
+	if _, ok := keyboardLayouts[keymap]; ok {
+		return nil
+	}
+	keymaps := []string{"us"}
+	for name := range keyboardLayouts {
+		keymaps = append(keymaps, name)
+	}
+	sort.Strings(keymaps)
+	return fmt.Errorf("unknown boot_keymap %q, must be one of %s", keymap, strings.Join(keymaps, ", "))
+}
+
+// keyStrokes returns how the characters of layout are typed on a US keyboard.
+func keyStrokes(layout keyboardLayout) map[rune]keyStroke {
+	strokes := make(map[rune]keyStroke)
+	add := func(chars string, shift, altGr bool) {
+		if n := utf8.RuneCountInString(chars); n != len(usKeys) {
+			panic(fmt.Sprintf("keyboard layout of %d keys instead of %d: %q", n, len(usKeys), chars))
+		}
+		for i, r := range []rune(chars) {
+			if r == ' ' {
+				continue
+			}
+			stroke := keyStroke{
+				key:   usKeys[i],
+				shift: shift,
+				altGr: altGr,
+				dead:  !altGr && strings.ContainsRune(layout.dead, r),
+			}
+			// The first key typing a character is the simplest one, unless
+			// it is a dead key.
+			if prev, ok := strokes[r]; ok && !(prev.dead && !stroke.dead) {
+				continue
+			}
+			strokes[r] = stroke
+		}
+	}
+	add(layout.plain, false, false)
+	add(layout.shift, true, false)
+	add(layout.altGr, false, true)
+	return strokes
+}
+
+// keymapDriver is a BCDriver typing the characters as on a keyboard of
+// another layout than US.
+type keymapDriver struct {
+	BCDriver
+	strokes map[rune]keyStroke
+}
+
+// NewKeymapDriver returns a driver typing the characters with d as on a
+// keyboard with the layout keymap, as configured with boot_keymap. It
+// returns d with the US layout.
+func NewKeymapDriver(d BCDriver, keymap string) (BCDriver, error) {
+	if err := ValidateKeymap(keymap); err != nil {
+		return nil, err
+	}
+	layout, ok := keyboardLayouts[keymap]
+	if !ok {
+		return d, nil
+	}
+	return &keymapDriver{BCDriver: d, strokes: keyStrokes(layout)}, nil
+}
+
+func (d *keymapDriver) SendKey(key rune, action KeyAction) error {
+	stroke, ok := d.strokes[key]
+	if !ok {
+		return d.BCDriver.SendKey(key, action)
+	}
+	log.Printf("Typing char '%c' with key '%c', shift %v, altgr %v, dead %v",
+		key, stroke.key, stroke.shift, stroke.altGr, stroke.dead)
+
+	var modifiers []string
+	if stroke.shift {
+		modifiers = append(modifiers, "leftshift")
+	}
+	if stroke.altGr {
+		modifiers = append(modifiers, "rightalt")
+	}
+
+	if action&(KeyOn|KeyPress) != 0 {
+		for _, modifier := range modifiers {
+			if err := d.BCDriver.SendSpecial(modifier, KeyOn); err != nil {
+				return err
+			}
+		}
+	}
+	var err error
+	if stroke.key == oem102 {
+		err = d.BCDriver.SendSpecial("oem102", action)
+	} else {
+		err = d.BCDriver.SendKey(stroke.key, action)
+	}
+	if err != nil {
+		return err
+	}
+	if action&(KeyOff|KeyPress) != 0 {
+		for i := len(modifiers) - 1; i >= 0; i-- {
+			if err := d.BCDriver.SendSpecial(modifiers[i], KeyOff); err != nil {
+				return err
+			}
+		}
+	}
+	if stroke.dead && action&KeyPress != 0 {
+		return d.BCDriver.SendKey(' ', KeyPress)
+	}
+	return nil
+}
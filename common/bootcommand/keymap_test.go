@@ -0,0 +1,103 @@
+package bootcommand
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingDriver is a BCDriver recording the keys typed.
+type recordingDriver struct {
+	keys []string
+}
+
+func (d *recordingDriver) SendKey(key rune, action KeyAction) error {
+	d.keys = append(d.keys, fmt.Sprintf("%c %s", key, action))
+	return nil
+}
+
+func (d *recordingDriver) SendSpecial(special string, action KeyAction) error {
+	d.keys = append(d.keys, fmt.Sprintf("<%s> %s", special, action))
+	return nil
+}
+
+func (d *recordingDriver) Flush() error { return nil }
+
+func TestValidateKeymap(t *testing.T) {
+	for _, keymap := range []string{"", "us", "de", "fr", "gb"} {
+		assert.NoError(t, ValidateKeymap(keymap), keymap)
+	}
+	assert.Error(t, ValidateKeymap("qwerty"))
+}
+
+func TestKeyStrokes(t *testing.T) {
+	tc := []struct {
+		keymap string
+		char   rune
+		stroke keyStroke
+	}{
+		{"de", 'z', keyStroke{key: 'y'}},
+		{"de", 'Y', keyStroke{key: 'z', shift: true}},
+		{"de", '-', keyStroke{key: '/'}},
+		{"de", '"', keyStroke{key: '2', shift: true}},
+		{"de", '/', keyStroke{key: '7', shift: true}},
+		{"de", '\\', keyStroke{key: '-', altGr: true}},
+		{"de", '{', keyStroke{key: '7', altGr: true}},
+		{"de", '}', keyStroke{key: '0', altGr: true}},
+		{"de", '@', keyStroke{key: 'q', altGr: true}},
+		{"de", '~', keyStroke{key: ']', altGr: true}},
+		{"de", 'µ', keyStroke{key: 'm', altGr: true}},
+		{"de", '<', keyStroke{key: oem102}},
+		{"de", '|', keyStroke{key: oem102, altGr: true}},
+		{"de", '^', keyStroke{key: '`', dead: true}},
+		{"fr", 'a', keyStroke{key: 'q'}},
+		{"fr", '1', keyStroke{key: '1', shift: true}},
+		{"fr", '~', keyStroke{key: '2', altGr: true}},
+		{"fr", '#', keyStroke{key: '3', altGr: true}},
+		{"fr", '@', keyStroke{key: '0', altGr: true}},
+		{"fr", '}', keyStroke{key: '=', altGr: true}},
+		{"fr", '^', keyStroke{key: '9', altGr: true}},
+		{"fr", '€', keyStroke{key: 'e', altGr: true}},
+		{"fr", '.', keyStroke{key: ',', shift: true}},
+		{"gb", '"', keyStroke{key: '2', shift: true}},
+		{"gb", '@', keyStroke{key: '\'', shift: true}},
+		{"gb", '#', keyStroke{key: '\\'}},
+		{"gb", '\\', keyStroke{key: oem102}},
+		{"gb", '€', keyStroke{key: '4', altGr: true}},
+	}
+	for _, tt := range tc {
+		strokes := keyStrokes(keyboardLayouts[tt.keymap])
+		assert.Equalf(t, tt.stroke, strokes[tt.char], "%s %c", tt.keymap, tt.char)
+	}
+}
+
+func TestKeymapDriver(t *testing.T) {
+	rec := &recordingDriver{}
+	d, err := NewKeymapDriver(rec, "de")
+	assert.NoError(t, err)
+
+	seq, err := GenerateExpressionSequence(`zY@^é<zOn><zOff>`)
+	assert.NoError(t, err)
+	assert.NoError(t, seq.Do(context.Background(), d))
+	assert.Equal(t, []string{
+		"y Press",
+		"<leftshift> On", "z Press", "<leftshift> Off",
+		"<rightalt> On", "q Press", "<rightalt> Off",
+		"` Press", "  Press",
+		"é Press",
+		"y On", "y Off",
+	}, rec.keys)
+}
+
+func TestNewKeymapDriver_us(t *testing.T) {
+	rec := &recordingDriver{}
+	for _, keymap := range []string{"", "us"} {
+		d, err := NewKeymapDriver(rec, keymap)
+		assert.NoError(t, err)
+		assert.Equal(t, rec, d)
+	}
+	_, err := NewKeymapDriver(rec, "qwerty")
+	assert.Error(t, err)
+}
@@ -82,6 +82,8 @@ func NewPCXTDriver(send SendCodeFunc, chunkSize int, interval time.Duration) *pc
 	sMap["leftshift"] = &scancode{[]string{"2a"}, []string{"aa"}}
 	sMap["leftsuper"] = &scancode{[]string{"e0", "5b"}, []string{"e0", "db"}}
 	sMap["menu"] = &scancode{[]string{"e0", "5d"}, []string{"e0", "dd"}}
+	// oem102 is the 102nd key of ISO keyboards, typed by the keymaps.
+	sMap["oem102"] = &scancode{[]string{"56"}, []string{"d6"}}
 	sMap["pagedown"] = &scancode{[]string{"e0", "51"}, []string{"e0", "d1"}}
 	sMap["pageup"] = &scancode{[]string{"e0", "49"}, []string{"e0", "c9"}}
 	sMap["return"] = &scancode{[]string{"1c"}, []string{"9c"}}
@@ -71,6 +71,9 @@ func NewUSBDriver(send SendUsbScanCodes, interval time.Duration) *usbDriver {
 		"leftsuper":  key.CodeLeftGUI,
 		"rightsuper": key.CodeRightGUI,
 		"spacebar":   key.CodeSpacebar,
+		// oem102 is the 102nd key of ISO keyboards, typed by the keymaps:
+		// the non-US \ and | key.
+		"oem102": key.Code(100),
 	}
 
 	scancodeIndex := make(map[string]key.Code)
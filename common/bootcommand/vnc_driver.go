@@ -65,6 +65,9 @@ func NewVNCDriver(c VNCKeyEvent, interval time.Duration) *vncDriver {
 	sMap["leftshift"] = 0xFFE1
 	sMap["leftsuper"] = 0xFFEB
 	sMap["menu"] = 0xFF67
+	// oem102 is the 102nd key of ISO keyboards, typed by the keymaps: the
+	// "less" keysym of the 102nd key of the US keymap of QEMU.
+	sMap["oem102"] = 0x3C
 	sMap["pagedown"] = 0xFF56
 	sMap["pageup"] = 0xFF55
 	sMap["return"] = 0xFF0D
@@ -0,0 +1,278 @@
+// Package cleanup keeps track of the remote resources builders create, like
+// instances, key pairs or security groups. Every build process writes the
+// resources it currently owns to a record file on disk, so that the
+// resources left behind by a crashed or killed build can be found and
+// removed later on with `packer cleanup`.
+package cleanup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/packer/packer"
+)
+
+// Resource is a remote resource created by a builder.
+type Resource struct {
+	// Provider tells which sweeper can remove the resource, for example
+	// "amazon".
+	Provider string `json:"provider"`
+	// Type is the kind of resource for the provider, for example "instance".
+	Type string `json:"type"`
+	// ID identifies the resource for the provider.
+	ID string `json:"id"`
+	// Region is where the resource lives, if the provider has regions.
+	Region string `json:"region,omitempty"`
+	// Account is the project or subscription the resource belongs to, if
+	// the credentials of the environment don't tell it.
+	Account string `json:"account,omitempty"`
+	// Created is when the resource was tracked.
+	Created time.Time `json:"created"`
+}
+
+func (r Resource) String() string {
+	s := fmt.Sprintf("%s %s %s", r.Provider, r.Type, r.ID)
+	var where []string
+	for _, w := range []string{r.Account, r.Region} {
+		if w != "" {
+			where = append(where, w)
+		}
+	}
+	if len(where) > 0 {
+		s += fmt.Sprintf(" (%s)", strings.Join(where, ", "))
+	}
+	return s
+}
+
+// Record is the set of resources owned by one build process.
+type Record struct {
+	// Path is the file the record is stored in.
+	Path string `json:"-"`
+
+	PID       int        `json:"pid"`
+	Started   time.Time  `json:"started"`
+	Resources []Resource `json:"resources"`
+}
+
+// Dir returns the directory records are stored in.
+func Dir() (string, error) {
+	if dir := os.Getenv("PACKER_CLEANUP_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := packer.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cleanup"), nil
+}
+
+var (
+	mu      sync.Mutex
+	current *Record
+)
+
+// Track records that r was created by this process. Failing to track a
+// resource only gets logged, it should never fail a build.
+func Track(r Resource) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if current == nil {
+		dir, err := Dir()
+		if err != nil {
+			log.Printf("[WARN] cleanup: could not track %s: %s", r, err)
+			return
+		}
+		current = &Record{
+			Path:    filepath.Join(dir, fmt.Sprintf("%d-%d.json", os.Getpid(), time.Now().UnixNano())),
+			PID:     os.Getpid(),
+			Started: time.Now().UTC(),
+		}
+	}
+	if r.Created.IsZero() {
+		r.Created = time.Now().UTC()
+	}
+	current.Resources = append(current.Resources, r)
+	if err := current.Save(); err != nil {
+		log.Printf("[WARN] cleanup: could not track %s: %s", r, err)
+	}
+}
+
+// Untrack records that the resource was removed. The record file of this
+// process is deleted once it owns no more resources.
+func Untrack(provider, typ, id string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if current == nil {
+		return
+	}
+	current.remove(provider, typ, id)
+	if len(current.Resources) > 0 {
+		if err := current.Save(); err != nil {
+			log.Printf("[WARN] cleanup: could not untrack %s %s %s: %s", provider, typ, id, err)
+		}
+		return
+	}
+	if err := current.Delete(); err != nil {
+		log.Printf("[WARN] cleanup: %s", err)
+	}
+	current = nil
+}
+
+// Adopt tracks r, created by a build process that died, as owned by this
+// process, like when resuming the build: r is removed from the records of the
+// dead processes, so that `packer cleanup` leaves it to this process.
+func Adopt(r Resource) {
+	Track(r)
+
+	dir, err := Dir()
+	if err != nil {
+		log.Printf("[WARN] cleanup: could not adopt %s: %s", r, err)
+		return
+	}
+	orphans, err := Orphans(dir)
+	if err != nil {
+		log.Printf("[WARN] cleanup: could not adopt %s: %s", r, err)
+		return
+	}
+	for _, rec := range orphans {
+		if !rec.remove(r.Provider, r.Type, r.ID) {
+			continue
+		}
+		if len(rec.Resources) > 0 {
+			err = rec.Save()
+		} else {
+			err = rec.Delete()
+		}
+		if err != nil {
+			log.Printf("[WARN] cleanup: could not adopt %s: %s", r, err)
+		}
+	}
+}
+
+func (rec *Record) remove(provider, typ, id string) bool {
+	for i, r := range rec.Resources {
+		if r.Provider == provider && r.Type == typ && r.ID == id {
+			rec.Resources = append(rec.Resources[:i], rec.Resources[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Save writes the record to its file.
+func (rec *Record) Save() error {
+	if err := os.MkdirAll(filepath.Dir(rec.Path), 0700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename so that a killed process never leaves a partial
+	// record behind.
+	tmp := rec.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, rec.Path)
+}
+
+// Delete removes the record file.
+func (rec *Record) Delete() error {
+	err := os.Remove(rec.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Orphans returns the records in dir of the processes that are no longer
+// running, oldest first.
+func Orphans(dir string) ([]*Record, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*Record
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		rec := &Record{Path: file}
+		if err := json.Unmarshal(b, rec); err != nil {
+			log.Printf("[WARN] cleanup: skipping unreadable record %s: %s", file, err)
+			continue
+		}
+		if rec.PID == os.Getpid() || ProcessAlive(rec.PID) {
+			continue
+		}
+		records = append(records, rec)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Started.Before(records[j].Started)
+	})
+	return records, nil
+}
+
+// SweepFunc removes a resource of a provider. It should not fail when the
+// resource is already gone.
+type SweepFunc func(ctx context.Context, r Resource) error
+
+// Sweep removes the resources of rec using the sweeper of their provider,
+// in the reverse order of their creation since resources usually depend on
+// the ones created before them. Resources that could not be removed are kept
+// in the record; the record file is deleted once it is empty.
+func Sweep(ctx context.Context, rec *Record, sweepers map[string]SweepFunc, ui packer.Ui) error {
+	var errs error
+	var left []Resource
+	for i := len(rec.Resources) - 1; i >= 0; i-- {
+		r := rec.Resources[i]
+		sweep, ok := sweepers[r.Provider]
+		if !ok {
+			errs = multierror.Append(errs, fmt.Errorf("%s: no sweeper for provider %q", r, r.Provider))
+			left = append([]Resource{r}, left...)
+			continue
+		}
+		ui.Say(fmt.Sprintf("Removing %s...", r))
+		if err := sweep(ctx, r); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("%s: %s", r, err))
+			left = append([]Resource{r}, left...)
+		}
+	}
+
+	rec.Resources = left
+	if len(left) == 0 {
+		if err := rec.Delete(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+		return errs
+	}
+	if err := rec.Save(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	return errs
+}
+
+// Summary describes the resources of the record in a human readable way.
+func (rec *Record) Summary() string {
+	lines := []string{fmt.Sprintf("Build process %d started at %s:", rec.PID, rec.Started.Format(time.RFC3339))}
+	for _, r := range rec.Resources {
+		lines = append(lines, "  "+r.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,173 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func testDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "packer-cleanup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("PACKER_CLEANUP_DIR", dir)
+	return dir
+}
+
+func TestTrackUntrack(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv("PACKER_CLEANUP_DIR")
+
+	Track(Resource{Provider: "mock", Type: "instance", ID: "i-1"})
+	Track(Resource{Provider: "mock", Type: "keypair", ID: "k-1"})
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected one record, got %v", files)
+	}
+
+	// Our own record is never an orphan.
+	orphans, err := Orphans(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 0 {
+		t.Fatalf("unexpected orphans: %#v", orphans)
+	}
+
+	Untrack("mock", "instance", "i-1")
+	if len(current.Resources) != 1 || current.Resources[0].ID != "k-1" {
+		t.Fatalf("bad: %#v", current.Resources)
+	}
+
+	Untrack("mock", "keypair", "k-1")
+	files, _ = filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 0 {
+		t.Fatalf("record should be deleted, got %v", files)
+	}
+}
+
+func TestOrphansAndSweep(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv("PACKER_CLEANUP_DIR")
+
+	rec := &Record{
+		Path:    filepath.Join(dir, "dead.json"),
+		PID:     math.MaxInt32,
+		Started: time.Now(),
+		Resources: []Resource{
+			{Provider: "mock", Type: "keypair", ID: "k-1"},
+			{Provider: "mock", Type: "instance", ID: "i-1"},
+			{Provider: "unknown", Type: "disk", ID: "d-1"},
+		},
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := Orphans(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 1 || len(orphans[0].Resources) != 3 {
+		t.Fatalf("bad: %#v", orphans)
+	}
+
+	var swept []string
+	sweepers := map[string]SweepFunc{
+		"mock": func(_ context.Context, r Resource) error {
+			if r.Type == "keypair" {
+				return errors.New("nope")
+			}
+			swept = append(swept, r.ID)
+			return nil
+		},
+	}
+	if err := Sweep(context.Background(), orphans[0], sweepers, new(packer.NoopUi)); err == nil {
+		t.Fatal("should error")
+	}
+	if len(swept) != 1 || swept[0] != "i-1" {
+		t.Fatalf("bad: %v", swept)
+	}
+
+	// Resources that could not be removed are kept.
+	orphans, err = Orphans(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 1 || len(orphans[0].Resources) != 2 {
+		t.Fatalf("bad: %#v", orphans)
+	}
+
+	sweepers["mock"] = func(context.Context, Resource) error { return nil }
+	sweepers["unknown"] = func(context.Context, Resource) error { return nil }
+	if err := Sweep(context.Background(), orphans[0], sweepers, new(packer.NoopUi)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(rec.Path); !os.IsNotExist(err) {
+		t.Fatalf("record should be deleted: %v", err)
+	}
+}
+
+func TestAdopt(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv("PACKER_CLEANUP_DIR")
+
+	rec := &Record{
+		Path:    filepath.Join(dir, "dead.json"),
+		PID:     math.MaxInt32,
+		Started: time.Now(),
+		Resources: []Resource{
+			{Provider: "mock", Type: "keypair", ID: "k-1"},
+			{Provider: "mock", Type: "instance", ID: "i-1"},
+		},
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	Adopt(Resource{Provider: "mock", Type: "instance", ID: "i-1"})
+	defer Untrack("mock", "instance", "i-1")
+
+	orphans, err := Orphans(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 1 || len(orphans[0].Resources) != 1 || orphans[0].Resources[0].ID != "k-1" {
+		t.Fatalf("the instance should be adopted: %#v", orphans)
+	}
+	if len(current.Resources) != 1 || current.Resources[0].ID != "i-1" {
+		t.Fatalf("bad: %#v", current.Resources)
+	}
+
+	Adopt(Resource{Provider: "mock", Type: "keypair", ID: "k-1"})
+	defer Untrack("mock", "keypair", "k-1")
+	if _, err := os.Stat(rec.Path); !os.IsNotExist(err) {
+		t.Fatalf("the empty record should be deleted: %s", err)
+	}
+}
+
+func TestResource_String(t *testing.T) {
+	cases := map[string]Resource{
+		"mock instance i-1":                   {Provider: "mock", Type: "instance", ID: "i-1"},
+		"mock instance i-1 (us-east-1)":       {Provider: "mock", Type: "instance", ID: "i-1", Region: "us-east-1"},
+		"mock disk d-1 (project, us-east1-b)": {Provider: "mock", Type: "disk", ID: "d-1", Region: "us-east1-b", Account: "project"},
+		"mock firewall-rule f-1 (project)":    {Provider: "mock", Type: "firewall-rule", ID: "f-1", Account: "project"},
+	}
+	for expected, r := range cases {
+		if got := r.String(); got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package cleanup
+
+import "syscall"
+
+// ProcessAlive tells whether a process with the given pid is running.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	// EPERM means the process exists but belongs to somebody else.
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,16 @@
+//go:build windows
+// +build windows
+
+package cleanup
+
+import "os"
+
+// ProcessAlive tells whether a process with the given pid is running.
+func ProcessAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -1,6 +1,8 @@
 package common
 
 import (
+	"log"
+	"os"
 	"time"
 )
 
@@ -12,3 +14,25 @@ const PackerKeyEnv = "PACKER_KEY_INTERVAL"
 // PackerKeyDefault 100ms is appropriate for shared build infrastructure while a
 // shorter delay (e.g. 10ms) can be used on a workstation. See PackerKeyEnv.
 const PackerKeyDefault = 100 * time.Millisecond
+
+// PackerCleanupTimeoutEnv is used to specify how long the cleanups of a
+// cancelled build can take, like "30m", so that an interrupted build always
+// exits. 0 lets the cleanups take as long as they need.
+const PackerCleanupTimeoutEnv = "PACKER_CLEANUP_TIMEOUT"
+
+// PackerCleanupTimeoutDefault 15m leaves enough time to delete the instances
+// and disks of most clouds. See PackerCleanupTimeoutEnv.
+const PackerCleanupTimeoutDefault = 15 * time.Minute
+
+// CleanupTimeout returns how long the cleanups of a cancelled build can take.
+// See PackerCleanupTimeoutEnv.
+func CleanupTimeout() time.Duration {
+	if v := os.Getenv(PackerCleanupTimeoutEnv); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err == nil {
+			return timeout
+		}
+		log.Printf("Ignoring invalid %s %q: %s", PackerCleanupTimeoutEnv, v, err)
+	}
+	return PackerCleanupTimeoutDefault
+}
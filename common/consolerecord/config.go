@@ -0,0 +1,50 @@
+//go:generate struct-markdown
+
+package consolerecord
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// RecordConfig controls the periodic capture of the VM console while a build
+// runs. The captures are invaluable for debugging unattended installs that
+// stall or fail on a headless build agent.
+type RecordConfig struct {
+	// Capture the console of the VM as a series of PNG images while it boots
+	// and is provisioned. Capturing starts as soon as the VM is started and
+	// stops when the build ends. When a build fails, one last capture is
+	// taken before the VM is torn down, so the final image shows the state
+	// of the console at the time of the failure. Defaults to false.
+	RecordConsole bool `mapstructure:"record_console" required:"false"`
+	// The time to wait between two captures of the console. Defaults to
+	// "5s".
+	RecordConsoleInterval time.Duration `mapstructure:"record_console_interval" required:"false"`
+	// The directory the console captures are written to. It is kept after
+	// the build, whether it succeeded or not, and is not part of the
+	// artifact. Defaults to `console-BUILDNAME`, where "BUILDNAME" is the
+	// name of the build.
+	RecordConsoleDirectory string `mapstructure:"record_console_directory" required:"false"`
+}
+
+func (c *RecordConfig) Prepare(ctx *interpolate.Context, pc *common.PackerConfig) []error {
+	var errs []error
+
+	if c.RecordConsoleInterval == 0 {
+		c.RecordConsoleInterval = 5 * time.Second
+	}
+
+	if c.RecordConsoleInterval < 0 {
+		errs = append(errs, errors.New("record_console_interval must be positive"))
+	}
+
+	if c.RecordConsoleDirectory == "" {
+		c.RecordConsoleDirectory = fmt.Sprintf("console-%s", pc.PackerBuildName)
+	}
+
+	return errs
+}
@@ -0,0 +1,45 @@
+package consolerecord
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+func testPackerConfig() *common.PackerConfig {
+	return &common.PackerConfig{PackerBuildName: "foo"}
+}
+
+func TestRecordConfigPrepare_Defaults(t *testing.T) {
+	c := new(RecordConfig)
+	errs := c.Prepare(interpolate.NewContext(), testPackerConfig())
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	if c.RecordConsoleInterval != 5*time.Second {
+		t.Fatalf("bad: %s", c.RecordConsoleInterval)
+	}
+	if c.RecordConsoleDirectory != "console-foo" {
+		t.Fatalf("bad: %s", c.RecordConsoleDirectory)
+	}
+}
+
+func TestRecordConfigPrepare_RecordConsoleInterval(t *testing.T) {
+	c := &RecordConfig{RecordConsoleInterval: time.Second}
+	errs := c.Prepare(interpolate.NewContext(), testPackerConfig())
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.RecordConsoleInterval != time.Second {
+		t.Fatalf("bad: %s", c.RecordConsoleInterval)
+	}
+
+	c = &RecordConfig{RecordConsoleInterval: -time.Second}
+	errs = c.Prepare(interpolate.NewContext(), testPackerConfig())
+	if len(errs) != 1 {
+		t.Fatalf("should have one error: %#v", errs)
+	}
+}
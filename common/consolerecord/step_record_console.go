@@ -0,0 +1,118 @@
+package consolerecord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// A Screenshotter captures the console of a running VM.
+type Screenshotter interface {
+	// Screenshot writes a PNG image of the console to path.
+	Screenshot(path string) error
+	// Close releases any connection held to the console.
+	Close() error
+}
+
+// StepRecordConsole captures the console of the VM every
+// RecordConsoleInterval until the step is cleaned up.
+//
+// Uses:
+//   ui packer.Ui
+//
+// Produces:
+//   <nothing>
+type StepRecordConsole struct {
+	Config *RecordConfig
+	// NewScreenshotter returns a Screenshotter for the console of the
+	// running VM.
+	NewScreenshotter func(state multistep.StateBag) (Screenshotter, error)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	count  int
+}
+
+func (s *StepRecordConsole) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if !s.Config.RecordConsole {
+		return multistep.ActionContinue
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	dir := s.Config.RecordConsoleDirectory
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		err := fmt.Errorf("Error creating console recording directory: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	screenshotter, err := s.NewScreenshotter(state)
+	if err != nil {
+		err := fmt.Errorf("Error connecting to the console: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Recording the console to %s every %s...", dir, s.Config.RecordConsoleInterval))
+
+	// The recording outlives this step, so it is not bound to ctx; it is
+	// stopped by Cleanup.
+	recordCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.record(recordCtx, screenshotter)
+
+	return multistep.ActionContinue
+}
+
+func (s *StepRecordConsole) record(ctx context.Context, screenshotter Screenshotter) {
+	defer close(s.done)
+	defer screenshotter.Close()
+
+	ticker := time.NewTicker(s.Config.RecordConsoleInterval)
+	defer ticker.Stop()
+
+	for {
+		s.capture(screenshotter)
+
+		select {
+		case <-ctx.Done():
+			// Capture the console one last time so the recording ends
+			// with the state the VM was left in.
+			s.capture(screenshotter)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *StepRecordConsole) capture(screenshotter Screenshotter) {
+	path := filepath.Join(s.Config.RecordConsoleDirectory, fmt.Sprintf("console-%05d.png", s.count))
+	if err := screenshotter.Screenshot(path); err != nil {
+		log.Printf("[WARN] Error capturing the console: %s", err)
+		return
+	}
+	s.count++
+}
+
+func (s *StepRecordConsole) Cleanup(state multistep.StateBag) {
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	<-s.done
+	s.cancel = nil
+
+	ui := state.Get("ui").(packer.Ui)
+	ui.Say(fmt.Sprintf("Recorded %d console captures to %s", s.count, s.Config.RecordConsoleDirectory))
+}
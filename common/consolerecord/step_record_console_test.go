@@ -0,0 +1,132 @@
+package consolerecord
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+type fakeScreenshotter struct {
+	Fail   bool
+	Closed bool
+}
+
+func (s *fakeScreenshotter) Screenshot(path string) error {
+	if s.Fail {
+		return fmt.Errorf("console unavailable")
+	}
+	return ioutil.WriteFile(path, []byte("png"), 0644)
+}
+
+func (s *fakeScreenshotter) Close() error {
+	s.Closed = true
+	return nil
+}
+
+func testState(t *testing.T) multistep.StateBag {
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	})
+	return state
+}
+
+func TestStepRecordConsole_impl(t *testing.T) {
+	var _ multistep.Step = new(StepRecordConsole)
+}
+
+func TestStepRecordConsole_Disabled(t *testing.T) {
+	state := testState(t)
+	step := &StepRecordConsole{
+		Config: &RecordConfig{},
+		NewScreenshotter: func(multistep.StateBag) (Screenshotter, error) {
+			t.Fatal("should not connect to the console")
+			return nil, nil
+		},
+	}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	step.Cleanup(state)
+}
+
+func TestStepRecordConsole(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	state := testState(t)
+	screenshotter := new(fakeScreenshotter)
+	step := &StepRecordConsole{
+		Config: &RecordConfig{
+			RecordConsole:          true,
+			RecordConsoleInterval:  10 * time.Millisecond,
+			RecordConsoleDirectory: filepath.Join(td, "console"),
+		},
+		NewScreenshotter: func(multistep.StateBag) (Screenshotter, error) {
+			return screenshotter, nil
+		},
+	}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	time.Sleep(50 * time.Millisecond)
+	step.Cleanup(state)
+
+	if !screenshotter.Closed {
+		t.Fatal("screenshotter should be closed")
+	}
+
+	files, err := filepath.Glob(filepath.Join(td, "console", "console-*.png"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	// At least the first and the final capture.
+	if len(files) < 2 || len(files) != step.count {
+		t.Fatalf("bad: %d captures, %d counted", len(files), step.count)
+	}
+}
+
+func TestStepRecordConsole_ScreenshotError(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	state := testState(t)
+	step := &StepRecordConsole{
+		Config: &RecordConfig{
+			RecordConsole:          true,
+			RecordConsoleInterval:  10 * time.Millisecond,
+			RecordConsoleDirectory: td,
+		},
+		NewScreenshotter: func(multistep.StateBag) (Screenshotter, error) {
+			return &fakeScreenshotter{Fail: true}, nil
+		},
+	}
+
+	// A console that can't be captured must not fail the build.
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	time.Sleep(20 * time.Millisecond)
+	step.Cleanup(state)
+
+	if step.count != 0 {
+		t.Fatalf("bad: %d", step.count)
+	}
+}
@@ -0,0 +1,161 @@
+package consolerecord
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net"
+	"os"
+	"time"
+
+	"github.com/mitchellh/go-vnc"
+)
+
+// VNCScreenshotter captures the console of a VM through its VNC server. The
+// connection is shared, so it does not disconnect other clients such as the
+// one typing the boot command; if another client drops it, it is re-opened
+// on the next capture.
+type VNCScreenshotter struct {
+	Address  string
+	Password string
+	// Timeout bounds the time spent connecting and waiting for the
+	// framebuffer. Defaults to 30 seconds.
+	Timeout time.Duration
+
+	conn *vnc.ClientConn
+	msgs chan vnc.ServerMessage
+	img  *image.RGBA
+}
+
+func (s *VNCScreenshotter) Screenshot(path string) error {
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.update(); err != nil {
+		s.Close()
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, s.img)
+}
+
+func (s *VNCScreenshotter) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *VNCScreenshotter) timeout() time.Duration {
+	if s.Timeout == 0 {
+		return 30 * time.Second
+	}
+	return s.Timeout
+}
+
+func (s *VNCScreenshotter) connect() error {
+	nc, err := net.DialTimeout("tcp", s.Address, s.timeout())
+	if err != nil {
+		return fmt.Errorf("Error connecting to VNC: %s", err)
+	}
+
+	var auth []vnc.ClientAuth
+	if s.Password != "" {
+		auth = []vnc.ClientAuth{&vnc.PasswordAuth{Password: s.Password}}
+	} else {
+		auth = []vnc.ClientAuth{new(vnc.ClientAuthNone)}
+	}
+
+	msgs := make(chan vnc.ServerMessage, 16)
+	c, err := vnc.Client(nc, &vnc.ClientConfig{
+		Auth:            auth,
+		Exclusive:       false,
+		ServerMessageCh: msgs,
+	})
+	if err != nil {
+		return fmt.Errorf("Error handshaking with VNC: %s", err)
+	}
+
+	s.conn = c
+	s.msgs = msgs
+	s.img = image.NewRGBA(image.Rect(0, 0, int(c.FrameBufferWidth), int(c.FrameBufferHeight)))
+	return nil
+}
+
+// update requests the whole framebuffer and draws it into s.img.
+func (s *VNCScreenshotter) update() error {
+	bounds := s.img.Bounds()
+	err := s.conn.FramebufferUpdateRequest(false, 0, 0, uint16(bounds.Dx()), uint16(bounds.Dy()))
+	if err != nil {
+		return fmt.Errorf("Error requesting the VNC framebuffer: %s", err)
+	}
+
+	timeout := time.After(s.timeout())
+	for {
+		select {
+		case msg := <-s.msgs:
+			update, ok := msg.(*vnc.FramebufferUpdateMessage)
+			if !ok {
+				continue
+			}
+			for _, rect := range update.Rectangles {
+				drawRectangle(s.img, rect, s.conn.PixelFormat)
+			}
+			return nil
+		case <-timeout:
+			return fmt.Errorf("Timeout waiting for the VNC framebuffer")
+		}
+	}
+}
+
+// drawRectangle draws a raw encoded rectangle of a framebuffer update into
+// img.
+func drawRectangle(img *image.RGBA, rect vnc.Rectangle, pf vnc.PixelFormat) {
+	raw, ok := rect.Enc.(*vnc.RawEncoding)
+	if !ok {
+		return
+	}
+
+	width := int(rect.Width)
+	for y := 0; y < int(rect.Height); y++ {
+		for x := 0; x < width; x++ {
+			c := raw.Colors[y*width+x]
+			img.SetRGBA(int(rect.X)+x, int(rect.Y)+y, toRGBA(c, pf))
+		}
+	}
+}
+
+// toRGBA converts a VNC color to RGBA. True colors are scaled by the maximum
+// value of each channel, while color map entries use the full 16 bits.
+func toRGBA(c vnc.Color, pf vnc.PixelFormat) color.RGBA {
+	if !pf.TrueColor {
+		return color.RGBA{R: uint8(c.R >> 8), G: uint8(c.G >> 8), B: uint8(c.B >> 8), A: 0xff}
+	}
+
+	return color.RGBA{
+		R: scale(c.R, pf.RedMax),
+		G: scale(c.G, pf.GreenMax),
+		B: scale(c.B, pf.BlueMax),
+		A: 0xff,
+	}
+}
+
+func scale(v, max uint16) uint8 {
+	if max == 0 {
+		return 0
+	}
+	return uint8(uint32(v) * 0xff / uint32(max))
+}
@@ -0,0 +1,50 @@
+package consolerecord
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/mitchellh/go-vnc"
+)
+
+func TestDrawRectangle(t *testing.T) {
+	pf := vnc.PixelFormat{
+		BPP:       16,
+		TrueColor: true,
+		RedMax:    31,
+		GreenMax:  63,
+		BlueMax:   31,
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	rect := vnc.Rectangle{
+		X:      1,
+		Y:      2,
+		Width:  2,
+		Height: 1,
+		Enc: &vnc.RawEncoding{Colors: []vnc.Color{
+			{R: 31, G: 0, B: 0},
+			{R: 0, G: 63, B: 31},
+		}},
+	}
+	drawRectangle(img, rect, pf)
+
+	expected := map[image.Point]color.RGBA{
+		{1, 2}: {R: 0xff, G: 0, B: 0, A: 0xff},
+		{2, 2}: {R: 0, G: 0xff, B: 0xff, A: 0xff},
+		{0, 0}: {},
+	}
+	for p, c := range expected {
+		if got := img.RGBAAt(p.X, p.Y); got != c {
+			t.Fatalf("bad color at %s: %#v", p, got)
+		}
+	}
+}
+
+func TestToRGBA_ColorMap(t *testing.T) {
+	c := toRGBA(vnc.Color{R: 0xffff, G: 0x8000, B: 0}, vnc.PixelFormat{})
+	if c != (color.RGBA{R: 0xff, G: 0x80, B: 0, A: 0xff}) {
+		t.Fatalf("bad: %#v", c)
+	}
+}
@@ -0,0 +1,238 @@
+// Package cost estimates what the cloud resources a build uses cost: its
+// instances for the time they run, their disks for the time they exist, and
+// the data copied out of the region, like images copied to other regions.
+// Builders record the usage of their resources on the Meter of the build,
+// and StepMeter prices it once the build cleaned up.
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvPrices is the environment variable naming a JSON file of prices,
+// overriding the default prices of the builders.
+const EnvPrices = "PACKER_PRICES"
+
+// Kinds of usage, which tell how a resource is priced.
+const (
+	// Instance is priced per hour.
+	Instance = "instance"
+	// Storage is priced per GB and per month.
+	Storage = "storage"
+	// Egress is priced per GB.
+	Egress = "egress"
+)
+
+// hoursPerMonth is the number of hours of a month for storage prices.
+const hoursPerMonth = 730
+
+// Prices are the prices of resources, keyed by "kind/type", like
+// "instance/t3.micro" or "storage/gp3". The "kind/*" keys price the types
+// without a price of their own.
+type Prices map[string]float64
+
+// PriceFile is the format of the file of EnvPrices.
+type PriceFile struct {
+	// Currency is the currency of the prices, USD by default.
+	Currency string `json:"currency"`
+	Prices   Prices `json:"prices"`
+}
+
+func (p Prices) price(kind, typ string) (float64, bool) {
+	if price, ok := p[kind+"/"+typ]; ok {
+		return price, true
+	}
+	price, ok := p[kind+"/*"]
+	return price, ok
+}
+
+// Usage is the use of one resource by the build.
+type Usage struct {
+	Kind string `json:"kind"`
+	// Resource identifies the resource, like the id of an instance.
+	Resource string `json:"resource"`
+	// Type is the type of the resource, like an instance type, a disk type
+	// or the destination of a copy.
+	Type string `json:"type"`
+	// Size is the size in GB of storage and egress.
+	Size  float64   `json:"size,omitempty"`
+	Start time.Time `json:"start,omitempty"`
+	Stop  time.Time `json:"stop,omitempty"`
+	// Cost is the estimated cost of the usage, and Priced tells whether the
+	// type of the resource has a price at all.
+	Cost   float64 `json:"cost"`
+	Priced bool    `json:"priced"`
+}
+
+// Duration returns how long the resource was used.
+func (u *Usage) Duration() time.Duration {
+	return u.Stop.Sub(u.Start)
+}
+
+func (u *Usage) String() string {
+	switch u.Kind {
+	case Instance:
+		return fmt.Sprintf("instance %s for %s", u.Type, u.Duration().Round(time.Second))
+	case Storage:
+		return fmt.Sprintf("%s storage of %g GB for %s", u.Type, u.Size, u.Duration().Round(time.Second))
+	default:
+		return fmt.Sprintf("%s of %g GB to %s", u.Kind, u.Size, u.Type)
+	}
+}
+
+// Meter records the usage of the resources of a build. A nil Meter records
+// nothing, so that steps can record usage whether or not their builder
+// estimates costs.
+type Meter struct {
+	Currency string
+	Prices   Prices
+
+	l     sync.Mutex
+	usage []*Usage
+}
+
+// NewMeter returns a Meter pricing usage with prices, overridden by the
+// prices of the file of EnvPrices, if set.
+func NewMeter(prices Prices) (*Meter, error) {
+	m := &Meter{Currency: "USD", Prices: Prices{}}
+	for k, v := range prices {
+		m.Prices[k] = v
+	}
+
+	path := os.Getenv(EnvPrices)
+	if path == "" {
+		return m, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %s", EnvPrices, err)
+	}
+	var file PriceFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("reading %s: %s", path, err)
+	}
+	if file.Currency != "" {
+		m.Currency = file.Currency
+	}
+	for k, v := range file.Prices {
+		m.Prices[k] = v
+	}
+	return m, nil
+}
+
+// Start records that the resource of kind started being used, with size in
+// GB for storage.
+func (m *Meter) Start(kind, resource, typ string, size float64) {
+	if m == nil {
+		return
+	}
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.usage = append(m.usage, &Usage{
+		Kind:     kind,
+		Resource: resource,
+		Type:     typ,
+		Size:     size,
+		Start:    time.Now().UTC(),
+	})
+}
+
+// Stop records that the resource of kind is no longer used.
+func (m *Meter) Stop(kind, resource string) {
+	if m == nil {
+		return
+	}
+	m.l.Lock()
+	defer m.l.Unlock()
+	for _, u := range m.usage {
+		if u.Kind == kind && u.Resource == resource && u.Stop.IsZero() {
+			u.Stop = time.Now().UTC()
+		}
+	}
+}
+
+// Add records a one-off usage, like size GB copied to typ.
+func (m *Meter) Add(kind, resource, typ string, size float64) {
+	if m == nil {
+		return
+	}
+	m.l.Lock()
+	defer m.l.Unlock()
+	now := time.Now().UTC()
+	m.usage = append(m.usage, &Usage{
+		Kind:     kind,
+		Resource: resource,
+		Type:     typ,
+		Size:     size,
+		Start:    now,
+		Stop:     now,
+	})
+}
+
+// Report is the estimated cost of a build.
+type Report struct {
+	Currency string  `json:"currency"`
+	Total    float64 `json:"total"`
+	Usage    []Usage `json:"usage"`
+}
+
+// Report prices the usage recorded so far. The resources still in use are
+// priced until now.
+func (m *Meter) Report() *Report {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	r := &Report{Currency: m.Currency, Usage: []Usage{}}
+	now := time.Now().UTC()
+	for _, u := range m.usage {
+		usage := *u
+		if usage.Stop.IsZero() {
+			usage.Stop = now
+		}
+		price, ok := m.Prices.price(usage.Kind, usage.Type)
+		usage.Priced = ok
+		switch usage.Kind {
+		case Instance:
+			usage.Cost = price * usage.Duration().Hours()
+		case Storage:
+			usage.Cost = price * usage.Size * usage.Duration().Hours() / hoursPerMonth
+		default:
+			usage.Cost = price * usage.Size
+		}
+		r.Total += usage.Cost
+		r.Usage = append(r.Usage, usage)
+	}
+	sort.SliceStable(r.Usage, func(i, j int) bool { return r.Usage[i].Start.Before(r.Usage[j].Start) })
+	return r
+}
+
+// Unpriced returns the usage whose type has no price.
+func (r *Report) Unpriced() []string {
+	var unpriced []string
+	for _, u := range r.Usage {
+		if !u.Priced {
+			unpriced = append(unpriced, u.Kind+"/"+u.Type)
+		}
+	}
+	return unpriced
+}
+
+// Amount returns the estimated total, like "0.0421 USD".
+func (r *Report) Amount() string {
+	return fmt.Sprintf("%.4f %s", r.Total, r.Currency)
+}
+
+func (r *Report) String() string {
+	s := r.Amount()
+	if unpriced := r.Unpriced(); len(unpriced) > 0 {
+		s += fmt.Sprintf(" (without the unpriced %s)", strings.Join(unpriced, ", "))
+	}
+	return s
+}
@@ -0,0 +1,131 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+var testPrices = Prices{
+	"instance/t3.micro":   0.01,
+	"storage/*":           0.073,
+	"egress/inter-region": 0.02,
+}
+
+func TestMeter(t *testing.T) {
+	m, err := NewMeter(testPrices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Start(Instance, "i-1", "t3.micro", 0)
+	m.Start(Storage, "vol-1", "gp3", 100)
+	m.Start(Instance, "i-2", "m9.huge", 0)
+	m.Add(Egress, "ami-1", "inter-region", 8)
+	m.Stop(Instance, "i-1")
+	m.Stop(Storage, "vol-1")
+
+	// Ran for two hours.
+	now := time.Now().UTC()
+	for _, u := range m.usage {
+		if u.Kind != Egress {
+			u.Start = now.Add(-2 * time.Hour)
+			if !u.Stop.IsZero() {
+				u.Stop = now
+			}
+		}
+	}
+
+	r := m.Report()
+	expected := []float64{0.02, 2 * 0.073 * 100 / hoursPerMonth, 0, 0.16}
+	if len(r.Usage) != len(expected) {
+		t.Fatalf("unexpected usage %#v", r.Usage)
+	}
+	total := 0.0
+	for i, cost := range expected {
+		if math.Abs(r.Usage[i].Cost-cost) > 1e-9 {
+			t.Errorf("unexpected cost of %s: %f, expected %f", &r.Usage[i], r.Usage[i].Cost, cost)
+		}
+		total += cost
+	}
+	if math.Abs(r.Total-total) > 1e-9 {
+		t.Errorf("unexpected total %f, expected %f", r.Total, total)
+	}
+	if r.Usage[2].Stop.IsZero() {
+		t.Error("the running instance should be priced until now")
+	}
+	if r.String() != "0.2000 USD (without the unpriced instance/m9.huge)" {
+		t.Errorf("unexpected report %q", r)
+	}
+}
+
+func TestMeter_nil(t *testing.T) {
+	var m *Meter
+	m.Start(Instance, "i-1", "t3.micro", 0)
+	m.Stop(Instance, "i-1")
+	m.Add(Egress, "ami-1", "inter-region", 8)
+}
+
+func TestNewMeter_prices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-cost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "prices.json")
+	data := `{"currency": "EUR", "prices": {"instance/t3.micro": 0.009, "instance/t3.small": 0.018}}`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv(EnvPrices, os.Getenv(EnvPrices))
+	os.Setenv(EnvPrices, path)
+
+	m, err := NewMeter(testPrices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Currency != "EUR" || m.Prices["instance/t3.micro"] != 0.009 || m.Prices["instance/t3.small"] != 0.018 || m.Prices["storage/*"] != 0.073 {
+		t.Fatalf("unexpected prices %s %v", m.Currency, m.Prices)
+	}
+	if testPrices["instance/t3.micro"] != 0.01 {
+		t.Fatal("the default prices shouldn't change")
+	}
+
+	os.Setenv(EnvPrices, filepath.Join(dir, "missing.json"))
+	if _, err := NewMeter(testPrices); err == nil {
+		t.Fatal("should error without the prices file")
+	}
+}
+
+func TestStepMeter(t *testing.T) {
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", packer.TestUi(t))
+	state.Put("generated_data", map[string]interface{}{"SourceAMI": "ami-0"})
+
+	step := &StepMeter{Prices: testPrices}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("unexpected action %v", action)
+	}
+	FromState(state).Add(Egress, "ami-1", "inter-region", 10)
+	step.Cleanup(state)
+
+	data := state.Get("generated_data").(map[string]interface{})
+	if data["SourceAMI"] != "ami-0" || data[EstimatedCostKey] != "0.2000 USD" {
+		t.Fatalf("unexpected generated data %#v", data)
+	}
+	var r Report
+	if err := json.Unmarshal([]byte(data[CostReportKey].(string)), &r); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Usage) != 1 || r.Usage[0].Resource != "ami-1" || !r.Usage[0].Priced {
+		t.Fatalf("unexpected report %#v", r)
+	}
+}
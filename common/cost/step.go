@@ -0,0 +1,73 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// Generated data keys of the estimated cost of a build: the amount, like
+// "0.0421 USD", and the Report as JSON.
+const (
+	EstimatedCostKey = "EstimatedCost"
+	CostReportKey    = "CostReport"
+)
+
+// stateKey is the key of the Meter of the build in the state bag.
+const stateKey = "cost_meter"
+
+// FromState returns the Meter of the build, or nil when the builder doesn't
+// estimate costs.
+func FromState(state multistep.StateBag) *Meter {
+	m, _ := state.Get(stateKey).(*Meter)
+	return m
+}
+
+// StepMeter puts the Meter of the build in the state bag. It comes first so
+// that its cleanup runs last, once the other steps removed their resources:
+// it then adds the estimated cost of the build to the generated data.
+type StepMeter struct {
+	// Prices are the default prices of the builder.
+	Prices Prices
+}
+
+func (s *StepMeter) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	m, err := NewMeter(s.Prices)
+	if err != nil {
+		err = fmt.Errorf("Error loading the prices: %s", err)
+		state.Put("error", err)
+		state.Get("ui").(packer.Ui).Error(err.Error())
+		return multistep.ActionHalt
+	}
+	state.Put(stateKey, m)
+	return multistep.ActionContinue
+}
+
+func (s *StepMeter) Cleanup(state multistep.StateBag) {
+	m := FromState(state)
+	if m == nil {
+		return
+	}
+	report := m.Report()
+	if len(report.Usage) == 0 {
+		return
+	}
+	state.Get("ui").(packer.Ui).Say(fmt.Sprintf("Estimated cost of the build: %s", report))
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("[WARN] cost: %s", err)
+		return
+	}
+	generatedData, ok := state.Get("generated_data").(map[string]interface{})
+	if !ok {
+		generatedData = make(map[string]interface{})
+	}
+	generatedData[EstimatedCostKey] = report.Amount()
+	generatedData[CostReportKey] = string(b)
+	state.Put("generated_data", generatedData)
+}
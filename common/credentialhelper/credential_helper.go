@@ -0,0 +1,189 @@
+// Package credentialhelper runs credential helpers, the commands returning
+// the credentials the cloud builders authenticate with.
+//
+// Packer runs the command of the helper, writes a Request to its stdin, and
+// reads the Credentials from its stdout, as JSON. The credentials are cached
+// until they are about to expire, then the helper is run again, so the
+// builds outliving the credentials, like STS or OIDC tokens valid for an
+// hour, keep on authenticating.
+package credentialhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version is the version of the protocol of the credential helpers.
+const Version = 1
+
+// The providers the credentials are requested for.
+const (
+	// ProviderAWS requests an access key, a secret key and, optionally, a
+	// session token.
+	ProviderAWS = "aws"
+	// ProviderAzure requests a JWT Packer authenticates with as the client
+	// of a service principal, like a federated OIDC token.
+	ProviderAzure = "azure"
+	// ProviderGCP requests an OAuth2 access token.
+	ProviderGCP = "gcp"
+)
+
+// DefaultExpiryWindow is how long before they expire the credentials are
+// refreshed.
+const DefaultExpiryWindow = 5 * time.Minute
+
+// DefaultTimeout is how long the helper can run.
+const DefaultTimeout = time.Minute
+
+// Request is what the helper is asked for, written to its stdin.
+type Request struct {
+	Version  int    `json:"version"`
+	Provider string `json:"provider"`
+	// Scopes are the scopes of the Google Cloud token.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// Credentials are the credentials returned by the helper on its stdout.
+type Credentials struct {
+	Version int `json:"version"`
+	// AccessKey and SecretKey are the AWS keys.
+	AccessKey string `json:"access_key,omitempty"`
+	SecretKey string `json:"secret_key,omitempty"`
+	// Token is the AWS session token, the Azure client JWT or the Google
+	// Cloud access token.
+	Token string `json:"token,omitempty"`
+	// Expiration is when the credentials expire. The credentials without
+	// expiration are never refreshed.
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+// expired tells whether the credentials expire within window.
+func (c *Credentials) expired(window time.Duration) bool {
+	return c.Expiration != nil && !time.Now().Add(window).Before(*c.Expiration)
+}
+
+// Helper is a credential helper.
+type Helper struct {
+	// Command is the helper and its arguments.
+	Command []string
+	Request Request
+	// ExpiryWindow is how long before they expire the credentials are
+	// refreshed, DefaultExpiryWindow when zero.
+	ExpiryWindow time.Duration
+	// Timeout is how long the helper can run, DefaultTimeout when zero.
+	Timeout time.Duration
+
+	mu          sync.Mutex
+	credentials *Credentials
+}
+
+// New returns the helper running command for the credentials of provider.
+func New(command []string, provider string) *Helper {
+	return &Helper{
+		Command: command,
+		Request: Request{Version: Version, Provider: provider},
+	}
+}
+
+func (h *Helper) expiryWindow() time.Duration {
+	if h.ExpiryWindow == 0 {
+		return DefaultExpiryWindow
+	}
+	return h.ExpiryWindow
+}
+
+// Expired tells whether the credentials must be requested again, when they
+// aren't cached or are about to expire.
+func (h *Helper) Expired() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.expired()
+}
+
+func (h *Helper) expired() bool {
+	return h.credentials == nil || h.credentials.expired(h.expiryWindow())
+}
+
+// Get returns the credentials, running the helper when they are expired.
+func (h *Helper) Get(ctx context.Context) (*Credentials, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.expired() {
+		return h.credentials, nil
+	}
+	c, err := h.run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	h.credentials = c
+	return c, nil
+}
+
+func (h *Helper) run(ctx context.Context) (*Credentials, error) {
+	if len(h.Command) == 0 {
+		return nil, fmt.Errorf("credential helper not set")
+	}
+	name := h.Command[0]
+
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := json.Marshal(h.Request)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, h.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	log.Printf("[INFO] Requesting %s credentials from credential helper %q", h.Request.Provider, name)
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("credential helper %q failed: %s: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("credential helper %q failed: %s", name, err)
+	}
+
+	var c Credentials
+	if err := json.Unmarshal(stdout.Bytes(), &c); err != nil {
+		return nil, fmt.Errorf("credential helper %q returned invalid credentials: %s", name, err)
+	}
+	if err := c.validate(h.Request.Provider); err != nil {
+		return nil, fmt.Errorf("credential helper %q returned invalid credentials: %s", name, err)
+	}
+	if c.Expiration != nil {
+		log.Printf("[INFO] Credentials from credential helper %q expire at %s", name, c.Expiration)
+	}
+	return &c, nil
+}
+
+func (c *Credentials) validate(provider string) error {
+	if c.Version != Version {
+		return fmt.Errorf("unsupported version %d, expected %d", c.Version, Version)
+	}
+	switch provider {
+	case ProviderAWS:
+		if c.AccessKey == "" || c.SecretKey == "" {
+			return fmt.Errorf("access_key and secret_key must be set")
+		}
+	default:
+		if c.Token == "" {
+			return fmt.Errorf("token must be set")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package credentialhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// helperCommand returns the command running TestHelperProcess as a
+// credential helper.
+func helperCommand(args ...string) []string {
+	os.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	return append([]string{os.Args[0], "-test.run=TestHelperProcess", "--"}, args...)
+}
+
+// TestHelperProcess is a credential helper returning credentials expiring
+// after the duration of its argument, or failing.
+func TestHelperProcess(*testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	args = args[1:]
+
+	var req Request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid request: %s", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "fail":
+		fmt.Fprint(os.Stderr, "not logged in")
+		os.Exit(2)
+	case "invalid":
+		fmt.Print(`{"version": 1}`)
+	default:
+		ttl, _ := time.ParseDuration(args[0])
+		expiration := time.Now().Add(ttl)
+		json.NewEncoder(os.Stdout).Encode(Credentials{
+			Version:    Version,
+			AccessKey:  "AKID",
+			SecretKey:  "secret",
+			Token:      fmt.Sprintf("%s-%d", req.Provider, time.Now().UnixNano()),
+			Expiration: &expiration,
+		})
+	}
+}
+
+func TestHelper_Get(t *testing.T) {
+	h := New(helperCommand("1h"), ProviderAWS)
+	c, err := h.Get(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if c.AccessKey != "AKID" || c.SecretKey != "secret" || !strings.HasPrefix(c.Token, "aws-") {
+		t.Fatalf("bad: %#v", c)
+	}
+
+	// The credentials are cached until they are about to expire.
+	if h.Expired() {
+		t.Fatal("credentials expired")
+	}
+	cached, err := h.Get(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if cached.Token != c.Token {
+		t.Fatalf("credentials not cached: %#v", cached)
+	}
+}
+
+func TestHelper_Get_refresh(t *testing.T) {
+	h := New(helperCommand("1m"), ProviderGCP)
+	c, err := h.Get(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The credentials expire within the expiry window.
+	if !h.Expired() {
+		t.Fatal("credentials not expired")
+	}
+	refreshed, err := h.Get(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if refreshed.Token == c.Token {
+		t.Fatalf("credentials not refreshed: %#v", refreshed)
+	}
+}
+
+func TestHelper_Get_errors(t *testing.T) {
+	cases := map[string]string{
+		"fail":    "not logged in",
+		"invalid": "access_key and secret_key must be set",
+	}
+	for arg, expected := range cases {
+		h := New(helperCommand(arg), ProviderAWS)
+		_, err := h.Get(context.Background())
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("%s: expected error containing %q, got %v", arg, expected, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+//go:generate struct-markdown
+
+// Package firewall manages the temporary firewall rules letting Packer reach
+// the communicator of the instances of cloud builders: a least-privilege
+// inbound rule for the port of the communicator, only from the public IP
+// address Packer connects from, created before the instance launches and
+// deleted once the build is done.
+package firewall
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/packer/common/publicip"
+)
+
+// Config is the temporary firewall rule of a builder.
+type Config struct {
+	// Create a temporary firewall rule allowing only the public IP address
+	// Packer connects from to reach the port of the communicator of the
+	// instance, and delete it once the build is done. The address is
+	// `public_ip_address`, or else detected with `public_ip_providers`.
+	TemporaryFirewallRule bool `mapstructure:"temporary_firewall_rule" required:"false"`
+	// The IPv4 or IPv6 CIDR blocks the temporary firewall rule allows,
+	// instead of the detected public IP address of Packer, like when Packer
+	// connects through a proxy or a VPN.
+	TemporaryFirewallSourceCidrs []string `mapstructure:"temporary_firewall_source_cidrs" required:"false"`
+	// How the public IP address of Packer is detected.
+	PublicIPConfig publicip.Config `mapstructure:",squash"`
+}
+
+func (c *Config) Prepare() []error {
+	errs := c.PublicIPConfig.Prepare()
+	for _, cidr := range c.TemporaryFirewallSourceCidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Errorf("Error parsing CIDR in temporary_firewall_source_cidrs: %s", err))
+		}
+	}
+
+	return errs
+}
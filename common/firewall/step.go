@@ -0,0 +1,116 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common/publicip"
+	"github.com/hashicorp/packer/common/retry"
+	"github.com/hashicorp/packer/common/uuid"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// StateRule is the key of the name of the temporary firewall rule in the
+// state of a build, for the steps launching the instance to apply it.
+const StateRule = "temporary_firewall_rule"
+
+// Rule is a temporary firewall rule allowing TCP connections to Port from
+// SourceCidrs.
+type Rule struct {
+	// Name is a unique name, made of lower case letters, digits and dashes,
+	// starting with a letter.
+	Name        string
+	Port        int
+	SourceCidrs []string
+	// ID is what the builder sets to identify the rule it created, when it
+	// isn't Name.
+	ID string
+}
+
+// Func creates or deletes a temporary firewall rule on the cloud of a
+// builder.
+type Func func(ctx context.Context, state multistep.StateBag, rule *Rule) error
+
+// StepTemporaryRule creates the temporary firewall rule of Config before the
+// instance is launched, and deletes it in its cleanup, retrying while the
+// instance still uses it. The name of the rule is put in the state as
+// StateRule.
+type StepTemporaryRule struct {
+	Config *Config
+	Comm   *communicator.Config
+	// Create creates the rule, Delete deletes it. Delete is nil when the
+	// rule is deleted with the other resources of the build.
+	Create Func
+	Delete Func
+
+	rule *Rule
+}
+
+func (s *StepTemporaryRule) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if !s.Config.TemporaryFirewallRule || s.Comm.Type == "none" {
+		return multistep.ActionContinue
+	}
+	ui := state.Get("ui").(packer.Ui)
+
+	cidrs := s.Config.TemporaryFirewallSourceCidrs
+	if len(cidrs) == 0 {
+		ui.Say("Detecting the public IP address of Packer...")
+		ip, err := s.Config.PublicIPConfig.Detect(ctx)
+		if err != nil {
+			err := fmt.Errorf("Error detecting the public IP address of Packer for the temporary firewall rule: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		publicip.Put(state, ip)
+		cidrs = []string{ip.CIDR()}
+	}
+
+	rule := &Rule{
+		Name:        fmt.Sprintf("packer-%s", uuid.TimeOrderedUUID()),
+		Port:        s.Comm.Port(),
+		SourceCidrs: cidrs,
+	}
+	ui.Say(fmt.Sprintf("Creating temporary firewall rule %s allowing port %d from %s...",
+		rule.Name, rule.Port, strings.Join(cidrs, ", ")))
+	if err := s.Create(ctx, state, rule); err != nil {
+		err := fmt.Errorf("Error creating temporary firewall rule: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	if rule.ID == "" {
+		rule.ID = rule.Name
+	}
+	s.rule = rule
+	state.Put(StateRule, rule.Name)
+	return multistep.ActionContinue
+}
+
+func (s *StepTemporaryRule) Cleanup(state multistep.StateBag) {
+	if s.rule == nil || s.Delete == nil {
+		return
+	}
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say(fmt.Sprintf("Deleting temporary firewall rule %s...", s.rule.Name))
+	// The rule can't be deleted while the instance being deleted still
+	// uses it.
+	err := retry.Config{
+		Tries:      5,
+		RetryDelay: func() time.Duration { return 5 * time.Second },
+	}.Run(multistep.CleanupContext(state), func(ctx context.Context) error {
+		return s.Delete(ctx, state, s.rule)
+	})
+	if err != nil {
+		ui.Error(fmt.Sprintf(
+			"Error cleaning up temporary firewall rule. Please delete the rule manually:"+
+				" err: %s; rule: %s", err, s.rule.ID))
+		return
+	}
+	s.rule = nil
+}
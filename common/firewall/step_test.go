@@ -0,0 +1,108 @@
+package firewall
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func testState(t *testing.T) multistep.StateBag {
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", packer.TestUi(t))
+	return state
+}
+
+func TestConfig_Prepare(t *testing.T) {
+	c := &Config{TemporaryFirewallSourceCidrs: []string{"10.0.0.0/8", "2001:db8::/32"}}
+	if errs := c.Prepare(); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	c = &Config{TemporaryFirewallSourceCidrs: []string{"10.0.0.1"}}
+	c.PublicIPConfig.PublicIPProviders = []string{"ftp://example.com"}
+	if errs := c.Prepare(); len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got: %v", errs)
+	}
+}
+
+func TestStepTemporaryRule(t *testing.T) {
+	var created, deleted *Rule
+	step := &StepTemporaryRule{
+		Config: &Config{TemporaryFirewallRule: true, TemporaryFirewallSourceCidrs: []string{"198.51.100.0/24"}},
+		Comm:   &communicator.Config{Type: "ssh", SSH: communicator.SSH{SSHPort: 22}},
+		Create: func(_ context.Context, _ multistep.StateBag, rule *Rule) error {
+			created = rule
+			rule.ID = "sg-" + rule.Name
+			return nil
+		},
+		Delete: func(_ context.Context, _ multistep.StateBag, rule *Rule) error {
+			deleted = rule
+			return nil
+		},
+	}
+
+	state := testState(t)
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("unexpected action: %v, %v", action, state.Get("error"))
+	}
+	if created == nil || created.Port != 22 || !reflect.DeepEqual(created.SourceCidrs, []string{"198.51.100.0/24"}) {
+		t.Fatalf("bad rule: %#v", created)
+	}
+	if state.Get(StateRule) != created.Name {
+		t.Fatalf("the name of the rule should be in the state, got %v", state.Get(StateRule))
+	}
+
+	step.Cleanup(state)
+	if deleted != created || deleted.ID != "sg-"+created.Name {
+		t.Fatalf("the created rule should be deleted, got %#v", deleted)
+	}
+}
+
+func TestStepTemporaryRule_disabled(t *testing.T) {
+	step := &StepTemporaryRule{
+		Config: &Config{},
+		Comm:   &communicator.Config{Type: "ssh"},
+		Create: func(context.Context, multistep.StateBag, *Rule) error {
+			t.Fatal("no rule should be created")
+			return nil
+		},
+	}
+	state := testState(t)
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("unexpected action: %v", action)
+	}
+	step.Cleanup(state)
+	if _, ok := state.GetOk(StateRule); ok {
+		t.Fatal("no rule should be in the state")
+	}
+}
+
+func TestStepTemporaryRule_publicIP(t *testing.T) {
+	var created *Rule
+	config := &Config{TemporaryFirewallRule: true}
+	config.PublicIPConfig.PublicIPAddress = "2001:db8::7"
+	step := &StepTemporaryRule{
+		Config: config,
+		Comm:   &communicator.Config{Type: "winrm", WinRM: communicator.WinRM{WinRMPort: 5986}},
+		Create: func(_ context.Context, _ multistep.StateBag, rule *Rule) error {
+			created = rule
+			return nil
+		},
+	}
+
+	state := testState(t)
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("unexpected action: %v, %v", action, state.Get("error"))
+	}
+	if created == nil || created.Port != 5986 || !reflect.DeepEqual(created.SourceCidrs, []string{"2001:db8::7/128"}) {
+		t.Fatalf("bad rule: %#v", created)
+	}
+	generatedData := state.Get("generated_data").(map[string]interface{})
+	if generatedData["PublicIP"] != "2001:db8::7" {
+		t.Fatalf("the public IP address should be in the generated data, got %v", generatedData)
+	}
+}
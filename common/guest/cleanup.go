@@ -0,0 +1,144 @@
+package guest
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/masterzen/winrm"
+)
+
+// CleanupTask is a cleanup task run on the guest through the communicator
+// once provisioned, so that the image doesn't keep what is specific to the
+// build or to the guest.
+type CleanupTask struct {
+	// Name is the name of the task in guest_cleanup.
+	Name string
+	// Description describes what the task cleans up.
+	Description string
+
+	// linux and windows return the commands of the task on the guest OS,
+	// or no command when there is nothing to clean up. The task is not
+	// supported on the guest OS when they are not set.
+	linux   func(comm *communicator.Config) []string
+	windows func(comm *communicator.Config) []string
+}
+
+// Commands returns the commands running the task on the guest of comm, and
+// whether the task is supported on the guest OS.
+func (t *CleanupTask) Commands(comm *communicator.Config) ([]string, bool) {
+	var commands func(comm *communicator.Config) []string
+	switch comm.GuestOS() {
+	case "linux":
+		commands = t.linux
+	case "windows":
+		commands = t.windows
+	}
+	if commands == nil {
+		return nil, false
+	}
+	return commands(comm), true
+}
+
+// cleanupTasks are the guest cleanup tasks.
+var cleanupTasks = []*CleanupTask{
+	{
+		Name:        "authorized_keys",
+		Description: "the temporary key from the authorized_keys files",
+		linux: func(comm *communicator.Config) []string {
+			if comm.SSHTemporaryKeyPairName == "" {
+				return nil
+			}
+			// Per the OpenSSH manual (https://man.openbsd.org/sshd.8), a
+			// typical line in the 'authorized_keys' file contains several
+			// fields that are delimited by spaces, the last one being a
+			// comment: the comment of the temporary key is
+			// SSHTemporaryKeyPairName, so its line ends with
+			// ' packer-key-pair-comment' (note the leading space).
+			return []string{
+				fmt.Sprintf("sed -i.bak '/ %s$/d' ~/.ssh/authorized_keys; rm ~/.ssh/authorized_keys.bak", comm.SSHTemporaryKeyPairName),
+				fmt.Sprintf("sudo sed -i.bak '/ %s$/d' /root/.ssh/authorized_keys; sudo rm /root/.ssh/authorized_keys.bak", comm.SSHTemporaryKeyPairName),
+			}
+		},
+	},
+	{
+		Name:        "machine_id",
+		Description: "the machine ID, generated again at the next boot",
+		linux: func(comm *communicator.Config) []string {
+			return []string{"sudo sh -c 'truncate -s 0 /etc/machine-id && rm -f /var/lib/dbus/machine-id'"}
+		},
+	},
+	{
+		Name:        "cloud_init",
+		Description: "the cloud-init state and logs, so that it runs again at the next boot",
+		linux: func(comm *communicator.Config) []string {
+			return []string{"sudo sh -c 'if command -v cloud-init >/dev/null; then cloud-init clean --logs; else rm -rf /var/lib/cloud/instance /var/lib/cloud/instances /var/log/cloud-init*.log; fi'"}
+		},
+	},
+	{
+		Name:        "winrm_listeners",
+		Description: "the WinRM listeners, at the next boot so that the build connection is kept",
+		windows: func(comm *communicator.Config) []string {
+			return []string{winrm.Powershell(`$ErrorActionPreference = 'Stop'
+$command = 'Remove-Item -Path WSMan:\localhost\Listener\* -Recurse; Unregister-ScheduledTask -TaskName PackerRemoveWinRMListeners -Confirm:$false'
+$action = New-ScheduledTaskAction -Execute 'powershell.exe' -Argument "-NoProfile -Command $command"
+$trigger = New-ScheduledTaskTrigger -AtStartup
+Register-ScheduledTask -TaskName PackerRemoveWinRMListeners -Action $action -Trigger $trigger -User SYSTEM -RunLevel Highest -Force | Out-Null`)}
+		},
+	},
+	{
+		Name:        "sysprep",
+		Description: "the guest identity, generalizing it with sysprep",
+		windows: func(comm *communicator.Config) []string {
+			return []string{winrm.Powershell(`$ErrorActionPreference = 'Stop'
+$process = Start-Process -FilePath "$env:SystemRoot\System32\Sysprep\Sysprep.exe" -ArgumentList '/oobe', '/generalize', '/quiet', '/quit' -Wait -PassThru
+exit $process.ExitCode`)}
+		},
+	},
+}
+
+// CleanupTaskNames returns the names of the guest cleanup tasks.
+func CleanupTaskNames() []string {
+	names := make([]string, len(cleanupTasks))
+	for i, t := range cleanupTasks {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func cleanupTask(name string) *CleanupTask {
+	for _, t := range cleanupTasks {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// CleanupTasks returns the guest cleanup tasks to run, in the order of
+// guest_cleanup. The authorized_keys task runs with the
+// ssh_clear_authorized_keys option of comm too.
+func (c *Config) CleanupTasks(comm *communicator.Config) []*CleanupTask {
+	var tasks []*CleanupTask
+	clearKeys := comm.SSHClearAuthorizedKeys
+	for _, name := range c.GuestCleanup {
+		if t := cleanupTask(name); t != nil {
+			tasks = append(tasks, t)
+			if name == "authorized_keys" {
+				clearKeys = false
+			}
+		}
+	}
+	if clearKeys {
+		tasks = append([]*CleanupTask{cleanupTask("authorized_keys")}, tasks...)
+	}
+	return tasks
+}
+
+func (c *Config) prepareCleanup() (errs []error) {
+	for _, name := range c.GuestCleanup {
+		if cleanupTask(name) == nil {
+			errs = append(errs, fmt.Errorf("guest_cleanup ('%s') is invalid, valid tasks: %v", name, CleanupTaskNames()))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,203 @@
+package guest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common/shellquote"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/masterzen/winrm"
+)
+
+func (c *Config) prepareClock() []error {
+	var errs []error
+	if c.ClockSkewFix {
+		c.ClockSkewCheck = true
+	}
+	if c.ClockMaxSkew < 0 {
+		errs = append(errs, fmt.Errorf("clock_max_skew must not be negative"))
+	}
+	if c.ClockSkewCheck && c.ClockMaxSkew == 0 {
+		c.ClockMaxSkew = time.Minute
+	}
+	return errs
+}
+
+// The commands outputting the time of the guest, in seconds since the epoch.
+const (
+	clockLinuxCommand   = "date -u +%s"
+	clockWindowsCommand = "[DateTimeOffset]::UtcNow.ToUnixTimeSeconds()"
+)
+
+// The commands synchronizing the clock of the guest with NTP.
+const (
+	clockLinuxSync = `[ "$(id -u)" = 0 ] || sudo="sudo -n"
+if command -v chronyc >/dev/null 2>&1; then $sudo chronyc -a makestep
+elif command -v ntpdate >/dev/null 2>&1; then $sudo ntpdate -u pool.ntp.org
+else exit 1; fi`
+	clockWindowsSync = `w32tm /resync /force | Out-Null
+exit $LASTEXITCODE`
+)
+
+// The commands setting the clock of the guest, from seconds since the epoch.
+const (
+	clockLinuxSet = `[ "$(id -u)" = 0 ] || sudo="sudo -n"
+$sudo date -u -s @%d`
+	clockWindowsSet = `Set-Date -Date ([DateTimeOffset]::FromUnixTimeSeconds(%d).LocalDateTime) | Out-Null`
+)
+
+// StepCheckClock checks the clock of the guest once connected, as
+// configured with clock_skew_check, and synchronizes it when it is skewed
+// with clock_skew_fix.
+type StepCheckClock struct {
+	Config *Config
+	Comm   *communicator.Config
+
+	now func() time.Time
+}
+
+func (s *StepCheckClock) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if !s.Config.ClockSkewCheck {
+		return multistep.ActionContinue
+	}
+	comm, ok := state.Get("communicator").(packer.Communicator)
+	if !ok {
+		return multistep.ActionContinue
+	}
+	guestOS := s.guestOS(state)
+	if guestOS == "" {
+		log.Printf("[WARN] Can't check the clock of a guest of unknown OS")
+		return multistep.ActionContinue
+	}
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Checking the clock of the guest...")
+	skew, err := s.skew(ctx, comm, guestOS)
+	if err == nil && absDuration(skew) > s.Config.ClockMaxSkew && s.Config.ClockSkewFix {
+		ui.Message(fmt.Sprintf("The clock of the guest is %s, synchronizing it...", describeSkew(skew)))
+		skew, err = s.fix(ctx, comm, guestOS)
+	}
+	if err == nil && absDuration(skew) > s.Config.ClockMaxSkew {
+		err = fmt.Errorf("the clock of the guest is %s, more than clock_max_skew (%s)", describeSkew(skew), s.Config.ClockMaxSkew)
+		if !s.Config.ClockSkewFix {
+			err = fmt.Errorf("%s; set clock_skew_fix to synchronize it", err)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("Error checking the clock of the guest: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	ui.Message(fmt.Sprintf("The clock of the guest is %s", describeSkew(skew)))
+	return multistep.ActionContinue
+}
+
+func (s *StepCheckClock) Cleanup(multistep.StateBag) {}
+
+// guestOS returns the OS of the guest, from its facts when they are known.
+func (s *StepCheckClock) guestOS(state multistep.StateBag) string {
+	if facts, ok := state.Get("guest_facts").(*communicator.GuestFacts); ok && facts.OSFamily != "" {
+		if facts.OSFamily == "windows" {
+			return "windows"
+		}
+		return "linux"
+	}
+	return s.Comm.GuestOS()
+}
+
+// fix synchronizes the clock of the guest with NTP, or sets it to the time
+// of the host when it is still skewed, and returns the skew left.
+func (s *StepCheckClock) fix(ctx context.Context, comm packer.Communicator, guestOS string) (time.Duration, error) {
+	sync, set := "sh -c "+shellquote.Quote(clockLinuxSync), clockLinuxSet
+	if guestOS == "windows" {
+		sync, set = winrm.Powershell(clockWindowsSync), clockWindowsSet
+	}
+	if _, err := s.run(ctx, comm, sync); err != nil {
+		log.Printf("[WARN] Error synchronizing the clock of the guest with NTP: %s", err)
+	} else if skew, err := s.skew(ctx, comm, guestOS); err != nil || absDuration(skew) <= s.Config.ClockMaxSkew {
+		return skew, err
+	}
+
+	log.Printf("[INFO] Setting the clock of the guest to the time of the host")
+	set = fmt.Sprintf(set, s.clock().Unix())
+	if guestOS == "windows" {
+		set = winrm.Powershell(set)
+	} else {
+		set = "sh -c " + shellquote.Quote(set)
+	}
+	if _, err := s.run(ctx, comm, set); err != nil {
+		return 0, fmt.Errorf("could not set the clock: %s", err)
+	}
+	return s.skew(ctx, comm, guestOS)
+}
+
+// skew returns how far ahead of the clock of the host the clock of the
+// guest is, measured halfway through the command reading it.
+func (s *StepCheckClock) skew(ctx context.Context, comm packer.Communicator, guestOS string) (time.Duration, error) {
+	command := clockLinuxCommand
+	if guestOS == "windows" {
+		command = winrm.Powershell(clockWindowsCommand)
+	}
+	start := s.clock()
+	out, err := s.run(ctx, comm, command)
+	if err != nil {
+		return 0, err
+	}
+	host := start.Add(s.clock().Sub(start) / 2)
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected time %q", strings.TrimSpace(out))
+	}
+	// The guest time is truncated to the second.
+	guest := time.Unix(seconds, int64(time.Second/2))
+	return guest.Sub(host).Round(time.Second), nil
+}
+
+// run runs command and returns its output, failing when it exits with a
+// non-zero status.
+func (s *StepCheckClock) run(ctx context.Context, comm packer.Communicator, command string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := &packer.RemoteCmd{Command: command, Stdout: &stdout, Stderr: &stderr}
+	if err := comm.Start(ctx, cmd); err != nil {
+		return "", err
+	}
+	if status := cmd.Wait(); status != 0 {
+		return "", fmt.Errorf("the command exited with status %d: %s", status, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func (s *StepCheckClock) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// describeSkew describes how far ahead or behind the clock of the guest is.
+func describeSkew(skew time.Duration) string {
+	switch {
+	case skew > 0:
+		return fmt.Sprintf("%s ahead", skew)
+	case skew < 0:
+		return fmt.Sprintf("%s behind", -skew)
+	default:
+		return "synchronized"
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,90 @@
+package guest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// testClockComm is a communicator of a guest whose clock is off by skew,
+// without NTP.
+type testClockComm struct {
+	packer.MockCommunicator
+	now  time.Time
+	skew time.Duration
+}
+
+func (c *testClockComm) Start(ctx context.Context, cmd *packer.RemoteCmd) error {
+	status := 0
+	switch {
+	case cmd.Command == clockLinuxCommand:
+		io.WriteString(cmd.Stdout, fmt.Sprintf("%d\n", c.now.Add(c.skew).Unix()))
+	case strings.Contains(cmd.Command, "chronyc"):
+		status = 1
+	case strings.Contains(cmd.Command, "date -u -s @"):
+		c.skew = 0
+	default:
+		status = 127
+	}
+	go cmd.SetExited(status)
+	return nil
+}
+
+func TestStepCheckClock(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	cases := []struct {
+		name    string
+		config  Config
+		skew    time.Duration
+		action  multistep.StepAction
+		fixSkew time.Duration
+	}{
+		{"disabled", Config{}, time.Hour, multistep.ActionContinue, time.Hour},
+		{"in sync", Config{ClockSkewCheck: true}, 10 * time.Second, multistep.ActionContinue, 10 * time.Second},
+		{"skewed", Config{ClockSkewCheck: true}, -2 * time.Hour, multistep.ActionHalt, -2 * time.Hour},
+		{"fixed", Config{ClockSkewFix: true}, 2 * time.Hour, multistep.ActionContinue, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if errs := tc.config.prepareClock(); len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			comm := &testClockComm{now: now, skew: tc.skew}
+			state := testState(t)
+			state.Put("communicator", comm)
+
+			step := &StepCheckClock{
+				Config: &tc.config,
+				Comm:   testSSHConfig(),
+				now:    func() time.Time { return now },
+			}
+			if action := step.Run(context.Background(), state); action != tc.action {
+				t.Fatalf("unexpected action %#v: %v", action, state.Get("error"))
+			}
+			if comm.skew != tc.fixSkew {
+				t.Fatalf("unexpected skew %s after the step, expected %s", comm.skew, tc.fixSkew)
+			}
+		})
+	}
+}
+
+func TestConfig_prepareClock(t *testing.T) {
+	c := &Config{ClockSkewFix: true}
+	if errs := c.prepareClock(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if !c.ClockSkewCheck || c.ClockMaxSkew != time.Minute {
+		t.Fatalf("unexpected defaults: %t %s", c.ClockSkewCheck, c.ClockMaxSkew)
+	}
+
+	c = &Config{ClockMaxSkew: -time.Second}
+	if errs := c.prepareClock(); len(errs) != 1 {
+		t.Fatalf("a negative clock_max_skew should be invalid: %v", errs)
+	}
+}
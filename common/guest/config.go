@@ -0,0 +1,155 @@
+//go:generate struct-markdown
+//go:generate mapstructure-to-hcl2 -type Config
+
+// Package guest holds what Packer does on the guest of the builders
+// connecting to it with SSH or WinRM, besides running the provisioners: the
+// environment of the commands and the clock check once connected, and the
+// credential rotation, cleanup and generalization once provisioned.
+//
+// The builders supporting it embed a Config next to their communicator
+// configuration and add its steps: StepCheckClock and StepEnvironment after
+// communicator.StepConnect, and StepRotateCredentials, StepCleanup,
+// StepLinuxGeneralize and StepSysprep, in that order, after
+// common.StepProvision.
+package guest
+
+import (
+	"time"
+
+	"github.com/hashicorp/packer/helper/communicator"
+)
+
+// Config is the configuration of what Packer does on the guest, besides
+// running the provisioners.
+type Config struct {
+	// Rotates the credential of the communicator user once provisioned,
+	// before the guest is cleaned up and generalized, so that the image
+	// doesn't contain the build-time secret:
+	//
+	// -   `password` - The password of the user is replaced with a random
+	//     one: generated on the guest over SSH, and by Packer over WinRM,
+	//     which keeps using it for the commands that follow.
+	//
+	// -   `remove_user` - The user is removed, with its home directory on
+	//     Linux. Since the commands that follow would run as the removed
+	//     user, it can't be combined with `guest_cleanup`,
+	//     `ssh_clear_authorized_keys`, `linux_generalize` or
+	//     `sysprep_generalize`.
+	//
+	// By default, the credential is kept. The rotation is reported as
+	// `credential_rotation` in the manifest. The rotation runs with `sudo`
+	// over SSH, and the WinRM user must be an administrator. Commands run by
+	// the builder with the build password after the rotation, like a
+	// `shutdown_command` piping it to `sudo -S`, can't use it anymore.
+	CredentialRotation string `mapstructure:"credential_rotation"`
+	// Cleanup tasks run on the guest once provisioned, after the credential
+	// rotation, in order, so that the image doesn't keep what is specific to
+	// the build or to the guest:
+	//
+	// -   `authorized_keys` - Removes the temporary SSH key from
+	//     `~/.ssh/authorized_keys` and `/root/.ssh/authorized_keys`, like
+	//     `ssh_clear_authorized_keys`.
+	//
+	// -   `machine_id` - Empties `/etc/machine-id`, generated again at the
+	//     next boot.
+	//
+	// -   `cloud_init` - Removes the cloud-init state and logs, so that
+	//     cloud-init runs again at the next boot.
+	//
+	// -   `winrm_listeners` - Removes the WinRM listeners at the next boot,
+	//     so that the WinRM connection of the build is kept.
+	//
+	// -   `sysprep` - Generalizes the guest with sysprep, without shutting
+	//     it down.
+	//
+	// The `authorized_keys`, `machine_id` and `cloud_init` tasks are run on
+	// the guests connected to with SSH, using `sudo`; the `winrm_listeners`
+	// and `sysprep` tasks on the guests connected to with WinRM. The tasks
+	// not supported by the guest are skipped.
+	GuestCleanup []string `mapstructure:"guest_cleanup"`
+	// If `true`, the guest cleanup tasks, and their commands, are reported
+	// instead of being run.
+	GuestCleanupDryRun bool `mapstructure:"guest_cleanup_dry_run"`
+	// If `true`, the guest is generalized with sysprep once provisioned,
+	// after the credential rotation and the guest cleanup, and before the
+	// shutdown of the builder. Sysprep is started with
+	// `/generalize /oobe /quit`, and its progress is followed through the
+	// image state of the registry until it succeeds, or until it fails with
+	// the errors of its log. Requires the WinRM communicator, and can't be
+	// combined with the `sysprep` guest cleanup task.
+	SysprepGeneralize bool `mapstructure:"sysprep_generalize"`
+	// The path to a local answer file uploaded to the guest and passed to
+	// sysprep with `/unattend`, to configure the next boot of the image.
+	SysprepUnattendFile string `mapstructure:"sysprep_unattend_file"`
+	// If `true`, sysprep is started with `/mode:vm`, to only generalize
+	// what an image booted on the same hardware needs.
+	SysprepModeVM bool `mapstructure:"sysprep_mode_vm"`
+	// How long to wait for sysprep to generalize the guest. Defaults to
+	// `15m`.
+	SysprepTimeout time.Duration `mapstructure:"sysprep_timeout"`
+	// If `true`, the Linux guest is generalized once provisioned, after the
+	// credential rotation and the guest cleanup, and before the shutdown of
+	// the builder, like with virt-sysprep: its machine ID, logs, persistent
+	// network udev rules, cloud-init state, shell history and temporary
+	// files are removed, with `sudo`. Requires the SSH communicator.
+	LinuxGeneralize bool `mapstructure:"linux_generalize"`
+	// The generalization operations to run, instead of the default ones:
+	// `machine_id`, `dbus_machine_id`, `udev_rules`, `cloud_init`,
+	// `shell_history`, `tmp_files` and `logs` run by default; `ssh_host_keys`
+	// and `package_cache` only run when listed.
+	LinuxGeneralizeOperations []string `mapstructure:"linux_generalize_operations"`
+	// The generalization operations not to run.
+	LinuxGeneralizeSkip []string `mapstructure:"linux_generalize_skip"`
+	// The shell the commands of the provisioners run with on the guest, like
+	// `/bin/sh`, `bash -l` for a login shell reading the profile of the
+	// user, or `pwsh`, so that scripts behave the same across
+	// distributions. POSIX shells run the commands with `-c`, `pwsh` and
+	// `powershell` with `-EncodedCommand`. By default, the commands run with
+	// the shell of the user over SSH, and with `cmd` over WinRM, where only
+	// `pwsh` and `powershell` can be set.
+	RemoteShell string `mapstructure:"remote_shell"`
+	// The locale the commands of the provisioners run with on the guest,
+	// like `C.UTF-8`: `LANG` and `LC_ALL` are set to it. By default, the
+	// locale of the guest is kept.
+	RemoteLocale string `mapstructure:"remote_locale"`
+	// The `PATH` the commands of the provisioners run with on the guest,
+	// replacing the `PATH` of the shell. By default, the `PATH` of the shell
+	// is kept.
+	RemotePath string `mapstructure:"remote_path"`
+	// If `true`, the clock of the guest is compared to the clock of the
+	// host once connected, before the provisioners run, and the build fails
+	// when they are more than `clock_max_skew` apart: a skewed clock, like
+	// after an ISO install without NTP, makes TLS certificates and package
+	// signatures look invalid.
+	ClockSkewCheck bool `mapstructure:"clock_skew_check"`
+	// The largest difference allowed between the clocks of the guest and
+	// the host. Defaults to `1m`.
+	ClockMaxSkew time.Duration `mapstructure:"clock_max_skew"`
+	// If `true`, a skewed clock of the guest is synchronized instead of
+	// failing the build: with `chronyc makestep` or `ntpdate` on Linux and
+	// `w32tm /resync /force` on Windows, and set to the time of the host when
+	// it is still skewed after that. Implies `clock_skew_check`.
+	ClockSkewFix bool `mapstructure:"clock_skew_fix"`
+}
+
+// Prepare validates the configuration against comm, the prepared
+// configuration of the communicator of the builder, and sets the defaults.
+func (c *Config) Prepare(comm *communicator.Config) []error {
+	var errs []error
+	errs = append(errs, c.prepareCredentialRotation(comm)...)
+	errs = append(errs, c.prepareCleanup()...)
+	errs = append(errs, c.prepareSysprep(comm)...)
+	errs = append(errs, c.prepareLinuxGeneralize(comm)...)
+	errs = append(errs, c.prepareEnvironment(comm)...)
+	errs = append(errs, c.prepareClock()...)
+	return errs
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
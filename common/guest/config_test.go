@@ -0,0 +1,194 @@
+package guest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/helper/communicator"
+)
+
+func testSSHConfig() *communicator.Config {
+	return &communicator.Config{
+		Type: "ssh",
+		SSH: communicator.SSH{
+			SSHUsername: "packer",
+			SSHPassword: "test",
+		},
+	}
+}
+
+func testWinRMConfig() *communicator.Config {
+	return &communicator.Config{
+		Type: "winrm",
+		WinRM: communicator.WinRM{
+			WinRMUser:     "admin",
+			WinRMPassword: "test",
+		},
+	}
+}
+
+func TestConfig_credentialRotation(t *testing.T) {
+	comm := testWinRMConfig()
+	c := &Config{CredentialRotation: "password"}
+	if err := c.Prepare(comm); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+	command := c.CredentialRotationCommand(comm, "secret")
+	if !strings.HasPrefix(command, "powershell.exe -EncodedCommand ") {
+		t.Fatalf("bad command: %s", command)
+	}
+
+	c.CredentialRotation = "remove_user"
+	comm.WinRMUser = "Administrator"
+	if err := c.Prepare(comm); len(err) != 1 {
+		t.Fatalf("removing the built-in administrator should fail: %#v", err)
+	}
+
+	comm.WinRMUser = "admin'; whoami"
+	if err := c.Prepare(comm); len(err) != 1 {
+		t.Fatalf("an unsafe user name should fail: %#v", err)
+	}
+
+	c.CredentialRotation = "delete"
+	if err := c.Prepare(comm); len(err) != 1 {
+		t.Fatalf("an invalid rotation should fail: %#v", err)
+	}
+
+	c = &Config{CredentialRotation: "password"}
+	if err := c.Prepare(&communicator.Config{Type: "none"}); len(err) != 1 {
+		t.Fatalf("a rotation without communicator should fail: %#v", err)
+	}
+
+	// The steps running after the rotation can't run as the removed user.
+	comm = testSSHConfig()
+	comm.SSHClearAuthorizedKeys = true
+	c = &Config{CredentialRotation: "remove_user", LinuxGeneralize: true}
+	if err := c.Prepare(comm); len(err) != 2 {
+		t.Fatalf("removing the user before the cleanup and the generalization should fail: %#v", err)
+	}
+}
+
+func TestConfig_cleanup(t *testing.T) {
+	comm := testSSHConfig()
+	comm.SSHClearAuthorizedKeys = true
+	c := &Config{GuestCleanup: []string{"cloud_init", "machine_id"}}
+	if err := c.Prepare(comm); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+	var names []string
+	for _, task := range c.CleanupTasks(comm) {
+		names = append(names, task.Name)
+	}
+	expected := []string{"authorized_keys", "cloud_init", "machine_id"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("bad tasks: %#v", names)
+	}
+
+	// Without a temporary key, there is nothing to remove.
+	if commands, supported := c.CleanupTasks(comm)[0].Commands(comm); !supported || len(commands) != 0 {
+		t.Fatalf("bad authorized_keys commands: %#v, %t", commands, supported)
+	}
+	comm.SSHTemporaryKeyPairName = "packer_5f0c"
+	if commands, _ := c.CleanupTasks(comm)[0].Commands(comm); len(commands) != 2 {
+		t.Fatalf("bad authorized_keys commands: %#v", commands)
+	}
+
+	comm.Type = "winrm"
+	if _, supported := c.CleanupTasks(comm)[1].Commands(comm); supported {
+		t.Fatal("cloud_init is not supported on WinRM guests")
+	}
+
+	c.GuestCleanup = []string{"sysprep", "registry"}
+	if err := c.Prepare(testWinRMConfig()); len(err) != 1 {
+		t.Fatalf("an invalid task should fail: %#v", err)
+	}
+}
+
+func TestConfig_sysprep(t *testing.T) {
+	comm := testWinRMConfig()
+	c := &Config{
+		SysprepGeneralize: true,
+		SysprepModeVM:     true,
+	}
+	if err := c.Prepare(comm); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+	if c.SysprepTimeout != 15*time.Minute {
+		t.Fatalf("bad timeout: %s", c.SysprepTimeout)
+	}
+	if !strings.HasPrefix(c.SysprepCommand(), "powershell.exe -EncodedCommand ") {
+		t.Fatalf("bad command: %s", c.SysprepCommand())
+	}
+
+	c.SysprepUnattendFile = "/nonexistent/unattend.xml"
+	c.GuestCleanup = []string{"sysprep"}
+	if err := c.Prepare(comm); len(err) != 2 {
+		t.Fatalf("a missing answer file and the sysprep task should fail: %#v", err)
+	}
+
+	c = &Config{SysprepGeneralize: true}
+	if err := c.Prepare(testSSHConfig()); len(err) != 1 {
+		t.Fatalf("sysprep over SSH should fail: %#v", err)
+	}
+}
+
+func TestConfig_linuxGeneralize(t *testing.T) {
+	comm := testSSHConfig()
+	c := &Config{LinuxGeneralize: true}
+	if err := c.Prepare(comm); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+	names := func() []string {
+		var names []string
+		for _, op := range c.GeneralizeOperations() {
+			names = append(names, op.Name)
+		}
+		return names
+	}
+	expected := []string{"machine_id", "dbus_machine_id", "udev_rules", "cloud_init", "shell_history", "tmp_files", "logs"}
+	if !reflect.DeepEqual(names(), expected) {
+		t.Fatalf("bad default operations: %#v", names())
+	}
+
+	c.LinuxGeneralizeSkip = []string{"tmp_files", "logs"}
+	expected = []string{"machine_id", "dbus_machine_id", "udev_rules", "cloud_init", "shell_history"}
+	if !reflect.DeepEqual(names(), expected) {
+		t.Fatalf("bad operations: %#v", names())
+	}
+
+	c.LinuxGeneralizeOperations = []string{"ssh_host_keys", "logs", "machine_id"}
+	expected = []string{"machine_id", "ssh_host_keys"}
+	if !reflect.DeepEqual(names(), expected) {
+		t.Fatalf("bad operations: %#v", names())
+	}
+
+	c.LinuxGeneralizeOperations = []string{"registry"}
+	c.LinuxGeneralizeSkip = []string{"history"}
+	if err := c.Prepare(comm); len(err) != 2 {
+		t.Fatalf("invalid operations should fail: %#v", err)
+	}
+
+	c = &Config{LinuxGeneralize: true}
+	if err := c.Prepare(testWinRMConfig()); len(err) != 1 {
+		t.Fatalf("generalizing a WinRM guest should fail: %#v", err)
+	}
+}
+
+func TestParseSysprepStatus(t *testing.T) {
+	status := ParseSysprepStatus("ImageState=IMAGE_STATE_GENERALIZE_RESEAL_TO_OOBE\r\nRunning=False\r\nErrors=\r\n")
+	if !status.Done() || status.Failed() {
+		t.Fatalf("sysprep should be done: %#v", status)
+	}
+
+	status = ParseSysprepStatus("ImageState=IMAGE_STATE_COMPLETE\nRunning=True\nErrors=\n")
+	if status.Done() || status.Failed() {
+		t.Fatalf("sysprep should be running: %#v", status)
+	}
+
+	status = ParseSysprepStatus("ImageState=IMAGE_STATE_COMPLETE\nRunning=False\nErrors=Error SYSPRP Package Foo was installed for a user\n")
+	if !status.Failed() || status.Errors != "Error SYSPRP Package Foo was installed for a user" {
+		t.Fatalf("sysprep should have failed: %#v", status)
+	}
+}
@@ -0,0 +1,151 @@
+package guest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/hashicorp/packer/common/shellquote"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// The kinds of shells the commands run with, as set by remote_shell.
+const (
+	shellPOSIX      = "posix"
+	shellPowerShell = "powershell"
+	shellCmd        = "cmd"
+)
+
+func (c *Config) prepareEnvironment(comm *communicator.Config) []error {
+	var errs []error
+	if c.RemoteShell != "" && comm.Type == "winrm" && c.shellKind(comm) != shellPowerShell {
+		errs = append(errs, fmt.Errorf(
+			"remote_shell ('%s') is invalid with the winrm communicator, valid shells: pwsh, powershell",
+			c.RemoteShell))
+	}
+	if strings.ContainsAny(c.RemoteLocale+c.RemotePath, "\"\n") {
+		errs = append(errs, fmt.Errorf("remote_locale and remote_path can't contain double quotes or newlines"))
+	}
+	return errs
+}
+
+// shellKind returns the kind of shell the commands run with over comm.
+func (c *Config) shellKind(comm *communicator.Config) string {
+	fields := strings.Fields(c.RemoteShell)
+	if len(fields) == 0 {
+		if comm.Type == "winrm" {
+			return shellCmd
+		}
+		return shellPOSIX
+	}
+	name := strings.ToLower(path.Base(strings.Replace(fields[0], `\`, "/", -1)))
+	switch strings.TrimSuffix(name, ".exe") {
+	case "pwsh", "powershell":
+		return shellPowerShell
+	default:
+		return shellPOSIX
+	}
+}
+
+// normalizesEnvironment tells whether the commands run with the shell, the
+// locale or the PATH of the configuration.
+func (c *Config) normalizesEnvironment() bool {
+	return c.RemoteShell != "" || c.RemoteLocale != "" || c.RemotePath != ""
+}
+
+// RemoteCommand returns command run over comm with the shell, the locale and
+// the PATH of the configuration.
+func (c *Config) RemoteCommand(comm *communicator.Config, command string) string {
+	var env []string
+	if c.RemoteLocale != "" {
+		env = append(env, "LANG="+c.RemoteLocale, "LC_ALL="+c.RemoteLocale)
+	}
+	if c.RemotePath != "" {
+		env = append(env, "PATH="+c.RemotePath)
+	}
+
+	switch c.shellKind(comm) {
+	case shellPowerShell:
+		var b strings.Builder
+		for _, kv := range env {
+			kv := strings.SplitN(kv, "=", 2)
+			fmt.Fprintf(&b, "$env:%s = '%s'\n", kv[0], strings.Replace(kv[1], "'", "''", -1))
+		}
+		b.WriteString(command)
+		return fmt.Sprintf("%s -NoProfile -NonInteractive -EncodedCommand %s",
+			c.RemoteShell, encodePowerShell(b.String()))
+	case shellCmd:
+		var b strings.Builder
+		for _, kv := range env {
+			fmt.Fprintf(&b, `set "%s" && `, kv)
+		}
+		b.WriteString(command)
+		return b.String()
+	default:
+		if len(env) > 0 {
+			quoted := make([]string, len(env))
+			for i, kv := range env {
+				kv := strings.SplitN(kv, "=", 2)
+				quoted[i] = kv[0] + "=" + shellquote.Quote(kv[1])
+			}
+			command = fmt.Sprintf("export %s; %s", strings.Join(quoted, " "), command)
+		}
+		if c.RemoteShell == "" {
+			return command
+		}
+		return fmt.Sprintf("%s -c %s", c.RemoteShell, shellquote.Quote(command))
+	}
+}
+
+// encodePowerShell encodes script for -EncodedCommand: base64 of UTF-16LE.
+func encodePowerShell(script string) string {
+	units := utf16.Encode([]rune(script))
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[2*i:], u)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// StepEnvironment runs the commands of the provisioners with the shell, the
+// locale and the PATH of remote_shell, remote_locale and remote_path, by
+// wrapping the communicator once connected.
+type StepEnvironment struct {
+	Config *Config
+	Comm   *communicator.Config
+}
+
+func (s *StepEnvironment) Run(_ context.Context, state multistep.StateBag) multistep.StepAction {
+	comm, ok := state.Get("communicator").(packer.Communicator)
+	if !ok || !s.Config.normalizesEnvironment() {
+		return multistep.ActionContinue
+	}
+	state.Put("communicator", &environmentCommunicator{
+		Communicator: comm,
+		config:       s.Config,
+		comm:         s.Comm,
+	})
+	return multistep.ActionContinue
+}
+
+func (s *StepEnvironment) Cleanup(state multistep.StateBag) {
+}
+
+// environmentCommunicator runs the commands of a communicator with the
+// shell, the locale and the PATH of the configuration.
+type environmentCommunicator struct {
+	packer.Communicator
+	config *Config
+	comm   *communicator.Config
+}
+
+func (c *environmentCommunicator) Start(ctx context.Context, cmd *packer.RemoteCmd) error {
+	cmd.Command = c.config.RemoteCommand(c.comm, cmd.Command)
+	return c.Communicator.Start(ctx, cmd)
+}
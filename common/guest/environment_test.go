@@ -0,0 +1,96 @@
+package guest
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestConfig_RemoteCommand(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   Config
+		comm     *communicator.Config
+		expected string
+	}{
+		{"default", Config{}, testSSHConfig(), "echo $LANG"},
+		{"login shell", Config{RemoteShell: "bash -l"}, testSSHConfig(), `bash -l -c 'echo $LANG'`},
+		{"environment", Config{RemoteLocale: "C.UTF-8", RemotePath: "/usr/bin:/bin"}, testSSHConfig(),
+			`export LANG='C.UTF-8' LC_ALL='C.UTF-8' PATH='/usr/bin:/bin'; echo $LANG`},
+		{"shell and environment", Config{RemoteShell: "/bin/sh", RemoteLocale: "C"}, testSSHConfig(),
+			`/bin/sh -c 'export LANG='"'"'C'"'"' LC_ALL='"'"'C'"'"'; echo $LANG'`},
+		{"cmd", Config{RemotePath: `C:\Windows\system32`}, testWinRMConfig(),
+			`set "PATH=C:\Windows\system32" && echo $LANG`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if command := tc.config.RemoteCommand(tc.comm, "echo $LANG"); command != tc.expected {
+				t.Fatalf("got %s, expected %s", command, tc.expected)
+			}
+		})
+	}
+}
+
+func TestConfig_RemoteCommand_powershell(t *testing.T) {
+	c := Config{RemoteShell: "pwsh", RemoteLocale: "en-US"}
+	command := c.RemoteCommand(testWinRMConfig(), "Write-Output 'é'")
+	prefix := "pwsh -NoProfile -NonInteractive -EncodedCommand "
+	if !strings.HasPrefix(command, prefix) {
+		t.Fatalf("unexpected command %s", command)
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(command, prefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "$env:LANG = 'en-US'\n$env:LC_ALL = 'en-US'\nWrite-Output 'é'"
+	var script []rune
+	for i := 0; i+1 < len(b); i += 2 {
+		script = append(script, rune(b[i])|rune(b[i+1])<<8)
+	}
+	if string(script) != expected {
+		t.Fatalf("got %q, expected %q", string(script), expected)
+	}
+}
+
+func TestConfig_prepareEnvironment(t *testing.T) {
+	c := &Config{RemoteShell: "bash -l"}
+	if err := c.Prepare(testWinRMConfig()); len(err) != 1 {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	c = &Config{RemotePath: "/bin\n"}
+	if err := c.Prepare(testSSHConfig()); len(err) != 1 {
+		t.Fatalf("bad: %#v", err)
+	}
+}
+
+func TestStepEnvironment(t *testing.T) {
+	state := testState(t)
+	comm := new(packer.MockCommunicator)
+	state.Put("communicator", comm)
+
+	step := &StepEnvironment{Config: new(Config), Comm: testSSHConfig()}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if state.Get("communicator") != comm {
+		t.Fatal("the communicator was wrapped without remote_shell, remote_locale or remote_path")
+	}
+
+	step.Config.RemoteShell = "bash -l"
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	cmd := &packer.RemoteCmd{Command: "make"}
+	if err := state.Get("communicator").(packer.Communicator).Start(context.Background(), cmd); err != nil {
+		t.Fatal(err)
+	}
+	if comm.StartCmd.Command != "bash -l -c 'make'" {
+		t.Fatalf("unexpected command %s", comm.StartCmd.Command)
+	}
+}
@@ -0,0 +1,145 @@
+package guest
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/packer/common/shellquote"
+	"github.com/hashicorp/packer/helper/communicator"
+)
+
+// GeneralizeOperation is an operation removing what is specific to a Linux
+// guest, so that the machines booted from the image don't share it.
+type GeneralizeOperation struct {
+	// Name is the name of the operation in linux_generalize_operations.
+	Name string
+	// Description describes what the operation removes.
+	Description string
+	// Default tells whether the operation runs when
+	// linux_generalize_operations is not set.
+	Default bool
+	// Command is the shell command of the operation, run as root.
+	Command string
+}
+
+// RemoteCommand returns the command running the operation on the guest, with
+// sudo.
+func (op *GeneralizeOperation) RemoteCommand() string {
+	return "sudo sh -c " + shellquote.Quote(op.Command)
+}
+
+// generalizeOperations are the operations of linux_generalize, in the order
+// they run.
+var generalizeOperations = []*GeneralizeOperation{
+	{
+		Name:        "machine_id",
+		Description: "the machine ID, generated again at the next boot",
+		Default:     true,
+		Command:     "if [ -f /etc/machine-id ]; then truncate -s 0 /etc/machine-id; fi",
+	},
+	{
+		Name:        "dbus_machine_id",
+		Description: "the D-Bus machine ID",
+		Default:     true,
+		// It is usually a link to /etc/machine-id.
+		Command: "if [ ! -L /var/lib/dbus/machine-id ]; then rm -f /var/lib/dbus/machine-id; fi",
+	},
+	{
+		Name:        "udev_rules",
+		Description: "the persistent network udev rules, tying the interface names to the MAC addresses",
+		Default:     true,
+		Command:     "rm -f /etc/udev/rules.d/70-persistent-net.rules /etc/udev/rules.d/75-persistent-net-generator.rules",
+	},
+	{
+		Name:        "cloud_init",
+		Description: "the cloud-init state and logs, so that it runs again at the next boot",
+		Default:     true,
+		Command:     "if command -v cloud-init >/dev/null; then cloud-init clean --logs; else rm -rf /var/lib/cloud/instance /var/lib/cloud/instances /var/log/cloud-init*.log; fi",
+	},
+	{
+		Name:        "shell_history",
+		Description: "the shell history of the users",
+		Default:     true,
+		Command:     "rm -f /root/.bash_history /root/.zsh_history /home/*/.bash_history /home/*/.zsh_history",
+	},
+	{
+		Name:        "tmp_files",
+		Description: "the temporary files",
+		Default:     true,
+		Command:     "find /tmp /var/tmp -mindepth 1 -delete",
+	},
+	{
+		Name:        "logs",
+		Description: "the logs, truncating the current ones",
+		Default:     true,
+		Command:     "find /var/log -type f \\( -name '*.gz' -o -name '*.[0-9]' -o -name '*.old' \\) -delete; find /var/log -type f -exec truncate -s 0 {} +; rm -rf /var/log/journal/*",
+	},
+	{
+		Name:        "ssh_host_keys",
+		Description: "the SSH host keys, which not all distributions generate again at the next boot",
+		Command:     "rm -f /etc/ssh/ssh_host_*key*",
+	},
+	{
+		Name:        "package_cache",
+		Description: "the cache of the package manager",
+		Command:     "if command -v apt-get >/dev/null; then apt-get clean; elif command -v dnf >/dev/null; then dnf clean all; elif command -v yum >/dev/null; then yum clean all; elif command -v zypper >/dev/null; then zypper clean --all; elif command -v apk >/dev/null; then rm -rf /var/cache/apk/*; fi",
+	},
+}
+
+// GeneralizeOperationNames returns the names of the Linux generalization
+// operations.
+func GeneralizeOperationNames() []string {
+	names := make([]string, len(generalizeOperations))
+	for i, op := range generalizeOperations {
+		names[i] = op.Name
+	}
+	return names
+}
+
+func generalizeOperation(name string) *GeneralizeOperation {
+	for _, op := range generalizeOperations {
+		if op.Name == name {
+			return op
+		}
+	}
+	return nil
+}
+
+// GeneralizeOperations returns the Linux generalization operations to run:
+// those of linux_generalize_operations, or the default ones, but those of
+// linux_generalize_skip. There are none without linux_generalize.
+func (c *Config) GeneralizeOperations() []*GeneralizeOperation {
+	if !c.LinuxGeneralize {
+		return nil
+	}
+	allowed := func(op *GeneralizeOperation) bool { return op.Default }
+	if len(c.LinuxGeneralizeOperations) > 0 {
+		allowed = func(op *GeneralizeOperation) bool { return containsString(c.LinuxGeneralizeOperations, op.Name) }
+	}
+	var ops []*GeneralizeOperation
+	for _, op := range generalizeOperations {
+		if allowed(op) && !containsString(c.LinuxGeneralizeSkip, op.Name) {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+func (c *Config) prepareLinuxGeneralize(comm *communicator.Config) (errs []error) {
+	if !c.LinuxGeneralize {
+		return nil
+	}
+	if comm.Type != "ssh" {
+		errs = append(errs, fmt.Errorf("linux_generalize requires the ssh communicator"))
+	}
+	for _, name := range c.LinuxGeneralizeOperations {
+		if generalizeOperation(name) == nil {
+			errs = append(errs, fmt.Errorf("linux_generalize_operations ('%s') is invalid, valid operations: %v", name, GeneralizeOperationNames()))
+		}
+	}
+	for _, name := range c.LinuxGeneralizeSkip {
+		if generalizeOperation(name) == nil {
+			errs = append(errs, fmt.Errorf("linux_generalize_skip ('%s') is invalid, valid operations: %v", name, GeneralizeOperationNames()))
+		}
+	}
+	return errs
+}
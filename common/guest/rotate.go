@@ -0,0 +1,101 @@
+package guest
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/masterzen/winrm"
+)
+
+const (
+	// CredentialRotationPassword replaces the password of the communicator
+	// user with a random one.
+	CredentialRotationPassword = "password"
+	// CredentialRotationRemoveUser removes the communicator user.
+	CredentialRotationRemoveUser = "remove_user"
+)
+
+// rotationUserRe matches the user names the rotation commands can be built
+// for without quoting issues.
+var rotationUserRe = regexp.MustCompile(`^[\w.@ -]+$`)
+
+func (c *Config) prepareCredentialRotation(comm *communicator.Config) (errs []error) {
+	switch c.CredentialRotation {
+	case "":
+		return nil
+	case CredentialRotationPassword, CredentialRotationRemoveUser:
+	default:
+		return []error{fmt.Errorf("credential_rotation ('%s') is invalid, valid values: %s, %s",
+			c.CredentialRotation, CredentialRotationPassword, CredentialRotationRemoveUser)}
+	}
+
+	if comm.Type != "ssh" && comm.Type != "winrm" {
+		return []error{fmt.Errorf("credential_rotation can't be used with the %s communicator", comm.Type)}
+	}
+	user := comm.User()
+	if user != "" && !rotationUserRe.MatchString(user) {
+		errs = append(errs, fmt.Errorf("credential_rotation can't be used with the user %q", user))
+	}
+	if c.CredentialRotation == CredentialRotationRemoveUser {
+		if comm.Type == "ssh" && user == "root" || comm.Type == "winrm" && strings.EqualFold(user, "Administrator") {
+			errs = append(errs, fmt.Errorf("credential_rotation can't remove the built-in %s user", user))
+		}
+		// The steps following the rotation run as the removed user.
+		if len(c.CleanupTasks(comm)) > 0 {
+			errs = append(errs, fmt.Errorf("credential_rotation 'remove_user' can't be combined with guest_cleanup or ssh_clear_authorized_keys"))
+		}
+		if c.LinuxGeneralize {
+			errs = append(errs, fmt.Errorf("credential_rotation 'remove_user' can't be combined with linux_generalize"))
+		}
+		if c.SysprepGeneralize {
+			errs = append(errs, fmt.Errorf("credential_rotation 'remove_user' can't be combined with sysprep_generalize"))
+		}
+	}
+	return errs
+}
+
+// CredentialRotationCommand returns the command rotating the credential of
+// the communicator user on the guest, or an empty string when
+// credential_rotation is not set. Over WinRM, password is the new password
+// of the user; over SSH, the new password is generated on the guest.
+func (c *Config) CredentialRotationCommand(comm *communicator.Config, password string) string {
+	user := comm.User()
+	switch comm.Type {
+	case "ssh":
+		switch c.CredentialRotation {
+		case CredentialRotationPassword:
+			return fmt.Sprintf(
+				`printf '%%s:%%s\n' '%s' "$(head -c 32 /dev/urandom | base64 | tr -d '\n')" | sudo chpasswd`, user)
+		case CredentialRotationRemoveUser:
+			// The user is logged in: its session keeps running until the
+			// guest stops.
+			return fmt.Sprintf("sudo userdel -f -r '%s'", user)
+		}
+	case "winrm":
+		switch c.CredentialRotation {
+		case CredentialRotationPassword:
+			return winrm.Powershell(fmt.Sprintf(`$ErrorActionPreference = 'Stop'
+([ADSI]"WinNT://$env:COMPUTERNAME/%s,user").SetPassword('%s')`, user, password))
+		case CredentialRotationRemoveUser:
+			return winrm.Powershell(fmt.Sprintf(`$ErrorActionPreference = 'Stop'
+([ADSI]"WinNT://$env:COMPUTERNAME").Delete("user", "%s")`, user))
+		}
+	}
+	return ""
+}
+
+// newPassword returns a random password meeting the complexity requirements
+// of Windows.
+func newPassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	// The random part can miss a class of characters, the suffix has them
+	// all.
+	return base64.RawURLEncoding.EncodeToString(b) + "_aA1", nil
+}
@@ -0,0 +1,73 @@
+package guest
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// StepCleanup runs the guest cleanup tasks of guest_cleanup through the
+// communicator, or reports them with guest_cleanup_dry_run.
+//
+// Like removing the temporary key from the authorized_keys files, the
+// cleanup is mostly cosmetic: a task failing is reported, but doesn't fail
+// the build.
+type StepCleanup struct {
+	Config *Config
+	Comm   *communicator.Config
+}
+
+func (s *StepCleanup) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	tasks := s.Config.CleanupTasks(s.Comm)
+	if len(tasks) == 0 {
+		return multistep.ActionContinue
+	}
+
+	comm, ok := state.Get("communicator").(packer.Communicator)
+	if !ok {
+		return multistep.ActionContinue
+	}
+	ui := state.Get("ui").(packer.Ui)
+
+	if s.Config.GuestCleanupDryRun {
+		ui.Say("Guest cleanup dry run: the tasks are not run")
+	} else {
+		ui.Say("Cleaning up the guest...")
+	}
+	for _, task := range tasks {
+		commands, supported := task.Commands(s.Comm)
+		if !supported {
+			ui.Message(fmt.Sprintf("Skipping %s: not supported on this guest", task.Name))
+			continue
+		}
+		if len(commands) == 0 {
+			ui.Message(fmt.Sprintf("Skipping %s: nothing to clean up", task.Name))
+			continue
+		}
+		ui.Message(fmt.Sprintf("Removing %s (%s)", task.Description, task.Name))
+		for _, command := range commands {
+			if s.Config.GuestCleanupDryRun {
+				ui.Message(fmt.Sprintf("Would run: %s", command))
+				continue
+			}
+			cmd := &packer.RemoteCmd{Command: command}
+			err := cmd.RunWithUi(ctx, comm, ui)
+			if err == nil && cmd.ExitStatus() != 0 {
+				err = fmt.Errorf("the command exited with status %d", cmd.ExitStatus())
+			}
+			if err != nil {
+				log.Printf("Error running the %s guest cleanup task: %s", task.Name, err)
+				ui.Message(fmt.Sprintf("The %s guest cleanup failed, please clean up manually: %s", task.Name, err))
+			}
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepCleanup) Cleanup(state multistep.StateBag) {
+}
@@ -0,0 +1,47 @@
+package guest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestStepCleanup_Impl(t *testing.T) {
+	var _ multistep.Step = new(StepCleanup)
+}
+
+func TestStepCleanup(t *testing.T) {
+	state := testState(t)
+	comm := new(packer.MockCommunicator)
+	state.Put("communicator", comm)
+
+	config := &Config{
+		GuestCleanup:       []string{"machine_id", "sysprep"},
+		GuestCleanupDryRun: true,
+	}
+	step := &StepCleanup{Config: config, Comm: testSSHConfig()}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if comm.StartCalled {
+		t.Fatal("a command was run in a dry run")
+	}
+
+	config.GuestCleanupDryRun = false
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	// sysprep is not supported on SSH guests: machine_id ran last.
+	if !strings.Contains(comm.StartCmd.Command, "/etc/machine-id") {
+		t.Fatalf("bad command: %s", comm.StartCmd.Command)
+	}
+
+	// Failing tasks don't fail the build.
+	comm.StartExitStatus = 1
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
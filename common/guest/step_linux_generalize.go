@@ -0,0 +1,49 @@
+package guest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// StepLinuxGeneralize generalizes a Linux guest, as configured with
+// linux_generalize, once provisioned: it runs the generalization operations
+// through the communicator, as root, and fails the build when one fails.
+type StepLinuxGeneralize struct {
+	Config *Config
+}
+
+func (s *StepLinuxGeneralize) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ops := s.Config.GeneralizeOperations()
+	if len(ops) == 0 {
+		return multistep.ActionContinue
+	}
+
+	comm, ok := state.Get("communicator").(packer.Communicator)
+	if !ok {
+		return multistep.ActionContinue
+	}
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Generalizing the guest...")
+	for _, op := range ops {
+		ui.Message(fmt.Sprintf("Removing %s (%s)", op.Description, op.Name))
+		cmd := &packer.RemoteCmd{Command: op.RemoteCommand()}
+		err := cmd.RunWithUi(ctx, comm, ui)
+		if err == nil && cmd.ExitStatus() != 0 {
+			err = fmt.Errorf("the command exited with status %d", cmd.ExitStatus())
+		}
+		if err != nil {
+			err = fmt.Errorf("Error running the %s generalization operation: %s", op.Name, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+	return multistep.ActionContinue
+}
+
+func (s *StepLinuxGeneralize) Cleanup(state multistep.StateBag) {
+}
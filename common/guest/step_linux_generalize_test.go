@@ -0,0 +1,45 @@
+package guest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestStepLinuxGeneralize_Impl(t *testing.T) {
+	var _ multistep.Step = new(StepLinuxGeneralize)
+}
+
+func TestStepLinuxGeneralize(t *testing.T) {
+	state := testState(t)
+	comm := new(packer.MockCommunicator)
+	state.Put("communicator", comm)
+
+	config := new(Config)
+	step := &StepLinuxGeneralize{Config: config}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if comm.StartCalled {
+		t.Fatal("the guest was generalized without linux_generalize")
+	}
+
+	config.LinuxGeneralize = true
+	config.LinuxGeneralizeOperations = []string{"machine_id", "ssh_host_keys"}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	// ssh_host_keys ran last.
+	if !strings.HasPrefix(comm.StartCmd.Command, "sudo sh -c '") || !strings.Contains(comm.StartCmd.Command, "/etc/ssh/ssh_host_") {
+		t.Fatalf("bad command: %s", comm.StartCmd.Command)
+	}
+
+	// Failing operations fail the build.
+	comm.StartExitStatus = 1
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
@@ -0,0 +1,103 @@
+package guest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// StepRotateCredentials rotates the credential of the communicator user,
+// as configured with credential_rotation, once the guest is provisioned.
+// The rotation is reported as CredentialRotation in the generated data.
+//
+// It runs before the guest is cleaned up and generalized: over WinRM, where
+// every command authenticates, the connection switches to the new password.
+type StepRotateCredentials struct {
+	Config *Config
+	Comm   *communicator.Config
+}
+
+func (s *StepRotateCredentials) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Config.CredentialRotationCommand(s.Comm, "") == "" {
+		return multistep.ActionContinue
+	}
+
+	comm, ok := state.Get("communicator").(packer.Communicator)
+	if !ok {
+		return multistep.ActionContinue
+	}
+	ui := state.Get("ui").(packer.Ui)
+
+	if err := s.rotate(ctx, ui, state, comm); err != nil {
+		err = fmt.Errorf("Error rotating the credential of %s: %s", s.Comm.User(), err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	generatedData, ok := state.Get("generated_data").(map[string]interface{})
+	if !ok {
+		generatedData = make(map[string]interface{})
+	}
+	generatedData["CredentialRotation"] = s.Config.CredentialRotation
+	state.Put("generated_data", generatedData)
+	return multistep.ActionContinue
+}
+
+func (s *StepRotateCredentials) rotate(ctx context.Context, ui packer.Ui, state multistep.StateBag, comm packer.Communicator) error {
+	switch s.Config.CredentialRotation {
+	case CredentialRotationPassword:
+		ui.Say(fmt.Sprintf("Replacing the password of %s...", s.Comm.User()))
+	case CredentialRotationRemoveUser:
+		ui.Say(fmt.Sprintf("Removing the user %s...", s.Comm.User()))
+	}
+	if s.Comm.Type == "winrm" && s.Config.CredentialRotation == CredentialRotationPassword {
+		return s.rotateWinRMPassword(ctx, state)
+	}
+
+	cmd := &packer.RemoteCmd{Command: s.Config.CredentialRotationCommand(s.Comm, "")}
+	err := cmd.RunWithUi(ctx, comm, ui)
+	if err == nil && cmd.ExitStatus() != 0 {
+		err = fmt.Errorf("the command exited with status %d", cmd.ExitStatus())
+	}
+	return err
+}
+
+// rotateWinRMPassword sets a new password, generated by Packer, and
+// switches the connection to it. The command runs with the communicator of
+// the connection, which doesn't change it like remote_shell does, so that
+// the password stays hidden from the logs.
+func (s *StepRotateCredentials) rotateWinRMPassword(ctx context.Context, state multistep.StateBag) error {
+	conn := communicator.ConnectionFromState(state)
+	if conn == nil {
+		return fmt.Errorf("there is no connection to switch to the new password")
+	}
+	password, err := newPassword()
+	if err != nil {
+		return err
+	}
+	command := s.Config.CredentialRotationCommand(s.Comm, password)
+	packer.LogSecretFilter.Set(password, command)
+
+	var stderr bytes.Buffer
+	status, err := conn.Exec(ctx, command, nil, &stderr)
+	if err == nil && status != 0 {
+		err = fmt.Errorf("the command exited with status %d: %s", status, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return err
+	}
+	if err := conn.SetPassword(password); err != nil {
+		return fmt.Errorf("switching the connection to the new password: %s", err)
+	}
+	s.Comm.WinRMPassword = password
+	return nil
+}
+
+func (s *StepRotateCredentials) Cleanup(state multistep.StateBag) {
+}
@@ -0,0 +1,95 @@
+package guest
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func testState(t *testing.T) multistep.StateBag {
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	})
+	return state
+}
+
+// testPasswordComm is a communicator authenticating every command with the
+// password.
+type testPasswordComm struct {
+	packer.MockCommunicator
+	password string
+}
+
+func (c *testPasswordComm) SetPassword(password string) error {
+	c.password = password
+	return nil
+}
+
+func TestStepRotateCredentials_Impl(t *testing.T) {
+	var _ multistep.Step = new(StepRotateCredentials)
+}
+
+func TestStepRotateCredentials(t *testing.T) {
+	state := testState(t)
+	comm := new(packer.MockCommunicator)
+	state.Put("communicator", comm)
+	state.Put("generated_data", map[string]interface{}{"ID": "i-123"})
+
+	config := new(Config)
+	step := &StepRotateCredentials{Config: config, Comm: testSSHConfig()}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if comm.StartCalled {
+		t.Fatal("a command was run without credential_rotation")
+	}
+
+	config.CredentialRotation = CredentialRotationRemoveUser
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if !strings.Contains(comm.StartCmd.Command, "userdel") {
+		t.Fatalf("bad command: %s", comm.StartCmd.Command)
+	}
+	generatedData := state.Get("generated_data").(map[string]interface{})
+	if generatedData["CredentialRotation"] != "remove_user" || generatedData["ID"] != "i-123" {
+		t.Fatalf("bad generated data: %#v", generatedData)
+	}
+
+	comm.StartExitStatus = 1
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("a failed rotation should halt: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("no error set")
+	}
+}
+
+func TestStepRotateCredentials_winrmPassword(t *testing.T) {
+	state := testState(t)
+	raw := new(testPasswordComm)
+	state.Put("communicator", raw)
+	state.Put(communicator.ConnectionStateKey, communicator.NewConnection("winrm", raw, raw))
+
+	comm := testWinRMConfig()
+	step := &StepRotateCredentials{
+		Config: &Config{CredentialRotation: CredentialRotationPassword},
+		Comm:   comm,
+	}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v: %v", action, state.Get("error"))
+	}
+	if raw.password == "" || raw.password == "test" {
+		t.Fatalf("the connection didn't switch to the new password: %q", raw.password)
+	}
+	if comm.WinRMPassword != raw.password {
+		t.Fatalf("the configuration didn't switch to the new password: %q", comm.WinRMPassword)
+	}
+}
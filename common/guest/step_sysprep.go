@@ -0,0 +1,127 @@
+package guest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// sysprepPollInterval is how often the status of sysprep is checked.
+var sysprepPollInterval = 10 * time.Second
+
+// StepSysprep generalizes a Windows guest with sysprep, as configured with
+// sysprep_generalize, once provisioned. It uploads the answer file, starts
+// sysprep and waits for the image state of the guest to tell it is
+// generalized, failing with the errors of sysprep when it exits before.
+type StepSysprep struct {
+	Config *Config
+}
+
+func (s *StepSysprep) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if !s.Config.SysprepGeneralize {
+		return multistep.ActionContinue
+	}
+
+	comm, ok := state.Get("communicator").(packer.Communicator)
+	if !ok {
+		return multistep.ActionContinue
+	}
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Generalizing the guest with sysprep...")
+	if err := s.sysprep(ctx, ui, comm); err != nil {
+		err = fmt.Errorf("Error generalizing the guest with sysprep: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	ui.Message("The guest is generalized")
+	return multistep.ActionContinue
+}
+
+func (s *StepSysprep) sysprep(ctx context.Context, ui packer.Ui, comm packer.Communicator) error {
+	if s.Config.SysprepUnattendFile != "" {
+		ui.Message(fmt.Sprintf("Uploading the answer file %s", s.Config.SysprepUnattendFile))
+		f, err := os.Open(s.Config.SysprepUnattendFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := comm.Upload(SysprepUnattendPath, f, nil); err != nil {
+			return fmt.Errorf("uploading the answer file: %s", err)
+		}
+	}
+
+	cmd := &packer.RemoteCmd{Command: s.Config.SysprepCommand()}
+	err := cmd.RunWithUi(ctx, comm, ui)
+	if err == nil && cmd.ExitStatus() != 0 {
+		err = fmt.Errorf("the command exited with status %d", cmd.ExitStatus())
+	}
+	if err != nil {
+		return fmt.Errorf("starting sysprep: %s", err)
+	}
+
+	ui.Message(fmt.Sprintf("Waiting up to %s for sysprep to finish...", s.Config.SysprepTimeout))
+	return WaitForSysprep(ctx, comm, s.Config.SysprepTimeout)
+}
+
+// WaitForSysprep waits up to timeout for sysprep to generalize the guest,
+// following the image state of its registry, and returns the errors of its
+// log when it exits without generalizing it.
+func WaitForSysprep(ctx context.Context, comm packer.Communicator, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("timeout waiting for sysprep to finish")
+			}
+			return ctx.Err()
+		case <-time.After(sysprepPollInterval):
+		}
+
+		status, err := sysprepStatus(ctx, comm)
+		if err != nil {
+			// The guest can be slow to answer while sysprep runs.
+			log.Printf("[WARN] Error checking the status of sysprep: %s", err)
+			continue
+		}
+		log.Printf("[DEBUG] Sysprep status: %#v", status)
+		switch {
+		case status.Done():
+			return nil
+		case status.Failed():
+			if status.Errors != "" {
+				return fmt.Errorf("sysprep exited with the image state %q: %s", status.ImageState, status.Errors)
+			}
+			return fmt.Errorf("sysprep exited with the image state %q", status.ImageState)
+		}
+	}
+}
+
+// sysprepStatus returns the status of sysprep on the guest.
+func sysprepStatus(ctx context.Context, comm packer.Communicator) (*SysprepStatus, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := &packer.RemoteCmd{
+		Command: SysprepStatusCommand,
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	}
+	if err := comm.Start(ctx, cmd); err != nil {
+		return nil, err
+	}
+	if status := cmd.Wait(); status != 0 {
+		return nil, fmt.Errorf("the command exited with status %d: %s", status, stderr.String())
+	}
+	return ParseSysprepStatus(stdout.String()), nil
+}
+
+func (s *StepSysprep) Cleanup(state multistep.StateBag) {
+}
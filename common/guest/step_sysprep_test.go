@@ -0,0 +1,59 @@
+package guest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestStepSysprep_Impl(t *testing.T) {
+	var _ multistep.Step = new(StepSysprep)
+}
+
+func TestStepSysprep(t *testing.T) {
+	defer func(d time.Duration) { sysprepPollInterval = d }(sysprepPollInterval)
+	sysprepPollInterval = time.Millisecond
+
+	state := testState(t)
+	comm := &packer.MockCommunicator{
+		StartStdout: "ImageState=IMAGE_STATE_GENERALIZE_RESEAL_TO_OOBE\nRunning=False\nErrors=\n",
+	}
+	state.Put("communicator", comm)
+
+	config := new(Config)
+	step := &StepSysprep{Config: config}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if comm.StartCalled {
+		t.Fatal("sysprep ran without sysprep_generalize")
+	}
+
+	config.SysprepGeneralize = true
+	config.SysprepTimeout = time.Second
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v: %v", action, state.Get("error"))
+	}
+
+	comm.StartStdout = "ImageState=IMAGE_STATE_COMPLETE\nRunning=False\nErrors=SYSPRP failure\n"
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if err := state.Get("error").(error); !strings.Contains(err.Error(), "SYSPRP failure") {
+		t.Fatalf("bad error: %s", err)
+	}
+
+	state.Remove("error")
+	comm.StartStdout = "ImageState=IMAGE_STATE_COMPLETE\nRunning=True\nErrors=\n"
+	config.SysprepTimeout = 50 * time.Millisecond
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if err := state.Get("error").(error); !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("bad error: %s", err)
+	}
+}
@@ -0,0 +1,112 @@
+package guest
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/masterzen/winrm"
+)
+
+// SysprepUnattendPath is where the sysprep_unattend_file is uploaded on the
+// guest.
+const SysprepUnattendPath = `C:\Windows\Panther\Unattend\unattend.xml`
+
+// SysprepGeneralizedState is the image state of a guest successfully
+// generalized with /oobe.
+const SysprepGeneralizedState = "IMAGE_STATE_GENERALIZE_RESEAL_TO_OOBE"
+
+func (c *Config) prepareSysprep(comm *communicator.Config) (errs []error) {
+	if !c.SysprepGeneralize {
+		return nil
+	}
+	if comm.Type != "winrm" {
+		errs = append(errs, fmt.Errorf("sysprep_generalize requires the winrm communicator"))
+	}
+	for _, name := range c.GuestCleanup {
+		if name == "sysprep" {
+			errs = append(errs, fmt.Errorf("sysprep_generalize can't be combined with the sysprep guest cleanup task"))
+		}
+	}
+	if c.SysprepUnattendFile != "" {
+		if _, err := os.Stat(c.SysprepUnattendFile); err != nil {
+			errs = append(errs, fmt.Errorf("sysprep_unattend_file is invalid: %s", err))
+		}
+	}
+	if c.SysprepTimeout == 0 {
+		c.SysprepTimeout = 15 * time.Minute
+	}
+	return errs
+}
+
+// SysprepCommand returns the command starting sysprep on the guest, without
+// waiting for it.
+func (c *Config) SysprepCommand() string {
+	args := []string{"'/generalize'", "'/oobe'", "'/quiet'", "'/quit'"}
+	if c.SysprepModeVM {
+		args = append(args, "'/mode:vm'")
+	}
+	if c.SysprepUnattendFile != "" {
+		args = append(args, fmt.Sprintf("'/unattend:%s'", SysprepUnattendPath))
+	}
+	return winrm.Powershell(fmt.Sprintf(`$ErrorActionPreference = 'Stop'
+Remove-Item -Path "$env:SystemRoot\System32\Sysprep\Panther\setuperr.log" -ErrorAction SilentlyContinue
+Start-Process -FilePath "$env:SystemRoot\System32\Sysprep\Sysprep.exe" -ArgumentList %s | Out-Null`,
+		strings.Join(args, ", ")))
+}
+
+// SysprepStatusCommand is the command outputting the status of sysprep, to
+// be parsed by ParseSysprepStatus.
+var SysprepStatusCommand = winrm.Powershell(`$state = (Get-ItemProperty -Path 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\Setup\State' -ErrorAction SilentlyContinue).ImageState
+$running = [bool](Get-Process -Name sysprep -ErrorAction SilentlyContinue)
+$log = "$env:SystemRoot\System32\Sysprep\Panther\setuperr.log"
+$errors = ''
+if (Test-Path $log) { $errors = (Get-Content -Path $log -Tail 5) -join ' | ' }
+"ImageState=$state"
+"Running=$running"
+"Errors=$errors"`)
+
+// SysprepStatus is the status of sysprep on the guest.
+type SysprepStatus struct {
+	// ImageState is the image state of the registry, which is
+	// SysprepGeneralizedState once the guest is generalized.
+	ImageState string
+	// Running tells whether sysprep is still running.
+	Running bool
+	// Errors are the last lines of the error log of sysprep.
+	Errors string
+}
+
+// Done tells whether sysprep succeeded.
+func (s *SysprepStatus) Done() bool {
+	return s.ImageState == SysprepGeneralizedState
+}
+
+// Failed tells whether sysprep exited without generalizing the guest.
+func (s *SysprepStatus) Failed() bool {
+	return !s.Running && !s.Done()
+}
+
+// ParseSysprepStatus parses the output of SysprepStatusCommand.
+func ParseSysprepStatus(out string) *SysprepStatus {
+	status := new(SysprepStatus)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		kv := strings.SplitN(strings.TrimSpace(scanner.Text()), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "ImageState":
+			status.ImageState = kv[1]
+		case "Running":
+			status.Running = strings.EqualFold(kv[1], "true")
+		case "Errors":
+			status.Errors = kv[1]
+		}
+	}
+	return status
+}
@@ -0,0 +1,268 @@
+// Package inventory describes what a machine image is made of: its packages,
+// the hashes of its files and its listening ports, as captured by the
+// inventory provisioner. It reports the changes between the inventories of
+// two images, to review an image before promoting it.
+package inventory
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Inventory is what a machine image is made of.
+type Inventory struct {
+	// Build is the name of the build of the image.
+	Build    string    `json:"build,omitempty"`
+	Captured time.Time `json:"captured"`
+	// Packages are the versions of the installed packages, by name.
+	Packages map[string]string `json:"packages,omitempty"`
+	// Files are the SHA256 hashes of the files, by path.
+	Files map[string]string `json:"files,omitempty"`
+	// Ports are the listening ports, like tcp/0.0.0.0:22, sorted.
+	Ports []string `json:"ports,omitempty"`
+}
+
+// Read reads the inventory in the JSON file at path.
+func Read(path string) (*Inventory, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	i := &Inventory{}
+	if err := json.Unmarshal(data, i); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return i, nil
+}
+
+// Write writes i to the JSON file at path.
+func (i *Inventory) Write(path string) error {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Script prints the inventory of a Linux or Unix machine for Parse: its
+// packages, the hashes of the files of the directories passed as arguments,
+// and its listening ports.
+const Script = `#!/bin/sh
+echo "== packages"
+if command -v dpkg-query >/dev/null 2>&1; then
+  dpkg-query -W -f '${Package}\t${Version}\n'
+elif command -v rpm >/dev/null 2>&1; then
+  rpm -qa --qf '%{NAME}\t%{VERSION}-%{RELEASE}\n'
+elif command -v pacman >/dev/null 2>&1; then
+  pacman -Q | tr ' ' '\t'
+elif command -v apk >/dev/null 2>&1; then
+  apk info -v 2>/dev/null | sed 's/-\([0-9][^-]*-r[0-9]*\)$/\t\1/'
+fi
+echo "== files"
+for dir in "$@"; do
+  find "$dir" -xdev -type f -exec sha256sum {} + 2>/dev/null
+done
+echo "== ports"
+if command -v ss >/dev/null 2>&1; then
+  ss -ltun
+elif command -v netstat >/dev/null 2>&1; then
+  netstat -ltun
+fi
+exit 0
+`
+
+// Parse reads the inventory printed by Script.
+func Parse(r io.Reader) (*Inventory, error) {
+	i := &Inventory{
+		Packages: map[string]string{},
+		Files:    map[string]string{},
+	}
+	ports := map[string]bool{}
+	section := ""
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "== ") {
+			section = strings.TrimPrefix(line, "== ")
+			continue
+		}
+		switch section {
+		case "packages":
+			parts := strings.SplitN(line, "\t", 2)
+			if len(parts) == 2 && parts[0] != "" {
+				i.Packages[parts[0]] = parts[1]
+			}
+		case "files":
+			parts := strings.SplitN(line, "  ", 2)
+			if len(parts) == 2 {
+				i.Files[parts[1]] = parts[0]
+			}
+		case "ports":
+			if port := parsePort(line); port != "" {
+				ports[port] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if section == "" {
+		return nil, fmt.Errorf("no inventory in the output")
+	}
+	for port := range ports {
+		i.Ports = append(i.Ports, port)
+	}
+	sort.Strings(i.Ports)
+	return i, nil
+}
+
+// parsePort returns the port of a line of ss -ltun or netstat -ltun, like
+// tcp/0.0.0.0:22, or "" when the line isn't a socket.
+func parsePort(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) < 5 {
+		return ""
+	}
+	proto := strings.TrimSuffix(fields[0], "6")
+	if proto != "tcp" && proto != "udp" {
+		return ""
+	}
+	// ss prints the state of the socket before its queues, netstat doesn't.
+	local := fields[4]
+	if _, err := fmt.Sscan(fields[1], new(int)); err == nil {
+		local = fields[3]
+	}
+	sep := strings.LastIndex(local, ":")
+	if sep < 0 {
+		return ""
+	}
+	host, port := local[:sep], local[sep+1:]
+	host = strings.Trim(host, "[]")
+	if iface := strings.Index(host, "%"); iface >= 0 {
+		host = host[:iface]
+	}
+	return proto + "/" + net.JoinHostPort(host, port)
+}
+
+// Change is the change of a package, a file or a port.
+type Change struct {
+	Name string `json:"name"`
+	// Old and New are the versions of a package or the hashes of a file.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// Changes are the changes of the packages, the files or the ports of an
+// image, sorted by name.
+type Changes struct {
+	Added   []Change `json:"added"`
+	Removed []Change `json:"removed"`
+	Changed []Change `json:"changed"`
+}
+
+// Len returns the number of changes.
+func (c *Changes) Len() int {
+	return len(c.Added) + len(c.Removed) + len(c.Changed)
+}
+
+// Report is the change report between the inventories of two images.
+type Report struct {
+	Old      string  `json:"old,omitempty"`
+	New      string  `json:"new,omitempty"`
+	Packages Changes `json:"packages"`
+	Files    Changes `json:"files"`
+	Ports    Changes `json:"ports"`
+}
+
+// Diff returns the changes from the inventory old to the inventory new.
+func Diff(old, new *Inventory) *Report {
+	ports := func(i *Inventory) map[string]string {
+		m := map[string]string{}
+		for _, p := range i.Ports {
+			m[p] = ""
+		}
+		return m
+	}
+	return &Report{
+		Old:      old.Build,
+		New:      new.Build,
+		Packages: diffMaps(old.Packages, new.Packages),
+		Files:    diffMaps(old.Files, new.Files),
+		Ports:    diffMaps(ports(old), ports(new)),
+	}
+}
+
+func diffMaps(old, new map[string]string) Changes {
+	c := Changes{Added: []Change{}, Removed: []Change{}, Changed: []Change{}}
+	for name, o := range old {
+		n, ok := new[name]
+		switch {
+		case !ok:
+			c.Removed = append(c.Removed, Change{Name: name, Old: o})
+		case n != o:
+			c.Changed = append(c.Changed, Change{Name: name, Old: o, New: n})
+		}
+	}
+	for name, n := range new {
+		if _, ok := old[name]; !ok {
+			c.Added = append(c.Added, Change{Name: name, New: n})
+		}
+	}
+	for _, changes := range [][]Change{c.Added, c.Removed, c.Changed} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	}
+	return c
+}
+
+// Empty tells whether the images are the same.
+func (r *Report) Empty() bool {
+	return r.Packages.Len()+r.Files.Len()+r.Ports.Len() == 0
+}
+
+// String returns the report for humans, one change per line.
+func (r *Report) String() string {
+	var b strings.Builder
+	if r.Old != "" || r.New != "" {
+		fmt.Fprintf(&b, "Changes from %s to %s:\n", r.Old, r.New)
+	}
+	section := func(title string, c Changes, showValues bool) {
+		if c.Len() == 0 {
+			fmt.Fprintf(&b, "%s: no changes\n", title)
+			return
+		}
+		fmt.Fprintf(&b, "%s: %d added, %d removed, %d changed\n", title, len(c.Added), len(c.Removed), len(c.Changed))
+		for _, a := range c.Added {
+			if showValues {
+				fmt.Fprintf(&b, "  + %s %s\n", a.Name, a.New)
+			} else {
+				fmt.Fprintf(&b, "  + %s\n", a.Name)
+			}
+		}
+		for _, r := range c.Removed {
+			if showValues {
+				fmt.Fprintf(&b, "  - %s %s\n", r.Name, r.Old)
+			} else {
+				fmt.Fprintf(&b, "  - %s\n", r.Name)
+			}
+		}
+		for _, ch := range c.Changed {
+			if showValues {
+				fmt.Fprintf(&b, "  ~ %s %s => %s\n", ch.Name, ch.Old, ch.New)
+			} else {
+				fmt.Fprintf(&b, "  ~ %s\n", ch.Name)
+			}
+		}
+	}
+	section("Packages", r.Packages, true)
+	section("Files", r.Files, false)
+	section("Ports", r.Ports, false)
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,109 @@
+package inventory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testOutput = `== packages
+openssl	1.1.1f-1ubuntu2
+nginx	1.18.0-0ubuntu1
+== files
+9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08  /etc/nginx/nginx.conf
+60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752  /etc/ssh/sshd config
+== ports
+Netid State  Recv-Q Send-Q Local Address:Port  Peer Address:Port Process
+udp   UNCONN 0      0      127.0.0.53%lo:53         0.0.0.0:*
+tcp   LISTEN 0      128          0.0.0.0:22         0.0.0.0:*
+tcp   LISTEN 0      128             [::]:22            [::]:*
+Active Internet connections (only servers)
+Proto Recv-Q Send-Q Local Address           Foreign Address         State
+tcp        0      0 0.0.0.0:80              0.0.0.0:*               LISTEN
+tcp6       0      0 :::80                   :::*                    LISTEN
+`
+
+func TestParse(t *testing.T) {
+	i, err := Parse(strings.NewReader(testOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Inventory{
+		Packages: map[string]string{"openssl": "1.1.1f-1ubuntu2", "nginx": "1.18.0-0ubuntu1"},
+		Files: map[string]string{
+			"/etc/nginx/nginx.conf": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			"/etc/ssh/sshd config":  "60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752",
+		},
+		Ports: []string{"tcp/0.0.0.0:22", "tcp/0.0.0.0:80", "tcp/[::]:22", "tcp/[::]:80", "udp/127.0.0.53:53"},
+	}
+	if !reflect.DeepEqual(i, expected) {
+		t.Fatalf("unexpected inventory %#v, expected %#v", i, expected)
+	}
+
+	if _, err := Parse(strings.NewReader("sh: not found\n")); err == nil {
+		t.Fatal("should error without an inventory")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := &Inventory{
+		Build:    "old",
+		Packages: map[string]string{"openssl": "1.1.1f-1ubuntu2", "apache2": "2.4.41"},
+		Files:    map[string]string{"/etc/ssh/sshd_config": "a", "/etc/motd": "b"},
+		Ports:    []string{"tcp/0.0.0.0:22", "tcp/0.0.0.0:8080"},
+	}
+	new := &Inventory{
+		Build:    "new",
+		Packages: map[string]string{"openssl": "1.1.1f-1ubuntu2.1", "nginx": "1.18.0"},
+		Files:    map[string]string{"/etc/ssh/sshd_config": "c", "/etc/motd": "b", "/etc/nginx/nginx.conf": "d"},
+		Ports:    []string{"tcp/0.0.0.0:22", "tcp/0.0.0.0:80"},
+	}
+
+	r := Diff(old, new)
+	if r.Empty() {
+		t.Fatal("the report shouldn't be empty")
+	}
+	expected := `Changes from old to new:
+Packages: 1 added, 1 removed, 1 changed
+  + nginx 1.18.0
+  - apache2 2.4.41
+  ~ openssl 1.1.1f-1ubuntu2 => 1.1.1f-1ubuntu2.1
+Files: 1 added, 0 removed, 1 changed
+  + /etc/nginx/nginx.conf
+  ~ /etc/ssh/sshd_config
+Ports: 1 added, 1 removed, 0 changed
+  + tcp/0.0.0.0:80
+  - tcp/0.0.0.0:8080`
+	if r.String() != expected {
+		t.Fatalf("unexpected report:\n%s\nexpected:\n%s", r, expected)
+	}
+
+	if r := Diff(old, old); !r.Empty() {
+		t.Fatalf("unexpected changes:\n%s", r)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "inventory.json")
+	i := &Inventory{Build: "qemu.base", Packages: map[string]string{"nginx": "1.18.0"}, Ports: []string{"tcp/0.0.0.0:22"}}
+	if err := i.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.Captured = i.Captured
+	if !reflect.DeepEqual(got, i) {
+		t.Fatalf("unexpected inventory %#v, expected %#v", got, i)
+	}
+}
@@ -0,0 +1,108 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// StateJournal is the key of the journal of the build in the state bag, when
+// the build has one.
+const StateJournal = "journal"
+
+// ResumableStep is a step that can resume from what it saved in the journal
+// of a build whose packer process died, like re-attaching to the instance it
+// created, rather than running again. Resume is only called when the step
+// completed before the build died.
+type ResumableStep interface {
+	multistep.Step
+
+	Resume(context.Context, multistep.StateBag) multistep.StepAction
+}
+
+// JournalSet saves value under key in the journal of the build, for a step to
+// resume from it. It does nothing when the build has no journal; failing to
+// save only gets logged, it should never fail a build.
+func JournalSet(state multistep.StateBag, key, value string) {
+	j, ok := state.GetOk(StateJournal)
+	if !ok {
+		return
+	}
+	if err := j.(*packer.Journal).Set(key, value); err != nil {
+		log.Printf("[WARN] journal: could not save %s: %s", key, err)
+	}
+}
+
+// JournalGet returns the value saved under key in the journal of the build,
+// or "".
+func JournalGet(state multistep.StateBag, key string) string {
+	j, ok := state.GetOk(StateJournal)
+	if !ok {
+		return ""
+	}
+	return j.(*packer.Journal).Get(key)
+}
+
+// loadJournal returns the journal of the build configured by config, nil
+// when it has none.
+func loadJournal(config PackerConfig) *packer.Journal {
+	if config.PackerJournal == "" {
+		return nil
+	}
+	j, err := packer.LoadJournal(config.PackerJournal)
+	if err != nil {
+		// Builds not started by the build command have no journal.
+		log.Printf("No journal for build %s: %s", config.PackerBuildName, err)
+		return nil
+	}
+	return j
+}
+
+// journalStep records in the journal of the build that the step completed,
+// and resumes it from the journal when resuming the build.
+type journalStep struct {
+	step    multistep.Step
+	name    string
+	journal *packer.Journal
+	resume  bool
+}
+
+func newJournalStep(step multistep.Step, i int, journal *packer.Journal, resume bool) journalStep {
+	return journalStep{
+		step: step,
+		// The index tells apart the steps of the same type.
+		name:    fmt.Sprintf("%d-%s", i, typeName(step)),
+		journal: journal,
+		resume:  resume,
+	}
+}
+
+func (s journalStep) InnerStepName() string {
+	return typeName(s.step)
+}
+
+func (s journalStep) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	state.Put(StateJournal, s.journal)
+
+	if s.resume && s.journal.Completed(s.name) {
+		if step, ok := s.step.(ResumableStep); ok {
+			log.Printf("Resuming step %s from the journal", s.name)
+			return step.Resume(ctx, state)
+		}
+	}
+
+	action := s.step.Run(ctx, state)
+	if action == multistep.ActionContinue {
+		if err := s.journal.Complete(s.name); err != nil {
+			log.Printf("[WARN] journal: could not record step %s: %s", s.name, err)
+		}
+	}
+	return action
+}
+
+func (s journalStep) Cleanup(state multistep.StateBag) {
+	s.step.Cleanup(state)
+}
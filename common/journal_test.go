@@ -0,0 +1,76 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+type journalTestStep struct {
+	runs, resumes int
+}
+
+func (s *journalTestStep) Run(_ context.Context, state multistep.StateBag) multistep.StepAction {
+	s.runs++
+	JournalSet(state, "value", "saved")
+	return multistep.ActionContinue
+}
+
+func (s *journalTestStep) Cleanup(multistep.StateBag) {}
+
+type resumableTestStep struct {
+	journalTestStep
+	resumed string
+}
+
+func (s *resumableTestStep) Resume(_ context.Context, state multistep.StateBag) multistep.StepAction {
+	s.resumes++
+	s.resumed = JournalGet(state, "value")
+	return multistep.ActionContinue
+}
+
+func TestRunner_journal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	journal := &packer.Journal{Path: filepath.Join(dir, "build.json")}
+	if err := journal.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	plain := new(journalTestStep)
+	resumable := new(resumableTestStep)
+	run := func(resume bool) {
+		state := new(multistep.BasicStateBag)
+		ui := &packer.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)}
+		state.Put("ui", ui)
+		config := PackerConfig{PackerJournal: journal.Path, PackerResume: resume}
+		NewRunner([]multistep.Step{resumable, plain}, config, ui).Run(context.Background(), state)
+	}
+
+	run(false)
+	loaded, err := packer.LoadJournal(journal.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Completed("0-resumableTestStep") || !loaded.Completed("1-journalTestStep") {
+		t.Fatalf("the steps should be completed: %#v", loaded.Steps)
+	}
+
+	run(true)
+	if resumable.runs != 1 || resumable.resumes != 1 || resumable.resumed != "saved" {
+		t.Fatalf("the resumable step should resume: %#v", resumable)
+	}
+	if plain.runs != 2 {
+		t.Fatalf("the other steps should run again: %#v", plain)
+	}
+}
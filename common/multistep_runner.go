@@ -5,16 +5,29 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/otel"
 )
 
 func newRunner(steps []multistep.Step, config PackerConfig, ui packer.Ui) (multistep.Runner, multistep.DebugPauseFn) {
+	// Journal the steps themselves, so that resumable steps can be told
+	// apart.
+	if journal := loadJournal(config); journal != nil {
+		for i, step := range steps {
+			steps[i] = newJournalStep(step, i, journal, config.PackerResume)
+		}
+	}
+
 	switch config.PackerOnError {
 	case "", "cleanup":
+		for i, step := range steps {
+			steps[i] = cleanupStep{step, ui}
+		}
 	case "abort":
 		for i, step := range steps {
 			steps[i] = abortStep{
@@ -37,11 +50,21 @@ func newRunner(steps []multistep.Step, config PackerConfig, ui packer.Ui) (multi
 		}
 	}
 
+	for i, step := range steps {
+		steps[i] = instrumentedStep{step, ui}
+	}
+
+	if len(config.PackerBreakpoints) > 0 {
+		for i, step := range steps {
+			steps[i] = breakpointStep{step, config.PackerBreakpoints, ui}
+		}
+	}
+
 	if config.PackerDebug {
 		pauseFn := MultistepDebugFn(ui)
-		return &multistep.DebugRunner{Steps: steps, PauseFn: pauseFn}, pauseFn
+		return &multistep.DebugRunner{Steps: steps, PauseFn: pauseFn, CleanupTimeout: CleanupTimeout()}, pauseFn
 	} else {
-		return &multistep.BasicRunner{Steps: steps}, nil
+		return &multistep.BasicRunner{Steps: steps, CleanupTimeout: CleanupTimeout()}, nil
 	}
 }
 
@@ -68,6 +91,99 @@ func typeName(i interface{}) string {
 	return reflect.Indirect(reflect.ValueOf(i)).Type().Name()
 }
 
+// innerStepName returns the type name of step, or of the step it wraps.
+func innerStepName(step multistep.Step) string {
+	if inner, ok := step.(interface{ InnerStepName() string }); ok {
+		return inner.InnerStepName()
+	}
+	return typeName(step)
+}
+
+// instrumentedStep tells when a step starts and finishes through
+// machine-readable messages, and traces it.
+type instrumentedStep struct {
+	step multistep.Step
+	ui   packer.Ui
+}
+
+func (s instrumentedStep) InnerStepName() string {
+	if inner, ok := s.step.(interface{ InnerStepName() string }); ok {
+		return inner.InnerStepName()
+	}
+	return typeName(s.step)
+}
+
+func (s instrumentedStep) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	name := s.InnerStepName()
+	s.ui.Machine(packer.EventStepStarted, name)
+	ctx, span := otel.StartSpan(ctx, "step "+name, otel.String("packer.step", name))
+	start := time.Now()
+
+	action := s.step.Run(ctx, state)
+
+	result := "continue"
+	var err error
+	if action == multistep.ActionHalt {
+		result = "halt"
+		if e, ok := state.GetOk("error"); ok {
+			err, _ = e.(error)
+		}
+	}
+	span.SetAttributes(otel.String("packer.step.action", result))
+	span.End(err)
+	duration := time.Since(start)
+	otel.Record("packer.step.duration", "s", duration.Seconds(), otel.String("packer.step", name))
+	s.ui.Machine(packer.EventStepFinished, name, result,
+		strconv.FormatFloat(duration.Seconds(), 'f', 3, 64))
+	return action
+}
+
+func (s instrumentedStep) Cleanup(state multistep.StateBag) {
+	s.step.Cleanup(state)
+}
+
+// breakpointStep pauses before the step when it matches a breakpoint, so
+// that the operator can inspect the state before continuing.
+type breakpointStep struct {
+	step        multistep.Step
+	breakpoints []string
+	ui          packer.Ui
+}
+
+func (s breakpointStep) InnerStepName() string {
+	if inner, ok := s.step.(interface{ InnerStepName() string }); ok {
+		return inner.InnerStepName()
+	}
+	return typeName(s.step)
+}
+
+func (s breakpointStep) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	name := s.InnerStepName()
+	if !packer.MatchBreakpoint(s.breakpoints, name) {
+		return s.step.Run(ctx, state)
+	}
+
+	describe := func() []string {
+		if bag, ok := state.(interface{ Values() map[string]interface{} }); ok {
+			return packer.DescribeState(bag.Values())
+		}
+		return []string{"<not available>"}
+	}
+	cancelled := func() bool {
+		_, ok := state.GetOk(multistep.StateCancelled)
+		return ok || ctx.Err() != nil
+	}
+	if packer.Breakpoint(s.ui, fmt.Sprintf("step '%s'", name), describe, cancelled) == packer.BreakpointAbort {
+		state.Put("error", fmt.Errorf("Build aborted at the breakpoint before step '%s'", name))
+		return multistep.ActionHalt
+	}
+	return s.step.Run(ctx, state)
+}
+
+func (s breakpointStep) Cleanup(state multistep.StateBag) {
+	s.step.Cleanup(state)
+}
+
 type abortStep struct {
 	step        multistep.Step
 	cleanupProv bool
@@ -75,7 +191,7 @@ type abortStep struct {
 }
 
 func (s abortStep) InnerStepName() string {
-	return typeName(s.step)
+	return innerStepName(s.step)
 }
 
 func (s abortStep) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -88,20 +204,45 @@ func (s abortStep) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	shouldCleanup := handleAbortsAndInterupts(state, s.ui, typeName(s.step))
+	shouldCleanup := handleAbortsAndInterupts(state, s.ui, s.InnerStepName())
 	if !shouldCleanup {
 		return
 	}
 	s.step.Cleanup(state)
 }
 
+// cleanupStep always cleans up, unless a provisioner asked to abort the
+// build.
+type cleanupStep struct {
+	step multistep.Step
+	ui   packer.Ui
+}
+
+func (s cleanupStep) InnerStepName() string {
+	return innerStepName(s.step)
+}
+
+func (s cleanupStep) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	return s.step.Run(ctx, state)
+}
+
+func (s cleanupStep) Cleanup(state multistep.StateBag) {
+	if _, ok := state.GetOk("aborted"); ok {
+		shouldCleanup := handleAbortsAndInterupts(state, s.ui, s.InnerStepName())
+		if !shouldCleanup {
+			return
+		}
+	}
+	s.step.Cleanup(state)
+}
+
 type askStep struct {
 	step multistep.Step
 	ui   packer.Ui
 }
 
 func (s askStep) InnerStepName() string {
-	return typeName(s.step)
+	return innerStepName(s.step)
 }
 
 func (s askStep) Run(ctx context.Context, state multistep.StateBag) (action multistep.StepAction) {
@@ -117,7 +258,7 @@ func (s askStep) Run(ctx context.Context, state multistep.StateBag) (action mult
 			s.ui.Error(fmt.Sprintf("%s", err))
 		}
 
-		switch ask(s.ui, typeName(s.step), state) {
+		switch ask(s.ui, s.InnerStepName(), state) {
 		case askCleanup:
 			return
 		case askAbort:
@@ -131,7 +272,7 @@ func (s askStep) Run(ctx context.Context, state multistep.StateBag) (action mult
 
 func (s askStep) Cleanup(state multistep.StateBag) {
 	if _, ok := state.GetOk("aborted"); ok {
-		shouldCleanup := handleAbortsAndInterupts(state, s.ui, typeName(s.step))
+		shouldCleanup := handleAbortsAndInterupts(state, s.ui, s.InnerStepName())
 		if !shouldCleanup {
 			return
 		}
@@ -0,0 +1,138 @@
+package naming
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Charset describes the names allowed by a platform: the characters they can
+// be made of and their length.
+type Charset struct {
+	// Platform is the name of the platform, used in the errors.
+	Platform string
+	// Allowed tells whether a character is allowed in the names.
+	Allowed func(b byte) bool
+	// Lower tells whether the names are lower case.
+	Lower bool
+	// MaxLength is the maximum length of the names, 0 when unlimited.
+	MaxLength int
+	// TrimRight are the characters the names can't end with.
+	TrimRight string
+	// Path tells whether the names are file paths, whose collision suffixes
+	// go before the extension of the file.
+	Path bool
+}
+
+func isalphanumeric(b byte) bool {
+	return ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+func isloweralphanumeric(b byte) bool {
+	return ('0' <= b && b <= '9') || ('a' <= b && b <= 'z')
+}
+
+// The names of the platforms.
+var (
+	// AMI names, see the Name parameter of
+	// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateImage.html
+	Amazon = &Charset{
+		Platform: "AMI",
+		Allowed: func(b byte) bool {
+			return isalphanumeric(b) || strings.IndexByte("()[] ./-'@_", b) != -1
+		},
+		MaxLength: 128,
+	}
+
+	// Google Compute and Yandex image names.
+	Google = &Charset{
+		Platform:  "image",
+		Allowed:   isloweralphanumeric,
+		Lower:     true,
+		MaxLength: 63,
+	}
+
+	// Azure managed image names.
+	Azure = &Charset{
+		Platform: "managed image",
+		Allowed: func(b byte) bool {
+			return isalphanumeric(b) || b == '.' || b == '_' || b == '-'
+		},
+		MaxLength: 80,
+		TrimRight: "-_.",
+	}
+
+	// Output files, like Vagrant boxes and archives: any character but the
+	// control characters and the ones Windows doesn't allow in file names.
+	// The directories of the paths aren't checked.
+	File = &Charset{
+		Platform: "output file",
+		Allowed: func(b byte) bool {
+			return b >= ' ' && b != 0x7f && strings.IndexByte(`\:*?"<>|`, b) == -1
+		},
+		Path: true,
+	}
+)
+
+// Clean replaces the characters of s that aren't allowed with "-", lower
+// cases it when the names are lower case, and trims the characters the
+// names can't end with. The length of s is left as is, and only the file
+// name of a path is cleaned.
+func (c *Charset) Clean(s string) string {
+	if c.Path {
+		dir, file := filepath.Split(s)
+		return dir + (&Charset{Allowed: c.Allowed, Lower: c.Lower, TrimRight: c.TrimRight}).Clean(file)
+	}
+	if c.Lower {
+		s = strings.ToLower(s)
+	}
+	b := []byte(s)
+	for i := range b {
+		if !c.Allowed(b[i]) {
+			b[i] = '-'
+		}
+	}
+	return string(bytes.TrimRight(b, c.TrimRight))
+}
+
+// Check returns an error when s isn't a valid name.
+func (c *Charset) Check(s string) error {
+	if s == "" {
+		return fmt.Errorf("the %s name is empty", c.Platform)
+	}
+	if c.MaxLength > 0 && len(s) > c.MaxLength {
+		return fmt.Errorf("the %s name %q is longer than %d characters", c.Platform, s, c.MaxLength)
+	}
+	if c.Clean(s) != s {
+		return fmt.Errorf("the %s name %q contains characters that aren't allowed", c.Platform, s)
+	}
+	return nil
+}
+
+// truncate returns s cut to the maximum length, minus room characters.
+func (c *Charset) truncate(s string, room int) string {
+	if c.MaxLength > 0 && len(s)+room > c.MaxLength {
+		s = s[:c.MaxLength-room]
+		s = strings.TrimRight(s, c.TrimRight)
+	}
+	return s
+}
+
+// withSuffix returns name with suffix, before the extension of the file when
+// the names are file paths, truncating name so that the result fits.
+func (c *Charset) withSuffix(name, suffix string) string {
+	ext := ""
+	if c.Path {
+		ext = filepath.Ext(name)
+		if strings.HasSuffix(strings.TrimSuffix(name, ext), ".tar") {
+			ext = ".tar" + ext
+		}
+		if filepath.Base(name) == ext {
+			// A dot file has no extension.
+			ext = ""
+		}
+	}
+	base := c.truncate(strings.TrimSuffix(name, ext), len(suffix)+len(ext))
+	return base + suffix + ext
+}
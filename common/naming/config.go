@@ -0,0 +1,158 @@
+//go:generate struct-markdown
+
+// Package naming is the naming policy of the artifacts, like AMIs, images,
+// output files and Vagrant boxes: the names are made from a template with the
+// metadata of the build, cleaned to the characters allowed by the platform,
+// and made unique with a suffix when an artifact with the name exists.
+package naming
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/hashicorp/packer/common/random"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// The values of name_collision.
+const (
+	// CollisionCounter appends -2, -3 and so on to the name.
+	CollisionCounter = "counter"
+	// CollisionTimestamp appends the UNIX timestamp of the build to the name.
+	CollisionTimestamp = "timestamp"
+	// CollisionRandom appends 8 random lower case alphanumeric characters to
+	// the name.
+	CollisionRandom = "random"
+)
+
+// maxCollisionTries is how many names are tried before giving up.
+const maxCollisionTries = 100
+
+// Config is the naming policy of the artifact of a builder or a
+// post-processor.
+type Config struct {
+	// A template of the name of the artifact, wrapping the name configured
+	// with `{{ .Name }}`, so that the artifacts of several builds or builders
+	// follow the same convention. The template can use the name and type of
+	// the build with `{{ .BuildName }}` and `{{ .BuilderType }}`, and the
+	// template functions, like `{{ .Name }}-{{ .BuildName }}-{{ timestamp }}`.
+	// Defaults to the name configured.
+	NamePattern string `mapstructure:"name_pattern" required:"false"`
+	// Replace the characters of the name not allowed by the platform with `-`,
+	// lower case it when the platform wants lower case names, and cut it to
+	// the maximum length of the platform, instead of failing the build.
+	NameClean bool `mapstructure:"name_clean" required:"false"`
+	// How to name the artifact when an artifact with its name already exists,
+	// instead of failing or handling the existing artifact as set with
+	// `-force`, `-replace` or `-skip-if-exists`:
+	// `counter` appends `-2`, `-3` and so on to the name, `timestamp` appends
+	// the UNIX timestamp of the build, and `random` appends 8 random lower
+	// case alphanumeric characters. The suffix goes before the extension of
+	// output files. Defaults to none.
+	NameCollision string `mapstructure:"name_collision" required:"false"`
+}
+
+func (c *Config) Prepare() []error {
+	var errs []error
+
+	switch c.NameCollision {
+	case "", CollisionCounter, CollisionTimestamp, CollisionRandom:
+	default:
+		errs = append(errs, fmt.Errorf("name_collision must be one of %s, %s or %s",
+			CollisionCounter, CollisionTimestamp, CollisionRandom))
+	}
+
+	return errs
+}
+
+// patternData is the data of name_pattern.
+type patternData struct {
+	Name        string
+	BuildName   string
+	BuilderType string
+}
+
+// Name returns the name of the artifact made from name, the name configured,
+// with name_pattern, and cleaned with charset when name_clean is set. The
+// name is left for the caller to check, so that it reports the errors the
+// way it already does.
+func (c *Config) Name(ctx *interpolate.Context, name string, charset *Charset) (string, error) {
+	if c.NamePattern != "" {
+		var pctx interpolate.Context
+		if ctx != nil {
+			pctx = *ctx
+		}
+		pctx.Data = &patternData{
+			Name:        name,
+			BuildName:   pctx.BuildName,
+			BuilderType: pctx.BuildType,
+		}
+		var err error
+		name, err = interpolate.Render(c.NamePattern, &pctx)
+		if err != nil {
+			return "", fmt.Errorf("Error rendering name_pattern: %s", err)
+		}
+	}
+	if c.NameClean {
+		name = charset.truncate(charset.Clean(name), 0)
+	}
+	return name, nil
+}
+
+// Unique returns name, or name with the suffix of name_collision when exists
+// says an artifact with the name exists. It returns name as is when
+// name_collision isn't set.
+func (c *Config) Unique(name string, charset *Charset, exists func(name string) (bool, error)) (string, error) {
+	if c.NameCollision == "" {
+		return name, nil
+	}
+
+	candidate := name
+	for i := 1; i <= maxCollisionTries; i++ {
+		found, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			if candidate != name {
+				log.Printf("[INFO] %s %q exists, naming the artifact %q", charset.Platform, name, candidate)
+			}
+			return candidate, nil
+		}
+
+		switch c.NameCollision {
+		case CollisionCounter:
+			candidate = charset.withSuffix(name, "-"+strconv.Itoa(i+1))
+		case CollisionTimestamp:
+			if i > 1 {
+				return "", fmt.Errorf("%s %q exists", charset.Platform, candidate)
+			}
+			candidate = charset.withSuffix(name, "-"+strconv.FormatInt(interpolate.InitTime.Unix(), 10))
+		case CollisionRandom:
+			candidate = charset.withSuffix(name, "-"+random.AlphaNumLower(8))
+		}
+	}
+	return "", fmt.Errorf("no unique %s name found for %q after %d tries", charset.Platform, name, maxCollisionTries)
+}
+
+// OutputFile returns the path of the output file made from path, the path
+// configured, with Name, and made unique with the suffix of name_collision
+// when a file exists at the path. The path isn't checked, the file names
+// allowed depend on the file system.
+func (c *Config) OutputFile(ctx *interpolate.Context, path string) (string, error) {
+	path, err := c.Name(ctx, path, File)
+	if err != nil {
+		return "", err
+	}
+	return c.Unique(path, File, fileExists)
+}
+
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
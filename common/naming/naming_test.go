@@ -0,0 +1,165 @@
+package naming
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+func TestCharset_Clean(t *testing.T) {
+	cases := []struct {
+		charset  *Charset
+		name     string
+		expected string
+	}{
+		{Amazon, "AMZamz09()./-_:&^ $%[]#'@", "AMZamz09()./-_--- --[]-'@"},
+		{Google, "Ubuntu 20.04_LTS", "ubuntu-20-04-lts"},
+		{Azure, "My()./-_:&^ $%[]#'@name", "My--.--_-----------name"},
+		{Azure, "abcde-:_", "abcde"},
+		{File, "out:put/box:1?.box", "out:put/box-1-.box"},
+	}
+	for _, tc := range cases {
+		if got := tc.charset.Clean(tc.name); got != tc.expected {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.expected, got)
+		}
+		if err := tc.charset.Check(tc.charset.Clean(tc.name)); err != nil {
+			t.Fatalf("%s: cleaned name rejected: %s", tc.name, err)
+		}
+	}
+
+	if err := Google.Check(strings.Repeat("a", 64)); err == nil {
+		t.Fatal("a name longer than the maximum length should be rejected")
+	}
+	if err := Amazon.Check("foo+bar"); err == nil {
+		t.Fatal("a name with characters not allowed should be rejected")
+	}
+}
+
+func TestConfig_Prepare(t *testing.T) {
+	for _, collision := range []string{"", CollisionCounter, CollisionTimestamp, CollisionRandom} {
+		c := &Config{NameCollision: collision}
+		if errs := c.Prepare(); len(errs) != 0 {
+			t.Fatalf("%q: %v", collision, errs)
+		}
+	}
+
+	c := &Config{NameCollision: "overwrite"}
+	if errs := c.Prepare(); len(errs) != 1 {
+		t.Fatalf("an unknown strategy should be rejected, got: %v", errs)
+	}
+}
+
+func TestConfig_Name(t *testing.T) {
+	ctx := &interpolate.Context{BuildName: "Web Server", BuildType: "googlecompute"}
+
+	c := &Config{}
+	name, err := c.Name(ctx, "Base Image", Google)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "Base Image" {
+		t.Fatalf("the name should be left as is without a policy, got %q", name)
+	}
+
+	c = &Config{
+		NamePattern: "{{ .Name }}-{{ .BuildName }}-{{ .BuilderType }}",
+		NameClean:   true,
+	}
+	name, err = c.Name(ctx, "Base Image", Google)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "base-image-web-server-googlecompute" {
+		t.Fatalf("bad name: %q", name)
+	}
+
+	name, err = c.Name(ctx, strings.Repeat("a", 70), Google)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(name) != Google.MaxLength {
+		t.Fatalf("the name should be cut to the maximum length, got %q", name)
+	}
+
+	c = &Config{NamePattern: "{{ .Name"}
+	if _, err := c.Name(ctx, "foo", Google); err == nil {
+		t.Fatal("an invalid pattern should be an error")
+	}
+}
+
+func TestConfig_Unique(t *testing.T) {
+	taken := map[string]bool{"base": true, "base-2": true}
+	exists := func(name string) (bool, error) { return taken[name], nil }
+
+	c := &Config{}
+	if name, _ := c.Unique("base", Google, exists); name != "base" {
+		t.Fatalf("the name should be left as is without a strategy, got %q", name)
+	}
+
+	c.NameCollision = CollisionCounter
+	if name, _ := c.Unique("free", Google, exists); name != "free" {
+		t.Fatalf("a free name should be left as is, got %q", name)
+	}
+	if name, _ := c.Unique("base", Google, exists); name != "base-3" {
+		t.Fatalf("bad counter name: %q", name)
+	}
+
+	c.NameCollision = CollisionTimestamp
+	expected := "base-" + strconv.FormatInt(interpolate.InitTime.Unix(), 10)
+	if name, _ := c.Unique("base", Google, exists); name != expected {
+		t.Fatalf("bad timestamp name: %q", name)
+	}
+	taken[expected] = true
+	if _, err := c.Unique("base", Google, exists); err == nil {
+		t.Fatal("a taken timestamp name should be an error")
+	}
+
+	c.NameCollision = CollisionRandom
+	name, _ := c.Unique("base", Google, exists)
+	if !regexp.MustCompile(`^base-[a-z0-9]{8}$`).MatchString(name) {
+		t.Fatalf("bad random name: %q", name)
+	}
+
+	c.NameCollision = CollisionCounter
+	long := strings.Repeat("a", Google.MaxLength)
+	taken[long] = true
+	if name, _ := c.Unique(long, Google, exists); name != long[:Google.MaxLength-2]+"-2" {
+		t.Fatalf("the suffixed name should be cut to the maximum length, got %q", name)
+	}
+}
+
+func TestConfig_OutputFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "naming")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, f := range []string{"box.box", "logs.tar.gz"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &Config{NameCollision: CollisionCounter}
+	cases := map[string]string{
+		"box.box":     "box-2.box",
+		"logs.tar.gz": "logs-2.tar.gz",
+		"new.box":     "new.box",
+	}
+	for file, expected := range cases {
+		path, err := c.OutputFile(&interpolate.Context{}, filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != filepath.Join(dir, expected) {
+			t.Fatalf("%s: expected %s, got %s", file, expected, path)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+//go:generate struct-markdown
+
+// Package oidc gets the OIDC tokens of CI jobs, like the jobs of GitHub
+// Actions and GitLab CI, for the cloud builders to exchange them for
+// credentials.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+
+	commonhelper "github.com/hashicorp/packer/helper/common"
+)
+
+// The CI systems Packer gets OIDC tokens from.
+const (
+	ProviderGitHubActions = "github-actions"
+	ProviderGitLab        = "gitlab"
+)
+
+// DefaultGitLabTokenEnv is the environment variable the ID token of GitLab CI
+// jobs is read from by default.
+const DefaultGitLabTokenEnv = "GITLAB_OIDC_TOKEN"
+
+// Config is the configuration of the OIDC token of the CI job Packer runs in,
+// exchanged for the credentials of the builder.
+type Config struct {
+	// The CI system issuing the OIDC token, `github-actions` or `gitlab`.
+	// Detected from the environment of the CI job when not set.
+	OIDCProvider string `mapstructure:"oidc_provider" required:"false"`
+	// The audience of the OIDC token requested from GitHub Actions. The
+	// default depends on the cloud: `sts.amazonaws.com` for Amazon,
+	// `api://AzureADTokenExchange` for Azure, and the URL of the workload
+	// identity provider for Google Cloud.
+	OIDCAudience string `mapstructure:"oidc_audience" required:"false"`
+	// The environment variable the ID token of the GitLab CI job is read
+	// from, declared with `id_tokens` in `.gitlab-ci.yml`. Defaults to
+	// `GITLAB_OIDC_TOKEN`.
+	OIDCTokenEnv string `mapstructure:"oidc_token_env" required:"false"`
+}
+
+func (c *Config) Prepare() []error {
+	var errs []error
+
+	switch c.OIDCProvider {
+	case "", ProviderGitHubActions, ProviderGitLab:
+	default:
+		errs = append(errs, fmt.Errorf("oidc_provider must be %q or %q, got %q",
+			ProviderGitHubActions, ProviderGitLab, c.OIDCProvider))
+	}
+
+	if c.OIDCTokenEnv == "" {
+		c.OIDCTokenEnv = DefaultGitLabTokenEnv
+	}
+
+	return errs
+}
+
+// Detect returns the CI system Packer runs in, or an empty string when it
+// doesn't run in a CI system issuing OIDC tokens.
+func Detect() string {
+	switch {
+	case os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL") != "":
+		return ProviderGitHubActions
+	case os.Getenv("GITLAB_CI") != "":
+		return ProviderGitLab
+	}
+	return ""
+}
+
+// TokenSource returns the source of the OIDC tokens of the CI job, with the
+// audience defaultAudience when none is configured.
+func (c *Config) TokenSource(defaultAudience string) (*TokenSource, error) {
+	provider := c.OIDCProvider
+	if provider == "" {
+		provider = Detect()
+	}
+	if provider == "" {
+		return nil, fmt.Errorf("Packer isn't running in a CI job issuing OIDC tokens, " +
+			"set oidc_provider to get the OIDC token of the CI job")
+	}
+
+	ts := &TokenSource{
+		Provider: provider,
+		Audience: c.OIDCAudience,
+		TokenEnv: c.OIDCTokenEnv,
+		client:   commonhelper.HttpClient(),
+	}
+	if ts.Audience == "" {
+		ts.Audience = defaultAudience
+	}
+	if ts.TokenEnv == "" {
+		ts.TokenEnv = DefaultGitLabTokenEnv
+	}
+	return ts, nil
+}
+
+// TokenSource gets the OIDC tokens of the CI job.
+type TokenSource struct {
+	Provider string
+	// Audience is the audience of the tokens requested from GitHub Actions.
+	Audience string
+	// TokenEnv is the environment variable the GitLab token is read from.
+	TokenEnv string
+
+	client *http.Client
+}
+
+// Token returns an OIDC token. GitHub Actions issues a new token for every
+// request, while the token of GitLab CI jobs is the same for the whole job.
+func (ts *TokenSource) Token(ctx context.Context) (string, error) {
+	switch ts.Provider {
+	case ProviderGitHubActions:
+		return ts.gitHubActionsToken(ctx)
+	case ProviderGitLab:
+		token := os.Getenv(ts.TokenEnv)
+		if token == "" {
+			return "", fmt.Errorf("The GitLab CI job has no OIDC token in %s, "+
+				"declare it with id_tokens in .gitlab-ci.yml", ts.TokenEnv)
+		}
+		return token, nil
+	}
+	return "", fmt.Errorf("unknown OIDC provider %q", ts.Provider)
+}
+
+func (ts *TokenSource) gitHubActionsToken(ctx context.Context) (string, error) {
+	reqURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	reqToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if reqURL == "" || reqToken == "" {
+		return "", fmt.Errorf("The GitHub Actions job can't request OIDC tokens, " +
+			"grant it the id-token: write permission")
+	}
+
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return "", fmt.Errorf("Invalid ACTIONS_ID_TOKEN_REQUEST_URL: %s", err)
+	}
+	if ts.Audience != "" {
+		q := u.Query()
+		q.Set("audience", ts.Audience)
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+reqToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Error requesting the OIDC token from GitHub Actions: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("Error requesting the OIDC token from GitHub Actions: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Error requesting the OIDC token from GitHub Actions: %s: %s",
+			resp.Status, body)
+	}
+
+	var token struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.Value == "" {
+		return "", fmt.Errorf("GitHub Actions returned no OIDC token: %s", body)
+	}
+	return token.Value, nil
+}
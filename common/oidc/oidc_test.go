@@ -0,0 +1,116 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func setenv(env map[string]string) func() {
+	old := map[string]string{}
+	for k, v := range env {
+		old[k] = os.Getenv(k)
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, v := range old {
+			os.Setenv(k, v)
+		}
+	}
+}
+
+func TestConfigPrepare(t *testing.T) {
+	c := &Config{OIDCProvider: "jenkins"}
+	if errs := c.Prepare(); len(errs) != 1 {
+		t.Fatalf("should error on unknown provider: %v", errs)
+	}
+
+	c = &Config{}
+	if errs := c.Prepare(); len(errs) != 0 {
+		t.Fatalf("err: %v", errs)
+	}
+	if c.OIDCTokenEnv != DefaultGitLabTokenEnv {
+		t.Fatalf("bad token env: %q", c.OIDCTokenEnv)
+	}
+}
+
+func TestTokenSource_gitHubActions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer request-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"value": "token-for-%s"}`, r.URL.Query().Get("audience"))
+	}))
+	defer server.Close()
+
+	defer setenv(map[string]string{
+		"ACTIONS_ID_TOKEN_REQUEST_URL":   server.URL + "/token?api-version=2.0",
+		"ACTIONS_ID_TOKEN_REQUEST_TOKEN": "request-token",
+	})()
+
+	c := &Config{}
+	ts, err := c.TokenSource("sts.amazonaws.com")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if ts.Provider != ProviderGitHubActions {
+		t.Fatalf("provider not detected: %q", ts.Provider)
+	}
+	token, err := ts.Token(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if token != "token-for-sts.amazonaws.com" {
+		t.Fatalf("bad token: %q", token)
+	}
+
+	os.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "wrong")
+	if _, err := ts.Token(context.Background()); err == nil {
+		t.Fatal("should error when GitHub Actions refuses the request")
+	}
+}
+
+func TestTokenSource_gitLab(t *testing.T) {
+	defer setenv(map[string]string{
+		"ACTIONS_ID_TOKEN_REQUEST_URL": "",
+		"GITLAB_CI":                    "true",
+		"AWS_OIDC_TOKEN":               "gitlab-token",
+	})()
+
+	c := &Config{OIDCTokenEnv: "AWS_OIDC_TOKEN"}
+	ts, err := c.TokenSource("sts.amazonaws.com")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if ts.Provider != ProviderGitLab {
+		t.Fatalf("provider not detected: %q", ts.Provider)
+	}
+	token, err := ts.Token(context.Background())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if token != "gitlab-token" {
+		t.Fatalf("bad token: %q", token)
+	}
+
+	ts.TokenEnv = "UNSET_OIDC_TOKEN"
+	if _, err := ts.Token(context.Background()); err == nil {
+		t.Fatal("should error without token")
+	}
+}
+
+func TestTokenSource_notInCI(t *testing.T) {
+	defer setenv(map[string]string{
+		"ACTIONS_ID_TOKEN_REQUEST_URL": "",
+		"GITLAB_CI":                    "",
+	})()
+
+	c := &Config{}
+	if _, err := c.TokenSource(""); err == nil {
+		t.Fatal("should error outside of CI jobs")
+	}
+}
@@ -1,5 +1,7 @@
 package common
 
+import "github.com/hashicorp/packer/packer"
+
 // PackerConfig is a struct that contains the configuration keys that
 // are sent by packer, properly tagged already so mapstructure can load
 // them. Embed this structure into your configuration class to get it.
@@ -7,8 +9,43 @@ type PackerConfig struct {
 	PackerBuildName     string            `mapstructure:"packer_build_name"`
 	PackerBuilderType   string            `mapstructure:"packer_builder_type"`
 	PackerDebug         bool              `mapstructure:"packer_debug"`
+	PackerBreakpoints   []string          `mapstructure:"packer_breakpoints"`
 	PackerForce         bool              `mapstructure:"packer_force"`
 	PackerOnError       string            `mapstructure:"packer_on_error"`
+	PackerOnConflict    string            `mapstructure:"packer_on_conflict"`
+	PackerJournal       string            `mapstructure:"packer_journal"`
+	PackerResume        bool              `mapstructure:"packer_resume"`
+	PackerBillingTags   map[string]string `mapstructure:"packer_billing_tags"`
+	PackerPreflight     string            `mapstructure:"packer_preflight"`
 	PackerUserVars      map[string]string `mapstructure:"packer_user_variables"`
 	PackerSensitiveVars []string          `mapstructure:"packer_sensitive_variables"`
 }
+
+// WithBillingTags returns tags, the tags of the temporary resources of the
+// build, with the billing tags of the build. The billing tags win over the
+// tags of the template.
+func (c *PackerConfig) WithBillingTags(tags map[string]string) map[string]string {
+	if len(c.PackerBillingTags) == 0 {
+		return tags
+	}
+	merged := make(map[string]string, len(tags)+len(c.PackerBillingTags))
+	for k, v := range tags {
+		merged[k] = v
+	}
+	for k, v := range c.PackerBillingTags {
+		merged[k] = v
+	}
+	return merged
+}
+
+// OnConflict returns what to do when the artifact of the build already
+// exists: one of the packer.Conflict* modes.
+func (c *PackerConfig) OnConflict() string {
+	if c.PackerOnConflict != "" {
+		return c.PackerOnConflict
+	}
+	if c.PackerForce {
+		return packer.ConflictForce
+	}
+	return packer.ConflictFail
+}
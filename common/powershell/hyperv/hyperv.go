@@ -698,9 +698,14 @@ func SetVirtualMachineSecureBoot(vmName string, enableSecureBoot bool, templateN
 	var script = `
 param([string]$vmName, [string]$enableSecureBootString, [string]$templateName)
 $cmdlet = Get-Command Hyper-V\Set-VMFirmware
+$templateId = [guid]::Empty
 # The SecureBootTemplate parameter is only available in later versions
 if ($cmdlet.Parameters.SecureBootTemplate) {
-	Hyper-V\Set-VMFirmware -VMName $vmName -EnableSecureBoot $enableSecureBootString -SecureBootTemplate $templateName
+	if ([guid]::TryParse($templateName, [ref]$templateId)) {
+		Hyper-V\Set-VMFirmware -VMName $vmName -EnableSecureBoot $enableSecureBootString -SecureBootTemplateId $templateId
+	} else {
+		Hyper-V\Set-VMFirmware -VMName $vmName -EnableSecureBoot $enableSecureBootString -SecureBootTemplate $templateName
+	}
 } else {
 	Hyper-V\Set-VMFirmware -VMName $vmName -EnableSecureBoot $enableSecureBootString
 }
@@ -721,6 +726,28 @@ if ($cmdlet.Parameters.SecureBootTemplate) {
 	return err
 }
 
+func EnableVirtualMachineTPM(vmName string, guardianName string) error {
+	var script = `
+param([string]$vmName, [string]$guardianName)
+# A virtual machine cloned with a TPM keeps the key protector it was created with
+if ((Hyper-V\Get-VMSecurity -VMName $vmName).TpmEnabled) {
+	return
+}
+if ($guardianName) {
+	$guardian = Get-HgsGuardian -Name $guardianName
+	$keyProtector = New-HgsKeyProtector -Owner $guardian -AllowUntrustedRoot
+	Hyper-V\Set-VMKeyProtector -VMName $vmName -KeyProtector $keyProtector.RawData
+} else {
+	Hyper-V\Set-VMKeyProtector -VMName $vmName -NewLocalKeyProtector
+}
+Hyper-V\Enable-VMTPM -VMName $vmName
+`
+
+	var ps powershell.PowerShellCmd
+	err := ps.Run(script, vmName, guardianName)
+	return err
+}
+
 func DeleteVirtualMachine(vmName string) error {
 
 	var script = `
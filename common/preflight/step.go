@@ -0,0 +1,114 @@
+// Package preflight checks that the credentials of a builder can perform all
+// the operations of a build before the builder launches anything.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// MissingPermissionsError is returned when the credentials of a builder
+// can't perform some of the operations of the build.
+type MissingPermissionsError struct {
+	// Principal is who the credentials authenticate, like the ARN of an
+	// IAM role or the email of a service account, when known.
+	Principal string
+	// Missing are the permissions the credentials lack, sorted.
+	Missing []string
+}
+
+func (e *MissingPermissionsError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s missing %d permission(s) required by the build:",
+		credentials(e.Principal, "are"), len(e.Missing))
+	for _, p := range e.Missing {
+		fmt.Fprintf(&b, "\n  %s", p)
+	}
+	return b.String()
+}
+
+// Checker checks which of permissions the credentials of the builder lack.
+// It returns who the credentials authenticate, when known, and the missing
+// permissions.
+type Checker func(ctx context.Context, state multistep.StateBag, permissions []string) (principal string, missing []string, err error)
+
+// StepPreflight checks the permissions of the credentials of the builder,
+// in the preflight mode of the build. It comes before the steps creating
+// resources: missing permissions halt the build with a
+// MissingPermissionsError listing all of them, and the PreflightOnly mode
+// skips the build once they are checked.
+type StepPreflight struct {
+	// Mode is one of the packer.Preflight* modes, or empty not to check
+	// anything.
+	Mode string
+	// Permissions are the permissions the build requires, in the format of
+	// the cloud, like "ec2:RunInstances".
+	Permissions []string
+	Check       Checker
+}
+
+func (s *StepPreflight) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Mode == "" {
+		return multistep.ActionContinue
+	}
+	ui := state.Get("ui").(packer.Ui)
+
+	permissions := unique(s.Permissions)
+	ui.Say(fmt.Sprintf("Checking the %d permissions the build requires...", len(permissions)))
+	principal, missing, err := s.Check(ctx, state, permissions)
+	if err != nil {
+		err = fmt.Errorf("Could not check the permissions of the credentials: %s", err)
+		if s.Mode == packer.PreflightOnly {
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		ui.Error(fmt.Sprintf("%s; building anyway.", err))
+		return multistep.ActionContinue
+	}
+	if len(missing) > 0 {
+		err := &MissingPermissionsError{Principal: principal, Missing: unique(missing)}
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	ui.Say(fmt.Sprintf("%s all the permissions the build requires.", credentials(principal, "have")))
+
+	if s.Mode == packer.PreflightOnly {
+		ui.Say("Skipping the build, only checking its permissions.")
+		state.Put(common.StateSkipped, true)
+		return multistep.ActionHalt
+	}
+	return multistep.ActionContinue
+}
+
+func (s *StepPreflight) Cleanup(multistep.StateBag) {}
+
+// credentials starts a sentence about the credentials of principal with
+// verb.
+func credentials(principal, verb string) string {
+	if principal == "" {
+		return "The credentials " + verb
+	}
+	return fmt.Sprintf("The credentials of %s %s", principal, verb)
+}
+
+// unique returns the sorted permissions without duplicates.
+func unique(permissions []string) []string {
+	seen := make(map[string]bool, len(permissions))
+	res := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		if p != "" && !seen[p] {
+			seen[p] = true
+			res = append(res, p)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,97 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func testState(t *testing.T) multistep.StateBag {
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", packer.TestUi(t))
+	return state
+}
+
+func TestStepPreflight(t *testing.T) {
+	var checked []string
+	check := func(_ context.Context, _ multistep.StateBag, permissions []string) (string, []string, error) {
+		checked = permissions
+		return "arn:aws:iam::123456789012:role/packer", nil, nil
+	}
+
+	cases := []struct {
+		mode    string
+		action  multistep.StepAction
+		skipped bool
+		checked []string
+	}{
+		{"", multistep.ActionContinue, false, nil},
+		{packer.PreflightCheck, multistep.ActionContinue, false, []string{"a:A", "b:B"}},
+		{packer.PreflightOnly, multistep.ActionHalt, true, []string{"a:A", "b:B"}},
+	}
+	for _, tc := range cases {
+		checked = nil
+		state := testState(t)
+		step := &StepPreflight{Mode: tc.mode, Permissions: []string{"b:B", "a:A", "b:B"}, Check: check}
+		if action := step.Run(context.Background(), state); action != tc.action {
+			t.Fatalf("%q: unexpected action %v", tc.mode, action)
+		}
+		if common.Skipped(state) != tc.skipped {
+			t.Fatalf("%q: skipped: %t, expected %t", tc.mode, common.Skipped(state), tc.skipped)
+		}
+		if _, ok := state.GetOk("error"); ok {
+			t.Fatalf("%q: unexpected error %v", tc.mode, state.Get("error"))
+		}
+		if !reflect.DeepEqual(checked, tc.checked) {
+			t.Fatalf("%q: checked %v, expected %v", tc.mode, checked, tc.checked)
+		}
+	}
+}
+
+func TestStepPreflight_missing(t *testing.T) {
+	state := testState(t)
+	step := &StepPreflight{
+		Mode:        packer.PreflightCheck,
+		Permissions: []string{"a:A", "b:B", "c:C"},
+		Check: func(context.Context, multistep.StateBag, []string) (string, []string, error) {
+			return "packer@project.iam.gserviceaccount.com", []string{"c:C", "a:A"}, nil
+		},
+	}
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("unexpected action %v", action)
+	}
+	err, ok := state.Get("error").(*MissingPermissionsError)
+	if !ok {
+		t.Fatalf("unexpected error %#v", state.Get("error"))
+	}
+	expected := "The credentials of packer@project.iam.gserviceaccount.com are missing 2 permission(s) required by the build:\n  a:A\n  c:C"
+	if err.Error() != expected {
+		t.Fatalf("unexpected error %q", err)
+	}
+}
+
+func TestStepPreflight_checkError(t *testing.T) {
+	check := func(context.Context, multistep.StateBag, []string) (string, []string, error) {
+		return "", nil, errors.New("access denied")
+	}
+
+	state := testState(t)
+	step := &StepPreflight{Mode: packer.PreflightCheck, Permissions: []string{"a:A"}, Check: check}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("the build should go on when the permissions can't be checked, got %v", action)
+	}
+
+	state = testState(t)
+	step.Mode = packer.PreflightOnly
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("unexpected action %v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should error when only checking the permissions")
+	}
+}
@@ -0,0 +1,113 @@
+//go:generate struct-markdown
+
+// Package publicip detects the public IP address Packer connects from, for the
+// builders to allow it in the firewall of their instances. The address is
+// asked to providers in order until one answers: HTTPS services answering it
+// as plain text, the network interfaces of the machine running Packer, or
+// the providers registered with Register.
+package publicip
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// DefaultProviders are the providers asked for the public IP address when
+// none is configured.
+var DefaultProviders = []string{
+	"https://checkip.amazonaws.com",
+	"https://api.ipify.org",
+	"https://icanhazip.com",
+}
+
+// Config is how the public IP address of Packer is detected.
+type Config struct {
+	// The public IP address Packer connects from, instead of detecting it,
+	// like when the providers can't be reached from the machine running
+	// Packer.
+	PublicIPAddress string `mapstructure:"public_ip_address" required:"false"`
+	// The providers asked for the public IP address Packer connects from, in
+	// order, until one answers: an `https://` URL answering the address as
+	// plain text, or `interface:<name>` for the first global unicast address
+	// of a network interface of the machine running Packer, like
+	// `interface:eth0`. Defaults to `https://checkip.amazonaws.com`,
+	// `https://api.ipify.org` and `https://icanhazip.com`.
+	PublicIPProviders []string `mapstructure:"public_ip_providers" required:"false"`
+}
+
+func (c *Config) Prepare() []error {
+	var errs []error
+
+	if c.PublicIPAddress != "" && net.ParseIP(c.PublicIPAddress) == nil {
+		errs = append(errs, fmt.Errorf("public_ip_address is not an IP address: %q", c.PublicIPAddress))
+	}
+	if len(c.PublicIPProviders) == 0 {
+		c.PublicIPProviders = DefaultProviders
+	}
+	for _, spec := range c.PublicIPProviders {
+		if _, err := NewProvider(spec); err != nil {
+			errs = append(errs, fmt.Errorf("Error in public_ip_providers: %s", err))
+		}
+	}
+
+	return errs
+}
+
+// Result is a detected public IP address and the provider that answered it.
+type Result struct {
+	IP       net.IP
+	Provider string
+}
+
+// CIDR returns the address of r as a CIDR block of a single address.
+func (r *Result) CIDR() string {
+	if r.IP.To4() != nil {
+		return r.IP.String() + "/32"
+	}
+	return r.IP.String() + "/128"
+}
+
+// Detect returns the public IP address of Packer: public_ip_address when it
+// is set, or else the address answered by the first provider of
+// public_ip_providers that answers one.
+func (c *Config) Detect(ctx context.Context) (*Result, error) {
+	if c.PublicIPAddress != "" {
+		return &Result{IP: net.ParseIP(c.PublicIPAddress), Provider: "public_ip_address"}, nil
+	}
+
+	var errs []string
+	for _, spec := range c.PublicIPProviders {
+		p, err := NewProvider(spec)
+		if err == nil {
+			var ip net.IP
+			if ip, err = p.PublicIP(ctx); err == nil {
+				return &Result{IP: ip, Provider: spec}, nil
+			}
+		}
+		log.Printf("[WARN] Public IP provider %s failed: %s", spec, err)
+		errs = append(errs, fmt.Sprintf("%s: %s", spec, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("no provider answered the public IP address: %s", strings.Join(errs, "; "))
+}
+
+// Put records r as public_ip in the state bag, and as PublicIP and
+// PublicIPProvider in the generated data, for the manifest.
+func Put(state multistep.StateBag, r *Result) {
+	log.Printf("Public IP address: %s, from %s", r.IP, r.Provider)
+	state.Put("public_ip", r.IP.String())
+	generatedData, ok := state.Get("generated_data").(map[string]interface{})
+	if !ok {
+		generatedData = make(map[string]interface{})
+	}
+	generatedData["PublicIP"] = r.IP.String()
+	generatedData["PublicIPProvider"] = r.Provider
+	state.Put("generated_data", generatedData)
+}
@@ -0,0 +1,144 @@
+package publicip
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider answers the public IP address of Packer.
+type Provider interface {
+	PublicIP(ctx context.Context) (net.IP, error)
+}
+
+// NewProviderFunc returns the provider of a URL of its scheme.
+type NewProviderFunc func(u *url.URL) (Provider, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]NewProviderFunc{
+		"http":      newHTTPProvider,
+		"https":     newHTTPProvider,
+		"interface": newInterfaceProvider,
+	}
+)
+
+// Register makes the providers of the URLs with scheme available in
+// public_ip_providers, replacing the providers of the scheme registered
+// before.
+func Register(scheme string, f NewProviderFunc) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[scheme] = f
+}
+
+// NewProvider returns the provider of spec, a URL whose scheme is registered.
+func NewProvider(spec string) (Provider, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	providersMu.RLock()
+	f, ok := providers[u.Scheme]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", spec)
+	}
+	return f(u)
+}
+
+// httpProvider asks an HTTP service answering the IP address of the caller
+// as plain text.
+type httpProvider struct {
+	url string
+}
+
+func newHTTPProvider(u *url.URL) (Provider, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("no host in %q", u)
+	}
+	return &httpProvider{url: u.String()}, nil
+}
+
+func (p *httpProvider) PublicIP(ctx context.Context) (net.IP, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequest("GET", p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s", p.url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return nil, err
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return nil, fmt.Errorf("%s didn't answer an IP address: %q", p.url, body)
+	}
+	return ip, nil
+}
+
+// interfaceAddrs returns the addresses of the network interface name, it is
+// replaced in tests.
+var interfaceAddrs = func(name string) ([]net.Addr, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return iface.Addrs()
+}
+
+// interfaceProvider inspects a network interface of the machine running
+// Packer, for when it has the public address.
+type interfaceProvider struct {
+	name string
+}
+
+func newInterfaceProvider(u *url.URL) (Provider, error) {
+	if u.Opaque == "" {
+		return nil, fmt.Errorf("no interface name in %q, like interface:eth0", u)
+	}
+	return &interfaceProvider{name: u.Opaque}, nil
+}
+
+// PublicIP returns the first global unicast IPv4 address of the interface, or
+// else its first global unicast IPv6 address.
+func (p *interfaceProvider) PublicIP(context.Context) (net.IP, error) {
+	addrs, err := interfaceAddrs(p.name)
+	if err != nil {
+		return nil, err
+	}
+	var ipv6 net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || !ipNet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+		if ipv6 == nil {
+			ipv6 = ipNet.IP
+		}
+	}
+	if ipv6 == nil {
+		return nil, fmt.Errorf("interface %s has no global unicast address", p.name)
+	}
+	return ipv6, nil
+}
@@ -0,0 +1,128 @@
+package publicip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestConfig_Prepare(t *testing.T) {
+	c := &Config{}
+	if errs := c.Prepare(); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if len(c.PublicIPProviders) != len(DefaultProviders) {
+		t.Fatalf("bad default providers: %v", c.PublicIPProviders)
+	}
+
+	c = &Config{
+		PublicIPAddress:   "203.0.113.300",
+		PublicIPProviders: []string{"https://", "interface:", "ftp://example.com", "interface:eth0"},
+	}
+	if errs := c.Prepare(); len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got: %v", errs)
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	answer := "203.0.113.7\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, answer)
+	}))
+	defer server.Close()
+
+	p, err := NewProvider(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"203.0.113.7\n": "203.0.113.7/32",
+		"2001:db8::7":   "2001:db8::7/128",
+		"<html>nope</>": "",
+	}
+	for a, expected := range cases {
+		answer = a
+		ip, err := p.PublicIP(context.Background())
+		if expected == "" {
+			if err == nil {
+				t.Fatalf("%q: expected an error", a)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cidr := (&Result{IP: ip}).CIDR(); cidr != expected {
+			t.Fatalf("%q: expected %s, got %s", a, expected, cidr)
+		}
+	}
+}
+
+func TestInterfaceProvider(t *testing.T) {
+	defer func(f func(string) ([]net.Addr, error)) { interfaceAddrs = f }(interfaceAddrs)
+	interfaceAddrs = func(name string) ([]net.Addr, error) {
+		if name != "eth0" {
+			return nil, errors.New("no such interface")
+		}
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("fe80::1")},
+			&net.IPNet{IP: net.ParseIP("2001:db8::7")},
+			&net.IPNet{IP: net.ParseIP("127.0.0.1")},
+			&net.IPNet{IP: net.ParseIP("198.51.100.7")},
+		}, nil
+	}
+
+	p, err := NewProvider("interface:eth0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ip, err := p.PublicIP(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip.String() != "198.51.100.7" {
+		t.Fatalf("the IPv4 address should be preferred, got %s", ip)
+	}
+}
+
+type staticProvider string
+
+func (p staticProvider) PublicIP(context.Context) (net.IP, error) {
+	if p == "" {
+		return nil, errors.New("down")
+	}
+	return net.ParseIP(string(p)), nil
+}
+
+func TestConfig_Detect(t *testing.T) {
+	Register("test", func(u *url.URL) (Provider, error) {
+		return staticProvider(u.Opaque), nil
+	})
+
+	c := &Config{PublicIPProviders: []string{"test:", "test:192.0.2.1"}}
+	if errs := c.Prepare(); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	r, err := c.Detect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.IP.String() != "192.0.2.1" || r.Provider != "test:192.0.2.1" {
+		t.Fatalf("the first provider answering should be used, got %#v", r)
+	}
+
+	c.PublicIPAddress = "192.0.2.2"
+	if r, _ = c.Detect(context.Background()); r.IP.String() != "192.0.2.2" || r.Provider != "public_ip_address" {
+		t.Fatalf("public_ip_address should be used, got %#v", r)
+	}
+
+	c = &Config{PublicIPProviders: []string{"test:"}}
+	if _, err := c.Detect(context.Background()); err == nil {
+		t.Fatal("expected an error when no provider answers")
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitOpenError is returned for the API requests sent while the circuit
+// breaker is open.
+type CircuitOpenError struct {
+	Name  string
+	Until time.Time
+}
+
+func (err *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s API requests are failing, not sending requests until %s",
+		err.Name, err.Until.Format(time.RFC3339))
+}
+
+// breaker is a circuit breaker. It opens after threshold failures in a row
+// and stays open for timeout. Then it lets a single request through, closing
+// when it succeeds and opening again when it fails.
+type breaker struct {
+	threshold int
+	timeout   time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow returns an error when the request must not be sent.
+func (b *breaker) allow(name string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+	until := b.openedAt.Add(b.timeout)
+	if b.probing || time.Now().Before(until) {
+		return &CircuitOpenError{Name: name, Until: until}
+	}
+	b.probing = true
+	return nil
+}
+
+// record records the outcome of a request.
+func (b *breaker) record(success bool) (opened bool) {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		return true
+	}
+	return false
+}
+
+// abort records a request ending without outcome, like a cancelled request.
+func (b *breaker) abort() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
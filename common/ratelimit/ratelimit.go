@@ -0,0 +1,190 @@
+//go:generate struct-markdown
+
+// Package ratelimit is the middleware of the HTTP clients of the cloud
+// builders. It limits the rate of the API requests, retries the throttled
+// and failed requests within a retry budget, and stops sending requests for a
+// while when the API keeps on failing, so parallel builds back off instead of
+// failing on the throttling of the provider.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultCircuitBreakerTimeout is how long the circuit stays open by default.
+const DefaultCircuitBreakerTimeout = 30 * time.Second
+
+// Config is the configuration of the middleware of the API requests of the
+// builder. The limits are shared by the API clients of the builder, and apply
+// to each build: the requests of N parallel builds are limited to N times the
+// configured rate.
+type Config struct {
+	// The maximum number of API requests per second. Requests above the rate
+	// wait for their turn. Defaults to `0`, unlimited.
+	APIRateLimit float64 `mapstructure:"api_rate_limit" required:"false"`
+	// The number of API requests that can be sent at once, above
+	// `api_rate_limit`. Defaults to the rate limit rounded up.
+	APIBurst int `mapstructure:"api_burst" required:"false"`
+	// The number of times a throttled (HTTP 429) or failed (HTTP 5xx or
+	// network error) API request is retried, with an exponential backoff or
+	// after the delay requested by the API with `Retry-After`. Defaults to
+	// `0`, the requests are only retried by the SDK of the cloud.
+	APIMaxRetries int `mapstructure:"api_max_retries" required:"false"`
+	// The maximum number of retries of all the API requests of the build, so
+	// a failing API isn't hammered by the retries. Defaults to `0`, only
+	// `api_max_retries` limits the retries.
+	APIRetryBudget int `mapstructure:"api_retry_budget" required:"false"`
+	// The number of API requests failing in a row, after their retries,
+	// opening the circuit breaker: the API requests fail right away until
+	// `api_circuit_breaker_timeout` has elapsed. Defaults to `0`, the circuit
+	// breaker is disabled.
+	APICircuitBreakerThreshold int `mapstructure:"api_circuit_breaker_threshold" required:"false"`
+	// How long the circuit breaker stays open, after which a single API
+	// request is sent to test the API. Defaults to `30s`.
+	APICircuitBreakerTimeout time.Duration `mapstructure:"api_circuit_breaker_timeout" required:"false"`
+}
+
+func (c *Config) Prepare() []error {
+	var errs []error
+
+	if c.APIRateLimit < 0 {
+		errs = append(errs, fmt.Errorf("api_rate_limit must be positive"))
+	}
+	if c.APIBurst < 0 {
+		errs = append(errs, fmt.Errorf("api_burst must be positive"))
+	}
+	if c.APIMaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("api_max_retries must be positive"))
+	}
+	if c.APIRetryBudget < 0 {
+		errs = append(errs, fmt.Errorf("api_retry_budget must be positive"))
+	}
+	if c.APICircuitBreakerThreshold < 0 {
+		errs = append(errs, fmt.Errorf("api_circuit_breaker_threshold must be positive"))
+	}
+	if c.APICircuitBreakerTimeout < 0 {
+		errs = append(errs, fmt.Errorf("api_circuit_breaker_timeout must be positive"))
+	}
+
+	if c.APIRateLimit > 0 && c.APIBurst == 0 {
+		c.APIBurst = int(math.Ceil(c.APIRateLimit))
+	}
+	if c.APICircuitBreakerThreshold > 0 && c.APICircuitBreakerTimeout == 0 {
+		c.APICircuitBreakerTimeout = DefaultCircuitBreakerTimeout
+	}
+
+	return errs
+}
+
+// Enabled tells whether the API requests go through the middleware.
+func (c *Config) Enabled() bool {
+	return c.APIRateLimit > 0 || c.APIMaxRetries > 0 || c.APICircuitBreakerThreshold > 0
+}
+
+// Transport returns base wrapped in the middleware, or base when the
+// middleware is disabled. The transports of the same name and configuration
+// share their limits, so name is usually the name of the builder. base is
+// http.DefaultTransport when nil.
+func (c *Config) Transport(name string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if c == nil || !c.Enabled() {
+		return base
+	}
+	return &Transport{
+		Base:   base,
+		name:   name,
+		config: *c,
+		limits: sharedLimits(name, *c),
+	}
+}
+
+// Client returns a copy of client sending its requests through the
+// middleware, or client when the middleware is disabled. client is a new
+// http.Client when nil.
+func (c *Config) Client(name string, client *http.Client) *http.Client {
+	if client == nil {
+		client = &http.Client{}
+	}
+	if c == nil || !c.Enabled() {
+		return client
+	}
+	wrapped := *client
+	wrapped.Transport = c.Transport(name, client.Transport)
+	return &wrapped
+}
+
+// limits are the rate limiter, the retry budget and the circuit breaker
+// shared by the transports of the same name.
+type limits struct {
+	limiter *rate.Limiter
+	budget  *budget
+	breaker *breaker
+}
+
+type limitsKey struct {
+	name   string
+	config Config
+}
+
+var (
+	sharedMu sync.Mutex
+	shared   = map[limitsKey]*limits{}
+)
+
+func sharedLimits(name string, c Config) *limits {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	key := limitsKey{name, c}
+	if l, ok := shared[key]; ok {
+		return l
+	}
+	l := &limits{}
+	if c.APIRateLimit > 0 {
+		burst := c.APIBurst
+		if burst == 0 {
+			burst = int(math.Ceil(c.APIRateLimit))
+		}
+		l.limiter = rate.NewLimiter(rate.Limit(c.APIRateLimit), burst)
+	}
+	if c.APIRetryBudget > 0 {
+		l.budget = &budget{remaining: c.APIRetryBudget}
+	}
+	if c.APICircuitBreakerThreshold > 0 {
+		timeout := c.APICircuitBreakerTimeout
+		if timeout == 0 {
+			timeout = DefaultCircuitBreakerTimeout
+		}
+		l.breaker = &breaker{threshold: c.APICircuitBreakerThreshold, timeout: timeout}
+	}
+	shared[key] = l
+	return l
+}
+
+// budget is the number of retries left.
+type budget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+// take uses a retry of the budget, if any is left.
+func (b *budget) take() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining == 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
@@ -0,0 +1,213 @@
+package ratelimit
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func init() {
+	retryBaseDelay = time.Millisecond
+	retryMaxDelay = 10 * time.Millisecond
+}
+
+// server returns a server responding with the status codes of statuses, then
+// 200, and counting the requests.
+func server(requests *int32, statuses ...int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(requests, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		if int(n) <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+		}
+		w.Write(body)
+	}))
+}
+
+func TestConfigPrepare(t *testing.T) {
+	c := &Config{APIRateLimit: 2.5, APICircuitBreakerThreshold: 3}
+	if errs := c.Prepare(); len(errs) != 0 {
+		t.Fatalf("err: %v", errs)
+	}
+	if c.APIBurst != 3 {
+		t.Fatalf("bad burst: %d", c.APIBurst)
+	}
+	if c.APICircuitBreakerTimeout != DefaultCircuitBreakerTimeout {
+		t.Fatalf("bad timeout: %s", c.APICircuitBreakerTimeout)
+	}
+
+	c = &Config{APIRateLimit: -1, APIMaxRetries: -1}
+	if errs := c.Prepare(); len(errs) != 2 {
+		t.Fatalf("should error on negative values: %v", errs)
+	}
+}
+
+func TestConfigTransport_disabled(t *testing.T) {
+	c := &Config{}
+	if c.Transport("test", http.DefaultTransport) != http.DefaultTransport {
+		t.Fatal("disabled middleware should return the base transport")
+	}
+}
+
+func TestTransport_retry(t *testing.T) {
+	var requests int32
+	s := server(&requests, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+	defer s.Close()
+
+	c := &Config{APIMaxRetries: 2}
+	client := c.Client("TestTransport_retry", nil)
+	resp, err := client.Post(s.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "body" {
+		t.Fatalf("bad response: %s %q", resp.Status, body)
+	}
+	if requests != 3 {
+		t.Fatalf("bad number of requests: %d", requests)
+	}
+}
+
+func TestTransport_retryExhausted(t *testing.T) {
+	var requests int32
+	s := server(&requests, 500, 500, 500, 500)
+	defer s.Close()
+
+	c := &Config{APIMaxRetries: 2}
+	resp, err := c.Client("TestTransport_retryExhausted", nil).Get(s.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 500 || requests != 3 {
+		t.Fatalf("bad: %s after %d requests", resp.Status, requests)
+	}
+}
+
+func TestTransport_retryBudget(t *testing.T) {
+	var requests int32
+	s := server(&requests, 503, 503, 503, 503, 503, 503)
+	defer s.Close()
+
+	c := &Config{APIMaxRetries: 5, APIRetryBudget: 3}
+	client := c.Client("TestTransport_retryBudget", nil)
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(s.URL)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		resp.Body.Close()
+	}
+	// 1 request and 3 retries, then 1 request without retries.
+	if requests != 5 {
+		t.Fatalf("bad number of requests: %d", requests)
+	}
+}
+
+func TestTransport_noRetry(t *testing.T) {
+	var requests int32
+	s := server(&requests, http.StatusNotFound)
+	defer s.Close()
+
+	c := &Config{APIMaxRetries: 2}
+	resp, err := c.Client("TestTransport_noRetry", nil).Get(s.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || requests != 1 {
+		t.Fatalf("bad: %s after %d requests", resp.Status, requests)
+	}
+}
+
+func TestTransport_circuitBreaker(t *testing.T) {
+	var requests int32
+	s := server(&requests, 500, 500)
+	defer s.Close()
+
+	c := &Config{APICircuitBreakerThreshold: 2, APICircuitBreakerTimeout: 50 * time.Millisecond}
+	client := c.Client("TestTransport_circuitBreaker", nil)
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(s.URL)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		resp.Body.Close()
+	}
+
+	// The circuit is open.
+	if _, err := client.Get(s.URL); err == nil || !strings.Contains(err.Error(), "not sending requests") {
+		t.Fatalf("expected the circuit to be open, got %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("request sent through the open circuit: %d", requests)
+	}
+
+	// The circuit closes once a request succeeds after the timeout.
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(s.URL)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		resp.Body.Close()
+	}
+	if requests != 4 {
+		t.Fatalf("bad number of requests: %d", requests)
+	}
+}
+
+func TestTransport_rateLimit(t *testing.T) {
+	var requests int32
+	s := server(&requests)
+	defer s.Close()
+
+	c := &Config{APIRateLimit: 20, APIBurst: 1}
+	// The clients of the same name share the limit.
+	clients := []*http.Client{
+		c.Client("TestTransport_rateLimit", nil),
+		c.Client("TestTransport_rateLimit", nil),
+	}
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		resp, err := clients[i%2].Get(s.URL)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		resp.Body.Close()
+	}
+	// The first request is sent right away, then one every 50ms.
+	if elapsed := time.Since(start); elapsed < 240*time.Millisecond {
+		t.Fatalf("requests not rate limited: %s", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest("GET", s.URL, nil)
+	if _, err := clients[0].Do(req.WithContext(ctx)); err == nil {
+		t.Fatal("should error when the context is done before the request is sent")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}
+	if d := retryDelay(0, resp); d != 7*time.Second {
+		t.Fatalf("Retry-After not honored: %s", d)
+	}
+	resp.Header.Set("Retry-After", "3600")
+	if d := retryDelay(0, resp); d != retryAfterMaxDelay {
+		t.Fatalf("Retry-After not capped: %s", d)
+	}
+	for attempt := 0; attempt < 100; attempt++ {
+		if d := retryDelay(attempt, nil); d > retryMaxDelay {
+			t.Fatalf("delay of attempt %d above the maximum: %s", attempt, d)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The delays between the retries: retryBaseDelay doubled on every retry, up to
+// retryMaxDelay, with jitter. The delays requested with Retry-After are capped
+// at retryAfterMaxDelay.
+var (
+	retryBaseDelay     = time.Second
+	retryMaxDelay      = 30 * time.Second
+	retryAfterMaxDelay = 5 * time.Minute
+)
+
+// Transport is the http.RoundTripper sending the API requests through the
+// middleware.
+type Transport struct {
+	// Base sends the requests.
+	Base http.RoundTripper
+
+	name   string
+	config Config
+	limits *limits
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if err := t.limits.breaker.allow(t.name); err != nil {
+		return nil, err
+	}
+
+	// Requests with a body can only be retried when the body can be read
+	// again.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(ctx); err != nil {
+			t.limits.breaker.abort()
+			return nil, err
+		}
+
+		r := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				t.record(false)
+				return nil, err
+			}
+			r = req.WithContext(ctx)
+			r.Body = body
+		}
+
+		resp, err := t.Base.RoundTrip(r)
+		if !shouldRetry(ctx, resp, err) {
+			t.record(true)
+			return resp, err
+		}
+		if attempt >= t.config.APIMaxRetries || !replayable || !t.limits.budget.take() {
+			t.record(false)
+			return resp, err
+		}
+
+		delay := retryDelay(attempt, resp)
+		if err != nil {
+			log.Printf("[WARN] %s API request %s %s failed: %s, retrying in %s (%d/%d)",
+				t.name, req.Method, req.URL.Path, err, delay, attempt+1, t.config.APIMaxRetries)
+		} else {
+			log.Printf("[WARN] %s API request %s %s failed: %s, retrying in %s (%d/%d)",
+				t.name, req.Method, req.URL.Path, resp.Status, delay, attempt+1, t.config.APIMaxRetries)
+			// Drain the body so the connection can be reused.
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			t.limits.breaker.abort()
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (t *Transport) wait(ctx context.Context) error {
+	if t.limits.limiter == nil {
+		return nil
+	}
+	return t.limits.limiter.Wait(ctx)
+}
+
+func (t *Transport) record(success bool) {
+	if t.limits.breaker.record(success) {
+		log.Printf("[WARN] %s API requests are failing, not sending requests for %s",
+			t.name, t.limits.breaker.timeout)
+	}
+}
+
+// shouldRetry tells whether the request was throttled or failed on the side
+// of the API.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay returns how long to wait before the next attempt, the delay
+// requested by the Retry-After header of resp or an exponential backoff.
+func retryDelay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if delay > retryAfterMaxDelay {
+				return retryAfterMaxDelay
+			}
+			return delay
+		}
+	}
+
+	delay := retryMaxDelay
+	if attempt < 30 {
+		if d := retryBaseDelay << uint(attempt); d < retryMaxDelay {
+			delay = d
+		}
+	}
+	// Up to 50% of jitter, so the parallel builds don't retry in sync.
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retryAfter parses the value of a Retry-After header, in seconds or as a
+// date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(v); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
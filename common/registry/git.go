@@ -0,0 +1,141 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Git is a Local backend in a git working copy, committing every change, so
+// that the registry is versioned and reviewable. With Push, it pulls before
+// reading and pushes the commits to the upstream of the working copy.
+type Git struct {
+	Local
+	// Repository is the root of the working copy; Local.Dir is in it.
+	Repository string
+	Push       bool
+
+	l sync.Mutex
+}
+
+var _ Backend = new(Git)
+
+func newGit(u *url.URL) (Backend, error) {
+	path, err := urlPath(u)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	g := &Git{
+		Local:      Local{Dir: path},
+		Repository: path,
+		Push:       q.Get("push") == "true",
+	}
+	if dir := q.Get("dir"); dir != "" {
+		g.Local.Dir = filepath.Join(path, dir)
+	}
+	return g, nil
+}
+
+func (g *Git) git(ctx context.Context, args ...string) error {
+	_, err := g.output(ctx, args...)
+	return err
+}
+
+func (g *Git) output(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.Repository
+	var out, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %s: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out.String(), nil
+}
+
+func (g *Git) pull(ctx context.Context) error {
+	if !g.Push {
+		return nil
+	}
+	return g.git(ctx, "pull", "--rebase", "--quiet")
+}
+
+// commit commits the changes of the registry, and pushes them.
+func (g *Git) commit(ctx context.Context, message string) error {
+	if err := g.git(ctx, "add", "-A", "--", g.Local.Dir); err != nil {
+		return err
+	}
+	status, err := g.output(ctx, "status", "--porcelain", "--", g.Local.Dir)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(status) == "" {
+		// Nothing changed, like when a channel is set to its build.
+		return nil
+	}
+	if err := g.git(ctx, "commit", "--quiet", "-m", message, "--", g.Local.Dir); err != nil {
+		return err
+	}
+	log.Printf("registry: committed %q", message)
+	if !g.Push {
+		return nil
+	}
+	return g.git(ctx, "push", "--quiet")
+}
+
+func (g *Git) PutBuild(ctx context.Context, b *Build) error {
+	g.l.Lock()
+	defer g.l.Unlock()
+	if err := g.pull(ctx); err != nil {
+		return err
+	}
+	if err := g.Local.PutBuild(ctx, b); err != nil {
+		return err
+	}
+	return g.commit(ctx, fmt.Sprintf("Publish build %s of %s", b.ID, b.Image))
+}
+
+func (g *Git) Build(ctx context.Context, image, id string) (*Build, error) {
+	g.l.Lock()
+	defer g.l.Unlock()
+	if err := g.pull(ctx); err != nil {
+		return nil, err
+	}
+	return g.Local.Build(ctx, image, id)
+}
+
+func (g *Git) Builds(ctx context.Context, image string) ([]*Build, error) {
+	g.l.Lock()
+	defer g.l.Unlock()
+	if err := g.pull(ctx); err != nil {
+		return nil, err
+	}
+	return g.Local.Builds(ctx, image)
+}
+
+func (g *Git) SetChannel(ctx context.Context, image, channel, id string) error {
+	g.l.Lock()
+	defer g.l.Unlock()
+	if err := g.pull(ctx); err != nil {
+		return err
+	}
+	if err := g.Local.SetChannel(ctx, image, channel, id); err != nil {
+		return err
+	}
+	return g.commit(ctx, fmt.Sprintf("Assign build %s of %s to %s", id, image, channel))
+}
+
+func (g *Git) Channels(ctx context.Context, image string) (map[string]string, error) {
+	g.l.Lock()
+	defer g.l.Unlock()
+	if err := g.pull(ctx); err != nil {
+		return nil, err
+	}
+	return g.Local.Channels(ctx, image)
+}
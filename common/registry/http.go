@@ -0,0 +1,210 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	commonhelper "github.com/hashicorp/packer/helper/common"
+)
+
+// EnvToken is the environment variable setting the token the HTTP backend
+// authenticates with.
+const EnvToken = "PACKER_REGISTRY_TOKEN"
+
+// HTTP is a backend using the HTTP API of a registry:
+//
+//	GET  /images/IMAGE/builds             the builds of IMAGE
+//	GET  /images/IMAGE/builds/ID          a build
+//	PUT  /images/IMAGE/builds/ID          publishes a build
+//	GET  /images/IMAGE/channels           the channels of IMAGE
+//	PUT  /images/IMAGE/channels/CHANNEL   assigns the build {"build_id": ID}
+//
+// Requests send the token as a bearer token. NewHandler serves the API.
+type HTTP struct {
+	// URL is the prefix of the paths of the API.
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+var _ Backend = new(HTTP)
+
+func newHTTP(u *url.URL) (Backend, error) {
+	return &HTTP{
+		URL:    strings.TrimSuffix(u.String(), "/"),
+		Token:  os.Getenv(EnvToken),
+		Client: commonhelper.HttpClient(),
+	}, nil
+}
+
+// channelAssignment is the body of PUT /images/IMAGE/channels/CHANNEL.
+type channelAssignment struct {
+	BuildID string `json:"build_id"`
+}
+
+func (h *HTTP) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, h.URL+path, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode >= 300:
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func imagePath(image string, parts ...string) string {
+	p := "/images/" + url.PathEscape(image)
+	for _, part := range parts {
+		p += "/" + url.PathEscape(part)
+	}
+	return p
+}
+
+func (h *HTTP) PutBuild(ctx context.Context, b *Build) error {
+	return h.do(ctx, http.MethodPut, imagePath(b.Image, "builds", b.ID), b, nil)
+}
+
+func (h *HTTP) Build(ctx context.Context, image, id string) (*Build, error) {
+	b := &Build{}
+	if err := h.do(ctx, http.MethodGet, imagePath(image, "builds", id), nil, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (h *HTTP) Builds(ctx context.Context, image string) ([]*Build, error) {
+	var builds []*Build
+	err := h.do(ctx, http.MethodGet, imagePath(image, "builds"), nil, &builds)
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	return builds, err
+}
+
+func (h *HTTP) SetChannel(ctx context.Context, image, channel, id string) error {
+	return h.do(ctx, http.MethodPut, imagePath(image, "channels", channel), &channelAssignment{BuildID: id}, nil)
+}
+
+func (h *HTTP) Channels(ctx context.Context, image string) (map[string]string, error) {
+	channels := map[string]string{}
+	err := h.do(ctx, http.MethodGet, imagePath(image, "channels"), nil, &channels)
+	if err == ErrNotFound {
+		return map[string]string{}, nil
+	}
+	return channels, err
+}
+
+// NewHandler serves the HTTP API of b, for the HTTP backend. Requests must
+// send token as a bearer token, unless it is empty.
+func NewHandler(b Backend, token string) http.Handler {
+	return &handler{b: b, token: token}
+}
+
+type handler struct {
+	b     Backend
+	token string
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" {
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(auth), []byte(h.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "images" {
+		http.NotFound(w, r)
+		return
+	}
+	image, kind, rest := parts[1], parts[2], parts[3:]
+	ctx := r.Context()
+
+	var res interface{}
+	var err error
+	switch {
+	case kind == "builds" && len(rest) == 0 && r.Method == http.MethodGet:
+		var builds []*Build
+		builds, err = h.b.Builds(ctx, image)
+		if builds == nil {
+			builds = []*Build{}
+		}
+		res = builds
+	case kind == "builds" && len(rest) == 1 && r.Method == http.MethodGet:
+		res, err = h.b.Build(ctx, image, rest[0])
+	case kind == "builds" && len(rest) == 1 && r.Method == http.MethodPut:
+		build := &Build{}
+		if err := json.NewDecoder(r.Body).Decode(build); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if build.ID != rest[0] || build.Image != image {
+			http.Error(w, "the build doesn't match its path", http.StatusBadRequest)
+			return
+		}
+		err = h.b.PutBuild(ctx, build)
+	case kind == "channels" && len(rest) == 0 && r.Method == http.MethodGet:
+		res, err = h.b.Channels(ctx, image)
+	case kind == "channels" && len(rest) == 1 && r.Method == http.MethodPut:
+		var a channelAssignment
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		err = Promote(ctx, h.b, image, rest[0], a.BuildID)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case err == ErrNotFound:
+		http.NotFound(w, r)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	case res == nil:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local is a backend storing the registry in a directory: the builds of an
+// image are JSON files of IMAGE/builds, and its channels are in
+// IMAGE/channels.json.
+type Local struct {
+	Dir string
+}
+
+var _ Backend = new(Local)
+
+// NewLocal returns a backend storing the registry in dir.
+func NewLocal(dir string) *Local {
+	return &Local{Dir: dir}
+}
+
+func newLocal(u *url.URL) (Backend, error) {
+	path, err := urlPath(u)
+	if err != nil {
+		return nil, err
+	}
+	return NewLocal(path), nil
+}
+
+func (b *Local) buildPath(image, id string) string {
+	return filepath.Join(b.Dir, image, "builds", url.PathEscape(id)+".json")
+}
+
+func (b *Local) channelsPath(image string) string {
+	return filepath.Join(b.Dir, image, "channels.json")
+}
+
+func (b *Local) PutBuild(_ context.Context, build *Build) error {
+	if err := ValidName(build.Image); err != nil {
+		return err
+	}
+	return writeJSON(b.buildPath(build.Image, build.ID), build)
+}
+
+func (b *Local) Build(_ context.Context, image, id string) (*Build, error) {
+	if err := ValidName(image); err != nil {
+		return nil, err
+	}
+	build := &Build{}
+	if err := readJSON(b.buildPath(image, id), build); err != nil {
+		return nil, err
+	}
+	return build, nil
+}
+
+func (b *Local) Builds(ctx context.Context, image string) ([]*Build, error) {
+	if err := ValidName(image); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(filepath.Join(b.Dir, image, "builds"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var builds []*Build
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		build := &Build{}
+		if err := readJSON(filepath.Join(b.Dir, image, "builds", f.Name()), build); err != nil {
+			return nil, err
+		}
+		builds = append(builds, build)
+	}
+	SortBuilds(builds)
+	return builds, nil
+}
+
+func (b *Local) SetChannel(ctx context.Context, image, channel, id string) error {
+	channels, err := b.Channels(ctx, image)
+	if err != nil {
+		return err
+	}
+	channels[channel] = id
+	return writeJSON(b.channelsPath(image), channels)
+}
+
+func (b *Local) Channels(_ context.Context, image string) (map[string]string, error) {
+	if err := ValidName(image); err != nil {
+		return nil, err
+	}
+	channels := map[string]string{}
+	err := readJSON(b.channelsPath(image), &channels)
+	if err == ErrNotFound {
+		return map[string]string{}, nil
+	}
+	return channels, err
+}
+
+// writeJSON writes v to path atomically, so that readers never see it half
+// written.
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// readJSON reads v from path, returning ErrNotFound when the file doesn't
+// exist.
+func readJSON(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,174 @@
+// Package registry publishes the metadata of the images Packer builds to an
+// image registry, and assigns builds to channels: a channel, like
+// "production", names the build of an image that consumers should use, so
+// that promoting a build doesn't require changing the consumers.
+//
+// Registries are backends implementing Backend; the file, git and HTTP
+// backends are built in, others are added with Register.
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	uuid "github.com/hashicorp/go-uuid"
+)
+
+// EnvAddress is the environment variable setting the registry when the
+// address isn't configured.
+const EnvAddress = "PACKER_REGISTRY"
+
+// ErrNotFound is returned for builds and images that aren't in a registry.
+var ErrNotFound = errors.New("not found")
+
+// Artifact describes an artifact of a build.
+type Artifact struct {
+	BuilderID string   `json:"builder_id"`
+	ID        string   `json:"id"`
+	String    string   `json:"string"`
+	Files     []string `json:"files,omitempty"`
+}
+
+// Build is the metadata of a build of an image.
+type Build struct {
+	// ID is unique to the build in the registry.
+	ID string `json:"id"`
+	// Image is the name the builds of an image are published under.
+	Image string `json:"image"`
+	// Name is the name of the build in its configuration.
+	Name        string    `json:"name"`
+	BuilderType string    `json:"builder_type,omitempty"`
+	RunUUID     string    `json:"run_uuid,omitempty"`
+	Created     time.Time `json:"created"`
+	// Labels are arbitrary metadata, like the commit of the configuration.
+	Labels    map[string]string `json:"labels,omitempty"`
+	Artifacts []Artifact        `json:"artifacts"`
+}
+
+// NewBuild returns a new build of image, with a unique ID.
+func NewBuild(image, name string) (*Build, error) {
+	if err := ValidName(image); err != nil {
+		return nil, err
+	}
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	return &Build{
+		ID:      id,
+		Image:   image,
+		Name:    name,
+		RunUUID: os.Getenv("PACKER_RUN_UUID"),
+		Created: time.Now().UTC(),
+	}, nil
+}
+
+// Backend stores the builds of images and their channels.
+type Backend interface {
+	// PutBuild publishes b.
+	PutBuild(ctx context.Context, b *Build) error
+	// Build returns the build of image with the given ID, or ErrNotFound.
+	Build(ctx context.Context, image, id string) (*Build, error)
+	// Builds returns the builds of image.
+	Builds(ctx context.Context, image string) ([]*Build, error)
+
+	// SetChannel assigns the build of image with the given ID to channel.
+	SetChannel(ctx context.Context, image, channel, id string) error
+	// Channels returns the IDs of the builds assigned to the channels of
+	// image, by channel.
+	Channels(ctx context.Context, image string) (map[string]string, error)
+}
+
+var nameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// ValidName checks the name of an image or a channel, which backends use in
+// paths.
+func ValidName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid name %q: it must be letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// Promote assigns the build of image with the given ID to channel, after
+// checking that the build exists: it returns ErrNotFound when it doesn't.
+func Promote(ctx context.Context, b Backend, image, channel, id string) error {
+	if err := ValidName(channel); err != nil {
+		return err
+	}
+	if _, err := b.Build(ctx, image, id); err != nil {
+		return err
+	}
+	return b.SetChannel(ctx, image, channel, id)
+}
+
+// SortBuilds sorts builds from the oldest to the newest.
+func SortBuilds(builds []*Build) {
+	sort.SliceStable(builds, func(i, j int) bool { return builds[i].Created.Before(builds[j].Created) })
+}
+
+// Factory returns the backend of an address.
+type Factory func(u *url.URL) (Backend, error)
+
+var (
+	factoriesMu sync.Mutex
+	factories   = map[string]Factory{
+		"file":  newLocal,
+		"git":   newGit,
+		"http":  newHTTP,
+		"https": newHTTP,
+	}
+)
+
+// Register adds the backend of the addresses with the given scheme, like a
+// database backend.
+func Register(scheme string, f Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[scheme] = f
+}
+
+// New returns the backend configured by address:
+//
+//	file:path                          a local directory
+//	git:path?push=true                 a directory of a git working copy,
+//	                                   committing and pushing every change
+//	http(s)://host/prefix              the HTTP API served by NewHandler
+//
+// and the schemes added with Register.
+func New(address string) (Backend, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry %q: %s", address, err)
+	}
+	factoriesMu.Lock()
+	f, ok := factories[u.Scheme]
+	factoriesMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("invalid registry %q: unknown scheme %q", address, u.Scheme)
+	}
+	b, err := f(u)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry %q: %s", address, err)
+	}
+	return b, nil
+}
+
+// urlPath returns the path of a file: or git: address.
+func urlPath(u *url.URL) (string, error) {
+	path := u.Opaque
+	if path == "" {
+		path = u.Path
+	}
+	if path == "" {
+		return "", fmt.Errorf("the %s backend needs a path", u.Scheme)
+	}
+	return path, nil
+}
@@ -0,0 +1,162 @@
+package registry
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBackend(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	if builds, err := b.Builds(ctx, "base"); err != nil || len(builds) != 0 {
+		t.Fatalf("unexpected builds of an empty registry: %v, %v", builds, err)
+	}
+	if _, err := b.Build(ctx, "base", "nope"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		build, err := NewBuild("base", "qemu.base")
+		if err != nil {
+			t.Fatal(err)
+		}
+		build.Created = build.Created.Add(time.Duration(i) * time.Second)
+		build.Labels = map[string]string{"commit": "abc"}
+		build.Artifacts = []Artifact{{BuilderID: "transcend.qemu", ID: "VM", String: "VM files", Files: []string{"disk.qcow2"}}}
+		if err := b.PutBuild(ctx, build); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, build.ID)
+
+		got, err := b.Build(ctx, "base", build.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Created.Equal(build.Created) {
+			t.Fatalf("unexpected created time %s, expected %s", got.Created, build.Created)
+		}
+		got.Created = build.Created
+		if !reflect.DeepEqual(got, build) {
+			t.Fatalf("unexpected build %#v, expected %#v", got, build)
+		}
+	}
+
+	builds, err := b.Builds(ctx, "base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builds) != 2 || builds[0].ID != ids[0] || builds[1].ID != ids[1] {
+		t.Fatalf("unexpected builds %v, expected %v", builds, ids)
+	}
+
+	if err := Promote(ctx, b, "base", "production", "nope"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound promoting an unknown build, got %v", err)
+	}
+	for _, a := range [][2]string{{"production", ids[0]}, {"dev", ids[1]}, {"production", ids[1]}, {"production", ids[1]}} {
+		if err := Promote(ctx, b, "base", a[0], a[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	channels, err := b.Channels(ctx, "base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"production": ids[1], "dev": ids[1]}; !reflect.DeepEqual(channels, expected) {
+		t.Fatalf("unexpected channels %v, expected %v", channels, expected)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b, err := New("file:" + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackend(t, b)
+}
+
+func TestGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.email", "packer@example.com"},
+		{"config", "user.name", "packer"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %s", args, err, out)
+		}
+	}
+
+	b, err := New("git:" + dir + "?dir=registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackend(t, b)
+
+	cmd := exec.Command("git", "log", "--oneline")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 builds and 3 channel changes.
+	if lines := strings.Split(strings.TrimSpace(string(out)), "\n"); len(lines) != 5 {
+		t.Fatalf("expected 5 commits, got:\n%s", out)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ts := httptest.NewServer(NewHandler(NewLocal(dir), "secret"))
+	defer ts.Close()
+
+	os.Setenv(EnvToken, "wrong")
+	b, err := New(ts.URL + "/")
+	os.Unsetenv(EnvToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Builds(context.Background(), "base"); err == nil {
+		t.Fatal("expected an error with a wrong token")
+	}
+	b.(*HTTP).Token = "secret"
+	testBackend(t, b)
+}
+
+func TestNew(t *testing.T) {
+	for _, address := range []string{"file:", "nope://x", "git:"} {
+		if _, err := New(address); err == nil {
+			t.Errorf("%q: expected an error", address)
+		}
+	}
+	if _, err := NewBuild("../x", "b"); err == nil {
+		t.Error("expected an error for an invalid image name")
+	}
+}
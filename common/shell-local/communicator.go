@@ -7,13 +7,19 @@ import (
 	"log"
 	"os"
 	"os/exec"
-	"syscall"
+	"strings"
 
 	"github.com/hashicorp/packer/packer"
 )
 
 type Communicator struct {
 	ExecuteCommand []string
+
+	// If true, ExecuteCommand is joined with spaces and passed as is as the
+	// command line of the process on Windows, as cmd.exe doesn't understand
+	// the quoting os/exec applies to each argument. It has no effect on other
+	// platforms.
+	RawCommandLine bool
 }
 
 func (c *Communicator) Start(ctx context.Context, cmd *packer.RemoteCmd) error {
@@ -27,6 +33,9 @@ func (c *Communicator) Start(ctx context.Context, cmd *packer.RemoteCmd) error {
 	localCmd.Stdin = cmd.Stdin
 	localCmd.Stdout = cmd.Stdout
 	localCmd.Stderr = cmd.Stderr
+	if c.RawCommandLine {
+		setRawCommandLine(localCmd, strings.Join(c.ExecuteCommand, " "))
+	}
 
 	// Start it. If it doesn't work, then error right away.
 	if err := localCmd.Start(); err != nil {
@@ -40,12 +49,10 @@ func (c *Communicator) Start(ctx context.Context, cmd *packer.RemoteCmd) error {
 		err := localCmd.Wait()
 		if err != nil {
 			if exitErr, ok := err.(*exec.ExitError); ok {
-				exitStatus = 1
-
-				// There is no process-independent way to get the REAL
-				// exit status so we just try to go deeper.
-				if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
-					exitStatus = status.ExitStatus()
+				exitStatus = exitErr.ExitCode()
+				if exitStatus < 0 {
+					// Terminated by a signal
+					exitStatus = 1
 				}
 			}
 		}
@@ -0,0 +1,8 @@
+//go:build !windows
+// +build !windows
+
+package shell_local
+
+import "os/exec"
+
+func setRawCommandLine(cmd *exec.Cmd, line string) {}
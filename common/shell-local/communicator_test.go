@@ -44,3 +44,25 @@ func TestCommunicator(t *testing.T) {
 		t.Fatalf("bad: %s", buf.String())
 	}
 }
+
+func TestCommunicator_exitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows not supported for this test")
+		return
+	}
+
+	c := &Communicator{
+		ExecuteCommand: []string{"/bin/sh", "-c", "exit 3"},
+	}
+
+	cmd := &packer.RemoteCmd{}
+	if err := c.Start(context.Background(), cmd); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cmd.Wait()
+
+	if cmd.ExitStatus() != 3 {
+		t.Fatalf("bad exit status: %d", cmd.ExitStatus())
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows
+// +build windows
+
+package shell_local
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func setRawCommandLine(cmd *exec.Cmd, line string) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: line}
+}
@@ -41,6 +41,21 @@ type Config struct {
 	// End dedupe with postprocessor
 	UseLinuxPathing bool `mapstructure:"use_linux_pathing"`
 
+	// The shell that runs the script: "sh", "cmd", "powershell" or "pwsh".
+	// It sets the defaults of execute_command, env_var_format,
+	// inline_shebang and tempfile_extension, and how environment variable
+	// values are quoted.
+	Shell string `mapstructure:"shell"`
+
+	// The container image to run the script in, instead of on the host.
+	ContainerImage string `mapstructure:"container_image"`
+
+	// The container runtime used to run container_image.
+	ContainerRuntime string `mapstructure:"container_runtime"`
+
+	// Additional arguments to the run command of the container runtime.
+	ContainerRunArgs []string `mapstructure:"container_run_args"`
+
 	// used to track the data sent to shell-local from the builder
 	// GeneratedData
 
@@ -48,6 +63,50 @@ type Config struct {
 	generatedData map[string]interface{}
 }
 
+// shellDefaults holds the settings that depend on the shell running the
+// script.
+type shellDefaults struct {
+	ExecuteCommand    []string
+	EnvVarFormat      string
+	InlineShebang     string
+	TempfileExtension string
+	// Quote escapes an environment variable value for EnvVarFormat.
+	Quote func(string) string
+}
+
+var shells = map[string]shellDefaults{
+	"sh": {
+		ExecuteCommand: []string{"/bin/sh", "-c", "{{.Vars}} {{.Script}}"},
+		EnvVarFormat:   "%s='%s' ",
+		InlineShebang:  "/bin/sh -e",
+		Quote: func(v string) string {
+			return strings.Replace(v, "'", `'"'"'`, -1)
+		},
+	},
+	"cmd": {
+		ExecuteCommand:    []string{"cmd", "/V", "/C", "{{.Vars}}", "call", `"{{.Script}}"`},
+		EnvVarFormat:      `set "%s=%s" && `,
+		TempfileExtension: "cmd",
+		// Everything up to the last quote is the value in set "key=value"
+		Quote: func(v string) string { return v },
+	},
+	"powershell": powershellDefaults("powershell"),
+	"pwsh":       powershellDefaults("pwsh"),
+}
+
+func powershellDefaults(program string) shellDefaults {
+	return shellDefaults{
+		ExecuteCommand: []string{program, "-NoProfile", "-NonInteractive",
+			"-ExecutionPolicy", "Bypass", "-Command",
+			"{{.Vars}}& '{{.Script}}'; exit $LastExitCode"},
+		EnvVarFormat:      "$env:%s='%s'; ",
+		TempfileExtension: "ps1",
+		Quote: func(v string) string {
+			return strings.Replace(v, "'", "''", -1)
+		},
+	}
+}
+
 func Decode(config *Config, raws ...interface{}) error {
 	err := configHelper.Decode(config, &configHelper.DecodeOpts{
 		Interpolate:        true,
@@ -69,30 +128,48 @@ func Decode(config *Config, raws ...interface{}) error {
 func Validate(config *Config) error {
 	var errs *packer.MultiError
 
-	if runtime.GOOS == "windows" {
+	if config.Shell == "" {
+		if runtime.GOOS == "windows" && !config.UseLinuxPathing && config.ContainerImage == "" {
+			config.Shell = "cmd"
+		} else {
+			config.Shell = "sh"
+		}
+	}
+
+	shell, ok := shells[config.Shell]
+	if !ok {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("shell must be one of sh, cmd, powershell or pwsh: %s", config.Shell))
+	} else {
 		if len(config.ExecuteCommand) == 0 {
-			config.ExecuteCommand = []string{
-				"cmd",
-				"/V",
-				"/C",
-				"{{.Vars}}",
-				"call",
-				"{{.Script}}",
+			config.ExecuteCommand = shell.ExecuteCommand
+			if config.Shell == "sh" && runtime.GOOS == "windows" && config.ContainerImage == "" {
+				// The Windows Subsystem for Linux provides bash, not /bin/sh
+				config.ExecuteCommand = []string{"bash", "-c", "{{.Vars}} {{.Script}}"}
 			}
 		}
-		if len(config.TempfileExtension) == 0 {
-			config.TempfileExtension = ".cmd"
-		}
-	} else {
 		if config.InlineShebang == "" {
-			config.InlineShebang = "/bin/sh -e"
+			config.InlineShebang = shell.InlineShebang
 		}
-		if len(config.ExecuteCommand) == 0 {
-			config.ExecuteCommand = []string{
-				"/bin/sh",
-				"-c",
-				"{{.Vars}} {{.Script}}",
-			}
+		if config.TempfileExtension == "" {
+			config.TempfileExtension = shell.TempfileExtension
+		}
+		if config.EnvVarFormat == "" {
+			config.EnvVarFormat = shell.EnvVarFormat
+		}
+	}
+
+	if config.ContainerImage != "" {
+		if config.ContainerRuntime == "" {
+			config.ContainerRuntime = "docker"
+		}
+		if config.Shell == "cmd" {
+			errs = packer.MultiErrorAppend(errs,
+				errors.New("The cmd shell can't be used with container_image."))
+		}
+		if config.UseLinuxPathing {
+			errs = packer.MultiErrorAppend(errs,
+				errors.New("use_linux_pathing can't be used with container_image."))
 		}
 	}
 
@@ -189,14 +266,6 @@ func Validate(config *Config) error {
 		}
 	}
 
-	if config.EnvVarFormat == "" {
-		if (runtime.GOOS == "windows") && !config.UseLinuxPathing {
-			config.EnvVarFormat = "set %s=%s && "
-		} else {
-			config.EnvVarFormat = "%s='%s' "
-		}
-	}
-
 	// drop unnecessary "." in extension; we add this later.
 	if config.TempfileExtension != "" {
 		if strings.HasPrefix(config.TempfileExtension, ".") {
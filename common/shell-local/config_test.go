@@ -14,3 +14,44 @@ func TestConvertToLinuxPath(t *testing.T) {
 		"Should have converted %s to %s -- not %s", winPath, winBashPath, converted)
 
 }
+
+func TestValidate_shell(t *testing.T) {
+	config := &Config{}
+	config.Inline = []string{"echo foo"}
+	config.Shell = "powershell"
+	assert.NoError(t, Validate(config))
+	assert.Equal(t, "ps1", config.TempfileExtension)
+	assert.Equal(t, "$env:%s='%s'; ", config.EnvVarFormat)
+	assert.Equal(t, "powershell", config.ExecuteCommand[0])
+
+	// User settings take precedence over the shell defaults
+	config = &Config{}
+	config.Inline = []string{"echo foo"}
+	config.Shell = "pwsh"
+	config.TempfileExtension = ".psm1"
+	config.ExecuteCommand = []string{"pwsh", "-File", "{{.Script}}"}
+	assert.NoError(t, Validate(config))
+	assert.Equal(t, "psm1", config.TempfileExtension)
+	assert.Equal(t, []string{"pwsh", "-File", "{{.Script}}"}, config.ExecuteCommand)
+
+	config = &Config{}
+	config.Inline = []string{"echo foo"}
+	config.Shell = "zsh"
+	assert.Error(t, Validate(config))
+}
+
+func TestValidate_container(t *testing.T) {
+	config := &Config{}
+	config.Inline = []string{"echo foo"}
+	config.ContainerImage = "alpine"
+	assert.NoError(t, Validate(config))
+	assert.Equal(t, "sh", config.Shell)
+	assert.Equal(t, "docker", config.ContainerRuntime)
+	assert.Equal(t, []string{"/bin/sh", "-c", "{{.Vars}} {{.Script}}"}, config.ExecuteCommand)
+
+	config = &Config{}
+	config.Inline = []string{"echo foo"}
+	config.ContainerImage = "alpine"
+	config.Shell = "cmd"
+	assert.Error(t, Validate(config))
+}
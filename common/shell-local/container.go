@@ -0,0 +1,41 @@
+package shell_local
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+const (
+	containerScriptDir = "/packer-shell-local"
+	containerWorkDir   = "/workspace"
+)
+
+// containerScriptPath returns the path at which the script is mounted in the
+// container.
+func containerScriptPath(script string) string {
+	return path.Join(containerScriptDir, filepath.Base(script))
+}
+
+// containerCommand wraps command so that it runs in config.ContainerImage,
+// with the script mounted read-only and the current directory mounted as the
+// working directory. The entrypoint of the image is reset so that command is
+// run as is.
+func containerCommand(config *Config, script string, command []string) ([]string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading working directory: %s", err)
+	}
+
+	args := []string{
+		config.ContainerRuntime, "run", "--rm",
+		"--entrypoint=",
+		"-v", fmt.Sprintf("%s:%s:ro", script, containerScriptPath(script)),
+		"-v", fmt.Sprintf("%s:%s", wd, containerWorkDir),
+		"-w", containerWorkDir,
+	}
+	args = append(args, config.ContainerRunArgs...)
+	args = append(args, config.ContainerImage)
+	return append(args, command...), nil
+}
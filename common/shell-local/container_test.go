@@ -0,0 +1,41 @@
+package shell_local
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerCommand(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	script := filepath.Join(wd, "script.sh")
+
+	config := &Config{
+		ContainerImage:   "hashicorp/terraform:light",
+		ContainerRuntime: "podman",
+		ContainerRunArgs: []string{"--network", "host"},
+	}
+	command := []string{"/bin/sh", "-c", "FOO='bar' /packer-shell-local/script.sh"}
+
+	args, err := containerCommand(config, script, command)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{
+		"podman", "run", "--rm", "--entrypoint=",
+		"-v", fmt.Sprintf("%s:/packer-shell-local/script.sh:ro", script),
+		"-v", fmt.Sprintf("%s:/workspace", wd),
+		"-w", "/workspace",
+		"--network", "host",
+		"hashicorp/terraform:light",
+		"/bin/sh", "-c", "FOO='bar' /packer-shell-local/script.sh",
+	}
+	assert.Equal(t, expected, args)
+}
@@ -81,21 +81,34 @@ func Run(ctx context.Context, ui packer.Ui, config *Config, generatedData map[st
 				err,
 			)
 		}
-		interpolatedCmds, err := createInterpolatedCommands(config, absScript, flattenedEnvVars)
+		scriptPath := absScript
+		if config.ContainerImage != "" {
+			scriptPath = containerScriptPath(absScript)
+		}
+		interpolatedCmds, err := createInterpolatedCommands(config, scriptPath, flattenedEnvVars)
 		if err != nil {
 			return false, err
 		}
-		ui.Say(fmt.Sprintf("Running local shell script: %s", script))
 
 		comm := &Communicator{
 			ExecuteCommand: interpolatedCmds,
+			RawCommandLine: config.Shell == "cmd",
+		}
+		if config.ContainerImage != "" {
+			comm.ExecuteCommand, err = containerCommand(config, absScript, interpolatedCmds)
+			if err != nil {
+				return false, err
+			}
+			ui.Say(fmt.Sprintf("Running local shell script in %s: %s", config.ContainerImage, script))
+		} else {
+			ui.Say(fmt.Sprintf("Running local shell script: %s", script))
 		}
 
 		// The remoteCmd generated here isn't actually run, but it allows us to
 		// use the same interafce for the shell-local communicator as we use for
 		// the other communicators; ultimately, this command is just used for
 		// buffers and for reading the final exit status.
-		flattenedCmd := strings.Join(interpolatedCmds, " ")
+		flattenedCmd := strings.Join(comm.ExecuteCommand, " ")
 		cmd := &packer.RemoteCmd{Command: flattenedCmd}
 		log.Printf("[INFO] (shell-local): starting local command: %s", flattenedCmd)
 		if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
@@ -201,9 +214,12 @@ func createFlattenedEnvVars(config *Config) (string, error) {
 		}
 		// Split vars into key/value components
 		keyValue := strings.SplitN(envVar, "=", 2)
-		// Store pair, replacing any single quotes in value so they parse
-		// correctly with required environment variable format
-		envVars[keyValue[0]] = strings.Replace(keyValue[1], "'", `'"'"'`, -1)
+		envVars[keyValue[0]] = keyValue[1]
+	}
+
+	quote := shells["sh"].Quote
+	if shell, ok := shells[config.Shell]; ok {
+		quote = shell.Quote
 	}
 
 	// Create a list of env var keys in sorted order
@@ -214,7 +230,9 @@ func createFlattenedEnvVars(config *Config) (string, error) {
 	sort.Strings(keys)
 
 	for _, key := range keys {
-		flattened += fmt.Sprintf(config.EnvVarFormat, key, envVars[key])
+		// Quote the value so that it parses correctly with the environment
+		// variable format of the shell
+		flattened += fmt.Sprintf(config.EnvVarFormat, key, quote(envVars[key]))
 	}
 	return flattened, nil
 }
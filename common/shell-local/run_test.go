@@ -0,0 +1,31 @@
+package shell_local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateFlattenedEnvVars(t *testing.T) {
+	cases := []struct {
+		Shell    string
+		Expected string
+	}{
+		{"sh", `FOO='it'"'"'s' PACKER_BUILDER_TYPE='' PACKER_BUILD_NAME='' `},
+		{"cmd", `set "FOO=it's" && set "PACKER_BUILDER_TYPE=" && set "PACKER_BUILD_NAME=" && `},
+		{"powershell", `$env:FOO='it''s'; $env:PACKER_BUILDER_TYPE=''; $env:PACKER_BUILD_NAME=''; `},
+	}
+
+	for _, tc := range cases {
+		config := &Config{Shell: tc.Shell}
+		config.Vars = []string{"FOO=it's"}
+		config.EnvVarFormat = shells[tc.Shell].EnvVarFormat
+		config.generatedData = map[string]interface{}{}
+
+		flattened, err := createFlattenedEnvVars(config)
+		if err != nil {
+			t.Fatalf("%s: err: %s", tc.Shell, err)
+		}
+		assert.Equal(t, tc.Expected, flattened, tc.Shell)
+	}
+}
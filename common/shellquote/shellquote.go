@@ -0,0 +1,10 @@
+// Package shellquote quotes the arguments of the commands Packer runs on the
+// machines it builds.
+package shellquote
+
+import "strings"
+
+// Quote quotes s as a single word for a POSIX shell.
+func Quote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
@@ -0,0 +1,17 @@
+package shellquote
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	cases := map[string]string{
+		"":           `''`,
+		"/etc/fstab": `'/etc/fstab'`,
+		"/etc/it's":  `'/etc/it'"'"'s'`,
+		"$HOME; ls":  `'$HOME; ls'`,
+	}
+	for s, expected := range cases {
+		if q := Quote(s); q != expected {
+			t.Fatalf("Quote(%q) = %s, expected %s", s, q, expected)
+		}
+	}
+}
@@ -23,6 +23,11 @@ type ShutdownConfig struct {
 	// in this time it is considered an error. By default, the time out is "5m"
 	// (five minutes).
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" required:"false"`
+	// If the machine doesn't shut down in `shutdown_timeout`, stop it
+	// forcefully instead of failing the build. The path taken to shut the
+	// machine down is available as the `ShutdownPath` build variable, and in
+	// the manifest. Defaults to false.
+	ForceShutdown bool `mapstructure:"force_shutdown" required:"false"`
 }
 
 func (c *ShutdownConfig) Prepare(ctx *interpolate.Context) []error {
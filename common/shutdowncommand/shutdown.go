@@ -0,0 +1,221 @@
+package shutdowncommand
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// Machine is the machine of a builder, as shut down by a Shutdown.
+type Machine interface {
+	// IsRunning tells whether the machine is still running.
+	IsRunning() (bool, error)
+	// Stop stops the machine forcefully.
+	Stop() error
+}
+
+// ACPIMachine is a Machine that can also be shut down gracefully by the
+// hypervisor, like with the ACPI power button or the guest tools.
+type ACPIMachine interface {
+	Machine
+	StopViaACPI() error
+}
+
+// The paths a Shutdown takes to shut a machine down.
+const (
+	// PathAlreadyOff is taken when the machine is already off, like when
+	// the last provisioner shut it down.
+	PathAlreadyOff = "already_off"
+	// PathCommand is taken when the shutdown command shut the machine
+	// down.
+	PathCommand = "command"
+	// PathACPI is taken when the hypervisor shut the machine down
+	// gracefully.
+	PathACPI = "acpi"
+	// PathExternal is taken when the shutdown is disabled and the guest
+	// shut itself down.
+	PathExternal = "external"
+	// PathForced is taken when the machine is stopped forcefully, without
+	// a shutdown command.
+	PathForced = "forced"
+	// PathEscalated is taken when the machine didn't shut down gracefully
+	// in time and was stopped forcefully.
+	PathEscalated = "escalated"
+)
+
+// Shutdown shuts a machine down: it issues the shutdown command through the
+// communicator, the ACPI shutdown or the forced stop of the machine, and
+// waits for the machine to stop running within the timeout, forcing it to
+// stop when it doesn't and Force is set.
+type Shutdown struct {
+	Command string
+	Timeout time.Duration
+	// ACPI shuts the machine down with StopViaACPI, when it is an
+	// ACPIMachine, instead of the shutdown command.
+	ACPI bool
+	// Disabled waits for the guest to shut itself down.
+	Disabled bool
+	// Force stops the machine forcefully when it doesn't shut down
+	// gracefully in time, instead of failing.
+	Force bool
+	// PollInterval is how often the machine is checked. Defaults to half a
+	// second.
+	PollInterval time.Duration
+}
+
+// Run shuts m down, and returns the path it took. comm can be nil when the
+// builder has no communicator.
+func (s *Shutdown) Run(ctx context.Context, ui packer.Ui, comm packer.Communicator, m Machine) (string, error) {
+	acpi, hasACPI := m.(ACPIMachine)
+	var cmd *packer.RemoteCmd
+	var stdout, stderr bytes.Buffer
+	path := PathForced
+	switch {
+	case s.ACPI && hasACPI:
+		ui.Say("Shutting down the virtual machine gracefully through the hypervisor...")
+		if err := acpi.StopViaACPI(); err != nil {
+			return path, fmt.Errorf("Error stopping VM: %s", err)
+		}
+		path = PathACPI
+	case s.Disabled:
+		ui.Say("Automatic shutdown disabled. Please shutdown virtual machine.")
+		path = PathExternal
+	case s.Command != "" && comm != nil:
+		// The last provisioner may have shut the machine down already, and
+		// the command would never run.
+		if running, err := m.IsRunning(); err == nil && !running {
+			ui.Say("The virtual machine is already shut down")
+			return PathAlreadyOff, nil
+		}
+		ui.Say("Gracefully halting virtual machine...")
+		log.Printf("Executing shutdown command: %s", s.Command)
+		cmd = &packer.RemoteCmd{Command: s.Command, Stdout: &stdout, Stderr: &stderr}
+		// The command usually doesn't exit, the guest shutting down first.
+		if err := comm.Start(ctx, cmd); err != nil {
+			return path, fmt.Errorf("Failed to send shutdown command: %s", err)
+		}
+		path = PathCommand
+	default:
+		ui.Say("Halting the virtual machine...")
+		if err := m.Stop(); err != nil {
+			return path, fmt.Errorf("Error stopping VM: %s", err)
+		}
+	}
+
+	log.Printf("Waiting max %s for shutdown to complete", s.Timeout)
+	err := s.wait(ctx, m)
+	if err == nil || path == PathForced {
+		return path, err
+	}
+
+	if cmd != nil {
+		log.Printf("Shutdown stdout: %s", stdout.String())
+		log.Printf("Shutdown stderr: %s", stderr.String())
+		if exited, status := commandExited(cmd); exited && status != 0 {
+			err = fmt.Errorf("%s The shutdown command exited with status %d.", err, status)
+		}
+	}
+	if !s.Force || ctx.Err() != nil {
+		return path, err
+	}
+
+	ui.Say(fmt.Sprintf("The virtual machine didn't shut down in %s, forcing it to stop...", s.Timeout))
+	if err := m.Stop(); err != nil {
+		return PathEscalated, fmt.Errorf("Error stopping VM: %s", err)
+	}
+	return PathEscalated, s.wait(ctx, m)
+}
+
+// wait waits for m to stop running.
+func (s *Shutdown) wait(ctx context.Context, m Machine) error {
+	interval := s.PollInterval
+	if interval == 0 {
+		interval = 500 * time.Millisecond
+	}
+	timeout := time.After(s.Timeout)
+	for {
+		if running, err := m.IsRunning(); err != nil {
+			log.Printf("Error checking whether the VM is running: %s", err)
+		} else if !running {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			return errors.New("Timeout while waiting for machine to shut down.")
+		case <-time.After(interval):
+		}
+	}
+}
+
+// commandExited returns whether cmd exited, and its exit status.
+func commandExited(cmd *packer.RemoteCmd) (bool, int) {
+	exited := make(chan int, 1)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case status := <-exited:
+		return true, status
+	case <-time.After(10 * time.Millisecond):
+		return false, 0
+	}
+}
+
+// StepShutdown shuts the machine of a builder down with its Shutdown, and
+// records the path it took with PutPath.
+//
+// Uses:
+//   communicator packer.Communicator, when set
+//   ui packer.Ui
+//
+// Produces:
+//   shutdown_path string
+type StepShutdown struct {
+	Shutdown
+	// Machine returns the machine to shut down.
+	Machine func(multistep.StateBag) Machine
+	// Delay is waited once the machine is shut down, like for the
+	// hypervisor to release the files of the machine.
+	Delay time.Duration
+}
+
+func (s *StepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+	comm, _ := state.Get("communicator").(packer.Communicator)
+
+	path, err := s.Shutdown.Run(ctx, ui, comm, s.Machine(state))
+	if err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	PutPath(state, path)
+
+	if s.Delay > 0 {
+		log.Printf("Delay for %s after shutdown to allow locks to clear...", s.Delay)
+		time.Sleep(s.Delay)
+	}
+	return multistep.ActionContinue
+}
+
+func (s *StepShutdown) Cleanup(state multistep.StateBag) {}
+
+// PutPath records path, the path taken to shut the machine down, as
+// shutdown_path in the state bag, and as ShutdownPath in the generated data.
+func PutPath(state multistep.StateBag, path string) {
+	log.Printf("VM shut down: %s", path)
+	state.Put("shutdown_path", path)
+	generatedData, ok := state.Get("generated_data").(map[string]interface{})
+	if !ok {
+		generatedData = make(map[string]interface{})
+	}
+	generatedData["ShutdownPath"] = path
+	state.Put("generated_data", generatedData)
+}
@@ -0,0 +1,187 @@
+package shutdowncommand
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// testMachine stops running on Stop, or on StopViaACPI when acpiStops is set.
+type testMachine struct {
+	sync.Mutex
+	running    bool
+	acpiStops  bool
+	stopCalled bool
+	acpiCalled bool
+}
+
+func (m *testMachine) IsRunning() (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.running, nil
+}
+
+func (m *testMachine) Stop() error {
+	m.Lock()
+	defer m.Unlock()
+	m.stopCalled = true
+	m.running = false
+	return nil
+}
+
+func (m *testMachine) StopViaACPI() error {
+	m.Lock()
+	defer m.Unlock()
+	m.acpiCalled = true
+	if m.acpiStops {
+		m.running = false
+	}
+	return nil
+}
+
+func testShutdown() *Shutdown {
+	return &Shutdown{
+		Timeout:      50 * time.Millisecond,
+		PollInterval: time.Millisecond,
+	}
+}
+
+func TestShutdown_forced(t *testing.T) {
+	s := testShutdown()
+	m := &testMachine{running: true}
+
+	path, err := s.Run(context.Background(), packer.TestUi(t), new(packer.MockCommunicator), m)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if path != PathForced {
+		t.Fatalf("bad path: %s", path)
+	}
+	if !m.stopCalled {
+		t.Fatal("should stop the machine")
+	}
+}
+
+func TestShutdown_command(t *testing.T) {
+	s := testShutdown()
+	s.Command = "poweroff"
+	m := &testMachine{running: true}
+	comm := new(packer.MockCommunicator)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		m.Lock()
+		defer m.Unlock()
+		m.running = false
+	}()
+
+	path, err := s.Run(context.Background(), packer.TestUi(t), comm, m)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if path != PathCommand {
+		t.Fatalf("bad path: %s", path)
+	}
+	if comm.StartCmd.Command != "poweroff" {
+		t.Fatalf("bad command: %#v", comm.StartCmd)
+	}
+	if m.stopCalled {
+		t.Fatal("should not stop the machine")
+	}
+}
+
+func TestShutdown_commandAlreadyOff(t *testing.T) {
+	s := testShutdown()
+	s.Command = "poweroff"
+	comm := new(packer.MockCommunicator)
+
+	path, err := s.Run(context.Background(), packer.TestUi(t), comm, &testMachine{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if path != PathAlreadyOff {
+		t.Fatalf("bad path: %s", path)
+	}
+	if comm.StartCalled {
+		t.Fatal("should not run the command")
+	}
+}
+
+func TestShutdown_acpi(t *testing.T) {
+	s := testShutdown()
+	s.ACPI = true
+	s.Command = "poweroff"
+	m := &testMachine{running: true, acpiStops: true}
+	comm := new(packer.MockCommunicator)
+
+	path, err := s.Run(context.Background(), packer.TestUi(t), comm, m)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if path != PathACPI {
+		t.Fatalf("bad path: %s", path)
+	}
+	if !m.acpiCalled || comm.StartCalled {
+		t.Fatal("should only shut down via ACPI")
+	}
+}
+
+func TestShutdown_timeout(t *testing.T) {
+	s := testShutdown()
+	s.Command = "poweroff"
+	m := &testMachine{running: true}
+
+	path, err := s.Run(context.Background(), packer.TestUi(t), new(packer.MockCommunicator), m)
+	if err == nil {
+		t.Fatal("should time out")
+	}
+	if path != PathCommand {
+		t.Fatalf("bad path: %s", path)
+	}
+	if m.stopCalled {
+		t.Fatal("should not stop the machine")
+	}
+}
+
+func TestShutdown_escalated(t *testing.T) {
+	s := testShutdown()
+	s.Disabled = true
+	s.Force = true
+	m := &testMachine{running: true}
+
+	path, err := s.Run(context.Background(), packer.TestUi(t), new(packer.MockCommunicator), m)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if path != PathEscalated {
+		t.Fatalf("bad path: %s", path)
+	}
+	if !m.stopCalled {
+		t.Fatal("should stop the machine")
+	}
+}
+
+func TestStepShutdown(t *testing.T) {
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", packer.TestUi(t))
+	step := &StepShutdown{
+		Shutdown: *testShutdown(),
+		Machine: func(multistep.StateBag) Machine {
+			return &testMachine{running: true}
+		},
+	}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if path := state.Get("shutdown_path"); path != PathForced {
+		t.Fatalf("bad shutdown_path: %#v", path)
+	}
+	generatedData := state.Get("generated_data").(map[string]interface{})
+	if generatedData["ShutdownPath"] != PathForced {
+		t.Fatalf("bad generated data: %#v", generatedData)
+	}
+}
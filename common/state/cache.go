@@ -0,0 +1,23 @@
+package state
+
+import "time"
+
+// CacheEntry records the artifacts of a successful build, under the hash of
+// the inputs of the build: a build with the same inputs can reuse them
+// rather than build them again.
+type CacheEntry struct {
+	// Key is the hash of the inputs of the build.
+	Key       string           `json:"key"`
+	Build     string           `json:"build"`
+	Host      string           `json:"host"`
+	Created   time.Time        `json:"created"`
+	Artifacts []CachedArtifact `json:"artifacts"`
+}
+
+// CachedArtifact describes an artifact of a cached build.
+type CachedArtifact struct {
+	BuilderID string   `json:"builder_id"`
+	ID        string   `json:"id"`
+	Files     []string `json:"files,omitempty"`
+	String    string   `json:"string"`
+}
@@ -0,0 +1,172 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// Consul is a backend storing the state in the consul KV store, under
+// Prefix.
+type Consul struct {
+	KV     *consulapi.KV
+	Prefix string
+}
+
+var _ Backend = new(Consul)
+
+// newConsul returns the consul backend of consul://host:port/prefix. The
+// address, the token and the TLS settings default to the CONSUL_HTTP_*
+// environment variables.
+func newConsul(u *url.URL) (*Consul, error) {
+	config := consulapi.DefaultConfig()
+	if u.Host != "" {
+		config.Address = u.Host
+	}
+	if scheme := u.Query().Get("scheme"); scheme != "" {
+		config.Scheme = scheme
+	}
+	client, err := consulapi.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("creating consul client: %s", err)
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix == "" {
+		prefix = "packer"
+	}
+	return &Consul{KV: client.KV(), Prefix: prefix}, nil
+}
+
+func (b *Consul) key(kind, name string) string {
+	return b.Prefix + "/" + kind + "/" + url.PathEscape(name)
+}
+
+func (b *Consul) PutBuild(ctx context.Context, l *Lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	_, err = b.KV.Put(&consulapi.KVPair{Key: b.key("builds", l.ID), Value: data}, b.writeOptions(ctx))
+	return err
+}
+
+func (b *Consul) DeleteBuild(ctx context.Context, id string) error {
+	_, err := b.KV.Delete(b.key("builds", id), b.writeOptions(ctx))
+	return err
+}
+
+func (b *Consul) Builds(ctx context.Context) ([]*Lease, error) {
+	leases, err := b.list(ctx, "builds")
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Lease, 0, len(leases))
+	for _, l := range leases {
+		res = append(res, l)
+	}
+	return res, nil
+}
+
+func (b *Consul) Lock(ctx context.Context, name string, l *Lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	// A check-and-set with an index of 0 only writes keys that don't exist.
+	ok, _, err := b.KV.CAS(&consulapi.KVPair{Key: b.key("locks", name), Value: data}, b.writeOptions(ctx))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		holder, _, _ := b.get(ctx, b.key("locks", name))
+		return &LockedError{Name: name, Holder: holder}
+	}
+	return nil
+}
+
+func (b *Consul) Unlock(ctx context.Context, name, id string) error {
+	key := b.key("locks", name)
+	holder, pair, err := b.get(ctx, key)
+	if err != nil || pair == nil {
+		return err
+	}
+	if id != "" && holder.ID != id {
+		return fmt.Errorf("%q is locked by %s", name, holder)
+	}
+	ok, _, err := b.KV.DeleteCAS(pair, b.writeOptions(ctx))
+	if err == nil && !ok {
+		err = fmt.Errorf("%q was locked again in the meantime", name)
+	}
+	return err
+}
+
+func (b *Consul) Locks(ctx context.Context) (map[string]*Lease, error) {
+	return b.list(ctx, "locks")
+}
+
+func (b *Consul) PutCached(ctx context.Context, e *CacheEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = b.KV.Put(&consulapi.KVPair{Key: b.key("cache", e.Key), Value: data}, b.writeOptions(ctx))
+	return err
+}
+
+func (b *Consul) Cached(ctx context.Context, key string) (*CacheEntry, error) {
+	pair, _, err := b.KV.Get(b.key("cache", key), b.queryOptions(ctx))
+	if err != nil || pair == nil {
+		return nil, err
+	}
+	e := &CacheEntry{}
+	if err := json.Unmarshal(pair.Value, e); err != nil {
+		return nil, fmt.Errorf("%s: %s", pair.Key, err)
+	}
+	return e, nil
+}
+
+func (b *Consul) get(ctx context.Context, key string) (*Lease, *consulapi.KVPair, error) {
+	pair, _, err := b.KV.Get(key, b.queryOptions(ctx))
+	if err != nil || pair == nil {
+		return nil, nil, err
+	}
+	l := &Lease{}
+	if err := json.Unmarshal(pair.Value, l); err != nil {
+		return nil, nil, fmt.Errorf("%s: %s", key, err)
+	}
+	return l, pair, nil
+}
+
+// list reads the leases of a kind, keyed by their unescaped names.
+func (b *Consul) list(ctx context.Context, kind string) (map[string]*Lease, error) {
+	prefix := b.Prefix + "/" + kind + "/"
+	pairs, _, err := b.KV.List(prefix, b.queryOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]*Lease{}
+	for _, pair := range pairs {
+		name, err := url.PathUnescape(strings.TrimPrefix(pair.Key, prefix))
+		if err != nil {
+			continue
+		}
+		l := &Lease{}
+		if err := json.Unmarshal(pair.Value, l); err != nil {
+			return nil, fmt.Errorf("%s: %s", pair.Key, err)
+		}
+		res[name] = l
+	}
+	return res, nil
+}
+
+func (b *Consul) writeOptions(ctx context.Context) *consulapi.WriteOptions {
+	return (&consulapi.WriteOptions{}).WithContext(ctx)
+}
+
+func (b *Consul) queryOptions(ctx context.Context) *consulapi.QueryOptions {
+	return (&consulapi.QueryOptions{}).WithContext(ctx)
+}
@@ -0,0 +1,216 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local is a backend storing the state in a directory, for builds sharing a
+// machine or a network file system.
+type Local struct {
+	Dir string
+}
+
+var _ Backend = new(Local)
+
+// NewLocal returns a backend storing the state in dir.
+func NewLocal(dir string) *Local {
+	return &Local{Dir: dir}
+}
+
+func (b *Local) path(kind, key string) string {
+	return filepath.Join(b.Dir, kind, url.PathEscape(key)+".json")
+}
+
+func (b *Local) PutBuild(_ context.Context, l *Lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	path := b.path("builds", l.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := writeTemp(filepath.Dir(path), data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (b *Local) DeleteBuild(_ context.Context, id string) error {
+	err := os.Remove(b.path("builds", id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (b *Local) Builds(_ context.Context) ([]*Lease, error) {
+	leases, err := b.list("builds")
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Lease, 0, len(leases))
+	for _, l := range leases {
+		res = append(res, l)
+	}
+	return res, nil
+}
+
+func (b *Local) Lock(_ context.Context, name string, l *Lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	path := b.path("locks", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// The lease is written to a temporary file first, then linked to the
+	// lock file: linking fails when the lock file exists, so only one build
+	// gets the lock, and the lock file is never seen half written.
+	tmp, err := writeTemp(filepath.Dir(path), data)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	err = os.Link(tmp, path)
+	if os.IsExist(err) {
+		holder, _ := readLease(path)
+		return &LockedError{Name: name, Holder: holder}
+	}
+	return err
+}
+
+func (b *Local) Unlock(_ context.Context, name, id string) error {
+	path := b.path("locks", name)
+	if id != "" {
+		holder, err := readLease(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if holder.ID != id {
+			return fmt.Errorf("%q is locked by %s", name, holder)
+		}
+	}
+	err := os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (b *Local) Locks(_ context.Context) (map[string]*Lease, error) {
+	return b.list("locks")
+}
+
+func (b *Local) PutCached(_ context.Context, e *CacheEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	path := b.path("cache", e.Key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := writeTemp(filepath.Dir(path), data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (b *Local) Cached(_ context.Context, key string) (*CacheEntry, error) {
+	path := b.path("cache", key)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e := &CacheEntry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return e, nil
+}
+
+// list reads the leases of a kind, keyed by their unescaped names.
+func (b *Local) list(kind string) (map[string]*Lease, error) {
+	files, err := ioutil.ReadDir(filepath.Join(b.Dir, kind))
+	if os.IsNotExist(err) {
+		return map[string]*Lease{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]*Lease{}
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		name, err := url.PathUnescape(strings.TrimSuffix(f.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		l, err := readLease(filepath.Join(b.Dir, kind, f.Name()))
+		if os.IsNotExist(err) {
+			// Released in the meantime.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		res[name] = l
+	}
+	return res, nil
+}
+
+func readLease(path string) (*Lease, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l := &Lease{}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return l, nil
+}
+
+// writeTemp writes data to a new hidden file of dir, and returns its path.
+func writeTemp(dir string, data []byte) (string, error) {
+	f, err := ioutil.TempFile(dir, ".tmp")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,287 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3 is a backend recording the running builds in an S3 bucket, under
+// Prefix, and locking names in a DynamoDB table. Like for the S3 backend of
+// Terraform, the table has a string partition key called LockID.
+type S3 struct {
+	S3       *s3.S3
+	DynamoDB *dynamodb.DynamoDB
+	Bucket   string
+	Prefix   string
+	Table    string
+}
+
+var _ Backend = new(S3)
+
+// newS3 returns the S3 backend of s3://bucket/prefix?dynamodb_table=table.
+// The region can be set with the region parameter, the credentials are the
+// default credentials of the environment.
+func newS3(u *url.URL) (*S3, error) {
+	q := u.Query()
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid state backend %q: the s3 backend needs a bucket", u)
+	}
+	table := q.Get("dynamodb_table")
+	if table == "" {
+		return nil, fmt.Errorf("invalid state backend %q: the s3 backend needs a dynamodb_table to lock names", u)
+	}
+
+	config := aws.Config{}
+	if region := q.Get("region"); region != "" {
+		config.Region = aws.String(region)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix == "" {
+		prefix = "packer"
+	}
+	return &S3{
+		S3:       s3.New(sess),
+		DynamoDB: dynamodb.New(sess),
+		Bucket:   u.Host,
+		Prefix:   prefix,
+		Table:    table,
+	}, nil
+}
+
+func (b *S3) buildKey(id string) string {
+	return b.Prefix + "/builds/" + url.PathEscape(id) + ".json"
+}
+
+func (b *S3) cacheKey(key string) string {
+	return b.Prefix + "/cache/" + url.PathEscape(key) + ".json"
+}
+
+// lockID is the partition key of the lock of name, unique to the bucket and
+// the prefix so that backends can share a table.
+func (b *S3) lockID(name string) string {
+	return b.Bucket + "/" + b.Prefix + "/locks/" + url.PathEscape(name)
+}
+
+func (b *S3) PutBuild(ctx context.Context, l *Lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	_, err = b.S3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(b.buildKey(l.ID)),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+func (b *S3) DeleteBuild(ctx context.Context, id string) error {
+	_, err := b.S3.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(b.buildKey(id)),
+	})
+	return err
+}
+
+func (b *S3) Builds(ctx context.Context) ([]*Lease, error) {
+	var keys []string
+	err := b.S3.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.Bucket),
+		Prefix: aws.String(b.Prefix + "/builds/"),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			keys = append(keys, aws.StringValue(o.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*Lease
+	for _, key := range keys {
+		out, err := b.S3.GetObjectWithContext(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(b.Bucket),
+			Key:    aws.String(key),
+		})
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			// Finished in the meantime.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(out.Body)
+		out.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		l := &Lease{}
+		if err := json.Unmarshal(data, l); err != nil {
+			return nil, fmt.Errorf("%s: %s", key, err)
+		}
+		res = append(res, l)
+	}
+	return res, nil
+}
+
+func (b *S3) PutCached(ctx context.Context, e *CacheEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = b.S3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(b.cacheKey(e.Key)),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+func (b *S3) Cached(ctx context.Context, key string) (*CacheEntry, error) {
+	out, err := b.S3.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(b.cacheKey(key)),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(out.Body)
+	out.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	e := &CacheEntry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, fmt.Errorf("%s: %s", b.cacheKey(key), err)
+	}
+	return e, nil
+}
+
+func (b *S3) Lock(ctx context.Context, name string, l *Lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	_, err = b.DynamoDB.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(b.Table),
+		Item: map[string]*dynamodb.AttributeValue{
+			"LockID":   {S: aws.String(b.lockID(name))},
+			"HolderID": {S: aws.String(l.ID)},
+			"Info":     {S: aws.String(string(data))},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(LockID)"),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		holder, _ := b.holder(ctx, name)
+		return &LockedError{Name: name, Holder: holder}
+	}
+	return err
+}
+
+func (b *S3) Unlock(ctx context.Context, name, id string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(b.Table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"LockID": {S: aws.String(b.lockID(name))},
+		},
+	}
+	if id != "" {
+		input.ConditionExpression = aws.String("attribute_not_exists(LockID) OR HolderID = :id")
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":id": {S: aws.String(id)},
+		}
+	}
+	_, err := b.DynamoDB.DeleteItemWithContext(ctx, input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		holder, _ := b.holder(ctx, name)
+		return &LockedError{Name: name, Holder: holder}
+	}
+	return err
+}
+
+func (b *S3) Locks(ctx context.Context) (map[string]*Lease, error) {
+	prefix := b.lockID("")
+	res := map[string]*Lease{}
+	var lerr error
+	err := b.DynamoDB.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		TableName:        aws.String(b.Table),
+		FilterExpression: aws.String("begins_with(LockID, :prefix)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":prefix": {S: aws.String(prefix)},
+		},
+	}, func(page *dynamodb.ScanOutput, _ bool) bool {
+		for _, item := range page.Items {
+			id := aws.StringValue(item["LockID"].S)
+			name, err := url.PathUnescape(strings.TrimPrefix(id, prefix))
+			if err != nil {
+				continue
+			}
+			l, err := itemLease(item)
+			if err != nil {
+				lerr = fmt.Errorf("%s: %s", id, err)
+				return false
+			}
+			res[name] = l
+		}
+		return true
+	})
+	if err == nil {
+		err = lerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// holder returns the lease holding the lock of name.
+func (b *S3) holder(ctx context.Context, name string) (*Lease, error) {
+	out, err := b.DynamoDB.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(b.Table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"LockID": {S: aws.String(b.lockID(name))},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("%q is not locked", name)
+	}
+	return itemLease(out.Item)
+}
+
+func itemLease(item map[string]*dynamodb.AttributeValue) (*Lease, error) {
+	info, ok := item["Info"]
+	if !ok || info.S == nil {
+		return nil, fmt.Errorf("missing lock info")
+	}
+	l := &Lease{}
+	if err := json.Unmarshal([]byte(*info.S), l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,181 @@
+// Package state shares the state of builds between Packer processes, for
+// example between CI jobs running on different machines. A state backend
+// records the builds in flight, and locks the names builds share, like the
+// name of an image or an output directory, so that two builds never write
+// the same artifact at the same time.
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	uuid "github.com/hashicorp/go-uuid"
+)
+
+// EnvBackend is the environment variable setting the state backend when the
+// -state option isn't set.
+const EnvBackend = "PACKER_STATE"
+
+// Lease identifies a running build. It is recorded while the build runs, and
+// tells who holds the locks of the build.
+type Lease struct {
+	// ID is unique to a run of a build.
+	ID      string    `json:"id"`
+	Build   string    `json:"build"`
+	Host    string    `json:"host"`
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+	// Locks are the names locked by the build.
+	Locks []string `json:"locks,omitempty"`
+}
+
+// NewLease returns the lease of a build of this process, locking names.
+func NewLease(build string, names []string) (*Lease, error) {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	locks := append([]string(nil), names...)
+	sort.Strings(locks)
+	return &Lease{
+		ID:      id,
+		Build:   build,
+		Host:    host,
+		PID:     os.Getpid(),
+		Started: time.Now().UTC(),
+		Locks:   locks,
+	}, nil
+}
+
+func (l *Lease) String() string {
+	return fmt.Sprintf("build '%s' of process %d on %s since %s", l.Build, l.PID, l.Host, l.Started.Format(time.RFC3339))
+}
+
+// LockedError is returned when a name is locked by another build.
+type LockedError struct {
+	Name   string
+	Holder *Lease
+}
+
+func (e *LockedError) Error() string {
+	if e.Holder == nil {
+		return fmt.Sprintf("%q is locked", e.Name)
+	}
+	return fmt.Sprintf("%q is locked by %s", e.Name, e.Holder)
+}
+
+// Backend stores the state of builds.
+type Backend interface {
+	// PutBuild records that the build of l is running.
+	PutBuild(ctx context.Context, l *Lease) error
+	// DeleteBuild forgets the running build whose lease has the given ID.
+	DeleteBuild(ctx context.Context, id string) error
+	// Builds returns the running builds.
+	Builds(ctx context.Context) ([]*Lease, error)
+
+	// Lock locks name for the build of l. It returns a *LockedError when the
+	// name is locked by another build.
+	Lock(ctx context.Context, name string, l *Lease) error
+	// Unlock unlocks name when it is locked by the lease with the given ID,
+	// or whoever locked it when id is empty.
+	Unlock(ctx context.Context, name, id string) error
+	// Locks returns the locked names and their holders.
+	Locks(ctx context.Context) (map[string]*Lease, error)
+
+	// PutCached records the cache entry of a successful build, replacing
+	// the entry with the same key.
+	PutCached(ctx context.Context, e *CacheEntry) error
+	// Cached returns the cache entry with the given key, nil when there is
+	// none.
+	Cached(ctx context.Context, key string) (*CacheEntry, error)
+}
+
+// New returns the backend configured by address:
+//
+//	file:path                                  a local directory
+//	s3://bucket/prefix?dynamodb_table=t        an S3 bucket, locking with a DynamoDB table
+//	consul://host:port/prefix                  the consul KV store
+func New(address string) (Backend, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state backend %q: %s", address, err)
+	}
+	switch u.Scheme {
+	case "file":
+		path := u.Opaque
+		if path == "" {
+			path = u.Path
+		}
+		if path == "" {
+			return nil, fmt.Errorf("invalid state backend %q: the file backend needs a path", address)
+		}
+		return NewLocal(path), nil
+	case "s3":
+		return newS3(u)
+	case "consul":
+		return newConsul(u)
+	default:
+		return nil, fmt.Errorf("invalid state backend %q: unknown scheme %q", address, u.Scheme)
+	}
+}
+
+// Acquire records the build of l as running and locks its names. When a name
+// is locked by another build, it retries until timeout, then releases what
+// it acquired.
+func Acquire(ctx context.Context, b Backend, l *Lease, timeout time.Duration) error {
+	if err := b.PutBuild(ctx, l); err != nil {
+		return fmt.Errorf("recording build: %s", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for i, name := range l.Locks {
+		for {
+			err := b.Lock(ctx, name, l)
+			if err == nil {
+				break
+			}
+			if _, ok := err.(*LockedError); !ok || time.Now().After(deadline) {
+				l := &Lease{ID: l.ID, Locks: l.Locks[:i]}
+				if rerr := Release(context.Background(), b, l); rerr != nil {
+					log.Printf("[WARN] Failed to release state: %s", rerr)
+				}
+				return err
+			}
+			log.Printf("[DEBUG] %s, retrying", err)
+			select {
+			case <-time.After(lockRetryInterval):
+			case <-ctx.Done():
+				err = ctx.Err()
+				l := &Lease{ID: l.ID, Locks: l.Locks[:i]}
+				if rerr := Release(context.Background(), b, l); rerr != nil {
+					log.Printf("[WARN] Failed to release state: %s", rerr)
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var lockRetryInterval = 5 * time.Second
+
+// Release unlocks the names of l and forgets its build.
+func Release(ctx context.Context, b Backend, l *Lease) error {
+	var err error
+	for i := len(l.Locks) - 1; i >= 0; i-- {
+		if uerr := b.Unlock(ctx, l.Locks[i], l.ID); uerr != nil {
+			err = multierror.Append(err, fmt.Errorf("unlocking %q: %s", l.Locks[i], uerr))
+		}
+	}
+	if derr := b.DeleteBuild(ctx, l.ID); derr != nil {
+		err = multierror.Append(err, fmt.Errorf("forgetting build: %s", derr))
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+package state
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testLocal(t *testing.T) (*Local, func()) {
+	dir, err := ioutil.TempDir("", "packer-state")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return NewLocal(dir), func() { os.RemoveAll(dir) }
+}
+
+func testLease(t *testing.T, build string, names ...string) *Lease {
+	l, err := NewLease(build, names)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return l
+}
+
+func TestLocal_locks(t *testing.T) {
+	b, cleanup := testLocal(t)
+	defer cleanup()
+	ctx := context.Background()
+	a, other := testLease(t, "a"), testLease(t, "b")
+
+	if err := b.Lock(ctx, "images/base", a); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	err := b.Lock(ctx, "images/base", other)
+	lerr, ok := err.(*LockedError)
+	if !ok {
+		t.Fatalf("should be locked: %v", err)
+	}
+	if lerr.Holder == nil || lerr.Holder.ID != a.ID {
+		t.Fatalf("bad holder: %#v", lerr.Holder)
+	}
+
+	locks, err := b.Locks(ctx)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(locks) != 1 || locks["images/base"].ID != a.ID {
+		t.Fatalf("bad locks: %#v", locks)
+	}
+
+	if err := b.Unlock(ctx, "images/base", other.ID); err == nil {
+		t.Fatal("should not unlock the lock of another build")
+	}
+	if err := b.Unlock(ctx, "images/base", a.ID); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := b.Lock(ctx, "images/base", other); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	// Forced
+	if err := b.Unlock(ctx, "images/base", ""); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := b.Unlock(ctx, "images/base", a.ID); err != nil {
+		t.Fatalf("unlocking an unlocked name should succeed: %s", err)
+	}
+}
+
+func TestLocal_builds(t *testing.T) {
+	b, cleanup := testLocal(t)
+	defer cleanup()
+	ctx := context.Background()
+	l := testLease(t, "a", "x")
+
+	if err := b.PutBuild(ctx, l); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	builds, err := b.Builds(ctx)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(builds) != 1 || !reflect.DeepEqual(builds[0], l) {
+		t.Fatalf("bad builds: %#v", builds)
+	}
+	if err := b.DeleteBuild(ctx, l.ID); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if builds, _ := b.Builds(ctx); len(builds) != 0 {
+		t.Fatalf("bad builds: %#v", builds)
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	b, cleanup := testLocal(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	a := testLease(t, "a", "y", "x")
+	if err := Acquire(ctx, b, a, 0); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Locking x fails, so y is unlocked again.
+	other := testLease(t, "b", "x", "z")
+	if _, ok := Acquire(ctx, b, other, 0).(*LockedError); !ok {
+		t.Fatal("should be locked")
+	}
+	locks, _ := b.Locks(ctx)
+	if len(locks) != 2 || locks["x"].ID != a.ID || locks["y"].ID != a.ID {
+		t.Fatalf("bad locks: %#v", locks)
+	}
+	builds, _ := b.Builds(ctx)
+	if len(builds) != 1 {
+		t.Fatalf("bad builds: %#v", builds)
+	}
+
+	if err := Release(ctx, b, a); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if locks, _ := b.Locks(ctx); len(locks) != 0 {
+		t.Fatalf("bad locks: %#v", locks)
+	}
+	if builds, _ := b.Builds(ctx); len(builds) != 0 {
+		t.Fatalf("bad builds: %#v", builds)
+	}
+}
+
+func TestAcquire_wait(t *testing.T) {
+	defer func(d time.Duration) { lockRetryInterval = d }(lockRetryInterval)
+	lockRetryInterval = 10 * time.Millisecond
+
+	b, cleanup := testLocal(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	a := testLease(t, "a", "x")
+	if err := Acquire(ctx, b, a, 0); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		Release(ctx, b, a)
+	}()
+	if err := Acquire(ctx, b, testLease(t, "b", "x"), time.Minute); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := map[string]bool{
+		"file:state":                             true,
+		"file:///tmp/state":                      true,
+		"file:":                                  false,
+		"s3://bucket/prefix?dynamodb_table=lock": true,
+		"s3://bucket/prefix":                     false,
+		"consul://127.0.0.1:8500/packer":         true,
+		"ftp://example.com":                      false,
+	}
+	for address, ok := range cases {
+		if _, err := New(address); (err == nil) != ok {
+			t.Fatalf("%s: bad result: %v", address, err)
+		}
+	}
+}
+
+func TestLocal_cache(t *testing.T) {
+	b, cleanup := testLocal(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	e, err := b.Cached(ctx, "abc")
+	if err != nil || e != nil {
+		t.Fatalf("nothing should be cached: %#v, %v", e, err)
+	}
+
+	want := &CacheEntry{
+		Key:     "abc",
+		Build:   "amazon-ebs.base",
+		Created: time.Now().UTC().Truncate(time.Second),
+		Artifacts: []CachedArtifact{
+			{BuilderID: "mitchellh.amazonebs", ID: "us-east-1:ami-1", String: "AMIs were created"},
+		},
+	}
+	if err := b.PutCached(ctx, want); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	e, err = b.Cached(ctx, "abc")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(e, want) {
+		t.Fatalf("bad entry: %#v", e)
+	}
+}
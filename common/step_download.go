@@ -18,6 +18,7 @@ import (
 	urlhelper "github.com/hashicorp/go-getter/v2/helper/url"
 
 	"github.com/hashicorp/packer/common/filelock"
+	commonhelper "github.com/hashicorp/packer/helper/common"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
@@ -60,8 +61,21 @@ var defaultGetterClient = getter.Client{
 }
 
 func init() {
-	defaultGetterClient.Getters = append(defaultGetterClient.Getters, new(gcs.Getter))
-	defaultGetterClient.Getters = append(defaultGetterClient.Getters, new(s3.Getter))
+	getters := make([]getter.Getter, 0, len(defaultGetterClient.Getters)+2)
+	for _, g := range defaultGetterClient.Getters {
+		if _, ok := g.(*getter.HttpGetter); ok {
+			// Download with the proxy, the CA bundle and the client
+			// certificate of the environment.
+			g = &getter.HttpGetter{
+				Netrc:  true,
+				Client: commonhelper.HttpClient(),
+			}
+		}
+		getters = append(getters, g)
+	}
+	getters = append(getters, new(gcs.Getter))
+	getters = append(getters, new(s3.Getter))
+	defaultGetterClient.Getters = getters
 }
 
 func (s *StepDownload) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
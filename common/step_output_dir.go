@@ -13,30 +13,50 @@ import (
 )
 
 // StepOutputDir sets up the output directory by creating it if it does
-// not exist, deleting it if it does exist and we're forcing, and cleaning
-// it up when we're done with it.
+// not exist, handling it according to OnConflict if it does exist, and
+// cleaning it up when we're done with it.
 type StepOutputDir struct {
+	// Force deletes an existing output directory, when OnConflict is not
+	// set.
 	Force bool
-	Path  string
-
-	cleanup bool
+	// OnConflict is what to do with an existing output directory: one of
+	// the packer.Conflict* modes. An output directory being replaced is
+	// moved aside, and only deleted once the build succeeded.
+	OnConflict string
+	Path       string
+
+	cleanup      bool
+	previousPath string
 }
 
 func (s *StepOutputDir) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	ui := state.Get("ui").(packer.Ui)
 
+	onConflict := s.OnConflict
+	if onConflict == "" && s.Force {
+		onConflict = packer.ConflictForce
+	}
+
 	if _, err := os.Stat(s.Path); err == nil {
-		if !s.Force {
-			err := fmt.Errorf(
-				"Output directory exists: %s\n\n"+
-					"Use the force flag to delete it prior to building.",
-				s.Path)
-			state.Put("error", err)
-			return multistep.ActionHalt
+		action := HandleArtifactConflict(state, onConflict, "Output directory", s.Path)
+		if action != multistep.ActionContinue {
+			return action
 		}
 
-		ui.Say("Deleting previous output directory...")
-		os.RemoveAll(s.Path)
+		if onConflict == packer.ConflictReplace {
+			ui.Say("Moving previous output directory aside until the build succeeds...")
+			previousPath := s.Path + ".packer-previous"
+			os.RemoveAll(previousPath)
+			if err := os.Rename(s.Path, previousPath); err != nil {
+				err = fmt.Errorf("Couldn't move previous output directory: %s", err)
+				state.Put("error", err)
+				return multistep.ActionHalt
+			}
+			s.previousPath = previousPath
+		} else {
+			ui.Say("Deleting previous output directory...")
+			os.RemoveAll(s.Path)
+		}
 	}
 
 	// Enable cleanup
@@ -62,13 +82,14 @@ func (s *StepOutputDir) Run(ctx context.Context, state multistep.StateBag) multi
 }
 
 func (s *StepOutputDir) Cleanup(state multistep.StateBag) {
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	defer s.cleanupPrevious(state, cancelled || halted)
+
 	if !s.cleanup {
 		return
 	}
 
-	_, cancelled := state.GetOk(multistep.StateCancelled)
-	_, halted := state.GetOk(multistep.StateHalted)
-
 	if cancelled || halted {
 		ui := state.Get("ui").(packer.Ui)
 
@@ -84,3 +105,26 @@ func (s *StepOutputDir) Cleanup(state multistep.StateBag) {
 		}
 	}
 }
+
+// cleanupPrevious deletes the previous output directory once it was
+// replaced, or restores it when the build failed.
+func (s *StepOutputDir) cleanupPrevious(state multistep.StateBag, failed bool) {
+	if s.previousPath == "" {
+		return
+	}
+	ui := state.Get("ui").(packer.Ui)
+
+	if !failed {
+		ui.Say("Deleting previous output directory...")
+		if err := os.RemoveAll(s.previousPath); err != nil {
+			log.Printf("Error removing previous output dir: %s", err)
+		}
+		return
+	}
+
+	ui.Say("Restoring previous output directory...")
+	os.RemoveAll(s.Path)
+	if err := os.Rename(s.previousPath, s.Path); err != nil {
+		ui.Error(fmt.Sprintf("Couldn't restore previous output directory %s: %s", s.previousPath, err))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/packer/helper/multistep"
@@ -133,3 +134,70 @@ func TestStepOutputDir_halted(t *testing.T) {
 		t.Fatal("should not exist")
 	}
 }
+
+func TestStepOutputDir_onConflict(t *testing.T) {
+	cases := []struct {
+		onConflict string
+		halted     bool
+
+		action    multistep.StepAction
+		err       bool
+		skipped   bool
+		keptAfter bool
+	}{
+		{onConflict: packer.ConflictFail, action: multistep.ActionHalt, err: true, keptAfter: true},
+		{onConflict: packer.ConflictForce, action: multistep.ActionContinue},
+		{onConflict: packer.ConflictReplace, action: multistep.ActionContinue},
+		{onConflict: packer.ConflictReplace, halted: true, action: multistep.ActionContinue, keptAfter: true},
+		{onConflict: packer.ConflictSkip, action: multistep.ActionHalt, skipped: true, keptAfter: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.onConflict, func(t *testing.T) {
+			state := testState(t)
+			step := testStepOutputDir(t)
+			step.OnConflict = tc.onConflict
+
+			// Make the dir, with a previous artifact
+			if err := os.MkdirAll(step.Path, 0755); err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			defer os.RemoveAll(step.Path)
+			previous := filepath.Join(step.Path, "previous")
+			if err := ioutil.WriteFile(previous, nil, 0644); err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			exists := func() bool {
+				_, err := os.Stat(previous)
+				return err == nil
+			}
+
+			if action := step.Run(context.Background(), state); action != tc.action {
+				t.Fatalf("bad action: %#v", action)
+			}
+			if _, ok := state.GetOk("error"); ok != tc.err {
+				t.Fatalf("error: %t, expected %t", ok, tc.err)
+			}
+			if Skipped(state) != tc.skipped {
+				t.Fatalf("skipped: %t, expected %t", Skipped(state), tc.skipped)
+			}
+			if exists() && tc.onConflict != packer.ConflictFail && tc.onConflict != packer.ConflictSkip {
+				t.Fatal("previous artifact should not be in the output directory")
+			}
+			if _, err := os.Stat(step.Path + ".packer-previous"); (err == nil) != (tc.onConflict == packer.ConflictReplace) {
+				t.Fatalf("previous output directory moved aside: %t", err == nil)
+			}
+
+			if tc.halted || tc.action == multistep.ActionHalt {
+				state.Put(multistep.StateHalted, true)
+			}
+			step.Cleanup(state)
+			if exists() != tc.keptAfter {
+				t.Fatalf("previous artifact kept: %t, expected %t", exists(), tc.keptAfter)
+			}
+			if _, err := os.Stat(step.Path + ".packer-previous"); err == nil {
+				t.Fatal("previous output directory should be gone")
+			}
+		})
+	}
+}
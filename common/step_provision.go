@@ -68,6 +68,12 @@ func PopulateProvisionHookData(state multistep.StateBag) map[string]interface{}
 		hookData["PackerHTTPAddr"] = fmt.Sprintf("%s:%s", hookData["PackerHTTPIP"], hookData["PackerHTTPPort"])
 	}
 
+	// The facts of the guest are detected by StepConnect.
+	facts, _ := state.Get("guest_facts").(*communicator.GuestFacts)
+	for k, v := range facts.GeneratedData() {
+		hookData[k] = v
+	}
+
 	// Read communicator data into hook data
 	comm, ok := state.GetOk("communicator_config")
 	if !ok {
@@ -137,6 +143,11 @@ func (s *StepProvision) runWithHook(ctx context.Context, state multistep.StateBa
 					// We don't overwrite the error if it's a cleanup
 					// provisioner being run.
 					state.Put("error", err)
+					if packer.IsAbortError(err) {
+						// A provisioner asked to stop the build without
+						// cleaning up.
+						state.Put("aborted", true)
+					}
 				} else if hooktype == packer.HookCleanupProvision {
 					origErr := state.Get("error").(error)
 					state.Put("error", fmt.Errorf("Cleanup failed: %s. "+
@@ -162,11 +173,23 @@ func (s *StepProvision) Run(ctx context.Context, state multistep.StateBag) multi
 	return s.runWithHook(ctx, state, packer.HookProvision)
 }
 
+// Resume skips the provisioners when they all ran before the packer process
+// of the resumed build died.
+func (s *StepProvision) Resume(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+	ui.Say("Provisioning completed before the build was interrupted, skipping it")
+	state.Put("generated_data", PopulateProvisionHookData(state))
+	return multistep.ActionContinue
+}
+
 func (s *StepProvision) Cleanup(state multistep.StateBag) {
 	// We have a "final" provisioner that gets defined by "error-cleanup-provisioner"
 	// which we only call if there's an error during the provision run and
 	// the "error-cleanup-provisioner" is defined.
+	if _, ok := state.GetOk("aborted"); ok {
+		return
+	}
 	if _, ok := state.GetOk("error"); ok {
-		s.runWithHook(context.Background(), state, packer.HookCleanupProvision)
+		s.runWithHook(multistep.CleanupContext(state), state, packer.HookCleanupProvision)
 	}
 }
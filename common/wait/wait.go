@@ -0,0 +1,205 @@
+// Package wait polls cloud resources until they reach a state, like an
+// instance becoming running or getting an IP address. Polls back off
+// exponentially, waits are bounded by a timeout and by their context, and
+// state changes are reported as they happen.
+package wait
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// Defaults of Config.
+const (
+	DefaultMinDelay   = 2 * time.Second
+	DefaultMaxDelay   = 30 * time.Second
+	DefaultMultiplier = 1.5
+)
+
+// Config configures a wait.
+type Config struct {
+	// Description is what is waited for, like "droplet 42 to become
+	// active"; it is used in the logs, the progress and the errors.
+	Description string
+	// Timeout bounds the wait. Zero means no limit but the context.
+	Timeout time.Duration
+
+	// MinDelay is the delay before the second poll; the delay is
+	// multiplied by Multiplier after each poll, up to MaxDelay.
+	MinDelay, MaxDelay time.Duration
+	Multiplier         float64
+
+	// Progress is called after the first poll and after every poll that
+	// changed the state.
+	Progress func(Progress)
+}
+
+// Progress is the state of a wait.
+type Progress struct {
+	Description string
+	// State is the last state polled.
+	State   string
+	Attempt int
+	Elapsed time.Duration
+}
+
+// TimeoutError is returned when the timeout of a wait expired.
+type TimeoutError struct {
+	Description string
+	Timeout     time.Duration
+	// LastState is the last state polled, if any.
+	LastState string
+}
+
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("timeout after %s while waiting for %s", e.Timeout, e.Description)
+	if e.LastState != "" {
+		msg += fmt.Sprintf(", last state: %q", e.LastState)
+	}
+	return msg
+}
+
+// UnexpectedStateError is returned by ForState when the state is neither a
+// pending nor a target state.
+type UnexpectedStateError struct {
+	Description string
+	State       string
+	Target      []string
+}
+
+func (e *UnexpectedStateError) Error() string {
+	return fmt.Sprintf("unexpected state %q while waiting for %s, wanted %s", e.State, e.Description, strings.Join(e.Target, " or "))
+}
+
+// Condition polls the waited resource: it returns whether the wait is over
+// and the current state. An error stops the wait.
+type Condition func(ctx context.Context) (done bool, state string, err error)
+
+// Until polls cond until it is done, fails, the timeout expires or ctx is
+// done.
+func (c Config) Until(ctx context.Context, cond Condition) error {
+	minDelay, maxDelay, multiplier := c.MinDelay, c.MaxDelay, c.Multiplier
+	if minDelay == 0 {
+		minDelay = DefaultMinDelay
+	}
+	if maxDelay == 0 {
+		maxDelay = DefaultMaxDelay
+	}
+	if maxDelay < minDelay {
+		maxDelay = minDelay
+	}
+	if multiplier < 1 {
+		multiplier = DefaultMultiplier
+	}
+
+	var deadline <-chan time.Time
+	if c.Timeout > 0 {
+		timer := time.NewTimer(c.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	log.Printf("[INFO] Waiting for %s (timeout: %s)", c.Description, c.Timeout)
+	start := time.Now()
+	delay := minDelay
+	lastState := ""
+	for attempt := 1; ; attempt++ {
+		done, state, err := cond(ctx)
+		if err != nil {
+			return err
+		}
+		if attempt == 1 || state != lastState {
+			log.Printf("[DEBUG] Waiting for %s: state %q (attempt %d)", c.Description, state, attempt)
+			if c.Progress != nil {
+				c.Progress(Progress{
+					Description: c.Description,
+					State:       state,
+					Attempt:     attempt,
+					Elapsed:     time.Since(start),
+				})
+			}
+		}
+		lastState = state
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return &TimeoutError{Description: c.Description, Timeout: c.Timeout, LastState: lastState}
+		case <-time.After(delay):
+		}
+		delay = time.Duration(float64(delay) * multiplier)
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+}
+
+// StateRefreshFunc polls a resource, returning it and its state.
+type StateRefreshFunc func(ctx context.Context) (result interface{}, state string, err error)
+
+// ForState polls refresh until the state is one of target, and returns the
+// last result. When pending is set, other states fail the wait.
+func (c Config) ForState(ctx context.Context, refresh StateRefreshFunc, pending, target []string) (interface{}, error) {
+	var result interface{}
+	err := c.Until(ctx, func(ctx context.Context) (bool, string, error) {
+		var state string
+		var err error
+		result, state, err = refresh(ctx)
+		if err != nil {
+			return false, state, err
+		}
+		if contains(target, state) {
+			return true, state, nil
+		}
+		if len(pending) > 0 && !contains(pending, state) {
+			return false, state, &UnexpectedStateError{Description: c.Description, State: state, Target: target}
+		}
+		return false, state, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ForValue polls get until it returns a value, like an IP address, and
+// returns it.
+func (c Config) ForValue(ctx context.Context, get func(ctx context.Context) (string, error)) (string, error) {
+	var value string
+	err := c.Until(ctx, func(ctx context.Context) (bool, string, error) {
+		var err error
+		value, err = get(ctx)
+		return value != "", value, err
+	})
+	return value, err
+}
+
+// UiProgress returns a Progress function reporting the state changes of a
+// wait to ui.
+func UiProgress(ui packer.Ui) func(Progress) {
+	return func(p Progress) {
+		if p.State == "" {
+			ui.Message(fmt.Sprintf("Waiting for %s...", p.Description))
+			return
+		}
+		ui.Message(fmt.Sprintf("Waiting for %s: %s (%s)", p.Description, p.State, p.Elapsed.Round(time.Second)))
+	}
+}
+
+func contains(states []string, state string) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
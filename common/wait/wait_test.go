@@ -0,0 +1,114 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// states returns a StateRefreshFunc returning states in order, then the last
+// one forever.
+func states(s ...string) StateRefreshFunc {
+	i := 0
+	return func(context.Context) (interface{}, string, error) {
+		state := s[i]
+		if i < len(s)-1 {
+			i++
+		}
+		return i, state, nil
+	}
+}
+
+var fast = Config{Description: "test", MinDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+func TestForState(t *testing.T) {
+	c := fast
+	var progress []string
+	c.Progress = func(p Progress) { progress = append(progress, p.State) }
+
+	result, err := c.ForState(context.Background(), states("pending", "pending", "starting", "running"), []string{"pending", "starting"}, []string{"running"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != 3 {
+		t.Fatalf("unexpected result %v", result)
+	}
+	if expected := []string{"pending", "starting", "running"}; !reflect.DeepEqual(progress, expected) {
+		t.Fatalf("unexpected progress %v, expected %v", progress, expected)
+	}
+}
+
+func TestForState_unexpected(t *testing.T) {
+	_, err := fast.ForState(context.Background(), states("pending", "error"), []string{"pending"}, []string{"running"})
+	if _, ok := err.(*UnexpectedStateError); !ok {
+		t.Fatalf("expected an UnexpectedStateError, got %v", err)
+	}
+}
+
+func TestUntil_timeout(t *testing.T) {
+	c := fast
+	c.Timeout = 20 * time.Millisecond
+	_, err := c.ForState(context.Background(), states("pending"), nil, []string{"running"})
+	terr, ok := err.(*TimeoutError)
+	if !ok {
+		t.Fatalf("expected a TimeoutError, got %v", err)
+	}
+	if terr.LastState != "pending" {
+		t.Fatalf("unexpected last state %q", terr.LastState)
+	}
+}
+
+func TestUntil_cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := fast.ForState(ctx, states("pending"), nil, []string{"running"})
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestUntil_error(t *testing.T) {
+	expected := errors.New("boom")
+	err := fast.Until(context.Background(), func(context.Context) (bool, string, error) {
+		return false, "", expected
+	})
+	if err != expected {
+		t.Fatalf("expected %v, got %v", expected, err)
+	}
+}
+
+func TestUntil_backoff(t *testing.T) {
+	c := Config{MinDelay: 10 * time.Millisecond, MaxDelay: 40 * time.Millisecond, Multiplier: 2}
+	var polls []time.Time
+	err := c.Until(context.Background(), func(context.Context) (bool, string, error) {
+		polls = append(polls, time.Now())
+		return len(polls) == 5, "", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10, 20, 40, 40ms.
+	expected := []time.Duration{10, 20, 40, 40}
+	for i, d := range expected {
+		if got := polls[i+1].Sub(polls[i]); got < d*time.Millisecond {
+			t.Errorf("delay %d: %s, expected at least %dms", i, got, d)
+		}
+	}
+}
+
+func TestForValue(t *testing.T) {
+	values := []string{"", "", "10.0.0.2"}
+	ip, err := fast.ForValue(context.Background(), func(context.Context) (string, error) {
+		v := values[0]
+		values = values[1:]
+		return v, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip != "10.0.0.2" {
+		t.Fatalf("unexpected value %q", ip)
+	}
+}
@@ -0,0 +1,182 @@
+// Package memory implements an in-memory communicator, for the tests of the
+// builders, provisioners and tools using a communicator without a guest. Its
+// files are kept in memory and its commands are run by a Handler, by default
+// a small subset of the POSIX shell.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// Handler runs cmd on c and returns its exit status. It must return when ctx
+// is cancelled.
+type Handler func(ctx context.Context, c *Communicator, cmd *packer.RemoteCmd) int
+
+// Communicator is an in-memory communicator. Its zero value is ready to
+// use.
+type Communicator struct {
+	// Handler runs the commands. Defaults to Shell.
+	Handler Handler
+
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+var _ packer.Communicator = new(Communicator)
+
+func (c *Communicator) Start(ctx context.Context, cmd *packer.RemoteCmd) error {
+	handler := c.Handler
+	if handler == nil {
+		handler = Shell
+	}
+	if cmd.Stdin == nil {
+		cmd.Stdin = new(bytes.Buffer)
+	}
+	if cmd.Stdout == nil {
+		cmd.Stdout = ioutil.Discard
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = ioutil.Discard
+	}
+	go func() {
+		cmd.SetExited(handler(ctx, c, cmd))
+	}()
+	return nil
+}
+
+func (c *Communicator) Upload(dst string, r io.Reader, _ *os.FileInfo) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	c.WriteFile(dst, data)
+	return nil
+}
+
+// UploadDir uploads the files of src to dst, in a directory named after src
+// unless src ends with a slash, like rsync. The files whose path relative to
+// src, or name, matches a pattern of exclude are skipped.
+func (c *Communicator) UploadDir(dst string, src string, exclude []string) error {
+	if !strings.HasSuffix(src, "/") && !strings.HasSuffix(src, string(filepath.Separator)) {
+		dst = path.Join(dst, filepath.Base(src))
+	}
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if excluded(filepath.ToSlash(rel), exclude) {
+			return nil
+		}
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		c.WriteFile(path.Join(dst, filepath.ToSlash(rel)), data)
+		return nil
+	})
+}
+
+func (c *Communicator) Download(src string, w io.Writer) error {
+	data, ok := c.ReadFile(src)
+	if !ok {
+		return fmt.Errorf("%s: no such file", src)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// DownloadDir downloads the files of src to dst, in a directory named after
+// src unless src ends with a slash, like UploadDir.
+func (c *Communicator) DownloadDir(src string, dst string, exclude []string) error {
+	if !strings.HasSuffix(src, "/") {
+		dst = filepath.Join(dst, path.Base(src))
+	}
+	prefix := path.Clean(src) + "/"
+	for _, name := range c.Files() {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rel := strings.TrimPrefix(name, prefix)
+		if excluded(rel, exclude) {
+			continue
+		}
+		data, _ := c.ReadFile(name)
+		target := filepath.Join(dst, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile writes data to the file name, creating or replacing it.
+func (c *Communicator) WriteFile(name string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.files == nil {
+		c.files = make(map[string][]byte)
+	}
+	c.files[path.Clean(name)] = append([]byte(nil), data...)
+}
+
+// ReadFile returns the content of the file name, and whether it exists.
+func (c *Communicator) ReadFile(name string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.files[path.Clean(name)]
+	return append([]byte(nil), data...), ok
+}
+
+// RemoveFile removes the file name, and returns whether it existed.
+func (c *Communicator) RemoveFile(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.files[path.Clean(name)]
+	delete(c.files, path.Clean(name))
+	return ok
+}
+
+// Files returns the sorted names of the files.
+func (c *Communicator) Files() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.files))
+	for name := range c.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// excluded returns whether the relative path rel, or its name, matches one of
+// the patterns.
+func excluded(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
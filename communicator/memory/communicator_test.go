@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func run(t *testing.T, c *Communicator, command string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	cmd := &packer.RemoteCmd{Command: command, Stdout: &stdout, Stderr: &stderr}
+	if err := cmd.RunWithUi(context.Background(), c, packer.TestUi(t)); err != nil {
+		t.Fatalf("%q: %s", command, err)
+	}
+	return stdout.String(), stderr.String(), cmd.ExitStatus()
+}
+
+func TestShell(t *testing.T) {
+	c := new(Communicator)
+	cases := []struct {
+		command        string
+		stdout, stderr string
+		status         int
+	}{
+		{"echo 'hello  world' \"✓\"", "hello  world ✓\n", "", 0},
+		{"echo -n foo", "foo", "", 0},
+		{"echo oops >&2", "", "oops\n", 0},
+		{"echo one > /tmp/file", "", "", 0},
+		{"echo two >> /tmp/file", "", "", 0},
+		{"cat /tmp/file", "one\ntwo\n", "", 0},
+		{"cat /tmp/missing", "", "cat: /tmp/missing: No such file or directory\n", 1},
+		{"rm /tmp/file", "", "", 0},
+		{"rm -f /tmp/file", "", "", 0},
+		{"exit 42", "", "", 42},
+		{"false", "", "", 1},
+		{"true", "", "", 0},
+		{"unknown", "", "sh: unknown: command not found\n", 127},
+	}
+	for _, tc := range cases {
+		stdout, stderr, status := run(t, c, tc.command)
+		if stdout != tc.stdout || stderr != tc.stderr || status != tc.status {
+			t.Errorf("%q: expected %q, %q, %d, got %q, %q, %d",
+				tc.command, tc.stdout, tc.stderr, tc.status, stdout, stderr, status)
+		}
+	}
+}
+
+func TestShell_cancel(t *testing.T) {
+	c := new(Communicator)
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := &packer.RemoteCmd{Command: "sleep 3600"}
+	if err := c.Start(ctx, cmd); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("the command should exit once cancelled")
+	case status := <-waitCh(cmd):
+		if status != 143 {
+			t.Fatalf("expected exit status 143, got %d", status)
+		}
+	}
+}
+
+func waitCh(cmd *packer.RemoteCmd) <-chan int {
+	ch := make(chan int, 1)
+	go func() { ch <- cmd.Wait() }()
+	return ch
+}
+
+func TestCommunicator_files(t *testing.T) {
+	c := new(Communicator)
+	if err := c.Upload("/etc/motd", strings.NewReader("hello"), nil); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := c.Download("/etc/motd", &buf); err != nil || buf.String() != "hello" {
+		t.Fatalf("bad download: %q, %v", buf.String(), err)
+	}
+	if err := c.Download("/etc/missing", &buf); err == nil {
+		t.Fatal("downloading a missing file should fail")
+	}
+	if !c.RemoveFile("/etc/motd") || c.RemoveFile("/etc/motd") {
+		t.Fatal("the file should be removed once")
+	}
+}
+
+func TestCommunicator_dirs(t *testing.T) {
+	src, err := ioutil.TempDir("", "packer-memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	for name, data := range map[string]string{"a.txt": "a", "sub/b.txt": "b", "skip.log": "log"} {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := new(Communicator)
+	if err := c.UploadDir("/dst", src, []string{"*.log"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UploadDir("/dst", src+"/", nil); err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Base(src)
+	expected := []string{
+		"/dst/" + base + "/a.txt", "/dst/" + base + "/sub/b.txt",
+		"/dst/a.txt", "/dst/skip.log", "/dst/sub/b.txt",
+	}
+	sort.Strings(expected)
+	if files := c.Files(); !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected %v, got %v", expected, files)
+	}
+
+	dst, err := ioutil.TempDir("", "packer-memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+	if err := c.DownloadDir("/dst/"+base+"/", dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dst, "sub", "b.txt"))
+	if err != nil || string(data) != "b" {
+		t.Fatalf("bad downloaded file: %q, %v", data, err)
+	}
+}
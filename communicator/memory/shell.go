@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// Shell runs the simple commands of the POSIX shell below, with their
+// arguments quoted with single or double quotes, and their standard output
+// redirected with `>&2`, `> file` or `>> file`:
+//
+//	echo [-n] [arg...]
+//	cat [file...]
+//	rm [-f] file...
+//	sleep seconds
+//	exit [status]
+//	true
+//	false
+//
+// Other commands exit with 127, like in a shell. A command cancelled by its
+// context exits with 143, like when terminated.
+func Shell(ctx context.Context, c *Communicator, cmd *packer.RemoteCmd) int {
+	args, err := splitWords(cmd.Command)
+	if err != nil {
+		fmt.Fprintf(cmd.Stderr, "sh: %s\n", err)
+		return 2
+	}
+	if len(args) == 0 {
+		return 0
+	}
+
+	stdout := cmd.Stdout
+	var redirect func()
+	for i := 0; i < len(args); i++ {
+		var file string
+		var appending bool
+		switch arg := args[i]; {
+		case arg == ">&2" || arg == "1>&2":
+			stdout = cmd.Stderr
+		case arg == ">" || arg == ">>":
+			if i+1 == len(args) {
+				fmt.Fprintln(cmd.Stderr, "sh: syntax error: no file to redirect to")
+				return 2
+			}
+			file, appending = args[i+1], arg == ">>"
+			args = append(args[:i+1], args[i+2:]...)
+		case strings.HasPrefix(arg, ">>"):
+			file, appending = arg[2:], true
+		case strings.HasPrefix(arg, ">"):
+			file = arg[1:]
+		default:
+			continue
+		}
+		args = append(args[:i], args[i+1:]...)
+		i--
+		if file != "" {
+			buf := new(strings.Builder)
+			stdout = buf
+			redirect = func() {
+				data := []byte(buf.String())
+				if appending {
+					previous, _ := c.ReadFile(file)
+					data = append(previous, data...)
+				}
+				c.WriteFile(file, data)
+			}
+		}
+	}
+
+	status := runCommand(ctx, c, args, cmd.Stdin, stdout, cmd.Stderr)
+	if redirect != nil {
+		redirect()
+	}
+	return status
+}
+
+func runCommand(ctx context.Context, c *Communicator, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	switch name := args[0]; name {
+	case "echo":
+		newline := "\n"
+		if len(args) > 1 && args[1] == "-n" {
+			args, newline = args[1:], ""
+		}
+		fmt.Fprint(stdout, strings.Join(args[1:], " ")+newline)
+	case "cat":
+		if len(args) == 1 {
+			if _, err := io.Copy(stdout, stdin); err != nil {
+				fmt.Fprintf(stderr, "cat: %s\n", err)
+				return 1
+			}
+			return 0
+		}
+		status := 0
+		for _, file := range args[1:] {
+			data, ok := c.ReadFile(file)
+			if !ok {
+				fmt.Fprintf(stderr, "cat: %s: No such file or directory\n", file)
+				status = 1
+				continue
+			}
+			stdout.Write(data)
+		}
+		return status
+	case "rm":
+		force := len(args) > 1 && args[1] == "-f"
+		if force {
+			args = args[1:]
+		}
+		status := 0
+		for _, file := range args[1:] {
+			if !c.RemoveFile(file) && !force {
+				fmt.Fprintf(stderr, "rm: %s: No such file or directory\n", file)
+				status = 1
+			}
+		}
+		return status
+	case "sleep":
+		seconds, err := strconv.ParseFloat(strings.Join(args[1:], ""), 64)
+		if err != nil {
+			fmt.Fprintln(stderr, "sleep: invalid time interval")
+			return 1
+		}
+		select {
+		case <-time.After(time.Duration(seconds * float64(time.Second))):
+		case <-ctx.Done():
+			return 143
+		}
+	case "exit":
+		if len(args) == 1 {
+			return 0
+		}
+		status, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Fprintf(stderr, "sh: exit: %s: numeric argument required\n", args[1])
+			return 2
+		}
+		return status & 0xff
+	case "true":
+	case "false":
+		return 1
+	default:
+		fmt.Fprintf(stderr, "sh: %s: command not found\n", name)
+		return 127
+	}
+	return 0
+}
+
+// splitWords splits command into words, removing the quotes around them.
+func splitWords(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quoted string")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,272 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// CallbackServer is an SSH server guests connect to, to open a reverse
+// tunnel to their own SSH server, like with
+//
+//	ssh -N -R 22:localhost:22 -p 2222 packer@packer-host
+//
+// Packer then connects to the guest through that tunnel, so guests that can't
+// be connected to, like guests behind NAT, can still be built. The server only
+// accepts reverse tunnels; any user name is accepted, guests authenticate with
+// one of the authorized keys.
+type CallbackServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+
+	l sync.Mutex
+	// tunnel is the tunnel of the last guest connection.
+	tunnel *callbackTunnel
+	// changed is closed and replaced when tunnel changes.
+	changed chan struct{}
+	closed  bool
+}
+
+type callbackTunnel struct {
+	conn *ssh.ServerConn
+	addr string
+	port uint32
+}
+
+// NewCallbackServer listens on address for guests authenticated with one of
+// authorizedKeys, in the authorized_keys format. hostKey is the PEM encoded
+// private host key of the server; a key is generated when it is empty.
+func NewCallbackServer(address string, authorizedKeys []byte, hostKey []byte) (*CallbackServer, error) {
+	var keys [][]byte
+	for rest := authorizedKeys; len(bytes.TrimSpace(rest)) > 0; {
+		key, _, _, r, err := ssh.ParseAuthorizedKey(rest)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing authorized keys: %s", err)
+		}
+		keys = append(keys, key.Marshal())
+		rest = r
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no authorized keys for the callback server")
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			for _, k := range keys {
+				if bytes.Equal(k, key.Marshal()) {
+					return nil, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown key for %q", conn.User())
+		},
+	}
+	var signer ssh.Signer
+	var err error
+	if len(hostKey) > 0 {
+		signer, err = ssh.ParsePrivateKey(hostKey)
+	} else {
+		var key *ecdsa.PrivateKey
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err == nil {
+			signer, err = ssh.NewSignerFromKey(key)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error loading the callback host key: %s", err)
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	s := &CallbackServer{
+		listener: l,
+		config:   config,
+		changed:  make(chan struct{}),
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *CallbackServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close stops the server and closes the guest connections.
+func (s *CallbackServer) Close() error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.tunnel != nil {
+		s.tunnel.conn.Close()
+	}
+	close(s.changed)
+	return s.listener.Close()
+}
+
+func (s *CallbackServer) serve() {
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(c)
+	}
+}
+
+func (s *CallbackServer) handle(c net.Conn) {
+	c.SetDeadline(time.Now().Add(30 * time.Second))
+	conn, chans, reqs, err := ssh.NewServerConn(c, s.config)
+	if err != nil {
+		log.Printf("[DEBUG] SSH callback: handshake with %s failed: %s", c.RemoteAddr(), err)
+		c.Close()
+		return
+	}
+	c.SetDeadline(time.Time{})
+	log.Printf("[INFO] SSH callback: %s connected from %s", conn.User(), conn.RemoteAddr())
+
+	go func() {
+		for ch := range chans {
+			ch.Reject(ssh.Prohibited, "only reverse tunnels are allowed")
+		}
+	}()
+
+	var tunnel *callbackTunnel
+	for req := range reqs {
+		switch req.Type {
+		case "tcpip-forward":
+			var args struct {
+				Addr string
+				Port uint32
+			}
+			if err := ssh.Unmarshal(req.Payload, &args); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			var reply []byte
+			if args.Port == 0 {
+				// The port is not listened on: any port identifies the
+				// tunnel for the guest.
+				args.Port = 22
+				reply = ssh.Marshal(&struct{ Port uint32 }{args.Port})
+			}
+			tunnel = &callbackTunnel{conn: conn, addr: args.Addr, port: args.Port}
+			log.Printf("[INFO] SSH callback: tunnel opened by %s", conn.RemoteAddr())
+			s.setTunnel(nil, tunnel)
+			req.Reply(true, reply)
+		case "cancel-tcpip-forward":
+			req.Reply(true, nil)
+		default:
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}
+	log.Printf("[INFO] SSH callback: %s disconnected", conn.RemoteAddr())
+	if tunnel != nil {
+		s.setTunnel(tunnel, nil)
+	}
+}
+
+// setTunnel replaces the tunnel old with next; next replaces any tunnel when
+// old is nil.
+func (s *CallbackServer) setTunnel(old, next *callbackTunnel) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.closed || (old != nil && s.tunnel != old) {
+		return
+	}
+	if old == nil && s.tunnel != nil && s.tunnel.conn != next.conn {
+		// The guest reconnected, like after a reboot.
+		s.tunnel.conn.Close()
+	}
+	s.tunnel = next
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Dial waits for a guest tunnel, for up to timeout, and returns a connection
+// to the guest SSH server through it.
+func (s *CallbackServer) Dial(timeout time.Duration) (net.Conn, error) {
+	deadline := time.After(timeout)
+	for {
+		s.l.Lock()
+		tunnel, changed, closed := s.tunnel, s.changed, s.closed
+		s.l.Unlock()
+		if closed {
+			return nil, errors.New("the SSH callback server is closed")
+		}
+		if tunnel != nil {
+			return tunnel.dial()
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			return nil, fmt.Errorf("no guest connected to %s", s.Addr())
+		}
+	}
+}
+
+// ConnectFunc returns a function connecting to the guest SSH server through
+// the guest tunnel, suitable for use with the SSH communicator configuration.
+func (s *CallbackServer) ConnectFunc() func() (net.Conn, error) {
+	return func() (net.Conn, error) {
+		return s.Dial(15 * time.Second)
+	}
+}
+
+func (t *callbackTunnel) dial() (net.Conn, error) {
+	// The origin of the connections is the end of the tunnel on the Packer
+	// side.
+	origin, ok := t.conn.LocalAddr().(*net.TCPAddr)
+	if !ok {
+		origin = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+	}
+	payload := ssh.Marshal(&struct {
+		Addr       string
+		Port       uint32
+		OriginAddr string
+		OriginPort uint32
+	}{t.addr, t.port, origin.IP.String(), uint32(origin.Port)})
+	ch, reqs, err := t.conn.OpenChannel("forwarded-tcpip", payload)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening the guest tunnel: %s", err)
+	}
+	go ssh.DiscardRequests(reqs)
+	return &channelConn{
+		Channel: ch,
+		local:   t.conn.LocalAddr(),
+		remote: &net.TCPAddr{
+			IP:   net.ParseIP(t.addr),
+			Port: int(t.port),
+		},
+	}, nil
+}
+
+// channelConn is a net.Conn over an SSH channel.
+type channelConn struct {
+	ssh.Channel
+	local, remote net.Addr
+}
+
+var _ net.Conn = new(channelConn)
+
+func (c *channelConn) LocalAddr() net.Addr                { return c.local }
+func (c *channelConn) RemoteAddr() net.Addr               { return c.remote }
+func (c *channelConn) SetDeadline(t time.Time) error      { return nil }
+func (c *channelConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *channelConn) SetWriteDeadline(t time.Time) error { return nil }
@@ -0,0 +1,90 @@
+package ssh
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSigner(t *testing.T) ssh.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestCallbackServer(t *testing.T) {
+	guestKey := newTestSigner(t)
+	server, err := NewCallbackServer("127.0.0.1:0", ssh.MarshalAuthorizedKey(guestKey.PublicKey()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	if _, err := server.Dial(10 * time.Millisecond); err == nil {
+		t.Fatal("dial without a guest should fail")
+	}
+
+	// An unknown key is refused.
+	_, err = ssh.Dial("tcp", server.Addr().String(), &ssh.ClientConfig{
+		User:            "packer",
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(newTestSigner(t))},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	if err == nil {
+		t.Fatal("unknown key accepted")
+	}
+
+	// The guest connects and opens a reverse tunnel to an echo server.
+	guest, err := ssh.Dial("tcp", server.Addr().String(), &ssh.ClientConfig{
+		User:            "packer",
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(guestKey)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer guest.Close()
+	l, err := guest.Listen("tcp", "127.0.0.1:22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(c, c)
+				c.Close()
+			}()
+		}
+	}()
+
+	conn, err := server.Dial(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 5)
+	if _, err := io.ReadFull(conn, b); err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "hello" {
+		t.Fatalf("bad echo: %q", b)
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/packer/packer"
@@ -29,10 +31,24 @@ import (
 var ErrHandshakeTimeout = fmt.Errorf("Timeout during SSH handshake")
 
 type comm struct {
+	// connections counts the connections to the guest, atomically.
+	connections uint64
+
 	client  *ssh.Client
 	config  *Config
 	conn    net.Conn
 	address string
+
+	// sftpLock guards the SFTP fields, the communicator is shared by the
+	// steps and provisioners of a build. sftpConnection is the connection
+	// the SFTP server of the guest was detected for, sftpServer is the path
+	// of the uploaded sftp-server when it is used as the SFTP server, and
+	// sftpFallback is whether scp is used since the guest has none.
+	sftpLock       sync.Mutex
+	sftpDetected   bool
+	sftpConnection uint64
+	sftpServer     string
+	sftpFallback   bool
 }
 
 // TunnelDirection is the supported tunnel directions
@@ -66,6 +82,10 @@ type Config struct {
 	// Pty, if true, will request a pty from the remote end.
 	Pty bool
 
+	// PtyOutput is how the output of the commands run in the pty is
+	// rendered: one of the PtyOutput* modes, PtyOutputRaw when empty.
+	PtyOutput string
+
 	// DisableAgentForwarding, if true, will not forward the SSH agent.
 	DisableAgentForwarding bool
 
@@ -73,9 +93,23 @@ type Config struct {
 	// saying the connection failed.
 	HandshakeTimeout time.Duration
 
+	// BannerTimeout limits the amount of time we'll wait for the server to
+	// identify itself once connected. Zero means it is only limited by
+	// HandshakeTimeout.
+	BannerTimeout time.Duration
+
 	// UseSftp, if true, sftp will be used instead of scp for file transfers
 	UseSftp bool
 
+	// SftpServer is the local path of a static sftp-server binary, uploaded
+	// with scp and run as the SFTP server when the guest has no SFTP
+	// subsystem.
+	SftpServer string
+
+	// SftpFallback, if set, is called with the reason when the guest has no
+	// SFTP server and the files are transferred with scp instead.
+	SftpFallback func(reason error)
+
 	// KeepAliveInterval sets how often we send a channel request to the
 	// server. A value < 0 disables.
 	KeepAliveInterval time.Duration
@@ -83,6 +117,11 @@ type Config struct {
 	// Timeout is how long to wait for a read or write to succeed.
 	Timeout time.Duration
 
+	// LivenessTimeout, if set, is how long to wait for the server to reply
+	// to a keepalive request, sent every KeepAliveInterval, before closing
+	// the connection, failing the running commands.
+	LivenessTimeout time.Duration
+
 	Tunnels []TunnelSpec
 }
 
@@ -99,6 +138,7 @@ func New(address string, config *Config) (result *comm, err error) {
 		result = nil
 		return
 	}
+	result.useSftp()
 
 	return
 }
@@ -115,6 +155,9 @@ func (c *comm) Start(ctx context.Context, cmd *packer.RemoteCmd) (err error) {
 	session.Stderr = cmd.Stderr
 
 	if c.config.Pty {
+		session.Stdout = newPtyWriter(cmd.Stdout, c.config.PtyOutput)
+		session.Stderr = newPtyWriter(cmd.Stderr, c.config.PtyOutput)
+
 		// Request a PTY
 		termModes := ssh.TerminalModes{
 			ssh.ECHO:          0,     // do not echo
@@ -166,13 +209,22 @@ func (c *comm) Start(ctx context.Context, cmd *packer.RemoteCmd) (err error) {
 				log.Printf("[ERROR] Error occurred waiting for ssh session: %s", err.Error())
 			}
 		}
+		// Output the last lines rendered when they don't end with a
+		// newline.
+		for _, w := range []io.Writer{session.Stdout, session.Stderr} {
+			if w, ok := w.(*ptyWriter); ok {
+				if err := w.Flush(); err != nil {
+					log.Printf("[WARN] Error writing the output of the remote command: %s", err)
+				}
+			}
+		}
 		cmd.SetExited(exitStatus)
 	}()
 	return
 }
 
 func (c *comm) Upload(path string, input io.Reader, fi *os.FileInfo) error {
-	if c.config.UseSftp {
+	if c.useSftp() {
 		return c.sftpUploadSession(path, input, fi)
 	} else {
 		return c.scpUploadSession(path, input, fi)
@@ -181,7 +233,7 @@ func (c *comm) Upload(path string, input io.Reader, fi *os.FileInfo) error {
 
 func (c *comm) UploadDir(dst string, src string, excl []string) error {
 	log.Printf("[DEBUG] Upload dir '%s' to '%s'", src, dst)
-	if c.config.UseSftp {
+	if c.useSftp() {
 		return c.sftpUploadDirSession(dst, src, excl)
 	} else {
 		return c.scpUploadDirSession(dst, src, excl)
@@ -261,12 +313,44 @@ func (c *comm) DownloadDir(src string, dst string, excl []string) error {
 }
 
 func (c *comm) Download(path string, output io.Writer) error {
-	if c.config.UseSftp {
+	if c.useSftp() {
 		return c.sftpDownloadSession(path, output)
 	}
 	return c.scpDownloadSession(path, output)
 }
 
+// Dial opens a connection to addr from the guest, through the SSH
+// connection.
+func (c *comm) Dial(network, addr string) (net.Conn, error) {
+	if c.client == nil {
+		if err := c.reconnect(); err != nil {
+			return nil, err
+		}
+	}
+	if c.client == nil {
+		return nil, errors.New("client not available")
+	}
+	return c.client.Dial(network, addr)
+}
+
+// Reconnect closes the SSH connection and connects again, like after the
+// guest rebooted.
+func (c *comm) Reconnect() error {
+	return c.reconnect()
+}
+
+// Close closes the SSH connection, removing the uploaded sftp-server first.
+// The communicator connects again when it is used after that.
+func (c *comm) Close() error {
+	if c.client == nil {
+		return nil
+	}
+	c.removeSftpServer()
+	err := c.client.Close()
+	c.client, c.conn = nil, nil
+	return err
+}
+
 func (c *comm) newSession() (session *ssh.Session, err error) {
 	log.Println("[DEBUG] Opening new ssh session")
 	if c.client == nil {
@@ -300,6 +384,8 @@ func (c *comm) reconnect() (err error) {
 	// Set the conn and client to nil since we'll recreate it
 	c.conn = nil
 	c.client = nil
+	// The guest may have changed, like when it rebooted
+	atomic.AddUint64(&c.connections, 1)
 
 	log.Printf("[DEBUG] reconnecting to TCP connection for SSH")
 	c.conn, err = c.config.Connection()
@@ -319,6 +405,9 @@ func (c *comm) reconnect() (err error) {
 	if c.config.Timeout > 0 {
 		c.conn = &timeoutConn{c.conn, c.config.Timeout, c.config.Timeout}
 	}
+	if c.config.BannerTimeout > 0 {
+		c.conn = newBannerConn(c.conn, c.config.BannerTimeout)
+	}
 
 	log.Printf("[DEBUG] handshaking with SSH")
 
@@ -362,6 +451,13 @@ func (c *comm) reconnect() (err error) {
 	log.Printf("[DEBUG] handshake complete!")
 	if sshConn != nil {
 		c.client = ssh.NewClient(sshConn, sshChan, req)
+		if c.config.LivenessTimeout > 0 {
+			interval := c.config.KeepAliveInterval
+			if interval <= 0 {
+				interval = c.config.LivenessTimeout / 2
+			}
+			go monitorLiveness(sshConn, c.conn, interval, c.config.LivenessTimeout)
+		}
 	}
 	c.connectToAgent()
 	err = c.connectTunnels(sshConn)
@@ -621,11 +717,19 @@ func (c *comm) newSftpClient() (*sftp.Client, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	if err := session.RequestSubsystem("sftp"); err != nil {
-		return nil, err
+	c.sftpLock.Lock()
+	server := c.sftpServer
+	c.sftpLock.Unlock()
+	client, err := c.startSftp(session, server)
+	if err != nil {
+		session.Close()
 	}
+	return client, err
+}
 
+// startSftp starts an SFTP client on session, talking to the SFTP subsystem
+// of the guest, or to the sftp-server at server when not empty.
+func (c *comm) startSftp(session *ssh.Session, server string) (*sftp.Client, error) {
 	pw, err := session.StdinPipe()
 	if err != nil {
 		return nil, err
@@ -635,6 +739,15 @@ func (c *comm) newSftpClient() (*sftp.Client, error) {
 		return nil, err
 	}
 
+	if server != "" {
+		err = session.Start(server)
+	} else {
+		err = session.RequestSubsystem("sftp")
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	// Capture stdout so we can return errors to the user
 	var stdout bytes.Buffer
 	tee := io.TeeReader(pr, &stdout)
@@ -2,7 +2,10 @@ package ssh
 
 import (
 	"fmt"
+	"io"
+	"log"
 	"net"
+	"os/exec"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -86,3 +89,70 @@ func (c *bastionConn) Close() error {
 	c.Conn.Close()
 	return c.Bastion.Close()
 }
+
+// CommandConnectFunc is a convenience method for returning a function that
+// connects to a host through the standard input and output of a command,
+// like the ProxyCommand of OpenSSH.
+func CommandConnectFunc(name string, args ...string) func() (net.Conn, error) {
+	return func() (net.Conn, error) {
+		cmd := exec.Command(name, args...)
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		cmd.Stderr = &logWriter{prefix: name}
+		log.Printf("[INFO] Connecting through %s %v", name, args)
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("Error starting %s: %s", name, err)
+		}
+		return &commandConn{cmd: cmd, stdin: stdin, stdout: stdout}, nil
+	}
+}
+
+// commandConn is a net.Conn over the standard input and output of a
+// command.
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+var _ net.Conn = new(commandConn)
+
+func (c *commandConn) Read(b []byte) (int, error)  { return c.stdout.Read(b) }
+func (c *commandConn) Write(b []byte) (int, error) { return c.stdin.Write(b) }
+
+func (c *commandConn) Close() error {
+	c.stdin.Close()
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+	c.cmd.Wait()
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr                { return commandAddr(c.cmd.Path) }
+func (c *commandConn) RemoteAddr() net.Addr               { return commandAddr(c.cmd.Path) }
+func (c *commandConn) SetDeadline(t time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// commandAddr is the address of a connection through a command.
+type commandAddr string
+
+func (a commandAddr) Network() string { return "command" }
+func (a commandAddr) String() string  { return string(a) }
+
+// logWriter logs the lines written to it.
+type logWriter struct {
+	prefix string
+}
+
+func (w *logWriter) Write(b []byte) (int, error) {
+	log.Printf("[DEBUG] %s: %s", w.prefix, b)
+	return len(b), nil
+}
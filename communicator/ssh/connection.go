@@ -1,7 +1,9 @@
 package ssh
 
 import (
+	"errors"
 	"net"
+	"sync"
 	"time"
 )
 
@@ -28,3 +30,63 @@ func (c *timeoutConn) Write(b []byte) (int, error) {
 	}
 	return c.Conn.Write(b)
 }
+
+// ErrBannerTimeout is returned when the server didn't identify itself within
+// Config.BannerTimeout.
+var ErrBannerTimeout = errors.New("Timeout waiting for the SSH server identification")
+
+// bannerConn wraps a net.Conn and closes it when the server doesn't send its
+// identification line, which can follow other lines like a banner, before
+// a timeout.
+type bannerConn struct {
+	net.Conn
+
+	l        sync.Mutex
+	timer    *time.Timer
+	timedOut bool
+	line     []byte
+	done     bool
+}
+
+func newBannerConn(c net.Conn, timeout time.Duration) *bannerConn {
+	bc := &bannerConn{Conn: c}
+	bc.timer = time.AfterFunc(timeout, func() {
+		bc.l.Lock()
+		bc.timedOut = true
+		bc.l.Unlock()
+		c.Close()
+	})
+	return bc
+}
+
+func (c *bannerConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+
+	c.l.Lock()
+	defer c.l.Unlock()
+	if c.timedOut {
+		return n, ErrBannerTimeout
+	}
+	for _, ch := range b[:n] {
+		if c.done {
+			break
+		}
+		if ch != '\n' {
+			if len(c.line) < 4 {
+				c.line = append(c.line, ch)
+			}
+			continue
+		}
+		if string(c.line) == "SSH-" {
+			c.done = true
+			c.timer.Stop()
+		}
+		c.line = c.line[:0]
+	}
+	return n, err
+}
+
+func (c *bannerConn) Close() error {
+	c.timer.Stop()
+	return c.Conn.Close()
+}
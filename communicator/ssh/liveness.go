@@ -0,0 +1,43 @@
+package ssh
+
+import (
+	"io"
+	"log"
+	"time"
+)
+
+// requestSender sends global requests over an SSH connection, like
+// ssh.Conn.
+type requestSender interface {
+	SendRequest(name string, wantReply bool, payload []byte) (bool, []byte, error)
+}
+
+// monitorLiveness sends a keepalive request to the server every interval,
+// until the connection is closed, and closes conn when the server doesn't
+// reply within timeout, like when the guest crashed or was preempted: the
+// running commands then fail right away instead of waiting for the
+// connection to time out.
+func monitorLiveness(sender requestSender, conn io.Closer, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	replies := make(chan error, 1)
+	for range ticker.C {
+		go func() {
+			// Any reply, even a failure for the unknown request, shows
+			// that the server is alive.
+			_, _, err := sender.SendRequest("keepalive@openssh.com", true, nil)
+			replies <- err
+		}()
+		select {
+		case err := <-replies:
+			if err != nil {
+				// The connection is closed
+				return
+			}
+		case <-time.After(timeout):
+			log.Printf("[ERROR] The SSH server did not reply for %s, closing the connection", timeout)
+			conn.Close()
+			return
+		}
+	}
+}
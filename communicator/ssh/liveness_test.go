@@ -0,0 +1,64 @@
+package ssh
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeSender struct {
+	reply chan error
+}
+
+func (s *fakeSender) SendRequest(name string, wantReply bool, payload []byte) (bool, []byte, error) {
+	return false, nil, <-s.reply
+}
+
+type fakeCloser chan struct{}
+
+func (c fakeCloser) Close() error {
+	close(c)
+	return nil
+}
+
+func TestMonitorLiveness(t *testing.T) {
+	sender := &fakeSender{reply: make(chan error)}
+	closed := make(fakeCloser)
+	done := make(chan struct{})
+	go func() {
+		monitorLiveness(sender, closed, 10*time.Millisecond, 50*time.Millisecond)
+		close(done)
+	}()
+
+	// The server replies to the first keepalives, then stops replying.
+	for i := 0; i < 3; i++ {
+		sender.reply <- nil
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the connection of an unresponsive server wasn't closed")
+	}
+	<-done
+}
+
+func TestMonitorLiveness_closed(t *testing.T) {
+	sender := &fakeSender{reply: make(chan error, 1)}
+	sender.reply <- errors.New("closed")
+	closed := make(fakeCloser)
+	done := make(chan struct{})
+	go func() {
+		monitorLiveness(sender, closed, 10*time.Millisecond, time.Second)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("monitoring didn't stop with the connection")
+	}
+	select {
+	case <-closed:
+		t.Fatal("a closed connection was closed again")
+	default:
+	}
+}
@@ -0,0 +1,58 @@
+package ssh
+
+import (
+	"log"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// otpQuestions are the words of the keyboard-interactive questions asking for
+// a one-time password rather than the password.
+var otpQuestions = []string{
+	"code", "token", "otp", "one-time", "one time", "verification",
+	"authenticator", "2fa", "mfa", "factor",
+}
+
+// IsOTPQuestion tells whether a keyboard-interactive question asks for a
+// one-time password.
+func IsOTPQuestion(question string) bool {
+	q := strings.ToLower(question)
+	for _, w := range otpQuestions {
+		if strings.Contains(q, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// An implementation of ssh.KeyboardInteractiveChallenge that answers the
+// questions asking for a one-time password with a code returned by otp,
+// computed when the question is asked, and the other questions with the
+// password. When password is empty, every question is answered with a code.
+// The questions are logged.
+func OTPKeyboardInteractive(password string, otp func() (string, error)) ssh.KeyboardInteractiveChallenge {
+	return func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+		log.Printf("Keyboard interactive challenge: ")
+		log.Printf("-- User: %s", user)
+		log.Printf("-- Instructions: %s", instruction)
+		for i, question := range questions {
+			log.Printf("-- Question %d: %s", i+1, question)
+		}
+
+		answers := make([]string, len(questions))
+		for i, question := range questions {
+			if password != "" && !IsOTPQuestion(question) {
+				answers[i] = password
+				continue
+			}
+			code, err := otp()
+			if err != nil {
+				return nil, err
+			}
+			answers[i] = code
+		}
+
+		return answers, nil
+	}
+}
@@ -0,0 +1,44 @@
+package ssh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOTPKeyboardInteractive_Challenge(t *testing.T) {
+	otp := func() (string, error) { return "123456", nil }
+
+	p := OTPKeyboardInteractive("foo", otp)
+	result, err := p("foo", "bar", []string{"Password: ", "Verification code: "}, nil)
+	if err != nil {
+		t.Fatalf("err not nil: %s", err)
+	}
+	if !reflect.DeepEqual(result, []string{"foo", "123456"}) {
+		t.Fatalf("invalid answers: %#v", result)
+	}
+
+	p = OTPKeyboardInteractive("", otp)
+	result, err = p("foo", "bar", []string{"Password: "}, nil)
+	if err != nil {
+		t.Fatalf("err not nil: %s", err)
+	}
+	if !reflect.DeepEqual(result, []string{"123456"}) {
+		t.Fatalf("invalid answers: %#v", result)
+	}
+}
+
+func TestIsOTPQuestion(t *testing.T) {
+	cases := map[string]bool{
+		"Password: ":                   false,
+		"foo@bastion's password: ":     false,
+		"Verification code: ":          true,
+		"Enter your OTP: ":             true,
+		"One-time password (OATH): ":   true,
+		"Duo two-factor login for foo": true,
+	}
+	for question, expected := range cases {
+		if got := IsOTPQuestion(question); got != expected {
+			t.Fatalf("%q: got %t, expected %t", question, got, expected)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package ssh
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// The ways the output of the commands run in a PTY is rendered, set in
+// Config.PtyOutput.
+const (
+	// PtyOutputRaw passes the output as is, the default.
+	PtyOutputRaw = "raw"
+	// PtyOutputCooked renders the output like a terminal would: the lines
+	// rewritten with carriage returns, backspaces and cursor movements,
+	// like progress bars, are output once, as last rendered, and the colors
+	// are kept.
+	PtyOutputCooked = "cooked"
+	// PtyOutputStrip renders the output like PtyOutputCooked, without the
+	// colors.
+	PtyOutputStrip = "strip"
+)
+
+// PtyOutputs are the valid values of Config.PtyOutput.
+var PtyOutputs = []string{PtyOutputRaw, PtyOutputCooked, PtyOutputStrip}
+
+// ptyProgressInterval is how often a line rewritten in place, like a progress
+// bar, is output while it is not complete.
+var ptyProgressInterval = 10 * time.Second
+
+// The states of the parser of the escape sequences.
+const (
+	ptyGround = iota
+	ptyEscape
+	ptyCharset
+	ptyCSI
+	ptyOSC
+	ptyOSCEscape
+)
+
+// ptyCell is a character of the line being rendered, with the SGR sequences
+// of its style.
+type ptyCell struct {
+	r     rune
+	style string
+}
+
+// ptyWriter renders the output of a PTY line by line, like a terminal
+// would, and writes the lines to w as they complete.
+type ptyWriter struct {
+	w     io.Writer
+	strip bool
+	now   func() time.Time
+
+	m      sync.Mutex
+	state  int
+	params []byte
+	runes  []byte
+	line   []ptyCell
+	col    int
+	style  string
+	// progress is the line rendered when a carriage return rewrites it,
+	// output if it is rewritten rather than completed.
+	progress string
+	last     time.Time
+}
+
+// newPtyWriter returns a writer rendering the output of a PTY to w, in one
+// of the PtyOutput* modes, or w itself for PtyOutputRaw.
+func newPtyWriter(w io.Writer, mode string) io.Writer {
+	if w == nil || mode == "" || mode == PtyOutputRaw {
+		return w
+	}
+	return &ptyWriter{w: w, strip: mode == PtyOutputStrip, now: time.Now, last: time.Now()}
+}
+
+func (p *ptyWriter) Write(b []byte) (int, error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	for _, c := range b {
+		if err := p.feed(c); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush writes the line being rendered, when the output doesn't end with a
+// newline.
+func (p *ptyWriter) Flush() error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if len(p.line) == 0 {
+		return nil
+	}
+	return p.newline()
+}
+
+func (p *ptyWriter) feed(c byte) error {
+	switch p.state {
+	case ptyEscape:
+		switch c {
+		case '[':
+			p.state, p.params = ptyCSI, p.params[:0]
+		case ']':
+			p.state = ptyOSC
+		case '(', ')', '*', '+':
+			p.state = ptyCharset
+		default:
+			p.state = ptyGround
+		}
+		return nil
+	case ptyCharset:
+		p.state = ptyGround
+		return nil
+	case ptyCSI:
+		if c >= 0x40 && c <= 0x7e {
+			p.state = ptyGround
+			p.csi(string(p.params), c)
+		} else {
+			p.params = append(p.params, c)
+		}
+		return nil
+	case ptyOSC:
+		switch c {
+		case '\a':
+			p.state = ptyGround
+		case 0x1b:
+			p.state = ptyOSCEscape
+		}
+		return nil
+	case ptyOSCEscape:
+		p.state = ptyOSC
+		if c == '\\' {
+			p.state = ptyGround
+		}
+		return nil
+	}
+
+	switch c {
+	case '\n':
+		p.progress = ""
+		return p.newline()
+	case '\r':
+		p.col = 0
+		if len(p.line) > 0 && p.now().Sub(p.last) >= ptyProgressInterval {
+			p.progress = p.render()
+		}
+		return nil
+	}
+	if p.progress != "" {
+		// The line is rewritten, like a progress bar: output its
+		// progress from time to time.
+		if err := p.write(p.progress); err != nil {
+			return err
+		}
+		p.progress = ""
+	}
+
+	switch {
+	case c == 0x1b:
+		p.state = ptyEscape
+	case c == '\b':
+		if p.col > 0 {
+			p.col--
+		}
+	case c == '\t':
+		p.put('\t')
+	case c < 0x20 || c == 0x7f:
+		// Other control characters, like the bell.
+	default:
+		p.runes = append(p.runes, c)
+		if utf8.FullRune(p.runes) {
+			r, _ := utf8.DecodeRune(p.runes)
+			p.runes = p.runes[:0]
+			p.put(r)
+		}
+	}
+	return nil
+}
+
+// csi applies the control sequence with params and final byte c.
+func (p *ptyWriter) csi(params string, c byte) {
+	n := 0
+	if i := strings.IndexByte(params, ';'); i >= 0 {
+		n, _ = strconv.Atoi(params[:i])
+	} else {
+		n, _ = strconv.Atoi(params)
+	}
+
+	switch c {
+	case 'm':
+		if p.strip {
+			return
+		}
+		if params == "" || params == "0" {
+			p.style = ""
+		} else {
+			p.style += "\x1b[" + params + "m"
+		}
+	case 'K':
+		switch n {
+		case 0:
+			if p.col < len(p.line) {
+				p.line = p.line[:p.col]
+			}
+		case 1:
+			for i := 0; i <= p.col && i < len(p.line); i++ {
+				p.line[i] = ptyCell{r: ' '}
+			}
+		case 2:
+			p.line = p.line[:0]
+		}
+	case 'G':
+		if n < 1 {
+			n = 1
+		}
+		p.col = n - 1
+	case 'C':
+		if n < 1 {
+			n = 1
+		}
+		p.col += n
+	case 'D':
+		if n < 1 {
+			n = 1
+		}
+		if p.col -= n; p.col < 0 {
+			p.col = 0
+		}
+	}
+	// The other sequences, like the movements to other lines, can't be
+	// rendered line by line and are dropped.
+}
+
+// put writes r at the cursor.
+func (p *ptyWriter) put(r rune) {
+	for len(p.line) < p.col {
+		p.line = append(p.line, ptyCell{r: ' '})
+	}
+	cell := ptyCell{r: r, style: p.style}
+	if p.col < len(p.line) {
+		p.line[p.col] = cell
+	} else {
+		p.line = append(p.line, cell)
+	}
+	p.col++
+}
+
+// newline writes the line and starts a new one.
+func (p *ptyWriter) newline() error {
+	err := p.write(p.render())
+	p.line, p.col = p.line[:0], 0
+	return err
+}
+
+// write writes a rendered line.
+func (p *ptyWriter) write(line string) error {
+	p.last = p.now()
+	_, err := io.WriteString(p.w, line+"\n")
+	return err
+}
+
+// render returns the line as rendered.
+func (p *ptyWriter) render() string {
+	var b strings.Builder
+	style := ""
+	for _, cell := range p.line {
+		if cell.style != style {
+			if style != "" {
+				b.WriteString("\x1b[0m")
+			}
+			b.WriteString(cell.style)
+			style = cell.style
+		}
+		b.WriteRune(cell.r)
+	}
+	if style != "" {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package ssh
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestPtyWriter(t *testing.T) {
+	cases := []struct {
+		name   string
+		mode   string
+		input  []string
+		output string
+	}{
+		{"raw", PtyOutputRaw, []string{"a\r\x1b[32mb\x1b[0m\r\n"}, "a\r\x1b[32mb\x1b[0m\r\n"},
+		{"crlf", PtyOutputCooked, []string{"foo\r\nbar\r\n\r\n"}, "foo\nbar\n\n"},
+		{"progress", PtyOutputCooked, []string{"10%\r", "50%\r", "100%\r\ndone\r\n"}, "100%\ndone\n"},
+		{"erase", PtyOutputCooked, []string{"downloading 10%\r\x1b[Kdone\r\n"}, "done\n"},
+		{"erase whole line", PtyOutputCooked, []string{"abc\x1b[2K\x1b[1Gd\r\n"}, "d\n"},
+		{"backspace", PtyOutputCooked, []string{"ab\bc\n"}, "ac\n"},
+		{"cursor", PtyOutputCooked, []string{"abcd\x1b[3Dx\x1b[1Cy\n"}, "axcy\n"},
+		{"colors", PtyOutputCooked, []string{"\x1b[1;31merr\x1b[0m: x\n"}, "\x1b[1;31merr\x1b[0m: x\n"},
+		{"overwritten colors", PtyOutputCooked, []string{"\x1b[32mok\x1b[m\rK\n"}, "K\x1b[32mk\x1b[0m\n"},
+		{"strip", PtyOutputStrip, []string{"\x1b[1;31merr\x1b[0m: x\x1b]0;title\a\x1b(B\n"}, "err: x\n"},
+		{"split sequences", PtyOutputStrip, []string{"\x1b", "[3", "1mé", "\xc3", "\xa9\n"}, "éé\n"},
+		{"unterminated", PtyOutputCooked, []string{"last line"}, "last line\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := newPtyWriter(&out, tc.mode)
+			for _, s := range tc.input {
+				if _, err := w.Write([]byte(s)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if w, ok := w.(*ptyWriter); ok {
+				if err := w.Flush(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if out.String() != tc.output {
+				t.Fatalf("got %q, expected %q", out.String(), tc.output)
+			}
+		})
+	}
+}
+
+func TestPtyWriter_progress(t *testing.T) {
+	var out bytes.Buffer
+	now := time.Now()
+	w := newPtyWriter(&out, PtyOutputStrip).(*ptyWriter)
+	w.now, w.last = func() time.Time { return now }, now
+
+	w.Write([]byte("1%\r2%\r"))
+	if out.Len() != 0 {
+		t.Fatalf("the progress shouldn't be output yet: %q", out.String())
+	}
+	now = now.Add(ptyProgressInterval)
+	w.Write([]byte("3%\r4%\r"))
+	now = now.Add(ptyProgressInterval / 2)
+	w.Write([]byte("5%\r100%\r\n"))
+	if expected := "3%\n100%\n"; out.String() != expected {
+		t.Fatalf("got %q, expected %q", out.String(), expected)
+	}
+}
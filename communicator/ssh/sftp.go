@@ -0,0 +1,132 @@
+package ssh
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+)
+
+// sftpServerPath is where Config.SftpServer is uploaded on the guest.
+const sftpServerPath = "/tmp/packer-sftp-server"
+
+// useSftp returns whether the files are transferred with SFTP, detecting the
+// SFTP server of the guest first when it wasn't since the communicator
+// connected.
+func (c *comm) useSftp() bool {
+	if !c.config.UseSftp {
+		return false
+	}
+	c.sftpLock.Lock()
+	defer c.sftpLock.Unlock()
+	if !c.sftpDetected || c.sftpConnection != atomic.LoadUint64(&c.connections) {
+		c.detectSftp()
+	}
+	return !c.sftpFallback
+}
+
+// detectSftp checks that the guest runs an SFTP server, uploading and
+// running Config.SftpServer when it has no SFTP subsystem, and falls back to
+// scp when it still has none. Failing to open a session isn't a missing SFTP
+// server, and is left to the transfers to report. The caller holds sftpLock.
+func (c *comm) detectSftp() {
+	c.sftpDetected = false
+	c.sftpConnection = atomic.LoadUint64(&c.connections)
+	c.sftpServer = ""
+	c.sftpFallback = false
+
+	failure, err := c.probeSftp("")
+	if err != nil {
+		log.Printf("[ERROR] sftp: error detecting the SFTP server: %s", err)
+		return
+	}
+	c.sftpDetected = true
+	if failure == nil {
+		return
+	}
+	reason := fmt.Errorf("the guest has no SFTP subsystem: %s", failure)
+
+	if c.config.SftpServer != "" {
+		if err := c.uploadSftpServer(); err != nil {
+			reason = fmt.Errorf("%s, and uploading %s failed: %s", reason, c.config.SftpServer, err)
+		} else if failure, err := c.probeSftp(sftpServerPath); err != nil || failure != nil {
+			if err == nil {
+				err = failure
+			}
+			reason = fmt.Errorf("%s, and running %s failed: %s", reason, c.config.SftpServer, err)
+		} else {
+			log.Printf("[INFO] sftp: %s, using the uploaded %s", reason, sftpServerPath)
+			c.sftpServer = sftpServerPath
+			return
+		}
+	}
+
+	log.Printf("[WARN] sftp: %s, falling back to scp", reason)
+	c.sftpFallback = true
+	if c.config.SftpFallback != nil {
+		c.config.SftpFallback(reason)
+	}
+}
+
+// probeSftp starts an SFTP client on the SFTP subsystem of the guest, or on
+// server when not empty. It returns why the SFTP server failed to start, or
+// an error when no session could be opened to try.
+func (c *comm) probeSftp(server string) (failure error, err error) {
+	session, err := c.newSession()
+	if err != nil {
+		return nil, err
+	}
+	client, failure := c.startSftp(session, server)
+	if failure != nil {
+		session.Close()
+		return failure, nil
+	}
+	if err := client.Close(); err != nil {
+		log.Printf("[WARN] sftp: error closing the SFTP client: %s", err)
+	}
+	return nil, nil
+}
+
+// uploadSftpServer uploads Config.SftpServer to sftpServerPath with scp.
+func (c *comm) uploadSftpServer() error {
+	f, err := os.Open(c.config.SftpServer)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := c.scpUploadSession(sftpServerPath, f, &fi); err != nil {
+		return err
+	}
+
+	session, err := c.newSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	return session.Run("chmod 0755 " + sftpServerPath)
+}
+
+// removeSftpServer removes the sftp-server uploaded to sftpServerPath, if
+// any.
+func (c *comm) removeSftpServer() {
+	c.sftpLock.Lock()
+	defer c.sftpLock.Unlock()
+	if c.sftpServer == "" {
+		return
+	}
+	c.sftpDetected = false
+	c.sftpServer = ""
+
+	session, err := c.client.NewSession()
+	if err == nil {
+		err = session.Run("rm -f " + sftpServerPath)
+		session.Close()
+	}
+	if err != nil {
+		log.Printf("[WARN] sftp: error removing %s: %s", sftpServerPath, err)
+	}
+}
@@ -0,0 +1,139 @@
+package ssh
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// newMockSftpServer returns the address of an SSH server accepting the
+// sftp subsystem when hasSftp, and rejecting it otherwise. The commands run
+// are sent to commands when not nil, and succeed.
+func newMockSftpServer(t *testing.T, hasSftp bool, commands chan<- string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen for connection: %s", err)
+	}
+
+	go func() {
+		defer l.Close()
+		c, err := l.Accept()
+		if err != nil {
+			t.Errorf("Unable to accept incoming connection: %s", err)
+			return
+		}
+		defer c.Close()
+		_, chans, reqs, err := ssh.NewServerConn(c, serverConfig)
+		if err != nil {
+			t.Logf("Handshaking error: %v", err)
+			return
+		}
+		go ssh.DiscardRequests(reqs)
+		for newChannel := range chans {
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				t.Errorf("Unable to accept channel.")
+				return
+			}
+			go func() {
+				defer channel.Close()
+				for req := range requests {
+					if req.Type == "exec" && commands != nil {
+						req.Reply(true, nil)
+						commands <- string(req.Payload[4:])
+						channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+						return
+					}
+					ok := hasSftp && req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+					req.Reply(ok, nil)
+					if ok {
+						server, err := sftp.NewServer(channel, channel)
+						if err != nil {
+							t.Errorf("Unable to start the SFTP server: %s", err)
+							return
+						}
+						server.Serve()
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func testSftpComm(t *testing.T, hasSftp bool) (*comm, error) {
+	return testSftpCommCommands(t, hasSftp, nil)
+}
+
+func testSftpCommCommands(t *testing.T, hasSftp bool, commands chan<- string) (*comm, error) {
+	address := newMockSftpServer(t, hasSftp, commands)
+	var reason error
+	c, err := New(address, &Config{
+		Connection: func() (net.Conn, error) { return net.Dial("tcp", address) },
+		SSHConfig: &ssh.ClientConfig{
+			User:            "user",
+			Auth:            []ssh.AuthMethod{ssh.Password("pass")},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		},
+		UseSftp:      true,
+		SftpFallback: func(err error) { reason = err },
+	})
+	if err != nil {
+		t.Fatalf("error connecting to SSH: %s", err)
+	}
+	return c, reason
+}
+
+func TestDetectSftp(t *testing.T) {
+	c, reason := testSftpComm(t, true)
+	if reason != nil || !c.useSftp() {
+		t.Fatalf("SFTP should be used, fell back to scp: %v", reason)
+	}
+}
+
+func TestDetectSftp_fallback(t *testing.T) {
+	c, reason := testSftpComm(t, false)
+	if reason == nil || c.useSftp() {
+		t.Fatal("should fall back to scp when the guest has no SFTP subsystem")
+	}
+}
+
+func TestUseSftp_concurrent(t *testing.T) {
+	c, _ := testSftpComm(t, true)
+	// Detect the SFTP server again, like after a reconnection.
+	atomic.AddUint64(&c.connections, 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !c.useSftp() {
+				t.Error("SFTP should be used")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClose_removeSftpServer(t *testing.T) {
+	commands := make(chan string, 1)
+	c, _ := testSftpCommCommands(t, true, commands)
+	c.sftpServer = sftpServerPath
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if cmd := <-commands; cmd != "rm -f "+sftpServerPath {
+		t.Fatalf("the uploaded sftp-server should be removed, ran: %s", cmd)
+	}
+	if c.sftpServer != "" {
+		t.Fatal("the sftp-server should be forgotten")
+	}
+}
@@ -0,0 +1,87 @@
+package ssh
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketConnectFunc is a convenience method for returning a function that
+// connects to a host through a WebSocket endpoint forwarding its binary
+// messages to the host, like the application gateways only allowing HTTPS.
+func WebSocketConnectFunc(url string, header http.Header, tlsConfig *tls.Config) func() (net.Conn, error) {
+	return func() (net.Conn, error) {
+		dialer := &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			TLSClientConfig:  tlsConfig,
+			HandshakeTimeout: 15 * time.Second,
+		}
+		log.Printf("[INFO] Connecting through WebSocket %s", url)
+		ws, resp, err := dialer.Dial(url, header)
+		if err != nil {
+			if resp != nil {
+				return nil, fmt.Errorf("Error connecting to the WebSocket: %s: %s", err, resp.Status)
+			}
+			return nil, fmt.Errorf("Error connecting to the WebSocket: %s", err)
+		}
+		return &webSocketConn{Conn: ws}, nil
+	}
+}
+
+// webSocketConn is a net.Conn over the binary messages of a WebSocket.
+type webSocketConn struct {
+	*websocket.Conn
+
+	reader  io.Reader
+	writeMu sync.Mutex
+}
+
+var _ net.Conn = new(webSocketConn)
+
+func (c *webSocketConn) Read(b []byte) (int, error) {
+	for {
+		if c.reader == nil {
+			_, r, err := c.NextReader()
+			if err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					return 0, io.EOF
+				}
+				return 0, err
+			}
+			c.reader = r
+		}
+		n, err := c.reader.Read(b)
+		if err == io.EOF {
+			// The message is over, the next one continues the stream.
+			c.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (c *webSocketConn) Write(b []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.WriteMessage(websocket.BinaryMessage, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *webSocketConn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
@@ -0,0 +1,62 @@
+package ssh
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketConnectFunc(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		// Echo the stream back, split in two messages.
+		for {
+			_, b, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			half := len(b) / 2
+			ws.WriteMessage(websocket.BinaryMessage, b[:half])
+			ws.WriteMessage(websocket.BinaryMessage, b[half:])
+		}
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	if _, err := WebSocketConnectFunc(url, nil, nil)(); err == nil {
+		t.Fatal("connecting without the token should fail")
+	}
+
+	header := http.Header{"Authorization": []string{"Bearer secret"}}
+	conn, err := WebSocketConnectFunc(url, header, nil)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	data := []byte("SSH-2.0-Go\r\n")
+	if _, err := conn.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	read := make([]byte, len(data))
+	if _, err := io.ReadFull(conn, read); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, data) {
+		t.Fatalf("expected %q, got %q", data, read)
+	}
+}
@@ -0,0 +1,216 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// result is the result of a command.
+type result struct {
+	stdout, stderr string
+	status         int
+}
+
+// run runs command with stdin and returns its result once it exits.
+func run(ctx context.Context, comm packer.Communicator, command string, stdin io.Reader) (*result, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := &packer.RemoteCmd{
+		Command: command,
+		Stdin:   stdin,
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	}
+	if err := comm.Start(ctx, cmd); err != nil {
+		return nil, fmt.Errorf("%q failed to start: %s", command, err)
+	}
+	status, err := wait(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %s", command, err)
+	}
+	return &result{stdout: stdout.String(), stderr: stderr.String(), status: status}, nil
+}
+
+// wait returns the exit status of cmd, or an error when ctx is done first.
+func wait(ctx context.Context, cmd *packer.RemoteCmd) (int, error) {
+	exited := make(chan int, 1)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case status := <-exited:
+		return status, nil
+	case <-ctx.Done():
+		return 0, fmt.Errorf("the command didn't exit: %s", ctx.Err())
+	}
+}
+
+// testExec checks that the standard output and error of the commands are
+// kept apart, and that their unicode text is kept.
+func testExec(ctx context.Context, c *TestCase) error {
+	text := "conformance ✓ é 日本語"
+	r, err := run(ctx, c.Communicator, c.Commands.Echo(text), nil)
+	if err != nil {
+		return err
+	}
+	if r.status != 0 || r.stdout != text+"\n" || r.stderr != "" {
+		return fmt.Errorf("echo: expected stdout %q and status 0, got %#v", text+"\n", r)
+	}
+
+	r, err = run(ctx, c.Communicator, c.Commands.EchoStderr(text), nil)
+	if err != nil {
+		return err
+	}
+	if r.status != 0 || r.stderr != text+"\n" || r.stdout != "" {
+		return fmt.Errorf("echo to stderr: expected stderr %q and status 0, got %#v", text+"\n", r)
+	}
+	return nil
+}
+
+// testStdin checks that the standard input of a command is sent to it, and
+// closed once read.
+func testStdin(ctx context.Context, c *TestCase) error {
+	data := make([]byte, 64<<10)
+	if _, err := rand.Read(data); err != nil {
+		return err
+	}
+	var stdout bytes.Buffer
+	cmd := &packer.RemoteCmd{Command: c.Commands.Cat, Stdin: bytes.NewReader(data), Stdout: &stdout}
+	if err := c.Communicator.Start(ctx, cmd); err != nil {
+		return err
+	}
+	status, err := wait(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		return fmt.Errorf("%q exited with %d", c.Commands.Cat, status)
+	}
+	if !bytes.Equal(stdout.Bytes(), data) {
+		return fmt.Errorf("%q wrote %d bytes of its %d bytes of stdin", c.Commands.Cat, stdout.Len(), len(data))
+	}
+	return nil
+}
+
+// testExitStatus checks that the exit status of the commands is kept.
+func testExitStatus(ctx context.Context, c *TestCase) error {
+	for _, status := range []int{0, 1, 42, 255} {
+		r, err := run(ctx, c.Communicator, c.Commands.Exit(status), nil)
+		if err != nil {
+			return err
+		}
+		if r.status != status {
+			return fmt.Errorf("expected exit status %d, got %d", status, r.status)
+		}
+	}
+	return nil
+}
+
+// testConcurrency checks that commands run at the same time don't mix their
+// output.
+func testConcurrency(ctx context.Context, c *TestCase) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			text := fmt.Sprintf("command %d", i)
+			r, err := run(ctx, c.Communicator, c.Commands.Echo(text), nil)
+			if err == nil && (r.status != 0 || r.stdout != text+"\n") {
+				err = fmt.Errorf("expected stdout %q, got %#v", text+"\n", r)
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// testLargeTransfer checks that a large binary file is uploaded and
+// downloaded unchanged.
+func testLargeTransfer(ctx context.Context, c *TestCase) error {
+	data := make([]byte, c.TransferSize)
+	if _, err := rand.Read(data); err != nil {
+		return err
+	}
+	return roundTrip(ctx, c, c.Dir+"/packer-conformance-large.bin", data)
+}
+
+// testUnicodePaths checks that files with unicode names are uploaded and
+// downloaded.
+func testUnicodePaths(ctx context.Context, c *TestCase) error {
+	return roundTrip(ctx, c, c.Dir+"/packer-données-日本語-✓.txt", []byte("unicode ✓\n"))
+}
+
+// roundTrip uploads data to path and checks that it is downloaded unchanged.
+func roundTrip(ctx context.Context, c *TestCase, path string, data []byte) error {
+	if err := c.Communicator.Upload(path, packer.ContextReader(ctx, bytes.NewReader(data)), nil); err != nil {
+		return fmt.Errorf("Error uploading %s: %s", path, err)
+	}
+	h := sha256.New()
+	counter := &countingWriter{w: h}
+	if err := c.Communicator.Download(path, counter); err != nil {
+		return fmt.Errorf("Error downloading %s: %s", path, err)
+	}
+	expected := sha256.Sum256(data)
+	if counter.n != int64(len(data)) || !bytes.Equal(h.Sum(nil), expected[:]) {
+		return fmt.Errorf("%s: downloaded %d bytes differing from the %d bytes uploaded", path, counter.n, len(data))
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+// testDownloadMissing checks that downloading a file that doesn't exist
+// fails.
+func testDownloadMissing(ctx context.Context, c *TestCase) error {
+	var buf bytes.Buffer
+	path := c.Dir + "/packer-conformance-missing"
+	if err := c.Communicator.Download(path, &buf); err == nil {
+		return fmt.Errorf("downloading %s that doesn't exist should fail", path)
+	}
+	return nil
+}
+
+// testCancellation checks that a command stops once the context it was
+// started with is cancelled.
+func testCancellation(ctx context.Context, c *TestCase) error {
+	cmdCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	command := c.Commands.Sleep(time.Hour)
+	cmd := &packer.RemoteCmd{Command: command}
+	if err := c.Communicator.Start(cmdCtx, cmd); err != nil {
+		return err
+	}
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	waitCtx, stop := context.WithTimeout(ctx, 30*time.Second)
+	defer stop()
+	if _, err := wait(waitCtx, cmd); err != nil {
+		return errors.New("the command should stop once its context is cancelled")
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Package testing is the conformance test suite of the communicators: the
+// semantics of the commands, exit statuses, transfers and cancellation
+// Packer and its provisioners expect from every communicator. Communicators,
+// including the ones of the CustomConnect of the builders, run it against a
+// connected guest with Test.
+package testing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// Commands are the commands the conformance tests run on the guest.
+type Commands struct {
+	// Echo returns a command writing s and a newline to the standard
+	// output.
+	Echo func(s string) string
+	// EchoStderr returns a command writing s and a newline to the standard
+	// error.
+	EchoStderr func(s string) string
+	// Exit returns a command exiting with status.
+	Exit func(status int) string
+	// Cat is a command copying its standard input to its standard output.
+	Cat string
+	// Sleep returns a command running for d.
+	Sleep func(d time.Duration) string
+}
+
+// ShellCommands are the commands of the POSIX shell.
+var ShellCommands = Commands{
+	Echo:       func(s string) string { return fmt.Sprintf("echo '%s'", s) },
+	EchoStderr: func(s string) string { return fmt.Sprintf("echo '%s' >&2", s) },
+	Exit:       func(status int) string { return fmt.Sprintf("exit %d", status) },
+	Cat:        "cat",
+	Sleep:      func(d time.Duration) string { return fmt.Sprintf("sleep %d", int(d.Seconds())) },
+}
+
+// TestCase is the communicator the conformance tests run against.
+type TestCase struct {
+	// Communicator is the communicator to test, connected to a guest.
+	Communicator packer.Communicator
+
+	// Commands are the commands of the guest. Defaults to ShellCommands.
+	Commands *Commands
+
+	// Dir is an existing directory of the guest the files are uploaded to.
+	Dir string
+
+	// TransferSize is the size of the file of the large transfer test.
+	// Defaults to 16 MiB.
+	TransferSize int
+
+	// Timeout is the timeout of every test. Defaults to 5 minutes.
+	Timeout time.Duration
+
+	// Skip are the names of the tests to skip, like "cancellation" for the
+	// communicators that can't stop a command once started.
+	Skip []string
+}
+
+// conformanceTest is a conformance test, failing with the error it returns.
+type conformanceTest struct {
+	name string
+	run  func(ctx context.Context, c *TestCase) error
+}
+
+var conformanceTests = []conformanceTest{
+	{"exec", testExec},
+	{"stdin", testStdin},
+	{"exit_status", testExitStatus},
+	{"concurrency", testConcurrency},
+	{"large_transfer", testLargeTransfer},
+	{"unicode_paths", testUnicodePaths},
+	{"download_missing", testDownloadMissing},
+	{"cancellation", testCancellation},
+}
+
+// Test runs the conformance tests against the communicator of c, as
+// subtests of t.
+func Test(t *testing.T, c TestCase) {
+	if c.Communicator == nil || c.Dir == "" {
+		t.Fatal("Communicator and Dir are required")
+	}
+	if c.Commands == nil {
+		c.Commands = &ShellCommands
+	}
+	if c.TransferSize == 0 {
+		c.TransferSize = 16 << 20
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 5 * time.Minute
+	}
+	c.Dir = strings.TrimSuffix(c.Dir, "/")
+
+	for _, test := range conformanceTests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			for _, skip := range c.Skip {
+				if skip == test.name {
+					t.Skip("skipped by the test case")
+				}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+			defer cancel()
+			if err := test.run(ctx, &c); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/packer/communicator/memory"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestTest_memory(t *testing.T) {
+	Test(t, TestCase{
+		Communicator: new(memory.Communicator),
+		Dir:          "/tmp/",
+		TransferSize: 1 << 20,
+	})
+}
+
+func TestConformanceTests_fail(t *testing.T) {
+	// A communicator losing the exit status of the commands.
+	broken := &memory.Communicator{
+		Handler: func(ctx context.Context, c *memory.Communicator, cmd *packer.RemoteCmd) int {
+			memory.Shell(ctx, c, cmd)
+			return 0
+		},
+	}
+	c := &TestCase{Communicator: broken, Commands: &ShellCommands, Dir: "/tmp"}
+
+	if err := testExitStatus(context.Background(), c); err == nil {
+		t.Fatal("exit_status should fail")
+	}
+	if err := testExec(context.Background(), c); err != nil {
+		t.Fatalf("exec should pass: %s", err)
+	}
+}
@@ -22,6 +22,8 @@ type Communicator struct {
 	config   *Config
 	client   *winrm.Client
 	endpoint *winrm.Endpoint
+	params   winrm.Parameters
+	psrp     *psrpClient
 }
 
 // New creates a new communicator implementation over WinRM.
@@ -54,6 +56,33 @@ func New(config *Config) (*Communicator, error) {
 		return nil, err
 	}
 
+	c := &Communicator{
+		config:   config,
+		client:   client,
+		endpoint: endpoint,
+		params:   params,
+	}
+	if config.UsePSRP {
+		c.psrp, err = newPSRPClient(endpoint, config, params)
+		if err != nil {
+			return nil, err
+		}
+
+		// Open a runspace pool to verify the connection
+		log.Printf("[DEBUG] connecting to remote runspace pool using PSRP")
+		pool, err := c.psrp.openRunspacePool()
+		if err != nil {
+			log.Printf("[ERROR] connection error: %s", err)
+			return nil, err
+		}
+
+		if err := pool.close(); err != nil {
+			log.Printf("[ERROR] error closing connection: %s", err)
+			return nil, err
+		}
+		return c, nil
+	}
+
 	// Create the shell to verify the connection
 	log.Printf("[DEBUG] connecting to remote shell using WinRM")
 	shell, err := client.CreateShell()
@@ -67,15 +96,40 @@ func New(config *Config) (*Communicator, error) {
 		return nil, err
 	}
 
-	return &Communicator{
-		config:   config,
-		client:   client,
-		endpoint: endpoint,
-	}, nil
+	return c, nil
+}
+
+// SetPassword switches the communicator to password, once the password of
+// the user was changed on the guest. The commands already running keep
+// their shell.
+func (c *Communicator) SetPassword(password string) error {
+	client, err := winrm.NewClientWithParameters(
+		c.endpoint, c.config.Username, password, &c.params)
+	if err != nil {
+		return err
+	}
+	var psrp *psrpClient
+	if c.psrp != nil {
+		config := *c.config
+		config.Password = password
+		psrp, err = newPSRPClient(c.endpoint, &config, c.params)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.config.Password = password
+	c.client = client
+	c.psrp = psrp
+	return nil
 }
 
 // Start implementation of communicator.Communicator interface
 func (c *Communicator) Start(ctx context.Context, rc *packer.RemoteCmd) error {
+	if c.psrp != nil {
+		return c.startPSRP(rc)
+	}
+
 	shell, err := c.client.CreateShell()
 	if err != nil {
 		return err
@@ -87,11 +141,11 @@ func (c *Communicator) Start(ctx context.Context, rc *packer.RemoteCmd) error {
 		return err
 	}
 
-	go runCommand(shell, cmd, rc)
+	go c.runCommand(shell, cmd, rc)
 	return nil
 }
 
-func runCommand(shell *winrm.Shell, cmd *winrm.Command, rc *packer.RemoteCmd) {
+func (c *Communicator) runCommand(shell *winrm.Shell, cmd *winrm.Command, rc *packer.RemoteCmd) {
 	defer shell.Close()
 	var wg sync.WaitGroup
 
@@ -114,14 +168,86 @@ func runCommand(shell *winrm.Shell, cmd *winrm.Command, rc *packer.RemoteCmd) {
 		log.Printf("[WARN] Failed to read stderr for command '%s'", rc.Command)
 	}
 
-	cmd.Wait()
-	wg.Wait()
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		wg.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-c.monitorLiveness(exited):
+		// The requests to the guest can hang until they time out: fail
+		// the command right away.
+		log.Printf("[ERROR] The guest stopped answering while running '%s'", rc.Command)
+		go cmd.Close()
+		rc.SetExited(packer.CmdDisconnect)
+		return
+	}
 
 	code := cmd.ExitCode()
 	log.Printf("[INFO] command '%s' exited with code: %d", rc.Command, code)
 	rc.SetExited(code)
 }
 
+// startPSRP runs the command in a pipeline of its own runspace pool.
+func (c *Communicator) startPSRP(rc *packer.RemoteCmd) error {
+	pool, err := c.psrp.openRunspacePool()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] starting remote command with PSRP: %s", rc.Command)
+	pipeline, err := pool.invoke(psrpCommandScript, [][2]string{{"Command", rc.Command}})
+	if err != nil {
+		pool.close()
+		return err
+	}
+
+	go c.runPSRPCommand(pipeline, rc)
+	return nil
+}
+
+func (c *Communicator) runPSRPCommand(pipeline *psrpPipeline, rc *packer.RemoteCmd) {
+	stdout, stderr := rc.Stdout, rc.Stderr
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+
+	var code int
+	var err error
+	exited := make(chan struct{})
+	go func() {
+		code, err = pipeline.wait(stdout, stderr)
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-c.monitorLiveness(exited):
+		log.Printf("[ERROR] The guest stopped answering while running '%s'", rc.Command)
+		go func() {
+			pipeline.stop()
+			pipeline.pool.close()
+		}()
+		rc.SetExited(packer.CmdDisconnect)
+		return
+	}
+
+	if closeErr := pipeline.pool.close(); closeErr != nil {
+		log.Printf("[WARN] Error closing the runspace pool: %s", closeErr)
+	}
+	if err != nil {
+		log.Printf("[ERROR] command '%s' failed: %s", rc.Command, err)
+		rc.SetExited(packer.CmdDisconnect)
+		return
+	}
+	log.Printf("[INFO] command '%s' exited with code: %d", rc.Command, code)
+	rc.SetExited(code)
+}
+
 // Upload implementation of communicator.Communicator interface
 func (c *Communicator) Upload(path string, input io.Reader, fi *os.FileInfo) error {
 	wcp, err := c.newCopyClient()
@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"net"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/dylanmei/winrmtest"
 	"github.com/hashicorp/packer/packer"
+	"github.com/masterzen/winrm"
 )
 
 const PAYLOAD = "stuff"
@@ -90,6 +92,38 @@ func TestStart(t *testing.T) {
 	}
 }
 
+func TestSetPassword(t *testing.T) {
+	wrm := newMockWinRMServer(t)
+	defer wrm.Close()
+
+	c, err := New(&Config{
+		Host:     wrm.Host,
+		Port:     wrm.Port,
+		Username: "user",
+		Password: "pass",
+		Timeout:  30 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("error creating communicator: %s", err)
+	}
+	if err := c.SetPassword("rotated"); err != nil {
+		t.Fatalf("error setting the password: %s", err)
+	}
+	if password := c.getClientConfig().Auth.Password; password != "rotated" {
+		t.Fatalf("bad password of the copy client: %q", password)
+	}
+
+	stdout := new(bytes.Buffer)
+	cmd := &packer.RemoteCmd{Command: "echo foo", Stdout: stdout}
+	if err := c.Start(context.Background(), cmd); err != nil {
+		t.Fatalf("error executing remote command: %s", err)
+	}
+	cmd.Wait()
+	if stdout.String() != "foo" {
+		t.Fatalf("bad command response: expected %q, got %q", "foo", stdout.String())
+	}
+}
+
 func TestUpload(t *testing.T) {
 	wrm := newMockWinRMServer(t)
 	defer wrm.Close()
@@ -142,3 +176,51 @@ func TestUpload_nilFileInfo(t *testing.T) {
 		t.Fatalf("Should have errored because of nil fileinfo")
 	}
 }
+
+func TestStart_liveness(t *testing.T) {
+	wrm := newMockWinRMServer(t)
+	defer wrm.Close()
+	unblock := make(chan struct{})
+	defer close(unblock)
+	wrm.CommandFunc(
+		winrmtest.MatchText("hang"),
+		func(out, err io.Writer) int {
+			<-unblock
+			return 0
+		})
+
+	c, err := New(&Config{
+		Host:            wrm.Host,
+		Port:            wrm.Port,
+		Username:        "user",
+		Password:        "pass",
+		Timeout:         30 * time.Second,
+		LivenessTimeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("error creating communicator: %s", err)
+	}
+
+	// The guest disappears: the pings fail.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	c.endpoint = &winrm.Endpoint{Host: "127.0.0.1", Port: l.Addr().(*net.TCPAddr).Port}
+
+	cmd := &packer.RemoteCmd{Command: "hang"}
+	if err := c.Start(context.Background(), cmd); err != nil {
+		t.Fatalf("error executing remote command: %s", err)
+	}
+	exited := make(chan int)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case status := <-exited:
+		if status != packer.CmdDisconnect {
+			t.Fatalf("bad exit status: %d", status)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the command of an unresponsive guest didn't fail")
+	}
+}
@@ -16,4 +16,12 @@ type Config struct {
 	Https              bool
 	Insecure           bool
 	TransportDecorator func() winrm.Transporter
+
+	// UsePSRP runs the commands in PowerShell Remoting Protocol pipelines
+	// instead of winrs shells.
+	UsePSRP bool
+
+	// LivenessTimeout, if set, is how long the guest can go without
+	// answering pings while a command runs before the command fails.
+	LivenessTimeout time.Duration
 }
@@ -0,0 +1,67 @@
+package winrm
+
+import (
+	"log"
+	"time"
+
+	"github.com/masterzen/winrm"
+)
+
+// monitorLiveness pings the guest, by opening and closing a shell, until
+// stop is closed. The returned channel is closed when the guest didn't
+// answer the pings for LivenessTimeout, like when it crashed or was
+// preempted. The channel is never closed when LivenessTimeout is not set.
+func (c *Communicator) monitorLiveness(stop <-chan struct{}) <-chan struct{} {
+	dead := make(chan struct{})
+	timeout := c.config.LivenessTimeout
+	if timeout <= 0 {
+		return dead
+	}
+
+	// The pings use their own client, so that they time out with the
+	// liveness timeout instead of the timeout of the commands.
+	endpoint := *c.endpoint
+	endpoint.Timeout = timeout
+	params := *winrm.DefaultParameters
+	params.TransportDecorator = c.config.TransportDecorator
+	params.Timeout = formatDuration(timeout)
+	client, err := winrm.NewClientWithParameters(&endpoint, c.config.Username, c.config.Password, &params)
+	if err != nil {
+		log.Printf("[WARN] Unable to create the WinRM liveness client: %s", err)
+		return dead
+	}
+
+	interval := timeout / 3
+	if interval < time.Second {
+		interval = time.Second
+	}
+	go func() {
+		lastAlive := time.Now()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(interval):
+			}
+			if err := ping(client); err != nil {
+				log.Printf("[DEBUG] WinRM ping failed: %s", err)
+				if time.Since(lastAlive) >= timeout {
+					log.Printf("[ERROR] The guest did not answer WinRM pings for %s", timeout)
+					close(dead)
+					return
+				}
+				continue
+			}
+			lastAlive = time.Now()
+		}
+	}()
+	return dead
+}
+
+func ping(client *winrm.Client) error {
+	shell, err := client.CreateShell()
+	if err != nil {
+		return err
+	}
+	return shell.Close()
+}
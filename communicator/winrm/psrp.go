@@ -0,0 +1,429 @@
+package winrm
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// The PowerShell Remoting Protocol (MS-PSRP) messages exchanged with the
+// guest, sent in fragments in the streams of a WS-Management shell.
+
+// The message types used by the communicator.
+const (
+	psrpSessionCapability      uint32 = 0x00010002
+	psrpInitRunspacePool       uint32 = 0x00010004
+	psrpRunspacePoolState      uint32 = 0x00021005
+	psrpCreatePipeline         uint32 = 0x00021006
+	psrpApplicationPrivateData uint32 = 0x00021009
+	psrpPipelineOutput         uint32 = 0x00041004
+	psrpErrorRecord            uint32 = 0x00041005
+	psrpPipelineState          uint32 = 0x00041006
+)
+
+// The destinations of the messages.
+const (
+	psrpDestinationClient uint32 = 1
+	psrpDestinationServer uint32 = 2
+)
+
+// The states of runspace pools and pipelines.
+const (
+	psrpRunspacePoolOpened = 2
+	psrpRunspacePoolClosed = 3
+	psrpRunspacePoolBroken = 5
+
+	psrpPipelineStopped   = 3
+	psrpPipelineCompleted = 4
+	psrpPipelineFailed    = 5
+)
+
+// psrpMaxBlob is the maximum size of the message data of a fragment, which
+// keeps the requests under the default maximum envelope size of WinRM.
+const psrpMaxBlob = 32 * 1024
+
+// psrpHeaderSize is the size of the header of the messages, before the
+// data.
+const psrpHeaderSize = 40
+
+// psrpFragmentHeaderSize is the size of the header of the fragments.
+const psrpFragmentHeaderSize = 21
+
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// guid is a GUID, stored in its textual byte order.
+type guid [16]byte
+
+func newGUID() guid {
+	var g guid
+	if _, err := rand.Read(g[:]); err != nil {
+		panic(err)
+	}
+	g[6] = g[6]&0x0f | 0x40
+	g[8] = g[8]&0x3f | 0x80
+	return g
+}
+
+func (g guid) String() string {
+	return fmt.Sprintf("%X-%X-%X-%X-%X", g[0:4], g[4:6], g[6:8], g[8:10], g[10:])
+}
+
+// bytes returns the GUID in the mixed endian byte order of .NET, the one of
+// the messages.
+func (g guid) bytes() []byte {
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint32(b[0:], binary.BigEndian.Uint32(g[0:]))
+	binary.LittleEndian.PutUint16(b[4:], binary.BigEndian.Uint16(g[4:]))
+	binary.LittleEndian.PutUint16(b[6:], binary.BigEndian.Uint16(g[6:]))
+	copy(b[8:], g[8:])
+	return b
+}
+
+func guidFromBytes(b []byte) guid {
+	var g guid
+	binary.BigEndian.PutUint32(g[0:], binary.LittleEndian.Uint32(b[0:]))
+	binary.BigEndian.PutUint16(g[4:], binary.LittleEndian.Uint16(b[4:]))
+	binary.BigEndian.PutUint16(g[6:], binary.LittleEndian.Uint16(b[6:]))
+	copy(g[8:], b[8:])
+	return g
+}
+
+// psrpMessage is a PSRP message, with its CLIXML data.
+type psrpMessage struct {
+	Destination  uint32
+	Type         uint32
+	RunspacePool guid
+	Pipeline     guid
+	Data         string
+}
+
+func (m *psrpMessage) marshal() []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, m.Destination)
+	binary.Write(&b, binary.LittleEndian, m.Type)
+	b.Write(m.RunspacePool.bytes())
+	b.Write(m.Pipeline.bytes())
+	b.Write(utf8BOM)
+	b.WriteString(m.Data)
+	return b.Bytes()
+}
+
+func unmarshalPSRPMessage(b []byte) (*psrpMessage, error) {
+	if len(b) < psrpHeaderSize {
+		return nil, fmt.Errorf("PSRP message too short: %d bytes", len(b))
+	}
+	return &psrpMessage{
+		Destination:  binary.LittleEndian.Uint32(b[0:]),
+		Type:         binary.LittleEndian.Uint32(b[4:]),
+		RunspacePool: guidFromBytes(b[8:24]),
+		Pipeline:     guidFromBytes(b[24:40]),
+		Data:         string(bytes.TrimPrefix(b[psrpHeaderSize:], utf8BOM)),
+	}, nil
+}
+
+// psrpFragmenter splits the messages in fragments, numbering them like the
+// objects of a runspace pool are.
+type psrpFragmenter struct {
+	objectID uint64
+}
+
+func (f *psrpFragmenter) fragment(m *psrpMessage) [][]byte {
+	f.objectID++
+	data := m.marshal()
+	var fragments [][]byte
+	for id := uint64(0); id == 0 || len(data) > 0; id++ {
+		n := len(data)
+		if n > psrpMaxBlob {
+			n = psrpMaxBlob
+		}
+		var flags byte
+		if id == 0 {
+			flags |= 0x1
+		}
+		if n == len(data) {
+			flags |= 0x2
+		}
+		var b bytes.Buffer
+		binary.Write(&b, binary.BigEndian, f.objectID)
+		binary.Write(&b, binary.BigEndian, id)
+		b.WriteByte(flags)
+		binary.Write(&b, binary.BigEndian, uint32(n))
+		b.Write(data[:n])
+		fragments = append(fragments, b.Bytes())
+		data = data[n:]
+	}
+	return fragments
+}
+
+// psrpDefragmenter reassembles the messages of the fragments received.
+type psrpDefragmenter struct {
+	partial map[uint64][]byte
+}
+
+// write returns the messages completed by the fragments of b.
+func (d *psrpDefragmenter) write(b []byte) ([]*psrpMessage, error) {
+	if d.partial == nil {
+		d.partial = make(map[uint64][]byte)
+	}
+	var messages []*psrpMessage
+	for len(b) > 0 {
+		if len(b) < psrpFragmentHeaderSize {
+			return messages, fmt.Errorf("truncated PSRP fragment header")
+		}
+		objectID := binary.BigEndian.Uint64(b[0:])
+		flags := b[16]
+		n := int(binary.BigEndian.Uint32(b[17:]))
+		b = b[psrpFragmentHeaderSize:]
+		if len(b) < n {
+			return messages, fmt.Errorf("truncated PSRP fragment of object %d", objectID)
+		}
+		data := append(d.partial[objectID], b[:n]...)
+		b = b[n:]
+		if flags&0x2 == 0 {
+			d.partial[objectID] = data
+			continue
+		}
+		delete(d.partial, objectID)
+		m, err := unmarshalPSRPMessage(data)
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// clixml is an element of the CLIXML serialization of PowerShell objects.
+type clixml struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Text    string     `xml:",chardata"`
+	Nodes   []clixml   `xml:",any"`
+}
+
+func parseCLIXML(data string) (*clixml, error) {
+	var n clixml
+	if err := xml.Unmarshal([]byte(data), &n); err != nil {
+		return nil, fmt.Errorf("malformed CLIXML: %s", err)
+	}
+	return &n, nil
+}
+
+func (n *clixml) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// property returns the property of an object named name, nil when it has
+// none.
+func (n *clixml) property(name string) *clixml {
+	for i := range n.Nodes {
+		switch c := &n.Nodes[i]; c.XMLName.Local {
+		case "MS", "Props":
+			if p := c.property(name); p != nil {
+				return p
+			}
+		default:
+			if c.attr("N") == name {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// int returns the value of an integer, or of an enum object.
+func (n *clixml) int() (int, bool) {
+	text := n.Text
+	if n.XMLName.Local == "Obj" {
+		for _, c := range n.Nodes {
+			if c.XMLName.Local == "I32" {
+				text = c.Text
+			}
+		}
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(text))
+	return i, err == nil
+}
+
+// String returns the string of a primitive value, or the ToString of an
+// object.
+func (n *clixml) String() string {
+	if n.XMLName.Local == "Obj" {
+		for _, c := range n.Nodes {
+			if c.XMLName.Local == "ToString" {
+				return clixmlUnescape(c.Text)
+			}
+		}
+		if p := n.property("Message"); p != nil {
+			return p.String()
+		}
+		return ""
+	}
+	return clixmlUnescape(n.Text)
+}
+
+// clixmlEscape escapes s for a CLIXML string, which encodes the characters
+// XML can't hold as _xHHHH_, and the underscores starting such sequences.
+func clixmlEscape(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_' && strings.HasPrefix(s[i:], "_x"):
+			b.WriteString("_x005F_")
+		case r < 0x20 || r == 0xfffe || r == 0xffff:
+			fmt.Fprintf(&b, "_x%04X_", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(b.String()))
+	return escaped.String()
+}
+
+var clixmlEscapeRe = regexp.MustCompile(`_x([0-9A-Fa-f]{4})_`)
+
+// clixmlUnescape decodes the _xHHHH_ sequences of a CLIXML string, UTF-16
+// code units which can be surrogate pairs.
+func clixmlUnescape(s string) string {
+	if !strings.Contains(s, "_x") {
+		return s
+	}
+	var units []uint16
+	for len(s) > 0 {
+		loc := clixmlEscapeRe.FindStringSubmatchIndex(s)
+		if loc == nil {
+			units = append(units, utf16.Encode([]rune(s))...)
+			break
+		}
+		units = append(units, utf16.Encode([]rune(s[:loc[0]]))...)
+		u, _ := strconv.ParseUint(s[loc[2]:loc[3]], 16, 16)
+		units = append(units, uint16(u))
+		s = s[loc[1]:]
+	}
+	return string(utf16.Decode(units))
+}
+
+// psrpTemplate removes the indentation of the CLIXML templates, written
+// indented for readability.
+func psrpTemplate(s string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		b.WriteString(strings.TrimSpace(line))
+	}
+	return b.String()
+}
+
+var psrpSessionCapabilityData = psrpTemplate(`
+<Obj RefId="0"><MS>
+  <Version N="protocolversion">2.3</Version>
+  <Version N="PSVersion">2.0</Version>
+  <Version N="SerializationVersion">1.1.0.1</Version>
+</MS></Obj>`)
+
+// psrpHostInfo is the host of the runspace pools and pipelines: none, so
+// that the guest doesn't call back the client.
+const psrpHostInfo = `
+  <Obj N="HostInfo" RefId="%d"><MS>
+    <B N="_isHostNull">true</B>
+    <B N="_isHostUINull">true</B>
+    <B N="_isHostRawUINull">true</B>
+    <B N="_useRunspaceHost">true</B>
+  </MS></Obj>`
+
+var psrpInitRunspacePoolData = psrpTemplate(`
+<Obj RefId="0"><MS>
+  <I32 N="MinRunspaces">1</I32>
+  <I32 N="MaxRunspaces">1</I32>
+  <Obj N="PSThreadOptions" RefId="1">
+    <TN RefId="0">
+      <T>System.Management.Automation.Runspaces.PSThreadOptions</T>
+      <T>System.Enum</T><T>System.ValueType</T><T>System.Object</T>
+    </TN>
+    <ToString>Default</ToString><I32>0</I32>
+  </Obj>
+  <Obj N="ApartmentState" RefId="2">
+    <TN RefId="1">
+      <T>System.Threading.ApartmentState</T>
+      <T>System.Enum</T><T>System.ValueType</T><T>System.Object</T>
+    </TN>
+    <ToString>Unknown</ToString><I32>2</I32>
+  </Obj>` + fmt.Sprintf(psrpHostInfo, 3) + `
+  <Nil N="ApplicationArguments" />
+</MS></Obj>`)
+
+var psrpCreatePipelineTemplate = psrpTemplate(`
+<Obj RefId="0"><MS>
+  <B N="NoInput">true</B>
+  <Obj N="ApartmentState" RefId="1">
+    <TN RefId="0">
+      <T>System.Threading.ApartmentState</T>
+      <T>System.Enum</T><T>System.ValueType</T><T>System.Object</T>
+    </TN>
+    <ToString>Unknown</ToString><I32>2</I32>
+  </Obj>
+  <Obj N="RemoteStreamOptions" RefId="2">
+    <TN RefId="1">
+      <T>System.Management.Automation.RemoteStreamOptions</T>
+      <T>System.Enum</T><T>System.ValueType</T><T>System.Object</T>
+    </TN>
+    <ToString>None</ToString><I32>0</I32>
+  </Obj>
+  <B N="AddToHistory">false</B>` + fmt.Sprintf(psrpHostInfo, 3) + `
+  <Obj N="PowerShell" RefId="4"><MS>
+    <Obj N="Cmds" RefId="5">
+      <TN RefId="2">
+        <T>System.Collections.Generic.List` + "`" + `1[[System.Management.Automation.PSObject, System.Management.Automation, Version=1.0.0.0, Culture=neutral, PublicKeyToken=31bf3856ad364e35]]</T>
+        <T>System.Object</T>
+      </TN>
+      <LST>
+        <Obj RefId="6"><MS>
+          <S N="Cmd">%s</S>
+          <B N="IsScript">true</B>
+          <Nil N="UseLocalScope" />
+          <Obj N="MergeMyResult" RefId="7">
+            <TN RefId="3">
+              <T>System.Management.Automation.Runspaces.PipelineResultTypes</T>
+              <T>System.Enum</T><T>System.ValueType</T><T>System.Object</T>
+            </TN>
+            <ToString>None</ToString><I32>0</I32>
+          </Obj>
+          <Obj N="MergeToResult" RefId="8"><TNRef RefId="3" /><ToString>None</ToString><I32>0</I32></Obj>
+          <Obj N="MergePreviousResults" RefId="9"><TNRef RefId="3" /><ToString>None</ToString><I32>0</I32></Obj>
+          <Obj N="MergeError" RefId="10"><TNRef RefId="3" /><ToString>None</ToString><I32>0</I32></Obj>
+          <Obj N="MergeWarning" RefId="11"><TNRef RefId="3" /><ToString>None</ToString><I32>0</I32></Obj>
+          <Obj N="MergeVerbose" RefId="12"><TNRef RefId="3" /><ToString>None</ToString><I32>0</I32></Obj>
+          <Obj N="MergeDebug" RefId="13"><TNRef RefId="3" /><ToString>None</ToString><I32>0</I32></Obj>
+          <Obj N="MergeInformation" RefId="14"><TNRef RefId="3" /><ToString>None</ToString><I32>0</I32></Obj>
+          <Obj N="Args" RefId="15"><TNRef RefId="2" /><LST>%s</LST></Obj>
+        </MS></Obj>
+      </LST>
+    </Obj>
+    <B N="IsNested">false</B>
+    <Nil N="History" />
+    <B N="RedirectShellErrorOutputPipe">true</B>
+  </MS></Obj>
+  <B N="IsNested">false</B>
+</MS></Obj>`)
+
+// psrpCreatePipelineData returns the data of the message creating a
+// pipeline running script, with the named parameters of params.
+func psrpCreatePipelineData(script string, params [][2]string) string {
+	var args strings.Builder
+	for i, p := range params {
+		fmt.Fprintf(&args, `<Obj RefId="%d"><MS><S N="N">%s</S><S N="V">%s</S></MS></Obj>`,
+			16+i, clixmlEscape(p[0]), clixmlEscape(p[1]))
+	}
+	return fmt.Sprintf(psrpCreatePipelineTemplate, clixmlEscape(script), args.String())
+}
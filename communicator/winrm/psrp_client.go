@@ -0,0 +1,358 @@
+package winrm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/masterzen/simplexml/dom"
+	"github.com/masterzen/winrm"
+	"github.com/masterzen/winrm/soap"
+)
+
+const (
+	psrpResourceURI = "http://schemas.microsoft.com/powershell/Microsoft.PowerShell"
+	psrpSignalStop  = "powershell/signal/crtl_c"
+	signalTerminate = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/signal/terminate"
+)
+
+var domNSPowerShell = dom.Namespace{Prefix: "pwsh", Uri: "http://schemas.microsoft.com/powershell"}
+
+// psrpCommandScript runs a command line with cmd.exe, like winrs does, and
+// outputs its exit code last. PowerShell expands the command line of the
+// environment variable after the stop-parsing token, so that it's passed to
+// cmd.exe as is instead of being quoted again.
+const psrpCommandScript = `param([string]$Command)
+$env:PACKER_PSRP_COMMAND = $Command
+& $env:ComSpec --% /s /c "%PACKER_PSRP_COMMAND%"
+$LASTEXITCODE`
+
+// psrpClient runs commands in PowerShell pipelines of the guest, with the
+// PowerShell Remoting Protocol over WS-Management, instead of winrs shells.
+type psrpClient struct {
+	client    *winrm.Client
+	transport winrm.Transporter
+	url       string
+}
+
+func newPSRPClient(endpoint *winrm.Endpoint, config *Config, params winrm.Parameters) (*psrpClient, error) {
+	c := &psrpClient{
+		url: fmt.Sprintf("http://%s:%d/wsman", endpoint.Host, endpoint.Port),
+	}
+	if endpoint.HTTPS {
+		c.url = fmt.Sprintf("https://%s:%d/wsman", endpoint.Host, endpoint.Port)
+	}
+
+	// The client only holds the settings of the requests, which are sent
+	// with its transport directly.
+	decorator := params.TransportDecorator
+	params.TransportDecorator = func() winrm.Transporter {
+		if decorator != nil {
+			c.transport = decorator()
+		} else {
+			c.transport = winrm.NewClientWithDial(params.Dial)
+		}
+		return c.transport
+	}
+	client, err := winrm.NewClientWithParameters(endpoint, config.Username, config.Password, &params)
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+	return c, nil
+}
+
+func (c *psrpClient) header(message *soap.SoapMessage, action string) *soap.SoapHeader {
+	return message.Header().
+		To(c.url).
+		ReplyTo("http://schemas.xmlsoap.org/ws/2004/08/addressing/role/anonymous").
+		MaxEnvelopeSize(c.client.EnvelopeSize).
+		Id("uuid:" + newGUID().String()).
+		Locale(c.client.Locale).
+		Timeout(c.client.Timeout).
+		Action(action).
+		ResourceURI(psrpResourceURI)
+}
+
+func (c *psrpClient) send(message *soap.SoapMessage) (string, error) {
+	return c.transport.Post(c.client, message)
+}
+
+// psrpRunspacePool is a runspace pool of the guest, the shell its pipelines
+// run in.
+type psrpRunspacePool struct {
+	client     *psrpClient
+	id         guid
+	shellID    string
+	fragmenter psrpFragmenter
+}
+
+// openRunspacePool opens a runspace pool, of a single runspace.
+func (c *psrpClient) openRunspacePool() (*psrpRunspacePool, error) {
+	pool := &psrpRunspacePool{client: c, id: newGUID()}
+
+	var creation bytes.Buffer
+	for _, m := range []*psrpMessage{
+		pool.message(psrpSessionCapability, guid{}, psrpSessionCapabilityData),
+		pool.message(psrpInitRunspacePool, guid{}, psrpInitRunspacePoolData),
+	} {
+		for _, f := range pool.fragmenter.fragment(m) {
+			creation.Write(f)
+		}
+	}
+
+	message := soap.NewMessage()
+	c.header(message, "http://schemas.xmlsoap.org/ws/2004/09/transfer/Create").
+		AddOption(soap.NewHeaderOption("protocolversion", "2.3")).
+		Build()
+	shell := message.CreateBodyElement("Shell", soap.DOM_NS_WIN_SHELL)
+	shell.SetAttr("ShellId", pool.id.String())
+	message.CreateElement(shell, "InputStreams", soap.DOM_NS_WIN_SHELL).SetContent("stdin pr")
+	message.CreateElement(shell, "OutputStreams", soap.DOM_NS_WIN_SHELL).SetContent("stdout")
+	message.CreateElement(shell, "creationXml", domNSPowerShell).
+		SetContent(base64.StdEncoding.EncodeToString(creation.Bytes()))
+
+	response, err := c.send(message)
+	if err != nil {
+		return nil, err
+	}
+	if pool.shellID, err = winrm.ParseOpenShellResponse(response); err != nil {
+		return nil, err
+	}
+
+	var defrag psrpDefragmenter
+	for {
+		messages, done, err := pool.receive("", &defrag)
+		if err != nil {
+			pool.close()
+			return nil, err
+		}
+		for _, m := range messages {
+			if m.Type != psrpRunspacePoolState {
+				continue
+			}
+			state, reason, err := psrpState(m, "RunspaceState")
+			if err != nil {
+				pool.close()
+				return nil, err
+			}
+			switch state {
+			case psrpRunspacePoolOpened:
+				return pool, nil
+			case psrpRunspacePoolClosed, psrpRunspacePoolBroken:
+				pool.close()
+				return nil, fmt.Errorf("the runspace pool could not be opened: %s", reason)
+			}
+		}
+		if done {
+			pool.close()
+			return nil, fmt.Errorf("the runspace pool was closed while opening")
+		}
+	}
+}
+
+func (p *psrpRunspacePool) message(messageType uint32, pipeline guid, data string) *psrpMessage {
+	return &psrpMessage{
+		Destination:  psrpDestinationServer,
+		Type:         messageType,
+		RunspacePool: p.id,
+		Pipeline:     pipeline,
+		Data:         data,
+	}
+}
+
+// receive returns the messages received from the runspace pool, or from
+// the pipeline of commandID when it's set, and whether the pipeline is
+// done. It returns no message when the operation timed out without output.
+func (p *psrpRunspacePool) receive(commandID string, defrag *psrpDefragmenter) ([]*psrpMessage, bool, error) {
+	message := soap.NewMessage()
+	p.client.header(message, "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Receive").
+		ShellId(p.shellID).
+		AddOption(soap.NewHeaderOption("WSMAN_CMDSHELL_OPTION_KEEPALIVE", "TRUE")).
+		Build()
+	receive := message.CreateBodyElement("Receive", soap.DOM_NS_WIN_SHELL)
+	stream := message.CreateElement(receive, "DesiredStream", soap.DOM_NS_WIN_SHELL)
+	if commandID != "" {
+		stream.SetAttr("CommandId", commandID)
+	}
+	stream.SetContent("stdout")
+
+	response, err := p.client.send(message)
+	if err != nil {
+		if strings.Contains(err.Error(), "OperationTimeout") {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	var fragments bytes.Buffer
+	done, _, err := winrm.ParseSlurpOutputResponse(response, &fragments, "stdout")
+	if err != nil {
+		return nil, false, err
+	}
+	messages, err := defrag.write(fragments.Bytes())
+	return messages, done, err
+}
+
+// signal sends code to the pipeline of commandID.
+func (p *psrpRunspacePool) signal(commandID, code string) error {
+	message := soap.NewMessage()
+	p.client.header(message, "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Signal").
+		ShellId(p.shellID).
+		Build()
+	signal := message.CreateBodyElement("Signal", soap.DOM_NS_WIN_SHELL)
+	signal.SetAttr("CommandId", commandID)
+	message.CreateElement(signal, "Code", soap.DOM_NS_WIN_SHELL).SetContent(code)
+	_, err := p.client.send(message)
+	return err
+}
+
+// close closes the runspace pool, stopping its pipelines.
+func (p *psrpRunspacePool) close() error {
+	message := soap.NewMessage()
+	p.client.header(message, "http://schemas.xmlsoap.org/ws/2004/09/transfer/Delete").
+		ShellId(p.shellID).
+		Build()
+	message.NewBody()
+	_, err := p.client.send(message)
+	return err
+}
+
+// psrpPipeline is a pipeline running in a runspace pool.
+type psrpPipeline struct {
+	pool      *psrpRunspacePool
+	id        guid
+	commandID string
+}
+
+// invoke starts a pipeline running script with the named parameters of
+// params.
+func (p *psrpRunspacePool) invoke(script string, params [][2]string) (*psrpPipeline, error) {
+	pipeline := &psrpPipeline{pool: p, id: newGUID()}
+	fragments := p.fragmenter.fragment(p.message(
+		psrpCreatePipeline, pipeline.id, psrpCreatePipelineData(script, params)))
+
+	message := soap.NewMessage()
+	p.client.header(message, "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Command").
+		ShellId(p.shellID).
+		Build()
+	commandLine := message.CreateBodyElement("CommandLine", soap.DOM_NS_WIN_SHELL)
+	commandLine.SetAttr("CommandId", pipeline.id.String())
+	message.CreateElement(commandLine, "Command", soap.DOM_NS_WIN_SHELL).SetContent("Invoke-Expression")
+	message.CreateElement(commandLine, "Arguments", soap.DOM_NS_WIN_SHELL).
+		SetContent(base64.StdEncoding.EncodeToString(fragments[0]))
+
+	response, err := p.client.send(message)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.commandID, err = winrm.ParseExecuteCommandResponse(response); err != nil {
+		return nil, err
+	}
+
+	// The fragments that don't fit in the command are sent as its input.
+	for _, f := range fragments[1:] {
+		message := soap.NewMessage()
+		p.client.header(message, "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Send").
+			ShellId(p.shellID).
+			Build()
+		send := message.CreateBodyElement("Send", soap.DOM_NS_WIN_SHELL)
+		message.CreateElement(send, "Stream", soap.DOM_NS_WIN_SHELL).
+			SetAttr("Name", "stdin").
+			SetAttr("CommandId", pipeline.commandID).
+			SetContent(base64.StdEncoding.EncodeToString(f))
+		if _, err := p.client.send(message); err != nil {
+			return nil, err
+		}
+	}
+	return pipeline, nil
+}
+
+// wait streams the output of the pipeline to stdout and its errors to
+// stderr, one line per object, until it ended. It returns the last integer
+// output, the exit code of psrpCommandScript, or 1 when the pipeline
+// failed.
+func (p *psrpPipeline) wait(stdout, stderr io.Writer) (int, error) {
+	var defrag psrpDefragmenter
+	exitCode := 0
+	for {
+		messages, done, err := p.pool.receive(p.commandID, &defrag)
+		if err != nil {
+			return 0, err
+		}
+		for _, m := range messages {
+			switch m.Type {
+			case psrpPipelineOutput, psrpErrorRecord:
+				n, err := parseCLIXML(m.Data)
+				if err != nil {
+					return 0, err
+				}
+				if m.Type == psrpPipelineOutput {
+					switch n.XMLName.Local {
+					case "I32":
+						exitCode, _ = n.int()
+						continue
+					case "Nil":
+						continue
+					}
+				}
+				w := stdout
+				if m.Type == psrpErrorRecord {
+					w = stderr
+				}
+				fmt.Fprintf(w, "%s\r\n", n)
+			case psrpPipelineState:
+				state, reason, err := psrpState(m, "PipelineState")
+				if err != nil {
+					return 0, err
+				}
+				if err := p.pool.signal(p.commandID, signalTerminate); err != nil {
+					log.Printf("[DEBUG] Error terminating the PSRP pipeline: %s", err)
+				}
+				switch state {
+				case psrpPipelineCompleted:
+					return exitCode, nil
+				case psrpPipelineFailed:
+					fmt.Fprintf(stderr, "%s\r\n", reason)
+					return 1, nil
+				case psrpPipelineStopped:
+					return 0, fmt.Errorf("the pipeline was stopped: %s", reason)
+				}
+			default:
+				log.Printf("[TRACE] Ignoring PSRP message of type 0x%08x", m.Type)
+			}
+		}
+		if done {
+			return 0, fmt.Errorf("the pipeline ended without a state")
+		}
+	}
+}
+
+// stop stops the pipeline.
+func (p *psrpPipeline) stop() error {
+	return p.pool.signal(p.commandID, psrpSignalStop)
+}
+
+// psrpState returns the state of a runspace pool or pipeline state message,
+// and the error record explaining it.
+func psrpState(m *psrpMessage, name string) (int, string, error) {
+	n, err := parseCLIXML(m.Data)
+	if err != nil {
+		return 0, "", err
+	}
+	p := n.property(name)
+	if p == nil {
+		return 0, "", fmt.Errorf("%s missing from the PSRP state message", name)
+	}
+	state, ok := p.int()
+	if !ok {
+		return 0, "", fmt.Errorf("invalid %s %q", name, p.Text)
+	}
+	var reason string
+	if e := n.property("ExceptionAsErrorRecord"); e != nil {
+		reason = e.String()
+	}
+	return state, reason, nil
+}
@@ -0,0 +1,300 @@
+package winrm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestGUID_bytes(t *testing.T) {
+	g := newGUID()
+	if got := guidFromBytes(g.bytes()); got != g {
+		t.Fatalf("bad round trip: %s, expected %s", got, g)
+	}
+
+	g = guid{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	if g.String() != "00010203-0405-0607-0809-0A0B0C0D0E0F" {
+		t.Fatalf("bad string: %s", g)
+	}
+	expected := []byte{0x03, 0x02, 0x01, 0x00, 0x05, 0x04, 0x07, 0x06, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	if !bytes.Equal(g.bytes(), expected) {
+		t.Fatalf("bad .NET byte order: %x", g.bytes())
+	}
+}
+
+func TestPSRPFragmenter(t *testing.T) {
+	var f psrpFragmenter
+	small := &psrpMessage{
+		Destination:  psrpDestinationServer,
+		Type:         psrpSessionCapability,
+		RunspacePool: newGUID(),
+		Data:         psrpSessionCapabilityData,
+	}
+	large := &psrpMessage{
+		Destination:  psrpDestinationServer,
+		Type:         psrpCreatePipeline,
+		RunspacePool: small.RunspacePool,
+		Pipeline:     newGUID(),
+		Data:         strings.Repeat("x", 2*psrpMaxBlob),
+	}
+
+	var first, second []byte
+	for _, frag := range f.fragment(small) {
+		first = append(first, frag...)
+	}
+	fragments := f.fragment(large)
+	if len(fragments) != 3 {
+		t.Fatalf("bad number of fragments: %d", len(fragments))
+	}
+	first = append(first, fragments[0]...)
+	second = append(fragments[1], fragments[2]...)
+
+	// The fragments of a message can be received in several responses.
+	var d psrpDefragmenter
+	messages, err := d.write(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("bad number of messages: %d", len(messages))
+	}
+	m, err := d.write(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages = append(messages, m...)
+	if len(messages) != 2 {
+		t.Fatalf("bad number of messages: %d", len(messages))
+	}
+	for i, expected := range []*psrpMessage{small, large} {
+		if *messages[i] != *expected {
+			t.Fatalf("bad message %d: %#v", i, messages[i])
+		}
+	}
+}
+
+func TestCLIXMLEscape(t *testing.T) {
+	s := "a_b _x0041_ <&> line\r\n\ttab \U0001F600"
+	escaped := clixmlEscape(s)
+	if strings.ContainsAny(escaped, "\r\n\t<>") {
+		t.Fatalf("characters left unescaped: %q", escaped)
+	}
+	n, err := parseCLIXML("<S>" + escaped + "</S>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.String() != s {
+		t.Fatalf("bad round trip: %q", n.String())
+	}
+	if got := clixmlUnescape("_xD83D__xDE00_"); got != "\U0001F600" {
+		t.Fatalf("bad surrogate pair: %q", got)
+	}
+}
+
+func TestPSRPCreatePipelineData(t *testing.T) {
+	n, err := parseCLIXML(psrpCreatePipelineData(psrpCommandScript, [][2]string{{"Command", `echo "a&b"`}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds := n.property("PowerShell").property("Cmds")
+	if cmds == nil {
+		t.Fatal("no commands")
+	}
+	cmd := &cmds.Nodes[1].Nodes[0]
+	if got := cmd.property("Cmd").String(); got != psrpCommandScript {
+		t.Fatalf("bad script: %q", got)
+	}
+	arg := &cmd.property("Args").Nodes[1].Nodes[0]
+	if arg.property("N").String() != "Command" || arg.property("V").String() != `echo "a&b"` {
+		t.Fatalf("bad argument: %#v", arg)
+	}
+}
+
+// psrpServer is a WS-Management server running the commands of PSRP
+// pipelines with run.
+type psrpServer struct {
+	*httptest.Server
+	t   *testing.T
+	run func(command string) []*psrpMessage
+
+	mu       sync.Mutex
+	pool     guid
+	pending  map[string][]*psrpMessage
+	requests []string
+}
+
+var (
+	psrpServerActionRe = regexp.MustCompile(`<a:Action[^>]*>([^<]+)</a:Action>`)
+	psrpServerBase64Re = regexp.MustCompile(`<(?:pwsh:creationXml|rsp:Arguments)[^>]*>([^<]+)<`)
+	psrpServerCommand  = regexp.MustCompile(`<rsp:DesiredStream CommandId="([^"]+)"`)
+)
+
+func newPSRPServer(t *testing.T, run func(command string) []*psrpMessage) *psrpServer {
+	s := &psrpServer{t: t, run: run, pending: make(map[string][]*psrpMessage)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *psrpServer) hostPort() (string, int) {
+	host, port, _ := net.SplitHostPort(s.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return host, p
+}
+
+func (s *psrpServer) messages(request string) []*psrpMessage {
+	m := psrpServerBase64Re.FindStringSubmatch(request)
+	if m == nil {
+		s.t.Errorf("no PSRP fragments in %s", request)
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(m[1])
+	if err != nil {
+		s.t.Error(err)
+	}
+	var d psrpDefragmenter
+	messages, err := d.write(data)
+	if err != nil {
+		s.t.Error(err)
+	}
+	return messages
+}
+
+func (s *psrpServer) handle(w http.ResponseWriter, r *http.Request) {
+	b, _ := ioutil.ReadAll(r.Body)
+	request := string(b)
+	action := psrpServerActionRe.FindStringSubmatch(request)[1]
+	action = action[strings.LastIndex(action, "/")+1:]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, action)
+
+	var body string
+	switch action {
+	case "Create":
+		messages := s.messages(request)
+		if len(messages) != 2 || messages[0].Type != psrpSessionCapability || messages[1].Type != psrpInitRunspacePool {
+			s.t.Errorf("bad runspace pool creation messages: %#v", messages)
+		}
+		s.pool = messages[1].RunspacePool
+		s.pending[""] = []*psrpMessage{{
+			Type: psrpRunspacePoolState,
+			Data: `<Obj RefId="0"><MS><I32 N="RunspaceState">2</I32></MS></Obj>`,
+		}}
+		body = `<x:ResourceCreated><w:ReferenceParameters><w:SelectorSet>` +
+			`<w:Selector Name="ShellId">` + s.pool.String() + `</w:Selector>` +
+			`</w:SelectorSet></w:ReferenceParameters></x:ResourceCreated>`
+	case "Command":
+		messages := s.messages(request)
+		if len(messages) != 1 || messages[0].Type != psrpCreatePipeline {
+			s.t.Errorf("bad pipeline creation messages: %#v", messages)
+			break
+		}
+		n, err := parseCLIXML(messages[0].Data)
+		if err != nil {
+			s.t.Error(err)
+			break
+		}
+		cmd := &n.property("PowerShell").property("Cmds").Nodes[1].Nodes[0]
+		command := cmd.property("Args").Nodes[1].Nodes[0].property("V").String()
+		id := messages[0].Pipeline.String()
+		s.pending[id] = append(s.run(command), &psrpMessage{
+			Type: psrpPipelineState,
+			Data: `<Obj RefId="0"><MS><I32 N="PipelineState">4</I32></MS></Obj>`,
+		})
+		body = `<rsp:CommandResponse><rsp:CommandId>` + id + `</rsp:CommandId></rsp:CommandResponse>`
+	case "Receive":
+		id := ""
+		if m := psrpServerCommand.FindStringSubmatch(request); m != nil {
+			id = m[1]
+		}
+		var f psrpFragmenter
+		var stream []byte
+		for _, m := range s.pending[id] {
+			m.Destination = psrpDestinationClient
+			m.RunspacePool = s.pool
+			for _, frag := range f.fragment(m) {
+				stream = append(stream, frag...)
+			}
+		}
+		delete(s.pending, id)
+		body = `<rsp:ReceiveResponse><rsp:Stream Name="stdout">` +
+			base64.StdEncoding.EncodeToString(stream) + `</rsp:Stream>`
+		if id != "" {
+			body += `<rsp:CommandState CommandId="` + id +
+				`" State="http://schemas.microsoft.com/wbem/wsman/1/windows/shell/CommandState/Done"/>`
+		}
+		body += `</rsp:ReceiveResponse>`
+	}
+
+	w.Header().Set("Content-Type", "application/soap+xml;charset=UTF-8")
+	fmt.Fprintf(w, `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"`+
+		` xmlns:x="http://schemas.xmlsoap.org/ws/2004/09/transfer"`+
+		` xmlns:w="http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd"`+
+		` xmlns:rsp="http://schemas.microsoft.com/wbem/wsman/1/windows/shell">`+
+		`<s:Header/><s:Body>%s</s:Body></s:Envelope>`, body)
+}
+
+func TestStart_psrp(t *testing.T) {
+	var command string
+	s := newPSRPServer(t, func(c string) []*psrpMessage {
+		command = c
+		return []*psrpMessage{
+			{Type: psrpPipelineOutput, Data: `<S>foo_x000D__x000A_bar</S>`},
+			{Type: psrpErrorRecord, Data: `<Obj RefId="0"><ToString>oops</ToString><MS /></Obj>`},
+			{Type: psrpPipelineOutput, Data: `<I32>3</I32>`},
+		}
+	})
+	defer s.Close()
+	host, port := s.hostPort()
+
+	c, err := New(&Config{
+		Host:     host,
+		Port:     port,
+		Username: "user",
+		Password: "pass",
+		Timeout:  30 * time.Second,
+		UsePSRP:  true,
+	})
+	if err != nil {
+		t.Fatalf("error creating communicator: %s", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := &packer.RemoteCmd{Command: `echo "foo" & echo bar`, Stdout: &stdout, Stderr: &stderr}
+	if err := c.Start(context.Background(), cmd); err != nil {
+		t.Fatalf("error executing remote command: %s", err)
+	}
+	if status := cmd.Wait(); status != 3 {
+		t.Fatalf("bad exit status: %d", status)
+	}
+	if command != cmd.Command {
+		t.Fatalf("bad command run: %q", command)
+	}
+	if stdout.String() != "foo\r\nbar\r\n" {
+		t.Fatalf("bad stdout: %q", stdout.String())
+	}
+	if stderr.String() != "oops\r\n" {
+		t.Fatalf("bad stderr: %q", stderr.String())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expected := "Create Receive Delete Create Receive Command Receive Signal Delete"
+	if got := strings.Join(s.requests, " "); got != expected {
+		t.Fatalf("bad requests: %s", got)
+	}
+}
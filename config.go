@@ -116,6 +116,34 @@ func (c *config) loadSingleComponent(path string) (string, error) {
 	return pluginName, nil
 }
 
+// LoadPlugin registers the component of the plugin binary at path, like the
+// plugins installed by packer init.
+func (c *config) LoadPlugin(path string) error {
+	name, err := c.loadSingleComponent(path)
+	if err != nil {
+		return err
+	}
+	log.Printf("loaded plugin: %s = %s", name, path)
+	return nil
+}
+
+// PluginDirectories returns the directories plugins are installed in by
+// packer init, by order of preference: the ones of PACKER_PLUGIN_PATH, then
+// the plugins directory of the config directory.
+func (c *config) PluginDirectories() []string {
+	var dirs []string
+	if packerPluginPath := os.Getenv("PACKER_PLUGIN_PATH"); packerPluginPath != "" {
+		dirs = append(dirs, filepath.SplitList(packerPluginPath)...)
+	}
+	dir, err := packer.ConfigDir()
+	if err != nil {
+		log.Printf("[ERR] Error loading config directory: %s", err)
+	} else {
+		dirs = append(dirs, filepath.Join(dir, "plugins"))
+	}
+	return dirs
+}
+
 // Discover discovers plugins.
 //
 // Search the directory of the executable, then the plugins directory, and
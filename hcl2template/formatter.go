@@ -0,0 +1,343 @@
+package hcl2template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/packer/template"
+)
+
+const hcl2VarFileSuffix = ".pkrvars.hcl"
+
+// topLevelBlockOrder is the order of the top level blocks of a formatted
+// file; blocks of the same type keep their order.
+var topLevelBlockOrder = map[string]int{
+	variablesLabel:    0,
+	variableLabel:     1,
+	localsLabel:       2,
+	communicatorLabel: 3,
+	semaphoreLabel:    4,
+	sourceLabel:       5,
+	buildLabel:        6,
+}
+
+// Formatter rewrites HCL2 configuration files and JSON templates to their
+// canonical format.
+type Formatter struct {
+	// ShowDiff writes the differences with the formatted files to Output.
+	ShowDiff bool
+	// Write writes the formatted files in place.
+	Write bool
+	// Recursive formats the files of sub-directories too.
+	Recursive bool
+	// Output is where the names of the files that are not formatted, and
+	// their differences, are written.
+	Output io.Writer
+}
+
+// Format formats the file at path or, when path is a directory, its HCL2
+// files and JSON templates. It returns the number of files that were not
+// formatted.
+func (f *Formatter) Format(path string) (int, hcl.Diagnostics) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Cannot read path",
+			Detail:   err.Error(),
+		}}
+	}
+	if !fi.IsDir() {
+		if strings.HasSuffix(path, hcl2JsonFileExt) || strings.HasSuffix(path, hcl2VarJsonFileExt) {
+			return 0, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Cannot format HCL2 JSON files",
+				Detail:   fmt.Sprintf("%s is written with the JSON syntax of HCL2, only native HCL2 files and JSON templates can be formatted.", path),
+			}}
+		}
+		return f.formatFile(path, strings.HasSuffix(path, ".json"))
+	}
+
+	var diags hcl.Diagnostics
+	changed := 0
+	err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != path && (!f.Recursive || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		isJSON := false
+		switch {
+		case strings.HasSuffix(p, hcl2FileExt), strings.HasSuffix(p, hcl2VarFileSuffix):
+		case strings.HasSuffix(p, ".json") && !strings.HasSuffix(p, hcl2JsonFileExt) && !strings.HasSuffix(p, hcl2VarJsonFileExt):
+			if !isJSONTemplate(p) {
+				return nil
+			}
+			isJSON = true
+		default:
+			return nil
+		}
+		n, moreDiags := f.formatFile(p, isJSON)
+		changed += n
+		diags = append(diags, moreDiags...)
+		return nil
+	})
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Cannot read directory",
+			Detail:   err.Error(),
+		})
+	}
+	return changed, diags
+}
+
+// isJSONTemplate tells whether the JSON file at path looks like a template,
+// so that the other JSON files of a directory are not formatted.
+func isJSONTemplate(path string) bool {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return false
+	}
+	_, ok := raw["builders"]
+	return ok
+}
+
+// formatFile formats one file, and returns 1 when it was not formatted.
+func (f *Formatter) formatFile(path string, isJSON bool) (int, hcl.Diagnostics) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Cannot read file",
+			Detail:   err.Error(),
+		}}
+	}
+
+	var out []byte
+	if isJSON {
+		out, err = template.Format(src)
+		if err != nil {
+			return 0, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Cannot format %s", path),
+				Detail:   err.Error(),
+			}}
+		}
+	} else {
+		var diags hcl.Diagnostics
+		out, diags = FormatHCL(src, path)
+		if diags.HasErrors() {
+			return 0, diags
+		}
+	}
+
+	if bytes.Equal(src, out) {
+		return 0, nil
+	}
+	if f.Output != nil {
+		fmt.Fprintln(f.Output, path)
+	}
+	if f.ShowDiff {
+		diff, err := bytesDiff(src, out, path)
+		if err != nil {
+			return 1, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Cannot diff %s", path),
+				Detail:   err.Error(),
+			}}
+		}
+		if f.Output != nil {
+			f.Output.Write(diff)
+		}
+	}
+	if f.Write {
+		if err := ioutil.WriteFile(path, out, 0644); err != nil {
+			return 1, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Cannot write %s", path),
+				Detail:   err.Error(),
+			}}
+		}
+	}
+	return 1, nil
+}
+
+// FormatHCL returns the canonical formatting of an HCL2 file: the top level
+// blocks ordered by type, and in each body the attributes sorted by name
+// before the nested blocks, which keep their order. Bodies with comments
+// that are not attached to an attribute or a block are only reindented.
+func FormatHCL(src []byte, filename string) ([]byte, hcl.Diagnostics) {
+	// hclwrite doesn't fully validate its input.
+	if _, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1}); diags.HasErrors() {
+		return nil, diags
+	}
+	file, diags := hclwrite.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	tokens := canonicalBody(file.Body(), true)
+	return hclwrite.Format(tokens.Bytes()), nil
+}
+
+// canonicalBody returns the tokens of body in canonical order.
+func canonicalBody(body *hclwrite.Body, topLevel bool) hclwrite.Tokens {
+	attrs := body.Attributes()
+	blocks := body.Blocks()
+
+	names := make([]string, 0, len(attrs))
+	itemComments := 0
+	for name, attr := range attrs {
+		names = append(names, name)
+		itemComments += countComments(attr.BuildTokens(nil))
+	}
+	for _, block := range blocks {
+		itemComments += countComments(block.BuildTokens(nil))
+	}
+	if all := body.BuildTokens(nil); countComments(all) != itemComments {
+		// Keep the order of the items, but still order the bodies of the
+		// blocks.
+		var tokens hclwrite.Tokens
+		for _, block := range blocks {
+			blockTokens := block.BuildTokens(nil)
+			start := indexToken(all, blockTokens[0])
+			tokens = append(tokens, all[:start]...)
+			tokens = append(tokens, canonicalBlock(block)...)
+			all = all[start+len(blockTokens):]
+		}
+		return append(tokens, all...)
+	}
+	sort.Strings(names)
+	if topLevel {
+		sort.SliceStable(blocks, func(i, j int) bool {
+			return blockRank(blocks[i]) < blockRank(blocks[j])
+		})
+	}
+
+	var tokens hclwrite.Tokens
+	for _, name := range names {
+		tokens = appendLine(tokens, attrs[name].BuildTokens(nil))
+	}
+	for i, block := range blocks {
+		if i > 0 || len(names) > 0 {
+			tokens = append(tokens, newlineToken())
+		}
+		tokens = appendLine(tokens, canonicalBlock(block))
+	}
+	return tokens
+}
+
+// canonicalBlock returns the tokens of block, with its body in canonical
+// order.
+func canonicalBlock(block *hclwrite.Block) hclwrite.Tokens {
+	all := block.BuildTokens(nil)
+	body := block.Body().BuildTokens(nil)
+	if len(body) == 0 {
+		return all
+	}
+	start := indexToken(all, body[0])
+	end := start + len(body)
+
+	tokens := append(hclwrite.Tokens{}, all[:start]...)
+	if tokens[len(tokens)-1].Type == hclsyntax.TokenOBrace {
+		// The newline opening the body is part of its tokens.
+		tokens = append(tokens, newlineToken())
+	}
+	tokens = appendLine(tokens, canonicalBody(block.Body(), false))
+	return append(tokens, all[end:]...)
+}
+
+// indexToken returns the index of t in tokens. The tokens of the items of a
+// body are shared with the ones of the body.
+func indexToken(tokens hclwrite.Tokens, t *hclwrite.Token) int {
+	for i := range tokens {
+		if tokens[i] == t {
+			return i
+		}
+	}
+	panic("token not found")
+}
+
+func blockRank(block *hclwrite.Block) int {
+	if rank, ok := topLevelBlockOrder[block.Type()]; ok {
+		return rank
+	}
+	return len(topLevelBlockOrder)
+}
+
+func countComments(tokens hclwrite.Tokens) int {
+	n := 0
+	for _, t := range tokens {
+		if t.Type == hclsyntax.TokenComment {
+			n++
+		}
+	}
+	return n
+}
+
+// appendLine appends line to tokens, making sure it ends with a newline.
+func appendLine(tokens, line hclwrite.Tokens) hclwrite.Tokens {
+	tokens = append(tokens, line...)
+	if len(line) == 0 {
+		return tokens
+	}
+	last := line[len(line)-1]
+	if last.Type == hclsyntax.TokenNewline || (last.Type == hclsyntax.TokenComment && bytes.HasSuffix(last.Bytes, []byte("\n"))) {
+		return tokens
+	}
+	return append(tokens, newlineToken())
+}
+
+func newlineToken() *hclwrite.Token {
+	return &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")}
+}
+
+// bytesDiff returns the unified diff of b1 and b2, using the diff command.
+func bytesDiff(b1, b2 []byte, path string) ([]byte, error) {
+	f1, err := ioutil.TempFile("", "packer-fmt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f1.Name())
+	defer f1.Close()
+
+	f2, err := ioutil.TempFile("", "packer-fmt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f2.Name())
+	defer f2.Close()
+
+	if _, err := f1.Write(b1); err != nil {
+		return nil, err
+	}
+	if _, err := f2.Write(b2); err != nil {
+		return nil, err
+	}
+
+	data, err := exec.Command("diff", "--label=old/"+path, "--label=new/"+path, "-u", f1.Name(), f2.Name()).CombinedOutput()
+	if len(data) > 0 {
+		// diff exits with a non-zero status when the files differ.
+		err = nil
+	}
+	return data, err
+}
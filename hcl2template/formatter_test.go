@@ -0,0 +1,111 @@
+package hcl2template
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormatHCL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"testdata/format/unformatted.pkr.hcl", "testdata/format/formatted.pkr.hcl"},
+		{"testdata/format/formatted.pkr.hcl", "testdata/format/formatted.pkr.hcl"},
+		{"testdata/format/comments.pkr.hcl", "testdata/format/comments_formatted.pkr.hcl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			src, err := ioutil.ReadFile(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := ioutil.ReadFile(tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, diags := FormatHCL(src, tt.in)
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("wrong formatting: %s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatHCL_invalid(t *testing.T) {
+	_, diags := FormatHCL([]byte("build {\n"), "invalid.pkr.hcl")
+	if !diags.HasErrors() {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestFormatter_Format(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-fmt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.pkr.hcl":          "variable \"a\" {\ntype=string\n}\n",
+		"ok.pkr.hcl":         "variable \"a\" {\n  type = string\n}\n",
+		"template.json":      `{"builders":[{"name":"a","type":"null"}]}`,
+		"other.json":         `{"b":1,"a":2}`,
+		"sub/b.pkrvars.hcl":  "b=1\na=2\n",
+		".hidden/c.pkr.hcl":  "a=1\n",
+		"a.pkr.json":         `{"b":1,"a":2}`,
+		"notes.txt":          "a=1",
+		"sub/other.hcl":      "a   =1",
+		"sub/sub/d.pkr.hcl":  "locals {\na=1\n}\n",
+		"sub/template2.json": `{"builders":[]}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	f := &Formatter{Output: &out}
+	changed, diags := f.Format(dir)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	want := filepath.Join(dir, "a.pkr.hcl") + "\n" + filepath.Join(dir, "template.json") + "\n"
+	if changed != 2 || out.String() != want {
+		t.Fatalf("unexpected changes %d:\n%s", changed, out.String())
+	}
+
+	out.Reset()
+	f = &Formatter{Output: &out, Recursive: true, Write: true}
+	changed, diags = f.Format(dir)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	if changed != 5 {
+		t.Fatalf("unexpected changes %d:\n%s", changed, out.String())
+	}
+	got, err := ioutil.ReadFile(filepath.Join(dir, "sub/b.pkrvars.hcl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "a = 2\nb = 1\n" {
+		t.Errorf("unexpected formatted var file:\n%s", got)
+	}
+
+	changed, diags = f.Format(dir)
+	if diags.HasErrors() || changed != 0 {
+		t.Fatalf("formatted files changed again: %d %s", changed, diags)
+	}
+}
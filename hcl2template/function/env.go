@@ -0,0 +1,28 @@
+package function
+
+import (
+	"os"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// EnvFunc constructs a function that returns the value of an environment
+// variable, or an empty string when it is not set.
+var EnvFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "key",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.StringVal(os.Getenv(args[0].AsString())), nil
+	},
+})
+
+// Env returns the value of the environment variable called key.
+func Env(key cty.Value) (cty.Value, error) {
+	return EnvFunc.Call([]cty.Value{key})
+}
@@ -0,0 +1,69 @@
+package function
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// secretFunc returns a function reading a string secret from a remote
+// store. required is the number of mandatory string parameters, optional
+// ones are passed as the empty string when not set. Every value read is
+// registered as sensitive so that it never appears in the logs.
+func secretFunc(get func(args ...string) (string, error), required []string, optional string) function.Function {
+	params := make([]function.Parameter, len(required))
+	for i, name := range required {
+		params[i] = function.Parameter{Name: name, Type: cty.String}
+	}
+	spec := &function.Spec{
+		Params: params,
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if len(args) > len(required)+1 {
+				return cty.NullVal(cty.String), fmt.Errorf("too many arguments, at most %d expected", len(required)+1)
+			}
+			strs := make([]string, len(required)+1)
+			for i, arg := range args {
+				strs[i] = arg.AsString()
+			}
+			v, err := get(strs...)
+			if err != nil {
+				return cty.NullVal(cty.String), err
+			}
+			packer.LogSecretFilter.Set(v)
+			return cty.StringVal(v), nil
+		},
+	}
+	if optional != "" {
+		spec.VarParam = &function.Parameter{Name: optional, Type: cty.String}
+	}
+	return function.New(spec)
+}
+
+// VaultFunc constructs a function that reads key from the Vault secret
+// at path.
+var VaultFunc = secretFunc(func(args ...string) (string, error) {
+	return interpolate.VaultSecret(args[0], args[1])
+}, []string{"path", "key"}, "")
+
+// AWSSecretsManagerFunc constructs a function that reads a secret from AWS
+// Secrets Manager. The key parameter is optional when the secret only
+// holds one value.
+var AWSSecretsManagerFunc = secretFunc(func(args ...string) (string, error) {
+	return interpolate.AWSSecret(args[0], args[1])
+}, []string{"name"}, "key")
+
+// AzureKeyVaultFunc constructs a function that reads a secret from an
+// Azure Key Vault. The version parameter is optional.
+var AzureKeyVaultFunc = secretFunc(func(args ...string) (string, error) {
+	return interpolate.AzureKeyVaultSecret(args[0], args[1], args[2])
+}, []string{"vault_name", "name"}, "version")
+
+// GCPSecretManagerFunc constructs a function that reads a secret from GCP
+// Secret Manager. The version parameter is optional.
+var GCPSecretManagerFunc = secretFunc(func(args ...string) (string, error) {
+	return interpolate.GCPSecret(args[0], args[1], args[2])
+}, []string{"project_id", "name"}, "version")
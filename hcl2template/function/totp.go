@@ -0,0 +1,32 @@
+package function
+
+import (
+	"github.com/hashicorp/packer/helper/totp"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// TOTPFunc constructs a function that returns the current time-based
+// one-time password of secret, the base32 seed or otpauth:// URI of an
+// authenticator app.
+var TOTPFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "secret",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		code, err := totp.Code(args[0].AsString())
+		if err != nil {
+			return cty.NullVal(cty.String), err
+		}
+		return cty.StringVal(code), nil
+	},
+})
+
+// TOTP returns the current one-time password of secret.
+func TOTP(secret cty.Value) (cty.Value, error) {
+	return TOTPFunc.Call([]cty.Value{secret})
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/ext/dynblock"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/packer/helper/secretfile"
 	"github.com/hashicorp/packer/packer"
 )
 
@@ -28,6 +30,9 @@ var configSchema = &hcl.BodySchema{
 		{Type: localsLabel},
 		{Type: buildLabel},
 		{Type: communicatorLabel, LabelNames: []string{"type", "name"}},
+		{Type: packerLabel},
+		{Type: importLabel},
+		{Type: semaphoreLabel, LabelNames: []string{"name"}},
 	},
 }
 
@@ -43,6 +48,10 @@ type Parser struct {
 	ProvisionersSchemas packer.ProvisionerStore
 
 	PostProcessorsSchemas packer.PostProcessorStore
+
+	// PluginConfig, when set, is used to load the plugins required by the
+	// configuration.
+	PluginConfig *packer.PluginConfig
 }
 
 const (
@@ -91,6 +100,11 @@ func (p *Parser) Parse(filename string, varFiles []string, argVars map[string]st
 		}
 	}
 
+	// parse imported config files, they come after the files importing them
+	imported, importDepths, moreDiags := p.parseImports(files)
+	diags = append(diags, moreDiags...)
+	files = append(files, imported...)
+
 	basedir := filename
 	if isDir, err := isDir(basedir); err == nil && !isDir {
 		basedir = filepath.Dir(basedir)
@@ -111,6 +125,13 @@ func (p *Parser) Parse(filename string, varFiles []string, argVars map[string]st
 		postProcessorsSchemas: p.PostProcessorsSchemas,
 		parser:                p,
 		files:                 files,
+		importDepths:          importDepths,
+	}
+
+	// Decode required plugins first, they only depend on the configuration
+	// files and are needed to install plugins.
+	for _, file := range files {
+		diags = append(diags, cfg.decodeRequiredPlugins(file)...)
 	}
 
 	// Decode variable blocks so that they are available later on. Here locals
@@ -129,41 +150,48 @@ func (p *Parser) Parse(filename string, varFiles []string, argVars map[string]st
 
 	// parse var files
 	{
+		// Auto-loaded var files come first, in the lexical order of their
+		// names, no matter if they are HCL or JSON files. Files passed as
+		// arguments come next, in the order they were given, so that they
+		// can override auto-loaded values.
 		hclVarFiles, jsonVarFiles, moreDiags := GetHCL2Files(filename, hcl2VarFileExt, hcl2VarJsonFileExt)
 		diags = append(diags, moreDiags...)
-		for _, file := range varFiles {
-			switch filepath.Ext(file) {
+		autoVarFiles := append(hclVarFiles, jsonVarFiles...)
+		sort.Strings(autoVarFiles)
+
+		var parsedVarFiles []*hcl.File
+		for _, filename := range append(autoVarFiles, varFiles...) {
+			var f *hcl.File
+			// Var files encrypted with sops or age are decrypted in memory.
+			src, err := secretfile.ReadFile(filename)
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Failed to read var file " + filename,
+					Detail:   err.Error(),
+				})
+				continue
+			}
+			switch filepath.Ext(secretfile.TrimAgeExt(filename)) {
 			case ".hcl":
-				hclVarFiles = append(hclVarFiles, file)
+				f, moreDiags = p.ParseHCL(src, filename)
 			case ".json":
-				jsonVarFiles = append(jsonVarFiles, file)
+				f, moreDiags = p.ParseJSON(src, filename)
 			default:
-				diags = append(moreDiags, &hcl.Diagnostic{
+				moreDiags = hcl.Diagnostics{&hcl.Diagnostic{
 					Severity: hcl.DiagError,
-					Summary:  "Could not guess format of " + file,
-					Detail:   "A var file must be suffixed with `.hcl` or `.json`.",
-				})
-			}
-		}
-		var varFiles []*hcl.File
-		for _, filename := range hclVarFiles {
-			f, moreDiags := p.ParseHCLFile(filename)
-			diags = append(diags, moreDiags...)
-			if moreDiags.HasErrors() {
-				continue
+					Summary:  "Could not guess format of " + filename,
+					Detail:   "A var file must be suffixed with `.hcl` or `.json`, followed by `.age` when it is encrypted with age.",
+				}}
 			}
-			varFiles = append(varFiles, f)
-		}
-		for _, filename := range jsonVarFiles {
-			f, moreDiags := p.ParseJSONFile(filename)
 			diags = append(diags, moreDiags...)
 			if moreDiags.HasErrors() {
 				continue
 			}
-			varFiles = append(varFiles, f)
+			parsedVarFiles = append(parsedVarFiles, f)
 		}
 
-		diags = append(diags, cfg.collectInputVariableValues(os.Environ(), varFiles, argVars)...)
+		diags = append(diags, cfg.collectInputVariableValues(os.Environ(), parsedVarFiles, argVars)...)
 	}
 	return cfg, diags
 }
@@ -171,6 +199,12 @@ func (p *Parser) Parse(filename string, varFiles []string, argVars map[string]st
 func (cfg *PackerConfig) Initialize() hcl.Diagnostics {
 	var diags hcl.Diagnostics
 
+	// Load the required plugins before decoding the sources that use them.
+	diags = append(diags, cfg.detectPluginBinaries()...)
+	if diags.HasErrors() {
+		return diags
+	}
+
 	_, moreDiags := cfg.InputVariables.Values()
 	diags = append(diags, moreDiags...)
 	_, moreDiags = cfg.LocalVariables.Values()
@@ -206,6 +240,9 @@ func (p *Parser) decodeConfig(f *hcl.File, cfg *PackerConfig) hcl.Diagnostics {
 
 			ref := source.Ref()
 			if existing, found := cfg.Sources[ref]; found {
+				if cfg.shadowed(existing.block.DefRange, source.block.DefRange) {
+					continue
+				}
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Duplicate " + sourceLabel + " block",
@@ -231,6 +268,13 @@ func (p *Parser) decodeConfig(f *hcl.File, cfg *PackerConfig) hcl.Diagnostics {
 			}
 			cfg.Builds = append(cfg.Builds, build)
 
+		case semaphoreLabel:
+			semaphore, moreDiags := cfg.decodeSemaphore(block)
+			diags = append(diags, moreDiags...)
+			if moreDiags.HasErrors() {
+				continue
+			}
+			diags = append(diags, cfg.addSemaphore(semaphore)...)
 		}
 	}
 
@@ -0,0 +1,27 @@
+package repl
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// IncompleteExpression tells whether src is the beginning of an expression
+// that continues on the next lines: when it has brackets, quotes,
+// interpolations or heredocs that are not closed yet.
+func IncompleteExpression(src string) bool {
+	tokens, _ := hclsyntax.LexExpression([]byte(src), "<console-input>", hcl.Pos{Line: 1, Column: 1})
+	depth := 0
+	for _, t := range tokens {
+		switch t.Type {
+		case hclsyntax.TokenOBrace, hclsyntax.TokenOBrack, hclsyntax.TokenOParen,
+			hclsyntax.TokenOQuote, hclsyntax.TokenOHeredoc,
+			hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenCBrace, hclsyntax.TokenCBrack, hclsyntax.TokenCParen,
+			hclsyntax.TokenCQuote, hclsyntax.TokenCHeredoc,
+			hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		}
+	}
+	return depth > 0
+}
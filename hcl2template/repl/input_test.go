@@ -0,0 +1,29 @@
+package repl
+
+import "testing"
+
+func TestIncompleteExpression(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{``, false},
+		{`var.foo`, false},
+		{`upper(var.foo)`, false},
+		{`upper(`, true},
+		{`{`, true},
+		{"{\n  a = 1\n}", false},
+		{`[1,`, true},
+		{`"${var.foo}"`, false},
+		{`"${var.foo`, true},
+		{`"abc`, true},
+		{"<<EOF\nabc\n", true},
+		{"<<EOF\nabc\nEOF\n", false},
+		{`)`, false},
+	}
+	for _, tt := range tests {
+		if got := IncompleteExpression(tt.src); got != tt.want {
+			t.Errorf("IncompleteExpression(%q) = %t, want %t", tt.src, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+    string       = "${packer.build_uuid}-${packer.git_dirty ? "dirty" : "clean"}"
+    not_squashed = "${packer.build_dir}/out"
+}
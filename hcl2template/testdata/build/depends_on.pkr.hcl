@@ -0,0 +1,18 @@
+// app is built from the image of base.
+build {
+    name = "base"
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+}
+
+build {
+    name = "app"
+    depends_on = ["base.virtualbox-iso.ubuntu-1204"]
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
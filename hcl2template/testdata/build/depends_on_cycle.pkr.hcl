@@ -0,0 +1,18 @@
+build {
+    name = "a"
+    depends_on = ["b.virtualbox-iso.ubuntu-1204"]
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+}
+
+build {
+    name = "b"
+    depends_on = ["a.virtualbox-iso.ubuntu-1204"]
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
@@ -0,0 +1,21 @@
+build {
+    name = "base"
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+}
+
+build {
+    name = "app"
+    depends_on = ["base.virtualbox-iso.ubuntu-1204"]
+    sources = [
+        "source.amazon-ebs.ubuntu-1604"
+    ]
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
+
+source "amazon-ebs" "ubuntu-1604" {
+    string = outputs["base.virtualbox-iso.ubuntu-1204"].id
+}
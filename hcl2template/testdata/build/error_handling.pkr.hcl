@@ -0,0 +1,20 @@
+// starts resources to provision them.
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    error_handling {
+        on_failure = "abort"
+    }
+
+    provisioner "shell" {
+        error_handling {
+            retries    = 3
+            on_failure = "continue"
+        }
+    }
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
@@ -0,0 +1,23 @@
+variable "password" {
+    type    = string
+    default = "packer"
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+    ssh_password = var.password
+    boot_wait    = "10m"
+}
+
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    provisioner "shell" {
+        inline = ["echo ${build.ID}"]
+    }
+
+    post-processor "manifest" {
+        output = "manifest.json"
+    }
+}
@@ -0,0 +1,10 @@
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    locks = ["image/${source.name}", "output/${source.type}"]
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
@@ -0,0 +1,21 @@
+// starts resources to provision them.
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    notification "slack" {
+        on       = ["failure"]
+        url      = "https://hooks.slack.com/services/T/B/X"
+        template = "{{ .BuildName }} failed: {{ .Error }}"
+    }
+
+    notification "smtp" {
+        smtp_address = "mail.example.com:587"
+        from         = "packer@example.com"
+        to           = ["ops@example.com"]
+    }
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
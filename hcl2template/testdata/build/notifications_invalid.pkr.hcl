@@ -0,0 +1,13 @@
+// starts resources to provision them.
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    notification "slack" {
+        on = ["finish"]
+    }
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
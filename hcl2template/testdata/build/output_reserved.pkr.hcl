@@ -0,0 +1,12 @@
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    output "id" {
+        value = artifact.id
+    }
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
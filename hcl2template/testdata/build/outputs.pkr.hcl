@@ -0,0 +1,20 @@
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    output "image" {
+        value = "${source.name}-${artifact.id}"
+    }
+
+    output "ami" {
+        value = artifact.metadata["region.us-east-1"]
+    }
+
+    output "count" {
+        value = length(artifacts)
+    }
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
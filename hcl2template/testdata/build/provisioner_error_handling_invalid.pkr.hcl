@@ -0,0 +1,15 @@
+// starts resources to provision them.
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    provisioner "shell" {
+        error_handling {
+            on_failure = "run-cleanup-provisioner"
+        }
+    }
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
@@ -0,0 +1,4 @@
+semaphore "virtualbox" {
+    limit  = 0
+    builds = ["virtualbox-iso.*"]
+}
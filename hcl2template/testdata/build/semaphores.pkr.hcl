@@ -0,0 +1,26 @@
+variable "cloud_limit" {
+    default = 8
+}
+
+semaphore "virtualbox" {
+    limit  = 2
+    builds = ["virtualbox-iso.*"]
+}
+
+semaphore "all" {
+    limit  = var.cloud_limit
+    builds = ["*"]
+}
+
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204",
+        "source.amazon-ebs.ubuntu-1604",
+    ]
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
+
+source "amazon-ebs" "ubuntu-1604" {
+}
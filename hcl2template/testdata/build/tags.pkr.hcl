@@ -0,0 +1,25 @@
+// The builds of linux are tagged linux and ci, the ones of windows are
+// tagged windows and ci, and the amazon-ebs builds are also tagged cloud.
+build {
+    name = "linux"
+    tags = ["linux", "ci"]
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204",
+        "source.amazon-ebs.ubuntu-1604",
+    ]
+}
+
+build {
+    name = "windows"
+    tags = ["windows", "ci"]
+    sources = [
+        "source.amazon-ebs.ubuntu-1604",
+    ]
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
+
+source "amazon-ebs" "ubuntu-1604" {
+    build_tags = ["cloud"]
+}
@@ -0,0 +1,13 @@
+// starts resources to provision them.
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    build_timeout        = "2h"
+    provision_timeout    = "1h"
+    post_process_timeout = "30m"
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
@@ -0,0 +1,11 @@
+// starts resources to provision them.
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    provision_timeout = "-1h"
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
@@ -0,0 +1,19 @@
+// starts resources to provision them.
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    provisioner "shell" {
+    }
+
+    verify {
+        on_failure = "continue"
+
+        provisioner "file" {
+        }
+    }
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
@@ -0,0 +1,16 @@
+// starts resources to provision them.
+build {
+    sources = [
+        "source.virtualbox-iso.ubuntu-1204"
+    ]
+
+    verify {
+        on_failure = "retry"
+
+        provisioner "shell" {
+        }
+    }
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
@@ -0,0 +1,10 @@
+build {
+  sources = ["source.null.a"]
+}
+
+# Kept where it is.
+
+source "null" "a" {
+  communicator = "none"
+  boot_wait    = "1s"
+}
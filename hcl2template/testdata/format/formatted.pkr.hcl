@@ -0,0 +1,26 @@
+variable "b" {
+  default = "x"
+  type    = string
+}
+
+locals {
+  a = 2
+  z = 1
+}
+
+source "null" "a" {
+  communicator = "none"
+}
+
+build {
+  name    = "x"
+  sources = ["source.null.a"]
+
+  # run it
+  provisioner "shell-local" {
+    environment_vars = ["A=1"] # inline
+    inline           = ["echo"]
+  }
+
+  provisioner "file" {}
+}
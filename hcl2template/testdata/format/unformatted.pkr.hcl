@@ -0,0 +1,25 @@
+build {
+  sources = ["source.null.a"]
+    name = "x"
+  # run it
+  provisioner "shell-local" {
+    inline = ["echo"]
+    environment_vars = ["A=1"] # inline
+  }
+  provisioner "file" {}
+}
+
+source "null" "a" {
+  communicator = "none"
+}
+
+variable "b" {
+  type = string
+  default = "x"
+}
+
+
+locals {
+  z = 1
+  a = 2
+}
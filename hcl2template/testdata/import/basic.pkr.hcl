@@ -0,0 +1,15 @@
+
+import {
+  path = "shared"
+}
+
+variable "image_name" {
+  default = "basic"
+}
+
+locals {
+  owner = "basic"
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+}
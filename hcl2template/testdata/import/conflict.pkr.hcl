@@ -0,0 +1,4 @@
+
+import {
+  path = "conflict"
+}
@@ -0,0 +1,12 @@
+
+// Imports the importing file back, which is only loaded once.
+import {
+  path = "../basic.pkr.hcl"
+}
+
+source "virtualbox-iso" "ubuntu-1204" {
+  iso_url = "shadowed"
+}
+
+source "amazon-ebs" "shared" {
+}
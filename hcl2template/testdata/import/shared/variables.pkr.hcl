@@ -0,0 +1,13 @@
+
+variable "image_name" {
+  default = "shared"
+}
+
+variable "region" {
+  default = "us-east-1"
+}
+
+locals {
+  owner = "shared"
+  team  = "images"
+}
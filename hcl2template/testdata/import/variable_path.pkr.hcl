@@ -0,0 +1,8 @@
+
+variable "shared" {
+  default = "shared"
+}
+
+import {
+  path = var.shared
+}
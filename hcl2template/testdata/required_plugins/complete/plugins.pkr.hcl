@@ -0,0 +1,11 @@
+packer {
+  required_plugins {
+    amazon = {
+      source  = "github.com/hashicorp/amazon"
+      version = ">= 1.0.0, < 2.0.0"
+    }
+    comment = {
+      source = "github.com/sylviamoss/comment"
+    }
+  }
+}
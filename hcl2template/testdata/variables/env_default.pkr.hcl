@@ -0,0 +1,5 @@
+
+variable "home" {
+  type    = string
+  default = env("PACKER_TEST_ENV_DEFAULT")
+}
@@ -0,0 +1,148 @@
+package hcl2template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+const (
+	buildFilterTagPrefix = "tag:"
+	tagsAccessor         = "tags"
+)
+
+// buildFilter is a pattern of the -only or -except command line options. It
+// selects builds by name with a glob pattern like "*.amazon-ebs.*", by tag
+// with a glob pattern prefixed with "tag:" like "tag:linux-*", or with an HCL
+// boolean expression between "${" and "}", like:
+//
+//	${contains(tags, "linux") && source.type == "amazon-ebs"}
+type buildFilter struct {
+	name glob.Glob
+	tag  glob.Glob
+	expr hcl.Expression
+}
+
+// parseBuildFilters parses the patterns of the -only or -except option, or of
+// the builds of a semaphore, described by optionName like "-only".
+func parseBuildFilters(patterns []string, optionName string) ([]buildFilter, hcl.Diagnostics) {
+	var filters []buildFilter
+	var diags hcl.Diagnostics
+
+	for _, pattern := range patterns {
+		var f buildFilter
+		var err error
+		switch {
+		case strings.HasPrefix(pattern, "${") && strings.HasSuffix(pattern, "}"):
+			src := strings.TrimSuffix(strings.TrimPrefix(pattern, "${"), "}")
+			expr, moreDiags := hclsyntax.ParseExpression([]byte(src), optionName, hcl.InitialPos)
+			if moreDiags.HasErrors() {
+				diags = append(diags, &hcl.Diagnostic{
+					Summary:  fmt.Sprintf("Invalid %s expression %s: %s", optionName, pattern, moreDiags.Error()),
+					Severity: hcl.DiagError,
+				})
+				continue
+			}
+			f.expr = expr
+		case strings.HasPrefix(pattern, buildFilterTagPrefix):
+			f.tag, err = glob.Compile(strings.TrimPrefix(pattern, buildFilterTagPrefix))
+		default:
+			f.name, err = glob.Compile(pattern)
+		}
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Summary:  fmt.Sprintf("Invalid %s pattern %s: %s", optionName, pattern, err),
+				Severity: hcl.DiagError,
+			})
+			continue
+		}
+		filters = append(filters, f)
+	}
+
+	return filters, diags
+}
+
+// nameGlobs returns the name patterns of filters, which also apply to the
+// names of post-processors.
+func nameGlobs(filters []buildFilter) []glob.Glob {
+	var globs []glob.Glob
+	for _, f := range filters {
+		if f.name != nil {
+			globs = append(globs, f.name)
+		}
+	}
+	return globs
+}
+
+// matchBuildFilters tells whether one of filters selects the build named
+// buildName of src, tagged with tags.
+func (cfg *PackerConfig) matchBuildFilters(filters []buildFilter, buildName string, src SourceBlock, tags []string) (bool, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	for _, f := range filters {
+		switch {
+		case f.name != nil:
+			if f.name.Match(buildName) {
+				return true, diags
+			}
+		case f.tag != nil:
+			for _, tag := range tags {
+				if f.tag.Match(tag) {
+					return true, diags
+				}
+			}
+		case f.expr != nil:
+			tagValues := cty.ListValEmpty(cty.String)
+			if len(tags) > 0 {
+				vals := make([]cty.Value, len(tags))
+				for i, tag := range tags {
+					vals[i] = cty.StringVal(tag)
+				}
+				tagValues = cty.ListVal(vals)
+			}
+			value, moreDiags := f.expr.Value(cfg.EvalContext(map[string]cty.Value{
+				sourcesAccessor: cty.ObjectVal(src.ctyValues()),
+				tagsAccessor:    tagValues,
+			}))
+			diags = append(diags, moreDiags...)
+			if moreDiags.HasErrors() {
+				continue
+			}
+			value, err := convert.Convert(value, cty.Bool)
+			if err != nil || value.IsNull() || !value.IsKnown() {
+				diags = append(diags, &hcl.Diagnostic{
+					Summary:  "Invalid build filter result",
+					Detail:   "A build filter expression must be a boolean.",
+					Subject:  f.expr.Range().Ptr(),
+					Severity: hcl.DiagError,
+				})
+				continue
+			}
+			if value.True() {
+				return true, diags
+			}
+		}
+	}
+
+	return false, diags
+}
+
+// mergeTags returns the tags of a build block and of one of its sources,
+// without duplicates.
+func mergeTags(buildTags, sourceTags []string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, tag := range append(buildTags[:len(buildTags):len(buildTags)], sourceTags...) {
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
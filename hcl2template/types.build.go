@@ -1,9 +1,13 @@
 package hcl2template
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/packer/packer"
 )
 
 const (
@@ -16,6 +20,14 @@ const (
 	buildPostProcessorLabel = "post-processor"
 
 	buildPostProcessorsLabel = "post-processors"
+
+	buildErrorHandlingLabel = "error_handling"
+
+	buildOutputLabel = "output"
+
+	buildVerifyLabel = "verify"
+
+	buildNotificationLabel = "notification"
 )
 
 var buildSchema = &hcl.BodySchema{
@@ -25,6 +37,10 @@ var buildSchema = &hcl.BodySchema{
 		{Type: buildProvisionerLabel, LabelNames: []string{"type"}},
 		{Type: buildPostProcessorLabel, LabelNames: []string{"type"}},
 		{Type: buildPostProcessorsLabel, LabelNames: []string{}},
+		{Type: buildErrorHandlingLabel},
+		{Type: buildOutputLabel, LabelNames: []string{"name"}},
+		{Type: buildVerifyLabel},
+		{Type: buildNotificationLabel, LabelNames: []string{"type"}},
 	},
 }
 
@@ -54,30 +70,88 @@ type BuildBlock struct {
 	// Sources is the list of sources that we want to start in this build block.
 	Sources []SourceRef
 
+	// Tags are arbitrary labels of the builds of this block, along with the
+	// build_tags of their sources, that the -only and -except options can
+	// select them by.
+	Tags []string
+
+	// DependsOn are the names of the builds that must succeed before the
+	// builds of this block run, like "base.amazon-ebs.ubuntu". Their outputs
+	// are available through the outputs variable.
+	DependsOn []string
+
 	// ProvisionerBlocks references a list of HCL provisioner block that will
 	// will be ran against the sources.
 	ProvisionerBlocks []*ProvisionerBlock
 
+	// Verify is the verification stage of the build, when it has one.
+	Verify *VerifyBlock
+
 	// PostProcessorLists references the lists of lists of HCL post-processors
 	// block that will be run against the artifacts from the provisioning
 	// steps.
 	PostProcessorsLists [][]*PostProcessorBlock
 
+	// Locks is the list of the names shared with other builds, like image
+	// names, that each build of this block locks in the state backend while
+	// it runs. It is evaluated for every source.
+	Locks hcl.Expression
+
+	// Outputs are the named outputs of the builds of this block, computed
+	// from their artifacts once they succeeded.
+	Outputs []*OutputBlock
+
+	// OnFailure tells what to do when the build fails, like the -on-error
+	// command line option, which takes precedence.
+	OnFailure string
+
+	// BuildTimeout, ProvisionTimeout and PostProcessTimeout bound the time
+	// spent in the whole build, in its provisioning phase and in its
+	// post-processing phase. Zero means no timeout.
+	BuildTimeout       time.Duration
+	ProvisionTimeout   time.Duration
+	PostProcessTimeout time.Duration
+
+	// Notifications are sent when the builds of this block start, succeed
+	// or fail.
+	Notifications []*packer.Notification
+
 	HCL2Ref HCL2Ref
 }
 
 type Builds []*BuildBlock
 
+// OutputBlock references an HCL 'output' block of a build, for example:
+//
+//	output "ami" {
+//		value = artifact.metadata["region.us-east-1"]
+//	}
+type OutputBlock struct {
+	Name string
+	// Value is evaluated with the artifact and artifacts variables.
+	Value hcl.Expression
+
+	HCL2Ref HCL2Ref
+}
+
 // decodeBuildConfig is called when a 'build' block has been detected. It will
 // load the references to the contents of the build block.
 func (p *Parser) decodeBuildConfig(block *hcl.Block, cfg *PackerConfig) (*BuildBlock, hcl.Diagnostics) {
 	build := &BuildBlock{}
 
 	var b struct {
-		Name        string   `hcl:"name,optional"`
-		Description string   `hcl:"description,optional"`
-		FromSources []string `hcl:"sources,optional"`
-		Config      hcl.Body `hcl:",remain"`
+		Name        string         `hcl:"name,optional"`
+		Description string         `hcl:"description,optional"`
+		FromSources []string       `hcl:"sources,optional"`
+		Tags        []string       `hcl:"tags,optional"`
+		DependsOn   []string       `hcl:"depends_on,optional"`
+		Locks       hcl.Expression `hcl:"locks,optional"`
+
+		BuildTimeout       string `hcl:"build_timeout,optional"`
+		ProvisionTimeout   string `hcl:"provision_timeout,optional"`
+		PostProcessTimeout string `hcl:"post_process_timeout,optional"`
+
+		Config hcl.Body `hcl:",remain"`
 	}
 	diags := gohcl.DecodeBody(block.Body, nil, &b)
 	if diags.HasErrors() {
@@ -86,6 +160,38 @@ func (p *Parser) decodeBuildConfig(block *hcl.Block, cfg *PackerConfig) (*BuildB
 
 	build.Name = b.Name
 	build.Description = b.Description
+	build.HCL2Ref = newHCL2Ref(block, b.Config)
+	build.Tags = b.Tags
+	build.DependsOn = b.DependsOn
+	build.Locks = b.Locks
+
+	for _, t := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"build_timeout", b.BuildTimeout, &build.BuildTimeout},
+		{"provision_timeout", b.ProvisionTimeout, &build.ProvisionTimeout},
+		{"post_process_timeout", b.PostProcessTimeout, &build.PostProcessTimeout},
+	} {
+		if t.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(t.value)
+		if err == nil && d < 0 {
+			err = fmt.Errorf("must not be negative")
+		}
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to parse " + t.name + " duration",
+				Detail:   err.Error(),
+				Subject:  block.DefRange.Ptr(),
+			})
+			continue
+		}
+		*t.dst = d
+	}
 
 	for _, buildFrom := range b.FromSources {
 		ref := sourceRefFromString(buildFrom)
@@ -136,6 +242,125 @@ func (p *Parser) decodeBuildConfig(block *hcl.Block, cfg *PackerConfig) (*BuildB
 				continue
 			}
 			build.PostProcessorsLists = append(build.PostProcessorsLists, []*PostProcessorBlock{pp})
+		case buildErrorHandlingLabel:
+			var eh errorHandlingBlock
+			moreDiags := gohcl.DecodeBody(block.Body, nil, &eh)
+			diags = append(diags, moreDiags...)
+			if moreDiags.HasErrors() {
+				continue
+			}
+			if err := packer.ValidateOnFailure(eh.OnFailure, packer.BuildOnFailureValues); err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid error_handling",
+					Detail:   err.Error(),
+					Subject:  block.DefRange.Ptr(),
+				})
+				continue
+			}
+			if eh.Retries != 0 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid error_handling",
+					Detail:   "retries can only be set on provisioners",
+					Subject:  block.DefRange.Ptr(),
+				})
+				continue
+			}
+			build.OnFailure = eh.OnFailure
+		case buildOutputLabel:
+			var o struct {
+				Value hcl.Expression `hcl:"value"`
+			}
+			moreDiags := gohcl.DecodeBody(block.Body, nil, &o)
+			diags = append(diags, moreDiags...)
+			if moreDiags.HasErrors() {
+				continue
+			}
+			name := block.Labels[0]
+			err := packer.ValidateOutputName(name)
+			for _, other := range build.Outputs {
+				if other.Name == name {
+					err = fmt.Errorf("output %q is defined more than once", name)
+				}
+			}
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid output",
+					Detail:   err.Error(),
+					Subject:  block.DefRange.Ptr(),
+				})
+				continue
+			}
+			build.Outputs = append(build.Outputs, &OutputBlock{
+				Name:    name,
+				Value:   o.Value,
+				HCL2Ref: newHCL2Ref(block, block.Body),
+			})
+		case buildVerifyLabel:
+			if build.Verify != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate " + buildVerifyLabel + " block",
+					Detail:   "A build can only have one " + buildVerifyLabel + " block.",
+					Subject:  block.DefRange.Ptr(),
+				})
+				continue
+			}
+			verify, moreDiags := p.decodeVerify(block, cfg)
+			diags = append(diags, moreDiags...)
+			if moreDiags.HasErrors() {
+				continue
+			}
+			build.Verify = verify
+		case buildNotificationLabel:
+			var n struct {
+				On       []string          `hcl:"on,optional"`
+				URL      string            `hcl:"url,optional"`
+				Method   string            `hcl:"method,optional"`
+				Headers  map[string]string `hcl:"headers,optional"`
+				Template string            `hcl:"template,optional"`
+				Subject  string            `hcl:"subject,optional"`
+
+				SMTPAddress  string   `hcl:"smtp_address,optional"`
+				SMTPUsername string   `hcl:"smtp_username,optional"`
+				SMTPPassword string   `hcl:"smtp_password,optional"`
+				From         string   `hcl:"from,optional"`
+				To           []string `hcl:"to,optional"`
+			}
+			moreDiags := gohcl.DecodeBody(block.Body, cfg.EvalContext(nil), &n)
+			diags = append(diags, moreDiags...)
+			if moreDiags.HasErrors() {
+				continue
+			}
+			notification := &packer.Notification{
+				Type:         block.Labels[0],
+				On:           n.On,
+				URL:          n.URL,
+				Method:       n.Method,
+				Headers:      n.Headers,
+				Template:     n.Template,
+				Subject:      n.Subject,
+				SMTPAddress:  n.SMTPAddress,
+				SMTPUsername: n.SMTPUsername,
+				SMTPPassword: n.SMTPPassword,
+				From:         n.From,
+				To:           n.To,
+			}
+			if err := notification.Validate(); err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid " + buildNotificationLabel,
+					Detail:   err.Error(),
+					Subject:  block.DefRange.Ptr(),
+				})
+				continue
+			}
+			if n.SMTPPassword != "" {
+				packer.LogSecretFilter.Set(n.SMTPPassword)
+			}
+			build.Notifications = append(build.Notifications, notification)
 		case buildPostProcessorsLabel:
 
 			content, moreDiags := block.Body.Content(postProcessorsSchema)
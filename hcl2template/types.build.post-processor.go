@@ -90,5 +90,6 @@ func (cfg *PackerConfig) startPostProcessor(source SourceBlock, pp *PostProcesso
 		})
 		return nil, diags
 	}
+	diags = append(diags, deprecationsToDiags("post-processor", pp.PType, postProcessor, pp.DefRange.Ptr())...)
 	return hclPostProcessor, diags
 }
@@ -57,28 +57,46 @@ func (o *OnlyExcept) Validate() hcl.Diagnostics {
 
 // ProvisionerBlock references a detected but unparsed provisioner
 type ProvisionerBlock struct {
-	PType       string
-	PName       string
-	PauseBefore time.Duration
-	MaxRetries  int
-	Timeout     time.Duration
-	OnlyExcept  OnlyExcept
+	PType          string
+	PName          string
+	PauseBefore    time.Duration
+	MaxRetries     int
+	Timeout        time.Duration
+	OnFailure      string
+	RemoteUser     string
+	RemotePassword string
+	// IdempotencyMarker is the file, or registry key, of the guest recording
+	// that the provisioner ran.
+	IdempotencyMarker string
+	OnlyExcept        OnlyExcept
 	HCL2Ref
 }
 
+// errorHandlingBlock is the content of an error_handling block.
+type errorHandlingBlock struct {
+	Retries   int    `hcl:"retries,optional"`
+	OnFailure string `hcl:"on_failure,optional"`
+}
+
 func (p *ProvisionerBlock) String() string {
 	return fmt.Sprintf(buildProvisionerLabel+"-block %q %q", p.PType, p.PName)
 }
 
 func (p *Parser) decodeProvisioner(block *hcl.Block, cfg *PackerConfig) (*ProvisionerBlock, hcl.Diagnostics) {
 	var b struct {
-		Name        string   `hcl:"name,optional"`
-		PauseBefore string   `hcl:"pause_before,optional"`
-		MaxRetries  int      `hcl:"max_retries,optional"`
-		Timeout     string   `hcl:"timeout,optional"`
-		Only        []string `hcl:"only,optional"`
-		Except      []string `hcl:"except,optional"`
-		Rest        hcl.Body `hcl:",remain"`
+		Name           string   `hcl:"name,optional"`
+		PauseBefore    string   `hcl:"pause_before,optional"`
+		MaxRetries     int      `hcl:"max_retries,optional"`
+		Timeout        string   `hcl:"timeout,optional"`
+		RemoteUser     string   `hcl:"remote_user,optional"`
+		RemotePassword string   `hcl:"remote_password,optional"`
+		Marker         string   `hcl:"idempotency_marker,optional"`
+		Only           []string `hcl:"only,optional"`
+		Except         []string `hcl:"except,optional"`
+
+		ErrorHandling *errorHandlingBlock `hcl:"error_handling,block"`
+
+		Rest hcl.Body `hcl:",remain"`
 	}
 	diags := gohcl.DecodeBody(block.Body, cfg.EvalContext(nil), &b)
 	if diags.HasErrors() {
@@ -86,11 +104,14 @@ func (p *Parser) decodeProvisioner(block *hcl.Block, cfg *PackerConfig) (*Provis
 	}
 
 	provisioner := &ProvisionerBlock{
-		PType:      block.Labels[0],
-		PName:      b.Name,
-		MaxRetries: b.MaxRetries,
-		OnlyExcept: OnlyExcept{Only: b.Only, Except: b.Except},
-		HCL2Ref:    newHCL2Ref(block, b.Rest),
+		PType:             block.Labels[0],
+		PName:             b.Name,
+		MaxRetries:        b.MaxRetries,
+		RemoteUser:        b.RemoteUser,
+		RemotePassword:    b.RemotePassword,
+		IdempotencyMarker: b.Marker,
+		OnlyExcept:        OnlyExcept{Only: b.Only, Except: b.Except},
+		HCL2Ref:           newHCL2Ref(block, b.Rest),
 	}
 
 	diags = diags.Extend(provisioner.OnlyExcept.Validate())
@@ -120,6 +141,46 @@ func (p *Parser) decodeProvisioner(block *hcl.Block, cfg *PackerConfig) (*Provis
 		provisioner.Timeout = timeout
 	}
 
+	if err := packer.ValidateRemoteUser(b.RemoteUser); err != nil {
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid remote_user",
+			Detail:   err.Error(),
+			Subject:  block.DefRange.Ptr(),
+		})
+	}
+	if b.RemotePassword != "" && b.RemoteUser == "" {
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid remote_password",
+			Detail:   "remote_password requires remote_user",
+			Subject:  block.DefRange.Ptr(),
+		})
+	}
+
+	if eh := b.ErrorHandling; eh != nil {
+		if err := packer.ValidateOnFailure(eh.OnFailure, packer.ProvisionerOnFailureValues); err != nil {
+			return nil, append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid error_handling",
+				Detail:   err.Error(),
+				Subject:  block.DefRange.Ptr(),
+			})
+		}
+		if eh.Retries != 0 && b.MaxRetries != 0 {
+			return nil, append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid error_handling",
+				Detail:   "error_handling.retries and max_retries are mutually exclusive",
+				Subject:  block.DefRange.Ptr(),
+			})
+		}
+		if eh.Retries != 0 {
+			provisioner.MaxRetries = eh.Retries
+		}
+		provisioner.OnFailure = eh.OnFailure
+	}
+
 	if !p.ProvisionersSchemas.Has(provisioner.PType) {
 		diags = append(diags, &hcl.Diagnostic{
 			Summary:  fmt.Sprintf("Unknown "+buildProvisionerLabel+" type %q", provisioner.PType),
@@ -160,5 +221,6 @@ func (cfg *PackerConfig) startProvisioner(source SourceBlock, pb *ProvisionerBlo
 		})
 		return nil, diags
 	}
+	diags = append(diags, deprecationsToDiags("provisioner", pb.PType, provisioner, pb.HCL2Ref.DefRange.Ptr())...)
 	return hclProvisioner, diags
 }
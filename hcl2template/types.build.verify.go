@@ -0,0 +1,84 @@
+package hcl2template
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+)
+
+const (
+	verifyOnFailureAbort    = "abort"
+	verifyOnFailureContinue = "continue"
+)
+
+// VerifyBlock references an HCL 'verify' block of a build, for example:
+//
+//	verify {
+//		on_failure = "continue"
+//		provisioner "inspec" { ... }
+//	}
+//
+// Its provisioners run after the ones of the build, before the machine is
+// shut down, and their failures are reported as test failures rather than as
+// build errors.
+type VerifyBlock struct {
+	// OnFailure is "abort" to fail the build as soon as the verification
+	// failed, or "continue" to let it produce its artifact anyway.
+	OnFailure string
+
+	ProvisionerBlocks []*ProvisionerBlock
+
+	HCL2Ref HCL2Ref
+}
+
+var verifySchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: buildProvisionerLabel, LabelNames: []string{"type"}},
+	},
+}
+
+func (p *Parser) decodeVerify(block *hcl.Block, cfg *PackerConfig) (*VerifyBlock, hcl.Diagnostics) {
+	var b struct {
+		OnFailure string   `hcl:"on_failure,optional"`
+		Rest      hcl.Body `hcl:",remain"`
+	}
+	diags := gohcl.DecodeBody(block.Body, nil, &b)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	verify := &VerifyBlock{
+		OnFailure: b.OnFailure,
+		HCL2Ref:   newHCL2Ref(block, b.Rest),
+	}
+	switch verify.OnFailure {
+	case "":
+		verify.OnFailure = verifyOnFailureAbort
+	case verifyOnFailureAbort, verifyOnFailureContinue:
+	default:
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid " + buildVerifyLabel + " block",
+			Detail: fmt.Sprintf("on_failure must be %q or %q, not %q",
+				verifyOnFailureAbort, verifyOnFailureContinue, verify.OnFailure),
+			Subject: block.DefRange.Ptr(),
+		})
+	}
+
+	content, moreDiags := b.Rest.Content(verifySchema)
+	diags = append(diags, moreDiags...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	for _, block := range content.Blocks {
+		pb, moreDiags := p.decodeProvisioner(block, cfg)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		verify.ProvisionerBlocks = append(verify.ProvisionerBlocks, pb)
+	}
+
+	return verify, diags
+}
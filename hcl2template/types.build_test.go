@@ -3,11 +3,28 @@ package hcl2template
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	. "github.com/hashicorp/packer/hcl2template/internal"
 	"github.com/hashicorp/packer/packer"
 )
 
+var testNotifications = []*packer.Notification{
+	{
+		Type:     "slack",
+		On:       []string{"failure"},
+		URL:      "https://hooks.slack.com/services/T/B/X",
+		Template: "{{ .BuildName }} failed: {{ .Error }}",
+	},
+	{
+		Type:        "smtp",
+		SMTPAddress: "mail.example.com:587",
+		From:        "packer@example.com",
+		To:          []string{"ops@example.com"},
+	},
+}
+
 func TestParse_build(t *testing.T) {
 	defaultParser := getBasicParser()
 
@@ -344,6 +361,409 @@ func TestParse_build(t *testing.T) {
 			},
 			false,
 		},
+		{"error handling",
+			defaultParser,
+			parseTestArgs{"testdata/build/error_handling.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "build"),
+				Sources: map[SourceRef]SourceBlock{
+					refVBIsoUbuntu1204: {Type: "virtualbox-iso", Name: "ubuntu-1204"},
+				},
+				Builds: Builds{
+					&BuildBlock{
+						Sources:   []SourceRef{refVBIsoUbuntu1204},
+						OnFailure: "abort",
+						ProvisionerBlocks: []*ProvisionerBlock{
+							{
+								PType:      "shell",
+								MaxRetries: 3,
+								OnFailure:  "continue",
+							},
+						},
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{
+				&packer.CoreBuild{
+					Type:     "virtualbox-iso.ubuntu-1204",
+					Prepared: true,
+					Builder:  emptyMockBuilder,
+					Provisioners: []packer.CoreBuildProvisioner{
+						{
+							PType: "shell",
+							Provisioner: &packer.ErrorHandledProvisioner{
+								OnFailure: "continue",
+								Provisioner: &packer.RetriedProvisioner{
+									MaxRetries: 3,
+									Provisioner: &HCL2Provisioner{
+										Provisioner: &MockProvisioner{
+											Config: MockConfig{
+												NestedMockConfig: NestedMockConfig{Tags: []MockTag{}},
+												NestedSlice:      []NestedMockConfig{},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					PostProcessors: [][]packer.CoreBuildPostProcessor{},
+				},
+			},
+			false,
+		},
+		{"invalid provisioner error handling",
+			defaultParser,
+			parseTestArgs{"testdata/build/provisioner_error_handling_invalid.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "build"),
+				Sources: map[SourceRef]SourceBlock{
+					refVBIsoUbuntu1204: {Type: "virtualbox-iso", Name: "ubuntu-1204"},
+				},
+			},
+			true, true,
+			nil,
+			false,
+		},
+		{"build timeouts",
+			defaultParser,
+			parseTestArgs{"testdata/build/timeouts.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "build"),
+				Sources: map[SourceRef]SourceBlock{
+					refVBIsoUbuntu1204: {Type: "virtualbox-iso", Name: "ubuntu-1204"},
+				},
+				Builds: Builds{
+					&BuildBlock{
+						Sources:            []SourceRef{refVBIsoUbuntu1204},
+						BuildTimeout:       2 * time.Hour,
+						ProvisionTimeout:   time.Hour,
+						PostProcessTimeout: 30 * time.Minute,
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{
+				&packer.CoreBuild{
+					Type:               "virtualbox-iso.ubuntu-1204",
+					Prepared:           true,
+					Builder:            emptyMockBuilder,
+					Provisioners:       []packer.CoreBuildProvisioner{},
+					PostProcessors:     [][]packer.CoreBuildPostProcessor{},
+					BuildTimeout:       2 * time.Hour,
+					ProvisionTimeout:   time.Hour,
+					PostProcessTimeout: 30 * time.Minute,
+				},
+			},
+			false,
+		},
+		{"invalid build timeout",
+			defaultParser,
+			parseTestArgs{"testdata/build/timeouts_invalid.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "build"),
+				Sources: map[SourceRef]SourceBlock{
+					refVBIsoUbuntu1204: {Type: "virtualbox-iso", Name: "ubuntu-1204"},
+				},
+			},
+			true, true,
+			nil,
+			false,
+		},
+		{"build notifications",
+			defaultParser,
+			parseTestArgs{"testdata/build/notifications.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "build"),
+				Sources: map[SourceRef]SourceBlock{
+					refVBIsoUbuntu1204: {Type: "virtualbox-iso", Name: "ubuntu-1204"},
+				},
+				Builds: Builds{
+					&BuildBlock{
+						Sources:       []SourceRef{refVBIsoUbuntu1204},
+						Notifications: testNotifications,
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{
+				&packer.CoreBuild{
+					Type:           "virtualbox-iso.ubuntu-1204",
+					Prepared:       true,
+					Builder:        emptyMockBuilder,
+					Provisioners:   []packer.CoreBuildProvisioner{},
+					PostProcessors: [][]packer.CoreBuildPostProcessor{},
+					Notifications:  testNotifications,
+				},
+			},
+			false,
+		},
+		{"invalid build notification",
+			defaultParser,
+			parseTestArgs{"testdata/build/notifications_invalid.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "build"),
+				Sources: map[SourceRef]SourceBlock{
+					refVBIsoUbuntu1204: {Type: "virtualbox-iso", Name: "ubuntu-1204"},
+				},
+			},
+			true, true,
+			nil,
+			false,
+		},
+		{"verify stage",
+			defaultParser,
+			parseTestArgs{"testdata/build/verify.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "build"),
+				Sources: map[SourceRef]SourceBlock{
+					refVBIsoUbuntu1204: {Type: "virtualbox-iso", Name: "ubuntu-1204"},
+				},
+				Builds: Builds{
+					&BuildBlock{
+						Sources: []SourceRef{refVBIsoUbuntu1204},
+						ProvisionerBlocks: []*ProvisionerBlock{
+							{
+								PType: "shell",
+							},
+						},
+						Verify: &VerifyBlock{
+							OnFailure: "continue",
+							ProvisionerBlocks: []*ProvisionerBlock{
+								{
+									PType: "file",
+								},
+							},
+						},
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{
+				&packer.CoreBuild{
+					Type:     "virtualbox-iso.ubuntu-1204",
+					Prepared: true,
+					Builder:  emptyMockBuilder,
+					Provisioners: []packer.CoreBuildProvisioner{
+						{
+							PType: "shell",
+							Provisioner: &HCL2Provisioner{
+								Provisioner: &MockProvisioner{
+									Config: MockConfig{
+										NestedMockConfig: NestedMockConfig{Tags: []MockTag{}},
+										NestedSlice:      []NestedMockConfig{},
+									},
+								},
+							},
+						},
+					},
+					Verifiers: []packer.CoreBuildProvisioner{
+						{
+							PType: "file",
+							Provisioner: &HCL2Provisioner{
+								Provisioner: &MockProvisioner{
+									Config: MockConfig{
+										NestedMockConfig: NestedMockConfig{Tags: []MockTag{}},
+										NestedSlice:      []NestedMockConfig{},
+									},
+								},
+							},
+						},
+					},
+					VerifyContinueOnFailure: true,
+					PostProcessors:          [][]packer.CoreBuildPostProcessor{},
+				},
+			},
+			false,
+		},
+		{"invalid verify on_failure",
+			defaultParser,
+			parseTestArgs{"testdata/build/verify_invalid.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "build"),
+				Sources: map[SourceRef]SourceBlock{
+					refVBIsoUbuntu1204: {Type: "virtualbox-iso", Name: "ubuntu-1204"},
+				},
+			},
+			true, true,
+			nil,
+			false,
+		},
+		{"build dependencies",
+			defaultParser,
+			parseTestArgs{"testdata/build/depends_on.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "build"),
+				Sources: map[SourceRef]SourceBlock{
+					refVBIsoUbuntu1204: {Type: "virtualbox-iso", Name: "ubuntu-1204"},
+				},
+				Builds: Builds{
+					&BuildBlock{
+						Name:    "base",
+						Sources: []SourceRef{refVBIsoUbuntu1204},
+					},
+					&BuildBlock{
+						Name:      "app",
+						DependsOn: []string{"base.virtualbox-iso.ubuntu-1204"},
+						Sources:   []SourceRef{refVBIsoUbuntu1204},
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{
+				&packer.CoreBuild{
+					BuildName:      "base",
+					Type:           "virtualbox-iso.ubuntu-1204",
+					Prepared:       true,
+					Builder:        emptyMockBuilder,
+					Provisioners:   []packer.CoreBuildProvisioner{},
+					PostProcessors: [][]packer.CoreBuildPostProcessor{},
+				},
+				&packer.CoreBuild{
+					BuildName:      "app",
+					Type:           "virtualbox-iso.ubuntu-1204",
+					Dependencies:   []string{"base.virtualbox-iso.ubuntu-1204"},
+					Prepared:       true,
+					Builder:        emptyMockBuilder,
+					Provisioners:   []packer.CoreBuildProvisioner{},
+					PostProcessors: [][]packer.CoreBuildPostProcessor{},
+				},
+			},
+			false,
+		},
+		{"cyclic build dependencies",
+			defaultParser,
+			parseTestArgs{"testdata/build/depends_on_cycle.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "build"),
+				Sources: map[SourceRef]SourceBlock{
+					refVBIsoUbuntu1204: {Type: "virtualbox-iso", Name: "ubuntu-1204"},
+				},
+				Builds: Builds{
+					&BuildBlock{
+						Name:      "a",
+						DependsOn: []string{"b.virtualbox-iso.ubuntu-1204"},
+						Sources:   []SourceRef{refVBIsoUbuntu1204},
+					},
+					&BuildBlock{
+						Name:      "b",
+						DependsOn: []string{"a.virtualbox-iso.ubuntu-1204"},
+						Sources:   []SourceRef{refVBIsoUbuntu1204},
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{
+				&packer.CoreBuild{
+					BuildName:      "a",
+					Type:           "virtualbox-iso.ubuntu-1204",
+					Dependencies:   []string{"b.virtualbox-iso.ubuntu-1204"},
+					Prepared:       true,
+					Builder:        emptyMockBuilder,
+					Provisioners:   []packer.CoreBuildProvisioner{},
+					PostProcessors: [][]packer.CoreBuildPostProcessor{},
+				},
+				&packer.CoreBuild{
+					BuildName:      "b",
+					Type:           "virtualbox-iso.ubuntu-1204",
+					Dependencies:   []string{"a.virtualbox-iso.ubuntu-1204"},
+					Prepared:       true,
+					Builder:        emptyMockBuilder,
+					Provisioners:   []packer.CoreBuildProvisioner{},
+					PostProcessors: [][]packer.CoreBuildPostProcessor{},
+				},
+			},
+			true,
+		},
 	}
 	testParse(t, tests)
 }
+
+func TestGetBuilds_filters(t *testing.T) {
+	tests := []struct {
+		name   string
+		only   []string
+		except []string
+		want   []string
+	}{
+		{"no filter", nil, nil,
+			[]string{"linux.virtualbox-iso.ubuntu-1204", "linux.amazon-ebs.ubuntu-1604", "windows.amazon-ebs.ubuntu-1604"}},
+		{"name", []string{"linux.*"}, nil,
+			[]string{"linux.virtualbox-iso.ubuntu-1204", "linux.amazon-ebs.ubuntu-1604"}},
+		{"build tag", []string{"tag:windows"}, nil,
+			[]string{"windows.amazon-ebs.ubuntu-1604"}},
+		{"source tag", nil, []string{"tag:cloud"},
+			[]string{"linux.virtualbox-iso.ubuntu-1204"}},
+		{"tag glob", []string{"tag:win*", "tag:lin*"}, nil,
+			[]string{"linux.virtualbox-iso.ubuntu-1204", "linux.amazon-ebs.ubuntu-1604", "windows.amazon-ebs.ubuntu-1604"}},
+		{"expression", []string{`${contains(tags, "ci") && source.type == "amazon-ebs"}`}, []string{"tag:windows"},
+			[]string{"linux.amazon-ebs.ubuntu-1604"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, diags := getBasicParser().Parse("testdata/build/tags.pkr.hcl", nil, nil)
+			diags = append(diags, cfg.Initialize()...)
+			if diags.HasErrors() {
+				t.Fatalf("Parse: %s", diags)
+			}
+			builds, diags := cfg.GetBuilds(packer.GetBuildsOptions{Only: tt.only, Except: tt.except})
+			if diags.HasErrors() {
+				t.Fatalf("GetBuilds: %s", diags)
+			}
+			var got []string
+			for _, b := range builds {
+				got = append(got, b.Name())
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("unexpected builds: %s", diff)
+			}
+		})
+	}
+}
+
+func TestGetBuilds_invalidFilters(t *testing.T) {
+	for _, only := range []string{"foo[]bar", "tag:foo[]bar", "${tags ==}", `${"linux"}`} {
+		t.Run(only, func(t *testing.T) {
+			cfg, diags := getBasicParser().Parse("testdata/build/tags.pkr.hcl", nil, nil)
+			diags = append(diags, cfg.Initialize()...)
+			if diags.HasErrors() {
+				t.Fatalf("Parse: %s", diags)
+			}
+			_, diags = cfg.GetBuilds(packer.GetBuildsOptions{Only: []string{only}})
+			if !diags.HasErrors() {
+				t.Fatal("should error")
+			}
+		})
+	}
+}
+
+func TestGetBuilds_buildInfo(t *testing.T) {
+	cfg, diags := getBasicParser().Parse("testdata/build/build_info.pkr.hcl", nil, nil)
+	diags = append(diags, cfg.Initialize()...)
+	if diags.HasErrors() {
+		t.Fatalf("Parse: %s", diags)
+	}
+	builds, diags := cfg.GetBuilds(packer.GetBuildsOptions{})
+	if diags.HasErrors() {
+		t.Fatalf("GetBuilds: %s", diags)
+	}
+
+	build := builds[0].(*packer.CoreBuild)
+	info := build.Info()
+	if info == nil {
+		t.Fatal("the build should have a build info")
+	}
+	state := "clean"
+	if info.Git.Dirty {
+		state = "dirty"
+	}
+	config := build.Builder.(*MockBuilder).Config
+	if config.String != info.UUID+"-"+state {
+		t.Fatalf("unexpected string: %s", config.String)
+	}
+	if config.NotSquashed != info.Dir+"/out" {
+		t.Fatalf("unexpected not_squashed: %s", config.NotSquashed)
+	}
+}
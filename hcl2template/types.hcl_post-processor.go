@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -25,6 +26,13 @@ func (p *HCL2PostProcessor) ConfigSpec() hcldec.ObjectSpec {
 	return p.PostProcessor.ConfigSpec()
 }
 
+func (p *HCL2PostProcessor) Deprecations() []config.Deprecation {
+	if r, ok := p.PostProcessor.(packer.DeprecationReporter); ok {
+		return r.Deprecations()
+	}
+	return nil
+}
+
 func (p *HCL2PostProcessor) HCL2Prepare(buildVars map[string]interface{}) error {
 	var diags hcl.Diagnostics
 	ectx := p.evalContext
@@ -64,10 +72,8 @@ func (p *HCL2PostProcessor) Configure(args ...interface{}) error {
 
 func (p *HCL2PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, bool, error) {
 	generatedData := make(map[string]interface{})
-	if artifactStateData, ok := artifact.State("generated_data").(map[interface{}]interface{}); ok {
-		for k, v := range artifactStateData {
-			generatedData[k.(string)] = v
-		}
+	if artifactStateData := artifact.State("generated_data"); artifactStateData != nil {
+		generatedData = packer.CastDataToMap(artifactStateData)
 	}
 
 	err := p.HCL2Prepare(generatedData)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -25,6 +26,13 @@ func (p *HCL2Provisioner) ConfigSpec() hcldec.ObjectSpec {
 	return p.Provisioner.ConfigSpec()
 }
 
+func (p *HCL2Provisioner) Deprecations() []config.Deprecation {
+	if r, ok := p.Provisioner.(packer.DeprecationReporter); ok {
+		return r.Deprecations()
+	}
+	return nil
+}
+
 func (p *HCL2Provisioner) HCL2Prepare(buildVars map[string]interface{}) error {
 	var diags hcl.Diagnostics
 	ectx := p.evalContext
@@ -0,0 +1,143 @@
+package hcl2template
+
+import (
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const importLabel = "import"
+
+var importSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: importLabel},
+	},
+}
+
+var importBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "path", Required: true},
+	},
+}
+
+// parseImports parses the config files imported by the import blocks of
+// files, then the files they import in turn, breadth first. Every file is
+// only parsed once, so import cycles are fine. It returns the imported files
+// in the order they were found, and how many imports away from files each of
+// them is, by file name.
+func (p *Parser) parseImports(files []*hcl.File) ([]*hcl.File, map[string]int, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	var imported []*hcl.File
+	depths := map[string]int{}
+
+	loaded := map[string]bool{}
+	for _, f := range files {
+		loaded[absFilename(f)] = true
+	}
+
+	for depth := 1; len(files) > 0; depth++ {
+		var next []*hcl.File
+		for _, f := range files {
+			paths, moreDiags := decodeImportPaths(f)
+			diags = append(diags, moreDiags...)
+			for _, path := range paths {
+				hclFiles, jsonFiles, moreDiags := GetHCL2Files(path, hcl2FileExt, hcl2JsonFileExt)
+				diags = append(diags, moreDiags...)
+				if len(hclFiles)+len(jsonFiles) == 0 && !moreDiags.HasErrors() {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Could not find any config file to import in " + path,
+						Detail: "An imported config file must be suffixed with " +
+							"`.pkr.hcl` or `.pkr.json`. A folder can be referenced.",
+					})
+				}
+				for _, filename := range append(hclFiles, jsonFiles...) {
+					abs, err := filepath.Abs(filename)
+					if err != nil {
+						abs = filename
+					}
+					if loaded[abs] {
+						continue
+					}
+					loaded[abs] = true
+
+					var file *hcl.File
+					if filepath.Ext(filename) == ".json" {
+						file, moreDiags = p.ParseJSONFile(filename)
+					} else {
+						file, moreDiags = p.ParseHCLFile(filename)
+					}
+					diags = append(diags, moreDiags...)
+					if moreDiags.HasErrors() {
+						continue
+					}
+					depths[filename] = depth
+					imported = append(imported, file)
+					next = append(next, file)
+				}
+			}
+		}
+		files = next
+	}
+
+	return imported, depths, diags
+}
+
+// decodeImportPaths returns the paths of the import blocks of f, relative to
+// the directory of f.
+func decodeImportPaths(f *hcl.File) ([]string, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	var paths []string
+
+	content, _, moreDiags := f.Body.PartialContent(importSchema)
+	diags = append(diags, moreDiags...)
+
+	for _, block := range content.Blocks {
+		attrs, moreDiags := block.Body.Content(importBlockSchema)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		attr := attrs.Attributes["path"]
+
+		// Imports are loaded before anything else, so the path can't
+		// reference variables.
+		value, moreDiags := attr.Expr.Value(nil)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		if value.IsNull() || !value.Type().Equals(cty.String) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid import path",
+				Detail:   "The path of an import must be a string.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			continue
+		}
+
+		path := value.AsString()
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(attr.Range.Filename), path)
+		}
+		paths = append(paths, path)
+	}
+	return paths, diags
+}
+
+func absFilename(f *hcl.File) string {
+	filename := f.Body.MissingItemRange().Filename
+	if abs, err := filepath.Abs(filename); err == nil {
+		return abs
+	}
+	return filename
+}
+
+// shadowed tells whether the definition at rng is overridden by the existing
+// definition of the same name at existing, because the file of existing
+// imports the file of rng, directly or not.
+func (cfg *PackerConfig) shadowed(existing, rng hcl.Range) bool {
+	return cfg.importDepths[existing.Filename] < cfg.importDepths[rng.Filename]
+}
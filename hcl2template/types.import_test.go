@@ -0,0 +1,108 @@
+package hcl2template
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestParse_import(t *testing.T) {
+	defaultParser := getBasicParser()
+
+	tests := []parseTest{
+		{"imported directory",
+			defaultParser,
+			parseTestArgs{"testdata/import/basic.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "import"),
+				Sources: map[SourceRef]SourceBlock{
+					{
+						Type: "virtualbox-iso",
+						Name: "ubuntu-1204",
+					}: {
+						Type: "virtualbox-iso",
+						Name: "ubuntu-1204",
+					},
+					{
+						Type: "amazon-ebs",
+						Name: "shared",
+					}: {
+						Type: "amazon-ebs",
+						Name: "shared",
+					},
+				},
+				InputVariables: Variables{
+					"image_name": &Variable{
+						Name:         "image_name",
+						DefaultValue: cty.StringVal("basic"),
+					},
+					"region": &Variable{
+						Name:         "region",
+						DefaultValue: cty.StringVal("us-east-1"),
+					},
+				},
+				LocalVariables: Variables{
+					"owner": &Variable{
+						Name:         "owner",
+						DefaultValue: cty.StringVal("basic"),
+					},
+					"team": &Variable{
+						Name:         "team",
+						DefaultValue: cty.StringVal("images"),
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{},
+			false,
+		},
+		{"conflicting imports",
+			defaultParser,
+			parseTestArgs{"testdata/import/conflict.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "import"),
+				Sources: map[SourceRef]SourceBlock{
+					{
+						Type: "amazon-ebs",
+						Name: "shared",
+					}: {
+						Type: "amazon-ebs",
+						Name: "shared",
+					},
+				},
+			},
+			true, true,
+			nil,
+			false,
+		},
+		{"inexistent import",
+			defaultParser,
+			parseTestArgs{"testdata/import/missing.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "import"),
+			},
+			true, true,
+			nil,
+			false,
+		},
+		{"variable import path",
+			defaultParser,
+			parseTestArgs{"testdata/import/variable_path.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "import"),
+				InputVariables: Variables{
+					"shared": &Variable{
+						Name:         "shared",
+						DefaultValue: cty.StringVal("shared"),
+					},
+				},
+			},
+			true, true,
+			nil,
+			false,
+		},
+	}
+	testParse(t, tests)
+}
@@ -0,0 +1,75 @@
+package hcl2template
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	hcl2shim "github.com/hashicorp/packer/hcl2template/shim"
+	"github.com/hashicorp/packer/packer"
+)
+
+// LintConfig returns the sources, provisioners and post-processors of the
+// config for the lint rules to check. The options that can't be evaluated
+// before the builds run, like the ones using the build variables, are
+// returned without a value.
+func (cfg *PackerConfig) LintConfig() ([]*packer.LintBlock, hcl.Diagnostics) {
+	ectx := cfg.EvalContext(nil)
+
+	refs := make([]SourceRef, 0, len(cfg.Sources))
+	for ref := range cfg.Sources {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].String() < refs[j].String() })
+
+	var blocks []*packer.LintBlock
+	for _, ref := range refs {
+		src := cfg.Sources[ref]
+		blocks = append(blocks, lintBlock(packer.LintBuilder, src.Type, "source."+ref.String(),
+			src.block.DefRange, src.body, ectx))
+	}
+	for _, build := range cfg.Builds {
+		provisioners := build.ProvisionerBlocks
+		if build.Verify != nil {
+			provisioners = append(provisioners[:len(provisioners):len(provisioners)], build.Verify.ProvisionerBlocks...)
+		}
+		for _, pb := range provisioners {
+			blocks = append(blocks, lintBlock(packer.LintProvisioner, pb.PType, pb.PName,
+				pb.DefRange, pb.Rest, ectx))
+		}
+		for _, ppbs := range build.PostProcessorsLists {
+			for _, ppb := range ppbs {
+				blocks = append(blocks, lintBlock(packer.LintPostProcessor, ppb.PType, ppb.PName,
+					ppb.DefRange, ppb.Rest, ectx))
+			}
+		}
+	}
+	return blocks, nil
+}
+
+func lintBlock(kind, typ, name string, rng hcl.Range, body hcl.Body, ectx *hcl.EvalContext) *packer.LintBlock {
+	b := &packer.LintBlock{
+		Kind:         kind,
+		Type:         typ,
+		Name:         name,
+		Options:      map[string]interface{}{},
+		Range:        rng,
+		OptionRanges: map[string]hcl.Range{},
+	}
+	if body == nil {
+		return b
+	}
+
+	// Nested blocks are not linted, the attributes found next to them are
+	// returned anyway.
+	attrs, _ := body.JustAttributes()
+	for name, attr := range attrs {
+		b.OptionRanges[name] = attr.Range
+		b.Options[name] = nil
+		v, diags := attr.Expr.Value(ectx)
+		if diags.HasErrors() || !v.IsWhollyKnown() {
+			continue
+		}
+		b.Options[name] = hcl2shim.ConfigValueFromHCL2(v)
+	}
+	return b
+}
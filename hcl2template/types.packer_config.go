@@ -2,13 +2,17 @@ package hcl2template
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/gobwas/glob"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	pkrfunction "github.com/hashicorp/packer/hcl2template/function"
 	"github.com/hashicorp/packer/packer"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
 )
 
 // PackerConfig represents a loaded Packer HCL config. It will contain
@@ -19,6 +23,10 @@ type PackerConfig struct {
 	// directory Packer was called from
 	Cwd string
 
+	// RequiredPlugins are the plugins of the required_plugins blocks, by
+	// name.
+	RequiredPlugins map[string]*RequiredPlugin
+
 	// Available Source blocks
 	Sources map[SourceRef]SourceBlock
 
@@ -36,6 +44,9 @@ type PackerConfig struct {
 	// Builds is the list of Build blocks defined in the config files.
 	Builds Builds
 
+	// Semaphores are the semaphore blocks, by name.
+	Semaphores map[string]*SemaphoreBlock
+
 	builderSchemas packer.BuilderStore
 
 	provisionersSchemas packer.ProvisionerStore
@@ -47,6 +58,10 @@ type PackerConfig struct {
 
 	parser *Parser
 	files  []*hcl.File
+
+	// importDepths tells how many imports away from the config files each
+	// imported file is, by file name.
+	importDepths map[string]int
 }
 
 type ValidationOptions struct {
@@ -59,6 +74,10 @@ const (
 	pathVariablesAccessor  = "path"
 	sourcesAccessor        = "source"
 	buildAccessor          = "build"
+	outputsAccessor        = "outputs"
+	artifactAccessor       = "artifact"
+	artifactsAccessor      = "artifacts"
+	packerAccessor         = "packer"
 )
 
 // EvalContext returns the *hcl.EvalContext that will be passed to an hcl
@@ -89,6 +108,139 @@ func (cfg *PackerConfig) EvalContext(variables map[string]cty.Value) *hcl.EvalCo
 	return ectx
 }
 
+// outputsValue returns the value of the outputs variable: an object with an
+// object of outputs per build.
+func outputsValue(outputs packer.BuildOutputs) cty.Value {
+	builds := map[string]cty.Value{}
+	for build, values := range outputs {
+		vals := map[string]cty.Value{}
+		for k, v := range values {
+			vals[k] = cty.StringVal(v)
+		}
+		builds[build] = cty.ObjectVal(vals)
+	}
+	return cty.ObjectVal(builds)
+}
+
+// artifactValue returns the value of an artifact for the expressions of
+// outputs.
+func artifactValue(a packer.ArtifactInfo) cty.Value {
+	files := cty.ListValEmpty(cty.String)
+	if len(a.Files) > 0 {
+		vals := make([]cty.Value, len(a.Files))
+		for i, f := range a.Files {
+			vals[i] = cty.StringVal(f)
+		}
+		files = cty.ListVal(vals)
+	}
+	metadata := cty.MapValEmpty(cty.String)
+	if len(a.Metadata) > 0 {
+		vals := make(map[string]cty.Value, len(a.Metadata))
+		for k, v := range a.Metadata {
+			vals[k] = cty.StringVal(v)
+		}
+		metadata = cty.MapVal(vals)
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"id":         cty.StringVal(a.ID),
+		"builder_id": cty.StringVal(a.BuilderID),
+		"string":     cty.StringVal(a.String),
+		"files":      files,
+		"metadata":   metadata,
+	})
+}
+
+// buildInfoValue returns the value of the packer variable: the info of the
+// current build.
+func buildInfoValue(info *packer.BuildInfo) cty.Value {
+	vals := map[string]cty.Value{}
+	for k, v := range info.Map() {
+		vals[k] = cty.StringVal(v)
+	}
+	vals[packer.BuildInfoGitDirtyKey] = cty.BoolVal(info.Git.Dirty)
+	return cty.ObjectVal(vals)
+}
+
+// evaluateOutputs evaluates the named outputs of a build of src that produced
+// the artifacts described by data.
+func (cfg *PackerConfig) evaluateOutputs(outputs []*OutputBlock, src SourceBlock, info *packer.BuildInfo, data *packer.OutputsData) (map[string]string, error) {
+	artifacts := make([]cty.Value, len(data.Artifacts))
+	for i, a := range data.Artifacts {
+		artifacts[i] = artifactValue(a)
+	}
+	ectx := cfg.EvalContext(map[string]cty.Value{
+		sourcesAccessor:   cty.ObjectVal(src.ctyValues()),
+		packerAccessor:    buildInfoValue(info),
+		artifactAccessor:  artifactValue(data.ArtifactInfo),
+		artifactsAccessor: cty.TupleVal(artifacts),
+	})
+
+	res := make(map[string]string, len(outputs))
+	var diags hcl.Diagnostics
+	for _, o := range outputs {
+		v, moreDiags := o.Value.Value(ectx)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		v, err := convert.Convert(v, cty.String)
+		if err == nil && (v.IsNull() || !v.IsKnown()) {
+			err = fmt.Errorf("the value is not known")
+		}
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Invalid value for output %q", o.Name),
+				Detail:   err.Error(),
+				Subject:  o.Value.Range().Ptr(),
+			})
+			continue
+		}
+		res[o.Name] = v.AsString()
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return res, nil
+}
+
+// evaluateLocks evaluates the names locked by a build of src.
+func (cfg *PackerConfig) evaluateLocks(expr hcl.Expression, src SourceBlock) ([]string, hcl.Diagnostics) {
+	if expr == nil {
+		return nil, nil
+	}
+	v, diags := expr.Value(cfg.EvalContext(map[string]cty.Value{
+		sourcesAccessor: cty.ObjectVal(src.ctyValues()),
+	}))
+	if diags.HasErrors() || v.IsNull() {
+		return nil, diags
+	}
+	v, err := convert.Convert(v, cty.List(cty.String))
+	if err == nil && !v.IsWhollyKnown() {
+		err = fmt.Errorf("the value is not known")
+	}
+	var locks []string
+	if err == nil {
+		for it := v.ElementIterator(); it.Next(); {
+			_, l := it.Element()
+			if l.IsNull() {
+				err = fmt.Errorf("lock names can't be null")
+				break
+			}
+			locks = append(locks, l.AsString())
+		}
+	}
+	if err != nil {
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid locks",
+			Detail:   err.Error(),
+			Subject:  expr.Range().Ptr(),
+		})
+	}
+	return locks, diags
+}
+
 // decodeInputVariables looks in the found blocks for 'variables' and
 // 'variable' blocks. It should be called firsthand so that other blocks can
 // use the variables.
@@ -98,16 +250,29 @@ func (c *PackerConfig) decodeInputVariables(f *hcl.File) hcl.Diagnostics {
 	content, moreDiags := f.Body.Content(configSchema)
 	diags = append(diags, moreDiags...)
 
+	// The defaults of variables can only read the environment.
+	ectx := &hcl.EvalContext{
+		Functions: map[string]function.Function{
+			"env": pkrfunction.EnvFunc,
+		},
+	}
+
 	for _, block := range content.Blocks {
 		switch block.Type {
 		case variableLabel:
-			moreDiags := c.InputVariables.decodeVariableBlock(block, nil)
+			if v, found := c.InputVariables[block.Labels[0]]; found && c.shadowed(v.Range, block.DefRange) {
+				continue
+			}
+			moreDiags := c.InputVariables.decodeVariableBlock(block, ectx)
 			diags = append(diags, moreDiags...)
 		case variablesLabel:
 			attrs, moreDiags := block.Body.JustAttributes()
 			diags = append(diags, moreDiags...)
 			for key, attr := range attrs {
-				moreDiags = c.InputVariables.decodeVariable(key, attr, nil)
+				if v, found := c.InputVariables[key]; found && c.shadowed(v.Range, attr.Range) {
+					continue
+				}
+				moreDiags = c.InputVariables.decodeVariable(key, attr, ectx)
 				diags = append(diags, moreDiags...)
 			}
 		}
@@ -131,6 +296,9 @@ func (c *PackerConfig) parseLocalVariables(f *hcl.File) ([]*LocalBlock, hcl.Diag
 			attrs, moreDiags := block.Body.JustAttributes()
 			diags = append(diags, moreDiags...)
 			for name, attr := range attrs {
+				if l := c.localBlock(name); l != nil && c.shadowed(l.Expr.Range(), attr.Expr.Range()) {
+					continue
+				}
 				if _, found := c.LocalVariables[name]; found {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
@@ -152,6 +320,16 @@ func (c *PackerConfig) parseLocalVariables(f *hcl.File) ([]*LocalBlock, hcl.Diag
 	return locals, diags
 }
 
+// localBlock returns the parsed local named name, nil if there is none.
+func (c *PackerConfig) localBlock(name string) *LocalBlock {
+	for _, l := range c.LocalBlocks {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
 func (c *PackerConfig) evaluateLocalVariables(locals []*LocalBlock) hcl.Diagnostics {
 	var diags hcl.Diagnostics
 
@@ -224,6 +402,15 @@ func (cfg *PackerConfig) getCoreBuildProvisioners(source SourceBlock, blocks []*
 			continue
 		}
 
+		// If we're running as another user, the commands of the provisioner
+		// are run and its files uploaded as that user.
+		if pb.RemoteUser != "" {
+			provisioner = &packer.UserProvisioner{
+				RemoteUser:     pb.RemoteUser,
+				RemotePassword: pb.RemotePassword,
+				Provisioner:    provisioner,
+			}
+		}
 		// If we're pausing, we wrap the provisioner in a special pauser.
 		if pb.PauseBefore != 0 {
 			provisioner = &packer.PausedProvisioner{
@@ -242,6 +429,20 @@ func (cfg *PackerConfig) getCoreBuildProvisioners(source SourceBlock, blocks []*
 				Provisioner: provisioner,
 			}
 		}
+		// If the provisioner has an idempotency marker, it is skipped when
+		// the marker exists and the marker is recorded once it succeeded.
+		if pb.IdempotencyMarker != "" {
+			provisioner = &packer.MarkedProvisioner{
+				Marker:      pb.IdempotencyMarker,
+				Provisioner: provisioner,
+			}
+		}
+		if pb.OnFailure != "" {
+			provisioner = &packer.ErrorHandledProvisioner{
+				OnFailure:   pb.OnFailure,
+				Provisioner: provisioner,
+			}
+		}
 
 		res = append(res, packer.CoreBuildProvisioner{
 			PType:       pb.PType,
@@ -307,6 +508,16 @@ func (cfg *PackerConfig) GetBuilds(opts packer.GetBuildsOptions) ([]packer.Build
 	res := []packer.Build{}
 	var diags hcl.Diagnostics
 
+	onlyFilters, moreDiags := parseBuildFilters(opts.Only, "-only")
+	diags = append(diags, moreDiags...)
+	exceptFilters, moreDiags := parseBuildFilters(opts.Except, "-except")
+	diags = append(diags, moreDiags...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	cfg.only = nameGlobs(onlyFilters)
+	cfg.except = nameGlobs(exceptFilters)
+
 	for _, build := range cfg.Builds {
 		for _, from := range build.Sources {
 			src, found := cfg.Sources[from.Ref()]
@@ -329,79 +540,142 @@ func (cfg *PackerConfig) GetBuilds(opts packer.GetBuildsOptions) ([]packer.Build
 
 			// Apply the -only and -except command-line options to exclude matching builds.
 			buildName := pcb.Name()
+			tags := mergeTags(build.Tags, src.BuildTags)
 			// -only
-			if len(opts.Only) > 0 {
-				onlyGlobs, diags := convertFilterOption(opts.Only, "only")
-				if diags.HasErrors() {
-					return nil, diags
-				}
-				cfg.only = onlyGlobs
-				include := false
-				for _, onlyGlob := range onlyGlobs {
-					if onlyGlob.Match(buildName) {
-						include = true
-						break
-					}
-				}
+			if len(onlyFilters) > 0 {
+				include, moreDiags := cfg.matchBuildFilters(onlyFilters, buildName, src, tags)
+				diags = append(diags, moreDiags...)
 				if !include {
 					continue
 				}
 			}
 
 			// -except
-			if len(opts.Except) > 0 {
-				exceptGlobs, diags := convertFilterOption(opts.Except, "except")
-				if diags.HasErrors() {
-					return nil, diags
-				}
-				cfg.except = exceptGlobs
-				exclude := false
-				for _, exceptGlob := range exceptGlobs {
-					if exceptGlob.Match(buildName) {
-						exclude = true
-						break
-					}
-				}
+			if len(exceptFilters) > 0 {
+				exclude, moreDiags := cfg.matchBuildFilters(exceptFilters, buildName, src, tags)
+				diags = append(diags, moreDiags...)
 				if exclude {
 					continue
 				}
 			}
 
-			builder, moreDiags, generatedVars := cfg.startBuilder(src, cfg.EvalContext(nil), opts)
+			// The -on-error command line option takes precedence over the
+			// error_handling block of the build.
+			buildOpts := opts
+			if buildOpts.OnError == "" {
+				buildOpts.OnError = build.OnFailure
+			}
+
+			pcb.Dependencies = build.DependsOn
+			pcb.BuildTimeout = build.BuildTimeout
+			pcb.ProvisionTimeout = build.ProvisionTimeout
+			pcb.PostProcessTimeout = build.PostProcessTimeout
+			pcb.Notifications = build.Notifications
+
+			build := build
+			locks, moreDiags := cfg.evaluateLocks(build.Locks, src)
 			diags = append(diags, moreDiags...)
 			if moreDiags.HasErrors() {
 				continue
 			}
+			pcb.Locks = locks
+
+			semaphores, moreDiags := cfg.buildSemaphores(buildName, src, tags)
+			diags = append(diags, moreDiags...)
+			pcb.Semaphores = semaphores
+			pcb.Breakpoints = opts.Breakpoints
 
-			// If the builder has provided a list of to-be-generated variables that
-			// should be made accessible to provisioners, pass that list into
-			// the provisioner prepare() so that the provisioner can appropriately
-			// validate user input against what will become available. Otherwise,
-			// only pass the default variables, using the basic placeholder data.
-			unknownBuildValues := map[string]cty.Value{}
-			for _, k := range append(packer.BuilderDataCommonKeys, generatedVars...) {
-				unknownBuildValues[k] = cty.StringVal("<unknown>")
+			info := packer.NewBuildInfo(cfg.Basedir)
+			pcb.SetInfo(info)
+			if opts.Resume != "" {
+				pcb.SetResume(opts.Resume)
 			}
 
-			variables := map[string]cty.Value{
-				sourcesAccessor: cty.ObjectVal(src.ctyValues()),
-				buildAccessor:   cty.ObjectVal(unknownBuildValues),
+			if len(build.Outputs) > 0 {
+				pcb.NamedOutputs = func(data *packer.OutputsData) (map[string]string, error) {
+					return cfg.evaluateOutputs(build.Outputs, src, info, data)
+				}
 			}
 
-			provisioners, moreDiags := cfg.getCoreBuildProvisioners(src, build.ProvisionerBlocks, cfg.EvalContext(variables))
-			diags = append(diags, moreDiags...)
-			if moreDiags.HasErrors() {
+			prepare := func(outputs packer.BuildOutputs) hcl.Diagnostics {
+				var diags hcl.Diagnostics
+				variables := map[string]cty.Value{
+					packerAccessor: buildInfoValue(info),
+				}
+				if outputs != nil {
+					variables[outputsAccessor] = outputsValue(outputs)
+				}
+
+				builder, moreDiags, generatedVars := cfg.startBuilder(src, cfg.EvalContext(variables), buildOpts, info)
+				diags = append(diags, moreDiags...)
+				if moreDiags.HasErrors() {
+					return diags
+				}
+
+				// If the builder has provided a list of to-be-generated variables that
+				// should be made accessible to provisioners, pass that list into
+				// the provisioner prepare() so that the provisioner can appropriately
+				// validate user input against what will become available. Otherwise,
+				// only pass the default variables, using the basic placeholder data.
+				unknownBuildValues := map[string]cty.Value{}
+				for _, k := range append(packer.BuilderDataCommonKeys, generatedVars...) {
+					unknownBuildValues[k] = cty.StringVal("<unknown>")
+				}
+
+				variables[sourcesAccessor] = cty.ObjectVal(src.ctyValues())
+				variables[buildAccessor] = cty.ObjectVal(unknownBuildValues)
+
+				provisioners, moreDiags := cfg.getCoreBuildProvisioners(src, build.ProvisionerBlocks, cfg.EvalContext(variables))
+				diags = append(diags, moreDiags...)
+				if moreDiags.HasErrors() {
+					return diags
+				}
+				if build.Verify != nil {
+					verifiers, moreDiags := cfg.getCoreBuildProvisioners(src, build.Verify.ProvisionerBlocks, cfg.EvalContext(variables))
+					diags = append(diags, moreDiags...)
+					if moreDiags.HasErrors() {
+						return diags
+					}
+					pcb.Verifiers = verifiers
+					pcb.VerifyContinueOnFailure = build.Verify.OnFailure == verifyOnFailureContinue
+					unknownBuildValues[packer.VerificationStatusKey] = cty.StringVal("<unknown>")
+					unknownBuildValues[packer.VerificationFailuresKey] = cty.StringVal("<unknown>")
+					variables[buildAccessor] = cty.ObjectVal(unknownBuildValues)
+				}
+				pps, moreDiags := cfg.getCoreBuildPostProcessors(src, build.PostProcessorsLists, cfg.EvalContext(variables))
+				diags = append(diags, moreDiags...)
+				if moreDiags.HasErrors() {
+					return diags
+				}
+
+				pcb.Builder = builder
+				pcb.Provisioners = provisioners
+				pcb.PostProcessors = pps
+				return diags
+			}
+
+			// A build depending on other builds is started once they ran,
+			// with their outputs.
+			if opts.DeferDependents && len(build.DependsOn) > 0 {
+				pcb.PrepareFunc = func(outputs packer.BuildOutputs) ([]string, error) {
+					if diags := prepare(outputs); diags.HasErrors() {
+						return nil, diags
+					}
+					return nil, nil
+				}
+				res = append(res, pcb)
 				continue
 			}
-			pps, moreDiags := cfg.getCoreBuildPostProcessors(src, build.PostProcessorsLists, cfg.EvalContext(variables))
+
+			var outputs packer.BuildOutputs
+			if len(build.DependsOn) > 0 {
+				outputs = packer.PlaceholderOutputs(build.DependsOn)
+			}
+			moreDiags = prepare(outputs)
 			diags = append(diags, moreDiags...)
 			if moreDiags.HasErrors() {
 				continue
 			}
-
-			pcb.Builder = builder
-			pcb.Provisioners = provisioners
-			pcb.PostProcessors = pps
 			pcb.Prepared = true
 
 			// Prepare just sets the "prepareCalled" flag on CoreBuild, since
@@ -420,6 +694,17 @@ func (cfg *PackerConfig) GetBuilds(opts packer.GetBuildsOptions) ([]packer.Build
 			res = append(res, pcb)
 		}
 	}
+
+	// Dependencies can only be checked when all the builds are there.
+	if len(opts.Only) == 0 && len(opts.Except) == 0 {
+		if _, err := packer.SortBuilds(res); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid build dependencies",
+				Detail:   err.Error(),
+			})
+		}
+	}
 	return res, diags
 }
 
@@ -436,6 +721,11 @@ exists in your config file.
 
 "variables" will dump all available variables and their values.
 
+"sources" will list the sources, and "functions" the available functions.
+
+An expression can span multiple lines, like an object or a heredoc: the
+console waits for its brackets and quotes to be closed before evaluating it.
+
 To exit the console, type "exit" and hit <enter>, or use Control-C.
 
 /!\ It is not possible to use go templating interpolation like "{{timestamp}}"
@@ -452,6 +742,10 @@ func (p *PackerConfig) EvaluateExpression(line string) (out string, exit bool, d
 		return PackerConsoleHelp, false, nil
 	case line == "variables":
 		return p.printVariables(), false, nil
+	case line == "sources":
+		return p.printSources(), false, nil
+	case line == "functions":
+		return p.printFunctions(), false, nil
 	default:
 		return p.handleEval(line)
 	}
@@ -472,6 +766,25 @@ func (p *PackerConfig) printVariables() string {
 	return out.String()
 }
 
+func (p *PackerConfig) printSources() string {
+	refs := make([]string, 0, len(p.Sources))
+	for ref := range p.Sources {
+		refs = append(refs, "source."+ref.String())
+	}
+	sort.Strings(refs)
+	return strings.Join(refs, "\n")
+}
+
+func (p *PackerConfig) printFunctions() string {
+	funcs := Functions(p.Basedir)
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, "\n")
+}
+
 func (p *PackerConfig) printBuilds() string {
 	out := &strings.Builder{}
 	out.WriteString("> builds:\n")
@@ -502,6 +815,16 @@ func (p *PackerConfig) printBuilds() string {
 			}
 			fmt.Fprintf(out, "      %s\n", str)
 		}
+		if build.Verify != nil {
+			fmt.Fprintf(out, "\n    verify (on_failure = %s):\n\n", build.Verify.OnFailure)
+			for _, prov := range build.Verify.ProvisionerBlocks {
+				str := prov.PType
+				if prov.PName != "" {
+					str = strings.Join([]string{prov.PType, prov.PName}, ".")
+				}
+				fmt.Fprintf(out, "      %s\n", str)
+			}
+		}
 		fmt.Fprintf(out, "\n    post-processors:\n")
 		if len(build.PostProcessorsLists) == 0 {
 			fmt.Fprintf(out, "\n      <no post-processor>\n")
@@ -1,8 +1,10 @@
 package hcl2template
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	. "github.com/hashicorp/packer/hcl2template/internal"
 	"github.com/hashicorp/packer/packer"
 	"github.com/zclconf/go-cty/cty"
@@ -288,6 +290,128 @@ func TestParser_complete(t *testing.T) {
 	testParse(t, tests)
 }
 
+func TestPackerConfig_GetBuilds_deferDependents(t *testing.T) {
+	cfg, diags := getBasicParser().Parse("testdata/build/depends_on_outputs.pkr.hcl", nil, nil)
+	diags = append(diags, cfg.Initialize()...)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	builds, diags := cfg.GetBuilds(packer.GetBuildsOptions{DeferDependents: true})
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	if len(builds) != 2 {
+		t.Fatalf("expected 2 builds, got %d", len(builds))
+	}
+
+	app := builds[1].(*packer.CoreBuild)
+	if app.Builder != nil {
+		t.Fatal("the builder of app should not be started before base ran")
+	}
+	app.SetOutputs(packer.BuildOutputs{
+		"base.virtualbox-iso.ubuntu-1204": {"id": "base-image", "builder_id": "mock"},
+	})
+	if _, err := app.Prepare(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := app.Builder.(*MockBuilder).Config.String; got != "base-image" {
+		t.Fatalf("bad string: %q", got)
+	}
+}
+
+func TestPackerConfig_GetBuilds_outputs(t *testing.T) {
+	cfg, diags := getBasicParser().Parse("testdata/build/outputs.pkr.hcl", nil, nil)
+	diags = append(diags, cfg.Initialize()...)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	builds, diags := cfg.GetBuilds(packer.GetBuildsOptions{})
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	outputs, err := packer.Outputs(builds[0], []packer.Artifact{
+		&packer.MockArtifact{IdValue: "first"},
+		&packer.MockArtifact{
+			IdValue:     "last",
+			StateValues: map[string]interface{}{"atlas.artifact.metadata": map[string]string{"region.us-east-1": "ami-1234"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string]string{
+		"id":         "last",
+		"builder_id": "bid",
+		"image":      "ubuntu-1204-last",
+		"ami":        "ami-1234",
+		"count":      "2",
+	}
+	if diff := cmp.Diff(expected, outputs); diff != "" {
+		t.Fatalf("bad outputs: %s", diff)
+	}
+
+	// The metadata of the artifact has no region.
+	if _, err := packer.Outputs(builds[0], []packer.Artifact{&packer.MockArtifact{}}); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestPackerConfig_GetBuilds_locks(t *testing.T) {
+	cfg, diags := getBasicParser().Parse("testdata/build/locks.pkr.hcl", nil, nil)
+	diags = append(diags, cfg.Initialize()...)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	builds, diags := cfg.GetBuilds(packer.GetBuildsOptions{})
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	expected := []string{"image/ubuntu-1204", "output/virtualbox-iso"}
+	if diff := cmp.Diff(expected, builds[0].(*packer.CoreBuild).Locks); diff != "" {
+		t.Fatalf("bad locks: %s", diff)
+	}
+}
+
+func TestPackerConfig_GetBuilds_semaphores(t *testing.T) {
+	cfg, diags := getBasicParser().Parse("testdata/build/semaphores.pkr.hcl", nil, nil)
+	diags = append(diags, cfg.Initialize()...)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	builds, diags := cfg.GetBuilds(packer.GetBuildsOptions{})
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	expected := map[string]map[string]int{
+		"virtualbox-iso.ubuntu-1204": {"virtualbox": 2, "all": 8},
+		"amazon-ebs.ubuntu-1604":     {"all": 8},
+	}
+	got := map[string]map[string]int{}
+	for _, b := range builds {
+		got[b.Name()] = b.(*packer.CoreBuild).Semaphores
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("bad semaphores: %s", diff)
+	}
+
+	cfg, diags = getBasicParser().Parse("testdata/build/semaphore_invalid.pkr.hcl", nil, nil)
+	diags = append(diags, cfg.Initialize()...)
+	if !diags.HasErrors() {
+		t.Fatal("a semaphore without slots should be rejected")
+	}
+}
+
+func TestPackerConfig_outputReserved(t *testing.T) {
+	cfg, diags := getBasicParser().Parse("testdata/build/output_reserved.pkr.hcl", nil, nil)
+	diags = append(diags, cfg.Initialize()...)
+	if !diags.HasErrors() {
+		t.Fatal("should error")
+	}
+}
+
 func TestParser_ValidateFilterOption(t *testing.T) {
 	tests := []struct {
 		pattern     string
@@ -313,3 +437,87 @@ func TestParser_ValidateFilterOption(t *testing.T) {
 func pointerToBool(b bool) *bool {
 	return &b
 }
+
+func TestPackerConfig_LintConfig(t *testing.T) {
+	cfg, diags := getBasicParser().Parse("testdata/build/lint.pkr.hcl", nil, nil)
+	diags = append(diags, cfg.Initialize()...)
+	if diags.HasErrors() {
+		t.Fatalf("err: %s", diags)
+	}
+
+	blocks, diags := cfg.LintConfig()
+	if diags.HasErrors() {
+		t.Fatalf("err: %s", diags)
+	}
+	if len(blocks) != 3 {
+		t.Fatalf("bad: %#v", blocks)
+	}
+
+	source := blocks[0]
+	if source.Kind != packer.LintBuilder || source.Type != "virtualbox-iso" || source.Name != "source.virtualbox-iso.ubuntu-1204" {
+		t.Fatalf("bad source: %#v", source)
+	}
+	expected := map[string]interface{}{
+		"ssh_password": "packer",
+		"boot_wait":    "10m",
+	}
+	if diff := cmp.Diff(expected, source.Options); diff != "" {
+		t.Fatalf("bad options: %s", diff)
+	}
+	if line := source.OptionRange("boot_wait").Start.Line; line != 8 {
+		t.Fatalf("bad boot_wait line: %d", line)
+	}
+
+	// The build variables are only known once the build runs.
+	provisioner := blocks[1]
+	if provisioner.Kind != packer.LintProvisioner || provisioner.Type != "shell" {
+		t.Fatalf("bad provisioner: %#v", provisioner)
+	}
+	if v, ok := provisioner.Options["inline"]; !ok || v != nil {
+		t.Fatalf("bad inline: %#v", provisioner.Options)
+	}
+
+	postProcessor := blocks[2]
+	if postProcessor.Kind != packer.LintPostProcessor || postProcessor.Options["output"] != "manifest.json" {
+		t.Fatalf("bad post-processor: %#v", postProcessor)
+	}
+}
+
+func TestPackerConfig_EvaluateExpression(t *testing.T) {
+	cfg, diags := getBasicParser().Parse("testdata/complete", nil, nil)
+	diags = append(diags, cfg.Initialize()...)
+	if diags.HasErrors() {
+		t.Fatalf("err: %s", diags)
+	}
+
+	tests := []struct {
+		line     string
+		expected string
+		exit     bool
+	}{
+		{"", "", false},
+		{"exit", "", true},
+		{"local.feefoo", "value_image-id-default", false},
+		{`upper("${var.foo}-x")`, "VALUE-X", false},
+		{"{\n  port = var.port\n}", "{\n  \"port\" = 42\n}", false},
+		{"sources", "source.amazon-ebs.ubuntu-1604\nsource.virtualbox-iso.ubuntu-1204", false},
+	}
+	for _, tt := range tests {
+		out, exit, diags := cfg.EvaluateExpression(tt.line)
+		if diags.HasErrors() {
+			t.Fatalf("%q: %s", tt.line, diags)
+		}
+		if out != tt.expected || exit != tt.exit {
+			t.Fatalf("%q: got %q %t, expected %q %t", tt.line, out, exit, tt.expected, tt.exit)
+		}
+	}
+
+	out, _, _ := cfg.EvaluateExpression("functions")
+	if !strings.Contains(out, "\nformatdate\n") {
+		t.Fatalf("formatdate is not in the functions:\n%s", out)
+	}
+
+	if _, _, diags := cfg.EvaluateExpression("var.unknown"); !diags.HasErrors() {
+		t.Fatal("should error")
+	}
+}
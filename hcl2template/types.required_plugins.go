@@ -0,0 +1,244 @@
+package hcl2template
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	plugingetter "github.com/hashicorp/packer/packer/plugin-getter"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	packerLabel          = "packer"
+	requiredPluginsLabel = "required_plugins"
+)
+
+var packerBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: requiredPluginsLabel},
+	},
+}
+
+// RequiredPlugin is a plugin required in a required_plugins block:
+//  packer {
+//    required_plugins {
+//      amazon = {
+//        source  = "github.com/hashicorp/amazon"
+//        version = ">= 1.0.0"
+//      }
+//    }
+//  }
+type RequiredPlugin struct {
+	// Name is the local name of the plugin, amazon above.
+	Name string
+	// Source is the source address of the plugin.
+	Source string
+	// Version is the raw version constraint of the plugin, if any.
+	Version string
+
+	Requirement *plugingetter.Requirement
+
+	DeclRange hcl.Range
+}
+
+// decodeRequiredPlugins looks in the found blocks for 'packer' blocks and
+// decodes their required_plugins blocks. It does not need any evaluation
+// context, so that plugins can be installed before anything else is decoded.
+func (cfg *PackerConfig) decodeRequiredPlugins(f *hcl.File) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	content, moreDiags := f.Body.Content(configSchema)
+	diags = append(diags, moreDiags...)
+
+	for _, block := range content.Blocks {
+		if block.Type != packerLabel {
+			continue
+		}
+		packerContent, moreDiags := block.Body.Content(packerBlockSchema)
+		diags = append(diags, moreDiags...)
+		for _, rpBlock := range packerContent.Blocks {
+			attrs, moreDiags := rpBlock.Body.JustAttributes()
+			diags = append(diags, moreDiags...)
+			for name, attr := range attrs {
+				rp, moreDiags := decodeRequiredPlugin(name, attr)
+				diags = append(diags, moreDiags...)
+				if moreDiags.HasErrors() {
+					continue
+				}
+				if existing, found := cfg.RequiredPlugins[name]; found {
+					if cfg.shadowed(existing.DeclRange, rp.DeclRange) {
+						continue
+					}
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Duplicate required plugin",
+						Detail: fmt.Sprintf("The plugin %q is already required at %s.",
+							name, existing.DeclRange.String()),
+						Subject: attr.NameRange.Ptr(),
+					})
+					continue
+				}
+				if cfg.RequiredPlugins == nil {
+					cfg.RequiredPlugins = map[string]*RequiredPlugin{}
+				}
+				cfg.RequiredPlugins[name] = rp
+			}
+		}
+	}
+	return diags
+}
+
+func decodeRequiredPlugin(name string, attr *hcl.Attribute) (*RequiredPlugin, hcl.Diagnostics) {
+	rp := &RequiredPlugin{Name: name, DeclRange: attr.Range}
+
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	invalid := func(detail string) (*RequiredPlugin, hcl.Diagnostics) {
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid required plugin " + name,
+			Detail:   detail,
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+	}
+	if !value.Type().IsObjectType() || value.IsNull() {
+		return invalid(`A required plugin must be an object like { source = "github.com/hashicorp/amazon", version = ">= 1.0.0" }.`)
+	}
+	for key := range value.Type().AttributeTypes() {
+		if key != "source" && key != "version" {
+			return invalid(fmt.Sprintf("Unexpected attribute %q: only source and version can be set.", key))
+		}
+	}
+	for key, dst := range map[string]*string{"source": &rp.Source, "version": &rp.Version} {
+		if !value.Type().HasAttribute(key) {
+			continue
+		}
+		v := value.GetAttr(key)
+		if v.IsNull() || v.Type() != cty.String {
+			return invalid(fmt.Sprintf("The %s must be a string.", key))
+		}
+		*dst = v.AsString()
+	}
+	if rp.Source == "" {
+		return invalid("The source of the plugin must be set.")
+	}
+
+	id, err := plugingetter.ParseSource(rp.Source)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	rp.Requirement = &plugingetter.Requirement{Accessor: name, Identifier: id}
+	if rp.Version != "" {
+		constraints, err := version.NewConstraint(rp.Version)
+		if err != nil {
+			return invalid(fmt.Sprintf("The version constraint %q is invalid: %s", rp.Version, err))
+		}
+		rp.Requirement.VersionConstraints = constraints
+	}
+	return rp, diags
+}
+
+// PluginRequirements returns the requirements of the required plugins,
+// sorted by name.
+func (cfg *PackerConfig) PluginRequirements() []*plugingetter.Requirement {
+	names := make([]string, 0, len(cfg.RequiredPlugins))
+	for name := range cfg.RequiredPlugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	reqs := make([]*plugingetter.Requirement, 0, len(names))
+	for _, name := range names {
+		reqs = append(reqs, cfg.RequiredPlugins[name].Requirement)
+	}
+	return reqs
+}
+
+// LockFilePath returns the path of the lock file of the configuration.
+func (cfg *PackerConfig) LockFilePath() string {
+	return plugingetter.LockFilePath(cfg.Basedir)
+}
+
+// detectPluginBinaries loads the installed plugins required by the
+// configuration, so that their components can be used. The version locked
+// in the lock file is used when there is one, else the latest installed
+// version matching the constraints.
+func (cfg *PackerConfig) detectPluginBinaries() hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	if len(cfg.RequiredPlugins) == 0 {
+		return nil
+	}
+	pluginConfig := cfg.parser.PluginConfig
+	if pluginConfig == nil {
+		log.Printf("[TRACE] no plugin config, not loading required plugins")
+		return nil
+	}
+
+	lock, moreDiags := plugingetter.ReadLockFile(cfg.LockFilePath())
+	diags = append(diags, moreDiags...)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	opts := plugingetter.ListInstallationsOptions{
+		PluginDirectories: pluginConfig.PluginDirectories,
+	}
+	for _, req := range cfg.PluginRequirements() {
+		rp := cfg.RequiredPlugins[req.Accessor]
+		installs, err := req.ListInstallations(opts)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to list the installations of plugin " + rp.Name,
+				Detail:   err.Error(),
+				Subject:  rp.DeclRange.Ptr(),
+			})
+			continue
+		}
+		install := selectInstallation(installs, lock.Plugins[req.Identifier.String()])
+		if install == nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing plugin " + rp.Name,
+				Detail: fmt.Sprintf("No installed version of %s matches the "+
+					"version constraints %q or the lock file. Run "+
+					"'packer init' to install it.", req.Identifier, rp.Version),
+				Subject: rp.DeclRange.Ptr(),
+			})
+			continue
+		}
+		log.Printf("[INFO] using %s v%s from %s", req.Identifier, install.Version, install.Dir)
+		for _, bin := range install.Binaries {
+			if err := pluginConfig.LoadPlugin(bin); err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Failed to load plugin " + rp.Name,
+					Detail:   err.Error(),
+					Subject:  rp.DeclRange.Ptr(),
+				})
+			}
+		}
+	}
+	return diags
+}
+
+// selectInstallation returns the installation of the locked version, or the
+// latest one when the plugin is not locked.
+func selectInstallation(installs []*plugingetter.Installation, locked *plugingetter.LockedPlugin) *plugingetter.Installation {
+	if locked == nil {
+		if len(installs) == 0 {
+			return nil
+		}
+		return installs[len(installs)-1]
+	}
+	for _, install := range installs {
+		if install.Version.String() == locked.Version {
+			return install
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package hcl2template
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+	plugingetter "github.com/hashicorp/packer/packer/plugin-getter"
+)
+
+func TestParser_requiredPlugins(t *testing.T) {
+	cfg, diags := getBasicParser().Parse("testdata/required_plugins/complete", nil, nil)
+	if diags.HasErrors() {
+		t.Fatalf("Parse: %s", diags)
+	}
+	var got []string
+	for _, req := range cfg.PluginRequirements() {
+		got = append(got, req.Accessor+" "+req.Identifier.String()+" "+req.VersionConstraints.String())
+	}
+	want := []string{
+		"amazon github.com/hashicorp/amazon >= 1.0.0, < 2.0.0",
+		"comment github.com/sylviamoss/comment ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("PluginRequirements: got %q, want %q", got, want)
+	}
+
+	_, diags = getBasicParser().Parse("testdata/required_plugins/invalid", nil, nil)
+	if !diags.HasErrors() {
+		t.Fatal("Parse: expected an error for the invalid source")
+	}
+}
+
+func TestPackerConfig_detectPluginBinaries(t *testing.T) {
+	pluginDir, err := ioutil.TempDir("", "packer-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(pluginDir)
+
+	platform := plugingetter.Platform(runtime.GOOS, runtime.GOARCH)
+	for _, v := range []string{"1.0.0", "1.1.0", "2.0.0"} {
+		dir := filepath.Join(pluginDir, "github.com", "hashicorp", "amazon", v, platform)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "packer-builder-amazon-ebs"), nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var loaded []string
+	parser := getBasicParser()
+	parser.PluginConfig = &packer.PluginConfig{
+		PluginDirectories: []string{pluginDir},
+		LoadPlugin: func(path string) error {
+			loaded = append(loaded, path)
+			return nil
+		},
+	}
+	cfg, diags := parser.Parse("testdata/required_plugins/complete", nil, nil)
+	if diags.HasErrors() {
+		t.Fatalf("Parse: %s", diags)
+	}
+	delete(cfg.RequiredPlugins, "comment")
+	cfg.Basedir = pluginDir // no lock file
+
+	if diags := cfg.detectPluginBinaries(); diags.HasErrors() {
+		t.Fatalf("detectPluginBinaries: %s", diags)
+	}
+	want := []string{filepath.Join(pluginDir, "github.com", "hashicorp", "amazon", "1.1.0", platform, "packer-builder-amazon-ebs")}
+	if !reflect.DeepEqual(loaded, want) {
+		t.Fatalf("loaded %q, want %q", loaded, want)
+	}
+}
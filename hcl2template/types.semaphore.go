@@ -0,0 +1,122 @@
+package hcl2template
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+)
+
+const semaphoreLabel = "semaphore"
+
+var semaphoreSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "limit", Required: true},
+		{Name: "builds", Required: true},
+	},
+}
+
+// SemaphoreBlock is a named semaphore limiting how many of the builds it
+// selects run at once, like:
+//
+//	semaphore "vsphere" {
+//	  limit  = 2
+//	  builds = ["*.vsphere-iso.*", "tag:vmware"]
+//	}
+//
+// The builds are selected with the patterns of the -only option. A build can
+// be selected by several semaphores, it then waits for a slot of each of them.
+type SemaphoreBlock struct {
+	Name string
+	// Limit is the number of builds running at once.
+	Limit int
+	// Builds are the patterns selecting the builds.
+	Builds []string
+
+	filters []buildFilter
+	block   *hcl.Block
+}
+
+func (cfg *PackerConfig) decodeSemaphore(block *hcl.Block) (*SemaphoreBlock, hcl.Diagnostics) {
+	s := &SemaphoreBlock{
+		Name:  block.Labels[0],
+		block: block,
+	}
+
+	content, diags := block.Body.Content(semaphoreSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ectx := cfg.EvalContext(nil)
+	limit := content.Attributes["limit"]
+	diags = append(diags, gohcl.DecodeExpression(limit.Expr, ectx, &s.Limit)...)
+	diags = append(diags, gohcl.DecodeExpression(content.Attributes["builds"].Expr, ectx, &s.Builds)...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if s.Limit < 1 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid semaphore limit",
+			Detail:   "The limit of a semaphore must be at least 1.",
+			Subject:  limit.Expr.Range().Ptr(),
+		})
+	}
+
+	filters, moreDiags := parseBuildFilters(s.Builds, fmt.Sprintf("semaphore %q builds", s.Name))
+	for _, d := range moreDiags {
+		d.Subject = content.Attributes["builds"].Expr.Range().Ptr()
+	}
+	diags = append(diags, moreDiags...)
+	s.filters = filters
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return s, diags
+}
+
+// addSemaphore adds s to the semaphores of the config, unless a semaphore of
+// the same name is already defined.
+func (cfg *PackerConfig) addSemaphore(s *SemaphoreBlock) hcl.Diagnostics {
+	if existing, found := cfg.Semaphores[s.Name]; found {
+		if cfg.shadowed(existing.block.DefRange, s.block.DefRange) {
+			return nil
+		}
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Duplicate " + semaphoreLabel + " block",
+			Detail: fmt.Sprintf("This "+semaphoreLabel+" block has the same "+
+				"name as a previous block declared at %s. Each "+semaphoreLabel+
+				" must have a unique name.", existing.block.DefRange.Ptr()),
+			Subject: s.block.DefRange.Ptr(),
+		}}
+	}
+
+	if cfg.Semaphores == nil {
+		cfg.Semaphores = map[string]*SemaphoreBlock{}
+	}
+	cfg.Semaphores[s.Name] = s
+	return nil
+}
+
+// buildSemaphores returns the limits of the semaphores selecting the build
+// named buildName of src, tagged with tags, by semaphore name.
+func (cfg *PackerConfig) buildSemaphores(buildName string, src SourceBlock, tags []string) (map[string]int, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	var semaphores map[string]int
+	for name, s := range cfg.Semaphores {
+		match, moreDiags := cfg.matchBuildFilters(s.filters, buildName, src, tags)
+		diags = append(diags, moreDiags...)
+		if !match {
+			continue
+		}
+		if semaphores == nil {
+			semaphores = map[string]int{}
+		}
+		semaphores[name] = s.Limit
+	}
+	return semaphores, diags
+}
@@ -10,6 +10,12 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+var sourceSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "build_tags"},
+	},
+}
+
 // SourceBlock references an HCL 'source' block.
 type SourceBlock struct {
 	// Type of source; ex: virtualbox-iso
@@ -17,7 +23,14 @@ type SourceBlock struct {
 	// Given name; if any
 	Name string
 
+	// BuildTags are arbitrary labels of the builds of this source, that the
+	// -only and -except options can select them by.
+	BuildTags []string
+
 	block *hcl.Block
+	// body is the body of block without the options that are not passed to
+	// the builder.
+	body hcl.Body
 
 	// addition will be merged into block to allow user to override builder settings
 	// per build.source block.
@@ -75,6 +88,18 @@ func (p *Parser) decodeSource(block *hcl.Block) (SourceBlock, hcl.Diagnostics) {
 	}
 	var diags hcl.Diagnostics
 
+	// build_tags is not passed to the builder, the tags option of many
+	// builders already tagging the images they create.
+	content, body, moreDiags := block.Body.PartialContent(sourceSchema)
+	diags = append(diags, moreDiags...)
+	source.body = body
+	if attr, found := content.Attributes["build_tags"]; found {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &source.BuildTags)...)
+	}
+	if diags.HasErrors() {
+		return source, diags
+	}
+
 	if !p.BuilderSchemas.Has(source.Type) {
 		diags = append(diags, &hcl.Diagnostic{
 			Summary:  "Unknown " + buildSourceLabel + " type " + source.Type,
@@ -88,7 +113,7 @@ func (p *Parser) decodeSource(block *hcl.Block) (SourceBlock, hcl.Diagnostics) {
 	return source, diags
 }
 
-func (cfg *PackerConfig) startBuilder(source SourceBlock, ectx *hcl.EvalContext, opts packer.GetBuildsOptions) (packer.Builder, hcl.Diagnostics, []string) {
+func (cfg *PackerConfig) startBuilder(source SourceBlock, ectx *hcl.EvalContext, opts packer.GetBuildsOptions, info *packer.BuildInfo) (packer.Builder, hcl.Diagnostics, []string) {
 	var diags hcl.Diagnostics
 
 	builder, err := cfg.builderSchemas.Start(source.Type)
@@ -101,9 +126,9 @@ func (cfg *PackerConfig) startBuilder(source SourceBlock, ectx *hcl.EvalContext,
 		return builder, diags, nil
 	}
 
-	body := source.block.Body
+	body := source.body
 	if source.addition != nil {
-		body = hcl.MergeBodies([]hcl.Body{source.block.Body, source.addition})
+		body = hcl.MergeBodies([]hcl.Body{source.body, source.addition})
 	}
 
 	decoded, moreDiags := decodeHCL2Spec(body, ectx, builder)
@@ -119,12 +144,31 @@ func (cfg *PackerConfig) startBuilder(source SourceBlock, ectx *hcl.EvalContext,
 	// easier to reason about.
 	builderVars := source.builderVariables()
 	builderVars["packer_debug"] = strconv.FormatBool(opts.Debug)
-	builderVars["packer_force"] = strconv.FormatBool(opts.Force)
+	builderVars["packer_force"] = strconv.FormatBool(opts.Force ||
+		opts.OnConflict == packer.ConflictForce || opts.OnConflict == packer.ConflictReplace)
 	builderVars["packer_on_error"] = opts.OnError
+	builderVars["packer_on_conflict"] = opts.OnConflict
+	builderVars["packer_journal"] = info.Journal()
+	builderVars["packer_resume"] = strconv.FormatBool(opts.Resume != "")
+	builderVars["packer_preflight"] = opts.Preflight
+
+	raws := []interface{}{builderVars}
+	if len(opts.Breakpoints) > 0 {
+		raws = append(raws, map[string]interface{}{
+			packer.BreakpointsConfigKey: opts.Breakpoints,
+		})
+	}
+	if len(opts.BillingTags) > 0 {
+		raws = append(raws, map[string]interface{}{
+			packer.BillingTagsConfigKey: opts.BillingTags,
+		})
+	}
+	raws = append(raws, decoded)
 
-	generatedVars, warning, err := builder.Prepare(builderVars, decoded)
+	generatedVars, warning, err := builder.Prepare(raws...)
 	moreDiags = warningErrorsToDiags(source.block, warning, err)
 	diags = append(diags, moreDiags...)
+	diags = append(diags, deprecationsToDiags("builder", source.Type, builder, &source.block.DefRange)...)
 	return builder, diags, generatedVars
 }
 
@@ -2,6 +2,7 @@ package hcl2template
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -16,6 +17,11 @@ import (
 
 func TestParse_variables(t *testing.T) {
 	defaultParser := getBasicParser()
+	os.Setenv("PACKER_TEST_ENV_DEFAULT", "/home/packer")
+	defer os.Unsetenv("PACKER_TEST_ENV_DEFAULT")
+	// The identity of testdata/variables/encrypted.pkrvars.hcl.age.
+	os.Setenv("SOPS_AGE_KEY", "AGE-SECRET-KEY-1GUL6NA7MVYDC260E4EJYNNL7Z8PPACQZLUFG9M35GSCLRMYY869SVYJ9UQ")
+	defer os.Unsetenv("SOPS_AGE_KEY")
 
 	tests := []parseTest{
 		{"basic variables",
@@ -119,6 +125,24 @@ func TestParse_variables(t *testing.T) {
 			false,
 		},
 
+		{"env default",
+			defaultParser,
+			parseTestArgs{"testdata/variables/env_default.pkr.hcl", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "variables"),
+				InputVariables: Variables{
+					"home": &Variable{
+						Name:         "home",
+						DefaultValue: cty.StringVal("/home/packer"),
+						Type:         cty.String,
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{},
+			false,
+		},
+
 		{"unknown key",
 			defaultParser,
 			parseTestArgs{"testdata/variables/unknown_key.pkr.hcl", nil, nil},
@@ -261,6 +285,68 @@ func TestParse_variables(t *testing.T) {
 			false,
 		},
 
+		{"auto var-files are loaded in lexical order",
+			defaultParser,
+			parseTestArgs{"testdata/variables/var-files-precedence", nil, nil},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "variables", "var-files-precedence"),
+				InputVariables: Variables{
+					"foo": &Variable{
+						DefaultValue: cty.StringVal("bar"),
+						Name:         "foo",
+						Type:         cty.String,
+						VarfileValue: cty.StringVal("b"),
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{},
+			false,
+		},
+
+		{"var-files override auto var-files in the given order",
+			defaultParser,
+			parseTestArgs{"testdata/variables/var-files-precedence", nil, []string{
+				"testdata/variables/set-foo-too-wee.hcl",
+				"testdata/variables/var-files-precedence/staging.pkrvars.hcl",
+			}},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "variables", "var-files-precedence"),
+				InputVariables: Variables{
+					"foo": &Variable{
+						DefaultValue: cty.StringVal("bar"),
+						Name:         "foo",
+						Type:         cty.String,
+						VarfileValue: cty.StringVal("staging"),
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{},
+			false,
+		},
+
+		{"age encrypted var-file",
+			defaultParser,
+			parseTestArgs{"testdata/variables/foo-string.variable.pkr.hcl", nil, []string{
+				"testdata/variables/encrypted.pkrvars.hcl.age",
+			}},
+			&PackerConfig{
+				Basedir: filepath.Join("testdata", "variables"),
+				InputVariables: Variables{
+					"foo": &Variable{
+						DefaultValue: cty.StringVal("bar"),
+						Name:         "foo",
+						Type:         cty.String,
+						VarfileValue: cty.StringVal("secret-value"),
+					},
+				},
+			},
+			false, false,
+			[]packer.Build{},
+			false,
+		},
+
 		{"unknown variable from var-file",
 			defaultParser,
 			parseTestArgs{"testdata/variables/empty.pkr.hcl", nil, []string{"testdata/variables/set-foo-too-wee.hcl"}},
@@ -0,0 +1,182 @@
+package upgrade
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// hclsyntaxStart is where the generated configuration starts, to parse it.
+var hclsyntaxStart = hcl.Pos{Line: 1, Column: 1, Byte: 0}
+
+// maxInlineList is the length of the longest list written on one line.
+const maxInlineList = 80
+
+// writeBody writes the options of config as the attributes and blocks of a
+// body. The spec, when known, tells which options are blocks.
+func (c *converter) writeBody(out *bytes.Buffer, config map[string]interface{}, spec hcldec.ObjectSpec, sc scope, where string) {
+	var blocks bytes.Buffer
+	for _, k := range sortedKeys(config) {
+		v := config[k]
+		name, blockSpec, isBlock := specOf(spec, k)
+		if spec == nil {
+			isBlock = looksLikeBlock(v)
+		}
+		if !hclsyntax.ValidIdentifier(name) {
+			c.warn("%s: %q is not a valid HCL2 name, it is commented out", where, k)
+			fmt.Fprintf(out, "# %s = %s\n", name, strings.Replace(c.value(v, sc, where), "\n", " ", -1))
+			continue
+		}
+		if isBlock {
+			if c.writeBlocks(&blocks, name, v, blockSpec, sc, where) {
+				continue
+			}
+		}
+		fmt.Fprintf(out, "%s = %s\n", name, c.value(v, sc, where))
+	}
+	out.Write(blocks.Bytes())
+}
+
+// writeBlocks writes v as blocks called name, and tells whether it could:
+// maps are written as one block and lists of maps as one block per map.
+func (c *converter) writeBlocks(out *bytes.Buffer, name string, v interface{}, spec hcldec.ObjectSpec, sc scope, where string) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		fmt.Fprintf(out, "\n%s {\n", name)
+		c.writeBody(out, v, spec, sc, where)
+		out.WriteString("}\n")
+		return true
+	case []interface{}:
+		for _, e := range v {
+			if _, ok := e.(map[string]interface{}); !ok {
+				return false
+			}
+		}
+		for _, e := range v {
+			fmt.Fprintf(out, "\n%s {\n", name)
+			c.writeBody(out, e.(map[string]interface{}), spec, sc, where)
+			out.WriteString("}\n")
+		}
+		return true
+	}
+	return false
+}
+
+// specOf returns the HCL2 name of the option called key, and whether it is
+// a block, with the spec of its body when known.
+func specOf(spec hcldec.ObjectSpec, key string) (string, hcldec.ObjectSpec, bool) {
+	switch s := spec[key].(type) {
+	case *hcldec.AttrSpec:
+		return s.Name, nil, false
+	case *hcldec.BlockSpec:
+		return s.TypeName, nestedSpec(s.Nested), true
+	case *hcldec.BlockListSpec:
+		return s.TypeName, nestedSpec(s.Nested), true
+	case *hcldec.BlockSetSpec:
+		return s.TypeName, nestedSpec(s.Nested), true
+	case *hcldec.BlockAttrsSpec:
+		// A block of free form attributes, like tags.
+		return s.TypeName, hcldec.ObjectSpec{}, true
+	}
+	return key, nil, false
+}
+
+func nestedSpec(s hcldec.Spec) hcldec.ObjectSpec {
+	if o, ok := s.(hcldec.ObjectSpec); ok {
+		return o
+	}
+	return hcldec.ObjectSpec{}
+}
+
+// looksLikeBlock tells whether v is likely a block when the spec is not
+// known: maps with values that are not all scalars, and lists of maps.
+func looksLikeBlock(v interface{}) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, e := range v {
+			switch e.(type) {
+			case map[string]interface{}, []interface{}:
+				return true
+			}
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return false
+		}
+		for _, e := range v {
+			if _, ok := e.(map[string]interface{}); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// value returns the HCL2 expression of a JSON value.
+func (c *converter) value(v interface{}, sc scope, where string) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		return c.convertString(v, sc, where)
+	case []interface{}:
+		elems := make([]string, len(v))
+		length := 0
+		multiline := false
+		for i, e := range v {
+			elems[i] = c.value(e, sc, where)
+			length += len(elems[i]) + 2
+			if strings.Contains(elems[i], "\n") {
+				multiline = true
+			}
+		}
+		if !multiline && length <= maxInlineList {
+			return "[" + strings.Join(elems, ", ") + "]"
+		}
+		return "[\n" + strings.Join(elems, ",\n") + ",\n]"
+	case []string:
+		elems := make([]interface{}, len(v))
+		for i, e := range v {
+			elems[i] = e
+		}
+		return c.value(elems, sc, where)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, k := range keys {
+			key := k
+			if !hclsyntax.ValidIdentifier(k) || strings.Contains(k, "{{") {
+				key = c.convertString(k, sc, where)
+				if !strings.HasPrefix(key, `"`) {
+					key = "(" + key + ")"
+				}
+			}
+			fmt.Fprintf(&b, "%s = %s\n", key, c.value(v[k], sc, where))
+		}
+		b.WriteString("}")
+		return b.String()
+	}
+	c.warn("%s: the value %v can't be converted", where, v)
+	return "null"
+}
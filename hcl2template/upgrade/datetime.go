@@ -0,0 +1,141 @@
+package upgrade
+
+import (
+	"fmt"
+	"strings"
+)
+
+// goLayoutTokens maps the elements of Go time layouts, as used by the isotime
+// function, to the ones of the HCL2 formatdate function. Longer elements
+// come first so that they are matched first.
+var goLayoutTokens = []struct{ goToken, hclToken string }{
+	{"January", "MMMM"},
+	{"Monday", "EEEE"},
+	{"Z07:00", "Z"},
+	{"-07:00", "ZZZZZ"},
+	{"-0700", "ZZZZ"},
+	{"2006", "YYYY"},
+	{"Jan", "MMM"},
+	{"Mon", "EEE"},
+	{"MST", "ZZZ"},
+	{"01", "MM"},
+	{"02", "DD"},
+	{"03", "HH"},
+	{"04", "mm"},
+	{"05", "ss"},
+	{"06", "YY"},
+	{"15", "hh"},
+	{"PM", "AA"},
+	{"pm", "aa"},
+	{"1", "M"},
+	{"2", "D"},
+	{"3", "H"},
+	{"4", "m"},
+	{"5", "s"},
+}
+
+// strftimeTokens maps the conversions of strftime formats, as used by the
+// strftime function, to the elements of the HCL2 formatdate function.
+var strftimeTokens = map[rune]string{
+	'Y': "YYYY",
+	'y': "YY",
+	'B': "MMMM",
+	'b': "MMM",
+	'h': "MMM",
+	'm': "MM",
+	'd': "DD",
+	'e': "D",
+	'A': "EEEE",
+	'a': "EEE",
+	'H': "hh",
+	'I': "HH",
+	'M': "mm",
+	'S': "ss",
+	'p': "AA",
+	'Z': "ZZZ",
+	'z': "ZZZZ",
+}
+
+// formatdateBuilder builds a formatdate spec, quoting the literal letters.
+type formatdateBuilder struct {
+	strings.Builder
+	literal []rune
+}
+
+func (b *formatdateBuilder) token(t string) {
+	b.flush()
+	b.WriteString(t)
+}
+
+func (b *formatdateBuilder) text(r rune) {
+	b.literal = append(b.literal, r)
+}
+
+// flush writes the pending literal text; letters and quotes have to be
+// quoted not to be read as elements of the spec.
+func (b *formatdateBuilder) flush() {
+	if len(b.literal) == 0 {
+		return
+	}
+	s := string(b.literal)
+	b.literal = nil
+	if !strings.ContainsAny(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ'") {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString("'" + strings.Replace(s, "'", "''", -1) + "'")
+}
+
+func (b *formatdateBuilder) String() string {
+	b.flush()
+	return b.Builder.String()
+}
+
+// formatdateFromGoLayout returns the formatdate spec of a Go time layout.
+func formatdateFromGoLayout(layout string) (string, error) {
+	var b formatdateBuilder
+	rest := layout
+next:
+	for rest != "" {
+		if strings.HasPrefix(rest, ".0") || strings.HasPrefix(rest, ".9") || strings.HasPrefix(rest, "_2") {
+			return "", fmt.Errorf("the %q element of %q has no HCL2 equivalent", rest[:2], layout)
+		}
+		for _, t := range goLayoutTokens {
+			if strings.HasPrefix(rest, t.goToken) {
+				b.token(t.hclToken)
+				rest = rest[len(t.goToken):]
+				continue next
+			}
+		}
+		r := []rune(rest)[0]
+		b.text(r)
+		rest = rest[len(string(r)):]
+	}
+	return b.String(), nil
+}
+
+// formatdateFromStrftime returns the formatdate spec of a strftime format.
+func formatdateFromStrftime(format string) (string, error) {
+	var b formatdateBuilder
+	runes := []rune(format)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' {
+			b.text(runes[i])
+			continue
+		}
+		if i+1 == len(runes) {
+			return "", fmt.Errorf("%q ends with %%", format)
+		}
+		i++
+		if runes[i] == '%' {
+			b.text('%')
+			continue
+		}
+		t, ok := strftimeTokens[runes[i]]
+		if !ok {
+			return "", fmt.Errorf("the %%%c conversion of %q has no HCL2 equivalent", runes[i], format)
+		}
+		b.token(t)
+	}
+	return b.String(), nil
+}
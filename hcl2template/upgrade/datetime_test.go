@@ -0,0 +1,48 @@
+package upgrade
+
+import "testing"
+
+func TestFormatdateFromGoLayout(t *testing.T) {
+	tests := []struct {
+		layout  string
+		want    string
+		wantErr bool
+	}{
+		{"2006-01-02T15:04:05Z07:00", "YYYY-MM-DD'T'hh:mm:ssZ", false},
+		{"Jan 2, 2006 at 3:04pm (MST)", "MMM D, YYYY' at 'H:mmaa (ZZZ)", false},
+		{"Monday 20060102", "EEEE YYYYMMDD", false},
+		{"15:04:05.000", "", true},
+	}
+	for _, tt := range tests {
+		got, err := formatdateFromGoLayout(tt.layout)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("formatdateFromGoLayout(%q): unexpected error %v", tt.layout, err)
+		}
+		if got != tt.want {
+			t.Errorf("formatdateFromGoLayout(%q) = %q, want %q", tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestFormatdateFromStrftime(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{"%Y-%m-%d %H:%M:%S", "YYYY-MM-DD hh:mm:ss", false},
+		{"%d %B %Y, it's %I%p", "DD MMMM YYYY', it''s 'HHAA", false},
+		{"100%% é %y", "100% é YY", false},
+		{"%j", "", true},
+		{"%", "", true},
+	}
+	for _, tt := range tests {
+		got, err := formatdateFromStrftime(tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("formatdateFromStrftime(%q): unexpected error %v", tt.format, err)
+		}
+		if got != tt.want {
+			t.Errorf("formatdateFromStrftime(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
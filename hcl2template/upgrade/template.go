@@ -0,0 +1,458 @@
+package upgrade
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template/parse"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// scope is where a string of a JSON template is interpolated; it tells
+// which functions and variables are available.
+type scope int
+
+const (
+	// scopeVariable is the default value of a user variable.
+	scopeVariable scope = iota
+	// scopeSource is the configuration of a builder, or one of its locks.
+	scopeSource
+	// scopeProvisioner is the configuration of a provisioner or of a
+	// post-processor.
+	scopeProvisioner
+	// scopeOutput is a named output of a build.
+	scopeOutput
+)
+
+// artifactFields are the fields of the data of named outputs, and of its
+// artifacts, with their names in HCL2.
+var artifactFields = map[string]string{
+	"ID":        "id",
+	"BuilderID": "builder_id",
+	"String":    "string",
+	"Files":     "files",
+	"Metadata":  "metadata",
+}
+
+// hclString returns s as a quoted HCL2 string, escaping the template
+// sequences.
+func hclString(s string) string {
+	return `"` + escapeTemplate(s) + `"`
+}
+
+// escapeTemplate escapes s to be used as the literal text of a quoted HCL2
+// template.
+func escapeTemplate(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '$', '%':
+			b.WriteRune(r)
+			if i+1 < len(runes) && runes[i+1] == '{' {
+				b.WriteRune(r)
+			}
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(&b, `\u%04x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// templatePart is either literal text or an HCL2 expression.
+type templatePart struct {
+	text string
+	expr string
+}
+
+// convertString returns the HCL2 expression of a string of a JSON template,
+// converting its interpolations. The interpolations that can't be converted
+// are kept as they are and reported, where tells where they are.
+func (c *converter) convertString(s string, sc scope, where string) string {
+	parts, ok := c.templateParts(s, sc, where)
+	if !ok {
+		return hclString(s)
+	}
+	if len(parts) == 1 && parts[0].expr != "" {
+		return parts[0].expr
+	}
+
+	var b strings.Builder
+	b.WriteString(`"`)
+	for _, p := range parts {
+		if p.expr != "" {
+			b.WriteString("${" + p.expr + "}")
+			continue
+		}
+		b.WriteString(escapeTemplate(p.text))
+	}
+	b.WriteString(`"`)
+	return b.String()
+}
+
+// templateParts splits s into literal text and converted interpolations.
+func (c *converter) templateParts(s string, sc scope, where string) ([]templatePart, bool) {
+	if !strings.Contains(s, "{{") {
+		return []templatePart{{text: s}}, true
+	}
+
+	tree := parse.New(where)
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(s, "{{", "}}", map[string]*parse.Tree{}); err != nil {
+		c.warn("%s: could not parse %q, it is kept as is: %s", where, s, err)
+		return nil, false
+	}
+
+	var parts []templatePart
+	for _, n := range tree.Root.Nodes {
+		switch n := n.(type) {
+		case *parse.TextNode:
+			parts = append(parts, templatePart{text: string(n.Text)})
+		case *parse.CommentNode:
+		case *parse.ActionNode:
+			if isTemplateField(n.Pipe) && sc != scopeOutput {
+				// The components interpolate these fields themselves,
+				// like {{ .HTTPIP }} in boot commands.
+				parts = append(parts, templatePart{text: n.String()})
+				continue
+			}
+			expr, err := c.convertPipe(n.Pipe, sc)
+			if err != nil {
+				c.warn("%s: %s is kept as is: %s", where, n, err)
+				parts = append(parts, templatePart{text: n.String()})
+				continue
+			}
+			parts = append(parts, templatePart{expr: expr})
+		default:
+			c.warn("%s: %s is kept as is: it has no HCL2 equivalent", where, n)
+			parts = append(parts, templatePart{text: n.String()})
+		}
+	}
+	return parts, true
+}
+
+// isTemplateField tells whether p only reads fields of the data of the
+// template, like {{ .Path }}.
+func isTemplateField(p *parse.PipeNode) bool {
+	if len(p.Decl) > 0 {
+		return false
+	}
+	for _, cmd := range p.Cmds {
+		for _, arg := range cmd.Args {
+			switch arg.(type) {
+			case *parse.FieldNode, *parse.DotNode:
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *converter) convertPipe(p *parse.PipeNode, sc scope) (string, error) {
+	if len(p.Decl) > 0 {
+		return "", fmt.Errorf("template variables have no HCL2 equivalent")
+	}
+	expr := ""
+	for i, cmd := range p.Cmds {
+		var prev []string
+		if i > 0 {
+			prev = []string{expr}
+		}
+		var err error
+		expr, err = c.convertCommand(cmd, prev, sc)
+		if err != nil {
+			return "", err
+		}
+	}
+	return expr, nil
+}
+
+func (c *converter) convertCommand(cmd *parse.CommandNode, prev []string, sc scope) (string, error) {
+	ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok {
+		if len(cmd.Args) > 1 || len(prev) > 0 {
+			return "", fmt.Errorf("%s is not a function", cmd.Args[0])
+		}
+		return c.convertArg(cmd.Args[0], sc)
+	}
+	return c.convertFunc(ident.Ident, cmd.Args[1:], prev, sc)
+}
+
+// convertArg returns the HCL2 expression of an argument of a function.
+func (c *converter) convertArg(n parse.Node, sc scope) (string, error) {
+	switch n := n.(type) {
+	case *parse.StringNode:
+		return hclString(n.Text), nil
+	case *parse.NumberNode:
+		return n.Text, nil
+	case *parse.BoolNode:
+		return strconv.FormatBool(n.True), nil
+	case *parse.PipeNode:
+		return c.convertPipe(n, sc)
+	case *parse.IdentifierNode:
+		return c.convertFunc(n.Ident, nil, nil, sc)
+	case *parse.FieldNode:
+		if sc == scopeOutput {
+			return artifactExpr("artifact", n.Ident)
+		}
+	case *parse.ChainNode:
+		if sc == scopeOutput {
+			base, err := c.convertArg(n.Node, sc)
+			if err != nil {
+				return "", err
+			}
+			return artifactExpr(base, n.Field)
+		}
+	}
+	return "", fmt.Errorf("%s has no HCL2 equivalent here", n)
+}
+
+// artifactExpr returns the HCL2 expression reading the fields of an
+// artifact.
+func artifactExpr(base string, fields []string) (string, error) {
+	expr := base
+	for _, f := range fields {
+		if f == "Artifacts" && base == "artifact" && expr == base {
+			expr = "artifacts"
+			continue
+		}
+		name, ok := artifactFields[f]
+		if !ok {
+			return "", fmt.Errorf("unknown artifact field %q", f)
+		}
+		expr += "." + name
+	}
+	return expr, nil
+}
+
+// stringArg returns the value of a literal string argument.
+func stringArg(n parse.Node) (string, error) {
+	s, ok := n.(*parse.StringNode)
+	if !ok {
+		return "", fmt.Errorf("%s is not a literal string", n)
+	}
+	return s.Text, nil
+}
+
+// traversal returns the HCL2 expression reading the attribute called name
+// of base.
+func traversal(base, name string) string {
+	if hclsyntax.ValidIdentifier(name) {
+		return base + "." + name
+	}
+	return base + "[" + hclString(name) + "]"
+}
+
+func (c *converter) convertFunc(name string, rawArgs []parse.Node, prev []string, sc scope) (string, error) {
+	// Functions reading their arguments literally.
+	switch name {
+	case "user", "env", "build", "isotime", "strftime":
+		if len(prev) > 0 {
+			return "", fmt.Errorf("%s can't be used in a pipeline", name)
+		}
+	}
+	switch name {
+	case "user":
+		if len(rawArgs) != 1 {
+			return "", fmt.Errorf("user takes one argument")
+		}
+		v, err := stringArg(rawArgs[0])
+		if err != nil {
+			return "", err
+		}
+		expr, ok := c.vars[v]
+		if !ok {
+			return "", fmt.Errorf("unknown variable %q", v)
+		}
+		return expr, nil
+	case "env":
+		if sc != scopeVariable {
+			return "", fmt.Errorf("env can only be used in the default value of variables")
+		}
+		if len(rawArgs) != 1 {
+			return "", fmt.Errorf("env takes one argument")
+		}
+		v, err := stringArg(rawArgs[0])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("env(%s)", hclString(v)), nil
+	case "build":
+		if sc != scopeProvisioner {
+			return "", fmt.Errorf("build variables are only available to provisioners and post-processors")
+		}
+		if len(rawArgs) != 1 {
+			return "", fmt.Errorf("build takes one argument")
+		}
+		v, err := stringArg(rawArgs[0])
+		if err != nil {
+			return "", err
+		}
+		return traversal("build", v), nil
+	case "isotime", "strftime":
+		if len(rawArgs) == 0 && name == "isotime" {
+			return "timestamp()", nil
+		}
+		if len(rawArgs) != 1 {
+			return "", fmt.Errorf("%s takes one argument", name)
+		}
+		format, err := stringArg(rawArgs[0])
+		if err != nil {
+			return "", err
+		}
+		convert := formatdateFromGoLayout
+		if name == "strftime" {
+			convert = formatdateFromStrftime
+		}
+		spec, err := convert(format)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("formatdate(%s, timestamp())", hclString(spec)), nil
+	}
+
+	args := make([]string, 0, len(rawArgs)+len(prev))
+	for _, a := range rawArgs {
+		expr, err := c.convertArg(a, sc)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, expr)
+	}
+	args = append(args, prev...)
+
+	wantArgs := func(n int) error {
+		if len(args) != n {
+			return fmt.Errorf("%s takes %d argument(s)", name, n)
+		}
+		return nil
+	}
+
+	switch name {
+	case "timestamp":
+		if err := wantArgs(0); err != nil {
+			return "", err
+		}
+		c.usesTimestamp = true
+		return "local.timestamp", nil
+	case "uuid":
+		if err := wantArgs(0); err != nil {
+			return "", err
+		}
+		return "uuidv4()", nil
+	case "build_name", "build_type":
+		if err := wantArgs(0); err != nil {
+			return "", err
+		}
+		if sc == scopeVariable {
+			return "", fmt.Errorf("%s is not available here", name)
+		}
+		return "source." + strings.TrimPrefix(name, "build_"), nil
+	case "template_dir":
+		if err := wantArgs(0); err != nil {
+			return "", err
+		}
+		return "path.root", nil
+	case "pwd":
+		if err := wantArgs(0); err != nil {
+			return "", err
+		}
+		return "path.cwd", nil
+	case "upper", "lower":
+		if err := wantArgs(1); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s(%s)", name, args[0]), nil
+	case "replace_all":
+		if err := wantArgs(3); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("replace(%s, %s, %s)", args[2], args[0], args[1]), nil
+	case "replace":
+		if err := wantArgs(4); err != nil {
+			return "", err
+		}
+		if args[2] != "-1" {
+			return "", fmt.Errorf("HCL2 can only replace all the occurrences of a string")
+		}
+		return fmt.Sprintf("replace(%s, %s, %s)", args[3], args[0], args[1]), nil
+	case "split":
+		if err := wantArgs(3); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("split(%s, %s)[%s]", args[1], args[0], args[2]), nil
+	case "build_output":
+		if err := wantArgs(2); err != nil {
+			return "", err
+		}
+		b, err := stringArg(rawArgs[0])
+		if err != nil {
+			return "", err
+		}
+		k, err := stringArg(rawArgs[1])
+		if err != nil {
+			return "", err
+		}
+		build, ok := c.buildNames[b]
+		if !ok {
+			return "", fmt.Errorf("unknown build %q", b)
+		}
+		return traversal(fmt.Sprintf("outputs[%s]", hclString(build)), k), nil
+	case "formatdate", "regex_replace", "jsonencode":
+		// These functions of HCL2 templates take the same arguments in
+		// JSON templates.
+		arity := map[string]int{"formatdate": 2, "regex_replace": 3, "jsonencode": 1}
+		if err := wantArgs(arity[name]); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s(%s)", name, strings.Join(args, ", ")), nil
+	case "lookup":
+		// The maps of JSON templates are JSON objects in strings.
+		switch len(args) {
+		case 2:
+			return fmt.Sprintf("jsondecode(%s)[%s]", args[0], args[1]), nil
+		case 3:
+			return fmt.Sprintf("lookup(jsondecode(%s), %s, %s)", args[0], args[1], args[2]), nil
+		}
+		return "", fmt.Errorf("lookup takes 2 or 3 arguments")
+	case "vault", "aws_secretsmanager", "azure_keyvault", "gcp_secretmanager":
+		return fmt.Sprintf("%s(%s)", name, strings.Join(args, ", ")), nil
+	case "printf":
+		if len(args) == 0 {
+			return "", fmt.Errorf("printf takes at least one argument")
+		}
+		return fmt.Sprintf("format(%s)", strings.Join(args, ", ")), nil
+	case "len":
+		if err := wantArgs(1); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("length(%s)", args[0]), nil
+	case "index":
+		if len(args) < 2 {
+			return "", fmt.Errorf("index takes at least two arguments")
+		}
+		expr := args[0]
+		for _, a := range args[1:] {
+			expr += "[" + a + "]"
+		}
+		return expr, nil
+	}
+	return "", fmt.Errorf("the %s function has no HCL2 equivalent", name)
+}
@@ -0,0 +1,118 @@
+# This file was generated from a JSON template by the 'packer hcl2_upgrade'
+# command. Check that everything is correct before using it. The blocks in this
+# file can be moved to other files in the same directory, like the variable
+# blocks to a variables.pkr.hcl file; these files need the .pkr.hcl extension
+# to be read by Packer.
+
+# Builds the base image.
+
+# min_packer_version = "1.5.0"
+
+variable "ami_name" {
+  type    = string
+  default = null
+}
+
+variable "home" {
+  type    = string
+  default = env("HOME")
+}
+
+variable "password" {
+  type      = string
+  default   = ""
+  sensitive = true
+}
+
+variable "region" {
+  type    = string
+  default = "us-east-1"
+}
+
+variable "token" {
+  type = string
+}
+
+locals {
+  timestamp = regex_replace(timestamp(), "[- TZ:]", "")
+  ami_name  = var.ami_name != null ? var.ami_name : "base-${var.region}-${local.timestamp}"
+}
+
+source "docker" "docker" {
+  commit = true
+  image  = "ubuntu:20.04"
+}
+
+source "amazon-ebs" "ubuntu" {
+  ami_name      = local.ami_name
+  instance_type = "t2.micro"
+  region        = var.region
+  tags = {
+    Name                  = source.name
+    "${var.region}-owner" = "me"
+  }
+
+  launch_block_device_mappings {
+    device_name = "/dev/sda1"
+    volume_size = 40
+  }
+
+  source_ami_filter {
+    filters = {
+      name             = "ubuntu/images/*"
+      root-device-type = "ebs"
+    }
+    most_recent = true
+    owners      = ["099720109477"]
+  }
+}
+
+build {
+  description = "base image"
+  sources     = ["source.docker.docker", "source.amazon-ebs.ubuntu"]
+
+  notification "slack" {
+    on       = ["failure"]
+    url      = var.token
+    template = "{{ .BuildName }} failed: {{ .Error }}"
+  }
+
+  provisioner "shell" {
+    only         = ["docker.docker"]
+    pause_before = "10s"
+    max_retries  = 3
+    inline       = ["apt-get update"]
+  }
+
+  provisioner "shell" {
+    except       = ["docker.docker"]
+    pause_before = "10s"
+    max_retries  = 3
+    inline = [
+      "echo ${build.ID}",
+      "echo $${HOME}",
+      "date +${formatdate("YYYY-MM-DD", timestamp())}",
+    ]
+  }
+
+  provisioner "file" {
+    only        = ["amazon-ebs.ubuntu"]
+    destination = "/tmp"
+    source      = "${path.root}/files"
+  }
+
+  post-processor "manifest" {
+  }
+
+  post-processors {
+    post-processor "docker-tag" {
+      only       = ["docker.docker"]
+      repository = "base"
+      tags       = [var.region]
+    }
+
+    post-processor "docker-push" {
+      only = ["docker.docker"]
+    }
+  }
+}
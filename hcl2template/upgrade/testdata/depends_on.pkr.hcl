@@ -0,0 +1,63 @@
+# This file was generated from a JSON template by the 'packer hcl2_upgrade'
+# command. Check that everything is correct before using it. The blocks in this
+# file can be moved to other files in the same directory, like the variable
+# blocks to a variables.pkr.hcl file; these files need the .pkr.hcl extension
+# to be read by Packer.
+
+variable "version" {
+  type    = string
+  default = "1.0"
+}
+
+source "null" "app_v2" {
+  communicator = "none"
+}
+
+source "null" "base" {
+  communicator = "none"
+}
+
+build {
+  name       = "app_v2"
+  sources    = ["source.null.app_v2"]
+  depends_on = ["base.null.base"]
+
+  provisioner "shell-local" {
+    inline = ["echo ${outputs["base.null.base"].image}"]
+  }
+
+  provisioner "shell-local" {
+    inline = ["echo ${upper(var.version)}"]
+  }
+
+  post-processors {
+    post-processor "manifest" {
+    }
+
+    post-processor "shell-local" {
+      inline = ["echo done"]
+    }
+  }
+}
+
+build {
+  name    = "base"
+  sources = ["source.null.base"]
+  locks   = ["base-${var.version}"]
+
+  output "image" {
+    value = artifact.id
+  }
+
+  output "region" {
+    value = artifact.metadata["region"]
+  }
+
+  provisioner "shell-local" {
+    inline = ["echo base"]
+  }
+
+  post-processor "shell-local" {
+    inline = ["echo done"]
+  }
+}
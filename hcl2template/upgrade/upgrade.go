@@ -0,0 +1,618 @@
+// Package upgrade converts legacy JSON templates to HCL2 configurations.
+package upgrade
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/packer/template"
+)
+
+// The kinds of components, for Options.Spec.
+const (
+	KindBuilder       = "builder"
+	KindProvisioner   = "provisioner"
+	KindPostProcessor = "post-processor"
+)
+
+// Options are the options of Upgrade.
+type Options struct {
+	// Spec returns the HCL2 spec of the configuration of a component, by
+	// kind and type, or nil when it is not known. The spec tells which
+	// options are blocks; without it, maps of values that are not all
+	// scalars and lists of maps are written as blocks.
+	Spec func(kind, typ string) hcldec.ObjectSpec
+}
+
+const header = `# This file was generated from a JSON template by the 'packer hcl2_upgrade'
+# command. Check that everything is correct before using it. The blocks in this
+# file can be moved to other files in the same directory, like the variable
+# blocks to a variables.pkr.hcl file; these files need the .pkr.hcl extension
+# to be read by Packer.
+`
+
+// Upgrade returns the HCL2 configuration equivalent to tpl, and warnings
+// about what could not be converted exactly.
+func Upgrade(tpl *template.Template, opts Options) ([]byte, []string, error) {
+	c := &converter{
+		tpl:        tpl,
+		opts:       opts,
+		vars:       map[string]string{},
+		buildNames: map[string]string{},
+		sources:    map[string]string{},
+	}
+	return c.upgrade()
+}
+
+type converter struct {
+	tpl  *template.Template
+	opts Options
+
+	// vars are the expressions reading the user variables, by name.
+	vars map[string]string
+	// sources are the references to the sources, like "amazon-ebs.ubuntu",
+	// by builder name.
+	sources map[string]string
+	// buildNames are the names of the HCL2 builds, by builder name.
+	buildNames map[string]string
+	// builderNames are the names of the builders, sorted.
+	builderNames []string
+
+	usesTimestamp bool
+	warnings      []string
+}
+
+func (c *converter) warn(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *converter) upgrade() ([]byte, []string, error) {
+	tpl := c.tpl
+	if len(tpl.Builders) == 0 {
+		return nil, nil, fmt.Errorf("the template has no builders")
+	}
+
+	// One build block per builder is needed when builders depend on other
+	// builders, lock names or have named outputs.
+	perBuilder := false
+	for name, b := range tpl.Builders {
+		c.builderNames = append(c.builderNames, name)
+		if len(b.DependsOn) > 0 || len(b.Outputs) > 0 || len(b.Locks) > 0 {
+			perBuilder = true
+		}
+	}
+	sort.Strings(c.builderNames)
+
+	used := map[string]bool{}
+	for _, name := range c.builderNames {
+		b := tpl.Builders[name]
+		sourceName := uniqueIdentifier(name, used)
+		if sourceName != name {
+			c.warn("builder %q: renamed to %q, a valid HCL2 name", name, sourceName)
+		}
+		ref := b.Type + "." + sourceName
+		c.sources[name] = ref
+		c.buildNames[name] = ref
+		if perBuilder {
+			c.buildNames[name] = sourceName + "." + ref
+		}
+	}
+
+	vars, locals := c.variables()
+
+	var sources bytes.Buffer
+	for _, name := range c.builderNames {
+		b := tpl.Builders[name]
+		sourceName := strings.TrimPrefix(c.sources[name], b.Type+".")
+		fmt.Fprintf(&sources, "\nsource %s %s {\n", hclString(b.Type), hclString(sourceName))
+		c.writeBody(&sources, b.Config, c.spec(KindBuilder, b.Type), scopeSource, fmt.Sprintf("builder %q", name))
+		sources.WriteString("}\n")
+	}
+
+	var builds bytes.Buffer
+	if perBuilder {
+		for _, name := range c.builderNames {
+			c.writeBuild(&builds, []string{name}, true)
+		}
+	} else {
+		c.writeBuild(&builds, c.builderNames, false)
+	}
+
+	if tpl.CleanupProvisioner != nil {
+		c.warn("the error-cleanup-provisioner has no HCL2 equivalent, it is commented out")
+		var p bytes.Buffer
+		c.writeProvisioner(&p, tpl.CleanupProvisioner, tpl.CleanupProvisioner.Config, nil, nil, "error-cleanup-provisioner")
+		builds.WriteString("\n# The error-cleanup-provisioner has no HCL2 equivalent:\n")
+		builds.WriteString(commentOut(p.String()))
+	}
+
+	var out bytes.Buffer
+	out.WriteString(header)
+	for _, k := range sortedKeys(tpl.Comments) {
+		out.WriteString("\n" + commentOut(tpl.Comments[k]))
+	}
+	if tpl.MinVersion != "" {
+		c.warn("min_packer_version has no HCL2 equivalent, it is kept as a comment")
+		fmt.Fprintf(&out, "\n# min_packer_version = %q\n", tpl.MinVersion)
+	}
+	out.Write(vars.Bytes())
+	if c.usesTimestamp {
+		c.warn("{{timestamp}} was a UNIX timestamp, local.timestamp is a date like 20200102150405")
+		locals = append([]string{`timestamp = regex_replace(timestamp(), "[- TZ:]", "")`}, locals...)
+	}
+	if len(locals) > 0 {
+		out.WriteString("\nlocals {\n")
+		for _, l := range locals {
+			out.WriteString(l + "\n")
+		}
+		out.WriteString("}\n")
+	}
+	out.Write(sources.Bytes())
+	out.Write(builds.Bytes())
+
+	formatted := hclwrite.Format(out.Bytes())
+	if _, diags := hclsyntax.ParseConfig(formatted, "upgraded.pkr.hcl", hclsyntaxStart); diags.HasErrors() {
+		return nil, c.warnings, fmt.Errorf("the generated configuration is invalid: %s", diags)
+	}
+	return formatted, c.warnings, nil
+}
+
+func (c *converter) spec(kind, typ string) hcldec.ObjectSpec {
+	if c.opts.Spec == nil {
+		return nil
+	}
+	return c.opts.Spec(kind, typ)
+}
+
+// variables returns the variable blocks of the user variables, and the
+// locals computing the ones whose default uses other variables or
+// functions.
+func (c *converter) variables() (*bytes.Buffer, []string) {
+	tpl := c.tpl
+	sensitive := map[string]bool{}
+	for _, v := range tpl.SensitiveVariables {
+		sensitive[v.Key] = true
+	}
+
+	// The variables with interpolated defaults are read through a local,
+	// since the defaults of HCL2 variables can only use env.
+	names := sortedKeys(tpl.Variables)
+	hclNames := map[string]string{}
+	used := map[string]bool{}
+	wrapped := map[string]bool{}
+	for _, name := range names {
+		hclName := uniqueIdentifier(name, used)
+		if hclName != name {
+			c.warn("variable %q: renamed to %q, a valid HCL2 name", name, hclName)
+		}
+		hclNames[name] = hclName
+		c.vars[name] = "var." + hclName
+		if needsLocal(tpl.Variables[name].Default) {
+			wrapped[name] = true
+			c.vars[name] = "local." + hclName
+		}
+	}
+
+	var out bytes.Buffer
+	var locals []string
+	for _, name := range names {
+		v := tpl.Variables[name]
+		hclName := hclNames[name]
+		where := fmt.Sprintf("variable %q", name)
+
+		fmt.Fprintf(&out, "\nvariable %s {\n", hclString(hclName))
+		out.WriteString("type = string\n")
+		switch {
+		case v.Required:
+		case wrapped[name]:
+			out.WriteString("default = null\n")
+			locals = append(locals, fmt.Sprintf("%s = var.%s != null ? var.%s : %s",
+				hclName, hclName, hclName, c.convertString(v.Default, scopeProvisioner, where)))
+		default:
+			fmt.Fprintf(&out, "default = %s\n", c.convertString(v.Default, scopeVariable, where))
+		}
+		if sensitive[name] {
+			out.WriteString("sensitive = true\n")
+			if wrapped[name] {
+				c.warn("%s: its default is computed in a local, which is not hidden from the output", where)
+			}
+		}
+		out.WriteString("}\n")
+	}
+	return &out, locals
+}
+
+// needsLocal tells whether the default of a variable uses other variables
+// or functions that HCL2 variables can't use in their default.
+func needsLocal(def string) bool {
+	if !strings.Contains(def, "{{") {
+		return false
+	}
+	tree := parse.New("default")
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(def, "{{", "}}", map[string]*parse.Tree{}); err != nil {
+		return false
+	}
+	for _, n := range tree.Root.Nodes {
+		a, ok := n.(*parse.ActionNode)
+		if !ok {
+			continue
+		}
+		for _, cmd := range a.Pipe.Cmds {
+			ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+			if !ok || ident.Ident != "env" || len(a.Pipe.Cmds) > 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeBuild writes the build block of the builders called names. A build
+// block per builder holds the settings of its builder, and only the
+// provisioners and post-processors running for it.
+func (c *converter) writeBuild(out *bytes.Buffer, names []string, perBuilder bool) {
+	tpl := c.tpl
+	out.WriteString("\nbuild {\n")
+	if perBuilder {
+		fmt.Fprintf(out, "name = %s\n", hclString(strings.SplitN(c.buildNames[names[0]], ".", 2)[0]))
+	}
+	if tpl.Description != "" {
+		fmt.Fprintf(out, "description = %s\n", hclString(tpl.Description))
+	}
+	refs := make([]string, len(names))
+	for i, n := range names {
+		refs[i] = hclString("source." + c.sources[n])
+	}
+	fmt.Fprintf(out, "sources = [%s]\n", strings.Join(refs, ", "))
+
+	if perBuilder {
+		b := tpl.Builders[names[0]]
+		where := fmt.Sprintf("builder %q", names[0])
+		if len(b.DependsOn) > 0 {
+			deps := make([]string, 0, len(b.DependsOn))
+			for _, d := range b.DependsOn {
+				deps = append(deps, hclString(c.buildNames[d]))
+			}
+			fmt.Fprintf(out, "depends_on = [%s]\n", strings.Join(deps, ", "))
+		}
+		if len(b.Locks) > 0 {
+			locks := make([]string, 0, len(b.Locks))
+			for _, l := range b.Locks {
+				locks = append(locks, c.convertString(l, scopeSource, where+" locks"))
+			}
+			fmt.Fprintf(out, "locks = [%s]\n", strings.Join(locks, ", "))
+		}
+	}
+
+	for _, t := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"build_timeout", tpl.BuildTimeout},
+		{"provision_timeout", tpl.ProvisionTimeout},
+		{"post_process_timeout", tpl.PostProcessTimeout},
+	} {
+		if t.value != 0 {
+			fmt.Fprintf(out, "%s = %s\n", t.name, hclString(t.value.String()))
+		}
+	}
+	if eh := tpl.ErrorHandling; eh != nil && eh.OnFailure != "" {
+		fmt.Fprintf(out, "\nerror_handling {\non_failure = %s\n}\n", hclString(eh.OnFailure))
+	}
+	for i, n := range tpl.Notifications {
+		c.writeNotification(out, n, fmt.Sprintf("notification %d (%s)", i+1, n.Type))
+	}
+
+	if perBuilder {
+		b := tpl.Builders[names[0]]
+		for _, k := range sortedKeys(b.Outputs) {
+			where := fmt.Sprintf("builder %q output %q", names[0], k)
+			fmt.Fprintf(out, "\noutput %s {\nvalue = %s\n}\n", hclString(k), c.convertString(b.Outputs[k], scopeOutput, where))
+		}
+	}
+
+	for i, p := range tpl.Provisioners {
+		where := fmt.Sprintf("provisioner %d (%s)", i+1, p.Type)
+		if perBuilder {
+			c.writeBuilderProvisioner(out, p, names[0], where)
+			continue
+		}
+		c.writeProvisioners(out, p, where)
+	}
+
+	for i, chain := range tpl.PostProcessors {
+		if perBuilder {
+			var kept []*template.PostProcessor
+			for _, pp := range chain {
+				if !pp.OnlyExcept.Skip(names[0]) {
+					kept = append(kept, pp)
+				}
+			}
+			chain = kept
+		}
+		if len(chain) == 0 {
+			continue
+		}
+		if len(chain) == 1 {
+			c.writePostProcessor(out, chain[0], perBuilder, fmt.Sprintf("post-processor %d (%s)", i+1, chain[0].Type))
+			continue
+		}
+		out.WriteString("\npost-processors {")
+		for j, pp := range chain {
+			c.writePostProcessor(out, pp, perBuilder, fmt.Sprintf("post-processor %d.%d (%s)", i+1, j+1, pp.Type))
+		}
+		out.WriteString("}\n")
+	}
+	out.WriteString("}\n")
+}
+
+// writeBuilderProvisioner writes p as it runs for the builder called name,
+// if it does.
+func (c *converter) writeBuilderProvisioner(out *bytes.Buffer, p *template.Provisioner, name, where string) {
+	if p.OnlyExcept.Skip(name) {
+		return
+	}
+	config := p.Config
+	if override, ok := p.Override[name].(map[string]interface{}); ok {
+		config = mergeConfig(p.Config, override)
+	}
+	c.writeProvisioner(out, p, config, nil, nil, where)
+}
+
+// writeNotification writes the notification block of n. Its template and
+// subject are interpolated with the data of the build when it is sent, they
+// are kept as they are.
+func (c *converter) writeNotification(out *bytes.Buffer, n *template.Notification, where string) {
+	fmt.Fprintf(out, "\nnotification %s {\n", hclString(n.Type))
+	if len(n.On) > 0 {
+		on := make([]string, 0, len(n.On))
+		for _, e := range n.On {
+			on = append(on, hclString(e))
+		}
+		fmt.Fprintf(out, "on = [%s]\n", strings.Join(on, ", "))
+	}
+	for _, a := range []struct {
+		name  string
+		value string
+	}{
+		{"url", n.URL},
+		{"method", n.Method},
+		{"smtp_address", n.SMTPAddress},
+		{"smtp_username", n.SMTPUsername},
+		{"smtp_password", n.SMTPPassword},
+		{"from", n.From},
+	} {
+		if a.value != "" {
+			fmt.Fprintf(out, "%s = %s\n", a.name, c.convertString(a.value, scopeSource, where+" "+a.name))
+		}
+	}
+	if len(n.To) > 0 {
+		to := make([]string, 0, len(n.To))
+		for _, t := range n.To {
+			to = append(to, c.convertString(t, scopeSource, where+" to"))
+		}
+		fmt.Fprintf(out, "to = [%s]\n", strings.Join(to, ", "))
+	}
+	if len(n.Headers) > 0 {
+		fmt.Fprintf(out, "headers = {\n")
+		for _, k := range sortedKeys(n.Headers) {
+			fmt.Fprintf(out, "%s = %s\n", hclString(k), c.convertString(n.Headers[k], scopeSource, where+" headers"))
+		}
+		fmt.Fprintf(out, "}\n")
+	}
+	if n.Subject != "" {
+		fmt.Fprintf(out, "subject = %s\n", hclString(n.Subject))
+	}
+	if n.Template != "" {
+		fmt.Fprintf(out, "template = %s\n", hclString(n.Template))
+	}
+	fmt.Fprintf(out, "}\n")
+}
+
+// writeProvisioners writes p in a build block with all the sources. HCL2 has
+// no overrides: p is written once per overridden builder with the config
+// of this builder, and once for the other builders.
+func (c *converter) writeProvisioners(out *bytes.Buffer, p *template.Provisioner, where string) {
+	var overridden, others []string
+	for _, name := range c.builderNames {
+		if p.OnlyExcept.Skip(name) {
+			continue
+		}
+		if _, ok := p.Override[name]; ok {
+			overridden = append(overridden, name)
+			continue
+		}
+		others = append(others, name)
+	}
+	for name := range p.Override {
+		if _, ok := c.tpl.Builders[name]; !ok {
+			c.warn("%s: the override of the unknown builder %q is ignored", where, name)
+		}
+	}
+
+	for _, name := range overridden {
+		override, ok := p.Override[name].(map[string]interface{})
+		if !ok {
+			c.warn("%s: the override of builder %q is not an object, it is ignored", where, name)
+			override = nil
+		}
+		c.writeProvisioner(out, p, mergeConfig(p.Config, override), []string{c.sources[name]}, nil, where)
+	}
+	if len(others) == 0 {
+		return
+	}
+
+	var only, except []string
+	switch {
+	case len(overridden) == 0:
+		only = c.sourceRefs(p.OnlyExcept.Only)
+		except = c.sourceRefs(p.OnlyExcept.Except)
+	case len(p.OnlyExcept.Only) > 0:
+		only = c.sourceRefs(others)
+	default:
+		except = c.sourceRefs(append(append([]string{}, p.OnlyExcept.Except...), overridden...))
+	}
+	c.writeProvisioner(out, p, p.Config, only, except, where)
+}
+
+// sourceRefs returns the references to the sources of the builders called
+// names, as used by only and except.
+func (c *converter) sourceRefs(names []string) []string {
+	var refs []string
+	for _, n := range names {
+		ref, ok := c.sources[n]
+		if !ok {
+			c.warn("only or except: unknown builder %q", n)
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+func (c *converter) writeProvisioner(out *bytes.Buffer, p *template.Provisioner, config map[string]interface{}, only, except []string, where string) {
+	fmt.Fprintf(out, "\nprovisioner %s {\n", hclString(p.Type))
+	writeStringList(out, "only", only)
+	writeStringList(out, "except", except)
+	if p.PauseBefore != 0 {
+		fmt.Fprintf(out, "pause_before = %s\n", hclString(p.PauseBefore.String()))
+	}
+	if p.MaxRetries != "" {
+		if _, err := strconv.Atoi(p.MaxRetries); err == nil {
+			fmt.Fprintf(out, "max_retries = %s\n", p.MaxRetries)
+		} else {
+			fmt.Fprintf(out, "max_retries = %s\n", c.convertString(p.MaxRetries, scopeProvisioner, where))
+		}
+	}
+	if p.Timeout != 0 {
+		fmt.Fprintf(out, "timeout = %s\n", hclString(p.Timeout.String()))
+	}
+	if p.RemoteUser != "" {
+		fmt.Fprintf(out, "remote_user = %s\n", c.convertString(p.RemoteUser, scopeProvisioner, where))
+	}
+	if p.RemotePassword != "" {
+		fmt.Fprintf(out, "remote_password = %s\n", c.convertString(p.RemotePassword, scopeProvisioner, where))
+	}
+	if p.IdempotencyMarker != "" {
+		fmt.Fprintf(out, "idempotency_marker = %s\n", c.convertString(p.IdempotencyMarker, scopeProvisioner, where))
+	}
+	if eh := p.ErrorHandling; eh != nil {
+		out.WriteString("\nerror_handling {\n")
+		if eh.Retries != 0 {
+			fmt.Fprintf(out, "retries = %d\n", eh.Retries)
+		}
+		if eh.OnFailure != "" {
+			fmt.Fprintf(out, "on_failure = %s\n", hclString(eh.OnFailure))
+		}
+		out.WriteString("}\n")
+	}
+	c.writeBody(out, config, c.spec(KindProvisioner, p.Type), scopeProvisioner, where)
+	out.WriteString("}\n")
+}
+
+func (c *converter) writePostProcessor(out *bytes.Buffer, pp *template.PostProcessor, perBuilder bool, where string) {
+	fmt.Fprintf(out, "\npost-processor %s {\n", hclString(pp.Type))
+	if pp.Name != "" && pp.Name != pp.Type {
+		fmt.Fprintf(out, "name = %s\n", hclString(pp.Name))
+	}
+	if !perBuilder {
+		writeStringList(out, "only", c.sourceRefs(pp.OnlyExcept.Only))
+		writeStringList(out, "except", c.sourceRefs(pp.OnlyExcept.Except))
+	}
+	if pp.KeepInputArtifact != nil {
+		fmt.Fprintf(out, "keep_input_artifact = %t\n", *pp.KeepInputArtifact)
+	}
+	c.writeBody(out, pp.Config, c.spec(KindPostProcessor, pp.Type), scopeProvisioner, where)
+	out.WriteString("}\n")
+}
+
+func writeStringList(out *bytes.Buffer, name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = hclString(v)
+	}
+	fmt.Fprintf(out, "%s = [%s]\n", name, strings.Join(quoted, ", "))
+}
+
+// mergeConfig returns config with the top level keys of override, like
+// when overrides are applied.
+func mergeConfig(config, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(config)+len(override))
+	for k, v := range config {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// uniqueIdentifier returns name as a valid HCL2 identifier, not yet in
+// used.
+func uniqueIdentifier(name string, used map[string]bool) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case (r >= '0' && r <= '9') || r == '-':
+			if i == 0 {
+				b.WriteString("_")
+			}
+		default:
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	id := b.String()
+	if id == "" {
+		id = "_"
+	}
+	unique := id
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", id, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// commentOut returns s with every line commented out.
+func commentOut(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("# "+l, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*template.Variable:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,152 @@
+package upgrade
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/template"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestUpgrade(t *testing.T) {
+	tests := []struct {
+		name         string
+		wantWarnings []string
+	}{
+		{
+			"complete",
+			[]string{
+				"min_packer_version has no HCL2 equivalent, it is kept as a comment",
+				"{{timestamp}} was a UNIX timestamp, local.timestamp is a date like 20200102150405",
+			},
+		},
+		{
+			"depends_on",
+			[]string{
+				`builder "app.v2": renamed to "app_v2", a valid HCL2 name`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := template.ParseFile(filepath.Join("testdata", tt.name+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := ioutil.ReadFile(filepath.Join("testdata", tt.name+".pkr.hcl"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, warnings, err := Upgrade(tpl, Options{})
+			if err != nil {
+				t.Fatalf("Upgrade: %s", err)
+			}
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("wrong configuration: %s", diff)
+			}
+			if diff := cmp.Diff(tt.wantWarnings, warnings); diff != "" {
+				t.Errorf("wrong warnings: %s", diff)
+			}
+		})
+	}
+}
+
+func TestUpgrade_spec(t *testing.T) {
+	tpl := &template.Template{
+		Builders: map[string]*template.Builder{
+			"vm": {
+				Name: "vm",
+				Type: "test",
+				Config: map[string]interface{}{
+					"tags":     map[string]interface{}{"a": "b"},
+					"run_tags": map[string]interface{}{"a": "b"},
+					"disk":     map[string]interface{}{"size": 10.0},
+				},
+			},
+		},
+	}
+	spec := func(kind, typ string) hcldec.ObjectSpec {
+		if kind != KindBuilder || typ != "test" {
+			return nil
+		}
+		return hcldec.ObjectSpec{
+			"tags":     &hcldec.BlockAttrsSpec{TypeName: "tags", ElementType: cty.String},
+			"run_tags": &hcldec.AttrSpec{Name: "run_tags", Type: cty.Map(cty.String)},
+			"disk":     &hcldec.AttrSpec{Name: "disk", Type: cty.DynamicPseudoType},
+		}
+	}
+
+	got, _, err := Upgrade(tpl, Options{Spec: spec})
+	if err != nil {
+		t.Fatalf("Upgrade: %s", err)
+	}
+	want := `
+source "test" "vm" {
+  disk = {
+    size = 10
+  }
+  run_tags = {
+    a = "b"
+  }
+
+  tags {
+    a = "b"
+  }
+}
+`
+	if diff := cmp.Diff(header+want+`
+build {
+  sources = ["source.test.vm"]
+}
+`, string(got)); diff != "" {
+		t.Errorf("wrong configuration: %s", diff)
+	}
+}
+
+func TestConvertString(t *testing.T) {
+	tests := []struct {
+		in    string
+		scope scope
+		want  string
+	}{
+		{"plain", scopeSource, `"plain"`},
+		{"{{user `a`}}", scopeSource, "var.a"},
+		{"x-{{user `a`}}", scopeSource, `"x-${var.a}"`},
+		{"${HOME} %{x}", scopeSource, `"$${HOME} %%{x}"`},
+		{"{{env `HOME`}}", scopeVariable, `env("HOME")`},
+		{"{{uuid}}", scopeSource, "uuidv4()"},
+		{"{{isotime}}", scopeSource, "timestamp()"},
+		{"{{isotime `2006-01-02 15:04`}}", scopeSource, `formatdate("YYYY-MM-DD hh:mm", timestamp())`},
+		{"{{strftime `%Y%m%d`}}", scopeSource, `formatdate("YYYYMMDD", timestamp())`},
+		{"{{build_name}}-{{build_type}}", scopeSource, `"${source.name}-${source.type}"`},
+		{"{{template_dir}}/x", scopeSource, `"${path.root}/x"`},
+		{"{{pwd}}", scopeSource, "path.cwd"},
+		{"{{build `Host`}}", scopeProvisioner, "build.Host"},
+		{"{{user `a` | lower}}", scopeSource, "lower(var.a)"},
+		{"{{replace_all `-` `_` (user `a`)}}", scopeSource, `replace(var.a, "-", "_")`},
+		{"{{split (user `a`) `,` 1}}", scopeSource, `split(",", var.a)[1]`},
+		{"{{formatdate `YYYY-MM-DD` isotime}}", scopeSource, `formatdate("YYYY-MM-DD", timestamp())`},
+		{"{{regex_replace (user `a`) `[^a-z]` `-`}}", scopeSource, `regex_replace(var.a, "[^a-z]", "-")`},
+		{"{{lookup (user `a`) `us-east-1`}}", scopeSource, `jsondecode(var.a)["us-east-1"]`},
+		{"{{lookup (user `a`) `us-east-1` ``}}", scopeSource, `lookup(jsondecode(var.a), "us-east-1", "")`},
+		{"{{ .ID }}", scopeOutput, "artifact.id"},
+		{"{{ (index .Artifacts 0).Files }}", scopeOutput, "artifacts[0].files"},
+		{"{{ .Vars }}", scopeProvisioner, `"{{.Vars}}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			c := &converter{vars: map[string]string{"a": "var.a"}}
+			got := c.convertString(tt.in, tt.scope, "test")
+			if got != tt.want {
+				t.Errorf("convertString(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+			if len(c.warnings) > 0 {
+				t.Errorf("unexpected warnings: %v", c.warnings)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/packer/hcl2template/repl"
 	hcl2shim "github.com/hashicorp/packer/hcl2template/shim"
+	"github.com/hashicorp/packer/packer"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -34,6 +35,20 @@ func warningErrorsToDiags(block *hcl.Block, warnings []string, err error) hcl.Di
 	return diags
 }
 
+// deprecationsToDiags returns the warnings of the deprecated options set in
+// the configuration of the component, reported at subject.
+func deprecationsToDiags(kind, typ string, component interface{}, subject *hcl.Range) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for _, warning := range packer.DeprecationWarnings(packer.ComponentDeprecations(kind, typ, component)) {
+		diags = append(diags, &hcl.Diagnostic{
+			Summary:  warning,
+			Subject:  subject,
+			Severity: hcl.DiagWarning,
+		})
+	}
+	return diags
+}
+
 func isDir(name string) (bool, error) {
 	s, err := os.Stat(name)
 	if err != nil {
@@ -1,14 +1,213 @@
 package common
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"log"
 	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// The environment variables configuring the outbound HTTPS requests of
+// Packer, on top of HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+const (
+	// EnvCABundle is a PEM file, or a directory of PEM files, with the CA
+	// certificates trusted on top of the certificates of the system, like
+	// the CA of a corporate proxy.
+	EnvCABundle = "PACKER_CA_BUNDLE"
+	// EnvClientCert and EnvClientKey are the PEM files of the client
+	// certificate presented to the servers requiring mutual TLS.
+	EnvClientCert = "PACKER_CLIENT_CERT"
+	EnvClientKey  = "PACKER_CLIENT_KEY"
 )
 
+// HttpClientWithEnvironmentProxy returns a client configured with
+// HttpTransport.
 func HttpClientWithEnvironmentProxy() *http.Client {
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-		},
+	return HttpClient()
+}
+
+// HttpClient returns a new client sending its requests with HttpTransport.
+func HttpClient() *http.Client {
+	return &http.Client{
+		Transport: HttpTransport(),
+	}
+}
+
+// HttpTransport returns a new pooled transport honoring the proxy, the CA
+// bundle and the client certificate of the environment. The errors of the
+// environment are logged, they are reported to the user by
+// ValidateEnvironment when Packer starts.
+func HttpTransport() *http.Transport {
+	transport := cleanhttp.DefaultPooledTransport()
+	if err := ConfigureTransport(transport); err != nil {
+		log.Printf("[ERROR] %s", err)
+	}
+	return transport
+}
+
+// ConfigureClient configures the transport of client with
+// ConfigureTransport, when it is an *http.Transport.
+func ConfigureClient(client *http.Client) error {
+	if client == nil {
+		return nil
+	}
+	if client.Transport == nil {
+		transport := cleanhttp.DefaultPooledTransport()
+		client.Transport = transport
+		return ConfigureTransport(transport)
+	}
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		return ConfigureTransport(transport)
+	}
+	return nil
+}
+
+// ConfigureTransport configures transport with the proxy, the CA bundle and
+// the client certificate of the environment, keeping its own settings: the
+// CA certificates are added to its root CAs, and the client certificate is
+// only set when it has none.
+func ConfigureTransport(transport *http.Transport) error {
+	if transport.Proxy == nil {
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+
+	roots, err := caBundle()
+	if err != nil {
+		return err
+	}
+	cert, err := clientCertificate()
+	if err != nil {
+		return err
+	}
+	if roots == nil && cert == nil {
+		return nil
+	}
+
+	var tlsConfig *tls.Config
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	} else {
+		tlsConfig = &tls.Config{}
+	}
+	if roots != nil {
+		if tlsConfig.RootCAs == nil {
+			tlsConfig.RootCAs = systemCertPool()
+		}
+		for _, c := range roots {
+			tlsConfig.RootCAs.AddCert(c)
+		}
+	}
+	if cert != nil && len(tlsConfig.Certificates) == 0 && tlsConfig.GetClientCertificate == nil {
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return nil
+}
+
+// ValidateEnvironment returns an error when the CA bundle or the client
+// certificate of the environment can't be loaded.
+func ValidateEnvironment() error {
+	if _, err := caBundle(); err != nil {
+		return err
+	}
+	_, err := clientCertificate()
+	return err
+}
+
+func systemCertPool() *x509.CertPool {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		// Windows has no system cert pool before Go 1.18.
+		log.Printf("[WARN] Error loading the CA certificates of the system: %s", err)
+		return x509.NewCertPool()
+	}
+	return pool
+}
+
+// caBundle returns the certificates of EnvCABundle, or nil when it isn't
+// set.
+func caBundle() ([]*x509.Certificate, error) {
+	path := os.Getenv(EnvCABundle)
+	if path == "" {
+		return nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading the CA bundle of %s: %s", EnvCABundle, err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading the CA bundle of %s: %s", EnvCABundle, err)
+		}
+		files = files[:0]
+		for _, e := range entries {
+			if !e.IsDir() {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+	}
+
+	var certs []*x509.Certificate
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading the CA bundle of %s: %s", EnvCABundle, err)
+		}
+		c, err := parseCertificates(data)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading the CA bundle of %s: %s: %s", EnvCABundle, f, err)
+		}
+		certs = append(certs, c...)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("The CA bundle of %s has no PEM certificates: %s", EnvCABundle, path)
+	}
+	return certs, nil
+}
+
+// clientCertificate returns the certificate of EnvClientCert and
+// EnvClientKey, or nil when they aren't set.
+func clientCertificate() (*tls.Certificate, error) {
+	certFile, keyFile := os.Getenv(EnvClientCert), os.Getenv(EnvClientKey)
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("%s and %s must both be set", EnvClientCert, EnvClientKey)
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading the client certificate of %s: %s", EnvClientCert, err)
+	}
+	return &cert, nil
+}
+
+// parseCertificates returns the certificates of the PEM blocks of data.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, c)
 	}
-	return httpClient
 }
@@ -0,0 +1,159 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setenv(env map[string]string) func() {
+	old := map[string]string{}
+	for k, v := range env {
+		old[k] = os.Getenv(k)
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, v := range old {
+			os.Setenv(k, v)
+		}
+	}
+}
+
+// writeClientCertificate writes a self-signed client certificate and its key
+// to dir.
+func writeClientCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "packer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestHttpClient_caBundleAndClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-http")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	}), 0600)
+	certFile, keyFile := writeClientCertificate(t, dir)
+
+	defer setenv(map[string]string{EnvCABundle: "", EnvClientCert: "", EnvClientKey: ""})()
+
+	if _, err := HttpClient().Get(server.URL); err == nil {
+		t.Fatal("the server certificate shouldn't be trusted without the CA bundle")
+	}
+
+	// A directory of certificates can be used as the bundle.
+	os.Setenv(EnvCABundle, dir)
+	resp, err := HttpClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("client certificate sent without being configured: %s", resp.Status)
+	}
+
+	os.Setenv(EnvCABundle, caFile)
+	os.Setenv(EnvClientCert, certFile)
+	os.Setenv(EnvClientKey, keyFile)
+	resp, err = HttpClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("client certificate not sent: %s", resp.Status)
+	}
+}
+
+func TestConfigureTransport_keepsSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-http")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeClientCertificate(t, dir)
+	defer setenv(map[string]string{EnvCABundle: certFile, EnvClientCert: certFile, EnvClientKey: keyFile})()
+
+	own := tls.Certificate{Certificate: [][]byte{[]byte("own")}}
+	transport := &http.Transport{TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       []tls.Certificate{own},
+	}}
+	if err := ConfigureTransport(transport); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c := transport.TLSClientConfig
+	if !c.InsecureSkipVerify || len(c.Certificates) != 1 || string(c.Certificates[0].Certificate[0]) != "own" {
+		t.Fatalf("settings of the transport not kept: %#v", c)
+	}
+	if c.RootCAs == nil || transport.Proxy == nil {
+		t.Fatalf("transport not configured: %#v", transport)
+	}
+}
+
+func TestValidateEnvironment(t *testing.T) {
+	defer setenv(map[string]string{EnvCABundle: "", EnvClientCert: "", EnvClientKey: ""})()
+	if err := ValidateEnvironment(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	os.Setenv(EnvCABundle, "/does/not/exist")
+	if err := ValidateEnvironment(); err == nil {
+		t.Fatal("should error with a missing CA bundle")
+	}
+
+	os.Setenv(EnvCABundle, "")
+	os.Setenv(EnvClientCert, "client.pem")
+	if err := ValidateEnvironment(); err == nil {
+		t.Fatal("should error without the client key")
+	}
+}
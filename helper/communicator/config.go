@@ -7,8 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"net"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
@@ -16,6 +18,7 @@ import (
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/helper/multistep"
 	helperssh "github.com/hashicorp/packer/helper/ssh"
+	"github.com/hashicorp/packer/helper/totp"
 	"github.com/hashicorp/packer/packer"
 	"github.com/hashicorp/packer/template/interpolate"
 	"github.com/masterzen/winrm"
@@ -55,6 +58,27 @@ type Config struct {
 	// and beginning provisioning.
 	PauseBeforeConnect time.Duration `mapstructure:"pause_before_connecting"`
 
+	// How long the guest can go without answering the liveness checks of
+	// the communicator before the running command fails, like when the guest
+	// crashed or was preempted. SSH checks are the keepalive requests sent
+	// every `ssh_keep_alive_interval`; WinRM checks open and close a shell
+	// while a command runs. By default, a command fails only when the
+	// connection times out, which can take several minutes.
+	LivenessTimeout time.Duration `mapstructure:"liveness_timeout"`
+	// How often the communicator reports the throughput of its transfers,
+	// the latency of its commands and its connection retries, to tell a
+	// slow guest from a stuck build. The reports are also machine-readable
+	// `communicator-metrics` messages and events of the event stream. By
+	// default, nothing is reported.
+	MetricsInterval time.Duration `mapstructure:"metrics_interval"`
+	// If `true`, the OS of the guest isn't detected once connected. By
+	// default, a short script finds the OS family, name, version and
+	// architecture of the guest, its init system and its package manager,
+	// available to the provisioners as the `OSFamily`, `OSName`,
+	// `OSVersion`, `OSArch`, `InitSystem` and `PackageManager` build
+	// variables. They are empty when unknown.
+	DisableGuestFacts bool `mapstructure:"disable_guest_facts"`
+
 	SSH   `mapstructure:",squash"`
 	WinRM `mapstructure:",squash"`
 }
@@ -81,6 +105,17 @@ type SSH struct {
 	// generates a name that looks like `packer_<UUID>`, where &lt;UUID&gt; is
 	// a 36 character unique identifier.
 	SSHTemporaryKeyPairName string `mapstructure:"temporary_key_pair_name" undocumented:"true"`
+	// If true, the builds of a run that generate a temporary key pair share
+	// a single key pair instead of generating one each, which creates fewer
+	// key pairs in cloud accounts when many builds run in parallel. Defaults
+	// to `false`. Only the Amazon and VirtualBox builders share key pairs.
+	SSHTemporaryKeyPairShared bool `mapstructure:"temporary_key_pair_shared"`
+	// If true and Packer runs with `-debug`, the temporary key pair is kept
+	// when the build ends and reused by the next debug runs, instead of
+	// generating a new one every run. The private key is stored in the
+	// `ssh_keys` directory of the Packer cache. Defaults to `false`. Only
+	// the Amazon and VirtualBox builders reuse key pairs.
+	SSHTemporaryKeyPairReuse bool `mapstructure:"temporary_key_pair_reuse"`
 	// This overrides the value of ciphers supported by default by golang.
 	// The default value is [
 	//   "aes128-gcm@openssh.com",
@@ -98,14 +133,34 @@ type SSH struct {
 	// mostly cosmetic option, since Packer will delete the temporary private
 	// key from the host system regardless of whether this is set to true
 	// (unless the user has set the `-debug` flag). Defaults to "false";
-	// currently only works on guests with `sed` installed.
+	// currently only works on guests with `sed` installed. This is the
+	// `authorized_keys` task of [`guest_cleanup`](#guest_cleanup).
 	SSHClearAuthorizedKeys bool `mapstructure:"ssh_clear_authorized_keys"`
 	// If set, Packer will override the value of key exchange (kex) altorighms
 	// supported by default by golang. Acceptable values include:
+	// "mlkem768x25519-sha256", "curve25519-sha256",
 	// "curve25519-sha256@libssh.org", "ecdh-sha2-nistp256",
 	// "ecdh-sha2-nistp384", "ecdh-sha2-nistp521",
-	// "diffie-hellman-group14-sha1", and "diffie-hellman-group1-sha1".
+	// "diffie-hellman-group-exchange-sha256", "diffie-hellman-group16-sha512",
+	// "diffie-hellman-group14-sha256", "diffie-hellman-group14-sha1",
+	// "diffie-hellman-group-exchange-sha1", and "diffie-hellman-group1-sha1".
+	// The post-quantum `sntrup761x25519-sha512@openssh.com` algorithm isn't
+	// supported by the SSH library of Packer, the OpenSSH servers offering it
+	// also offer `mlkem768x25519-sha256` or `curve25519-sha256`.
 	SSHKEXAlgos []string `mapstructure:"ssh_key_exchange_algorithms"`
+	// A profile setting the ciphers, key exchange, MAC and host key
+	// algorithms negotiated with the SSH servers, including the bastion host,
+	// in one option: `fips` only allows FIPS 140-2 approved algorithms, for
+	// FedRAMP or FIPS constrained environments, `modern` the ones
+	// recommended by current OpenSSH releases and `legacy` every algorithm
+	// supported, for old guests. With `fips`, the guest must have an ECDSA or
+	// RSA host key. With `fips` and `modern`, RSA host keys are only verified
+	// with `rsa-sha2-512` and `rsa-sha2-256` signatures, not the SHA-1
+	// `ssh-rsa` ones. [`ssh_ciphers`](#ssh_ciphers) and
+	// [`ssh_key_exchange_algorithms`](#ssh_key_exchange_algorithms) can
+	// narrow the algorithms of the profile, and are rejected when they
+	// aren't allowed by it. Unset by default, to use the defaults of Go.
+	SSHSecurityPolicy string `mapstructure:"ssh_security_policy"`
 	// Path to a PEM encoded private key file to use to authenticate with SSH.
 	// The `~` can be used in path and will be expanded to the home directory
 	// of current user.
@@ -117,11 +172,18 @@ type SSH struct {
 	// If `true`, a PTY will be requested for the SSH connection. This defaults
 	// to `false`.
 	SSHPty bool `mapstructure:"ssh_pty"`
+	// How the output of the commands run in the PTY is rendered: `cooked`
+	// renders the lines rewritten with carriage returns and cursor
+	// movements, like progress bars, once, as a terminal shows them last,
+	// `strip` does so without the colors, and `raw` passes the output as
+	// is. Defaults to `cooked`, or to `strip` when the terminal of Packer
+	// doesn't render colors, like on Windows.
+	SSHPtyOutput string `mapstructure:"ssh_pty_output"`
 	// The time to wait for SSH to become available. Packer uses this to
 	// determine when the machine has booted so this is usually quite long.
 	// Example value: `10m`.
 	SSHTimeout     time.Duration `mapstructure:"ssh_timeout"`
-	SSHWaitTimeout time.Duration `mapstructure:"ssh_wait_timeout" undocumented:"true"`
+	SSHWaitTimeout time.Duration `mapstructure:"ssh_wait_timeout" undocumented:"true" deprecated:"ssh_timeout"`
 	// If true, the local SSH agent will be used to authenticate connections to
 	// the source instance. No temporary keypair will be created, and the
 	// values of [`ssh_password`](#ssh_password) and
@@ -134,6 +196,21 @@ type SSH struct {
 	// The number of handshakes to attempt with SSH once it can connect. This
 	// defaults to `10`.
 	SSHHandshakeAttempts int `mapstructure:"ssh_handshake_attempts"`
+	// The amount of time to wait for the SSH handshake, from the connection
+	// to the authentication, before trying again. Example value: `2m`.
+	// Defaults to `1m`.
+	SSHHandshakeTimeout time.Duration `mapstructure:"ssh_handshake_timeout"`
+	// The amount of time to wait for the SSH server to identify itself once
+	// connected, before trying again. Servers may send other lines, like a
+	// banner, before their identification. Example value: `30s`. Defaults
+	// to the [`ssh_handshake_timeout`](#ssh_handshake_timeout).
+	SSHBannerReadTimeout time.Duration `mapstructure:"ssh_banner_read_timeout"`
+	// The amount of time, from the first connection to the guest, during
+	// which authentication errors don't count against
+	// [`ssh_handshake_attempts`](#ssh_handshake_attempts), for guests that
+	// reject or throttle authentication until they are fully booted. Example
+	// value: `2m`. Defaults to `0s`.
+	SSHPreAuthGracePeriod time.Duration `mapstructure:"ssh_pre_auth_grace_period"`
 	// A bastion host to use for the actual SSH connection.
 	SSHBastionHost string `mapstructure:"ssh_bastion_host"`
 	// The port of the bastion host. Defaults to `22`.
@@ -147,6 +224,13 @@ type SSH struct {
 	SSHBastionPassword string `mapstructure:"ssh_bastion_password"`
 	// If `true`, the keyboard-interactive used to authenticate with bastion host.
 	SSHBastionInteractive bool `mapstructure:"ssh_bastion_interactive"`
+	// The TOTP secret of the bastion host, the base32 seed or
+	// `otpauth://totp/` URI of an authenticator app, for bastion hosts
+	// requiring a one-time password. The code is computed when the bastion
+	// host asks for it during keyboard-interactive authentication; the
+	// questions asking for a code are answered with it and the others with
+	// [`ssh_bastion_password`](#ssh_bastion_password).
+	SSHBastionTOTPSecret string `mapstructure:"ssh_bastion_totp_secret"`
 	// Path to a PEM encoded private key file to use to authenticate with the
 	// bastion host. The `~` can be used in path and will be expanded to the
 	// home directory of current user.
@@ -158,6 +242,11 @@ type SSH struct {
 	// `scp` or `sftp` - How to transfer files, Secure copy (default) or SSH
 	// File Transfer Protocol.
 	SSHFileTransferMethod string `mapstructure:"ssh_file_transfer_method"`
+	// The path of a static `sftp-server` binary for the guest, uploaded with
+	// scp and run as the SFTP server when `ssh_file_transfer_method` is `sftp`
+	// but the guest has no SFTP subsystem. Without it, or when it fails to
+	// run, Packer warns and transfers the files with scp instead.
+	SSHSftpServerBinary string `mapstructure:"ssh_sftp_server_binary"`
 	// A SOCKS proxy host to use for SSH connection
 	SSHProxyHost string `mapstructure:"ssh_proxy_host"`
 	// A port of the SOCKS proxy. Defaults to `1080`.
@@ -166,6 +255,69 @@ type SSH struct {
 	SSHProxyUsername string `mapstructure:"ssh_proxy_username"`
 	// The optional password to use to authenticate with the proxy server.
 	SSHProxyPassword string `mapstructure:"ssh_proxy_password"`
+	// An address Packer listens on for the guest to connect back to, like
+	// `0.0.0.0:2222`, for guests that can't be connected to, like guests
+	// behind NAT. Instead of connecting to the guest, Packer waits for it to
+	// open a reverse SSH tunnel to its SSH server through this address, and
+	// connects through the tunnel. The guest is configured to open the tunnel,
+	// with its user data or boot command, by running something like
+	// `ssh -N -R 22:localhost:22 -p 2222 -o StrictHostKeyChecking=no packer@<packer host>`
+	// in a loop. Requires
+	// [`ssh_callback_authorized_keys_file`](#ssh_callback_authorized_keys_file).
+	SSHCallbackAddress string `mapstructure:"ssh_callback_address"`
+	// Path to an `authorized_keys` file of the keys guests authenticate with
+	// to open their tunnel to
+	// [`ssh_callback_address`](#ssh_callback_address).
+	SSHCallbackAuthorizedKeysFile string `mapstructure:"ssh_callback_authorized_keys_file"`
+	// Path to a PEM encoded private host key for
+	// [`ssh_callback_address`](#ssh_callback_address). By default a host key
+	// is generated for every build.
+	SSHCallbackHostKeyFile string `mapstructure:"ssh_callback_host_key_file"`
+	// The address of a Teleport proxy, like `teleport.example.com:443`, to
+	// connect to the guest through, with `tsh proxy ssh`. The `tsh` command
+	// must be in the `PATH`. The guest is the Teleport node of
+	// [`ssh_host`](#ssh_host), or of the address of the guest when it isn't
+	// set.
+	SSHTeleportProxy string `mapstructure:"ssh_teleport_proxy"`
+	// The Teleport cluster of the guest. Defaults to the cluster of
+	// [`ssh_teleport_proxy`](#ssh_teleport_proxy).
+	SSHTeleportCluster string `mapstructure:"ssh_teleport_cluster"`
+	// Path to a Teleport identity file, exported with `tsh login --out`. It
+	// is used by `tsh` to connect to the proxy, and its certificate
+	// authenticates to the guest. By default, the current `tsh` login is
+	// used and the guest is authenticated like without Teleport, like with
+	// [`ssh_agent_auth`](#ssh_agent_auth) since `tsh` adds its keys to the
+	// SSH agent.
+	SSHTeleportIdentityFile string `mapstructure:"ssh_teleport_identity_file"`
+	// The ID of a HashiCorp Boundary target to connect to the guest through.
+	// Packer opens a session to the target with `boundary connect`: the
+	// `boundary` command must be in the `PATH` and authenticated, like with
+	// the `BOUNDARY_TOKEN` environment variable. The username and password
+	// or SSH private key credentials brokered by the session authenticate to
+	// the guest. Packer opens several connections to the guest: the session
+	// connection limit of the target must allow them.
+	SSHBoundaryTargetID string `mapstructure:"ssh_boundary_target_id"`
+	// The ID of the host of [`ssh_boundary_target_id`](#ssh_boundary_target_id)
+	// to connect to, when the target has several hosts.
+	SSHBoundaryHostID string `mapstructure:"ssh_boundary_host_id"`
+	// The address of the Boundary controller. Defaults to the
+	// `BOUNDARY_ADDR` environment variable.
+	SSHBoundaryAddr string `mapstructure:"ssh_boundary_addr"`
+	// The `wss://` URL of a WebSocket endpoint to connect to the guest
+	// through, like an application gateway when only HTTPS egress is
+	// allowed. The endpoint forwards the binary messages of the WebSocket to
+	// the SSH port of the guest. `{host}` and `{port}` in the URL are
+	// replaced with the address and port of the guest, like
+	// `wss://gateway.example.com/ssh?host={host}&port={port}`. The proxy of
+	// the `HTTPS_PROXY` environment variable is used.
+	SSHWebSocketURL string `mapstructure:"ssh_websocket_url"`
+	// A bearer token sent in the `Authorization` header of the WebSocket
+	// handshake with [`ssh_websocket_url`](#ssh_websocket_url). It requires a
+	// `wss://` URL.
+	SSHWebSocketToken string `mapstructure:"ssh_websocket_token"`
+	// Don't verify the TLS certificate of
+	// [`ssh_websocket_url`](#ssh_websocket_url).
+	SSHWebSocketInsecureSkipTLSVerify bool `mapstructure:"ssh_websocket_insecure_skip_tls_verify"`
 	// How often to send "keep alive" messages to the server. Set to a negative
 	// value (`-1s`) to disable. Example value: `10s`. Defaults to `5s`.
 	SSHKeepAliveInterval time.Duration `mapstructure:"ssh_keep_alive_interval"`
@@ -218,7 +370,39 @@ type WinRM struct {
 	// requirement for basic authentication to be enabled within the target
 	// guest. Further reading for remote connection authentication can be found
 	// [here](https://msdn.microsoft.com/en-us/library/aa384295(v=vs.85).aspx).
-	WinRMUseNTLM            bool `mapstructure:"winrm_use_ntlm"`
+	WinRMUseNTLM bool `mapstructure:"winrm_use_ntlm"`
+	// If `true`, run the commands in PowerShell Remoting Protocol (PSRP)
+	// pipelines rather than in winrs shells. The output is streamed as the
+	// commands write it and long running scripts aren't subject to the
+	// limits of the shells. Requires PowerShell 5 or later on the guest. The
+	// files are still uploaded and downloaded with winrs shells.
+	WinRMUsePSRP bool `mapstructure:"winrm_use_psrp"`
+	// A bastion host to tunnel the WinRM connection through, for guests
+	// that can't be connected to directly, like in private subnets. The
+	// WinRM HTTP(S) connections are forwarded by the bastion SSH server.
+	WinRMBastionHost string `mapstructure:"winrm_bastion_host"`
+	// The port of the bastion host. Defaults to `22`.
+	WinRMBastionPort int `mapstructure:"winrm_bastion_port"`
+	// The username to connect to the bastion host.
+	WinRMBastionUsername string `mapstructure:"winrm_bastion_username"`
+	// The password to use to authenticate with the bastion host.
+	WinRMBastionPassword string `mapstructure:"winrm_bastion_password"`
+	// Path to a PEM encoded private key file to use to authenticate with the
+	// bastion host. The `~` can be used in path and will be expanded to the
+	// home directory of current user.
+	WinRMBastionPrivateKeyFile string `mapstructure:"winrm_bastion_private_key_file"`
+	// If `true`, the local SSH agent will be used to authenticate with the
+	// bastion host. Defaults to `false`.
+	WinRMBastionAgentAuth bool `mapstructure:"winrm_bastion_agent_auth"`
+	// A SOCKS proxy host to tunnel the WinRM connection through, like one
+	// opened with `ssh -D`.
+	WinRMProxyHost string `mapstructure:"winrm_proxy_host"`
+	// The port of the SOCKS proxy. Defaults to `1080`.
+	WinRMProxyPort int `mapstructure:"winrm_proxy_port"`
+	// The optional username to authenticate with the proxy server.
+	WinRMProxyUsername string `mapstructure:"winrm_proxy_username"`
+	// The optional password to use to authenticate with the proxy server.
+	WinRMProxyPassword      string `mapstructure:"winrm_proxy_password"`
 	WinRMTransportDecorator func() winrm.Transporter
 }
 
@@ -278,6 +462,66 @@ func (c *Config) ReadSSHPrivateKeyFile() ([]byte, error) {
 	return privateKey, nil
 }
 
+func (c *Config) readSSHCallbackFile(path string) ([]byte, error) {
+	path, err := packer.ExpandUser(path)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+// SSHCallbackServer starts the server guests connect back to when
+// ssh_callback_address is set, and returns nil otherwise.
+func (c *Config) SSHCallbackServer() (*packerssh.CallbackServer, error) {
+	if c.SSHCallbackAddress == "" {
+		return nil, nil
+	}
+	authorizedKeys, err := c.readSSHCallbackFile(c.SSHCallbackAuthorizedKeysFile)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading ssh_callback_authorized_keys_file: %s", err)
+	}
+	var hostKey []byte
+	if c.SSHCallbackHostKeyFile != "" {
+		hostKey, err = c.readSSHCallbackFile(c.SSHCallbackHostKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading ssh_callback_host_key_file: %s", err)
+		}
+	}
+	return packerssh.NewCallbackServer(c.SSHCallbackAddress, authorizedKeys, hostKey)
+}
+
+// SharedTemporaryKeyPair returns the temporary key pair the build named
+// buildName shares with other builds, or nil when it generates its own:
+// the key pair of the run with temporary_key_pair_shared, and the key pair
+// kept across debug runs with temporary_key_pair_reuse.
+func (c *Config) SharedTemporaryKeyPair(buildName string, debug bool) (*helperssh.SharedKeyPair, error) {
+	reuse := c.SSHTemporaryKeyPairReuse && debug
+	shared := c.SSHTemporaryKeyPairShared && helperssh.RunKeyPairDir() != ""
+	kp := &helperssh.SharedKeyPair{
+		// RSA keys are the ones every cloud can import.
+		Config: helperssh.CreateKeyPairConfig{Type: helperssh.Rsa},
+	}
+	switch {
+	case reuse:
+		dir, err := packer.CachePath("ssh_keys")
+		if err != nil {
+			return nil, err
+		}
+		kp.Dir = dir
+		kp.Name = "packer_" + buildName
+		if shared {
+			kp.Name = "packer_shared"
+		}
+		kp.Persist = true
+	case shared:
+		kp.Dir = helperssh.RunKeyPairDir()
+		kp.Name = "packer_" + os.Getenv("PACKER_RUN_UUID")
+	default:
+		return nil, nil
+	}
+	return kp, nil
+}
+
 // SSHConfigFunc returns a function that can be used for the SSH communicator
 // config for connecting to the instance created over SSH using the private key
 // or password.
@@ -286,14 +530,12 @@ func (c *Config) SSHConfigFunc() func(multistep.StateBag) (*ssh.ClientConfig, er
 		sshConfig := &ssh.ClientConfig{
 			User:            c.SSHUsername,
 			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			BannerCallback: func(message string) error {
+				log.Printf("[INFO] SSH banner: %s", message)
+				return nil
+			},
 		}
-		if len(c.SSHCiphers) != 0 {
-			sshConfig.Config.Ciphers = c.SSHCiphers
-		}
-
-		if len(c.SSHKEXAlgos) != 0 {
-			sshConfig.Config.KeyExchanges = c.SSHKEXAlgos
-		}
+		c.configureSSHAlgorithms(sshConfig)
 
 		if c.SSHAgentAuth {
 			authSock := os.Getenv("SSH_AUTH_SOCK")
@@ -353,6 +595,14 @@ func (c *Config) SSHConfigFunc() func(multistep.StateBag) (*ssh.ClientConfig, er
 			sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeys(signer))
 		}
 
+		if c.SSHTeleportIdentityFile != "" {
+			signer, err := c.teleportSigner()
+			if err != nil {
+				return nil, err
+			}
+			sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeys(signer))
+		}
+
 		if c.SSHPassword != "" {
 			sshConfig.Auth = append(sshConfig.Auth,
 				ssh.Password(c.SSHPassword),
@@ -452,6 +702,10 @@ func (c *Config) prepareSSH(ctx *interpolate.Context) []error {
 		c.SSHHandshakeAttempts = 10
 	}
 
+	if c.SSHHandshakeTimeout == 0 {
+		c.SSHHandshakeTimeout = 1 * time.Minute
+	}
+
 	if c.SSHBastionHost != "" {
 		if c.SSHBastionPort == 0 {
 			c.SSHBastionPort = 22
@@ -477,6 +731,13 @@ func (c *Config) prepareSSH(ctx *interpolate.Context) []error {
 		c.SSHFileTransferMethod = "scp"
 	}
 
+	if c.SSHPtyOutput == "" {
+		c.SSHPtyOutput = packerssh.PtyOutputCooked
+		if !packer.SupportsColors() {
+			c.SSHPtyOutput = packerssh.PtyOutputStrip
+		}
+	}
+
 	// Backwards compatibility
 	if c.SSHWaitTimeout != 0 {
 		c.SSHTimeout = c.SSHWaitTimeout
@@ -517,9 +778,9 @@ func (c *Config) prepareSSH(ctx *interpolate.Context) []error {
 	}
 
 	if c.SSHBastionHost != "" && !c.SSHBastionAgentAuth {
-		if c.SSHBastionPassword == "" && c.SSHBastionPrivateKeyFile == "" {
+		if c.SSHBastionPassword == "" && c.SSHBastionPrivateKeyFile == "" && c.SSHBastionTOTPSecret == "" {
 			errs = append(errs, errors.New(
-				"ssh_bastion_password or ssh_bastion_private_key_file must be specified"))
+				"ssh_bastion_password, ssh_bastion_private_key_file or ssh_bastion_totp_secret must be specified"))
 		} else if c.SSHBastionPrivateKeyFile != "" {
 			path, err := packer.ExpandUser(c.SSHBastionPrivateKeyFile)
 			if err != nil {
@@ -548,16 +809,62 @@ func (c *Config) prepareSSH(ctx *interpolate.Context) []error {
 		}
 	}
 
+	if c.SSHBastionTOTPSecret != "" {
+		if _, err := totp.Parse(c.SSHBastionTOTPSecret); err != nil {
+			errs = append(errs, fmt.Errorf("ssh_bastion_totp_secret is invalid: %s", err))
+		}
+	}
+
 	if c.SSHFileTransferMethod != "scp" && c.SSHFileTransferMethod != "sftp" {
 		errs = append(errs, fmt.Errorf(
 			"ssh_file_transfer_method ('%s') is invalid, valid methods: sftp, scp",
 			c.SSHFileTransferMethod))
 	}
 
+	if c.SSHSftpServerBinary != "" {
+		if c.SSHFileTransferMethod != "sftp" {
+			errs = append(errs, errors.New(
+				"ssh_sftp_server_binary requires ssh_file_transfer_method to be sftp"))
+		} else if _, err := os.Stat(c.SSHSftpServerBinary); err != nil {
+			errs = append(errs, fmt.Errorf(
+				"ssh_sftp_server_binary is invalid: %s", err))
+		}
+	}
+
+	switch c.SSHPtyOutput {
+	case packerssh.PtyOutputRaw, packerssh.PtyOutputCooked, packerssh.PtyOutputStrip:
+	default:
+		errs = append(errs, fmt.Errorf(
+			"ssh_pty_output ('%s') is invalid, valid modes: %s",
+			c.SSHPtyOutput, strings.Join(packerssh.PtyOutputs, ", ")))
+	}
+
 	if c.SSHBastionHost != "" && c.SSHProxyHost != "" {
 		errs = append(errs, errors.New("please specify either ssh_bastion_host or ssh_proxy_host, not both"))
 	}
 
+	if c.SSHCallbackAddress != "" {
+		if c.SSHBastionHost != "" || c.SSHProxyHost != "" {
+			errs = append(errs, errors.New("ssh_callback_address can't be used with ssh_bastion_host or ssh_proxy_host"))
+		}
+		if _, _, err := net.SplitHostPort(c.SSHCallbackAddress); err != nil {
+			errs = append(errs, fmt.Errorf("ssh_callback_address is invalid: %s", err))
+		}
+		if c.SSHCallbackAuthorizedKeysFile == "" {
+			errs = append(errs, errors.New("ssh_callback_authorized_keys_file must be specified with ssh_callback_address"))
+		} else if _, err := c.readSSHCallbackFile(c.SSHCallbackAuthorizedKeysFile); err != nil {
+			errs = append(errs, fmt.Errorf("ssh_callback_authorized_keys_file is invalid: %s", err))
+		}
+		if c.SSHCallbackHostKeyFile != "" {
+			if _, err := c.readSSHCallbackFile(c.SSHCallbackHostKeyFile); err != nil {
+				errs = append(errs, fmt.Errorf("ssh_callback_host_key_file is invalid: %s", err))
+			}
+		}
+	}
+
+	errs = append(errs, c.prepareSSHSecurityPolicy()...)
+	errs = append(errs, c.prepareSSHGateways()...)
+
 	for _, v := range c.SSHLocalTunnels {
 		_, err := helperssh.ParseTunnelArgument(v, packerssh.UnsetTunnel)
 		if err != nil {
@@ -592,6 +899,8 @@ func (c *Config) prepareWinRM(ctx *interpolate.Context) (errs []error) {
 		c.WinRMTransportDecorator = func() winrm.Transporter { return &winrm.ClientNTLM{} }
 	}
 
+	errs = append(errs, c.prepareWinRMTunnel()...)
+
 	if c.WinRMUser == "" {
 		errs = append(errs, errors.New("winrm_username must be specified."))
 	}
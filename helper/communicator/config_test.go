@@ -1,9 +1,12 @@
 package communicator
 
 import (
+	"io/ioutil"
+	"os"
 	"reflect"
 	"testing"
 
+	packerssh "github.com/hashicorp/packer/communicator/ssh"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/template/interpolate"
 	"github.com/masterzen/winrm"
@@ -139,6 +142,61 @@ func TestConfig_winrm_use_ntlm(t *testing.T) {
 
 }
 
+func TestConfig_winrm_bastion(t *testing.T) {
+	c := &Config{
+		Type: "winrm",
+		WinRM: WinRM{
+			WinRMUser:        "admin",
+			WinRMBastionHost: "mybastionhost.company.com",
+		},
+	}
+	if err := c.Prepare(testContext(t)); len(err) != 2 {
+		t.Fatalf("a bastion without credentials should fail: %#v", err)
+	}
+
+	c.WinRMBastionUsername = "root"
+	c.WinRMBastionPassword = "test"
+	c.WinRMUseNTLM = true
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+	if c.WinRMBastionPort != 22 {
+		t.Fatalf("bad bastion port: %d", c.WinRMBastionPort)
+	}
+	if _, ok := c.WinRMTransportDecorator().(*winrm.ClientNTLM); !ok {
+		t.Fatalf("WinRMTransportDecorator isn't ClientNTLM.")
+	}
+
+	c.WinRMProxyHost = "localhost"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("a bastion with a proxy should fail: %#v", err)
+	}
+}
+
+func TestConfig_winrm_proxy(t *testing.T) {
+	c := &Config{
+		Type: "winrm",
+		WinRM: WinRM{
+			WinRMUser:      "admin",
+			WinRMProxyHost: "localhost",
+		},
+	}
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+	if c.WinRMProxyPort != 1080 {
+		t.Fatalf("bad proxy port: %d", c.WinRMProxyPort)
+	}
+	if c.WinRMTransportDecorator == nil {
+		t.Fatalf("WinRMTransportDecorator not set.")
+	}
+
+	c.WinRMNoProxy = true
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("a proxy with winrm_no_proxy should fail: %#v", err)
+	}
+}
+
 func TestSSHBastion(t *testing.T) {
 	c := &Config{
 		Type: "ssh",
@@ -163,6 +221,207 @@ func TestSSHBastion(t *testing.T) {
 
 }
 
+func TestSSHBastionTOTPSecret(t *testing.T) {
+	c := &Config{
+		Type: "ssh",
+		SSH: SSH{
+			SSHUsername:          "root",
+			SSHBastionHost:       "mybastionhost.company.com",
+			SSHBastionTOTPSecret: "JBSWY3DPEHPK3PXP",
+		},
+	}
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	c.SSHBastionTOTPSecret = "not base32!"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("an invalid TOTP secret should be an error, got: %#v", err)
+	}
+}
+
+func TestSSHPtyOutput(t *testing.T) {
+	defer os.Setenv("PACKER_NO_COLOR", os.Getenv("PACKER_NO_COLOR"))
+	os.Setenv("PACKER_NO_COLOR", "1")
+
+	c := testConfig()
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+	if c.SSHPtyOutput != packerssh.PtyOutputStrip {
+		t.Fatalf("the colors should be stripped without colored output, got %q", c.SSHPtyOutput)
+	}
+
+	c = testConfig()
+	c.SSHPtyOutput = "cooked"
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	c = testConfig()
+	c.SSHPtyOutput = "bad"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("bad: %#v", err)
+	}
+}
+
+func TestSSHSftpServerBinary(t *testing.T) {
+	c := testConfig()
+	c.SSHFileTransferMethod = "sftp"
+	c.SSHSftpServerBinary = "config_test.go"
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	c = testConfig()
+	c.SSHSftpServerBinary = "config_test.go"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("the binary should require sftp: %#v", err)
+	}
+
+	c = testConfig()
+	c.SSHFileTransferMethod = "sftp"
+	c.SSHSftpServerBinary = "missing-sftp-server"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("the binary should exist: %#v", err)
+	}
+}
+
+func TestSSHCallback(t *testing.T) {
+	c := &Config{
+		Type: "ssh",
+		SSH: SSH{
+			SSHUsername:        "root",
+			SSHCallbackAddress: "127.0.0.1:0",
+		},
+	}
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("callback without authorized keys should fail: %#v", err)
+	}
+
+	f, err := ioutil.TempFile("", "packer-authorized-keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHJkw7ExgGVHuHoYTDnQgnwXELQdg8aJYk0fGYmXp8aD guest\n")
+	f.Close()
+
+	c.SSHCallbackAuthorizedKeysFile = f.Name()
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+	server, err := c.SSHCallbackServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	c.SSHBastionHost = "mybastionhost.company.com"
+	c.SSHBastionPassword = "test"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("callback with a bastion should fail: %#v", err)
+	}
+}
+
+func TestSSHTeleport(t *testing.T) {
+	c := &Config{
+		Type: "ssh",
+		SSH: SSH{
+			SSHUsername:        "root",
+			SSHPassword:        "test",
+			SSHTeleportCluster: "leaf",
+		},
+	}
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("a cluster without a proxy should fail: %#v", err)
+	}
+
+	c.SSHTeleportProxy = "teleport.example.com:443"
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+	args, err := c.teleportArgs("node1", 22)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"proxy", "ssh", "--proxy=teleport.example.com:443", "--cluster=leaf", "root@node1:22"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("bad args: %#v", args)
+	}
+
+	c.SSHTeleportIdentityFile = "/does/not/exist"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("a missing identity file should fail: %#v", err)
+	}
+
+	c.SSHTeleportIdentityFile = ""
+	c.SSHBastionHost = "mybastionhost.company.com"
+	c.SSHBastionPassword = "test"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("teleport with a bastion should fail: %#v", err)
+	}
+}
+
+func TestSSHBoundary(t *testing.T) {
+	c := &Config{
+		Type: "ssh",
+		SSH: SSH{
+			SSHUsername:       "root",
+			SSHBoundaryHostID: "hst_1234567890",
+		},
+	}
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("a host without a target should fail: %#v", err)
+	}
+
+	c.SSHBoundaryTargetID = "ttcp_1234567890"
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	c.SSHTeleportProxy = "teleport.example.com:443"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("boundary with teleport should fail: %#v", err)
+	}
+}
+
+func TestSSHWebSocket(t *testing.T) {
+	c := &Config{
+		Type: "ssh",
+		SSH: SSH{
+			SSHUsername:       "root",
+			SSHPassword:       "test",
+			SSHWebSocketToken: "secret",
+		},
+	}
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("a token without a URL should fail: %#v", err)
+	}
+
+	c.SSHWebSocketURL = "ws://gateway.example.com/ssh"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("a token over ws:// should fail: %#v", err)
+	}
+
+	c.SSHWebSocketURL = "wss://gateway.example.com/ssh?host={host}&port={port}"
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+	if u := c.webSocketURL("10.0.0.5", 2222); u != "wss://gateway.example.com/ssh?host=10.0.0.5&port=2222" {
+		t.Fatalf("bad URL: %s", u)
+	}
+	if h := c.webSocketHeader().Get("Authorization"); h != "Bearer secret" {
+		t.Fatalf("bad Authorization header: %s", h)
+	}
+
+	c.SSHBastionHost = "mybastionhost.company.com"
+	c.SSHBastionPassword = "test"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("a WebSocket with a bastion should fail: %#v", err)
+	}
+}
+
 func TestSSHConfigFunc_ciphers(t *testing.T) {
 	state := new(multistep.BasicStateBag)
 
@@ -0,0 +1,205 @@
+package communicator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// ConnectionStateKey is the key of the Connection in the state bag.
+const ConnectionStateKey = "connection"
+
+// ErrConnectionClosed is returned by the operations of a closed Connection.
+var ErrConnectionClosed = errors.New("the connection to the guest is closed")
+
+// ErrTunnelUnsupported is returned by Connection.Tunnel when the
+// communicator can't open connections from the guest.
+var ErrTunnelUnsupported = errors.New("the communicator can't open tunnels")
+
+// ErrPasswordUnsupported is returned by Connection.SetPassword when the
+// communicator doesn't authenticate with the password of the user for every
+// operation.
+var ErrPasswordUnsupported = errors.New("the communicator can't switch passwords")
+
+// tunneler is implemented by the communicators that can open connections
+// from the guest, like the SSH communicator.
+type tunneler interface {
+	Dial(network, addr string) (net.Conn, error)
+}
+
+// reconnecter is implemented by the communicators holding a connection to
+// the guest, like the SSH communicator.
+type reconnecter interface {
+	Reconnect() error
+}
+
+// passwordSetter is implemented by the communicators authenticating every
+// operation with the password of the user, like the WinRM communicator.
+type passwordSetter interface {
+	SetPassword(password string) error
+}
+
+// Connection is the handle of the connection to the guest established by
+// StepConnect, stored in the state bag under ConnectionStateKey. Builders
+// use it to operate on the guest during the build, whatever the type of the
+// communicator.
+//
+// A Connection is reference counted: the step that connected holds a
+// reference until it is cleaned up, and the steps keeping the connection
+// after that Acquire their own reference and Close it once done. The
+// communicator is closed with the last reference.
+type Connection struct {
+	// Type is the type of the communicator: ssh, winrm or a custom type.
+	Type string
+
+	// comm runs the commands with the settings of the configuration, and
+	// raw is the underlying communicator.
+	comm packer.Communicator
+	raw  packer.Communicator
+
+	m    sync.Mutex
+	refs int
+}
+
+// NewConnection returns a handle of the connection of raw, operating on the
+// guest with comm, that has one reference.
+func NewConnection(typ string, comm, raw packer.Communicator) *Connection {
+	return &Connection{Type: typ, comm: comm, raw: raw, refs: 1}
+}
+
+// ConnectionFromState returns the Connection of the state bag, or nil.
+func ConnectionFromState(state multistep.StateBag) *Connection {
+	c, _ := state.Get(ConnectionStateKey).(*Connection)
+	return c
+}
+
+// Communicator returns the communicator of the connection.
+func (c *Connection) Communicator() packer.Communicator {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.comm
+}
+
+// setCommunicator replaces the communicator of the connection, like with one
+// wrapping it.
+func (c *Connection) setCommunicator(comm packer.Communicator) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.comm = comm
+}
+
+// Acquire adds a reference to the connection, released with Close, and
+// returns it. It fails when the connection is closed.
+func (c *Connection) Acquire() (*Connection, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.refs == 0 {
+		return nil, ErrConnectionClosed
+	}
+	c.refs++
+	return c, nil
+}
+
+// Close releases a reference to the connection, closing the communicator
+// with the last one.
+func (c *Connection) Close() error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.refs == 0 {
+		return ErrConnectionClosed
+	}
+	c.refs--
+	if c.refs > 0 {
+		return nil
+	}
+	log.Printf("[INFO] Closing the %s connection", c.Type)
+	if closer, ok := c.raw.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// open fails when the connection is closed.
+func (c *Connection) open() error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.refs == 0 {
+		return ErrConnectionClosed
+	}
+	return nil
+}
+
+// Exec runs command on the guest, writing its output to stdout and stderr,
+// that can be nil, and returns its exit status.
+func (c *Connection) Exec(ctx context.Context, command string, stdout, stderr io.Writer) (int, error) {
+	if err := c.open(); err != nil {
+		return 0, err
+	}
+	cmd := &packer.RemoteCmd{Command: command, Stdout: stdout, Stderr: stderr}
+	if err := c.Communicator().Start(ctx, cmd); err != nil {
+		return 0, err
+	}
+	return cmd.Wait(), nil
+}
+
+// Upload uploads the content of r to the path dst of the guest.
+func (c *Connection) Upload(dst string, r io.Reader) error {
+	if err := c.open(); err != nil {
+		return err
+	}
+	return c.Communicator().Upload(dst, r, nil)
+}
+
+// Tunnel opens a connection to addr from the guest, like to reach a service
+// only listening on the guest. It fails with ErrTunnelUnsupported when the
+// communicator can't open tunnels.
+func (c *Connection) Tunnel(network, addr string) (net.Conn, error) {
+	if err := c.open(); err != nil {
+		return nil, err
+	}
+	t, ok := c.raw.(tunneler)
+	if !ok {
+		return nil, ErrTunnelUnsupported
+	}
+	conn, err := t.Dial(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not open a tunnel to %s: %s", addr, err)
+	}
+	return conn, nil
+}
+
+// Reconnect closes the connection to the guest and connects again, like
+// after the guest rebooted. The communicators that don't hold a connection,
+// like WinRM, connect for every operation anyway.
+func (c *Connection) Reconnect() error {
+	if err := c.open(); err != nil {
+		return err
+	}
+	if r, ok := c.raw.(reconnecter); ok {
+		log.Printf("[INFO] Reconnecting the %s connection", c.Type)
+		return r.Reconnect()
+	}
+	return nil
+}
+
+// SetPassword switches the communicator to password, once the password of
+// the user was changed on the guest. It fails with ErrPasswordUnsupported
+// when the communicator authenticates once, like SSH, and keeps its
+// connection.
+func (c *Connection) SetPassword(password string) error {
+	if err := c.open(); err != nil {
+		return err
+	}
+	p, ok := c.raw.(passwordSetter)
+	if !ok {
+		return ErrPasswordUnsupported
+	}
+	return p.SetPassword(password)
+}
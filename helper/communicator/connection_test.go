@@ -0,0 +1,149 @@
+package communicator
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// testConnComm is a communicator holding a connection to the guest.
+type testConnComm struct {
+	packer.MockCommunicator
+	dialed     []string
+	reconnects int
+	closed     bool
+}
+
+func (c *testConnComm) Dial(network, addr string) (net.Conn, error) {
+	c.dialed = append(c.dialed, addr)
+	conn, _ := net.Pipe()
+	return conn, nil
+}
+
+func (c *testConnComm) Reconnect() error {
+	c.reconnects++
+	return nil
+}
+
+func (c *testConnComm) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestConnection(t *testing.T) {
+	raw := &testConnComm{MockCommunicator: packer.MockCommunicator{StartStdout: "hello", StartExitStatus: 3}}
+	conn := NewConnection("ssh", raw, raw)
+
+	var stdout bytes.Buffer
+	status, err := conn.Exec(context.Background(), "echo hello", &stdout, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != 3 || stdout.String() != "hello" || raw.StartCmd.Command != "echo hello" {
+		t.Fatalf("unexpected exec: %d %q %q", status, stdout.String(), raw.StartCmd.Command)
+	}
+	if err := conn.Upload("/tmp/file", strings.NewReader("data")); err != nil {
+		t.Fatal(err)
+	}
+	if raw.UploadPath != "/tmp/file" || raw.UploadData != "data" {
+		t.Fatalf("unexpected upload of %q to %s", raw.UploadData, raw.UploadPath)
+	}
+	tunnel, err := conn.Tunnel("tcp", "127.0.0.1:5432")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tunnel.Close()
+	if len(raw.dialed) != 1 || raw.dialed[0] != "127.0.0.1:5432" {
+		t.Fatalf("unexpected tunnels: %v", raw.dialed)
+	}
+	if err := conn.Reconnect(); err != nil || raw.reconnects != 1 {
+		t.Fatalf("should reconnect: %v", err)
+	}
+
+	if _, err := conn.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Close(); err != nil || raw.closed {
+		t.Fatalf("a referenced connection shouldn't be closed: %v", err)
+	}
+	if err := conn.Close(); err != nil || !raw.closed {
+		t.Fatalf("the connection should be closed: %v", err)
+	}
+	if _, err := conn.Exec(context.Background(), "true", nil, nil); err != ErrConnectionClosed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := conn.Acquire(); err != ErrConnectionClosed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestConnection_tunnelUnsupported(t *testing.T) {
+	comm := new(packer.MockCommunicator)
+	conn := NewConnection("winrm", comm, comm)
+	if _, err := conn.Tunnel("tcp", "127.0.0.1:5432"); err != ErrTunnelUnsupported {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := conn.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.SetPassword("rotated"); err != ErrPasswordUnsupported {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// testConnectStep is a custom connect step.
+type testConnectStep struct {
+	comm packer.Communicator
+	runs int
+}
+
+func (s *testConnectStep) Run(_ context.Context, state multistep.StateBag) multistep.StepAction {
+	s.runs++
+	state.Put("communicator", s.comm)
+	return multistep.ActionContinue
+}
+
+func (s *testConnectStep) Cleanup(multistep.StateBag) {}
+
+func TestStepConnect_resume(t *testing.T) {
+	state := testState(t)
+	raw := new(testConnComm)
+	connect := &testConnectStep{comm: raw}
+	config := &Config{Type: "custom", DisableGuestFacts: true}
+
+	host := func(multistep.StateBag) (string, error) { return "10.0.0.1", nil }
+	step := &StepConnect{Config: config, Host: host, CustomConnect: map[string]multistep.Step{"custom": connect}}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	conn := ConnectionFromState(state)
+	if conn == nil || conn.Communicator() != raw {
+		t.Fatalf("unexpected connection %#v", conn)
+	}
+	// A step keeps the connection.
+	if _, err := conn.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	step.Cleanup(state)
+	if raw.closed {
+		t.Fatal("the connection is still referenced")
+	}
+
+	step = &StepConnect{Config: config, Host: host, CustomConnect: map[string]multistep.Step{"custom": connect}}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if connect.runs != 1 || raw.reconnects != 1 {
+		t.Fatalf("the connection should be resumed: %d runs, %d reconnects", connect.runs, raw.reconnects)
+	}
+	step.Cleanup(state)
+	conn.Close()
+	if !raw.closed {
+		t.Fatal("the connection should be closed")
+	}
+}
@@ -0,0 +1,159 @@
+package communicator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/packer/common/shellquote"
+	"github.com/hashicorp/packer/packer"
+	"github.com/masterzen/winrm"
+)
+
+// GuestFacts describe the OS of the guest, as detected once connected.
+// Unknown facts are empty.
+type GuestFacts struct {
+	// OSFamily is the kernel of the guest, lower-cased: linux, windows,
+	// darwin, freebsd...
+	OSFamily string
+	// OSName is the distribution of the guest, like the ID of os-release
+	// on Linux, or the caption of the OS on Windows.
+	OSName    string
+	OSVersion string
+	// OSArch is the machine hardware name, as output by uname -m: x86_64,
+	// aarch64...
+	OSArch string
+	// InitSystem is the service manager of the guest: systemd, upstart,
+	// openrc, sysvinit, launchd or windows.
+	InitSystem string
+	// PackageManager is the first package manager found on the guest: apt,
+	// dnf, yum, zypper, apk, pacman, pkg, brew, choco or winget.
+	PackageManager string
+}
+
+// guestFactsScript outputs the facts of a Unix guest as key=value lines.
+const guestFactsScript = `os=$(uname -s | tr '[:upper:]' '[:lower:]')
+echo "OSFamily=$os"
+echo "OSArch=$(uname -m)"
+if [ -r /etc/os-release ]; then
+  (. /etc/os-release; echo "OSName=$ID"; echo "OSVersion=$VERSION_ID")
+elif [ "$os" = darwin ]; then
+  echo "OSName=macos"; echo "OSVersion=$(sw_vers -productVersion)"
+else
+  echo "OSName=$os"; echo "OSVersion=$(uname -r)"
+fi
+if [ -d /run/systemd/system ]; then echo "InitSystem=systemd"
+elif [ "$os" = darwin ]; then echo "InitSystem=launchd"
+elif command -v openrc >/dev/null 2>&1; then echo "InitSystem=openrc"
+elif /sbin/initctl version 2>/dev/null | grep -q upstart; then echo "InitSystem=upstart"
+elif [ -d /etc/init.d ] || [ -d /etc/rc.d ]; then echo "InitSystem=sysvinit"
+fi
+for pm in apt-get dnf yum zypper apk pacman pkg brew; do
+  if command -v $pm >/dev/null 2>&1; then echo "PackageManager=${pm%-get}"; break; fi
+done
+`
+
+// guestFactsPowershell outputs the facts of a Windows guest as key=value
+// lines.
+const guestFactsPowershell = `$os = Get-CimInstance Win32_OperatingSystem
+"OSFamily=windows"
+"OSName=" + $os.Caption
+"OSVersion=" + $os.Version
+"OSArch=" + $env:PROCESSOR_ARCHITECTURE
+"InitSystem=windows"
+foreach ($pm in "choco", "winget") {
+  if (Get-Command $pm -ErrorAction SilentlyContinue) { "PackageManager=" + $pm; break }
+}
+`
+
+// GuestOS returns the OS of the guest, linux or windows, as seen from the
+// communicator, or an empty string when the communicator is neither SSH nor
+// WinRM.
+func (c *Config) GuestOS() string {
+	switch c.Type {
+	case "ssh":
+		return "linux"
+	case "winrm":
+		return "windows"
+	default:
+		return ""
+	}
+}
+
+// DetectGuestFacts runs a detection script on the guest, depending on the
+// OS as seen from the communicator, and returns the facts it found.
+func DetectGuestFacts(ctx context.Context, comm packer.Communicator, guestOS string) (*GuestFacts, error) {
+	var command string
+	switch guestOS {
+	case "linux":
+		command = "sh -c " + shellquote.Quote(guestFactsScript)
+	case "windows":
+		command = winrm.Powershell(guestFactsPowershell)
+	default:
+		return nil, fmt.Errorf("can't detect the facts of a %q guest", guestOS)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := &packer.RemoteCmd{Command: command, Stdout: &stdout, Stderr: &stderr}
+	if err := comm.Start(ctx, cmd); err != nil {
+		return nil, err
+	}
+	if status := cmd.Wait(); status != 0 {
+		return nil, fmt.Errorf("the detection exited with status %d: %s", status, strings.TrimSpace(stderr.String()))
+	}
+	return parseGuestFacts(stdout.String()), nil
+}
+
+// parseGuestFacts parses the key=value lines output by the detection
+// scripts.
+func parseGuestFacts(out string) *GuestFacts {
+	facts := new(GuestFacts)
+	fields := map[string]*string{
+		"OSFamily":       &facts.OSFamily,
+		"OSName":         &facts.OSName,
+		"OSVersion":      &facts.OSVersion,
+		"OSArch":         &facts.OSArch,
+		"InitSystem":     &facts.InitSystem,
+		"PackageManager": &facts.PackageManager,
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		kv := strings.SplitN(strings.TrimSpace(scanner.Text()), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		if field, ok := fields[kv[0]]; ok {
+			*field = strings.TrimSpace(kv[1])
+		}
+	}
+
+	// Follow uname for the architectures reported by Windows.
+	switch strings.ToUpper(facts.OSArch) {
+	case "AMD64":
+		facts.OSArch = "x86_64"
+	case "ARM64":
+		facts.OSArch = "aarch64"
+	case "X86":
+		facts.OSArch = "i686"
+	}
+	return facts
+}
+
+// GeneratedData returns the facts as generated data, available to the
+// provisioners as build variables. Every key is set, even when f is nil, so
+// that templates can test for unknown facts.
+func (f *GuestFacts) GeneratedData() map[string]string {
+	if f == nil {
+		f = new(GuestFacts)
+	}
+	return map[string]string{
+		"OSFamily":       f.OSFamily,
+		"OSName":         f.OSName,
+		"OSVersion":      f.OSVersion,
+		"OSArch":         f.OSArch,
+		"InitSystem":     f.InitSystem,
+		"PackageManager": f.PackageManager,
+	}
+}
@@ -0,0 +1,65 @@
+package communicator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestParseGuestFacts(t *testing.T) {
+	cases := []struct {
+		out      string
+		expected GuestFacts
+	}{
+		{
+			"OSFamily=linux\nOSArch=x86_64\nOSName=ubuntu\nOSVersion=20.04\nInitSystem=systemd\nPackageManager=apt\n",
+			GuestFacts{"linux", "ubuntu", "20.04", "x86_64", "systemd", "apt"},
+		},
+		{
+			"OSFamily=windows\r\nOSName=Microsoft Windows Server 2019 Datacenter\r\nOSVersion=10.0.17763\r\nOSArch=AMD64\r\nInitSystem=windows\r\n",
+			GuestFacts{"windows", "Microsoft Windows Server 2019 Datacenter", "10.0.17763", "x86_64", "windows", ""},
+		},
+		{
+			"motd\nOSFamily=freebsd\nUnknown=foo\n",
+			GuestFacts{OSFamily: "freebsd"},
+		},
+	}
+	for _, tc := range cases {
+		if facts := parseGuestFacts(tc.out); !reflect.DeepEqual(*facts, tc.expected) {
+			t.Fatalf("bad facts for %q:\n%#v\nexpected:\n%#v", tc.out, *facts, tc.expected)
+		}
+	}
+}
+
+func TestDetectGuestFacts(t *testing.T) {
+	comm := &packer.MockCommunicator{StartStdout: "OSFamily=linux\nPackageManager=dnf\n"}
+	facts, err := DetectGuestFacts(context.Background(), comm, "linux")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.HasPrefix(comm.StartCmd.Command, "sh -c '") {
+		t.Fatalf("bad command: %s", comm.StartCmd.Command)
+	}
+	if facts.OSFamily != "linux" || facts.PackageManager != "dnf" {
+		t.Fatalf("bad facts: %#v", facts)
+	}
+
+	comm = &packer.MockCommunicator{StartExitStatus: 127}
+	if _, err := DetectGuestFacts(context.Background(), comm, "linux"); err == nil {
+		t.Fatal("should fail when the detection fails")
+	}
+	if _, err := DetectGuestFacts(context.Background(), comm, ""); err == nil {
+		t.Fatal("should fail without a guest OS")
+	}
+}
+
+func TestGuestFacts_GeneratedData(t *testing.T) {
+	var facts *GuestFacts
+	data := facts.GeneratedData()
+	if len(data) != 6 || data["OSFamily"] != "" {
+		t.Fatalf("all the facts should be set, empty: %#v", data)
+	}
+}
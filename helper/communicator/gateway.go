@@ -0,0 +1,250 @@
+package communicator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	helperssh "github.com/hashicorp/packer/helper/ssh"
+	"github.com/hashicorp/packer/packer"
+	"golang.org/x/crypto/ssh"
+)
+
+// prepareSSHGateways validates the access gateways the guest is connected to
+// through: Teleport, Boundary and WebSocket endpoints.
+func (c *Config) prepareSSHGateways() []error {
+	var errs []error
+	var gateways []string
+	for _, option := range []struct{ name, value string }{
+		{"ssh_bastion_host", c.SSHBastionHost},
+		{"ssh_proxy_host", c.SSHProxyHost},
+		{"ssh_callback_address", c.SSHCallbackAddress},
+		{"ssh_teleport_proxy", c.SSHTeleportProxy},
+		{"ssh_boundary_target_id", c.SSHBoundaryTargetID},
+		{"ssh_websocket_url", c.SSHWebSocketURL},
+	} {
+		if option.value != "" {
+			gateways = append(gateways, option.name)
+		}
+	}
+	if (c.SSHTeleportProxy != "" || c.SSHBoundaryTargetID != "" || c.SSHWebSocketURL != "") && len(gateways) > 1 {
+		errs = append(errs, fmt.Errorf("only one of %s can be used", strings.Join(gateways, ", ")))
+	}
+
+	if c.SSHTeleportProxy == "" && (c.SSHTeleportCluster != "" || c.SSHTeleportIdentityFile != "") {
+		errs = append(errs, errors.New("ssh_teleport_cluster and ssh_teleport_identity_file require ssh_teleport_proxy"))
+	}
+	if c.SSHTeleportIdentityFile != "" {
+		if _, err := c.teleportSigner(); err != nil {
+			errs = append(errs, fmt.Errorf("ssh_teleport_identity_file is invalid: %s", err))
+		}
+	}
+	if c.SSHBoundaryTargetID == "" && (c.SSHBoundaryHostID != "" || c.SSHBoundaryAddr != "") {
+		errs = append(errs, errors.New("ssh_boundary_host_id and ssh_boundary_addr require ssh_boundary_target_id"))
+	}
+	if c.SSHWebSocketURL != "" {
+		u, err := url.Parse(c.SSHWebSocketURL)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("ssh_websocket_url is invalid: %s", err))
+		case u.Scheme != "ws" && u.Scheme != "wss":
+			errs = append(errs, errors.New("ssh_websocket_url must be a ws:// or wss:// URL"))
+		case u.Scheme != "wss" && c.SSHWebSocketToken != "":
+			errs = append(errs, errors.New("ssh_websocket_token requires a wss:// ssh_websocket_url"))
+		}
+	} else if c.SSHWebSocketToken != "" || c.SSHWebSocketInsecureSkipTLSVerify {
+		errs = append(errs, errors.New("ssh_websocket_token and ssh_websocket_insecure_skip_tls_verify require ssh_websocket_url"))
+	}
+	return errs
+}
+
+// webSocketURL returns ssh_websocket_url for the guest at port of host.
+func (c *Config) webSocketURL(host string, port int) string {
+	return strings.NewReplacer(
+		"{host}", url.QueryEscape(host),
+		"{port}", strconv.Itoa(port),
+	).Replace(c.SSHWebSocketURL)
+}
+
+// webSocketHeader returns the header of the WebSocket handshake with
+// ssh_websocket_url.
+func (c *Config) webSocketHeader() http.Header {
+	header := make(http.Header)
+	if c.SSHWebSocketToken != "" {
+		header.Set("Authorization", "Bearer "+c.SSHWebSocketToken)
+	}
+	return header
+}
+
+func (c *Config) teleportIdentityFile() (string, error) {
+	return packer.ExpandUser(c.SSHTeleportIdentityFile)
+}
+
+// teleportSigner returns the signer of the certificate of the Teleport
+// identity file.
+func (c *Config) teleportSigner() (ssh.Signer, error) {
+	path, err := c.teleportIdentityFile()
+	if err != nil {
+		return nil, err
+	}
+	return helperssh.IdentityFileSigner(path)
+}
+
+// teleportArgs returns the arguments of tsh to connect to port of the
+// Teleport node host.
+func (c *Config) teleportArgs(host string, port int) ([]string, error) {
+	args := []string{"proxy", "ssh", "--proxy=" + c.SSHTeleportProxy}
+	if c.SSHTeleportCluster != "" {
+		args = append(args, "--cluster="+c.SSHTeleportCluster)
+	}
+	if c.SSHTeleportIdentityFile != "" {
+		path, err := c.teleportIdentityFile()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--identity="+path)
+	}
+	return append(args, fmt.Sprintf("%s@%s:%d", c.SSHUsername, host, port)), nil
+}
+
+// boundarySession is a Boundary session to the guest, proxied by a
+// `boundary connect` process.
+type boundarySession struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+
+	// Address is the local address of the session proxy.
+	Address string
+	// Credentials are the credentials brokered by the session.
+	Username   string
+	Password   string
+	PrivateKey string
+}
+
+// boundaryConnectInfo is the output of `boundary connect -format=json`.
+type boundaryConnectInfo struct {
+	Address     string `json:"address"`
+	Port        int    `json:"port"`
+	SessionID   string `json:"session_id"`
+	Credentials []struct {
+		Credential map[string]interface{} `json:"credential"`
+		Secret     struct {
+			Decoded map[string]interface{} `json:"decoded"`
+		} `json:"secret"`
+	} `json:"credentials"`
+}
+
+// startBoundarySession opens a Boundary session to the guest.
+func (c *Config) startBoundarySession() (*boundarySession, error) {
+	args := []string{
+		"connect",
+		"-target-id=" + c.SSHBoundaryTargetID,
+		"-listen-addr=127.0.0.1",
+		"-listen-port=0",
+		"-format=json",
+	}
+	if c.SSHBoundaryHostID != "" {
+		args = append(args, "-host-id="+c.SSHBoundaryHostID)
+	}
+	if c.SSHBoundaryAddr != "" {
+		args = append(args, "-addr="+c.SSHBoundaryAddr)
+	}
+	cmd := exec.Command("boundary", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("Error starting boundary: %s", err)
+	}
+
+	var info boundaryConnectInfo
+	if err := json.NewDecoder(stdout).Decode(&info); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, fmt.Errorf("Error opening the Boundary session: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	log.Printf("[INFO] Boundary session %s listening on %s:%d", info.SessionID, info.Address, info.Port)
+
+	s := &boundarySession{
+		cmd:     cmd,
+		done:    make(chan struct{}),
+		Address: info.Address + ":" + strconv.Itoa(info.Port),
+	}
+	go func() {
+		io.Copy(ioutil.Discard, stdout)
+		err := cmd.Wait()
+		log.Printf("[INFO] Boundary session %s ended: %v", info.SessionID, err)
+		close(s.done)
+	}()
+	for _, cred := range info.Credentials {
+		fields := cred.Credential
+		if len(fields) == 0 {
+			fields = cred.Secret.Decoded
+		}
+		for key, value := range fields {
+			v, ok := value.(string)
+			if !ok {
+				continue
+			}
+			switch key {
+			case "username":
+				s.Username = v
+			case "password":
+				s.Password = v
+			case "private_key":
+				s.PrivateKey = v
+			}
+		}
+	}
+	return s, nil
+}
+
+// configure sets the credentials brokered by the session in config.
+func (s *boundarySession) configure(config *ssh.ClientConfig) error {
+	if s.Username != "" {
+		config.User = s.Username
+	}
+	if s.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(s.PrivateKey))
+		if err != nil {
+			return fmt.Errorf("Error parsing the private key brokered by Boundary: %s", err)
+		}
+		config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+	}
+	if s.Password != "" {
+		config.Auth = append(config.Auth, ssh.Password(s.Password))
+	}
+	return nil
+}
+
+// Exited returns whether the session proxy exited, like when the session
+// expired.
+func (s *boundarySession) Exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close ends the session.
+func (s *boundarySession) Close() error {
+	if s.Exited() {
+		return nil
+	}
+	err := s.cmd.Process.Kill()
+	<-s.done
+	return err
+}
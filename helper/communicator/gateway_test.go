@@ -0,0 +1,56 @@
+package communicator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestStartBoundarySession(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake boundary command is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "packer-boundary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := `#!/bin/sh
+echo '{"address":"127.0.0.1","port":52345,"session_id":"s_1234567890","credentials":[{"secret":{"decoded":{"username":"admin","password":"secret"}}}]}'
+exec sleep 60
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "boundary"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	c := &Config{SSH: SSH{SSHUsername: "root", SSHBoundaryTargetID: "ttcp_1234567890"}}
+	session, err := c.startBoundarySession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Address != "127.0.0.1:52345" {
+		t.Fatalf("bad address: %s", session.Address)
+	}
+	if session.Exited() {
+		t.Fatal("the session exited early")
+	}
+
+	config := &ssh.ClientConfig{User: "root"}
+	if err := session.configure(config); err != nil {
+		t.Fatal(err)
+	}
+	if config.User != "admin" || len(config.Auth) != 1 {
+		t.Fatalf("brokered credentials not used: %s, %d auth methods", config.User, len(config.Auth))
+	}
+
+	session.Close()
+	if !session.Exited() {
+		t.Fatal("the session is still running")
+	}
+}
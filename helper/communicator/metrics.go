@@ -0,0 +1,186 @@
+package communicator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// connectionMetrics collects the metrics of a communicator, from its first
+// connection attempt. The methods called while connecting can be called on a
+// nil connectionMetrics.
+type connectionMetrics struct {
+	l sync.Mutex
+	m packer.CommunicatorMetrics
+
+	uploadTime, downloadTime time.Duration
+	startTime                time.Duration
+	connected                bool
+}
+
+// retried counts a failed connection attempt.
+func (m *connectionMetrics) retried() {
+	if m == nil {
+		return
+	}
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.m.Retries++
+}
+
+// connect is called once the communicator is connected, so that its next
+// connections are counted as retries.
+func (m *connectionMetrics) connect() {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.connected = true
+}
+
+// connectFunc counts the connections of connect made once the communicator
+// is connected, like when it reconnects after a reboot of the guest.
+func (m *connectionMetrics) connectFunc(connect func() (net.Conn, error)) func() (net.Conn, error) {
+	if m == nil {
+		return connect
+	}
+	return func() (net.Conn, error) {
+		m.l.Lock()
+		if m.connected {
+			m.m.Retries++
+		}
+		m.l.Unlock()
+		return connect()
+	}
+}
+
+func (m *connectionMetrics) uploaded(n int64, d time.Duration) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.m.BytesUploaded += n
+	m.uploadTime += d
+}
+
+func (m *connectionMetrics) downloaded(n int64, d time.Duration) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.m.BytesDownloaded += n
+	m.downloadTime += d
+}
+
+// started counts a command the guest took d to start.
+func (m *connectionMetrics) started(d time.Duration) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.m.Commands++
+	m.m.RunningCommands++
+	m.startTime += d
+}
+
+func (m *connectionMetrics) exited() {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.m.RunningCommands--
+}
+
+// snapshot returns the current metrics.
+func (m *connectionMetrics) snapshot() packer.CommunicatorMetrics {
+	m.l.Lock()
+	defer m.l.Unlock()
+	s := m.m
+	if m.uploadTime > 0 {
+		s.UploadThroughput = float64(s.BytesUploaded) / m.uploadTime.Seconds()
+	}
+	if m.downloadTime > 0 {
+		s.DownloadThroughput = float64(s.BytesDownloaded) / m.downloadTime.Seconds()
+	}
+	if s.Commands > 0 {
+		s.CommandLatency = m.startTime.Seconds() / float64(s.Commands)
+	}
+	return s
+}
+
+// report writes the metrics to ui, as a message and as a machine-readable
+// message.
+func (m *connectionMetrics) report(ui packer.Ui) {
+	s := m.snapshot()
+	ui.Message(fmt.Sprintf(
+		"Communicator: uploaded %s (%s/s), downloaded %s (%s/s), "+
+			"%d commands (%d running, %s average latency), %d connection retries",
+		formatBytes(float64(s.BytesUploaded)), formatBytes(s.UploadThroughput),
+		formatBytes(float64(s.BytesDownloaded)), formatBytes(s.DownloadThroughput),
+		s.Commands, s.RunningCommands,
+		time.Duration(s.CommandLatency*float64(time.Second)).Round(time.Millisecond),
+		s.Retries))
+	ui.Machine(packer.EventCommunicatorMetrics, s.MachineArgs()...)
+}
+
+// reportEvery reports the metrics to ui every interval, and a last time
+// when stop is closed.
+func (m *connectionMetrics) reportEvery(ui packer.Ui, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			m.report(ui)
+			return
+		case <-ticker.C:
+			m.report(ui)
+		}
+	}
+}
+
+// formatBytes formats n bytes with a binary unit.
+func formatBytes(n float64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%.0f B", n)
+	}
+	i := 0
+	for n >= unit && i < 4 {
+		n /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %ciB", n, "KMGT"[i-1])
+}
+
+// metricsCommunicator collects the metrics of the transfers and the
+// commands of a communicator. The transfers of directories are not counted.
+type metricsCommunicator struct {
+	packer.Communicator
+	metrics *connectionMetrics
+}
+
+func (c *metricsCommunicator) Start(ctx context.Context, cmd *packer.RemoteCmd) error {
+	start := time.Now()
+	if err := c.Communicator.Start(ctx, cmd); err != nil {
+		return err
+	}
+	c.metrics.started(time.Since(start))
+	go func() {
+		cmd.Wait()
+		c.metrics.exited()
+	}()
+	return nil
+}
+
+func (c *metricsCommunicator) Upload(dst string, r io.Reader, fi *os.FileInfo) error {
+	start := time.Now()
+	cr := &countingReader{Reader: r}
+	err := c.Communicator.Upload(dst, cr, fi)
+	c.metrics.uploaded(cr.n, time.Since(start))
+	return err
+}
+
+func (c *metricsCommunicator) Download(src string, w io.Writer) error {
+	start := time.Now()
+	cw := &countingWriter{Writer: w}
+	err := c.Communicator.Download(src, cw)
+	c.metrics.downloaded(cw.n, time.Since(start))
+	return err
+}
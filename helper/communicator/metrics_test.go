@@ -0,0 +1,92 @@
+package communicator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestMetricsCommunicator(t *testing.T) {
+	metrics := new(connectionMetrics)
+	comm := &metricsCommunicator{
+		Communicator: &packer.MockCommunicator{DownloadData: "hello"},
+		metrics:      metrics,
+	}
+
+	if err := comm.Upload("/tmp/foo", strings.NewReader("0123456789"), nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := comm.Download("/tmp/bar", new(bytes.Buffer)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	cmd := &packer.RemoteCmd{Command: "true"}
+	if err := comm.Start(context.Background(), cmd); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	cmd.Wait()
+
+	deadline := time.Now().Add(5 * time.Second)
+	s := metrics.snapshot()
+	for s.RunningCommands != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		s = metrics.snapshot()
+	}
+	if s.BytesUploaded != 10 || s.BytesDownloaded != 5 {
+		t.Fatalf("bad transferred bytes: %#v", s)
+	}
+	if s.Commands != 1 || s.RunningCommands != 0 {
+		t.Fatalf("bad commands: %#v", s)
+	}
+}
+
+func TestConnectionMetrics_retries(t *testing.T) {
+	var nilMetrics *connectionMetrics
+	// Should not panic
+	nilMetrics.retried()
+	nilMetrics.connectFunc(nil)
+
+	metrics := new(connectionMetrics)
+	connect := metrics.connectFunc(func() (net.Conn, error) {
+		return nil, errors.New("refused")
+	})
+	metrics.retried()
+	connect()
+	if s := metrics.snapshot(); s.Retries != 1 {
+		t.Fatalf("connections before connecting should not be counted: %#v", s)
+	}
+
+	metrics.connect()
+	connect()
+	if s := metrics.snapshot(); s.Retries != 2 {
+		t.Fatalf("reconnections should be counted: %#v", s)
+	}
+}
+
+func TestConnectionMetrics_report(t *testing.T) {
+	metrics := new(connectionMetrics)
+	metrics.uploaded(3*1024*1024, 2*time.Second)
+	metrics.started(200 * time.Millisecond)
+	metrics.started(400 * time.Millisecond)
+	metrics.exited()
+	metrics.retried()
+
+	var out, machine bytes.Buffer
+	ui := &packer.BasicUi{Writer: &out, ErrorWriter: &out}
+	metrics.report(&packer.MachineReadableUi{Writer: &machine})
+	metrics.report(ui)
+
+	expected := "Communicator: uploaded 3.0 MiB (1.5 MiB/s), downloaded 0 B (0 B/s), " +
+		"2 commands (1 running, 300ms average latency), 1 connection retries\n"
+	if out.String() != expected {
+		t.Fatalf("bad message:\n%q\nexpected:\n%q", out.String(), expected)
+	}
+	if !strings.Contains(machine.String(), ",communicator-metrics,3145728,0,1572864,0,2,1,0.3,1\n") {
+		t.Fatalf("bad machine-readable message: %q", machine.String())
+	}
+}
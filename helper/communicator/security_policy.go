@@ -0,0 +1,195 @@
+package communicator
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// The SSH security policies, the sets of algorithms negotiated with the SSH
+// servers.
+const (
+	// SSHSecurityPolicyFIPS only allows FIPS 140-2 approved algorithms:
+	// AES ciphers, NIST curves and SHA-2 hashes.
+	SSHSecurityPolicyFIPS = "fips"
+	// SSHSecurityPolicyModern allows the algorithms recommended by current
+	// OpenSSH releases.
+	SSHSecurityPolicyModern = "modern"
+	// SSHSecurityPolicyLegacy allows every algorithm supported by Packer,
+	// including the deprecated ones old guests may require.
+	SSHSecurityPolicyLegacy = "legacy"
+)
+
+// SSHSecurityPolicies are the valid values of ssh_security_policy.
+var SSHSecurityPolicies = []string{
+	SSHSecurityPolicyFIPS,
+	SSHSecurityPolicyModern,
+	SSHSecurityPolicyLegacy,
+}
+
+// sshKeyExchanges are the key exchange algorithms supported by the SSH
+// library. The post-quantum mlkem768x25519-sha256 needs Packer built with Go
+// 1.24 or later; sntrup761x25519-sha512@openssh.com isn't supported: servers
+// offering it also offer curve25519-sha256.
+var sshKeyExchanges = []string{
+	ssh.KeyExchangeMLKEM768X25519,
+	ssh.KeyExchangeCurve25519, "curve25519-sha256@libssh.org",
+	ssh.KeyExchangeECDHP256, ssh.KeyExchangeECDHP384, ssh.KeyExchangeECDHP521,
+	ssh.KeyExchangeDHGEXSHA256, ssh.KeyExchangeDH16SHA512, ssh.KeyExchangeDH14SHA256,
+	ssh.InsecureKeyExchangeDH14SHA1,
+	ssh.InsecureKeyExchangeDHGEXSHA1,
+	ssh.InsecureKeyExchangeDH1SHA1,
+}
+
+// sshAlgorithms are the algorithms of an SSH security policy, in order of
+// preference.
+type sshAlgorithms struct {
+	Ciphers      []string
+	KeyExchanges []string
+	MACs         []string
+	HostKeys     []string
+}
+
+var sshSecurityPolicies = map[string]*sshAlgorithms{
+	SSHSecurityPolicyFIPS: {
+		Ciphers: []string{
+			"aes128-gcm@openssh.com",
+			"aes256-ctr", "aes192-ctr", "aes128-ctr",
+		},
+		KeyExchanges: []string{
+			ssh.KeyExchangeECDHP256, ssh.KeyExchangeECDHP384, ssh.KeyExchangeECDHP521,
+			ssh.KeyExchangeDHGEXSHA256,
+		},
+		MACs: []string{
+			"hmac-sha2-256-etm@openssh.com", "hmac-sha2-256",
+		},
+		// RSA host keys are only verified with SHA-2 signatures, FIPS no
+		// longer approves SHA-1.
+		HostKeys: []string{
+			ssh.CertAlgoECDSA256v01, ssh.CertAlgoECDSA384v01, ssh.CertAlgoECDSA521v01,
+			ssh.CertAlgoRSASHA512v01, ssh.CertAlgoRSASHA256v01,
+			ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521,
+			ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256,
+		},
+	},
+	SSHSecurityPolicyModern: {
+		Ciphers: []string{
+			"chacha20-poly1305@openssh.com", "aes128-gcm@openssh.com",
+			"aes256-ctr", "aes192-ctr", "aes128-ctr",
+		},
+		KeyExchanges: []string{
+			ssh.KeyExchangeMLKEM768X25519,
+			ssh.KeyExchangeCurve25519, "curve25519-sha256@libssh.org",
+			ssh.KeyExchangeECDHP256, ssh.KeyExchangeECDHP384, ssh.KeyExchangeECDHP521,
+			ssh.KeyExchangeDHGEXSHA256,
+		},
+		MACs: []string{
+			"hmac-sha2-256-etm@openssh.com", "hmac-sha2-256",
+		},
+		// Like OpenSSH 8.8 and later, RSA host keys are only verified
+		// with SHA-2 signatures.
+		HostKeys: []string{
+			ssh.CertAlgoED25519v01,
+			ssh.CertAlgoECDSA256v01, ssh.CertAlgoECDSA384v01, ssh.CertAlgoECDSA521v01,
+			ssh.CertAlgoRSASHA512v01, ssh.CertAlgoRSASHA256v01,
+			ssh.KeyAlgoED25519,
+			ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521,
+			ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256,
+		},
+	},
+	SSHSecurityPolicyLegacy: {
+		Ciphers: []string{
+			"aes128-gcm@openssh.com", "chacha20-poly1305@openssh.com",
+			"aes128-ctr", "aes192-ctr", "aes256-ctr",
+			"aes128-cbc", "3des-cbc",
+			"arcfour256", "arcfour128", "arcfour",
+		},
+		KeyExchanges: sshKeyExchanges,
+		MACs: []string{
+			"hmac-sha2-256-etm@openssh.com", "hmac-sha2-256",
+			"hmac-sha1", "hmac-sha1-96",
+		},
+		HostKeys: []string{
+			ssh.CertAlgoED25519v01,
+			ssh.CertAlgoECDSA256v01, ssh.CertAlgoECDSA384v01, ssh.CertAlgoECDSA521v01,
+			ssh.CertAlgoRSASHA512v01, ssh.CertAlgoRSASHA256v01,
+			ssh.CertAlgoRSAv01, ssh.InsecureCertAlgoDSAv01,
+			ssh.KeyAlgoED25519,
+			ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521,
+			ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256,
+			ssh.KeyAlgoRSA, ssh.InsecureKeyAlgoDSA,
+		},
+	},
+}
+
+func (c *Config) prepareSSHSecurityPolicy() []error {
+	var errs []error
+	for _, kex := range c.SSHKEXAlgos {
+		if !containsString(sshKeyExchanges, kex) {
+			errs = append(errs, fmt.Errorf(
+				"ssh_key_exchange_algorithms: %s is not supported, supported: %s",
+				kex, strings.Join(sshKeyExchanges, ", ")))
+		}
+	}
+
+	if c.SSHSecurityPolicy == "" {
+		return errs
+	}
+	policy, ok := sshSecurityPolicies[c.SSHSecurityPolicy]
+	if !ok {
+		return append(errs, fmt.Errorf(
+			"ssh_security_policy ('%s') is invalid, valid policies: %s",
+			c.SSHSecurityPolicy, strings.Join(SSHSecurityPolicies, ", ")))
+	}
+
+	check := func(option string, values, allowed []string) {
+		var rejected []string
+		for _, v := range values {
+			if !containsString(allowed, v) {
+				rejected = append(rejected, v)
+			}
+		}
+		if len(rejected) > 0 {
+			errs = append(errs, fmt.Errorf(
+				"%s: %s not allowed by the %s ssh_security_policy, allowed: %s",
+				option, strings.Join(rejected, ", "), c.SSHSecurityPolicy,
+				strings.Join(allowed, ", ")))
+		}
+	}
+	check("ssh_ciphers", c.SSHCiphers, policy.Ciphers)
+	check("ssh_key_exchange_algorithms", c.SSHKEXAlgos, policy.KeyExchanges)
+	return errs
+}
+
+// configure sets the algorithms of an SSH client config.
+func (a *sshAlgorithms) configure(config *ssh.ClientConfig) {
+	config.Ciphers = a.Ciphers
+	config.KeyExchanges = a.KeyExchanges
+	config.MACs = a.MACs
+	config.HostKeyAlgorithms = a.HostKeys
+}
+
+// configureSSHAlgorithms sets the algorithms of the SSH client config of the
+// guest from ssh_security_policy, ssh_ciphers and
+// ssh_key_exchange_algorithms, which narrow the ones of the policy.
+func (c *Config) configureSSHAlgorithms(config *ssh.ClientConfig) {
+	if policy, ok := sshSecurityPolicies[c.SSHSecurityPolicy]; ok {
+		policy.configure(config)
+	}
+	if len(c.SSHCiphers) != 0 {
+		config.Ciphers = c.SSHCiphers
+	}
+	if len(c.SSHKEXAlgos) != 0 {
+		config.KeyExchanges = c.SSHKEXAlgos
+	}
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package communicator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestConfig_sshSecurityPolicy(t *testing.T) {
+	c := testConfig()
+	c.SSHSecurityPolicy = "fips"
+	c.SSHCiphers = []string{"aes256-ctr"}
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	c = testConfig()
+	c.SSHSecurityPolicy = "fips"
+	c.SSHCiphers = []string{"aes256-ctr", "chacha20-poly1305@openssh.com"}
+	c.SSHKEXAlgos = []string{"curve25519-sha256@libssh.org"}
+	if err := c.Prepare(testContext(t)); len(err) != 2 {
+		t.Fatalf("non-compliant ciphers and kex algorithms should be rejected, got: %#v", err)
+	}
+
+	c = testConfig()
+	c.SSHSecurityPolicy = "modern"
+	c.SSHKEXAlgos = []string{"mlkem768x25519-sha256", "curve25519-sha256"}
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	c = testConfig()
+	c.SSHKEXAlgos = []string{"sntrup761x25519-sha512@openssh.com", "curve25519-sha256@libssh.org"}
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("unsupported kex algorithms should be rejected, got: %#v", err)
+	}
+
+	c = testConfig()
+	c.SSHSecurityPolicy = "strict"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("an unknown policy should be rejected, got: %#v", err)
+	}
+}
+
+func TestConfig_configureSSHAlgorithms(t *testing.T) {
+	c := testConfig()
+	config := &ssh.ClientConfig{}
+	c.configureSSHAlgorithms(config)
+	if config.Ciphers != nil || config.MACs != nil || config.HostKeyAlgorithms != nil {
+		t.Fatalf("the defaults should be used without a policy: %#v", config)
+	}
+
+	c.SSHSecurityPolicy = "fips"
+	c.SSHCiphers = []string{"aes256-ctr"}
+	c.configureSSHAlgorithms(config)
+	fips := sshSecurityPolicies["fips"]
+	if !reflect.DeepEqual(config.Ciphers, c.SSHCiphers) {
+		t.Fatalf("ssh_ciphers should narrow the ciphers of the policy, got: %v", config.Ciphers)
+	}
+	if !reflect.DeepEqual(config.KeyExchanges, fips.KeyExchanges) ||
+		!reflect.DeepEqual(config.MACs, fips.MACs) ||
+		!reflect.DeepEqual(config.HostKeyAlgorithms, fips.HostKeys) {
+		t.Fatalf("the algorithms of the policy should be set: %#v", config)
+	}
+}
+
+func TestSSHSecurityPolicy_handshake(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range append([]string{""}, SSHSecurityPolicies...) {
+		serverConfig := &ssh.ServerConfig{NoClientAuth: true}
+		serverConfig.AddHostKey(hostKey)
+
+		c := testConfig()
+		c.SSHSecurityPolicy = name
+		clientConfig, err := c.SSHConfigFunc()(new(multistep.BasicStateBag))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			server, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn, _, _, err := ssh.NewServerConn(server, serverConfig)
+			if err == nil {
+				conn.Close()
+			}
+			server.Close()
+		}()
+		client, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, _, _, err := ssh.NewClientConn(client, l.Addr().String(), clientConfig)
+		l.Close()
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		conn.Close()
+	}
+}
@@ -4,17 +4,24 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
+	"strconv"
 	"time"
 
 	"github.com/hashicorp/packer/communicator/none"
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/otel"
 	gossh "golang.org/x/crypto/ssh"
 )
 
 // StepConnect is a multistep Step implementation that connects to
 // the proper communicator and stores it in the "communicator" key in the
-// state bag.
+// state bag, along with a handle of the connection in the "connection" key.
+//
+// StepConnect is resumable: when it runs again while the connection it
+// established is still open, like in another sequence of steps of the same
+// build, it reconnects the communicator instead of waiting for it again.
 type StepConnect struct {
 	// Config is the communicator config struct
 	Config *Config
@@ -23,6 +30,20 @@ type StepConnect struct {
 	// connections.
 	Host func(multistep.StateBag) (string, error)
 
+	// Resolve, if set, returns the address of the guest, as host:port, for
+	// every new connection to the guest, including when the communicator
+	// reconnects once connected, so that the guest is followed when its
+	// address changes during the build, like after a DHCP renewal or a
+	// migration to another host. It takes precedence over Host and the port
+	// callbacks.
+	Resolve func(multistep.StateBag) (string, error)
+
+	// Dial, if set, is used instead of a TCP connection to connect to the
+	// address of the guest, like to connect through a transport of the
+	// builder. It takes precedence over the bastion and proxy options of
+	// the communicator.
+	Dial func(network, addr string) (net.Conn, error)
+
 	// The fields below are callbacks to assist with connecting to SSH.
 	//
 	// SSHConfig should return the default configuration for
@@ -42,7 +63,10 @@ type StepConnect struct {
 	// existing types.
 	CustomConnect map[string]multistep.Step
 
-	substep multistep.Step
+	substep     multistep.Step
+	connection  *Connection
+	metrics     *connectionMetrics
+	stopMetrics chan struct{}
 }
 
 func (s *StepConnect) pause(pauseLen time.Duration, ctx context.Context) bool {
@@ -60,19 +84,32 @@ func (s *StepConnect) pause(pauseLen time.Duration, ctx context.Context) bool {
 func (s *StepConnect) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	ui := state.Get("ui").(packer.Ui)
 
+	if s.resume(state) {
+		return multistep.ActionContinue
+	}
+
+	if s.Config.MetricsInterval > 0 {
+		s.metrics = new(connectionMetrics)
+	}
 	typeMap := map[string]multistep.Step{
 		"none": nil,
 		"ssh": &StepConnectSSH{
 			Config:    s.Config,
 			Host:      s.Host,
+			Resolve:   s.Resolve,
+			Dial:      s.Dial,
 			SSHConfig: s.SSHConfig,
 			SSHPort:   s.SSHPort,
+			metrics:   s.metrics,
 		},
 		"winrm": &StepConnectWinRM{
 			Config:      s.Config,
 			Host:        s.Host,
+			Resolve:     s.Resolve,
+			Dial:        s.Dial,
 			WinRMConfig: s.WinRMConfig,
 			WinRMPort:   s.WinRMPort,
+			metrics:     s.metrics,
 		},
 	}
 	for k, v := range s.CustomConnect {
@@ -99,17 +136,46 @@ func (s *StepConnect) Run(ctx context.Context, state multistep.StateBag) multist
 		return multistep.ActionContinue
 	}
 
-	if host, err := s.Host(state); err == nil {
+	host := s.Host
+	if s.Resolve != nil {
+		host = s.Resolve
+	}
+	if host, err := host(state); err == nil {
 		ui.Say(fmt.Sprintf("Using %s communicator to connect: %s", s.Config.Type, host))
 	} else {
 		log.Printf("[DEBUG] Unable to get address during connection step: %s", err)
 	}
 
 	s.substep = step
+	_, span := otel.StartSpan(ctx, "connect", otel.String("packer.communicator", s.Config.Type))
 	action := s.substep.Run(ctx, state)
 	if action == multistep.ActionHalt {
+		err, _ := state.Get("error").(error)
+		span.End(err)
 		return action
 	}
+	span.End(nil)
+	otel.Record("packer.communicator.connect.duration", "s", span.Duration().Seconds(),
+		otel.String("packer.communicator", s.Config.Type))
+	raw, _ := state.Get("communicator").(packer.Communicator)
+	if comm, ok := state.Get("communicator").(packer.Communicator); ok && s.metrics != nil {
+		s.metrics.connect()
+		state.Put("communicator", &metricsCommunicator{Communicator: comm, metrics: s.metrics})
+		s.stopMetrics = make(chan struct{})
+		go s.metrics.reportEvery(ui, s.Config.MetricsInterval, s.stopMetrics)
+	}
+	if comm, ok := state.Get("communicator").(packer.Communicator); ok && otel.Enabled() {
+		state.Put("communicator", &tracedCommunicator{Communicator: comm, typ: s.Config.Type})
+	}
+	if comm, ok := state.Get("communicator").(packer.Communicator); ok && raw != nil {
+		if conn := ConnectionFromState(state); conn != nil && conn.raw == raw {
+			// The substep owns the connection.
+			conn.setCommunicator(comm)
+		} else {
+			s.connection = NewConnection(s.Config.Type, comm, raw)
+			state.Put(ConnectionStateKey, s.connection)
+		}
+	}
 
 	if s.Config.PauseBeforeConnect > 0 {
 		cancelled := s.pause(s.Config.PauseBeforeConnect, ctx)
@@ -122,11 +188,84 @@ func (s *StepConnect) Run(ctx context.Context, state multistep.StateBag) multist
 	// for specialized interpolation later
 	state.Put("communicator_config", s.Config)
 
+	if !s.Config.DisableGuestFacts && s.Config.GuestOS() != "" {
+		s.detectGuestFacts(ctx, state)
+	}
+
 	return multistep.ActionContinue
 }
 
+// resume reuses the connection of the state bag when it is still open,
+// reconnecting it, and tells whether it did.
+func (s *StepConnect) resume(state multistep.StateBag) bool {
+	previous := ConnectionFromState(state)
+	if previous == nil || previous.Type != s.Config.Type {
+		return false
+	}
+	conn, err := previous.Acquire()
+	if err != nil {
+		return false
+	}
+	if err := conn.Reconnect(); err != nil {
+		log.Printf("[WARN] Error reconnecting the %s connection, connecting again: %s", conn.Type, err)
+		conn.Close()
+		return false
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	ui.Say(fmt.Sprintf("Resuming the %s connection", conn.Type))
+	s.connection = conn
+	state.Put("communicator", conn.Communicator())
+	state.Put("communicator_config", s.Config)
+	return true
+}
+
+// detectGuestFacts puts the facts of the guest in the state bag. Failures are
+// only logged, leaving the facts unknown.
+func (s *StepConnect) detectGuestFacts(ctx context.Context, state multistep.StateBag) {
+	comm, ok := state.Get("communicator").(packer.Communicator)
+	if !ok {
+		return
+	}
+	facts, err := DetectGuestFacts(ctx, comm, s.Config.GuestOS())
+	if err != nil {
+		log.Printf("[WARN] Error detecting the guest facts: %s", err)
+		return
+	}
+	log.Printf("[INFO] Guest facts: %#v", facts)
+	state.Put("guest_facts", facts)
+}
+
 func (s *StepConnect) Cleanup(state multistep.StateBag) {
+	if s.connection != nil {
+		if err := s.connection.Close(); err != nil {
+			log.Printf("[WARN] Error closing the %s connection: %s", s.connection.Type, err)
+		}
+		s.connection = nil
+	}
+	if s.stopMetrics != nil {
+		close(s.stopMetrics)
+		s.stopMetrics = nil
+	}
 	if s.substep != nil {
 		s.substep.Cleanup(state)
 	}
 }
+
+// resolveAddress returns the host and port of the guest address returned by
+// resolve.
+func resolveAddress(state multistep.StateBag, resolve func(multistep.StateBag) (string, error)) (string, int, error) {
+	address, err := resolve(state)
+	if err != nil {
+		return "", 0, err
+	}
+	host, p, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in %s: %s", address, err)
+	}
+	return host, port, nil
+}
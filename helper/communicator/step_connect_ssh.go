@@ -2,6 +2,7 @@ package communicator
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -16,6 +17,7 @@ import (
 	"github.com/hashicorp/packer/communicator/ssh"
 	"github.com/hashicorp/packer/helper/multistep"
 	helperssh "github.com/hashicorp/packer/helper/ssh"
+	"github.com/hashicorp/packer/helper/totp"
 	"github.com/hashicorp/packer/packer"
 	gossh "golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
@@ -29,13 +31,34 @@ type StepConnectSSH struct {
 	// All the fields below are documented on StepConnect
 	Config    *Config
 	Host      func(multistep.StateBag) (string, error)
+	Resolve   func(multistep.StateBag) (string, error)
+	Dial      func(network, addr string) (net.Conn, error)
 	SSHConfig func(multistep.StateBag) (*gossh.ClientConfig, error)
 	SSHPort   func(multistep.StateBag) (int, error)
+
+	callback   *ssh.CallbackServer
+	boundary   *boundarySession
+	metrics    *connectionMetrics
+	connection *Connection
 }
 
 func (s *StepConnectSSH) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	ui := state.Get("ui").(packer.Ui)
 
+	if s.callback == nil {
+		callback, err := s.Config.SSHCallbackServer()
+		if err != nil {
+			err := fmt.Errorf("Error starting the SSH callback server: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		if callback != nil {
+			ui.Say(fmt.Sprintf("Waiting for the guest to connect back to %s...", callback.Addr()))
+		}
+		s.callback = callback
+	}
+
 	var comm packer.Communicator
 	var err error
 
@@ -63,6 +86,8 @@ func (s *StepConnectSSH) Run(ctx context.Context, state multistep.StateBag) mult
 
 			ui.Say("Connected to SSH!")
 			state.Put("communicator", comm)
+			s.connection = NewConnection("ssh", comm, comm)
+			state.Put(ConnectionStateKey, s.connection)
 			return multistep.ActionContinue
 		case <-timeout:
 			err := fmt.Errorf("Timeout waiting for SSH.")
@@ -82,9 +107,25 @@ func (s *StepConnectSSH) Run(ctx context.Context, state multistep.StateBag) mult
 }
 
 func (s *StepConnectSSH) Cleanup(multistep.StateBag) {
+	if s.connection != nil {
+		if err := s.connection.Close(); err != nil {
+			log.Printf("[WARN] Error closing the SSH connection: %s", err)
+		}
+		s.connection = nil
+	}
+	if s.callback != nil {
+		s.callback.Close()
+		s.callback = nil
+	}
+	if s.boundary != nil {
+		s.boundary.Close()
+		s.boundary = nil
+	}
 }
 
 func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, ctx context.Context) (packer.Communicator, error) {
+	ui := state.Get("ui").(packer.Ui)
+
 	// Determine if we're using a bastion host, and if so, retrieve
 	// that configuration. This configuration doesn't change so we
 	// do this one before entering the retry loop.
@@ -115,13 +156,47 @@ func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, ctx context.Contex
 
 	}
 
+	// connectTo returns the function connecting to port of host.
+	connectTo := func(host string, port int) (func() (net.Conn, error), error) {
+		address := fmt.Sprintf("%s:%d", host, port)
+		switch {
+		case s.Dial != nil:
+			// The builder knows how to connect to the guest
+			dial := s.Dial
+			return func() (net.Conn, error) { return dial("tcp", address) }, nil
+		case bAddr != "":
+			// We're using a bastion host, so use the bastion connfunc
+			return ssh.BastionConnectFunc(bProto, bAddr, bConf, "tcp", address), nil
+		case s.Config.SSHTeleportProxy != "":
+			// Connect through the Teleport proxy
+			args, err := s.Config.teleportArgs(host, port)
+			if err != nil {
+				return nil, err
+			}
+			return ssh.CommandConnectFunc("tsh", args...), nil
+		case s.Config.SSHWebSocketURL != "":
+			// Connect through the WebSocket endpoint
+			tlsConfig := &tls.Config{InsecureSkipVerify: s.Config.SSHWebSocketInsecureSkipTLSVerify}
+			return ssh.WebSocketConnectFunc(s.Config.webSocketURL(host, port), s.Config.webSocketHeader(), tlsConfig), nil
+		case pAddr != "":
+			// Connect via SOCKS5 proxy
+			return ssh.ProxyConnectFunc(pAddr, pAuth, "tcp", address), nil
+		default:
+			// No bastion host, connect directly
+			return ssh.ConnectFunc("tcp", address), nil
+		}
+	}
+
 	handshakeAttempts := 0
+	// Authentication errors don't count as attempts until graceEnd.
+	var graceEnd time.Time
 
 	var comm packer.Communicator
 	first := true
 	for {
 		// Don't check for cancel or wait on first iteration
 		if !first {
+			s.metrics.retried()
 			select {
 			case <-ctx.Done():
 				log.Println("[DEBUG] SSH wait cancelled. Exiting loop.")
@@ -131,24 +206,54 @@ func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, ctx context.Contex
 		}
 		first = false
 
-		// First we request the TCP connection information
-		host, err := s.Host(state)
-		if err != nil {
-			log.Printf("[DEBUG] Error getting SSH address: %s", err)
-			continue
-		}
-		// store host and port in config so we can access them from provisioners
-		s.Config.SSHHost = host
-		port := s.Config.SSHPort
-		if s.SSHPort != nil {
-			port, err = s.SSHPort(state)
+		var connFunc func() (net.Conn, error)
+		var address string
+		if s.callback != nil {
+			// The guest connects back, so its address doesn't matter.
+			address = s.callback.Addr().String()
+			connFunc = s.callback.ConnectFunc()
+			state.Put("communicator_config", s.Config)
+		} else if s.Config.SSHBoundaryTargetID != "" {
+			// Boundary knows the address of the guest: connect to the
+			// session proxy, opening a new session when it ended.
+			if s.boundary != nil && s.boundary.Exited() {
+				s.boundary = nil
+			}
+			if s.boundary == nil {
+				session, err := s.Config.startBoundarySession()
+				if err != nil {
+					log.Printf("[DEBUG] Error opening the Boundary session: %s", err)
+					continue
+				}
+				s.boundary = session
+			}
+			address = s.boundary.Address
+			connFunc = ssh.ConnectFunc("tcp", address)
+			state.Put("communicator_config", s.Config)
+		} else {
+			// First we request the TCP connection information
+			host, port, err := s.address(state)
 			if err != nil {
-				log.Printf("[DEBUG] Error getting SSH port: %s", err)
+				log.Printf("[DEBUG] Error getting SSH address: %s", err)
 				continue
 			}
+			// store host and port in config so we can access them from provisioners
+			s.Config.SSHHost = host
 			s.Config.SSHPort = port
+			state.Put("communicator_config", s.Config)
+
+			// Attempt to connect to SSH port
+			address = fmt.Sprintf("%s:%d", host, port)
+			connFunc, err = connectTo(host, port)
+			if err != nil {
+				return nil, err
+			}
+			if s.Resolve != nil {
+				// Resolve the address again for every connection, like
+				// when reconnecting after a reboot.
+				connFunc = s.resolvingConnectFunc(state, connectTo)
+			}
 		}
-		state.Put("communicator_config", s.Config)
 
 		// Retrieve the SSH configuration
 		sshConfig, err := s.SSHConfig(state)
@@ -156,20 +261,10 @@ func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, ctx context.Contex
 			log.Printf("[DEBUG] Error getting SSH config: %s", err)
 			continue
 		}
-
-		// Attempt to connect to SSH port
-		var connFunc func() (net.Conn, error)
-		address := fmt.Sprintf("%s:%d", host, port)
-		if bAddr != "" {
-			// We're using a bastion host, so use the bastion connfunc
-			connFunc = ssh.BastionConnectFunc(
-				bProto, bAddr, bConf, "tcp", address)
-		} else if pAddr != "" {
-			// Connect via SOCKS5 proxy
-			connFunc = ssh.ProxyConnectFunc(pAddr, pAuth, "tcp", address)
-		} else {
-			// No bastion host, connect directly
-			connFunc = ssh.ConnectFunc("tcp", address)
+		if s.boundary != nil {
+			if err := s.boundary.configure(sshConfig); err != nil {
+				return nil, err
+			}
 		}
 
 		nc, err := connFunc()
@@ -178,6 +273,9 @@ func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, ctx context.Contex
 			continue
 		}
 		nc.Close()
+		if graceEnd.IsZero() {
+			graceEnd = time.Now().Add(s.Config.SSHPreAuthGracePeriod)
+		}
 
 		// Parse out all the requested Port Tunnels that will go over our SSH connection
 		var tunnels []ssh.TunnelSpec
@@ -200,14 +298,22 @@ func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, ctx context.Contex
 
 		// Then we attempt to connect via SSH
 		config := &ssh.Config{
-			Connection:             connFunc,
+			Connection:             s.metrics.connectFunc(connFunc),
 			SSHConfig:              sshConfig,
 			Pty:                    s.Config.SSHPty,
+			PtyOutput:              s.Config.SSHPtyOutput,
 			DisableAgentForwarding: s.Config.SSHDisableAgentForwarding,
+			HandshakeTimeout:       s.Config.SSHHandshakeTimeout,
+			BannerTimeout:          s.Config.SSHBannerReadTimeout,
 			UseSftp:                s.Config.SSHFileTransferMethod == "sftp",
+			SftpServer:             s.Config.SSHSftpServerBinary,
 			KeepAliveInterval:      s.Config.SSHKeepAliveInterval,
 			Timeout:                s.Config.SSHReadWriteTimeout,
+			LivenessTimeout:        s.Config.LivenessTimeout,
 			Tunnels:                tunnels,
+			SftpFallback: func(reason error) {
+				ui.Error(fmt.Sprintf("Warning: transferring the files with scp instead of sftp: %s", reason))
+			},
 		}
 
 		log.Printf("[INFO] Attempting SSH connection to %s...", address)
@@ -227,7 +333,11 @@ func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, ctx context.Contex
 					" your credentials as part of your debugging process. "+
 					"original error: %s",
 					err)
-				handshakeAttempts += 1
+				if time.Now().Before(graceEnd) {
+					log.Printf("[DEBUG] Authentication error during the pre-auth grace period, not counting it.")
+				} else {
+					handshakeAttempts += 1
+				}
 			}
 
 			if handshakeAttempts < s.Config.SSHHandshakeAttempts {
@@ -246,9 +356,64 @@ func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, ctx context.Contex
 	return comm, nil
 }
 
+// address returns the host and port of the guest.
+func (s *StepConnectSSH) address(state multistep.StateBag) (string, int, error) {
+	if s.Resolve != nil {
+		return resolveAddress(state, s.Resolve)
+	}
+	host, err := s.Host(state)
+	if err != nil {
+		return "", 0, err
+	}
+	port := s.Config.SSHPort
+	if s.SSHPort != nil {
+		port, err = s.SSHPort(state)
+		if err != nil {
+			return "", 0, fmt.Errorf("Error getting SSH port: %s", err)
+		}
+	}
+	return host, port, nil
+}
+
+// resolvingConnectFunc returns a function connecting to the address of the
+// guest returned by Resolve when it is called.
+func (s *StepConnectSSH) resolvingConnectFunc(
+	state multistep.StateBag,
+	connectTo func(string, int) (func() (net.Conn, error), error)) func() (net.Conn, error) {
+	return func() (net.Conn, error) {
+		host, port, err := resolveAddress(state, s.Resolve)
+		if err != nil {
+			return nil, fmt.Errorf("Error resolving the guest address: %s", err)
+		}
+		if host != s.Config.SSHHost || port != s.Config.SSHPort {
+			log.Printf("[INFO] The guest address changed to %s:%d", host, port)
+			s.Config.SSHHost = host
+			s.Config.SSHPort = port
+		}
+		connect, err := connectTo(host, port)
+		if err != nil {
+			return nil, err
+		}
+		return connect()
+	}
+}
+
 func sshBastionConfig(config *Config) (*gossh.ClientConfig, error) {
 	auth := make([]gossh.AuthMethod, 0, 2)
 
+	// Only the first keyboard-interactive method is tried, so the one
+	// answering the one-time password questions goes first.
+	if config.SSHBastionTOTPSecret != "" {
+		key, err := totp.Parse(config.SSHBastionTOTPSecret)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing SSH bastion TOTP secret: %s", err)
+		}
+		auth = append(auth, gossh.KeyboardInteractive(
+			ssh.OTPKeyboardInteractive(config.SSHBastionPassword, func() (string, error) {
+				return key.Code(time.Now()), nil
+			})))
+	}
+
 	if config.SSHBastionInteractive {
 		var c io.ReadWriteCloser
 		if terminal.IsTerminal(int(os.Stdin.Fd())) {
@@ -311,9 +476,13 @@ func sshBastionConfig(config *Config) (*gossh.ClientConfig, error) {
 		auth = append(auth, gossh.PublicKeysCallback(agent.NewClient(sshAgent).Signers))
 	}
 
-	return &gossh.ClientConfig{
+	bastionConfig := &gossh.ClientConfig{
 		User:            config.SSHBastionUsername,
 		Auth:            auth,
 		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
-	}, nil
+	}
+	if policy, ok := sshSecurityPolicies[config.SSHSecurityPolicy]; ok {
+		policy.configure(bastionConfig)
+	}
+	return bastionConfig, nil
 }
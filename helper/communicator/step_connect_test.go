@@ -3,6 +3,9 @@ package communicator
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"net"
+	"reflect"
 	"testing"
 
 	"github.com/hashicorp/packer/helper/multistep"
@@ -29,6 +32,74 @@ func TestStepConnect_none(t *testing.T) {
 	}
 }
 
+func TestStepConnectSSH_resolve(t *testing.T) {
+	state := testState(t)
+	address := "10.0.0.1:22"
+	step := &StepConnectSSH{
+		Config:  &Config{Type: "ssh"},
+		Resolve: func(multistep.StateBag) (string, error) { return address, nil },
+	}
+
+	host, port, err := step.address(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host != "10.0.0.1" || port != 22 {
+		t.Fatalf("bad address: %s:%d", host, port)
+	}
+
+	var connected []string
+	connectTo := func(host string, port int) (func() (net.Conn, error), error) {
+		return func() (net.Conn, error) {
+			connected = append(connected, fmt.Sprintf("%s:%d", host, port))
+			c, _ := net.Pipe()
+			return c, nil
+		}, nil
+	}
+	connect := step.resolvingConnectFunc(state, connectTo)
+	for _, a := range []string{"10.0.0.1:22", "10.0.0.2:2222"} {
+		address = a
+		c, err := connect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Close()
+	}
+	if !reflect.DeepEqual(connected, []string{"10.0.0.1:22", "10.0.0.2:2222"}) {
+		t.Fatalf("the guest wasn't followed: %v", connected)
+	}
+	if step.Config.SSHHost != "10.0.0.2" || step.Config.SSHPort != 2222 {
+		t.Fatalf("the config wasn't updated: %s:%d", step.Config.SSHHost, step.Config.SSHPort)
+	}
+
+	address = "10.0.0.3"
+	if _, err := connect(); err == nil {
+		t.Fatal("an address without port should fail")
+	}
+}
+
+func TestStepConnectWinRM_dialFunc(t *testing.T) {
+	state := testState(t)
+	var dialed []string
+	step := &StepConnectWinRM{
+		Config:  &Config{Type: "winrm"},
+		Resolve: func(multistep.StateBag) (string, error) { return "10.0.0.2:5986", nil },
+		Dial: func(network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			c, _ := net.Pipe()
+			return c, nil
+		},
+	}
+	c, err := step.dialFunc(state)("tcp", "10.0.0.1:5985")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if !reflect.DeepEqual(dialed, []string{"10.0.0.2:5986"}) {
+		t.Fatalf("bad dialed addresses: %v", dialed)
+	}
+}
+
 func testState(t *testing.T) multistep.StateBag {
 	state := new(multistep.BasicStateBag)
 	state.Put("hook", &packer.MockHook{})
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,12 +31,18 @@ import (
 //
 // Produces:
 //   communicator packer.Communicator
+//   connection *Connection
 type StepConnectWinRM struct {
 	// All the fields below are documented on StepConnect
 	Config      *Config
 	Host        func(multistep.StateBag) (string, error)
+	Resolve     func(multistep.StateBag) (string, error)
+	Dial        func(network, addr string) (net.Conn, error)
 	WinRMConfig func(multistep.StateBag) (*WinRMConfig, error)
 	WinRMPort   func(multistep.StateBag) (int, error)
+
+	metrics    *connectionMetrics
+	connection *Connection
 }
 
 func (s *StepConnectWinRM) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -66,6 +74,8 @@ func (s *StepConnectWinRM) Run(ctx context.Context, state multistep.StateBag) mu
 
 			ui.Say("Connected to WinRM!")
 			state.Put("communicator", comm)
+			s.connection = NewConnection("winrm", comm, comm)
+			state.Put(ConnectionStateKey, s.connection)
 			return multistep.ActionContinue
 		case <-timeout:
 			err := fmt.Errorf("Timeout waiting for WinRM.")
@@ -85,6 +95,12 @@ func (s *StepConnectWinRM) Run(ctx context.Context, state multistep.StateBag) mu
 }
 
 func (s *StepConnectWinRM) Cleanup(multistep.StateBag) {
+	if s.connection != nil {
+		if err := s.connection.Close(); err != nil {
+			log.Printf("[WARN] Error closing the WinRM connection: %s", err)
+		}
+		s.connection = nil
+	}
 }
 
 func (s *StepConnectWinRM) waitForWinRM(state multistep.StateBag, ctx context.Context) (packer.Communicator, error) {
@@ -93,6 +109,7 @@ func (s *StepConnectWinRM) waitForWinRM(state multistep.StateBag, ctx context.Co
 	for {
 		// Don't check for cancel or wait on first iteration
 		if !first {
+			s.metrics.retried()
 			select {
 			case <-ctx.Done():
 				log.Println("[INFO] WinRM wait cancelled. Exiting loop.")
@@ -102,22 +119,13 @@ func (s *StepConnectWinRM) waitForWinRM(state multistep.StateBag, ctx context.Co
 		}
 		first = false
 
-		host, err := s.Host(state)
+		host, port, err := s.address(state)
 		if err != nil {
-			log.Printf("[DEBUG] Error getting WinRM host: %s", err)
+			log.Printf("[DEBUG] Error getting WinRM address: %s", err)
 			continue
 		}
 		s.Config.WinRMHost = host
-
-		port := s.Config.WinRMPort
-		if s.WinRMPort != nil {
-			port, err = s.WinRMPort(state)
-			if err != nil {
-				log.Printf("[DEBUG] Error getting WinRM port: %s", err)
-				continue
-			}
-			s.Config.WinRMPort = port
-		}
+		s.Config.WinRMPort = port
 
 		state.Put("communicator_config", s.Config)
 
@@ -145,6 +153,9 @@ func (s *StepConnectWinRM) waitForWinRM(state multistep.StateBag, ctx context.Co
 			}
 			s.Config.WinRMTransportDecorator = ProxyTransportDecorator
 		}
+		if s.Dial != nil || s.Resolve != nil {
+			s.Config.WinRMTransportDecorator = s.Config.winrmTransportDecorator(s.dialFunc(state))
+		}
 
 		log.Println("[INFO] Attempting WinRM connection...")
 		comm, err = winrm.New(&winrm.Config{
@@ -156,6 +167,8 @@ func (s *StepConnectWinRM) waitForWinRM(state multistep.StateBag, ctx context.Co
 			Https:              s.Config.WinRMUseSSL,
 			Insecure:           s.Config.WinRMInsecure,
 			TransportDecorator: s.Config.WinRMTransportDecorator,
+			LivenessTimeout:    s.Config.LivenessTimeout,
+			UsePSRP:            s.Config.WinRMUsePSRP,
 		})
 		if err != nil {
 			log.Printf("[ERROR] WinRM connection err: %s", err)
@@ -186,6 +199,7 @@ func (s *StepConnectWinRM) waitForWinRM(state multistep.StateBag, ctx context.Co
 
 		if err != nil {
 			log.Printf("Communication connection err: %s", err)
+			s.metrics.retried()
 			continue
 		}
 
@@ -193,6 +207,7 @@ func (s *StepConnectWinRM) waitForWinRM(state multistep.StateBag, ctx context.Co
 		stdoutToRead := buf2.String()
 		if !strings.Contains(stdoutToRead, "WinRM connected.") {
 			log.Printf("echo didn't succeed; retrying...")
+			s.metrics.retried()
 			continue
 		}
 		break
@@ -201,6 +216,55 @@ func (s *StepConnectWinRM) waitForWinRM(state multistep.StateBag, ctx context.Co
 	return comm, nil
 }
 
+// address returns the host and port of the guest.
+func (s *StepConnectWinRM) address(state multistep.StateBag) (string, int, error) {
+	if s.Resolve != nil {
+		return resolveAddress(state, s.Resolve)
+	}
+	host, err := s.Host(state)
+	if err != nil {
+		return "", 0, err
+	}
+	port := s.Config.WinRMPort
+	if s.WinRMPort != nil {
+		port, err = s.WinRMPort(state)
+		if err != nil {
+			return "", 0, fmt.Errorf("Error getting WinRM port: %s", err)
+		}
+	}
+	return host, port, nil
+}
+
+// dialFunc returns the function connecting to the guest with Dial, and to
+// the address returned by Resolve when it is set, since the WinRM client
+// connects to the address of the first connection otherwise.
+func (s *StepConnectWinRM) dialFunc(state multistep.StateBag) func(network, addr string) (net.Conn, error) {
+	dial := s.Dial
+	if dial == nil {
+		if s.Config.WinRMBastionHost != "" || s.Config.WinRMProxyHost != "" {
+			dial = s.Config.winrmDial
+		} else {
+			dial = (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).Dial
+		}
+	}
+	if s.Resolve == nil {
+		return dial
+	}
+	return func(network, addr string) (net.Conn, error) {
+		host, port, err := resolveAddress(state, s.Resolve)
+		if err != nil {
+			return nil, fmt.Errorf("Error resolving the guest address: %s", err)
+		}
+		if host != s.Config.WinRMHost || port != s.Config.WinRMPort {
+			log.Printf("[INFO] The guest address changed to %s:%d", host, port)
+		}
+		return dial(network, net.JoinHostPort(host, strconv.Itoa(port)))
+	}
+}
+
 // setNoProxy configures the $NO_PROXY env var
 func setNoProxy(host string, port int) error {
 	current := os.Getenv("NO_PROXY")
@@ -0,0 +1,176 @@
+package communicator
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// WinRMPasswordSource retrieves the password generated by the guest for the
+// WinRM user, like the EC2 GetPasswordData API does. Builders put it in the
+// state bag under "winrmPasswordSource", much like the "privateKey" of the
+// SSH communicator, for StepGetWinRMPassword to wait for the password.
+type WinRMPasswordSource struct {
+	// Get returns the password, or an empty string when the guest didn't
+	// generate it yet. It is called every five seconds until the password
+	// is available.
+	Get func(context.Context, multistep.StateBag) (string, error)
+
+	// Encrypted is true when Get returns the password encrypted with the
+	// public key of the SSH key pair of the guest, with RSA PKCS #1 v1.5, and
+	// encoded with base64. The password is then decrypted with the SSH
+	// private key of the communicator.
+	Encrypted bool
+}
+
+// winrmPasswordInterval is how often the password source is called.
+var winrmPasswordInterval = 5 * time.Second
+
+// StepGetWinRMPassword waits for the password of the WinRM user retrieved by
+// the WinRMPasswordSource of the state bag, decrypts it if needed, and sets it
+// on the WinRM config. The step is skipped when the communicator isn't WinRM,
+// when the password is set, or when the builder doesn't supply a source.
+//
+// Uses:
+//   ui packer.Ui
+//   winrmPasswordSource WinRMPasswordSource
+//
+// Produces:
+//   winrm_password string
+type StepGetWinRMPassword struct {
+	Debug bool
+	Comm  *Config
+	// Timeout is how long to wait for the password. By default, there is
+	// no timeout.
+	Timeout time.Duration
+}
+
+func (s *StepGetWinRMPassword) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+
+	// Skip if we're not using winrm
+	if s.Comm.Type != "winrm" {
+		log.Printf("[INFO] Not using winrm communicator, skipping get password...")
+		return multistep.ActionContinue
+	}
+
+	// If we already have a password, skip it
+	if s.Comm.WinRMPassword != "" {
+		ui.Say("Skipping waiting for password since WinRM password set...")
+		return multistep.ActionContinue
+	}
+
+	source, ok := state.GetOk("winrmPasswordSource")
+	if !ok {
+		log.Printf("[INFO] No WinRM password source, skipping get password...")
+		return multistep.ActionContinue
+	}
+
+	ui.Say("Waiting for auto-generated password for instance...")
+	ui.Message(
+		"It is normal for this process to take up to 15 minutes,\n" +
+			"but it usually takes around 5. Please wait.")
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	if s.Timeout > 0 {
+		waitCtx, cancel = context.WithTimeout(ctx, s.Timeout)
+	}
+	defer cancel()
+	password, err := s.waitForPassword(waitCtx, state, source.(WinRMPasswordSource))
+	if err != nil {
+		if ctx.Err() != nil {
+			// The step sequence was cancelled, so cancel waiting for
+			// password and just start the halting process.
+			log.Println("[WARN] Interrupt detected, quitting waiting for password.")
+			return multistep.ActionHalt
+		}
+		if waitCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("Timeout waiting for password.")
+		}
+		ui.Error(fmt.Sprintf("Error waiting for password: %s", err))
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	ui.Message(" \nPassword retrieved!")
+	s.Comm.WinRMPassword = password
+
+	// In debug-mode, we output the password
+	if s.Debug {
+		ui.Message(fmt.Sprintf(
+			"Password (since debug is enabled): %s", s.Comm.WinRMPassword))
+	}
+	// store so that we can access this later during provisioning
+	state.Put("winrm_password", s.Comm.WinRMPassword)
+	packer.LogSecretFilter.Set(s.Comm.WinRMPassword)
+
+	return multistep.ActionContinue
+}
+
+func (s *StepGetWinRMPassword) Cleanup(multistep.StateBag) {}
+
+func (s *StepGetWinRMPassword) waitForPassword(ctx context.Context, state multistep.StateBag, source WinRMPasswordSource) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("[INFO] Retrieve password wait cancelled. Exiting loop.")
+			return "", errors.New("Retrieve password wait cancelled")
+		case <-time.After(winrmPasswordInterval):
+		}
+
+		log.Printf("Retrieving auto-generated instance password...")
+		password, err := source.Get(ctx, state)
+		if err != nil {
+			return "", err
+		}
+		if password == "" {
+			log.Printf("[DEBUG] Password is blank, will retry...")
+			continue
+		}
+		if !source.Encrypted {
+			return password, nil
+		}
+
+		decryptedPassword, err := decryptWinRMPassword(password, s.Comm.SSHPrivateKey)
+		if err != nil {
+			return "", fmt.Errorf("Error decrypting auto-generated instance password: %s", err)
+		}
+		return decryptedPassword, nil
+	}
+}
+
+// decryptWinRMPassword decrypts a base64 encoded password encrypted with the
+// public key of pemBytes.
+func decryptWinRMPassword(password string, pemBytes []byte) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(password)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := gossh.ParseRawPrivateKey(pemBytes)
+	if err != nil {
+		if _, ok := err.(*gossh.PassphraseMissingError); ok {
+			return "", errors.New("encrypted private key isn't yet supported")
+		}
+		return "", err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("the password can only be decrypted with an RSA key, not %T", key)
+	}
+
+	out, err := rsa.DecryptPKCS1v15(nil, rsaKey, encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
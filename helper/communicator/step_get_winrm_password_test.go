@@ -0,0 +1,92 @@
+package communicator
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestStepGetWinRMPassword(t *testing.T) {
+	defer func(d time.Duration) { winrmPasswordInterval = d }(winrmPasswordInterval)
+	winrmPasswordInterval = time.Millisecond
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	encrypted, err := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, []byte("s3cr3t"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	calls := 0
+	state := testState(t)
+	state.Put("winrmPasswordSource", WinRMPasswordSource{
+		Get: func(context.Context, multistep.StateBag) (string, error) {
+			calls++
+			if calls < 3 {
+				return "", nil
+			}
+			return base64.StdEncoding.EncodeToString(encrypted), nil
+		},
+		Encrypted: true,
+	})
+
+	comm := &Config{
+		Type: "winrm",
+		SSH: SSH{
+			SSHPrivateKey: pem.EncodeToMemory(&pem.Block{
+				Type:  "RSA PRIVATE KEY",
+				Bytes: x509.MarshalPKCS1PrivateKey(key),
+			}),
+		},
+	}
+	step := &StepGetWinRMPassword{Comm: comm}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v: %v", action, state.Get("error"))
+	}
+	if comm.WinRMPassword != "s3cr3t" {
+		t.Fatalf("bad password: %q", comm.WinRMPassword)
+	}
+	if state.Get("winrm_password") != "s3cr3t" {
+		t.Fatalf("bad winrm_password: %v", state.Get("winrm_password"))
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+
+	// The password is set now.
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue || calls != 3 {
+		t.Fatalf("the step should be skipped: %#v, %d calls", action, calls)
+	}
+}
+
+func TestStepGetWinRMPassword_timeout(t *testing.T) {
+	defer func(d time.Duration) { winrmPasswordInterval = d }(winrmPasswordInterval)
+	winrmPasswordInterval = time.Millisecond
+
+	state := testState(t)
+	state.Put("winrmPasswordSource", WinRMPasswordSource{
+		Get: func(context.Context, multistep.StateBag) (string, error) {
+			return "", nil
+		},
+	})
+
+	step := &StepGetWinRMPassword{
+		Comm:    &Config{Type: "winrm"},
+		Timeout: 50 * time.Millisecond,
+	}
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if err, ok := state.Get("error").(error); !ok || err.Error() != "Timeout waiting for password." {
+		t.Fatalf("bad error: %v", state.Get("error"))
+	}
+}
@@ -0,0 +1,91 @@
+package communicator
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/otel"
+)
+
+// tracedCommunicator records the duration and the throughput of the
+// transfers of a communicator.
+type tracedCommunicator struct {
+	packer.Communicator
+	typ string
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+func (c *tracedCommunicator) transferred(op string, span *otel.Span, n int64, err error) {
+	span.SetAttributes(otel.Attribute{Key: "packer.communicator.bytes", Value: n})
+	span.End(err)
+	attrs := []otel.Attribute{
+		otel.String("packer.communicator", c.typ),
+		otel.String("packer.communicator.operation", op),
+	}
+	seconds := span.Duration().Seconds()
+	otel.Record("packer.communicator.transfer.duration", "s", seconds, attrs...)
+	if err == nil && seconds > 0 {
+		otel.Record("packer.communicator.transfer.throughput", "By/s", float64(n)/seconds, attrs...)
+	}
+}
+
+func (c *tracedCommunicator) Upload(dst string, r io.Reader, fi *os.FileInfo) error {
+	_, span := otel.StartSpan(context.Background(), "communicator upload", otel.String("packer.communicator", c.typ))
+	cr := &countingReader{Reader: r}
+	err := c.Communicator.Upload(dst, cr, fi)
+	c.transferred("upload", span, cr.n, err)
+	return err
+}
+
+func (c *tracedCommunicator) Download(src string, w io.Writer) error {
+	_, span := otel.StartSpan(context.Background(), "communicator download", otel.String("packer.communicator", c.typ))
+	cw := &countingWriter{Writer: w}
+	err := c.Communicator.Download(src, cw)
+	c.transferred("download", span, cw.n, err)
+	return err
+}
+
+func (c *tracedCommunicator) UploadDir(dst string, src string, exclude []string) error {
+	_, span := otel.StartSpan(context.Background(), "communicator upload directory", otel.String("packer.communicator", c.typ))
+	err := c.Communicator.UploadDir(dst, src, exclude)
+	span.End(err)
+	otel.Record("packer.communicator.transfer.duration", "s", span.Duration().Seconds(),
+		otel.String("packer.communicator", c.typ),
+		otel.String("packer.communicator.operation", "upload-dir"))
+	return err
+}
+
+func (c *tracedCommunicator) DownloadDir(src string, dst string, exclude []string) error {
+	_, span := otel.StartSpan(context.Background(), "communicator download directory", otel.String("packer.communicator", c.typ))
+	err := c.Communicator.DownloadDir(src, dst, exclude)
+	span.End(err)
+	otel.Record("packer.communicator.transfer.duration", "s", span.Duration().Seconds(),
+		otel.String("packer.communicator", c.typ),
+		otel.String("packer.communicator.operation", "download-dir"))
+	return err
+}
@@ -1,8 +1,143 @@
 package communicator
 
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/hashicorp/packer/communicator/ssh"
+	helperssh "github.com/hashicorp/packer/helper/ssh"
+	"github.com/hashicorp/packer/packer"
+	"github.com/masterzen/winrm"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/net/proxy"
+)
+
 // WinRMConfig is configuration that can be returned at runtime to
 // dynamically configure WinRM.
 type WinRMConfig struct {
 	Username string
 	Password string
 }
+
+// prepareWinRMTunnel validates the bastion host or SOCKS proxy the WinRM
+// connection is tunneled through, and sets the transport dialing through it.
+func (c *Config) prepareWinRMTunnel() (errs []error) {
+	if c.WinRMBastionHost == "" && c.WinRMProxyHost == "" {
+		return nil
+	}
+	if c.WinRMBastionHost != "" && c.WinRMProxyHost != "" {
+		errs = append(errs, errors.New("please specify either winrm_bastion_host or winrm_proxy_host, not both"))
+	}
+	if c.WinRMNoProxy {
+		errs = append(errs, errors.New("winrm_no_proxy can't be used with winrm_bastion_host or winrm_proxy_host"))
+	}
+
+	if c.WinRMBastionHost != "" {
+		if c.WinRMBastionPort == 0 {
+			c.WinRMBastionPort = 22
+		}
+		if c.WinRMBastionUsername == "" {
+			errs = append(errs, errors.New("winrm_bastion_username must be specified with winrm_bastion_host"))
+		}
+		if c.WinRMBastionPassword == "" && c.WinRMBastionPrivateKeyFile == "" && !c.WinRMBastionAgentAuth {
+			errs = append(errs, errors.New(
+				"winrm_bastion_password, winrm_bastion_private_key_file or winrm_bastion_agent_auth must be specified"))
+		}
+		if c.WinRMBastionPrivateKeyFile != "" {
+			path, err := packer.ExpandUser(c.WinRMBastionPrivateKeyFile)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("winrm_bastion_private_key_file is invalid: %s", err))
+			} else if _, err := helperssh.FileSigner(path); err != nil {
+				errs = append(errs, fmt.Errorf("winrm_bastion_private_key_file is invalid: %s", err))
+			}
+		}
+	}
+	if c.WinRMProxyHost != "" && c.WinRMProxyPort == 0 {
+		c.WinRMProxyPort = 1080
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	c.WinRMTransportDecorator = c.winrmTransportDecorator(c.winrmDial)
+	return nil
+}
+
+// winrmTransportDecorator returns the transport decorator connecting to the
+// guest with dial.
+func (c *Config) winrmTransportDecorator(dial func(network, addr string) (net.Conn, error)) func() winrm.Transporter {
+	if c.WinRMUseNTLM {
+		return func() winrm.Transporter { return winrm.NewClientNTLMWithDial(dial) }
+	}
+	return func() winrm.Transporter { return winrm.NewClientWithDial(dial) }
+}
+
+// winrmDial connects to addr through the bastion host or the SOCKS proxy.
+func (c *Config) winrmDial(network, addr string) (net.Conn, error) {
+	if c.WinRMProxyHost != "" {
+		var auth *proxy.Auth
+		if c.WinRMProxyUsername != "" {
+			auth = &proxy.Auth{
+				User:     c.WinRMProxyUsername,
+				Password: c.WinRMProxyPassword,
+			}
+		}
+		pAddr := net.JoinHostPort(c.WinRMProxyHost, strconv.Itoa(c.WinRMProxyPort))
+		return ssh.ProxyConnectFunc(pAddr, auth, network, addr)()
+	}
+
+	bConf, err := winrmBastionConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	bAddr := net.JoinHostPort(c.WinRMBastionHost, strconv.Itoa(c.WinRMBastionPort))
+	return ssh.BastionConnectFunc("tcp", bAddr, bConf, network, addr)()
+}
+
+func winrmBastionConfig(config *Config) (*gossh.ClientConfig, error) {
+	auth := make([]gossh.AuthMethod, 0, 2)
+
+	if config.WinRMBastionPassword != "" {
+		auth = append(auth,
+			gossh.Password(config.WinRMBastionPassword),
+			gossh.KeyboardInteractive(
+				ssh.PasswordKeyboardInteractive(config.WinRMBastionPassword)))
+	}
+
+	if config.WinRMBastionPrivateKeyFile != "" {
+		path, err := packer.ExpandUser(config.WinRMBastionPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"Error expanding path for WinRM bastion private key: %s", err)
+		}
+		signer, err := helperssh.FileSigner(path)
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, gossh.PublicKeys(signer))
+	}
+
+	if config.WinRMBastionAgentAuth {
+		authSock := os.Getenv("SSH_AUTH_SOCK")
+		if authSock == "" {
+			return nil, fmt.Errorf("SSH_AUTH_SOCK is not set")
+		}
+
+		sshAgent, err := net.Dial("unix", authSock)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot connect to SSH Agent socket %q: %s", authSock, err)
+		}
+
+		auth = append(auth, gossh.PublicKeysCallback(agent.NewClient(sshAgent).Signers))
+	}
+
+	return &gossh.ClientConfig{
+		User:            config.WinRMBastionUsername,
+		Auth:            auth,
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+	}, nil
+}
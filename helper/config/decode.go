@@ -9,7 +9,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
-	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/packer/tmp"
 	"github.com/hashicorp/packer/template/interpolate"
 	"github.com/mitchellh/mapstructure"
 	"github.com/zclconf/go-cty/cty"
@@ -50,9 +50,6 @@ func Decode(target interface{}, config *DecodeOpts, raws ...interface{}) error {
 		if !ok {
 			continue
 		}
-		type flatConfigurer interface {
-			FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec }
-		}
 		ctarget := target.(flatConfigurer)
 		flatCfg := ctarget.FlatMapstructure()
 		err := gocty.FromCtyValue(cval, flatCfg)
@@ -85,6 +82,7 @@ func Decode(target interface{}, config *DecodeOpts, raws ...interface{}) error {
 			p.Set(reflect.Zero(p.Type()))
 		}
 	}
+
 	if config == nil {
 		config = &DecodeOpts{Interpolate: true}
 	}
@@ -92,6 +90,12 @@ func Decode(target interface{}, config *DecodeOpts, raws ...interface{}) error {
 	// Detect user variables from the raws and merge them into our context
 	ctxData, raws := DetectContextData(raws...)
 
+	// The temporary files of the plugin are created in the scratch directory
+	// of its build, removed once it ran.
+	if dir := detectBuildDir(raws...); dir != "" {
+		tmp.SetDir(dir)
+	}
+
 	// Interpolate first
 	if config.Interpolate {
 		ctx, err := DetectContext(raws...)
@@ -105,6 +109,8 @@ func Decode(target interface{}, config *DecodeOpts, raws ...interface{}) error {
 			config.InterpolateContext.BuildType = ctx.BuildType
 			config.InterpolateContext.TemplatePath = ctx.TemplatePath
 			config.InterpolateContext.UserVariables = ctx.UserVariables
+			config.InterpolateContext.BuildOutputs = ctx.BuildOutputs
+			config.InterpolateContext.BuildInfo = ctx.BuildInfo
 			if config.InterpolateContext.Data == nil {
 				config.InterpolateContext.Data = ctxData
 			}
@@ -234,6 +240,8 @@ func DetectContext(raws ...interface{}) (*interpolate.Context, error) {
 		TemplatePath  string            `mapstructure:"packer_template_path"`
 		Vars          map[string]string `mapstructure:"packer_user_variables"`
 		SensitiveVars []string          `mapstructure:"packer_sensitive_variables"`
+		Outputs       map[string]string `mapstructure:"packer_build_outputs"`
+		Info          map[string]string `mapstructure:"packer_build_info"`
 	}
 
 	for _, r := range raws {
@@ -249,9 +257,25 @@ func DetectContext(raws ...interface{}) (*interpolate.Context, error) {
 		TemplatePath:       s.TemplatePath,
 		UserVariables:      s.Vars,
 		SensitiveVariables: s.SensitiveVars,
+		BuildOutputs:       s.Outputs,
+		BuildInfo:          s.Info,
 	}, nil
 }
 
+// detectBuildDir returns the scratch directory of the build, the build_dir
+// of the build info, from the raw configuration params.
+func detectBuildDir(raws ...interface{}) string {
+	var s struct {
+		Info map[string]string `mapstructure:"packer_build_info"`
+	}
+	for _, r := range raws {
+		if err := mapstructure.Decode(r, &s); err != nil {
+			return ""
+		}
+	}
+	return s.Info["build_dir"]
+}
+
 func uint8ToStringHook(f reflect.Kind, t reflect.Kind, v interface{}) (interface{}, error) {
 	// We need to convert []uint8 to string. We have to do this
 	// because internally Packer uses MsgPack for RPC and the MsgPack
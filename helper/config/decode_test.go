@@ -1,10 +1,14 @@
 package config
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/packer/packer/tmp"
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
@@ -133,3 +137,32 @@ func TestDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestDecode_buildDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	buildDir := filepath.Join(dir, "packer-build-test")
+	defer tmp.SetDir("")
+
+	var result struct{ Name string }
+	err = Decode(&result, nil,
+		map[string]interface{}{"name": "foo"},
+		map[string]interface{}{
+			"packer_build_info": map[string]string{"build_dir": buildDir},
+		})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	f, err := tmp.File("packer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if filepath.Dir(f.Name()) != buildDir {
+		t.Fatalf("the temporary files should be in the build dir, got %s", f.Name())
+	}
+}
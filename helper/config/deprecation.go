@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Deprecation is a deprecated option set in a configuration.
+//
+// Options are deprecated with the deprecated struct tag of their field, set
+// to the option replacing them, or to "" when nothing replaces them:
+//
+//	SSHWaitTimeout time.Duration `mapstructure:"ssh_wait_timeout" deprecated:"ssh_timeout"`
+type Deprecation struct {
+	Option      string
+	Replacement string
+}
+
+// Warning returns the warning telling users to stop setting the option.
+func (d Deprecation) Warning() string {
+	if d.Replacement == "" {
+		return fmt.Sprintf("The option %q is deprecated and will be removed "+
+			"in a future version of Packer.", d.Option)
+	}
+	return fmt.Sprintf("The option %q is deprecated, use %q instead. "+
+		"`packer fix` updates JSON templates to use %q.",
+		d.Option, d.Replacement, d.Replacement)
+}
+
+// FindDeprecations returns the deprecated options of target set in the raws,
+// sorted by option.
+func FindDeprecations(target interface{}, raws ...interface{}) []Deprecation {
+	deprecated := map[string]string{}
+	deprecatedOptions(reflect.TypeOf(target), deprecated)
+	if len(deprecated) == 0 {
+		return nil
+	}
+
+	set := map[string]bool{}
+	for _, raw := range raws {
+		// HCL2 configurations are cty objects.
+		if cval, ok := raw.(cty.Value); ok {
+			if !cval.IsWhollyKnown() || cval.IsNull() || !cval.Type().IsObjectType() {
+				continue
+			}
+			for k, e := range cval.AsValueMap() {
+				if !e.IsNull() {
+					set[k] = true
+				}
+			}
+			continue
+		}
+		v := reflect.ValueOf(raw)
+		if v.Kind() != reflect.Map {
+			continue
+		}
+		for _, k := range v.MapKeys() {
+			// Options not set in HCL2 templates are null.
+			if e := v.MapIndex(k); e.Kind() == reflect.Interface && e.IsNil() {
+				continue
+			}
+			set[fmt.Sprint(k.Interface())] = true
+		}
+	}
+
+	var found []Deprecation
+	for option, replacement := range deprecated {
+		if set[option] {
+			found = append(found, Deprecation{Option: option, Replacement: replacement})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Option < found[j].Option })
+	return found
+}
+
+// deprecatedOptions adds the deprecated options of the struct type t, and of
+// the structs it squashes, to deprecated.
+func deprecatedOptions(t reflect.Type, deprecated map[string]string) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("mapstructure"), ",")
+		if len(tag) > 1 && tag[1] == "squash" {
+			deprecatedOptions(f.Type, deprecated)
+			continue
+		}
+		replacement, ok := f.Tag.Lookup("deprecated")
+		if !ok {
+			continue
+		}
+		name := tag[0]
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		deprecated[name] = replacement
+	}
+}
+
+// ComponentDeprecations returns the deprecated options set in the raws of
+// the configurations of component, sorted by option. The configurations are
+// the types with the FlatMapstructure method generated for their HCL2 spec:
+// the component itself, or its fields, like the config of builders.
+func ComponentDeprecations(component interface{}, raws ...interface{}) []Deprecation {
+	t := reflect.TypeOf(component)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	configs := []reflect.Type{t}
+	for i := 0; i < t.NumField(); i++ {
+		configs = append(configs, t.Field(i).Type)
+	}
+	var found []Deprecation
+	known := map[Deprecation]bool{}
+	for _, ct := range configs {
+		if ct.Kind() != reflect.Ptr {
+			ct = reflect.PtrTo(ct)
+		}
+		if !ct.Implements(flatConfigurerType) {
+			continue
+		}
+		for _, d := range FindDeprecations(reflect.Zero(ct).Interface(), raws...) {
+			if !known[d] {
+				known[d] = true
+				found = append(found, d)
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Option < found[j].Option })
+	return found
+}
+
+// flatConfigurer is implemented by the configurations with a generated HCL2
+// spec.
+type flatConfigurer interface {
+	FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec }
+}
+
+var flatConfigurerType = reflect.TypeOf((*flatConfigurer)(nil)).Elem()
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type deprecationTarget struct {
+	Nested `mapstructure:",squash"`
+
+	Timeout     time.Duration `mapstructure:"timeout"`
+	WaitTimeout time.Duration `mapstructure:"wait_timeout" deprecated:"timeout"`
+	Legacy      string        `mapstructure:"legacy" deprecated:""`
+}
+
+type Nested struct {
+	PortMin    int `mapstructure:"port_min"`
+	OldPortMin int `mapstructure:"old_port_min" deprecated:"port_min"`
+}
+
+func TestFindDeprecations(t *testing.T) {
+	raws := []interface{}{
+		map[string]interface{}{
+			"wait_timeout": "5m",
+			"old_port_min": 22,
+		},
+		map[interface{}]interface{}{
+			"legacy":  "yes",
+			"timeout": "1m",
+		},
+	}
+	expected := []Deprecation{
+		{Option: "legacy"},
+		{Option: "old_port_min", Replacement: "port_min"},
+		{Option: "wait_timeout", Replacement: "timeout"},
+	}
+	if got := FindDeprecations(&deprecationTarget{}, raws...); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("bad: %#v", got)
+	}
+
+	// Options not set in HCL2 templates are null.
+	raw := map[string]interface{}{"timeout": "1m", "wait_timeout": nil}
+	if got := FindDeprecations(&deprecationTarget{}, raw); got != nil {
+		t.Fatalf("bad: %#v", got)
+	}
+}
+
+func (*deprecationTarget) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return nil
+}
+
+type deprecationComponent struct {
+	config deprecationTarget
+	ui     interface{}
+}
+
+func TestComponentDeprecations(t *testing.T) {
+	raws := []interface{}{
+		map[string]interface{}{
+			"wait_timeout": "5m",
+			"legacy":       "yes",
+		},
+		cty.ObjectVal(map[string]cty.Value{
+			"old_port_min": cty.NumberIntVal(22),
+			"wait_timeout": cty.NullVal(cty.String),
+		}),
+	}
+	expected := []Deprecation{
+		{Option: "legacy"},
+		{Option: "old_port_min", Replacement: "port_min"},
+		{Option: "wait_timeout", Replacement: "timeout"},
+	}
+	if got := ComponentDeprecations(&deprecationComponent{}, raws...); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("bad: %#v", got)
+	}
+	// Components can be their own configuration.
+	if got := ComponentDeprecations(&deprecationTarget{}, raws...); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("bad: %#v", got)
+	}
+	if got := ComponentDeprecations(&struct{ Name string }{}, raws...); got != nil {
+		t.Fatalf("bad: %#v", got)
+	}
+}
+
+func TestDeprecationWarning(t *testing.T) {
+	warnings := []string{
+		Deprecation{Option: "wait_timeout", Replacement: "timeout"}.Warning(),
+		Deprecation{Option: "legacy"}.Warning(),
+	}
+	expected := []string{
+		"The option \"wait_timeout\" is deprecated, use \"timeout\" instead. " +
+			"`packer fix` updates JSON templates to use \"timeout\".",
+		"The option \"legacy\" is deprecated and will be removed in a future version of Packer.",
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Fatalf("bad: %#v", warnings)
+	}
+}
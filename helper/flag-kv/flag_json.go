@@ -3,7 +3,8 @@ package kvflag
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+
+	"github.com/hashicorp/packer/helper/secretfile"
 )
 
 // FlagJSON is a flag.Value implementation for parsing user variables
@@ -14,18 +15,19 @@ func (v *FlagJSON) String() string {
 	return ""
 }
 
+// Set reads the variables of the JSON file raw, decrypting it when it is
+// encrypted with sops or age.
 func (v *FlagJSON) Set(raw string) error {
-	f, err := os.Open(raw)
+	data, err := secretfile.ReadFile(raw)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
 	if *v == nil {
 		*v = make(map[string]string)
 	}
 
-	if err := json.NewDecoder(f).Decode(v); err != nil {
+	if err := json.Unmarshal(data, v); err != nil {
 		return fmt.Errorf(
 			"Error reading variables in '%s': %s", raw, err)
 	}
@@ -10,7 +10,26 @@ func (s *StringFlag) String() string {
 	return strings.Join(*s, ",")
 }
 
+// Set appends the comma-separated items of value. Commas between braces, like
+// in the {a,b} glob alternatives or the ${...} expressions, don't separate
+// items.
 func (s *StringFlag) Set(value string) error {
-	*s = append(*s, strings.Split(value, ",")...)
+	depth, start := 0, 0
+	for i, c := range value {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				*s = append(*s, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	*s = append(*s, value[start:])
 	return nil
 }
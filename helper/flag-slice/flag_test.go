@@ -48,3 +48,18 @@ func TestMultiStringFlag(t *testing.T) {
 		t.Fatalf("Expected: %#v, found: %#v", expected, sv)
 	}
 }
+
+// TestMultiStringFlag_braces tests that commas between braces don't split
+// items, like in: blah -flag='{foo,bar}.*,${contains(tags, "baz")}'
+func TestMultiStringFlag_braces(t *testing.T) {
+	sv := new(StringFlag)
+	err := sv.Set(`{foo,bar}.*,${contains(tags, "baz")},qux`)
+	if err != nil {
+		t.Fatalf("err :%s", err)
+	}
+
+	expected := []string{"{foo,bar}.*", `${contains(tags, "baz")}`, "qux"}
+	if !reflect.DeepEqual([]string(*sv), expected) {
+		t.Fatalf("Expected: %#v, found: %#v", expected, sv)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type runState int32
@@ -20,6 +21,11 @@ type BasicRunner struct {
 	// modified.
 	Steps []Step
 
+	// CleanupTimeout bounds the time the cleanups of the steps can take
+	// once the runner is cancelled: the CleanupContext is cancelled when
+	// it is over. Zero means no bound.
+	CleanupTimeout time.Duration
+
 	l     sync.Mutex
 	state runState
 }
@@ -42,12 +48,22 @@ func (b *BasicRunner) Run(ctx context.Context, state StateBag) {
 		b.l.Unlock()
 	}()
 
+	// The cleanups are deferred after this, so they run before the cleanup
+	// context is released.
+	cleanupCtx, cancelCleanup := context.WithCancel(context.Background())
+	defer cancelCleanup()
+	state.Put(StateCleanupContext, cleanupCtx)
+
 	// This goroutine listens for cancels and puts the StateCancelled key
-	// as quickly as possible into the state bag to mark it.
+	// as quickly as possible into the state bag to mark it. It then starts
+	// the cleanup timeout.
 	go func() {
 		select {
 		case <-ctx.Done():
 			state.Put(StateCancelled, true)
+			if b.CleanupTimeout > 0 {
+				time.AfterFunc(b.CleanupTimeout, cancelCleanup)
+			}
 		case <-doneCh:
 		}
 	}()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestBasicRunner_ImplRunner(t *testing.T) {
@@ -159,6 +160,40 @@ func TestBasicRunner_Cancel(t *testing.T) {
 
 }
 
+func TestBasicRunner_CleanupContext(t *testing.T) {
+	topCtx, topCtxCancel := context.WithCancel(context.Background())
+	defer topCtxCancel()
+
+	cleanedUp := false
+	r := &BasicRunner{CleanupTimeout: 500 * time.Millisecond}
+	r.Steps = []Step{
+		TestStepFn{
+			run: func(ctx context.Context, sb StateBag) StepAction {
+				topCtxCancel()
+				<-ctx.Done()
+				return ActionContinue
+			},
+			cleanup: func(sb StateBag) {
+				ctx := CleanupContext(sb)
+				if ctx.Err() != nil {
+					t.Fatal("cleanup context should not be cancelled with the steps")
+				}
+				select {
+				case <-ctx.Done():
+				case <-time.After(5 * time.Second):
+					t.Fatal("cleanup context should be cancelled after the cleanup timeout")
+				}
+				cleanedUp = true
+			},
+		},
+	}
+
+	r.Run(topCtx, new(BasicStateBag))
+	if !cleanedUp {
+		t.Fatal("should clean up")
+	}
+}
+
 func TestBasicRunner_Cancel_Special(t *testing.T) {
 	stepOne := &TestStepInjectCancel{}
 	stepTwo := &TestStepInjectCancel{}
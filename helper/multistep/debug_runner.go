@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 )
 
 // DebugLocation is the location where the pause is occurring when debugging
@@ -41,6 +42,10 @@ type DebugRunner struct {
 	// The function is given the state so that the state can be inspected.
 	PauseFn DebugPauseFn
 
+	// CleanupTimeout bounds the time the cleanups of the steps can take
+	// once the runner is cancelled, like the BasicRunner CleanupTimeout.
+	CleanupTimeout time.Duration
+
 	l      sync.Mutex
 	runner *BasicRunner
 }
@@ -50,7 +55,7 @@ func (r *DebugRunner) Run(ctx context.Context, state StateBag) {
 	if r.runner != nil {
 		panic("already running")
 	}
-	r.runner = new(BasicRunner)
+	r.runner = &BasicRunner{CleanupTimeout: r.CleanupTimeout}
 	r.l.Unlock()
 
 	pauseFn := r.PauseFn
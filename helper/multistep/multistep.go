@@ -19,6 +19,22 @@ const StateCancelled = "cancelled"
 // This is the key set in the state bag when a step halted the sequence.
 const StateHalted = "halted"
 
+// This is the key set in the state bag to the context the cleanups of the
+// steps run with. See CleanupContext.
+const StateCleanupContext = "cleanup_context"
+
+// CleanupContext returns the context the cleanups of the steps should make
+// their calls with. Unlike the context of the steps, it is not cancelled when
+// the runner is cancelled, so that the cleanups can delete what the steps
+// created, but it is cancelled once the cleanup timeout of the runner is
+// over.
+func CleanupContext(state StateBag) context.Context {
+	if ctx, ok := state.GetOk(StateCleanupContext); ok {
+		return ctx.(context.Context)
+	}
+	return context.Background()
+}
+
 // Step is a single step that is part of a potentially large sequence
 // of other steps, responsible for performing some specific action.
 type Step interface {
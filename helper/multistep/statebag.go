@@ -50,3 +50,15 @@ func (b *BasicStateBag) Put(k string, v interface{}) {
 func (b *BasicStateBag) Remove(k string) {
 	delete(b.data, k)
 }
+
+// Values returns a copy of the contents of the state bag.
+func (b *BasicStateBag) Values() map[string]interface{} {
+	b.l.RLock()
+	defer b.l.RUnlock()
+
+	values := make(map[string]interface{}, len(b.data))
+	for k, v := range b.data {
+		values[k] = v
+	}
+	return values
+}
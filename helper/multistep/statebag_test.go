@@ -28,3 +28,22 @@ func TestBasicStateBag(t *testing.T) {
 		t.Fatalf("bad")
 	}
 }
+
+func TestBasicStateBag_Values(t *testing.T) {
+	b := new(BasicStateBag)
+	if len(b.Values()) != 0 {
+		t.Fatalf("bad: %#v", b.Values())
+	}
+
+	b.Put("foo", "bar")
+	values := b.Values()
+	if len(values) != 1 || values["foo"] != "bar" {
+		t.Fatalf("bad: %#v", values)
+	}
+
+	// Changing the copy doesn't change the state bag.
+	values["foo"] = "baz"
+	if b.Get("foo").(string) != "bar" {
+		t.Fatalf("bad")
+	}
+}
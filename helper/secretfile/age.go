@@ -0,0 +1,358 @@
+package secretfile
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// These are the markers of age files.
+const (
+	ageIntro       = "age-encryption.org/v1\n"
+	ageArmorHeader = "-----BEGIN AGE ENCRYPTED FILE-----"
+	ageArmorFooter = "-----END AGE ENCRYPTED FILE-----"
+)
+
+// ageChunkSize is the size of the plaintext chunks of the payload of an age
+// file.
+const ageChunkSize = 64 * 1024
+
+// ageTagSize is the size of the ChaCha20-Poly1305 tags.
+const ageTagSize = 16
+
+// ageColumns is the width of the base64 lines of age headers and armor.
+const ageColumns = 64
+
+var ageB64 = base64.RawStdEncoding.Strict()
+
+// AgeIdentity is an age X25519 identity, the secret key decrypting the
+// files encrypted for its recipient.
+type AgeIdentity struct {
+	secret    []byte
+	recipient []byte
+}
+
+// ParseAgeIdentity parses an AGE-SECRET-KEY-1... identity.
+func ParseAgeIdentity(s string) (*AgeIdentity, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed age identity: %s", err)
+	}
+	if hrp != "age-secret-key-" {
+		return nil, fmt.Errorf("malformed age identity: unknown type %q", hrp)
+	}
+	if len(data) != curve25519.ScalarSize {
+		return nil, fmt.Errorf("malformed age identity: bad length")
+	}
+	recipient, err := curve25519.X25519(data, curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("malformed age identity: %s", err)
+	}
+	return &AgeIdentity{secret: data, recipient: recipient}, nil
+}
+
+// ParseAgeIdentities parses the identities of an age key file: one
+// identity per line, with # comments and blank lines ignored.
+func ParseAgeIdentities(r io.Reader) ([]*AgeIdentity, error) {
+	var ids []*AgeIdentity
+	scanner := bufio.NewScanner(r)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := ParseAgeIdentity(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// unwrap returns the file key of a X25519 stanza, or nil when it is not
+// encrypted for the identity.
+func (id *AgeIdentity) unwrap(s *ageStanza) ([]byte, error) {
+	if len(s.args) != 1 {
+		return nil, errors.New("invalid X25519 recipient stanza")
+	}
+	share, err := ageB64.DecodeString(s.args[0])
+	if err != nil || len(share) != curve25519.PointSize {
+		return nil, errors.New("invalid X25519 recipient stanza")
+	}
+	if len(s.body) != 16+ageTagSize {
+		return nil, errors.New("invalid X25519 recipient stanza")
+	}
+
+	shared, err := curve25519.X25519(id.secret, share)
+	if err != nil {
+		return nil, err
+	}
+	salt := append(append([]byte{}, share...), id.recipient...)
+	wrapKey := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte("age-encryption.org/v1/X25519")), wrapKey); err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(wrapKey)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), s.body, nil)
+	if err != nil {
+		// The stanza is for another recipient.
+		return nil, nil
+	}
+	return fileKey, nil
+}
+
+// ageStanza is a recipient stanza of the header of an age file.
+type ageStanza struct {
+	typ  string
+	args []string
+	body []byte
+}
+
+// isAge tells whether data is an age file, armored or not.
+func isAge(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(ageIntro)) ||
+		bytes.HasPrefix(bytes.TrimSpace(data), []byte(ageArmorHeader))
+}
+
+// DecryptAge decrypts the age file data, armored or not, with one of
+// identities.
+func DecryptAge(data []byte, identities []*AgeIdentity) ([]byte, error) {
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte(ageArmorHeader)) {
+		var err error
+		if data, err = ageDearmor(trimmed); err != nil {
+			return nil, err
+		}
+	}
+
+	stanzas, header, mac, payload, err := parseAgeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileKey []byte
+	for _, s := range stanzas {
+		if s.typ != "X25519" {
+			continue
+		}
+		for _, id := range identities {
+			if fileKey, err = id.unwrap(s); err != nil {
+				return nil, err
+			}
+			if fileKey != nil {
+				break
+			}
+		}
+		if fileKey != nil {
+			break
+		}
+	}
+	if fileKey == nil {
+		return nil, errors.New("no age identity matches the recipients of the file")
+	}
+
+	h := hmac.New(sha256.New, ageKey(fileKey, nil, "header"))
+	h.Write(header)
+	if !hmac.Equal(h.Sum(nil), mac) {
+		return nil, errors.New("bad age header MAC")
+	}
+
+	if len(payload) < 16 {
+		return nil, errors.New("truncated age payload")
+	}
+	return ageDecryptPayload(ageKey(fileKey, payload[:16], "payload"), payload[16:])
+}
+
+// ageKey derives a key from the file key of an age file.
+func ageKey(fileKey, salt []byte, label string) []byte {
+	key := make([]byte, chacha20poly1305.KeySize)
+	io.ReadFull(hkdf.New(sha256.New, fileKey, salt, []byte(label)), key)
+	return key
+}
+
+// parseAgeHeader splits an age file into its recipient stanzas, the part of
+// its header covered by its MAC, the MAC and the payload.
+func parseAgeHeader(data []byte) (stanzas []*ageStanza, header, mac, payload []byte, err error) {
+	if !bytes.HasPrefix(data, []byte(ageIntro)) {
+		return nil, nil, nil, nil, errors.New("not an age file")
+	}
+	rest := data[len(ageIntro):]
+	line := func() (string, bool) {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			return "", false
+		}
+		l := string(rest[:i])
+		rest = rest[i+1:]
+		return l, true
+	}
+	malformed := errors.New("malformed age header")
+
+	for {
+		l, ok := line()
+		if !ok {
+			return nil, nil, nil, nil, malformed
+		}
+		if strings.HasPrefix(l, "--- ") {
+			header = data[:len(data)-len(rest)-len(l)-1+len("---")]
+			if mac, err = ageB64.DecodeString(l[len("--- "):]); err != nil {
+				return nil, nil, nil, nil, malformed
+			}
+			return stanzas, header, mac, rest, nil
+		}
+		if !strings.HasPrefix(l, "-> ") {
+			return nil, nil, nil, nil, malformed
+		}
+		args := strings.Split(l[len("-> "):], " ")
+		s := &ageStanza{typ: args[0], args: args[1:]}
+		for {
+			b, ok := line()
+			if !ok || len(b) > ageColumns {
+				return nil, nil, nil, nil, malformed
+			}
+			decoded, err := ageB64.DecodeString(b)
+			if err != nil {
+				return nil, nil, nil, nil, malformed
+			}
+			s.body = append(s.body, decoded...)
+			if len(b) < ageColumns {
+				break
+			}
+		}
+		stanzas = append(stanzas, s)
+	}
+}
+
+// ageDecryptPayload decrypts the chunks of the payload of an age file.
+func ageDecryptPayload(key, payload []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	var plaintext []byte
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	for counter := uint64(0); ; counter++ {
+		for i := 0; i < 8; i++ {
+			nonce[len(nonce)-2-i] = byte(counter >> (8 * uint(i)))
+		}
+		size := ageChunkSize + ageTagSize
+		last := len(payload) <= size
+		if last {
+			size = len(payload)
+			nonce[len(nonce)-1] = 1
+		}
+		chunk, err := aead.Open(nil, nonce, payload[:size], nil)
+		if err != nil {
+			return nil, errors.New("failed to decrypt the age payload")
+		}
+		if len(chunk) == 0 && (!last || counter > 0) {
+			return nil, errors.New("malformed age payload")
+		}
+		plaintext = append(plaintext, chunk...)
+		payload = payload[size:]
+		if last {
+			return plaintext, nil
+		}
+	}
+}
+
+// ageDearmor decodes an armored age file.
+func ageDearmor(data []byte) ([]byte, error) {
+	s := strings.TrimPrefix(string(data), ageArmorHeader)
+	i := strings.Index(s, ageArmorFooter)
+	if i < 0 {
+		return nil, errors.New("malformed armored age file: missing footer")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s[:i]), ""))
+	if err != nil {
+		return nil, fmt.Errorf("malformed armored age file: %s", err)
+	}
+	return decoded, nil
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Decode decodes a bech32 string, with no length limit like age.
+func bech32Decode(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndex(s, "1")
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, errors.New("separator '1' at invalid position")
+	}
+	hrp := s[:pos]
+	var values []byte
+	for _, c := range s[pos+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return "", nil, fmt.Errorf("invalid character %q", c)
+		}
+		values = append(values, byte(v))
+	}
+	if bech32Polymod(append(bech32ExpandHRP(hrp), values...)) != 1 {
+		return "", nil, errors.New("invalid checksum")
+	}
+
+	// Convert the 5-bit groups to bytes, without the checksum.
+	var data []byte
+	acc, bits := uint32(0), uint(0)
+	for _, v := range values[:len(values)-6] {
+		acc = acc<<5 | uint32(v)
+		bits += 5
+		for bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return "", nil, errors.New("invalid padding")
+	}
+	return hrp, data, nil
+}
+
+func bech32ExpandHRP(hrp string) []byte {
+	v := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		v = append(v, hrp[i]>>5)
+	}
+	v = append(v, 0)
+	for i := 0; i < len(hrp); i++ {
+		v = append(v, hrp[i]&31)
+	}
+	return v
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
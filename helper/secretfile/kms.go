@@ -0,0 +1,61 @@
+package secretfile
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+)
+
+// newKMSClient returns an AWS KMS client of region, using the profile and
+// the role of a sops key when they are set.
+func newKMSClient(region, profile, role string) (kmsiface.KMSAPI, error) {
+	opts := session.Options{
+		Config:            *aws.NewConfig().WithRegion(region).WithCredentialsChainVerboseErrors(true),
+		Profile:           profile,
+		SharedConfigState: session.SharedConfigEnable,
+	}
+	sess, err := session.NewSessionWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if role != "" {
+		return kms.New(sess, &aws.Config{Credentials: stscreds.NewCredentials(sess, role)}), nil
+	}
+	return kms.New(sess), nil
+}
+
+// kmsDecrypt decrypts the data key of a sops file encrypted with AWS KMS.
+func (d *Decrypter) kmsDecrypt(k *sopsKMSKey) ([]byte, error) {
+	// The ARN is arn:aws:kms:REGION:ACCOUNT:key/ID.
+	parts := strings.Split(k.ARN, ":")
+	if len(parts) < 6 || parts[2] != "kms" {
+		return nil, fmt.Errorf("malformed KMS key ARN")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(k.Enc)
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted data key: %s", err)
+	}
+
+	newClient := d.KMS
+	if newClient == nil {
+		newClient = newKMSClient
+	}
+	client, err := newClient(parts[3], k.AWSProfile, k.Role)
+	if err != nil {
+		return nil, err
+	}
+	out, err := client.Decrypt(&kms.DecryptInput{
+		CiphertextBlob:    ciphertext,
+		EncryptionContext: k.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
@@ -0,0 +1,116 @@
+// Package secretfile reads files encrypted with sops or age, like var
+// files, decrypting them in memory so that their secrets can be committed.
+//
+// Age files are decrypted with the age identities of the SOPS_AGE_KEY
+// environment variable, or of the SOPS_AGE_KEY_FILE file, the way sops finds
+// them. sops files are decrypted with the same identities, or with AWS KMS
+// using the AWS credentials of the environment.
+package secretfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+)
+
+// AgeExt is the extension of age files, removed from their name to know
+// their format: vars.pkrvars.hcl.age is an HCL file.
+const AgeExt = ".age"
+
+// Decrypter decrypts sops and age files.
+type Decrypter struct {
+	// AgeIdentities decrypt age files and the data keys of sops files
+	// encrypted for age recipients.
+	AgeIdentities []*AgeIdentity
+	// KMS returns the client decrypting the data keys of sops files
+	// encrypted with AWS KMS, given the region of the key and the profile
+	// and role set in the file. It defaults to an AWS KMS client.
+	KMS func(region, profile, role string) (kmsiface.KMSAPI, error)
+}
+
+// NewDecrypter returns a Decrypter with the age identities of the
+// environment: the ones of the SOPS_AGE_KEY variable, of the
+// SOPS_AGE_KEY_FILE file, and of sops/age/keys.txt in the user config
+// directory when it exists.
+func NewDecrypter() (*Decrypter, error) {
+	d := &Decrypter{}
+	if keys := os.Getenv("SOPS_AGE_KEY"); keys != "" {
+		ids, err := ParseAgeIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("SOPS_AGE_KEY: %s", err)
+		}
+		d.AgeIdentities = append(d.AgeIdentities, ids...)
+	}
+
+	file, required := os.Getenv("SOPS_AGE_KEY_FILE"), true
+	if file == "" {
+		required = false
+		if dir, err := os.UserConfigDir(); err == nil {
+			file = filepath.Join(dir, "sops", "age", "keys.txt")
+		}
+	}
+	if file != "" {
+		f, err := os.Open(file)
+		switch {
+		case err == nil:
+			ids, err := ParseAgeIdentities(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", file, err)
+			}
+			d.AgeIdentities = append(d.AgeIdentities, ids...)
+		case required || !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// Encrypted tells whether data, the contents of a file, is encrypted with
+// sops or age.
+func Encrypted(data []byte) bool {
+	return isAge(data) || isSops(data)
+}
+
+// Decrypt returns the plaintext of data, the contents of the file called
+// name, or data when it is not encrypted. sops JSON files are decrypted to
+// JSON, without their sops metadata.
+func (d *Decrypter) Decrypt(name string, data []byte) ([]byte, error) {
+	var plaintext []byte
+	var err error
+	switch {
+	case isAge(data):
+		plaintext, err = DecryptAge(data, d.AgeIdentities)
+	case isSops(data):
+		plaintext, err = d.decryptSops(TrimAgeExt(name), data)
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error decrypting %s: %s", name, err)
+	}
+	return plaintext, nil
+}
+
+// ReadFile reads the file called name, decrypting it with the keys of the
+// environment when it is encrypted with sops or age.
+func ReadFile(name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil || !Encrypted(data) {
+		return data, err
+	}
+	d, err := NewDecrypter()
+	if err != nil {
+		return nil, err
+	}
+	return d.Decrypt(name, data)
+}
+
+// TrimAgeExt returns name without its .age extension.
+func TrimAgeExt(name string) string {
+	return strings.TrimSuffix(name, AgeExt)
+}
@@ -0,0 +1,338 @@
+package secretfile
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+func bech32Encode(hrp string, data []byte) string {
+	var values []byte
+	acc, bits := uint32(0), uint(0)
+	for _, b := range data {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			values = append(values, byte(acc>>bits)&31)
+		}
+	}
+	if bits > 0 {
+		values = append(values, byte(acc<<(5-bits))&31)
+	}
+	polymod := bech32Polymod(append(append(bech32ExpandHRP(hrp), values...), 0, 0, 0, 0, 0, 0)) ^ 1
+	for i := 0; i < 6; i++ {
+		values = append(values, byte(polymod>>uint(5*(5-i)))&31)
+	}
+	s := hrp + "1"
+	for _, v := range values {
+		s += string(bech32Charset[v])
+	}
+	return s
+}
+
+// testAgeKey returns a new age identity and its recipient public key.
+func testAgeKey(t *testing.T) (string, []byte) {
+	secret := make([]byte, curve25519.ScalarSize)
+	rand.Read(secret)
+	public, err := curve25519.X25519(secret, curve25519.Basepoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.ToUpper(bech32Encode("age-secret-key-", secret)), public
+}
+
+func testHKDF(ikm, salt []byte, info string) []byte {
+	key := make([]byte, 32)
+	io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte(info)), key)
+	return key
+}
+
+// testAgeEncrypt encrypts plaintext for recipient the way age does.
+func testAgeEncrypt(t *testing.T, plaintext, recipient []byte, armor bool) []byte {
+	fileKey := make([]byte, 16)
+	rand.Read(fileKey)
+
+	ephemeral := make([]byte, curve25519.ScalarSize)
+	rand.Read(ephemeral)
+	share, _ := curve25519.X25519(ephemeral, curve25519.Basepoint)
+	shared, _ := curve25519.X25519(ephemeral, recipient)
+	aead, _ := chacha20poly1305.New(testHKDF(shared, append(append([]byte{}, share...), recipient...), "age-encryption.org/v1/X25519"))
+	body := aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), fileKey, nil)
+
+	var out bytes.Buffer
+	out.WriteString(ageIntro)
+	fmt.Fprintf(&out, "-> X25519 %s\n", ageB64.EncodeToString(share))
+	fmt.Fprintf(&out, "%s\n", ageB64.EncodeToString(body))
+	out.WriteString("---")
+	h := hmac.New(sha256.New, testHKDF(fileKey, nil, "header"))
+	h.Write(out.Bytes())
+	fmt.Fprintf(&out, " %s\n", ageB64.EncodeToString(h.Sum(nil)))
+
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	out.Write(nonce)
+	aead, _ = chacha20poly1305.New(testHKDF(fileKey, nonce, "payload"))
+	chunkNonce := make([]byte, chacha20poly1305.NonceSize)
+	for counter := 0; ; counter++ {
+		chunkNonce[10] = byte(counter)
+		chunk := plaintext
+		last := len(chunk) <= ageChunkSize
+		if !last {
+			chunk = chunk[:ageChunkSize]
+		} else {
+			chunkNonce[11] = 1
+		}
+		out.Write(aead.Seal(nil, chunkNonce, chunk, nil))
+		plaintext = plaintext[len(chunk):]
+		if last {
+			break
+		}
+	}
+
+	if !armor {
+		return out.Bytes()
+	}
+	encoded := base64.StdEncoding.EncodeToString(out.Bytes())
+	var armored bytes.Buffer
+	armored.WriteString(ageArmorHeader + "\n")
+	for len(encoded) > ageColumns {
+		armored.WriteString(encoded[:ageColumns] + "\n")
+		encoded = encoded[ageColumns:]
+	}
+	armored.WriteString(encoded + "\n" + ageArmorFooter + "\n")
+	return armored.Bytes()
+}
+
+func TestBech32Decode(t *testing.T) {
+	// The valid strings of BIP 173.
+	for _, s := range []string{
+		"A12UEL5L",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+	} {
+		if _, _, err := bech32Decode(s); err != nil {
+			t.Errorf("%s: %s", s, err)
+		}
+	}
+	for _, s := range []string{"A12UEL5X", "a12UEL5L", "pzry9x0s0muk"} {
+		if _, _, err := bech32Decode(s); err == nil {
+			t.Errorf("%s: should error", s)
+		}
+	}
+}
+
+func TestParseAgeIdentities(t *testing.T) {
+	key, public := testAgeKey(t)
+	ids, err := ParseAgeIdentities(strings.NewReader("# created: today\n# public key: age1...\n" + key + "\n\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || !bytes.Equal(ids[0].recipient, public) {
+		t.Fatalf("bad identities: %#v", ids)
+	}
+	if _, err := ParseAgeIdentities(strings.NewReader(key[:len(key)-1] + "q")); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestDecryptAge(t *testing.T) {
+	key, public := testAgeKey(t)
+	other, _ := testAgeKey(t)
+	id, _ := ParseAgeIdentity(key)
+	otherID, _ := ParseAgeIdentity(other)
+
+	big := bytes.Repeat([]byte("0123456789abcdef"), ageChunkSize/8+3)
+	for _, plaintext := range [][]byte{[]byte("password = \"hunter2\"\n"), {}, big, big[:ageChunkSize]} {
+		for _, armor := range []bool{false, true} {
+			data := testAgeEncrypt(t, plaintext, public, armor)
+			if !isAge(data) {
+				t.Fatal("not detected as an age file")
+			}
+			got, err := DecryptAge(data, []*AgeIdentity{otherID, id})
+			if err != nil {
+				t.Fatalf("%d bytes, armor %t: %s", len(plaintext), armor, err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Fatalf("%d bytes, armor %t: bad plaintext", len(plaintext), armor)
+			}
+		}
+	}
+
+	data := testAgeEncrypt(t, []byte("secret"), public, false)
+	if _, err := DecryptAge(data, []*AgeIdentity{otherID}); err == nil {
+		t.Fatal("should error without the identity")
+	}
+	data[len(data)-1] ^= 1
+	if _, err := DecryptAge(data, []*AgeIdentity{id}); err == nil {
+		t.Fatal("should error once modified")
+	}
+}
+
+// testSops builds a sops file encrypted with key.
+type testSops struct {
+	t    *testing.T
+	key  []byte
+	mac  []byte
+	json []string
+}
+
+func (s *testSops) encrypt(value, typ, aad string) string {
+	block, _ := aes.NewCipher(s.key)
+	gcm, _ := cipher.NewGCMWithNonceSize(block, 32)
+	iv := make([]byte, 32)
+	rand.Read(iv)
+	sealed := gcm.Seal(nil, iv, []byte(value), []byte(aad))
+	data, tag := sealed[:len(sealed)-16], sealed[len(sealed)-16:]
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(data), base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(tag), typ)
+}
+
+// add adds the value at path, the way sops encrypts it unless plain.
+func (s *testSops) add(path, value, typ string, plain bool) {
+	s.mac = append(s.mac, value...)
+	keys := strings.Split(path, ":")
+	v := fmt.Sprintf("%q", s.encrypt(value, typ, path+":"))
+	if plain {
+		v = value
+	}
+	s.json = append(s.json, fmt.Sprintf("%q: %s", keys[len(keys)-1], v))
+}
+
+func (s *testSops) file(master string) []byte {
+	lastModified := time.Now().UTC().Format(time.RFC3339)
+	sum := sha512.Sum512(s.mac)
+	mac := s.encrypt(fmt.Sprintf("%X", sum[:]), "str", lastModified)
+	return []byte(fmt.Sprintf(`{%s, "sops": {%s, "lastmodified": %q, "mac": %q, "unencrypted_suffix": "_unencrypted", "version": "3.6.1"}}`,
+		strings.Join(s.json, ", "), master, lastModified, mac))
+}
+
+func testSopsFile(t *testing.T) *testSops {
+	s := &testSops{t: t, key: make([]byte, 32)}
+	rand.Read(s.key)
+	return s
+}
+
+func TestDecryptSops_age(t *testing.T) {
+	key, public := testAgeKey(t)
+	id, _ := ParseAgeIdentity(key)
+
+	s := testSopsFile(t)
+	s.add("password", "hunter2", "str", false)
+	s.add("port", "22", "int", false)
+	s.add("debug", "True", "bool", false)
+	s.add("region_unencrypted", "us-east-1", "str", true)
+	s.json[3] = `"region_unencrypted": "us-east-1"`
+	enc := testAgeEncrypt(t, s.key, public, true)
+	data := s.file(fmt.Sprintf(`"age": [{"recipient": "age1...", "enc": %q}]`, enc))
+	if !Encrypted(data) {
+		t.Fatal("not detected as a sops file")
+	}
+
+	d := &Decrypter{AgeIdentities: []*AgeIdentity{id}}
+	got, err := d.Decrypt("secrets.pkrvars.json", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"password":"hunter2","port":22,"debug":true,"region_unencrypted":"us-east-1"}`
+	if string(got) != expected {
+		t.Fatalf("bad plaintext: %s", got)
+	}
+
+	if _, err := (&Decrypter{}).Decrypt("secrets.pkrvars.json", data); err == nil || !strings.Contains(err.Error(), "SOPS_AGE_KEY") {
+		t.Fatalf("bad error: %v", err)
+	}
+	tampered := bytes.Replace(data, []byte(`"password": "ENC`), []byte(`"port": "ENC`), 1)
+	if _, err := d.Decrypt("secrets.pkrvars.json", tampered); err == nil {
+		t.Fatal("should error once modified")
+	}
+}
+
+type testKMS struct {
+	kmsiface.KMSAPI
+	key   []byte
+	input *kms.DecryptInput
+}
+
+func (k *testKMS) Decrypt(input *kms.DecryptInput) (*kms.DecryptOutput, error) {
+	k.input = input
+	return &kms.DecryptOutput{Plaintext: k.key}, nil
+}
+
+func TestDecryptSops_kmsBinary(t *testing.T) {
+	s := testSopsFile(t)
+	s.add("data", "password = \"hunter2\"\n", "str", false)
+	data := s.file(`"kms": [{"arn": "arn:aws:kms:eu-west-1:123456789012:key/abc", "enc": "Y2lwaGVy", "aws_profile": "ops", "context": {"team": "images"}}]`)
+
+	client := &testKMS{key: s.key}
+	var region, profile string
+	d := &Decrypter{KMS: func(r, p, role string) (kmsiface.KMSAPI, error) {
+		region, profile = r, p
+		return client, nil
+	}}
+	got, err := d.Decrypt("secrets.pkrvars.hcl", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "password = \"hunter2\"\n" {
+		t.Fatalf("bad plaintext: %q", got)
+	}
+	if region != "eu-west-1" || profile != "ops" || string(client.input.CiphertextBlob) != "cipher" ||
+		*client.input.EncryptionContext["team"] != "images" {
+		t.Fatalf("bad KMS call: %s %s %#v", region, profile, client.input)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secretfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, public := testAgeKey(t)
+	keyFile := filepath.Join(dir, "keys.txt")
+	ioutil.WriteFile(keyFile, []byte(key+"\n"), 0600)
+	defer os.Setenv("SOPS_AGE_KEY_FILE", os.Getenv("SOPS_AGE_KEY_FILE"))
+	os.Setenv("SOPS_AGE_KEY_FILE", keyFile)
+
+	plain := filepath.Join(dir, "vars.pkrvars.hcl")
+	ioutil.WriteFile(plain, []byte(`a = "b"`), 0600)
+	encrypted := filepath.Join(dir, "secrets.pkrvars.hcl.age")
+	ioutil.WriteFile(encrypted, testAgeEncrypt(t, []byte(`password = "hunter2"`), public, false), 0600)
+
+	for file, expected := range map[string]string{plain: `a = "b"`, encrypted: `password = "hunter2"`} {
+		got, err := ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != expected {
+			t.Fatalf("%s: bad contents: %s", file, got)
+		}
+	}
+
+	os.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(dir, "missing.txt"))
+	if _, err := ReadFile(encrypted); err == nil {
+		t.Fatal("should error with a missing key file")
+	}
+}
@@ -0,0 +1,450 @@
+package secretfile
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sopsMetadataKey is the key of the metadata of sops files.
+const sopsMetadataKey = "sops"
+
+// sopsValue matches the encrypted values of sops files.
+var sopsValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+// sopsMetadata is the metadata of a sops file, telling how it is encrypted.
+type sopsMetadata struct {
+	KMS       []*sopsKMSKey     `json:"kms"`
+	Age       []*sopsAgeKey     `json:"age"`
+	PGP       []json.RawMessage `json:"pgp"`
+	GCPKMS    []json.RawMessage `json:"gcp_kms"`
+	AzureKV   []json.RawMessage `json:"azure_kv"`
+	HCVault   []json.RawMessage `json:"hc_vault"`
+	KeyGroups []struct {
+		KMS     []*sopsKMSKey     `json:"kms"`
+		Age     []*sopsAgeKey     `json:"age"`
+		PGP     []json.RawMessage `json:"pgp"`
+		GCPKMS  []json.RawMessage `json:"gcp_kms"`
+		AzureKV []json.RawMessage `json:"azure_kv"`
+		HCVault []json.RawMessage `json:"hc_vault"`
+	} `json:"key_groups"`
+
+	LastModified      string `json:"lastmodified"`
+	MAC               string `json:"mac"`
+	UnencryptedSuffix string `json:"unencrypted_suffix"`
+	EncryptedSuffix   string `json:"encrypted_suffix"`
+	UnencryptedRegex  string `json:"unencrypted_regex"`
+	EncryptedRegex    string `json:"encrypted_regex"`
+	MACOnlyEncrypted  bool   `json:"mac_only_encrypted"`
+	Version           string `json:"version"`
+}
+
+// sopsAgeKey is the data key of a sops file encrypted for an age recipient.
+type sopsAgeKey struct {
+	Recipient string `json:"recipient"`
+	Enc       string `json:"enc"`
+}
+
+// sopsKMSKey is the data key of a sops file encrypted with AWS KMS.
+type sopsKMSKey struct {
+	ARN        string             `json:"arn"`
+	Role       string             `json:"role"`
+	Context    map[string]*string `json:"context"`
+	Enc        string             `json:"enc"`
+	AWSProfile string             `json:"aws_profile"`
+}
+
+// sopsItem is a key of a JSON object, which keeps the order of its keys:
+// the MAC of a sops file covers its values in order.
+type sopsItem struct {
+	Key   string
+	Value interface{}
+}
+
+// sopsBranch is a JSON object.
+type sopsBranch []sopsItem
+
+// isSops tells whether data is a JSON file encrypted with sops.
+func isSops(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return false
+	}
+	var v struct {
+		Sops *struct {
+			MAC     string `json:"mac"`
+			Version string `json:"version"`
+		} `json:"sops"`
+	}
+	return json.Unmarshal(data, &v) == nil && v.Sops != nil && v.Sops.MAC != "" && v.Sops.Version != ""
+}
+
+// decryptSops decrypts the sops file called name. JSON files are decrypted
+// to JSON, while the other files, which sops stores in its binary format,
+// are decrypted to their original contents.
+func (d *Decrypter) decryptSops(name string, data []byte) ([]byte, error) {
+	root, err := parseSopsJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	var meta *sopsMetadata
+	var tree sopsBranch
+	for _, item := range root {
+		if item.Key != sopsMetadataKey {
+			tree = append(tree, item)
+			continue
+		}
+		var raw bytes.Buffer
+		if err := writeSopsJSON(&raw, item.Value); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw.Bytes(), &meta); err != nil {
+			return nil, fmt.Errorf("malformed sops metadata: %s", err)
+		}
+	}
+	if meta == nil {
+		return nil, errors.New("missing sops metadata")
+	}
+
+	key, err := d.sopsDataKey(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := sha512.New()
+	walk := &sopsWalker{meta: meta, key: key, mac: mac}
+	if err := walk.branch(tree, nil); err != nil {
+		return nil, err
+	}
+	lastModified, err := time.Parse(time.RFC3339, meta.LastModified)
+	if err != nil {
+		return nil, fmt.Errorf("malformed sops lastmodified: %s", err)
+	}
+	want, err := sopsDecryptValue(meta.MAC, key, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the sops MAC: %s", err)
+	}
+	if got := fmt.Sprintf("%X", mac.Sum(nil)); got != want {
+		return nil, errors.New("the sops MAC doesn't match, the file was modified")
+	}
+
+	if strings.ToLower(filepath.Ext(name)) == ".json" {
+		var buf bytes.Buffer
+		if err := writeSopsJSON(&buf, tree); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	if len(tree) != 1 || tree[0].Key != "data" {
+		return nil, errors.New("malformed sops binary file: it must only have a data key")
+	}
+	s, ok := tree[0].Value.(string)
+	if !ok {
+		return nil, errors.New("malformed sops binary file: data must be a string")
+	}
+	return []byte(s), nil
+}
+
+// sopsDataKey decrypts the data key of a sops file with one of its master
+// keys.
+func (d *Decrypter) sopsDataKey(meta *sopsMetadata) ([]byte, error) {
+	ages, kmsKeys := meta.Age, meta.KMS
+	unsupported := len(meta.PGP) + len(meta.GCPKMS) + len(meta.AzureKV) + len(meta.HCVault)
+	if len(meta.KeyGroups) > 1 {
+		return nil, errors.New("sops files with several key groups are not supported")
+	}
+	for _, g := range meta.KeyGroups {
+		ages = append(ages, g.Age...)
+		kmsKeys = append(kmsKeys, g.KMS...)
+		unsupported += len(g.PGP) + len(g.GCPKMS) + len(g.AzureKV) + len(g.HCVault)
+	}
+
+	var errs []string
+	if len(ages) > 0 {
+		if len(d.AgeIdentities) == 0 {
+			errs = append(errs, "age: no identity, set SOPS_AGE_KEY or SOPS_AGE_KEY_FILE")
+		}
+		for _, k := range ages {
+			if len(d.AgeIdentities) == 0 {
+				break
+			}
+			key, err := DecryptAge([]byte(k.Enc), d.AgeIdentities)
+			if err == nil {
+				return key, nil
+			}
+			errs = append(errs, fmt.Sprintf("age %s: %s", k.Recipient, err))
+		}
+	}
+	for _, k := range kmsKeys {
+		key, err := d.kmsDecrypt(k)
+		if err == nil {
+			return key, nil
+		}
+		errs = append(errs, fmt.Sprintf("kms %s: %s", k.ARN, err))
+	}
+	if unsupported > 0 {
+		errs = append(errs, "the pgp, gcp_kms, azure_kv and hc_vault keys are not supported")
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("the sops file has no master key")
+	}
+	return nil, fmt.Errorf("failed to decrypt the sops data key:\n  %s", strings.Join(errs, "\n  "))
+}
+
+// sopsWalker decrypts the values of a sops file and computes their MAC, the
+// way sops walks them.
+type sopsWalker struct {
+	meta *sopsMetadata
+	key  []byte
+	mac  io.Writer
+}
+
+func (w *sopsWalker) branch(b sopsBranch, path []string) error {
+	for i := range b {
+		p := append(append([]string{}, path...), b[i].Key)
+		v, err := w.value(b[i].Value, p)
+		if err != nil {
+			return err
+		}
+		b[i].Value = v
+	}
+	return nil
+}
+
+func (w *sopsWalker) value(v interface{}, path []string) (interface{}, error) {
+	switch v := v.(type) {
+	case sopsBranch:
+		return v, w.branch(v, path)
+	case []interface{}:
+		// The items of a list have the path of the list.
+		for i := range v {
+			item, err := w.value(v[i], path)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = item
+		}
+		return v, nil
+	case nil:
+		return nil, nil
+	}
+
+	encrypted := w.encrypted(path)
+	if encrypted {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: the value is not encrypted", strings.Join(path, "."))
+		}
+		decrypted, err := sopsDecryptValue(s, w.key, strings.Join(path, ":")+":")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", strings.Join(path, "."), err)
+		}
+		v = decrypted
+	}
+	if !w.meta.MACOnlyEncrypted || encrypted {
+		w.mac.Write(sopsBytes(v))
+	}
+	return v, nil
+}
+
+// encrypted tells whether the value at path is encrypted, following the
+// settings of the file.
+func (w *sopsWalker) encrypted(path []string) bool {
+	m := w.meta
+	matches := func(fn func(string) bool) bool {
+		for _, p := range path {
+			if fn(p) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case m.UnencryptedSuffix != "":
+		return !matches(func(p string) bool { return strings.HasSuffix(p, m.UnencryptedSuffix) })
+	case m.EncryptedSuffix != "":
+		return matches(func(p string) bool { return strings.HasSuffix(p, m.EncryptedSuffix) })
+	case m.UnencryptedRegex != "":
+		re, err := regexp.Compile(m.UnencryptedRegex)
+		return err != nil || !matches(re.MatchString)
+	case m.EncryptedRegex != "":
+		re, err := regexp.Compile(m.EncryptedRegex)
+		return err != nil || matches(re.MatchString)
+	}
+	return true
+}
+
+// sopsDecryptValue decrypts an ENC[AES256_GCM,...] value of a sops file.
+func sopsDecryptValue(s string, key []byte, additionalData string) (interface{}, error) {
+	if s == "" {
+		return "", nil
+	}
+	m := sopsValue.FindStringSubmatch(s)
+	if m == nil {
+		return nil, errors.New("malformed encrypted value")
+	}
+	var parts [3][]byte
+	for i := range parts {
+		var err error
+		if parts[i], err = base64.StdEncoding.DecodeString(m[i+1]); err != nil {
+			return nil, fmt.Errorf("malformed encrypted value: %s", err)
+		}
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, errors.New("failed to decrypt the value")
+	}
+
+	switch typ := m[4]; typ {
+	case "str", "bytes":
+		return string(plaintext), nil
+	case "int":
+		return strconv.Atoi(string(plaintext))
+	case "float":
+		return strconv.ParseFloat(string(plaintext), 64)
+	case "bool":
+		return strconv.ParseBool(string(plaintext))
+	default:
+		return nil, fmt.Errorf("unsupported value type %q", typ)
+	}
+}
+
+// sopsBytes returns the bytes of a value covered by the MAC of sops files.
+func sopsBytes(v interface{}) []byte {
+	switch v := v.(type) {
+	case string:
+		return []byte(v)
+	case int:
+		return []byte(strconv.Itoa(v))
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		// sops writes booleans the Python way.
+		if v {
+			return []byte("True")
+		}
+		return []byte("False")
+	}
+	return []byte(fmt.Sprint(v))
+}
+
+// parseSopsJSON parses a JSON object, keeping the order of its keys.
+func parseSopsJSON(data []byte) (sopsBranch, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := sopsJSONValue(dec)
+	if err != nil {
+		return nil, fmt.Errorf("malformed sops file: %s", err)
+	}
+	b, ok := v.(sopsBranch)
+	if !ok {
+		return nil, errors.New("malformed sops file: not an object")
+	}
+	return b, nil
+}
+
+func sopsJSONValue(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := t.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			b := sopsBranch{}
+			for dec.More() {
+				k, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := sopsJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				b = append(b, sopsItem{Key: k.(string), Value: v})
+			}
+			_, err := dec.Token()
+			return b, err
+		case '[':
+			l := []interface{}{}
+			for dec.More() {
+				v, err := sopsJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				l = append(l, v)
+			}
+			_, err := dec.Token()
+			return l, err
+		}
+		return nil, fmt.Errorf("unexpected %s", t)
+	case json.Number:
+		if i, err := strconv.Atoi(t.String()); err == nil {
+			return i, nil
+		}
+		return t.Float64()
+	default:
+		return t, nil
+	}
+}
+
+// writeSopsJSON writes v as JSON, keeping the order of the keys of its
+// objects.
+func writeSopsJSON(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case sopsBranch:
+		buf.WriteByte('{')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			k, _ := json.Marshal(item.Key)
+			buf.Write(k)
+			buf.WriteByte(':')
+			if err := writeSopsJSON(buf, item.Value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeSopsJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	return nil
+}
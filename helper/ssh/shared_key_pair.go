@@ -0,0 +1,200 @@
+package ssh
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer/common/filelock"
+	"github.com/hashicorp/packer/packer/tmp"
+)
+
+// SharedKeyPair is a key pair stored in a directory, so that builds running
+// in different processes use the same key pair instead of generating one
+// each. Builds acquire the key pair before using it and release it when they
+// are done; the key pair is deleted when its last user releases it, unless it
+// is persisted.
+//
+// Users acquire the key pair in a scope, like a cloud region: what the users
+// of a scope share, like a cloud key pair, is created by its first user and
+// removed by its last one.
+type SharedKeyPair struct {
+	// Dir is the directory the key pair is stored in.
+	Dir string
+	// Name is the name of the key pair, and its comment when it is
+	// generated.
+	Name string
+	// Config configures the key pair when it is generated.
+	Config CreateKeyPairConfig
+	// Persist keeps the key pair when its last user releases it, so that
+	// later runs reuse it.
+	Persist bool
+}
+
+// RunKeyPairDir returns the directory of the key pairs shared by the builds
+// of the current Packer run, or an empty string outside of a run.
+func RunKeyPairDir() string {
+	id := os.Getenv("PACKER_RUN_UUID")
+	if id == "" {
+		return ""
+	}
+	return filepath.Join(tmp.RunDir(), "packer-keys-"+id)
+}
+
+// fileName returns name without the characters that can't be in a file name.
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+func (s *SharedKeyPair) path(suffix string) string {
+	return filepath.Join(s.Dir, fileName(s.Name)+suffix)
+}
+
+func (s *SharedKeyPair) keyPath() string   { return s.path(".pem") }
+func (s *SharedKeyPair) usersPath() string { return s.path(".users") }
+
+func (s *SharedKeyPair) scopePath(scope string) string {
+	if scope == "" {
+		scope = "default"
+	}
+	return filepath.Join(s.usersPath(), fileName(scope))
+}
+
+func (s *SharedKeyPair) userPath(scope, user string) string {
+	return filepath.Join(s.scopePath(scope), fileName(user))
+}
+
+func (s *SharedKeyPair) lock() (func(), error) {
+	if err := os.MkdirAll(s.usersPath(), 0700); err != nil {
+		return nil, err
+	}
+	lock := filelock.New(s.path(".lock"))
+	if err := lock.Lock(); err != nil {
+		return nil, fmt.Errorf("Error locking key pair %s: %s", s.Name, err)
+	}
+	return func() {
+		if err := lock.Unlock(); err != nil {
+			log.Printf("[WARN] Error unlocking key pair %s: %s", s.Name, err)
+		}
+	}, nil
+}
+
+// Acquire returns the key pair for user, generating it when it doesn't exist
+// yet. When scope has no other users, create is called, if set, with the key
+// pair before it is returned: it creates what the users of scope share, like
+// a cloud key pair, and must succeed when it already exists, for a persisted
+// key pair.
+func (s *SharedKeyPair) Acquire(scope, user string, create func(KeyPair) error) (KeyPair, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return KeyPair{}, err
+	}
+	defer unlock()
+
+	var kp KeyPair
+	generated := false
+	privateKey, err := ioutil.ReadFile(s.keyPath())
+	switch {
+	case err == nil:
+		kp, err = KeyPairFromPrivateKey(FromPrivateKeyConfig{
+			RawPrivateKeyPemBlock: privateKey,
+			Comment:               s.Name,
+		})
+		if err != nil {
+			return KeyPair{}, fmt.Errorf("Error loading key pair %s: %s", s.keyPath(), err)
+		}
+	case os.IsNotExist(err):
+		config := s.Config
+		config.Comment = s.Name
+		kp, err = NewKeyPair(config)
+		if err != nil {
+			return KeyPair{}, err
+		}
+		if err := ioutil.WriteFile(s.keyPath(), kp.PrivateKeyPemBlock, 0600); err != nil {
+			return KeyPair{}, err
+		}
+		generated = true
+	default:
+		return KeyPair{}, err
+	}
+
+	if err := os.MkdirAll(s.scopePath(scope), 0700); err != nil {
+		return KeyPair{}, err
+	}
+	users, err := ioutil.ReadDir(s.scopePath(scope))
+	if err != nil {
+		return KeyPair{}, err
+	}
+	if len(users) == 0 && create != nil {
+		if err := create(kp); err != nil {
+			if generated {
+				os.Remove(s.keyPath())
+			}
+			return KeyPair{}, err
+		}
+	}
+
+	if err := ioutil.WriteFile(s.userPath(scope, user), nil, 0600); err != nil {
+		return KeyPair{}, err
+	}
+	log.Printf("[INFO] Acquired key pair %s for %s", s.Name, user)
+	return kp, nil
+}
+
+// Release releases the key pair of user. When user is the last user of scope
+// and the key pair is not persisted, remove is called, if set, and the key
+// pair is deleted once no scope has users.
+func (s *SharedKeyPair) Release(scope, user string, remove func() error) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(s.userPath(scope, user)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	log.Printf("[INFO] Released key pair %s for %s", s.Name, user)
+
+	if s.Persist {
+		return nil
+	}
+	users, err := ioutil.ReadDir(s.scopePath(scope))
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return nil
+	}
+	if remove != nil {
+		if err := remove(); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(s.scopePath(scope)); err != nil {
+		return err
+	}
+
+	// Scopes are removed with their last user: the key pair is not used
+	// anymore when none is left.
+	scopes, err := ioutil.ReadDir(s.usersPath())
+	if err != nil {
+		return err
+	}
+	if len(scopes) > 0 {
+		return nil
+	}
+	if err := os.Remove(s.keyPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Remove(s.usersPath())
+}
@@ -0,0 +1,121 @@
+package ssh
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestSharedKeyPair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-shared-key-pair")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kp := &SharedKeyPair{Dir: dir, Name: "packer_run", Config: CreateKeyPairConfig{Type: Ecdsa}}
+	created := map[string]int{}
+	create := func(scope string) func(KeyPair) error {
+		return func(KeyPair) error {
+			created[scope]++
+			return nil
+		}
+	}
+	removed := map[string]int{}
+	remove := func(scope string) func() error {
+		return func() error {
+			removed[scope]++
+			return nil
+		}
+	}
+
+	a, err := kp.Acquire("us-east-1", "a", create("us-east-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := kp.Acquire("us-east-1", "b", create("us-east-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := kp.Acquire("eu-west-1", "c", create("eu-west-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.PrivateKeyPemBlock, b.PrivateKeyPemBlock) || !bytes.Equal(a.PrivateKeyPemBlock, c.PrivateKeyPemBlock) {
+		t.Fatal("users got different key pairs")
+	}
+	if a.Comment != "packer_run" {
+		t.Fatalf("bad comment: %q", a.Comment)
+	}
+	if created["us-east-1"] != 1 || created["eu-west-1"] != 1 {
+		t.Fatalf("bad creations: %v", created)
+	}
+
+	if err := kp.Release("us-east-1", "a", remove("us-east-1")); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 0 {
+		t.Fatalf("removed while in use: %v", removed)
+	}
+	if err := kp.Release("us-east-1", "b", remove("us-east-1")); err != nil {
+		t.Fatal(err)
+	}
+	if removed["us-east-1"] != 1 {
+		t.Fatalf("bad removals: %v", removed)
+	}
+	if _, err := os.Stat(kp.keyPath()); err != nil {
+		t.Fatalf("key pair deleted while in use: %s", err)
+	}
+	if err := kp.Release("eu-west-1", "c", remove("eu-west-1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(kp.keyPath()); !os.IsNotExist(err) {
+		t.Fatalf("key pair not deleted: %v", err)
+	}
+
+	// A new key pair is generated once the last one was deleted.
+	d, err := kp.Acquire("us-east-1", "d", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a.PrivateKeyPemBlock, d.PrivateKeyPemBlock) {
+		t.Fatal("deleted key pair was reused")
+	}
+}
+
+func TestSharedKeyPair_persist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-shared-key-pair")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kp := &SharedKeyPair{Dir: dir, Name: "packer_amazon-ebs.debug", Config: CreateKeyPairConfig{Type: Ecdsa}, Persist: true}
+	first, err := kp.Acquire("", "a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = kp.Release("", "a", func() error {
+		t.Fatal("persisted key pair removed")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := 0
+	second, err := kp.Acquire("", "b", func(KeyPair) error {
+		created++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.PrivateKeyPemBlock, second.PrivateKeyPemBlock) {
+		t.Fatal("persisted key pair was not reused")
+	}
+	if created != 1 {
+		t.Fatalf("the first user of a run should create the key pair, created %d times", created)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -53,6 +54,50 @@ func FileSigner(path string) (ssh.Signer, error) {
 	return signer, nil
 }
 
+// IdentityFileSigner returns an ssh.Signer for an identity file, like the
+// ones exported by `tsh login --out`: a file holding a private key and its
+// SSH user certificate, among other certificates.
+func IdentityFileSigner(path string) (ssh.Signer, error) {
+	identity, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keySigner ssh.Signer
+	for rest := identity; keySigner == nil; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, fmt.Errorf("no private key found in %s", path)
+		}
+		if strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			keySigner, err = ssh.ParsePrivateKey(pem.EncodeToMemory(block))
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing the private key of %s: %s", path, err)
+			}
+		}
+	}
+
+	for _, line := range strings.Split(string(identity), "\n") {
+		if !strings.Contains(line, "-cert-v01@openssh.com ") {
+			continue
+		}
+		pk, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			continue
+		}
+		cert, ok := pk.(*ssh.Certificate)
+		if !ok || cert.CertType != ssh.UserCert {
+			continue
+		}
+		if err := checkValidCert(cert); err != nil {
+			return nil, fmt.Errorf("%s not a valid cert: %v", path, err)
+		}
+		return ssh.NewCertSigner(cert, keySigner)
+	}
+	return nil, fmt.Errorf("no SSH certificate found in %s", path)
+}
+
 func ReadCertificate(certificatePath string, keySigner ssh.Signer) (ssh.Signer, error) {
 
 	if certificatePath == "" {
@@ -0,0 +1,72 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestIdentityFileSigner(t *testing.T) {
+	user, err := NewKeyPair(CreateKeyPairConfig{Type: Ecdsa})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := NewKeyPair(CreateKeyPairConfig{Type: Ecdsa})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caSigner, err := gossh.ParsePrivateKey(ca.PrivateKeyPemBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userKey, _, _, _, err := gossh.ParseAuthorizedKey(user.PublicKeyAuthorizedKeysLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &gossh.Certificate{
+		Key:             userKey,
+		CertType:        gossh.UserCert,
+		ValidPrincipals: []string{"packer"},
+		ValidAfter:      uint64(time.Now().Add(-time.Minute).Unix()),
+		ValidBefore:     uint64(time.Now().Add(time.Hour).Unix()),
+	}
+	if err := cert.SignCert(rand.Reader, caSigner); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "packer-identity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Identity files hold the private key, the SSH certificate, and other
+	// certificates.
+	identity := string(user.PrivateKeyPemBlock) +
+		string(gossh.MarshalAuthorizedKey(cert)) +
+		"@cert-authority *.example.com " + string(ca.PublicKeyAuthorizedKeysLine)
+	path := filepath.Join(dir, "identity")
+	if err := ioutil.WriteFile(path, []byte(identity), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := IdentityFileSigner(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := signer.PublicKey().(*gossh.Certificate); !ok {
+		t.Fatalf("the signer should use the certificate, got a %T", signer.PublicKey())
+	}
+
+	if err := ioutil.WriteFile(path, user.PrivateKeyPemBlock, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := IdentityFileSigner(path); err == nil {
+		t.Fatal("an identity file without a certificate should fail")
+	}
+}
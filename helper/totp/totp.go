@@ -0,0 +1,113 @@
+// Package totp computes the time-based one-time passwords of RFC 6238, the
+// second factor of the authenticator apps, from their shared secret.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key is the shared secret of a TOTP generator with its settings.
+type Key struct {
+	Secret    []byte
+	Digits    int
+	Period    time.Duration
+	Algorithm func() hash.Hash
+}
+
+// Parse parses a TOTP secret: either the base32 seed displayed by most
+// services, case insensitive and with optional spaces and padding, or an
+// otpauth://totp/ URI, whose digits, period and algorithm parameters are
+// honoured. The defaults are 6 digits, 30 seconds and SHA1.
+func Parse(s string) (*Key, error) {
+	k := &Key{
+		Digits:    6,
+		Period:    30 * time.Second,
+		Algorithm: sha1.New,
+	}
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToLower(s), "otpauth://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("malformed otpauth URI: %s", err)
+		}
+		if u.Host != "totp" {
+			return nil, fmt.Errorf("unsupported otpauth type %q, only totp is supported", u.Host)
+		}
+		q := u.Query()
+		s = q.Get("secret")
+		if v := q.Get("digits"); v != "" {
+			d, err := strconv.Atoi(v)
+			if err != nil || d < 6 || d > 8 {
+				return nil, fmt.Errorf("invalid digits %q, 6 to 8 expected", v)
+			}
+			k.Digits = d
+		}
+		if v := q.Get("period"); v != "" {
+			p, err := strconv.Atoi(v)
+			if err != nil || p <= 0 {
+				return nil, fmt.Errorf("invalid period %q", v)
+			}
+			k.Period = time.Duration(p) * time.Second
+		}
+		switch v := strings.ToUpper(q.Get("algorithm")); v {
+		case "", "SHA1":
+		case "SHA256":
+			k.Algorithm = sha256.New
+		case "SHA512":
+			k.Algorithm = sha512.New
+		default:
+			return nil, fmt.Errorf("unsupported algorithm %q", v)
+		}
+	}
+
+	s = strings.ToUpper(strings.Replace(s, " ", "", -1))
+	s = strings.TrimRight(s, "=")
+	if s == "" {
+		return nil, fmt.Errorf("empty TOTP secret")
+	}
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("TOTP secret is not valid base32: %s", err)
+	}
+	k.Secret = secret
+	return k, nil
+}
+
+// Code returns the one-time password of the key at t.
+func (k *Key) Code(t time.Time) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/int64(k.Period/time.Second)))
+	h := hmac.New(k.Algorithm, k.Secret)
+	h.Write(counter[:])
+	sum := h.Sum(nil)
+
+	// Dynamic truncation of RFC 4226.
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < k.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, value%mod)
+}
+
+// Code returns the current one-time password of secret, parsed like Parse
+// does.
+func Code(secret string) (string, error) {
+	k, err := Parse(secret)
+	if err != nil {
+		return "", err
+	}
+	return k.Code(time.Now()), nil
+}
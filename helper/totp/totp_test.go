@@ -0,0 +1,82 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyCode_rfc6238(t *testing.T) {
+	seeds := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	cases := []struct {
+		algorithm string
+		unix      int64
+		code      string
+	}{
+		{"SHA1", 59, "94287082"},
+		{"SHA256", 59, "46119246"},
+		{"SHA512", 59, "90693936"},
+		{"SHA1", 1111111109, "07081804"},
+		{"SHA256", 1111111109, "68084774"},
+		{"SHA512", 1111111109, "25091201"},
+		{"SHA1", 1111111111, "14050471"},
+		{"SHA1", 1234567890, "89005924"},
+		{"SHA1", 2000000000, "69279037"},
+		{"SHA1", 20000000000, "65353130"},
+	}
+	for _, tc := range cases {
+		secret := base32.StdEncoding.EncodeToString([]byte(seeds[tc.algorithm]))
+		uri := "otpauth://totp/packer?digits=8&algorithm=" + tc.algorithm +
+			"&secret=" + url.QueryEscape(secret)
+		k, err := Parse(uri)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.algorithm, err)
+		}
+		if got := k.Code(time.Unix(tc.unix, 0)); got != tc.code {
+			t.Fatalf("%s at %d: got %s, expected %s", tc.algorithm, tc.unix, got, tc.code)
+		}
+	}
+}
+
+func TestParse_base32(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	for _, s := range []string{
+		secret,
+		strings.ToLower(secret),
+		strings.TrimRight(secret, "="),
+		"  GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ ",
+	} {
+		k, err := Parse(s)
+		if err != nil {
+			t.Fatalf("%q: %s", s, err)
+		}
+		if k.Digits != 6 || k.Period != 30*time.Second {
+			t.Fatalf("%q: bad defaults: %d digits, %s", s, k.Digits, k.Period)
+		}
+		if got := k.Code(time.Unix(59, 0)); got != "287082" {
+			t.Fatalf("%q: got %s", s, got)
+		}
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"not base32!",
+		"otpauth://hotp/packer?secret=GEZDGNBV",
+		"otpauth://totp/packer?secret=GEZDGNBV&digits=4",
+		"otpauth://totp/packer?secret=GEZDGNBV&period=0",
+		"otpauth://totp/packer?secret=GEZDGNBV&algorithm=MD5",
+		"otpauth://totp/packer",
+	} {
+		if _, err := Parse(s); err == nil {
+			t.Fatalf("%q: expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// CustomRule is a rule declared in a rules file. It reports the blocks of
+// its kind and type setting its option, or only setting it to a value
+// equal to Equals or matching Matches. With Missing, it reports the blocks
+// not setting the option instead.
+type CustomRule struct {
+	Name string `json:"name"`
+	// Kind is the kind of the blocks to check: builder, provisioner or
+	// post-processor. All the blocks are checked when it is empty.
+	Kind string `json:"kind"`
+	// Type is the type of the blocks to check, or a glob pattern like
+	// "amazon-*". All the blocks are checked when it is empty.
+	Type string `json:"type"`
+
+	Option  string      `json:"option"`
+	Equals  interface{} `json:"equals"`
+	Matches string      `json:"matches"`
+	Missing bool        `json:"missing"`
+
+	// Level is the severity of the issues: error, warning or info. Issues
+	// are warnings by default.
+	Level   string `json:"severity"`
+	Message string `json:"message"`
+
+	severity Severity
+	matches  *regexp.Regexp
+}
+
+// rulesFile is the content of a rules file.
+type rulesFile struct {
+	Rules []*CustomRule `json:"rules"`
+}
+
+// LoadRules reads the custom rules of a rules file, by name.
+func LoadRules(path string) (map[string]Rule, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f rulesFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("error parsing rules file %s: %s", path, err)
+	}
+
+	rules := map[string]Rule{}
+	for i, r := range f.Rules {
+		if err := r.prepare(); err != nil {
+			return nil, fmt.Errorf("%s: rule %d: %s", path, i+1, err)
+		}
+		if _, ok := rules[r.Name]; ok {
+			return nil, fmt.Errorf("%s: rule %q is declared twice", path, r.Name)
+		}
+		rules[r.Name] = r
+	}
+	return rules, nil
+}
+
+func (r *CustomRule) prepare() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Option == "" {
+		return fmt.Errorf("option is required")
+	}
+	switch r.Kind {
+	case "", packer.LintBuilder, packer.LintProvisioner, packer.LintPostProcessor:
+	default:
+		return fmt.Errorf("unknown kind %q", r.Kind)
+	}
+	if _, err := filepath.Match(r.Type, ""); err != nil {
+		return fmt.Errorf("bad type pattern %q: %s", r.Type, err)
+	}
+	if r.Missing && (r.Equals != nil || r.Matches != "") {
+		return fmt.Errorf("missing can't be used with equals or matches")
+	}
+	r.severity = SeverityWarning
+	if r.Level != "" {
+		severity, err := ParseSeverity(r.Level)
+		if err != nil {
+			return err
+		}
+		r.severity = severity
+	}
+	if r.Matches != "" {
+		re, err := regexp.Compile(r.Matches)
+		if err != nil {
+			return fmt.Errorf("bad matches expression: %s", err)
+		}
+		r.matches = re
+	}
+	if r.Message == "" {
+		r.Message = fmt.Sprintf("%s is not allowed", r.Option)
+		if r.Missing {
+			r.Message = fmt.Sprintf("%s is required", r.Option)
+		}
+	}
+	return nil
+}
+
+func (r *CustomRule) Check(b *packer.LintBlock) []Issue {
+	if r.Kind != "" && r.Kind != b.Kind {
+		return nil
+	}
+	if r.Type != "" {
+		if ok, _ := filepath.Match(r.Type, b.Type); !ok {
+			return nil
+		}
+	}
+
+	if r.Missing {
+		if isSet(b, r.Option) {
+			return nil
+		}
+		return []Issue{{Message: r.Message}}
+	}
+
+	if !isSet(b, r.Option) {
+		return nil
+	}
+	if r.Equals != nil || r.matches != nil {
+		// Values that can't be known yet are not reported.
+		v, ok := stringOption(b, r.Option)
+		if !ok {
+			return nil
+		}
+		if r.Equals != nil && v != fmt.Sprint(r.Equals) {
+			return nil
+		}
+		if r.matches != nil && !r.matches.MatchString(v) {
+			return nil
+		}
+	}
+	return []Issue{{Option: r.Option, Message: r.Message}}
+}
+
+func (r *CustomRule) Severity() Severity {
+	return r.severity
+}
+
+func (r *CustomRule) Synopsis() string {
+	return r.Message
+}
@@ -0,0 +1,78 @@
+package lint
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func writeRules(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadRules(t *testing.T) {
+	path := writeRules(t, `{"rules": [
+		{"name": "no-public-ip", "kind": "builder", "type": "amazon-*", "option": "associate_public_ip_address", "equals": true, "severity": "error"},
+		{"name": "require-tags", "kind": "builder", "type": "amazon-ebs", "option": "tags", "missing": true},
+		{"name": "pinned-ami", "option": "source_ami", "matches": "^ami-"}
+	]}`)
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("bad: %#v", rules)
+	}
+	if s := rules["no-public-ip"].Severity(); s != SeverityError {
+		t.Fatalf("bad severity: %s", s)
+	}
+	if s := rules["require-tags"].Severity(); s != SeverityWarning {
+		t.Fatalf("bad default severity: %s", s)
+	}
+
+	cases := []struct {
+		rule    string
+		typ     string
+		options map[string]interface{}
+		issues  int
+	}{
+		{"no-public-ip", "amazon-ebs", map[string]interface{}{"associate_public_ip_address": true}, 1},
+		{"no-public-ip", "amazon-ebs", map[string]interface{}{"associate_public_ip_address": "true"}, 1},
+		{"no-public-ip", "amazon-ebs", map[string]interface{}{"associate_public_ip_address": false}, 0},
+		{"no-public-ip", "googlecompute", map[string]interface{}{"associate_public_ip_address": true}, 0},
+		{"require-tags", "amazon-ebs", map[string]interface{}{}, 1},
+		{"require-tags", "amazon-ebs", map[string]interface{}{"tags": map[string]interface{}{"a": "b"}}, 0},
+		{"pinned-ami", "amazon-ebs", map[string]interface{}{"source_ami": "ami-1234"}, 1},
+		{"pinned-ami", "amazon-ebs", map[string]interface{}{"source_ami": nil}, 0},
+	}
+	for _, tc := range cases {
+		b := &packer.LintBlock{Kind: packer.LintBuilder, Type: tc.typ, Options: tc.options}
+		if issues := rules[tc.rule].Check(b); len(issues) != tc.issues {
+			t.Errorf("%s %s %v: expected %d issues, got %#v", tc.rule, tc.typ, tc.options, tc.issues, issues)
+		}
+	}
+}
+
+func TestLoadRules_invalid(t *testing.T) {
+	cases := []string{
+		`{"rules": [{"option": "a"}]}`,
+		`{"rules": [{"name": "a"}]}`,
+		`{"rules": [{"name": "a", "option": "a", "kind": "source"}]}`,
+		`{"rules": [{"name": "a", "option": "a", "severity": "fatal"}]}`,
+		`{"rules": [{"name": "a", "option": "a", "matches": "("}]}`,
+		`{"rules": [{"name": "a", "option": "a", "missing": true, "equals": 1}]}`,
+		`{"rules": [{"name": "a", "option": "a"}, {"name": "a", "option": "b"}]}`,
+		`not json`,
+	}
+	for _, content := range cases {
+		if _, err := LoadRules(writeRules(t, content)); err == nil {
+			t.Errorf("%s: should error", content)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// Severity is how serious an issue is.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	if int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// ParseSeverity returns the severity called name.
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if n == name {
+			return Severity(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q, expected one of %s", name, strings.Join(severityNames, ", "))
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	v, err := ParseSeverity(string(text))
+	if err == nil {
+		*s = v
+	}
+	return err
+}
+
+// An Issue is a problem found in a configuration by a rule.
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Block describes the builder, provisioner or post-processor the issue
+	// was found in.
+	Block string `json:"block"`
+	// Option is the name of the option the issue is about, if any.
+	Option  string `json:"option,omitempty"`
+	Message string `json:"message"`
+
+	Filename string `json:"filename,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+func (i *Issue) String() string {
+	pos := i.Filename
+	if i.Line > 0 {
+		pos = fmt.Sprintf("%s:%d", pos, i.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s (%s)", pos, i.Severity, i.Block, i.Message, i.Rule)
+}
+
+// A Rule checks the builders, provisioners and post-processors of
+// configurations.
+type Rule interface {
+	// Check returns the issues found in a block. Only the Option and the
+	// Message of the issues need to be set.
+	Check(b *packer.LintBlock) []Issue
+
+	// Severity returns the severity of the issues found by the rule.
+	Severity() Severity
+
+	// Synopsis returns a string description of what the rule checks.
+	Synopsis() string
+}
+
+// Rules is the map of all available rules, by name.
+var Rules map[string]Rule
+
+func init() {
+	Rules = map[string]Rule{
+		"deprecated-options": new(RuleDeprecatedOptions),
+		"insecure-winrm":     new(RuleInsecureWinRM),
+		"ssh-password-auth":  new(RuleSSHPasswordAuth),
+		"missing-checksum":   new(RuleMissingChecksum),
+		"long-boot-wait":     new(RuleLongBootWait),
+	}
+}
+
+// Register adds a rule to the available rules. Registering a rule with the
+// name of an existing rule replaces it.
+func Register(name string, r Rule) {
+	Rules[name] = r
+}
+
+// Run checks the blocks with the rules and returns the issues found, sorted
+// by position.
+func Run(blocks []*packer.LintBlock, rules map[string]Rule) []Issue {
+	names := make([]string, 0, len(rules))
+	for n := range rules {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var issues []Issue
+	for _, b := range blocks {
+		for _, n := range names {
+			r := rules[n]
+			for _, i := range r.Check(b) {
+				rng := b.OptionRange(i.Option)
+				i.Rule = n
+				i.Severity = r.Severity()
+				i.Block = b.String()
+				i.Filename = rng.Filename
+				i.Line = rng.Start.Line
+				issues = append(issues, i)
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Filename != issues[j].Filename {
+			return issues[i].Filename < issues[j].Filename
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues
+}
@@ -0,0 +1,70 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []Severity{SeverityInfo, SeverityWarning, SeverityError} {
+		actual, err := ParseSeverity(s.String())
+		if err != nil || actual != s {
+			t.Fatalf("%s: got %s, %v", s, actual, err)
+		}
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestRun(t *testing.T) {
+	blocks := []*packer.LintBlock{
+		{
+			Kind: packer.LintBuilder,
+			Type: "virtualbox-iso",
+			Name: "source.virtualbox-iso.ubuntu",
+			Options: map[string]interface{}{
+				"boot_wait":      "10m",
+				"winrm_insecure": true,
+			},
+			Range: hcl.Range{Filename: "ubuntu.pkr.hcl", Start: hcl.Pos{Line: 1}},
+			OptionRanges: map[string]hcl.Range{
+				"boot_wait":      {Filename: "ubuntu.pkr.hcl", Start: hcl.Pos{Line: 4}},
+				"winrm_insecure": {Filename: "ubuntu.pkr.hcl", Start: hcl.Pos{Line: 3}},
+			},
+		},
+		{
+			Kind:    packer.LintProvisioner,
+			Type:    "shell",
+			Options: map[string]interface{}{"inline": []interface{}{"true"}},
+			Range:   hcl.Range{Filename: "ubuntu.pkr.hcl", Start: hcl.Pos{Line: 10}},
+		},
+	}
+	rules := map[string]Rule{
+		"insecure-winrm": new(RuleInsecureWinRM),
+		"long-boot-wait": new(RuleLongBootWait),
+	}
+
+	issues := Run(blocks, rules)
+	if len(issues) != 2 {
+		t.Fatalf("bad: %#v", issues)
+	}
+	expected := Issue{
+		Rule:     "insecure-winrm",
+		Severity: SeverityWarning,
+		Block:    "builder 'source.virtualbox-iso.ubuntu' (virtualbox-iso)",
+		Option:   "winrm_insecure",
+		Message:  issues[0].Message,
+		Filename: "ubuntu.pkr.hcl",
+		Line:     3,
+	}
+	if !reflect.DeepEqual(issues[0], expected) {
+		t.Fatalf("got %#v, expected %#v", issues[0], expected)
+	}
+	if issues[1].Rule != "long-boot-wait" || issues[1].Line != 4 {
+		t.Fatalf("bad: %#v", issues[1])
+	}
+}
@@ -0,0 +1,61 @@
+package lint
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// isSet tells whether the option called name is set in b, even when its
+// value can't be known before the build runs.
+func isSet(b *packer.LintBlock, name string) bool {
+	v, ok := b.Options[name]
+	if !ok {
+		return false
+	}
+	if s, isString := v.(string); isString && s == "" {
+		return false
+	}
+	return true
+}
+
+// stringOption returns the value of the option called name, when it is
+// set and known.
+func stringOption(b *packer.LintBlock, name string) (string, bool) {
+	v, ok := b.Options[name]
+	if !ok || v == nil {
+		return "", false
+	}
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool, int, int64, float64:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// boolOption returns the value of the boolean option called name, when it
+// is set and known. Strings like "true" are accepted, like when decoding
+// the config.
+func boolOption(b *packer.LintBlock, name string) (bool, bool) {
+	s, ok := stringOption(b, name)
+	if !ok {
+		return false, false
+	}
+	v, err := strconv.ParseBool(s)
+	return v, err == nil
+}
+
+// durationOption returns the value of the duration option called name, when
+// it is set and known.
+func durationOption(b *packer.LintBlock, name string) (time.Duration, bool) {
+	s, ok := stringOption(b, name)
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(s)
+	return d, err == nil
+}
@@ -0,0 +1,90 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/packer/fix"
+	"github.com/hashicorp/packer/packer"
+)
+
+// RuleDeprecatedOptions reports the blocks that packer fix would change,
+// because they use deprecated options.
+type RuleDeprecatedOptions struct{}
+
+// fixSections are the sections of a template the blocks are in, by kind.
+var fixSections = map[string]string{
+	packer.LintBuilder:       "builders",
+	packer.LintProvisioner:   "provisioners",
+	packer.LintPostProcessor: "post-processors",
+}
+
+func (RuleDeprecatedOptions) Check(b *packer.LintBlock) []Issue {
+	section := fixSections[b.Kind]
+	before, err := fixInput(b)
+	if err != nil {
+		return nil
+	}
+
+	var issues []Issue
+	for _, name := range fix.FixerOrder {
+		fixer := fix.Fixers[name]
+		input, _ := fixInput(b)
+		output, err := fixer.Fix(input)
+		if err != nil {
+			continue
+		}
+		after, err := roundTrip(output[section])
+		if err != nil || reflect.DeepEqual(before[section], after) {
+			continue
+		}
+
+		option := ""
+		for _, o := range fixer.DeprecatedOptions() {
+			if _, ok := b.Options[o]; ok {
+				option = o
+				break
+			}
+		}
+		issues = append(issues, Issue{
+			Option:  option,
+			Message: fmt.Sprintf("deprecated configuration, fixed by packer fix with the %q fixer: %s", name, fixer.Synopsis()),
+		})
+	}
+	return issues
+}
+
+// fixInput returns a template holding a copy of b only, for the fixers to
+// change.
+func fixInput(b *packer.LintBlock) (map[string]interface{}, error) {
+	block := map[string]interface{}{"type": b.Type}
+	for k, v := range b.Options {
+		block[k] = v
+	}
+	section, err := roundTrip([]interface{}{block})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{fixSections[b.Kind]: section}, nil
+}
+
+// roundTrip returns v as decoded from JSON, so that values of different Go
+// types can be compared.
+func roundTrip(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	err = json.Unmarshal(raw, &out)
+	return out, err
+}
+
+func (RuleDeprecatedOptions) Severity() Severity {
+	return SeverityWarning
+}
+
+func (RuleDeprecatedOptions) Synopsis() string {
+	return `Reports the options that "packer fix" would update`
+}
@@ -0,0 +1,28 @@
+package lint
+
+import "github.com/hashicorp/packer/packer"
+
+// RuleInsecureWinRM reports the builders that don't verify the certificate
+// of the WinRM server.
+type RuleInsecureWinRM struct{}
+
+func (RuleInsecureWinRM) Check(b *packer.LintBlock) []Issue {
+	if b.Kind != packer.LintBuilder {
+		return nil
+	}
+	if insecure, _ := boolOption(b, "winrm_insecure"); !insecure {
+		return nil
+	}
+	return []Issue{{
+		Option:  "winrm_insecure",
+		Message: "the certificate of the WinRM server is not verified, anyone on the network can impersonate the machine",
+	}}
+}
+
+func (RuleInsecureWinRM) Severity() Severity {
+	return SeverityWarning
+}
+
+func (RuleInsecureWinRM) Synopsis() string {
+	return `Reports builders setting "winrm_insecure"`
+}
@@ -0,0 +1,38 @@
+package lint
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// maxBootWait is the longest boot_wait not reported.
+const maxBootWait = 5 * time.Minute
+
+// RuleLongBootWait reports the builders waiting for so long before typing
+// their boot command that every build is slowed down.
+type RuleLongBootWait struct{}
+
+func (RuleLongBootWait) Check(b *packer.LintBlock) []Issue {
+	if b.Kind != packer.LintBuilder {
+		return nil
+	}
+	wait, ok := durationOption(b, "boot_wait")
+	if !ok || wait <= maxBootWait {
+		return nil
+	}
+	return []Issue{{
+		Option: "boot_wait",
+		Message: fmt.Sprintf("boot_wait is %s, longer than %s; wait in the boot command with <waitXX> "+
+			"or for the communicator to connect instead", wait, maxBootWait),
+	}}
+}
+
+func (RuleLongBootWait) Severity() Severity {
+	return SeverityWarning
+}
+
+func (RuleLongBootWait) Synopsis() string {
+	return fmt.Sprintf(`Reports builders with a "boot_wait" longer than %s`, maxBootWait)
+}
@@ -0,0 +1,40 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// RuleMissingChecksum reports the builders downloading an ISO without
+// verifying its checksum.
+type RuleMissingChecksum struct{}
+
+func (RuleMissingChecksum) Check(b *packer.LintBlock) []Issue {
+	if b.Kind != packer.LintBuilder {
+		return nil
+	}
+	if !isSet(b, "iso_url") && !isSet(b, "iso_urls") {
+		return nil
+	}
+	if !isSet(b, "iso_checksum") {
+		return []Issue{{
+			Message: "the ISO is downloaded without iso_checksum, its content is never verified",
+		}}
+	}
+	if checksum, _ := stringOption(b, "iso_checksum"); strings.EqualFold(checksum, "none") {
+		return []Issue{{
+			Option:  "iso_checksum",
+			Message: `iso_checksum is "none", the content of the downloaded ISO is never verified`,
+		}}
+	}
+	return nil
+}
+
+func (RuleMissingChecksum) Severity() Severity {
+	return SeverityError
+}
+
+func (RuleMissingChecksum) Synopsis() string {
+	return `Reports builders downloading an ISO without "iso_checksum"`
+}
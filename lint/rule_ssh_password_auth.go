@@ -0,0 +1,31 @@
+package lint
+
+import "github.com/hashicorp/packer/packer"
+
+// RuleSSHPasswordAuth reports the builders connecting over SSH with a
+// password instead of a key pair.
+type RuleSSHPasswordAuth struct{}
+
+func (RuleSSHPasswordAuth) Check(b *packer.LintBlock) []Issue {
+	if b.Kind != packer.LintBuilder || !isSet(b, "ssh_password") {
+		return nil
+	}
+	if isSet(b, "ssh_private_key_file") {
+		return nil
+	}
+	if agent, _ := boolOption(b, "ssh_agent_auth"); agent {
+		return nil
+	}
+	return []Issue{{
+		Option:  "ssh_password",
+		Message: "SSH uses password authentication, prefer a key pair with ssh_private_key_file or a temporary key pair",
+	}}
+}
+
+func (RuleSSHPasswordAuth) Severity() Severity {
+	return SeverityWarning
+}
+
+func (RuleSSHPasswordAuth) Synopsis() string {
+	return `Reports builders connecting over SSH with "ssh_password" only`
+}
@@ -0,0 +1,58 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestRules(t *testing.T) {
+	cases := []struct {
+		rule    Rule
+		kind    string
+		typ     string
+		options map[string]interface{}
+		// option is the option of the issue expected, "-" for no issue.
+		option string
+	}{
+		{new(RuleDeprecatedOptions), packer.LintBuilder, "vmware-iso", map[string]interface{}{"ssh_key_path": "key"}, "ssh_key_path"},
+		{new(RuleDeprecatedOptions), packer.LintBuilder, "vmware-iso", map[string]interface{}{"ssh_private_key_file": "key"}, "-"},
+		{new(RuleDeprecatedOptions), packer.LintPostProcessor, "manifest", map[string]interface{}{"filename": "manifest.json"}, "filename"},
+		{new(RuleDeprecatedOptions), packer.LintProvisioner, "shell", map[string]interface{}{"inline": []interface{}{"true"}}, "-"},
+
+		{new(RuleInsecureWinRM), packer.LintBuilder, "amazon-ebs", map[string]interface{}{"winrm_insecure": true}, "winrm_insecure"},
+		{new(RuleInsecureWinRM), packer.LintBuilder, "amazon-ebs", map[string]interface{}{"winrm_insecure": "true"}, "winrm_insecure"},
+		{new(RuleInsecureWinRM), packer.LintBuilder, "amazon-ebs", map[string]interface{}{"winrm_insecure": false}, "-"},
+		{new(RuleInsecureWinRM), packer.LintBuilder, "amazon-ebs", map[string]interface{}{"winrm_insecure": nil}, "-"},
+
+		{new(RuleSSHPasswordAuth), packer.LintBuilder, "qemu", map[string]interface{}{"ssh_password": "packer"}, "ssh_password"},
+		{new(RuleSSHPasswordAuth), packer.LintBuilder, "qemu", map[string]interface{}{"ssh_password": nil}, "ssh_password"},
+		{new(RuleSSHPasswordAuth), packer.LintBuilder, "qemu", map[string]interface{}{"ssh_password": "packer", "ssh_private_key_file": "key"}, "-"},
+		{new(RuleSSHPasswordAuth), packer.LintBuilder, "qemu", map[string]interface{}{"ssh_password": "packer", "ssh_agent_auth": true}, "-"},
+		{new(RuleSSHPasswordAuth), packer.LintBuilder, "qemu", map[string]interface{}{}, "-"},
+
+		{new(RuleMissingChecksum), packer.LintBuilder, "qemu", map[string]interface{}{"iso_url": "http://example.com/a.iso"}, ""},
+		{new(RuleMissingChecksum), packer.LintBuilder, "qemu", map[string]interface{}{"iso_urls": []interface{}{"a.iso"}, "iso_checksum": "none"}, "iso_checksum"},
+		{new(RuleMissingChecksum), packer.LintBuilder, "qemu", map[string]interface{}{"iso_url": "a.iso", "iso_checksum": "sha256:abcd"}, "-"},
+		{new(RuleMissingChecksum), packer.LintBuilder, "qemu", map[string]interface{}{"iso_url": "a.iso", "iso_checksum": nil}, "-"},
+		{new(RuleMissingChecksum), packer.LintBuilder, "amazon-ebs", map[string]interface{}{}, "-"},
+
+		{new(RuleLongBootWait), packer.LintBuilder, "qemu", map[string]interface{}{"boot_wait": "10m"}, "boot_wait"},
+		{new(RuleLongBootWait), packer.LintBuilder, "qemu", map[string]interface{}{"boot_wait": "5m"}, "-"},
+		{new(RuleLongBootWait), packer.LintBuilder, "qemu", map[string]interface{}{"boot_wait": "{{user `wait`}}"}, "-"},
+	}
+
+	for _, tc := range cases {
+		b := &packer.LintBlock{Kind: tc.kind, Type: tc.typ, Options: tc.options}
+		issues := tc.rule.Check(b)
+		if tc.option == "-" {
+			if len(issues) != 0 {
+				t.Errorf("%T %v: unexpected issues: %#v", tc.rule, tc.options, issues)
+			}
+			continue
+		}
+		if len(issues) != 1 || issues[0].Option != tc.option || issues[0].Message == "" {
+			t.Errorf("%T %v: expected an issue about %q, got %#v", tc.rule, tc.options, tc.option, issues)
+		}
+	}
+}
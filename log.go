@@ -7,49 +7,57 @@ import (
 	"io"
 	"os"
 	"strings"
+
+	"github.com/hashicorp/packer/packer/logging"
 )
 
 // These are the environmental variables that determine if we log, and if
 // we log whether or not the log should go to a file.
-const EnvLog = "PACKER_LOG"          //Set to True
-const EnvLogFile = "PACKER_LOG_PATH" //Set to a file
+const EnvLog = logging.EnvLog         //Set to True or to a level
+const EnvLogFile = logging.EnvLogPath //Set to a file
 
 // logOutput determines where we should send logs (if anywhere).
 func logOutput() (logOutput io.Writer, err error) {
-	logOutput = nil
-	if os.Getenv(EnvLog) != "" && os.Getenv(EnvLog) != "0" {
-		logOutput = os.Stderr
+	config, err := logging.ConfigFromEnv()
+	if err != nil || config == nil {
+		return nil, err
+	}
 
-		if logPath := os.Getenv(EnvLogFile); logPath != "" {
-			var err error
-			logOutput, err = os.Create(logPath)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			// no path; do a little light filtering to avoid double-dipping UI
-			// calls.
-			r, w := io.Pipe()
-			scanner := bufio.NewScanner(r)
-			scanner.Split(ScanLinesSmallerThanBuffer)
+	if config.Path != "" {
+		logOutput, err = logging.OpenFile(config)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// no path; do a little light filtering to avoid double-dipping UI
+		// calls.
+		r, w := io.Pipe()
+		scanner := bufio.NewScanner(r)
+		scanner.Split(ScanLinesSmallerThanBuffer)
 
-			go func(scanner *bufio.Scanner) {
-				for scanner.Scan() {
-					if strings.Contains(scanner.Text(), "ui:") {
-						continue
-					}
-					if strings.Contains(scanner.Text(), "ui error:") {
-						continue
-					}
-					os.Stderr.WriteString(fmt.Sprint(scanner.Text() + "\n"))
+		go func(scanner *bufio.Scanner) {
+			for scanner.Scan() {
+				if strings.Contains(scanner.Text(), "ui:") {
+					continue
 				}
-				if err := scanner.Err(); err != nil {
-					os.Stderr.WriteString(err.Error())
-					w.Close()
+				if strings.Contains(scanner.Text(), "ui error:") {
+					continue
 				}
-			}(scanner)
-			logOutput = w
-		}
+				os.Stderr.WriteString(fmt.Sprint(scanner.Text() + "\n"))
+			}
+			if err := scanner.Err(); err != nil {
+				os.Stderr.WriteString(err.Error())
+				w.Close()
+			}
+		}(scanner)
+		logOutput = w
+	}
+
+	if config.Filtered() {
+		// The wrapped process and the plugins log the callers of the log
+		// calls for the filter to find the subsystems logging.
+		os.Setenv(logging.EnvLogCallers, "1")
+		logOutput = logging.NewFilter(config, logOutput)
 	}
 
 	return
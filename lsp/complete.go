@@ -0,0 +1,426 @@
+package lsp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Kind is a kind of component.
+type Kind string
+
+const (
+	Builder       Kind = "builder"
+	Provisioner   Kind = "provisioner"
+	PostProcessor Kind = "post-processor"
+)
+
+// Components are the builders, provisioners and post-processors whose
+// options the server completes and documents.
+type Components interface {
+	// Types returns the types of the components of kind.
+	Types(kind Kind) []string
+	// Spec returns the spec of the config of the component, nil when the
+	// component is unknown.
+	Spec(kind Kind, typ string) hcldec.ObjectSpec
+	// Doc returns the doc of the option of the component, or "".
+	Doc(kind Kind, typ, option string) string
+}
+
+// keyword is a block or an attribute of the templates themselves, rather
+// than of a component.
+type keyword struct {
+	name   string
+	block  bool
+	detail string
+}
+
+// topLevelKeywords are the blocks of a template, see hcl2template.
+var topLevelKeywords = []keyword{
+	{name: "build", block: true, detail: "build {}"},
+	{name: "import", block: true, detail: "import {}"},
+	{name: "locals", block: true, detail: "locals {}"},
+	{name: "packer", block: true, detail: `packer {}`},
+	{name: "source", block: true, detail: `source "TYPE" "NAME" {}`},
+	{name: "variable", block: true, detail: `variable "NAME" {}`},
+	{name: "variables", block: true, detail: "variables {}"},
+}
+
+// buildKeywords are the blocks and attributes of a build block.
+var buildKeywords = []keyword{
+	{name: "build_timeout", detail: "string"},
+	{name: "depends_on", detail: "list of string"},
+	{name: "description", detail: "string"},
+	{name: "error_handling", block: true, detail: "error_handling {}"},
+	{name: "locks", detail: "list of string"},
+	{name: "name", detail: "string"},
+	{name: "output", block: true, detail: `output "NAME" {}`},
+	{name: "post-processor", block: true, detail: `post-processor "TYPE" {}`},
+	{name: "post-processors", block: true, detail: "post-processors {}"},
+	{name: "post_process_timeout", detail: "string"},
+	{name: "provision_timeout", detail: "string"},
+	{name: "provisioner", block: true, detail: `provisioner "TYPE" {}`},
+	{name: "source", block: true, detail: `source "TYPE.NAME" {}`},
+	{name: "sources", detail: "list of string"},
+	{name: "tags", detail: "list of string"},
+	{name: "verify", block: true, detail: "verify {}"},
+}
+
+// provisionerKeywords are the options every provisioner block has.
+var provisionerKeywords = []keyword{
+	{name: "error_handling", block: true, detail: "error_handling {}"},
+	{name: "except", detail: "list of string"},
+	{name: "max_retries", detail: "number"},
+	{name: "name", detail: "string"},
+	{name: "only", detail: "list of string"},
+	{name: "pause_before", detail: "string"},
+	{name: "timeout", detail: "string"},
+}
+
+// postProcessorKeywords are the options every post-processor block has.
+var postProcessorKeywords = []keyword{
+	{name: "except", detail: "list of string"},
+	{name: "keep_input_artifact", detail: "bool"},
+	{name: "name", detail: "string"},
+	{name: "only", detail: "list of string"},
+}
+
+// blockHeader is the header of a block enclosing a position, like
+// `source "amazon-ebs" "example"`. Object expressions, like `tags = {`,
+// are headers too, with object set.
+type blockHeader struct {
+	typ    string
+	labels []string
+	object bool
+}
+
+// scanBlocks returns the blocks enclosing the end of src, outermost first,
+// and the tokens of the last line of src. It only needs src to be lexically
+// valid, so that it works on templates being edited.
+func scanBlocks(src []byte) ([]blockHeader, hclsyntax.Tokens) {
+	tokens, _ := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+
+	var stack []blockHeader
+	var line hclsyntax.Tokens
+	for _, tok := range tokens {
+		switch tok.Type {
+		case hclsyntax.TokenEOF:
+			return stack, line
+		case hclsyntax.TokenOBrace:
+			stack = append(stack, newBlockHeader(line))
+			line = nil
+		case hclsyntax.TokenCBrace:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			line = nil
+		case hclsyntax.TokenNewline:
+			line = nil
+		default:
+			line = append(line, tok)
+		}
+	}
+	return stack, line
+}
+
+// newBlockHeader returns the header of the block opened by the tokens before
+// its brace.
+func newBlockHeader(tokens hclsyntax.Tokens) blockHeader {
+	if len(tokens) == 0 || tokens[0].Type != hclsyntax.TokenIdent {
+		return blockHeader{object: true}
+	}
+	h := blockHeader{typ: string(tokens[0].Bytes)}
+	for i := 1; i < len(tokens); i++ {
+		switch tokens[i].Type {
+		case hclsyntax.TokenIdent:
+			h.labels = append(h.labels, string(tokens[i].Bytes))
+		case hclsyntax.TokenOQuote:
+			label := ""
+			if i+1 < len(tokens) && tokens[i+1].Type == hclsyntax.TokenQuotedLit {
+				i++
+				label = string(tokens[i].Bytes)
+			}
+			if i+1 >= len(tokens) || tokens[i+1].Type != hclsyntax.TokenCQuote {
+				return blockHeader{object: true}
+			}
+			i++
+			h.labels = append(h.labels, label)
+		default:
+			return blockHeader{object: true}
+		}
+	}
+	return h
+}
+
+// blockContext is what a position in a template is in.
+type blockContext struct {
+	// keywords are the options of the template itself at the position.
+	keywords []keyword
+	// kind and typ are the component configured at the position, if any, and
+	// nested the blocks of its config enclosing the position.
+	kind   Kind
+	typ    string
+	nested []string
+}
+
+// resolveContext returns the context of a position enclosed by stack. ok is
+// false when the position is in something the server doesn't know, like an
+// object expression or a variable block.
+func resolveContext(stack []blockHeader) (ctx blockContext, ok bool) {
+	for _, h := range stack {
+		if h.object {
+			return ctx, false
+		}
+	}
+	if len(stack) == 0 {
+		return blockContext{keywords: topLevelKeywords}, true
+	}
+
+	component := func(kind Kind, h blockHeader, nested []blockHeader) (blockContext, bool) {
+		if len(h.labels) == 0 {
+			return blockContext{}, false
+		}
+		ctx := blockContext{kind: kind, typ: h.labels[0]}
+		if len(nested) == 0 {
+			switch kind {
+			case Provisioner:
+				ctx.keywords = provisionerKeywords
+			case PostProcessor:
+				ctx.keywords = postProcessorKeywords
+			}
+		}
+		for _, n := range nested {
+			ctx.nested = append(ctx.nested, n.typ)
+		}
+		return ctx, true
+	}
+
+	switch stack[0].typ {
+	case "source":
+		return component(Builder, stack[0], stack[1:])
+	case "build":
+		if len(stack) == 1 {
+			return blockContext{keywords: buildKeywords}, true
+		}
+		h := stack[1]
+		switch h.typ {
+		case "source":
+			if len(h.labels) == 0 {
+				return ctx, false
+			}
+			// Sources are referenced as TYPE.NAME or source.TYPE.NAME.
+			ref := strings.Split(strings.TrimPrefix(h.labels[0], "source."), ".")
+			h.labels = []string{ref[0]}
+			return component(Builder, h, stack[2:])
+		case "provisioner":
+			return component(Provisioner, h, stack[2:])
+		case "post-processor":
+			return component(PostProcessor, h, stack[2:])
+		case "post-processors":
+			if len(stack) == 2 {
+				return blockContext{keywords: []keyword{
+					{name: "post-processor", block: true, detail: `post-processor "TYPE" {}`},
+				}}, true
+			}
+			if stack[2].typ == "post-processor" {
+				return component(PostProcessor, stack[2], stack[3:])
+			}
+		}
+	}
+	return ctx, false
+}
+
+// nestedSpec returns the spec of the blocks named nested of spec, nil when
+// there is none.
+func nestedSpec(spec hcldec.ObjectSpec, nested []string) hcldec.ObjectSpec {
+	for _, name := range nested {
+		var next hcldec.ObjectSpec
+		for _, s := range spec {
+			if typeName, child := blockSpec(s); typeName == name {
+				next, _ = child.(hcldec.ObjectSpec)
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		spec = next
+	}
+	return spec
+}
+
+// blockSpec returns the type name and nested spec of the block spec s, "" when
+// s is not a block spec.
+func blockSpec(s hcldec.Spec) (string, hcldec.Spec) {
+	switch s := s.(type) {
+	case *hcldec.BlockSpec:
+		return s.TypeName, s.Nested
+	case *hcldec.BlockListSpec:
+		return s.TypeName, s.Nested
+	case *hcldec.BlockSetSpec:
+		return s.TypeName, s.Nested
+	case *hcldec.BlockTupleSpec:
+		return s.TypeName, s.Nested
+	case *hcldec.BlockMapSpec:
+		return s.TypeName, s.Nested
+	case *hcldec.BlockObjectSpec:
+		return s.TypeName, s.Nested
+	case *hcldec.BlockAttrsSpec:
+		return s.TypeName, nil
+	}
+	return "", nil
+}
+
+// specOption returns the name of the option of spec s, whether it is a
+// block, and a short description of its type.
+func specOption(s hcldec.Spec) (name string, block bool, detail string) {
+	switch s := s.(type) {
+	case *hcldec.AttrSpec:
+		detail = s.Type.FriendlyName()
+		if s.Required {
+			detail += ", required"
+		}
+		return s.Name, false, detail
+	case *hcldec.BlockListSpec, *hcldec.BlockSetSpec, *hcldec.BlockTupleSpec:
+		name, _ := blockSpec(s)
+		return name, true, "blocks"
+	case *hcldec.BlockAttrsSpec:
+		return s.TypeName, true, "block of " + s.ElementType.FriendlyName()
+	}
+	if name, _ := blockSpec(s); name != "" {
+		return name, true, "block"
+	}
+	return "", false, ""
+}
+
+// completions returns the completions at offset in src.
+func completions(components Components, src []byte, offset int) []CompletionItem {
+	stack, line := scanBlocks(src[:offset])
+
+	// The type of a component, like `source "amaz`.
+	if len(line) >= 2 && line[0].Type == hclsyntax.TokenIdent && line[1].Type == hclsyntax.TokenOQuote &&
+		(len(line) == 2 || (len(line) == 3 && line[2].Type == hclsyntax.TokenQuotedLit)) {
+		var kind Kind
+		switch string(line[0].Bytes) {
+		case "source":
+			if len(stack) == 0 {
+				kind = Builder
+			}
+		case "provisioner":
+			kind = Provisioner
+		case "post-processor":
+			kind = PostProcessor
+		}
+		if kind == "" || components == nil {
+			return nil
+		}
+		var items []CompletionItem
+		for _, typ := range components.Types(kind) {
+			items = append(items, CompletionItem{Label: typ, Kind: kindClass, Detail: string(kind)})
+		}
+		return items
+	}
+
+	// Only options are completed, not their values.
+	if len(line) > 1 || (len(line) == 1 && line[0].Type != hclsyntax.TokenIdent) {
+		return nil
+	}
+	ctx, ok := resolveContext(stack)
+	if !ok {
+		return nil
+	}
+
+	var items []CompletionItem
+	for _, k := range ctx.keywords {
+		items = append(items, optionItem(k.name, k.block, k.detail, ""))
+	}
+	if ctx.kind != "" && components != nil {
+		spec := nestedSpec(components.Spec(ctx.kind, ctx.typ), ctx.nested)
+		for _, s := range spec {
+			name, block, detail := specOption(s)
+			if name == "" {
+				continue
+			}
+			items = append(items, optionItem(name, block, detail, components.Doc(ctx.kind, ctx.typ, name)))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items
+}
+
+func optionItem(name string, block bool, detail, doc string) CompletionItem {
+	item := CompletionItem{Label: name, Kind: kindProperty, Detail: detail, InsertText: name + " = "}
+	if block {
+		item.Kind = kindModule
+		item.InsertText = name + " "
+	}
+	if doc != "" {
+		item.Documentation = &MarkupContent{Kind: "markdown", Value: doc}
+	}
+	return item
+}
+
+// hover returns the doc of the option at offset in src, nil when there is
+// none.
+func hover(components Components, src []byte, offset int) *Hover {
+	tokens, _ := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+	var tok *hclsyntax.Token
+	for i := range tokens {
+		t := &tokens[i]
+		if t.Type == hclsyntax.TokenIdent && t.Range.Start.Byte <= offset && offset <= t.Range.End.Byte {
+			// Options start their line.
+			if i == 0 || tokens[i-1].Type == hclsyntax.TokenNewline || tokens[i-1].Type == hclsyntax.TokenOBrace {
+				tok = t
+			}
+			break
+		}
+	}
+	if tok == nil {
+		return nil
+	}
+
+	stack, _ := scanBlocks(src[:tok.Range.Start.Byte])
+	ctx, ok := resolveContext(stack)
+	if !ok {
+		return nil
+	}
+	name := string(tok.Bytes)
+
+	var detail, doc string
+	for _, k := range ctx.keywords {
+		if k.name == name {
+			detail = k.detail
+		}
+	}
+	if ctx.kind != "" && components != nil {
+		for _, s := range nestedSpec(components.Spec(ctx.kind, ctx.typ), ctx.nested) {
+			if n, _, d := specOption(s); n == name {
+				detail = d
+			}
+		}
+		doc = components.Doc(ctx.kind, ctx.typ, name)
+	}
+	if detail == "" && doc == "" {
+		return nil
+	}
+
+	value := fmt.Sprintf("`%s`", name)
+	if detail != "" {
+		value += fmt.Sprintf(" (%s)", detail)
+	}
+	if doc != "" {
+		value += "\n\n" + doc
+	}
+	r := tok.Range
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: value},
+		Range: &Range{
+			Start: bytePosition(src, r.Start.Byte),
+			End:   bytePosition(src, r.End.Byte),
+		},
+	}
+}
@@ -0,0 +1,175 @@
+package lsp
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type testComponents struct{}
+
+func (testComponents) Types(kind Kind) []string {
+	switch kind {
+	case Builder:
+		return []string{"amazon-ebs", "file"}
+	case Provisioner:
+		return []string{"shell"}
+	}
+	return nil
+}
+
+func (testComponents) Spec(kind Kind, typ string) hcldec.ObjectSpec {
+	switch {
+	case kind == Builder && typ == "amazon-ebs":
+		return hcldec.ObjectSpec{
+			"ami_name":      &hcldec.AttrSpec{Name: "ami_name", Type: cty.String, Required: true},
+			"instance_type": &hcldec.AttrSpec{Name: "instance_type", Type: cty.String},
+			"launch_block_device_mappings": &hcldec.BlockListSpec{
+				TypeName: "launch_block_device_mappings",
+				Nested: hcldec.ObjectSpec{
+					"device_name": &hcldec.AttrSpec{Name: "device_name", Type: cty.String},
+				},
+			},
+		}
+	case kind == Provisioner && typ == "shell":
+		return hcldec.ObjectSpec{
+			"inline": &hcldec.AttrSpec{Name: "inline", Type: cty.List(cty.String)},
+		}
+	}
+	return nil
+}
+
+func (testComponents) Doc(kind Kind, typ, option string) string {
+	if kind == Builder && option == "ami_name" {
+		return "The name of the resulting AMI."
+	}
+	return ""
+}
+
+func labels(items []CompletionItem) []string {
+	var l []string
+	for _, i := range items {
+		l = append(l, i.Label)
+	}
+	sort.Strings(l)
+	return l
+}
+
+func TestCompletions(t *testing.T) {
+	tc := []struct {
+		name string
+		// src is the template, the cursor is at the |.
+		src  string
+		want []string
+	}{
+		{
+			name: "top level",
+			src:  "|",
+			want: []string{"build", "import", "locals", "packer", "source", "variable", "variables"},
+		},
+		{
+			name: "builder types",
+			src:  `source "am|`,
+			want: []string{"amazon-ebs", "file"},
+		},
+		{
+			name: "source options",
+			src: `source "amazon-ebs" "example" {
+  ami_name = "{{timestamp}}"
+  inst|
+}`,
+			want: []string{"ami_name", "instance_type", "launch_block_device_mappings"},
+		},
+		{
+			name: "nested block options",
+			src: `source "amazon-ebs" "example" {
+  launch_block_device_mappings {
+    |
+  }
+}`,
+			want: []string{"device_name"},
+		},
+		{
+			name: "values are not completed",
+			src: `source "amazon-ebs" "example" {
+  ami_name = |
+}`,
+		},
+		{
+			name: "objects are not completed",
+			src: `source "amazon-ebs" "example" {
+  tags = {
+    |
+  }
+}`,
+		},
+		{
+			name: "build source options",
+			src: `build {
+  source "source.amazon-ebs.example" {
+    |
+  }
+}`,
+			want: []string{"ami_name", "instance_type", "launch_block_device_mappings"},
+		},
+		{
+			name: "provisioner types",
+			src: `build {
+  provisioner "|`,
+			want: []string{"shell"},
+		},
+		{
+			name: "provisioner options",
+			src: `build {
+  sources = ["source.amazon-ebs.example"]
+  provisioner "shell" {
+    inline = ["echo ${source.name} }"]
+    |
+  }
+}`,
+			want: []string{"error_handling", "except", "inline", "max_retries", "name", "only", "pause_before", "timeout"},
+		},
+		{
+			name: "unknown component",
+			src: `source "unknown" "example" {
+  |
+}`,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			offset := strings.Index(tt.src, "|")
+			src := []byte(strings.Replace(tt.src, "|", "", 1))
+			got := labels(completions(testComponents{}, src, offset))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("unexpected completions: %s", diff)
+			}
+		})
+	}
+}
+
+func TestHover(t *testing.T) {
+	src := []byte(`source "amazon-ebs" "example" {
+  ami_name = "packer"
+}`)
+	h := hover(testComponents{}, src, strings.Index(string(src), "ami_name")+2)
+	if h == nil {
+		t.Fatal("expected the doc of ami_name")
+	}
+	want := "`ami_name` (string, required)\n\nThe name of the resulting AMI."
+	if h.Contents.Value != want {
+		t.Fatalf("unexpected hover %q", h.Contents.Value)
+	}
+	if diff := cmp.Diff(&Range{Start: Position{1, 2}, End: Position{1, 10}}, h.Range); diff != "" {
+		t.Fatalf("unexpected range: %s", diff)
+	}
+
+	if h := hover(testComponents{}, src, strings.Index(string(src), "packer")+1); h != nil {
+		t.Fatalf("values should not have docs, got %q", h.Contents.Value)
+	}
+}
@@ -0,0 +1,113 @@
+package lsp
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Docs are the docs of the options of the components, generated from the
+// comments of their config structs by struct-markdown into the partials of
+// the website, like website/pages/partials/builder/amazon/ebs.
+type Docs struct {
+	// options are the docs of the options by package directory, like
+	// builder/amazon/ebs, and option name.
+	options map[string]map[string]string
+	dirs    []string
+}
+
+// optionDoc matches the first line of the doc of an option, like
+// "- `ami_name` (string) - The name of the resulting AMI.".
+var optionDoc = regexp.MustCompile("^- `([^`]+)` \\((.*?)\\) - (.*)$")
+
+// LoadDocs indexes the partials of dir.
+func LoadDocs(dir string) (*Docs, error) {
+	d := &Docs{options: map[string]map[string]string{}}
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(p, ".mdx") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return d.parse(filepath.ToSlash(rel), f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for dir := range d.options {
+		d.dirs = append(d.dirs, dir)
+	}
+	sort.Strings(d.dirs)
+	return d, nil
+}
+
+func (d *Docs) parse(dir string, r io.Reader) error {
+	var name string
+	var doc []string
+	flush := func() {
+		if name == "" {
+			return
+		}
+		if d.options[dir] == nil {
+			d.options[dir] = map[string]string{}
+		}
+		if _, ok := d.options[dir][name]; !ok {
+			d.options[dir][name] = strings.Join(doc, "\n")
+		}
+		name, doc = "", nil
+	}
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if m := optionDoc.FindStringSubmatch(line); m != nil {
+			flush()
+			name, doc = m[1], []string{m[3]}
+			continue
+		}
+		if name != "" && strings.HasPrefix(line, "  ") {
+			doc = append(doc, strings.TrimPrefix(line, "  "))
+			continue
+		}
+		flush()
+	}
+	flush()
+	return s.Err()
+}
+
+// Option returns the doc of the option of the component of the package
+// directory pkgDir, like builder/amazon/ebs, or "". Options of the embedded
+// configs are looked up in the common packages next to pkgDir, then in the
+// common packages of Packer, then in all the packages.
+func (d *Docs) Option(pkgDir, name string) string {
+	if d == nil {
+		return ""
+	}
+	dirs := []string{
+		pkgDir,
+		path.Join(path.Dir(pkgDir), "common"),
+		"common",
+		"helper/communicator",
+	}
+	for _, dir := range append(dirs, d.dirs...) {
+		if doc, ok := d.options[dir][name]; ok {
+			return doc
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package lsp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDocs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-lsp-docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	partials := map[string]string{
+		"builder/amazon/ebs/Config-not-required.mdx": "<!-- Code generated from the comments of the Config struct in builder/amazon/ebs/builder.go; DO NOT EDIT MANUALLY -->\n\n" +
+			"- `run_tags` (map[string]string) - Tags to apply to the instance\n  that is launched.\n\n" +
+			"- `ami_name` (string) - Overridden name.\n",
+		"builder/amazon/common/AMIConfig-required.mdx": "- `ami_name` (string) - The name of the resulting AMI.\n",
+		"helper/communicator/SSH-not-required.mdx":     "- `ssh_port` (int) - The port to connect to SSH.\n",
+	}
+	for name, contents := range partials {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	docs, err := LoadDocs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		pkgDir, option, doc string
+	}{
+		{"builder/amazon/ebs", "run_tags", "Tags to apply to the instance\nthat is launched."},
+		{"builder/amazon/ebs", "ami_name", "Overridden name."},
+		{"builder/amazon/ebssurrogate", "ami_name", "The name of the resulting AMI."},
+		{"builder/amazon/ebs", "ssh_port", "The port to connect to SSH."},
+		{"builder/amazon/ebs", "unknown", ""},
+	} {
+		if doc := docs.Option(tc.pkgDir, tc.option); doc != tc.doc {
+			t.Errorf("%s %s: expected %q, got %q", tc.pkgDir, tc.option, tc.doc, doc)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+// Package lsp implements a Language Server Protocol server for HCL2
+// templates: completion and docs of the options of the components, and
+// validation of the templates as they are edited.
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Server is a language server for HCL2 templates, for one client.
+type Server struct {
+	// Components are the components whose options are completed.
+	Components Components
+	// Validate returns the diagnostics of the template of dir, reading the
+	// files being edited from files, by path, rather than from the disk.
+	Validate func(dir string, files map[string][]byte) hcl.Diagnostics
+	// Version is the version of the server reported to the client.
+	Version string
+
+	conn *conn
+	// documents are the open documents, by URI.
+	documents map[string]string
+	// published are the documents the last validation of their directory
+	// found diagnostics in, by URI, to clear them once fixed.
+	published map[string]bool
+}
+
+// errExit stops the server.
+var errExit = errors.New("exit")
+
+// Serve reads the requests of the client from r and writes the responses
+// to w, until the client asks the server to exit or closes r.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	s.documents = map[string]string{}
+	s.published = map[string]bool{}
+
+	for {
+		b, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(b, &req); err != nil {
+			if err := s.conn.write(errorResponse{
+				JSONRPC: "2.0",
+				Error:   responseError{Code: codeParseError, Message: err.Error()},
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, err := s.handle(req)
+		if err == errExit {
+			return nil
+		}
+		if req.ID == nil {
+			// Notifications have no response.
+			if err != nil {
+				log.Printf("[WARN] lsp: %s: %s", req.Method, err)
+			}
+			continue
+		}
+		if err != nil {
+			resErr := responseError{Code: codeInvalidParams, Message: err.Error()}
+			if re, ok := err.(*responseError); ok {
+				resErr = *re
+			}
+			err = s.conn.write(errorResponse{JSONRPC: "2.0", ID: req.ID, Error: resErr})
+		} else {
+			err = s.conn.write(response{JSONRPC: "2.0", ID: req.ID, Result: result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+func (s *Server) handle(req request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:   syncFull,
+				CompletionProvider: &CompletionOptions{TriggerCharacters: []string{`"`}},
+				HoverProvider:      true,
+			},
+			ServerInfo: ServerInfo{Name: "packer", Version: s.Version},
+		}, nil
+	case "shutdown":
+		return nil, nil
+	case "exit":
+		return nil, errExit
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+		return nil, s.validate(params.TextDocument.URI)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+		return nil, s.validate(params.TextDocument.URI)
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.validate(params.TextDocument.URI)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		if s.published[params.TextDocument.URI] {
+			delete(s.published, params.TextDocument.URI)
+			return nil, s.publish(params.TextDocument.URI, nil)
+		}
+		return nil, nil
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		src := []byte(s.documents[params.TextDocument.URI])
+		items := completions(s.Components, src, byteOffset(src, params.Position))
+		if items == nil {
+			items = []CompletionItem{}
+		}
+		return CompletionList{Items: items}, nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		src := []byte(s.documents[params.TextDocument.URI])
+		if h := hover(s.Components, src, byteOffset(src, params.Position)); h != nil {
+			return h, nil
+		}
+		return nil, nil
+	}
+
+	if strings.HasPrefix(req.Method, "$/") || req.ID == nil {
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+// isTemplate tells whether the file at path is part of a template.
+func isTemplate(path string) bool {
+	return strings.HasSuffix(path, ".pkr.hcl") || strings.HasSuffix(path, ".pkr.json")
+}
+
+// validate validates the template of the document, and publishes the
+// diagnostics of the files of the template.
+func (s *Server) validate(uri string) error {
+	path, err := uriPath(uri)
+	if err != nil || !isTemplate(path) || s.Validate == nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+
+	files := map[string][]byte{}
+	uris := map[string]string{}
+	byURI := map[string][]Diagnostic{}
+	for u, text := range s.documents {
+		p, err := uriPath(u)
+		if err != nil || filepath.Dir(p) != dir || !isTemplate(p) {
+			continue
+		}
+		files[p] = []byte(text)
+		uris[p] = u
+		byURI[u] = []Diagnostic{}
+	}
+	for u := range s.published {
+		if p, err := uriPath(u); err == nil && filepath.Dir(p) == dir {
+			byURI[u] = []Diagnostic{}
+		}
+	}
+
+	for _, diag := range s.Validate(dir, files) {
+		u, r := uri, Range{}
+		if diag.Subject != nil {
+			if open, ok := uris[diag.Subject.Filename]; ok {
+				u = open
+			} else if diag.Subject.Filename != "" {
+				u = pathURI(diag.Subject.Filename)
+			}
+			src := files[diag.Subject.Filename]
+			r = Range{
+				Start: hclPosition(src, diag.Subject.Start),
+				End:   hclPosition(src, diag.Subject.End),
+			}
+		}
+		d := Diagnostic{
+			Range:    r,
+			Severity: severityError,
+			Source:   "packer",
+			Message:  diag.Summary,
+		}
+		if diag.Severity == hcl.DiagWarning {
+			d.Severity = severityWarning
+		}
+		if diag.Detail != "" {
+			d.Message += ": " + diag.Detail
+		}
+		byURI[u] = append(byURI[u], d)
+	}
+
+	for u, diags := range byURI {
+		if len(diags) == 0 {
+			delete(s.published, u)
+		} else {
+			s.published[u] = true
+		}
+		if err := s.publish(u, diags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) publish(uri string, diags []Diagnostic) error {
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	return s.conn.write(notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  PublishDiagnosticsParams{URI: uri, Diagnostics: diags},
+	})
+}
+
+// uriPath returns the path of a file URI.
+func uriPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", errors.New("not a file URI: " + uri)
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		// file:///C:/dir/file
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// pathURI returns the URI of the file at path.
+func pathURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// byteOffset returns the offset of pos in src.
+func byteOffset(src []byte, pos Position) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return len(src)
+		}
+		offset += i + 1
+	}
+	// Characters are counted in UTF-16 code units.
+	for units := 0; units < pos.Character && offset < len(src) && src[offset] != '\n'; {
+		r, size := utf8.DecodeRune(src[offset:])
+		units += len(utf16.Encode([]rune{r}))
+		offset += size
+	}
+	return offset
+}
+
+// bytePosition returns the position of offset in src.
+func bytePosition(src []byte, offset int) Position {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	pos := Position{}
+	start := 0
+	for i := 0; i < offset; i++ {
+		if src[i] == '\n' {
+			pos.Line++
+			start = i + 1
+		}
+	}
+	for _, r := range string(src[start:offset]) {
+		pos.Character += len(utf16.Encode([]rune{r}))
+	}
+	return pos
+}
+
+// hclPosition returns the position of pos, in src when the file is being
+// edited.
+func hclPosition(src []byte, pos hcl.Pos) Position {
+	if src != nil {
+		return bytePosition(src, pos.Byte)
+	}
+	p := Position{Line: pos.Line - 1, Character: pos.Column - 1}
+	if p.Line < 0 {
+		p.Line = 0
+	}
+	if p.Character < 0 {
+		p.Character = 0
+	}
+	return p
+}
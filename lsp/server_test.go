@@ -0,0 +1,118 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestServer(t *testing.T) {
+	path := filepath.Join(string(filepath.Separator)+"templates", "example.pkr.hcl")
+	uri := pathURI(path)
+	text := "source \"amazon-ebs\" \"example\" {\n  \n}\n"
+
+	var in bytes.Buffer
+	send := func(id int, method string, params interface{}) {
+		msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+		if id > 0 {
+			msg["id"] = id
+		}
+		b, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	}
+	send(1, "initialize", map[string]interface{}{})
+	send(0, "textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "hcl", Version: 1, Text: text},
+	})
+	send(2, "textDocument/completion", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: 1, Character: 2},
+	})
+	send(3, "unknown/method", nil)
+	send(4, "shutdown", nil)
+	send(0, "exit", nil)
+
+	var validated map[string][]byte
+	s := &Server{
+		Components: testComponents{},
+		Validate: func(dir string, files map[string][]byte) hcl.Diagnostics {
+			validated = files
+			return hcl.Diagnostics{{
+				Severity: hcl.DiagWarning,
+				Summary:  "Missing ami_name",
+				Subject: &hcl.Range{
+					Filename: path,
+					Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+					End:      hcl.Pos{Line: 1, Column: 7, Byte: 6},
+				},
+			}}
+		},
+	}
+	var out bytes.Buffer
+	if err := s.Serve(&in, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(map[string][]byte{path: []byte(text)}, validated); diff != "" {
+		t.Fatalf("unexpected validated files: %s", diff)
+	}
+
+	c := newConn(&out, nil)
+	var messages []string
+	for {
+		b, err := c.read()
+		if err != nil {
+			break
+		}
+		messages = append(messages, string(b))
+	}
+	if len(messages) != 5 {
+		t.Fatalf("expected 5 messages, got %d: %s", len(messages), strings.Join(messages, "\n"))
+	}
+	for i, want := range []string{
+		`"id":1,"result":{"capabilities":{"textDocumentSync":1,`,
+		`"method":"textDocument/publishDiagnostics","params":{"uri":"` + uri + `","diagnostics":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":6}},"severity":2,"source":"packer","message":"Missing ami_name"}]}`,
+		`"id":2,"result":{"isIncomplete":false,"items":[{"label":"ami_name"`,
+		`"id":3,"error":{"code":-32601,`,
+		`"id":4,"result":null`,
+	} {
+		if !strings.Contains(messages[i], want) {
+			t.Errorf("message %d: expected %s in %s", i, want, messages[i])
+		}
+	}
+}
+
+func TestByteOffset(t *testing.T) {
+	src := []byte("a\né\U0001F600b\nc")
+	for _, tc := range []struct {
+		pos    Position
+		offset int
+	}{
+		{Position{0, 0}, 0},
+		{Position{1, 0}, 2},
+		{Position{1, 1}, 4},
+		// The emoji is two UTF-16 code units.
+		{Position{1, 3}, 8},
+		{Position{1, 10}, 9},
+		{Position{2, 1}, 11},
+		{Position{5, 0}, 11},
+	} {
+		if got := byteOffset(src, tc.pos); got != tc.offset {
+			t.Errorf("byteOffset(%v) = %d, expected %d", tc.pos, got, tc.offset)
+		}
+		if tc.pos.Line < 3 && tc.pos.Character < 5 {
+			if got := bytePosition(src, tc.offset); got != tc.pos {
+				t.Errorf("bytePosition(%d) = %v, expected %v", tc.offset, got, tc.pos)
+			}
+		}
+	}
+}
@@ -5,20 +5,27 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/packer/command"
+	commonhelper "github.com/hashicorp/packer/helper/common"
 	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/logging"
+	"github.com/hashicorp/packer/packer/otel"
 	"github.com/hashicorp/packer/packer/plugin"
 	"github.com/hashicorp/packer/packer/tmp"
 	"github.com/hashicorp/packer/version"
@@ -50,6 +57,17 @@ func realMain() int {
 		UUID, _ := uuid.GenerateUUID()
 		os.Setenv("PACKER_RUN_UUID", UUID)
 
+		// The log flags are set as environment variables for the logs of the
+		// wrapped process and of the plugins to be configured like ours.
+		_, logEnv, err := extractLogFlags(os.Args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Couldn't setup log output: %s\n", err)
+			return 1
+		}
+		for k, v := range logEnv {
+			os.Setenv(k, v)
+		}
+
 		// Determine where logs should go in general (requested by the user)
 		logWriter, err := logOutput()
 		if err != nil {
@@ -75,6 +93,17 @@ func realMain() int {
 		defer os.Remove(logTempFile.Name())
 		defer logTempFile.Close()
 
+		// The temporary files of the run and of its builds go in a scratch
+		// directory, removed once the wrapped process exited, even when it
+		// crashed. It is kept in debug mode, to inspect them.
+		if os.Getenv(tmp.EnvDir) == "" {
+			runDir := filepath.Join(os.TempDir(), "packer-run-"+UUID)
+			os.Setenv(tmp.EnvDir, runDir)
+			if !extractDebug(os.Args[1:]) {
+				defer os.RemoveAll(runDir)
+			}
+		}
+
 		// Tell the logger to log to this file
 		os.Setenv(EnvLog, "")
 		os.Setenv(EnvLogFile, "")
@@ -136,10 +165,12 @@ func wrappedMain() int {
 	log.SetOutput(&packer.LogSecretFilter)
 
 	inPlugin := os.Getenv(plugin.MagicCookieKey) == plugin.MagicCookieValue
+	logFlags := log.LstdFlags
 	if inPlugin {
 		// This prevents double-logging timestamps
-		log.SetFlags(0)
+		logFlags = 0
 	}
+	log.SetFlags(logging.Flags(logFlags))
 
 	log.Printf("[INFO] Packer version: %s [%s %s %s]",
 		version.FormattedVersion(),
@@ -157,6 +188,13 @@ func wrappedMain() int {
 		return 1
 	}
 
+	// The CA bundle and the client certificate of the outbound HTTPS
+	// requests are loaded by every plugin, so they are checked once here.
+	if err := commonhelper.ValidateEnvironment(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring HTTPS requests: \n\n%s\n", err)
+		return 1
+	}
+
 	// Fire off the checkpoint.
 	go runCheckpoint(config)
 	if !config.DisableCheckpoint {
@@ -174,7 +212,9 @@ func wrappedMain() int {
 
 	// Determine if we're in machine-readable mode by mucking around with
 	// the arguments...
-	args, machineReadable := extractMachineReadable(os.Args[1:])
+	// The log flags were already handled by the parent process.
+	args, _, _ := extractLogFlags(os.Args[1:])
+	args, machineReadable := extractMachineReadable(args)
 
 	defer plugin.CleanupClients()
 
@@ -224,6 +264,11 @@ func wrappedMain() int {
 				BuilderStore:       config.Builders,
 				ProvisionerStore:   config.Provisioners,
 				PostProcessorStore: config.PostProcessors,
+
+				PluginConfig: &packer.PluginConfig{
+					PluginDirectories: config.PluginDirectories(),
+					LoadPlugin:        config.LoadPlugin,
+				},
 			},
 			Version: version.Version,
 		},
@@ -246,6 +291,11 @@ func wrappedMain() int {
 			log.Printf("[WARN] (telemetry) Error finalizing report. This is safe to ignore. %s", err.Error())
 		}
 	}
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 2*time.Second)
+	if err := otel.Flush(flushCtx); err != nil {
+		log.Printf("[WARN] (otel) Error exporting telemetry. This is safe to ignore. %s", err.Error())
+	}
+	cancelFlush()
 
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
@@ -293,6 +343,82 @@ func extractMachineReadable(args []string) ([]string, bool) {
 	return args, false
 }
 
+// extractDebug checks the args for the -debug flag, which keeps the scratch
+// directories of the run.
+func extractDebug(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == "debug" {
+			return true
+		}
+		if strings.HasPrefix(name, "debug=") {
+			debug, _ := strconv.ParseBool(name[len("debug="):])
+			return debug
+		}
+	}
+	return false
+}
+
+// logFlags are the flags configuring the logs, and the environment variables
+// they set.
+var logFlags = map[string]string{
+	"-log-level":  logging.EnvLog,
+	"-log-levels": logging.EnvLogLevels,
+	"-log-path":   logging.EnvLogPath,
+}
+
+// extractLogFlags checks the args for the log flags, -log-level=LEVEL,
+// -log-levels=SUBSYSTEM=LEVEL,..., -log-path=PATH and -log-json, and returns
+// the environment variables they set. It modifies the args to remove these
+// flags.
+func extractLogFlags(args []string) ([]string, map[string]string, error) {
+	env := map[string]string{}
+	result := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "-log-json" {
+			env[logging.EnvLogFormat] = "json"
+			continue
+		}
+		name, value := arg, ""
+		hasValue := false
+		if idx := strings.Index(arg, "="); idx >= 0 {
+			name, value, hasValue = arg[:idx], arg[idx+1:], true
+		}
+		key, ok := logFlags[name]
+		if !ok {
+			result = append(result, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return result, env, fmt.Errorf("flag %s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "-log-level":
+			if _, err := logging.ParseLevel(value); err != nil {
+				return result, env, err
+			}
+		case "-log-levels":
+			if _, err := logging.ParseLevels(value); err != nil {
+				return result, env, err
+			}
+		}
+		env[key] = value
+	}
+
+	return result, env, nil
+}
+
 func loadConfig() (*config, error) {
 	var config config
 	config.PluginMinPort = 10000
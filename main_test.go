@@ -62,6 +62,56 @@ func TestExtractMachineReadable(t *testing.T) {
 	}
 }
 
+func TestExtractDebug(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"build", "template.json"}, false},
+		{[]string{"build", "-debug", "template.json"}, true},
+		{[]string{"build", "--debug", "template.json"}, true},
+		{[]string{"build", "-debug=true", "template.json"}, true},
+		{[]string{"build", "-debug=false", "template.json"}, false},
+		{[]string{"build", "debug"}, false},
+		{[]string{"build", "--", "-debug"}, false},
+	}
+	for _, tc := range cases {
+		if debug := extractDebug(tc.args); debug != tc.expected {
+			t.Fatalf("%v: expected %t, got %t", tc.args, tc.expected, debug)
+		}
+	}
+}
+
+func TestExtractLogFlags(t *testing.T) {
+	args := []string{"build", "-log-level=info", "-log-levels", "communicator=trace", "-log-json", "template.json"}
+	result, env, err := extractLogFlags(args)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []string{"build", "template.json"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+	expectedEnv := map[string]string{
+		"PACKER_LOG":        "info",
+		"PACKER_LOG_LEVELS": "communicator=trace",
+		"PACKER_LOG_FORMAT": "json",
+	}
+	if !reflect.DeepEqual(env, expectedEnv) {
+		t.Fatalf("bad: %#v", env)
+	}
+
+	for _, args := range [][]string{
+		{"build", "-log-level=loud"},
+		{"build", "-log-levels=rpc"},
+		{"build", "-log-path"},
+	} {
+		if _, _, err := extractLogFlags(args); err == nil {
+			t.Fatalf("%v: should error", args)
+		}
+	}
+}
+
 func TestRandom(t *testing.T) {
 	if rand.Intn(9999999) == 8498210 {
 		t.Fatal("math.rand is not seeded properly")
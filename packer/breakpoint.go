@@ -0,0 +1,147 @@
+package packer
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// BreakpointsConfigKey is the key in the configuration of builders for
+	// the names of the steps to pause at.
+	BreakpointsConfigKey = "packer_breakpoints"
+
+	// BreakpointAll pauses at every step, with the -step option.
+	BreakpointAll = "*"
+)
+
+// MatchBreakpoint tells whether a step called name should pause, with the
+// given breakpoints. Breakpoints are names or glob patterns, like
+// "StepCreate*" or "provisioner.shell".
+func MatchBreakpoint(breakpoints []string, name string) bool {
+	for _, b := range breakpoints {
+		if b == name || b == BreakpointAll {
+			return true
+		}
+		if ok, _ := filepath.Match(b, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakpointAction is what the operator chose to do at a breakpoint.
+type BreakpointAction int
+
+const (
+	BreakpointContinue BreakpointAction = iota
+	BreakpointAbort
+)
+
+// Breakpoint pauses before a step and waits for the operator to continue or
+// abort. Until then, the operator can show the state of the build, as
+// returned by state, as many times as needed. Breakpoint continues when
+// cancelled returns true, which is checked regularly.
+func Breakpoint(ui Ui, name string, state func() []string, cancelled func() bool) BreakpointAction {
+	ui.Say(fmt.Sprintf("Breakpoint before %s", name))
+	for {
+		result := make(chan string, 1)
+		go func() {
+			line, err := ui.Ask("[enter] Continue, [s] show the state, or [a] abort the build?")
+			if err != nil {
+				log.Printf("Error asking for input: %s", err)
+			}
+			result <- line
+		}()
+
+		var line string
+	wait:
+		for {
+			select {
+			case line = <-result:
+				break wait
+			case <-time.After(100 * time.Millisecond):
+				if cancelled() {
+					return BreakpointContinue
+				}
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "", "c":
+			return BreakpointContinue
+		case "a":
+			return BreakpointAbort
+		case "s":
+			lines := state()
+			if len(lines) == 0 {
+				ui.Say("  <empty>")
+			}
+			for _, l := range lines {
+				ui.Say("  " + l)
+			}
+		default:
+			ui.Say(fmt.Sprintf("Incorrect input: %#v", line))
+		}
+	}
+}
+
+// sensitiveKeys are parts of the keys of values that are never shown at a
+// breakpoint nor recorded.
+var sensitiveKeys = []string{"password", "private", "secret", "token"}
+
+// IsSensitiveKey tells whether key, like "db_password", names a sensitive
+// value.
+func IsSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// DescribeState returns one line per value, sorted by key, for the operator
+// to inspect at a breakpoint. Long values are cut, sensitive ones are
+// hidden.
+func DescribeState(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s = %s", k, describeValue(k, values[k])))
+	}
+	return lines
+}
+
+func describeValue(key string, v interface{}) string {
+	if IsSensitiveKey(key) {
+		return "<sensitive>"
+	}
+
+	var s string
+	switch v := v.(type) {
+	case string, bool, int, int64, float64, []string, map[string]string, time.Duration:
+		s = fmt.Sprintf("%v", v)
+	case fmt.Stringer:
+		s = v.String()
+	case error:
+		s = v.Error()
+	default:
+		s = fmt.Sprintf("<%T>", v)
+	}
+	s = LogSecretFilter.FilterString(s)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	if len(s) > 100 {
+		s = s[:97] + "..."
+	}
+	return s
+}
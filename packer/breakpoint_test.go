@@ -0,0 +1,99 @@
+package packer
+
+import (
+	"strings"
+	"testing"
+)
+
+// scriptedUi answers questions with the given lines, in order.
+type scriptedUi struct {
+	*BasicUi
+	answers []string
+}
+
+func (u *scriptedUi) Ask(string) (string, error) {
+	answer := u.answers[0]
+	u.answers = u.answers[1:]
+	return answer, nil
+}
+
+func TestMatchBreakpoint(t *testing.T) {
+	cases := []struct {
+		breakpoints []string
+		name        string
+		expected    bool
+	}{
+		{nil, "StepCreateVM", false},
+		{[]string{"StepCreateVM"}, "StepCreateVM", true},
+		{[]string{"StepCreateVM"}, "StepCreateDisk", false},
+		{[]string{"StepCreate*"}, "StepCreateDisk", true},
+		{[]string{"*"}, "provisioner.shell", true},
+		{[]string{"StepCreateVM", "provisioner.shell"}, "provisioner.shell", true},
+		{[]string{"provisioner.*"}, "StepCreateVM", false},
+	}
+
+	for _, tc := range cases {
+		if actual := MatchBreakpoint(tc.breakpoints, tc.name); actual != tc.expected {
+			t.Errorf("MatchBreakpoint(%q, %q) = %t, expected %t", tc.breakpoints, tc.name, actual, tc.expected)
+		}
+	}
+}
+
+func TestBreakpoint(t *testing.T) {
+	cases := []struct {
+		answers  []string
+		expected BreakpointAction
+		output   string
+	}{
+		{[]string{""}, BreakpointContinue, ""},
+		{[]string{"a"}, BreakpointAbort, ""},
+		{[]string{"s", "c"}, BreakpointContinue, "instance_id = i-1234"},
+		{[]string{"x", "A"}, BreakpointAbort, "Incorrect input"},
+	}
+
+	for _, tc := range cases {
+		ui := &scriptedUi{BasicUi: testUi(), answers: tc.answers}
+		state := func() []string { return []string{"instance_id = i-1234"} }
+		cancelled := func() bool { return false }
+
+		if actual := Breakpoint(ui, "step 'StepCreateVM'", state, cancelled); actual != tc.expected {
+			t.Errorf("answers %q: got %d, expected %d", tc.answers, actual, tc.expected)
+		}
+		output := readWriter(ui.BasicUi)
+		if !strings.Contains(output, "Breakpoint before step 'StepCreateVM'") {
+			t.Errorf("answers %q: bad output: %s", tc.answers, output)
+		}
+		if !strings.Contains(output, tc.output) {
+			t.Errorf("answers %q: output should contain %q: %s", tc.answers, tc.output, output)
+		}
+	}
+}
+
+func TestDescribeState(t *testing.T) {
+	LogSecretFilter.Set("hunter2")
+	defer delete(LogSecretFilter.s, "hunter2")
+
+	lines := DescribeState(map[string]interface{}{
+		"instance_id":  "i-1234",
+		"ssh_password": "letmein",
+		"user_data":    "password=hunter2",
+		"long":         strings.Repeat("a", 200),
+		"ui":           testUi(),
+	})
+
+	expected := []string{
+		"instance_id = i-1234",
+		"long = " + strings.Repeat("a", 97) + "...",
+		"ssh_password = <sensitive>",
+		"ui = <*packer.BasicUi>",
+		"user_data = password=<sensitive>",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("bad: %q", lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d: got %q, expected %q", i, lines[i], expected[i])
+		}
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/packer/helper/common"
+	"github.com/hashicorp/packer/packer/otel"
 )
 
 const (
@@ -33,12 +37,40 @@ const (
 	// - "ask" - ask the user
 	OnErrorConfigKey = "packer_on_error"
 
+	// This key determines what to do when the artifact of a build already
+	// exists: one of the Conflict* modes.
+	OnConflictConfigKey = "packer_on_conflict"
+
 	// TemplatePathKey is the path to the template that configured this build
 	TemplatePathKey = "packer_template_path"
 
 	// This key contains a map[string]string of the user variables for
 	// template processing.
 	UserVariablesConfigKey = "packer_user_variables"
+
+	// This key contains a map[string]string of the outputs of the builds a
+	// build depends on, keyed by "build.output".
+	BuildOutputsConfigKey = "packer_build_outputs"
+
+	// This key contains a map[string]string of the build info, keyed by the
+	// BuildInfo*Key constants.
+	BuildInfoConfigKey = "packer_build_info"
+
+	// This key is the path of the journal file of the build, in which the
+	// builder records the steps it completed.
+	JournalConfigKey = "packer_journal"
+
+	// This key contains a map[string]string of the billing tags the builder
+	// adds to the temporary resources of the build.
+	BillingTagsConfigKey = "packer_billing_tags"
+
+	// This key is the preflight mode of the build, one of the Preflight*
+	// modes, when the builder checks its permissions before building.
+	PreflightConfigKey = "packer_preflight"
+
+	// This key is true when the build resumes a build whose packer process
+	// died, from its journal.
+	ResumeConfigKey = "packer_resume"
 )
 
 // A Build represents a single job within Packer that is responsible for
@@ -98,16 +130,72 @@ type CoreBuild struct {
 	TemplatePath       string
 	Variables          map[string]string
 
+	// Verifiers are the provisioners of the verify stage of the build: they
+	// run after the other provisioners and their failures are reported as a
+	// VerificationError. VerifyContinueOnFailure lets the build produce its
+	// artifact when they fail.
+	Verifiers               []CoreBuildProvisioner
+	VerifyContinueOnFailure bool
+
+	// BuildTimeout bounds the whole build, ProvisionTimeout the provisioning
+	// phase and PostProcessTimeout the post-processing phase. Zero means no
+	// timeout.
+	BuildTimeout       time.Duration
+	ProvisionTimeout   time.Duration
+	PostProcessTimeout time.Duration
+
+	// Dependencies are the names of the builds this build depends on.
+	Dependencies []string
+
+	// Breakpoints are the names of the steps and of the provisioners, like
+	// "provisioner.shell", to pause at. BreakpointAll pauses at all of them.
+	Breakpoints []string
+
+	// BillingTags are the tags the builder adds to the temporary resources
+	// of the build.
+	BillingTags map[string]string
+
+	// Preflight is the preflight mode of the build, one of the Preflight*
+	// modes, or empty.
+	Preflight string
+
+	// Notifications are sent when the build starts, succeeds or fails.
+	Notifications []*Notification
+
+	// Locks are the names shared with other builds, like image names, that
+	// the build locks in the state backend while it runs.
+	Locks []string
+
+	// Semaphores are the limits of the named semaphores the build takes a
+	// slot of while it runs, by semaphore name.
+	Semaphores map[string]int
+
+	// NamedOutputs computes the named outputs of the build from its
+	// artifacts, when it has any.
+	NamedOutputs func(*OutputsData) (map[string]string, error)
+
+	// PrepareFunc prepares a build from an HCL2 template that depends on
+	// other builds: it is not initialized until the outputs of its
+	// dependencies are known.
+	PrepareFunc func(BuildOutputs) ([]string, error)
+
 	// Indicates whether the build is already initialized before calling Prepare(..)
 	Prepared bool
 
 	debug         bool
 	force         bool
 	onError       string
+	onConflict    string
+	resume        bool
 	l             sync.Mutex
 	prepareCalled bool
+	outputs       BuildOutputs
+	info          *BuildInfo
+	generatedData map[string]interface{}
 }
 
+var _ DependentBuild = new(CoreBuild)
+
 // CoreBuildPostProcessor Keeps track of the post-processor and the
 // configuration of the post-processor used within a build.
 type CoreBuildPostProcessor struct {
@@ -127,6 +215,14 @@ type CoreBuildProvisioner struct {
 	config      []interface{}
 }
 
+// GeneratedData returns the generated data of the artifact of the builder,
+// once the build ran.
+func (b *CoreBuild) GeneratedData() map[string]interface{} {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.generatedData
+}
+
 // Returns the name of the build.
 func (b *CoreBuild) Name() string {
 	if b.BuildName != "" {
@@ -135,6 +231,29 @@ func (b *CoreBuild) Name() string {
 	return b.Type
 }
 
+// DependsOn returns the names of the builds this build depends on.
+func (b *CoreBuild) DependsOn() []string {
+	return b.Dependencies
+}
+
+// Info returns the build info of the build, nil when it has none.
+func (b *CoreBuild) Info() *BuildInfo {
+	return b.info
+}
+
+// SetInfo sets the build info of the build, whose scratch directory is
+// created when it runs.
+func (b *CoreBuild) SetInfo(info *BuildInfo) {
+	b.info = info
+}
+
+// SetOutputs sets the outputs of the builds this build depends on.
+func (b *CoreBuild) SetOutputs(outputs BuildOutputs) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.outputs = outputs
+}
+
 // Prepare prepares the build by doing some initialization for the builder
 // and any hooks. This _must_ be called prior to Run. The parameter is the
 // overrides for the variables within the template (if any).
@@ -153,6 +272,18 @@ func (b *CoreBuild) Prepare() (warn []string, err error) {
 		panic("prepare already called")
 	}
 
+	// Until the builds this build depends on ran, prepare it with
+	// placeholders so that it can be validated.
+	outputs := b.outputs
+	if outputs == nil && len(b.Dependencies) > 0 {
+		outputs = PlaceholderOutputs(b.Dependencies)
+	}
+
+	if b.PrepareFunc != nil {
+		b.prepareCalled = true
+		return b.PrepareFunc(outputs)
+	}
+
 	// Templates loaded from HCL2 will never get here. TODO: move this code into
 	// a custom json area instead of just aborting early for HCL.
 	b.prepareCalled = true
@@ -166,6 +297,30 @@ func (b *CoreBuild) Prepare() (warn []string, err error) {
 		TemplatePathKey:        b.TemplatePath,
 		UserVariablesConfigKey: b.Variables,
 	}
+	if outputs != nil {
+		packerConfig[BuildOutputsConfigKey] = outputs.flatten()
+	}
+	if b.onConflict != "" {
+		packerConfig[OnConflictConfigKey] = b.onConflict
+	}
+	if b.info != nil {
+		packerConfig[BuildInfoConfigKey] = b.info.Map()
+		if journal := b.info.Journal(); journal != "" {
+			packerConfig[JournalConfigKey] = journal
+		}
+	}
+	if b.resume {
+		packerConfig[ResumeConfigKey] = true
+	}
+	if len(b.Breakpoints) > 0 {
+		packerConfig[BreakpointsConfigKey] = b.Breakpoints
+	}
+	if len(b.BillingTags) > 0 {
+		packerConfig[BillingTagsConfigKey] = b.BillingTags
+	}
+	if b.Preflight != "" {
+		packerConfig[PreflightConfigKey] = b.Preflight
+	}
 
 	// Prepare the builder
 	generatedVars, warn, err := b.Builder.Prepare(b.BuilderConfig, packerConfig)
@@ -198,6 +353,18 @@ func (b *CoreBuild) Prepare() (warn []string, err error) {
 		}
 	}
 
+	// Prepare the verifiers
+	for _, coreProv := range b.Verifiers {
+		configs := make([]interface{}, len(coreProv.config), len(coreProv.config)+1)
+		copy(configs, coreProv.config)
+		configs = append(configs, packerConfig)
+		configs = append(configs, generatedPlaceholderMap)
+
+		if err = coreProv.Provisioner.Prepare(configs...); err != nil {
+			return
+		}
+	}
+
 	// Prepare the on-error-cleanup provisioner
 	if b.CleanupProvisioner.PType != "" {
 		configs := make([]interface{}, len(b.CleanupProvisioner.config), len(b.CleanupProvisioner.config)+1)
@@ -220,6 +387,7 @@ func (b *CoreBuild) Prepare() (warn []string, err error) {
 		}
 	}
 
+	warn = append(warn, DeprecationWarnings(b.Deprecations())...)
 	return
 }
 
@@ -229,6 +397,42 @@ func (b *CoreBuild) Run(ctx context.Context, originalUi Ui) ([]Artifact, error)
 		panic("Prepare must be called first")
 	}
 
+	if originalUi == nil {
+		originalUi = &NoopUi{}
+	}
+
+	ctx, span := otel.StartSpan(ctx, "build "+b.Name(),
+		otel.String("packer.build", b.Name()),
+		otel.String("packer.builder", b.BuilderType))
+	notifyUi := &TargetedUI{Target: b.Name(), Ui: originalUi}
+	b.notify(notifyUi, &NotificationData{BuildName: b.Name(), Event: NotificationOnStart})
+	start := time.Now()
+	artifacts, err := b.run(ctx, originalUi)
+	span.End(err)
+	notifyErr := err
+	if notifyErr == nil && ctx.Err() != nil {
+		notifyErr = ctx.Err()
+	}
+	b.notify(notifyUi, newNotificationData(b.Name(), time.Since(start), artifacts, notifyErr))
+	otel.Record("packer.build.duration", "s", span.Duration().Seconds(),
+		otel.String("packer.builder", b.BuilderType))
+	return artifacts, err
+}
+
+func (b *CoreBuild) run(ctx context.Context, originalUi Ui) ([]Artifact, error) {
+	if b.info != nil {
+		if err := os.MkdirAll(b.info.Dir, 0755); err != nil {
+			return nil, fmt.Errorf("Error creating the build directory: %s", err)
+		}
+		// The build directory is kept in debug mode, to inspect the
+		// temporary files of the build.
+		if b.debug {
+			defer originalUi.Say(fmt.Sprintf("Debug mode enabled. Keeping the build directory %s", b.info.Dir))
+		} else {
+			defer os.RemoveAll(b.info.Dir)
+		}
+	}
+
 	// Copy the hooks
 	hooks := make(map[string][]Hook)
 	for hookName, hookList := range b.hooks {
@@ -238,36 +442,24 @@ func (b *CoreBuild) Run(ctx context.Context, originalUi Ui) ([]Artifact, error)
 
 	// Add a hook for the provisioners if we have provisioners
 	if len(b.Provisioners) > 0 {
-		hookedProvisioners := make([]*HookedProvisioner, len(b.Provisioners))
-		for i, p := range b.Provisioners {
-			var pConfig interface{}
-			if len(p.config) > 0 {
-				pConfig = p.config[0]
-			}
-			if b.debug {
-				hookedProvisioners[i] = &HookedProvisioner{
-					&DebuggedProvisioner{Provisioner: p.Provisioner},
-					pConfig,
-					p.PType,
-				}
-			} else {
-				hookedProvisioners[i] = &HookedProvisioner{
-					p.Provisioner,
-					pConfig,
-					p.PType,
-				}
-			}
-		}
-
-		if _, ok := hooks[HookProvision]; !ok {
-			hooks[HookProvision] = make([]Hook, 0, 1)
-		}
-
 		hooks[HookProvision] = append(hooks[HookProvision], &ProvisionHook{
-			Provisioners: hookedProvisioners,
+			Provisioners: b.hookedProvisioners(b.Provisioners),
+			Timeout:      b.ProvisionTimeout,
+			Breakpoints:  b.Breakpoints,
 		})
 	}
 
+	// The verifiers run after the provisioners, in the same hook so that
+	// they run before the builder shuts the machine down.
+	var verifyHook *VerifyHook
+	if len(b.Verifiers) > 0 {
+		verifyHook = &VerifyHook{
+			Verifiers:         b.hookedProvisioners(b.Verifiers),
+			ContinueOnFailure: b.VerifyContinueOnFailure,
+		}
+		hooks[HookProvision] = append(hooks[HookProvision], verifyHook)
+	}
+
 	if b.CleanupProvisioner.PType != "" {
 		hookedCleanupProvisioner := &HookedProvisioner{
 			b.CleanupProvisioner.Provisioner,
@@ -288,20 +480,56 @@ func (b *CoreBuild) Run(ctx context.Context, originalUi Ui) ([]Artifact, error)
 		Ui:     originalUi,
 	}
 
+	// The build context is cancelled once the build timeout is reached;
+	// builders then halt and clean up like they do on an interrupt.
+	buildCtx := ctx
+	if b.BuildTimeout > 0 {
+		var cancel context.CancelFunc
+		buildCtx, cancel = context.WithTimeout(ctx, b.BuildTimeout)
+		defer cancel()
+	}
+
 	log.Printf("Running builder: %s", b.BuilderType)
 	ts := CheckpointReporter.AddSpan(b.BuilderType, "builder", b.BuilderConfig)
-	builderArtifact, err := b.Builder.Run(ctx, builderUi, hook)
+	builderCtx, builderSpan := otel.StartSpan(buildCtx, "builder "+b.BuilderType)
+	builderArtifact, err := b.Builder.Run(builderCtx, builderUi, hook)
+	builderSpan.End(err)
 	ts.End(err)
+	if buildCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return nil, fmt.Errorf("Build exceeded build_timeout of %s", b.BuildTimeout)
+	}
+	var verifyErr *VerificationError
+	if verifyHook != nil {
+		var verified bool
+		verified, verifyErr = verifyHook.Result()
+		if verified && builderArtifact != nil {
+			builderArtifact = &verifiedArtifact{Artifact: builderArtifact, err: verifyErr}
+		}
+	}
 	if err != nil {
+		// The builder wraps the error of the hook, report the verification
+		// failure as is.
+		if verifyErr != nil && !b.VerifyContinueOnFailure {
+			return nil, verifyErr
+		}
 		return nil, err
 	}
 
 	// If there was no result, don't worry about running post-processors
 	// because there is nothing they can do, just return.
 	if builderArtifact == nil {
+		if verifyErr != nil {
+			return nil, verifyErr
+		}
 		return nil, nil
 	}
 
+	if data := builderArtifact.State("generated_data"); data != nil {
+		b.l.Lock()
+		b.generatedData = CastDataToMap(data)
+		b.l.Unlock()
+	}
+
 	errors := make([]error, 0)
 	keepOriginalArtifact := len(b.PostProcessors) == 0
 
@@ -312,11 +540,27 @@ func (b *CoreBuild) Run(ctx context.Context, originalUi Ui) ([]Artifact, error)
 	default:
 	}
 
+	ppCtx := buildCtx
+	if b.PostProcessTimeout > 0 {
+		var cancel context.CancelFunc
+		ppCtx, cancel = context.WithTimeout(buildCtx, b.PostProcessTimeout)
+		defer cancel()
+	}
+
 	// Run the post-processors
 PostProcessorRunSeqLoop:
 	for _, ppSeq := range b.PostProcessors {
 		priorArtifact := builderArtifact
 		for i, corePP := range ppSeq {
+			if ppCtx.Err() == context.DeadlineExceeded {
+				if buildCtx.Err() == context.DeadlineExceeded {
+					errors = append(errors, fmt.Errorf("Build exceeded build_timeout of %s", b.BuildTimeout))
+				} else {
+					errors = append(errors, fmt.Errorf("Post-processing exceeded post_process_timeout of %s", b.PostProcessTimeout))
+				}
+				break PostProcessorRunSeqLoop
+			}
+
 			ppUi := &TargetedUI{
 				Target: fmt.Sprintf("%s (%s)", b.Name(), corePP.PType),
 				Ui:     originalUi,
@@ -328,7 +572,15 @@ PostProcessorRunSeqLoop:
 				builderUi.Say(fmt.Sprintf("Running post-processor: %s (type %s)", corePP.PName, corePP.PType))
 			}
 			ts := CheckpointReporter.AddSpan(corePP.PType, "post-processor", corePP.config)
-			artifact, defaultKeep, forceOverride, err := corePP.PostProcessor.PostProcess(ctx, ppUi, priorArtifact)
+			builderUi.Machine(EventPostProcessorStarted, corePP.PType)
+			start := time.Now()
+			spanCtx, span := otel.StartSpan(ppCtx, "post-processor "+corePP.PType)
+			artifact, defaultKeep, forceOverride, err := corePP.PostProcessor.PostProcess(spanCtx, ppUi, priorArtifact)
+			span.End(err)
+			builderUi.Machine(EventPostProcessorFinished, corePP.PType,
+				strconv.FormatFloat(time.Since(start).Seconds(), 'f', 3, 64))
+			otel.Record("packer.post_processor.duration", "s", span.Duration().Seconds(),
+				otel.String("packer.post_processor", corePP.PType))
 			ts.End(err)
 			if err != nil {
 				errors = append(errors, fmt.Errorf("Post-processor failed: %s", err))
@@ -404,12 +656,42 @@ PostProcessorRunSeqLoop:
 	}
 
 	if len(errors) > 0 {
+		if verifyErr != nil {
+			errors = append(errors, verifyErr)
+		}
 		err = &MultiError{errors}
+	} else if verifyErr != nil {
+		err = verifyErr
 	}
 
 	return artifacts, err
 }
 
+// hookedProvisioners returns the provisioners to run in a hook.
+func (b *CoreBuild) hookedProvisioners(provisioners []CoreBuildProvisioner) []*HookedProvisioner {
+	hookedProvisioners := make([]*HookedProvisioner, len(provisioners))
+	for i, p := range provisioners {
+		var pConfig interface{}
+		if len(p.config) > 0 {
+			pConfig = p.config[0]
+		}
+		if b.debug {
+			hookedProvisioners[i] = &HookedProvisioner{
+				&DebuggedProvisioner{Provisioner: p.Provisioner},
+				pConfig,
+				p.PType,
+			}
+		} else {
+			hookedProvisioners[i] = &HookedProvisioner{
+				p.Provisioner,
+				pConfig,
+				p.PType,
+			}
+		}
+	}
+	return hookedProvisioners
+}
+
 func (b *CoreBuild) SetDebug(val bool) {
 	if b.prepareCalled {
 		panic("prepare has already been called")
@@ -431,5 +713,37 @@ func (b *CoreBuild) SetOnError(val string) {
 		panic("prepare has already been called")
 	}
 
-	b.onError = val
+	// An empty value keeps the error_handling setting of the template.
+	if val != "" {
+		b.onError = val
+	}
+}
+
+// SetOnConflict determines what to do when the artifact of the build already
+// exists: one of the Conflict* modes. The force and replace modes also force
+// the build, for the builders that only know about forcing.
+func (b *CoreBuild) SetOnConflict(val string) {
+	if b.prepareCalled {
+		panic("prepare has already been called")
+	}
+
+	b.onConflict = val
+	if val == ConflictForce || val == ConflictReplace {
+		b.force = true
+	}
+}
+
+// SetResume makes the build resume the build with the given id, whose packer
+// process died: the builder resumes the steps recorded as completed in its
+// journal rather than running them again.
+func (b *CoreBuild) SetResume(buildID string) {
+	if b.prepareCalled {
+		panic("prepare has already been called")
+	}
+
+	b.resume = true
+	if b.info != nil {
+		b.info.UUID = buildID
+		b.info.Dir = buildDir(buildID)
+	}
 }
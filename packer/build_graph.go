@@ -0,0 +1,145 @@
+package packer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DependentBuild is a Build that uses the artifacts of other builds, like an
+// application image built from a base image. It runs once the builds it
+// depends on succeeded, and is prepared with their outputs.
+type DependentBuild interface {
+	Build
+
+	// DependsOn returns the names of the builds this build depends on.
+	DependsOn() []string
+
+	// SetOutputs sets the outputs of the builds this build depends on. It
+	// must be called before Prepare.
+	SetOutputs(BuildOutputs)
+}
+
+// BuildOutputs are the outputs of builds, by build name and output name.
+type BuildOutputs map[string]map[string]string
+
+// ArtifactOutputs returns the outputs of a build that produced artifacts:
+// the id and the builder_id of its last artifact, which is the result of its
+// last post-processor.
+func ArtifactOutputs(artifacts []Artifact) map[string]string {
+	outputs := map[string]string{}
+	for i := len(artifacts) - 1; i >= 0; i-- {
+		if a := artifacts[i]; a != nil {
+			outputs["id"] = a.Id()
+			outputs["builder_id"] = a.BuilderId()
+			break
+		}
+	}
+	return outputs
+}
+
+// flatten returns the outputs keyed by "build.output", to be passed to the
+// plugins.
+func (o BuildOutputs) flatten() map[string]string {
+	if o == nil {
+		return nil
+	}
+	flat := map[string]string{}
+	for build, outputs := range o {
+		for k, v := range outputs {
+			flat[build+"."+k] = v
+		}
+	}
+	return flat
+}
+
+// BuildDependencies returns the names of the builds b depends on.
+func BuildDependencies(b Build) []string {
+	if d, ok := b.(DependentBuild); ok {
+		return d.DependsOn()
+	}
+	return nil
+}
+
+// SortBuilds orders builds so that every build comes after the builds it
+// depends on, keeping the original order otherwise. It fails when a build
+// depends on a build that isn't in builds or when dependencies are cyclic.
+func SortBuilds(builds []Build) ([]Build, error) {
+	names := make([]string, len(builds))
+	byName := make(map[string]Build, len(builds))
+	deps := make(map[string][]string, len(builds))
+	for i, b := range builds {
+		names[i] = b.Name()
+		byName[b.Name()] = b
+		deps[b.Name()] = BuildDependencies(b)
+	}
+
+	sorted, err := SortBuildNames(names, deps)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Build, len(sorted))
+	for i, n := range sorted {
+		res[i] = byName[n]
+	}
+	return res, nil
+}
+
+// SortBuildNames orders the names of builds so that every build comes after
+// the builds it depends on, in deps, keeping the original order otherwise.
+func SortBuildNames(names []string, deps map[string][]string) ([]string, error) {
+	known := make(map[string]bool, len(names))
+	for _, n := range names {
+		known[n] = true
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var sorted []string
+	var visit func(n string, path []string) error
+	visit = func(n string, path []string) error {
+		switch state[n] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle between builds: %s", strings.Join(append(path, n), " -> "))
+		}
+		state[n] = visiting
+		for _, d := range deps[n] {
+			if !known[d] {
+				return fmt.Errorf("build %q depends on build %q, which is not part of this run", n, d)
+			}
+			if err := visit(d, append(path, n)); err != nil {
+				return err
+			}
+		}
+		state[n] = visited
+		sorted = append(sorted, n)
+		return nil
+	}
+
+	for _, n := range names {
+		if err := visit(n, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// BuildOutputNames are the names of the outputs of every build.
+var BuildOutputNames = []string{"builder_id", "id"}
+
+// PlaceholderOutputs returns outputs with placeholder values for the given
+// builds, used to validate builds before the builds they depend on ran.
+func PlaceholderOutputs(builds []string) BuildOutputs {
+	outputs := BuildOutputs{}
+	for _, b := range builds {
+		outputs[b] = map[string]string{}
+		for _, k := range BuildOutputNames {
+			outputs[b][k] = fmt.Sprintf("Output_%s_of_%s", k, b)
+		}
+	}
+	return outputs
+}
@@ -0,0 +1,79 @@
+package packer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortBuildNames(t *testing.T) {
+	cases := []struct {
+		Names    []string
+		Deps     map[string][]string
+		Expected []string
+		Err      bool
+	}{
+		{
+			[]string{"a", "b", "c"},
+			nil,
+			[]string{"a", "b", "c"},
+			false,
+		},
+		{
+			[]string{"app", "other", "base"},
+			map[string][]string{"app": {"base"}},
+			[]string{"base", "app", "other"},
+			false,
+		},
+		{
+			[]string{"app", "base", "middle"},
+			map[string][]string{"app": {"middle"}, "middle": {"base"}},
+			[]string{"base", "middle", "app"},
+			false,
+		},
+		{
+			[]string{"a", "b"},
+			map[string][]string{"a": {"b"}, "b": {"a"}},
+			nil,
+			true,
+		},
+		{
+			[]string{"app"},
+			map[string][]string{"app": {"base"}},
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		sorted, err := SortBuildNames(tc.Names, tc.Deps)
+		if (err != nil) != tc.Err {
+			t.Fatalf("%v: err: %s", tc.Names, err)
+		}
+		if !reflect.DeepEqual(sorted, tc.Expected) {
+			t.Fatalf("%v: got %v, expected %v", tc.Names, sorted, tc.Expected)
+		}
+	}
+}
+
+func TestSortBuilds(t *testing.T) {
+	base := &CoreBuild{Type: "base"}
+	app := &CoreBuild{Type: "app", Dependencies: []string{"base"}}
+	sorted, err := SortBuilds([]Build{app, base})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if sorted[0] != base || sorted[1] != app {
+		t.Fatalf("bad order: %s, %s", sorted[0].Name(), sorted[1].Name())
+	}
+}
+
+func TestArtifactOutputs(t *testing.T) {
+	artifacts := []Artifact{
+		&MockArtifact{BuilderIdValue: "bldr", IdValue: "first"},
+		&MockArtifact{BuilderIdValue: "pp", IdValue: "last"},
+	}
+	expected := map[string]string{"id": "last", "builder_id": "pp"}
+	if outputs := ArtifactOutputs(artifacts); !reflect.DeepEqual(outputs, expected) {
+		t.Fatalf("bad: %#v", outputs)
+	}
+}
@@ -0,0 +1,138 @@
+package packer
+
+import (
+	"bytes"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/packer/common/uuid"
+	"github.com/hashicorp/packer/packer/tmp"
+)
+
+// The keys of the build info, as passed to the builds in the
+// BuildInfoConfigKey configuration key.
+const (
+	BuildInfoUUIDKey           = "build_uuid"
+	BuildInfoDirKey            = "build_dir"
+	BuildInfoStartKey          = "build_start"
+	BuildInfoStartUnixKey      = "build_start_unix"
+	BuildInfoGitCommitKey      = "git_commit"
+	BuildInfoGitShortCommitKey = "git_short_commit"
+	BuildInfoGitBranchKey      = "git_branch"
+	BuildInfoGitDirtyKey       = "git_dirty"
+)
+
+// BuildInfo describes a build to the templates configuring it, so that they
+// can name its outputs: its unique id, its scratch directory, when it started
+// and the git metadata of its template.
+type BuildInfo struct {
+	UUID string
+	// Dir is a scratch directory of the build, in the scratch directory of
+	// the Packer run, created when it runs and removed once it ran. The
+	// temporary files of its plugins are created in it.
+	Dir   string
+	Start time.Time
+	Git   GitInfo
+}
+
+// GitInfo is the git metadata of the directory of a template. It is empty
+// when the template is not in a git repository.
+type GitInfo struct {
+	Commit string
+	Branch string
+	// Dirty tells whether the repository has uncommitted changes.
+	Dirty bool
+}
+
+// NewBuildInfo describes a new build of the template in templateDir.
+func NewBuildInfo(templateDir string) *BuildInfo {
+	id := uuid.TimeOrderedUUID()
+	return &BuildInfo{
+		UUID:  id,
+		Dir:   buildDir(id),
+		Start: time.Now().UTC(),
+		Git:   ReadGitInfo(templateDir),
+	}
+}
+
+func buildDir(id string) string {
+	return filepath.Join(tmp.RunDir(), "packer-build-"+id)
+}
+
+// Journal returns the file of the journal of the build, "" when there is no
+// place to store journals.
+func (i *BuildInfo) Journal() string {
+	path, err := JournalPath(i.UUID)
+	if err != nil {
+		log.Printf("No journal for build %s: %s", i.UUID, err)
+		return ""
+	}
+	return path
+}
+
+// Map returns the values of the build info, by key.
+func (i *BuildInfo) Map() map[string]string {
+	shortCommit := i.Git.Commit
+	if len(shortCommit) > 7 {
+		shortCommit = shortCommit[:7]
+	}
+	return map[string]string{
+		BuildInfoUUIDKey:           i.UUID,
+		BuildInfoDirKey:            i.Dir,
+		BuildInfoStartKey:          i.Start.Format(time.RFC3339),
+		BuildInfoStartUnixKey:      strconv.FormatInt(i.Start.Unix(), 10),
+		BuildInfoGitCommitKey:      i.Git.Commit,
+		BuildInfoGitShortCommitKey: shortCommit,
+		BuildInfoGitBranchKey:      i.Git.Branch,
+		BuildInfoGitDirtyKey:       strconv.FormatBool(i.Git.Dirty),
+	}
+}
+
+var (
+	gitInfos  = map[string]GitInfo{}
+	gitInfosL sync.Mutex
+)
+
+// ReadGitInfo returns the git metadata of dir, read once per directory.
+func ReadGitInfo(dir string) GitInfo {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	gitInfosL.Lock()
+	defer gitInfosL.Unlock()
+	if info, ok := gitInfos[dir]; ok {
+		return info
+	}
+
+	info := GitInfo{}
+	if commit, err := git(dir, "rev-parse", "HEAD"); err == nil {
+		info.Commit = commit
+		// A detached HEAD has no branch.
+		if branch, err := git(dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+			info.Branch = branch
+		}
+		if status, err := git(dir, "status", "--porcelain"); err == nil {
+			info.Dirty = status != ""
+		}
+	} else {
+		log.Printf("No git metadata for %s: %s", dir, err)
+	}
+	gitInfos[dir] = info
+	return info
+}
+
+func git(dir string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,132 @@
+package packer
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/packer/tmp"
+)
+
+func TestNewBuildInfo(t *testing.T) {
+	info := NewBuildInfo(".")
+	if info.UUID == "" {
+		t.Fatal("should have a uuid")
+	}
+	if filepath.Dir(info.Dir) != tmp.RunDir() || !strings.Contains(info.Dir, info.UUID) {
+		t.Fatalf("bad dir: %s", info.Dir)
+	}
+	if other := NewBuildInfo("."); other.UUID == info.UUID || other.Dir == info.Dir {
+		t.Fatal("builds should have their own uuid and dir")
+	}
+
+	m := info.Map()
+	for _, k := range []string{
+		BuildInfoUUIDKey,
+		BuildInfoDirKey,
+		BuildInfoStartKey,
+		BuildInfoStartUnixKey,
+		BuildInfoGitCommitKey,
+		BuildInfoGitShortCommitKey,
+		BuildInfoGitBranchKey,
+		BuildInfoGitDirtyKey,
+	} {
+		if _, ok := m[k]; !ok {
+			t.Fatalf("missing %s", k)
+		}
+	}
+}
+
+func TestReadGitInfo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	if info := ReadGitInfo(td); info != (GitInfo{}) {
+		t.Fatalf("should be empty outside of a repository: %#v", info)
+	}
+
+	repo := filepath.Join(td, "repo")
+	for _, args := range [][]string{
+		{"init", "-q", repo},
+		{"-C", repo, "checkout", "-q", "-b", "images"},
+		{"-C", repo, "-c", "user.name=packer", "-c", "user.email=packer@example.com",
+			"commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s: %s", args, err, out)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(repo, "template.pkr.hcl"), nil, 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	info := ReadGitInfo(repo)
+	if len(info.Commit) != 40 {
+		t.Fatalf("bad commit: %s", info.Commit)
+	}
+	if info.Branch != "images" {
+		t.Fatalf("bad branch: %s", info.Branch)
+	}
+	if !info.Dirty {
+		t.Fatal("should be dirty")
+	}
+}
+
+func TestBuild_Run_buildDir(t *testing.T) {
+	build := testBuild()
+	info := NewBuildInfo(".")
+	build.SetInfo(info)
+	build.Builder.(*MockBuilder).RunFn = func(context.Context) {
+		if _, err := os.Stat(info.Dir); err != nil {
+			t.Fatalf("the build dir should exist: %s", err)
+		}
+	}
+	build.Prepare()
+	if _, err := build.Run(context.Background(), testUi()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := os.Stat(info.Dir); !os.IsNotExist(err) {
+		t.Fatalf("the build dir should be removed: %v", err)
+	}
+	if build.Builder.(*MockBuilder).PrepareConfig[1].(map[string]interface{})[BuildInfoConfigKey] == nil {
+		t.Fatal("should pass the build info to the builder")
+	}
+}
+
+func TestBuild_Run_buildDirDebug(t *testing.T) {
+	build := testBuild()
+	info := NewBuildInfo(".")
+	build.SetInfo(info)
+	build.SetDebug(true)
+	build.Prepare()
+	if _, err := build.Run(context.Background(), testUi()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(info.Dir)
+
+	if _, err := os.Stat(info.Dir); err != nil {
+		t.Fatalf("the build dir should be kept in debug mode: %s", err)
+	}
+}
+
+func TestNewBuildInfo_runDir(t *testing.T) {
+	defer os.Setenv(tmp.EnvDir, os.Getenv(tmp.EnvDir))
+	os.Setenv(tmp.EnvDir, filepath.Join(os.TempDir(), "packer-run-test"))
+
+	info := NewBuildInfo(".")
+	if filepath.Dir(info.Dir) != filepath.Join(os.TempDir(), "packer-run-test") {
+		t.Fatalf("the build dir should be in the run dir: %s", info.Dir)
+	}
+}
@@ -0,0 +1,393 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMonitorLines is how many output lines a BuildMonitor keeps by
+// default.
+const defaultMonitorLines = 1000
+
+// The states of a build of a BuildMonitor.
+const (
+	BuildStatePending   = "pending"
+	BuildStateRunning   = "running"
+	BuildStatePaused    = "paused"
+	BuildStateSucceeded = "succeeded"
+	BuildStateFailed    = "failed"
+	BuildStateCancelled = "cancelled"
+)
+
+// ErrUnknownBuild is returned by the controls of a BuildMonitor for builds it
+// doesn't monitor.
+var ErrUnknownBuild = errors.New("unknown build")
+
+// ErrBuildFinished is returned by the controls of a BuildMonitor for builds
+// that are done.
+var ErrBuildFinished = errors.New("the build is finished")
+
+// BuildMonitor keeps track of the builds run in parallel, for the HTTP API of
+// the build command: their state, their steps and the last lines they output.
+// It also pauses and cancels them. A paused build waits before its next
+// step until it is resumed.
+type BuildMonitor struct {
+	// MaxLines is how many of the last output lines of the builds are kept,
+	// 1000 when 0.
+	MaxLines int
+	// Interrupt cancels all the builds, like an interrupt, so that no more
+	// builds start.
+	Interrupt context.CancelFunc
+
+	l        sync.Mutex
+	builds   []*monitoredBuild
+	lines    []LogLine
+	nextLine int64
+}
+
+// BuildStatus is the state of a build of a BuildMonitor.
+type BuildStatus struct {
+	Name string `json:"name"`
+	// State is one of the BuildState* states.
+	State string `json:"state"`
+	// Paused tells that the build pauses before its next step, until it is
+	// resumed.
+	Paused   bool       `json:"paused"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	// Step is the name of the running step.
+	Step  string       `json:"step,omitempty"`
+	Steps []StepStatus `json:"steps"`
+	Error string       `json:"error,omitempty"`
+}
+
+// StepStatus is a step of a build of a BuildMonitor.
+type StepStatus struct {
+	Name    string    `json:"name"`
+	Started time.Time `json:"started"`
+	// Action is what the runner did after the step, "continue" or "halt",
+	// once it finished.
+	Action string `json:"action,omitempty"`
+	// Duration is the time the step took, in seconds, once it finished.
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// LogLine is a message output by a build.
+type LogLine struct {
+	// ID increases with every line, to get the lines after a given one.
+	ID    int64     `json:"id"`
+	Time  time.Time `json:"time"`
+	Build string    `json:"build"`
+	// Level is the kind of message: "say", "message" or "error".
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+type monitoredBuild struct {
+	status    BuildStatus
+	cancel    context.CancelFunc
+	cancelled bool
+	// resume is closed to resume the build once paused.
+	resume chan struct{}
+	ctx    context.Context
+}
+
+// BuildUi returns ui recording the output and the steps of the build called
+// name, to be passed to its Run method. Builds are listed in the order of the
+// calls to BuildUi.
+func (m *BuildMonitor) BuildUi(name string, ui Ui) Ui {
+	m.l.Lock()
+	defer m.l.Unlock()
+	b := &monitoredBuild{status: BuildStatus{Name: name, State: BuildStatePending}}
+	m.builds = append(m.builds, b)
+	return &monitorUi{Ui: ui, m: m, b: b}
+}
+
+// Context returns the context of the build called name, cancelled by Cancel.
+func (m *BuildMonitor) Context(parent context.Context, name string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	m.l.Lock()
+	defer m.l.Unlock()
+	if b := m.build(name); b != nil {
+		b.ctx, b.cancel = ctx, cancel
+		if b.cancelled {
+			cancel()
+		}
+	}
+	return ctx, cancel
+}
+
+// Started marks the build called name as running.
+func (m *BuildMonitor) Started(name string) {
+	m.update(name, func(b *monitoredBuild) {
+		now := time.Now().UTC()
+		b.status.Started = &now
+		b.status.State = BuildStateRunning
+	})
+}
+
+// Finished marks the build called name as done, or failed when err is not
+// nil.
+func (m *BuildMonitor) Finished(name string, err error) {
+	m.update(name, func(b *monitoredBuild) {
+		now := time.Now().UTC()
+		b.status.Finished = &now
+		if b.status.Started == nil {
+			b.status.Started = &now
+		}
+		b.status.Step = ""
+		b.status.Paused = false
+		switch {
+		case err == nil:
+			b.status.State = BuildStateSucceeded
+		case b.cancelled:
+			b.status.State = BuildStateCancelled
+			b.status.Error = err.Error()
+		default:
+			b.status.State = BuildStateFailed
+			b.status.Error = err.Error()
+		}
+	})
+}
+
+// Builds returns the status of the builds.
+func (m *BuildMonitor) Builds() []BuildStatus {
+	m.l.Lock()
+	defer m.l.Unlock()
+	statuses := make([]BuildStatus, 0, len(m.builds))
+	for _, b := range m.builds {
+		statuses = append(statuses, b.snapshot())
+	}
+	return statuses
+}
+
+// Build returns the status of the build called name.
+func (m *BuildMonitor) Build(name string) (BuildStatus, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	b := m.build(name)
+	if b == nil {
+		return BuildStatus{}, ErrUnknownBuild
+	}
+	return b.snapshot(), nil
+}
+
+// Lines returns the last lines output after the line after, by the build
+// called build or by all of them when empty, at most limit of them when
+// positive.
+func (m *BuildMonitor) Lines(build string, after int64, limit int) []LogLine {
+	m.l.Lock()
+	defer m.l.Unlock()
+	lines := []LogLine{}
+	for _, l := range m.lines {
+		if l.ID > after && (build == "" || l.Build == build) {
+			lines = append(lines, l)
+		}
+	}
+	if limit > 0 && len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	return lines
+}
+
+// Pause pauses the build called name before its next step.
+func (m *BuildMonitor) Pause(name string) error {
+	return m.control(name, func(b *monitoredBuild) {
+		if b.resume == nil {
+			b.resume = make(chan struct{})
+			b.status.Paused = true
+		}
+	})
+}
+
+// Resume resumes the build called name once paused.
+func (m *BuildMonitor) Resume(name string) error {
+	return m.control(name, func(b *monitoredBuild) {
+		if b.resume != nil {
+			close(b.resume)
+			b.resume = nil
+			b.status.Paused = false
+		}
+	})
+}
+
+// Cancel cancels the build called name, that cleans up and stops.
+func (m *BuildMonitor) Cancel(name string) error {
+	return m.control(name, func(b *monitoredBuild) {
+		b.cancelled = true
+		if b.cancel != nil {
+			b.cancel()
+		}
+	})
+}
+
+// CancelAll cancels all the builds and calls Interrupt, so that no more
+// builds start.
+func (m *BuildMonitor) CancelAll() {
+	m.l.Lock()
+	for _, b := range m.builds {
+		if b.status.Finished == nil {
+			b.cancelled = true
+			if b.cancel != nil {
+				b.cancel()
+			}
+		}
+	}
+	m.l.Unlock()
+	if m.Interrupt != nil {
+		m.Interrupt()
+	}
+}
+
+// control runs fn on the build called name, unless it is finished.
+func (m *BuildMonitor) control(name string, fn func(*monitoredBuild)) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+	b := m.build(name)
+	if b == nil {
+		return ErrUnknownBuild
+	}
+	if b.status.Finished != nil {
+		return ErrBuildFinished
+	}
+	fn(b)
+	return nil
+}
+
+func (m *BuildMonitor) update(name string, fn func(*monitoredBuild)) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	if b := m.build(name); b != nil {
+		fn(b)
+	}
+}
+
+// build returns the build called name, or nil. m.l must be held.
+func (m *BuildMonitor) build(name string) *monitoredBuild {
+	for _, b := range m.builds {
+		if b.status.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// record keeps the lines of message output by build.
+func (m *BuildMonitor) record(build, level, message string) {
+	message = LogSecretFilter.FilterString(message)
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	for i := range lines {
+		lines[i] = untarget(build, lines[i])
+	}
+
+	max := m.MaxLines
+	if max <= 0 {
+		max = defaultMonitorLines
+	}
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.nextLine++
+	m.lines = append(m.lines, LogLine{
+		ID:      m.nextLine,
+		Time:    time.Now().UTC(),
+		Build:   build,
+		Level:   level,
+		Message: strings.Join(lines, "\n"),
+	})
+	if len(m.lines) > max {
+		m.lines = append(m.lines[:0], m.lines[len(m.lines)-max:]...)
+	}
+}
+
+// waitResumed blocks while build b is paused, until it is resumed or
+// cancelled.
+func (m *BuildMonitor) waitResumed(b *monitoredBuild, step string) {
+	m.l.Lock()
+	resume, ctx := b.resume, b.ctx
+	if resume == nil {
+		m.l.Unlock()
+		return
+	}
+	b.status.State = BuildStatePaused
+	m.l.Unlock()
+
+	m.record(b.status.Name, "say", fmt.Sprintf("Paused before step %s", step))
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	select {
+	case <-resume:
+	case <-done:
+	}
+
+	m.l.Lock()
+	if b.status.State == BuildStatePaused {
+		b.status.State = BuildStateRunning
+	}
+	m.l.Unlock()
+}
+
+func (b *monitoredBuild) snapshot() BuildStatus {
+	s := b.status
+	s.Steps = append([]StepStatus{}, b.status.Steps...)
+	return s
+}
+
+// monitorUi is the UI of a build of a BuildMonitor.
+type monitorUi struct {
+	Ui
+	m *BuildMonitor
+	b *monitoredBuild
+}
+
+var _ Ui = new(monitorUi)
+
+func (u *monitorUi) Say(message string) {
+	u.Ui.Say(message)
+	u.m.record(u.b.status.Name, "say", message)
+}
+
+func (u *monitorUi) Message(message string) {
+	u.Ui.Message(message)
+	u.m.record(u.b.status.Name, "message", message)
+}
+
+func (u *monitorUi) Error(message string) {
+	u.Ui.Error(message)
+	u.m.record(u.b.status.Name, "error", message)
+}
+
+func (u *monitorUi) Machine(t string, args ...string) {
+	category := t
+	if i := strings.Index(t, ","); i > -1 {
+		category = t[i+1:]
+	}
+	switch {
+	case category == EventStepStarted && len(args) >= 1:
+		// The builders wait for the message to be handled before running
+		// the step, so that the build pauses here.
+		u.m.waitResumed(u.b, args[0])
+		u.m.update(u.b.status.Name, func(b *monitoredBuild) {
+			b.status.Step = args[0]
+			b.status.Steps = append(b.status.Steps, StepStatus{Name: args[0], Started: time.Now().UTC()})
+		})
+	case category == EventStepFinished && len(args) >= 3:
+		u.m.update(u.b.status.Name, func(b *monitoredBuild) {
+			b.status.Step = ""
+			for i := len(b.status.Steps) - 1; i >= 0; i-- {
+				if s := &b.status.Steps[i]; s.Name == args[0] && s.Action == "" {
+					s.Action = args[1]
+					s.Duration, _ = strconv.ParseFloat(args[2], 64)
+					break
+				}
+			}
+		})
+	}
+	u.Ui.Machine(t, args...)
+}
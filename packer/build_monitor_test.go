@@ -0,0 +1,151 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuildMonitor(t *testing.T) {
+	m := &BuildMonitor{MaxLines: 3}
+	ui := &TargetedUI{Target: "vm", Ui: m.BuildUi("vm", TestUi(t))}
+	m.BuildUi("other", TestUi(t))
+
+	if s, _ := m.Build("vm"); s.State != BuildStatePending {
+		t.Fatalf("bad state: %s", s.State)
+	}
+	m.Started("vm")
+	ui.Machine(EventStepStarted, "StepCreateVM")
+	s, err := m.Build("vm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.State != BuildStateRunning || s.Step != "StepCreateVM" || len(s.Steps) != 1 {
+		t.Fatalf("bad status: %#v", s)
+	}
+	ui.Machine(EventStepFinished, "StepCreateVM", "continue", "1.500")
+	m.Finished("vm", nil)
+
+	s, _ = m.Build("vm")
+	if s.State != BuildStateSucceeded || s.Step != "" || s.Finished == nil {
+		t.Fatalf("bad status: %#v", s)
+	}
+	if step := s.Steps[0]; step.Action != "continue" || step.Duration != 1.5 {
+		t.Fatalf("bad step: %#v", step)
+	}
+	if err := m.Pause("vm"); err != ErrBuildFinished {
+		t.Fatalf("bad error: %v", err)
+	}
+	if _, err := m.Build("nope"); err != ErrUnknownBuild {
+		t.Fatalf("bad error: %v", err)
+	}
+	if len(m.Builds()) != 2 {
+		t.Fatalf("bad builds: %#v", m.Builds())
+	}
+}
+
+func TestBuildMonitor_lines(t *testing.T) {
+	m := &BuildMonitor{MaxLines: 3}
+	ui := &TargetedUI{Target: "vm", Ui: m.BuildUi("vm", TestUi(t))}
+	other := m.BuildUi("other", TestUi(t))
+
+	ui.Say("one")
+	ui.Message("two\nthree")
+	other.Error("four")
+	ui.Say("five")
+
+	lines := m.Lines("", 0, 0)
+	if len(lines) != 3 {
+		t.Fatalf("bad lines: %#v", lines)
+	}
+	if l := lines[0]; l.Build != "vm" || l.Level != "message" || l.Message != "two\nthree" {
+		t.Fatalf("bad line: %#v", l)
+	}
+	if lines := m.Lines("vm", lines[0].ID, 0); len(lines) != 1 || lines[0].Message != "five" {
+		t.Fatalf("bad lines: %#v", lines)
+	}
+	if lines := m.Lines("", 0, 1); len(lines) != 1 || lines[0].Message != "five" {
+		t.Fatalf("bad lines: %#v", lines)
+	}
+}
+
+func TestBuildMonitor_pause(t *testing.T) {
+	m := &BuildMonitor{}
+	ui := m.BuildUi("vm", TestUi(t))
+	ctx, cancel := m.Context(context.Background(), "vm")
+	defer cancel()
+	m.Started("vm")
+
+	if err := m.Pause("vm"); err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	go func() {
+		ui.Machine("vm,"+EventStepStarted, "StepCreateVM")
+		close(started)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if s, _ := m.Build("vm"); s.State == BuildStatePaused {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the build didn't pause")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-started:
+		t.Fatal("the step started while paused")
+	default:
+	}
+
+	if err := m.Resume("vm"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the build didn't resume")
+	}
+	if s, _ := m.Build("vm"); s.State != BuildStateRunning || s.Paused || s.Step != "StepCreateVM" {
+		t.Fatalf("bad status: %#v", s)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("the build is cancelled")
+	}
+}
+
+func TestBuildMonitor_cancel(t *testing.T) {
+	interrupted := false
+	m := &BuildMonitor{Interrupt: func() { interrupted = true }}
+	m.BuildUi("vm", TestUi(t))
+	m.BuildUi("pending", TestUi(t))
+	ctx, cancel := m.Context(context.Background(), "vm")
+	defer cancel()
+	m.Started("vm")
+
+	if err := m.Cancel("vm"); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() == nil {
+		t.Fatal("the build is not cancelled")
+	}
+	m.Finished("vm", errors.New("cancelled"))
+	if s, _ := m.Build("vm"); s.State != BuildStateCancelled {
+		t.Fatalf("bad state: %s", s.State)
+	}
+
+	m.CancelAll()
+	if !interrupted {
+		t.Fatal("not interrupted")
+	}
+	// The builds cancelled before they start are cancelled once they do.
+	pending, cancelPending := m.Context(context.Background(), "pending")
+	defer cancelPending()
+	if pending.Err() == nil {
+		t.Fatal("the pending build is not cancelled")
+	}
+}
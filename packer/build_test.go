@@ -3,7 +3,9 @@ package packer
 import (
 	"context"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/packer/helper/common"
 )
@@ -88,6 +90,66 @@ func TestBuild_Prepare(t *testing.T) {
 	}
 }
 
+func TestBuild_Prepare_outputs(t *testing.T) {
+	build := testBuild()
+	build.Dependencies = []string{"base"}
+	build.SetOutputs(BuildOutputs{"base": {"id": "ami-123"}})
+	builder := build.Builder.(*MockBuilder)
+
+	if _, err := build.Prepare(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	packerConfig := testDefaultPackerConfig()
+	packerConfig[BuildOutputsConfigKey] = map[string]string{"base.id": "ami-123"}
+	if !reflect.DeepEqual(builder.PrepareConfig, []interface{}{42, packerConfig}) {
+		t.Fatalf("bad: %#v", builder.PrepareConfig)
+	}
+}
+
+func TestBuild_Prepare_billingTags(t *testing.T) {
+	build := testBuild()
+	build.BillingTags = map[string]string{"team": "images"}
+	builder := build.Builder.(*MockBuilder)
+
+	if _, err := build.Prepare(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	packerConfig := testDefaultPackerConfig()
+	packerConfig[BillingTagsConfigKey] = map[string]string{"team": "images"}
+	if !reflect.DeepEqual(builder.PrepareConfig, []interface{}{42, packerConfig}) {
+		t.Fatalf("bad: %#v", builder.PrepareConfig)
+	}
+}
+
+func TestBuild_Prepare_preflight(t *testing.T) {
+	build := testBuild()
+	build.Preflight = PreflightOnly
+	builder := build.Builder.(*MockBuilder)
+
+	if _, err := build.Prepare(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	packerConfig := testDefaultPackerConfig()
+	packerConfig[PreflightConfigKey] = PreflightOnly
+	if !reflect.DeepEqual(builder.PrepareConfig, []interface{}{42, packerConfig}) {
+		t.Fatalf("bad: %#v", builder.PrepareConfig)
+	}
+}
+
+func TestBuild_Prepare_placeholderOutputs(t *testing.T) {
+	build := testBuild()
+	build.Dependencies = []string{"base"}
+	builder := build.Builder.(*MockBuilder)
+
+	if _, err := build.Prepare(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	outputs := builder.PrepareConfig[1].(map[string]interface{})[BuildOutputsConfigKey].(map[string]string)
+	if outputs["base.id"] == "" || outputs["base.builder_id"] == "" {
+		t.Fatalf("bad: %#v", outputs)
+	}
+}
+
 func TestBuild_Prepare_SkipWhenBuilderAlreadyInitialized(t *testing.T) {
 	build := testBuild()
 	builder := build.Builder.(*MockBuilder)
@@ -165,6 +227,21 @@ func TestBuild_Prepare_Debug(t *testing.T) {
 	}
 }
 
+func TestBuild_Prepare_OnConflict(t *testing.T) {
+	packerConfig := testDefaultPackerConfig()
+	packerConfig[ForceConfigKey] = true
+	packerConfig[OnConflictConfigKey] = ConflictReplace
+
+	build := testBuild()
+	builder := build.Builder.(*MockBuilder)
+
+	build.SetOnConflict(ConflictReplace)
+	build.Prepare()
+	if !reflect.DeepEqual(builder.PrepareConfig, []interface{}{42, packerConfig}) {
+		t.Fatalf("bad: %#v", builder.PrepareConfig)
+	}
+}
+
 func TestBuildPrepare_variables_default(t *testing.T) {
 	packerConfig := testDefaultPackerConfig()
 	packerConfig[UserVariablesConfigKey] = map[string]string{
@@ -489,3 +566,47 @@ func TestBuild_Cancel(t *testing.T) {
 		t.Fatal("build should err")
 	}
 }
+
+func TestBuild_Run_buildTimeout(t *testing.T) {
+	build := testBuild()
+	build.BuildTimeout = 10 * time.Millisecond
+	build.Prepare()
+
+	builder := build.Builder.(*MockBuilder)
+	builder.RunFn = func(ctx context.Context) {
+		<-ctx.Done()
+	}
+
+	_, err := build.Run(context.Background(), testUi())
+	if err == nil {
+		t.Fatal("build should err")
+	}
+	if !strings.Contains(err.Error(), "build_timeout") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	pp := build.PostProcessors[0][0].PostProcessor.(*MockPostProcessor)
+	if pp.PostProcessCalled {
+		t.Fatal("post-processor should not be called")
+	}
+}
+
+func TestBuild_Run_provisionTimeout(t *testing.T) {
+	build := testBuild()
+	build.ProvisionTimeout = 10 * time.Millisecond
+	build.Prepare()
+
+	prov := build.Provisioners[0].Provisioner.(*MockProvisioner)
+	prov.ProvFunc = func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	_, err := build.Run(context.Background(), testUi())
+	if err == nil {
+		t.Fatal("build should err")
+	}
+	if !strings.Contains(err.Error(), "provision_timeout") {
+		t.Fatalf("bad: %s", err)
+	}
+}
@@ -0,0 +1,292 @@
+package packer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Machine-readable messages telling when the provisioners and the
+// post-processors of a build start and finish, like the step-started and
+// step-finished messages of the steps. The started messages have the name of
+// the provisioner or the post-processor as argument, the finished messages
+// the name and the time it took, in seconds.
+const (
+	EventProvisionerStarted    = "provisioner-started"
+	EventProvisionerFinished   = "provisioner-finished"
+	EventPostProcessorStarted  = "post-processor-started"
+	EventPostProcessorFinished = "post-processor-finished"
+)
+
+// The kinds of the spans of a build's timings.
+const (
+	TimingBuild         = "build"
+	TimingStep          = "step"
+	TimingProvisioner   = "provisioner"
+	TimingPostProcessor = "post-processor"
+)
+
+// TimingSpan is the time a build, or a step, a provisioner or a
+// post-processor of it, took.
+type TimingSpan struct {
+	Name string `json:"name"`
+	// Kind is one of the Timing* kinds.
+	Kind    string    `json:"kind"`
+	Started time.Time `json:"started"`
+	// Duration is the time the span took, in seconds.
+	Duration float64 `json:"duration"`
+	// Spans are the spans that ran during this one, like the provisioners
+	// run by the provisioning step of a builder.
+	Spans []*TimingSpan `json:"spans,omitempty"`
+}
+
+// Label is how the span is shown in the reports: the name of the steps, and
+// the kind and the name of the other spans.
+func (s *TimingSpan) Label() string {
+	if s.Kind == TimingStep || s.Kind == TimingBuild {
+		return s.Name
+	}
+	return s.Kind + " " + s.Name
+}
+
+// self returns the time spent in s but in none of its spans, in seconds.
+func (s *TimingSpan) self() float64 {
+	self := s.Duration
+	for _, c := range s.Spans {
+		self -= c.Duration
+	}
+	if self < 0 {
+		return 0
+	}
+	return self
+}
+
+// Timings records how long the builds, their steps, their provisioners and
+// their post-processors take, from the machine-readable messages of the
+// builds, to report where the time of the builds went.
+type Timings struct {
+	l      sync.Mutex
+	builds []*TimingSpan
+	// open are the spans of the builds not finished yet, innermost last.
+	open map[string][]*TimingSpan
+}
+
+// BuildUi returns ui recording the timings of the build called name, to be
+// passed to its Run method. Builds are reported in the order of the calls to
+// BuildUi.
+func (t *Timings) BuildUi(name string, ui Ui) Ui {
+	t.l.Lock()
+	defer t.l.Unlock()
+	t.builds = append(t.builds, &TimingSpan{Name: name, Kind: TimingBuild})
+	return &timingsUi{Ui: ui, t: t, name: name}
+}
+
+// Started marks the start of the build called name.
+func (t *Timings) Started(name string) {
+	t.l.Lock()
+	defer t.l.Unlock()
+	b := t.build(name)
+	if b == nil {
+		return
+	}
+	b.Started = time.Now().UTC()
+	if t.open == nil {
+		t.open = map[string][]*TimingSpan{}
+	}
+	t.open[name] = []*TimingSpan{b}
+}
+
+// Finished marks the end of the build called name, ending the spans it left
+// running.
+func (t *Timings) Finished(name string) {
+	t.l.Lock()
+	defer t.l.Unlock()
+	for _, s := range t.open[name] {
+		s.Duration = time.Since(s.Started).Seconds()
+	}
+	delete(t.open, name)
+}
+
+// Builds returns the timings of the builds that started.
+func (t *Timings) Builds() []*TimingSpan {
+	t.l.Lock()
+	defer t.l.Unlock()
+	var builds []*TimingSpan
+	for _, b := range t.builds {
+		if !b.Started.IsZero() {
+			builds = append(builds, b)
+		}
+	}
+	return builds
+}
+
+func (t *Timings) build(name string) *TimingSpan {
+	for _, b := range t.builds {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// start opens a span in the innermost running span of the build.
+func (t *Timings) start(build, kind, name string) {
+	t.l.Lock()
+	defer t.l.Unlock()
+	open := t.open[build]
+	if len(open) == 0 {
+		return
+	}
+	s := &TimingSpan{Name: name, Kind: kind, Started: time.Now().UTC()}
+	parent := open[len(open)-1]
+	parent.Spans = append(parent.Spans, s)
+	t.open[build] = append(open, s)
+}
+
+// finish ends the innermost running span of the build of the given kind and
+// name, and the spans it left running. duration is the time the span took as
+// measured by the build, in seconds, or empty to measure it here.
+func (t *Timings) finish(build, kind, name, duration string) {
+	t.l.Lock()
+	defer t.l.Unlock()
+	open := t.open[build]
+	for i := len(open) - 1; i > 0; i-- {
+		if s := open[i]; s.Kind != kind || s.Name != name {
+			continue
+		}
+		for _, s := range open[i+1:] {
+			s.Duration = time.Since(s.Started).Seconds()
+		}
+		s := open[i]
+		s.Duration = time.Since(s.Started).Seconds()
+		if d, err := strconv.ParseFloat(duration, 64); err == nil {
+			s.Duration = d
+		}
+		t.open[build] = open[:i]
+		return
+	}
+}
+
+// WriteJSON writes the timings of the builds as a JSON object with a "builds"
+// list of spans.
+func (t *Timings) WriteJSON(w io.Writer) error {
+	builds := t.Builds()
+	if builds == nil {
+		builds = []*TimingSpan{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Builds []*TimingSpan `json:"builds"`
+	}{builds})
+}
+
+// WriteFolded writes the timings of the builds as folded stacks, the input
+// format of flame graph tools like flamegraph.pl or speedscope: a line per
+// span with the labels of the spans from the build down to it, separated by
+// semicolons, and the milliseconds spent in the span but in none of its
+// spans.
+func (t *Timings) WriteFolded(w io.Writer) error {
+	var write func(stack string, s *TimingSpan) error
+	write = func(stack string, s *TimingSpan) error {
+		label := strings.Replace(s.Label(), ";", "_", -1)
+		if stack != "" {
+			label = stack + ";" + label
+		}
+		if ms := int64(s.self() * 1000); ms > 0 {
+			if _, err := fmt.Fprintf(w, "%s %d\n", label, ms); err != nil {
+				return err
+			}
+		}
+		for _, c := range s.Spans {
+			if err := write(label, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, b := range t.Builds() {
+		if err := write("", b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Table returns the timings of the builds as a table, listing every span
+// indented under the span it ran in, with the time it took and the share of
+// the time of the build it represents.
+func (t *Timings) Table() string {
+	type row struct {
+		label, duration, share string
+	}
+	var rows []row
+	width := 0
+	var add func(depth int, s *TimingSpan, total float64)
+	add = func(depth int, s *TimingSpan, total float64) {
+		r := row{
+			label:    strings.Repeat("  ", depth) + s.Label(),
+			duration: formatSeconds(s.Duration),
+		}
+		if total > 0 {
+			r.share = fmt.Sprintf("%5.1f%%", 100*s.Duration/total)
+		}
+		if len(r.label) > width {
+			width = len(r.label)
+		}
+		rows = append(rows, r)
+		for _, c := range s.Spans {
+			add(depth+1, c, total)
+		}
+	}
+	for _, b := range t.Builds() {
+		add(0, b, b.Duration)
+	}
+
+	var out strings.Builder
+	for _, r := range rows {
+		fmt.Fprintf(&out, "%-*s %10s %s\n", width, r.label, r.duration, r.share)
+	}
+	return out.String()
+}
+
+// formatSeconds formats a duration in seconds to the tenth of a second.
+func formatSeconds(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second)).Round(100 * time.Millisecond)
+	if d == 0 {
+		return "0.0s"
+	}
+	return d.String()
+}
+
+type timingsUi struct {
+	Ui
+	t    *Timings
+	name string
+}
+
+func (u *timingsUi) Machine(t string, args ...string) {
+	category := t
+	if i := strings.Index(t, ","); i > -1 {
+		category = t[i+1:]
+	}
+	switch {
+	case category == EventStepStarted && len(args) >= 1:
+		u.t.start(u.name, TimingStep, args[0])
+	case category == EventStepFinished && len(args) >= 3:
+		u.t.finish(u.name, TimingStep, args[0], args[2])
+	case category == EventProvisionerStarted && len(args) >= 1:
+		u.t.start(u.name, TimingProvisioner, args[0])
+	case category == EventProvisionerFinished && len(args) >= 2:
+		u.t.finish(u.name, TimingProvisioner, args[0], args[1])
+	case category == EventPostProcessorStarted && len(args) >= 1:
+		u.t.start(u.name, TimingPostProcessor, args[0])
+	case category == EventPostProcessorFinished && len(args) >= 2:
+		u.t.finish(u.name, TimingPostProcessor, args[0], args[1])
+	}
+	u.Ui.Machine(t, args...)
+}
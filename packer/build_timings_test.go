@@ -0,0 +1,66 @@
+package packer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTimings(t *testing.T) {
+	timings := &Timings{}
+	ui := &TargetedUI{Target: "vm", Ui: timings.BuildUi("vm", TestUi(t))}
+	timings.BuildUi("skipped", TestUi(t))
+
+	timings.Started("vm")
+	ui.Machine(EventStepStarted, "StepCreateVM")
+	ui.Machine(EventStepFinished, "StepCreateVM", "continue", "1.500")
+	ui.Machine(EventStepStarted, "StepProvision")
+	ui.Machine(EventProvisionerStarted, "shell")
+	ui.Machine(EventProvisionerFinished, "shell", "2.000")
+	ui.Machine(EventStepFinished, "StepProvision", "continue", "3.000")
+	ui.Machine(EventPostProcessorStarted, "manifest")
+	ui.Machine(EventPostProcessorFinished, "manifest", "0.500")
+	ui.Machine(EventStepStarted, "StepInterrupted")
+	timings.Finished("vm")
+
+	builds := timings.Builds()
+	if len(builds) != 1 || builds[0].Name != "vm" {
+		t.Fatalf("only the started builds should be reported: %#v", builds)
+	}
+	spans := builds[0].Spans
+	if len(spans) != 4 || len(spans[1].Spans) != 1 || spans[1].Spans[0].Label() != "provisioner shell" {
+		t.Fatalf("bad spans: %#v", spans)
+	}
+
+	var folded bytes.Buffer
+	if err := timings.WriteFolded(&folded); err != nil {
+		t.Fatal(err)
+	}
+	expected := "vm;StepCreateVM 1500\n" +
+		"vm;StepProvision 1000\n" +
+		"vm;StepProvision;provisioner shell 2000\n" +
+		"vm;post-processor manifest 500\n"
+	if folded.String() != expected {
+		t.Fatalf("bad folded stacks:\n%s", folded.String())
+	}
+
+	var out bytes.Buffer
+	if err := timings.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Builds []*TimingSpan `json:"builds"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Builds) != 1 || report.Builds[0].Spans[1].Spans[0].Duration != 2 {
+		t.Fatalf("bad JSON report: %s", out.String())
+	}
+
+	table := timings.Table()
+	if !strings.Contains(table, "\n    provisioner shell ") || !strings.Contains(table, " 2s ") {
+		t.Fatalf("bad table:\n%s", table)
+	}
+}
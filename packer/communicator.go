@@ -168,6 +168,25 @@ OutputLoop:
 	return nil
 }
 
+// ContextReader returns a reader of r failing with the error of ctx once ctx
+// is cancelled, so that an upload reading it stops when the build is
+// cancelled rather than once it is complete.
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: r}
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
 // SetExited is a helper for setting that this process is exited. This
 // should be called by communicators who are running a remote command in
 // order to set that the command is done.
@@ -86,3 +86,21 @@ func TestRemoteCmd_Wait(t *testing.T) {
 		t.Fatal("never got exit notification")
 	}
 }
+
+func TestContextReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := ContextReader(ctx, strings.NewReader("hello world"))
+
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(buf) != "hello" {
+		t.Fatalf("bad: %q", buf)
+	}
+
+	cancel()
+	if _, err := r.Read(buf); err != context.Canceled {
+		t.Fatalf("reading a cancelled reader should fail, got: %v", err)
+	}
+}
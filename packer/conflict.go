@@ -0,0 +1,20 @@
+package packer
+
+// The ways a build handles an existing artifact with the name of the artifact
+// it creates, like an AMI or an output directory. They are passed to the
+// builders in the OnConflictConfigKey configuration key.
+const (
+	// ConflictFail fails the build, the default.
+	ConflictFail = "fail"
+	// ConflictForce deletes the existing artifact before building.
+	ConflictForce = "force"
+	// ConflictReplace keeps the existing artifact until the build creates
+	// its own, so that it is kept when the build fails.
+	ConflictReplace = "replace"
+	// ConflictSkip skips the build without error, keeping the existing
+	// artifact.
+	ConflictSkip = "skip"
+)
+
+// ConflictModes are the valid values of the OnConflictConfigKey.
+var ConflictModes = []string{ConflictFail, ConflictForce, ConflictReplace, ConflictSkip}
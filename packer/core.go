@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -90,6 +91,20 @@ type ComponentFinder struct {
 	BuilderStore       BuilderStore
 	ProvisionerStore   ProvisionerStore
 	PostProcessorStore PostProcessorStore
+
+	// PluginConfig loads the plugins required by HCL2 configurations.
+	PluginConfig *PluginConfig
+}
+
+// PluginConfig tells where the plugins installed by `packer init` are, and
+// how to load them.
+type PluginConfig struct {
+	// PluginDirectories are the directories plugins are installed in, by
+	// order of preference. Plugins are installed in the first one.
+	PluginDirectories []string
+	// LoadPlugin registers the component of the plugin binary at path in
+	// the component stores, replacing the component of the same name.
+	LoadPlugin func(path string) error
 }
 
 // NewCore creates a new Core.
@@ -115,6 +130,8 @@ func (core *Core) Initialize() error {
 	for _, secret := range core.secrets {
 		LogSecretFilter.Set(secret)
 	}
+	// Values read from a secret store are always sensitive.
+	LogSecretFilter.Set(interpolate.Secrets()...)
 
 	// Go through and interpolate all the build names. We should be able
 	// to do this at this point with the variables.
@@ -180,6 +197,26 @@ func (c *Core) generateCoreBuildProvisioner(rawP *template.Provisioner, rawName
 			config = append(config, override)
 		}
 	}
+	// If we're running as another user, the commands of the provisioner are
+	// run and its files uploaded as that user.
+	if rawP.RemoteUser != "" {
+		remoteUser, err := interpolate.Render(rawP.RemoteUser, c.Context())
+		if err != nil {
+			return cbp, fmt.Errorf("failed to interpolate `remote_user`: %s", err.Error())
+		}
+		if err := ValidateRemoteUser(remoteUser); err != nil {
+			return cbp, err
+		}
+		remotePassword, err := interpolate.Render(rawP.RemotePassword, c.Context())
+		if err != nil {
+			return cbp, fmt.Errorf("failed to interpolate `remote_password`: %s", err.Error())
+		}
+		provisioner = &UserProvisioner{
+			RemoteUser:     remoteUser,
+			RemotePassword: remotePassword,
+			Provisioner:    provisioner,
+		}
+	}
 	// If we're pausing, we wrap the provisioner in a special pauser.
 	if rawP.PauseBefore != 0 {
 		provisioner = &PausedProvisioner{
@@ -203,12 +240,33 @@ func (c *Core) generateCoreBuildProvisioner(rawP *template.Provisioner, rawName
 			return cbp, fmt.Errorf("`max_retries` must be a valid integer: %s", err.Error())
 		}
 	}
+	if rawP.ErrorHandling != nil && rawP.ErrorHandling.Retries != 0 {
+		maxRetries = rawP.ErrorHandling.Retries
+	}
 	if maxRetries != 0 {
 		provisioner = &RetriedProvisioner{
 			MaxRetries:  maxRetries,
 			Provisioner: provisioner,
 		}
 	}
+	// If the provisioner has an idempotency marker, it is skipped when the
+	// marker exists and the marker is recorded once it succeeded.
+	if rawP.IdempotencyMarker != "" {
+		marker, err := interpolate.Render(rawP.IdempotencyMarker, c.Context())
+		if err != nil {
+			return cbp, fmt.Errorf("failed to interpolate `idempotency_marker`: %s", err.Error())
+		}
+		provisioner = &MarkedProvisioner{
+			Marker:      marker,
+			Provisioner: provisioner,
+		}
+	}
+	if rawP.ErrorHandling != nil && rawP.ErrorHandling.OnFailure != "" {
+		provisioner = &ErrorHandledProvisioner{
+			OnFailure:   rawP.ErrorHandling.OnFailure,
+			Provisioner: provisioner,
+		}
+	}
 	cbp = CoreBuildProvisioner{
 		PType:       rawP.Type,
 		Provisioner: provisioner,
@@ -240,6 +298,23 @@ func (c *Core) GetBuilds(opts GetBuildsOptions) ([]Build, hcl.Diagnostics) {
 		b.SetDebug(opts.Debug)
 		b.SetForce(opts.Force)
 		b.SetOnError(opts.OnError)
+		if cb, ok := b.(*CoreBuild); ok {
+			cb.Breakpoints = opts.Breakpoints
+			cb.BillingTags = opts.BillingTags
+			cb.Preflight = opts.Preflight
+			if opts.OnConflict != "" {
+				cb.SetOnConflict(opts.OnConflict)
+			}
+			if opts.Resume != "" {
+				cb.SetResume(opts.Resume)
+			}
+		}
+
+		if opts.DeferDependents && len(BuildDependencies(b)) > 0 {
+			log.Printf("Deferring the preparation of build %s until its dependencies ran", b.Name())
+			builds = append(builds, b)
+			continue
+		}
 
 		warnings, err := b.Prepare()
 		if err != nil {
@@ -370,10 +445,59 @@ func (c *Core) Build(n string) (Build, error) {
 
 	// TODO hooks one day
 
+	info := NewBuildInfo(filepath.Dir(c.Template.Path))
+
+	var locks []string
+	for _, l := range configBuilder.Locks {
+		ctx := c.Context()
+		ctx.BuildName = n
+		ctx.BuildType = configBuilder.Type
+		ctx.BuildInfo = info.Map()
+		rendered, err := interpolate.Render(l, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error interpolating lock %q: %s", l, err)
+		}
+		locks = append(locks, rendered)
+	}
+
+	var namedOutputs func(*OutputsData) (map[string]string, error)
+	if len(configBuilder.Outputs) > 0 {
+		namedOutputs = func(data *OutputsData) (map[string]string, error) {
+			ctx := c.Context()
+			ctx.BuildName = n
+			ctx.BuildType = configBuilder.Type
+			ctx.BuildInfo = info.Map()
+			ctx.Data = data
+			outputs := make(map[string]string, len(configBuilder.Outputs))
+			for k, v := range configBuilder.Outputs {
+				rendered, err := interpolate.Render(v, ctx)
+				if err != nil {
+					return nil, fmt.Errorf("output %s: %s", k, err)
+				}
+				outputs[k] = rendered
+			}
+			return outputs, nil
+		}
+	}
+
+	notifications, err := c.notifications()
+	if err != nil {
+		return nil, err
+	}
+
+	onError := ""
+	if c.Template.ErrorHandling != nil {
+		onError = c.Template.ErrorHandling.OnFailure
+	}
+
 	// Return a structure that contains the plugins, their types, variables, and
 	// the raw builder config loaded from the json template
 	return &CoreBuild{
+		info:               info,
 		Type:               n,
+		Dependencies:       configBuilder.DependsOn,
+		Locks:              locks,
+		NamedOutputs:       namedOutputs,
 		Builder:            builder,
 		BuilderConfig:      configBuilder.Config,
 		BuilderType:        configBuilder.Type,
@@ -382,9 +506,59 @@ func (c *Core) Build(n string) (Build, error) {
 		CleanupProvisioner: cleanupProvisioner,
 		TemplatePath:       c.Template.Path,
 		Variables:          c.variables,
+		BuildTimeout:       c.Template.BuildTimeout,
+		ProvisionTimeout:   c.Template.ProvisionTimeout,
+		PostProcessTimeout: c.Template.PostProcessTimeout,
+		Notifications:      notifications,
+		onError:            onError,
 	}, nil
 }
 
+// notifications renders the notifications of the template with the user
+// variables. Their payload and subject are rendered once they are sent.
+func (c *Core) notifications() ([]*Notification, error) {
+	var notifications []*Notification
+	for i, tn := range c.Template.Notifications {
+		var err error
+		ctx := c.Context()
+		render := func(v string) string {
+			if err == nil {
+				v, err = interpolate.Render(v, ctx)
+			}
+			return v
+		}
+		n := &Notification{
+			Type:         tn.Type,
+			On:           tn.On,
+			URL:          render(tn.URL),
+			Method:       tn.Method,
+			Template:     tn.Template,
+			Subject:      tn.Subject,
+			SMTPAddress:  render(tn.SMTPAddress),
+			SMTPUsername: render(tn.SMTPUsername),
+			SMTPPassword: render(tn.SMTPPassword),
+			From:         render(tn.From),
+		}
+		for _, to := range tn.To {
+			n.To = append(n.To, render(to))
+		}
+		if len(tn.Headers) > 0 {
+			n.Headers = make(map[string]string, len(tn.Headers))
+			for k, v := range tn.Headers {
+				n.Headers[k] = render(v)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("notification %d: %s", i+1, err)
+		}
+		if n.SMTPPassword != "" {
+			LogSecretFilter.Set(n.SMTPPassword)
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
 // Context returns an interpolation context.
 func (c *Core) Context() *interpolate.Context {
 	return &interpolate.Context{
@@ -419,9 +593,15 @@ func (c *Core) EvaluateExpression(line string) (string, bool, hcl.Diagnostics) {
 	case line == "help":
 		return ConsoleHelp, false, nil
 	case line == "variables":
+		vars := c.Context().UserVariables
+		keys := make([]string, 0, len(vars))
+		for k := range vars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
 		varsstring := "\n"
-		for k, v := range c.Context().UserVariables {
-			varsstring += fmt.Sprintf("%s: %+v,\n", k, v)
+		for _, k := range keys {
+			varsstring += fmt.Sprintf("%s: %+v,\n", k, vars[k])
 		}
 
 		return varsstring, false, nil
@@ -666,6 +846,89 @@ func (c *Core) validate() error {
 		}
 	}
 
+	// Validate error handling settings
+	if eh := c.Template.ErrorHandling; eh != nil {
+		if verr := ValidateOnFailure(eh.OnFailure, BuildOnFailureValues); verr != nil {
+			err = multierror.Append(err, fmt.Errorf("error_handling: %s", verr))
+		}
+		if eh.Retries != 0 {
+			err = multierror.Append(err, fmt.Errorf(
+				"error_handling: retries can only be set on provisioners"))
+		}
+	}
+	// Validate notifications
+	for i, n := range c.Template.Notifications {
+		verr := (&Notification{
+			Type:        n.Type,
+			On:          n.On,
+			URL:         n.URL,
+			SMTPAddress: n.SMTPAddress,
+			From:        n.From,
+			To:          n.To,
+		}).Validate()
+		if verr != nil {
+			err = multierror.Append(err, fmt.Errorf("notification %d: %s", i+1, verr))
+		}
+	}
+	// Validate timeouts
+	if c.Template.BuildTimeout < 0 {
+		err = multierror.Append(err, fmt.Errorf("build_timeout must not be negative"))
+	}
+	if c.Template.ProvisionTimeout < 0 {
+		err = multierror.Append(err, fmt.Errorf("provision_timeout must not be negative"))
+	}
+	if c.Template.PostProcessTimeout < 0 {
+		err = multierror.Append(err, fmt.Errorf("post_process_timeout must not be negative"))
+	}
+	for i, p := range c.Template.Provisioners {
+		if verr := ValidateRemoteUser(p.RemoteUser); verr != nil && !strings.Contains(p.RemoteUser, "{{") {
+			err = multierror.Append(err, fmt.Errorf("provisioner %d: %s", i+1, verr))
+		}
+		if p.RemotePassword != "" && p.RemoteUser == "" {
+			err = multierror.Append(err, fmt.Errorf(
+				"provisioner %d: remote_password requires remote_user", i+1))
+		}
+		eh := p.ErrorHandling
+		if eh == nil {
+			continue
+		}
+		if verr := ValidateOnFailure(eh.OnFailure, ProvisionerOnFailureValues); verr != nil {
+			err = multierror.Append(err, fmt.Errorf(
+				"provisioner %d: error_handling: %s", i+1, verr))
+		}
+		if eh.Retries != 0 && p.MaxRetries != "" {
+			err = multierror.Append(err, fmt.Errorf(
+				"provisioner %d: error_handling.retries and max_retries are mutually exclusive", i+1))
+		}
+	}
+
+	// Validate that the dependencies between builds are not cyclic
+	names := make([]string, 0, len(c.Template.Builders))
+	deps := make(map[string][]string, len(c.Template.Builders))
+	for n, b := range c.Template.Builders {
+		names = append(names, n)
+		deps[n] = b.DependsOn
+	}
+	sort.Strings(names)
+
+	// Validate the names of the outputs
+	for _, n := range names {
+		outputs := make([]string, 0, len(c.Template.Builders[n].Outputs))
+		for k := range c.Template.Builders[n].Outputs {
+			outputs = append(outputs, k)
+		}
+		sort.Strings(outputs)
+		for _, k := range outputs {
+			if verr := ValidateOutputName(k); verr != nil {
+				err = multierror.Append(err, fmt.Errorf("builder %s: %s", n, verr))
+			}
+		}
+	}
+
+	if _, verr := SortBuildNames(names, deps); verr != nil {
+		err = multierror.Append(err, verr)
+	}
+
 	// TODO: validate all builders exist
 	// TODO: ^^ provisioner
 	// TODO: ^^ post-processor
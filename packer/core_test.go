@@ -113,6 +113,98 @@ func TestCoreBuild_basicInterpolated(t *testing.T) {
 	}
 }
 
+func TestCoreLintConfig(t *testing.T) {
+	config := TestCoreConfig(t)
+	testCoreTemplate(t, config, fixtureDir("lint.json"))
+	config.Variables = map[string]string{"password": "packer"}
+	core := TestCore(t, config)
+
+	blocks, diags := core.LintConfig()
+	if diags.HasErrors() {
+		t.Fatalf("err: %s", diags)
+	}
+	if len(blocks) != 3 {
+		t.Fatalf("bad: %#v", blocks)
+	}
+	for i, kind := range []string{LintBuilder, LintProvisioner, LintPostProcessor} {
+		if blocks[i].Kind != kind || blocks[i].Type != "test" {
+			t.Fatalf("bad block %d: %#v", i, blocks[i])
+		}
+		if blocks[i].Range.Filename != core.Template.Path {
+			t.Fatalf("bad range: %#v", blocks[i].Range)
+		}
+	}
+	if blocks[0].Options["ssh_password"] != "{{ user `password` }}" {
+		t.Fatalf("bad options: %#v", blocks[0].Options)
+	}
+}
+
+func TestCoreBuild_outputs(t *testing.T) {
+	config := TestCoreConfig(t)
+	testCoreTemplate(t, config, fixtureDir("build-outputs.json"))
+	core := TestCore(t, config)
+
+	build, err := core.Build("test")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	outputs, err := Outputs(build, []Artifact{
+		&MockArtifact{IdValue: "first"},
+		&MockArtifact{
+			IdValue:     "last",
+			StateValues: map[string]interface{}{"atlas.artifact.metadata": map[string]string{"region.us-east-1": "ami-1234"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string]string{
+		"id":         "last",
+		"builder_id": "bid",
+		"image":      "test-last",
+		"ami":        "ami-1234",
+		"first":      "first",
+	}
+	if !reflect.DeepEqual(outputs, expected) {
+		t.Fatalf("bad: %#v", outputs)
+	}
+}
+
+func TestCoreBuild_locks(t *testing.T) {
+	config := TestCoreConfig(t)
+	testCoreTemplate(t, config, fixtureDir("build-locks.json"))
+	core := TestCore(t, config)
+
+	build, err := core.Build("test")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if locks := build.(*CoreBuild).Locks; !reflect.DeepEqual(locks, []string{"image/test-1.0"}) {
+		t.Fatalf("bad: %#v", locks)
+	}
+}
+
+func TestCoreBuild_notifications(t *testing.T) {
+	config := TestCoreConfig(t)
+	testCoreTemplate(t, config, fixtureDir("build-notifications.json"))
+	core := TestCore(t, config)
+
+	build, err := core.Build("test")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []*Notification{{
+		Type:     "http",
+		URL:      "https://example.com/hooks/packer",
+		Headers:  map[string]string{"Authorization": "Bearer secret"},
+		Template: "{{ .BuildName }}",
+	}}
+	if n := build.(*CoreBuild).Notifications; !reflect.DeepEqual(n, expected) {
+		t.Fatalf("bad: %#v", n)
+	}
+}
+
 func TestCoreBuild_env(t *testing.T) {
 	os.Setenv("PACKER_TEST_ENV", "test")
 	defer os.Setenv("PACKER_TEST_ENV", "")
@@ -474,6 +566,15 @@ func TestCoreValidate(t *testing.T) {
 		// Min version good
 		{"validate-min-version.json", map[string]string{"foo": "bar"}, false},
 		{"validate-min-version-high.json", map[string]string{"foo": "bar"}, true},
+
+		// Invalid error handling
+		{"validate-error-handling.json", nil, true},
+
+		// Incomplete notification
+		{"validate-notifications.json", nil, true},
+
+		// Reserved output name
+		{"validate-output-reserved.json", nil, true},
 	}
 
 	for _, tc := range cases {
@@ -846,3 +947,53 @@ func TestCoreBuild_provRetry(t *testing.T) {
 		t.Fatal("provisioner should retry for max_retries integer value")
 	}
 }
+
+func TestCoreBuild_provErrorHandling(t *testing.T) {
+	config := TestCoreConfig(t)
+	testCoreTemplate(t, config, fixtureDir("build-prov-error-handling.json"))
+	b := TestBuilder(t, config, "test")
+	p := TestProvisioner(t, config, "test")
+	core := TestCore(t, config)
+
+	b.ArtifactId = "hello"
+
+	build, err := core.Build("test")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	coreBuild := build.(*CoreBuild)
+	if coreBuild.onError != "abort" {
+		t.Fatalf("the build should abort on error, got %q", coreBuild.onError)
+	}
+	build.SetOnError("ask")
+	if coreBuild.onError != "ask" {
+		t.Fatalf("-on-error should take precedence, got %q", coreBuild.onError)
+	}
+
+	if _, err := build.Prepare(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	p.ProvFunc = func(ctx context.Context) error {
+		return errors.New("failed")
+	}
+	prov, ok := coreBuild.Provisioners[0].Provisioner.(*ErrorHandledProvisioner)
+	if !ok {
+		t.Fatalf("provisioner should handle errors: %#v", coreBuild.Provisioners[0].Provisioner)
+	}
+	if prov.OnFailure != OnFailureContinue {
+		t.Fatalf("bad: %s", prov.OnFailure)
+	}
+	if retried, ok := prov.Provisioner.(*RetriedProvisioner); !ok || retried.MaxRetries != 1 {
+		t.Fatalf("provisioner should be retried once: %#v", prov.Provisioner)
+	}
+
+	artifact, err := build.Run(context.Background(), testUi())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(artifact) != 1 {
+		t.Fatalf("bad: %#v", artifact)
+	}
+}
@@ -0,0 +1,77 @@
+package packer
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/config"
+)
+
+// DeprecationReporter is implemented by the components reporting the
+// deprecated options set in the configuration they were last prepared with,
+// like the components started as plugins.
+type DeprecationReporter interface {
+	Deprecations() []config.Deprecation
+}
+
+// ComponentDeprecation is a deprecated option set in the configuration of a
+// component of a build.
+type ComponentDeprecation struct {
+	// Kind is builder, provisioner or post-processor.
+	Kind string
+	Type string
+	config.Deprecation
+}
+
+// Warning returns the warning of the deprecated option, naming its
+// component.
+func (d ComponentDeprecation) Warning() string {
+	return fmt.Sprintf("%s %q: %s", d.Kind, d.Type, d.Deprecation.Warning())
+}
+
+// deprecations returns the deprecated options reported by component c, or
+// nil when it doesn't report them.
+func deprecations(c interface{}) []config.Deprecation {
+	if r, ok := c.(DeprecationReporter); ok {
+		return r.Deprecations()
+	}
+	return nil
+}
+
+// ComponentDeprecations returns the deprecated options reported by the
+// component c of type typ.
+func ComponentDeprecations(kind, typ string, c interface{}) []ComponentDeprecation {
+	var res []ComponentDeprecation
+	for _, d := range deprecations(c) {
+		res = append(res, ComponentDeprecation{Kind: kind, Type: typ, Deprecation: d})
+	}
+	return res
+}
+
+// DeprecationWarnings returns the warnings of the deprecated options.
+func DeprecationWarnings(deprecations []ComponentDeprecation) []string {
+	var warnings []string
+	for _, d := range deprecations {
+		warnings = append(warnings, d.Warning())
+	}
+	return warnings
+}
+
+// Deprecations returns the deprecated options set in the configurations of
+// the components of the build. The build must be prepared.
+func (b *CoreBuild) Deprecations() []ComponentDeprecation {
+	res := ComponentDeprecations("builder", b.BuilderType, b.Builder)
+	provisioners := append([]CoreBuildProvisioner{}, b.Provisioners...)
+	provisioners = append(provisioners, b.Verifiers...)
+	if b.CleanupProvisioner.PType != "" {
+		provisioners = append(provisioners, b.CleanupProvisioner)
+	}
+	for _, p := range provisioners {
+		res = append(res, ComponentDeprecations("provisioner", p.PType, p.Provisioner)...)
+	}
+	for _, ppSeq := range b.PostProcessors {
+		for _, pp := range ppSeq {
+			res = append(res, ComponentDeprecations("post-processor", pp.PType, pp.PostProcessor)...)
+		}
+	}
+	return res
+}
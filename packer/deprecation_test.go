@@ -0,0 +1,49 @@
+package packer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/helper/config"
+)
+
+type deprecatedProvisioner struct {
+	MockProvisioner
+	deprecations []config.Deprecation
+}
+
+func (p *deprecatedProvisioner) Deprecations() []config.Deprecation { return p.deprecations }
+
+func TestBuildPrepare_deprecations(t *testing.T) {
+	build := testBuild()
+	p := &deprecatedProvisioner{
+		deprecations: []config.Deprecation{{Option: "old", Replacement: "new"}},
+	}
+	// Deprecations are reported through the wrappers of the provisioners.
+	build.Provisioners[0].Provisioner = &TimeoutProvisioner{
+		Provisioner: &RetriedProvisioner{MaxRetries: 1, Provisioner: p},
+		Timeout:     time.Minute,
+	}
+
+	warn, err := build.Prepare()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []string{
+		"provisioner \"mock-provisioner\": The option \"old\" is deprecated, " +
+			"use \"new\" instead. `packer fix` updates JSON templates to use \"new\".",
+	}
+	if !reflect.DeepEqual(warn, expected) {
+		t.Fatalf("bad: %#v", warn)
+	}
+
+	expectedDeprecations := []ComponentDeprecation{{
+		Kind:        "provisioner",
+		Type:        "mock-provisioner",
+		Deprecation: config.Deprecation{Option: "old", Replacement: "new"},
+	}}
+	if got := build.Deprecations(); !reflect.DeepEqual(got, expectedDeprecations) {
+		t.Fatalf("bad: %#v", got)
+	}
+}
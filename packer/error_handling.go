@@ -0,0 +1,61 @@
+package packer
+
+import "fmt"
+
+// These are the values on_failure can take in the error_handling setting of
+// a provisioner.
+const (
+	// OnFailureCleanup fails the build, which then reacts like its own
+	// on_failure setting tells. This is the default.
+	OnFailureCleanup = "cleanup"
+	// OnFailureAbort fails the build and skips the cleanup of every step.
+	OnFailureAbort = "abort"
+	// OnFailureAsk asks the user what to do.
+	OnFailureAsk = "ask"
+	// OnFailureContinue reports the error and continues the build.
+	OnFailureContinue = "continue"
+)
+
+// ProvisionerOnFailureValues are the valid on_failure values of a
+// provisioner.
+var ProvisionerOnFailureValues = []string{
+	OnFailureCleanup, OnFailureAbort, OnFailureAsk, OnFailureContinue,
+}
+
+// BuildOnFailureValues are the valid on_failure values of a build; they are
+// the values of the -on-error command line option.
+var BuildOnFailureValues = []string{
+	"cleanup", "abort", "ask", "run-cleanup-provisioner",
+}
+
+// ValidateOnFailure returns an error when value is not empty and not one of
+// valid.
+func ValidateOnFailure(value string, valid []string) error {
+	if value == "" {
+		return nil
+	}
+	for _, v := range valid {
+		if v == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("on_failure must be one of %q, got %q", valid, value)
+}
+
+// AbortError wraps the error of a step that asked to stop the build without
+// cleaning anything up.
+type AbortError struct {
+	Err error
+}
+
+func (e *AbortError) Error() string { return e.Err.Error() }
+
+// Aborted always returns true.
+func (e *AbortError) Aborted() bool { return true }
+
+// IsAbortError tells whether err asks to abort the build without cleanup.
+// It also works with errors that went through RPC.
+func IsAbortError(err error) bool {
+	a, ok := err.(interface{ Aborted() bool })
+	return ok && a.Aborted()
+}
@@ -0,0 +1,235 @@
+package packer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Types of the events of an EventStream.
+const (
+	EventBuildStarted  = "build-started"
+	EventBuildFinished = "build-finished"
+	EventStepStarted   = "step-started"
+	EventStepFinished  = "step-finished"
+	EventArtifact      = "artifact"
+	EventError         = "error"
+	EventUi            = "ui"
+
+	EventCommunicatorMetrics = "communicator-metrics"
+
+	// EventVerificationFailed is emitted instead of EventError when a build
+	// failed because its verifiers did.
+	EventVerificationFailed = "verification-failed"
+)
+
+// Event is an entry of an EventStream. Only the fields relevant to the type of
+// the event are set.
+type Event struct {
+	Time  time.Time `json:"time"`
+	Type  string    `json:"type"`
+	Build string    `json:"build,omitempty"`
+
+	// Step is the name of the step of step-started and step-finished events.
+	Step string `json:"step,omitempty"`
+	// Action is what the runner does after a step-finished event: "continue"
+	// or "halt".
+	Action string `json:"action,omitempty"`
+	// Duration is the time a step or a build took, in seconds.
+	Duration float64 `json:"duration,omitempty"`
+
+	// Error is the error message of error and build-finished events.
+	Error string `json:"error,omitempty"`
+
+	// Level is the kind of message of a ui event: "say", "message" or
+	// "error".
+	Level   string `json:"level,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Artifact is set on artifact events.
+	Artifact *ArtifactEvent `json:"artifact,omitempty"`
+
+	// Metrics is set on communicator-metrics events.
+	Metrics *CommunicatorMetrics `json:"metrics,omitempty"`
+}
+
+// ArtifactEvent describes an artifact produced by a build.
+type ArtifactEvent struct {
+	BuilderID string   `json:"builder_id"`
+	ID        string   `json:"id"`
+	String    string   `json:"string"`
+	Files     []string `json:"files"`
+}
+
+// NewArtifactEvent describes an artifact for an artifact event.
+func NewArtifactEvent(a Artifact) *ArtifactEvent {
+	return &ArtifactEvent{
+		BuilderID: a.BuilderId(),
+		ID:        a.Id(),
+		String:    a.String(),
+		Files:     a.Files(),
+	}
+}
+
+// CommunicatorMetrics are the metrics of the communicator of a build since it
+// connected.
+type CommunicatorMetrics struct {
+	BytesUploaded   int64 `json:"bytes_uploaded"`
+	BytesDownloaded int64 `json:"bytes_downloaded"`
+	// UploadThroughput and DownloadThroughput are the bytes transferred per
+	// second of transfer.
+	UploadThroughput   float64 `json:"upload_throughput"`
+	DownloadThroughput float64 `json:"download_throughput"`
+
+	// Commands is the number of started commands, and RunningCommands the
+	// ones not exited yet.
+	Commands        int64 `json:"commands"`
+	RunningCommands int64 `json:"running_commands"`
+	// CommandLatency is the average time, in seconds, the guest took to
+	// start a command.
+	CommandLatency float64 `json:"command_latency"`
+
+	// Retries is the number of failed connection attempts, including the
+	// reconnections once connected.
+	Retries int64 `json:"retries"`
+}
+
+// MachineArgs returns m as the arguments of a communicator-metrics
+// machine-readable message.
+func (m *CommunicatorMetrics) MachineArgs() []string {
+	return []string{
+		strconv.FormatInt(m.BytesUploaded, 10),
+		strconv.FormatInt(m.BytesDownloaded, 10),
+		strconv.FormatFloat(m.UploadThroughput, 'f', -1, 64),
+		strconv.FormatFloat(m.DownloadThroughput, 'f', -1, 64),
+		strconv.FormatInt(m.Commands, 10),
+		strconv.FormatInt(m.RunningCommands, 10),
+		strconv.FormatFloat(m.CommandLatency, 'f', -1, 64),
+		strconv.FormatInt(m.Retries, 10),
+	}
+}
+
+// parseCommunicatorMetrics parses the arguments of a communicator-metrics
+// machine-readable message.
+func parseCommunicatorMetrics(args []string) (*CommunicatorMetrics, error) {
+	if len(args) != 8 {
+		return nil, fmt.Errorf("expected 8 arguments, got %d", len(args))
+	}
+	var ints [5]int64
+	for i, j := range []int{0, 1, 4, 5, 7} {
+		n, err := strconv.ParseInt(args[j], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = n
+	}
+	var floats [3]float64
+	for i, j := range []int{2, 3, 6} {
+		f, err := strconv.ParseFloat(args[j], 64)
+		if err != nil {
+			return nil, err
+		}
+		floats[i] = f
+	}
+	return &CommunicatorMetrics{
+		BytesUploaded:      ints[0],
+		BytesDownloaded:    ints[1],
+		UploadThroughput:   floats[0],
+		DownloadThroughput: floats[1],
+		Commands:           ints[2],
+		RunningCommands:    ints[3],
+		CommandLatency:     floats[2],
+		Retries:            ints[4],
+	}, nil
+}
+
+// EventStream writes events as JSON lines. It is safe to be called from
+// multiple goroutines.
+type EventStream struct {
+	l sync.Mutex
+	w io.Writer
+}
+
+// NewEventStream returns an EventStream writing to w.
+func NewEventStream(w io.Writer) *EventStream {
+	return &EventStream{w: w}
+}
+
+// Emit writes e to the stream, setting its time when empty. Write errors are
+// only logged so that a broken consumer never fails a build. Emitting to a nil
+// stream does nothing.
+func (s *EventStream) Emit(e Event) {
+	if s == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("[ERR] Failed to encode event: %s", err)
+		return
+	}
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	if _, err := s.w.Write(append(b, '\n')); err != nil {
+		log.Printf("[ERR] Failed to write event: %s", err)
+	}
+}
+
+// EventStreamUi is a UI that wraps another UI implementation and turns the
+// step-started, step-finished and communicator-metrics machine-readable
+// messages of the builders into events of Stream.
+type EventStreamUi struct {
+	Ui     Ui
+	Stream *EventStream
+}
+
+var _ Ui = new(EventStreamUi)
+
+func (u *EventStreamUi) Ask(query string) (string, error) { return u.Ui.Ask(query) }
+func (u *EventStreamUi) Say(message string)               { u.Ui.Say(message) }
+func (u *EventStreamUi) Message(message string)           { u.Ui.Message(message) }
+func (u *EventStreamUi) Error(message string)             { u.Ui.Error(message) }
+
+func (u *EventStreamUi) TrackProgress(src string, currentSize, totalSize int64, stream io.ReadCloser) io.ReadCloser {
+	return u.Ui.TrackProgress(src, currentSize, totalSize, stream)
+}
+
+func (u *EventStreamUi) Machine(t string, args ...string) {
+	// The target of a machine-readable message is the name of the build; it
+	// gets prefixed by the TargetedUI of the build.
+	target, category := "", t
+	if i := strings.Index(t, ","); i > -1 {
+		target, category = t[:i], t[i+1:]
+	}
+
+	switch {
+	case category == EventStepStarted && len(args) >= 1:
+		u.Stream.Emit(Event{Type: EventStepStarted, Build: target, Step: args[0]})
+	case category == EventStepFinished && len(args) >= 3:
+		duration, _ := strconv.ParseFloat(args[2], 64)
+		u.Stream.Emit(Event{
+			Type:     EventStepFinished,
+			Build:    target,
+			Step:     args[0],
+			Action:   args[1],
+			Duration: duration,
+		})
+	case category == EventCommunicatorMetrics:
+		metrics, err := parseCommunicatorMetrics(args)
+		if err != nil {
+			log.Printf("[ERR] Invalid communicator metrics %q: %s", args, err)
+			break
+		}
+		u.Stream.Emit(Event{Type: EventCommunicatorMetrics, Build: target, Metrics: metrics})
+	}
+
+	u.Ui.Machine(t, args...)
+}
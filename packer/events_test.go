@@ -0,0 +1,76 @@
+package packer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func readEvents(t *testing.T, buf *bytes.Buffer) []Event {
+	var events []Event
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("bad event %q: %s", scanner.Text(), err)
+		}
+		e.Time = e.Time.UTC()
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestEventStreamUi(t *testing.T) {
+	buf := new(bytes.Buffer)
+	stream := NewEventStream(buf)
+	machine := new(bytes.Buffer)
+	ui := &TargetedUI{
+		Target: "amazon-ebs.example",
+		Ui: &EventStreamUi{
+			Ui:     &MachineReadableUi{Writer: machine},
+			Stream: stream,
+		},
+	}
+
+	ui.Machine(EventStepStarted, "StepKeyPair")
+	ui.Machine(EventStepFinished, "StepKeyPair", "halt", "1.500")
+	ui.Machine("artifact-count", "1")
+	metrics := &CommunicatorMetrics{
+		BytesUploaded:    2048,
+		UploadThroughput: 1024.5,
+		Commands:         3,
+		RunningCommands:  1,
+		CommandLatency:   0.25,
+		Retries:          2,
+	}
+	ui.Machine(EventCommunicatorMetrics, metrics.MachineArgs()...)
+
+	events := readEvents(t, buf)
+	for i := range events {
+		if events[i].Time.IsZero() {
+			t.Fatalf("event %d has no time", i)
+		}
+		events[i].Time = events[0].Time
+	}
+	expected := []Event{
+		{Time: events[0].Time, Type: EventStepStarted, Build: "amazon-ebs.example", Step: "StepKeyPair"},
+		{Time: events[0].Time, Type: EventStepFinished, Build: "amazon-ebs.example", Step: "StepKeyPair", Action: "halt", Duration: 1.5},
+		{Time: events[0].Time, Type: EventCommunicatorMetrics, Build: "amazon-ebs.example", Metrics: metrics},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("bad events:\n%#v\nexpected:\n%#v", events, expected)
+	}
+
+	// Machine-readable messages are still passed through.
+	if lines := bytes.Count(machine.Bytes(), []byte("\n")); lines != 4 {
+		t.Fatalf("expected 4 machine-readable lines, got %q", machine.String())
+	}
+}
+
+func TestEventStream_nil(t *testing.T) {
+	var stream *EventStream
+	// Should not panic
+	stream.Emit(Event{Type: EventBuildStarted})
+}
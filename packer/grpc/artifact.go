@@ -0,0 +1,138 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/grpc/pluginpb"
+	"google.golang.org/grpc"
+)
+
+// artifact is a packer.Artifact of another process.
+type artifact struct {
+	ref    *pluginpb.ObjectRef
+	client pluginpb.ArtifactClient
+}
+
+var _ packer.Artifact = new(artifact)
+
+func (a *artifact) objectRef() *pluginpb.ObjectRef { return a.ref }
+
+func (a *artifact) request() *pluginpb.ArtifactRequest {
+	return &pluginpb.ArtifactRequest{Id: a.ref.Id}
+}
+
+func (a *artifact) getString(method string, get func(context.Context, *pluginpb.ArtifactRequest, ...grpc.CallOption) (*pluginpb.ArtifactString, error)) string {
+	resp, err := get(context.Background(), a.request())
+	if err != nil {
+		log.Printf("Error in Artifact.%s plugin call: %s", method, rpcError(err))
+		return ""
+	}
+	return resp.Value
+}
+
+func (a *artifact) BuilderId() string { return a.getString("BuilderId", a.client.BuilderId) }
+func (a *artifact) Id() string        { return a.getString("Id", a.client.Id) }
+func (a *artifact) String() string    { return a.getString("String", a.client.String) }
+
+func (a *artifact) Files() []string {
+	resp, err := a.client.Files(context.Background(), a.request())
+	if err != nil {
+		log.Printf("Error in Artifact.Files plugin call: %s", rpcError(err))
+		return nil
+	}
+	return resp.Files
+}
+
+func (a *artifact) State(name string) interface{} {
+	req := a.request()
+	req.Name = name
+	resp, err := a.client.State(context.Background(), req)
+	if err != nil {
+		log.Printf("Error in Artifact.State plugin call: %s", rpcError(err))
+		return nil
+	}
+	var state interface{}
+	if err := decodeData(resp.State, &state); err != nil {
+		log.Printf("Error in Artifact.State plugin call: %s", err)
+		return nil
+	}
+	return state
+}
+
+func (a *artifact) Destroy() error {
+	_, err := a.client.Destroy(context.Background(), a.request())
+	return rpcError(err)
+}
+
+// artifactServer serves the packer.Artifact objects of a process.
+type artifactServer struct {
+	p *peer
+}
+
+func (s *artifactServer) artifact(id string) (packer.Artifact, error) {
+	o, err := s.p.object(id)
+	if err != nil {
+		return nil, err
+	}
+	a, ok := o.(packer.Artifact)
+	if !ok {
+		return nil, fmt.Errorf("plugin object %q is not an Artifact", id)
+	}
+	return a, nil
+}
+
+func (s *artifactServer) getString(req *pluginpb.ArtifactRequest, get func(a packer.Artifact) string) (*pluginpb.ArtifactString, error) {
+	a, err := s.artifact(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.ArtifactString{Value: get(a)}, nil
+}
+
+func (s *artifactServer) BuilderId(_ context.Context, req *pluginpb.ArtifactRequest) (*pluginpb.ArtifactString, error) {
+	return s.getString(req, packer.Artifact.BuilderId)
+}
+
+func (s *artifactServer) Id(_ context.Context, req *pluginpb.ArtifactRequest) (*pluginpb.ArtifactString, error) {
+	return s.getString(req, packer.Artifact.Id)
+}
+
+func (s *artifactServer) String(_ context.Context, req *pluginpb.ArtifactRequest) (*pluginpb.ArtifactString, error) {
+	return s.getString(req, packer.Artifact.String)
+}
+
+func (s *artifactServer) Files(_ context.Context, req *pluginpb.ArtifactRequest) (*pluginpb.ArtifactFiles, error) {
+	a, err := s.artifact(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.ArtifactFiles{Files: a.Files()}, nil
+}
+
+func (s *artifactServer) State(_ context.Context, req *pluginpb.ArtifactRequest) (*pluginpb.ArtifactState, error) {
+	a, err := s.artifact(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	state := a.State(req.Name)
+	if state == nil {
+		return &pluginpb.ArtifactState{}, nil
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.ArtifactState{State: b}, nil
+}
+
+func (s *artifactServer) Destroy(_ context.Context, req *pluginpb.ArtifactRequest) (*pluginpb.Empty, error) {
+	a, err := s.artifact(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.Empty{}, a.Destroy()
+}
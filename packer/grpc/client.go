@@ -0,0 +1,266 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/grpc/pluginpb"
+	"google.golang.org/grpc"
+)
+
+// Client uses the components served by a plugin over gRPC.
+type Client struct {
+	p       *peer
+	network string
+	address string
+}
+
+// Dial connects to the plugin listening on addr. The client serves the
+// objects it passes to the plugin, like the Ui of a build, on l.
+func Dial(addr net.Addr, l net.Listener) (*Client, error) {
+	p := newPeer()
+	if _, err := p.conn(addr.Network(), addr.String()); err != nil {
+		return nil, err
+	}
+	p.listen(l)
+	go p.server.Serve(l)
+	return &Client{p: p, network: addr.Network(), address: addr.String()}, nil
+}
+
+// Close closes the connections to the plugin and stops serving the objects
+// passed to it.
+func (c *Client) Close() error {
+	c.p.stop()
+	return nil
+}
+
+func (c *Client) conn() *grpc.ClientConn {
+	// The connection was opened by Dial.
+	conn, _ := c.p.conn(c.network, c.address)
+	return conn
+}
+
+// Builder returns the builder of the plugin.
+func (c *Client) Builder() packer.Builder {
+	return &builder{p: c.p, client: pluginpb.NewBuilderClient(c.conn())}
+}
+
+// Provisioner returns the provisioner of the plugin.
+func (c *Client) Provisioner() packer.Provisioner {
+	return &provisioner{p: c.p, client: pluginpb.NewProvisionerClient(c.conn())}
+}
+
+// PostProcessor returns the post-processor of the plugin.
+func (c *Client) PostProcessor() packer.PostProcessor {
+	return &postProcessor{p: c.p, client: pluginpb.NewPostProcessorClient(c.conn())}
+}
+
+func decodeConfigSpec(resp *pluginpb.ConfigSpecResponse, err error) hcldec.ObjectSpec {
+	// Like with the net/rpc protocol, ConfigSpec can't return an error.
+	if err != nil {
+		panic(fmt.Sprintf("ConfigSpec failed: %v", rpcError(err)))
+	}
+	spec := hcldec.ObjectSpec{}
+	if err := gob.NewDecoder(bytes.NewReader(resp.ConfigSpec)).Decode(&spec); err != nil {
+		panic(fmt.Sprintf("ConfigSpec failed: %v", err))
+	}
+	return spec
+}
+
+func prepareRequest(configs []interface{}) (*pluginpb.PrepareRequest, error) {
+	values, err := encodeConfigs(configs)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.PrepareRequest{Configs: values}, nil
+}
+
+type builder struct {
+	p                *peer
+	client           pluginpb.BuilderClient
+	deprecations     []config.Deprecation
+	plannedResources []string
+}
+
+var _ packer.Builder = new(builder)
+
+func (b *builder) ConfigSpec() hcldec.ObjectSpec {
+	return decodeConfigSpec(b.client.ConfigSpec(context.Background(), &pluginpb.Empty{}))
+}
+
+func (b *builder) Prepare(configs ...interface{}) ([]string, []string, error) {
+	req, err := prepareRequest(configs)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := b.client.Prepare(context.Background(), req)
+	if err != nil {
+		return nil, nil, rpcError(err)
+	}
+	b.deprecations = decodeDeprecations(resp.Deprecations)
+	b.plannedResources = decodePlannedResources(resp.Planned)
+	return resp.GeneratedVars, resp.Warnings, responseError(resp.Error)
+}
+
+func (b *builder) Deprecations() []config.Deprecation {
+	return b.deprecations
+}
+
+func (b *builder) PlannedResources() []string {
+	return b.plannedResources
+}
+
+func (b *builder) Run(ctx context.Context, ui packer.Ui, h packer.Hook) (packer.Artifact, error) {
+	e := b.p.exports()
+	defer e.release()
+	req := &pluginpb.BuilderRunRequest{Ui: e.ref(ui)}
+	if h != nil {
+		req.Hook = e.ref(h)
+	}
+
+	// The stream is not bound to ctx: the call is cancelled by closing it,
+	// so that its result can still be received.
+	streamCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := b.client.Run(streamCtx)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	resp := new(pluginpb.BuilderRunResponse)
+	if err := longCall(ctx, stream, req, resp); err != nil {
+		return nil, err
+	}
+	a, err := b.p.artifact(resp.Artifact)
+	if err != nil {
+		return nil, err
+	}
+	return a, responseError(resp.Error)
+}
+
+type provisioner struct {
+	p            *peer
+	client       pluginpb.ProvisionerClient
+	deprecations []config.Deprecation
+}
+
+var _ packer.Provisioner = new(provisioner)
+
+func (p *provisioner) ConfigSpec() hcldec.ObjectSpec {
+	return decodeConfigSpec(p.client.ConfigSpec(context.Background(), &pluginpb.Empty{}))
+}
+
+func (p *provisioner) Prepare(configs ...interface{}) error {
+	req, err := prepareRequest(configs)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Prepare(context.Background(), req)
+	if err != nil {
+		return rpcError(err)
+	}
+	p.deprecations = decodeDeprecations(resp.Deprecations)
+	return responseError(resp.Error)
+}
+
+func (p *provisioner) Deprecations() []config.Deprecation {
+	return p.deprecations
+}
+
+func (p *provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.Communicator, generatedData map[string]interface{}) error {
+	e := p.p.exports()
+	defer e.release()
+	data, err := encodeData(generatedData)
+	if err != nil {
+		return err
+	}
+	req := &pluginpb.ProvisionRequest{GeneratedData: data, Ui: e.ref(ui)}
+	if comm != nil {
+		req.Communicator = e.ref(comm)
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := p.client.Provision(streamCtx)
+	if err != nil {
+		return rpcError(err)
+	}
+	resp := new(pluginpb.ProvisionResponse)
+	if err := longCall(ctx, stream, req, resp); err != nil {
+		return err
+	}
+	return responseError(resp.Error)
+}
+
+type postProcessor struct {
+	p                *peer
+	client           pluginpb.PostProcessorClient
+	deprecations     []config.Deprecation
+	plannedResources []string
+}
+
+var _ packer.PostProcessor = new(postProcessor)
+
+func (p *postProcessor) ConfigSpec() hcldec.ObjectSpec {
+	return decodeConfigSpec(p.client.ConfigSpec(context.Background(), &pluginpb.Empty{}))
+}
+
+func (p *postProcessor) Configure(configs ...interface{}) error {
+	req, err := prepareRequest(configs)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Configure(context.Background(), req)
+	if err != nil {
+		return rpcError(err)
+	}
+	p.deprecations = decodeDeprecations(resp.Deprecations)
+	p.plannedResources = decodePlannedResources(resp.Planned)
+	return responseError(resp.Error)
+}
+
+func (p *postProcessor) Deprecations() []config.Deprecation {
+	return p.deprecations
+}
+
+func (p *postProcessor) PlannedResources() []string {
+	return p.plannedResources
+}
+
+func (p *postProcessor) PostProcess(ctx context.Context, ui packer.Ui, a packer.Artifact) (packer.Artifact, bool, bool, error) {
+	e := p.p.exports()
+	defer e.release()
+	req := &pluginpb.PostProcessRequest{Ui: e.ref(ui), Artifact: e.ref(a)}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := p.client.PostProcess(streamCtx)
+	if err != nil {
+		return nil, false, false, rpcError(err)
+	}
+	resp := new(pluginpb.PostProcessResponse)
+	if err := longCall(ctx, stream, req, resp); err != nil {
+		return nil, false, false, err
+	}
+	result := a
+	if !sameRef(resp.Artifact, req.Artifact) {
+		result, err = p.p.artifact(resp.Artifact)
+		if err != nil {
+			return nil, false, false, err
+		}
+	}
+	return result, resp.Keep, resp.ForceOverride, responseError(resp.Error)
+}
+
+// sameRef tells if a and b reference the same object.
+func sameRef(a, b *pluginpb.ObjectRef) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Network == b.Network && a.Address == b.Address && a.Id == b.Id
+}
@@ -0,0 +1,281 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/grpc/pluginpb"
+)
+
+// communicator is a packer.Communicator of another process.
+type communicator struct {
+	ref    *pluginpb.ObjectRef
+	client pluginpb.CommunicatorClient
+}
+
+var _ packer.Communicator = new(communicator)
+
+func (c *communicator) objectRef() *pluginpb.ObjectRef { return c.ref }
+
+// fileInfo is the os.FileInfo of an uploaded file.
+type fileInfo struct {
+	info *pluginpb.FileInfo
+}
+
+func (fi *fileInfo) Name() string       { return fi.info.Name }
+func (fi *fileInfo) Size() int64        { return fi.info.Size }
+func (fi *fileInfo) Mode() os.FileMode  { return os.FileMode(fi.info.Mode) }
+func (fi *fileInfo) ModTime() time.Time { return time.Unix(0, fi.info.ModTime) }
+func (fi *fileInfo) IsDir() bool        { return fi.info.IsDir }
+func (fi *fileInfo) Sys() interface{}   { return nil }
+
+// Start starts the command on the other side, where its outputs are
+// streamed back. It returns once the command started; its exit status is
+// set when it ends.
+func (c *communicator) Start(ctx context.Context, cmd *packer.RemoteCmd) error {
+	stream, err := c.client.Start(ctx)
+	if err != nil {
+		return rpcError(err)
+	}
+	err = stream.Send(&pluginpb.StartRequest{
+		Id:       c.ref.Id,
+		Command:  cmd.Command,
+		HasStdin: cmd.Stdin != nil,
+	})
+	if err != nil && err != io.EOF {
+		return rpcError(err)
+	}
+
+	// handle handles a response, and tells if the command ended.
+	handle := func(resp *pluginpb.StartResponse) bool {
+		if len(resp.Stdout) > 0 && cmd.Stdout != nil {
+			if _, err := cmd.Stdout.Write(resp.Stdout); err != nil {
+				log.Printf("[ERR] plugin communicator: %q: %s", cmd.Command, err)
+			}
+		}
+		if len(resp.Stderr) > 0 && cmd.Stderr != nil {
+			if _, err := cmd.Stderr.Write(resp.Stderr); err != nil {
+				log.Printf("[ERR] plugin communicator: %q: %s", cmd.Command, err)
+			}
+		}
+		if resp.Exited {
+			log.Printf("[INFO] plugin communicator: %q ended with: %d", cmd.Command, resp.ExitStatus)
+			cmd.SetExited(int(resp.ExitStatus))
+		}
+		return resp.Exited
+	}
+
+	// The first response tells if the command started: the call fails when
+	// it could not.
+	resp, err := stream.Recv()
+	if err != nil {
+		return rpcError(err)
+	}
+	if handle(resp) {
+		return nil
+	}
+
+	go func() {
+		if cmd.Stdin != nil {
+			err := sendChunks(cmd.Stdin, func(data []byte) error {
+				return stream.Send(&pluginpb.StartRequest{Stdin: data})
+			})
+			if err != nil && err != io.EOF {
+				log.Printf("[ERR] could not send the stdin of %q: %s", cmd.Command, err)
+			}
+		}
+		stream.CloseSend()
+	}()
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				log.Printf("[ERR] plugin communicator: %q: %s", cmd.Command, rpcError(err))
+				cmd.SetExited(packer.CmdDisconnect)
+				return
+			}
+			if handle(resp) {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *communicator) Upload(path string, r io.Reader, fi *os.FileInfo) error {
+	stream, err := c.client.Upload(context.Background())
+	if err != nil {
+		return rpcError(err)
+	}
+	req := &pluginpb.UploadRequest{Id: c.ref.Id, Path: path}
+	if fi != nil {
+		info := *fi
+		req.FileInfo = &pluginpb.FileInfo{
+			Name:    info.Name(),
+			Size:    info.Size(),
+			Mode:    uint32(info.Mode()),
+			ModTime: info.ModTime().UnixNano(),
+			IsDir:   info.IsDir(),
+		}
+	}
+	err = stream.Send(req)
+	if err == nil {
+		err = sendChunks(r, func(data []byte) error {
+			return stream.Send(&pluginpb.UploadRequest{Data: data})
+		})
+	}
+	// When the upload failed on the other side, the stream was closed and
+	// its error is the one of the call.
+	if _, closeErr := stream.CloseAndRecv(); closeErr != nil {
+		return rpcError(closeErr)
+	}
+	return err
+}
+
+func (c *communicator) UploadDir(dst string, src string, exclude []string) error {
+	_, err := c.client.UploadDir(context.Background(), &pluginpb.DirRequest{Id: c.ref.Id, Dst: dst, Src: src, Exclude: exclude})
+	return rpcError(err)
+}
+
+func (c *communicator) Download(path string, w io.Writer) error {
+	stream, err := c.client.Download(context.Background(), &pluginpb.DownloadRequest{Id: c.ref.Id, Path: path})
+	if err != nil {
+		return rpcError(err)
+	}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return rpcError(err)
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *communicator) DownloadDir(src string, dst string, exclude []string) error {
+	_, err := c.client.DownloadDir(context.Background(), &pluginpb.DirRequest{Id: c.ref.Id, Dst: dst, Src: src, Exclude: exclude})
+	return rpcError(err)
+}
+
+// communicatorServer serves the packer.Communicator objects of a process.
+type communicatorServer struct {
+	p *peer
+}
+
+func (s *communicatorServer) communicator(id string) (packer.Communicator, error) {
+	o, err := s.p.object(id)
+	if err != nil {
+		return nil, err
+	}
+	comm, ok := o.(packer.Communicator)
+	if !ok {
+		return nil, fmt.Errorf("plugin object %q is not a Communicator", id)
+	}
+	return comm, nil
+}
+
+func (s *communicatorServer) Start(stream pluginpb.Communicator_StartServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	comm, err := s.communicator(req.Id)
+	if err != nil {
+		return err
+	}
+
+	var sendMu sync.Mutex
+	send := func(resp *pluginpb.StartResponse) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return stream.Send(resp)
+	}
+	cmd := &packer.RemoteCmd{
+		Command: req.Command,
+		Stdout: &chunkWriter{send: func(data []byte) error {
+			return send(&pluginpb.StartResponse{Stdout: data})
+		}},
+		Stderr: &chunkWriter{send: func(data []byte) error {
+			return send(&pluginpb.StartResponse{Stderr: data})
+		}},
+	}
+	if req.HasStdin {
+		cmd.Stdin = &chunkReader{recv: func() ([]byte, error) {
+			req, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			return req.Stdin, nil
+		}}
+	}
+	if err := comm.Start(stream.Context(), cmd); err != nil {
+		return err
+	}
+	if err := send(&pluginpb.StartResponse{Started: true}); err != nil {
+		return err
+	}
+	return send(&pluginpb.StartResponse{Exited: true, ExitStatus: int32(cmd.Wait())})
+}
+
+func (s *communicatorServer) Upload(stream pluginpb.Communicator_UploadServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	comm, err := s.communicator(req.Id)
+	if err != nil {
+		return err
+	}
+	var fi *os.FileInfo
+	if req.FileInfo != nil {
+		info := os.FileInfo(&fileInfo{info: req.FileInfo})
+		fi = &info
+	}
+	r := &chunkReader{recv: func() ([]byte, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return req.Data, nil
+	}}
+	if err := comm.Upload(req.Path, r, fi); err != nil {
+		return err
+	}
+	return stream.SendAndClose(&pluginpb.Empty{})
+}
+
+func (s *communicatorServer) UploadDir(_ context.Context, req *pluginpb.DirRequest) (*pluginpb.Empty, error) {
+	comm, err := s.communicator(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.Empty{}, comm.UploadDir(req.Dst, req.Src, req.Exclude)
+}
+
+func (s *communicatorServer) Download(req *pluginpb.DownloadRequest, stream pluginpb.Communicator_DownloadServer) error {
+	comm, err := s.communicator(req.Id)
+	if err != nil {
+		return err
+	}
+	return comm.Download(req.Path, &chunkWriter{send: func(data []byte) error {
+		return stream.Send(&pluginpb.Chunk{Data: data})
+	}})
+}
+
+func (s *communicatorServer) DownloadDir(_ context.Context, req *pluginpb.DirRequest) (*pluginpb.Empty, error) {
+	comm, err := s.communicator(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.Empty{}, comm.DownloadDir(req.Src, req.Dst, req.Exclude)
+}
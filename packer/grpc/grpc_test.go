@@ -0,0 +1,335 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+)
+
+func testListener(t *testing.T) net.Listener {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func testClient(t *testing.T, register func(s *Server)) *Client {
+	t.Helper()
+	l := testListener(t)
+	server := NewServer()
+	register(server)
+	go server.Serve(l)
+	t.Cleanup(server.Stop)
+
+	client, err := Dial(l.Addr(), testListener(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// testUi records what is said.
+type testUi struct {
+	packer.NoopUi
+	said []string
+}
+
+func (u *testUi) Say(message string) { u.said = append(u.said, message) }
+
+// hookBuilder runs the provision hook with its communicator.
+type hookBuilder struct {
+	packer.MockBuilder
+	comm packer.Communicator
+}
+
+func (b *hookBuilder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
+	ui.Say("running")
+	if err := hook.Run(ctx, packer.HookProvision, ui, b.comm, map[string]interface{}{"ID": "i-1"}); err != nil {
+		return nil, err
+	}
+	return &packer.MockArtifact{IdValue: "i-1"}, nil
+}
+
+// commHook uses the communicator of the builder.
+type commHook struct {
+	name     string
+	data     interface{}
+	stdout   string
+	download string
+}
+
+func (h *commHook) Run(ctx context.Context, name string, ui packer.Ui, comm packer.Communicator, data interface{}) error {
+	h.name, h.data = name, data
+	ui.Say("provisioning")
+
+	var stdout bytes.Buffer
+	cmd := &packer.RemoteCmd{Command: "echo", Stdin: strings.NewReader("in"), Stdout: &stdout}
+	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+		return err
+	}
+	h.stdout = stdout.String()
+
+	big := strings.Repeat("x", 3*chunkSize+1)
+	if err := comm.Upload("/big", strings.NewReader(big), nil); err != nil {
+		return err
+	}
+	var download bytes.Buffer
+	if err := comm.Download("/file", &download); err != nil {
+		return err
+	}
+	h.download = download.String()
+	return nil
+}
+
+func TestBuilder_Run(t *testing.T) {
+	comm := &packer.MockCommunicator{StartStdout: "out", DownloadData: "downloaded"}
+	b := &hookBuilder{comm: comm}
+	client := testClient(t, func(s *Server) { s.RegisterBuilder(b) })
+
+	builder := client.Builder()
+	if _, _, err := builder.Prepare(map[string]interface{}{"foo": "bar"}); err != nil {
+		t.Fatalf("Prepare: %s", err)
+	}
+	if !b.PrepareCalled || !reflect.DeepEqual(b.PrepareConfig, []interface{}{map[string]interface{}{"foo": "bar"}}) {
+		t.Fatalf("Prepare: bad config %#v", b.PrepareConfig)
+	}
+
+	ui := &testUi{}
+	hook := &commHook{}
+	artifact, err := builder.Run(context.Background(), ui, hook)
+	if err != nil {
+		t.Fatalf("Run: %s", err)
+	}
+	if artifact.Id() != "i-1" {
+		t.Fatalf("bad artifact id %q", artifact.Id())
+	}
+	if !reflect.DeepEqual(ui.said, []string{"running", "provisioning"}) {
+		t.Fatalf("bad ui %q", ui.said)
+	}
+	if hook.name != packer.HookProvision || !reflect.DeepEqual(hook.data, map[string]interface{}{"ID": "i-1"}) {
+		t.Fatalf("bad hook call %q %#v", hook.name, hook.data)
+	}
+	if hook.stdout != "out" || comm.StartStdin != "in" {
+		t.Fatalf("bad command: stdout %q, stdin %q", hook.stdout, comm.StartStdin)
+	}
+	if comm.UploadPath != "/big" || len(comm.UploadData) != 3*chunkSize+1 {
+		t.Fatalf("bad upload of %d bytes to %q", len(comm.UploadData), comm.UploadPath)
+	}
+	if hook.download != "downloaded" {
+		t.Fatalf("bad download %q", hook.download)
+	}
+}
+
+// abortHook fails like a provisioner with on_failure "abort".
+type abortHook struct{}
+
+func (abortHook) Run(context.Context, string, packer.Ui, packer.Communicator, interface{}) error {
+	return &packer.AbortError{Err: errors.New("exit status 3")}
+}
+
+// recordingBuilder records the error of the hook it runs.
+type recordingBuilder struct {
+	packer.MockBuilder
+	hookErr error
+}
+
+func (b *recordingBuilder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
+	b.hookErr = hook.Run(ctx, packer.HookProvision, ui, nil, nil)
+	return nil, b.hookErr
+}
+
+func TestBuilder_Run_abort(t *testing.T) {
+	b := &recordingBuilder{}
+	client := testClient(t, func(s *Server) { s.RegisterBuilder(b) })
+
+	_, err := client.Builder().Run(context.Background(), &testUi{}, abortHook{})
+	if err == nil || err.Error() != "exit status 3" {
+		t.Fatalf("Run: expected the error of the hook, got %v", err)
+	}
+	if !packer.IsAbortError(b.hookErr) {
+		t.Fatalf("the builder should get an abort error, got %#v", b.hookErr)
+	}
+}
+
+// blockingBuilder runs until its context is cancelled.
+type blockingBuilder struct {
+	packer.MockBuilder
+	started chan struct{}
+}
+
+func (b *blockingBuilder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
+	close(b.started)
+	<-ctx.Done()
+	return nil, errors.New("cancelled: " + ctx.Err().Error())
+}
+
+func TestBuilder_Run_cancel(t *testing.T) {
+	b := &blockingBuilder{started: make(chan struct{})}
+	client := testClient(t, func(s *Server) { s.RegisterBuilder(b) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-b.started
+		cancel()
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := client.Builder().Run(ctx, &testUi{}, nil)
+		errCh <- err
+	}()
+	select {
+	case err := <-errCh:
+		if err == nil || err.Error() != "cancelled: context canceled" {
+			t.Fatalf("Run: expected the error of the builder, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the builder was not cancelled")
+	}
+}
+
+// compressPostProcessor returns an artifact named after its input.
+type compressPostProcessor struct {
+	packer.MockPostProcessor
+}
+
+func (p *compressPostProcessor) PostProcess(ctx context.Context, ui packer.Ui, a packer.Artifact) (packer.Artifact, bool, bool, error) {
+	if a.Id() == "keep" {
+		return a, true, false, nil
+	}
+	return &packer.MockArtifact{IdValue: a.Id() + ".gz"}, false, false, nil
+}
+
+func TestPostProcessor_PostProcess(t *testing.T) {
+	client := testClient(t, func(s *Server) { s.RegisterPostProcessor(&compressPostProcessor{}) })
+	pp := client.PostProcessor()
+
+	artifact, keep, _, err := pp.PostProcess(context.Background(), &testUi{}, &packer.MockArtifact{IdValue: "input"})
+	if err != nil {
+		t.Fatalf("PostProcess: %s", err)
+	}
+	if artifact.Id() != "input.gz" || keep {
+		t.Fatalf("bad result %q %v", artifact.Id(), keep)
+	}
+
+	input := &packer.MockArtifact{IdValue: "keep"}
+	artifact, keep, _, err = pp.PostProcess(context.Background(), &testUi{}, input)
+	if err != nil {
+		t.Fatalf("PostProcess: %s", err)
+	}
+	if artifact != input || !keep {
+		t.Fatalf("the input artifact must be returned, got %#v %v", artifact, keep)
+	}
+}
+
+func TestProvisioner_Provision(t *testing.T) {
+	p := &packer.MockProvisioner{}
+	client := testClient(t, func(s *Server) { s.RegisterProvisioner(p) })
+
+	comm := &packer.MockCommunicator{}
+	err := client.Provisioner().Provision(context.Background(), &testUi{}, comm, map[string]interface{}{"Host": "h"})
+	if err != nil {
+		t.Fatalf("Provision: %s", err)
+	}
+	if !p.ProvCalled || p.ProvRetried {
+		t.Fatal("Provision was not called")
+	}
+}
+
+type deprecatedConfig struct {
+	Old string `mapstructure:"old" deprecated:"new"`
+}
+
+func (*deprecatedConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return nil
+}
+
+type deprecatedProvisioner struct {
+	packer.MockProvisioner
+	config deprecatedConfig
+}
+
+type deprecatedPostProcessor struct {
+	packer.MockPostProcessor
+	config deprecatedConfig
+}
+
+func TestPrepare_deprecations(t *testing.T) {
+	client := testClient(t, func(s *Server) {
+		s.RegisterProvisioner(new(deprecatedProvisioner))
+		s.RegisterPostProcessor(new(deprecatedPostProcessor))
+	})
+	raw := map[string]interface{}{"old": "value"}
+	expected := []config.Deprecation{{Option: "old", Replacement: "new"}}
+
+	p := client.Provisioner()
+	if err := p.Prepare(raw); err != nil {
+		t.Fatalf("Prepare: %s", err)
+	}
+	if got := p.(packer.DeprecationReporter).Deprecations(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("provisioner: expected deprecations %#v, got %#v", expected, got)
+	}
+
+	pp := client.PostProcessor()
+	if err := pp.Configure(raw); err != nil {
+		t.Fatalf("Configure: %s", err)
+	}
+	if got := pp.(packer.DeprecationReporter).Deprecations(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("post-processor: expected deprecations %#v, got %#v", expected, got)
+	}
+}
+
+type plannedBuilder struct {
+	packer.MockBuilder
+	resources []string
+}
+
+func (b *plannedBuilder) PlannedResources() []string {
+	return b.resources
+}
+
+type plannedPostProcessor struct {
+	packer.MockPostProcessor
+	resources []string
+}
+
+func (p *plannedPostProcessor) PlannedResources() []string {
+	return p.resources
+}
+
+func TestPrepare_plannedResources(t *testing.T) {
+	for _, resources := range [][]string{{"temporary instance", "image"}, {}, nil} {
+		client := testClient(t, func(s *Server) {
+			s.RegisterBuilder(&plannedBuilder{resources: resources})
+			s.RegisterPostProcessor(&plannedPostProcessor{resources: resources})
+		})
+
+		b := client.Builder()
+		if _, _, err := b.Prepare(); err != nil {
+			t.Fatalf("Prepare: %s", err)
+		}
+		if got := packer.PlannedResources(b); !reflect.DeepEqual(got, resources) {
+			t.Fatalf("builder: expected planned resources %#v, got %#v", resources, got)
+		}
+
+		pp := client.PostProcessor()
+		if err := pp.Configure(); err != nil {
+			t.Fatalf("Configure: %s", err)
+		}
+		if got := packer.PlannedResources(pp); !reflect.DeepEqual(got, resources) {
+			t.Fatalf("post-processor: expected planned resources %#v, got %#v", resources, got)
+		}
+	}
+}
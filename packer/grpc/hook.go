@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/grpc/pluginpb"
+)
+
+// hook is a packer.Hook of another process.
+type hook struct {
+	p      *peer
+	ref    *pluginpb.ObjectRef
+	client pluginpb.HookClient
+}
+
+var _ packer.Hook = new(hook)
+
+func (h *hook) objectRef() *pluginpb.ObjectRef { return h.ref }
+
+func (h *hook) Run(ctx context.Context, name string, ui packer.Ui, comm packer.Communicator, data interface{}) error {
+	e := h.p.exports()
+	defer e.release()
+	b, err := encodeData(data)
+	if err != nil {
+		return err
+	}
+	req := &pluginpb.HookRunRequest{Id: h.ref.Id, Name: name, Data: b, Ui: e.ref(ui)}
+	if comm != nil {
+		req.Communicator = e.ref(comm)
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := h.client.Run(streamCtx)
+	if err != nil {
+		return rpcError(err)
+	}
+	resp := new(pluginpb.HookRunResponse)
+	if err := longCall(ctx, stream, req, resp); err != nil {
+		return err
+	}
+	err = responseError(resp.Error)
+	if resp.Abort {
+		err = &packer.AbortError{Err: err}
+	}
+	return err
+}
+
+// hookServer serves the packer.Hook objects of a process.
+type hookServer struct {
+	p *peer
+}
+
+func (s *hookServer) Run(stream pluginpb.Hook_RunServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	o, err := s.p.object(req.Id)
+	if err != nil {
+		return err
+	}
+	h, ok := o.(packer.Hook)
+	if !ok {
+		return fmt.Errorf("plugin object %q is not a Hook", req.Id)
+	}
+	ctx, cancel := callContext(stream)
+	defer cancel()
+
+	ui, err := s.p.ui(req.Ui)
+	if err != nil {
+		return err
+	}
+	comm, err := s.p.communicator(req.Communicator)
+	if err != nil {
+		return err
+	}
+	var data interface{}
+	if err := decodeData(req.Data, &data); err != nil {
+		return err
+	}
+	err = h.Run(ctx, req.Name, ui, comm, data)
+	return stream.SendAndClose(&pluginpb.HookRunResponse{
+		Error: errorString(err),
+		Abort: packer.IsAbortError(err),
+	})
+}
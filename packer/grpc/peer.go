@@ -0,0 +1,391 @@
+// Package grpc implements the gRPC protocol of Packer plugins, whose
+// services are defined in pluginpb/plugin.proto.
+//
+// A plugin serves its component with a Server; Packer uses it through a
+// Client. Both sides serve the objects they pass to the other, like the Ui
+// and the Hook of a build, or the Communicator of a builder, so that they
+// can be called while the call that got them runs. Cancelling the context of
+// a call cancels the context of the component in the plugin, and files and
+// command outputs are streamed in chunks instead of being sent in single
+// messages.
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/grpc/pluginpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// chunkSize is the maximum size of the data of a message, well below the
+// default 4MB limit of gRPC messages.
+const chunkSize = 64 * 1024
+
+// peer serves the objects a process passes to the other processes of a
+// build, and connects to the processes serving the objects it gets.
+type peer struct {
+	server *grpc.Server
+	// network and address are the ones of the listener of server.
+	network string
+	address string
+
+	mu      sync.Mutex
+	nextID  int
+	objects map[string]interface{}
+	conns   map[string]*grpc.ClientConn
+}
+
+func newPeer() *peer {
+	p := &peer{
+		server:  grpc.NewServer(),
+		objects: map[string]interface{}{},
+		conns:   map[string]*grpc.ClientConn{},
+	}
+	pluginpb.RegisterUiServer(p.server, &uiServer{p: p})
+	pluginpb.RegisterHookServer(p.server, &hookServer{p: p})
+	pluginpb.RegisterCommunicatorServer(p.server, &communicatorServer{p: p})
+	pluginpb.RegisterArtifactServer(p.server, &artifactServer{p: p})
+	return p
+}
+
+// listen sets the listener p serves on; it must be called before the first
+// call is served or made.
+func (p *peer) listen(l net.Listener) {
+	p.network, p.address = l.Addr().Network(), l.Addr().String()
+}
+
+func (p *peer) stop() {
+	p.server.Stop()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+}
+
+// export serves o until the returned function is called.
+func (p *peer) export(o interface{}) (*pluginpb.ObjectRef, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nextID++
+	id := strconv.Itoa(p.nextID)
+	p.objects[id] = o
+	return &pluginpb.ObjectRef{Network: p.network, Address: p.address, Id: id}, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.objects, id)
+	}
+}
+
+// ref returns the reference of o, serving it until the process exits when
+// it is not an object of another process.
+func (p *peer) ref(o interface{}) *pluginpb.ObjectRef {
+	if r, ok := o.(remote); ok {
+		return r.objectRef()
+	}
+	ref, _ := p.export(o)
+	return ref
+}
+
+// object returns the object of id served by p.
+func (p *peer) object(id string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	o, ok := p.objects[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown plugin object %q", id)
+	}
+	return o, nil
+}
+
+// local returns the object of ref when p serves it, so that an object
+// coming back to the process that serves it is used directly.
+func (p *peer) local(ref *pluginpb.ObjectRef) (interface{}, bool) {
+	if ref.Network != p.network || ref.Address != p.address {
+		return nil, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	o, ok := p.objects[ref.Id]
+	return o, ok
+}
+
+// conn returns the connection to the process listening on address.
+func (p *peer) conn(network, address string) (*grpc.ClientConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := network + ":" + address
+	if conn, ok := p.conns[key]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.Dial("passthrough:///"+address,
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, address)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[key] = conn
+	return conn, nil
+}
+
+func (p *peer) refConn(ref *pluginpb.ObjectRef) (*grpc.ClientConn, error) {
+	return p.conn(ref.Network, ref.Address)
+}
+
+// remote is implemented by the objects of other processes.
+type remote interface {
+	objectRef() *pluginpb.ObjectRef
+}
+
+// exports are the objects a call passes to the other side, served until
+// the call returns.
+type exports struct {
+	p        *peer
+	unexport []func()
+}
+
+func (p *peer) exports() *exports {
+	return &exports{p: p}
+}
+
+// ref returns the reference of o, or nil when o is nil.
+func (e *exports) ref(o interface{}) *pluginpb.ObjectRef {
+	if o == nil {
+		return nil
+	}
+	if r, ok := o.(remote); ok {
+		return r.objectRef()
+	}
+	ref, unexport := e.p.export(o)
+	e.unexport = append(e.unexport, unexport)
+	return ref
+}
+
+func (e *exports) release() {
+	for _, unexport := range e.unexport {
+		unexport()
+	}
+}
+
+// clientStream is the client side of a long running call.
+type clientStream interface {
+	SendMsg(m interface{}) error
+	RecvMsg(m interface{}) error
+	CloseSend() error
+}
+
+// longCall makes the long running call of stream with req, and receives
+// its response in resp. When ctx is done, the stream is closed to cancel
+// the call, and its response is still awaited.
+func longCall(ctx context.Context, stream clientStream, req, resp interface{}) error {
+	if err := stream.SendMsg(req); err != nil {
+		// The error of the call is received with its status.
+		if err != io.EOF {
+			return rpcError(err)
+		}
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.CloseSend()
+		case <-done:
+		}
+	}()
+	return rpcError(stream.RecvMsg(resp))
+}
+
+// callContext returns the context of a long running call, that is cancelled
+// when the caller closes the stream.
+func callContext(stream grpc.ServerStream) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(stream.Context())
+	go func() {
+		// The caller sends no other message: RecvMsg returns when it
+		// closes the stream, or when the call ends.
+		stream.RecvMsg(new(pluginpb.Empty))
+		cancel()
+	}()
+	return ctx, cancel
+}
+
+// rpcError returns the error of the other side without its gRPC status.
+func rpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unavailable {
+		return errors.New(s.Message())
+	}
+	return err
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func responseError(s string) error {
+	if s == "" {
+		return nil
+	}
+	return errors.New(s)
+}
+
+// encodeData JSON encodes the data of a hook or a provisioner.
+func encodeData(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func decodeData(b []byte, v interface{}) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, v)
+}
+
+// ui returns the packer.Ui of ref.
+func (p *peer) ui(ref *pluginpb.ObjectRef) (packer.Ui, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	if o, ok := p.local(ref); ok {
+		if u, ok := o.(packer.Ui); ok {
+			return u, nil
+		}
+	}
+	conn, err := p.refConn(ref)
+	if err != nil {
+		return nil, err
+	}
+	return &ui{ref: ref, client: pluginpb.NewUiClient(conn)}, nil
+}
+
+// hook returns the packer.Hook of ref.
+func (p *peer) hook(ref *pluginpb.ObjectRef) (packer.Hook, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	if o, ok := p.local(ref); ok {
+		if h, ok := o.(packer.Hook); ok {
+			return h, nil
+		}
+	}
+	conn, err := p.refConn(ref)
+	if err != nil {
+		return nil, err
+	}
+	return &hook{p: p, ref: ref, client: pluginpb.NewHookClient(conn)}, nil
+}
+
+// communicator returns the packer.Communicator of ref.
+func (p *peer) communicator(ref *pluginpb.ObjectRef) (packer.Communicator, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	if o, ok := p.local(ref); ok {
+		if comm, ok := o.(packer.Communicator); ok {
+			return comm, nil
+		}
+	}
+	conn, err := p.refConn(ref)
+	if err != nil {
+		return nil, err
+	}
+	return &communicator{ref: ref, client: pluginpb.NewCommunicatorClient(conn)}, nil
+}
+
+// artifact returns the packer.Artifact of ref.
+func (p *peer) artifact(ref *pluginpb.ObjectRef) (packer.Artifact, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	if o, ok := p.local(ref); ok {
+		if a, ok := o.(packer.Artifact); ok {
+			return a, nil
+		}
+	}
+	conn, err := p.refConn(ref)
+	if err != nil {
+		return nil, err
+	}
+	return &artifact{ref: ref, client: pluginpb.NewArtifactClient(conn)}, nil
+}
+
+// sendChunks sends the content of r in chunks.
+func sendChunks(r io.Reader, send func(data []byte) error) error {
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			// The message can be encoded after send returns.
+			if err := send(append([]byte(nil), buf[:n]...)); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// chunkWriter sends the data written to it in chunks.
+type chunkWriter struct {
+	send func(data []byte) error
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	for written := 0; written < len(p); {
+		n := len(p) - written
+		if n > chunkSize {
+			n = chunkSize
+		}
+		data := append([]byte(nil), p[written:written+n]...)
+		if err := w.send(data); err != nil {
+			return written, err
+		}
+		written += n
+	}
+	return len(p), nil
+}
+
+// chunkReader reads the chunks it receives, until recv returns io.EOF.
+type chunkReader struct {
+	recv func() ([]byte, error)
+	buf  []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		data, err := r.recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,3 @@
+package pluginpb
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. plugin.proto
@@ -0,0 +1,313 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/grpc/pluginpb"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+func init() {
+	// Config specs are sent gob encoded, like with the net/rpc protocol.
+	gob.Register(new(hcldec.AttrSpec))
+	gob.Register(new(hcldec.BlockSpec))
+	gob.Register(new(hcldec.BlockAttrsSpec))
+	gob.Register(new(hcldec.BlockListSpec))
+	gob.Register(new(hcldec.BlockObjectSpec))
+	gob.Register(new(cty.Value))
+}
+
+// Server serves the components of a plugin over gRPC.
+type Server struct {
+	p *peer
+}
+
+// NewServer returns a new Server.
+func NewServer() *Server {
+	return &Server{p: newPeer()}
+}
+
+// Serve serves the registered components on l until Stop is called.
+func (s *Server) Serve(l net.Listener) error {
+	s.p.listen(l)
+	return s.p.server.Serve(l)
+}
+
+// Stop stops the server, cancelling the running calls.
+func (s *Server) Stop() {
+	s.p.stop()
+}
+
+func configSpec(c packer.HCL2Speccer) (*pluginpb.ConfigSpecResponse, error) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(c.ConfigSpec()); err != nil {
+		return nil, err
+	}
+	return &pluginpb.ConfigSpecResponse{ConfigSpec: b.Bytes()}, nil
+}
+
+// encodeConfigs encodes the values passed to Prepare or Configure: cty
+// values are JSON encoded with their type, like with the net/rpc protocol,
+// and maps of strings keep their type.
+func encodeConfigs(configs []interface{}) ([]*pluginpb.ConfigValue, error) {
+	values := make([]*pluginpb.ConfigValue, len(configs))
+	for i, config := range configs {
+		switch v := config.(type) {
+		case cty.Value:
+			b, err := ctyjson.Marshal(v, cty.DynamicPseudoType)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = &pluginpb.ConfigValue{Value: &pluginpb.ConfigValue_Cty{Cty: b}}
+			continue
+		case map[string]string:
+			// The placeholder data is only recognized with its type, see
+			// config.DetectContextData.
+			values[i] = &pluginpb.ConfigValue{Value: &pluginpb.ConfigValue_Strings{Strings: &pluginpb.Strings{Values: v}}}
+			continue
+		}
+		b, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = &pluginpb.ConfigValue{Value: &pluginpb.ConfigValue_Json{Json: b}}
+	}
+	return values, nil
+}
+
+// encodeDeprecations returns the deprecated options of component set in
+// configs.
+func encodeDeprecations(component interface{}, configs []interface{}) []*pluginpb.Deprecation {
+	var deprecations []*pluginpb.Deprecation
+	for _, d := range config.ComponentDeprecations(component, configs...) {
+		deprecations = append(deprecations, &pluginpb.Deprecation{Option: d.Option, Replacement: d.Replacement})
+	}
+	return deprecations
+}
+
+func decodeDeprecations(deprecations []*pluginpb.Deprecation) []config.Deprecation {
+	var options []config.Deprecation
+	for _, d := range deprecations {
+		options = append(options, config.Deprecation{Option: d.Option, Replacement: d.Replacement})
+	}
+	return options
+}
+
+// encodePlannedResources returns the resources component plans to create,
+// or nil when they are unknown.
+func encodePlannedResources(component interface{}) *pluginpb.PlannedResources {
+	r := packer.PlannedResources(component)
+	if r == nil {
+		return nil
+	}
+	return &pluginpb.PlannedResources{Resources: r}
+}
+
+func decodePlannedResources(planned *pluginpb.PlannedResources) []string {
+	if planned == nil {
+		return nil
+	}
+	if planned.Resources == nil {
+		return []string{}
+	}
+	return planned.Resources
+}
+
+func decodeConfigs(values []*pluginpb.ConfigValue) ([]interface{}, error) {
+	configs := make([]interface{}, len(values))
+	for i, v := range values {
+		switch v := v.Value.(type) {
+		case *pluginpb.ConfigValue_Cty:
+			val, err := ctyjson.Unmarshal(v.Cty, cty.DynamicPseudoType)
+			if err != nil {
+				return nil, err
+			}
+			configs[i] = val
+		case *pluginpb.ConfigValue_Strings:
+			values := v.Strings.Values
+			if values == nil {
+				values = map[string]string{}
+			}
+			configs[i] = values
+		case *pluginpb.ConfigValue_Json:
+			if err := json.Unmarshal(v.Json, &configs[i]); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("config %d has no value", i)
+		}
+	}
+	return configs, nil
+}
+
+// RegisterBuilder serves b.
+func (s *Server) RegisterBuilder(b packer.Builder) {
+	pluginpb.RegisterBuilderServer(s.p.server, &builderServer{p: s.p, builder: b})
+}
+
+type builderServer struct {
+	p       *peer
+	builder packer.Builder
+}
+
+func (s *builderServer) ConfigSpec(context.Context, *pluginpb.Empty) (*pluginpb.ConfigSpecResponse, error) {
+	return configSpec(s.builder)
+}
+
+func (s *builderServer) Prepare(_ context.Context, req *pluginpb.PrepareRequest) (*pluginpb.PrepareResponse, error) {
+	configs, err := decodeConfigs(req.Configs)
+	if err != nil {
+		return nil, err
+	}
+	generated, warnings, err := s.builder.Prepare(configs...)
+	return &pluginpb.PrepareResponse{
+		GeneratedVars: generated,
+		Warnings:      warnings,
+		Error:         errorString(err),
+		Deprecations:  encodeDeprecations(s.builder, configs),
+		Planned:       encodePlannedResources(s.builder),
+	}, nil
+}
+
+func (s *builderServer) Run(stream pluginpb.Builder_RunServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := callContext(stream)
+	defer cancel()
+
+	ui, err := s.p.ui(req.Ui)
+	if err != nil {
+		return err
+	}
+	h, err := s.p.hook(req.Hook)
+	if err != nil {
+		return err
+	}
+	a, err := s.builder.Run(ctx, ui, h)
+	resp := &pluginpb.BuilderRunResponse{Error: errorString(err)}
+	if a != nil {
+		resp.Artifact = s.p.ref(a)
+	}
+	return stream.SendAndClose(resp)
+}
+
+// RegisterProvisioner serves p.
+func (s *Server) RegisterProvisioner(p packer.Provisioner) {
+	pluginpb.RegisterProvisionerServer(s.p.server, &provisionerServer{p: s.p, provisioner: p})
+}
+
+type provisionerServer struct {
+	p           *peer
+	provisioner packer.Provisioner
+}
+
+func (s *provisionerServer) ConfigSpec(context.Context, *pluginpb.Empty) (*pluginpb.ConfigSpecResponse, error) {
+	return configSpec(s.provisioner)
+}
+
+func (s *provisionerServer) Prepare(_ context.Context, req *pluginpb.PrepareRequest) (*pluginpb.PrepareResponse, error) {
+	configs, err := decodeConfigs(req.Configs)
+	if err != nil {
+		return nil, err
+	}
+	err = s.provisioner.Prepare(configs...)
+	return &pluginpb.PrepareResponse{
+		Error:        errorString(err),
+		Deprecations: encodeDeprecations(s.provisioner, configs),
+	}, nil
+}
+
+func (s *provisionerServer) Provision(stream pluginpb.Provisioner_ProvisionServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := callContext(stream)
+	defer cancel()
+
+	ui, err := s.p.ui(req.Ui)
+	if err != nil {
+		return err
+	}
+	comm, err := s.p.communicator(req.Communicator)
+	if err != nil {
+		return err
+	}
+	var generatedData map[string]interface{}
+	if err := decodeData(req.GeneratedData, &generatedData); err != nil {
+		return err
+	}
+	err = s.provisioner.Provision(ctx, ui, comm, generatedData)
+	return stream.SendAndClose(&pluginpb.ProvisionResponse{Error: errorString(err)})
+}
+
+// RegisterPostProcessor serves p.
+//
+// The artifact a post-processor gets can only be used while PostProcess
+// runs: the artifact it returns must not call it afterwards, unless it is
+// the input artifact itself.
+func (s *Server) RegisterPostProcessor(p packer.PostProcessor) {
+	pluginpb.RegisterPostProcessorServer(s.p.server, &postProcessorServer{p: s.p, postProcessor: p})
+}
+
+type postProcessorServer struct {
+	p             *peer
+	postProcessor packer.PostProcessor
+}
+
+func (s *postProcessorServer) ConfigSpec(context.Context, *pluginpb.Empty) (*pluginpb.ConfigSpecResponse, error) {
+	return configSpec(s.postProcessor)
+}
+
+func (s *postProcessorServer) Configure(_ context.Context, req *pluginpb.PrepareRequest) (*pluginpb.PrepareResponse, error) {
+	configs, err := decodeConfigs(req.Configs)
+	if err != nil {
+		return nil, err
+	}
+	err = s.postProcessor.Configure(configs...)
+	return &pluginpb.PrepareResponse{
+		Error:        errorString(err),
+		Deprecations: encodeDeprecations(s.postProcessor, configs),
+		Planned:      encodePlannedResources(s.postProcessor),
+	}, nil
+}
+
+func (s *postProcessorServer) PostProcess(stream pluginpb.PostProcessor_PostProcessServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := callContext(stream)
+	defer cancel()
+
+	ui, err := s.p.ui(req.Ui)
+	if err != nil {
+		return err
+	}
+	input, err := s.p.artifact(req.Artifact)
+	if err != nil {
+		return err
+	}
+	a, keep, forceOverride, err := s.postProcessor.PostProcess(ctx, ui, input)
+	resp := &pluginpb.PostProcessResponse{
+		Keep:          keep,
+		ForceOverride: forceOverride,
+		Error:         errorString(err),
+	}
+	if a != nil {
+		// The input artifact keeps its reference.
+		resp.Artifact = s.p.ref(a)
+	}
+	return stream.SendAndClose(resp)
+}
@@ -0,0 +1,193 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/grpc/pluginpb"
+	"google.golang.org/grpc"
+)
+
+// ui is a packer.Ui of another process.
+type ui struct {
+	ref    *pluginpb.ObjectRef
+	client pluginpb.UiClient
+}
+
+var _ packer.Ui = new(ui)
+
+func (u *ui) objectRef() *pluginpb.ObjectRef { return u.ref }
+
+func (u *ui) say(method string, message string, call func(context.Context, *pluginpb.UiRequest, ...grpc.CallOption) (*pluginpb.Empty, error)) {
+	if _, err := call(context.Background(), &pluginpb.UiRequest{Id: u.ref.Id, Message: message}); err != nil {
+		log.Printf("Error in Ui.%s plugin call: %s", method, rpcError(err))
+	}
+}
+
+func (u *ui) Ask(query string) (string, error) {
+	resp, err := u.client.Ask(context.Background(), &pluginpb.UiRequest{Id: u.ref.Id, Message: query})
+	if err != nil {
+		return "", rpcError(err)
+	}
+	return resp.Answer, nil
+}
+
+func (u *ui) Say(message string)     { u.say("Say", message, u.client.Say) }
+func (u *ui) Message(message string) { u.say("Message", message, u.client.Message) }
+func (u *ui) Error(message string)   { u.say("Error", message, u.client.Error) }
+
+func (u *ui) Machine(t string, args ...string) {
+	_, err := u.client.Machine(context.Background(), &pluginpb.MachineRequest{Id: u.ref.Id, Category: t, Args: args})
+	if err != nil {
+		log.Printf("Error in Ui.Machine plugin call: %s", rpcError(err))
+	}
+}
+
+// TrackProgress sends the number of bytes read from stream to the other
+// side, which tracks them with its Ui.
+func (u *ui) TrackProgress(src string, currentSize, totalSize int64, stream io.ReadCloser) io.ReadCloser {
+	progress, err := u.client.TrackProgress(context.Background())
+	if err == nil {
+		err = progress.Send(&pluginpb.TrackProgressRequest{
+			Id:          u.ref.Id,
+			Src:         src,
+			CurrentSize: currentSize,
+			TotalSize:   totalSize,
+		})
+	}
+	if err != nil {
+		log.Printf("Error in Ui.TrackProgress plugin call: %s", rpcError(err))
+		return stream
+	}
+	return &progressTracker{progress: progress, stream: stream}
+}
+
+type progressTracker struct {
+	progress pluginpb.Ui_TrackProgressClient
+	stream   io.ReadCloser
+}
+
+func (t *progressTracker) Read(b []byte) (int, error) {
+	n, err := t.stream.Read(b)
+	if n > 0 {
+		if err := t.progress.Send(&pluginpb.TrackProgressRequest{Read: int64(n)}); err != nil {
+			log.Printf("Error in Ui.TrackProgress plugin call: %s", rpcError(err))
+		}
+	}
+	return n, err
+}
+
+func (t *progressTracker) Close() error {
+	if _, err := t.progress.CloseAndRecv(); err != nil {
+		log.Printf("Error in Ui.TrackProgress plugin call: %s", rpcError(err))
+	}
+	return t.stream.Close()
+}
+
+// uiServer serves the packer.Ui objects of a process.
+type uiServer struct {
+	p *peer
+}
+
+func (s *uiServer) ui(id string) (packer.Ui, error) {
+	o, err := s.p.object(id)
+	if err != nil {
+		return nil, err
+	}
+	u, ok := o.(packer.Ui)
+	if !ok {
+		return nil, fmt.Errorf("plugin object %q is not a Ui", id)
+	}
+	return u, nil
+}
+
+func (s *uiServer) say(req *pluginpb.UiRequest, say func(u packer.Ui, message string)) (*pluginpb.Empty, error) {
+	u, err := s.ui(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	say(u, req.Message)
+	return &pluginpb.Empty{}, nil
+}
+
+func (s *uiServer) Ask(_ context.Context, req *pluginpb.UiRequest) (*pluginpb.AskResponse, error) {
+	u, err := s.ui(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	answer, err := u.Ask(req.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginpb.AskResponse{Answer: answer}, nil
+}
+
+func (s *uiServer) Say(_ context.Context, req *pluginpb.UiRequest) (*pluginpb.Empty, error) {
+	return s.say(req, packer.Ui.Say)
+}
+
+func (s *uiServer) Message(_ context.Context, req *pluginpb.UiRequest) (*pluginpb.Empty, error) {
+	return s.say(req, packer.Ui.Message)
+}
+
+func (s *uiServer) Error(_ context.Context, req *pluginpb.UiRequest) (*pluginpb.Empty, error) {
+	return s.say(req, packer.Ui.Error)
+}
+
+func (s *uiServer) Machine(_ context.Context, req *pluginpb.MachineRequest) (*pluginpb.Empty, error) {
+	u, err := s.ui(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	u.Machine(req.Category, req.Args...)
+	return &pluginpb.Empty{}, nil
+}
+
+func (s *uiServer) TrackProgress(stream pluginpb.Ui_TrackProgressServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	u, err := s.ui(req.Id)
+	if err != nil {
+		return err
+	}
+	tracked := u.TrackProgress(req.Src, req.CurrentSize, req.TotalSize, &progressReader{stream: stream})
+	io.Copy(ioutil.Discard, tracked)
+	if err := tracked.Close(); err != nil {
+		return err
+	}
+	return stream.SendAndClose(&pluginpb.Empty{})
+}
+
+// progressReader reads as many bytes as the other side read from the
+// tracked stream, so that the local progress tracker counts them.
+type progressReader struct {
+	stream pluginpb.Ui_TrackProgressServer
+	left   int64
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	for r.left == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.left = req.Read
+	}
+	n := len(b)
+	if int64(n) > r.left {
+		n = int(r.left)
+	}
+	for i := range b[:n] {
+		b[i] = 0
+	}
+	r.left -= int64(n)
+	return n, nil
+}
+
+func (r *progressReader) Close() error { return nil }
@@ -0,0 +1,174 @@
+package packer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Journal is the crash-safe record of a running build: the steps it
+// completed and what they saved to resume it, like the ids of the resources
+// they created. It is written by the packer process running the build and by
+// its builder as the steps complete, and removed once the build ran, so that
+// `packer resume` can resume a build whose packer process died.
+type Journal struct {
+	// Path is the file the journal is stored in.
+	Path string `json:"-"`
+
+	BuildID   string `json:"build_id"`
+	BuildName string `json:"build_name"`
+	// Args are the arguments of the build command, and Dir the directory it
+	// ran in, to run it again when resuming.
+	Args []string `json:"args"`
+	Dir  string   `json:"dir"`
+	// PID is the process running the build.
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+
+	// Steps are the steps the builder completed, in order.
+	Steps []string `json:"steps,omitempty"`
+	// Data is what the steps saved to resume the build.
+	Data map[string]string `json:"data,omitempty"`
+
+	l sync.Mutex
+}
+
+// JournalDir returns the directory journals are stored in.
+func JournalDir() (string, error) {
+	if dir := os.Getenv("PACKER_JOURNAL_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "journal"), nil
+}
+
+// JournalPath returns the file the journal of the build with the given id is
+// stored in.
+func JournalPath(buildID string) (string, error) {
+	dir, err := JournalDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, buildID+".json"), nil
+}
+
+// LoadJournal reads the journal stored in path.
+func LoadJournal(path string) (*Journal, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{Path: path}
+	if err := json.Unmarshal(b, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Journals returns the journals of dir, oldest first.
+func Journals(dir string) ([]*Journal, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var journals []*Journal
+	for _, file := range files {
+		j, err := LoadJournal(file)
+		if err != nil {
+			log.Printf("[WARN] journal: skipping unreadable journal %s: %s", file, err)
+			continue
+		}
+		journals = append(journals, j)
+	}
+
+	sort.Slice(journals, func(i, j int) bool {
+		return journals[i].Started.Before(journals[j].Started)
+	})
+	return journals, nil
+}
+
+// Completed tells whether the step was completed.
+func (j *Journal) Completed(step string) bool {
+	j.l.Lock()
+	defer j.l.Unlock()
+	for _, s := range j.Steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete records that the step was completed.
+func (j *Journal) Complete(step string) error {
+	j.l.Lock()
+	defer j.l.Unlock()
+	for _, s := range j.Steps {
+		if s == step {
+			return nil
+		}
+	}
+	j.Steps = append(j.Steps, step)
+	return j.save()
+}
+
+// Get returns the value a step saved under key, or "".
+func (j *Journal) Get(key string) string {
+	j.l.Lock()
+	defer j.l.Unlock()
+	return j.Data[key]
+}
+
+// Set saves the value under key, for the step to resume from it.
+func (j *Journal) Set(key, value string) error {
+	j.l.Lock()
+	defer j.l.Unlock()
+	if j.Data == nil {
+		j.Data = map[string]string{}
+	}
+	j.Data[key] = value
+	return j.save()
+}
+
+// Save writes the journal to its file.
+func (j *Journal) Save() error {
+	j.l.Lock()
+	defer j.l.Unlock()
+	return j.save()
+}
+
+func (j *Journal) save() error {
+	if err := os.MkdirAll(filepath.Dir(j.Path), 0700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	// The journal may hold temporary credentials, like private keys, so it
+	// is only readable by its owner. Write then rename so that a killed
+	// process never leaves a partial journal behind.
+	tmp := j.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.Path)
+}
+
+// Delete removes the journal file.
+func (j *Journal) Delete() error {
+	err := os.Remove(j.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package packer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("PACKER_JOURNAL_DIR", dir)
+	defer os.Unsetenv("PACKER_JOURNAL_DIR")
+
+	path, err := JournalPath("build-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "build-1.json") {
+		t.Fatalf("bad path: %s", path)
+	}
+
+	j := &Journal{
+		Path:      path,
+		BuildID:   "build-1",
+		BuildName: "amazon-ebs",
+		Args:      []string{"-force", "template.json"},
+		PID:       os.Getpid(),
+		Started:   time.Now(),
+	}
+	if err := j.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Complete("0-StepKeyPair"); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Set("key", "value"); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 && os.PathSeparator == '/' {
+		t.Fatalf("the journal should only be readable by its owner: %s", mode)
+	}
+
+	loaded, err := LoadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Completed("0-StepKeyPair") || loaded.Completed("1-StepRunSourceInstance") {
+		t.Fatalf("bad steps: %#v", loaded.Steps)
+	}
+	if loaded.Get("key") != "value" || loaded.BuildName != "amazon-ebs" || len(loaded.Args) != 2 {
+		t.Fatalf("bad journal: %#v", loaded)
+	}
+
+	journals, err := Journals(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journals) != 1 || journals[0].BuildID != "build-1" {
+		t.Fatalf("bad journals: %#v", journals)
+	}
+
+	if err := loaded.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Delete(); err != nil {
+		t.Fatalf("deleting a deleted journal should not fail: %s", err)
+	}
+}
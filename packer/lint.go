@@ -0,0 +1,96 @@
+package packer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// The kinds of the blocks that are linted.
+const (
+	LintBuilder       = "builder"
+	LintProvisioner   = "provisioner"
+	LintPostProcessor = "post-processor"
+)
+
+// LintBlock is a builder, a provisioner or a post-processor of a
+// configuration, as seen by the lint rules, before it is prepared.
+type LintBlock struct {
+	// Kind is LintBuilder, LintProvisioner or LintPostProcessor.
+	Kind string
+	Type string
+	Name string
+
+	// Options are the options set in the block, by name. The value of an
+	// option is nil when it can't be known before the build runs, like a
+	// value computed from the outputs of another build.
+	Options map[string]interface{}
+
+	// Range is where the block is defined. Only its filename is set for
+	// JSON templates.
+	Range hcl.Range
+	// OptionRanges are where the options are set, when known.
+	OptionRanges map[string]hcl.Range
+}
+
+func (b *LintBlock) String() string {
+	if b.Name == "" || b.Name == b.Type {
+		return fmt.Sprintf("%s '%s'", b.Kind, b.Type)
+	}
+	return fmt.Sprintf("%s '%s' (%s)", b.Kind, b.Name, b.Type)
+}
+
+// OptionRange returns where the option called name is set, or where the
+// block is defined when that isn't known.
+func (b *LintBlock) OptionRange(name string) hcl.Range {
+	if r, ok := b.OptionRanges[name]; ok {
+		return r
+	}
+	return b.Range
+}
+
+// LintConfig returns the builders, provisioners and post-processors of the
+// template.
+func (c *Core) LintConfig() ([]*LintBlock, hcl.Diagnostics) {
+	tpl := c.Template
+	file := hcl.Range{Filename: tpl.Path}
+
+	names := make([]string, 0, len(tpl.Builders))
+	for n := range tpl.Builders {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var blocks []*LintBlock
+	for _, n := range names {
+		b := tpl.Builders[n]
+		blocks = append(blocks, &LintBlock{
+			Kind:    LintBuilder,
+			Type:    b.Type,
+			Name:    b.Name,
+			Options: b.Config,
+			Range:   file,
+		})
+	}
+	for _, p := range tpl.Provisioners {
+		blocks = append(blocks, &LintBlock{
+			Kind:    LintProvisioner,
+			Type:    p.Type,
+			Options: p.Config,
+			Range:   file,
+		})
+	}
+	for _, chain := range tpl.PostProcessors {
+		for _, pp := range chain {
+			blocks = append(blocks, &LintBlock{
+				Kind:    LintPostProcessor,
+				Type:    pp.Type,
+				Name:    pp.Name,
+				Options: pp.Config,
+				Range:   file,
+			})
+		}
+	}
+	return blocks, nil
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// File is a log file rotated once it is bigger than its maximum size: path is
+// renamed to path.1, path.1 to path.2, and so on, and a new path is created.
+type File struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenFile creates the log file of the configuration, truncating it.
+func OpenFile(config *Config) (*File, error) {
+	f := &File{
+		path:     config.Path,
+		maxSize:  config.MaxSize,
+		maxFiles: config.MaxFiles,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) open() error {
+	file, err := os.Create(f.path)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	f.size = 0
+	return nil
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate renames the log files and creates a new one.
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	if f.maxFiles > 0 {
+		for i := f.maxFiles - 1; i > 0; i-- {
+			old := fmt.Sprintf("%s.%d", f.path, i)
+			if _, err := os.Stat(old); err != nil {
+				continue
+			}
+			if err := os.Rename(old, fmt.Sprintf("%s.%d", f.path, i+1)); err != nil {
+				return err
+			}
+		}
+		if err := os.Rename(f.path, f.path+".1"); err != nil {
+			return err
+		}
+	}
+	return f.open()
+}
+
+// Close closes the log file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFile_rotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-log")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "packer.log")
+	f, err := OpenFile(&Config{Path: path, MaxSize: 10, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for p, content := range expected {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if string(b) != content {
+			t.Fatalf("%s: bad: %q", p, b)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("too many files kept: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Flags returns the flags of the standard logger of a process started by
+// Packer: flags, with the callers of the log calls when Packer filters the
+// logs.
+func Flags(flags int) int {
+	if os.Getenv(EnvLogCallers) != "" {
+		flags |= log.Llongfile
+	}
+	return flags
+}
+
+// timeLayout is the layout of the times logged with log.LstdFlags.
+const timeLayout = "2006/01/02 15:04:05"
+
+var (
+	timeRe   = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?) `)
+	callerRe = regexp.MustCompile(`^(\S+\.go:\d+): `)
+	pluginRe = regexp.MustCompile(`^(\S+) plugin: `)
+	levelRe  = regexp.MustCompile(`^\[([A-Za-z]+)\] ?`)
+)
+
+// entry is a parsed log line.
+type entry struct {
+	Time      time.Time
+	Subsystem string
+	Level     Level
+	// Caller is the file and line of the log call, when logged.
+	Caller string
+	// Plugin is the name of the plugin the line was logged by.
+	Plugin string
+	// Text is the line without its time and callers, as it is logged
+	// without filtering.
+	Text string
+	// Message is Text without the plugin name and the level.
+	Message string
+}
+
+// parse parses a log line. Lines without a time, like the lines following the
+// first line of a message, aren't log lines of their own.
+func parse(line string) (e entry, ok bool) {
+	m := timeRe.FindStringSubmatch(line)
+	if m == nil {
+		return e, false
+	}
+	e.Time, _ = time.ParseInLocation(timeLayout, m[1], time.Local)
+	rest := line[len(m[0]):]
+
+	if m := callerRe.FindStringSubmatch(rest); m != nil {
+		e.Caller = m[1]
+		rest = rest[len(m[0]):]
+	}
+	var prefix string
+	if m := pluginRe.FindStringSubmatch(rest); m != nil {
+		e.Plugin = m[1]
+		prefix = m[0]
+		rest = rest[len(m[0]):]
+		// The caller logging the line is the plugin, the caller in Packer
+		// is the one relaying the logs of the plugins.
+		e.Caller = ""
+		if m := callerRe.FindStringSubmatch(rest); m != nil {
+			e.Caller = m[1]
+			rest = rest[len(m[0]):]
+		}
+	}
+	e.Text = prefix + rest
+
+	// Lines without a level are debug logs.
+	e.Level = Debug
+	if m := levelRe.FindStringSubmatch(rest); m != nil {
+		if l, err := ParseLevel(m[1]); err == nil && l != Off {
+			e.Level = l
+			rest = rest[len(m[0]):]
+		}
+	}
+	e.Message = rest
+	e.Subsystem = subsystem(e.Plugin, e.Caller)
+	return e, true
+}
+
+// subsystem returns the subsystem of a line logged by plugin, when not
+// empty, from caller.
+func subsystem(plugin, caller string) string {
+	caller = filepath.ToSlash(caller)
+	switch {
+	case strings.Contains(caller, "/communicator/"):
+		return "communicator"
+	case strings.Contains(caller, "/packer/rpc/"),
+		strings.Contains(caller, "/packer/plugin/"),
+		strings.Contains(caller, "/packer/grpc/"):
+		return "rpc"
+	case plugin != "":
+		return pluginSubsystem(plugin)
+	}
+	return "core"
+}
+
+// pluginSubsystem returns the subsystem of the plugin named name.
+func pluginSubsystem(name string) string {
+	name = strings.TrimSuffix(name, ".exe")
+	for _, kind := range []string{"builder", "provisioner", "post-processor"} {
+		if typ := strings.TrimPrefix(name, "packer-"+kind+"-"); typ != name {
+			return kind + "/" + typ
+		}
+	}
+	return "plugin/" + strings.TrimPrefix(name, "packer-plugin-")
+}
+
+// Filter is a writer of log lines writing the lines at the level of their
+// subsystem or above to its output, as text or as JSON.
+type Filter struct {
+	config *Config
+	output io.Writer
+
+	mu  sync.Mutex
+	buf []byte
+	// skip tells whether the last log line was filtered out, with the lines
+	// following it.
+	skip bool
+}
+
+// NewFilter returns a Filter writing the log lines to output, filtered and
+// formatted as configured.
+func NewFilter(config *Config, output io.Writer) *Filter {
+	return &Filter{config: config, output: output}
+}
+
+func (f *Filter) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.buf = append(f.buf, p...)
+	for {
+		i := bytes.IndexByte(f.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(f.buf[:i])
+		f.buf = f.buf[i+1:]
+		if err := f.writeLine(line); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes the last line when it isn't terminated by a newline.
+func (f *Filter) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.buf) == 0 {
+		return nil
+	}
+	line := string(f.buf)
+	f.buf = nil
+	return f.writeLine(line)
+}
+
+func (f *Filter) writeLine(line string) error {
+	e, ok := parse(line)
+	if !ok {
+		// The line follows a log line, or isn't a log line, like the output
+		// of a panic, and is written as is unless its log line is filtered
+		// out.
+		if f.skip {
+			return nil
+		}
+		if f.config.JSON {
+			return f.writeJSON(entry{Level: Info, Subsystem: "core", Message: line})
+		}
+		_, err := io.WriteString(f.output, line+"\n")
+		return err
+	}
+
+	f.skip = e.Level < f.config.LevelOf(e.Subsystem)
+	if f.skip {
+		return nil
+	}
+	if f.config.JSON {
+		return f.writeJSON(e)
+	}
+	text := e.Text
+	if !e.Time.IsZero() {
+		text = e.Time.Format(timeLayout) + " " + text
+	}
+	_, err := io.WriteString(f.output, text+"\n")
+	return err
+}
+
+func (f *Filter) writeJSON(e entry) error {
+	m := map[string]interface{}{
+		"@level":   e.Level.String(),
+		"@module":  e.Subsystem,
+		"@message": e.Message,
+	}
+	if !e.Time.IsZero() {
+		m["@timestamp"] = e.Time.Format(time.RFC3339)
+	}
+	if e.Caller != "" {
+		m["@caller"] = e.Caller
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = f.output.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		Line     string
+		Expected entry
+	}{
+		{
+			Line: "2020/06/05 10:42:00 [INFO] Packer version: 1.6.0",
+			Expected: entry{
+				Subsystem: "core",
+				Level:     Info,
+				Text:      "[INFO] Packer version: 1.6.0",
+				Message:   "Packer version: 1.6.0",
+			},
+		},
+		{
+			Line: "2020/06/05 10:42:00 /src/packer/packer/plugin/client.go:320: Waiting for RPC address",
+			Expected: entry{
+				Subsystem: "rpc",
+				Level:     Debug,
+				Caller:    "/src/packer/packer/plugin/client.go:320",
+				Text:      "Waiting for RPC address",
+				Message:   "Waiting for RPC address",
+			},
+		},
+		{
+			Line: "2020/06/05 10:42:00 /src/packer/packer/plugin/client.go:403: packer-builder-amazon-ebs plugin: " +
+				"/src/packer/builder/amazon/common/step_key_pair.go:40: [ERR] Error creating key pair",
+			Expected: entry{
+				Subsystem: "builder/amazon-ebs",
+				Level:     Error,
+				Caller:    "/src/packer/builder/amazon/common/step_key_pair.go:40",
+				Plugin:    "packer-builder-amazon-ebs",
+				Text:      "packer-builder-amazon-ebs plugin: [ERR] Error creating key pair",
+				Message:   "Error creating key pair",
+			},
+		},
+		{
+			Line: "2020/06/05 10:42:00 packer-provisioner-shell plugin: " +
+				"/src/packer/communicator/ssh/communicator.go:50: [TRACE] starting remote command",
+			Expected: entry{
+				Subsystem: "communicator",
+				Level:     Trace,
+				Caller:    "/src/packer/communicator/ssh/communicator.go:50",
+				Plugin:    "packer-provisioner-shell",
+				Text:      "packer-provisioner-shell plugin: [TRACE] starting remote command",
+				Message:   "starting remote command",
+			},
+		},
+		{
+			Line: "2020/06/05 10:42:00 packer-plugin-foo plugin: [WARNING] deprecated",
+			Expected: entry{
+				Subsystem: "plugin/foo",
+				Level:     Warn,
+				Plugin:    "packer-plugin-foo",
+				Text:      "packer-plugin-foo plugin: [WARNING] deprecated",
+				Message:   "deprecated",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		e, ok := parse(tc.Line)
+		if !ok {
+			t.Fatalf("%q: not parsed", tc.Line)
+		}
+		if e.Time.IsZero() {
+			t.Fatalf("%q: time not parsed", tc.Line)
+		}
+		tc.Expected.Time = e.Time
+		if e != tc.Expected {
+			t.Fatalf("%q: bad: %#v", tc.Line, e)
+		}
+	}
+
+	if _, ok := parse("goroutine 1 [running]:"); ok {
+		t.Fatal("line without time parsed")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	config := &Config{
+		Level:  Info,
+		Levels: []SubsystemLevel{{Pattern: "rpc", Level: Off}},
+	}
+	var buf bytes.Buffer
+	f := NewFilter(config, &buf)
+
+	input := "2020/06/05 10:42:00 /src/packer/main.go:10: [INFO] Packer version\n" +
+		"2020/06/05 10:42:00 /src/packer/main.go:11: Setting cache directory\n" +
+		"2020/06/05 10:42:00 /src/packer/packer/plugin/client.go:320: [ERR] Plugin exited\n" +
+		"2020/06/05 10:42:00 /src/packer/command/build.go:30: [WARN] multi-line\n" +
+		"second line\n" +
+		"2020/06/05 10:42:00 /src/packer/command/build.go:31: [DEBUG] filtered\n" +
+		"filtered too\n" +
+		"2020/06/05 10:42:00 [ERROR] split "
+	// Lines can be written in several writes.
+	if _, err := f.Write([]byte(input)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := f.Write([]byte("write\n")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := "2020/06/05 10:42:00 [INFO] Packer version\n" +
+		"2020/06/05 10:42:00 [WARN] multi-line\n" +
+		"second line\n" +
+		"2020/06/05 10:42:00 [ERROR] split write\n"
+	if buf.String() != expected {
+		t.Fatalf("bad:\n%s", buf.String())
+	}
+}
+
+func TestFilter_json(t *testing.T) {
+	config := &Config{Level: Trace, JSON: true}
+	var buf bytes.Buffer
+	f := NewFilter(config, &buf)
+
+	line := "2020/06/05 10:42:00 packer-builder-docker plugin: /src/packer/builder/docker/driver.go:20: [INFO] Pulling\n"
+	if _, err := f.Write([]byte(line)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("err: %s: %s", err, buf.String())
+	}
+	expected := map[string]interface{}{
+		"@level":   "info",
+		"@module":  "builder/docker",
+		"@message": "Pulling",
+		"@caller":  "/src/packer/builder/docker/driver.go:20",
+	}
+	for k, v := range expected {
+		if got[k] != v {
+			t.Fatalf("%s: expected %q, got %q", k, v, got[k])
+		}
+	}
+	if got["@timestamp"] == nil {
+		t.Fatal("no timestamp")
+	}
+}
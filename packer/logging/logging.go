@@ -0,0 +1,221 @@
+// Package logging filters the logs of Packer and of its plugins by level and
+// by subsystem, and writes them as text or as JSON, to stderr or to a file
+// that is rotated once it is too big.
+//
+// The logs of all the processes of a Packer run go through the Packer process
+// the user started, which filters them with a Filter. The other processes,
+// the wrapped Packer process and the plugins, log the file and line of the
+// log calls when filtering is enabled, see Flags, for the filter to find the
+// subsystem of each line:
+//
+//   - core: Packer itself.
+//   - communicator: the SSH and WinRM communicators.
+//   - rpc: the RPC between Packer and its plugins.
+//   - builder/NAME, provisioner/NAME and post-processor/NAME: the plugins,
+//     like builder/amazon-ebs. plugin/NAME for plugins serving several
+//     components.
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// These are the environment variables configuring the logs.
+const (
+	// EnvLog enables the logs. It is a level, or any other value but 0 to
+	// log everything.
+	EnvLog = "PACKER_LOG"
+	// EnvLogPath is the file the logs are written to, stderr by default.
+	EnvLogPath = "PACKER_LOG_PATH"
+	// EnvLogLevels are the levels of the subsystems, like
+	// "communicator=trace,builder/*=warn". The subsystems can be patterns.
+	EnvLogLevels = "PACKER_LOG_LEVELS"
+	// EnvLogFormat is the format of the logs, text or json.
+	EnvLogFormat = "PACKER_LOG_FORMAT"
+	// EnvLogMaxSize is the size, in MB, the log file is rotated at.
+	EnvLogMaxSize = "PACKER_LOG_MAX_SIZE"
+	// EnvLogMaxFiles is how many rotated log files are kept, 5 by default.
+	EnvLogMaxFiles = "PACKER_LOG_MAX_FILES"
+
+	// EnvLogCallers tells the processes started by Packer to log the callers
+	// of the log calls. Packer sets it when it filters the logs.
+	EnvLogCallers = "PACKER_LOG_CALLERS"
+)
+
+// Level is the level of a log line.
+type Level int
+
+const (
+	Trace Level = iota
+	Debug
+	Info
+	Warn
+	Error
+	// Off disables the logs.
+	Off
+)
+
+var levelNames = []string{"trace", "debug", "info", "warn", "error", "off"}
+
+func (l Level) String() string {
+	if l < Trace || l > Off {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses a level name, like debug.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace":
+		return Trace, nil
+	case "debug":
+		return Debug, nil
+	case "info":
+		return Info, nil
+	case "warn", "warning":
+		return Warn, nil
+	case "err", "error":
+		return Error, nil
+	case "off":
+		return Off, nil
+	}
+	return Off, fmt.Errorf("unknown log level %q, expected one of %s", s, strings.Join(levelNames, ", "))
+}
+
+// SubsystemLevel is the level of the subsystems matching Pattern, like
+// builder/*.
+type SubsystemLevel struct {
+	Pattern string
+	Level   Level
+}
+
+// Config configures the logs.
+type Config struct {
+	// Level is the level of the subsystems without a level of their own.
+	Level Level
+	// Levels are the levels of the subsystems. The last pattern matching a
+	// subsystem sets its level.
+	Levels []SubsystemLevel
+	// JSON writes the logs as JSON objects, one per line.
+	JSON bool
+
+	// Path is the file the logs are written to, stderr when empty.
+	Path string
+	// MaxSize is the size, in bytes, Path is rotated at. Zero disables the
+	// rotation.
+	MaxSize int64
+	// MaxFiles is how many rotated files are kept.
+	MaxFiles int
+}
+
+// ConfigFromEnv returns the configuration of the environment variables, nil
+// when the logs are disabled.
+func ConfigFromEnv() (*Config, error) {
+	c := &Config{Level: Off, MaxFiles: 5}
+
+	switch v := os.Getenv(EnvLog); v {
+	case "", "0":
+	default:
+		// Any other value, like 1, logs everything.
+		if l, err := ParseLevel(v); err == nil {
+			c.Level = l
+		} else {
+			c.Level = Trace
+		}
+	}
+
+	levels, err := ParseLevels(os.Getenv(EnvLogLevels))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", EnvLogLevels, err)
+	}
+	c.Levels = levels
+
+	switch f := strings.ToLower(os.Getenv(EnvLogFormat)); f {
+	case "", "text":
+	case "json":
+		c.JSON = true
+	default:
+		return nil, fmt.Errorf("%s: unknown log format %q, expected text or json", EnvLogFormat, f)
+	}
+
+	c.Path = os.Getenv(EnvLogPath)
+	if v := os.Getenv(EnvLogMaxSize); v != "" {
+		mb, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || mb < 0 {
+			return nil, fmt.Errorf("%s: invalid size %q", EnvLogMaxSize, v)
+		}
+		c.MaxSize = mb * 1024 * 1024
+	}
+	if v := os.Getenv(EnvLogMaxFiles); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s: invalid number of files %q", EnvLogMaxFiles, v)
+		}
+		c.MaxFiles = n
+	}
+
+	if !c.Enabled() {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// ParseLevels parses the levels of subsystems, like
+// "communicator=trace,builder/*=warn".
+func ParseLevels(s string) ([]SubsystemLevel, error) {
+	var levels []SubsystemLevel
+	for _, kv := range strings.Split(s, ",") {
+		if strings.TrimSpace(kv) == "" {
+			continue
+		}
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected subsystem=level, got %q", kv)
+		}
+		pattern := strings.TrimSpace(parts[0])
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid subsystem pattern %q: %s", pattern, err)
+		}
+		l, err := ParseLevel(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, SubsystemLevel{Pattern: pattern, Level: l})
+	}
+	return levels, nil
+}
+
+// Enabled tells whether some logs are written.
+func (c *Config) Enabled() bool {
+	if c.Level != Off {
+		return true
+	}
+	for _, l := range c.Levels {
+		if l.Level != Off {
+			return true
+		}
+	}
+	return false
+}
+
+// Filtered tells whether the logs must be filtered or reformatted, rather
+// than written as they are.
+func (c *Config) Filtered() bool {
+	return c.Level != Trace || len(c.Levels) > 0 || c.JSON
+}
+
+// LevelOf returns the level of the subsystem.
+func (c *Config) LevelOf(subsystem string) Level {
+	level := c.Level
+	for _, l := range c.Levels {
+		if ok, _ := path.Match(l.Pattern, subsystem); ok {
+			level = l.Level
+		}
+	}
+	return level
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	for _, k := range []string{EnvLog, EnvLogLevels, EnvLogFormat, EnvLogPath, EnvLogMaxSize, EnvLogMaxFiles} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Unsetenv(k)
+	}
+
+	cases := []struct {
+		Env      map[string]string
+		Expected *Config
+		Err      bool
+	}{
+		{Env: map[string]string{}},
+		{Env: map[string]string{EnvLog: "0"}},
+		{
+			Env:      map[string]string{EnvLog: "1"},
+			Expected: &Config{Level: Trace, MaxFiles: 5},
+		},
+		{
+			Env:      map[string]string{EnvLog: "WARN", EnvLogFormat: "json"},
+			Expected: &Config{Level: Warn, JSON: true, MaxFiles: 5},
+		},
+		{
+			Env: map[string]string{
+				EnvLogLevels:   "communicator=trace, builder/*=off",
+				EnvLogPath:     "packer.log",
+				EnvLogMaxSize:  "2",
+				EnvLogMaxFiles: "1",
+			},
+			Expected: &Config{
+				Level: Off,
+				Levels: []SubsystemLevel{
+					{Pattern: "communicator", Level: Trace},
+					{Pattern: "builder/*", Level: Off},
+				},
+				Path:     "packer.log",
+				MaxSize:  2 * 1024 * 1024,
+				MaxFiles: 1,
+			},
+		},
+		{Env: map[string]string{EnvLogLevels: "rpc=off"}},
+		{Env: map[string]string{EnvLog: "1", EnvLogLevels: "rpc"}, Err: true},
+		{Env: map[string]string{EnvLog: "1", EnvLogLevels: "rpc=loud"}, Err: true},
+		{Env: map[string]string{EnvLog: "1", EnvLogFormat: "xml"}, Err: true},
+		{Env: map[string]string{EnvLog: "1", EnvLogMaxSize: "big"}, Err: true},
+	}
+
+	for _, tc := range cases {
+		for k, v := range tc.Env {
+			os.Setenv(k, v)
+		}
+		config, err := ConfigFromEnv()
+		for k := range tc.Env {
+			os.Unsetenv(k)
+		}
+
+		if (err != nil) != tc.Err {
+			t.Fatalf("%v: err: %s", tc.Env, err)
+		}
+		if !reflect.DeepEqual(config, tc.Expected) {
+			t.Fatalf("%v: bad: %#v", tc.Env, config)
+		}
+	}
+}
+
+func TestConfig_LevelOf(t *testing.T) {
+	config := &Config{
+		Level: Info,
+		Levels: []SubsystemLevel{
+			{Pattern: "builder/*", Level: Warn},
+			{Pattern: "builder/amazon-ebs", Level: Debug},
+		},
+	}
+
+	cases := map[string]Level{
+		"core":               Info,
+		"builder/docker":     Warn,
+		"builder/amazon-ebs": Debug,
+	}
+	for subsystem, expected := range cases {
+		if got := config.LevelOf(subsystem); got != expected {
+			t.Fatalf("%s: expected %s, got %s", subsystem, expected, got)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package packer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// These are the types of notifications.
+const (
+	NotificationSlack = "slack"
+	NotificationHTTP  = "http"
+	NotificationSMTP  = "smtp"
+)
+
+// These are the events of a build a notification is sent on.
+const (
+	NotificationOnStart   = "start"
+	NotificationOnSuccess = "success"
+	NotificationOnFailure = "failure"
+)
+
+// NotificationTimeout bounds the time spent sending a notification.
+var NotificationTimeout = 30 * time.Second
+
+// Notification is sent when a build starts, succeeds or fails. Sending it
+// never fails the build: errors are only reported.
+type Notification struct {
+	// Type is one of slack, http or smtp.
+	Type string
+	// On are the events to notify of, all of them when empty.
+	On []string
+
+	// URL is the webhook of slack and http notifications.
+	URL string
+	// Method is the HTTP method of http notifications, POST by default.
+	Method string
+	// Headers are added to the requests of http notifications.
+	Headers map[string]string
+
+	// Template is the payload, rendered with the NotificationData: the text
+	// of a slack message, the body of an http request or of an email. The
+	// body of an http request is the JSON of the NotificationData when it is
+	// empty, and the others get a short summary of the event.
+	Template string
+	// Subject is the template of the subject of an email.
+	Subject string
+
+	// SMTPAddress is the host:port of the mail server of smtp
+	// notifications, which are sent from From to To.
+	SMTPAddress  string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	To           []string
+}
+
+// NotificationData is what a notification tells about a build. It is the
+// data of the templates of the notification.
+type NotificationData struct {
+	BuildName string `json:"build"`
+	// Event is one of start, success or failure.
+	Event string `json:"event"`
+	// Duration is how long the build ran, rounded to the second, once it
+	// finished.
+	Duration string `json:"duration,omitempty"`
+	// DurationSeconds is Duration in seconds.
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	// Error is the error of a failed build.
+	Error       string           `json:"error,omitempty"`
+	ArtifactIDs []string         `json:"artifact_ids,omitempty"`
+	Artifacts   []*ArtifactEvent `json:"artifacts,omitempty"`
+}
+
+// Validate returns an error when the notification is not complete.
+func (n *Notification) Validate() error {
+	for _, on := range n.On {
+		switch on {
+		case NotificationOnStart, NotificationOnSuccess, NotificationOnFailure:
+		default:
+			return fmt.Errorf("on must be a list of %q, %q and %q, got %q",
+				NotificationOnStart, NotificationOnSuccess, NotificationOnFailure, on)
+		}
+	}
+	switch n.Type {
+	case NotificationSlack, NotificationHTTP:
+		if n.URL == "" {
+			return fmt.Errorf("a %s notification requires url", n.Type)
+		}
+	case NotificationSMTP:
+		if n.SMTPAddress == "" || n.From == "" || len(n.To) == 0 {
+			return fmt.Errorf("an smtp notification requires smtp_address, from and to")
+		}
+	default:
+		return fmt.Errorf("the type of a notification must be one of %q, %q or %q, got %q",
+			NotificationSlack, NotificationHTTP, NotificationSMTP, n.Type)
+	}
+	return nil
+}
+
+// notifies tells whether the notification is sent on event.
+func (n *Notification) notifies(event string) bool {
+	if len(n.On) == 0 {
+		return true
+	}
+	for _, on := range n.On {
+		if on == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Send sends the notification of data.
+func (n *Notification) Send(ctx context.Context, data *NotificationData) error {
+	payload, err := n.render(n.Template, data)
+	if err != nil {
+		return fmt.Errorf("Error rendering the template: %s", err)
+	}
+	switch n.Type {
+	case NotificationSlack:
+		if payload == "" {
+			payload = data.summary()
+		}
+		body, _ := json.Marshal(map[string]string{"text": payload})
+		return n.post(ctx, "POST", map[string]string{"Content-Type": "application/json"}, body)
+	case NotificationHTTP:
+		headers := map[string]string{}
+		body := []byte(payload)
+		if payload == "" {
+			headers["Content-Type"] = "application/json"
+			body, _ = json.Marshal(data)
+		}
+		for k, v := range n.Headers {
+			headers[k] = v
+		}
+		method := n.Method
+		if method == "" {
+			method = "POST"
+		}
+		return n.post(ctx, method, headers, body)
+	case NotificationSMTP:
+		subject, err := n.render(n.Subject, data)
+		if err != nil {
+			return fmt.Errorf("Error rendering the subject: %s", err)
+		}
+		if subject == "" {
+			subject = fmt.Sprintf("Packer build '%s': %s", data.BuildName, data.Event)
+		}
+		if payload == "" {
+			payload = data.summary()
+		}
+		return n.mail(subject, payload)
+	}
+	return fmt.Errorf("unknown notification type %q", n.Type)
+}
+
+func (n *Notification) render(tpl string, data *NotificationData) (string, error) {
+	if tpl == "" {
+		return "", nil
+	}
+	return interpolate.Render(tpl, &interpolate.Context{Data: data})
+}
+
+func (n *Notification) post(ctx context.Context, method string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(method, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+func (n *Notification) mail(subject, body string) error {
+	var auth smtp.Auth
+	if n.SMTPUsername != "" {
+		host, _, _ := net.SplitHostPort(n.SMTPAddress)
+		auth = smtp.PlainAuth("", n.SMTPUsername, n.SMTPPassword, host)
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.Replace(subject, "\n", " ", -1))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.Replace(body, "\n", "\r\n", -1))
+	return smtp.SendMail(n.SMTPAddress, auth, n.From, n.To, msg.Bytes())
+}
+
+// summary is the default text of a notification.
+func (d *NotificationData) summary() string {
+	switch d.Event {
+	case NotificationOnStart:
+		return fmt.Sprintf("Packer build '%s' started.", d.BuildName)
+	case NotificationOnSuccess:
+		s := fmt.Sprintf("Packer build '%s' succeeded in %s.", d.BuildName, d.Duration)
+		if len(d.ArtifactIDs) > 0 {
+			s += " Artifacts: " + strings.Join(d.ArtifactIDs, ", ")
+		}
+		return s
+	default:
+		return fmt.Sprintf("Packer build '%s' failed after %s: %s", d.BuildName, d.Duration, d.Error)
+	}
+}
+
+// notify sends the notifications of the build on the event of data,
+// reporting their errors on ui. They don't use the context of the build, so
+// that the failure of a cancelled build is notified too.
+func (b *CoreBuild) notify(ui Ui, data *NotificationData) {
+	for _, n := range b.Notifications {
+		if !n.notifies(data.Event) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), NotificationTimeout)
+		err := n.Send(ctx, data)
+		cancel()
+		if err != nil {
+			log.Printf("[WARN] %s notification of build %s failed: %s", n.Type, data.BuildName, err)
+			ui.Error(fmt.Sprintf("Error sending the %s notification of the %s event: %s",
+				n.Type, data.Event, err))
+		}
+	}
+}
+
+// newNotificationData describes a finished build for its notifications.
+func newNotificationData(name string, d time.Duration, artifacts []Artifact, err error) *NotificationData {
+	data := &NotificationData{
+		BuildName:       name,
+		Event:           NotificationOnSuccess,
+		Duration:        d.Round(time.Second).String(),
+		DurationSeconds: d.Seconds(),
+	}
+	if err != nil {
+		data.Event = NotificationOnFailure
+		data.Error = err.Error()
+	}
+	for _, a := range artifacts {
+		if a == nil {
+			continue
+		}
+		e := NewArtifactEvent(a)
+		data.ArtifactIDs = append(data.ArtifactIDs, e.ID)
+		data.Artifacts = append(data.Artifacts, e)
+	}
+	return data
+}
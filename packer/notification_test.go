@@ -0,0 +1,138 @@
+package packer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// notificationServer records the requests it receives.
+type notificationServer struct {
+	*httptest.Server
+	l        sync.Mutex
+	requests []*http.Request
+	bodies   []string
+}
+
+func testNotificationServer(status int) *notificationServer {
+	s := &notificationServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.l.Lock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, string(body))
+		s.l.Unlock()
+		w.WriteHeader(status)
+	}))
+	return s
+}
+
+func TestNotificationValidate(t *testing.T) {
+	cases := []struct {
+		n   Notification
+		err bool
+	}{
+		{Notification{Type: "slack", URL: "https://example.com"}, false},
+		{Notification{Type: "http", URL: "https://example.com", On: []string{"start", "failure"}}, false},
+		{Notification{Type: "smtp", SMTPAddress: "mail:25", From: "a@b", To: []string{"c@d"}}, false},
+		{Notification{Type: "slack"}, true},
+		{Notification{Type: "smtp", SMTPAddress: "mail:25"}, true},
+		{Notification{Type: "http", URL: "https://example.com", On: []string{"finish"}}, true},
+		{Notification{Type: "pigeon"}, true},
+	}
+	for _, tc := range cases {
+		if err := tc.n.Validate(); (err != nil) != tc.err {
+			t.Errorf("%#v: unexpected error: %v", tc.n, err)
+		}
+	}
+}
+
+func TestNotificationSend_slack(t *testing.T) {
+	s := testNotificationServer(http.StatusOK)
+	defer s.Close()
+
+	n := &Notification{Type: NotificationSlack, URL: s.URL}
+	data := newNotificationData("vm", 90*time.Second, []Artifact{&MockArtifact{IdValue: "ami-1"}}, nil)
+	if err := n.Send(context.Background(), data); err != nil {
+		t.Fatal(err)
+	}
+	n.Template = "{{ .BuildName }} {{ .Event }} {{ range .ArtifactIDs }}{{ . }}{{ end }}"
+	if err := n.Send(context.Background(), data); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"text":"Packer build 'vm' succeeded in 1m30s. Artifacts: ami-1"}`,
+		`{"text":"vm success ami-1"}`,
+	}
+	if !reflect.DeepEqual(s.bodies, expected) {
+		t.Fatalf("bad bodies: %#v", s.bodies)
+	}
+}
+
+func TestNotificationSend_http(t *testing.T) {
+	s := testNotificationServer(http.StatusOK)
+	defer s.Close()
+
+	n := &Notification{
+		Type:    NotificationHTTP,
+		URL:     s.URL,
+		Method:  "PUT",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}
+	data := newNotificationData("vm", time.Minute, nil, errors.New("boom"))
+	if err := n.Send(context.Background(), data); err != nil {
+		t.Fatal(err)
+	}
+
+	r := s.requests[0]
+	if r.Method != "PUT" || r.Header.Get("Authorization") != "Bearer token" ||
+		r.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("bad request: %#v", r)
+	}
+	var got NotificationData
+	if err := json.Unmarshal([]byte(s.bodies[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, data) {
+		t.Fatalf("bad data: %#v", got)
+	}
+}
+
+func TestNotificationSend_error(t *testing.T) {
+	s := testNotificationServer(http.StatusInternalServerError)
+	defer s.Close()
+
+	n := &Notification{Type: NotificationHTTP, URL: s.URL}
+	data := &NotificationData{BuildName: "vm", Event: NotificationOnStart}
+	if err := n.Send(context.Background(), data); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestBuild_RunNotifications(t *testing.T) {
+	s := testNotificationServer(http.StatusOK)
+	defer s.Close()
+
+	build := testBuild()
+	build.Notifications = []*Notification{
+		{Type: NotificationHTTP, URL: s.URL, Template: "{{ .Event }}"},
+		{Type: NotificationHTTP, URL: s.URL, Template: "{{ .Event }} only", On: []string{"failure"}},
+	}
+	build.Prepare()
+	if _, err := build.Run(context.Background(), testUi()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{"start", "success"}
+	if !reflect.DeepEqual(s.bodies, expected) {
+		t.Fatalf("bad bodies: %#v", s.bodies)
+	}
+}
@@ -0,0 +1,376 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	commonhelper "github.com/hashicorp/packer/helper/common"
+	packerVersion "github.com/hashicorp/packer/version"
+)
+
+// exportDelay batches the spans and metrics recorded shortly after one
+// another in the same export.
+const exportDelay = 200 * time.Millisecond
+
+// exporter sends spans and metrics to an OTLP/HTTP collector. Exports happen
+// in the background soon after data is recorded because plugin processes
+// get killed when Packer is done with them.
+type exporter struct {
+	endpoint string
+	headers  map[string]string
+	service  string
+	traceID  string
+	client   *http.Client
+
+	l          sync.Mutex
+	spans      []*Span
+	histograms map[string]*histogram
+	wake       chan struct{}
+
+	// exportL serializes exports.
+	exportL sync.Mutex
+}
+
+// histogram aggregates the values recorded for a metric and a set of
+// attributes since the last export.
+type histogram struct {
+	name  string
+	unit  string
+	attrs []Attribute
+	start time.Time
+	count uint64
+	sum   float64
+	min   float64
+	max   float64
+}
+
+func newExporter(endpoint string, headers map[string]string, service string) *exporter {
+	e := &exporter{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		headers:    headers,
+		service:    service,
+		traceID:    runTraceID(),
+		client:     &http.Client{Transport: commonhelper.HttpTransport(), Timeout: 5 * time.Second},
+		histograms: map[string]*histogram{},
+		wake:       make(chan struct{}, 1),
+	}
+	go e.loop()
+	return e
+}
+
+func (e *exporter) loop() {
+	for range e.wake {
+		time.Sleep(exportDelay)
+		if err := e.flush(context.Background()); err != nil {
+			log.Printf("[WARN] (otel) export failed: %s", err)
+		}
+	}
+}
+
+func (e *exporter) notify() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (e *exporter) addSpan(s *Span) {
+	e.l.Lock()
+	e.spans = append(e.spans, s)
+	e.l.Unlock()
+	e.notify()
+}
+
+func (e *exporter) record(name, unit string, value float64, attrs []Attribute) {
+	key := name + fmt.Sprint(sortedAttributes(attrs))
+
+	e.l.Lock()
+	h, ok := e.histograms[key]
+	if !ok {
+		h = &histogram{name: name, unit: unit, attrs: attrs, start: time.Now(), min: value, max: value}
+		e.histograms[key] = h
+	}
+	h.count++
+	h.sum += value
+	if value < h.min {
+		h.min = value
+	}
+	if value > h.max {
+		h.max = value
+	}
+	e.l.Unlock()
+	e.notify()
+}
+
+// flush exports the pending data. Data that failed to export is dropped so
+// that an unreachable collector never makes Packer grow unbounded.
+func (e *exporter) flush(ctx context.Context) error {
+	e.exportL.Lock()
+	defer e.exportL.Unlock()
+
+	e.l.Lock()
+	spans, histograms := e.spans, e.histograms
+	e.spans, e.histograms = nil, map[string]*histogram{}
+	e.l.Unlock()
+
+	if len(spans) > 0 {
+		if err := e.post(ctx, "/v1/traces", e.tracesRequest(spans)); err != nil {
+			return err
+		}
+	}
+	if len(histograms) > 0 {
+		if err := e.post(ctx, "/v1/metrics", e.metricsRequest(histograms)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *exporter) post(ctx context.Context, path string, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", e.endpoint+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s: %s", path, resp.Status, msg)
+	}
+	return nil
+}
+
+// The types below are the OTLP/HTTP JSON encoding of the protocol buffers
+// of OpenTelemetry.
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpTracesRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpHistogramDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	Count             string         `json:"count"`
+	Sum               float64        `json:"sum"`
+	Min               float64        `json:"min"`
+	Max               float64        `json:"max"`
+	BucketCounts      []string       `json:"bucketCounts"`
+	ExplicitBounds    []float64      `json:"explicitBounds"`
+}
+
+type otlpMetric struct {
+	Name      string `json:"name"`
+	Unit      string `json:"unit"`
+	Histogram struct {
+		DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+		AggregationTemporality int                      `json:"aggregationTemporality"`
+	} `json:"histogram"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpMetricsRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+const (
+	otlpSpanKindInternal = 1
+
+	otlpStatusUnset = 0
+	otlpStatusError = 2
+
+	otlpTemporalityDelta = 1
+)
+
+func (e *exporter) resource() otlpResource {
+	return otlpResource{Attributes: otlpAttributes([]Attribute{
+		String("service.name", e.service),
+		String("service.version", packerVersion.FormattedVersion()),
+	})}
+}
+
+func (e *exporter) scope() otlpScope {
+	return otlpScope{Name: "github.com/hashicorp/packer", Version: packerVersion.FormattedVersion()}
+}
+
+func (e *exporter) tracesRequest(spans []*Span) *otlpTracesRequest {
+	ss := otlpScopeSpans{Scope: e.scope()}
+	for _, s := range spans {
+		status := otlpStatus{Code: otlpStatusUnset}
+		if s.err != "" {
+			status = otlpStatus{Code: otlpStatusError, Message: s.err}
+		}
+		ss.Spans = append(ss.Spans, otlpSpan{
+			TraceID:           s.traceID,
+			SpanID:            s.spanID,
+			ParentSpanID:      s.parentID,
+			Name:              s.name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: unixNano(s.start),
+			EndTimeUnixNano:   unixNano(s.end),
+			Attributes:        otlpAttributes(s.attrs),
+			Status:            status,
+		})
+	}
+	return &otlpTracesRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   e.resource(),
+		ScopeSpans: []otlpScopeSpans{ss},
+	}}}
+}
+
+func (e *exporter) metricsRequest(histograms map[string]*histogram) *otlpMetricsRequest {
+	// Group the data points by metric, in a stable order.
+	keys := make([]string, 0, len(histograms))
+	for k := range histograms {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	now := unixNano(time.Now())
+	var metrics []otlpMetric
+	index := map[string]int{}
+	for _, k := range keys {
+		h := histograms[k]
+		i, ok := index[h.name]
+		if !ok {
+			i = len(metrics)
+			index[h.name] = i
+			m := otlpMetric{Name: h.name, Unit: h.unit}
+			m.Histogram.AggregationTemporality = otlpTemporalityDelta
+			metrics = append(metrics, m)
+		}
+		count := strconv.FormatUint(h.count, 10)
+		metrics[i].Histogram.DataPoints = append(metrics[i].Histogram.DataPoints, otlpHistogramDataPoint{
+			Attributes:        otlpAttributes(h.attrs),
+			StartTimeUnixNano: unixNano(h.start),
+			TimeUnixNano:      now,
+			Count:             count,
+			Sum:               h.sum,
+			Min:               h.min,
+			Max:               h.max,
+			BucketCounts:      []string{count},
+			ExplicitBounds:    []float64{},
+		})
+	}
+
+	return &otlpMetricsRequest{ResourceMetrics: []otlpResourceMetrics{{
+		Resource:     e.resource(),
+		ScopeMetrics: []otlpScopeMetrics{{Scope: e.scope(), Metrics: metrics}},
+	}}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func sortedAttributes(attrs []Attribute) []Attribute {
+	sorted := append([]Attribute(nil), attrs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted
+}
+
+func otlpAttributes(attrs []Attribute) []otlpKeyValue {
+	var kvs []otlpKeyValue
+	for _, a := range attrs {
+		var v otlpAnyValue
+		switch value := a.Value.(type) {
+		case string:
+			v.StringValue = &value
+		case bool:
+			v.BoolValue = &value
+		case int64:
+			s := strconv.FormatInt(value, 10)
+			v.IntValue = &s
+		case int:
+			s := strconv.Itoa(value)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &value
+		default:
+			s := fmt.Sprint(value)
+			v.StringValue = &s
+		}
+		kvs = append(kvs, otlpKeyValue{Key: a.Key, Value: v})
+	}
+	return kvs
+}
@@ -0,0 +1,169 @@
+// Package otel records OpenTelemetry traces and metrics of builds and exports
+// them with the OTLP/HTTP protocol, using its JSON encoding.
+//
+// Telemetry is enabled by setting the standard OTEL_EXPORTER_OTLP_ENDPOINT
+// environment variable to the base URL of a collector, for example
+// http://localhost:4318. OTEL_EXPORTER_OTLP_HEADERS and OTEL_SERVICE_NAME are
+// honored too. Plugin processes inherit the environment of Packer, so they
+// export their own spans and metrics, all in the trace of the Packer run.
+package otel
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Attribute is a key/value pair describing a span or a metric data point.
+// Value can be a string, a bool, an int, an int64 or a float64.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute.
+func String(k, v string) Attribute { return Attribute{k, v} }
+
+// Int returns an integer attribute.
+func Int(k string, v int) Attribute { return Attribute{k, int64(v)} }
+
+// Bool returns a boolean attribute.
+func Bool(k string, v bool) Attribute { return Attribute{k, v} }
+
+var (
+	setupOnce sync.Once
+	exp       *exporter
+)
+
+// Enabled tells whether telemetry is exported.
+func Enabled() bool {
+	setupOnce.Do(setup)
+	return exp != nil
+}
+
+func setup() {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		return
+	}
+	service := os.Getenv("OTEL_SERVICE_NAME")
+	if service == "" {
+		service = "packer"
+	}
+	exp = newExporter(endpoint, parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")), service)
+}
+
+// parseHeaders parses the comma separated key=value pairs of
+// OTEL_EXPORTER_OTLP_HEADERS.
+func parseHeaders(s string) map[string]string {
+	headers := map[string]string{}
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return headers
+}
+
+// runTraceID returns the trace ID shared by all the processes of a Packer run.
+// It is the run UUID, which plugins inherit, or a random ID.
+func runTraceID() string {
+	id := strings.Replace(os.Getenv("PACKER_RUN_UUID"), "-", "", -1)
+	if _, err := hex.DecodeString(id); err == nil && len(id) == 32 {
+		return id
+	}
+	return randomID(16)
+}
+
+func randomID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Span is an operation of a trace. A nil Span, returned when telemetry is
+// disabled, can be used safely.
+type Span struct {
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	start    time.Time
+	end      time.Time
+	attrs    []Attribute
+	err      string
+}
+
+type spanKey struct{}
+
+// StartSpan starts a span, child of the span of ctx if any. The returned
+// context carries the new span.
+func StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	if !Enabled() {
+		return ctx, nil
+	}
+	s := &Span{
+		traceID: exp.traceID,
+		spanID:  randomID(8),
+		name:    name,
+		start:   time.Now(),
+		attrs:   attrs,
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok {
+		s.parentID = parent.spanID
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// End ends the span, marking it as failed when err is not nil, and queues it
+// for export.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	if err != nil {
+		s.err = err.Error()
+	}
+	exp.addSpan(s)
+}
+
+// Duration returns the time since the span started.
+func (s *Span) Duration() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return time.Since(s.start)
+}
+
+// Record records a value of a histogram metric, like a duration in seconds
+// with the "s" unit or a throughput with the "By/s" unit.
+func Record(name, unit string, value float64, attrs ...Attribute) {
+	if !Enabled() {
+		return
+	}
+	exp.record(name, unit, value, attrs)
+}
+
+// Flush exports the pending spans and metrics. It should be called before
+// the process exits.
+func Flush(ctx context.Context) error {
+	if !Enabled() {
+		return nil
+	}
+	return exp.flush(ctx)
+}
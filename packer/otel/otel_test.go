@@ -0,0 +1,125 @@
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type testCollector struct {
+	sync.Mutex
+	traces  []otlpTracesRequest
+	metrics []otlpMetricsRequest
+	headers []http.Header
+}
+
+func (c *testCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.Lock()
+	defer c.Unlock()
+	c.headers = append(c.headers, r.Header)
+	switch r.URL.Path {
+	case "/v1/traces":
+		var req otlpTracesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.traces = append(c.traces, req)
+	case "/v1/metrics":
+		var req otlpMetricsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.metrics = append(c.metrics, req)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func testExporter() (*testCollector, *httptest.Server) {
+	c := &testCollector{}
+	ts := httptest.NewServer(c)
+	setupOnce.Do(func() {})
+	exp = newExporter(ts.URL, map[string]string{"X-Api-Key": "secret"}, "packer-test")
+	return c, ts
+}
+
+func TestSpansAndMetrics(t *testing.T) {
+	c, ts := testExporter()
+	defer ts.Close()
+
+	// Keep the background loop from exporting while data is recorded.
+	exp.exportL.Lock()
+
+	ctx, build := StartSpan(context.Background(), "build", String("packer.build", "example"))
+	_, step := StartSpan(ctx, "step", Int("index", 1))
+	step.End(errors.New("boom"))
+	build.End(nil)
+
+	Record("packer.step.duration", "s", 1, String("step", "a"))
+	Record("packer.step.duration", "s", 3, String("step", "a"))
+	Record("packer.step.duration", "s", 2, String("step", "b"))
+
+	exp.exportL.Unlock()
+	if err := Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if len(c.traces) != 1 || len(c.metrics) != 1 {
+		t.Fatalf("expected one export of each kind, got %d traces and %d metrics", len(c.traces), len(c.metrics))
+	}
+	if got := c.headers[0].Get("X-Api-Key"); got != "secret" {
+		t.Fatalf("bad header: %q", got)
+	}
+
+	spans := c.traces[0].ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("bad spans: %#v", spans)
+	}
+	stepSpan, buildSpan := spans[0], spans[1]
+	if stepSpan.ParentSpanID != buildSpan.SpanID || buildSpan.ParentSpanID != "" {
+		t.Fatalf("bad parents: %#v", spans)
+	}
+	if stepSpan.TraceID != buildSpan.TraceID || len(stepSpan.TraceID) != 32 {
+		t.Fatalf("bad trace ids: %#v", spans)
+	}
+	if stepSpan.Status.Code != otlpStatusError || stepSpan.Status.Message != "boom" {
+		t.Fatalf("bad status: %#v", stepSpan.Status)
+	}
+	if *stepSpan.Attributes[0].Value.IntValue != "1" {
+		t.Fatalf("bad attributes: %#v", stepSpan.Attributes)
+	}
+
+	metrics := c.metrics[0].ResourceMetrics[0].ScopeMetrics[0].Metrics
+	if len(metrics) != 1 || metrics[0].Name != "packer.step.duration" {
+		t.Fatalf("bad metrics: %#v", metrics)
+	}
+	points := metrics[0].Histogram.DataPoints
+	if len(points) != 2 {
+		t.Fatalf("bad data points: %#v", points)
+	}
+	a := points[0]
+	if a.Count != "2" || a.Sum != 4 || a.Min != 1 || a.Max != 3 {
+		t.Fatalf("bad data point: %#v", a)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers := parseHeaders("a=1, b = 2,invalid,c=x=y")
+	expected := map[string]string{"a": "1", "b": "2", "c": "x=y"}
+	if len(headers) != len(expected) {
+		t.Fatalf("bad: %#v", headers)
+	}
+	for k, v := range expected {
+		if headers[k] != v {
+			t.Fatalf("bad: %#v", headers)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package packer
+
+import "fmt"
+
+// ArtifactInfo describes an artifact to the expressions of the named outputs
+// of a build.
+type ArtifactInfo struct {
+	ID        string
+	BuilderID string
+	String    string
+	Files     []string
+	// Metadata are the builder specific details of the artifact, like the ID
+	// of an image per region, keyed by "region.<name>".
+	Metadata map[string]string
+}
+
+// NewArtifactInfo describes a.
+func NewArtifactInfo(a Artifact) ArtifactInfo {
+	info := ArtifactInfo{
+		ID:        a.Id(),
+		BuilderID: a.BuilderId(),
+		String:    a.String(),
+		Files:     a.Files(),
+		Metadata:  map[string]string{},
+	}
+	if metadata, ok := a.State("atlas.artifact.metadata").(map[string]string); ok {
+		info.Metadata = metadata
+	}
+	return info
+}
+
+// OutputsData is the data the named outputs of a build are computed from: the
+// last artifact of the build, and all its artifacts.
+type OutputsData struct {
+	ArtifactInfo
+	Artifacts []ArtifactInfo
+}
+
+// NewOutputsData returns the data of the outputs of a build that produced
+// artifacts.
+func NewOutputsData(artifacts []Artifact) *OutputsData {
+	data := &OutputsData{}
+	for _, a := range artifacts {
+		if a != nil {
+			data.Artifacts = append(data.Artifacts, NewArtifactInfo(a))
+		}
+	}
+	if len(data.Artifacts) > 0 {
+		data.ArtifactInfo = data.Artifacts[len(data.Artifacts)-1]
+	}
+	return data
+}
+
+// ValidateOutputName checks that name can be used for a named output.
+func ValidateOutputName(name string) error {
+	for _, n := range BuildOutputNames {
+		if n == name {
+			return fmt.Errorf("output name %q is reserved", name)
+		}
+	}
+	if name == "" {
+		return fmt.Errorf("output name can't be empty")
+	}
+	return nil
+}
+
+// Outputs returns the outputs of the build b, which produced artifacts: the
+// id and builder_id outputs of every build, and the named outputs of b.
+func Outputs(b Build, artifacts []Artifact) (map[string]string, error) {
+	outputs := ArtifactOutputs(artifacts)
+	cb, ok := b.(*CoreBuild)
+	if !ok || cb.NamedOutputs == nil {
+		return outputs, nil
+	}
+	named, err := cb.NamedOutputs(NewOutputsData(artifacts))
+	if err != nil {
+		return outputs, fmt.Errorf("computing the outputs of build '%s': %s", b.Name(), err)
+	}
+	for k, v := range named {
+		outputs[k] = v
+	}
+	return outputs, nil
+}
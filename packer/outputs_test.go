@@ -0,0 +1,66 @@
+package packer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewOutputsData(t *testing.T) {
+	data := NewOutputsData([]Artifact{
+		&MockArtifact{IdValue: "first"},
+		nil,
+		&MockArtifact{
+			IdValue:     "last",
+			StateValues: map[string]interface{}{"atlas.artifact.metadata": map[string]string{"region.us-east-1": "ami-1234"}},
+		},
+	})
+	if len(data.Artifacts) != 2 {
+		t.Fatalf("bad artifacts: %#v", data.Artifacts)
+	}
+	if data.ID != "last" || data.Metadata["region.us-east-1"] != "ami-1234" {
+		t.Fatalf("bad last artifact: %#v", data.ArtifactInfo)
+	}
+	if data.Artifacts[0].ID != "first" || len(data.Artifacts[0].Metadata) != 0 {
+		t.Fatalf("bad first artifact: %#v", data.Artifacts[0])
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	artifacts := []Artifact{&MockArtifact{IdValue: "image"}}
+
+	b := &CoreBuild{Type: "app"}
+	outputs, err := Outputs(b, artifacts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if expected := map[string]string{"id": "image", "builder_id": "bid"}; !reflect.DeepEqual(outputs, expected) {
+		t.Fatalf("bad outputs: %#v", outputs)
+	}
+
+	b.NamedOutputs = func(data *OutputsData) (map[string]string, error) {
+		return map[string]string{"image": "named-" + data.ID}, nil
+	}
+	outputs, err = Outputs(b, artifacts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if expected := map[string]string{"id": "image", "builder_id": "bid", "image": "named-image"}; !reflect.DeepEqual(outputs, expected) {
+		t.Fatalf("bad outputs: %#v", outputs)
+	}
+
+	b.NamedOutputs = func(*OutputsData) (map[string]string, error) {
+		return nil, errors.New("oops")
+	}
+	if _, err := Outputs(b, artifacts); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestValidateOutputName(t *testing.T) {
+	for name, valid := range map[string]bool{"ami": true, "id": false, "builder_id": false, "": false} {
+		if err := ValidateOutputName(name); (err == nil) != valid {
+			t.Fatalf("%q: bad result: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package github gets plugins released on GitHub: the releases of the
+// github.com/<namespace>/<name> plugin are the ones of the
+// <namespace>/packer-plugin-<name> repository.
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/hashicorp/go-version"
+	commonhelper "github.com/hashicorp/packer/helper/common"
+	plugingetter "github.com/hashicorp/packer/packer/plugin-getter"
+)
+
+const (
+	defaultAPIURL      = "https://api.github.com"
+	defaultDownloadURL = "https://github.com"
+	// tokenEnvVar is the environment variable holding the GitHub token used
+	// to get the releases, to avoid the rate limit of anonymous requests.
+	tokenEnvVar = "PACKER_GITHUB_API_TOKEN"
+)
+
+// Getter is a plugingetter.Getter of the plugins released on GitHub.
+type Getter struct {
+	// Client is the HTTP client, a client honoring the proxy, the CA bundle
+	// and the client certificate of the environment by default.
+	Client *http.Client
+	// APIURL and DownloadURL are the URLs of the GitHub API and of the
+	// release downloads, for tests.
+	APIURL      string
+	DownloadURL string
+}
+
+var _ plugingetter.Getter = &Getter{}
+
+func (g *Getter) client() *http.Client {
+	if g.Client == nil {
+		g.Client = commonhelper.HttpClient()
+	}
+	return g.Client
+}
+
+func repository(id *plugingetter.Identity) (string, error) {
+	if id.Hostname != "github.com" {
+		return "", fmt.Errorf("%s is not a GitHub plugin", id)
+	}
+	return url.PathEscape(id.Namespace) + "/packer-plugin-" + url.PathEscape(id.Type), nil
+}
+
+func (g *Getter) get(u string, api bool) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if api {
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+		if token := os.Getenv(tokenEnvVar); token != "" {
+			req.Header.Set("Authorization", "token "+token)
+		}
+	}
+	log.Printf("[DEBUG] GET %s", u)
+	resp, err := g.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return resp, nil
+}
+
+type release struct {
+	TagName string `json:"tag_name"`
+	Draft   bool   `json:"draft"`
+}
+
+// Releases returns the versions of the releases of the repository of the
+// plugin, from their tags like v1.2.3.
+func (g *Getter) Releases(id *plugingetter.Identity) ([]*version.Version, error) {
+	repo, err := repository(id)
+	if err != nil {
+		return nil, err
+	}
+	apiURL := g.APIURL
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+	resp, err := g.get(fmt.Sprintf("%s/repos/%s/releases?per_page=100", apiURL, repo), true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var releases []release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("could not read the releases of %s: %s", repo, err)
+	}
+	var versions []*version.Version
+	for _, r := range releases {
+		if r.Draft {
+			continue
+		}
+		v, err := version.NewVersion(r.TagName)
+		if err != nil {
+			log.Printf("[TRACE] ignoring the release %q of %s: %s", r.TagName, repo, err)
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// Get downloads a file of the release of the plugin tagged with its
+// version.
+func (g *Getter) Get(id *plugingetter.Identity, v *version.Version, filename string) (io.ReadCloser, error) {
+	repo, err := repository(id)
+	if err != nil {
+		return nil, err
+	}
+	downloadURL := g.DownloadURL
+	if downloadURL == "" {
+		downloadURL = defaultDownloadURL
+	}
+	resp, err := g.get(fmt.Sprintf("%s/%s/releases/download/v%s/%s", downloadURL, repo, v, url.PathEscape(filename)), false)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
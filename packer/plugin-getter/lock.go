@@ -0,0 +1,125 @@
+package plugingetter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// LockFileName is the name of the lock file, next to the configuration.
+const LockFileName = ".packer.lock.hcl"
+
+// LockFilePath returns the path of the lock file of the configuration in
+// dir.
+func LockFilePath(dir string) string {
+	return filepath.Join(dir, LockFileName)
+}
+
+const lockFileHeader = `# This file is maintained automatically by "packer init".
+# Manual edits may be lost in future updates.
+`
+
+// Lock records the versions of the plugins installed for a configuration,
+// so that the same versions are used everywhere.
+type Lock struct {
+	// Plugins are the locked plugins, by source.
+	Plugins map[string]*LockedPlugin
+}
+
+// LockedPlugin is a locked version of a plugin.
+type LockedPlugin struct {
+	Source  string `hcl:"source,label"`
+	Version string `hcl:"version"`
+	// Constraints are the version constraints the version was chosen
+	// with.
+	Constraints string `hcl:"constraints,optional"`
+	// Checksums are the checksums of the zip files of the plugin, by
+	// platform, like linux_amd64.
+	Checksums map[string]string `hcl:"checksums,optional"`
+}
+
+type lockFile struct {
+	Plugins []*LockedPlugin `hcl:"plugin,block"`
+}
+
+// ReadLockFile reads the lock file at path. The lock is empty when the file
+// doesn't exist.
+func ReadLockFile(path string) (*Lock, hcl.Diagnostics) {
+	lock := &Lock{Plugins: map[string]*LockedPlugin{}}
+	src, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Cannot read the lock file",
+			Detail:   err.Error(),
+		}}
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var lf lockFile
+	if diags := gohcl.DecodeBody(file.Body, nil, &lf); diags.HasErrors() {
+		return nil, diags
+	}
+	for _, p := range lf.Plugins {
+		if _, ok := lock.Plugins[p.Source]; ok {
+			return nil, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate plugin in the lock file",
+				Detail:   fmt.Sprintf("The plugin %s is locked twice in %s.", p.Source, path),
+			}}
+		}
+		lock.Plugins[p.Source] = p
+	}
+	return lock, nil
+}
+
+// Bytes returns the content of the lock file of l, with the plugins sorted
+// by source.
+func (l *Lock) Bytes() []byte {
+	sources := make([]string, 0, len(l.Plugins))
+	for source := range l.Plugins {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+	for i, source := range sources {
+		p := l.Plugins[source]
+		if i > 0 {
+			body.AppendNewline()
+		}
+		block := body.AppendNewBlock("plugin", []string{source}).Body()
+		block.SetAttributeValue("version", cty.StringVal(p.Version))
+		if p.Constraints != "" {
+			block.SetAttributeValue("constraints", cty.StringVal(p.Constraints))
+		}
+		if len(p.Checksums) > 0 {
+			checksums := map[string]cty.Value{}
+			for platform, sum := range p.Checksums {
+				checksums[platform] = cty.StringVal(sum)
+			}
+			block.SetAttributeValue("checksums", cty.MapVal(checksums))
+		}
+	}
+	return append([]byte(lockFileHeader+"\n"), f.Bytes()...)
+}
+
+// Write writes the lock file at path.
+func (l *Lock) Write(path string) error {
+	return ioutil.WriteFile(path, l.Bytes(), 0644)
+}
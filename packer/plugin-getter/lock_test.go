@@ -0,0 +1,51 @@
+package plugingetter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLock_roundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := LockFilePath(dir)
+
+	lock, diags := ReadLockFile(path)
+	if diags.HasErrors() || len(lock.Plugins) != 0 {
+		t.Fatalf("ReadLockFile of a missing file: %v %s", lock, diags)
+	}
+
+	lock.Plugins["github.com/hashicorp/amazon"] = &LockedPlugin{
+		Source:      "github.com/hashicorp/amazon",
+		Version:     "1.1.0",
+		Constraints: ">= 1.0.0",
+		Checksums: map[string]string{
+			"linux_amd64":  "sha256:1234",
+			"darwin_amd64": "sha256:5678",
+		},
+	}
+	lock.Plugins["github.com/hashicorp/docker"] = &LockedPlugin{
+		Source:  "github.com/hashicorp/docker",
+		Version: "0.1.0",
+	}
+	if err := lock.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != LockFileName {
+		t.Fatalf("unexpected lock file path %s", path)
+	}
+
+	got, diags := ReadLockFile(path)
+	if diags.HasErrors() {
+		t.Fatalf("ReadLockFile: %s", diags)
+	}
+	if !reflect.DeepEqual(got, lock) {
+		t.Fatalf("ReadLockFile: got %#v, want %#v", got, lock)
+	}
+}
@@ -0,0 +1,429 @@
+// Package plugingetter installs the plugins required by a configuration, in
+// versioned directories, and finds the installed ones.
+package plugingetter
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// checksumFileName is the name of the file holding the checksum of the zip
+// file an installation was extracted from.
+const checksumFileName = "SHA256SUM"
+
+// componentPrefixes are the prefixes of the names of the binaries of a
+// plugin.
+var componentPrefixes = []string{"packer-builder-", "packer-provisioner-", "packer-post-processor-"}
+
+var (
+	hostnameRe     = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+	identityPartRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9_-]*[a-z0-9])?$`)
+)
+
+// Identity identifies a plugin by its source address, like
+// github.com/hashicorp/amazon.
+type Identity struct {
+	Hostname  string
+	Namespace string
+	Type      string
+}
+
+// ParseSource parses the source address of a plugin, like
+// "github.com/hashicorp/amazon".
+func ParseSource(source string) (*Identity, error) {
+	parts := strings.Split(source, "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("The source %q must be like hostname/namespace/name, for example github.com/hashicorp/amazon", source)
+	}
+	if !strings.Contains(parts[0], ".") || !hostnameRe.MatchString(parts[0]) {
+		return nil, fmt.Errorf("The hostname %q of the source %q is not a domain name", parts[0], source)
+	}
+	for _, part := range parts[1:] {
+		if !identityPartRe.MatchString(part) {
+			return nil, fmt.Errorf("%q of the source %q can only contain lowercase letters, digits, '-' and '_'", part, source)
+		}
+	}
+	return &Identity{Hostname: parts[0], Namespace: parts[1], Type: parts[2]}, nil
+}
+
+func (i *Identity) String() string {
+	return strings.Join([]string{i.Hostname, i.Namespace, i.Type}, "/")
+}
+
+// Requirement is a plugin required by a configuration.
+type Requirement struct {
+	// Accessor is the name of the requirement in the configuration.
+	Accessor string
+	// Identifier is the source of the plugin.
+	Identifier *Identity
+	// VersionConstraints are the versions of the plugin that can be used;
+	// any version can be used when they are empty.
+	VersionConstraints version.Constraints
+}
+
+// Installation is an installed version of a plugin.
+type Installation struct {
+	Version *version.Version
+	// Dir is the directory of the binaries of the plugin.
+	Dir string
+	// Binaries are the paths of the binaries of the plugin, sorted.
+	Binaries []string
+	// Checksum is the checksum of the zip file the plugin was installed
+	// from, like "sha256:1234...", or empty when unknown.
+	Checksum string
+}
+
+// Platform returns the name of the platform of os and arch, like
+// linux_amd64.
+func Platform(os, arch string) string {
+	return os + "_" + arch
+}
+
+// ListInstallationsOptions tells where to look for installed plugins.
+type ListInstallationsOptions struct {
+	// PluginDirectories are the directories plugins are installed in, by
+	// order of preference.
+	PluginDirectories []string
+	// OS and ARCH are the platform of the binaries, the current one by
+	// default.
+	OS, ARCH string
+}
+
+func (opts *ListInstallationsOptions) platform() string {
+	goos, goarch := opts.OS, opts.ARCH
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return Platform(goos, goarch)
+}
+
+// pluginDir returns the directory of the installations of the plugin in dir.
+func (r *Requirement) pluginDir(dir string) string {
+	return filepath.Join(dir, r.Identifier.Hostname, r.Identifier.Namespace, r.Identifier.Type)
+}
+
+// ListInstallations returns the installations of the plugin matching its
+// version constraints, sorted by version. When a version is installed in
+// several directories, the one of the first directory is used.
+func (r *Requirement) ListInstallations(opts ListInstallationsOptions) ([]*Installation, error) {
+	platform := opts.platform()
+	byVersion := map[string]*Installation{}
+	for _, dir := range opts.PluginDirectories {
+		pluginDir := r.pluginDir(dir)
+		entries, err := ioutil.ReadDir(pluginDir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			v, err := version.NewVersion(e.Name())
+			if err != nil || !e.IsDir() {
+				log.Printf("[TRACE] ignoring %s: not a version directory", filepath.Join(pluginDir, e.Name()))
+				continue
+			}
+			if !r.VersionConstraints.Check(v) {
+				continue
+			}
+			if _, ok := byVersion[v.String()]; ok {
+				continue
+			}
+			install, err := readInstallation(filepath.Join(pluginDir, e.Name(), platform), v)
+			if err != nil {
+				return nil, err
+			}
+			if install != nil {
+				byVersion[v.String()] = install
+			}
+		}
+	}
+
+	installs := make([]*Installation, 0, len(byVersion))
+	for _, install := range byVersion {
+		installs = append(installs, install)
+	}
+	sort.Slice(installs, func(i, j int) bool {
+		return installs[i].Version.LessThan(installs[j].Version)
+	})
+	return installs, nil
+}
+
+// readInstallation returns the installation in dir, or nil when there is
+// none.
+func readInstallation(dir string, v *version.Version) (*Installation, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	install := &Installation{Version: v, Dir: dir}
+	for _, e := range entries {
+		if !e.IsDir() && isComponentBinary(e.Name()) {
+			install.Binaries = append(install.Binaries, filepath.Join(dir, e.Name()))
+		}
+	}
+	if len(install.Binaries) == 0 {
+		return nil, nil
+	}
+	if sum, err := ioutil.ReadFile(filepath.Join(dir, checksumFileName)); err == nil {
+		install.Checksum = strings.TrimSpace(string(sum))
+	}
+	return install, nil
+}
+
+func isComponentBinary(name string) bool {
+	for _, prefix := range componentPrefixes {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Getter gets the releases of plugins.
+type Getter interface {
+	// Releases returns the released versions of a plugin.
+	Releases(id *Identity) ([]*version.Version, error)
+	// Get returns a file of a release of a plugin, like its zip file or
+	// its checksums file.
+	Get(id *Identity, v *version.Version, filename string) (io.ReadCloser, error)
+}
+
+// InstallOptions are the options of InstallLatest.
+type InstallOptions struct {
+	// Getters are used in order, until one has the plugin.
+	Getters []Getter
+	// InDirectory is the plugin directory to install the plugin in.
+	InDirectory string
+	// OS and ARCH are the platform of the binaries to install, the current
+	// one by default.
+	OS, ARCH string
+	// Version, when set, is the version to install, like a locked version,
+	// instead of the latest one matching the constraints.
+	Version *version.Version
+	// Checksum, when set, is the checksum the zip file of the plugin must
+	// have, like a locked one.
+	Checksum string
+}
+
+// ZipFileName returns the name of the zip file of a release of a plugin for
+// a platform.
+func ZipFileName(id *Identity, v *version.Version, platform string) string {
+	return fmt.Sprintf("packer-plugin-%s_v%s_%s.zip", id.Type, v, platform)
+}
+
+// ChecksumsFileName returns the name of the file listing the SHA256
+// checksums of the files of a release of a plugin.
+func ChecksumsFileName(id *Identity, v *version.Version) string {
+	return fmt.Sprintf("packer-plugin-%s_v%s_SHA256SUMS", id.Type, v)
+}
+
+// InstallLatest installs the latest release of the plugin matching its
+// version constraints, unless it is already installed, and returns its
+// installation. The zip file of the release is checked against the
+// checksums file of the release.
+func (r *Requirement) InstallLatest(opts InstallOptions) (*Installation, error) {
+	listOpts := ListInstallationsOptions{OS: opts.OS, ARCH: opts.ARCH}
+	platform := listOpts.platform()
+
+	var errs []string
+	for _, getter := range opts.Getters {
+		v := opts.Version
+		if v == nil {
+			releases, err := getter.Releases(r.Identifier)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			v = r.latest(releases)
+			if v == nil {
+				errs = append(errs, fmt.Sprintf("no release of %s matches the version constraints %q", r.Identifier, r.VersionConstraints))
+				continue
+			}
+		}
+
+		dir := filepath.Join(r.pluginDir(opts.InDirectory), v.String(), platform)
+		install, err := readInstallation(dir, v)
+		if err != nil {
+			return nil, err
+		}
+		// An installation without its checksum can't be verified against the
+		// lock file, it is installed again.
+		if install != nil && (opts.Checksum == "" || install.Checksum == opts.Checksum) {
+			log.Printf("[TRACE] %s v%s is already installed in %s", r.Identifier, v, dir)
+			return install, nil
+		}
+
+		install, err = r.install(getter, v, platform, dir, opts.Checksum)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		return install, nil
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no getter can install %s", r.Identifier)
+	}
+	return nil, fmt.Errorf("could not install %s: %s", r.Identifier, strings.Join(errs, "; "))
+}
+
+// latest returns the latest release matching the constraints, ignoring the
+// pre-releases.
+func (r *Requirement) latest(releases []*version.Version) *version.Version {
+	var latest *version.Version
+	for _, v := range releases {
+		if v.Prerelease() != "" || !r.VersionConstraints.Check(v) {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+	}
+	return latest
+}
+
+func (r *Requirement) install(getter Getter, v *version.Version, platform, dir, wantChecksum string) (*Installation, error) {
+	zipName := ZipFileName(r.Identifier, v, platform)
+	published, err := r.publishedChecksum(getter, v, zipName)
+	if err != nil {
+		return nil, err
+	}
+	if wantChecksum != "" && wantChecksum != published {
+		return nil, fmt.Errorf("the checksum of %s is %s, but %s was expected from the lock file", zipName, published, wantChecksum)
+	}
+
+	body, err := getter.Get(r.Identifier, v, zipName)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	tmp, err := ioutil.TempFile("", "packer-plugin")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), body)
+	if err != nil {
+		return nil, fmt.Errorf("could not download %s: %s", zipName, err)
+	}
+	checksum := "sha256:" + hex.EncodeToString(h.Sum(nil))
+	if checksum != published {
+		return nil, fmt.Errorf("the checksum of the downloaded %s is %s, but %s is published", zipName, checksum, published)
+	}
+
+	if err := extract(tmp, size, dir, checksum); err != nil {
+		return nil, fmt.Errorf("could not install %s: %s", zipName, err)
+	}
+	install, err := readInstallation(dir, v)
+	if err != nil {
+		return nil, err
+	}
+	if install == nil {
+		return nil, fmt.Errorf("%s has no plugin binary", zipName)
+	}
+	return install, nil
+}
+
+// publishedChecksum returns the checksum of the file called name in the
+// checksums file of the release.
+func (r *Requirement) publishedChecksum(getter Getter, v *version.Version, name string) (string, error) {
+	sumsName := ChecksumsFileName(r.Identifier, v)
+	sums, err := getter.Get(r.Identifier, v, sumsName)
+	if err != nil {
+		return "", err
+	}
+	defer sums.Close()
+
+	scanner := bufio.NewScanner(sums)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			if _, err := hex.DecodeString(fields[0]); err != nil || len(fields[0]) != sha256.Size*2 {
+				return "", fmt.Errorf("%s: bad checksum %q for %s", sumsName, fields[0], name)
+			}
+			return "sha256:" + strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s has no checksum for %s", sumsName, name)
+}
+
+// extract extracts the plugin binaries of a zip file into dir, replacing
+// its content.
+func extract(f *os.File, size int64, dir, checksum string) error {
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	// Binaries are extracted next to dir first, so that a failure doesn't
+	// leave a partial installation.
+	tmpDir, err := ioutil.TempDir(filepath.Dir(dir), ".installing-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, zf := range zr.File {
+		name := filepath.Base(zf.Name)
+		if zf.FileInfo().IsDir() || name != zf.Name || !isComponentBinary(name) {
+			log.Printf("[TRACE] ignoring %s of the plugin zip file", zf.Name)
+			continue
+		}
+		if err := extractFile(zf, filepath.Join(tmpDir, name)); err != nil {
+			return err
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, checksumFileName), []byte(checksum+"\n"), 0644); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(tmpDir, dir)
+}
+
+func extractFile(zf *zip.File, path string) error {
+	r, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,243 @@
+package plugingetter
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+)
+
+// mockGetter serves the files of releases from memory.
+type mockGetter struct {
+	releases []string
+	// files are the files of the releases, by version then name.
+	files map[string]map[string][]byte
+}
+
+func (g *mockGetter) Releases(id *Identity) ([]*version.Version, error) {
+	var versions []*version.Version
+	for _, r := range g.releases {
+		versions = append(versions, version.Must(version.NewVersion(r)))
+	}
+	return versions, nil
+}
+
+func (g *mockGetter) Get(id *Identity, v *version.Version, filename string) (io.ReadCloser, error) {
+	b, ok := g.files[v.String()][filename]
+	if !ok {
+		return nil, fmt.Errorf("%s v%s has no %s", id, v, filename)
+	}
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (g *mockGetter) addRelease(t *testing.T, id *Identity, v, platform string, binaries ...string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, bin := range binaries {
+		w, err := zw.Create(bin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(w, "%s v%s", bin, v)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ver := version.Must(version.NewVersion(v))
+	zipName := ZipFileName(id, ver, platform)
+	sum := sha256.Sum256(buf.Bytes())
+	if g.files == nil {
+		g.files = map[string]map[string][]byte{}
+	}
+	g.files[ver.String()] = map[string][]byte{
+		zipName:                    buf.Bytes(),
+		ChecksumsFileName(id, ver): []byte(hex.EncodeToString(sum[:]) + "  " + zipName + "\n"),
+	}
+	g.releases = append(g.releases, v)
+}
+
+func TestParseSource(t *testing.T) {
+	for source, wantErr := range map[string]bool{
+		"github.com/hashicorp/amazon":   false,
+		"example.com/my-org/my_plugin":  false,
+		"amazon":                        true,
+		"hashicorp/amazon":              true,
+		"github/hashicorp/amazon":       true,
+		"github.com/HashiCorp/amazon":   true,
+		"github.com/hashicorp/amazon/x": true,
+	} {
+		id, err := ParseSource(source)
+		if (err != nil) != wantErr {
+			t.Errorf("ParseSource(%q): unexpected error %v", source, err)
+			continue
+		}
+		if err == nil && id.String() != source {
+			t.Errorf("ParseSource(%q).String() = %q", source, id)
+		}
+	}
+}
+
+func TestRequirement_InstallLatest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	id, _ := ParseSource("github.com/hashicorp/amazon")
+	platform := Platform("linux", "amd64")
+	getter := &mockGetter{}
+	getter.addRelease(t, id, "1.0.0", platform, "packer-builder-amazon-ebs", "README.md")
+	getter.addRelease(t, id, "1.1.0", platform, "packer-builder-amazon-ebs", "packer-post-processor-amazon-import")
+	getter.addRelease(t, id, "1.2.0-beta", platform, "packer-builder-amazon-ebs")
+	getter.addRelease(t, id, "2.0.0", platform, "packer-builder-amazon-ebs")
+
+	constraints, err := version.NewConstraint("< 2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &Requirement{
+		Accessor:           "amazon",
+		Identifier:         id,
+		VersionConstraints: constraints,
+	}
+	opts := InstallOptions{
+		Getters:     []Getter{getter},
+		InDirectory: dir,
+		OS:          "linux",
+		ARCH:        "amd64",
+	}
+	install, err := req.InstallLatest(opts)
+	if err != nil {
+		t.Fatalf("InstallLatest: %s", err)
+	}
+	wantDir := filepath.Join(dir, "github.com", "hashicorp", "amazon", "1.1.0", platform)
+	if install.Version.String() != "1.1.0" || install.Dir != wantDir || len(install.Binaries) != 2 {
+		t.Fatalf("unexpected installation %#v", install)
+	}
+	if !strings.HasPrefix(install.Checksum, "sha256:") {
+		t.Fatalf("unexpected checksum %q", install.Checksum)
+	}
+
+	// The locked version is installed, when its checksum matches.
+	lockedOpts := opts
+	lockedOpts.Version = version.Must(version.NewVersion("1.0.0"))
+	lockedOpts.Checksum = "sha256:" + strings.Repeat("0", 64)
+	if _, err := req.InstallLatest(lockedOpts); err == nil || !strings.Contains(err.Error(), "lock file") {
+		t.Fatalf("InstallLatest: expected a checksum error, got %v", err)
+	}
+	lockedOpts.Checksum = ""
+	locked, err := req.InstallLatest(lockedOpts)
+	if err != nil {
+		t.Fatalf("InstallLatest: %s", err)
+	}
+	if len(locked.Binaries) != 1 {
+		t.Fatalf("only plugin binaries must be installed, got %q", locked.Binaries)
+	}
+
+	installs, err := req.ListInstallations(ListInstallationsOptions{
+		PluginDirectories: []string{dir},
+		OS:                "linux",
+		ARCH:              "amd64",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(installs) != 2 || installs[0].Version.String() != "1.0.0" || installs[1].Checksum != install.Checksum {
+		t.Fatalf("unexpected installations %#v", installs)
+	}
+}
+
+func TestRequirement_InstallLatest_badChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	id, _ := ParseSource("github.com/hashicorp/amazon")
+	platform := Platform("linux", "amd64")
+	getter := &mockGetter{}
+	getter.addRelease(t, id, "1.0.0", platform, "packer-builder-amazon-ebs")
+	v := version.Must(version.NewVersion("1.0.0"))
+	getter.files[v.String()][ZipFileName(id, v, platform)] = []byte("tampered")
+
+	req := &Requirement{Identifier: id}
+	_, err = req.InstallLatest(InstallOptions{
+		Getters:     []Getter{getter},
+		InDirectory: dir,
+		OS:          "linux",
+		ARCH:        "amd64",
+	})
+	if err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("InstallLatest: expected a checksum error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "github.com", "hashicorp", "amazon", "1.0.0", platform)); !os.IsNotExist(err) {
+		t.Fatalf("nothing must be installed: %v", err)
+	}
+}
+
+func TestRequirement_InstallLatest_missingChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	id, _ := ParseSource("github.com/hashicorp/amazon")
+	platform := Platform("linux", "amd64")
+	getter := &mockGetter{}
+	getter.addRelease(t, id, "1.0.0", platform, "packer-builder-amazon-ebs")
+
+	req := &Requirement{Identifier: id}
+	opts := InstallOptions{
+		Getters:     []Getter{getter},
+		InDirectory: dir,
+		OS:          "linux",
+		ARCH:        "amd64",
+		Version:     version.Must(version.NewVersion("1.0.0")),
+	}
+	install, err := req.InstallLatest(opts)
+	if err != nil {
+		t.Fatalf("InstallLatest: %s", err)
+	}
+
+	// An installation without its checksum, like one tampered with, doesn't
+	// match the checksum of the lock file: it is installed again.
+	if err := os.Remove(filepath.Join(install.Dir, checksumFileName)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(install.Binaries[0], []byte("tampered"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	opts.Checksum = install.Checksum
+	reinstall, err := req.InstallLatest(opts)
+	if err != nil {
+		t.Fatalf("InstallLatest: %s", err)
+	}
+	if reinstall.Checksum != install.Checksum {
+		t.Fatalf("the plugin should be installed again, got checksum %q", reinstall.Checksum)
+	}
+	if b, err := ioutil.ReadFile(reinstall.Binaries[0]); err != nil || string(b) != "packer-builder-amazon-ebs v1.0.0" {
+		t.Fatalf("the binary should be installed again, got %q, %v", b, err)
+	}
+
+	// It isn't used when the release doesn't match the lock file either.
+	if err := os.Remove(filepath.Join(install.Dir, checksumFileName)); err != nil {
+		t.Fatal(err)
+	}
+	opts.Checksum = "sha256:" + strings.Repeat("0", 64)
+	if _, err := req.InstallLatest(opts); err == nil || !strings.Contains(err.Error(), "lock file") {
+		t.Fatalf("InstallLatest: expected a checksum error, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 )
 
@@ -31,6 +32,17 @@ func (b *cmdBuilder) Prepare(config ...interface{}) ([]string, []string, error)
 	return b.builder.Prepare(config...)
 }
 
+func (b *cmdBuilder) Deprecations() []config.Deprecation {
+	if r, ok := b.builder.(packer.DeprecationReporter); ok {
+		return r.Deprecations()
+	}
+	return nil
+}
+
+func (b *cmdBuilder) PlannedResources() []string {
+	return packer.PlannedResources(b.builder)
+}
+
 func (b *cmdBuilder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	defer func() {
 		r := recover()
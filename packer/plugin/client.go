@@ -11,12 +11,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
 	"unicode"
 
 	"github.com/hashicorp/packer/packer"
+	packergrpc "github.com/hashicorp/packer/packer/grpc"
 	packrpc "github.com/hashicorp/packer/packer/rpc"
 )
 
@@ -37,6 +39,9 @@ type Client struct {
 	doneLogging chan struct{}
 	l           sync.Mutex
 	address     net.Addr
+	// protocol is the protocol the plugin serves its components with.
+	protocol   string
+	grpcClient *packergrpc.Client
 }
 
 // ClientConfig is the configuration used to initialize a new
@@ -131,6 +136,13 @@ func (c *Client) Exited() bool {
 // Returns a builder implementation that is communicating over this
 // client. If the client hasn't been started, this will start it.
 func (c *Client) Builder() (packer.Builder, error) {
+	if grpcClient, err := c.packergrpcClient(); err != nil || grpcClient != nil {
+		if err != nil {
+			return nil, err
+		}
+		return &cmdBuilder{grpcClient.Builder(), c}, nil
+	}
+
 	client, err := c.packrpcClient()
 	if err != nil {
 		return nil, err
@@ -142,6 +154,10 @@ func (c *Client) Builder() (packer.Builder, error) {
 // Returns a hook implementation that is communicating over this
 // client. If the client hasn't been started, this will start it.
 func (c *Client) Hook() (packer.Hook, error) {
+	if c.protocol == ProtocolGRPC {
+		return nil, errors.New("hooks can only be served over net/rpc")
+	}
+
 	client, err := c.packrpcClient()
 	if err != nil {
 		return nil, err
@@ -153,6 +169,13 @@ func (c *Client) Hook() (packer.Hook, error) {
 // Returns a post-processor implementation that is communicating over
 // this client. If the client hasn't been started, this will start it.
 func (c *Client) PostProcessor() (packer.PostProcessor, error) {
+	if grpcClient, err := c.packergrpcClient(); err != nil || grpcClient != nil {
+		if err != nil {
+			return nil, err
+		}
+		return &cmdPostProcessor{grpcClient.PostProcessor(), c}, nil
+	}
+
 	client, err := c.packrpcClient()
 	if err != nil {
 		return nil, err
@@ -164,6 +187,13 @@ func (c *Client) PostProcessor() (packer.PostProcessor, error) {
 // Returns a provisioner implementation that is communicating over this
 // client. If the client hasn't been started, this will start it.
 func (c *Client) Provisioner() (packer.Provisioner, error) {
+	if grpcClient, err := c.packergrpcClient(); err != nil || grpcClient != nil {
+		if err != nil {
+			return nil, err
+		}
+		return &cmdProvisioner{grpcClient.Provisioner(), c}, nil
+	}
+
 	client, err := c.packrpcClient()
 	if err != nil {
 		return nil, err
@@ -185,6 +215,9 @@ func (c *Client) Kill() {
 		return
 	}
 
+	if c.grpcClient != nil {
+		c.grpcClient.Close()
+	}
 	cmd.Process.Kill()
 
 	// Wait for the client to finish logging so we have a complete log
@@ -211,6 +244,7 @@ func (c *Client) Start() (addr net.Addr, err error) {
 		fmt.Sprintf("%s=%s", MagicCookieKey, MagicCookieValue),
 		fmt.Sprintf("PACKER_PLUGIN_MIN_PORT=%d", c.config.MinPort),
 		fmt.Sprintf("PACKER_PLUGIN_MAX_PORT=%d", c.config.MaxPort),
+		fmt.Sprintf("%s=%s,%s", ProtocolsEnvVar, ProtocolGRPC, ProtocolNetRPC),
 	}
 
 	stdout_r, stdout_w := io.Pipe()
@@ -311,7 +345,7 @@ func (c *Client) Start() (addr net.Addr, err error) {
 		// Trim the line and split by "|" in order to get the parts of
 		// the output.
 		line := strings.TrimSpace(string(lineBytes))
-		parts := strings.SplitN(line, "|", 3)
+		parts := strings.SplitN(line, "|", 4)
 		if len(parts) < 3 {
 			err = fmt.Errorf("Unrecognized remote plugin message: %s", line)
 			return
@@ -324,6 +358,17 @@ func (c *Client) Start() (addr net.Addr, err error) {
 			return
 		}
 
+		// Plugins that don't announce a protocol are served over net/rpc,
+		// like the ones built before the gRPC protocol.
+		c.protocol = ProtocolNetRPC
+		if len(parts) == 4 {
+			c.protocol = parts[3]
+		}
+		if c.protocol != ProtocolNetRPC && c.protocol != ProtocolGRPC {
+			err = fmt.Errorf("Unknown plugin protocol: %s", c.protocol)
+			return
+		}
+
 		switch parts[1] {
 		case "tcp":
 			addr, err = net.ResolveTCPAddr("tcp", parts[2])
@@ -368,6 +413,44 @@ func (c *Client) logStderr(r io.Reader) {
 	close(c.doneLogging)
 }
 
+// packergrpcClient returns the gRPC client of the plugin, or nil when the
+// plugin is served over net/rpc.
+func (c *Client) packergrpcClient() (*packergrpc.Client, error) {
+	addr, err := c.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	c.l.Lock()
+	defer c.l.Unlock()
+	if c.protocol != ProtocolGRPC {
+		return nil, nil
+	}
+	if c.grpcClient == nil {
+		// The plugin calls the objects Packer passes to it, like the Ui of
+		// the build, on this listener.
+		l, err := callbackListener()
+		if err != nil {
+			return nil, err
+		}
+		c.grpcClient, err = packergrpc.Dial(addr, l)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	return c.grpcClient, nil
+}
+
+// callbackListener returns the listener on which Packer serves the objects it
+// passes to a gRPC plugin.
+func callbackListener() (net.Listener, error) {
+	if runtime.GOOS == "windows" {
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+	return serverListener_unix()
+}
+
 func (c *Client) packrpcClient() (*packrpc.Client, error) {
 	addr, err := c.Start()
 	if err != nil {
@@ -0,0 +1,118 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template"
+)
+
+// TestGRPC_jsonTemplate builds a JSON template with components served over
+// gRPC by plugin processes, like the built-in components of Packer.
+func TestGRPC_jsonTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-plugin-grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	manifestPath := filepath.Join(dir, "manifest.json")
+
+	tpl, err := template.Parse(strings.NewReader(fmt.Sprintf(`{
+		"builders": [{"type": "null", "communicator": "none"}],
+		"provisioners": [{"type": "shell-local", "inline": ["echo hello from {{ build_name }}"]}],
+		"post-processors": [{"type": "manifest", "output": %q}]
+	}`, manifestPath)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var clients []*Client
+	defer func() {
+		for _, c := range clients {
+			c.Kill()
+		}
+	}()
+	start := func(name string) *Client {
+		c := NewClient(&ClientConfig{Cmd: helperProcess(name)})
+		clients = append(clients, c)
+		return c
+	}
+	core := packer.NewCore(&packer.CoreConfig{
+		Template: tpl,
+		Components: packer.ComponentFinder{
+			BuilderStore: packer.MapOfBuilder{
+				"null": func() (packer.Builder, error) { return start("grpc-builder").Builder() },
+			},
+			ProvisionerStore: packer.MapOfProvisioner{
+				"shell-local": func() (packer.Provisioner, error) { return start("grpc-provisioner").Provisioner() },
+			},
+			PostProcessorStore: packer.MapOfPostProcessor{
+				"manifest": func() (packer.PostProcessor, error) { return start("grpc-post-processor").PostProcessor() },
+			},
+		},
+	})
+	if err := core.Initialize(); err != nil {
+		t.Fatalf("Initialize: %s", err)
+	}
+	build, err := core.Build("null")
+	if err != nil {
+		t.Fatalf("Build: %s", err)
+	}
+	if _, err := build.Prepare(); err != nil {
+		t.Fatalf("Prepare: %s", err)
+	}
+
+	var out bytes.Buffer
+	ui := &packer.BasicUi{Reader: new(bytes.Buffer), Writer: &out, ErrorWriter: &out}
+	artifacts, err := build.Run(context.Background(), ui)
+	if err != nil {
+		t.Fatalf("Run: %s\n%s", err, out.String())
+	}
+	if len(artifacts) == 0 {
+		t.Fatal("no artifacts")
+	}
+	if !strings.Contains(out.String(), "hello from null") {
+		t.Fatalf("the provisioner didn't run:\n%s", out.String())
+	}
+	manifest, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("the post-processor didn't run: %s", err)
+	}
+	if !strings.Contains(string(manifest), `"builder_type": "null"`) {
+		t.Fatalf("bad manifest:\n%s", manifest)
+	}
+}
+
+func TestGRPC_builderDeprecations(t *testing.T) {
+	c := NewClient(&ClientConfig{Cmd: helperProcess("grpc-builder")})
+	defer c.Kill()
+	b, err := c.Builder()
+	if err != nil {
+		t.Fatalf("Builder: %s", err)
+	}
+
+	raw := map[string]interface{}{
+		"communicator":     "ssh",
+		"ssh_host":         "127.0.0.1",
+		"ssh_username":     "packer",
+		"ssh_password":     "packer",
+		"ssh_wait_timeout": "5m",
+	}
+	if _, _, err := b.Prepare(raw); err != nil {
+		t.Fatalf("Prepare: %s", err)
+	}
+	expected := []config.Deprecation{{Option: "ssh_wait_timeout", Replacement: "ssh_timeout"}}
+	got := b.(packer.DeprecationReporter).Deprecations()
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected deprecations %#v, got %#v", expected, got)
+	}
+}
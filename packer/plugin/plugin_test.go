@@ -8,7 +8,10 @@ import (
 	"testing"
 	"time"
 
+	nullbuilder "github.com/hashicorp/packer/builder/null"
 	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/post-processor/manifest"
+	shelllocal "github.com/hashicorp/packer/provisioner/shell-local"
 )
 
 func helperProcess(s ...string) *exec.Cmd {
@@ -62,6 +65,21 @@ func TestHelperProcess(*testing.T) {
 		}
 		server.RegisterBuilder(new(packer.MockBuilder))
 		server.Serve()
+	case "grpc-builder":
+		if err := Serve(Components{Builder: new(nullbuilder.Builder)}); err != nil {
+			log.Printf("[ERR] %s", err)
+			os.Exit(1)
+		}
+	case "grpc-post-processor":
+		if err := Serve(Components{PostProcessor: new(manifest.PostProcessor)}); err != nil {
+			log.Printf("[ERR] %s", err)
+			os.Exit(1)
+		}
+	case "grpc-provisioner":
+		if err := Serve(Components{Provisioner: new(shelllocal.Provisioner)}); err != nil {
+			log.Printf("[ERR] %s", err)
+			os.Exit(1)
+		}
 	case "hook":
 		server, err := Server()
 		if err != nil {
@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 )
 
@@ -31,6 +32,17 @@ func (c *cmdPostProcessor) Configure(config ...interface{}) error {
 	return c.p.Configure(config...)
 }
 
+func (c *cmdPostProcessor) Deprecations() []config.Deprecation {
+	if r, ok := c.p.(packer.DeprecationReporter); ok {
+		return r.Deprecations()
+	}
+	return nil
+}
+
+func (c *cmdPostProcessor) PlannedResources() []string {
+	return packer.PlannedResources(c.p)
+}
+
 func (c *cmdPostProcessor) PostProcess(ctx context.Context, ui packer.Ui, a packer.Artifact) (packer.Artifact, bool, bool, error) {
 	defer func() {
 		r := recover()
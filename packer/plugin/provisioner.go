@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 )
 
@@ -31,6 +32,13 @@ func (c *cmdProvisioner) Prepare(configs ...interface{}) error {
 	return c.p.Prepare(configs...)
 }
 
+func (c *cmdProvisioner) Deprecations() []config.Deprecation {
+	if r, ok := c.p.(packer.DeprecationReporter); ok {
+		return r.Deprecations()
+	}
+	return nil
+}
+
 func (c *cmdProvisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.Communicator, generatedData map[string]interface{}) error {
 	defer func() {
 		r := recover()
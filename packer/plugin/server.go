@@ -17,10 +17,13 @@ import (
 	"os/signal"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/hashicorp/packer/packer"
+	packergrpc "github.com/hashicorp/packer/packer/grpc"
 	packrpc "github.com/hashicorp/packer/packer/rpc"
 	"github.com/hashicorp/packer/packer/tmp"
 )
@@ -38,9 +41,83 @@ const MagicCookieValue = "d602bf8f470bc67ca7faa0386276bbdd4330efaf76d1a219cb4d69
 // know how to speak it.
 const APIVersion = "5"
 
-// Server waits for a connection to this plugin and returns a Packer
-// RPC server that you can use to register components and serve them.
-func Server() (*packrpc.Server, error) {
+// ProtocolsEnvVar is the environment variable Packer sets, when it starts a
+// plugin, to the comma separated list of the protocols it speaks.
+const ProtocolsEnvVar = "PACKER_PLUGIN_PROTOCOLS"
+
+const (
+	// ProtocolNetRPC is the net/rpc protocol, the only one spoken by old
+	// versions of Packer.
+	ProtocolNetRPC = "netrpc"
+	// ProtocolGRPC is the gRPC protocol: it is announced as the fourth
+	// field of the address line of the plugin.
+	ProtocolGRPC = "grpc"
+)
+
+// Components are the components a plugin binary serves; only one of them is
+// usually set.
+type Components struct {
+	Builder       packer.Builder
+	Provisioner   packer.Provisioner
+	PostProcessor packer.PostProcessor
+}
+
+// Serve serves the components of a plugin over gRPC when the Packer that
+// started the plugin speaks it, else over net/rpc.
+func Serve(c Components) error {
+	if !packerSpeaks(ProtocolGRPC) {
+		server, err := Server()
+		if err != nil {
+			return err
+		}
+		if c.Builder != nil {
+			server.RegisterBuilder(c.Builder)
+		}
+		if c.Provisioner != nil {
+			server.RegisterProvisioner(c.Provisioner)
+		}
+		if c.PostProcessor != nil {
+			server.RegisterPostProcessor(c.PostProcessor)
+		}
+		server.Serve()
+		return nil
+	}
+
+	listener, err := listen(ProtocolGRPC)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	server := packergrpc.NewServer()
+	if c.Builder != nil {
+		server.RegisterBuilder(c.Builder)
+	}
+	if c.Provisioner != nil {
+		server.RegisterProvisioner(c.Provisioner)
+	}
+	if c.PostProcessor != nil {
+		server.RegisterPostProcessor(c.PostProcessor)
+	}
+	eatInterrupts()
+
+	// Packer kills the plugin once it is done with it.
+	log.Println("Serving plugin over gRPC...")
+	return server.Serve(listener)
+}
+
+func packerSpeaks(protocol string) bool {
+	for _, p := range strings.Split(os.Getenv(ProtocolsEnvVar), ",") {
+		if strings.TrimSpace(p) == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// listen checks that the plugin was started by Packer, and outputs the
+// address of its listener for Packer to connect to it.
+func listen(protocol string) (net.Listener, error) {
 	if os.Getenv(MagicCookieKey) != MagicCookieValue {
 		return nil, errors.New(
 			"Please do not execute plugins directly. Packer will execute these for you.")
@@ -55,26 +132,25 @@ func Server() (*packrpc.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	defer listener.Close()
 
 	// Output the address to stdout
 	log.Printf("Plugin address: %s %s\n",
 		listener.Addr().Network(), listener.Addr().String())
-	fmt.Printf("%s|%s|%s\n",
+	line := fmt.Sprintf("%s|%s|%s",
 		APIVersion,
 		listener.Addr().Network(),
 		listener.Addr().String())
-	os.Stdout.Sync()
-
-	// Accept a connection
-	log.Println("Waiting for connection...")
-	conn, err := listener.Accept()
-	if err != nil {
-		log.Printf("Error accepting connection: %s\n", err.Error())
-		return nil, err
+	if protocol != ProtocolNetRPC {
+		line += "|" + protocol
 	}
+	fmt.Println(line)
+	os.Stdout.Sync()
+	return listener, nil
+}
 
-	// Eat the interrupts
+// eatInterrupts ignores the interrupts: Packer cancels the plugin calls
+// itself.
+func eatInterrupts() {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
 	go func() {
@@ -85,6 +161,26 @@ func Server() (*packrpc.Server, error) {
 			log.Printf("Received interrupt signal (count: %d). Ignoring.", newCount)
 		}
 	}()
+}
+
+// Server waits for a connection to this plugin and returns a Packer
+// RPC server that you can use to register components and serve them.
+func Server() (*packrpc.Server, error) {
+	listener, err := listen(ProtocolNetRPC)
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+
+	// Accept a connection
+	log.Println("Waiting for connection...")
+	conn, err := listener.Accept()
+	if err != nil {
+		log.Printf("Error accepting connection: %s\n", err.Error())
+		return nil, err
+	}
+
+	eatInterrupts()
 
 	// Serve a single connection
 	log.Println("Serving a plugin connection...")
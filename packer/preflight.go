@@ -0,0 +1,12 @@
+package packer
+
+// The preflight modes of a build, in which the builder checks that its
+// credentials can perform all the operations of the build before launching
+// anything. They are passed to the builders in the PreflightConfigKey
+// configuration key; the builders without preflight checks ignore them.
+const (
+	// PreflightCheck checks the permissions before building.
+	PreflightCheck = "check"
+	// PreflightOnly checks the permissions and skips the build.
+	PreflightOnly = "only"
+)
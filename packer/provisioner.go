@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/helper/common"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer/otel"
 )
 
 // A provisioner is responsible for installing and configuring software
@@ -40,6 +44,14 @@ type ProvisionHook struct {
 	// The provisioners to run as part of the hook. These should already
 	// be prepared (by calling Prepare) at some earlier stage.
 	Provisioners []*HookedProvisioner
+
+	// Timeout bounds the time spent running all of the provisioners. Zero
+	// means no timeout.
+	Timeout time.Duration
+
+	// Breakpoints pause before the provisioners they match, called
+	// "provisioner.<type>".
+	Breakpoints []string
 }
 
 // BuilderDataCommonKeys is the list of common keys that all builder will
@@ -65,6 +77,13 @@ var BuilderDataCommonKeys = []string{
 	"SSHPublicKey",
 	"SSHPrivateKey",
 	"WinRMPassword",
+	// The facts of the guest detected once connected.
+	"OSFamily",
+	"OSName",
+	"OSVersion",
+	"OSArch",
+	"InitSystem",
+	"PackageManager",
 }
 
 // Provisioners interpolate most of their fields in the prepare stage; this
@@ -131,13 +150,48 @@ func (h *ProvisionHook) Run(ctx context.Context, name string, ui Ui, comm Commun
 				"`communicator` config was set to \"none\". If you have any provisioners\n" +
 				"then a communicator is required. Please fix this to continue.")
 	}
+
+	// The timings of the provisioners are reported to the ui of the build,
+	// when there is one.
+	timingsUi := ui
+	if timingsUi == nil {
+		timingsUi = &NoopUi{}
+	}
+
+	provCtx := ctx
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		provCtx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
 	for _, p := range h.Provisioners {
+		if stepName := "provisioner." + p.TypeName; MatchBreakpoint(h.Breakpoints, stepName) {
+			data := CastDataToMap(data)
+			describe := func() []string { return DescribeState(data) }
+			cancelled := func() bool { return ctx.Err() != nil }
+			if Breakpoint(ui, fmt.Sprintf("provisioner '%s'", p.TypeName), describe, cancelled) == BreakpointAbort {
+				return fmt.Errorf("Build aborted at the breakpoint before provisioner '%s'", p.TypeName)
+			}
+		}
+
 		ts := CheckpointReporter.AddSpan(p.TypeName, "provisioner", p.Config)
 
 		cast := CastDataToMap(data)
-		err := p.Provisioner.Provision(ctx, ui, comm, cast)
+		timingsUi.Machine(EventProvisionerStarted, p.TypeName)
+		start := time.Now()
+		spanCtx, span := otel.StartSpan(provCtx, "provisioner "+p.TypeName)
+		err := p.Provisioner.Provision(spanCtx, ui, comm, cast)
+		span.End(err)
+		timingsUi.Machine(EventProvisionerFinished, p.TypeName,
+			strconv.FormatFloat(time.Since(start).Seconds(), 'f', 3, 64))
+		otel.Record("packer.provisioner.duration", "s", span.Duration().Seconds(),
+			otel.String("packer.provisioner", p.TypeName))
 
 		ts.End(err)
+		if provCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return fmt.Errorf("Provisioning exceeded provision_timeout of %s", h.Timeout)
+		}
 		if err != nil {
 			return err
 		}
@@ -158,6 +212,9 @@ func (p *PausedProvisioner) FlatConfig() interface{}       { return p.FlatConfig
 func (p *PausedProvisioner) Prepare(raws ...interface{}) error {
 	return p.Provisioner.Prepare(raws...)
 }
+func (p *PausedProvisioner) Deprecations() []config.Deprecation {
+	return deprecations(p.Provisioner)
+}
 
 func (p *PausedProvisioner) Provision(ctx context.Context, ui Ui, comm Communicator, generatedData map[string]interface{}) error {
 
@@ -184,6 +241,9 @@ func (r *RetriedProvisioner) FlatConfig() interface{}       { return r.FlatConfi
 func (r *RetriedProvisioner) Prepare(raws ...interface{}) error {
 	return r.Provisioner.Prepare(raws...)
 }
+func (r *RetriedProvisioner) Deprecations() []config.Deprecation {
+	return deprecations(r.Provisioner)
+}
 
 func (r *RetriedProvisioner) Provision(ctx context.Context, ui Ui, comm Communicator, generatedData map[string]interface{}) error {
 	if ctx.Err() != nil { // context was cancelled
@@ -214,6 +274,94 @@ func (r *RetriedProvisioner) Provision(ctx context.Context, ui Ui, comm Communic
 	return err
 }
 
+// ErrorHandledProvisioner is a Provisioner implementation that decides what
+// to do when its provisioner fails, depending on OnFailure.
+type ErrorHandledProvisioner struct {
+	OnFailure   string
+	Provisioner Provisioner
+}
+
+func (p *ErrorHandledProvisioner) ConfigSpec() hcldec.ObjectSpec { return p.Provisioner.ConfigSpec() }
+func (p *ErrorHandledProvisioner) FlatConfig() interface{} {
+	if f, ok := p.Provisioner.(interface{ FlatConfig() interface{} }); ok {
+		return f.FlatConfig()
+	}
+	return nil
+}
+func (p *ErrorHandledProvisioner) Prepare(raws ...interface{}) error {
+	return p.Provisioner.Prepare(raws...)
+}
+func (p *ErrorHandledProvisioner) Deprecations() []config.Deprecation {
+	return deprecations(p.Provisioner)
+}
+
+func (p *ErrorHandledProvisioner) Provision(ctx context.Context, ui Ui, comm Communicator, generatedData map[string]interface{}) error {
+	for {
+		err := p.Provisioner.Provision(ctx, ui, comm, generatedData)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		onFailure := p.OnFailure
+		if onFailure == OnFailureAsk {
+			onFailure = askOnFailure(ctx, ui, err)
+			if onFailure == onFailureRetry {
+				continue
+			}
+		}
+
+		switch onFailure {
+		case OnFailureContinue:
+			ui.Error(fmt.Sprintf("Provisioner failed, continuing: %s", err))
+			return nil
+		case OnFailureAbort:
+			return &AbortError{Err: err}
+		default:
+			return err
+		}
+	}
+}
+
+// onFailureRetry is only an answer to the ask prompt.
+const onFailureRetry = "retry"
+
+func askOnFailure(ctx context.Context, ui Ui, err error) string {
+	ui.Error(fmt.Sprintf("Provisioner failed: %s", err))
+
+	result := make(chan string, 1)
+	go func() {
+		for {
+			line, err := ui.Ask("[c] Clean up and exit, [a] abort without cleanup, [r] retry or [i] ignore the error and continue?")
+			if err != nil {
+				log.Printf("Error asking for input: %s", err)
+			}
+
+			input := strings.ToLower(line) + "c"
+			switch input[0] {
+			case 'c':
+				result <- OnFailureCleanup
+			case 'a':
+				result <- OnFailureAbort
+			case 'r':
+				result <- onFailureRetry
+			case 'i':
+				result <- OnFailureContinue
+			default:
+				ui.Say(fmt.Sprintf("Incorrect input: %#v", line))
+				continue
+			}
+			return
+		}
+	}()
+
+	select {
+	case r := <-result:
+		return r
+	case <-ctx.Done():
+		return OnFailureCleanup
+	}
+}
+
 // DebuggedProvisioner is a Provisioner implementation that waits until a key
 // press before the provisioner is actually run.
 type DebuggedProvisioner struct {
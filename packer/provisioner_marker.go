@@ -0,0 +1,133 @@
+package packer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/packer/common/shellquote"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/masterzen/winrm"
+)
+
+// MarkedProvisioner is a Provisioner implementation that only runs its
+// provisioner when the idempotency marker of the guest doesn't exist, and
+// records it once the provisioner succeeded, so that a build resumed or run
+// again against a checkpointed guest skips the provisioners that already
+// ran. The marker is a file of the guest, or a registry key on Windows
+// guests.
+type MarkedProvisioner struct {
+	Provisioner
+	Marker string
+}
+
+func (p *MarkedProvisioner) Deprecations() []config.Deprecation {
+	return deprecations(p.Provisioner)
+}
+
+func (p *MarkedProvisioner) Provision(ctx context.Context, ui Ui, comm Communicator, generatedData map[string]interface{}) error {
+	marker := &idempotencyMarker{
+		path:    p.Marker,
+		windows: remoteWindows(generatedData),
+	}
+	exists, err := marker.exists(ctx, comm)
+	if err != nil {
+		return fmt.Errorf("failed to check the idempotency marker %s: %s", p.Marker, err)
+	}
+	if exists {
+		ui.Say(fmt.Sprintf("Skipping the next provisioner, its idempotency marker %s exists", p.Marker))
+		return nil
+	}
+
+	if err := p.Provisioner.Provision(ctx, ui, comm, generatedData); err != nil {
+		return err
+	}
+
+	if err := marker.record(ctx, comm); err != nil {
+		return fmt.Errorf("failed to record the idempotency marker %s: %s", p.Marker, err)
+	}
+	ui.Say(fmt.Sprintf("Recorded the idempotency marker %s", p.Marker))
+	return nil
+}
+
+// idempotencyMarker is the idempotency marker of a guest.
+type idempotencyMarker struct {
+	path    string
+	windows bool
+}
+
+// registryKey tells whether the marker is a registry key: a path of the HKLM:
+// or HKCU: drives of PowerShell, or starting with a HKEY_ hive.
+func (m *idempotencyMarker) registryKey() bool {
+	p := strings.ToUpper(m.path)
+	return m.windows && (strings.HasPrefix(p, "HKLM:") ||
+		strings.HasPrefix(p, "HKCU:") ||
+		strings.HasPrefix(p, "HKEY_"))
+}
+
+// psPath returns the PowerShell path of the marker.
+func (m *idempotencyMarker) psPath() string {
+	if strings.HasPrefix(strings.ToUpper(m.path), "HKEY_") {
+		return "Registry::" + m.path
+	}
+	return m.path
+}
+
+func (m *idempotencyMarker) exists(ctx context.Context, comm Communicator) (bool, error) {
+	command := fmt.Sprintf("if [ -e %s ]; then exit 0; else exit 1; fi", shellquote.Quote(m.path))
+	if m.windows {
+		command = winrm.Powershell(fmt.Sprintf(
+			"if (Test-Path -LiteralPath %s) { exit 0 } else { exit 1 }", powershellQuote(m.psPath())))
+	}
+	status, err := m.run(ctx, comm, command)
+	if err != nil {
+		return false, err
+	}
+	switch status {
+	case 0:
+		return true, nil
+	case 1:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%q exited with status %d", command, status)
+	}
+}
+
+func (m *idempotencyMarker) record(ctx context.Context, comm Communicator) error {
+	command := fmt.Sprintf(`mkdir -p "$(dirname %[1]s)" && date -u > %[1]s`, shellquote.Quote(m.path))
+	if m.windows {
+		// Registry keys have no item type, files are created with their
+		// parent directories.
+		itemType := " -ItemType File"
+		if m.registryKey() {
+			itemType = ""
+		}
+		command = winrm.Powershell(fmt.Sprintf(
+			"$ErrorActionPreference = 'Stop'\nNew-Item -Path %s%s -Force | Out-Null",
+			powershellQuote(m.psPath()), itemType))
+	}
+	status, err := m.run(ctx, comm, command)
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		return fmt.Errorf("%q exited with status %d", command, status)
+	}
+	return nil
+}
+
+// run runs command and returns its exit status, with its error output as the
+// error when the status is neither 0 nor 1, the answers of the checks.
+func (m *idempotencyMarker) run(ctx context.Context, comm Communicator, command string) (int, error) {
+	var stderr bytes.Buffer
+	cmd := &RemoteCmd{Command: command, Stderr: &stderr}
+	if err := comm.Start(ctx, cmd); err != nil {
+		return 0, err
+	}
+	status := cmd.Wait()
+	if status > 1 && stderr.Len() > 0 {
+		return status, fmt.Errorf("exited with status %d: %s", status, strings.TrimSpace(stderr.String()))
+	}
+	return status, nil
+}
@@ -0,0 +1,108 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// markerCommunicator is a communicator whose guest has the idempotency
+// markers of markers, recording the ones created.
+type markerCommunicator struct {
+	MockCommunicator
+	markers  map[string]bool
+	commands []string
+}
+
+func (c *markerCommunicator) Start(ctx context.Context, rc *RemoteCmd) error {
+	c.commands = append(c.commands, rc.Command)
+	status := 0
+	for marker, exists := range c.markers {
+		if strings.Contains(rc.Command, "[ -e '"+marker+"' ]") && !exists {
+			status = 1
+		}
+		if strings.Contains(rc.Command, "> '"+marker+"'") {
+			c.markers[marker] = true
+		}
+	}
+	go rc.SetExited(status)
+	return nil
+}
+
+func TestMarkedProvisioner_impl(t *testing.T) {
+	var _ Provisioner = new(MarkedProvisioner)
+}
+
+func TestMarkedProvisionerProvision(t *testing.T) {
+	marker := "/var/lib/packer/base.done"
+	comm := &markerCommunicator{markers: map[string]bool{marker: false}}
+	data := map[string]interface{}{"ConnType": "ssh"}
+
+	mock := new(MockProvisioner)
+	prov := &MarkedProvisioner{Marker: marker, Provisioner: mock}
+	if err := prov.Provision(context.Background(), testUi(), comm, data); err != nil {
+		t.Fatal(err)
+	}
+	if !mock.ProvCalled {
+		t.Fatal("the provisioner should run without its marker")
+	}
+	if !comm.markers[marker] {
+		t.Fatalf("the marker should be recorded, commands: %q", comm.commands)
+	}
+
+	mock = new(MockProvisioner)
+	prov.Provisioner = mock
+	if err := prov.Provision(context.Background(), testUi(), comm, data); err != nil {
+		t.Fatal(err)
+	}
+	if mock.ProvCalled {
+		t.Fatal("the provisioner should be skipped once its marker exists")
+	}
+}
+
+func TestMarkedProvisionerProvision_failure(t *testing.T) {
+	marker := "/var/lib/packer/base.done"
+	comm := &markerCommunicator{markers: map[string]bool{marker: false}}
+	errTest := errors.New("provisioning failed")
+	mock := &MockProvisioner{ProvFunc: func(context.Context) error {
+		return errTest
+	}}
+	prov := &MarkedProvisioner{Marker: marker, Provisioner: mock}
+	if err := prov.Provision(context.Background(), testUi(), comm, nil); err != errTest {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if comm.markers[marker] {
+		t.Fatal("the marker should not be recorded when the provisioner fails")
+	}
+}
+
+func TestIdempotencyMarker_windows(t *testing.T) {
+	cases := []struct {
+		path     string
+		registry bool
+		psPath   string
+	}{
+		{`C:\ProgramData\packer\base.done`, false, `C:\ProgramData\packer\base.done`},
+		{`HKLM:\SOFTWARE\Packer\Base`, true, `HKLM:\SOFTWARE\Packer\Base`},
+		{`HKEY_LOCAL_MACHINE\SOFTWARE\Packer\Base`, true, `Registry::HKEY_LOCAL_MACHINE\SOFTWARE\Packer\Base`},
+	}
+	for _, tc := range cases {
+		m := &idempotencyMarker{path: tc.path, windows: true}
+		if m.registryKey() != tc.registry {
+			t.Fatalf("%s: registry key should be %t", tc.path, tc.registry)
+		}
+		if m.psPath() != tc.psPath {
+			t.Fatalf("%s: unexpected PowerShell path %s", tc.path, m.psPath())
+		}
+	}
+
+	comm := new(MockCommunicator)
+	m := &idempotencyMarker{path: `HKLM:\SOFTWARE\Packer\Base`, windows: true}
+	if err := m.record(context.Background(), comm); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(comm.StartCmd.Command, "powershell.exe ") {
+		t.Fatalf("the marker should be recorded with PowerShell: %q", comm.StartCmd.Command)
+	}
+}
@@ -1,9 +1,12 @@
 package packer
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -341,3 +344,146 @@ func TestRetriedProvisionerCancel(t *testing.T) {
 		t.Fatal("should have err")
 	}
 }
+
+func TestErrorHandledProvisioner_impl(t *testing.T) {
+	var _ Provisioner = new(ErrorHandledProvisioner)
+}
+
+func TestErrorHandledProvisioner_config(t *testing.T) {
+	mock := new(MockProvisioner)
+	prov := &ErrorHandledProvisioner{Provisioner: mock}
+
+	if !reflect.DeepEqual(prov.ConfigSpec(), mock.ConfigSpec()) {
+		t.Fatal("ConfigSpec should be the one of the provisioner")
+	}
+	if !reflect.DeepEqual(prov.FlatConfig(), mock.FlatConfig()) {
+		t.Fatal("FlatConfig should be the one of the provisioner")
+	}
+}
+
+func TestErrorHandledProvisionerProvision(t *testing.T) {
+	cases := []struct {
+		onFailure string
+		input     string
+		wantErr   bool
+		wantAbort bool
+		retried   bool
+	}{
+		{onFailure: OnFailureCleanup, wantErr: true},
+		{onFailure: OnFailureAbort, wantErr: true, wantAbort: true},
+		{onFailure: OnFailureContinue},
+		{onFailure: OnFailureAsk, input: "c\n", wantErr: true},
+		{onFailure: OnFailureAsk, input: "a\n", wantErr: true, wantAbort: true},
+		{onFailure: OnFailureAsk, input: "i\n"},
+		{onFailure: OnFailureAsk, input: "r\n", retried: true},
+	}
+
+	for _, tc := range cases {
+		mock := &MockProvisioner{
+			ProvFunc: func(ctx context.Context) error {
+				return errors.New("failed")
+			},
+		}
+		prov := &ErrorHandledProvisioner{
+			OnFailure:   tc.onFailure,
+			Provisioner: mock,
+		}
+
+		ui := testUi()
+		ui.TTY = &testTTY{say: tc.input}
+		err := prov.Provision(context.Background(), ui, new(MockCommunicator), make(map[string]interface{}))
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s %q: unexpected error: %v", tc.onFailure, tc.input, err)
+		}
+		if IsAbortError(err) != tc.wantAbort {
+			t.Fatalf("%s %q: unexpected abort error: %#v", tc.onFailure, tc.input, err)
+		}
+		if mock.ProvRetried != tc.retried {
+			t.Fatalf("%s %q: retried should be %t", tc.onFailure, tc.input, tc.retried)
+		}
+	}
+}
+
+func TestUserProvisioner_impl(t *testing.T) {
+	var _ Provisioner = new(UserProvisioner)
+}
+
+func TestUserProvisionerProvision(t *testing.T) {
+	mock := new(MockProvisioner)
+	prov := &UserProvisioner{
+		RemoteUser:  "app",
+		Provisioner: mock,
+	}
+
+	comm := &MockCommunicator{StartStdout: "/home/app\n"}
+	data := map[string]interface{}{"ConnType": "ssh"}
+	if err := prov.Provision(context.Background(), testUi(), comm, data); err != nil {
+		t.Fatal(err)
+	}
+	userComm := mock.ProvCommunicator
+
+	cmd := &RemoteCmd{Command: "echo 'hello'"}
+	if err := cmd.RunWithUi(context.Background(), userComm, testUi()); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `sudo -n -H -u app -- sh -c 'echo '"'"'hello'"'"''`; comm.StartCmd.Command != expected {
+		t.Fatalf("unexpected command %q, expected %q", comm.StartCmd.Command, expected)
+	}
+
+	if err := userComm.Upload("~/script.sh", strings.NewReader("data"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(comm.UploadPath, "/tmp/packer-remote-user-") || comm.UploadData != "data" {
+		t.Fatalf("unexpected upload of %q to %s", comm.UploadData, comm.UploadPath)
+	}
+	if !strings.Contains(comm.StartCmd.Command, "chown app: '\"'\"'/home/app/script.sh'\"'\"'") {
+		t.Fatalf("the upload should be owned by the user: %q", comm.StartCmd.Command)
+	}
+
+	if err := userComm.Download("/etc/hosts", new(bytes.Buffer)); err != nil {
+		t.Fatal(err)
+	}
+	if comm.DownloadPath != "/etc/hosts" {
+		t.Fatalf("unexpected download of %s", comm.DownloadPath)
+	}
+}
+
+func TestUserProvisionerProvision_windows(t *testing.T) {
+	mock := new(MockProvisioner)
+	prov := &UserProvisioner{
+		RemoteUser:  `DOMAIN\app`,
+		Provisioner: mock,
+	}
+	data := map[string]interface{}{"ConnType": "ssh", "OSFamily": "windows"}
+	if err := prov.Provision(context.Background(), testUi(), new(MockCommunicator), data); err == nil {
+		t.Fatal("should error without a password")
+	}
+
+	prov.RemotePassword = "s3cr3t"
+	comm := &MockCommunicator{StartStdout: `C:\Users\app` + "\r\n"}
+	if err := prov.Provision(context.Background(), testUi(), comm, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ProvCommunicator.UploadDir(`~\scripts`, "scripts", nil); err != nil {
+		t.Fatal(err)
+	}
+	if comm.UploadDirDst != `C:\Users\app\scripts` {
+		t.Fatalf("unexpected upload to %s", comm.UploadDirDst)
+	}
+	if !strings.HasPrefix(mock.ProvCommunicator.(*userCommunicator).command("dir"), "powershell.exe ") {
+		t.Fatal("the command should run with PowerShell")
+	}
+}
+
+func TestValidateRemoteUser(t *testing.T) {
+	for _, user := range []string{"", "app", "build-user", `DOMAIN\app`, "app@example.com"} {
+		if err := ValidateRemoteUser(user); err != nil {
+			t.Errorf("%q should be valid: %s", user, err)
+		}
+	}
+	for _, user := range []string{"app; rm -rf /", "$(id)", "a b", `a\b\c`} {
+		if err := ValidateRemoteUser(user); err == nil {
+			t.Errorf("%q should be invalid", user)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/hashicorp/packer/helper/config"
 )
 
 // TimeoutProvisioner is a Provisioner implementation that can timeout after a
@@ -13,6 +15,10 @@ type TimeoutProvisioner struct {
 	Timeout time.Duration
 }
 
+func (p *TimeoutProvisioner) Deprecations() []config.Deprecation {
+	return deprecations(p.Provisioner)
+}
+
 func (p *TimeoutProvisioner) Provision(ctx context.Context, ui Ui, comm Communicator, generatedData map[string]interface{}) error {
 	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
 	defer cancel()
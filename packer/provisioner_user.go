@@ -0,0 +1,258 @@
+package packer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/packer/common/shellquote"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/masterzen/winrm"
+)
+
+// remoteUserRe matches the valid remote users: a name, optionally prefixed
+// with a Windows domain.
+var remoteUserRe = regexp.MustCompile(`^([A-Za-z0-9._-]+\\)?[A-Za-z0-9._@-]+$`)
+
+// ValidateRemoteUser returns an error when user is not empty and is not a
+// valid user name.
+func ValidateRemoteUser(user string) error {
+	if user == "" || remoteUserRe.MatchString(user) {
+		return nil
+	}
+	return fmt.Errorf("remote_user must be a user name, optionally prefixed with a domain, got %q", user)
+}
+
+// UserProvisioner is a Provisioner implementation that runs the commands of
+// the provisioner as another user of the guest: with sudo on Unix guests,
+// and with the credentials of the user on Windows guests. The files
+// uploaded are owned by the user, and the destinations starting with ~ are
+// in the home directory of the user.
+type UserProvisioner struct {
+	Provisioner
+	RemoteUser string
+	// RemotePassword is the password of the user, required on Windows
+	// guests only.
+	RemotePassword string
+}
+
+func (p *UserProvisioner) Deprecations() []config.Deprecation {
+	return deprecations(p.Provisioner)
+}
+
+func (p *UserProvisioner) Provision(ctx context.Context, ui Ui, comm Communicator, generatedData map[string]interface{}) error {
+	windows := remoteWindows(generatedData)
+	if windows && p.RemotePassword == "" {
+		return fmt.Errorf("remote_password is required to run as %s on a Windows guest", p.RemoteUser)
+	}
+	if p.RemotePassword != "" {
+		LogSecretFilter.Set(p.RemotePassword)
+	}
+
+	ui.Say(fmt.Sprintf("Running the next provisioner as %s...", p.RemoteUser))
+	comm = &userCommunicator{
+		Communicator: comm,
+		user:         p.RemoteUser,
+		password:     p.RemotePassword,
+		windows:      windows,
+	}
+	return p.Provisioner.Provision(ctx, ui, comm, generatedData)
+}
+
+// remoteWindows tells whether the guest runs Windows, from its facts or,
+// when they are unknown, from the communicator.
+func remoteWindows(generatedData map[string]interface{}) bool {
+	if family, _ := generatedData["OSFamily"].(string); family != "" {
+		return family == "windows"
+	}
+	connType, _ := generatedData["ConnType"].(string)
+	return connType == "winrm"
+}
+
+// userCommunicator runs the commands and uploads the files of a
+// communicator as another user.
+type userCommunicator struct {
+	Communicator
+	user     string
+	password string
+	windows  bool
+
+	homeOnce sync.Once
+	home     string
+	homeErr  error
+}
+
+func (c *userCommunicator) Start(ctx context.Context, cmd *RemoteCmd) error {
+	cmd.Command = c.command(cmd.Command)
+	return c.Communicator.Start(ctx, cmd)
+}
+
+// command returns command run as the user.
+func (c *userCommunicator) command(command string) string {
+	if !c.windows {
+		return fmt.Sprintf("sudo -n -H -u %s -- sh -c %s", c.user, shellquote.Quote(command))
+	}
+
+	// The runas of PowerShell: the command runs with cmd, in a process
+	// started with the credentials of the user, and its output is relayed.
+	return winrm.Powershell(fmt.Sprintf(`$ErrorActionPreference = 'Stop'
+$password = ConvertTo-SecureString %s -AsPlainText -Force
+$credential = New-Object System.Management.Automation.PSCredential(%s, $password)
+$stdout = [IO.Path]::GetTempFileName()
+$stderr = [IO.Path]::GetTempFileName()
+$process = Start-Process -FilePath cmd.exe -ArgumentList %s -Credential $credential -WorkingDirectory $env:SystemRoot -LoadUserProfile -NoNewWindow -Wait -PassThru -RedirectStandardOutput $stdout -RedirectStandardError $stderr
+Get-Content $stdout
+Get-Content $stderr | ForEach-Object { [Console]::Error.WriteLine($_) }
+Remove-Item $stdout, $stderr
+exit $process.ExitCode
+`, powershellQuote(c.password), powershellQuote(c.user), powershellQuote(`/s /c "`+command+`"`)))
+}
+
+func (c *userCommunicator) Upload(dst string, r io.Reader, fi *os.FileInfo) error {
+	dst, err := c.resolve(dst)
+	if err != nil {
+		return err
+	}
+	if c.windows {
+		// The files uploaded to the profile of the user inherit its
+		// permissions.
+		return c.Communicator.Upload(dst, r, fi)
+	}
+
+	tmp, err := c.tempPath()
+	if err != nil {
+		return err
+	}
+	if err := c.Communicator.Upload(tmp, r, fi); err != nil {
+		return err
+	}
+	return c.sudo(fmt.Sprintf("cp %s %s && chown %s: %s",
+		shellquote.Quote(tmp), shellquote.Quote(dst), c.user, shellquote.Quote(dst)), tmp)
+}
+
+func (c *userCommunicator) UploadDir(dst string, src string, exclude []string) error {
+	dst, err := c.resolve(dst)
+	if err != nil {
+		return err
+	}
+	if c.windows {
+		return c.Communicator.UploadDir(dst, src, exclude)
+	}
+
+	tmp, err := c.tempPath()
+	if err != nil {
+		return err
+	}
+	if err := c.Communicator.UploadDir(tmp, src, exclude); err != nil {
+		return err
+	}
+	return c.sudo(fmt.Sprintf("chown -R %[1]s: %[2]s && { [ -d %[3]s ] || { mkdir -p %[3]s && chown %[1]s: %[3]s; }; } && cp -Rp %[2]s/. %[3]s",
+		c.user, shellquote.Quote(tmp), shellquote.Quote(dst)), tmp)
+}
+
+func (c *userCommunicator) Download(src string, w io.Writer) error {
+	src, err := c.resolve(src)
+	if err != nil {
+		return err
+	}
+	return c.Communicator.Download(src, w)
+}
+
+func (c *userCommunicator) DownloadDir(src string, dst string, exclude []string) error {
+	src, err := c.resolve(src)
+	if err != nil {
+		return err
+	}
+	return c.Communicator.DownloadDir(src, dst, exclude)
+}
+
+// sudo runs script as root, to move the files uploaded to tmp, and removes
+// tmp.
+func (c *userCommunicator) sudo(script string, tmp string) error {
+	cmd := &RemoteCmd{Command: fmt.Sprintf("sudo -n sh -c %s; status=$?; rm -rf %s; exit $status",
+		shellquote.Quote(script), shellquote.Quote(tmp))}
+	return c.run(cmd)
+}
+
+// run runs cmd as the bootstrap user and fails when it exits with a non-zero
+// status.
+func (c *userCommunicator) run(cmd *RemoteCmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := c.Communicator.Start(context.TODO(), cmd); err != nil {
+		return err
+	}
+	if status := cmd.Wait(); status != 0 {
+		return fmt.Errorf("%q exited with status %d: %s", cmd.Command, status, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// tempPath returns a temporary path of the guest for the files uploaded.
+func (c *userCommunicator) tempPath() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "/tmp/packer-remote-user-" + hex.EncodeToString(b), nil
+}
+
+// resolve returns path with a leading ~ replaced by the home directory of
+// the user.
+func (c *userCommunicator) resolve(path string) (string, error) {
+	rest := ""
+	switch {
+	case path == "~":
+	case strings.HasPrefix(path, "~/"), c.windows && strings.HasPrefix(path, `~\`):
+		rest = path[1:]
+	default:
+		return path, nil
+	}
+
+	c.homeOnce.Do(func() {
+		c.home, c.homeErr = c.resolveHome()
+	})
+	if c.homeErr != nil {
+		return "", fmt.Errorf("could not resolve the home directory of %s: %s", c.user, c.homeErr)
+	}
+	return c.home + rest, nil
+}
+
+// resolveHome returns the home directory of the user.
+func (c *userCommunicator) resolveHome() (string, error) {
+	var command string
+	if c.windows {
+		// The profile of the user exists once it logged in, otherwise it
+		// will be created in the default location.
+		command = winrm.Powershell(fmt.Sprintf(`$account = New-Object System.Security.Principal.NTAccount(%s)
+$sid = $account.Translate([System.Security.Principal.SecurityIdentifier]).Value
+$entry = Get-ItemProperty "HKLM:\SOFTWARE\Microsoft\Windows NT\CurrentVersion\ProfileList\$sid" -ErrorAction SilentlyContinue
+if ($entry) { $entry.ProfileImagePath } else { Join-Path (Split-Path $env:PUBLIC) %s }
+`, powershellQuote(c.user), powershellQuote(c.user[strings.LastIndex(c.user, `\`)+1:])))
+	} else {
+		command = fmt.Sprintf(`home=$(getent passwd %[1]s 2>/dev/null | cut -d: -f6); [ -n "$home" ] || home=$(echo ~%[1]s); echo "$home"`, c.user)
+	}
+
+	var stdout bytes.Buffer
+	cmd := &RemoteCmd{Command: command, Stdout: &stdout}
+	if err := c.run(cmd); err != nil {
+		return "", err
+	}
+	home := strings.TrimSpace(stdout.String())
+	if home == "" || strings.HasPrefix(home, "~") {
+		return "", fmt.Errorf("the user doesn't exist")
+	}
+	return home, nil
+}
+
+// powershellQuote quotes s for PowerShell.
+func powershellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,22 @@
+package packer
+
+// ResourcePlanner is implemented by the builders and post-processors
+// describing what they create when they run, for packer plan. They are
+// asked once prepared, so that the description follows their
+// configuration.
+type ResourcePlanner interface {
+	// PlannedResources returns the resources created when running, the
+	// temporary ones first, like "temporary EC2 instance" then "AMI". It
+	// returns an empty slice when nothing is created, and nil when the
+	// resources are unknown.
+	PlannedResources() []string
+}
+
+// PlannedResources returns the resources component c creates when it runs,
+// or nil when it doesn't describe them.
+func PlannedResources(c interface{}) []string {
+	if p, ok := c.(ResourcePlanner); ok {
+		return p.PlannedResources()
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 
+	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 )
 
@@ -11,6 +12,8 @@ import (
 // over an RPC connection.
 type builder struct {
 	commonClient
+	deprecations     []config.Deprecation
+	plannedResources []string
 }
 
 // BuilderServer wraps a packer.Builder implementation and makes it exportable
@@ -30,6 +33,8 @@ type BuilderPrepareArgs struct {
 type BuilderPrepareResponse struct {
 	GeneratedVars []string
 	Warnings      []string
+	Deprecations  []config.Deprecation
+	Planned       PlannedResources
 	Error         *BasicError
 }
 
@@ -47,10 +52,20 @@ func (b *builder) Prepare(config ...interface{}) ([]string, []string, error) {
 	if resp.Error != nil {
 		err = resp.Error
 	}
+	b.deprecations = resp.Deprecations
+	b.plannedResources = resp.Planned.resources()
 
 	return resp.GeneratedVars, resp.Warnings, err
 }
 
+func (b *builder) Deprecations() []config.Deprecation {
+	return b.deprecations
+}
+
+func (b *builder) PlannedResources() []string {
+	return b.plannedResources
+}
+
 func (b *builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
 	nextId := b.mux.NextId()
 	server := newServerWithMux(b.mux, nextId)
@@ -90,14 +105,16 @@ func (b *builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 }
 
 func (b *BuilderServer) Prepare(args *BuilderPrepareArgs, reply *BuilderPrepareResponse) error {
-	config, err := decodeCTYValues(args.Configs)
+	configs, err := decodeCTYValues(args.Configs)
 	if err != nil {
 		return err
 	}
-	generated, warnings, err := b.builder.Prepare(config...)
+	generated, warnings, err := b.builder.Prepare(configs...)
 	*reply = BuilderPrepareResponse{
 		GeneratedVars: generated,
 		Warnings:      warnings,
+		Deprecations:  config.ComponentDeprecations(b.builder, configs...),
+		Planned:       newPlannedResources(b.builder),
 		Error:         NewBasicError(err),
 	}
 	return nil
@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2/hcldec"
+	confighelper "github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 )
 
@@ -59,6 +61,73 @@ func TestBuilderPrepare_Warnings(t *testing.T) {
 	}
 }
 
+type plannedMockBuilder struct {
+	packer.MockBuilder
+	resources []string
+}
+
+func (b *plannedMockBuilder) PlannedResources() []string {
+	return b.resources
+}
+
+func TestBuilderPrepare_PlannedResources(t *testing.T) {
+	for _, b := range []packer.Builder{
+		&plannedMockBuilder{resources: []string{"temporary instance", "image"}},
+		&plannedMockBuilder{resources: []string{}},
+		new(packer.MockBuilder),
+	} {
+		client, server := testClientServer(t)
+		server.RegisterBuilder(b)
+		bClient := client.Builder()
+
+		if _, _, err := bClient.Prepare(nil); err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+		expected := packer.PlannedResources(b)
+		if got := packer.PlannedResources(bClient); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("expected planned resources %#v, got %#v", expected, got)
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+type deprecatedMockConfig struct {
+	Old string `mapstructure:"old" deprecated:"new"`
+}
+
+func (*deprecatedMockConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return nil
+}
+
+type deprecatedMockBuilder struct {
+	packer.MockBuilder
+	config deprecatedMockConfig
+}
+
+func TestBuilderPrepare_Deprecations(t *testing.T) {
+	b := new(deprecatedMockBuilder)
+	client, server := testClientServer(t)
+	defer client.Close()
+	defer server.Close()
+	server.RegisterBuilder(b)
+	bClient := client.Builder()
+
+	raw := map[string]interface{}{"old": "value"}
+	if _, _, err := bClient.Prepare(raw); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	// The builder only gets the configuration.
+	if len(b.PrepareConfig) != 1 {
+		t.Fatalf("bad: %#v", b.PrepareConfig)
+	}
+	expected := []confighelper.Deprecation{{Option: "old", Replacement: "new"}}
+	got := bClient.(packer.DeprecationReporter).Deprecations()
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected deprecations %#v, got %#v", expected, got)
+	}
+}
+
 func TestBuilderRun(t *testing.T) {
 	b := new(packer.MockBuilder)
 	client, server := testClientServer(t)
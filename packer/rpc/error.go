@@ -1,11 +1,17 @@
 package rpc
 
+import "github.com/hashicorp/packer/packer"
+
 // This is a type that wraps error types so that they can be messaged
 // across RPC channels. Since "error" is an interface, we can't always
 // gob-encode the underlying structure. This is a valid error interface
 // implementer that we will push across.
 type BasicError struct {
 	Message string
+
+	// Abort is set when the wrapped error asked to abort the build without
+	// cleanup, see packer.IsAbortError.
+	Abort bool
 }
 
 func NewBasicError(err error) *BasicError {
@@ -13,9 +19,17 @@ func NewBasicError(err error) *BasicError {
 		return nil
 	}
 
-	return &BasicError{err.Error()}
+	return &BasicError{
+		Message: err.Error(),
+		Abort:   packer.IsAbortError(err),
+	}
 }
 
 func (e *BasicError) Error() string {
 	return e.Message
 }
+
+// Aborted tells whether the wrapped error asked to abort the build.
+func (e *BasicError) Aborted() bool {
+	return e.Abort
+}
@@ -31,6 +31,10 @@ type HookRunArgs struct {
 	StreamId uint32
 }
 
+type HookRunResponse struct {
+	Err *BasicError
+}
+
 func (h *hook) Run(ctx context.Context, name string, ui packer.Ui, comm packer.Communicator, data interface{}) error {
 	nextId := h.mux.NextId()
 	server := newServerWithMux(h.mux, nextId)
@@ -57,10 +61,17 @@ func (h *hook) Run(ctx context.Context, name string, ui packer.Ui, comm packer.C
 		StreamId: nextId,
 	}
 
-	return h.client.Call(h.endpoint+".Run", &args, new(interface{}))
+	var response HookRunResponse
+	if err := h.client.Call(h.endpoint+".Run", &args, &response); err != nil {
+		return err
+	}
+	if response.Err != nil {
+		return response.Err
+	}
+	return nil
 }
 
-func (h *HookServer) Run(args *HookRunArgs, reply *interface{}) error {
+func (h *HookServer) Run(args *HookRunArgs, reply *HookRunResponse) error {
 	client, err := newClientWithMux(h.mux, args.StreamId)
 	if err != nil {
 		return NewBasicError(err)
@@ -72,11 +83,10 @@ func (h *HookServer) Run(args *HookRunArgs, reply *interface{}) error {
 		h.context, h.contextCancel = context.WithCancel(context.Background())
 	}
 	h.lock.Unlock()
-	if err := h.hook.Run(h.context, args.Name, client.Ui(), client.Communicator(), args.Data); err != nil {
-		return NewBasicError(err)
-	}
-
-	*reply = nil
+	// net/rpc only keeps the message of returned errors, send the error in
+	// the reply so that the caller knows whether it should abort.
+	err = h.hook.Run(h.context, args.Name, client.Ui(), client.Communicator(), args.Data)
+	*reply = HookRunResponse{Err: NewBasicError(err)}
 	return nil
 }
 
@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/hashicorp/packer/packer"
@@ -36,3 +37,28 @@ func TestHook_cancelWhileRun(t *testing.T) {
 		t.Fatal("should have errored")
 	}
 }
+
+func TestHook_abortError(t *testing.T) {
+	h := &packer.MockHook{
+		RunFunc: func(ctx context.Context) error {
+			return &packer.AbortError{Err: errors.New("foo")}
+		},
+	}
+
+	client, server := testClientServer(t)
+	defer client.Close()
+	defer server.Close()
+	server.RegisterHook(h)
+	hClient := client.Hook()
+
+	err := hClient.Run(context.Background(), "foo", nil, nil, nil)
+	if err == nil {
+		t.Fatal("should have errored")
+	}
+	if err.Error() != "foo" {
+		t.Fatalf("bad: %s", err)
+	}
+	if !packer.IsAbortError(err) {
+		t.Fatalf("abort should be kept across rpc: %#v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 
+	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 )
 
@@ -11,6 +12,8 @@ import (
 // executed over an RPC connection.
 type postProcessor struct {
 	commonClient
+	deprecations     []config.Deprecation
+	plannedResources []string
 }
 
 // PostProcessorServer wraps a packer.PostProcessor implementation and makes it
@@ -27,6 +30,12 @@ type PostProcessorConfigureArgs struct {
 	Configs []interface{}
 }
 
+type PostProcessorConfigureResponse struct {
+	Deprecations []config.Deprecation
+	Planned      PlannedResources
+	Error        *BasicError
+}
+
 type PostProcessorProcessResponse struct {
 	Err           *BasicError
 	Keep          bool
@@ -40,7 +49,24 @@ func (p *postProcessor) Configure(raw ...interface{}) error {
 		return err
 	}
 	args := &PostProcessorConfigureArgs{Configs: raw}
-	return p.client.Call(p.endpoint+".Configure", args, new(interface{}))
+	var resp PostProcessorConfigureResponse
+	if err := p.client.Call(p.endpoint+".Configure", args, &resp); err != nil {
+		return err
+	}
+	p.deprecations = resp.Deprecations
+	p.plannedResources = resp.Planned.resources()
+	if resp.Error != nil {
+		return resp.Error
+	}
+	return nil
+}
+
+func (p *postProcessor) Deprecations() []config.Deprecation {
+	return p.deprecations
+}
+
+func (p *postProcessor) PlannedResources() []string {
+	return p.plannedResources
 }
 
 func (p *postProcessor) PostProcess(ctx context.Context, ui packer.Ui, a packer.Artifact) (packer.Artifact, bool, bool, error) {
@@ -85,13 +111,18 @@ func (p *postProcessor) PostProcess(ctx context.Context, ui packer.Ui, a packer.
 	return client.Artifact(), response.Keep, response.ForceOverride, nil
 }
 
-func (p *PostProcessorServer) Configure(args *PostProcessorConfigureArgs, reply *interface{}) (err error) {
-	config, err := decodeCTYValues(args.Configs)
+func (p *PostProcessorServer) Configure(args *PostProcessorConfigureArgs, reply *PostProcessorConfigureResponse) (err error) {
+	configs, err := decodeCTYValues(args.Configs)
 	if err != nil {
 		return err
 	}
-	err = p.p.Configure(config...)
-	return err
+	err = p.p.Configure(configs...)
+	*reply = PostProcessorConfigureResponse{
+		Deprecations: config.ComponentDeprecations(p.p, configs...),
+		Planned:      newPlannedResources(p.p),
+		Error:        NewBasicError(err),
+	}
+	return nil
 }
 
 func (p *PostProcessorServer) PostProcess(streamId uint32, reply *PostProcessorProcessResponse) error {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 
+	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 )
 
@@ -11,6 +12,7 @@ import (
 // executed over an RPC connection.
 type provisioner struct {
 	commonClient
+	deprecations []config.Deprecation
 }
 
 // ProvisionerServer wraps a packer.Provisioner implementation and makes it
@@ -27,13 +29,30 @@ type ProvisionerPrepareArgs struct {
 	Configs []interface{}
 }
 
+type ProvisionerPrepareResponse struct {
+	Deprecations []config.Deprecation
+	Error        *BasicError
+}
+
 func (p *provisioner) Prepare(configs ...interface{}) error {
 	configs, err := encodeCTYValues(configs)
 	if err != nil {
 		return err
 	}
 	args := &ProvisionerPrepareArgs{configs}
-	return p.client.Call(p.endpoint+".Prepare", args, new(interface{}))
+	var resp ProvisionerPrepareResponse
+	if err := p.client.Call(p.endpoint+".Prepare", args, &resp); err != nil {
+		return err
+	}
+	p.deprecations = resp.Deprecations
+	if resp.Error != nil {
+		return resp.Error
+	}
+	return nil
+}
+
+func (p *provisioner) Deprecations() []config.Deprecation {
+	return p.deprecations
 }
 
 type ProvisionerProvisionArgs struct {
@@ -66,12 +85,17 @@ func (p *provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 	return p.client.Call(p.endpoint+".Provision", args, new(interface{}))
 }
 
-func (p *ProvisionerServer) Prepare(args *ProvisionerPrepareArgs, reply *interface{}) error {
-	config, err := decodeCTYValues(args.Configs)
+func (p *ProvisionerServer) Prepare(args *ProvisionerPrepareArgs, reply *ProvisionerPrepareResponse) error {
+	configs, err := decodeCTYValues(args.Configs)
 	if err != nil {
 		return err
 	}
-	return p.p.Prepare(config...)
+	err = p.p.Prepare(configs...)
+	*reply = ProvisionerPrepareResponse{
+		Deprecations: config.ComponentDeprecations(p.p, configs...),
+		Error:        NewBasicError(err),
+	}
+	return nil
 }
 
 func (p *ProvisionerServer) Provision(args *ProvisionerProvisionArgs, reply *interface{}) error {
@@ -0,0 +1,26 @@
+package rpc
+
+import "github.com/hashicorp/packer/packer"
+
+// PlannedResources are the resources a component plans to create, see
+// packer.ResourcePlanner. Known tells them apart from unknown resources,
+// since nil and empty slices look alike over RPC.
+type PlannedResources struct {
+	Resources []string
+	Known     bool
+}
+
+func newPlannedResources(c interface{}) PlannedResources {
+	r := packer.PlannedResources(c)
+	return PlannedResources{Resources: r, Known: r != nil}
+}
+
+func (p PlannedResources) resources() []string {
+	if !p.Known {
+		return nil
+	}
+	if p.Resources == nil {
+		return []string{}
+	}
+	return p.Resources
+}
@@ -8,6 +8,25 @@ type GetBuildsOptions struct {
 	Except, Only []string
 	Debug, Force bool
 	OnError      string
+	// OnConflict is what to do when the artifact of a build already
+	// exists: one of the Conflict* modes, ConflictFail when empty.
+	OnConflict string
+	// DeferDependents leaves the builds that depend on other builds
+	// unprepared, so that they can be prepared with the outputs of these
+	// builds once they ran.
+	DeferDependents bool
+	// Breakpoints are the names of the steps and of the provisioners to
+	// pause at.
+	Breakpoints []string
+	// BillingTags are the tags the builders add to the temporary resources
+	// of the builds.
+	BillingTags map[string]string
+	// Preflight is the preflight mode of the builds, one of the Preflight*
+	// modes, or empty.
+	Preflight string
+	// Resume is the id of the build whose packer process died that the
+	// builds resume, from its journal.
+	Resume string
 }
 
 type BuildGetter interface {
@@ -31,6 +50,7 @@ type Handler interface {
 	BuildGetter
 	ConfigFixer
 	ConfigInspector
+	ConfigLinter
 }
 
 //go:generate enumer -type FixConfigMode
@@ -63,3 +83,9 @@ type ConfigInspector interface {
 	// Inspect will output self inspection for a configuration
 	InspectConfig(InspectConfigOptions) (ret int)
 }
+
+type ConfigLinter interface {
+	// LintConfig returns the builders, provisioners and post-processors of
+	// the configuration for the lint rules to check.
+	LintConfig() ([]*LintBlock, hcl.Diagnostics)
+}
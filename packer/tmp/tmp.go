@@ -1,26 +1,67 @@
 // Package tmp provides temporary directory helpers.
 //
-// tmp stores temporary items in the system's
-// temporary directory unless a corresponding
+// tmp stores temporary items in the scratch directory of the build of the
+// process, set with SetDir, so that they are removed with it once the build
+// ran. Outside of a build, they are stored in the scratch directory of the
+// Packer run, set by Packer in the PACKER_TMP_DIR environment variable and
+// removed when Packer exits, even when it crashes. Otherwise they are stored
+// in the system's temporary directory unless a corresponding
 // environment variable is set ( see os.TempDir ).
 //
 // On Unix systems, it uses $TMPDIR if non-empty, else /tmp.
 // On Windows, it uses GetTempPath, returning the first non-empty
 // value from %TMP%, %TEMP%, %USERPROFILE%, or the Windows directory.
 // On Plan 9, it returns /tmp.
-//
-// The directory is neither guaranteed to exist nor have accessible
-// permissions.
 package tmp
 
 import (
 	"io/ioutil"
 	"os"
+	"sync"
 )
 
-var tmpDir = os.TempDir()
+// EnvDir is the environment variable of the scratch directory of the Packer
+// run.
+const EnvDir = "PACKER_TMP_DIR"
+
+var (
+	buildDir  string
+	buildDirL sync.Mutex
+)
+
+// RunDir returns the scratch directory of the Packer run, or the system
+// temporary directory outside of a run. It is not guaranteed to exist.
+func RunDir() string {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir
+	}
+	return os.TempDir()
+}
+
+// SetDir makes the scratch directory of the build of the process, dir, the
+// directory of the temporary items. It is created when needed.
+func SetDir(dir string) {
+	buildDirL.Lock()
+	defer buildDirL.Unlock()
+	buildDir = dir
+}
+
+// tmpDir returns the directory of the temporary items, creating it when it
+// is a scratch directory.
+func tmpDir() (string, error) {
+	buildDirL.Lock()
+	dir := buildDir
+	buildDirL.Unlock()
+	if dir == "" {
+		dir = os.Getenv(EnvDir)
+	}
+	if dir == "" {
+		return os.TempDir(), nil
+	}
+	return dir, os.MkdirAll(dir, 0700)
+}
 
-// Dir creates a new temporary directory in the system temporary
+// Dir creates a new temporary directory in the scratch or system temporary
 // directory with a name beginning with prefix and returns the path
 // of the new directory.
 // Multiple programs calling Dir simultaneously
@@ -28,10 +69,14 @@ var tmpDir = os.TempDir()
 // It is the caller's responsibility
 // to remove the file when no longer needed.
 func Dir(prefix string) (string, error) {
-	return ioutil.TempDir(tmpDir, prefix)
+	dir, err := tmpDir()
+	if err != nil {
+		return "", err
+	}
+	return ioutil.TempDir(dir, prefix)
 }
 
-// File creates a new temporary file in the system temporary
+// File creates a new temporary file in the scratch or system temporary
 // directory, opens the file for reading and writing, and
 // returns the resulting *os.File.
 // The filename is generated by taking pattern and adding a random
@@ -42,5 +87,9 @@ func Dir(prefix string) (string, error) {
 // to find the pathname of the file. It is the caller's responsibility
 // to remove the file when no longer needed.
 func File(pattern string) (*os.File, error) {
-	return ioutil.TempFile(tmpDir, pattern)
+	dir, err := tmpDir()
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.TempFile(dir, pattern)
 }
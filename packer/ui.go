@@ -105,6 +105,12 @@ func (u *ColoredUi) colorize(message string, color UiColor, bold bool) string {
 }
 
 func (u *ColoredUi) supportsColors() bool {
+	return SupportsColors()
+}
+
+// SupportsColors tells whether the terminal of packer renders the ANSI
+// colors.
+func SupportsColors() bool {
 	// Never use colors if we have this environmental variable
 	if os.Getenv("PACKER_NO_COLOR") != "" {
 		return false
@@ -0,0 +1,306 @@
+package packer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dashboardRefresh is how often a Dashboard redraws itself, so that the
+// elapsed times keep ticking between messages.
+const dashboardRefresh = 500 * time.Millisecond
+
+// Dashboard is a live view of the builds running in parallel: each build has a
+// line, redrawn in place, with its status, its elapsed time, its current step
+// and the last line it output. Errors and questions are written above the
+// dashboard through Ui so that they stay visible.
+type Dashboard struct {
+	// Ui writes the messages that are not replaced by the next redraw.
+	Ui Ui
+	// Writer is the terminal the dashboard is drawn on.
+	Writer io.Writer
+	// Width is the number of columns of the terminal. Longer lines are cut to
+	// keep one line per build.
+	Width int
+	// Color makes the names of the builds bold and colors their status.
+	Color bool
+
+	l      sync.Mutex
+	builds []*dashboardBuild
+	drawn  int
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+type dashboardBuild struct {
+	name      string
+	started   time.Time
+	finished  time.Time
+	failed    bool
+	step      string
+	stepStart time.Time
+	last      string
+}
+
+// BuildUi returns the UI of the build called name, to be passed to its Run
+// method. Builds are drawn in the order of the calls to BuildUi.
+func (d *Dashboard) BuildUi(name string) Ui {
+	d.l.Lock()
+	defer d.l.Unlock()
+	b := &dashboardBuild{name: name}
+	d.builds = append(d.builds, b)
+	return &dashboardUi{d: d, b: b}
+}
+
+// Started marks the build called name as running.
+func (d *Dashboard) Started(name string) {
+	d.update(name, func(b *dashboardBuild) { b.started = time.Now() })
+}
+
+// Finished marks the build called name as done, or failed when err is not
+// nil.
+func (d *Dashboard) Finished(name string, err error) {
+	d.update(name, func(b *dashboardBuild) {
+		b.finished = time.Now()
+		if b.started.IsZero() {
+			b.started = b.finished
+		}
+		b.failed = err != nil
+		b.step = ""
+	})
+}
+
+func (d *Dashboard) update(name string, fn func(*dashboardBuild)) {
+	d.l.Lock()
+	defer d.l.Unlock()
+	for _, b := range d.builds {
+		if b.name == name {
+			fn(b)
+		}
+	}
+	d.redraw()
+}
+
+// Start draws the dashboard and keeps it up to date until Stop is called.
+func (d *Dashboard) Start() {
+	d.stopCh = make(chan struct{})
+	d.doneCh = make(chan struct{})
+	go func() {
+		defer close(d.doneCh)
+		ticker := time.NewTicker(dashboardRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.l.Lock()
+				d.redraw()
+				d.l.Unlock()
+			case <-d.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing the dashboard, leaving its last state on the
+// terminal.
+func (d *Dashboard) Stop() {
+	if d.stopCh == nil {
+		return
+	}
+	close(d.stopCh)
+	<-d.doneCh
+	d.l.Lock()
+	defer d.l.Unlock()
+	d.redraw()
+	d.drawn = 0
+}
+
+// above writes through fn above the dashboard. The caller holds d.l.
+func (d *Dashboard) above(fn func()) {
+	d.clear()
+	fn()
+	d.redraw()
+}
+
+// clear erases the dashboard drawn last. The caller holds d.l.
+func (d *Dashboard) clear() {
+	if d.drawn > 0 {
+		fmt.Fprintf(d.Writer, "\033[%dA\033[J", d.drawn)
+		d.drawn = 0
+	}
+}
+
+// redraw replaces the dashboard drawn last. The caller holds d.l.
+func (d *Dashboard) redraw() {
+	var buf strings.Builder
+	if d.drawn > 0 {
+		fmt.Fprintf(&buf, "\033[%dA\033[J", d.drawn)
+	}
+	now := time.Now()
+	for _, b := range d.builds {
+		buf.WriteString(d.line(b, now))
+		buf.WriteByte('\n')
+	}
+	d.drawn = len(d.builds)
+	if _, err := io.WriteString(d.Writer, buf.String()); err != nil {
+		log.Printf("[ERR] Failed to draw dashboard: %s", err)
+	}
+}
+
+func (d *Dashboard) line(b *dashboardBuild, now time.Time) string {
+	width := 0
+	for _, other := range d.builds {
+		if len(other.name) > width {
+			width = len(other.name)
+		}
+	}
+
+	status, color, elapsed := "waiting", UiColor(0), time.Duration(0)
+	switch {
+	case b.started.IsZero():
+	case b.finished.IsZero():
+		status, color, elapsed = "running", UiColorCyan, now.Sub(b.started)
+	case b.failed:
+		status, color, elapsed = "failed", UiColorRed, b.finished.Sub(b.started)
+	default:
+		status, color, elapsed = "done", UiColorGreen, b.finished.Sub(b.started)
+	}
+
+	detail := b.last
+	if b.step != "" {
+		detail = fmt.Sprintf("%s (%s) %s", b.step, now.Sub(b.stepStart).Round(time.Second), b.last)
+	}
+	text := fmt.Sprintf("%-*s  %-7s %8s  %s", width, b.name, status, elapsed.Round(time.Second), detail)
+	if d.Width > 0 && len(text) > d.Width-1 {
+		text = text[:d.Width-1]
+	}
+	if !d.Color || len(text) < width+2+7 {
+		return text
+	}
+
+	// Only color the name and the status, which are never cut.
+	rest := text[width+2+7:]
+	name := fmt.Sprintf("\033[1m%-*s\033[0m  ", width, b.name)
+	if color == 0 {
+		return name + fmt.Sprintf("%-7s", status) + rest
+	}
+	return name + fmt.Sprintf("\033[%dm%-7s\033[0m", color, status) + rest
+}
+
+// dashboardUi is the UI of a build of a Dashboard.
+type dashboardUi struct {
+	d *Dashboard
+	b *dashboardBuild
+	NoopProgressTracker
+}
+
+var _ Ui = new(dashboardUi)
+
+func (u *dashboardUi) Ask(query string) (string, error) {
+	u.d.l.Lock()
+	defer u.d.l.Unlock()
+	var line string
+	var err error
+	u.d.above(func() { line, err = u.d.Ui.Ask(query) })
+	return line, err
+}
+
+func (u *dashboardUi) Say(message string)     { u.output(message) }
+func (u *dashboardUi) Message(message string) { u.output(message) }
+
+func (u *dashboardUi) Error(message string) {
+	u.d.l.Lock()
+	defer u.d.l.Unlock()
+	u.d.above(func() { u.d.Ui.Error(message) })
+}
+
+func (u *dashboardUi) Machine(t string, args ...string) {
+	category := t
+	if i := strings.Index(t, ","); i > -1 {
+		category = t[i+1:]
+	}
+	switch {
+	case category == EventStepStarted && len(args) >= 1:
+		u.d.update(u.b.name, func(b *dashboardBuild) {
+			b.step, b.stepStart = args[0], time.Now()
+		})
+	case category == EventStepFinished && len(args) >= 1:
+		u.d.update(u.b.name, func(b *dashboardBuild) { b.step = "" })
+	}
+	u.d.Ui.Machine(t, args...)
+}
+
+// output keeps the last line of message, without the prefix of the
+// TargetedUI of the build, as the last output line of the build.
+func (u *dashboardUi) output(message string) {
+	log.Printf("ui: %s", message)
+	for s := range LogSecretFilter.s {
+		if s != "" {
+			message = strings.Replace(message, s, "<sensitive>", -1)
+		}
+	}
+
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	last := untarget(u.b.name, lines[len(lines)-1])
+	u.d.update(u.b.name, func(b *dashboardBuild) {
+		b.last = strings.TrimSpace(strings.Replace(last, "\r", "", -1))
+	})
+}
+
+// untarget removes the prefix added by a TargetedUI to a line.
+func untarget(target, line string) string {
+	for _, arrow := range []string{"==> ", "    "} {
+		if strings.HasPrefix(line, arrow+target+": ") {
+			return strings.TrimPrefix(line, arrow+target+": ")
+		}
+	}
+	return line
+}
+
+// JSONUi is a UI writing its messages as ui events of an EventStream. Ask
+// always fails since there is no one to answer.
+type JSONUi struct {
+	Stream *EventStream
+	// Target is the build the messages belong to, if any.
+	Target string
+	NoopProgressTracker
+}
+
+var _ Ui = new(JSONUi)
+
+func (u *JSONUi) Ask(query string) (string, error) {
+	return "", errors.New("json UI can't ask")
+}
+
+func (u *JSONUi) Say(message string)     { u.emit("say", message) }
+func (u *JSONUi) Message(message string) { u.emit("message", message) }
+func (u *JSONUi) Error(message string)   { u.emit("error", message) }
+
+func (u *JSONUi) Machine(t string, args ...string) {
+	log.Printf("machine readable: %s %#v", t, args)
+}
+
+func (u *JSONUi) emit(level, message string) {
+	log.Printf("ui: %s", message)
+	for s := range LogSecretFilter.s {
+		if s != "" {
+			message = strings.Replace(message, s, "<sensitive>", -1)
+		}
+	}
+
+	lines := strings.Split(message, "\n")
+	for i := range lines {
+		lines[i] = untarget(u.Target, lines[i])
+	}
+	u.Stream.Emit(Event{
+		Type:    EventUi,
+		Build:   u.Target,
+		Level:   level,
+		Message: strings.Join(lines, "\n"),
+	})
+}
@@ -0,0 +1,87 @@
+package packer
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDashboard(t *testing.T) {
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	d := &Dashboard{
+		Ui:     &BasicUi{Writer: out, ErrorWriter: errOut},
+		Writer: out,
+	}
+	a := &TargetedUI{Target: "a", Ui: d.BuildUi("a")}
+	b := &TargetedUI{Target: "longer", Ui: d.BuildUi("longer")}
+
+	d.Started("a")
+	a.Machine(EventStepStarted, "StepCreateVM")
+	a.Say("Creating virtual machine...")
+	a.Message("Waiting for IP\nGot IP")
+	d.Started("longer")
+	b.Error("Oops")
+	d.Finished("longer", errors.New("oops"))
+
+	if got := errOut.String(); got != "==> longer: Oops\n" {
+		t.Fatalf("bad error output: %q", got)
+	}
+
+	// The last drawing follows the last clear sequence.
+	drawn := out.String()
+	drawn = drawn[strings.LastIndex(drawn, "\033[J")+len("\033[J"):]
+	lines := strings.Split(strings.TrimSuffix(drawn, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("bad dashboard: %q", drawn)
+	}
+	if !strings.HasPrefix(lines[0], "a       running") || !strings.HasSuffix(lines[0], "StepCreateVM (0s) Got IP") {
+		t.Fatalf("bad line: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "longer  failed") {
+		t.Fatalf("bad line: %q", lines[1])
+	}
+}
+
+func TestDashboard_width(t *testing.T) {
+	out := new(bytes.Buffer)
+	d := &Dashboard{Ui: &NoopUi{}, Writer: out, Width: 20}
+	ui := d.BuildUi("a")
+	ui.Say(strings.Repeat("x", 100))
+
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		if i := strings.LastIndex(line, "\033[J"); i > -1 {
+			line = line[i+len("\033[J"):]
+		}
+		if len(line) > 19 {
+			t.Fatalf("line too long: %q", line)
+		}
+	}
+}
+
+func TestJSONUi(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ui := &TargetedUI{
+		Target: "null.example",
+		Ui:     &JSONUi{Stream: NewEventStream(buf), Target: "null.example"},
+	}
+	ui.Say("Running\nthe build")
+	ui.Error("Oops")
+	if _, err := ui.Ask("?"); err == nil {
+		t.Fatal("should not be able to ask")
+	}
+
+	events := readEvents(t, buf)
+	for i := range events {
+		events[i].Time = events[0].Time
+	}
+	expected := []Event{
+		{Time: events[0].Time, Type: EventUi, Build: "null.example", Level: "say", Message: "Running\nthe build"},
+		{Time: events[0].Time, Type: EventUi, Build: "null.example", Level: "error", Message: "Oops"},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("bad events:\n%#v\nexpected:\n%#v", events, expected)
+	}
+}
@@ -0,0 +1,145 @@
+package packer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Generated data keys describing the verification of a build, set on the
+// artifact of the builder when the build has verifiers. The status is
+// "passed" or "failed", and the failures are one per line.
+const (
+	VerificationStatusKey   = "VerificationStatus"
+	VerificationFailuresKey = "VerificationFailures"
+)
+
+// VerificationFailure is the failure of one verifier of a build.
+type VerificationFailure struct {
+	Verifier string
+	Message  string
+}
+
+func (f VerificationFailure) String() string {
+	return fmt.Sprintf("%s: %s", f.Verifier, f.Message)
+}
+
+// VerificationError is returned by a build whose verifiers failed. It tells
+// test failures apart from build errors.
+type VerificationError struct {
+	Failures []VerificationFailure
+}
+
+func (e *VerificationError) Error() string {
+	failures := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		failures = append(failures, f.String())
+	}
+	return fmt.Sprintf("Verification failed: %s", strings.Join(failures, "; "))
+}
+
+// VerifyHook is a Hook running the verifiers of a build once it has been
+// provisioned and before it is shut down. Unlike provisioners, all the
+// verifiers run even when some of them fail, and their failures are
+// collected into a VerificationError.
+type VerifyHook struct {
+	// The verifiers, which are provisioners, already prepared.
+	Verifiers []*HookedProvisioner
+
+	// ContinueOnFailure lets the build go on when verifiers fail, so that it
+	// still produces its artifact. The build is reported as failed anyway.
+	ContinueOnFailure bool
+
+	l   sync.Mutex
+	ran bool
+	err *VerificationError
+}
+
+func (h *VerifyHook) Run(ctx context.Context, name string, ui Ui, comm Communicator, data interface{}) error {
+	if len(h.Verifiers) == 0 {
+		return nil
+	}
+
+	if comm == nil {
+		return fmt.Errorf(
+			"No communicator found for verifiers! This is usually because the\n" +
+				"`communicator` config was set to \"none\". If you have any verifiers\n" +
+				"then a communicator is required. Please fix this to continue.")
+	}
+
+	var failures []VerificationFailure
+	for _, p := range h.Verifiers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		ui.Say(fmt.Sprintf("Verifying with %s...", p.TypeName))
+		ts := CheckpointReporter.AddSpan(p.TypeName, "verifier", p.Config)
+		err := p.Provisioner.Provision(ctx, ui, comm, CastDataToMap(data))
+		ts.End(err)
+		if err != nil {
+			ui.Error(fmt.Sprintf("Verifier %s failed: %s", p.TypeName, err))
+			failures = append(failures, VerificationFailure{
+				Verifier: p.TypeName,
+				Message:  err.Error(),
+			})
+		}
+	}
+
+	h.l.Lock()
+	defer h.l.Unlock()
+	h.ran = true
+	h.err = nil
+	if len(failures) == 0 {
+		ui.Say("Verification passed.")
+		return nil
+	}
+
+	h.err = &VerificationError{Failures: failures}
+	if h.ContinueOnFailure {
+		ui.Error("Verification failed; continuing the build as on_failure is \"continue\".")
+		return nil
+	}
+	return h.err
+}
+
+// Result returns whether the verifiers ran, and the error they failed with,
+// if they did.
+func (h *VerifyHook) Result() (bool, *VerificationError) {
+	h.l.Lock()
+	defer h.l.Unlock()
+	return h.ran, h.err
+}
+
+// verifiedArtifact adds the result of the verification of a build to the
+// generated data of its artifact, for the post-processors.
+type verifiedArtifact struct {
+	Artifact
+	err *VerificationError
+}
+
+func (a *verifiedArtifact) State(name string) interface{} {
+	state := a.Artifact.State(name)
+	if name != "generated_data" {
+		return state
+	}
+
+	data := map[string]interface{}{}
+	if state != nil {
+		for k, v := range CastDataToMap(state) {
+			data[k] = v
+		}
+	}
+	data[VerificationStatusKey] = "passed"
+	data[VerificationFailuresKey] = ""
+	if a.err != nil {
+		failures := make([]string, 0, len(a.err.Failures))
+		for _, f := range a.err.Failures {
+			failures = append(failures, f.String())
+		}
+		data[VerificationStatusKey] = "failed"
+		data[VerificationFailuresKey] = strings.Join(failures, "\n")
+	}
+	return data
+}
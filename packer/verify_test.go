@@ -0,0 +1,133 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestVerifyHook_Impl(t *testing.T) {
+	var raw interface{}
+	raw = &VerifyHook{}
+	if _, ok := raw.(Hook); !ok {
+		t.Fatalf("must be a Hook")
+	}
+}
+
+func TestVerifyHook(t *testing.T) {
+	cases := []struct {
+		name              string
+		continueOnFailure bool
+		wantErr           bool
+	}{
+		{"abort", false, true},
+		{"continue", true, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			failing := &MockProvisioner{
+				ProvFunc: func(context.Context) error {
+					return errors.New("nginx is not running")
+				},
+			}
+			passing := &MockProvisioner{}
+			hook := &VerifyHook{
+				Verifiers: []*HookedProvisioner{
+					{failing, nil, "shell"},
+					{passing, nil, "file"},
+				},
+				ContinueOnFailure: tc.continueOnFailure,
+			}
+
+			err := hook.Run(context.Background(), HookProvision, testUi(), new(MockCommunicator), nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !passing.ProvCalled {
+				t.Fatal("all verifiers should run")
+			}
+
+			ran, verr := hook.Result()
+			if !ran {
+				t.Fatal("verifiers should have run")
+			}
+			if verr == nil || len(verr.Failures) != 1 {
+				t.Fatalf("bad: %#v", verr)
+			}
+			if verr.Failures[0].Verifier != "shell" {
+				t.Fatalf("bad verifier: %s", verr.Failures[0].Verifier)
+			}
+			if verr.Error() != "Verification failed: shell: nginx is not running" {
+				t.Fatalf("bad error: %s", verr.Error())
+			}
+		})
+	}
+}
+
+func TestVerifyHook_nilComm(t *testing.T) {
+	hook := &VerifyHook{
+		Verifiers: []*HookedProvisioner{
+			{&MockProvisioner{}, nil, "shell"},
+		},
+	}
+
+	err := hook.Run(context.Background(), HookProvision, testUi(), nil, nil)
+	if err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestBuild_RunVerifiers(t *testing.T) {
+	failing := func(context.Context) error {
+		return errors.New("port 80 is closed")
+	}
+
+	cases := []struct {
+		name              string
+		verifier          func(context.Context) error
+		continueOnFailure bool
+		wantArtifacts     int
+		wantErr           bool
+		wantStatus        string
+	}{
+		{"passed", nil, false, 2, false, "passed"},
+		{"failed, abort", failing, false, 0, true, ""},
+		{"failed, continue", failing, true, 2, true, "failed"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			build := testBuild()
+			build.Verifiers = []CoreBuildProvisioner{
+				{PType: "shell", Provisioner: &MockProvisioner{ProvFunc: tc.verifier}},
+			}
+			build.VerifyContinueOnFailure = tc.continueOnFailure
+			if _, err := build.Prepare(); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			artifacts, err := build.Run(context.Background(), testUi())
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				if _, ok := err.(*VerificationError); !ok {
+					t.Fatalf("should be a verification error: %#v", err)
+				}
+			}
+			if len(artifacts) != tc.wantArtifacts {
+				t.Fatalf("bad: %#v", artifacts)
+			}
+			if tc.wantStatus == "" {
+				return
+			}
+
+			pp := build.PostProcessors[0][0].PostProcessor.(*MockPostProcessor)
+			data := CastDataToMap(pp.PostProcessArtifact.State("generated_data"))
+			if data[VerificationStatusKey] != tc.wantStatus {
+				t.Fatalf("bad status: %#v", data)
+			}
+		})
+	}
+}
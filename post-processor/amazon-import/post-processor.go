@@ -126,6 +126,10 @@ func (p *PostProcessor) Configure(raws ...interface{}) error {
 	return nil
 }
 
+func (p *PostProcessor) PlannedResources() []string {
+	return []string{"temporary S3 object", "AMI imported from the artifact"}
+}
+
 func (p *PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, bool, error) {
 	var err error
 
@@ -16,6 +16,7 @@ import (
 	"github.com/biogo/hts/bgzf"
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/naming"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 	"github.com/hashicorp/packer/template/interpolate"
@@ -43,6 +44,8 @@ type Config struct {
 	OutputPath       string `mapstructure:"output"`
 	Format           string `mapstructure:"format"`
 	CompressionLevel int    `mapstructure:"compression_level"`
+	// The naming policy of the archive, applied to `output`.
+	NamingConfig naming.Config `mapstructure:",squash"`
 
 	// Derived fields
 	Archive   string
@@ -62,7 +65,7 @@ func (p *PostProcessor) Configure(raws ...interface{}) error {
 		Interpolate:        true,
 		InterpolateContext: &p.config.ctx,
 		InterpolateFilter: &interpolate.RenderFilter{
-			Exclude: []string{"output"},
+			Exclude: []string{"output", "name_pattern"},
 		},
 	}, raws...)
 	if err != nil {
@@ -95,6 +98,8 @@ func (p *PostProcessor) Configure(raws ...interface{}) error {
 			errs, fmt.Errorf("Error parsing target template: %s", err))
 	}
 
+	errs = packer.MultiErrorAppend(errs, p.config.NamingConfig.Prepare()...)
+
 	p.config.detectFromFilename()
 
 	if len(errs.Errors) > 0 {
@@ -129,9 +134,12 @@ func (p *PostProcessor) PostProcess(
 	target, err := interpolate.Render(p.config.OutputPath, &p.config.ctx)
 	if err != nil {
 		return nil, false, false, fmt.Errorf("Error interpolating output value: %s", err)
-	} else {
-		fmt.Println(target)
 	}
+	target, err = p.config.NamingConfig.OutputFile(&p.config.ctx, target)
+	if err != nil {
+		return nil, false, false, err
+	}
+	fmt.Println(target)
 
 	newArtifact := &Artifact{Path: target}
 
@@ -114,6 +114,10 @@ func (p *PostProcessor) Configure(raws ...interface{}) error {
 	return nil
 }
 
+func (p *PostProcessor) PlannedResources() []string {
+	return []string{"temporary compute instance", "image file in a storage bucket"}
+}
+
 func (p *PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, bool, error) {
 	switch artifact.BuilderId() {
 	case googlecompute.BuilderId, artifice.BuilderId:
@@ -186,7 +190,7 @@ func (p *PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, artifact
 	}
 
 	driver, err := googlecompute.NewDriverGCE(ui, builderProjectId,
-		p.config.account, p.config.VaultGCPOauthEngine)
+		p.config.account, p.config.VaultGCPOauthEngine, nil, nil)
 	if err != nil {
 		return nil, false, false, err
 	}
@@ -1,6 +1,9 @@
 package manifest
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 const BuilderId = "packer.post-processor.manifest"
 
@@ -17,6 +20,25 @@ type Artifact struct {
 	ArtifactId    string            `json:"artifact_id"`
 	PackerRunUUID string            `json:"packer_run_uuid"`
 	CustomData    map[string]string `json:"custom_data"`
+	// CredentialRotation is how the credential of the communicator user
+	// was rotated, if it was.
+	CredentialRotation string `json:"credential_rotation,omitempty"`
+	// ShutdownPath is how the builder shut the machine down, when it did.
+	ShutdownPath string `json:"shutdown_path,omitempty"`
+	// PublicIP is the public IP address of Packer the builder detected, and
+	// PublicIPProvider the provider that answered it.
+	PublicIP         string `json:"public_ip,omitempty"`
+	PublicIPProvider string `json:"public_ip_provider,omitempty"`
+	// VerificationStatus is "passed" or "failed" when the build has a
+	// verify stage, and VerificationFailures are the failures of its
+	// verifiers.
+	VerificationStatus   string   `json:"verification_status,omitempty"`
+	VerificationFailures []string `json:"verification_failures,omitempty"`
+	// Cost is the estimated cost of the resources of the build, when its
+	// builder estimates it.
+	Cost json.RawMessage `json:"cost,omitempty"`
+	// Reports are the JSON reports written during the build, by name.
+	Reports map[string]json.RawMessage `json:"reports,omitempty"`
 }
 
 func (a *Artifact) BuilderId() string {
@@ -11,10 +11,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/cost"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 	"github.com/hashicorp/packer/template/interpolate"
@@ -35,7 +37,11 @@ type Config struct {
 	// engine](https://packer.io/docs/templates/engine.html). Therefore, you
 	// may use user variables and template functions in this field.
 	CustomData map[string]string `mapstructure:"custom_data"`
-	ctx        interpolate.Context
+	// JSON reports written during the build, like the report of the
+	// `windows-update` provisioner, to add to the manifest by name. The
+	// paths are templates, and missing reports are skipped.
+	Reports map[string]string `mapstructure:"reports"`
+	ctx     interpolate.Context
 }
 
 type PostProcessor struct {
@@ -72,6 +78,10 @@ func (p *PostProcessor) Configure(raws ...interface{}) error {
 	return nil
 }
 
+func (p *PostProcessor) PlannedResources() []string {
+	return []string{"manifest file"}
+}
+
 func (p *PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, source packer.Artifact) (packer.Artifact, bool, bool, error) {
 	generatedData := source.State("generated_data")
 	if generatedData == nil {
@@ -91,6 +101,10 @@ func (p *PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, source pa
 	artifact := &Artifact{}
 
 	var err error
+	if artifact.Reports, err = p.readReports(ui); err != nil {
+		return nil, false, false, err
+	}
+
 	var fi os.FileInfo
 
 	// Create the current artifact.
@@ -110,6 +124,17 @@ func (p *PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, source pa
 	artifact.CustomData = p.config.CustomData
 	artifact.BuilderType = p.config.PackerBuilderType
 	artifact.BuildName = p.config.PackerBuildName
+	artifact.CredentialRotation = generatedString(generatedData, "CredentialRotation")
+	artifact.ShutdownPath = generatedString(generatedData, "ShutdownPath")
+	artifact.PublicIP = generatedString(generatedData, "PublicIP")
+	artifact.PublicIPProvider = generatedString(generatedData, "PublicIPProvider")
+	artifact.VerificationStatus = generatedString(generatedData, packer.VerificationStatusKey)
+	if failures := generatedString(generatedData, packer.VerificationFailuresKey); failures != "" {
+		artifact.VerificationFailures = strings.Split(failures, "\n")
+	}
+	if report := generatedString(generatedData, cost.CostReportKey); report != "" {
+		artifact.Cost = json.RawMessage(report)
+	}
 	artifact.BuildTime = time.Now().Unix()
 	if p.config.StripTime {
 		artifact.BuildTime = 0
@@ -182,3 +207,45 @@ func createInterpolatedCustomData(config *Config, customData string) (string, er
 	}
 	return interpolatedCmd, nil
 }
+
+// readReports reads the JSON reports to add to the manifest.
+func (p *PostProcessor) readReports(ui packer.Ui) (map[string]json.RawMessage, error) {
+	if len(p.config.Reports) == 0 {
+		return nil, nil
+	}
+
+	reports := make(map[string]json.RawMessage, len(p.config.Reports))
+	for name, path := range p.config.Reports {
+		path, err := interpolate.Render(path, &p.config.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Error interpolating report %s: %s", name, err)
+		}
+		contents, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			ui.Message(fmt.Sprintf("Report %s not found at %s, skipping it", name, path))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read report %s: %s", name, err)
+		}
+		if !json.Valid(contents) {
+			return nil, fmt.Errorf("Report %s at %s is not valid JSON", name, path)
+		}
+		reports[name] = json.RawMessage(contents)
+	}
+	return reports, nil
+}
+
+// generatedString returns the string value of key in the generated data,
+// decoded as a map of any kind of keys when it went through RPC.
+func generatedString(generatedData interface{}, key string) string {
+	var value interface{}
+	switch data := generatedData.(type) {
+	case map[string]interface{}:
+		value = data[key]
+	case map[interface{}]interface{}:
+		value = data[key]
+	}
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,123 @@
+//go:generate mapstructure-to-hcl2 -type Config
+//go:generate struct-markdown
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/registry"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// The address of the image registry, like `file:registry`,
+	// `git:path/to/repository?push=true` or `https://registry.example.com`.
+	// Defaults to the `PACKER_REGISTRY` environment variable.
+	Address string `mapstructure:"address"`
+	// The name the builds of the image are published under. Required.
+	Image string `mapstructure:"image" required:"true"`
+	// The channels to assign the build to, like `dev`. Builds are usually
+	// promoted to other channels later, with `packer registry promote`.
+	Channels []string `mapstructure:"channels"`
+	// Arbitrary metadata of the build, like the commit of the configuration.
+	// This is a [template engine](/docs/templates/engine).
+	Labels map[string]string `mapstructure:"labels"`
+
+	ctx interpolate.Context
+}
+
+type PostProcessor struct {
+	config Config
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	err := config.Decode(&p.config, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &p.config.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{"labels"},
+		},
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if p.config.Address == "" {
+		p.config.Address = os.Getenv(registry.EnvAddress)
+	}
+
+	var errs *packer.MultiError
+	if p.config.Address == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("address must be set, or the %s environment variable", registry.EnvAddress))
+	} else if _, err := registry.New(p.config.Address); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+	if err := registry.ValidName(p.config.Image); err != nil {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("image: %s", err))
+	}
+	for _, c := range p.config.Channels {
+		if err := registry.ValidName(c); err != nil {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("channels: %s", err))
+		}
+	}
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, source packer.Artifact) (packer.Artifact, bool, bool, error) {
+	generatedData := source.State("generated_data")
+	if generatedData == nil {
+		generatedData = make(map[string]interface{})
+	}
+	p.config.ctx.Data = generatedData
+
+	backend, err := registry.New(p.config.Address)
+	if err != nil {
+		return source, true, false, err
+	}
+
+	build, err := registry.NewBuild(p.config.Image, p.config.PackerBuildName)
+	if err != nil {
+		return source, true, false, err
+	}
+	build.BuilderType = p.config.PackerBuilderType
+	build.Labels = make(map[string]string, len(p.config.Labels))
+	for k, v := range p.config.Labels {
+		if build.Labels[k], err = interpolate.Render(v, &p.config.ctx); err != nil {
+			return source, true, false, fmt.Errorf("Error interpolating label %q: %s", k, err)
+		}
+	}
+	build.Artifacts = []registry.Artifact{{
+		BuilderID: source.BuilderId(),
+		ID:        source.Id(),
+		String:    source.String(),
+		Files:     source.Files(),
+	}}
+
+	ui.Say(fmt.Sprintf("Publishing build %s of %s to the image registry...", build.ID, build.Image))
+	if err := backend.PutBuild(ctx, build); err != nil {
+		return source, true, false, fmt.Errorf("Error publishing the build: %s", err)
+	}
+	for _, c := range p.config.Channels {
+		ui.Message(fmt.Sprintf("Assigning the build to the %s channel", c))
+		if err := backend.SetChannel(ctx, build.Image, c, build.ID); err != nil {
+			return source, true, false, fmt.Errorf("Error assigning the build to %s: %s", c, err)
+		}
+	}
+
+	// Publishing metadata never changes the artifact, which is kept.
+	return source, true, true, nil
+}
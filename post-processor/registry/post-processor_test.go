@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer/common/registry"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestPostProcessor_Configure(t *testing.T) {
+	for _, raw := range []map[string]interface{}{
+		{"image": "base"},
+		{"address": "nope://x", "image": "base"},
+		{"address": "file:registry"},
+		{"address": "file:registry", "image": "base", "channels": []string{"../dev"}},
+	} {
+		if err := new(PostProcessor).Configure(raw); err == nil {
+			t.Errorf("%v: expected an error", raw)
+		}
+	}
+}
+
+func TestPostProcessor_PostProcess(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var p PostProcessor
+	err = p.Configure(map[string]interface{}{
+		"address":             "file:" + dir,
+		"image":               "base",
+		"channels":            []string{"dev"},
+		"labels":              map[string]string{"commit": "abc", "id": "{{ .ID }}"},
+		"packer_build_name":   "qemu.base",
+		"packer_builder_type": "qemu",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := &packer.MockArtifact{
+		IdValue:     "VM",
+		FilesValue:  []string{"disk.qcow2"},
+		StateValues: map[string]interface{}{"generated_data": map[string]interface{}{"ID": "vm-1"}},
+	}
+	a, keep, _, err := p.PostProcess(context.Background(), packer.TestUi(t), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != source || !keep {
+		t.Fatal("the artifact should be kept unchanged")
+	}
+
+	backend := registry.NewLocal(dir)
+	builds, err := backend.Builds(context.Background(), "base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builds) != 1 {
+		t.Fatalf("expected a build, got %v", builds)
+	}
+	b := builds[0]
+	if b.Name != "qemu.base" || b.BuilderType != "qemu" {
+		t.Errorf("unexpected build %#v", b)
+	}
+	if expected := map[string]string{"commit": "abc", "id": "vm-1"}; !reflect.DeepEqual(b.Labels, expected) {
+		t.Errorf("unexpected labels %v", b.Labels)
+	}
+	if len(b.Artifacts) != 1 || b.Artifacts[0].ID != "VM" || !reflect.DeepEqual(b.Artifacts[0].Files, []string{"disk.qcow2"}) {
+		t.Errorf("unexpected artifacts %#v", b.Artifacts)
+	}
+
+	channels, err := backend.Channels(context.Background(), "base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channels["dev"] != b.ID {
+		t.Errorf("unexpected channels %v", channels)
+	}
+}
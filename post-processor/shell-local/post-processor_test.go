@@ -139,7 +139,7 @@ func TestPostProcessorPrepare_ExecuteCommand(t *testing.T) {
 	if runtime.GOOS != "windows" {
 		expected = []string{"/bin/sh", "-c", "{{.Vars}} {{.Script}}"}
 	} else {
-		expected = []string{"cmd", "/V", "/C", "{{.Vars}}", "call", "{{.Script}}"}
+		expected = []string{"cmd", "/V", "/C", "{{.Vars}}", "call", `"{{.Script}}"`}
 	}
 	assert.Equal(t, p.config.ExecuteCommand, expected,
 		"Did not get expected default: expected: %#v; received %#v", expected, p.config.ExecuteCommand)
@@ -17,6 +17,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/naming"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 	"github.com/hashicorp/packer/packer/tmp"
@@ -69,6 +70,8 @@ type Config struct {
 	VagrantfileTemplate          string `mapstructure:"vagrantfile_template"`
 	VagrantfileTemplateGenerated bool   `mapstructure:"vagrantfile_template_generated"`
 	ProviderOverride             string `mapstructure:"provider_override"`
+	// The naming policy of the box, applied to `output`.
+	NamingConfig naming.Config `mapstructure:",squash"`
 
 	ctx interpolate.Context
 }
@@ -137,6 +140,10 @@ func (p *PostProcessor) PostProcessProvider(name string, provider Provider, ui p
 	if err != nil {
 		return nil, false, err
 	}
+	outputPath, err = config.NamingConfig.OutputFile(&config.ctx, outputPath)
+	if err != nil {
+		return nil, false, err
+	}
 
 	// Create a temporary directory for us to build the contents of the box in
 	dir, err := tmp.Dir("packer")
@@ -201,6 +208,10 @@ func (p *PostProcessor) PostProcessProvider(name string, provider Provider, ui p
 	return NewArtifact(name, outputPath), provider.KeepInputArtifact(), nil
 }
 
+func (p *PostProcessor) PlannedResources() []string {
+	return []string{"Vagrant box file"}
+}
+
 func (p *PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, bool, error) {
 	name := p.config.ProviderOverride
 	if name == "" {
@@ -244,6 +255,7 @@ func (p *PostProcessor) configureSingle(c *Config, raws ...interface{}) error {
 		InterpolateFilter: &interpolate.RenderFilter{
 			Exclude: []string{
 				"output",
+				"name_pattern",
 			},
 		},
 	}, raws...)
@@ -269,6 +281,7 @@ func (p *PostProcessor) configureSingle(c *Config, raws ...interface{}) error {
 	}
 
 	var errs *packer.MultiError
+	errs = packer.MultiErrorAppend(errs, c.NamingConfig.Prepare()...)
 	if c.VagrantfileTemplate != "" && c.VagrantfileTemplateGenerated == false {
 		_, err := os.Stat(c.VagrantfileTemplate)
 		if err != nil {
@@ -20,7 +20,7 @@ func (s *stepChooseDatacenter) Run(ctx context.Context, state multistep.StateBag
 
 	ui.Message("Choosing datacenter...")
 
-	dc, err := finder.DatacenterOrDefault(context.Background(), s.Datacenter)
+	dc, err := finder.DatacenterOrDefault(ctx, s.Datacenter)
 	if err != nil {
 		state.Put("error", err)
 		ui.Error(err.Error())
@@ -34,7 +34,7 @@ func (s *stepCreateFolder) Run(ctx context.Context, state multistep.StateBag) mu
 	// If we don't find it, we save the folder name and continue with the previous path
 	// The iteration ends when we find an existing path otherwise it throws error
 	for {
-		ref, err = si.FindByInventoryPath(context.Background(), fullPath)
+		ref, err = si.FindByInventoryPath(ctx, fullPath)
 		if err != nil {
 			state.Put("error", err)
 			ui.Error(err.Error())
@@ -62,7 +62,7 @@ func (s *stepCreateFolder) Run(ctx context.Context, state multistep.StateBag) mu
 		for i := len(folders) - 1; i >= 0; i-- {
 			ui.Message(fmt.Sprintf("Creating folder: %v", folders[i]))
 
-			root, err = root.CreateFolder(context.Background(), folders[i])
+			root, err = root.CreateFolder(ctx, folders[i])
 			if err != nil {
 				state.Put("error", err)
 				ui.Error(err.Error())
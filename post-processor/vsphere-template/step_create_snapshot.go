@@ -56,7 +56,7 @@ func (s *stepCreateSnapshot) Run(ctx context.Context, state multistep.StateBag)
 		return multistep.ActionHalt
 	}
 
-	task, err := vm.CreateSnapshot(context.Background(), s.SnapshotName, s.SnapshotDescription, false, false)
+	task, err := vm.CreateSnapshot(ctx, s.SnapshotName, s.SnapshotDescription, false, false)
 
 	if err != nil {
 		state.Put("error", err)
@@ -64,7 +64,7 @@ func (s *stepCreateSnapshot) Run(ctx context.Context, state multistep.StateBag)
 		return multistep.ActionHalt
 	}
 
-	if err = task.Wait(context.Background()); err != nil {
+	if err = task.Wait(ctx); err != nil {
 		state.Put("error", err)
 		ui.Error(err.Error())
 		return multistep.ActionHalt
@@ -154,6 +154,10 @@ func getEncodedPassword(u *url.URL) (string, bool) {
 	return password, false
 }
 
+func (p *PostProcessor) PlannedResources() []string {
+	return []string{"virtual machine uploaded to vSphere"}
+}
+
 func (p *PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, bool, error) {
 	source := ""
 	for _, path := range artifact.Files() {
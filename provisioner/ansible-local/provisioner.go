@@ -15,6 +15,7 @@ import (
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 	"github.com/hashicorp/packer/packer/tmp"
+	"github.com/hashicorp/packer/provisioner"
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
@@ -69,13 +70,19 @@ type Config struct {
 
 	// The command to run ansible-galaxy
 	GalaxyCommand string `mapstructure:"galaxy_command"`
+
+	// Install the galaxy requirements on the machine running Packer, in a
+	// cache keyed by the hash of the galaxy file, and upload them instead
+	// of installing them on the machine.
+	GalaxyCache bool `mapstructure:"galaxy_cache"`
 }
 
 type Provisioner struct {
 	config Config
 
-	playbookFiles []string
-	generatedData map[string]interface{}
+	playbookFiles   []string
+	generatedData   map[string]interface{}
+	collectionsPath string
 }
 
 func (p *Provisioner) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
@@ -195,6 +202,7 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.Communicator, generatedData map[string]interface{}) error {
 	ui.Say("Provisioning with Ansible...")
 	p.generatedData = generatedData
+	p.collectionsPath = ""
 
 	if len(p.config.PlaybookDir) > 0 {
 		ui.Message("Uploading Playbook directory to Ansible staging directory...")
@@ -245,7 +253,7 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 		}()
 	}
 
-	if len(p.config.GalaxyFile) > 0 {
+	if len(p.config.GalaxyFile) > 0 && !p.config.GalaxyCache {
 		ui.Message("Uploading galaxy file...")
 		src := p.config.GalaxyFile
 		dst := filepath.ToSlash(filepath.Join(p.config.StagingDir, filepath.Base(src)))
@@ -357,21 +365,47 @@ func (p *Provisioner) provisionPlaybookFile(ui packer.Ui, comm packer.Communicat
 func (p *Provisioner) executeGalaxy(ui packer.Ui, comm packer.Communicator) error {
 	ctx := context.TODO()
 	rolesDir := filepath.ToSlash(filepath.Join(p.config.StagingDir, "roles"))
+	collectionsDir := filepath.ToSlash(filepath.Join(p.config.StagingDir, "collections"))
 	galaxyFile := filepath.ToSlash(filepath.Join(p.config.StagingDir, filepath.Base(p.config.GalaxyFile)))
 
-	// ansible-galaxy install -r requirements.yml -p roles/
-	command := fmt.Sprintf("cd %s && %s install -r %s -p %s",
-		p.config.StagingDir, p.config.GalaxyCommand, galaxyFile, rolesDir)
-	ui.Message(fmt.Sprintf("Executing Ansible Galaxy: %s", command))
-	cmd := &packer.RemoteCmd{
-		Command: command,
+	if p.config.GalaxyCache {
+		dir, err := provisioner.InstallGalaxyCache(ui, "ansible-galaxy", p.config.GalaxyFile, false)
+		if err != nil {
+			return err
+		}
+		ui.Message("Uploading galaxy roles and collections...")
+		if err := p.uploadDir(ui, comm, rolesDir, filepath.Join(dir, "roles")); err != nil {
+			return fmt.Errorf("Error uploading galaxy roles: %s", err)
+		}
+		if err := p.uploadDir(ui, comm, collectionsDir, filepath.Join(dir, "collections")); err != nil {
+			return fmt.Errorf("Error uploading galaxy collections: %s", err)
+		}
+		p.collectionsPath = collectionsDir
+		return nil
 	}
-	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+
+	requirements, err := provisioner.ReadGalaxyRequirements(p.config.GalaxyFile)
+	if err != nil {
 		return err
 	}
-	if cmd.ExitStatus() != 0 {
-		// ansible-galaxy version 2.0.0.2 doesn't return exit codes on error..
-		return fmt.Errorf("Non-zero exit status: %d", cmd.ExitStatus())
+	for _, args := range requirements.GalaxyInstallArgs(galaxyFile, rolesDir, collectionsDir, false) {
+		// ansible-galaxy install -r requirements.yml -p roles/
+		command := fmt.Sprintf("cd %s && %s %s",
+			p.config.StagingDir, p.config.GalaxyCommand, strings.Join(args, " "))
+		ui.Message(fmt.Sprintf("Executing Ansible Galaxy: %s", command))
+		cmd := &packer.RemoteCmd{
+			Command: command,
+		}
+		if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+			return err
+		}
+		if cmd.ExitStatus() != 0 {
+			// ansible-galaxy version 2.0.0.2 doesn't return exit codes on error..
+			return fmt.Errorf("Non-zero exit status: %d", cmd.ExitStatus())
+		}
+	}
+	if requirements.Collections {
+		p.collectionsPath = collectionsDir
 	}
 	return nil
 }
@@ -412,8 +446,13 @@ func (p *Provisioner) executeAnsiblePlaybook(
 	ui packer.Ui, comm packer.Communicator, playbookFile, extraArgs, inventory string,
 ) error {
 	ctx := context.TODO()
+	ansibleCommand := p.config.Command
+	if p.collectionsPath != "" {
+		// Only use the collections of the galaxy file
+		ansibleCommand = fmt.Sprintf("ANSIBLE_COLLECTIONS_PATHS=%s %s", p.collectionsPath, ansibleCommand)
+	}
 	command := fmt.Sprintf("cd %s && %s %s%s -c local -i %s",
-		p.config.StagingDir, p.config.Command, playbookFile, extraArgs, inventory,
+		p.config.StagingDir, ansibleCommand, playbookFile, extraArgs, inventory,
 	)
 	ui.Message(fmt.Sprintf("Executing Ansible: %s", command))
 	cmd := &packer.RemoteCmd{
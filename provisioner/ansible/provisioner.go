@@ -35,6 +35,7 @@ import (
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
 	"github.com/hashicorp/packer/packer/tmp"
+	"github.com/hashicorp/packer/provisioner"
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
@@ -176,6 +177,20 @@ type Config struct {
 	//   `ansible-galaxy` command. By default, this is empty, and thus `--roles-path`
 	//   option is not added to the command.
 	RolesPath string `mapstructure:"roles_path"`
+	// The path to the directory on your local system to install the
+	// collections of the `galaxy_file` in. Adds `-p /path/to/collections` to
+	// the `ansible-galaxy collection install` command, and sets
+	// `ANSIBLE_COLLECTIONS_PATHS` for `ansible-playbook`. By default, this is
+	// empty, and collections are installed in the default path of Ansible.
+	CollectionsPath string `mapstructure:"collections_path"`
+	// Install the roles and collections of the `galaxy_file` in an isolated
+	// directory of the packer cache, named after the hash of the file, and
+	// reuse them while the file does not change. `ANSIBLE_ROLES_PATH` and
+	// `ANSIBLE_COLLECTIONS_PATHS` are set for `ansible-playbook` so that only
+	// these are used. The requirements should pin their versions. Can't be
+	// used with `roles_path` or `collections_path`; `galaxy_force_install`
+	// reinstalls them. By default, this is `false`.
+	GalaxyCache bool `mapstructure:"galaxy_cache"`
 	// When `true`, set up a localhost proxy adapter
 	// so that Ansible has an IP address to connect to, even if your guest does not
 	// have an IP address. For example, the adapter is necessary for Docker builds
@@ -205,6 +220,7 @@ type Provisioner struct {
 	ansibleVersion    string
 	ansibleMajVersion uint
 	generatedData     map[string]interface{}
+	galaxyEnvVars     []string
 
 	setupAdapterFunc   func(ui packer.Ui, comm packer.Communicator) (string, error)
 	executeAnsibleFunc func(ui packer.Ui, comm packer.Communicator, privKeyFile string) error
@@ -255,6 +271,11 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		}
 	}
 
+	if p.config.GalaxyCache && (p.config.RolesPath != "" || p.config.CollectionsPath != "") {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("galaxy_cache can't be used with roles_path or collections_path"))
+	}
+
 	// Check that the authorized key file exists
 	if len(p.config.SSHAuthorizedKeyFile) > 0 {
 		err = validateFileConfig(p.config.SSHAuthorizedKeyFile, "ssh_authorized_key_file", true)
@@ -438,7 +459,13 @@ const DefaultWinRMInventoryFilev2 = "{{ .HostAlias}} ansible_host={{ .Host }} an
 
 func (p *Provisioner) createInventoryFile() error {
 	log.Printf("Creating inventory file for Ansible run...")
-	tf, err := ioutil.TempFile(p.config.InventoryDirectory, "packer-provisioner-ansible")
+	var tf *os.File
+	var err error
+	if p.config.InventoryDirectory != "" {
+		tf, err = ioutil.TempFile(p.config.InventoryDirectory, "packer-provisioner-ansible")
+	} else {
+		tf, err = tmp.File("packer-provisioner-ansible")
+	}
 	if err != nil {
 		return fmt.Errorf("Error preparing inventory file: %s", err)
 	}
@@ -619,61 +646,33 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 }
 
 func (p *Provisioner) executeGalaxy(ui packer.Ui, comm packer.Communicator) error {
-	galaxyFile := filepath.ToSlash(p.config.GalaxyFile)
-
-	// ansible-galaxy install -r requirements.yml
-	args := []string{"install", "-r", galaxyFile}
-	// Add force to arguments
-	if p.config.GalaxyForceInstall {
-		args = append(args, "-f")
-	}
-	// Add roles_path argument if specified
-	if p.config.RolesPath != "" {
-		args = append(args, "-p", filepath.ToSlash(p.config.RolesPath))
+	p.galaxyEnvVars = nil
+	if p.config.GalaxyCache {
+		dir, err := provisioner.InstallGalaxyCache(ui, p.config.GalaxyCommand, p.config.GalaxyFile, p.config.GalaxyForceInstall)
+		if err != nil {
+			return err
+		}
+		p.galaxyEnvVars = []string{
+			"ANSIBLE_ROLES_PATH=" + filepath.Join(dir, "roles"),
+			"ANSIBLE_COLLECTIONS_PATHS=" + filepath.Join(dir, "collections"),
+		}
+		return nil
 	}
 
-	ui.Message(fmt.Sprintf("Executing Ansible Galaxy"))
-	cmd := exec.Command(p.config.GalaxyCommand, args...)
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return err
-	}
-	stderr, err := cmd.StderrPipe()
+	requirements, err := provisioner.ReadGalaxyRequirements(p.config.GalaxyFile)
 	if err != nil {
 		return err
 	}
-	wg := sync.WaitGroup{}
-	repeat := func(r io.ReadCloser) {
-		reader := bufio.NewReader(r)
-		for {
-			line, err := reader.ReadString('\n')
-			if line != "" {
-				line = strings.TrimRightFunc(line, unicode.IsSpace)
-				ui.Message(line)
-			}
-			if err != nil {
-				if err == io.EOF {
-					break
-				} else {
-					ui.Error(err.Error())
-					break
-				}
-			}
+	galaxyFile := filepath.ToSlash(p.config.GalaxyFile)
+	rolesPath := filepath.ToSlash(p.config.RolesPath)
+	collectionsPath := filepath.ToSlash(p.config.CollectionsPath)
+	for _, args := range requirements.GalaxyInstallArgs(galaxyFile, rolesPath, collectionsPath, p.config.GalaxyForceInstall) {
+		if err := provisioner.RunLocalGalaxy(ui, p.config.GalaxyCommand, args); err != nil {
+			return err
 		}
-		wg.Done()
-	}
-	wg.Add(2)
-	go repeat(stdout)
-	go repeat(stderr)
-
-	if err := cmd.Start(); err != nil {
-		return err
 	}
-	wg.Wait()
-	err = cmd.Wait()
-	if err != nil {
-		return fmt.Errorf("Non-zero exit status: %s", err)
+	if p.config.CollectionsPath != "" {
+		p.galaxyEnvVars = []string{"ANSIBLE_COLLECTIONS_PATHS=" + p.config.CollectionsPath}
 	}
 	return nil
 }
@@ -744,6 +743,7 @@ func (p *Provisioner) executeAnsible(ui packer.Ui, comm packer.Communicator, pri
 	cmd := exec.Command(p.config.Command, args...)
 
 	cmd.Env = os.Environ()
+	cmd.Env = append(cmd.Env, p.galaxyEnvVars...)
 	if len(envvars) > 0 {
 		cmd.Env = append(cmd.Env, envvars...)
 	}
@@ -129,7 +129,8 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 		p.config.ClientConfig.CloudEnvironment(),
 		p.config.PollingDurationTimeout,
 		p.config.PollingDurationTimeout,
-		spnCloud)
+		spnCloud,
+		p.config.ClientConfig.Sender())
 
 	if err != nil {
 		ui.Say(fmt.Sprintf("Error saving debug key: %s", err))
@@ -5,6 +5,7 @@ package breakpoint
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -21,6 +22,19 @@ type Config struct {
 	Note    string `mapstructure:"note"`
 	Disable bool   `mapstructure:"disable"`
 
+	// How the build is resumed: "prompt" waits for the user to press enter,
+	// "webhook" waits for an HTTP request approving or rejecting the build.
+	Mode string `mapstructure:"mode"`
+	// The address the webhook listens on.
+	WebhookListenAddress string `mapstructure:"webhook_listen_address"`
+	// The base URL of the webhook as seen by whoever approves the build.
+	WebhookPublicURL string `mapstructure:"webhook_public_url"`
+	// A URL that receives a POST request describing the breakpoint when it
+	// is reached.
+	WebhookNotifyURL string `mapstructure:"webhook_notify_url"`
+	// How long to wait for an approval before failing the build.
+	WebhookTimeout time.Duration `mapstructure:"webhook_timeout"`
+
 	ctx interpolate.Context
 }
 
@@ -42,6 +56,34 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		return err
 	}
 
+	var errs *packer.MultiError
+
+	switch p.config.Mode {
+	case "":
+		p.config.Mode = "prompt"
+	case "prompt", "webhook":
+	default:
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("mode must be prompt or webhook: %s", p.config.Mode))
+	}
+
+	if p.config.Mode == "webhook" {
+		if p.config.WebhookListenAddress == "" {
+			p.config.WebhookListenAddress = "127.0.0.1:0"
+		}
+		if p.config.WebhookTimeout == 0 {
+			p.config.WebhookTimeout = time.Hour
+		}
+		if p.config.WebhookTimeout < 0 {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("webhook_timeout can't be negative"))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+
 	return nil
 }
 
@@ -63,6 +105,10 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 		ui.Say("Pausing at breakpoint provisioner.")
 	}
 
+	if p.config.Mode == "webhook" {
+		return p.waitForApproval(ctx, ui)
+	}
+
 	message := fmt.Sprintf(
 		"Press enter to continue.")
 
@@ -0,0 +1,175 @@
+package breakpoint
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// webhookNotification is the body of the request sent to webhook_notify_url.
+type webhookNotification struct {
+	BuildName   string `json:"build_name"`
+	BuilderType string `json:"builder_type"`
+	Note        string `json:"note"`
+	ApproveURL  string `json:"approve_url"`
+	RejectURL   string `json:"reject_url"`
+	Deadline    string `json:"deadline"`
+}
+
+// approvalHandler receives the approval or rejection of the build. Requests
+// must be POST requests carrying the token, either in the token query
+// parameter or as a bearer token.
+type approvalHandler struct {
+	token string
+	// result receives nil when the build is approved, and an error when it
+	// is rejected.
+	result chan error
+}
+
+func (h *approvalHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		http.Error(w, "invalid token", http.StatusForbidden)
+		return
+	}
+
+	var result error
+	switch r.URL.Path {
+	case "/approve":
+	case "/reject":
+		reason, _ := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 4096))
+		result = fmt.Errorf("Build rejected at breakpoint")
+		if len(bytes.TrimSpace(reason)) > 0 {
+			result = fmt.Errorf("Build rejected at breakpoint: %s", bytes.TrimSpace(reason))
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	select {
+	case h.result <- result:
+		fmt.Fprintln(w, "ok")
+	default:
+		http.Error(w, "the breakpoint was already resumed", http.StatusConflict)
+	}
+}
+
+// waitForApproval pauses the build until a request approves or rejects it
+// through the webhook, or until the timeout expires.
+func (p *Provisioner) waitForApproval(ctx context.Context, ui packer.Ui) error {
+	token, err := randomToken()
+	if err != nil {
+		return fmt.Errorf("Error generating webhook token: %s", err)
+	}
+
+	l, err := net.Listen("tcp", p.config.WebhookListenAddress)
+	if err != nil {
+		return fmt.Errorf("Error starting webhook listener: %s", err)
+	}
+
+	handler := &approvalHandler{
+		token:  token,
+		result: make(chan error, 1),
+	}
+	server := &http.Server{Handler: handler}
+	go server.Serve(l)
+	defer server.Close()
+
+	baseURL := strings.TrimSuffix(p.config.WebhookPublicURL, "/")
+	if baseURL == "" {
+		baseURL = "http://" + l.Addr().String()
+	}
+	approveURL := fmt.Sprintf("%s/approve?token=%s", baseURL, token)
+	rejectURL := fmt.Sprintf("%s/reject?token=%s", baseURL, token)
+	deadline := time.Now().Add(p.config.WebhookTimeout)
+
+	ui.Say(fmt.Sprintf("Waiting up to %s for approval. POST to %s to continue, "+
+		"or to %s to fail the build.", p.config.WebhookTimeout, approveURL, rejectURL))
+
+	if p.config.WebhookNotifyURL != "" {
+		err := notifyWebhook(ctx, p.config.WebhookNotifyURL, &webhookNotification{
+			BuildName:   p.config.PackerBuildName,
+			BuilderType: p.config.PackerBuilderType,
+			Note:        p.config.Note,
+			ApproveURL:  approveURL,
+			RejectURL:   rejectURL,
+			Deadline:    deadline.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return fmt.Errorf("Error notifying %s: %s", p.config.WebhookNotifyURL, err)
+		}
+	}
+
+	timer := time.NewTimer(p.config.WebhookTimeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-handler.result:
+		if err != nil {
+			return err
+		}
+		ui.Say("Breakpoint approved; continuing...")
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("Timed out after %s waiting for approval", p.config.WebhookTimeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func notifyWebhook(ctx context.Context, url string, notification *webhookNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	log.Printf("[INFO] Notifying breakpoint webhook %s", url)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,122 @@
+package breakpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func testUi() *packer.BasicUi {
+	return &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	}
+}
+
+func TestProvisionerPrepare_webhook(t *testing.T) {
+	var p Provisioner
+	err := p.Prepare(map[string]interface{}{"mode": "webhook"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if p.config.WebhookListenAddress != "127.0.0.1:0" {
+		t.Fatalf("bad: %s", p.config.WebhookListenAddress)
+	}
+	if p.config.WebhookTimeout != time.Hour {
+		t.Fatalf("bad: %s", p.config.WebhookTimeout)
+	}
+
+	p = Provisioner{}
+	err = p.Prepare(map[string]interface{}{"mode": "bad"})
+	if err == nil {
+		t.Fatal("should error")
+	}
+}
+
+// testWebhook runs the provisioner in webhook mode, and calls respond with the
+// notification it receives.
+func testWebhook(t *testing.T, timeout string, respond func(*webhookNotification)) error {
+	notifications := make(chan *webhookNotification, 1)
+	notify := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n webhookNotification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("err: %s", err)
+		}
+		notifications <- &n
+	}))
+	defer notify.Close()
+
+	var p Provisioner
+	err := p.Prepare(map[string]interface{}{
+		"mode":               "webhook",
+		"note":               "inspect",
+		"webhook_notify_url": notify.URL,
+		"webhook_timeout":    timeout,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	go func() {
+		respond(<-notifications)
+	}()
+
+	return p.Provision(context.Background(), testUi(), nil, nil)
+}
+
+func TestProvisionerProvision_webhookApprove(t *testing.T) {
+	err := testWebhook(t, "1m", func(n *webhookNotification) {
+		if n.Note != "inspect" {
+			t.Errorf("bad: %#v", n)
+		}
+
+		// A wrong token is refused
+		resp, err := http.Post(strings.Split(n.ApproveURL, "?")[0]+"?token=bad", "", nil)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("bad: %s", resp.Status)
+		}
+
+		resp, err = http.Post(n.ApproveURL, "", nil)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			return
+		}
+		resp.Body.Close()
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestProvisionerProvision_webhookReject(t *testing.T) {
+	err := testWebhook(t, "1m", func(n *webhookNotification) {
+		resp, err := http.Post(n.RejectURL, "text/plain", strings.NewReader("image is broken"))
+		if err != nil {
+			t.Errorf("err: %s", err)
+			return
+		}
+		resp.Body.Close()
+	})
+	if err == nil || !strings.Contains(err.Error(), "image is broken") {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestProvisionerProvision_webhookTimeout(t *testing.T) {
+	err := testWebhook(t, "100ms", func(*webhookNotification) {})
+	if err == nil || !strings.Contains(err.Error(), "Timed out") {
+		t.Fatalf("bad: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package file
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer/packer/tmp"
+)
+
+// excluded tells whether a path, relative to the directory being uploaded,
+// matches one of the exclude patterns. A pattern without a slash matches the
+// name of a file or directory at any depth, otherwise it matches the whole
+// relative path.
+func excluded(rel string, patterns []string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = rel[strings.LastIndex(rel, "/")+1:]
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filteredDir copies the src directory, without its excluded files, to a
+// new temporary directory, and returns the path of the copy. The copy has the
+// name of src so that it is uploaded the same way; the caller removes its
+// parent directory.
+func filteredDir(src string, patterns []string) (string, error) {
+	src = filepath.Clean(src)
+	tmpDir, err := tmp.Dir("packer-file")
+	if err != nil {
+		return "", err
+	}
+	dst := filepath.Join(tmpDir, filepath.Base(src))
+
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && excluded(rel, patterns) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", err
+	}
+	return dst, nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
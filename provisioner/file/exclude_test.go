@@ -0,0 +1,73 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExcluded(t *testing.T) {
+	patterns := []string{".git", "*.log", "build/cache"}
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{".git", true},
+		{"src/.git", true},
+		{"debug.log", true},
+		{"logs/debug.log", true},
+		{"build/cache", true},
+		{"src/build/cache", false},
+		{"main.go", false},
+	}
+	for _, tc := range cases {
+		if got := excluded(filepath.FromSlash(tc.path), patterns); got != tc.want {
+			t.Errorf("excluded(%q) = %t, want %t", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestFilteredDir(t *testing.T) {
+	src, err := ioutil.TempDir("", "packerdir")
+	if err != nil {
+		t.Fatalf("error temp folder: %s", err)
+	}
+	defer os.RemoveAll(src)
+
+	for _, name := range []string{"main.go", "debug.log", ".git/HEAD", "conf/app.conf"} {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst, err := filteredDir(src, []string{".git", "*.log"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(filepath.Dir(dst))
+
+	if filepath.Base(dst) != filepath.Base(src) {
+		t.Fatalf("copy should be named after the source: %s", dst)
+	}
+
+	var files []string
+	filepath.Walk(dst, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dst, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	sort.Strings(files)
+	if diff := cmp.Diff([]string{"conf/app.conf", "main.go"}, files); diff != "" {
+		t.Fatalf("unexpected files: %s", diff)
+	}
+}
@@ -25,6 +25,14 @@ type Config struct {
 	Source  string
 	Sources []string
 
+	// The content of the file to upload, instead of a local file. It is a
+	// template, rendered with the data generated by the builder.
+	Content string
+
+	// Glob patterns of the files and directories not to upload from the
+	// source directories.
+	Excludes []string
+
 	// The remote path where the local file will be uploaded to.
 	Destination string
 
@@ -48,7 +56,9 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		Interpolate:        true,
 		InterpolateContext: &p.config.ctx,
 		InterpolateFilter: &interpolate.RenderFilter{
-			Exclude: []string{},
+			Exclude: []string{
+				"content",
+			},
 		},
 	}, raws...)
 	if err != nil {
@@ -69,6 +79,28 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		p.config.Sources = append(p.config.Sources, p.config.Source)
 	}
 
+	if p.config.Content != "" {
+		if len(p.config.Sources) > 0 {
+			errs = packer.MultiErrorAppend(errs,
+				errors.New("Only one of content or source(s) can be specified."))
+		}
+		if p.config.Direction != "upload" {
+			errs = packer.MultiErrorAppend(errs,
+				errors.New("Content can only be uploaded."))
+		}
+		if strings.HasSuffix(p.config.Destination, "/") {
+			errs = packer.MultiErrorAppend(errs,
+				errors.New("Destination must be a file path when content is specified."))
+		}
+	}
+
+	for _, pattern := range p.config.Excludes {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("Bad exclude pattern '%s': %s", pattern, err))
+		}
+	}
+
 	if p.config.Direction == "upload" {
 		for _, src := range p.config.Sources {
 			if _, err := os.Stat(src); p.config.Generated == false && err != nil {
@@ -78,9 +110,9 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		}
 	}
 
-	if len(p.config.Sources) < 1 {
+	if len(p.config.Sources) < 1 && p.config.Content == "" {
 		errs = packer.MultiErrorAppend(errs,
-			errors.New("Source must be specified."))
+			errors.New("Source or content must be specified."))
 	}
 
 	if p.config.Destination == "" {
@@ -103,8 +135,10 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 
 	if p.config.Direction == "download" {
 		return p.ProvisionDownload(ui, comm)
+	} else if p.config.Content != "" {
+		return p.ProvisionContent(ui, comm)
 	} else {
-		return p.ProvisionUpload(ui, comm)
+		return p.ProvisionUpload(ctx, ui, comm)
 	}
 }
 
@@ -157,12 +191,34 @@ func (p *Provisioner) ProvisionDownload(ui packer.Ui, comm packer.Communicator)
 	return nil
 }
 
-func (p *Provisioner) ProvisionUpload(ui packer.Ui, comm packer.Communicator) error {
+func (p *Provisioner) ProvisionContent(ui packer.Ui, comm packer.Communicator) error {
+	dst, err := interpolate.Render(p.config.Destination, &p.config.ctx)
+	if err != nil {
+		return fmt.Errorf("Error interpolating destination: %s", err)
+	}
+	content, err := interpolate.Render(p.config.Content, &p.config.ctx)
+	if err != nil {
+		return fmt.Errorf("Error interpolating content: %s", err)
+	}
+
+	ui.Say(fmt.Sprintf("Uploading content => %s", dst))
+	if err := comm.Upload(dst, strings.NewReader(content), nil); err != nil {
+		ui.Error(fmt.Sprintf("Upload failed: %s", err))
+		return err
+	}
+	return nil
+}
+
+func (p *Provisioner) ProvisionUpload(ctx context.Context, ui packer.Ui, comm packer.Communicator) error {
 	dst, err := interpolate.Render(p.config.Destination, &p.config.ctx)
 	if err != nil {
 		return fmt.Errorf("Error interpolating destination: %s", err)
 	}
 	for _, src := range p.config.Sources {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		src, err := interpolate.Render(src, &p.config.ctx)
 		if err != nil {
 			return fmt.Errorf("Error interpolating source: %s", err)
@@ -177,6 +233,19 @@ func (p *Provisioner) ProvisionUpload(ui packer.Ui, comm packer.Communicator) er
 
 		// If we're uploading a directory, short circuit and do that
 		if info.IsDir() {
+			if len(p.config.Excludes) > 0 {
+				// Not all communicators support exclusions, upload a
+				// filtered copy of the directory instead.
+				filtered, err := filteredDir(src, p.config.Excludes)
+				if err != nil {
+					return fmt.Errorf("Error excluding files from %s: %s", src, err)
+				}
+				defer os.RemoveAll(filepath.Dir(filtered))
+				if strings.HasSuffix(src, "/") {
+					filtered += "/"
+				}
+				src = filtered
+			}
 			if err = comm.UploadDir(dst, src, nil); err != nil {
 				ui.Error(fmt.Sprintf("Upload failed: %s", err))
 				return err
@@ -205,7 +274,7 @@ func (p *Provisioner) ProvisionUpload(ui packer.Ui, comm packer.Communicator) er
 		defer pf.Close()
 
 		// Upload the file
-		if err = comm.Upload(filedst, pf, &fi); err != nil {
+		if err = comm.Upload(filedst, packer.ContextReader(ctx, pf), &fi); err != nil {
 			if strings.Contains(err.Error(), "Error restoring file") {
 				ui.Error(fmt.Sprintf("Upload failed: %s; this can occur when "+
 					"your file destination is a folder without a trailing "+
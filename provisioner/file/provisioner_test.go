@@ -385,3 +385,75 @@ func TestProvisionDownloadMkdirAll(t *testing.T) {
 		}
 	}
 }
+
+func TestProvisionerPrepare_Content(t *testing.T) {
+	var p Provisioner
+	config := testConfig()
+	config["content"] = "hello"
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("should allow content: %s", err)
+	}
+
+	p = Provisioner{}
+	config["source"] = "/this/should/not/exist"
+	config["generated"] = true
+	if err := p.Prepare(config); err == nil {
+		t.Fatalf("should not allow both content and source")
+	}
+
+	p = Provisioner{}
+	config = testConfig()
+	config["content"] = "hello"
+	config["direction"] = "download"
+	if err := p.Prepare(config); err == nil {
+		t.Fatalf("should not allow downloading content")
+	}
+
+	p = Provisioner{}
+	config = testConfig()
+	config["content"] = "hello"
+	config["destination"] = "/tmp/"
+	if err := p.Prepare(config); err == nil {
+		t.Fatalf("should require a file destination")
+	}
+}
+
+func TestProvisionerProvision_SendsContent(t *testing.T) {
+	var p Provisioner
+	config := map[string]interface{}{
+		"content":     "host = {{ .Host }}",
+		"destination": "something",
+	}
+
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	b := bytes.NewBuffer(nil)
+	ui := &packer.BasicUi{
+		Writer: b,
+	}
+	comm := &packer.MockCommunicator{}
+	err := p.Provision(context.Background(), ui, comm, map[string]interface{}{"Host": "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("should successfully provision: %s", err)
+	}
+
+	if comm.UploadPath != "something" {
+		t.Fatalf("should upload to configured destination")
+	}
+
+	if comm.UploadData != "host = 10.0.0.1" {
+		t.Fatalf("should upload rendered content, got %q", comm.UploadData)
+	}
+}
+
+func TestProvisionerPrepare_InvalidExclude(t *testing.T) {
+	var p Provisioner
+	config := testConfig()
+	config["content"] = "hello"
+	config["excludes"] = []string{"[.git"}
+	if err := p.Prepare(config); err == nil {
+		t.Fatalf("should not allow bad pattern")
+	}
+}
@@ -0,0 +1,202 @@
+package provisioner
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+var (
+	galaxyRolesKey       = regexp.MustCompile(`(?m)^roles\s*:`)
+	galaxyCollectionsKey = regexp.MustCompile(`(?m)^collections\s*:`)
+)
+
+// galaxyCacheMarker is written in a galaxy cache directory once the
+// requirements were all installed in it.
+const galaxyCacheMarker = ".packer-galaxy-complete"
+
+// GalaxyRequirements tells what an ansible-galaxy requirements file
+// installs.
+type GalaxyRequirements struct {
+	// The file is a list of roles, as before Ansible 2.9, rather than a
+	// map of roles and collections.
+	Legacy      bool
+	Roles       bool
+	Collections bool
+}
+
+// ReadGalaxyRequirements reads the kinds of requirements of an ansible-galaxy
+// requirements file.
+func ReadGalaxyRequirements(path string) (*GalaxyRequirements, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &GalaxyRequirements{
+		Roles:       galaxyRolesKey.Match(contents),
+		Collections: galaxyCollectionsKey.Match(contents),
+	}
+	if !r.Roles && !r.Collections {
+		r.Legacy = true
+		r.Roles = true
+	}
+	return r, nil
+}
+
+// GalaxyInstallArgs returns the ansible-galaxy commands, as lists of
+// arguments, installing the requirements of file into rolesPath and
+// collectionsPath. Empty paths are left to ansible-galaxy.
+func (r *GalaxyRequirements) GalaxyInstallArgs(file, rolesPath, collectionsPath string, force bool) [][]string {
+	var commands [][]string
+	if r.Roles {
+		// ansible-galaxy install -r requirements.yml
+		args := []string{"install", "-r", file}
+		if !r.Legacy {
+			args = []string{"role", "install", "-r", file}
+		}
+		if force {
+			args = append(args, "-f")
+		}
+		if rolesPath != "" {
+			args = append(args, "-p", rolesPath)
+		}
+		commands = append(commands, args)
+	}
+	if r.Collections {
+		// ansible-galaxy collection install -r requirements.yml
+		args := []string{"collection", "install", "-r", file}
+		if force {
+			args = append(args, "-f")
+		}
+		if collectionsPath != "" {
+			args = append(args, "-p", collectionsPath)
+		}
+		commands = append(commands, args)
+	}
+	return commands
+}
+
+// GalaxyCacheDir returns the directory of the packer cache in which the
+// requirements of a galaxy file are installed, named after the hash of the
+// file, so that builds using the same pinned requirements share it.
+func GalaxyCacheDir(galaxyFile string) (string, error) {
+	contents, err := ioutil.ReadFile(galaxyFile)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(contents)
+	return packer.CachePath("ansible-galaxy", hex.EncodeToString(sum[:])[:16])
+}
+
+// GalaxyCacheComplete tells whether the requirements were all installed in a
+// galaxy cache directory.
+func GalaxyCacheComplete(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, galaxyCacheMarker))
+	return err == nil
+}
+
+// CompleteGalaxyCache marks the requirements as all installed in a galaxy
+// cache directory.
+func CompleteGalaxyCache(dir string) error {
+	return ioutil.WriteFile(filepath.Join(dir, galaxyCacheMarker), nil, 0644)
+}
+
+// InstallGalaxyCache installs the requirements of a galaxy file on the
+// machine running Packer, in the roles and collections directories of its
+// galaxy cache directory, unless they were already installed there. It
+// returns the cache directory.
+func InstallGalaxyCache(ui packer.Ui, command, galaxyFile string, force bool) (string, error) {
+	dir, err := GalaxyCacheDir(galaxyFile)
+	if err != nil {
+		return "", err
+	}
+	if !force && GalaxyCacheComplete(dir) {
+		ui.Message(fmt.Sprintf("Using the Ansible Galaxy requirements cached in %s", dir))
+		return dir, nil
+	}
+
+	// Start over from a partial install
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	r, err := ReadGalaxyRequirements(galaxyFile)
+	if err != nil {
+		return "", err
+	}
+	rolesPath := filepath.Join(dir, "roles")
+	collectionsPath := filepath.Join(dir, "collections")
+	for _, args := range r.GalaxyInstallArgs(filepath.ToSlash(galaxyFile), rolesPath, collectionsPath, force) {
+		if err := RunLocalGalaxy(ui, command, args); err != nil {
+			return "", err
+		}
+	}
+	for _, path := range []string{rolesPath, collectionsPath} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return "", err
+		}
+	}
+	return dir, CompleteGalaxyCache(dir)
+}
+
+// RunLocalGalaxy runs ansible-galaxy on the machine running Packer, with the
+// output shown to the user.
+func RunLocalGalaxy(ui packer.Ui, command string, args []string) error {
+	ui.Message(fmt.Sprintf("Executing Ansible Galaxy: %s %s", command, strings.Join(args, " ")))
+	cmd := exec.Command(command, args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	wg := sync.WaitGroup{}
+	repeat := func(r io.ReadCloser) {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				line = strings.TrimRightFunc(line, unicode.IsSpace)
+				ui.Message(line)
+			}
+			if err != nil {
+				if err != io.EOF {
+					ui.Error(err.Error())
+				}
+				break
+			}
+		}
+		wg.Done()
+	}
+	wg.Add(2)
+	go repeat(stdout)
+	go repeat(stderr)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	wg.Wait()
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("Non-zero exit status: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package provisioner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func writeGalaxyFile(t *testing.T, dir, contents string) string {
+	path := filepath.Join(dir, "requirements.yml")
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return path
+}
+
+func TestReadGalaxyRequirements(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		contents string
+		expected GalaxyRequirements
+	}{
+		{"- src: geerlingguy.nginx\n  version: 2.8.0\n", GalaxyRequirements{Legacy: true, Roles: true}},
+		{"roles:\n  - name: geerlingguy.nginx\n    version: 2.8.0\n", GalaxyRequirements{Roles: true}},
+		{"collections:\n  - name: community.general\n    version: 1.3.0\n", GalaxyRequirements{Collections: true}},
+		{"---\nroles:\n  - name: geerlingguy.nginx\ncollections:\n  - name: community.general\n", GalaxyRequirements{Roles: true, Collections: true}},
+	}
+	for _, tc := range cases {
+		r, err := ReadGalaxyRequirements(writeGalaxyFile(t, dir, tc.contents))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if *r != tc.expected {
+			t.Errorf("%q: expected %#v, got %#v", tc.contents, tc.expected, *r)
+		}
+	}
+}
+
+func TestGalaxyRequirements_GalaxyInstallArgs(t *testing.T) {
+	legacy := &GalaxyRequirements{Legacy: true, Roles: true}
+	expected := [][]string{{"install", "-r", "req.yml", "-p", "roles"}}
+	if args := legacy.GalaxyInstallArgs("req.yml", "roles", "collections", false); !reflect.DeepEqual(args, expected) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	both := &GalaxyRequirements{Roles: true, Collections: true}
+	expected = [][]string{
+		{"role", "install", "-r", "req.yml", "-f"},
+		{"collection", "install", "-r", "req.yml", "-f", "-p", "collections"},
+	}
+	if args := both.GalaxyInstallArgs("req.yml", "", "collections", true); !reflect.DeepEqual(args, expected) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestGalaxyCacheDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("PACKER_CACHE_DIR", dir)
+	defer os.Unsetenv("PACKER_CACHE_DIR")
+
+	file := writeGalaxyFile(t, dir, "roles:\n  - name: geerlingguy.nginx\n    version: 2.8.0\n")
+	first, err := GalaxyCacheDir(file)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	again, _ := GalaxyCacheDir(file)
+	if first != again {
+		t.Fatalf("cache dir should only depend on the requirements: %s != %s", first, again)
+	}
+
+	file = writeGalaxyFile(t, dir, "roles:\n  - name: geerlingguy.nginx\n    version: 2.9.0\n")
+	changed, _ := GalaxyCacheDir(file)
+	if changed == first {
+		t.Fatalf("cache dir should change with the requirements")
+	}
+}
+
+func TestInstallGalaxyCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("PACKER_CACHE_DIR", dir)
+	defer os.Unsetenv("PACKER_CACHE_DIR")
+
+	file := writeGalaxyFile(t, dir, "roles:\n  - name: geerlingguy.nginx\n    version: 2.8.0\n")
+	cacheDir, err := InstallGalaxyCache(packer.TestUi(t), "true", file, false)
+	if err != nil {
+		t.Skipf("can't run true: %s", err)
+	}
+	if !GalaxyCacheComplete(cacheDir) {
+		t.Fatal("cache should be complete")
+	}
+	for _, sub := range []string{"roles", "collections"} {
+		if _, err := os.Stat(filepath.Join(cacheDir, sub)); err != nil {
+			t.Fatalf("missing %s directory: %s", sub, err)
+		}
+	}
+
+	// A complete cache is reused without running ansible-galaxy
+	if _, err := InstallGalaxyCache(packer.TestUi(t), "/does/not/exist", file, false); err != nil {
+		t.Fatalf("should reuse the cache: %s", err)
+	}
+	if _, err := InstallGalaxyCache(packer.TestUi(t), "/does/not/exist", file, true); err == nil {
+		t.Fatal("should reinstall when forced")
+	}
+}
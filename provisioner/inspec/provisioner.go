@@ -32,6 +32,7 @@ import (
 	"github.com/hashicorp/packer/common/adapter"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/tmp"
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
@@ -316,7 +317,12 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 
 	go p.adapter.Serve()
 
-	tf, err := ioutil.TempFile(p.config.AttributesDirectory, "packer-provisioner-inspec.*.yml")
+	var tf *os.File
+	if p.config.AttributesDirectory != "" {
+		tf, err = ioutil.TempFile(p.config.AttributesDirectory, "packer-provisioner-inspec.*.yml")
+	} else {
+		tf, err = tmp.File("packer-provisioner-inspec.*.yml")
+	}
 	if err != nil {
 		return fmt.Errorf("Error preparing packer attributes file: %s", err)
 	}
@@ -488,7 +494,7 @@ func newUserKey(pubKeyFile string) (*userKey, error) {
 		Headers: nil,
 		Bytes:   privateKeyDer,
 	}
-	tf, err := ioutil.TempFile("", "packer-provisioner-inspec.*.key")
+	tf, err := tmp.File("packer-provisioner-inspec.*.key")
 	if err != nil {
 		return nil, errors.New("failed to create temp file for generated key")
 	}
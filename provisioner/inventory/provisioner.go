@@ -0,0 +1,169 @@
+//go:generate mapstructure-to-hcl2 -type Config
+
+// This package implements a provisioner for Packer that captures the
+// inventory of the machine: its packages, the hashes of its files and its
+// listening ports, for `packer image-diff` to compare images.
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/inventory"
+	"github.com/hashicorp/packer/common/shellquote"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+const defaultRemotePath = "/tmp/packer-inventory.sh"
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// The local path of the JSON inventory. It defaults to
+	// packer-inventory-BUILD.json, where BUILD is the name of the build.
+	Output string `mapstructure:"output"`
+
+	// The directories whose files are hashed, like /etc.
+	Paths []string `mapstructure:"paths"`
+
+	// Don't capture the installed packages.
+	SkipPackages bool `mapstructure:"skip_packages"`
+
+	// Don't capture the listening ports.
+	SkipPorts bool `mapstructure:"skip_ports"`
+
+	// The command running the inventory script, with the path of the script
+	// as {{.Path}} and its arguments as {{.Args}}. It defaults to
+	// sh '{{.Path}}' {{.Args}}; run it with sudo to hash the files only root
+	// can read.
+	ExecuteCommand string `mapstructure:"execute_command"`
+
+	// The remote path the inventory script is uploaded to.
+	RemotePath string `mapstructure:"remote_path"`
+
+	ctx interpolate.Context
+}
+
+// ExecuteCommandTemplate is the data of execute_command.
+type ExecuteCommandTemplate struct {
+	Path string
+	Args string
+}
+
+type Provisioner struct {
+	config Config
+}
+
+func (p *Provisioner) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *Provisioner) Prepare(raws ...interface{}) error {
+	err := config.Decode(&p.config, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &p.config.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{
+				"execute_command",
+			},
+		},
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if p.config.Output == "" {
+		p.config.Output = "packer-inventory.json"
+		if p.config.PackerBuildName != "" {
+			p.config.Output = fmt.Sprintf("packer-inventory-%s.json", p.config.PackerBuildName)
+		}
+	}
+
+	if p.config.ExecuteCommand == "" {
+		p.config.ExecuteCommand = "sh '{{.Path}}' {{.Args}}"
+	}
+
+	if p.config.RemotePath == "" {
+		p.config.RemotePath = defaultRemotePath
+	}
+
+	var errs *packer.MultiError
+	for _, path := range p.config.Paths {
+		if !strings.HasPrefix(path, "/") {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("paths must be absolute: %s", path))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.Communicator, _ map[string]interface{}) error {
+	ui.Say("Capturing the inventory of the machine...")
+
+	if err := comm.Upload(p.config.RemotePath, strings.NewReader(inventory.Script), nil); err != nil {
+		return fmt.Errorf("Error uploading the inventory script: %s", err)
+	}
+	defer func() {
+		cmd := &packer.RemoteCmd{Command: fmt.Sprintf("rm -f '%s'", p.config.RemotePath)}
+		if err := comm.Start(ctx, cmd); err == nil {
+			cmd.Wait()
+		}
+	}()
+
+	var args []string
+	for _, path := range p.config.Paths {
+		args = append(args, shellquote.Quote(path))
+	}
+	p.config.ctx.Data = &ExecuteCommandTemplate{
+		Path: p.config.RemotePath,
+		Args: strings.Join(args, " "),
+	}
+	command, err := interpolate.Render(p.config.ExecuteCommand, &p.config.ctx)
+	if err != nil {
+		return fmt.Errorf("Error rendering execute_command: %s", err)
+	}
+
+	// The inventory can be long, it only goes to the output file.
+	var stdout, stderr bytes.Buffer
+	cmd := &packer.RemoteCmd{
+		Command: command,
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	}
+	if err := comm.Start(ctx, cmd); err != nil {
+		return fmt.Errorf("Error running the inventory script: %s", err)
+	}
+	if status := cmd.Wait(); status != 0 {
+		return fmt.Errorf("The inventory script exited with status %d: %s", status, strings.TrimSpace(stderr.String()))
+	}
+
+	inv, err := inventory.Parse(&stdout)
+	if err != nil {
+		return fmt.Errorf("Error reading the inventory: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	inv.Build = p.config.PackerBuildName
+	inv.Captured = time.Now().UTC()
+	if p.config.SkipPackages {
+		inv.Packages = nil
+	}
+	if p.config.SkipPorts {
+		inv.Ports = nil
+	}
+	if err := inv.Write(p.config.Output); err != nil {
+		return fmt.Errorf("Error writing the inventory: %s", err)
+	}
+
+	ui.Message(fmt.Sprintf("Captured %d packages, %d files and %d ports to %s",
+		len(inv.Packages), len(inv.Files), len(inv.Ports), p.config.Output))
+	return nil
+}
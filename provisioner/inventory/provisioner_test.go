@@ -0,0 +1,82 @@
+package inventory
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/packer/common/inventory"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestProvisioner_Impl(t *testing.T) {
+	var raw interface{}
+	raw = &Provisioner{}
+	if _, ok := raw.(packer.Provisioner); !ok {
+		t.Fatalf("must be a Provisioner")
+	}
+}
+
+func TestProvisionerPrepare_Defaults(t *testing.T) {
+	var p Provisioner
+	err := p.Prepare(map[string]interface{}{"packer_build_name": "qemu.base"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if p.config.Output != "packer-inventory-qemu.base.json" {
+		t.Errorf("unexpected output: %s", p.config.Output)
+	}
+	if p.config.RemotePath != defaultRemotePath {
+		t.Errorf("unexpected remote path: %s", p.config.RemotePath)
+	}
+}
+
+func TestProvisionerPrepare_relativePath(t *testing.T) {
+	var p Provisioner
+	if err := p.Prepare(map[string]interface{}{"paths": []string{"etc"}}); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestProvisionerProvision(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer-inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "inventory.json")
+
+	var p Provisioner
+	err = p.Prepare(map[string]interface{}{
+		"packer_build_name": "qemu.base",
+		"output":            output,
+		"paths":             []string{"/etc/it's"},
+		"skip_ports":        true,
+		"execute_command":   "sudo sh {{.Path}} {{.Args}}",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	comm := &packer.MockCommunicator{
+		StartStdout: "== packages\nnginx\t1.18.0\n== files\nabc  /etc/it's/conf\n== ports\ntcp LISTEN 0 128 0.0.0.0:22 0.0.0.0:*\n",
+	}
+	ui := packer.TestUi(t)
+	if err := p.Provision(context.Background(), ui, comm, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if comm.UploadPath != defaultRemotePath || comm.UploadData != inventory.Script {
+		t.Fatalf("unexpected upload of %s", comm.UploadPath)
+	}
+
+	inv, err := inventory.Read(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Build != "qemu.base" || inv.Packages["nginx"] != "1.18.0" || inv.Files["/etc/it's/conf"] != "abc" || inv.Ports != nil {
+		t.Fatalf("unexpected inventory %#v", inv)
+	}
+}
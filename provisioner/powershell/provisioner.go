@@ -308,7 +308,7 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 			if _, err := f.Seek(0, 0); err != nil {
 				return err
 			}
-			if err := comm.Upload(p.config.RemotePath, f, &fi); err != nil {
+			if err := comm.Upload(p.config.RemotePath, packer.ContextReader(ctx, f), &fi); err != nil {
 				return fmt.Errorf("Error uploading script: %s", err)
 			}
 
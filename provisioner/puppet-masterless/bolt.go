@@ -0,0 +1,94 @@
+package puppetmasterless
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// boltTaskResult is the JSON result of `bolt task run`. The result of
+// `bolt plan run` is the value returned by the plan.
+type boltTaskResult struct {
+	Items []struct {
+		Target string                 `json:"target"`
+		Status string                 `json:"status"`
+		Value  map[string]interface{} `json:"value"`
+	} `json:"items"`
+}
+
+// boltMode tells whether the provisioner runs a Bolt task or plan instead of
+// applying a manifest.
+func (p *Provisioner) boltMode() bool {
+	return p.config.BoltTask != "" || p.config.BoltPlan != ""
+}
+
+// uploadBoltParameters uploads the parameters of the Bolt task or plan as a
+// JSON file, so that they don't have to be quoted for the remote shell. It
+// returns the remote path of the file, empty without parameters.
+func (p *Provisioner) uploadBoltParameters(ui packer.Ui, comm packer.Communicator) (string, error) {
+	if len(p.config.BoltParameters) == 0 {
+		return "", nil
+	}
+
+	params, err := json.Marshal(p.config.BoltParameters)
+	if err != nil {
+		return "", err
+	}
+
+	ui.Message("Uploading Bolt parameters...")
+	path := fmt.Sprintf("%s/bolt-params.json", p.config.StagingDir)
+	if err := comm.Upload(path, bytes.NewReader(params), nil); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// handleBoltResult records the JSON result of Bolt and fails when the task or
+// plan failed.
+func (p *Provisioner) handleBoltResult(ui packer.Ui, stdout []byte, exitStatus int) error {
+	result := bytes.TrimSpace(stdout)
+	if !json.Valid(result) {
+		result = nil
+	}
+
+	if p.config.BoltResultPath != "" && result != nil {
+		var out bytes.Buffer
+		if err := json.Indent(&out, result, "", "  "); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(p.config.BoltResultPath, out.Bytes(), 0644); err != nil {
+			return fmt.Errorf("Error writing Bolt result: %s", err)
+		}
+		ui.Message(fmt.Sprintf("Bolt result written to %s", p.config.BoltResultPath))
+	}
+
+	if exitStatus == 0 || p.config.IgnoreExitCodes {
+		return nil
+	}
+
+	if p.config.BoltTask != "" && result != nil {
+		var taskResult boltTaskResult
+		if err := json.Unmarshal(result, &taskResult); err == nil {
+			var failures []string
+			for _, item := range taskResult.Items {
+				if item.Status == "success" {
+					continue
+				}
+				msg := item.Status
+				if e, ok := item.Value["_error"].(map[string]interface{}); ok {
+					msg = fmt.Sprintf("%v", e["msg"])
+				}
+				failures = append(failures, fmt.Sprintf("%s: %s", item.Target, msg))
+			}
+			if len(failures) > 0 {
+				return fmt.Errorf("Bolt task %s failed: %s", p.config.BoltTask, strings.Join(failures, "; "))
+			}
+		}
+	}
+
+	return fmt.Errorf("Bolt exited with a non-zero exit status: %d", exitStatus)
+}
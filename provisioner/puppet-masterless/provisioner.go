@@ -6,6 +6,7 @@
 package puppetmasterless
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -51,6 +52,17 @@ type Config struct {
 	// The main manifest file to apply to kick off the entire thing.
 	ManifestFile string `mapstructure:"manifest_file"`
 
+	// The Bolt task or plan to run with the uploaded modules instead of
+	// applying a manifest, like `package` or `profile::base`.
+	BoltTask string `mapstructure:"bolt_task"`
+	BoltPlan string `mapstructure:"bolt_plan"`
+
+	// The parameters of the Bolt task or plan.
+	BoltParameters map[string]interface{} `mapstructure:"bolt_parameters"`
+
+	// The local path where the JSON result of Bolt is written.
+	BoltResultPath string `mapstructure:"bolt_result_path"`
+
 	// A directory of manifest files that will be uploaded to the remote
 	// machine.
 	ManifestDir string `mapstructure:"manifest_dir"`
@@ -78,12 +90,13 @@ type Config struct {
 }
 
 type guestOSTypeConfig struct {
-	executeCommand   string
-	facterVarsFmt    string
-	facterVarsJoiner string
-	modulePathJoiner string
-	stagingDir       string
-	tempDir          string
+	executeCommand     string
+	boltExecuteCommand string
+	facterVarsFmt      string
+	facterVarsJoiner   string
+	modulePathJoiner   string
+	stagingDir         string
+	tempDir            string
 }
 
 // FIXME assumes both Packer host and target are same OS
@@ -102,6 +115,15 @@ var guestOSTypeConfigs = map[string]guestOSTypeConfig{
 			`{{if ne .ManifestDir ""}}--manifestdir='{{.ManifestDir}}' {{end}}` +
 			`{{if ne .ExtraArguments ""}}{{.ExtraArguments}} {{end}}` +
 			"{{.ManifestFile}}",
+		boltExecuteCommand: "cd {{.WorkingDir}} && " +
+			"{{if .Sudo}}sudo -E {{end}}" +
+			`{{if ne .PuppetBinDir ""}}{{.PuppetBinDir}}/{{end}}` +
+			"bolt {{.BoltAction}} run {{.BoltName}} --targets localhost --format json " +
+			"{{if .Debug}}--log-level debug {{end}}" +
+			`{{if ne .ModulePath ""}}--modulepath '{{.ModulePath}}' {{end}}` +
+			`{{if ne .HieraConfigPath ""}}--hiera-config '{{.HieraConfigPath}}' {{end}}` +
+			`{{if ne .BoltParamsFile ""}}--params '@{{.BoltParamsFile}}' {{end}}` +
+			`{{if ne .ExtraArguments ""}}{{.ExtraArguments}}{{end}}`,
 		facterVarsFmt:    "FACTER_%s='%s'",
 		facterVarsJoiner: " ",
 		modulePathJoiner: ":",
@@ -119,6 +141,14 @@ var guestOSTypeConfigs = map[string]guestOSTypeConfig{
 			`{{if ne .ManifestDir ""}}--manifestdir='{{.ManifestDir}}' {{end}}` +
 			`{{if ne .ExtraArguments ""}}{{.ExtraArguments}} {{end}}` +
 			"{{.ManifestFile}}",
+		boltExecuteCommand: "cd {{.WorkingDir}} && " +
+			`{{if ne .PuppetBinDir ""}}{{.PuppetBinDir}}/{{end}}` +
+			"bolt {{.BoltAction}} run {{.BoltName}} --targets localhost --format json " +
+			"{{if .Debug}}--log-level debug {{end}}" +
+			`{{if ne .ModulePath ""}}--modulepath "{{.ModulePath}}" {{end}}` +
+			`{{if ne .HieraConfigPath ""}}--hiera-config "{{.HieraConfigPath}}" {{end}}` +
+			`{{if ne .BoltParamsFile ""}}--params "@{{.BoltParamsFile}}" {{end}}` +
+			`{{if ne .ExtraArguments ""}}{{.ExtraArguments}}{{end}}`,
 		facterVarsFmt:    `SET "FACTER_%s=%s"`,
 		facterVarsJoiner: " & ",
 		modulePathJoiner: ";",
@@ -134,6 +164,9 @@ type Provisioner struct {
 }
 
 type ExecuteTemplate struct {
+	BoltAction       string
+	BoltName         string
+	BoltParamsFile   string
 	Debug            bool
 	ExtraArguments   string
 	FacterVars       string
@@ -187,6 +220,9 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 
 	if p.config.ExecuteCommand == "" {
 		p.config.ExecuteCommand = p.guestOSTypeConfig.executeCommand
+		if p.boltMode() {
+			p.config.ExecuteCommand = p.guestOSTypeConfig.boltExecuteCommand
+		}
 	}
 
 	if p.config.StagingDir == "" {
@@ -227,7 +263,22 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		}
 	}
 
-	if p.config.ManifestFile == "" {
+	if p.config.BoltTask != "" && p.config.BoltPlan != "" {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("Only one of bolt_task or bolt_plan can be specified."))
+	}
+
+	if !p.boltMode() && (len(p.config.BoltParameters) > 0 || p.config.BoltResultPath != "") {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("bolt_parameters and bolt_result_path require bolt_task or bolt_plan."))
+	}
+
+	if p.boltMode() {
+		if p.config.ManifestFile != "" || p.config.ManifestDir != "" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("manifest_file and manifest_dir can't be used with bolt_task or bolt_plan."))
+		}
+	} else if p.config.ManifestFile == "" {
 		errs = packer.MultiErrorAppend(errs,
 			fmt.Errorf("A manifest_file must be specified."))
 	} else {
@@ -299,10 +350,19 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 		modulePaths = append(modulePaths, targetPath)
 	}
 
-	// Upload manifests
-	remoteManifestFile, err := p.uploadManifests(ui, comm)
-	if err != nil {
-		return fmt.Errorf("Error uploading manifests: %s", err)
+	// Upload manifests, or the parameters of Bolt
+	var remoteManifestFile, remoteBoltParamsFile string
+	var err error
+	if p.boltMode() {
+		remoteBoltParamsFile, err = p.uploadBoltParameters(ui, comm)
+		if err != nil {
+			return fmt.Errorf("Error uploading Bolt parameters: %s", err)
+		}
+	} else {
+		remoteManifestFile, err = p.uploadManifests(ui, comm)
+		if err != nil {
+			return fmt.Errorf("Error uploading manifests: %s", err)
+		}
 	}
 
 	// Compile the facter variables
@@ -312,6 +372,9 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 	}
 
 	data := ExecuteTemplate{
+		BoltAction:       "task",
+		BoltName:         p.config.BoltTask,
+		BoltParamsFile:   remoteBoltParamsFile,
 		ExtraArguments:   "",
 		FacterVars:       strings.Join(facterVars, p.guestOSTypeConfig.facterVarsJoiner),
 		HieraConfigPath:  remoteHieraConfigPath,
@@ -323,6 +386,10 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 		Sudo:             !p.config.PreventSudo,
 		WorkingDir:       p.config.WorkingDir,
 	}
+	if p.config.BoltPlan != "" {
+		data.BoltAction = "plan"
+		data.BoltName = p.config.BoltPlan
+	}
 
 	p.config.ctx.Data = &data
 	_ExtraArguments, err := interpolate.Render(strings.Join(p.config.ExtraArguments, " "), &p.config.ctx)
@@ -343,16 +410,24 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 		}
 	}
 
+	var stdout bytes.Buffer
 	cmd := &packer.RemoteCmd{
 		Command: command,
 	}
+	if p.boltMode() {
+		cmd.Stdout = &stdout
+	}
 
 	ui.Message(fmt.Sprintf("Running Puppet: %s", command))
 	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
 		return fmt.Errorf("Got an error starting command: %s", err)
 	}
 
-	if cmd.ExitStatus() != 0 && cmd.ExitStatus() != 2 && !p.config.IgnoreExitCodes {
+	if p.boltMode() {
+		if err := p.handleBoltResult(ui, stdout.Bytes(), cmd.ExitStatus()); err != nil {
+			return err
+		}
+	} else if cmd.ExitStatus() != 0 && cmd.ExitStatus() != 2 && !p.config.IgnoreExitCodes {
 		return fmt.Errorf("Puppet exited with a non-zero exit status: %d", cmd.ExitStatus())
 	}
 
@@ -525,3 +525,113 @@ func TestProvisionerProvision_extraArguments(t *testing.T) {
 		t.Fatalf("Command %q contains an extra-space which may cause arg parsing issues", comm.StartCmd.Command)
 	}
 }
+
+func TestProvisionerPrepare_bolt(t *testing.T) {
+	config := map[string]interface{}{
+		"bolt_task": "package",
+	}
+	p := new(Provisioner)
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if p.config.ExecuteCommand != guestOSTypeConfigs["unix"].boltExecuteCommand {
+		t.Fatalf("should use the bolt command: %s", p.config.ExecuteCommand)
+	}
+
+	config["bolt_plan"] = "profile::base"
+	p = new(Provisioner)
+	if err := p.Prepare(config); err == nil {
+		t.Fatal("should not allow both a task and a plan")
+	}
+
+	config, tempfile := testConfig()
+	defer os.Remove(tempfile.Name())
+	defer tempfile.Close()
+	config["bolt_plan"] = "profile::base"
+	p = new(Provisioner)
+	if err := p.Prepare(config); err == nil {
+		t.Fatal("should not allow a manifest with bolt")
+	}
+
+	config, _ = testConfig()
+	config["bolt_result_path"] = "result.json"
+	p = new(Provisioner)
+	if err := p.Prepare(config); err == nil {
+		t.Fatal("should require bolt for bolt_result_path")
+	}
+}
+
+func TestGuestOSConfig_bolt_unix(t *testing.T) {
+	p := new(Provisioner)
+	err := p.Prepare(map[string]interface{}{"bolt_plan": "profile::base"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	p.config.ctx.Data = &ExecuteTemplate{
+		BoltAction:     "plan",
+		BoltName:       "profile::base",
+		BoltParamsFile: "/tmp/packer-puppet-masterless/bolt-params.json",
+		ModulePath:     "/m/p",
+		Sudo:           true,
+		WorkingDir:     p.config.WorkingDir,
+	}
+	command, err := interpolate.Render(p.config.ExecuteCommand, &p.config.ctx)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := "cd /tmp/packer-puppet-masterless && " +
+		"sudo -E bolt plan run profile::base --targets localhost --format json " +
+		"--modulepath '/m/p' --params '@/tmp/packer-puppet-masterless/bolt-params.json' "
+	assert.Equal(t, expected, command)
+}
+
+func TestProvisionerProvision_bolt(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	resultPath := filepath.Join(td, "result.json")
+	config := map[string]interface{}{
+		"bolt_task":        "package",
+		"bolt_parameters":  map[string]interface{}{"action": "install", "name": "nginx"},
+		"bolt_result_path": resultPath,
+	}
+	p := new(Provisioner)
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := &packer.MachineReadableUi{
+		Writer: ioutil.Discard,
+	}
+	comm := &packer.MockCommunicator{
+		StartStdout: `{"items":[{"target":"localhost","status":"success","value":{"status":"installed"}}]}`,
+	}
+	err = p.Provision(context.Background(), ui, comm, make(map[string]interface{}))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !strings.Contains(comm.StartCmd.Command, "bolt task run package") {
+		t.Fatalf("unexpected command: %s", comm.StartCmd.Command)
+	}
+	if comm.UploadData != `{"action":"install","name":"nginx"}` {
+		t.Fatalf("unexpected parameters: %s", comm.UploadData)
+	}
+	result, err := ioutil.ReadFile(resultPath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(string(result), `"status": "installed"`) {
+		t.Fatalf("unexpected result: %s", result)
+	}
+
+	stdout := []byte(`{"items":[{"target":"localhost","status":"failure","value":{"_error":{"msg":"nginx not found"}}}]}`)
+	err = p.handleBoltResult(ui, stdout, 2)
+	if err == nil || !strings.Contains(err.Error(), "localhost: nginx not found") {
+		t.Fatalf("should report the task failure: %v", err)
+	}
+}
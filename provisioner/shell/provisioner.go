@@ -250,7 +250,7 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 			}
 			remoteVFName := fmt.Sprintf("%s/%s", p.config.RemoteFolder,
 				fmt.Sprintf("varfile_%d.sh", rand.Intn(9999)))
-			if err := comm.Upload(remoteVFName, r, nil); err != nil {
+			if err := comm.Upload(remoteVFName, packer.ContextReader(ctx, r), nil); err != nil {
 				return fmt.Errorf("Error uploading envVarFile: %s", err)
 			}
 			tf.Close()
@@ -311,7 +311,7 @@ func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.C
 				r = &UnixReader{Reader: r}
 			}
 
-			if err := comm.Upload(p.config.RemotePath, r, nil); err != nil {
+			if err := comm.Upload(p.config.RemotePath, packer.ContextReader(ctx, r), nil); err != nil {
 				return fmt.Errorf("Error uploading script: %s", err)
 			}
 
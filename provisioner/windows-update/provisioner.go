@@ -0,0 +1,309 @@
+//go:generate mapstructure-to-hcl2 -type Config
+
+// This package implements a provisioner for Packer that installs Windows
+// updates, restarting the machine between update cycles.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/provisioner"
+	restart "github.com/hashicorp/packer/provisioner/windows-restart"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+var DefaultSearchCriteria = "AutoSelectOnWebSites=1 and IsInstalled=0"
+
+const (
+	remoteScriptPath = `C:/Windows/Temp/packer-windows-update.ps1`
+
+	// Exit statuses of the update script.
+	exitNoUpdates      = 0
+	exitInstalled      = 100
+	exitRebootRequired = 101
+
+	reportLinePrefix = "packer-windows-update: "
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// The Windows Update API search criteria of the updates to install.
+	SearchCriteria string `mapstructure:"search_criteria"`
+
+	// Only install the updates of these KB articles, like KB4052623.
+	IncludeKBs []string `mapstructure:"include_kbs"`
+
+	// Never install the updates of these KB articles.
+	ExcludeKBs []string `mapstructure:"exclude_kbs"`
+
+	// The maximum number of updates installed per cycle.
+	UpdateLimit int `mapstructure:"update_limit"`
+
+	// The maximum number of search, install and restart cycles.
+	MaxCycles int `mapstructure:"max_cycles"`
+
+	// The timeout for waiting for the machine to restart
+	RestartTimeout time.Duration `mapstructure:"restart_timeout"`
+
+	// The local path of a JSON report of the installed updates.
+	ReportPath string `mapstructure:"report_path"`
+
+	ctx interpolate.Context
+}
+
+// InstalledUpdate is an update installed by the provisioner, as listed in
+// its report.
+type InstalledUpdate struct {
+	KBs    []string `json:"kbs"`
+	Title  string   `json:"title"`
+	Result string   `json:"result"`
+	Cycle  int      `json:"cycle"`
+}
+
+// Report is the report of the updates installed by the provisioner.
+type Report struct {
+	Cycles  int               `json:"cycles"`
+	Pending bool              `json:"pending"`
+	Updates []InstalledUpdate `json:"updates"`
+}
+
+type Provisioner struct {
+	config       Config
+	communicator packer.Communicator
+}
+
+func (p *Provisioner) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *Provisioner) Prepare(raws ...interface{}) error {
+	err := config.Decode(&p.config, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &p.config.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{},
+		},
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if p.config.SearchCriteria == "" {
+		p.config.SearchCriteria = DefaultSearchCriteria
+	}
+
+	if p.config.UpdateLimit == 0 {
+		p.config.UpdateLimit = 1000
+	}
+
+	if p.config.MaxCycles == 0 {
+		p.config.MaxCycles = 10
+	}
+
+	if p.config.RestartTimeout == 0 {
+		p.config.RestartTimeout = 4 * time.Hour
+	}
+
+	var errs *packer.MultiError
+
+	if strings.Contains(p.config.SearchCriteria, `"`) {
+		errs = packer.MultiErrorAppend(errs,
+			errors.New("search_criteria can't contain double quotes."))
+	}
+
+	for _, kb := range append(p.config.IncludeKBs, p.config.ExcludeKBs...) {
+		if !strings.HasPrefix(strings.ToUpper(kb), "KB") || strings.ContainsAny(kb, `," `) {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("Bad KB article '%s', it must look like KB4052623.", kb))
+		}
+	}
+
+	if p.config.UpdateLimit < 0 {
+		errs = packer.MultiErrorAppend(errs,
+			errors.New("update_limit must be positive."))
+	}
+
+	if p.config.MaxCycles < 0 {
+		errs = packer.MultiErrorAppend(errs,
+			errors.New("max_cycles must be positive."))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.Communicator, _ map[string]interface{}) error {
+	ui.Say("Installing Windows updates...")
+	p.communicator = comm
+
+	report := &Report{}
+	cycle := 0
+	for {
+		if cycle == p.config.MaxCycles {
+			report.Pending = true
+			ui.Error(fmt.Sprintf("Updates may still be pending after %d update cycles.", cycle))
+			break
+		}
+		cycle++
+		report.Cycles = cycle
+
+		ui.Say(fmt.Sprintf("Windows update cycle %d...", cycle))
+		updates, exitStatus, err := p.runCycle(ctx, ui, comm)
+		if err != nil {
+			return err
+		}
+		for _, u := range updates {
+			u.Cycle = cycle
+			report.Updates = append(report.Updates, u)
+			if u.Result != "succeeded" {
+				ui.Error(fmt.Sprintf("Update %s: %s", u.Result, u.Title))
+			}
+		}
+		if len(updates) > 0 {
+			ui.Message(fmt.Sprintf("Installed %d updates", len(updates)))
+		}
+
+		if exitStatus == exitNoUpdates {
+			ui.Say("No more Windows updates to install.")
+			break
+		}
+		if exitStatus == exitRebootRequired {
+			ui.Say("Restarting the machine to complete the updates...")
+			if err := restartMachine(ctx, ui, comm, p.config.RestartTimeout); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.config.ReportPath != "" {
+		if err := writeReport(p.config.ReportPath, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runCycle runs the update script once, and returns the updates it
+// installed and its exit status.
+func (p *Provisioner) runCycle(ctx context.Context, ui packer.Ui, comm packer.Communicator) ([]InstalledUpdate, int, error) {
+	if err := comm.Upload(remoteScriptPath, strings.NewReader(updateScript), nil); err != nil {
+		return nil, 0, fmt.Errorf("Error uploading the update script: %s", err)
+	}
+
+	command, err := provisioner.GenerateElevatedRunner(p.scriptCommand(), p)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error generating elevated runner: %s", err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := &packer.RemoteCmd{
+		Command: command,
+		Stdout:  &stdout,
+	}
+	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+		return nil, 0, err
+	}
+
+	exitStatus := cmd.ExitStatus()
+	switch exitStatus {
+	case exitNoUpdates, exitInstalled, exitRebootRequired:
+	default:
+		return nil, 0, fmt.Errorf("Windows update script exited with non-zero exit status: %d", exitStatus)
+	}
+
+	return parseInstalledUpdates(&stdout), exitStatus, nil
+}
+
+// scriptCommand returns the command running the update script with the
+// options of the provisioner.
+func (p *Provisioner) scriptCommand() string {
+	return fmt.Sprintf(
+		`powershell -NoProfile -ExecutionPolicy Bypass -File "%s" -SearchCriteria "%s" -Include "%s" -Exclude "%s" -UpdateLimit %d`,
+		remoteScriptPath,
+		p.config.SearchCriteria,
+		strings.Join(p.config.IncludeKBs, ","),
+		strings.Join(p.config.ExcludeKBs, ","),
+		p.config.UpdateLimit)
+}
+
+// parseInstalledUpdates reads the updates installed by the update script
+// from its output.
+func parseInstalledUpdates(r io.Reader) []InstalledUpdate {
+	var updates []InstalledUpdate
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, reportLinePrefix) {
+			continue
+		}
+		fields := strings.SplitN(strings.TrimPrefix(line, reportLinePrefix), "|", 3)
+		if len(fields) != 3 {
+			log.Printf("Unexpected update line: %s", line)
+			continue
+		}
+		u := InstalledUpdate{
+			Result: fields[0],
+			Title:  fields[2],
+		}
+		if fields[1] != "" {
+			u.KBs = strings.Split(fields[1], ",")
+		}
+		updates = append(updates, u)
+	}
+	return updates
+}
+
+func writeReport(path string, report *Report) error {
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unable to marshal the update report: %s", err)
+	}
+	if err := ioutil.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("Unable to write the update report %s: %s", path, err)
+	}
+	return nil
+}
+
+// restartMachine restarts the machine and waits for the communicator to
+// reconnect, with the windows-restart provisioner.
+var restartMachine = func(ctx context.Context, ui packer.Ui, comm packer.Communicator, timeout time.Duration) error {
+	r := new(restart.Provisioner)
+	err := r.Prepare(map[string]interface{}{
+		"restart_timeout": timeout.String(),
+		"check_registry":  true,
+	})
+	if err != nil {
+		return err
+	}
+	return r.Provision(ctx, ui, comm, nil)
+}
+
+func (p *Provisioner) Communicator() packer.Communicator {
+	return p.communicator
+}
+
+// The updates are installed by the SYSTEM account, the Windows Update API
+// refusing to install updates from remote sessions.
+func (p *Provisioner) ElevatedUser() string {
+	return "SYSTEM"
+}
+
+func (p *Provisioner) ElevatedPassword() string {
+	return ""
+}
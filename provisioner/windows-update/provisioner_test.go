@@ -0,0 +1,218 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/packer/packer"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func TestProvisioner_Impl(t *testing.T) {
+	var raw interface{}
+	raw = &Provisioner{}
+	if _, ok := raw.(packer.Provisioner); !ok {
+		t.Fatalf("must be a Provisioner")
+	}
+}
+
+func TestProvisionerPrepare_Defaults(t *testing.T) {
+	var p Provisioner
+	err := p.Prepare(testConfig())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if p.config.SearchCriteria != DefaultSearchCriteria {
+		t.Errorf("unexpected search criteria: %s", p.config.SearchCriteria)
+	}
+	if p.config.UpdateLimit != 1000 {
+		t.Errorf("unexpected update limit: %d", p.config.UpdateLimit)
+	}
+	if p.config.MaxCycles != 10 {
+		t.Errorf("unexpected max cycles: %d", p.config.MaxCycles)
+	}
+	if p.config.RestartTimeout != 4*time.Hour {
+		t.Errorf("unexpected restart timeout: %s", p.config.RestartTimeout)
+	}
+}
+
+func TestProvisionerPrepare_Errors(t *testing.T) {
+	cases := map[string]interface{}{
+		"search_criteria": `Title="foo"`,
+		"include_kbs":     []string{"4052623"},
+		"exclude_kbs":     []string{"KB1,KB2"},
+		"update_limit":    -1,
+		"max_cycles":      -1,
+	}
+	for key, value := range cases {
+		var p Provisioner
+		config := testConfig()
+		config[key] = value
+		if err := p.Prepare(config); err == nil {
+			t.Errorf("%s: should have error", key)
+		}
+	}
+}
+
+func TestProvisioner_scriptCommand(t *testing.T) {
+	var p Provisioner
+	config := testConfig()
+	config["include_kbs"] = []string{"KB1", "KB2"}
+	config["exclude_kbs"] = []string{"KB3"}
+	config["update_limit"] = 5
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := `powershell -NoProfile -ExecutionPolicy Bypass -File "C:/Windows/Temp/packer-windows-update.ps1" ` +
+		`-SearchCriteria "AutoSelectOnWebSites=1 and IsInstalled=0" -Include "KB1,KB2" -Exclude "KB3" -UpdateLimit 5`
+	if cmd := p.scriptCommand(); cmd != expected {
+		t.Fatalf("unexpected command: %s", cmd)
+	}
+}
+
+func TestParseInstalledUpdates(t *testing.T) {
+	output := strings.Join([]string{
+		"Searching for updates matching: IsInstalled=0",
+		"packer-windows-update: succeeded|KB4052623|Update for Microsoft Defender (KB4052623)",
+		"packer-windows-update: failed||Driver update | with a pipe",
+		"packer-windows-update: bad line",
+	}, "\r\n")
+
+	expected := []InstalledUpdate{
+		{KBs: []string{"KB4052623"}, Title: "Update for Microsoft Defender (KB4052623)", Result: "succeeded"},
+		{Title: "Driver update | with a pipe", Result: "failed"},
+	}
+	if diff := cmp.Diff(expected, parseInstalledUpdates(strings.NewReader(output))); diff != "" {
+		t.Fatalf("unexpected updates: %s", diff)
+	}
+}
+
+// cycleCommunicator answers the update commands with a result per cycle.
+type cycleCommunicator struct {
+	packer.MockCommunicator
+	results []cycleResult
+}
+
+type cycleResult struct {
+	stdout     string
+	exitStatus int
+}
+
+func (c *cycleCommunicator) Start(ctx context.Context, rc *packer.RemoteCmd) error {
+	result := c.results[0]
+	c.results = c.results[1:]
+	c.StartStdout = result.stdout
+	c.StartExitStatus = result.exitStatus
+	return c.MockCommunicator.Start(ctx, rc)
+}
+
+func TestProvisionerProvision(t *testing.T) {
+	restarts := 0
+	restartMachine = func(context.Context, packer.Ui, packer.Communicator, time.Duration) error {
+		restarts++
+		return nil
+	}
+
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	var p Provisioner
+	config := testConfig()
+	config["report_path"] = filepath.Join(td, "updates.json")
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	comm := &cycleCommunicator{
+		results: []cycleResult{
+			{"packer-windows-update: succeeded|KB1|First\n", exitRebootRequired},
+			{"packer-windows-update: succeeded|KB2|Second\n", exitInstalled},
+			{"No updates to install.\n", exitNoUpdates},
+		},
+	}
+	err = p.Provision(context.Background(), packer.TestUi(t), comm, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if restarts != 1 {
+		t.Fatalf("should restart once, restarted %d times", restarts)
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(td, "updates.json"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var report Report
+	if err := json.Unmarshal(contents, &report); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := Report{
+		Cycles: 3,
+		Updates: []InstalledUpdate{
+			{KBs: []string{"KB1"}, Title: "First", Result: "succeeded", Cycle: 1},
+			{KBs: []string{"KB2"}, Title: "Second", Result: "succeeded", Cycle: 2},
+		},
+	}
+	if diff := cmp.Diff(expected, report); diff != "" {
+		t.Fatalf("unexpected report: %s", diff)
+	}
+}
+
+func TestProvisionerProvision_maxCycles(t *testing.T) {
+	restartMachine = func(context.Context, packer.Ui, packer.Communicator, time.Duration) error {
+		return nil
+	}
+
+	var p Provisioner
+	config := testConfig()
+	config["max_cycles"] = 2
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	comm := &cycleCommunicator{
+		results: []cycleResult{
+			{"", exitRebootRequired},
+			{"", exitRebootRequired},
+		},
+	}
+	err := p.Provision(context.Background(), packer.TestUi(t), comm, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(comm.results) != 0 {
+		t.Fatalf("should run two cycles")
+	}
+}
+
+func TestProvisionerProvision_scriptError(t *testing.T) {
+	var p Provisioner
+	if err := p.Prepare(testConfig()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	comm := &cycleCommunicator{
+		results: []cycleResult{
+			{"", 1},
+		},
+	}
+	err := p.Provision(context.Background(), packer.TestUi(t), comm, nil)
+	if err == nil {
+		t.Fatal("should error")
+	}
+}
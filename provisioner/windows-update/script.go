@@ -0,0 +1,86 @@
+package update
+
+// updateScript searches for updates, installs the ones allowed by the KB
+// lists, and prints a line per installed update for the provisioner to
+// parse. It exits with exitNoUpdates when there is nothing left to install,
+// exitInstalled when updates were installed and exitRebootRequired when the
+// machine must restart before the next cycle.
+const updateScript = `param(
+    [string]$SearchCriteria,
+    [string]$Include = '',
+    [string]$Exclude = '',
+    [int]$UpdateLimit = 1000
+)
+
+$ErrorActionPreference = 'Stop'
+$ProgressPreference = 'SilentlyContinue'
+
+function Get-KBs($update) {
+    @($update.KBArticleIDs | ForEach-Object { 'KB' + $_ })
+}
+
+$include = @($Include -split ',' | Where-Object { $_ } | ForEach-Object { $_.Trim().ToUpper() })
+$exclude = @($Exclude -split ',' | Where-Object { $_ } | ForEach-Object { $_.Trim().ToUpper() })
+
+if ((New-Object -ComObject 'Microsoft.Update.SystemInfo').RebootRequired) {
+    Write-Output 'A restart is pending before searching for updates.'
+    exit 101
+}
+
+$session = New-Object -ComObject 'Microsoft.Update.Session'
+$session.ClientApplicationID = 'packer-windows-update'
+Write-Output "Searching for updates matching: $SearchCriteria"
+$result = $session.CreateUpdateSearcher().Search($SearchCriteria)
+
+$updates = New-Object -ComObject 'Microsoft.Update.UpdateColl'
+foreach ($update in $result.Updates) {
+    $kbs = Get-KBs $update
+    if ($include.Count -gt 0 -and -not ($kbs | Where-Object { $include -contains $_ })) {
+        Write-Output "Skipping update not included: $($update.Title)"
+        continue
+    }
+    if ($kbs | Where-Object { $exclude -contains $_ }) {
+        Write-Output "Skipping excluded update: $($update.Title)"
+        continue
+    }
+    if ($updates.Count -ge $UpdateLimit) {
+        break
+    }
+    if (-not $update.EulaAccepted) {
+        $update.AcceptEula() | Out-Null
+    }
+    $updates.Add($update) | Out-Null
+}
+
+if ($updates.Count -eq 0) {
+    Write-Output 'No updates to install.'
+    exit 0
+}
+
+Write-Output "Downloading $($updates.Count) updates..."
+$downloader = $session.CreateUpdateDownloader()
+$downloader.Updates = $updates
+$downloader.Download() | Out-Null
+
+Write-Output "Installing $($updates.Count) updates..."
+$installer = $session.CreateUpdateInstaller()
+$installer.Updates = $updates
+$installResult = $installer.Install()
+
+for ($i = 0; $i -lt $updates.Count; $i++) {
+    $update = $updates.Item($i)
+    $status = switch ($installResult.GetUpdateResult($i).ResultCode) {
+        2 { 'succeeded' }
+        3 { 'succeeded_with_errors' }
+        4 { 'failed' }
+        5 { 'aborted' }
+        default { 'unknown' }
+    }
+    Write-Output ('packer-windows-update: {0}|{1}|{2}' -f $status, ((Get-KBs $update) -join ','), $update.Title)
+}
+
+if ($installResult.RebootRequired) {
+    exit 101
+}
+exit 100
+`
@@ -0,0 +1,328 @@
+// Package schema generates JSON schemas of the configs of the components from
+// their HCL2 specs, for external validators, docs generators and UIs.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/fix"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Draft is the JSON schema version of the generated schemas.
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON schema.
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type       string      `json:"type,omitempty"`
+	Const      interface{} `json:"const,omitempty"`
+	Default    interface{} `json:"default,omitempty"`
+	Deprecated bool        `json:"deprecated,omitempty"`
+
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	// AdditionalProperties is a *Schema, or false when the object can only
+	// have its properties.
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+
+	Items    *Schema `json:"items,omitempty"`
+	MinItems int     `json:"minItems,omitempty"`
+	MaxItems int     `json:"maxItems,omitempty"`
+
+	AnyOf       []*Schema          `json:"anyOf,omitempty"`
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// Kind is a kind of component, named after the section of JSON templates
+// listing them.
+type Kind string
+
+const (
+	Builders       Kind = "builders"
+	Provisioners   Kind = "provisioners"
+	PostProcessors Kind = "post-processors"
+)
+
+// Component is a component to generate the schema of.
+type Component struct {
+	Kind Kind
+	Type string
+	Spec hcldec.ObjectSpec
+	// Doc returns the doc of an option, or "", for the descriptions and
+	// defaults of the options. It can be nil.
+	Doc func(option string) string
+}
+
+// commonOptions are the options every component of a kind has in JSON
+// templates, besides its type.
+var commonOptions = map[Kind]map[string]*Schema{
+	Builders: {
+		"name": {Type: "string", Description: "The name of the build, the type of the builder by default."},
+	},
+	Provisioners: {
+		"except":       {Type: "array", Items: &Schema{Type: "string"}, Description: "Builds the provisioner doesn't run for."},
+		"max_retries":  {Type: "number", Description: "How many times to retry the provisioner when it fails."},
+		"only":         {Type: "array", Items: &Schema{Type: "string"}, Description: "Builds the provisioner only runs for."},
+		"override":     {Type: "object", Description: "Options of the provisioner to override for builds, by build name."},
+		"pause_before": {Type: "string", Description: "How long to wait before running the provisioner."},
+		"timeout":      {Type: "string", Description: "How long the provisioner can run before it is cancelled."},
+	},
+	PostProcessors: {
+		"except":              {Type: "array", Items: &Schema{Type: "string"}, Description: "Builds the post-processor doesn't run for."},
+		"keep_input_artifact": {Type: "boolean", Description: "Keep the artifact the post-processor processed."},
+		"name":                {Type: "string", Description: "The name of the post-processor."},
+		"only":                {Type: "array", Items: &Schema{Type: "string"}, Description: "Builds the post-processor only runs for."},
+	},
+}
+
+// Definition returns the name of the definition of the schema of the
+// component in the schema of templates, like builders.amazon-ebs.
+func Definition(kind Kind, typ string) string {
+	return string(kind) + "." + typ
+}
+
+// Template returns the schema of JSON templates using the components, with
+// the schema of each component in its definitions.
+func Template(components []Component) *Schema {
+	s := &Schema{
+		Schema:      Draft,
+		Title:       "Packer template",
+		Type:        "object",
+		Definitions: map[string]*Schema{},
+		Properties:  map[string]*Schema{},
+	}
+	refs := map[Kind][]*Schema{}
+	for _, c := range components {
+		name := Definition(c.Kind, c.Type)
+		s.Definitions[name] = ComponentSchema(c)
+		refs[c.Kind] = append(refs[c.Kind], &Schema{Ref: "#/definitions/" + name})
+	}
+	for _, kind := range []Kind{Builders, Provisioners} {
+		s.Properties[string(kind)] = &Schema{Type: "array", Items: &Schema{AnyOf: refs[kind]}}
+	}
+	// Post-processors can be chained in arrays, or be just their type.
+	pp := append([]*Schema{{Type: "string"}}, refs[PostProcessors]...)
+	pp = append(pp, &Schema{Type: "array", Items: &Schema{AnyOf: refs[PostProcessors]}})
+	s.Properties[string(PostProcessors)] = &Schema{Type: "array", Items: &Schema{AnyOf: pp}}
+	return s
+}
+
+// ComponentSchema returns the schema of the config of the component in JSON
+// templates.
+func ComponentSchema(c Component) *Schema {
+	s := FromSpec(c.Spec)
+	s.Title = fmt.Sprintf("%s %s", strings.TrimSuffix(string(c.Kind), "s"), c.Type)
+	if s.Properties == nil {
+		s.Properties = map[string]*Schema{}
+	}
+	for name, p := range s.Properties {
+		if c.Doc != nil {
+			describe(p, c.Doc(name))
+		}
+		if reason := deprecation(c.Kind, c.Type, name, p); reason != "" {
+			p.Deprecated = true
+			p.Description = strings.TrimSpace("Deprecated: " + reason + "\n\n" + p.Description)
+		}
+	}
+	for name, p := range commonOptions[c.Kind] {
+		if _, ok := s.Properties[name]; !ok {
+			s.Properties[name] = p
+		}
+	}
+	s.Properties["type"] = &Schema{Type: "string", Const: c.Type}
+	s.Required = append([]string{"type"}, s.Required...)
+	return s
+}
+
+// FromSpec returns the schema of the values decoded by spec.
+func FromSpec(spec hcldec.Spec) *Schema {
+	switch spec := spec.(type) {
+	case hcldec.ObjectSpec:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+		for name, child := range spec {
+			s.Properties[name] = FromSpec(child)
+			if required(child) {
+				s.Required = append(s.Required, name)
+			}
+		}
+		sort.Strings(s.Required)
+		return s
+	case *hcldec.AttrSpec:
+		return FromType(spec.Type)
+	case *hcldec.BlockSpec:
+		return FromSpec(spec.Nested)
+	case *hcldec.BlockListSpec:
+		return &Schema{Type: "array", Items: FromSpec(spec.Nested), MinItems: spec.MinItems, MaxItems: spec.MaxItems}
+	case *hcldec.BlockSetSpec:
+		return &Schema{Type: "array", Items: FromSpec(spec.Nested), MinItems: spec.MinItems, MaxItems: spec.MaxItems}
+	case *hcldec.BlockTupleSpec:
+		return &Schema{Type: "array", Items: FromSpec(spec.Nested), MinItems: spec.MinItems, MaxItems: spec.MaxItems}
+	case *hcldec.BlockMapSpec:
+		return &Schema{Type: "object", AdditionalProperties: FromSpec(spec.Nested)}
+	case *hcldec.BlockObjectSpec:
+		return &Schema{Type: "object", AdditionalProperties: FromSpec(spec.Nested)}
+	case *hcldec.BlockAttrsSpec:
+		return &Schema{Type: "object", AdditionalProperties: FromType(spec.ElementType)}
+	case *hcldec.DefaultSpec:
+		return FromSpec(spec.Primary)
+	}
+	// Unknown specs accept any value.
+	return &Schema{}
+}
+
+// required tells whether the option decoded by spec must be set.
+func required(spec hcldec.Spec) bool {
+	switch spec := spec.(type) {
+	case *hcldec.AttrSpec:
+		return spec.Required
+	case *hcldec.BlockSpec:
+		return spec.Required
+	case *hcldec.BlockAttrsSpec:
+		return spec.Required
+	case *hcldec.BlockListSpec:
+		return spec.MinItems > 0
+	case *hcldec.BlockSetSpec:
+		return spec.MinItems > 0
+	case *hcldec.BlockTupleSpec:
+		return spec.MinItems > 0
+	}
+	return false
+}
+
+// FromType returns the schema of the values of type t.
+func FromType(t cty.Type) *Schema {
+	switch {
+	case t == cty.String:
+		return &Schema{Type: "string"}
+	case t == cty.Number:
+		return &Schema{Type: "number"}
+	case t == cty.Bool:
+		return &Schema{Type: "boolean"}
+	case t.IsListType() || t.IsSetType():
+		return &Schema{Type: "array", Items: FromType(t.ElementType())}
+	case t.IsMapType():
+		return &Schema{Type: "object", AdditionalProperties: FromType(t.ElementType())}
+	case t.IsObjectType():
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for name, at := range t.AttributeTypes() {
+			s.Properties[name] = FromType(at)
+		}
+		return s
+	case t.IsTupleType():
+		return &Schema{Type: "array"}
+	}
+	// cty.DynamicPseudoType accepts any value.
+	return &Schema{}
+}
+
+// defaultValue matches the default of an option in its doc, like
+// "Defaults to `10m`.".
+var defaultValue = regexp.MustCompile("[Dd]efaults? (?:to|is) `([^`]+)`")
+
+// describe sets the description of the option of schema s from its doc, and
+// its default when the doc mentions one.
+func describe(s *Schema, doc string) {
+	if doc == "" {
+		return
+	}
+	s.Description = doc
+	m := defaultValue.FindStringSubmatch(doc)
+	if m == nil {
+		return
+	}
+	v := strings.Trim(m[1], `"`)
+	switch s.Type {
+	case "string":
+		s.Default = v
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			s.Default = n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			s.Default = b
+		}
+	}
+}
+
+// deprecation returns why the option of the component is deprecated, "" when
+// it isn't: the option is deprecated when packer fix updates a component
+// setting it.
+func deprecation(kind Kind, typ, option string, s *Schema) string {
+	for _, name := range fix.FixerOrder {
+		fixer := fix.Fixers[name]
+		deprecated := false
+		for _, o := range fixer.DeprecatedOptions() {
+			if o == option {
+				deprecated = true
+				break
+			}
+		}
+		if !deprecated {
+			continue
+		}
+
+		before := map[string]interface{}{
+			string(kind): []interface{}{
+				map[string]interface{}{"type": typ, option: sampleValue(s)},
+			},
+		}
+		input, err := roundTrip(before)
+		if err != nil {
+			continue
+		}
+		output, err := fixer.Fix(input.(map[string]interface{}))
+		if err != nil {
+			continue
+		}
+		after, err := roundTrip(output)
+		if err != nil {
+			continue
+		}
+		expected, _ := roundTrip(before)
+		if !reflect.DeepEqual(expected, after) {
+			return fmt.Sprintf("updated by packer fix with the %q fixer: %s", name, fixer.Synopsis())
+		}
+	}
+	return ""
+}
+
+// sampleValue returns a value of the type of s.
+func sampleValue(s *Schema) interface{} {
+	switch s.Type {
+	case "string":
+		return "value"
+	case "number":
+		return 1
+	case "boolean":
+		return true
+	case "array":
+		return []interface{}{}
+	case "object":
+		return map[string]interface{}{}
+	}
+	return "value"
+}
+
+// roundTrip returns v as decoded from JSON, like the fixers get templates.
+func roundTrip(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	err = json.Unmarshal(raw, &out)
+	return out, err
+}
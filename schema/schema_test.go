@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestComponentSchema(t *testing.T) {
+	c := Component{
+		Kind: Builders,
+		Type: "test",
+		Spec: hcldec.ObjectSpec{
+			"iso_url":          &hcldec.AttrSpec{Name: "iso_url", Type: cty.String, Required: true},
+			"disk_size":        &hcldec.AttrSpec{Name: "disk_size", Type: cty.Number},
+			"headless":         &hcldec.AttrSpec{Name: "headless", Type: cty.Bool},
+			"ssh_wait_timeout": &hcldec.AttrSpec{Name: "ssh_wait_timeout", Type: cty.String},
+			"tags":             &hcldec.AttrSpec{Name: "tags", Type: cty.Map(cty.String)},
+			"disk": &hcldec.BlockListSpec{
+				TypeName: "disk",
+				Nested: hcldec.ObjectSpec{
+					"size": &hcldec.AttrSpec{Name: "size", Type: cty.Number, Required: true},
+				},
+			},
+		},
+		Doc: func(option string) string {
+			switch option {
+			case "disk_size":
+				return "The size of the disk, in MB. Defaults to `40000`."
+			case "headless":
+				return "Whether to hide the console. Default is `false`."
+			}
+			return ""
+		},
+	}
+
+	got := ComponentSchema(c)
+	raw, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual map[string]interface{}
+	if err := json.Unmarshal(raw, &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"title":                "builder test",
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []interface{}{"type", "iso_url"},
+		"properties": map[string]interface{}{
+			"type":    map[string]interface{}{"type": "string", "const": "test"},
+			"name":    map[string]interface{}{"type": "string", "description": "The name of the build, the type of the builder by default."},
+			"iso_url": map[string]interface{}{"type": "string"},
+			"disk_size": map[string]interface{}{
+				"type":        "number",
+				"description": "The size of the disk, in MB. Defaults to `40000`.",
+				"default":     40000.0,
+			},
+			"headless": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Whether to hide the console. Default is `false`.",
+				"default":     false,
+			},
+			"ssh_wait_timeout": map[string]interface{}{
+				"type":        "string",
+				"deprecated":  true,
+				"description": `Deprecated: updated by packer fix with the "ssh-wait-timeout" fixer: Replaces "ssh_wait_timeout" with "ssh_timeout"`,
+			},
+			"tags": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+			},
+			"disk": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": false,
+					"required":             []interface{}{"size"},
+					"properties": map[string]interface{}{
+						"size": map[string]interface{}{"type": "number"},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected schema: %s", diff)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	s := Template([]Component{
+		{Kind: Builders, Type: "file", Spec: hcldec.ObjectSpec{}},
+		{Kind: PostProcessors, Type: "manifest", Spec: hcldec.ObjectSpec{}},
+	})
+	if _, ok := s.Definitions["builders.file"]; !ok {
+		t.Fatalf("missing builder definition: %v", s.Definitions)
+	}
+	if ref := s.Properties["builders"].Items.AnyOf[0].Ref; ref != "#/definitions/builders.file" {
+		t.Fatalf("unexpected builder reference %q", ref)
+	}
+	pp := s.Properties["post-processors"].Items.AnyOf
+	if len(pp) != 3 || pp[0].Type != "string" || pp[1].Ref != "#/definitions/post-processors.manifest" || pp[2].Type != "array" {
+		t.Fatalf("unexpected post-processors: %v", pp)
+	}
+}
@@ -288,12 +288,7 @@ func (c *PluginCommand) Run(args []string) int {
 	pluginType := parts[1] // capture group 1 (builder|post-processor|provisioner)
 	pluginName := parts[2] // capture group 2 (.+)
 
-	server, err := plugin.Server()
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf("Error starting plugin server: %s", err))
-		return 1
-	}
-
+	var components plugin.Components
 	switch pluginType {
 	case "builder":
 		builder, found := Builders[pluginName]
@@ -301,24 +296,27 @@ func (c *PluginCommand) Run(args []string) int {
 			c.Ui.Error(fmt.Sprintf("Could not load builder: %s", pluginName))
 			return 1
 		}
-		server.RegisterBuilder(builder)
+		components.Builder = builder
 	case "provisioner":
 		provisioner, found := Provisioners[pluginName]
 		if !found {
 			c.Ui.Error(fmt.Sprintf("Could not load provisioner: %s", pluginName))
 			return 1
 		}
-		server.RegisterProvisioner(provisioner)
+		components.Provisioner = provisioner
 	case "post-processor":
 		postProcessor, found := PostProcessors[pluginName]
 		if !found {
 			c.Ui.Error(fmt.Sprintf("Could not load post-processor: %s", pluginName))
 			return 1
 		}
-		server.RegisterPostProcessor(postProcessor)
+		components.PostProcessor = postProcessor
 	}
 
-	server.Serve()
+	if err := plugin.Serve(components); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error serving plugin: %s", err))
+		return 1
+	}
 
 	return 0
 }
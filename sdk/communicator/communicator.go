@@ -0,0 +1,38 @@
+// Package communicator configures the communicator of a builder and
+// connects it; it is the stable API of the communicator helper of Packer.
+package communicator
+
+import (
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+type (
+	// Config is the configuration of the communicator, to embed with
+	// `mapstructure:",squash"` in the configuration of a builder.
+	Config = communicator.Config
+	// SSH is the SSH configuration of Config.
+	SSH = communicator.SSH
+	// WinRM is the WinRM configuration of Config.
+	WinRM = communicator.WinRM
+
+	// StepConnect connects the communicator of Config, and puts it in
+	// the "communicator" key of the state bag, and its Connection in the
+	// "connection" key.
+	StepConnect = communicator.StepConnect
+	// Connection is the handle of the connection to the guest, to run
+	// commands, upload files and open tunnels during the build.
+	Connection = communicator.Connection
+)
+
+// ConnectionFromState returns the Connection of the state bag, or nil.
+func ConnectionFromState(state multistep.StateBag) *Connection {
+	return communicator.ConnectionFromState(state)
+}
+
+// CommHost returns the function StepConnect uses to get the host to
+// connect to: host when set, else the string of the state bag at
+// statebagKey.
+func CommHost(host string, statebagKey string) func(multistep.StateBag) (string, error) {
+	return communicator.CommHost(host, statebagKey)
+}
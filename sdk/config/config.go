@@ -0,0 +1,30 @@
+// Package config decodes the configuration of a component; it is the
+// stable API of the config helper of Packer.
+package config
+
+import (
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+type (
+	// PackerConfig holds the configuration Packer sends to every
+	// component, to embed with `mapstructure:",squash"`.
+	PackerConfig = common.PackerConfig
+	// DecodeOpts are the options of Decode.
+	DecodeOpts = config.DecodeOpts
+	// Trilean is a boolean that can be unset.
+	Trilean = config.Trilean
+
+	// InterpolateContext is the context templates are interpolated with.
+	InterpolateContext = interpolate.Context
+	// RenderFilter selects the keys that are interpolated.
+	RenderFilter = interpolate.RenderFilter
+)
+
+// Decode decodes the raw configurations Prepare or Configure get into
+// target, interpolating templates when opts tells to.
+func Decode(target interface{}, opts *DecodeOpts, raws ...interface{}) error {
+	return config.Decode(target, opts, raws...)
+}
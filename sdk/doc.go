@@ -0,0 +1,22 @@
+// Package sdk is the stable API of Packer for the authors of external
+// plugins.
+//
+// Plugins should only import the packages of sdk and the packer package of
+// the interfaces they implement, instead of the helper and common packages
+// of Packer, which can change between releases. The sdk packages re-export
+// what plugins need:
+//
+//   - sdk/config decodes the configuration of a component.
+//   - sdk/multistep runs the steps of a builder.
+//   - sdk/communicator configures and connects the communicators.
+//   - sdk/plugin serves the components of a plugin binary.
+//
+// The HCL2 spec of a configuration is generated with
+//
+//   go run github.com/hashicorp/packer/cmd/mapstructure-to-hcl2 -type Config
+//
+// and `packer plugin scaffold` generates the skeleton of a new plugin.
+//
+// Identifiers of the sdk packages are only removed or changed in a major
+// version of Packer. The sdk packages are part of the Packer module.
+package sdk
@@ -0,0 +1,50 @@
+// Package multistep runs the steps of a builder; it is the stable API of
+// the multistep helper of Packer.
+package multistep
+
+import (
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+type (
+	// Step is a step of a build.
+	Step = multistep.Step
+	// StepAction tells if the steps continue after a step.
+	StepAction = multistep.StepAction
+	// StateBag holds the state shared by the steps.
+	StateBag = multistep.StateBag
+	// BasicStateBag is a StateBag.
+	BasicStateBag = multistep.BasicStateBag
+	// Runner runs steps.
+	Runner = multistep.Runner
+)
+
+const (
+	ActionContinue = multistep.ActionContinue
+	ActionHalt     = multistep.ActionHalt
+
+	// StateCancelled is set in the state bag when the steps were
+	// cancelled.
+	StateCancelled = multistep.StateCancelled
+	// StateHalted is set in the state bag when a step halted.
+	StateHalted = multistep.StateHalted
+)
+
+// NewRunner returns the runner of steps for the Packer configuration of a
+// builder: it honors the -debug, -on-error and -breakpoint options of
+// packer build.
+func NewRunner(steps []Step, config common.PackerConfig, ui packer.Ui) Runner {
+	return common.NewRunner(steps, config, ui)
+}
+
+// NewRunnerWithPauseFn is NewRunner, pausing between steps in debug mode
+// with the pause function of state, if any.
+func NewRunnerWithPauseFn(steps []Step, config common.PackerConfig, ui packer.Ui, state StateBag) Runner {
+	return common.NewRunnerWithPauseFn(steps, config, ui, state)
+}
+
+// StepProvision runs the provisioners of the build, through the provision
+// hook. It needs the "hook" and "communicator" of the state bag.
+type StepProvision = common.StepProvision
@@ -0,0 +1,17 @@
+// Package plugin serves the components of a plugin binary; it is the
+// stable API of the plugin package of Packer.
+package plugin
+
+import (
+	"github.com/hashicorp/packer/packer/plugin"
+)
+
+// Components are the components a plugin binary serves.
+type Components = plugin.Components
+
+// Serve serves the components of a plugin, over gRPC when the Packer that
+// started the plugin speaks it, else over net/rpc. It returns when Packer
+// is done with the plugin.
+func Serve(c Components) error {
+	return plugin.Serve(c)
+}
@@ -0,0 +1,155 @@
+// Package scaffold generates the skeleton of a new plugin, built on the
+// packages of the SDK.
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"text/template"
+)
+
+// Kind is the kind of component a plugin serves.
+type Kind string
+
+const (
+	Builder       Kind = "builder"
+	Provisioner   Kind = "provisioner"
+	PostProcessor Kind = "post-processor"
+)
+
+// Kinds are the kinds of components that can be scaffolded.
+var Kinds = []Kind{Builder, Provisioner, PostProcessor}
+
+// Options are the options of Generate.
+type Options struct {
+	// Kind is the kind of the component.
+	Kind Kind
+	// Name is the name of the component, a lower case Go identifier; the
+	// plugin binary is packer-plugin-Name.
+	Name string
+	// Module is the path of the Go module of the plugin,
+	// github.com/<you>/packer-plugin-Name by default.
+	Module string
+	// PackerVersion is the version of Packer the plugin requires, the
+	// latest one when empty.
+	PackerVersion string
+}
+
+var nameRe = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// Validate checks the options, and sets the default module.
+func (o *Options) Validate() error {
+	switch o.Kind {
+	case Builder, Provisioner, PostProcessor:
+	default:
+		return fmt.Errorf("unknown kind %q, expected one of %s", o.Kind, Kinds)
+	}
+	if !nameRe.MatchString(o.Name) {
+		return fmt.Errorf("invalid name %q: it must be lower case letters and digits, starting with a letter", o.Name)
+	}
+	if o.Module == "" {
+		o.Module = "github.com/<you>/packer-plugin-" + o.Name
+	}
+	return nil
+}
+
+// templateData is what the templates of a plugin are executed with.
+type templateData struct {
+	Options
+	// Package is the package of the component.
+	Package string
+	// Field is the field of the component in plugin.Components, and its
+	// type.
+	Field string
+	// ID is the id of the artifacts of a builder.
+	ID string
+}
+
+// Files returns the files of the plugin of opts, by path relative to the
+// directory of the plugin. The Go files are formatted.
+func Files(opts Options) (map[string][]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	data := templateData{
+		Options: opts,
+		Package: opts.Name,
+		ID:      "packer.plugin." + opts.Name,
+	}
+
+	var templates map[string]string
+	switch opts.Kind {
+	case Builder:
+		data.Field, templates = "Builder", builderTemplates
+	case Provisioner:
+		data.Field, templates = "Provisioner", provisionerTemplates
+	case PostProcessor:
+		data.Field, templates = "PostProcessor", postProcessorTemplates
+	}
+
+	files := map[string][]byte{}
+	add := func(path, text string) error {
+		t, err := template.New(path).Parse(text)
+		if err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		if err := t.Execute(&b, data); err != nil {
+			return err
+		}
+		content := b.Bytes()
+		if filepath.Ext(path) == ".go" {
+			if content, err = format.Source(content); err != nil {
+				return fmt.Errorf("%s: %s", path, err)
+			}
+		}
+		files[path] = content
+		return nil
+	}
+	for path, text := range commonTemplates {
+		if err := add(path, text); err != nil {
+			return nil, err
+		}
+	}
+	for path, text := range templates {
+		if err := add(filepath.Join(opts.Name, path), text); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Generate writes the files of the plugin of opts in dir, and returns their
+// paths. It doesn't overwrite files.
+func Generate(dir string, opts Options) ([]string, error) {
+	files, err := Files(opts)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		path = filepath.Join(dir, path)
+		if _, err := os.Stat(path); err == nil {
+			return nil, fmt.Errorf("%s already exists", path)
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
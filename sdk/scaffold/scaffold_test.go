@@ -0,0 +1,75 @@
+package scaffold
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFiles(t *testing.T) {
+	for _, kind := range Kinds {
+		t.Run(string(kind), func(t *testing.T) {
+			files, err := Files(Options{Kind: kind, Name: "hello", Module: "example.com/packer-plugin-hello", PackerVersion: "1.6.0"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, path := range []string{"main.go", "go.mod", "README.md", filepath.Join("hello", "config.go")} {
+				if _, ok := files[path]; !ok {
+					t.Errorf("missing %s", path)
+				}
+			}
+			main := string(files["main.go"])
+			if !strings.Contains(main, `"example.com/packer-plugin-hello/hello"`) {
+				t.Errorf("main.go doesn't import the component:\n%s", main)
+			}
+			if !strings.Contains(string(files["go.mod"]), "require github.com/hashicorp/packer v1.6.0") {
+				t.Errorf("go.mod doesn't require packer:\n%s", files["go.mod"])
+			}
+			if !strings.Contains(string(files[filepath.Join("hello", "config.go")]), "//go:generate") {
+				t.Error("config.go doesn't generate its HCL2 spec")
+			}
+		})
+	}
+}
+
+func TestFiles_invalid(t *testing.T) {
+	for _, opts := range []Options{
+		{Kind: "builders", Name: "hello"},
+		{Kind: Builder, Name: ""},
+		{Kind: Builder, Name: "Hello"},
+		{Kind: Builder, Name: "hello-world"},
+		{Kind: Builder, Name: "1hello"},
+	} {
+		if _, err := Files(opts); err == nil {
+			t.Errorf("%#v: expected an error", opts)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scaffold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := Options{Kind: Provisioner, Name: "hello"}
+	paths, err := Generate(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no file generated")
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := Generate(dir, opts); err == nil {
+		t.Fatal("expected an error overwriting files")
+	}
+}
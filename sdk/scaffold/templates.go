@@ -0,0 +1,355 @@
+package scaffold
+
+// The templates of the files of a plugin, by path: the common templates
+// are at the root of the plugin, the others in the package of the
+// component.
+
+var commonTemplates = map[string]string{
+	"go.mod": `module {{.Module}}
+
+{{if .PackerVersion}}
+require github.com/hashicorp/packer v{{.PackerVersion}}
+{{end}}`,
+
+	"main.go": `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/packer/sdk/plugin"
+
+	"{{.Module}}/{{.Package}}"
+)
+
+func main() {
+	err := plugin.Serve(plugin.Components{
+		{{.Field}}: new({{.Package}}.{{.Field}}),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`,
+
+	"README.md": `# packer-plugin-{{.Name}}
+
+The {{.Name}} {{.Kind}} of Packer.
+
+## Build
+
+The HCL2 spec of the configuration, in {{.Name}}/config.hcl2spec.go, is
+generated from the Config struct:
+
+    go mod tidy
+    go generate ./...
+    go build -o packer-plugin-{{.Name}}
+
+Run ` + "`go generate ./...`" + ` again after changing the Config struct.
+
+## Install
+
+Copy packer-plugin-{{.Name}} in a plugin directory of Packer, like
+~/.packer.d/plugins, and use the "{{.Name}}" {{.Kind}} in your templates.
+`,
+}
+
+var builderTemplates = map[string]string{
+	"config.go": `//go:generate go run github.com/hashicorp/packer/cmd/mapstructure-to-hcl2 -type Config
+
+package {{.Package}}
+
+import (
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/sdk/communicator"
+	"github.com/hashicorp/packer/sdk/config"
+)
+
+// Config is the configuration of the builder.
+type Config struct {
+	config.PackerConfig ` + "`mapstructure:\",squash\"`" + `
+	Comm                communicator.Config ` + "`mapstructure:\",squash\"`" + `
+
+	// The message the builder says. Defaults to "Hello".
+	Message string ` + "`mapstructure:\"message\"`" + `
+
+	ctx config.InterpolateContext
+}
+
+// Prepare decodes and validates the configuration.
+func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Message == "" {
+		c.Message = "Hello"
+	}
+
+	// The builder doesn't create a machine to connect to, unless a host
+	// is configured.
+	if c.Comm.Type == "" && c.Comm.Host() == "" {
+		c.Comm.Type = "none"
+	}
+
+	var errs *packer.MultiError
+	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, errs
+	}
+	return nil, nil
+}
+`,
+
+	"builder.go": `package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/sdk/communicator"
+	"github.com/hashicorp/packer/sdk/multistep"
+)
+
+// BuilderId is the id of the artifacts of the builder.
+const BuilderId = "{{.ID}}"
+
+// Builder is the {{.Name}} builder.
+type Builder struct {
+	config Config
+	runner multistep.Runner
+}
+
+var _ packer.Builder = new(Builder)
+
+func (b *Builder) ConfigSpec() hcldec.ObjectSpec { return b.config.FlatMapstructure().HCL2Spec() }
+
+func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
+	warnings, err := b.config.Prepare(raws...)
+	if err != nil {
+		return nil, warnings, err
+	}
+	return nil, warnings, nil
+}
+
+func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
+	steps := []multistep.Step{
+		&stepSay{Message: b.config.Message},
+		&communicator.StepConnect{
+			Config:    &b.config.Comm,
+			Host:      communicator.CommHost(b.config.Comm.Host(), "host"),
+			SSHConfig: b.config.Comm.SSHConfigFunc(),
+		},
+		new(multistep.StepProvision),
+	}
+
+	state := new(multistep.BasicStateBag)
+	state.Put("config", &b.config)
+	state.Put("hook", hook)
+	state.Put("ui", ui)
+
+	b.runner = multistep.NewRunner(steps, b.config.PackerConfig, ui)
+	b.runner.Run(ctx, state)
+
+	if err, ok := state.GetOk("error"); ok {
+		return nil, err.(error)
+	}
+	return &Artifact{Message: b.config.Message}, nil
+}
+`,
+
+	"step_say.go": `package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/sdk/multistep"
+)
+
+// stepSay says a message.
+type stepSay struct {
+	Message string
+}
+
+func (s *stepSay) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+	ui.Say(s.Message)
+	return multistep.ActionContinue
+}
+
+func (s *stepSay) Cleanup(state multistep.StateBag) {}
+`,
+
+	"artifact.go": `package {{.Package}}
+
+import "fmt"
+
+// Artifact is the artifact of the builder.
+type Artifact struct {
+	Message string
+}
+
+func (*Artifact) BuilderId() string { return BuilderId }
+
+func (*Artifact) Files() []string { return nil }
+
+func (a *Artifact) Id() string { return a.Message }
+
+func (a *Artifact) String() string { return fmt.Sprintf("Said %q", a.Message) }
+
+func (*Artifact) State(name string) interface{} { return nil }
+
+func (*Artifact) Destroy() error { return nil }
+`,
+}
+
+var provisionerTemplates = map[string]string{
+	"config.go": `//go:generate go run github.com/hashicorp/packer/cmd/mapstructure-to-hcl2 -type Config
+
+package {{.Package}}
+
+import (
+	"github.com/hashicorp/packer/sdk/config"
+)
+
+// Config is the configuration of the provisioner.
+type Config struct {
+	config.PackerConfig ` + "`mapstructure:\",squash\"`" + `
+
+	// The message the provisioner echoes on the machine. Defaults to
+	// "Hello".
+	Message string ` + "`mapstructure:\"message\"`" + `
+
+	ctx config.InterpolateContext
+}
+
+// Prepare decodes and validates the configuration.
+func (c *Config) Prepare(raws ...interface{}) error {
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if c.Message == "" {
+		c.Message = "Hello"
+	}
+	return nil
+}
+`,
+
+	"provisioner.go": `package {{.Package}}
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/packer"
+)
+
+// Provisioner is the {{.Name}} provisioner.
+type Provisioner struct {
+	config Config
+}
+
+var _ packer.Provisioner = new(Provisioner)
+
+func (p *Provisioner) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *Provisioner) Prepare(raws ...interface{}) error {
+	return p.config.Prepare(raws...)
+}
+
+func (p *Provisioner) Provision(ctx context.Context, ui packer.Ui, comm packer.Communicator, generatedData map[string]interface{}) error {
+	ui.Say(fmt.Sprintf("Echoing %q on the machine...", p.config.Message))
+	cmd := &packer.RemoteCmd{Command: fmt.Sprintf("echo %q", p.config.Message)}
+	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+		return err
+	}
+	if status := cmd.ExitStatus(); status != 0 {
+		return fmt.Errorf("echo exited with status %d", status)
+	}
+	return nil
+}
+`,
+}
+
+var postProcessorTemplates = map[string]string{
+	"config.go": `//go:generate go run github.com/hashicorp/packer/cmd/mapstructure-to-hcl2 -type Config
+
+package {{.Package}}
+
+import (
+	"github.com/hashicorp/packer/sdk/config"
+)
+
+// Config is the configuration of the post-processor.
+type Config struct {
+	config.PackerConfig ` + "`mapstructure:\",squash\"`" + `
+
+	// The message the post-processor says about the artifact. Defaults
+	// to "Post-processing".
+	Message string ` + "`mapstructure:\"message\"`" + `
+
+	ctx config.InterpolateContext
+}
+
+// Prepare decodes and validates the configuration.
+func (c *Config) Prepare(raws ...interface{}) error {
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if c.Message == "" {
+		c.Message = "Post-processing"
+	}
+	return nil
+}
+`,
+
+	"post-processor.go": `package {{.Package}}
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer/packer"
+)
+
+// PostProcessor is the {{.Name}} post-processor.
+type PostProcessor struct {
+	config Config
+}
+
+var _ packer.PostProcessor = new(PostProcessor)
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	return p.config.Prepare(raws...)
+}
+
+// PostProcess says the message, and keeps the artifact unchanged.
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, bool, error) {
+	ui.Say(fmt.Sprintf("%s %s", p.config.Message, artifact.Id()))
+	return artifact, true, false, nil
+}
+`,
+}
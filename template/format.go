@@ -0,0 +1,171 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// topLevelKeyOrder is the order of the known keys of a template, once
+// formatted. Comments come first and unknown keys last.
+var topLevelKeyOrder = []string{
+	"description",
+	"min_packer_version",
+	"variables",
+	"sensitive-variables",
+	"build_timeout",
+	"provision_timeout",
+	"post_process_timeout",
+	"error_handling",
+	"builders",
+	"provisioners",
+	"error-cleanup-provisioner",
+	"post-processors",
+}
+
+// componentKeyOrder is the order of the keys coming first in builders,
+// provisioners and post-processors; the other keys are sorted.
+var componentKeyOrder = []string{"type", "name"}
+
+// Format returns the canonical formatting of a JSON template: indented with
+// two spaces, with its top level keys in a stable order, the type and name
+// of each component first and the other keys sorted. The order of lists,
+// like the provisioners, is kept.
+func Format(src []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("Error parsing JSON: %s", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("Error parsing JSON: unexpected data after the template")
+	}
+
+	f := &formatter{}
+	f.object(raw, topLevelOrder(raw), 0)
+	f.buf.WriteString("\n")
+	return f.buf.Bytes(), nil
+}
+
+func topLevelOrder(m map[string]interface{}) []string {
+	var comments, known, unknown []string
+	rank := map[string]int{}
+	for i, k := range topLevelKeyOrder {
+		rank[k] = i
+	}
+	for k := range m {
+		switch _, ok := rank[k]; {
+		case strings.HasPrefix(k, "_"):
+			comments = append(comments, k)
+		case ok:
+			known = append(known, k)
+		default:
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(comments)
+	sort.Slice(known, func(i, j int) bool { return rank[known[i]] < rank[known[j]] })
+	sort.Strings(unknown)
+	return append(append(comments, known...), unknown...)
+}
+
+// componentOrder returns the keys of a builder, provisioner or
+// post-processor in their formatted order.
+func componentOrder(m map[string]interface{}) []string {
+	var keys []string
+	for _, k := range componentKeyOrder {
+		if _, ok := m[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	var rest []string
+	for k := range m {
+		if k != "type" && k != "name" {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+type formatter struct {
+	buf bytes.Buffer
+}
+
+func (f *formatter) indent(depth int) {
+	f.buf.WriteString("\n" + strings.Repeat("  ", depth))
+}
+
+// object writes m with its keys in order. The elements of the lists of
+// components of a template get their keys in the component order.
+func (f *formatter) object(m map[string]interface{}, keys []string, depth int) {
+	if len(m) == 0 {
+		f.buf.WriteString("{}")
+		return
+	}
+	f.buf.WriteString("{")
+	for i, k := range keys {
+		if i > 0 {
+			f.buf.WriteString(",")
+		}
+		f.indent(depth + 1)
+		f.scalar(k)
+		f.buf.WriteString(": ")
+		components := false
+		if depth == 0 {
+			switch k {
+			case "builders", "provisioners", "post-processors", "error-cleanup-provisioner":
+				components = true
+			}
+		}
+		f.value(m[k], components, depth+1)
+	}
+	f.indent(depth)
+	f.buf.WriteString("}")
+}
+
+func (f *formatter) value(v interface{}, components bool, depth int) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if components {
+			f.object(v, componentOrder(v), depth)
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		f.object(v, keys, depth)
+	case []interface{}:
+		if len(v) == 0 {
+			f.buf.WriteString("[]")
+			return
+		}
+		f.buf.WriteString("[")
+		for i, e := range v {
+			if i > 0 {
+				f.buf.WriteString(",")
+			}
+			f.indent(depth + 1)
+			// Post-processors can be nested in sequences.
+			f.value(e, components, depth+1)
+		}
+		f.indent(depth)
+		f.buf.WriteString("]")
+	default:
+		f.scalar(v)
+	}
+}
+
+func (f *formatter) scalar(v interface{}) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// Values decoded from JSON can always be encoded.
+	_ = enc.Encode(v)
+	f.buf.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
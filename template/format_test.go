@@ -0,0 +1,43 @@
+package template
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormat(t *testing.T) {
+	src, err := ioutil.ReadFile(fixtureDir("format-unformatted.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile(fixtureDir("format-formatted.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Format(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("wrong formatting: %s", diff)
+	}
+
+	again, err := Format(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(got), string(again)); diff != "" {
+		t.Errorf("formatting is not stable: %s", diff)
+	}
+}
+
+func TestFormat_invalid(t *testing.T) {
+	for _, src := range []string{`{`, `[]`, `{} {}`} {
+		if _, err := Format([]byte(src)); err == nil {
+			t.Errorf("Format(%q): expected an error", src)
+		}
+	}
+}
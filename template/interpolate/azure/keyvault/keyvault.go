@@ -0,0 +1,90 @@
+// Package keyvault provide methods to get data from
+// Azure Key Vault
+package keyvault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	commonhelper "github.com/hashicorp/packer/helper/common"
+)
+
+const (
+	apiVersion = "7.0"
+	resource   = "https://vault.azure.net"
+)
+
+// Client represents an Azure Key Vault client
+type Client struct {
+	// BaseURLFormat is used to build the vault URL from the
+	// vault name. It defaults to the public Azure cloud.
+	BaseURLFormat string
+
+	authorizer autorest.Authorizer
+	httpClient *http.Client
+}
+
+// New creates an Azure Key Vault Client. Credentials are read from
+// the environment (AZURE_CLIENT_ID, AZURE_CLIENT_SECRET,
+// AZURE_TENANT_ID, ...) or from a managed identity when available.
+func New() (*Client, error) {
+	authorizer, err := auth.NewAuthorizerFromEnvironmentWithResource(resource)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		BaseURLFormat: "https://%s.vault.azure.net",
+		authorizer:    authorizer,
+		httpClient:    commonhelper.HttpClient(),
+	}, nil
+}
+
+// GetSecret return an Azure Key Vault secret
+// in plain text from a given secret name
+func (c *Client) GetSecret(spec *SecretSpec) (string, error) {
+	if spec.VaultName == "" || spec.Name == "" {
+		return "", errors.New("A vault name and a secret name must be set")
+	}
+
+	u := fmt.Sprintf(c.BaseURLFormat, spec.VaultName) + "/secrets/" + url.PathEscape(spec.Name)
+	if spec.Version != "" {
+		u += "/" + url.PathEscape(spec.Version)
+	}
+	u += "?api-version=" + apiVersion
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return "", err
+	}
+	if c.authorizer != nil {
+		req, err = autorest.Prepare(req, c.authorizer.WithAuthorization())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Unexpected status reading secret %q: %s", spec.Name, resp.Status)
+	}
+
+	var bundle secretBundle
+	if err := json.NewDecoder(resp.Body).Decode(&bundle); err != nil {
+		return "", err
+	}
+	if bundle.Value == nil {
+		return "", errors.New("No secret found")
+	}
+
+	return *bundle.Value, nil
+}
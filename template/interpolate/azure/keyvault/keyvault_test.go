@@ -0,0 +1,81 @@
+package keyvault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testClient(t *testing.T, h http.HandlerFunc) *Client {
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	return &Client{
+		BaseURLFormat: strings.Replace(ts.URL, "%", "%%", -1) + "/%s",
+		httpClient:    ts.Client(),
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	testCases := []struct {
+		description string
+		arg         *SecretSpec
+		status      int
+		body        string
+		wantPath    string
+		want        string
+		ok          bool
+	}{
+		{
+			description: "latest version",
+			arg:         &SecretSpec{VaultName: "myvault", Name: "db-password"},
+			status:      200,
+			body:        `{"value": "test"}`,
+			wantPath:    "/myvault/secrets/db-password",
+			want:        "test",
+			ok:          true,
+		},
+		{
+			description: "explicit version",
+			arg:         &SecretSpec{VaultName: "myvault", Name: "db-password", Version: "abc"},
+			status:      200,
+			body:        `{"value": "old"}`,
+			wantPath:    "/myvault/secrets/db-password/abc",
+			want:        "old",
+			ok:          true,
+		},
+		{
+			description: "secret not found",
+			arg:         &SecretSpec{VaultName: "myvault", Name: "nope"},
+			status:      404,
+			body:        `{}`,
+			wantPath:    "/myvault/secrets/nope",
+			ok:          false,
+		},
+		{
+			description: "missing vault name",
+			arg:         &SecretSpec{Name: "db-password"},
+			ok:          false,
+		},
+	}
+
+	for _, test := range testCases {
+		c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != test.wantPath {
+				t.Errorf("%s: bad path %q", test.description, r.URL.Path)
+			}
+			if r.URL.Query().Get("api-version") != apiVersion {
+				t.Errorf("%s: bad api version %q", test.description, r.URL.RawQuery)
+			}
+			w.WriteHeader(test.status)
+			w.Write([]byte(test.body))
+		})
+		got, err := c.GetSecret(test.arg)
+		if test.ok != (err == nil) {
+			t.Fatalf("%s: unexpected error state: %v", test.description, err)
+		}
+		if got != test.want {
+			t.Fatalf("%s: expected %q, got %q", test.description, test.want, got)
+		}
+	}
+}
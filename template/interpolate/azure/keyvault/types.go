@@ -0,0 +1,16 @@
+package keyvault
+
+// SecretSpec represent specs of secret to be searched.
+// If Version is not set then the latest version of the
+// secret is returned.
+type SecretSpec struct {
+	VaultName string
+	Name      string
+	Version   string
+}
+
+// secretBundle is the subset of the Key Vault secret bundle
+// we care about.
+type secretBundle struct {
+	Value *string `json:"value"`
+}
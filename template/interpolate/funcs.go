@@ -1,6 +1,7 @@
 package interpolate
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -13,10 +14,11 @@ import (
 	consulapi "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/packer/common/uuid"
 	"github.com/hashicorp/packer/helper/common"
-	awssmapi "github.com/hashicorp/packer/template/interpolate/aws/secretsmanager"
+	"github.com/hashicorp/packer/helper/totp"
 	"github.com/hashicorp/packer/version"
-	vaultapi "github.com/hashicorp/vault/api"
 	strftime "github.com/jehiah/go-strftime"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
 )
 
 // InitTime is the UTC time when this package was initialized. It is
@@ -46,13 +48,30 @@ var FuncGens = map[string]interface{}{
 	"vault":              funcGenVault,
 	"sed":                funcGenSed,
 	"build":              funcGenBuild,
+	"build_output":       funcGenBuildOutput,
+	"build_uuid":         funcGenBuildInfo("build_uuid"),
+	"build_dir":          funcGenBuildInfo("build_dir"),
+	"build_start":        funcGenBuildStart,
+	"git_commit":         funcGenBuildInfo("git_commit"),
+	"git_short_commit":   funcGenBuildInfo("git_short_commit"),
+	"git_branch":         funcGenBuildInfo("git_branch"),
+	"git_dirty":          funcGenBuildInfo("git_dirty"),
 	"aws_secretsmanager": funcGenAwsSecrets,
+	"azure_keyvault":     funcGenAzureKeyVault,
+	"gcp_secretmanager":  funcGenGCPSecretManager,
+	"totp":               funcGenTOTP,
 
 	"replace":     replace,
 	"replace_all": replace_all,
 
 	"upper": strings.ToUpper,
 	"lower": strings.ToLower,
+
+	// Functions of HCL2 templates, which behave the same in JSON templates.
+	"formatdate":    formatdate,
+	"jsonencode":    jsonencode,
+	"lookup":        lookup,
+	"regex_replace": regexReplace,
 }
 
 var ErrVariableNotSetString = "Error: variable not set:"
@@ -237,6 +256,56 @@ func funcGenUser(ctx *Context) interface{} {
 	}
 }
 
+func funcGenBuildOutput(ctx *Context) interface{} {
+	return func(build, k string) (string, error) {
+		if ctx == nil || ctx.BuildOutputs == nil {
+			return "", fmt.Errorf("build_output: the outputs of build %q are only available to builds that depend on it", build)
+		}
+		val, ok := ctx.BuildOutputs[build+"."+k]
+		if !ok {
+			return "", fmt.Errorf("build_output: build %q has no output %q", build, k)
+		}
+		return val, nil
+	}
+}
+
+// funcGenBuildInfo generates the functions reading the build info of key.
+func funcGenBuildInfo(key string) func(*Context) interface{} {
+	return func(ctx *Context) interface{} {
+		return func() (string, error) {
+			if ctx == nil || ctx.BuildInfo == nil {
+				return "", fmt.Errorf("%s not available", key)
+			}
+			return ctx.BuildInfo[key], nil
+		}
+	}
+}
+
+func funcGenBuildStart(ctx *Context) interface{} {
+	return func(format ...string) (string, error) {
+		if ctx == nil || ctx.BuildInfo == nil {
+			return "", errors.New("build_start not available")
+		}
+		start, err := time.Parse(time.RFC3339, ctx.BuildInfo["build_start"])
+		if err != nil {
+			return "", err
+		}
+
+		if len(format) == 0 {
+			return start.Format(time.RFC3339), nil
+		}
+
+		if len(format) > 1 {
+			return "", fmt.Errorf("too many values, 1 needed: %v", format)
+		}
+
+		if format[0] == "unix" {
+			return strconv.FormatInt(start.Unix(), 10), nil
+		}
+		return start.Format(format[0]), nil
+	}
+}
+
 func funcGenUuid(ctx *Context) interface{} {
 	return func() string {
 		return uuid.TimeOrderedUUID()
@@ -289,40 +358,7 @@ func funcGenVault(ctx *Context) interface{} {
 			// semantic checks should catch this.
 			return "", errors.New("Vault vars are only allowed in the variables section")
 		}
-		if token := os.Getenv("VAULT_TOKEN"); token == "" {
-			return "", errors.New("Must set VAULT_TOKEN env var in order to " +
-				"use vault template function")
-		}
-		// const EnvVaultAddress = "VAULT_ADDR"
-		// const EnvVaultToken = "VAULT_TOKEN"
-		vaultConfig := vaultapi.DefaultConfig()
-		cli, err := vaultapi.NewClient(vaultConfig)
-		if err != nil {
-			return "", fmt.Errorf("Error getting Vault client: %s", err)
-		}
-		secret, err := cli.Logical().Read(path)
-		if err != nil {
-			return "", fmt.Errorf("Error reading vault secret: %s", err)
-		}
-		if secret == nil {
-			return "", errors.New("Vault Secret does not exist at the given path")
-		}
-
-		data, ok := secret.Data["data"]
-		if !ok {
-			// maybe ths is v1, not v2 kv store
-			value, ok := secret.Data[key]
-			if ok {
-				return value.(string), nil
-			}
-
-			// neither v1 nor v2 proudced a valid value
-			return "", fmt.Errorf("Vault data was empty at the "+
-				"given path. Warnings: %s", strings.Join(secret.Warnings, "; "))
-		}
-
-		value := data.(map[string]interface{})[key].(string)
-		return value, nil
+		return VaultSecret(path, key)
 	}
 }
 
@@ -338,12 +374,6 @@ func funcGenAwsSecrets(ctx *Context) interface{} {
 		if len(secret) == 0 {
 			return "", errors.New("At least one parameter must be used")
 		}
-		// client uses AWS SDK CredentialChain method. So,credentials can
-		// be loaded from credential file, environment variables, or IAM
-		// roles.
-		client := awssmapi.New(
-			&awssmapi.AWSConfig{},
-		)
 
 		var name, key string
 		name = secret[0]
@@ -354,16 +384,53 @@ func funcGenAwsSecrets(ctx *Context) interface{} {
 			key = secret[1]
 		}
 
-		spec := &awssmapi.SecretSpec{
-			Name: name,
-			Key:  key,
+		return AWSSecret(name, key)
+	}
+}
+
+func funcGenAzureKeyVault(ctx *Context) interface{} {
+	return func(vaultName, name string, version ...string) (string, error) {
+		if !ctx.EnableEnv {
+			// The error message doesn't have to be that detailed since
+			// semantic checks should catch this.
+			return "", errors.New("Azure Key Vault vars are only allowed in the variables section")
+		}
+		if len(version) > 1 {
+			return "", fmt.Errorf("too many values, 1 needed: %v", version)
+		}
+		v := ""
+		if len(version) == 1 {
+			v = version[0]
 		}
 
-		s, err := client.GetSecret(spec)
-		if err != nil {
-			return "", fmt.Errorf("Error getting secret: %s", err)
+		return AzureKeyVaultSecret(vaultName, name, v)
+	}
+}
+
+func funcGenGCPSecretManager(ctx *Context) interface{} {
+	return func(projectID, name string, version ...string) (string, error) {
+		if !ctx.EnableEnv {
+			// The error message doesn't have to be that detailed since
+			// semantic checks should catch this.
+			return "", errors.New("GCP Secret Manager vars are only allowed in the variables section")
 		}
-		return s, nil
+		if len(version) > 1 {
+			return "", fmt.Errorf("too many values, 1 needed: %v", version)
+		}
+		v := ""
+		if len(version) == 1 {
+			v = version[0]
+		}
+
+		return GCPSecret(projectID, name, v)
+	}
+}
+
+// funcGenTOTP returns the current one-time password of a TOTP secret, the
+// base32 seed or otpauth:// URI of an authenticator app.
+func funcGenTOTP(ctx *Context) interface{} {
+	return func(secret string) (string, error) {
+		return totp.Code(secret)
 	}
 }
 
@@ -382,3 +449,69 @@ func replace_all(old, new, src string) string {
 func replace(old, new string, n int, src string) string {
 	return strings.Replace(src, old, new, n)
 }
+
+// formatdate formats the RFC 3339 timestamp, like `{{ formatdate "YYYY-MM-DD"
+// isotime }}`. See the formatdate function of HCL2 templates for the format.
+func formatdate(format, timestamp string) (string, error) {
+	v, err := stdlib.FormatDate(cty.StringVal(format), cty.StringVal(timestamp))
+	if err != nil {
+		return "", err
+	}
+	return v.AsString(), nil
+}
+
+// regexReplace replaces the matches of the regular expression pattern in s
+// with replacement, which can refer to the submatches, like $1.
+func regexReplace(s, pattern, replacement string) (string, error) {
+	v, err := stdlib.RegexReplace(cty.StringVal(s), cty.StringVal(pattern), cty.StringVal(replacement))
+	if err != nil {
+		return "", err
+	}
+	return v.AsString(), nil
+}
+
+func jsonencode(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// lookup returns the value of key in m, or the default when m doesn't have
+// key. m is a map or a JSON object, like the value of a user variable. The
+// values of JSON objects that aren't strings are returned as JSON.
+func lookup(m interface{}, key string, defaultValue ...string) (string, error) {
+	if len(defaultValue) > 1 {
+		return "", fmt.Errorf("too many values, 1 needed: %v", defaultValue)
+	}
+
+	var values map[string]interface{}
+	switch m := m.(type) {
+	case map[string]string:
+		values = make(map[string]interface{}, len(m))
+		for k, v := range m {
+			values[k] = v
+		}
+	case map[string]interface{}:
+		values = m
+	case string:
+		if err := json.Unmarshal([]byte(m), &values); err != nil {
+			return "", fmt.Errorf("lookup: %q is not a JSON object: %s", m, err)
+		}
+	default:
+		return "", fmt.Errorf("lookup: can't look up keys in a %T", m)
+	}
+
+	v, ok := values[key]
+	if !ok {
+		if len(defaultValue) == 0 {
+			return "", fmt.Errorf("lookup failed to find %q", key)
+		}
+		return defaultValue[0], nil
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return jsonencode(v)
+}
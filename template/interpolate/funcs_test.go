@@ -319,6 +319,28 @@ func TestFuncUser(t *testing.T) {
 	}
 }
 
+func TestFuncBuildOutput(t *testing.T) {
+	ctx := &Context{
+		BuildOutputs: map[string]string{
+			"base.id": "ami-123",
+		},
+	}
+	result, err := (&I{Value: `{{build_output "base" "id"}}`}).Render(ctx)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if result != "ami-123" {
+		t.Fatalf("bad: %s", result)
+	}
+
+	if _, err := (&I{Value: `{{build_output "base" "name"}}`}).Render(ctx); err == nil {
+		t.Fatal("should error on unknown output")
+	}
+	if _, err := (&I{Value: `{{build_output "base" "id"}}`}).Render(&Context{}); err == nil {
+		t.Fatal("should error without outputs")
+	}
+}
+
 func TestFuncPackerBuild(t *testing.T) {
 	type cases struct {
 		DataMap     interface{}
@@ -428,6 +450,25 @@ func TestFuncPackerVersion(t *testing.T) {
 	}
 }
 
+func TestFuncTOTP(t *testing.T) {
+	template := `{{totp "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"}}`
+
+	ctx := &Context{}
+	i := &I{Value: template}
+
+	result, err := i.Render(ctx)
+	if err != nil {
+		t.Fatalf("Input: %s\n\nerr: %s", template, err)
+	}
+	if len(result) != 6 {
+		t.Fatalf("Expected a 6 digits code, got: %s", result)
+	}
+
+	if _, err := (&I{Value: `{{totp "not base32!"}}`}).Render(ctx); err == nil {
+		t.Fatal("Expected an error for an invalid secret")
+	}
+}
+
 func TestReplaceFuncs(t *testing.T) {
 	cases := []struct {
 		Input  string
@@ -472,3 +513,108 @@ func TestReplaceFuncs(t *testing.T) {
 		}
 	}
 }
+
+func TestFuncBuildInfo(t *testing.T) {
+	ctx := &Context{
+		BuildInfo: map[string]string{
+			"build_uuid":       "0123",
+			"build_dir":        "/tmp/packer-build-0123",
+			"build_start":      "2020-08-03T10:11:12Z",
+			"git_commit":       "0123456789abcdef",
+			"git_short_commit": "0123456",
+			"git_branch":       "main",
+			"git_dirty":        "false",
+		},
+	}
+	cases := []struct {
+		Input  string
+		Output string
+	}{
+		{`{{build_uuid}}`, "0123"},
+		{`{{build_dir}}/out`, "/tmp/packer-build-0123/out"},
+		{`{{build_start}}`, "2020-08-03T10:11:12Z"},
+		{`{{build_start "20060102-150405"}}`, "20200803-101112"},
+		{`{{build_start "unix"}}`, "1596449472"},
+		{`{{git_short_commit}}-{{git_branch}}`, "0123456-main"},
+		{`{{git_dirty}}`, "false"},
+	}
+	for _, tc := range cases {
+		result, err := (&I{Value: tc.Input}).Render(ctx)
+		if err != nil {
+			t.Fatalf("Input: %s\n\nerr: %s", tc.Input, err)
+		}
+		if result != tc.Output {
+			t.Fatalf("Input: %s\n\nGot: %s", tc.Input, result)
+		}
+	}
+
+	if _, err := (&I{Value: `{{build_uuid}}`}).Render(&Context{}); err == nil {
+		t.Fatal("should error without build info")
+	}
+}
+
+func TestHCL2Funcs(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Output string
+	}{
+		{
+			`{{ formatdate "YYYY-MM-DD hh:mm" "2020-06-05T10:42:00Z" }}`,
+			`2020-06-05 10:42`,
+		},
+		{
+			`{{ regex_replace (lower (user "name")) "[^a-z0-9]+" "-" }}`,
+			`my-image-1`,
+		},
+		{
+			`{{ regex_replace "a1b22" "([a-z])([0-9]+)" "$2$1" }}`,
+			`1a22b`,
+		},
+		{
+			`{{ jsonencode (user "name") }}`,
+			`"My Image 1"`,
+		},
+		{
+			`{{ lookup (user "amis") "us-east-1" }}`,
+			`ami-123`,
+		},
+		{
+			`{{ lookup (user "amis") "eu-west-1" "ami-default" }}`,
+			`ami-default`,
+		},
+		{
+			`{{ lookup (user "amis") "count" }}`,
+			`2`,
+		},
+	}
+
+	ctx := &Context{
+		UserVariables: map[string]string{
+			"name": "My Image 1",
+			"amis": `{"us-east-1": "ami-123", "count": 2}`,
+		},
+	}
+	for _, tc := range cases {
+		i := &I{Value: tc.Input}
+		result, err := i.Render(ctx)
+		if err != nil {
+			t.Fatalf("Input: %s\n\nerr: %s", tc.Input, err)
+		}
+
+		if diff := cmp.Diff(tc.Output, result); diff != "" {
+			t.Fatalf("Input: %s\n\nUnexpected output: %s", tc.Input, diff)
+		}
+	}
+
+	for _, input := range []string{
+		`{{ lookup (user "amis") "eu-west-1" }}`,
+		`{{ lookup (user "name") "key" }}`,
+		`{{ formatdate "YYYY" "yesterday" }}`,
+		`{{ regex_replace "a" "(" "b" }}`,
+	} {
+		i := &I{Value: input}
+		if _, err := i.Render(ctx); err == nil {
+			t.Fatalf("Input: %s\n\nshould error", input)
+		}
+	}
+}
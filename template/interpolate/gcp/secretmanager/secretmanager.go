@@ -0,0 +1,79 @@
+// Package secretmanager provide methods to get data from
+// GCP Secret Manager
+package secretmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2/google"
+)
+
+const scope = "https://www.googleapis.com/auth/cloud-platform"
+
+// Client represents a GCP Secret Manager client
+type Client struct {
+	// Endpoint is the Secret Manager API endpoint.
+	Endpoint string
+
+	httpClient *http.Client
+}
+
+// New creates a GCP Secret Manager Client. It uses Application Default
+// Credentials, so credentials can be loaded from
+// GOOGLE_APPLICATION_CREDENTIALS, the gcloud SDK or the metadata server.
+func New() (*Client, error) {
+	client, err := google.DefaultClient(context.Background(), scope)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Endpoint:   "https://secretmanager.googleapis.com/v1",
+		httpClient: client,
+	}, nil
+}
+
+// GetSecret return a GCP Secret Manager secret
+// in plain text from a given secret name
+func (c *Client) GetSecret(spec *SecretSpec) (string, error) {
+	if spec.ProjectID == "" || spec.Name == "" {
+		return "", errors.New("A project id and a secret name must be set")
+	}
+	version := spec.Version
+	if version == "" {
+		version = "latest"
+	}
+
+	u := fmt.Sprintf("%s/projects/%s/secrets/%s/versions/%s:access",
+		c.Endpoint,
+		url.PathEscape(spec.ProjectID),
+		url.PathEscape(spec.Name),
+		url.PathEscape(version))
+
+	resp, err := c.httpClient.Get(u)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Unexpected status reading secret %q: %s", spec.Name, resp.Status)
+	}
+
+	var r accessResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(r.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("Error decoding secret payload: %s", err)
+	}
+
+	return string(data), nil
+}
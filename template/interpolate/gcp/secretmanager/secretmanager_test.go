@@ -0,0 +1,70 @@
+package secretmanager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetSecret(t *testing.T) {
+	testCases := []struct {
+		description string
+		arg         *SecretSpec
+		status      int
+		body        string
+		wantPath    string
+		want        string
+		ok          bool
+	}{
+		{
+			description: "latest version",
+			arg:         &SecretSpec{ProjectID: "my-project", Name: "db-password"},
+			status:      200,
+			body:        `{"name": "projects/1/secrets/db-password/versions/3", "payload": {"data": "dGVzdA=="}}`,
+			wantPath:    "/projects/my-project/secrets/db-password/versions/latest:access",
+			want:        "test",
+			ok:          true,
+		},
+		{
+			description: "explicit version",
+			arg:         &SecretSpec{ProjectID: "my-project", Name: "db-password", Version: "2"},
+			status:      200,
+			body:        `{"payload": {"data": "b2xk"}}`,
+			wantPath:    "/projects/my-project/secrets/db-password/versions/2:access",
+			want:        "old",
+			ok:          true,
+		},
+		{
+			description: "permission denied",
+			arg:         &SecretSpec{ProjectID: "my-project", Name: "db-password"},
+			status:      403,
+			body:        `{}`,
+			wantPath:    "/projects/my-project/secrets/db-password/versions/latest:access",
+			ok:          false,
+		},
+		{
+			description: "missing project",
+			arg:         &SecretSpec{Name: "db-password"},
+			ok:          false,
+		},
+	}
+
+	for _, test := range testCases {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != test.wantPath {
+				t.Errorf("%s: bad path %q", test.description, r.URL.Path)
+			}
+			w.WriteHeader(test.status)
+			w.Write([]byte(test.body))
+		}))
+		c := &Client{Endpoint: ts.URL, httpClient: ts.Client()}
+		got, err := c.GetSecret(test.arg)
+		ts.Close()
+		if test.ok != (err == nil) {
+			t.Fatalf("%s: unexpected error state: %v", test.description, err)
+		}
+		if got != test.want {
+			t.Fatalf("%s: expected %q, got %q", test.description, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package secretmanager
+
+// SecretSpec represent specs of secret to be searched.
+// If Version is not set then the "latest" version of the
+// secret is returned.
+type SecretSpec struct {
+	ProjectID string
+	Name      string
+	Version   string
+}
+
+// accessResponse is the response body of the
+// secrets.versions.access method.
+type accessResponse struct {
+	Name    string `json:"name"`
+	Payload struct {
+		Data string `json:"data"`
+	} `json:"payload"`
+}
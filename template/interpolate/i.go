@@ -22,6 +22,16 @@ type Context struct {
 	// "user" function reads from.
 	UserVariables map[string]string
 
+	// BuildOutputs are the outputs of the builds the current build depends
+	// on, keyed by "build.output", that the "build_output" function reads
+	// from.
+	BuildOutputs map[string]string
+
+	// BuildInfo describes the current build: its uuid, its scratch directory,
+	// when it started and the git metadata of its template, keyed like the
+	// functions reading them.
+	BuildInfo map[string]string
+
 	// SensitiveVariables is a list of variables to sanitize.
 	SensitiveVariables []string
 
@@ -0,0 +1,172 @@
+package interpolate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	commonhelper "github.com/hashicorp/packer/helper/common"
+	awssmapi "github.com/hashicorp/packer/template/interpolate/aws/secretsmanager"
+	azkvapi "github.com/hashicorp/packer/template/interpolate/azure/keyvault"
+	gcpsmapi "github.com/hashicorp/packer/template/interpolate/gcp/secretmanager"
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// secretCache stores every secret fetched from a remote secret store so
+// that a given secret is only read once per packer run, no matter how many
+// times it is referenced in a template. Every cached value is also
+// returned by Secrets so that it can be redacted from the logs and the UI.
+type secretCache struct {
+	m      sync.Mutex
+	values map[string]string
+}
+
+var secrets = &secretCache{values: map[string]string{}}
+
+func (c *secretCache) get(key string, fetch func() (string, error)) (string, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if v, ok := c.values[key]; ok {
+		return v, nil
+	}
+	v, err := fetch()
+	if err != nil {
+		return "", err
+	}
+	c.values[key] = v
+	return v, nil
+}
+
+// Secrets returns every value that was read from a secret store so far.
+// These values should be considered sensitive and filtered out of any
+// output.
+func Secrets() []string {
+	secrets.m.Lock()
+	defer secrets.m.Unlock()
+	res := make([]string, 0, len(secrets.values))
+	for _, v := range secrets.values {
+		res = append(res, v)
+	}
+	return res
+}
+
+func secretKey(kind string, args ...string) string {
+	return fmt.Sprintf("%s%q", kind, args)
+}
+
+// VaultSecret reads key from the Vault secret at path. Both kv v1 and v2
+// secret engines are supported. Results are cached.
+func VaultSecret(path, key string) (string, error) {
+	return secrets.get(secretKey("vault", path, key), func() (string, error) {
+		if token := os.Getenv("VAULT_TOKEN"); token == "" {
+			return "", errors.New("Must set VAULT_TOKEN env var in order to " +
+				"use vault template function")
+		}
+		// const EnvVaultAddress = "VAULT_ADDR"
+		// const EnvVaultToken = "VAULT_TOKEN"
+		vaultConfig := vaultapi.DefaultConfig()
+		if err := commonhelper.ConfigureClient(vaultConfig.HttpClient); err != nil {
+			return "", err
+		}
+		cli, err := vaultapi.NewClient(vaultConfig)
+		if err != nil {
+			return "", fmt.Errorf("Error getting Vault client: %s", err)
+		}
+		secret, err := cli.Logical().Read(path)
+		if err != nil {
+			return "", fmt.Errorf("Error reading vault secret: %s", err)
+		}
+		if secret == nil {
+			return "", errors.New("Vault Secret does not exist at the given path")
+		}
+
+		data, ok := secret.Data["data"]
+		if !ok {
+			// maybe ths is v1, not v2 kv store
+			value, ok := secret.Data[key]
+			if ok {
+				return value.(string), nil
+			}
+
+			// neither v1 nor v2 proudced a valid value
+			return "", fmt.Errorf("Vault data was empty at the "+
+				"given path. Warnings: %s", strings.Join(secret.Warnings, "; "))
+		}
+
+		value, ok := data.(map[string]interface{})[key].(string)
+		if !ok {
+			return "", fmt.Errorf("Vault key %q was not found at the given path", key)
+		}
+		return value, nil
+	})
+}
+
+// AWSSecret reads key from the AWS Secrets Manager secret called name. When
+// key is empty the only value of the secret is returned. Results are cached.
+func AWSSecret(name, key string) (string, error) {
+	return secrets.get(secretKey("aws_secretsmanager", name, key), func() (string, error) {
+		// client uses AWS SDK CredentialChain method. So,credentials can
+		// be loaded from credential file, environment variables, or IAM
+		// roles.
+		client := awssmapi.New(
+			&awssmapi.AWSConfig{},
+		)
+
+		spec := &awssmapi.SecretSpec{
+			Name: name,
+			Key:  key,
+		}
+
+		s, err := client.GetSecret(spec)
+		if err != nil {
+			return "", fmt.Errorf("Error getting secret: %s", err)
+		}
+		return s, nil
+	})
+}
+
+// AzureKeyVaultSecret reads the secret called name from the Azure Key
+// Vault vaultName. When version is empty the latest version is returned.
+// Results are cached.
+func AzureKeyVaultSecret(vaultName, name, version string) (string, error) {
+	return secrets.get(secretKey("azure_keyvault", vaultName, name, version), func() (string, error) {
+		client, err := azkvapi.New()
+		if err != nil {
+			return "", fmt.Errorf("Error getting Azure Key Vault client: %s", err)
+		}
+
+		s, err := client.GetSecret(&azkvapi.SecretSpec{
+			VaultName: vaultName,
+			Name:      name,
+			Version:   version,
+		})
+		if err != nil {
+			return "", fmt.Errorf("Error getting secret: %s", err)
+		}
+		return s, nil
+	})
+}
+
+// GCPSecret reads the secret called name in the GCP project projectID from
+// GCP Secret Manager. When version is empty the latest version is
+// returned. Results are cached.
+func GCPSecret(projectID, name, version string) (string, error) {
+	return secrets.get(secretKey("gcp_secretmanager", projectID, name, version), func() (string, error) {
+		client, err := gcpsmapi.New()
+		if err != nil {
+			return "", fmt.Errorf("Error getting GCP Secret Manager client: %s", err)
+		}
+
+		s, err := client.GetSecret(&gcpsmapi.SecretSpec{
+			ProjectID: projectID,
+			Name:      name,
+			Version:   version,
+		})
+		if err != nil {
+			return "", fmt.Errorf("Error getting secret: %s", err)
+		}
+		return s, nil
+	})
+}
@@ -0,0 +1,47 @@
+package interpolate
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSecretCache(t *testing.T) {
+	c := &secretCache{values: map[string]string{}}
+	calls := 0
+	fetch := func() (string, error) {
+		calls++
+		return "s3cr3t", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		v, err := c.get(secretKey("vault", "secret/foo", "bar"), fetch)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if v != "s3cr3t" {
+			t.Fatalf("bad: %s", v)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("secret should have been fetched once, got %d calls", calls)
+	}
+
+	_, err := c.get(secretKey("vault", "secret/foo", "baz"), func() (string, error) {
+		return "", errors.New("nope")
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if len(c.values) != 1 {
+		t.Fatalf("errors should not be cached: %#v", c.values)
+	}
+}
+
+func TestSecretKey(t *testing.T) {
+	if secretKey("gcp_secretmanager", "a", "b") == secretKey("gcp_secretmanager", "a\x00b") {
+		t.Fatal("keys should not collide")
+	}
+	if secretKey("vault", "a", "b") == secretKey("aws_secretsmanager", "a", "b") {
+		t.Fatal("keys should not collide across secret stores")
+	}
+}
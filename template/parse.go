@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/packer/packer/tmp"
@@ -31,6 +32,11 @@ type rawTemplate struct {
 	CleanupProvisioner interface{}            `mapstructure:"error-cleanup-provisioner" json:"error-cleanup-provisioner,omitempty"`
 	Variables          map[string]interface{} `json:"variables,omitempty"`
 	SensitiveVariables []string               `mapstructure:"sensitive-variables" json:"sensitive-variables,omitempty"`
+	ErrorHandling      *ErrorHandling         `mapstructure:"error_handling" json:"error_handling,omitempty"`
+	BuildTimeout       time.Duration          `mapstructure:"build_timeout" json:"build_timeout,omitempty"`
+	ProvisionTimeout   time.Duration          `mapstructure:"provision_timeout" json:"provision_timeout,omitempty"`
+	PostProcessTimeout time.Duration          `mapstructure:"post_process_timeout" json:"post_process_timeout,omitempty"`
+	Notifications      []*Notification        `mapstructure:"notifications" json:"notifications,omitempty"`
 
 	RawContents []byte `json:"-"`
 }
@@ -77,8 +83,12 @@ func (r *rawTemplate) decodeProvisioner(raw interface{}) (Provisioner, error) {
 	delete(p.Config, "override")
 	delete(p.Config, "pause_before")
 	delete(p.Config, "max_retries")
+	delete(p.Config, "error_handling")
 	delete(p.Config, "type")
 	delete(p.Config, "timeout")
+	delete(p.Config, "remote_user")
+	delete(p.Config, "remote_password")
+	delete(p.Config, "idempotency_marker")
 
 	if len(p.Config) == 0 {
 		p.Config = nil
@@ -96,6 +106,11 @@ func (r *rawTemplate) Template() (*Template, error) {
 	result.Description = r.Description
 	result.MinVersion = r.MinVersion
 	result.RawContents = r.RawContents
+	result.ErrorHandling = r.ErrorHandling
+	result.BuildTimeout = r.BuildTimeout
+	result.ProvisionTimeout = r.ProvisionTimeout
+	result.PostProcessTimeout = r.PostProcessTimeout
+	result.Notifications = r.Notifications
 
 	// Gather the comments
 	if len(r.Comments) > 0 {
@@ -153,6 +168,9 @@ func (r *rawTemplate) Template() (*Template, error) {
 
 		delete(b.Config, "name")
 		delete(b.Config, "type")
+		delete(b.Config, "depends_on")
+		delete(b.Config, "outputs")
+		delete(b.Config, "locks")
 
 		if len(b.Config) == 0 {
 			b.Config = nil
@@ -360,8 +378,9 @@ func Parse(r io.Reader) (*Template, error) {
 	var rawTpl rawTemplate
 	rawTpl.RawContents = buf.Bytes()
 	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
-		Metadata: &md,
-		Result:   &rawTpl,
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Metadata:   &md,
+		Result:     &rawTpl,
 	})
 	if err != nil {
 		return nil, err
@@ -481,6 +500,21 @@ func checkForDuplicateFields(d *json.Decoder) error {
 	return nil
 }
 
+// SyntaxError is a JSON syntax error in a template file, with its position.
+type SyntaxError struct {
+	Err error
+	// Line and Column are the position of the error.
+	Line   int
+	Column int
+	Offset int64
+	// Highlight are the lines of the template up to the error.
+	Highlight string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("Error parsing JSON: %s\nAt line %d, column %d (offset %d):\n%s", e.Err, e.Line, e.Column, e.Offset, e.Highlight)
+}
+
 // ParseFile is the same as Parse but is a helper to automatically open
 // a file for parsing.
 func ParseFile(path string) (*Template, error) {
@@ -519,8 +553,13 @@ func ParseFile(path string) (*Template, error) {
 		}
 		// Grab the error location, and return a string to point to offending syntax error
 		line, col, highlight := highlightPosition(f, syntaxErr.Offset)
-		err = fmt.Errorf("Error parsing JSON: %s\nAt line %d, column %d (offset %d):\n%s", err, line, col, syntaxErr.Offset, highlight)
-		return nil, err
+		return nil, &SyntaxError{
+			Err:       err,
+			Line:      line,
+			Column:    col,
+			Offset:    syntaxErr.Offset,
+			Highlight: highlight,
+		}
 	}
 
 	if !filepath.IsAbs(path) {
@@ -5,6 +5,7 @@ package template
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -132,6 +133,57 @@ func TestParse(t *testing.T) {
 			false,
 		},
 
+		{
+			"parse-provisioner-remote-user.json",
+			&Template{
+				Provisioners: []*Provisioner{
+					{
+						Type:       "something",
+						RemoteUser: "app",
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"parse-provisioner-idempotency-marker.json",
+			&Template{
+				Provisioners: []*Provisioner{
+					{
+						Type:              "something",
+						IdempotencyMarker: "/var/lib/packer/something.done",
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"parse-timeouts.json",
+			&Template{
+				BuildTimeout:       2 * time.Hour,
+				ProvisionTimeout:   time.Hour,
+				PostProcessTimeout: 30 * time.Minute,
+			},
+			false,
+		},
+
+		{
+			"parse-notifications.json",
+			&Template{
+				Notifications: []*Notification{
+					{
+						Type:    "http",
+						On:      []string{"start", "success"},
+						URL:     "https://example.com/hooks/packer",
+						Headers: map[string]string{"Authorization": "Bearer {{user `token`}}"},
+					},
+				},
+			},
+			false,
+		},
+
 		{
 			"parse-provisioner-only.json",
 			&Template{
@@ -571,6 +623,14 @@ func TestParse_bad(t *testing.T) {
 		if !strings.Contains(err.Error(), tc.Expected) {
 			t.Fatalf("file: %s\nExpected: %s\n%s\n", tc.File, tc.Expected, err.Error())
 		}
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Fatalf("file: %s\nexpected a syntax error, got %T", tc.File, err)
+		}
+		pos := fmt.Sprintf("line %d, column %d (offset %d)", syntaxErr.Line, syntaxErr.Column, syntaxErr.Offset)
+		if pos != tc.Expected {
+			t.Fatalf("file: %s\nExpected: %s\n%s\n", tc.File, tc.Expected, pos)
+		}
 	}
 }
 
@@ -1,4 +1,4 @@
-//go:generate mapstructure-to-hcl2 -type Provisioner
+//go:generate mapstructure-to-hcl2 -type Provisioner,ErrorHandling
 
 package template
 
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	multierror "github.com/hashicorp/go-multierror"
@@ -29,6 +30,20 @@ type Template struct {
 	CleanupProvisioner *Provisioner
 	PostProcessors     [][]*PostProcessor
 
+	// ErrorHandling tells what to do when a build fails. The -on-error
+	// command line option takes precedence.
+	ErrorHandling *ErrorHandling
+
+	// BuildTimeout, ProvisionTimeout and PostProcessTimeout bound the time
+	// spent in a whole build, in its provisioning phase and in its
+	// post-processing phase. A zero value means no timeout.
+	BuildTimeout       time.Duration
+	ProvisionTimeout   time.Duration
+	PostProcessTimeout time.Duration
+
+	// Notifications are sent when the builds start, succeed or fail.
+	Notifications []*Notification
+
 	// RawContents is just the raw data for this template
 	RawContents []byte
 }
@@ -39,6 +54,11 @@ func (t *Template) Raw() (*rawTemplate, error) {
 
 	out.MinVersion = t.MinVersion
 	out.Description = t.Description
+	out.ErrorHandling = t.ErrorHandling
+	out.BuildTimeout = t.BuildTimeout
+	out.ProvisionTimeout = t.ProvisionTimeout
+	out.PostProcessTimeout = t.PostProcessTimeout
+	out.Notifications = t.Notifications
 
 	for k, v := range t.Comments {
 		out.Comments = append(out.Comments, map[string]string{k: v})
@@ -73,8 +93,17 @@ func (t *Template) Raw() (*rawTemplate, error) {
 
 // Builder represents a builder configured in the template
 type Builder struct {
-	Name   string                 `json:"name,omitempty"`
-	Type   string                 `json:"type"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+	// DependsOn are the names of the builders whose builds must succeed
+	// before this one runs.
+	DependsOn []string `mapstructure:"depends_on" json:"depends_on,omitempty"`
+	// Outputs are the named outputs of the build, computed from its
+	// artifacts once it succeeded.
+	Outputs map[string]string `mapstructure:"outputs" json:"outputs,omitempty"`
+	// Locks are the names shared with other builds, like image names, that
+	// the build locks in the state backend while it runs.
+	Locks  []string               `mapstructure:"locks" json:"locks,omitempty"`
 	Config map[string]interface{} `json:"config,omitempty"`
 }
 
@@ -143,6 +172,45 @@ type Provisioner struct {
 	PauseBefore time.Duration          `mapstructure:"pause_before" json:"pause_before,omitempty"`
 	MaxRetries  string                 `mapstructure:"max_retries" json:"max_retries,omitempty"`
 	Timeout     time.Duration          `mapstructure:"timeout" json:"timeout,omitempty"`
+
+	RemoteUser     string `mapstructure:"remote_user" json:"remote_user,omitempty"`
+	RemotePassword string `mapstructure:"remote_password" json:"remote_password,omitempty"`
+
+	IdempotencyMarker string `mapstructure:"idempotency_marker" json:"idempotency_marker,omitempty"`
+
+	ErrorHandling *ErrorHandling `mapstructure:"error_handling" json:"error_handling,omitempty"`
+}
+
+// ErrorHandling describes what to do when a build or a provisioner fails.
+type ErrorHandling struct {
+	// Retries is the number of times a failing provisioner is retried.
+	// It is not valid for builds.
+	Retries int `mapstructure:"retries" json:"retries,omitempty"`
+	// OnFailure is one of cleanup, abort, ask or continue for a
+	// provisioner, and one of the values of the -on-error option for a
+	// build.
+	OnFailure string `mapstructure:"on_failure" json:"on_failure,omitempty"`
+}
+
+// Notification is a notification sent when the builds start, succeed or
+// fail. The template of its payload and of its subject is rendered once the
+// event happens, with the data of the build, while its other settings are
+// rendered with the user variables.
+type Notification struct {
+	Type    string            `mapstructure:"type" json:"type"`
+	On      []string          `mapstructure:"on" json:"on,omitempty"`
+	URL     string            `mapstructure:"url" json:"url,omitempty"`
+	Method  string            `mapstructure:"method" json:"method,omitempty"`
+	Headers map[string]string `mapstructure:"headers" json:"headers,omitempty"`
+
+	Template string `mapstructure:"template" json:"template,omitempty"`
+	Subject  string `mapstructure:"subject" json:"subject,omitempty"`
+
+	SMTPAddress  string   `mapstructure:"smtp_address" json:"smtp_address,omitempty"`
+	SMTPUsername string   `mapstructure:"smtp_username" json:"smtp_username,omitempty"`
+	SMTPPassword string   `mapstructure:"smtp_password" json:"smtp_password,omitempty"`
+	From         string   `mapstructure:"from" json:"from,omitempty"`
+	To           []string `mapstructure:"to" json:"to,omitempty"`
 }
 
 // MarshalJSON conducts the necessary flattening of the Provisioner struct
@@ -238,6 +306,21 @@ func (t *Template) Validate() error {
 		}
 	}
 
+	// Verify that builders depend on builders that exist
+	names := make([]string, 0, len(t.Builders))
+	for name := range t.Builders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, d := range t.Builders[name].DependsOn {
+			if _, ok := t.Builders[d]; !ok {
+				err = multierror.Append(err, fmt.Errorf(
+					"builder %s: depends on builder '%s', which doesn't exist", name, d))
+			}
+		}
+	}
+
 	// Verify post-processors
 	for i, chain := range t.PostProcessors {
 		for j, p := range chain {
@@ -77,6 +77,16 @@ func TestTemplateValidate(t *testing.T) {
 			"validate-good-pp-except.json",
 			false,
 		},
+
+		{
+			"validate-bad-depends-on.json",
+			true,
+		},
+
+		{
+			"validate-good-depends-on.json",
+			false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -21,7 +21,9 @@ export default [
               'post-processors',
             ],
           },
+          'import',
           'locals',
+          'semaphore',
           'source',
           'variable',
         ],
@@ -136,6 +138,16 @@ export default [
             category: 'uuid',
             content: ['uuidv4', 'uuidv5'],
           },
+          {
+            category: 'secrets',
+            content: [
+              'aws_secretsmanager',
+              'azure_keyvault',
+              'gcp_secretmanager',
+              'totp',
+              'vault',
+            ],
+          },
           {
             category: 'ipnet',
             content: ['cidrhost', 'cidrnetmask', 'cidrsubnet'],
@@ -160,7 +172,7 @@ export default [
   'terminology',
   {
     category: 'commands',
-    content: ['build', 'console', 'fix', 'inspect', 'validate'],
+    content: ['agent', 'artifacts', 'build', 'cleanup', 'console', 'fix', 'fmt', 'hcl2_upgrade', 'image-diff', 'init', 'inspect', 'lint', 'lsp', 'output', 'plan', 'registry', 'remote-build', 'resume', 'schema', 'state', 'validate'],
   },
   {
     category: 'templates',
@@ -241,6 +253,7 @@ export default [
       'converge',
       'file',
       'inspec',
+      'inventory',
       'powershell',
       'puppet-masterless',
       'puppet-server',
@@ -249,6 +262,7 @@ export default [
       'shell-local',
       'windows-shell',
       'windows-restart',
+      'windows-update',
       'custom',
       'community-supported',
     ],
@@ -270,6 +284,7 @@ export default [
       'googlecompute-export',
       'googlecompute-import',
       'manifest',
+      'registry',
       'shell-local',
       'ucloud-import',
       'vagrant',
@@ -295,5 +310,6 @@ export default [
   '---------',
   'environment-variables',
   'core-configuration',
+  'credential-helpers',
   'debugging',
 ]